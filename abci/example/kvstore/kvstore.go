@@ -64,6 +64,12 @@ type Application struct {
 	types.BaseApplication
 
 	state State
+
+	// snapshots by height, see snapshots.go
+	snapshots        map[uint64]*snapshot
+	restore          *restore
+	inBlock          bool // a block is being executed
+	snapshotAtCommit bool // a snapshot was requested during the block
 }
 
 func NewApplication() *Application {
@@ -81,6 +87,11 @@ func (app *Application) Info(req types.RequestInfo) (resInfo types.ResponseInfo)
 	}
 }
 
+func (app *Application) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginBlock {
+	app.inBlock = true
+	return types.ResponseBeginBlock{}
+}
+
 // tx is either "key=value" or just arbitrary bytes
 func (app *Application) DeliverTx(req types.RequestDeliverTx) types.ResponseDeliverTx {
 	var key, value []byte
@@ -118,6 +129,14 @@ func (app *Application) Commit() types.ResponseCommit {
 	app.state.AppHash = appHash
 	app.state.Height++
 	saveState(app.state)
+
+	app.inBlock = false
+	if app.snapshotAtCommit {
+		app.snapshotAtCommit = false
+		if err := app.takeSnapshot(); err != nil {
+			panic(err)
+		}
+	}
 	return types.ResponseCommit{Data: appHash}
 }
 
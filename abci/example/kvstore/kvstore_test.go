@@ -184,6 +184,65 @@ func TestValUpdates(t *testing.T) {
 
 }
 
+func TestSnapshots(t *testing.T) {
+	app := NewApplication()
+
+	// nothing to snapshot yet
+	require.True(t, app.CreateSnapshot(types.RequestCreateSnapshot{}).IsErr())
+
+	makeApplyBlock(t, app, 1, nil, []byte(testKey+"="+testValue))
+	res := app.CreateSnapshot(types.RequestCreateSnapshot{})
+	require.True(t, res.IsOK(), res)
+	require.EqualValues(t, 1, res.Height)
+
+	// a snapshot requested during a block is taken at its commit
+	app.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	app.DeliverTx(types.RequestDeliverTx{Tx: []byte("foo=bar")})
+	res = app.CreateSnapshot(types.RequestCreateSnapshot{})
+	require.True(t, res.IsOK(), res)
+	require.EqualValues(t, 2, res.Height)
+	app.EndBlock(types.RequestEndBlock{Height: 2})
+	app.Commit()
+
+	snapshots := app.ListSnapshots(types.RequestListSnapshots{}).Snapshots
+	require.Len(t, snapshots, 2)
+	require.EqualValues(t, 1, snapshots[0].Height)
+	require.EqualValues(t, 2, snapshots[1].Height)
+	snapshot := snapshots[1]
+
+	// restore the snapshot into an empty store
+	info := app.Info(types.RequestInfo{})
+	restored := NewApplication()
+	offer := restored.OfferSnapshot(types.RequestOfferSnapshot{Snapshot: snapshot, AppHash: info.LastBlockAppHash})
+	require.Equal(t, types.ResponseOfferSnapshot_ACCEPT, offer.Result)
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chunk := app.LoadSnapshotChunk(types.RequestLoadSnapshotChunk{
+			Height: snapshot.Height, Format: snapshot.Format, Chunk: i}).Chunk
+		require.NotEmpty(t, chunk)
+		apply := restored.ApplySnapshotChunk(types.RequestApplySnapshotChunk{Index: i, Chunk: chunk})
+		require.Equal(t, types.ResponseApplySnapshotChunk_ACCEPT, apply.Result)
+	}
+	require.Equal(t, info, restored.Info(types.RequestInfo{}))
+	query := restored.Query(types.RequestQuery{Data: []byte("foo")})
+	require.Equal(t, "bar", string(query.Value))
+
+	// a non-empty store doesn't take snapshots
+	offer = restored.OfferSnapshot(types.RequestOfferSnapshot{Snapshot: snapshot, AppHash: info.LastBlockAppHash})
+	require.Equal(t, types.ResponseOfferSnapshot_ABORT, offer.Result)
+
+	// a snapshot with the wrong app hash is rejected
+	other := NewApplication()
+	offer = other.OfferSnapshot(types.RequestOfferSnapshot{Snapshot: snapshot, AppHash: []byte("foo")})
+	require.Equal(t, types.ResponseOfferSnapshot_ACCEPT, offer.Result)
+	chunk := app.LoadSnapshotChunk(types.RequestLoadSnapshotChunk{Height: 2, Format: snapshot.Format}).Chunk
+	apply := other.ApplySnapshotChunk(types.RequestApplySnapshotChunk{Chunk: chunk})
+	require.Equal(t, types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT, apply.Result)
+
+	require.True(t, app.DeleteSnapshot(types.RequestDeleteSnapshot{Height: 1, Format: snapshot.Format}).IsOK())
+	require.True(t, app.DeleteSnapshot(types.RequestDeleteSnapshot{Height: 1, Format: snapshot.Format}).IsErr())
+	require.Len(t, app.ListSnapshots(types.RequestListSnapshots{}).Snapshots, 1)
+}
+
 func makeApplyBlock(
 	t *testing.T,
 	kvstore types.Application,
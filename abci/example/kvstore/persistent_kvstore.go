@@ -10,6 +10,7 @@ import (
 	"github.com/tendermint/tendermint/abci/example/code"
 	"github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
 	"github.com/tendermint/tendermint/libs/log"
 	tmtypes "github.com/tendermint/tendermint/types"
 	dbm "github.com/tendermint/tm-db"
@@ -120,6 +121,8 @@ func (app *PersistentKVStoreApplication) InitChain(req types.RequestInitChain) t
 
 // Track the block hash and header information
 func (app *PersistentKVStoreApplication) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginBlock {
+	app.app.BeginBlock(req)
+
 	// reset valset changes
 	app.ValUpdates = make([]types.ValidatorUpdate, 0)
 
@@ -143,6 +146,50 @@ func (app *PersistentKVStoreApplication) EndBlock(req types.RequestEndBlock) typ
 	return types.ResponseEndBlock{ValidatorUpdates: app.ValUpdates}
 }
 
+func (app *PersistentKVStoreApplication) ExtendVote(req types.RequestExtendVote) types.ResponseExtendVote {
+	return app.app.ExtendVote(req)
+}
+
+func (app *PersistentKVStoreApplication) VerifyVoteExtension(
+	req types.RequestVerifyVoteExtension) types.ResponseVerifyVoteExtension {
+	return app.app.VerifyVoteExtension(req)
+}
+
+func (app *PersistentKVStoreApplication) ListSnapshots(req types.RequestListSnapshots) types.ResponseListSnapshots {
+	return app.app.ListSnapshots(req)
+}
+
+func (app *PersistentKVStoreApplication) OfferSnapshot(req types.RequestOfferSnapshot) types.ResponseOfferSnapshot {
+	return app.app.OfferSnapshot(req)
+}
+
+func (app *PersistentKVStoreApplication) LoadSnapshotChunk(
+	req types.RequestLoadSnapshotChunk) types.ResponseLoadSnapshotChunk {
+	return app.app.LoadSnapshotChunk(req)
+}
+
+// ApplySnapshotChunk restores the validators, along with the key-value store,
+// once the last chunk is applied.
+func (app *PersistentKVStoreApplication) ApplySnapshotChunk(
+	req types.RequestApplySnapshotChunk) types.ResponseApplySnapshotChunk {
+	restoring := app.app.restore != nil
+	res := app.app.ApplySnapshotChunk(req)
+	if restoring && app.app.restore == nil && res.Result == types.ResponseApplySnapshotChunk_ACCEPT {
+		for _, v := range app.Validators() {
+			app.valAddrToPubKeyMap[string(pubKeyAddress(v.PubKey))] = v.PubKey
+		}
+	}
+	return res
+}
+
+func (app *PersistentKVStoreApplication) CreateSnapshot(req types.RequestCreateSnapshot) types.ResponseCreateSnapshot {
+	return app.app.CreateSnapshot(req)
+}
+
+func (app *PersistentKVStoreApplication) DeleteSnapshot(req types.RequestDeleteSnapshot) types.ResponseDeleteSnapshot {
+	return app.app.DeleteSnapshot(req)
+}
+
 //---------------------------------------------
 // update validators
 
@@ -174,7 +221,7 @@ func isValidatorTx(tx []byte) bool {
 }
 
 // format is "val:pubkey!power"
-// pubkey is a base64-encoded 32-byte ed25519 key
+// pubkey is a base64-encoded 32-byte ed25519 key or 33-byte secp256k1 key
 func (app *PersistentKVStoreApplication) execValidatorTx(tx []byte) types.ResponseDeliverTx {
 	tx = tx[len(ValidatorSetChangePrefix):]
 
@@ -204,15 +251,16 @@ func (app *PersistentKVStoreApplication) execValidatorTx(tx []byte) types.Respon
 	}
 
 	// update
+	if len(pubkey) == secp256k1.PubKeySecp256k1Size {
+		return app.updateValidator(types.Secp256k1ValidatorUpdate(pubkey, power))
+	}
 	return app.updateValidator(types.Ed25519ValidatorUpdate(pubkey, power))
 }
 
 // add, update, or remove a validator
 func (app *PersistentKVStoreApplication) updateValidator(v types.ValidatorUpdate) types.ResponseDeliverTx {
 	key := []byte("val:" + string(v.PubKey.Data))
-
-	pubkey := ed25519.PubKeyEd25519{}
-	copy(pubkey[:], v.PubKey.Data)
+	addr := pubKeyAddress(v.PubKey)
 
 	if v.Power == 0 {
 		// remove validator
@@ -227,7 +275,7 @@ func (app *PersistentKVStoreApplication) updateValidator(v types.ValidatorUpdate
 				Log:  fmt.Sprintf("Cannot remove non-existent validator %s", pubStr)}
 		}
 		app.app.state.db.Delete(key)
-		delete(app.valAddrToPubKeyMap, string(pubkey.Address()))
+		delete(app.valAddrToPubKeyMap, string(addr))
 	} else {
 		// add or update validator
 		value := bytes.NewBuffer(make([]byte, 0))
@@ -237,7 +285,7 @@ func (app *PersistentKVStoreApplication) updateValidator(v types.ValidatorUpdate
 				Log:  fmt.Sprintf("Error encoding validator: %v", err)}
 		}
 		app.app.state.db.Set(key, value.Bytes())
-		app.valAddrToPubKeyMap[string(pubkey.Address())] = v.PubKey
+		app.valAddrToPubKeyMap[string(addr)] = v.PubKey
 	}
 
 	// we only update the changes array if we successfully updated the tree
@@ -245,3 +293,15 @@ func (app *PersistentKVStoreApplication) updateValidator(v types.ValidatorUpdate
 
 	return types.ResponseDeliverTx{Code: code.CodeTypeOK}
 }
+
+// pubKeyAddress returns the address of the validator key pk.
+func pubKeyAddress(pk types.PubKey) []byte {
+	if pk.Type == types.PubKeySecp256k1 {
+		pubkey := secp256k1.PubKeySecp256k1{}
+		copy(pubkey[:], pk.Data)
+		return pubkey.Address()
+	}
+	pubkey := ed25519.PubKeyEd25519{}
+	copy(pubkey[:], pk.Data)
+	return pubkey.Address()
+}
@@ -0,0 +1,215 @@
+package kvstore
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/tendermint/tendermint/abci/example/code"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+const (
+	// snapshotFormat is the format of the snapshots: the JSON array of the
+	// key/value pairs of the store, split into chunks.
+	snapshotFormat uint32 = 1
+
+	snapshotChunkSize = 64 * 1024
+)
+
+// snapshot is a snapshot taken by the application, along with its chunks.
+type snapshot struct {
+	types.Snapshot
+	chunks [][]byte
+}
+
+// snapshotPair is a key/value pair of the store, in a snapshot.
+type snapshotPair struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// restore is a snapshot being restored.
+type restore struct {
+	snapshot types.Snapshot
+	appHash  []byte
+	chunks   [][]byte
+	received int
+}
+
+// ListSnapshots returns the snapshots taken so far. The snapshots are kept in
+// memory: they don't survive a restart.
+func (app *Application) ListSnapshots(req types.RequestListSnapshots) types.ResponseListSnapshots {
+	res := types.ResponseListSnapshots{Snapshots: make([]*types.Snapshot, 0, len(app.snapshots))}
+	for _, s := range app.snapshots {
+		s := s.Snapshot
+		res.Snapshots = append(res.Snapshots, &s)
+	}
+	sort.Slice(res.Snapshots, func(i, j int) bool { return res.Snapshots[i].Height < res.Snapshots[j].Height })
+	return res
+}
+
+// CreateSnapshot snapshots the store right away, unless a block is being
+// executed: the snapshot is then taken once the block is committed.
+func (app *Application) CreateSnapshot(req types.RequestCreateSnapshot) types.ResponseCreateSnapshot {
+	if app.inBlock {
+		app.snapshotAtCommit = true
+		return types.ResponseCreateSnapshot{Code: code.CodeTypeOK, Height: uint64(app.state.Height + 1)}
+	}
+	if app.state.Height == 0 {
+		return types.ResponseCreateSnapshot{Code: code.CodeTypeUnknownError, Log: "nothing to snapshot"}
+	}
+	if err := app.takeSnapshot(); err != nil {
+		return types.ResponseCreateSnapshot{Code: code.CodeTypeUnknownError, Log: err.Error()}
+	}
+	return types.ResponseCreateSnapshot{Code: code.CodeTypeOK, Height: uint64(app.state.Height)}
+}
+
+// DeleteSnapshot deletes the snapshot of the given height and format.
+func (app *Application) DeleteSnapshot(req types.RequestDeleteSnapshot) types.ResponseDeleteSnapshot {
+	s, ok := app.snapshots[req.Height]
+	if !ok || s.Format != req.Format {
+		return types.ResponseDeleteSnapshot{
+			Code: code.CodeTypeUnknownError,
+			Log:  fmt.Sprintf("no snapshot at height %d in format %d", req.Height, req.Format)}
+	}
+	delete(app.snapshots, req.Height)
+	return types.ResponseDeleteSnapshot{Code: code.CodeTypeOK}
+}
+
+// LoadSnapshotChunk returns the chunk, or nothing if there is no such chunk.
+func (app *Application) LoadSnapshotChunk(req types.RequestLoadSnapshotChunk) types.ResponseLoadSnapshotChunk {
+	s, ok := app.snapshots[req.Height]
+	if !ok || s.Format != req.Format || req.Chunk >= s.Chunks {
+		return types.ResponseLoadSnapshotChunk{}
+	}
+	return types.ResponseLoadSnapshotChunk{Chunk: s.chunks[req.Chunk]}
+}
+
+// OfferSnapshot accepts the snapshots of the known format, as long as the
+// store is empty.
+func (app *Application) OfferSnapshot(req types.RequestOfferSnapshot) types.ResponseOfferSnapshot {
+	switch {
+	case req.Snapshot == nil:
+		return types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_REJECT}
+	case req.Snapshot.Format != snapshotFormat:
+		return types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_REJECT_FORMAT}
+	case app.state.Height != 0:
+		return types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_ABORT}
+	case req.Snapshot.Chunks == 0:
+		return types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_REJECT}
+	}
+	app.restore = &restore{
+		snapshot: *req.Snapshot,
+		appHash:  req.AppHash,
+		chunks:   make([][]byte, req.Snapshot.Chunks),
+	}
+	return types.ResponseOfferSnapshot{Result: types.ResponseOfferSnapshot_ACCEPT}
+}
+
+// ApplySnapshotChunk collects the chunks of the snapshot being restored and,
+// once they have all been received, restores the store from them.
+func (app *Application) ApplySnapshotChunk(req types.RequestApplySnapshotChunk) types.ResponseApplySnapshotChunk {
+	r := app.restore
+	if r == nil {
+		return types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}
+	}
+	if req.Index >= uint32(len(r.chunks)) {
+		return types.ResponseApplySnapshotChunk{
+			Result:        types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT,
+			RejectSenders: []string{req.Sender}}
+	}
+	if r.chunks[req.Index] == nil {
+		r.received++
+	}
+	r.chunks[req.Index] = req.Chunk
+	if r.received < len(r.chunks) {
+		return types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ACCEPT}
+	}
+
+	app.restore = nil
+	if err := app.restoreSnapshot(r); err != nil {
+		return types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_REJECT_SNAPSHOT}
+	}
+	return types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ACCEPT}
+}
+
+// takeSnapshot snapshots the store at the current height.
+func (app *Application) takeSnapshot() error {
+	var pairs []snapshotPair
+	itr, err := app.state.db.Iterator(nil, nil)
+	if err != nil {
+		return err
+	}
+	for ; itr.Valid(); itr.Next() {
+		pairs = append(pairs, snapshotPair{Key: itr.Key(), Value: itr.Value()})
+	}
+	itr.Close()
+	data, err := json.Marshal(pairs)
+	if err != nil {
+		return err
+	}
+
+	var chunks [][]byte
+	for len(data) > snapshotChunkSize {
+		chunks = append(chunks, data[:snapshotChunkSize])
+		data = data[snapshotChunkSize:]
+	}
+	chunks = append(chunks, data)
+	hash := snapshotHash(chunks)
+
+	if app.snapshots == nil {
+		app.snapshots = make(map[uint64]*snapshot)
+	}
+	height := uint64(app.state.Height)
+	app.snapshots[height] = &snapshot{
+		Snapshot: types.Snapshot{
+			Height: height,
+			Format: snapshotFormat,
+			Chunks: uint32(len(chunks)),
+			Hash:   hash,
+		},
+		chunks: chunks,
+	}
+	return nil
+}
+
+// restoreSnapshot replaces the store with the contents of the snapshot, after
+// checking them against its hash and the app hash.
+func (app *Application) restoreSnapshot(r *restore) error {
+	if !bytes.Equal(snapshotHash(r.chunks), r.snapshot.Hash) {
+		return fmt.Errorf("snapshot hash mismatch")
+	}
+	var pairs []snapshotPair
+	if err := json.Unmarshal(bytes.Join(r.chunks, nil), &pairs); err != nil {
+		return err
+	}
+
+	var state State
+	for _, pair := range pairs {
+		if bytes.Equal(pair.Key, stateKey) {
+			if err := json.Unmarshal(pair.Value, &state); err != nil {
+				return err
+			}
+		}
+	}
+	if state.Height != int64(r.snapshot.Height) || !bytes.Equal(state.AppHash, r.appHash) {
+		return fmt.Errorf("snapshot state mismatch: height %d, app hash %X", state.Height, state.AppHash)
+	}
+
+	for _, pair := range pairs {
+		app.state.db.Set(pair.Key, pair.Value)
+	}
+	app.state = loadState(app.state.db)
+	return nil
+}
+
+func snapshotHash(chunks [][]byte) []byte {
+	h := sha256.New()
+	for _, chunk := range chunks {
+		h.Write(chunk) // nolint: errcheck
+	}
+	return h.Sum(nil)
+}
@@ -1,7 +1,8 @@
 package types
 
 const (
-	PubKeyEd25519 = "ed25519"
+	PubKeyEd25519   = "ed25519"
+	PubKeySecp256k1 = "secp256k1"
 )
 
 func Ed25519ValidatorUpdate(pubkey []byte, power int64) ValidatorUpdate {
@@ -14,3 +15,14 @@ func Ed25519ValidatorUpdate(pubkey []byte, power int64) ValidatorUpdate {
 		Power: power,
 	}
 }
+
+func Secp256k1ValidatorUpdate(pubkey []byte, power int64) ValidatorUpdate {
+	return ValidatorUpdate{
+		// Address:
+		PubKey: PubKey{
+			Type: PubKeySecp256k1,
+			Data: pubkey,
+		},
+		Power: power,
+	}
+}
@@ -9,6 +9,9 @@ import (
 
 const (
 	CodeTypeOK uint32 = 0
+	// CodeTypeNotSupported is returned by BaseApplication for the optional
+	// operations (e.g. CreateSnapshot) the application doesn't implement.
+	CodeTypeNotSupported uint32 = 1
 )
 
 // IsOK returns true if Code is OK.
@@ -41,6 +44,26 @@ func (r ResponseQuery) IsErr() bool {
 	return r.Code != CodeTypeOK
 }
 
+// IsOK returns true if Code is OK.
+func (r ResponseCreateSnapshot) IsOK() bool {
+	return r.Code == CodeTypeOK
+}
+
+// IsErr returns true if Code is something other than OK.
+func (r ResponseCreateSnapshot) IsErr() bool {
+	return r.Code != CodeTypeOK
+}
+
+// IsOK returns true if Code is OK.
+func (r ResponseDeleteSnapshot) IsOK() bool {
+	return r.Code == CodeTypeOK
+}
+
+// IsErr returns true if Code is something other than OK.
+func (r ResponseDeleteSnapshot) IsErr() bool {
+	return r.Code != CodeTypeOK
+}
+
 //---------------------------------------------------------------------------
 // override JSON marshalling so we emit defaults (ie. disable omitempty)
 
@@ -30,6 +30,16 @@ func MessageOutOfOrder(peerID p2p.ID, explanation string) PeerBehaviour {
 	return PeerBehaviour{peerID: peerID, reason: messageOutOfOrder{explanation}}
 }
 
+type misbehaviour struct {
+	explanation string
+}
+
+// Misbehaviour returns a misbehaviour PeerBehaviour, e.g. for sending an
+// invalid block or sending blocks too slowly, which gets the peer banned.
+func Misbehaviour(peerID p2p.ID, explanation string) PeerBehaviour {
+	return PeerBehaviour{peerID: peerID, reason: misbehaviour{explanation}}
+}
+
 type consensusVote struct {
 	explanation string
 }
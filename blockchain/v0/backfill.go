@@ -0,0 +1,185 @@
+package v0
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// time a peer has to send a block requested while backfilling
+	backfillRequestTimeout = 15 * time.Second
+)
+
+var errBackfillStopped = errors.New("blockchain reactor stopped")
+
+// backfillRequest is the block requested from a peer while backfilling.
+type backfillRequest struct {
+	height int64
+	peerID p2p.ID
+	// receives the block, or nil if the peer doesn't have it
+	blockCh chan *types.Block
+}
+
+// backfiller tracks the block requested while backfilling, so that the
+// responses are routed to it rather than to the pool.
+type backfiller struct {
+	mtx sync.Mutex
+	// the highest height backfilled, 0 if not backfilling
+	height  int64
+	request *backfillRequest
+}
+
+// Backfill fetches the blocks below the height the node restored a snapshot of
+// from the peers, down to retainHeight, so that the node can serve light
+// clients and evidence. Each block is verified against the trusted ID of the
+// block above it, starting from the given block ID and commit of that height,
+// as verified by state sync, and saved below the base of the store. It returns
+// once retainHeight is reached, or the reactor is stopped.
+//
+// NOTE: the validator sets of the heights backfilled are not restored.
+func (bcR *BlockchainReactor) Backfill(blockID types.BlockID, height int64, commit *types.Commit,
+	retainHeight int64) error {
+	if retainHeight < 1 {
+		retainHeight = 1
+	}
+	if retainHeight > height {
+		return nil
+	}
+
+	bcR.backfiller.mtx.Lock()
+	bcR.backfiller.height = height
+	bcR.backfiller.mtx.Unlock()
+	defer func() {
+		bcR.backfiller.mtx.Lock()
+		bcR.backfiller.height = 0
+		bcR.backfiller.mtx.Unlock()
+	}()
+
+	bcR.Logger.Info("Backfilling blocks", "height", height, "retainHeight", retainHeight)
+	trustedID, seenCommit := blockID, commit
+	for h := height; h >= retainHeight; h-- {
+		block, parts, err := bcR.backfillBlock(h, trustedID)
+		if err != nil {
+			return err
+		}
+		bcR.store.SaveBlockBelowBase(block, parts, seenCommit)
+		trustedID, seenCommit = block.LastBlockID, block.LastCommit
+	}
+	bcR.Logger.Info("Backfilled blocks", "base", bcR.store.Base(), "height", height)
+	return nil
+}
+
+// backfillBlock fetches the block of the given height from the peers having
+// it, one at a time, until one sends a block matching trustedID. The peers
+// sending another block are banned.
+func (bcR *BlockchainReactor) backfillBlock(height int64, trustedID types.BlockID) (
+	*types.Block, *types.PartSet, error) {
+	for {
+		peers := bcR.pool.peersWithBlock(height)
+		for _, peerID := range peers {
+			block, err := bcR.requestBackfillBlock(peerID, height)
+			if err != nil {
+				return nil, nil, err
+			}
+			if block == nil {
+				continue
+			}
+			parts, err := verifyBackfillBlock(block, trustedID)
+			if err != nil {
+				bcR.Logger.Error("Peer sent us an invalid block to backfill", "peer", peerID,
+					"height", height, "err", err)
+				bcR.pool.BanPeer(peerID)
+				if peer := bcR.Switch.Peers().Get(peerID); peer != nil {
+					bcR.Switch.BanPeerForError(peer, err)
+				}
+				continue
+			}
+			return block, parts, nil
+		}
+
+		if len(peers) == 0 {
+			bcR.Logger.Debug("No peers to backfill from", "height", height)
+			bcR.findPeers()
+		}
+		select {
+		case <-time.After(findPeersIntervalSeconds * time.Second):
+		case <-bcR.Quit():
+			return nil, nil, errBackfillStopped
+		}
+	}
+}
+
+// requestBackfillBlock requests the block of the given height from the peer,
+// returning nil if the peer doesn't have it or fails to send it in time.
+func (bcR *BlockchainReactor) requestBackfillBlock(peerID p2p.ID, height int64) (*types.Block, error) {
+	peer := bcR.Switch.Peers().Get(peerID)
+	if peer == nil {
+		return nil, nil
+	}
+
+	req := &backfillRequest{height: height, peerID: peerID, blockCh: make(chan *types.Block, 1)}
+	bcR.backfiller.mtx.Lock()
+	bcR.backfiller.request = req
+	bcR.backfiller.mtx.Unlock()
+	defer func() {
+		bcR.backfiller.mtx.Lock()
+		if bcR.backfiller.request == req {
+			bcR.backfiller.request = nil
+		}
+		bcR.backfiller.mtx.Unlock()
+	}()
+
+	msgBytes := cdc.MustMarshalBinaryBare(&bcBlockRequestMessage{Height: height})
+	if !peer.Send(BlockchainChannel, msgBytes) {
+		return nil, nil
+	}
+	select {
+	case block := <-req.blockCh:
+		return block, nil
+	case <-time.After(backfillRequestTimeout):
+		bcR.Logger.Info("Peer did not send the block to backfill in time", "peer", peerID, "height", height)
+		return nil, nil
+	case <-bcR.Quit():
+		return nil, errBackfillStopped
+	}
+}
+
+// receiveBackfillBlock passes the block received from the peer, or nil if the
+// peer doesn't have the block of the given height, to the pending backfill
+// request. It returns false if the height is not backfilled, the block being
+// then for fast sync.
+func (bcR *BlockchainReactor) receiveBackfillBlock(peerID p2p.ID, height int64, block *types.Block) bool {
+	bcR.backfiller.mtx.Lock()
+	defer bcR.backfiller.mtx.Unlock()
+
+	if height > bcR.backfiller.height {
+		return false
+	}
+	req := bcR.backfiller.request
+	if req == nil || req.peerID != peerID || req.height != height {
+		bcR.Logger.Debug("Peer sent us a block to backfill we didn't expect", "peer", peerID, "height", height)
+		return true
+	}
+	bcR.backfiller.request = nil
+	req.blockCh <- block
+	return true
+}
+
+// verifyBackfillBlock checks that the block is the one of the trusted ID,
+// returning its parts.
+func verifyBackfillBlock(block *types.Block, trustedID types.BlockID) (*types.PartSet, error) {
+	if err := block.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	parts := block.MakePartSet(types.BlockPartSizeBytes)
+	blockID := types.BlockID{Hash: block.Hash(), PartsHeader: parts.Header()}
+	if !blockID.Equals(trustedID) {
+		return nil, fmt.Errorf("expected block %v at height %d, got %v", trustedID, block.Height, blockID)
+	}
+	return parts, nil
+}
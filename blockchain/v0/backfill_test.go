@@ -0,0 +1,118 @@
+package v0
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/store"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestBackfill(t *testing.T) {
+	config = cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	genDoc, privVals := randGenesisDoc(1, false, 30)
+
+	maxBlockHeight := int64(20)
+	source := newBlockchainReactor(log.TestingLogger(), genDoc, privVals, maxBlockHeight)
+	defer source.app.Stop()
+	sourceStore := source.reactor.store
+
+	// a node which restored a snapshot of the last height, with no blocks
+	restoredStore := store.NewBlockStore(dbm.NewMemDB())
+	restored := NewBlockchainReactor(source.reactor.initialState.Copy(), nil, restoredStore, false)
+	restored.SetLogger(log.TestingLogger())
+	source.reactor.fastSync = false
+
+	reactors := []*BlockchainReactor{source.reactor, restored}
+	p2p.MakeConnectedSwitches(config.P2P, 2, func(i int, s *p2p.Switch) *p2p.Switch {
+		s.AddReactor("BLOCKCHAIN", reactors[i])
+		return s
+	}, p2p.Connect2Switches)
+	defer func() {
+		for _, r := range reactors {
+			r.Stop()
+		}
+	}()
+
+	// the blocks are requested from the peers whose status was received
+	require.Eventually(t, func() bool {
+		return len(restored.pool.peersWithBlock(maxBlockHeight)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	meta := sourceStore.LoadBlockMeta(maxBlockHeight)
+	commit := sourceStore.LoadSeenCommit(maxBlockHeight)
+	err := restored.Backfill(meta.BlockID, maxBlockHeight, commit, 10)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 10, restoredStore.Base())
+	assert.EqualValues(t, maxBlockHeight, restoredStore.Height())
+	for h := int64(10); h <= maxBlockHeight; h++ {
+		assert.Equal(t, sourceStore.LoadBlock(h).Hash(), restoredStore.LoadBlock(h).Hash())
+	}
+	// the seen commits are the ones of the blocks above
+	assert.Equal(t, commit, restoredStore.LoadSeenCommit(maxBlockHeight))
+	for h := int64(10); h < maxBlockHeight; h++ {
+		assert.Equal(t, sourceStore.LoadBlockCommit(h), restoredStore.LoadSeenCommit(h))
+	}
+	assert.Nil(t, restoredStore.LoadBlock(9))
+}
+
+func TestBackfillStops(t *testing.T) {
+	config = cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	genDoc, privVals := randGenesisDoc(1, false, 30)
+	pair := newBlockchainReactor(log.TestingLogger(), genDoc, privVals, 0)
+	defer pair.app.Stop()
+	p2p.MakeConnectedSwitches(config.P2P, 1, func(i int, s *p2p.Switch) *p2p.Switch {
+		s.AddReactor("BLOCKCHAIN", pair.reactor)
+		return s
+	}, p2p.Connect2Switches)
+
+	// no peer has the blocks: it waits until the reactor is stopped
+	done := make(chan error)
+	go func() {
+		done <- pair.reactor.Backfill(types.BlockID{}, 5, &types.Commit{}, 1)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	pair.reactor.Stop()
+	select {
+	case err := <-done:
+		assert.Equal(t, errBackfillStopped, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Backfill did not return")
+	}
+}
+
+func TestVerifyBackfillBlock(t *testing.T) {
+	config = cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	genDoc, privVals := randGenesisDoc(1, false, 30)
+	pair := newBlockchainReactor(log.TestingLogger(), genDoc, privVals, 3)
+	defer pair.app.Stop()
+	bs := pair.reactor.store
+
+	block := bs.LoadBlock(2)
+	trustedID := bs.LoadBlock(3).LastBlockID
+	parts, err := verifyBackfillBlock(block, trustedID)
+	require.NoError(t, err)
+	assert.Equal(t, trustedID.PartsHeader, parts.Header())
+
+	// another block
+	_, err = verifyBackfillBlock(bs.LoadBlock(1), trustedID)
+	assert.Error(t, err)
+
+	// the same header with other txs
+	block.Data.Txs = append(block.Data.Txs, types.Tx("forged"))
+	_, err = verifyBackfillBlock(block, trustedID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package v0
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/tendermint/tendermint/libs/metrics"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "fastsync"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Height of the last block synced.
+	Height metrics.Gauge
+	// Highest height reported by the peers.
+	TargetHeight metrics.Gauge
+	// Rate of blocks synced, in blocks per second.
+	BlockRate metrics.Gauge
+	// Number of blocks requested and not received yet.
+	PendingRequests metrics.Gauge
+	// Number of peers synced from.
+	NumPeers metrics.Gauge
+	// Rate at which a peer sends the requested blocks, in bytes per second.
+	PeerRecvRate metrics.Gauge
+	// Size of the blocks received and not synced yet.
+	BufferedBytes metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return PrometheusMetricsWithRegisterer(stdprometheus.DefaultRegisterer, namespace, labelsAndValues...)
+}
+
+// PrometheusMetricsWithRegisterer is like PrometheusMetrics, but registers the
+// metrics with the given registerer.
+func PrometheusMetricsWithRegisterer(registerer stdprometheus.Registerer, namespace string,
+	labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		Height: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "height",
+			Help:      "Height of the last block synced.",
+		}, labels).With(labelsAndValues...),
+		TargetHeight: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "target_height",
+			Help:      "Highest height reported by the peers.",
+		}, labels).With(labelsAndValues...),
+		BlockRate: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_rate",
+			Help:      "Rate of blocks synced, in blocks per second.",
+		}, labels).With(labelsAndValues...),
+		PendingRequests: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pending_requests",
+			Help:      "Number of blocks requested and not received yet.",
+		}, labels).With(labelsAndValues...),
+		NumPeers: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peers",
+			Help:      "Number of peers synced from.",
+		}, labels).With(labelsAndValues...),
+		PeerRecvRate: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_recv_rate",
+			Help:      "Rate at which a peer sends the requested blocks, in bytes per second.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		BufferedBytes: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "buffered_bytes",
+			Help:      "Size of the blocks received and not synced yet.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Height:          discard.NewGauge(),
+		TargetHeight:    discard.NewGauge(),
+		BlockRate:       discard.NewGauge(),
+		PendingRequests: discard.NewGauge(),
+		NumPeers:        discard.NewGauge(),
+		PeerRecvRate:    discard.NewGauge(),
+		BufferedBytes:   discard.NewGauge(),
+	}
+}
@@ -0,0 +1,72 @@
+package v0
+
+import (
+	"time"
+)
+
+const (
+	// Penalties of the events degrading the score of a peer.
+	penaltyTimeout      = 1.0              // no block in time, or sent too slowly
+	penaltyInvalidBlock = 2.0              // invalid or unexpected block
+	penaltyForgedCommit = peerBanThreshold // banned right away
+
+	// Penalty at which a peer is banned.
+	peerBanThreshold = 4.0
+	// Time it takes to forgive one point of penalty.
+	peerPenaltyDecay = time.Minute
+
+	// Duration of the first ban of a peer, doubled on each subsequent ban.
+	minPeerBanDuration = time.Minute
+	maxPeerBanDuration = 30 * time.Minute
+)
+
+// peerScore tracks the misbehavior of a peer, outliving the peer's removal
+// from the pool, so that a flaky peer which reconnects is deprioritized or,
+// after repeated offenses, temporarily banned.
+type peerScore struct {
+	penalty     float64
+	updatedAt   time.Time
+	bans        int
+	bannedUntil time.Time
+}
+
+// currentPenalty returns the penalty, decayed since it was last updated.
+func (s *peerScore) currentPenalty(now time.Time) float64 {
+	penalty := s.penalty - float64(now.Sub(s.updatedAt))/float64(peerPenaltyDecay)
+	if penalty < 0 {
+		return 0
+	}
+	return penalty
+}
+
+// penalize adds points to the penalty, and returns true if the peer is banned
+// as a result.
+func (s *peerScore) penalize(points float64, now time.Time) bool {
+	s.penalty = s.currentPenalty(now) + points
+	s.updatedAt = now
+	if s.penalty < peerBanThreshold {
+		return false
+	}
+
+	s.penalty = 0
+	s.bans++
+	d := minPeerBanDuration
+	for i := 1; i < s.bans && d < maxPeerBanDuration; i++ {
+		d *= 2
+	}
+	if d > maxPeerBanDuration {
+		d = maxPeerBanDuration
+	}
+	s.bannedUntil = now.Add(d)
+	return true
+}
+
+func (s *peerScore) isBanned(now time.Time) bool {
+	return now.Before(s.bannedUntil)
+}
+
+// forgettable returns true if the score no longer affects the peer, and won't
+// make its next ban longer.
+func (s *peerScore) forgettable(now time.Time) bool {
+	return s.currentPenalty(now) == 0 && now.Sub(s.bannedUntil) > maxPeerBanDuration
+}
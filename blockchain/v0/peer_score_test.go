@@ -0,0 +1,89 @@
+package v0
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/clock"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+func TestPeerScore(t *testing.T) {
+	now := time.Now()
+	s := &peerScore{}
+
+	assert.False(t, s.penalize(penaltyInvalidBlock, now))
+	assert.EqualValues(t, penaltyInvalidBlock, s.currentPenalty(now))
+	// the penalty decays
+	now = now.Add(peerPenaltyDecay)
+	assert.EqualValues(t, penaltyInvalidBlock-1, s.currentPenalty(now))
+	now = now.Add(10 * peerPenaltyDecay)
+	assert.EqualValues(t, 0, s.currentPenalty(now))
+
+	// repeated offenses get the peer banned, for longer each time
+	for i, d := range []time.Duration{minPeerBanDuration, 2 * minPeerBanDuration, 4 * minPeerBanDuration} {
+		assert.False(t, s.penalize(penaltyInvalidBlock, now))
+		require.True(t, s.penalize(penaltyInvalidBlock, now), "ban %d", i)
+		assert.True(t, s.isBanned(now))
+		assert.Equal(t, now.Add(d), s.bannedUntil)
+		assert.False(t, s.forgettable(now))
+		now = s.bannedUntil
+		assert.False(t, s.isBanned(now))
+	}
+
+	// up to the maximum duration
+	for i := 0; i < 10; i++ {
+		s.penalize(peerBanThreshold, now)
+	}
+	assert.Equal(t, now.Add(maxPeerBanDuration), s.bannedUntil)
+	assert.False(t, s.forgettable(s.bannedUntil))
+	assert.True(t, s.forgettable(s.bannedUntil.Add(maxPeerBanDuration+time.Second)))
+}
+
+func TestBlockPoolBanPeer(t *testing.T) {
+	pool := NewBlockPool(1, make(chan BlockRequest), make(chan peerError))
+	pool.SetLogger(log.TestingLogger())
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
+	pool.SetPeerRange("good", 1, 100)
+	pool.SetPeerRange("flaky", 1, 100)
+
+	// the penalized peer is picked last
+	pool.mtx.Lock()
+	pool.penalize("flaky", penaltyTimeout)
+	pool.mtx.Unlock()
+	for i := 0; i < 5; i++ {
+		peers := pool.pickIncrAvailablePeers(1)
+		require.Len(t, peers, 1)
+		assert.EqualValues(t, "good", peers[0].id)
+	}
+
+	// and is removed once banned, and can't be re-added until the ban expires
+	pool.mtx.Lock()
+	pool.penalize("flaky", penaltyInvalidBlock)
+	pool.penalize("flaky", penaltyInvalidBlock)
+	pool.mtx.Unlock()
+	assertPeers := func(ids ...p2p.ID) {
+		pool.mtx.Lock()
+		defer pool.mtx.Unlock()
+		assert.Len(t, pool.peers, len(ids))
+		for _, id := range ids {
+			assert.Contains(t, pool.peers, id)
+		}
+	}
+	assertPeers("good")
+	pool.SetPeerRange("flaky", 1, 100)
+	assertPeers("good")
+
+	fakeClock.Advance(minPeerBanDuration)
+	pool.SetPeerRange("flaky", 1, 100)
+	assertPeers("good", "flaky")
+
+	// a forged commit gets the peer banned right away
+	pool.BanPeer("good")
+	assertPeers("flaky")
+}
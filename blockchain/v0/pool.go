@@ -1,13 +1,15 @@
 package v0
 
 import (
-	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/tendermint/tendermint/libs/clock"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	flow "github.com/tendermint/tendermint/libs/flowrate"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/service"
@@ -32,7 +34,10 @@ const (
 	requestIntervalMS         = 2
 	maxTotalRequesters        = 600
 	maxPendingRequests        = maxTotalRequesters
-	maxPendingRequestsPerPeer = 20
+	maxPendingRequestsPerPeer = 20 // default, see SetMaxPendingRequestsPerPeer
+	parallelRequests          = 1  // default, see SetParallelRequests
+	maxBufferBytes            = 0  // default (no limit), see SetMaxBufferBytes
+	maxPeerRate               = 0  // default (no limit), see SetMaxPeerRate
 
 	// Minimum recv rate to ensure we're receiving blocks from a peer fast
 	// enough. If a peer is not sending us data at at least that rate, we
@@ -40,13 +45,21 @@ const (
 	//
 	// Assuming a DSL connection (not a good choice) 128 Kbps (upload) ~ 15 KB/s,
 	// sending data across atlantic ~ 7.5 KB/s.
-	minRecvRate = 7680
+	defaultMinRecvRate = 7680
+
+	// Parameters of the monitor measuring the recv rate of a peer.
+	defaultPeerSampleRate = time.Second
+	defaultPeerWindowSize = 40 * time.Second
 
 	// Maximum difference between current and new block's height.
 	maxDiffBetweenCurrentAndReceivedBlockHeight = 100
-)
 
-var peerTimeout = 15 * time.Second // not const so we can override with tests
+	// Time a peer has to send us a block we requested.
+	defaultPeerTimeout = 15 * time.Second
+
+	// Number of the last blocks synced the block rate is measured over.
+	blockRateWindow = 100
+)
 
 /*
 	Peers self report their heights when we join the block pool.
@@ -64,6 +77,14 @@ type BlockPool struct {
 	service.BaseService
 	startTime time.Time
 
+	clock       clock.Clock
+	peerTimeout time.Duration
+	// the recv rate below which a peer is disconnected, and the parameters
+	// of the monitor measuring it
+	minRecvRate    int64
+	peerSampleRate time.Duration
+	peerWindowSize time.Duration
+
 	mtx sync.Mutex
 	// block requests
 	requesters map[int64]*bpRequester
@@ -71,9 +92,29 @@ type BlockPool struct {
 	// peers
 	peers         map[p2p.ID]*bpPeer
 	maxPeerHeight int64 // the biggest reported height
+	// the biggest height reported, including by the peers removed since
+	highestPeerHeight int64
+	// when the pool was last left without peers, zero if it has some
+	noPeersSince time.Time
+	// maximum number of requests assigned to a single peer, guarded by mtx
+	maxPendingPerPeer int32
+	// number of peers each block is requested from, guarded by mtx
+	parallelRequests int
+	// the scores of the peers which misbehaved, including removed ones
+	scores map[p2p.ID]*peerScore
+	// when the last blocks were popped, to measure the block rate
+	popTimes []time.Time
+	// maximum size of the blocks received and not synced yet, 0 if
+	// unlimited, and the average size of the blocks, guarded by mtx
+	maxBufferBytes int64
+	avgBlockSize   int64
+	// maximum rate in bytes/s at which blocks are requested from a single
+	// peer, 0 if unlimited, guarded by mtx
+	maxPeerRate int64
 
 	// atomic
-	numPending int32 // number of requests pending assignment or block response
+	numPending    int32 // number of requests pending assignment or block response
+	bufferedBytes int64 // size of the blocks received and not synced yet
 
 	requestsCh chan<- BlockRequest
 	errorsCh   chan<- peerError
@@ -83,12 +124,24 @@ type BlockPool struct {
 // requests and errors will be sent to requestsCh and errorsCh accordingly.
 func NewBlockPool(start int64, requestsCh chan<- BlockRequest, errorsCh chan<- peerError) *BlockPool {
 	bp := &BlockPool{
-		peers: make(map[p2p.ID]*bpPeer),
+		peers:  make(map[p2p.ID]*bpPeer),
+		scores: make(map[p2p.ID]*peerScore),
 
 		requesters: make(map[int64]*bpRequester),
 		height:     start,
 		numPending: 0,
 
+		maxPendingPerPeer: maxPendingRequestsPerPeer,
+		parallelRequests:  parallelRequests,
+		maxBufferBytes:    maxBufferBytes,
+		maxPeerRate:       maxPeerRate,
+
+		clock:          clock.New(),
+		peerTimeout:    defaultPeerTimeout,
+		minRecvRate:    defaultMinRecvRate,
+		peerSampleRate: defaultPeerSampleRate,
+		peerWindowSize: defaultPeerWindowSize,
+
 		requestsCh: requestsCh,
 		errorsCh:   errorsCh,
 	}
@@ -96,11 +149,35 @@ func NewBlockPool(start int64, requestsCh chan<- BlockRequest, errorsCh chan<- p
 	return bp
 }
 
+// SetClock sets the clock measuring the peer timeouts and request latencies.
+// It must be called before the pool is started.
+func (pool *BlockPool) SetClock(c clock.Clock) {
+	pool.clock = c
+}
+
+// SetPeerParams sets the time a peer has to send a requested block, the
+// minimum rate (in bytes/s) at which it must send the blocks, and the sample
+// rate and window size of the monitor measuring it. A peer failing either is
+// disconnected. It must be called before the pool is started.
+func (pool *BlockPool) SetPeerParams(timeout time.Duration, minRecvRate int64,
+	sampleRate, windowSize time.Duration) {
+	pool.peerTimeout = timeout
+	pool.minRecvRate = minRecvRate
+	pool.peerSampleRate = sampleRate
+	pool.peerWindowSize = windowSize
+}
+
 // OnStart implements service.Service by spawning requesters routine and recording
 // pool's start time.
 func (pool *BlockPool) OnStart() error {
 	go pool.makeRequestersRoutine()
-	pool.startTime = time.Now()
+	pool.startTime = pool.clock.Now()
+
+	pool.mtx.Lock()
+	if len(pool.peers) == 0 {
+		pool.noPeersSince = pool.startTime
+	}
+	pool.mtx.Unlock()
 	return nil
 }
 
@@ -123,6 +200,10 @@ func (pool *BlockPool) makeRequestersRoutine() {
 			time.Sleep(requestIntervalMS * time.Millisecond)
 			// check for timed out peers
 			pool.removeTimedoutPeers()
+		case pool.bufferFull():
+			// wait for the blocks to be synced, freeing space.
+			time.Sleep(requestIntervalMS * time.Millisecond)
+			pool.removeTimedoutPeers()
 		default:
 			// request for more blocks.
 			pool.makeNextRequester()
@@ -138,13 +219,17 @@ func (pool *BlockPool) removeTimedoutPeers() {
 		if !peer.didTimeout && peer.numPending > 0 {
 			curRate := peer.recvMonitor.Status().CurRate
 			// curRate can be 0 on start
-			if curRate != 0 && curRate < minRecvRate {
-				err := errors.New("peer is not sending us data fast enough")
+			if curRate != 0 && curRate < pool.minRecvRate {
+				err := tmerrors.New(tmerrors.CodeTimeout, "peer is not sending us data fast enough")
+				pool.penalize(peer.id, penaltyTimeout)
 				pool.sendError(err, peer.id)
+				tail := peer.recvMonitor.TailStatus()
 				pool.Logger.Error("SendTimeout", "peer", peer.id,
 					"reason", err,
 					"curRate", fmt.Sprintf("%d KB/s", curRate/1024),
-					"minRate", fmt.Sprintf("%d KB/s", minRecvRate/1024))
+					"p10Rate", fmt.Sprintf("%d KB/s", tail.RateP10/1024),
+					"p90Latency", tail.LatencyP90,
+					"minRate", fmt.Sprintf("%d KB/s", pool.minRecvRate/1024))
 				peer.didTimeout = true
 			}
 		}
@@ -163,6 +248,25 @@ func (pool *BlockPool) GetStatus() (height int64, numPending int32, lenRequester
 	return pool.height, atomic.LoadInt32(&pool.numPending), len(pool.requesters)
 }
 
+// bufferFull returns true if the blocks received and not synced yet, and
+// those expected for the pending requests, would exceed maxBufferBytes. The
+// two blocks needed to sync the next block are always requested.
+func (pool *BlockPool) bufferFull() bool {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	if pool.maxBufferBytes <= 0 || len(pool.requesters) < 2 {
+		return false
+	}
+	// until a block is received, assume the blocks are as big as allowed
+	blockSize := pool.avgBlockSize
+	if blockSize == 0 {
+		blockSize = types.MaxBlockSizeBytes
+	}
+	expected := int64(atomic.LoadInt32(&pool.numPending)) * blockSize
+	return atomic.LoadInt64(&pool.bufferedBytes)+expected >= pool.maxBufferBytes
+}
+
 // IsCaughtUp returns true if this node is caught up, false - otherwise.
 // TODO: relax conditions, prevent abuse.
 func (pool *BlockPool) IsCaughtUp() bool {
@@ -180,12 +284,34 @@ func (pool *BlockPool) IsCaughtUp() bool {
 	// and that we're synced to the highest known height.
 	// Note we use maxPeerHeight - 1 because to sync block H requires block H+1
 	// to verify the LastCommit.
-	receivedBlockOrTimedOut := pool.height > 0 || time.Since(pool.startTime) > 5*time.Second
+	receivedBlockOrTimedOut := pool.height > 0 || pool.clock.Since(pool.startTime) > 5*time.Second
 	ourChainIsLongestAmongPeers := pool.maxPeerHeight == 0 || pool.height >= (pool.maxPeerHeight-1)
 	isCaughtUp := receivedBlockOrTimedOut && ourChainIsLongestAmongPeers
 	return isCaughtUp
 }
 
+// NoPeersFor returns for how long the pool has had no peers, 0 if it has some.
+func (pool *BlockPool) NoPeersFor() time.Duration {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	if pool.noPeersSince.IsZero() {
+		return 0
+	}
+	return pool.clock.Since(pool.noPeersSince)
+}
+
+// IsNearTip returns true if the pool is synced to within maxLag blocks of the
+// highest height reported by the peers, including the removed ones. It
+// returns false if no peer ever reported its height.
+func (pool *BlockPool) IsNearTip(maxLag int64) bool {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	// as in IsCaughtUp, syncing block H requires block H+1
+	return pool.highestPeerHeight > 0 && pool.height >= pool.highestPeerHeight-1-maxLag
+}
+
 // PeekTwoBlocks returns blocks at pool.height and pool.height+1.
 // We need to see the second block's Commit to validate the first block.
 // So we peek two blocks at a time.
@@ -216,8 +342,13 @@ func (pool *BlockPool) PopRequest() {
 		}
 		*/
 		r.Stop()
+		r.releaseBlock()
 		delete(pool.requesters, pool.height)
 		pool.height++
+		if len(pool.popTimes) == blockRateWindow {
+			pool.popTimes = pool.popTimes[1:]
+		}
+		pool.popTimes = append(pool.popTimes, pool.clock.Now())
 	} else {
 		panic(fmt.Sprintf("Expected requester to pop, got nothing at height %v", pool.height))
 	}
@@ -233,6 +364,7 @@ func (pool *BlockPool) RedoRequest(height int64) p2p.ID {
 	request := pool.requesters[height]
 	peerID := request.getPeerID()
 	if peerID != p2p.ID("") {
+		pool.penalize(peerID, penaltyInvalidBlock)
 		// RemovePeer will redo all requesters associated with this peer.
 		pool.removePeer(peerID)
 	}
@@ -260,23 +392,113 @@ func (pool *BlockPool) AddBlock(peerID p2p.ID, block *types.Block, blockSize int
 			diff *= -1
 		}
 		if diff > maxDiffBetweenCurrentAndReceivedBlockHeight {
-			pool.sendError(errors.New("peer sent us a block we didn't expect with a height too far ahead/behind"), peerID)
+			pool.penalize(peerID, penaltyInvalidBlock)
+			pool.sendError(tmerrors.New(tmerrors.CodePeerMisbehavior,
+				"peer sent us a block we didn't expect with a height too far ahead/behind"), peerID)
 		}
 		return
 	}
 
-	if requester.setBlock(block, peerID) {
+	if others, ok := requester.setBlock(block, peerID, blockSize); ok {
 		atomic.AddInt32(&pool.numPending, -1)
+		atomic.AddInt64(&pool.bufferedBytes, int64(blockSize))
+		if pool.avgBlockSize == 0 {
+			pool.avgBlockSize = int64(blockSize)
+		} else {
+			pool.avgBlockSize = (9*pool.avgBlockSize + int64(blockSize)) / 10
+		}
 		peer := pool.peers[peerID]
 		if peer != nil {
-			peer.decrPending(blockSize)
+			peer.decrPending(blockSize, requester.sinceRequest())
+		}
+		// The block was also requested from others, which lost the race: their
+		// requests no longer count against them.
+		for _, id := range others {
+			if peer := pool.peers[id]; peer != nil {
+				peer.cancelPending()
+			}
+		}
+	} else if requester.lostRace(peerID) {
+		// the block still took the peer's bandwidth
+		if peer := pool.peers[peerID]; peer != nil {
+			peer.spendRateBudget(blockSize, pool.clock.Now())
 		}
+		pool.Logger.Debug("peer sent us a block we already got from another peer",
+			"peer", peerID, "blockHeight", block.Height)
 	} else {
 		pool.Logger.Info("invalid peer", "peer", peerID, "blockHeight", block.Height)
-		pool.sendError(errors.New("invalid peer"), peerID)
+		pool.penalize(peerID, penaltyInvalidBlock)
+		pool.sendError(tmerrors.New(tmerrors.CodePeerMisbehavior, "invalid peer"), peerID)
 	}
 }
 
+// SyncProgress is the progress of fast sync.
+type SyncProgress struct {
+	// Height of the last block synced.
+	Height int64 `json:"height"`
+	// Highest height reported by the peers.
+	TargetHeight int64 `json:"target_height"`
+	// Rate of blocks synced over the last blocks, in blocks per second.
+	BlockRate float64 `json:"block_rate"`
+	// Number of blocks requested and not received yet, and of the blocks
+	// requested or received and not synced yet.
+	PendingRequests int32          `json:"pending_requests"`
+	Requesters      int            `json:"requesters"`
+	Peers           []PeerProgress `json:"peers"`
+	// Size of the blocks received and not synced yet.
+	BufferedBytes int64 `json:"buffered_bytes"`
+}
+
+// PeerProgress is the progress of fast sync with a peer.
+type PeerProgress struct {
+	ID              p2p.ID `json:"id"`
+	Height          int64  `json:"height"`
+	PendingRequests int32  `json:"pending_requests"`
+	// Rate at which the peer sends the requested blocks, in bytes per
+	// second, or 0 if no block is pending.
+	RecvRate int64 `json:"recv_rate"`
+	// Penalty of the peer for timeouts and invalid blocks, preferred
+	// peers having the lowest.
+	Penalty float64 `json:"penalty"`
+}
+
+// Progress returns the progress of fast sync, the peers sorted by ID.
+func (pool *BlockPool) Progress() SyncProgress {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	now := pool.clock.Now()
+	progress := SyncProgress{
+		Height:          pool.height - 1,
+		TargetHeight:    pool.maxPeerHeight,
+		PendingRequests: atomic.LoadInt32(&pool.numPending),
+		Requesters:      len(pool.requesters),
+		Peers:           make([]PeerProgress, 0, len(pool.peers)),
+		BufferedBytes:   atomic.LoadInt64(&pool.bufferedBytes),
+	}
+	if n := len(pool.popTimes); n > 1 {
+		if elapsed := pool.popTimes[n-1].Sub(pool.popTimes[0]); elapsed > 0 {
+			progress.BlockRate = float64(n-1) / elapsed.Seconds()
+		}
+	}
+	for _, peer := range pool.peers {
+		pp := PeerProgress{
+			ID:              peer.id,
+			Height:          peer.height,
+			PendingRequests: peer.numPending,
+			Penalty:         pool.penalty(peer.id, now),
+		}
+		if peer.numPending > 0 && peer.recvMonitor != nil {
+			pp.RecvRate = peer.recvMonitor.Status().CurRate
+		}
+		progress.Peers = append(progress.Peers, pp)
+	}
+	sort.Slice(progress.Peers, func(i, j int) bool {
+		return progress.Peers[i].ID < progress.Peers[j].ID
+	})
+	return progress
+}
+
 // MaxPeerHeight returns the highest reported height.
 func (pool *BlockPool) MaxPeerHeight() int64 {
 	pool.mtx.Lock()
@@ -284,23 +506,57 @@ func (pool *BlockPool) MaxPeerHeight() int64 {
 	return pool.maxPeerHeight
 }
 
-// SetPeerHeight sets the peer's alleged blockchain height.
-func (pool *BlockPool) SetPeerHeight(peerID p2p.ID, height int64) {
+// peersWithBlock returns the peers having the block of the given height, the
+// ones with the lowest penalties first. The pool needn't be running.
+func (pool *BlockPool) peersWithBlock(height int64) []p2p.ID {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
+	now := pool.clock.Now()
+	var peers []*bpPeer
+	for _, peer := range pool.peers {
+		if !peer.didTimeout && peer.base <= height && height <= peer.height {
+			peers = append(peers, peer)
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return pool.penalty(peers[i].id, now) < pool.penalty(peers[j].id, now)
+	})
+	ids := make([]p2p.ID, len(peers))
+	for i, peer := range peers {
+		ids[i] = peer.id
+	}
+	return ids
+}
+
+// SetPeerRange sets the peer's alleged blockchain base and height, the range
+// of the blocks it can send.
+func (pool *BlockPool) SetPeerRange(peerID p2p.ID, base int64, height int64) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	if score := pool.scores[peerID]; score != nil && score.isBanned(pool.clock.Now()) {
+		pool.Logger.Debug("Ignoring banned peer", "peer", peerID, "until", score.bannedUntil)
+		return
+	}
+
 	peer := pool.peers[peerID]
 	if peer != nil {
+		peer.base = base
 		peer.height = height
 	} else {
-		peer = newBPPeer(pool, peerID, height)
+		peer = newBPPeer(pool, peerID, base, height)
 		peer.setLogger(pool.Logger.With("peer", peerID))
 		pool.peers[peerID] = peer
+		pool.noPeersSince = time.Time{}
 	}
 
 	if height > pool.maxPeerHeight {
 		pool.maxPeerHeight = height
 	}
+	if height > pool.highestPeerHeight {
+		pool.highestPeerHeight = height
+	}
 }
 
 // RemovePeer removes the peer with peerID from the pool. If there's no peer
@@ -314,7 +570,7 @@ func (pool *BlockPool) RemovePeer(peerID p2p.ID) {
 
 func (pool *BlockPool) removePeer(peerID p2p.ID) {
 	for _, requester := range pool.requesters {
-		if requester.getPeerID() == peerID {
+		if requester.removePeer(peerID) {
 			requester.redo(peerID)
 		}
 	}
@@ -326,6 +582,9 @@ func (pool *BlockPool) removePeer(peerID p2p.ID) {
 		}
 
 		delete(pool.peers, peerID)
+		if len(pool.peers) == 0 {
+			pool.noPeersSince = pool.clock.Now()
+		}
 
 		// Find a new peer with the biggest height and update maxPeerHeight if the
 		// peer's height was the biggest.
@@ -335,6 +594,44 @@ func (pool *BlockPool) removePeer(peerID p2p.ID) {
 	}
 }
 
+// BanPeer bans the peer right away, e.g. for sending a forged commit, and
+// removes it from the pool.
+func (pool *BlockPool) BanPeer(peerID p2p.ID) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	pool.penalize(peerID, penaltyForgedCommit)
+}
+
+// penalize degrades the score of the peer, which is removed if it gets
+// banned. It must be called with mtx held.
+func (pool *BlockPool) penalize(peerID p2p.ID, points float64) {
+	now := pool.clock.Now()
+	score := pool.scores[peerID]
+	if score == nil {
+		for id, s := range pool.scores {
+			if s.forgettable(now) {
+				delete(pool.scores, id)
+			}
+		}
+		score = &peerScore{}
+		pool.scores[peerID] = score
+	}
+	if score.penalize(points, now) {
+		pool.Logger.Info("Banning peer", "peer", peerID, "until", score.bannedUntil, "bans", score.bans)
+		pool.removePeer(peerID)
+	}
+}
+
+// penalty returns the current penalty of the peer. It must be called with mtx
+// held.
+func (pool *BlockPool) penalty(peerID p2p.ID, now time.Time) float64 {
+	if score := pool.scores[peerID]; score != nil {
+		return score.currentPenalty(now)
+	}
+	return 0
+}
+
 // If no peers are left, maxPeerHeight is set to 0.
 func (pool *BlockPool) updateMaxPeerHeight() {
 	var max int64
@@ -346,27 +643,97 @@ func (pool *BlockPool) updateMaxPeerHeight() {
 	pool.maxPeerHeight = max
 }
 
-// Pick an available peer with at least the given minHeight.
-// If no peers are available, returns nil.
-func (pool *BlockPool) pickIncrAvailablePeer(minHeight int64) *bpPeer {
+// SetMaxPendingRequestsPerPeer sets the maximum number of blocks requested
+// from a single peer at a time. It can be changed while the pool is running and
+// applies to new requests.
+func (pool *BlockPool) SetMaxPendingRequestsPerPeer(max int) {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
+	pool.maxPendingPerPeer = int32(max)
+}
+
+// SetMaxBufferBytes sets the maximum size of the blocks received and not
+// synced yet, 0 meaning no limit. Once reached, no new blocks are requested
+// until the blocks are synced, e.g. if the application is slow to execute
+// them. It can be changed while the pool is running.
+func (pool *BlockPool) SetMaxBufferBytes(max int64) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	pool.maxBufferBytes = max
+}
+
+// SetMaxPeerRate sets the maximum rate in bytes/s at which blocks are
+// requested from a single peer, 0 meaning no limit, so that a peer serving
+// many nodes isn't saturated by this one. The blocks received from a peer are
+// taken from a budget refilled at that rate, up to a second of it, and no new
+// block is requested from the peer while the budget is exhausted. It can be
+// changed while the pool is running.
+func (pool *BlockPool) SetMaxPeerRate(rate int64) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	pool.maxPeerRate = rate
+}
+
+// SetParallelRequests sets the number of peers each block is requested from.
+// The first peer to send the block wins, so that a slow peer doesn't stall the
+// sync, at the cost of downloading each block up to n times. It can be changed
+// while the pool is running and applies to new requests.
+func (pool *BlockPool) SetParallelRequests(n int) {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	pool.parallelRequests = n
+}
+
+// Pick up to parallelRequests available peers having the block at the given
+// minHeight and above, preferring the peers with the lowest penalties. If no peers are
+// available, returns nil.
+func (pool *BlockPool) pickIncrAvailablePeers(minHeight int64) []*bpPeer {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	now := pool.clock.Now()
+	var available []*bpPeer
 	for _, peer := range pool.peers {
 		if peer.didTimeout {
 			pool.removePeer(peer.id)
 			continue
 		}
-		if peer.numPending >= maxPendingRequestsPerPeer {
+		if peer.numPending >= pool.maxPendingPerPeer {
+			continue
+		}
+		if peer.height < minHeight || peer.base > minHeight {
 			continue
 		}
-		if peer.height < minHeight {
+		if !peer.withinRateLimit(now) {
 			continue
 		}
+		available = append(available, peer)
+	}
+
+	// the peers which can only send the lowest blocks are picked first, and
+	// the others in a fixed order, so that consecutive blocks are requested
+	// from the same peer, in a single range request
+	sort.Slice(available, func(i, j int) bool {
+		pi, pj := pool.penalty(available[i].id, now), pool.penalty(available[j].id, now)
+		if pi != pj {
+			return pi < pj
+		}
+		if available[i].height != available[j].height {
+			return available[i].height < available[j].height
+		}
+		return available[i].id < available[j].id
+	})
+	if len(available) > pool.parallelRequests {
+		available = available[:pool.parallelRequests]
+	}
+	for _, peer := range available {
 		peer.incrPending()
-		return peer
 	}
-	return nil
+	return available
 }
 
 func (pool *BlockPool) makeNextRequester() {
@@ -431,20 +798,27 @@ func (pool *BlockPool) debug() string {
 type bpPeer struct {
 	didTimeout  bool
 	numPending  int32
+	base        int64
 	height      int64
 	pool        *BlockPool
 	id          p2p.ID
 	recvMonitor *flow.Monitor
 
-	timeout *time.Timer
+	// bytes the peer can still send before hitting pool.maxPeerRate, negative
+	// if it sent more, and when it was last refilled
+	rateBudget        float64
+	rateBudgetUpdated time.Time
+
+	timeout clock.Timer
 
 	logger log.Logger
 }
 
-func newBPPeer(pool *BlockPool, peerID p2p.ID, height int64) *bpPeer {
+func newBPPeer(pool *BlockPool, peerID p2p.ID, base int64, height int64) *bpPeer {
 	peer := &bpPeer{
 		pool:       pool,
 		id:         peerID,
+		base:       base,
 		height:     height,
 		numPending: 0,
 		logger:     log.NewNopLogger(),
@@ -457,17 +831,52 @@ func (peer *bpPeer) setLogger(l log.Logger) {
 }
 
 func (peer *bpPeer) resetMonitor() {
-	peer.recvMonitor = flow.New(time.Second, time.Second*40)
-	initialValue := float64(minRecvRate) * math.E
+	peer.recvMonitor = flow.New(peer.pool.peerSampleRate, peer.pool.peerWindowSize)
+	initialValue := float64(peer.pool.minRecvRate) * math.E
 	peer.recvMonitor.SetREMA(initialValue)
 }
 
 func (peer *bpPeer) resetTimeout() {
 	if peer.timeout == nil {
-		peer.timeout = time.AfterFunc(peerTimeout, peer.onTimeout)
+		peer.timeout = peer.pool.clock.AfterFunc(peer.pool.peerTimeout, peer.onTimeout)
+	} else {
+		peer.timeout.Reset(peer.pool.peerTimeout)
+	}
+}
+
+// withinRateLimit returns true if blocks can be requested from the peer
+// without exceeding pool.maxPeerRate.
+func (peer *bpPeer) withinRateLimit(now time.Time) bool {
+	if peer.pool.maxPeerRate <= 0 {
+		return true
+	}
+	peer.refillRateBudget(now)
+	return peer.rateBudget > 0
+}
+
+// spendRateBudget takes the size of a block received from the peer from its
+// rate budget.
+func (peer *bpPeer) spendRateBudget(recvSize int, now time.Time) {
+	if peer.pool.maxPeerRate <= 0 {
+		return
+	}
+	peer.refillRateBudget(now)
+	peer.rateBudget -= float64(recvSize)
+}
+
+// refillRateBudget adds pool.maxPeerRate bytes per second elapsed since the
+// last refill to the rate budget, up to a second of it.
+func (peer *bpPeer) refillRateBudget(now time.Time) {
+	rate := float64(peer.pool.maxPeerRate)
+	if peer.rateBudgetUpdated.IsZero() {
+		peer.rateBudget = rate
 	} else {
-		peer.timeout.Reset(peerTimeout)
+		peer.rateBudget += rate * now.Sub(peer.rateBudgetUpdated).Seconds()
 	}
+	if peer.rateBudget > rate {
+		peer.rateBudget = rate
+	}
+	peer.rateBudgetUpdated = now
 }
 
 func (peer *bpPeer) incrPending() {
@@ -478,7 +887,11 @@ func (peer *bpPeer) incrPending() {
 	peer.numPending++
 }
 
-func (peer *bpPeer) decrPending(recvSize int) {
+// decrPending records the receipt of a block of recvSize bytes, requested
+// latency ago.
+func (peer *bpPeer) decrPending(recvSize int, latency time.Duration) {
+	peer.recvMonitor.RecordLatency(latency)
+	peer.spendRateBudget(recvSize, peer.pool.clock.Now())
 	peer.numPending--
 	if peer.numPending == 0 {
 		peer.timeout.Stop()
@@ -488,13 +901,23 @@ func (peer *bpPeer) decrPending(recvSize int) {
 	}
 }
 
+// cancelPending releases a request whose block was received from another
+// peer.
+func (peer *bpPeer) cancelPending() {
+	peer.numPending--
+	if peer.numPending == 0 {
+		peer.timeout.Stop()
+	}
+}
+
 func (peer *bpPeer) onTimeout() {
 	peer.pool.mtx.Lock()
 	defer peer.pool.mtx.Unlock()
 
-	err := errors.New("peer did not send us anything")
+	err := tmerrors.New(tmerrors.CodeTimeout, "peer did not send us anything")
+	peer.pool.penalize(peer.id, penaltyTimeout)
 	peer.pool.sendError(err, peer.id)
-	peer.logger.Error("SendTimeout", "reason", err, "timeout", peerTimeout)
+	peer.logger.Error("SendTimeout", "reason", err, "timeout", peer.pool.peerTimeout)
 	peer.didTimeout = true
 }
 
@@ -505,11 +928,17 @@ type bpRequester struct {
 	pool       *BlockPool
 	height     int64
 	gotBlockCh chan struct{}
-	redoCh     chan p2p.ID //redo may send multitime, add peerId to identify repeat
+	redoCh     chan p2p.ID // signalled once removePeer requires a redo
 
 	mtx    sync.Mutex
-	peerID p2p.ID
-	block  *types.Block
+	peerID p2p.ID // the peer which sent the block, or the first one requested
+	// the peers the block is requested from, and those whose request was
+	// cancelled because another peer sent the block first
+	peers       []p2p.ID
+	lost        []p2p.ID
+	requestedAt time.Time // when the block was requested from peers
+	block       *types.Block
+	blockSize   int // counted in pool.bufferedBytes
 }
 
 func newBPRequester(pool *BlockPool, height int64) *bpRequester {
@@ -531,21 +960,49 @@ func (bpr *bpRequester) OnStart() error {
 	return nil
 }
 
-// Returns true if the peer matches and block doesn't already exist.
-func (bpr *bpRequester) setBlock(block *types.Block, peerID p2p.ID) bool {
+// Returns true if the block was requested from the peer and doesn't already
+// exist, along with the other peers the block was requested from.
+func (bpr *bpRequester) setBlock(block *types.Block, peerID p2p.ID, blockSize int) ([]p2p.ID, bool) {
 	bpr.mtx.Lock()
-	if bpr.block != nil || bpr.peerID != peerID {
+	if bpr.block != nil || !containsID(bpr.peers, peerID) {
 		bpr.mtx.Unlock()
-		return false
+		return nil, false
 	}
+	others := removeID(bpr.peers, peerID)
 	bpr.block = block
+	bpr.blockSize = blockSize
+	bpr.peerID = peerID
+	bpr.peers = []p2p.ID{peerID}
+	bpr.lost = append(bpr.lost, others...)
 	bpr.mtx.Unlock()
 
 	select {
 	case bpr.gotBlockCh <- struct{}{}:
 	default:
 	}
-	return true
+	return others, true
+}
+
+// lostRace returns true if the block was requested from the peer, but
+// another peer sent it first.
+func (bpr *bpRequester) lostRace(peerID p2p.ID) bool {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+	return containsID(bpr.lost, peerID)
+}
+
+// removePeer forgets the request sent to the peer, if any. It returns true if
+// the request must be redone: the block was sent by the peer, or no other
+// peer is left to send it.
+func (bpr *bpRequester) removePeer(peerID p2p.ID) bool {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+
+	if !containsID(bpr.peers, peerID) {
+		return false
+	}
+	bpr.peers = removeID(bpr.peers, peerID)
+	return bpr.block != nil || len(bpr.peers) == 0
 }
 
 func (bpr *bpRequester) getBlock() *types.Block {
@@ -560,6 +1017,13 @@ func (bpr *bpRequester) getPeerID() p2p.ID {
 	return bpr.peerID
 }
 
+// sinceRequest returns the time since the block was requested.
+func (bpr *bpRequester) sinceRequest() time.Duration {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+	return bpr.pool.clock.Since(bpr.requestedAt)
+}
+
 // This is called from the requestRoutine, upon redo().
 func (bpr *bpRequester) reset() {
 	bpr.mtx.Lock()
@@ -567,10 +1031,24 @@ func (bpr *bpRequester) reset() {
 
 	if bpr.block != nil {
 		atomic.AddInt32(&bpr.pool.numPending, 1)
+		atomic.AddInt64(&bpr.pool.bufferedBytes, -int64(bpr.blockSize))
 	}
 
 	bpr.peerID = ""
+	bpr.peers = nil
+	bpr.lost = nil
 	bpr.block = nil
+	bpr.blockSize = 0
+}
+
+// releaseBlock removes the size of the block, once synced, from
+// pool.bufferedBytes.
+func (bpr *bpRequester) releaseBlock() {
+	bpr.mtx.Lock()
+	defer bpr.mtx.Unlock()
+
+	atomic.AddInt64(&bpr.pool.bufferedBytes, -int64(bpr.blockSize))
+	bpr.blockSize = 0
 }
 
 // Tells bpRequester to pick another peer and try again.
@@ -588,15 +1066,15 @@ func (bpr *bpRequester) redo(peerID p2p.ID) {
 func (bpr *bpRequester) requestRoutine() {
 OUTER_LOOP:
 	for {
-		// Pick a peer to send request to.
-		var peer *bpPeer
+		// Pick the peers to send request to.
+		var peers []*bpPeer
 	PICK_PEER_LOOP:
 		for {
 			if !bpr.IsRunning() || !bpr.pool.IsRunning() {
 				return
 			}
-			peer = bpr.pool.pickIncrAvailablePeer(bpr.height)
-			if peer == nil {
+			peers = bpr.pool.pickIncrAvailablePeers(bpr.height)
+			if len(peers) == 0 {
 				//log.Info("No peers available", "height", height)
 				time.Sleep(requestIntervalMS * time.Millisecond)
 				continue PICK_PEER_LOOP
@@ -604,11 +1082,17 @@ OUTER_LOOP:
 			break PICK_PEER_LOOP
 		}
 		bpr.mtx.Lock()
-		bpr.peerID = peer.id
+		bpr.peerID = peers[0].id
+		for _, peer := range peers {
+			bpr.peers = append(bpr.peers, peer.id)
+		}
+		bpr.requestedAt = bpr.pool.clock.Now()
 		bpr.mtx.Unlock()
 
-		// Send request and wait.
-		bpr.pool.sendRequest(bpr.height, peer.id)
+		// Send requests and wait for the first block.
+		for _, peer := range peers {
+			bpr.pool.sendRequest(bpr.height, peer.id)
+		}
 	WAIT_LOOP:
 		for {
 			select {
@@ -617,13 +1101,11 @@ OUTER_LOOP:
 				return
 			case <-bpr.Quit():
 				return
-			case peerID := <-bpr.redoCh:
-				if peerID == bpr.peerID {
-					bpr.reset()
-					continue OUTER_LOOP
-				} else {
-					continue WAIT_LOOP
-				}
+			case <-bpr.redoCh:
+				// The request was already removed from the peer by
+				// removePeer, which redoes it only if no other peer is left.
+				bpr.reset()
+				continue OUTER_LOOP
 			case <-bpr.gotBlockCh:
 				// We got a block!
 				// Continue the for-loop and wait til Quit.
@@ -633,6 +1115,26 @@ OUTER_LOOP:
 	}
 }
 
+func containsID(ids []p2p.ID, id p2p.ID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+// removeID returns a copy of ids without id.
+func removeID(ids []p2p.ID, id p2p.ID) []p2p.ID {
+	res := make([]p2p.ID, 0, len(ids))
+	for _, x := range ids {
+		if x != id {
+			res = append(res, x)
+		}
+	}
+	return res
+}
+
 // BlockRequest stores a block request identified by the block Height and the PeerID responsible for
 // delivering the block
 type BlockRequest struct {
@@ -8,16 +8,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/libs/clock"
 	"github.com/tendermint/tendermint/libs/log"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/types"
 )
 
-func init() {
-	peerTimeout = 2 * time.Second
-}
-
 type testPeer struct {
 	id        p2p.ID
 	height    int64
@@ -93,7 +90,7 @@ func TestBlockPoolBasic(t *testing.T) {
 	// Introduce each peer.
 	go func() {
 		for _, peer := range peers {
-			pool.SetPeerHeight(peer.id, peer.height)
+			pool.SetPeerRange(peer.id, 1, peer.height)
 		}
 	}()
 
@@ -135,6 +132,8 @@ func TestBlockPoolTimeout(t *testing.T) {
 	requestsCh := make(chan BlockRequest, 1000)
 	pool := NewBlockPool(start, requestsCh, errorsCh)
 	pool.SetLogger(log.TestingLogger())
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
 	err := pool.Start()
 	if err != nil {
 		t.Error(err)
@@ -148,45 +147,68 @@ func TestBlockPoolTimeout(t *testing.T) {
 	// Introduce each peer.
 	go func() {
 		for _, peer := range peers {
-			pool.SetPeerHeight(peer.id, peer.height)
+			pool.SetPeerRange(peer.id, 1, peer.height)
 		}
 	}()
 
-	// Start a goroutine to pull blocks
+	// Pull requests, which are never answered
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
 		for {
-			if !pool.IsRunning() {
+			select {
+			case <-requestsCh:
+			case <-done:
 				return
 			}
-			first, second := pool.PeekTwoBlocks()
-			if first != nil && second != nil {
-				pool.PopRequest()
-			} else {
-				time.Sleep(1 * time.Second)
-			}
 		}
 	}()
 
-	// Pull from channels
-	counter := 0
+	// Once each peer has been sent a request, it has a timeout pending.
+	fakeClock.BlockUntil(len(peers))
+	fakeClock.Advance(pool.peerTimeout - time.Millisecond)
+	select {
+	case err := <-errorsCh:
+		t.Fatalf("peer timed out early: %v", err)
+	default:
+	}
+	fakeClock.Advance(time.Millisecond)
+
 	timedOut := map[p2p.ID]struct{}{}
-	for {
-		select {
-		case err := <-errorsCh:
-			t.Log(err)
-			// consider error to be always timeout here
-			if _, ok := timedOut[err.peerID]; !ok {
-				counter++
-				if counter == len(peers) {
-					return // Done!
-				}
-			}
-		case request := <-requestsCh:
-			t.Logf("Pulled new BlockRequest %+v", request)
-		}
+	for len(timedOut) < len(peers) {
+		err := <-errorsCh
+		t.Log(err)
+		// consider error to be always timeout here
+		timedOut[err.peerID] = struct{}{}
 	}
 }
 
+func TestBlockPoolPeerParams(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	errorsCh := make(chan peerError, 10)
+	pool := NewBlockPool(1, requestsCh, errorsCh)
+	pool.SetLogger(log.TestingLogger())
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
+	pool.SetPeerParams(time.Minute, 1024, 2*time.Second, time.Minute)
+	pool.SetPeerRange("1", 1, 1)
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+	<-requestsCh
+
+	// the default timeout doesn't apply
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(defaultPeerTimeout)
+	select {
+	case err := <-errorsCh:
+		t.Fatalf("peer timed out early: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	fakeClock.Advance(time.Minute - defaultPeerTimeout)
+	err := <-errorsCh
+	assert.EqualValues(t, "1", err.peerID)
+}
+
 func TestBlockPoolRemovePeer(t *testing.T) {
 	peers := make(testPeers, 10)
 	for i := 0; i < 10; i++ {
@@ -205,7 +227,7 @@ func TestBlockPoolRemovePeer(t *testing.T) {
 
 	// add peers
 	for peerID, peer := range peers {
-		pool.SetPeerHeight(peerID, peer.height)
+		pool.SetPeerRange(peerID, 1, peer.height)
 	}
 	assert.EqualValues(t, 10, pool.MaxPeerHeight())
 
@@ -223,3 +245,347 @@ func TestBlockPoolRemovePeer(t *testing.T) {
 
 	assert.EqualValues(t, 0, pool.MaxPeerHeight())
 }
+
+func TestBlockPoolMaxPendingRequestsPerPeer(t *testing.T) {
+	pool := NewBlockPool(1, make(chan BlockRequest), make(chan peerError))
+	pool.SetLogger(log.TestingLogger())
+	pool.SetPeerRange("1", 1, 100)
+
+	pool.SetMaxPendingRequestsPerPeer(3)
+	for i := 0; i < 3; i++ {
+		require.Len(t, pool.pickIncrAvailablePeers(1), 1)
+	}
+	assert.Empty(t, pool.pickIncrAvailablePeers(1))
+
+	// raising the limit applies to the next request
+	pool.SetMaxPendingRequestsPerPeer(4)
+	assert.Len(t, pool.pickIncrAvailablePeers(1), 1)
+	assert.Empty(t, pool.pickIncrAvailablePeers(1))
+}
+
+func TestBlockPoolParallelRequests(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	errorsCh := make(chan peerError, 10)
+	pool := NewBlockPool(1, requestsCh, errorsCh)
+	pool.SetLogger(log.TestingLogger())
+	pool.SetParallelRequests(2)
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
+	// only request block 1
+	for _, id := range []p2p.ID{"fast", "slow"} {
+		pool.SetPeerRange(id, 1, 1)
+	}
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	// block 1 is requested from both peers
+	requested := map[p2p.ID]bool{}
+	for i := 0; i < 2; i++ {
+		request := <-requestsCh
+		assert.EqualValues(t, 1, request.Height)
+		requested[request.PeerID] = true
+	}
+	assert.Equal(t, map[p2p.ID]bool{"fast": true, "slow": true}, requested)
+
+	// the first block wins, and the slow peer's request is cancelled
+	block := &types.Block{Header: types.Header{Height: 1}}
+	pool.AddBlock("fast", block, 123)
+	first, _ := pool.PeekTwoBlocks()
+	assert.Equal(t, block, first)
+	pool.mtx.Lock()
+	assert.EqualValues(t, 0, pool.peers["slow"].numPending)
+	pool.mtx.Unlock()
+
+	// the late block of the slow peer is ignored, and the slow peer doesn't
+	// time out
+	pool.AddBlock("slow", &types.Block{Header: types.Header{Height: 1}}, 123)
+	first, _ = pool.PeekTwoBlocks()
+	assert.Equal(t, block, first)
+	fakeClock.Advance(pool.peerTimeout)
+	select {
+	case err := <-errorsCh:
+		t.Fatalf("unexpected error: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBlockPoolParallelRequestsRemovePeer(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	pool := NewBlockPool(1, requestsCh, make(chan peerError, 10))
+	pool.SetLogger(log.TestingLogger())
+	pool.SetParallelRequests(2)
+	for _, id := range []p2p.ID{"1", "2"} {
+		pool.SetPeerRange(id, 1, 1)
+	}
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+	<-requestsCh
+	<-requestsCh
+
+	// the request isn't redone while another peer can send the block
+	pool.RemovePeer("1")
+	select {
+	case request := <-requestsCh:
+		t.Fatalf("unexpected request %v", request)
+	case <-time.After(50 * time.Millisecond):
+	}
+	pool.AddBlock("2", &types.Block{Header: types.Header{Height: 1}}, 123)
+	first, _ := pool.PeekTwoBlocks()
+	assert.NotNil(t, first)
+
+	// the block is requested again once its sender is removed
+	pool.SetPeerRange("3", 1, 1)
+	pool.RemovePeer("2")
+	request := <-requestsCh
+	assert.Equal(t, BlockRequest{1, "3"}, request)
+	first, _ = pool.PeekTwoBlocks()
+	assert.Nil(t, first)
+}
+
+func TestBlockPoolParallelRequestsRedoAfterLostRace(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	errorsCh := make(chan peerError, 10)
+	pool := NewBlockPool(1, requestsCh, errorsCh)
+	pool.SetLogger(log.TestingLogger())
+	pool.SetParallelRequests(2)
+	for _, id := range []p2p.ID{"fast", "slow"} {
+		pool.SetPeerRange(id, 1, 1)
+	}
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+	<-requestsCh
+	<-requestsCh
+
+	pool.AddBlock("fast", &types.Block{Header: types.Header{Height: 1}}, 123)
+	pool.mtx.Lock()
+	requester := pool.requesters[1]
+	pool.mtx.Unlock()
+	require.True(t, requester.lostRace("slow"))
+
+	// removing the sender redoes the request, from the peer which lost the
+	// race, which is no longer considered to have lost it
+	pool.RemovePeer("fast")
+	request := <-requestsCh
+	assert.Equal(t, BlockRequest{1, "slow"}, request)
+	assert.False(t, requester.lostRace("slow"))
+
+	block := &types.Block{Header: types.Header{Height: 1}}
+	pool.AddBlock("slow", block, 123)
+	first, _ := pool.PeekTwoBlocks()
+	assert.Equal(t, block, first)
+	pool.mtx.Lock()
+	assert.EqualValues(t, 0, pool.peers["slow"].numPending)
+	pool.mtx.Unlock()
+	select {
+	case err := <-errorsCh:
+		t.Fatalf("unexpected error: %v", err)
+	default:
+	}
+}
+
+func TestBPRequesterRemovePeer(t *testing.T) {
+	pool := NewBlockPool(1, make(chan BlockRequest), make(chan peerError))
+	bpr := newBPRequester(pool, 1)
+	bpr.peers = []p2p.ID{"a", "b", "c"}
+
+	// no redo while other peers may still send the block
+	assert.False(t, bpr.removePeer("a"))
+	assert.False(t, bpr.removePeer("unknown"))
+	assert.False(t, bpr.removePeer("a"))
+	assert.False(t, bpr.removePeer("b"))
+	assert.Equal(t, []p2p.ID{"c"}, bpr.peers)
+	assert.True(t, bpr.removePeer("c"))
+
+	// the sender of the block is removed while another peer is pending
+	bpr.reset()
+	bpr.peers = []p2p.ID{"a", "b"}
+	others, ok := bpr.setBlock(&types.Block{Header: types.Header{Height: 1}}, "b", 123)
+	require.True(t, ok)
+	assert.Equal(t, []p2p.ID{"a"}, others)
+	assert.False(t, bpr.removePeer("a"))
+	assert.True(t, bpr.removePeer("b"))
+
+	bpr.reset()
+	assert.Nil(t, bpr.getBlock())
+	assert.False(t, bpr.lostRace("a"))
+}
+
+func TestBlockPoolPeerBase(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	pool := NewBlockPool(5, requestsCh, make(chan peerError, 10))
+	pool.SetLogger(log.TestingLogger())
+	// the first peer's blocks start above the pool's height, e.g. after state sync
+	pool.SetPeerRange("1", 6, 10)
+	pool.SetPeerRange("2", 1, 5)
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	for i := 0; i < 6; i++ {
+		request := <-requestsCh
+		if request.Height == 5 {
+			assert.EqualValues(t, "2", request.PeerID)
+		} else {
+			assert.EqualValues(t, "1", request.PeerID)
+		}
+	}
+}
+
+func TestBlockPoolProgress(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 10)
+	pool := NewBlockPool(1, requestsCh, make(chan peerError, 10))
+	pool.SetLogger(log.TestingLogger())
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
+	pool.SetPeerRange("2", 1, 5)
+	pool.SetPeerRange("1", 1, 3)
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+	for i := 0; i < 5; i++ {
+		<-requestsCh
+	}
+
+	progress := pool.Progress()
+	assert.EqualValues(t, 0, progress.Height)
+	assert.EqualValues(t, 5, progress.TargetHeight)
+	assert.EqualValues(t, 5, progress.PendingRequests)
+	assert.Equal(t, 5, progress.Requesters)
+	assert.Zero(t, progress.BlockRate)
+	require.Len(t, progress.Peers, 2)
+	assert.EqualValues(t, "1", progress.Peers[0].ID)
+	assert.EqualValues(t, 3, progress.Peers[0].Height)
+	assert.EqualValues(t, 5, progress.Peers[0].PendingRequests+progress.Peers[1].PendingRequests)
+	for _, peer := range progress.Peers {
+		// the rate is only measured once the monitor took a sample
+		assert.Zero(t, peer.RecvRate)
+	}
+
+	// 3 blocks synced in 2 seconds
+	for h := int64(1); h <= 3; h++ {
+		pool.mtx.Lock()
+		peerID := pool.requesters[h].getPeerID()
+		pool.mtx.Unlock()
+		pool.AddBlock(peerID, &types.Block{Header: types.Header{Height: h}}, 123)
+		pool.PopRequest()
+		if h < 3 {
+			fakeClock.Advance(time.Second)
+		}
+	}
+	progress = pool.Progress()
+	assert.EqualValues(t, 3, progress.Height)
+	assert.EqualValues(t, 2, progress.PendingRequests)
+	assert.Equal(t, 1.0, progress.BlockRate)
+}
+
+func TestBlockPoolMaxBufferBytes(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 100)
+	pool := NewBlockPool(1, requestsCh, make(chan peerError, 10))
+	pool.SetLogger(log.TestingLogger())
+	pool.SetMaxBufferBytes(1000)
+	pool.SetMaxPendingRequestsPerPeer(100)
+	pool.SetPeerRange("1", 1, 100)
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	assertRequesters := func(n int) {
+		time.Sleep(50 * time.Millisecond)
+		_, _, lenRequesters := pool.GetStatus()
+		assert.Equal(t, n, lenRequesters)
+	}
+	addBlocks := func(n int) {
+		for i := 0; i < n; i++ {
+			request := <-requestsCh
+			pool.AddBlock(request.PeerID, &types.Block{Header: types.Header{Height: request.Height}}, 100)
+		}
+	}
+
+	// the size of the blocks is unknown, only the first two are requested
+	assertRequesters(2)
+	// the blocks received, and those requested, fill the buffer
+	addBlocks(2)
+	assertRequesters(10)
+	assert.EqualValues(t, 200, pool.Progress().BufferedBytes)
+
+	// syncing blocks frees space
+	pool.PopRequest()
+	pool.PopRequest()
+	assert.EqualValues(t, 0, pool.Progress().BufferedBytes)
+	assertRequesters(10)
+	addBlocks(10)
+	assertRequesters(10)
+	assert.EqualValues(t, 1000, pool.Progress().BufferedBytes)
+
+	// up to a higher limit
+	pool.SetMaxBufferBytes(1500)
+	assertRequesters(15)
+}
+
+func TestBlockPoolMaxPeerRate(t *testing.T) {
+	requestsCh := make(chan BlockRequest, 100)
+	pool := NewBlockPool(1, requestsCh, make(chan peerError, 10))
+	pool.SetLogger(log.TestingLogger())
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
+	pool.SetMaxPeerRate(1000)
+	pool.SetMaxPendingRequestsPerPeer(2)
+	pool.SetPeerRange("1", 1, 100)
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	assertRequests := func(n int) []BlockRequest {
+		requests := make([]BlockRequest, n)
+		for i := range requests {
+			select {
+			case requests[i] = <-requestsCh:
+			case <-time.After(time.Second):
+				t.Fatalf("expected %d requests, got %d", n, i)
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		require.Empty(t, requestsCh)
+		return requests
+	}
+	addBlocks := func(requests []BlockRequest) {
+		for _, request := range requests {
+			pool.AddBlock(request.PeerID, &types.Block{Header: types.Header{Height: request.Height}}, 1000)
+		}
+	}
+
+	// the blocks received exceed the budget of the peer
+	addBlocks(assertRequests(2))
+	assertRequests(0)
+	fakeClock.Advance(500 * time.Millisecond)
+	assertRequests(0)
+
+	// until it's refilled
+	fakeClock.Advance(600 * time.Millisecond)
+	addBlocks(assertRequests(2))
+	assertRequests(0)
+
+	// no limit
+	pool.SetMaxPeerRate(0)
+	assertRequests(2)
+}
+
+func TestBlockPoolNoPeers(t *testing.T) {
+	pool := NewBlockPool(1, make(chan BlockRequest, 10), make(chan peerError, 10))
+	pool.SetLogger(log.TestingLogger())
+	fakeClock := clock.NewFake(time.Now())
+	pool.SetClock(fakeClock)
+	require.NoError(t, pool.Start())
+	defer pool.Stop()
+
+	// the tip is unknown until a peer reports its height
+	fakeClock.Advance(time.Minute)
+	assert.Equal(t, time.Minute, pool.NoPeersFor())
+	assert.False(t, pool.IsNearTip(100))
+
+	pool.SetPeerRange("1", 1, 10)
+	assert.Zero(t, pool.NoPeersFor())
+	pool.RemovePeer("1")
+	fakeClock.Advance(time.Second)
+	assert.Equal(t, time.Second, pool.NoPeersFor())
+
+	// the height of the removed peer is remembered
+	assert.False(t, pool.IsNearTip(7))
+	assert.True(t, pool.IsNearTip(8))
+}
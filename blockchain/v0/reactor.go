@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 	"time"
 
 	amino "github.com/tendermint/go-amino"
 
 	"github.com/tendermint/tendermint/libs/log"
+	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/p2p"
 	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/store"
@@ -18,6 +21,13 @@ import (
 const (
 	// BlockchainChannel is a channel for blocks and status updates (`BlockStore` height)
 	BlockchainChannel = byte(0x40)
+	// BlockchainRangeChannel is a channel for requests of ranges of blocks,
+	// sent back on BlockchainChannel. The peers not advertising it are sent a
+	// request per block.
+	BlockchainRangeChannel = byte(0x41)
+
+	// maximum number of blocks of a range request
+	maxBlockRangeCount = 50
 
 	trySyncIntervalMS = 10
 
@@ -29,6 +39,13 @@ const (
 	statusUpdateIntervalSeconds = 10
 	// check if we should switch to consensus reactor
 	switchToConsensusIntervalSeconds = 1
+	// look for new peers every 5s while the pool has none
+	findPeersIntervalSeconds = 5
+
+	// switch to consensus after 1m without peers, if within 10 blocks of the
+	// highest height seen
+	defaultNoPeersTimeout = time.Minute
+	defaultNoPeersMaxLag  = 10
 
 	// NOTE: keep up to date with bcBlockResponseMessage
 	bcBlockResponseMessagePrefixSize   = 4
@@ -38,12 +55,21 @@ const (
 		bcBlockResponseMessageFieldKeySize
 )
 
+// commitTrustLevel is the part of the trusted voting power which must have
+// signed the commits of the blocks received, as lite2.DefaultTrustLevel.
+var commitTrustLevel = tmmath.Fraction{Numerator: 1, Denominator: 3}
+
 type consensusReactor interface {
 	// for when we switch from blockchain reactor and fast sync to
 	// the consensus machine
 	SwitchToConsensus(sm.State, uint64)
 }
 
+type pexReactor interface {
+	// to dial new peers when the pool has none
+	EnsurePeers()
+}
+
 type peerError struct {
 	err    error
 	peerID p2p.ID
@@ -67,13 +93,36 @@ type BlockchainReactor struct {
 
 	requestsCh <-chan BlockRequest
 	errorsCh   <-chan peerError
+
+	metrics *Metrics
+	// the peers whose recv rate was last reported, to reset it once removed
+	metricsPeers map[p2p.ID]struct{}
+
+	// fast sync switches to consensus once the pool has had no peers for
+	// noPeersTimeout, if within noPeersMaxLag blocks of the highest height
+	noPeersTimeout time.Duration
+	noPeersMaxLag  int64
+
+	// verify the commits of the blocks received, before they are synced
+	verifyCommits bool
+	// the validators of the last block synced, trusted to verify the commits
+	trustedValsMtx sync.RWMutex
+	trustedVals    *types.ValidatorSet
+
+	// the block requested while backfilling, see Backfill
+	backfiller backfiller
 }
 
 // NewBlockchainReactor returns new reactor instance.
 func NewBlockchainReactor(state sm.State, blockExec *sm.BlockExecutor, store *store.BlockStore,
 	fastSync bool) *BlockchainReactor {
 
-	if state.LastBlockHeight != store.Height() {
+	// the store is empty after state sync, until the first block is synced
+	storeHeight := store.Height()
+	if storeHeight == 0 {
+		storeHeight = state.LastBlockHeight
+	}
+	if state.LastBlockHeight != storeHeight {
 		panic(fmt.Sprintf("state (%v) and store (%v) height mismatch", state.LastBlockHeight,
 			store.Height()))
 	}
@@ -84,20 +133,25 @@ func NewBlockchainReactor(state sm.State, blockExec *sm.BlockExecutor, store *st
 	errorsCh := make(chan peerError, capacity) // so we don't block in #Receive#pool.AddBlock
 
 	pool := NewBlockPool(
-		store.Height()+1,
+		storeHeight+1,
 		requestsCh,
 		errorsCh,
 	)
 
 	bcR := &BlockchainReactor{
-		initialState: state,
-		blockExec:    blockExec,
-		store:        store,
-		pool:         pool,
-		fastSync:     fastSync,
-		requestsCh:   requestsCh,
-		errorsCh:     errorsCh,
+		initialState:   state,
+		blockExec:      blockExec,
+		store:          store,
+		pool:           pool,
+		fastSync:       fastSync,
+		requestsCh:     requestsCh,
+		errorsCh:       errorsCh,
+		metrics:        NopMetrics(),
+		metricsPeers:   make(map[p2p.ID]struct{}),
+		noPeersTimeout: defaultNoPeersTimeout,
+		noPeersMaxLag:  defaultNoPeersMaxLag,
 	}
+	bcR.setTrustedValidators(state.Validators)
 	bcR.BaseReactor = *p2p.NewBaseReactor("BlockchainReactor", bcR)
 	return bcR
 }
@@ -108,6 +162,87 @@ func (bcR *BlockchainReactor) SetLogger(l log.Logger) {
 	bcR.pool.Logger = l
 }
 
+// SetMaxPendingRequestsPerPeer sets the maximum number of blocks requested
+// from a single peer at a time while fast syncing.
+func (bcR *BlockchainReactor) SetMaxPendingRequestsPerPeer(max int) {
+	bcR.pool.SetMaxPendingRequestsPerPeer(max)
+}
+
+// SetMaxBufferBytes sets the maximum size of the blocks received and not
+// synced yet while fast syncing, 0 meaning no limit.
+func (bcR *BlockchainReactor) SetMaxBufferBytes(max int64) {
+	bcR.pool.SetMaxBufferBytes(max)
+}
+
+// SetMaxPeerRate sets the maximum rate in bytes/s at which blocks are
+// requested from a single peer while fast syncing, 0 meaning no limit.
+func (bcR *BlockchainReactor) SetMaxPeerRate(rate int64) {
+	bcR.pool.SetMaxPeerRate(rate)
+}
+
+// SetParallelRequests sets the number of peers each block is requested from
+// while fast syncing, the first response winning.
+func (bcR *BlockchainReactor) SetParallelRequests(n int) {
+	bcR.pool.SetParallelRequests(n)
+}
+
+// SetPeerParams sets the time a peer has to send a requested block, the
+// minimum rate at which it must send the blocks, and the sample rate and
+// window size of the monitor measuring it. It must be called before the
+// reactor is started.
+func (bcR *BlockchainReactor) SetPeerParams(timeout time.Duration, minRecvRate int64,
+	sampleRate, windowSize time.Duration) {
+	bcR.pool.SetPeerParams(timeout, minRecvRate, sampleRate, windowSize)
+}
+
+// SwitchToFastSync is called by the state sync reactor when switching to fast
+// sync, once the app has been restored at the height of the given state.
+func (bcR *BlockchainReactor) SwitchToFastSync(state sm.State) error {
+	bcR.fastSync = true
+	bcR.initialState = state
+	bcR.setTrustedValidators(state.Validators)
+
+	bcR.pool.height = state.LastBlockHeight + 1
+	if err := bcR.pool.Start(); err != nil {
+		return err
+	}
+	go bcR.poolRoutine()
+	return nil
+}
+
+// SetMetrics sets the metrics of fast sync. It must be called before the
+// reactor is started.
+func (bcR *BlockchainReactor) SetMetrics(metrics *Metrics) {
+	bcR.metrics = metrics
+}
+
+// SetNoPeersFallback sets after how long without peers fast sync switches to
+// consensus, 0 meaning never, provided the node is within maxLag blocks of the
+// highest height the peers reported. Otherwise, new peers are looked for. It
+// must be called before the reactor is started.
+func (bcR *BlockchainReactor) SetNoPeersFallback(timeout time.Duration, maxLag int64) {
+	bcR.noPeersTimeout = timeout
+	bcR.noPeersMaxLag = maxLag
+}
+
+// SetVerifyCommits sets whether the commits of the blocks are verified as they
+// are received, with the light client rules, rather than once they are synced.
+// The peers sending forged commits are then banned right away, without the
+// blocks before having to be synced first. It must be called before the
+// reactor is started.
+func (bcR *BlockchainReactor) SetVerifyCommits(verify bool) {
+	bcR.verifyCommits = verify
+}
+
+// SyncProgress returns the progress of fast sync, and false if the reactor is
+// not fast syncing.
+func (bcR *BlockchainReactor) SyncProgress() (SyncProgress, bool) {
+	if !bcR.pool.IsRunning() {
+		return SyncProgress{}, false
+	}
+	return bcR.pool.Progress(), true
+}
+
 // OnStart implements service.Service.
 func (bcR *BlockchainReactor) OnStart() error {
 	if bcR.fastSync {
@@ -135,17 +270,27 @@ func (bcR *BlockchainReactor) GetChannels() []*p2p.ChannelDescriptor {
 			RecvBufferCapacity:  50 * 4096,
 			RecvMessageCapacity: maxMsgSize,
 		},
+		{
+			ID:                  BlockchainRangeChannel,
+			Priority:            5,
+			SendQueueCapacity:   100,
+			RecvBufferCapacity:  1024,
+			RecvMessageCapacity: 1024,
+		},
 	}
 }
 
 // AddPeer implements Reactor by sending our state to peer.
 func (bcR *BlockchainReactor) AddPeer(peer p2p.Peer) {
-	msgBytes := cdc.MustMarshalBinaryBare(&bcStatusResponseMessage{bcR.store.Height()})
+	msgBytes := cdc.MustMarshalBinaryBare(&bcStatusResponseMessage{
+		Base:   bcR.store.Base(),
+		Height: bcR.store.Height(),
+	})
 	peer.Send(BlockchainChannel, msgBytes)
 	// it's OK if send fails. will try later in poolRoutine
 
 	// peer is added to the pool once we receive the first
-	// bcStatusResponseMessage from the peer and call pool.SetPeerHeight
+	// bcStatusResponseMessage from the peer and call pool.SetPeerRange
 }
 
 // RemovePeer implements Reactor by removing peer from the pool.
@@ -172,7 +317,27 @@ func (bcR *BlockchainReactor) respondToPeer(msg *bcBlockRequestMessage,
 	return src.TrySend(BlockchainChannel, msgBytes)
 }
 
-// Receive implements Reactor by handling 4 types of messages (look below).
+// respondToRangeRequest sends the blocks of the range to the requesting peer,
+// in order, stopping at the first block we don't have, for which we respond
+// saying we don't have it.
+func (bcR *BlockchainReactor) respondToRangeRequest(msg *bcBlockRangeRequestMessage, src p2p.Peer) {
+	for i := int64(0); i < msg.Count; i++ {
+		height := msg.Height + i
+		block := bcR.store.LoadBlock(height)
+		if block == nil {
+			bcR.Logger.Info("Peer asking for a block we don't have", "src", src, "height", height)
+			msgBytes := cdc.MustMarshalBinaryBare(&bcNoBlockResponseMessage{Height: height})
+			src.TrySend(BlockchainChannel, msgBytes)
+			return
+		}
+		msgBytes := cdc.MustMarshalBinaryBare(&bcBlockResponseMessage{Block: block})
+		if !src.TrySend(BlockchainChannel, msgBytes) {
+			return
+		}
+	}
+}
+
+// Receive implements Reactor by handling 6 types of messages (look below).
 func (bcR *BlockchainReactor) Receive(chID byte, src p2p.Peer, msgBytes []byte) {
 	msg, err := decodeMsg(msgBytes)
 	if err != nil {
@@ -192,15 +357,34 @@ func (bcR *BlockchainReactor) Receive(chID byte, src p2p.Peer, msgBytes []byte)
 	switch msg := msg.(type) {
 	case *bcBlockRequestMessage:
 		bcR.respondToPeer(msg, src)
+	case *bcBlockRangeRequestMessage:
+		bcR.respondToRangeRequest(msg, src)
 	case *bcBlockResponseMessage:
+		if bcR.receiveBackfillBlock(src.ID(), msg.Block.Height, msg.Block) {
+			return
+		}
+		if err := bcR.verifyLastCommit(msg.Block); err != nil {
+			bcR.Logger.Error("Peer sent us a block with an invalid commit", "peer", src,
+				"height", msg.Block.Height, "err", err)
+			bcR.pool.BanPeer(src.ID())
+			bcR.Switch.BanPeerForError(src, err)
+			return
+		}
 		bcR.pool.AddBlock(src.ID(), msg.Block, len(msgBytes))
+	case *bcNoBlockResponseMessage:
+		if !bcR.receiveBackfillBlock(src.ID(), msg.Height, nil) {
+			bcR.Logger.Debug("Peer does not have requested block", "peer", src, "height", msg.Height)
+		}
 	case *bcStatusRequestMessage:
 		// Send peer our state.
-		msgBytes := cdc.MustMarshalBinaryBare(&bcStatusResponseMessage{bcR.store.Height()})
+		msgBytes := cdc.MustMarshalBinaryBare(&bcStatusResponseMessage{
+			Base:   bcR.store.Base(),
+			Height: bcR.store.Height(),
+		})
 		src.TrySend(BlockchainChannel, msgBytes)
 	case *bcStatusResponseMessage:
 		// Got a peer status. Unverified.
-		bcR.pool.SetPeerHeight(src.ID(), msg.Height)
+		bcR.pool.SetPeerRange(src.ID(), msg.Base, msg.Height)
 	default:
 		bcR.Logger.Error(fmt.Sprintf("Unknown message type %v", reflect.TypeOf(msg)))
 	}
@@ -221,6 +405,7 @@ func (bcR *BlockchainReactor) poolRoutine() {
 
 	lastHundred := time.Now()
 	lastRate := 0.0
+	lastFindPeers := time.Time{}
 
 	didProcessCh := make(chan struct{}, 1)
 
@@ -232,15 +417,7 @@ func (bcR *BlockchainReactor) poolRoutine() {
 			case <-bcR.pool.Quit():
 				return
 			case request := <-bcR.requestsCh:
-				peer := bcR.Switch.Peers().Get(request.PeerID)
-				if peer == nil {
-					continue
-				}
-				msgBytes := cdc.MustMarshalBinaryBare(&bcBlockRequestMessage{request.Height})
-				queued := peer.TrySend(BlockchainChannel, msgBytes)
-				if !queued {
-					bcR.Logger.Debug("Send queue is full, drop block request", "peer", peer.ID(), "height", request.Height)
-				}
+				bcR.sendRequests(bcR.drainRequests(request))
 			case err := <-bcR.errorsCh:
 				peer := bcR.Switch.Peers().Get(err.peerID)
 				if peer != nil {
@@ -249,7 +426,7 @@ func (bcR *BlockchainReactor) poolRoutine() {
 
 			case <-statusUpdateTicker.C:
 				// ask for status updates
-				go bcR.BroadcastStatusRequest() // nolint: errcheck
+				bcR.BroadcastStatusRequest() // nolint: errcheck
 
 			}
 		}
@@ -259,23 +436,32 @@ FOR_LOOP:
 	for {
 		select {
 		case <-switchToConsensusTicker.C:
+			bcR.updateMetrics()
 			height, numPending, lenRequesters := bcR.pool.GetStatus()
 			outbound, inbound, _ := bcR.Switch.NumPeers()
 			bcR.Logger.Debug("Consensus ticker", "numPending", numPending, "total", lenRequesters,
 				"outbound", outbound, "inbound", inbound)
 			if bcR.pool.IsCaughtUp() {
 				bcR.Logger.Info("Time to switch to consensus reactor!", "height", height)
-				bcR.pool.Stop()
-				conR, ok := bcR.Switch.Reactor("CONSENSUS").(consensusReactor)
-				if ok {
-					conR.SwitchToConsensus(state, blocksSynced)
-				}
-				// else {
-				// should only happen during testing
-				// }
+				bcR.switchToConsensus(state, blocksSynced)
+				break FOR_LOOP
+			}
 
+			noPeersFor := bcR.pool.NoPeersFor()
+			if bcR.noPeersTimeout <= 0 || noPeersFor < bcR.noPeersTimeout {
+				continue FOR_LOOP
+			}
+			if bcR.pool.IsNearTip(bcR.noPeersMaxLag) {
+				bcR.Logger.Info("No peers to fast sync from, switching to consensus reactor near the tip",
+					"height", height, "noPeersFor", noPeersFor)
+				bcR.switchToConsensus(state, blocksSynced)
 				break FOR_LOOP
 			}
+			if time.Since(lastFindPeers) >= findPeersIntervalSeconds*time.Second {
+				bcR.Logger.Info("No peers to fast sync from, looking for new ones", "noPeersFor", noPeersFor)
+				bcR.findPeers()
+				lastFindPeers = time.Now()
+			}
 
 		case <-trySyncTicker.C: // chan time
 			select {
@@ -319,14 +505,14 @@ FOR_LOOP:
 				if peer != nil {
 					// NOTE: we've already removed the peer's request, but we
 					// still need to clean up the rest.
-					bcR.Switch.StopPeerForError(peer, fmt.Errorf("blockchainReactor validation error: %v", err))
+					bcR.Switch.BanPeerForError(peer, fmt.Errorf("blockchainReactor validation error: %v", err))
 				}
 				peerID2 := bcR.pool.RedoRequest(second.Height)
 				peer2 := bcR.Switch.Peers().Get(peerID2)
 				if peer2 != nil && peer2 != peer {
 					// NOTE: we've already removed the peer's request, but we
 					// still need to clean up the rest.
-					bcR.Switch.StopPeerForError(peer2, fmt.Errorf("blockchainReactor validation error: %v", err))
+					bcR.Switch.BanPeerForError(peer2, fmt.Errorf("blockchainReactor validation error: %v", err))
 				}
 				continue FOR_LOOP
 			} else {
@@ -344,6 +530,9 @@ FOR_LOOP:
 					panic(fmt.Sprintf("Failed to process committed block (%d:%X): %v", first.Height, first.Hash(), err))
 				}
 				blocksSynced++
+				if bcR.verifyCommits {
+					bcR.setTrustedValidators(state.Validators)
+				}
 
 				if blocksSynced%100 == 0 {
 					lastRate = 0.9*lastRate + 0.1*(100/time.Since(lastHundred).Seconds())
@@ -360,6 +549,155 @@ FOR_LOOP:
 	}
 }
 
+func (bcR *BlockchainReactor) updateMetrics() {
+	progress := bcR.pool.Progress()
+	bcR.metrics.Height.Set(float64(progress.Height))
+	bcR.metrics.TargetHeight.Set(float64(progress.TargetHeight))
+	bcR.metrics.BlockRate.Set(progress.BlockRate)
+	bcR.metrics.PendingRequests.Set(float64(progress.PendingRequests))
+	bcR.metrics.NumPeers.Set(float64(len(progress.Peers)))
+	bcR.metrics.BufferedBytes.Set(float64(progress.BufferedBytes))
+
+	peers := make(map[p2p.ID]struct{}, len(progress.Peers))
+	for _, peer := range progress.Peers {
+		bcR.metrics.PeerRecvRate.With("peer_id", string(peer.ID)).Set(float64(peer.RecvRate))
+		peers[peer.ID] = struct{}{}
+	}
+	for id := range bcR.metricsPeers {
+		if _, ok := peers[id]; !ok {
+			bcR.metrics.PeerRecvRate.With("peer_id", string(id)).Set(0)
+		}
+	}
+	bcR.metricsPeers = peers
+}
+
+// drainRequests returns the request and those queued after it, up to
+// maxPendingRequests.
+func (bcR *BlockchainReactor) drainRequests(request BlockRequest) []BlockRequest {
+	requests := []BlockRequest{request}
+	for len(requests) < maxPendingRequests {
+		select {
+		case request := <-bcR.requestsCh:
+			requests = append(requests, request)
+		default:
+			return requests
+		}
+	}
+	return requests
+}
+
+// sendRequests sends the block requests to the peers, those of consecutive
+// heights to the same peer as range requests if the peer supports them.
+func (bcR *BlockchainReactor) sendRequests(requests []BlockRequest) {
+	for _, requests := range groupRequests(requests) {
+		bcR.sendRange(requests)
+	}
+}
+
+// groupRequests sorts the requests by peer and height, and splits them into
+// groups of up to maxBlockRangeCount consecutive heights requested from the
+// same peer.
+func groupRequests(requests []BlockRequest) [][]BlockRequest {
+	sort.Slice(requests, func(i, j int) bool {
+		if requests[i].PeerID != requests[j].PeerID {
+			return requests[i].PeerID < requests[j].PeerID
+		}
+		return requests[i].Height < requests[j].Height
+	})
+
+	var groups [][]BlockRequest
+	for start := 0; start < len(requests); {
+		end := start + 1
+		for end < len(requests) && end-start < maxBlockRangeCount &&
+			requests[end].PeerID == requests[start].PeerID &&
+			requests[end].Height == requests[end-1].Height+1 {
+			end++
+		}
+		groups = append(groups, requests[start:end])
+		start = end
+	}
+	return groups
+}
+
+// sendRange requests the consecutive blocks from the same peer, in a single
+// message if there are several and the peer supports range requests.
+func (bcR *BlockchainReactor) sendRange(requests []BlockRequest) {
+	peer := bcR.Switch.Peers().Get(requests[0].PeerID)
+	if peer == nil {
+		return
+	}
+	if len(requests) > 1 {
+		msgBytes := cdc.MustMarshalBinaryBare(&bcBlockRangeRequestMessage{
+			Height: requests[0].Height,
+			Count:  int64(len(requests)),
+		})
+		if peer.TrySend(BlockchainRangeChannel, msgBytes) {
+			return
+		}
+	}
+	for _, request := range requests {
+		msgBytes := cdc.MustMarshalBinaryBare(&bcBlockRequestMessage{request.Height})
+		queued := peer.TrySend(BlockchainChannel, msgBytes)
+		if !queued {
+			bcR.Logger.Debug("Send queue is full, drop block request", "peer", peer.ID(), "height", request.Height)
+		}
+	}
+}
+
+// verifyLastCommit verifies the commit of the block preceding the block, if
+// enabled, with the light client rules: a third of the trusted voting power
+// must have signed it. If the validators changed too much since the last block
+// synced for the commit to be verified, it's only verified once the preceding
+// block is synced.
+func (bcR *BlockchainReactor) verifyLastCommit(block *types.Block) error {
+	if !bcR.verifyCommits || block.Height <= 1 {
+		return nil
+	}
+
+	bcR.trustedValsMtx.RLock()
+	vals := bcR.trustedVals
+	bcR.trustedValsMtx.RUnlock()
+
+	err := vals.VerifyCommitTrusting(bcR.initialState.ChainID, block.LastBlockID,
+		block.Height-1, block.LastCommit, commitTrustLevel)
+	if types.IsErrNotEnoughVotingPowerSigned(err) {
+		return nil
+	}
+	return err
+}
+
+// setTrustedValidators sets the validators verifying the commits received. The
+// set is copied, with its total voting power computed, so that it's only read
+// from then on.
+func (bcR *BlockchainReactor) setTrustedValidators(vals *types.ValidatorSet) {
+	vals = vals.Copy()
+	vals.TotalVotingPower()
+
+	bcR.trustedValsMtx.Lock()
+	bcR.trustedVals = vals
+	bcR.trustedValsMtx.Unlock()
+}
+
+func (bcR *BlockchainReactor) switchToConsensus(state sm.State, blocksSynced uint64) {
+	bcR.pool.Stop()
+	conR, ok := bcR.Switch.Reactor("CONSENSUS").(consensusReactor)
+	if ok {
+		conR.SwitchToConsensus(state, blocksSynced)
+	}
+	// else {
+	// should only happen during testing
+	// }
+}
+
+// findPeers asks the PEX reactor, if any, to dial new peers now, and the
+// connected peers for their status, none of them being in the pool.
+func (bcR *BlockchainReactor) findPeers() {
+	if pexR, ok := bcR.Switch.Reactor("PEX").(pexReactor); ok {
+		pexR.EnsurePeers()
+	}
+	bcR.BroadcastStatusRequest() // nolint: errcheck
+}
+
 // BroadcastStatusRequest broadcasts `BlockStore` height.
 func (bcR *BlockchainReactor) BroadcastStatusRequest() error {
 	msgBytes := cdc.MustMarshalBinaryBare(&bcStatusRequestMessage{bcR.store.Height()})
@@ -383,6 +721,7 @@ func RegisterBlockchainMessages(cdc *amino.Codec) {
 	cdc.RegisterConcrete(&bcNoBlockResponseMessage{}, "tendermint/blockchain/NoBlockResponse", nil)
 	cdc.RegisterConcrete(&bcStatusResponseMessage{}, "tendermint/blockchain/StatusResponse", nil)
 	cdc.RegisterConcrete(&bcStatusRequestMessage{}, "tendermint/blockchain/StatusRequest", nil)
+	cdc.RegisterConcrete(&bcBlockRangeRequestMessage{}, "tendermint/blockchain/BlockRangeRequest", nil)
 }
 
 func decodeMsg(bz []byte) (msg BlockchainMessage, err error) {
@@ -411,6 +750,26 @@ func (m *bcBlockRequestMessage) String() string {
 	return fmt.Sprintf("[bcBlockRequestMessage %v]", m.Height)
 }
 
+type bcBlockRangeRequestMessage struct {
+	Height int64
+	Count  int64
+}
+
+// ValidateBasic performs basic validation.
+func (m *bcBlockRangeRequestMessage) ValidateBasic() error {
+	if m.Height < 0 {
+		return errors.New("negative Height")
+	}
+	if m.Count < 1 || m.Count > maxBlockRangeCount {
+		return fmt.Errorf("count must be between 1 and %d", maxBlockRangeCount)
+	}
+	return nil
+}
+
+func (m *bcBlockRangeRequestMessage) String() string {
+	return fmt.Sprintf("[bcBlockRangeRequestMessage %v+%v]", m.Height, m.Count)
+}
+
 type bcNoBlockResponseMessage struct {
 	Height int64
 }
@@ -462,8 +821,11 @@ func (m *bcStatusRequestMessage) String() string {
 
 //-------------------------------------
 
+// bcStatusResponseMessage has the range of the blocks a peer can send. The
+// Base of the peers not sending it, whose store starts at 1, is 0.
 type bcStatusResponseMessage struct {
 	Height int64
+	Base   int64
 }
 
 // ValidateBasic performs basic validation.
@@ -471,9 +833,15 @@ func (m *bcStatusResponseMessage) ValidateBasic() error {
 	if m.Height < 0 {
 		return errors.New("negative Height")
 	}
+	if m.Base < 0 {
+		return errors.New("negative Base")
+	}
+	if m.Base > m.Height {
+		return fmt.Errorf("base %v cannot be greater than height %v", m.Base, m.Height)
+	}
 	return nil
 }
 
 func (m *bcStatusResponseMessage) String() string {
-	return fmt.Sprintf("[bcStatusResponseMessage %v]", m.Height)
+	return fmt.Sprintf("[bcStatusResponseMessage %v:%v]", m.Base, m.Height)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/tendermint/tendermint/store"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	cfg "github.com/tendermint/tendermint/config"
@@ -257,6 +258,30 @@ func TestBadBlockStopsPeer(t *testing.T) {
 	assert.True(t, lastReactorPair.reactor.Switch.Peers().Size() < len(reactorPairs)-1)
 }
 
+func TestVerifyLastCommit(t *testing.T) {
+	config = cfg.ResetTestRoot("blockchain_reactor_test")
+	defer os.RemoveAll(config.RootDir)
+	genDoc, privVals := randGenesisDoc(1, false, 30)
+	pair := newBlockchainReactor(log.TestingLogger(), genDoc, privVals, 3)
+	defer pair.app.Stop()
+	bcR := pair.reactor
+
+	block := bcR.store.LoadBlock(3)
+	forged := bcR.store.LoadBlock(3)
+	forged.LastCommit.Signatures[0].Signature = make([]byte, 64)
+	unknown := bcR.store.LoadBlock(3)
+	unknown.LastCommit.Signatures[0].ValidatorAddress = make([]byte, 20)
+
+	// not verified unless enabled
+	assert.NoError(t, bcR.verifyLastCommit(forged))
+
+	bcR.SetVerifyCommits(true)
+	assert.NoError(t, bcR.verifyLastCommit(block))
+	assert.Error(t, bcR.verifyLastCommit(forged))
+	// the commit can't be verified with the validators of the last block synced
+	assert.NoError(t, bcR.verifyLastCommit(unknown))
+}
+
 func TestBcBlockRequestMessageValidateBasic(t *testing.T) {
 	testCases := []struct {
 		testName      string
@@ -277,6 +302,46 @@ func TestBcBlockRequestMessageValidateBasic(t *testing.T) {
 	}
 }
 
+func TestBcBlockRangeRequestMessageValidateBasic(t *testing.T) {
+	testCases := []struct {
+		testName  string
+		height    int64
+		count     int64
+		expectErr bool
+	}{
+		{"Valid Range Request Message", 1, 1, false},
+		{"Valid Range Request Message", 1, maxBlockRangeCount, false},
+		{"Invalid Range Request Message", -1, 10, true},
+		{"Invalid Range Request Message", 1, 0, true},
+		{"Invalid Range Request Message", 1, maxBlockRangeCount + 1, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			request := bcBlockRangeRequestMessage{Height: tc.height, Count: tc.count}
+			assert.Equal(t, tc.expectErr, request.ValidateBasic() != nil, "Validate Basic had an unexpected result")
+		})
+	}
+}
+
+func TestGroupRequests(t *testing.T) {
+	var requests []BlockRequest
+	for _, h := range []int64{3, 1, 2, 5} {
+		requests = append(requests, BlockRequest{Height: h, PeerID: "A"})
+	}
+	for h := int64(1); h <= maxBlockRangeCount+1; h++ {
+		requests = append(requests, BlockRequest{Height: h, PeerID: "B"})
+	}
+
+	groups := groupRequests(requests)
+	require.Len(t, groups, 4)
+	assert.Equal(t, []BlockRequest{{1, "A"}, {2, "A"}, {3, "A"}}, groups[0])
+	assert.Equal(t, []BlockRequest{{5, "A"}}, groups[1])
+	assert.Len(t, groups[2], maxBlockRangeCount)
+	assert.Equal(t, []BlockRequest{{maxBlockRangeCount + 1, "B"}}, groups[3])
+}
+
 func TestBcNoBlockResponseMessageValidateBasic(t *testing.T) {
 	testCases := []struct {
 		testName          string
@@ -320,18 +385,22 @@ func TestBcStatusRequestMessageValidateBasic(t *testing.T) {
 func TestBcStatusResponseMessageValidateBasic(t *testing.T) {
 	testCases := []struct {
 		testName       string
+		responseBase   int64
 		responseHeight int64
 		expectErr      bool
 	}{
-		{"Valid Response Message", 0, false},
-		{"Valid Response Message", 1, false},
-		{"Invalid Response Message", -1, true},
+		{"Valid Response Message", 0, 0, false},
+		{"Valid Response Message", 0, 1, false},
+		{"Valid Response Message", 1, 1, false},
+		{"Invalid Response Message", 0, -1, true},
+		{"Invalid Response Message", -1, 1, true},
+		{"Invalid Response Message", 2, 1, true},
 	}
 
 	for _, tc := range testCases {
 		tc := tc
 		t.Run(tc.testName, func(t *testing.T) {
-			response := bcStatusResponseMessage{Height: tc.responseHeight}
+			response := bcStatusResponseMessage{Base: tc.responseBase, Height: tc.responseHeight}
 			assert.Equal(t, tc.expectErr, response.ValidateBasic() != nil, "Validate Basic had an unexpected result")
 		})
 	}
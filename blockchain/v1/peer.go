@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/tendermint/tendermint/libs/clock"
 	flow "github.com/tendermint/tendermint/libs/flowrate"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/p2p"
@@ -20,6 +21,7 @@ type BpPeerParams struct {
 	minRecvRate int64
 	sampleRate  time.Duration
 	windowSize  time.Duration
+	clock       clock.Clock // measures the timeout, the real clock if nil
 }
 
 // BpPeer is the datastructure associated with a fast sync peer.
@@ -30,7 +32,7 @@ type BpPeer struct {
 	Height                  int64                  // the peer reported height
 	NumPendingBlockRequests int                    // number of requests still waiting for block responses
 	blocks                  map[int64]*types.Block // blocks received or expected to be received from this peer
-	blockResponseTimer      *time.Timer
+	blockResponseTimer      clock.Timer
 	recvMonitor             *flow.Monitor
 	params                  *BpPeerParams // parameters for timer and monitor
 
@@ -175,7 +177,11 @@ func (peer *BpPeer) startMonitor() {
 
 func (peer *BpPeer) resetBlockResponseTimer() {
 	if peer.blockResponseTimer == nil {
-		peer.blockResponseTimer = time.AfterFunc(peer.params.timeout, peer.onTimeout)
+		c := peer.params.clock
+		if c == nil {
+			c = clock.New()
+		}
+		peer.blockResponseTimer = c.AfterFunc(peer.params.timeout, peer.onTimeout)
 	} else {
 		peer.blockResponseTimer.Reset(peer.params.timeout)
 	}
@@ -188,6 +194,18 @@ func (peer *BpPeer) stopBlockResponseTimer() bool {
 	return peer.blockResponseTimer.Stop()
 }
 
+// NewBpPeerParams returns the peer parameters with the given timeout for a
+// peer to respond to a block request, minimum recv rate (in bytes/s) and
+// sample rate and window size of the monitor measuring the recv rate.
+func NewBpPeerParams(timeout time.Duration, minRecvRate int64, sampleRate, windowSize time.Duration) *BpPeerParams {
+	return &BpPeerParams{
+		timeout:     timeout,
+		minRecvRate: minRecvRate,
+		sampleRate:  sampleRate,
+		windowSize:  windowSize,
+	}
+}
+
 // BpPeerDefaultParams returns the default peer parameters.
 func BpPeerDefaultParams() *BpPeerParams {
 	return &BpPeerParams{
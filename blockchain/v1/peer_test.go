@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/libs/clock"
 	"github.com/tendermint/tendermint/libs/log"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/p2p"
@@ -32,7 +33,8 @@ func TestPeerResetBlockResponseTimer(t *testing.T) {
 		lastErr         error      // last generated error
 		peerTestMtx     sync.Mutex // modifications of ^^ variables are also done from timer handler goroutine
 	)
-	params := &BpPeerParams{timeout: 2 * time.Millisecond}
+	fakeClock := clock.NewFake(time.Now())
+	params := &BpPeerParams{timeout: 2 * time.Millisecond, clock: fakeClock}
 
 	peer := NewBpPeer(
 		p2p.ID(tmrand.Str(12)), 10,
@@ -55,12 +57,18 @@ func TestPeerResetBlockResponseTimer(t *testing.T) {
 
 	// reset with running timer
 	peer.resetBlockResponseTimer()
-	time.Sleep(time.Millisecond)
+	fakeClock.Advance(time.Millisecond)
 	peer.resetBlockResponseTimer()
 	assert.NotNil(t, peer.blockResponseTimer)
 
+	// the timer was reset, so it doesn't expire at the initial deadline
+	fakeClock.Advance(time.Millisecond)
+	peerTestMtx.Lock()
+	assert.Equal(t, 0, numErrFuncCalls)
+	peerTestMtx.Unlock()
+
 	// let the timer expire and ...
-	time.Sleep(3 * time.Millisecond)
+	fakeClock.Advance(time.Millisecond)
 	// ... check timer is not running
 	checkByStoppingPeerTimer(t, peer, false)
 
@@ -181,7 +189,8 @@ func TestPeerAddBlock(t *testing.T) {
 
 func TestPeerOnErrFuncCalledDueToExpiration(t *testing.T) {
 
-	params := &BpPeerParams{timeout: 2 * time.Millisecond}
+	fakeClock := clock.NewFake(time.Now())
+	params := &BpPeerParams{timeout: 2 * time.Millisecond, clock: fakeClock}
 	var (
 		numErrFuncCalls int        // number of calls to the onErr function
 		lastErr         error      // last generated error
@@ -201,7 +210,7 @@ func TestPeerOnErrFuncCalledDueToExpiration(t *testing.T) {
 	peer.SetLogger(log.TestingLogger())
 
 	peer.RequestSent(1)
-	time.Sleep(4 * time.Millisecond)
+	fakeClock.Advance(2 * time.Millisecond)
 	// timer should have expired by now, check that the on error function was called
 	peerTestMtx.Lock()
 	assert.Equal(t, 1, numErrFuncCalls)
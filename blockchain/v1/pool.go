@@ -22,6 +22,8 @@ type BlockPool struct {
 	Height        int64 // height of next block to execute
 	MaxPeerHeight int64 // maximum height of all peers
 	toBcR         bcReactor
+
+	peerParams *BpPeerParams // parameters of the new peers, the defaults if nil
 }
 
 // NewBlockPool creates a new BlockPool.
@@ -79,7 +81,7 @@ func (pool *BlockPool) UpdatePeer(peerID p2p.ID, height int64) error {
 			return errPeerTooShort
 		}
 		// Add new peer.
-		peer = NewBpPeer(peerID, height, pool.toBcR.sendPeerError, nil)
+		peer = NewBpPeer(peerID, height, pool.toBcR.sendPeerError, pool.peerParams)
 		peer.SetLogger(pool.logger.With("peer", peerID))
 		pool.peers[peerID] = peer
 		pool.logger.Info("added peer", "peerID", peerID, "height", height, "num_peers", len(pool.peers))
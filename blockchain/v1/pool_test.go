@@ -110,6 +110,18 @@ func assertBlockPoolEquivalent(t *testing.T, poolWanted, pool *BlockPool) {
 
 }
 
+func TestBlockPoolPeerParams(t *testing.T) {
+	pool := NewBlockPool(1, newTestBcR())
+	pool.SetLogger(log.TestingLogger())
+	assert.NoError(t, pool.UpdatePeer("1", 10))
+	assert.Equal(t, BpPeerDefaultParams(), pool.peers["1"].params)
+
+	params := NewBpPeerParams(time.Minute, 1024, 2*time.Second, time.Minute)
+	pool.peerParams = params
+	assert.NoError(t, pool.UpdatePeer("2", 10))
+	assert.Equal(t, params, pool.peers["2"].params)
+}
+
 func TestBlockPoolUpdatePeer(t *testing.T) {
 	testBcR := newTestBcR()
 
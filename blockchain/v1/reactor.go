@@ -8,6 +8,7 @@ import (
 
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/behaviour"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/p2p"
 	sm "github.com/tendermint/tendermint/state"
@@ -108,6 +109,15 @@ func NewBlockchainReactor(state sm.State, blockExec *sm.BlockExecutor, store *st
 	return bcR
 }
 
+// SetPeerParams sets the time a peer has to send a requested block, the
+// minimum rate at which it must send the blocks, and the sample rate and
+// window size of the monitor measuring it. It must be called before the
+// reactor is started.
+func (bcR *BlockchainReactor) SetPeerParams(timeout time.Duration, minRecvRate int64,
+	sampleRate, windowSize time.Duration) {
+	bcR.fsm.pool.peerParams = NewBpPeerParams(timeout, minRecvRate, sampleRate, windowSize)
+}
+
 // bcReactorMessage is used by the reactor to send messages to the FSM.
 type bcReactorMessage struct {
 	event bReactorEvent
@@ -351,7 +361,7 @@ ForLoop:
 
 		case <-statusUpdateTicker.C:
 			// Ask for status updates.
-			go bcR.sendStatusRequest()
+			bcR.sendStatusRequest()
 
 		case msg := <-bcR.messagesForFSMCh:
 			// Sent from the Receive() routine when status (statusResponseEv) and
@@ -396,9 +406,16 @@ ForLoop:
 	}
 }
 
+// reportPeerErrorToSwitch gets the peer banned if it sent invalid blocks, or
+// sent them too slowly, and disconnected otherwise.
 func (bcR *BlockchainReactor) reportPeerErrorToSwitch(err error, peerID p2p.ID) {
 	peer := bcR.Switch.Peers().Get(peerID)
-	if peer != nil {
+	if peer == nil {
+		return
+	}
+	if err == errSlowPeer || tmerrors.CodeOf(err) == tmerrors.CodePeerMisbehavior {
+		_ = bcR.swReporter.Report(behaviour.Misbehaviour(peerID, err.Error()))
+	} else {
 		_ = bcR.swReporter.Report(behaviour.BadMessage(peerID, err.Error()))
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/types"
@@ -161,16 +162,24 @@ var (
 	errTimeoutEventWrongState = errors.New("timeout event for a state different than the current one")
 	errNoTallerPeer           = errors.New("fast sync timed out on waiting for a peer taller than this node")
 
-	// reported eventually to the switch
+	// reported eventually to the switch, with a code telling peers sending bad
+	// data from slow ones
 	// handle return
-	errPeerLowersItsHeight = errors.New("fast sync peer reports a height lower than previous")
+	errPeerLowersItsHeight = tmerrors.New(tmerrors.CodePeerMisbehavior,
+		"fast sync peer reports a height lower than previous")
 	// handle return
-	errNoPeerResponseForCurrentHeights = errors.New("fast sync timed out on peer block response for current heights")
-	errNoPeerResponse                  = errors.New("fast sync timed out on peer block response")               // xx
-	errBadDataFromPeer                 = errors.New("fast sync received block from wrong peer or block is bad") // xx
-	errDuplicateBlock                  = errors.New("fast sync received duplicate block from peer")
-	errBlockVerificationFailure        = errors.New("fast sync block verification failure")              // xx
-	errSlowPeer                        = errors.New("fast sync peer is not sending us data fast enough") // xx
+	errNoPeerResponseForCurrentHeights = tmerrors.New(tmerrors.CodeTimeout,
+		"fast sync timed out on peer block response for current heights")
+	errNoPeerResponse = tmerrors.New(tmerrors.CodeTimeout, // xx
+		"fast sync timed out on peer block response")
+	errBadDataFromPeer = tmerrors.New(tmerrors.CodePeerMisbehavior, // xx
+		"fast sync received block from wrong peer or block is bad")
+	errDuplicateBlock = tmerrors.New(tmerrors.CodePeerMisbehavior,
+		"fast sync received duplicate block from peer")
+	errBlockVerificationFailure = tmerrors.New(tmerrors.CodePeerMisbehavior, // xx
+		"fast sync block verification failure")
+	errSlowPeer = tmerrors.New(tmerrors.CodeTimeout, // xx
+		"fast sync peer is not sending us data fast enough")
 
 )
 
@@ -119,6 +119,9 @@ type BlockchainReactor struct {
 	processor *Routine
 	logger    log.Logger
 
+	// state of the scheduler routine, only accessed by the routine once started
+	sc *scheduler
+
 	mtx           sync.RWMutex
 	maxPeerHeight int64
 	syncHeight    int64
@@ -156,6 +159,7 @@ func newReactor(state state.State, store blockStore, reporter behaviour.Reporter
 		store:     store,
 		reporter:  reporter,
 		logger:    log.NewNopLogger(),
+		sc:        scheduler,
 	}
 }
 
@@ -179,6 +183,13 @@ func (r *BlockchainReactor) SetSwitch(sw *p2p.Switch) {
 	r.io = newSwitchIo(sw)
 }
 
+// SetPeerSelector sets the strategy picking the peer each block is requested
+// from, NewFewestPendingSelector by default. It must be called before the
+// reactor is started.
+func (r *BlockchainReactor) SetPeerSelector(selector PeerSelector) {
+	r.sc.selector = selector
+}
+
 func (r *BlockchainReactor) setMaxPeerHeight(height int64) {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
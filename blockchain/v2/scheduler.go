@@ -113,7 +113,7 @@ type scPeer struct {
 
 	height      int64 // updated when statusResponse is received
 	lastTouched time.Time
-	lastRate    int64 // last receive rate in bytes
+	lastRate    int64 // last receive rate in bytes/s
 }
 
 func (p scPeer) String() string {
@@ -167,6 +167,9 @@ type scheduler struct {
 
 	// a map of heights to the peers that put the block in blockStateReceived
 	receivedBlocks map[int64]p2p.ID
+
+	// the strategy picking the peer a block is requested from
+	selector PeerSelector
 }
 
 func (sc scheduler) String() string {
@@ -188,6 +191,7 @@ func newScheduler(initHeight int64, startTime time.Time) *scheduler {
 		targetPending:  10,               // TODO - pass as param
 		peerTimeout:    15 * time.Second, // TODO - pass as param
 		minRecvRate:    0,                //int64(7680), TODO - pass as param
+		selector:       NewFewestPendingSelector(),
 	}
 
 	return &sc
@@ -364,7 +368,7 @@ func (sc *scheduler) markReceived(peerID p2p.ID, height int64, size int64, now t
 			height, pendingTime, now)
 	}
 
-	peer.lastRate = size / now.Sub(pendingTime).Nanoseconds()
+	peer.lastRate = size * int64(time.Second) / now.Sub(pendingTime).Nanoseconds()
 
 	sc.setStateAtHeight(height, blockStateReceived)
 	delete(sc.pendingBlocks, height)
@@ -468,24 +472,18 @@ func (sc *scheduler) selectPeer(height int64) (p2p.ID, error) {
 		return "", fmt.Errorf("cannot find peer for height %d", height)
 	}
 
-	// create a map from number of pending requests to a list
-	// of peers having that number of pending requests.
-	pendingFrom := make(map[int][]p2p.ID)
-	for _, peerID := range peers {
-		numPending := len(sc.pendingFrom(peerID))
-		pendingFrom[numPending] = append(pendingFrom[numPending], peerID)
-	}
-
-	// find the set of peers with minimum number of pending requests.
-	var minPending int64 = math.MaxInt64
-	for mp := range pendingFrom {
-		if int64(mp) < minPending {
-			minPending = int64(mp)
+	sort.Sort(PeerByID(peers))
+	candidates := make([]PeerCandidate, len(peers))
+	for i, peerID := range peers {
+		peer := sc.peers[peerID]
+		candidates[i] = PeerCandidate{
+			ID:         peerID,
+			Height:     peer.height,
+			NumPending: len(sc.pendingFrom(peerID)),
+			RecvRate:   peer.lastRate,
 		}
 	}
-
-	sort.Sort(PeerByID(pendingFrom[int(minPending)]))
-	return pendingFrom[int(minPending)][0], nil
+	return sc.selector.SelectPeer(height, candidates), nil
 }
 
 // PeerByID is a list of peers sorted by peerID.
@@ -892,7 +892,7 @@ func TestScMarkReceived(t *testing.T) {
 			},
 			args: args{peerID: "P1", height: 2, size: 1000, tm: now.Add(time.Millisecond)},
 			wantFields: scTestParams{
-				peers:       map[string]*scPeer{"P1": {height: 2, state: peerStateReady}},
+				peers:       map[string]*scPeer{"P1": {height: 2, state: peerStateReady, lastRate: 1000}},
 				allB:        []int64{1, 2},
 				pending:     map[int64]p2p.ID{1: "P1"},
 				pendingTime: map[int64]time.Time{1: now},
@@ -2042,7 +2042,7 @@ func TestScHandle(t *testing.T) {
 					wantEvent: scBlockReceived{peerID: "P1", block: makeScBlock(1)},
 					wantSc: &scTestParams{
 						startTime:   now,
-						peers:       map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[4]}},
+						peers:       map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[4], lastRate: 33333}},
 						allB:        []int64{1, 2, 3},
 						pending:     map[int64]p2p.ID{2: "P1", 3: "P1"},
 						pendingTime: map[int64]time.Time{2: tick[2], 3: tick[3]},
@@ -2055,7 +2055,7 @@ func TestScHandle(t *testing.T) {
 					wantEvent: scBlockReceived{peerID: "P1", block: makeScBlock(2)},
 					wantSc: &scTestParams{
 						startTime:   now,
-						peers:       map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[5]}},
+						peers:       map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[5], lastRate: 33333}},
 						allB:        []int64{1, 2, 3},
 						pending:     map[int64]p2p.ID{3: "P1"},
 						pendingTime: map[int64]time.Time{3: tick[3]},
@@ -2068,7 +2068,7 @@ func TestScHandle(t *testing.T) {
 					wantEvent: scBlockReceived{peerID: "P1", block: makeScBlock(3)},
 					wantSc: &scTestParams{
 						startTime: now,
-						peers:     map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[6]}},
+						peers:     map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[6], lastRate: 33333}},
 						allB:      []int64{1, 2, 3},
 						received:  map[int64]p2p.ID{1: "P1", 2: "P1", 3: "P1"},
 						height:    1,
@@ -2079,7 +2079,7 @@ func TestScHandle(t *testing.T) {
 					wantEvent: noOpEvent{},
 					wantSc: &scTestParams{
 						startTime: now,
-						peers:     map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[6]}},
+						peers:     map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[6], lastRate: 33333}},
 						allB:      []int64{2, 3},
 						received:  map[int64]p2p.ID{2: "P1", 3: "P1"},
 						height:    2,
@@ -2090,7 +2090,7 @@ func TestScHandle(t *testing.T) {
 					wantEvent: scFinishedEv{},
 					wantSc: &scTestParams{
 						startTime: now,
-						peers:     map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[6]}},
+						peers:     map[string]*scPeer{"P1": {height: 3, state: peerStateReady, lastTouched: tick[6], lastRate: 33333}},
 						allB:      []int64{3},
 						received:  map[int64]p2p.ID{3: "P1"},
 						height:    3,
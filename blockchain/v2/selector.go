@@ -0,0 +1,93 @@
+package v2
+
+import (
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// PeerSelector is the strategy of the scheduler picking the peer a block is
+// requested from.
+type PeerSelector interface {
+	// SelectPeer returns the ID of one of the candidates, the ready peers at
+	// height or above, sorted by ID. There is at least one candidate.
+	SelectPeer(height int64, candidates []PeerCandidate) p2p.ID
+}
+
+// PeerCandidate is what the scheduler knows of a peer a block can be
+// requested from.
+type PeerCandidate struct {
+	ID         p2p.ID
+	Height     int64
+	NumPending int   // number of blocks requested and not received yet
+	RecvRate   int64 // bytes/s at which the last block was received, 0 if none
+}
+
+// NewFewestPendingSelector returns the default PeerSelector, picking the peer
+// with the fewest pending requests, the lowest ID on ties.
+func NewFewestPendingSelector() PeerSelector {
+	return fewestPendingSelector{}
+}
+
+type fewestPendingSelector struct{}
+
+func (fewestPendingSelector) SelectPeer(height int64, candidates []PeerCandidate) p2p.ID {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if c.NumPending < best.NumPending {
+			best = c
+		}
+	}
+	return best.ID
+}
+
+// NewRoundRobinSelector returns a PeerSelector picking the candidates in turn,
+// in the order of their IDs, regardless of their pending requests.
+func NewRoundRobinSelector() PeerSelector {
+	return &roundRobinSelector{}
+}
+
+type roundRobinSelector struct {
+	last p2p.ID
+}
+
+func (s *roundRobinSelector) SelectPeer(height int64, candidates []PeerCandidate) p2p.ID {
+	next := candidates[0].ID
+	for _, c := range candidates {
+		if c.ID > s.last {
+			next = c.ID
+			break
+		}
+	}
+	s.last = next
+	return next
+}
+
+// NewFastestPeerSelector returns a PeerSelector picking the peer expected to
+// send the block first, given its pending requests and recv rate. Peers which
+// haven't sent a block yet are picked first, so that their rate is measured.
+func NewFastestPeerSelector() PeerSelector {
+	return fastestPeerSelector{}
+}
+
+type fastestPeerSelector struct{}
+
+func (fastestPeerSelector) SelectPeer(height int64, candidates []PeerCandidate) p2p.ID {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if fasterPeer(c, best) {
+			best = c
+		}
+	}
+	return best.ID
+}
+
+// fasterPeer returns true if a is expected to send a new block before b.
+func fasterPeer(a, b PeerCandidate) bool {
+	switch {
+	case a.RecvRate == 0 && b.RecvRate == 0:
+		return a.NumPending < b.NumPending
+	case a.RecvRate == 0 || b.RecvRate == 0:
+		return a.RecvRate == 0
+	}
+	// the blocks are sent in turn: compare (pending+1)/rate
+	return int64(a.NumPending+1)*b.RecvRate < int64(b.NumPending+1)*a.RecvRate
+}
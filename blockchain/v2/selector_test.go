@@ -0,0 +1,78 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/p2p"
+)
+
+func TestFewestPendingSelector(t *testing.T) {
+	selector := NewFewestPendingSelector()
+	assert.Equal(t, p2p.ID("P2"), selector.SelectPeer(1, []PeerCandidate{
+		{ID: "P1", NumPending: 2},
+		{ID: "P2", NumPending: 1},
+		{ID: "P3", NumPending: 1},
+	}))
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	selector := NewRoundRobinSelector()
+	candidates := []PeerCandidate{{ID: "P1"}, {ID: "P2", NumPending: 5}, {ID: "P3"}}
+
+	var picked []p2p.ID
+	for i := 0; i < 4; i++ {
+		picked = append(picked, selector.SelectPeer(1, candidates))
+	}
+	assert.Equal(t, []p2p.ID{"P1", "P2", "P3", "P1"}, picked)
+
+	// the turn goes on among the remaining candidates
+	assert.Equal(t, p2p.ID("P3"), selector.SelectPeer(1, []PeerCandidate{{ID: "P0"}, {ID: "P3"}}))
+	assert.Equal(t, p2p.ID("P0"), selector.SelectPeer(1, []PeerCandidate{{ID: "P0"}, {ID: "P3"}}))
+}
+
+func TestFastestPeerSelector(t *testing.T) {
+	selector := NewFastestPeerSelector()
+
+	tests := []struct {
+		name       string
+		candidates []PeerCandidate
+		want       p2p.ID
+	}{
+		{
+			name:       "fastest peer",
+			candidates: []PeerCandidate{{ID: "P1", RecvRate: 100}, {ID: "P2", RecvRate: 200}},
+			want:       "P2",
+		},
+		{
+			name: "fastest peer busy",
+			candidates: []PeerCandidate{
+				{ID: "P1", RecvRate: 100},
+				{ID: "P2", RecvRate: 200, NumPending: 2},
+			},
+			want: "P1",
+		},
+		{
+			name: "peer without rate first",
+			candidates: []PeerCandidate{
+				{ID: "P1", RecvRate: 100},
+				{ID: "P2", NumPending: 1},
+				{ID: "P3"},
+			},
+			want: "P3",
+		},
+		{
+			name:       "same rate",
+			candidates: []PeerCandidate{{ID: "P1", RecvRate: 100}, {ID: "P2", RecvRate: 100}},
+			want:       "P1",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, selector.SelectPeer(1, tt.candidates))
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
+	"net"
 	"os"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	tmos "github.com/tendermint/tendermint/libs/os"
 
 	"github.com/tendermint/tendermint/privval"
+	privvalgrpc "github.com/tendermint/tendermint/privval/grpc"
 )
 
 func main() {
@@ -19,6 +22,17 @@ func main() {
 		chainID          = flag.String("chain-id", "mychain", "chain id")
 		privValKeyPath   = flag.String("priv-key", "", "priv val key file path")
 		privValStatePath = flag.String("priv-state", "", "priv val state file path")
+		keySharePath     = flag.String("key-share", "",
+			"key share file path, to run a shard of a threshold signer (see tendermint split_key) instead")
+		grpcAddr = flag.String("grpc-laddr", "",
+			"address to serve gRPC on (see priv_validator_grpc_addr), instead of connecting to addr")
+		tlsCertPath    = flag.String("tls-cert", "", "TLS certificate file path of the gRPC server")
+		tlsKeyPath     = flag.String("tls-key", "", "TLS key file path of the gRPC server")
+		tlsCAPath      = flag.String("tls-ca", "", "CA certificates file path to require and verify client certificates with")
+		passphrasePath = flag.String("priv-key-passphrase", "",
+			"file path of the passphrase of an encrypted priv val key, prompted for if empty")
+		kmsCommand = flag.String("priv-key-kms-command", "",
+			"command printing the base64 encoded secret of a priv val key encrypted with a key from a KMS")
 
 		logger = log.NewTMLogger(
 			log.NewSyncWriter(os.Stdout),
@@ -32,9 +46,16 @@ func main() {
 		"chainID", *chainID,
 		"privKeyPath", *privValKeyPath,
 		"privStatePath", *privValStatePath,
+		"keySharePath", *keySharePath,
+		"grpcAddr", *grpcAddr,
 	)
 
-	pv := privval.LoadFilePV(*privValKeyPath, *privValStatePath)
+	if *grpcAddr != "" {
+		pv := privval.LoadFilePVWithSecret(*privValKeyPath, *privValStatePath,
+			privval.NewKeySecretFunc(*passphrasePath, *kmsCommand))
+		serveGRPC(*grpcAddr, *chainID, pv, *tlsCertPath, *tlsKeyPath, *tlsCAPath, logger)
+		return
+	}
 
 	var dialer privval.SocketDialer
 	protocol, address := tmnet.ProtocolAndAddress(*addr)
@@ -50,7 +71,19 @@ func main() {
 	}
 
 	sd := privval.NewSignerDialerEndpoint(logger, dialer)
-	ss := privval.NewSignerServer(sd, *chainID, pv)
+	var ss *privval.SignerServer
+	if *keySharePath != "" {
+		shard, err := privval.LoadThresholdShard(*keySharePath, *privValStatePath)
+		if err != nil {
+			logger.Error("Failed to load the key share", "err", err)
+			os.Exit(1)
+		}
+		ss = privval.NewThresholdSignerServer(sd, *chainID, shard)
+	} else {
+		pv := privval.LoadFilePVWithSecret(*privValKeyPath, *privValStatePath,
+			privval.NewKeySecretFunc(*passphrasePath, *kmsCommand))
+		ss = privval.NewSignerServer(sd, *chainID, pv)
+	}
 
 	err := ss.Start()
 	if err != nil {
@@ -68,3 +101,34 @@ func main() {
 	// Run forever.
 	select {}
 }
+
+// serveGRPC serves pv over gRPC at addr, until receiving SIGTERM or CTRL-C.
+func serveGRPC(addr, chainID string, pv *privval.FilePV, tlsCertPath, tlsKeyPath, tlsCAPath string,
+	logger log.Logger) {
+	var tlsConfig *tls.Config
+	if tlsCertPath != "" {
+		var err error
+		tlsConfig, err = privvalgrpc.ServerTLSConfig(tlsCertPath, tlsKeyPath, tlsCAPath)
+		if err != nil {
+			logger.Error("Failed to load the TLS files", "err", err)
+			os.Exit(1)
+		}
+	}
+
+	protocol, address := tmnet.ProtocolAndAddress(addr)
+	ln, err := net.Listen(protocol, address)
+	if err != nil {
+		logger.Error("Failed to listen", "addr", addr, "err", err)
+		os.Exit(1)
+	}
+
+	grpcServer := privvalgrpc.NewGRPCServer(pv, chainID, tlsConfig)
+
+	// Stop upon receiving SIGTERM or CTRL-C.
+	tmos.TrapSignal(logger, grpcServer.GracefulStop)
+
+	if err := grpcServer.Serve(ln); err != nil {
+		logger.Error("gRPC server stopped", "err", err)
+		os.Exit(1)
+	}
+}
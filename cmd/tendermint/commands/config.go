@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+// ConfigCmd groups the commands dealing with the config file.
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Config file related commands",
+}
+
+// ConfigValidateCmd checks the config file and reports all problems found.
+var ConfigValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config file",
+	Long: `Validate $TMHOME/config/config.toml and report all problems at once:
+values of the wrong type, invalid or contradictory settings, malformed
+addresses, and unknown or deprecated fields, along with suggested fixes.
+
+Errors prevent the node from starting; warnings don't.`,
+	RunE: validateConfig,
+}
+
+// ConfigFieldsCmd lists all config fields with the environment variables and
+// flags overriding them.
+var ConfigFieldsCmd = &cobra.Command{
+	Use:   "fields",
+	Short: "List the config fields and how to override them",
+	Long: `List every field of config.toml with the environment variable and the
+flag of the node command overriding it, and its default value.
+
+Values are taken from, in order of precedence: flags, environment variables,
+config.toml and the defaults.`,
+	RunE: listConfigFields,
+}
+
+// ConfigApplyJournalCmd writes the config changes made at runtime to the
+// config file.
+var ConfigApplyJournalCmd = &cobra.Command{
+	Use:   "apply-journal",
+	Short: "Write the config changes made at runtime to the config file",
+	Long: `Apply the config changes recorded in $TMHOME/config/journal.jsonl, made at
+runtime with the unsafe_set_config RPC command, to $TMHOME/config/config.toml,
+so that they survive a restart, and remove the journal.
+
+The config file is rewritten from the default template, so comments added to
+it are lost.`,
+	RunE: applyConfigJournal,
+}
+
+func init() {
+	ConfigCmd.AddCommand(ConfigValidateCmd, ConfigFieldsCmd, ConfigApplyJournalCmd)
+}
+
+// envPrefix is the prefix of the environment variables overriding config
+// fields (see cli.PrepareBaseCmd).
+const envPrefix = "TM"
+
+func listConfigFields(cmd *cobra.Command, args []string) error {
+	if jsonOutput() {
+		type field struct {
+			Key     string      `json:"key"`
+			Env     string      `json:"env"`
+			Flag    string      `json:"flag"`
+			Default interface{} `json:"default"`
+		}
+		var fields []field
+		for _, f := range cfg.Fields(cfg.DefaultConfig()) {
+			value := f.Value
+			if d, ok := value.(time.Duration); ok {
+				value = d.String()
+			}
+			fields = append(fields, field{f.Key, cfg.EnvVar(envPrefix, f.Key), "--" + f.Key, value})
+		}
+		return printJSON(fields)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tENVIRONMENT VARIABLE\tFLAG\tDEFAULT")
+	for _, f := range cfg.Fields(cfg.DefaultConfig()) {
+		fmt.Fprintf(w, "%s\t%s\t--%s\t%v\n", f.Key, cfg.EnvVar(envPrefix, f.Key), f.Key, f.Value)
+	}
+	return w.Flush()
+}
+
+// bindConfigEnv binds an environment variable to every config field, so that
+// fields missing from config.toml can be set from the environment too.
+func bindConfigEnv() error {
+	for _, f := range cfg.Fields(cfg.DefaultConfig()) {
+		if err := viper.BindEnv(f.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addConfigFlags adds a flag named after its key for every config field which
+// doesn't have one yet.
+func addConfigFlags(cmd *cobra.Command) {
+	for _, f := range cfg.Fields(config) {
+		// log_level is a flag of the root command
+		if f.Key == "log_level" || cmd.Flags().Lookup(f.Key) != nil {
+			continue
+		}
+		usage := fmt.Sprintf("Overrides %s of config.toml", f.Key)
+		switch v := f.Value.(type) {
+		case bool:
+			cmd.Flags().Bool(f.Key, v, usage)
+		case int:
+			cmd.Flags().Int(f.Key, v, usage)
+		case int64:
+			cmd.Flags().Int64(f.Key, v, usage)
+		case float64:
+			cmd.Flags().Float64(f.Key, v, usage)
+		case time.Duration:
+			cmd.Flags().Duration(f.Key, v, usage)
+		case []string:
+			cmd.Flags().StringSlice(f.Key, v, usage)
+		default:
+			cmd.Flags().String(f.Key, fmt.Sprint(v), usage)
+		}
+	}
+}
+
+func validateConfig(cmd *cobra.Command, args []string) error {
+	problems, err := cfg.CheckConfigFile(viper.GetString(cli.HomeFlag))
+	if err != nil {
+		return err
+	}
+	errs := cfg.Errors(problems)
+	if jsonOutput() {
+		if problems == nil {
+			problems = []cfg.Problem{}
+		}
+		if err := printJSON(struct {
+			Valid    bool          `json:"valid"`
+			Problems []cfg.Problem `json:"problems"`
+		}{len(errs) == 0, problems}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("found %d errors in the config file", len(errs))
+	}
+	if len(problems) == 0 && !jsonOutput() {
+		fmt.Println("The config file is valid")
+	}
+	return nil
+}
+
+func applyConfigJournal(cmd *cobra.Command, args []string) error {
+	entries, err := cfg.ReadJournal(config.JournalFile())
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		if jsonOutput() {
+			return printJSON(map[string][]cfg.JournalEntry{"applied": {}})
+		}
+		fmt.Println("No config changes to apply")
+		return nil
+	}
+	conf, err := cfg.LoadConfigFile(config.RootDir)
+	if err != nil {
+		return err
+	}
+	if err := conf.ApplyJournal(entries); err != nil {
+		return err
+	}
+	configFile := filepath.Join(config.RootDir, "config", "config.toml")
+	cfg.WriteConfigFile(configFile, conf)
+	if jsonOutput() {
+		if err := printJSON(map[string][]cfg.JournalEntry{"applied": entries}); err != nil {
+			return err
+		}
+	} else {
+		for _, entry := range entries {
+			fmt.Printf("%s = %s\n", entry.Key, entry.Value)
+		}
+	}
+	return os.Remove(config.JournalFile())
+}
+
+// configError returns an error describing all errors found in conf, given
+// that validating it failed with err.
+func configError(conf *cfg.Config, err error) error {
+	errs := cfg.Errors(conf.Check())
+	if len(errs) == 0 {
+		return fmt.Errorf("error in config file: %v", err)
+	}
+	msgs := make([]string, len(errs))
+	for i, p := range errs {
+		msgs[i] = p.String()
+	}
+	return fmt.Errorf("errors in config file:\n%s", strings.Join(msgs, "\n"))
+}
+
+// logConfigWarnings logs the problems of conf which don't prevent the node
+// from running, e.g. deprecated fields. Keys set by flags of cmd are known.
+func logConfigWarnings(cmd *cobra.Command, conf *cfg.Config) {
+	var keys []string
+	for _, key := range viper.AllKeys() {
+		if cmd.Flag(key) == nil {
+			keys = append(keys, key)
+		}
+	}
+	for _, p := range append(cfg.CheckKeys(keys), conf.Check()...) {
+		if p.Warning {
+			logger.Info("Config warning", "key", p.Key, "problem", p.Message, "suggestion", p.Suggestion)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package debug
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/cli"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+var collectCmd = &cobra.Command{
+	Use:   "collect [compressed-output-file]",
+	Short: "Collect debugging data from a running Tendermint process into a compressed archive",
+	Long: `Collect debugging data from a running Tendermint process into a compressed
+archive: the node status, the consensus and networking state, the goroutine and
+heap profiles if a profiling server address is given, the node's config with
+its secrets redacted, the last lines of its log file if one is given, and the
+node's WAL, along with its last messages decoded to JSON.
+
+The node is not stopped, see 'debug kill' for a wedged node. Data which can't
+be collected, e.g. because the node doesn't respond to an RPC request, is
+skipped and logged.
+
+Example:
+$ tendermint debug collect /path/to/tm-debug.zip --pprof-laddr=localhost:6060 --log-file=/var/log/tendermint.log`,
+	Args: cobra.ExactArgs(1),
+	RunE: collectCmdHandler,
+}
+
+func collectCmdHandler(_ *cobra.Command, args []string) error {
+	outFile := args[0]
+	if outFile == "" {
+		return errors.New("invalid output file")
+	}
+
+	rpc, err := rpcclient.NewHTTPWithTimeout(nodeRPCAddr, "/websocket", timeout)
+	if err != nil {
+		return errors.Wrap(err, "failed to create new http client")
+	}
+
+	home := viper.GetString(cli.HomeFlag)
+	conf, err := loadConfig(home)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "tendermint_debug_tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	collectErr := collectDebugData(rpc, conf, tmpDir)
+
+	logger.Info("archiving and compressing debug directory...")
+	if err := zipDir(tmpDir, outFile); err != nil {
+		return errors.Wrap(err, "failed to create and compress archive")
+	}
+
+	return collectErr
+}
+
+// collectDebugData writes the debugging data of the node to the directory dir.
+// It carries on when some data can't be collected, so that as much as possible
+// is collected from a faulty node, and returns an error listing the data which
+// couldn't be.
+func collectDebugData(rpc *rpcclient.HTTP, conf *cfg.Config, dir string) error {
+	var failed []string
+	collect := func(what string, fn func() error) {
+		logger.Info("getting " + what + "...")
+		if err := fn(); err != nil {
+			logger.Error("failed to get "+what, "error", err)
+			failed = append(failed, what)
+		}
+	}
+
+	collect("node status", func() error {
+		return dumpStatus(rpc, dir, "status.json")
+	})
+	collect("node network info", func() error {
+		return dumpNetInfo(rpc, dir, "net_info.json")
+	})
+	collect("node consensus state", func() error {
+		return dumpConsensusState(rpc, dir, "consensus_state.json")
+	})
+
+	if profAddr != "" {
+		collect("node goroutine profile", func() error {
+			return dumpProfile(dir, profAddr, conf.ProfAuthToken, "goroutine", 2)
+		})
+		collect("node heap profile", func() error {
+			return dumpProfile(dir, profAddr, conf.ProfAuthToken, "heap", 2)
+		})
+	}
+
+	collect("node configuration", func() error {
+		return copyRedactedConfig(conf.RootDir, dir)
+	})
+
+	if logFile != "" {
+		collect("node logs", func() error {
+			return copyLogTail(logFile, int(logLines), dir)
+		})
+	}
+
+	collect("node WAL", func() error {
+		return copyWAL(conf, dir)
+	})
+	collect("node WAL tail", func() error {
+		return dumpWALTail(conf, int(walMessages), dir, "wal_tail.json")
+	})
+
+	if len(failed) > 0 {
+		return errors.Errorf("failed to get %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
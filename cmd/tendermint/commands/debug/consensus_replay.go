@@ -0,0 +1,73 @@
+package debug
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	cs "github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+var consensusReplayCmd = &cobra.Command{
+	Use:   "consensus-replay",
+	Short: "Replay the consensus WAL of a stopped node, printing each step, timeout and vote",
+	Long: `Replay the consensus WAL of a stopped node, from the genesis, printing each
+message replayed: the step transitions, the timeouts fired, and the proposals
+and votes received, with the time they were written to the WAL.
+
+The blocks are executed by a mock app, which returns the responses and the app
+hashes recorded by the node, so the replay is deterministic and doesn't need
+the app. The blocks committed are checked against the block store of the
+node, and the node's data isn't modified. Run it on a stopped node, or on a
+copy of its data directory.
+
+The replay stops once the consensus has gone past --until-height, or past
+--until-round of that height, rather than at the end of the WAL.
+
+Example:
+$ tendermint debug consensus-replay --home=/path/to/app.d --until-height=142 --until-round=3`,
+	Args: cobra.NoArgs,
+	RunE: consensusReplayCmdHandler,
+}
+
+func init() {
+	consensusReplayCmd.Flags().Int64Var(
+		&untilHeight,
+		flagUntilHeight,
+		0,
+		"The height after which to stop the replay (0 replays the whole WAL)",
+	)
+	consensusReplayCmd.Flags().IntVar(
+		&untilRound,
+		flagUntilRound,
+		-1,
+		"The round of --until-height after which to stop the replay (-1 for all its rounds)",
+	)
+}
+
+func consensusReplayCmdHandler(cmd *cobra.Command, _ []string) error {
+	if untilHeight < 0 {
+		return errors.New("until-height can't be negative")
+	}
+	if untilRound < -1 {
+		return errors.New("until-round can't be less than -1")
+	}
+	if untilRound >= 0 && untilHeight == 0 {
+		return errors.New("until-round requires until-height")
+	}
+
+	home := viper.GetString(cli.HomeFlag)
+	conf, err := loadConfig(home)
+	if err != nil {
+		return err
+	}
+
+	opts := cs.ReplayOptions{
+		Until:   cs.Breakpoint{Height: untilHeight, Round: untilRound},
+		MockApp: true,
+		Trace:   true,
+		Out:     cmd.OutOrStdout(),
+	}
+	return cs.ReplayFile(conf.BaseConfig, conf.Consensus, opts)
+}
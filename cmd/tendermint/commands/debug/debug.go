@@ -12,10 +12,22 @@ var (
 	nodeRPCAddr string
 	profAddr    string
 	frequency   uint
+	logFile     string
+	logLines    uint
+	walMessages uint
+	timeout     uint
+	untilHeight int64
+	untilRound  int
 
 	flagNodeRPCAddr = "rpc-laddr"
 	flagProfAddr    = "pprof-laddr"
 	flagFrequency   = "frequency"
+	flagLogFile     = "log-file"
+	flagLogLines    = "log-lines"
+	flagWALMessages = "wal-messages"
+	flagTimeout     = "timeout"
+	flagUntilHeight = "until-height"
+	flagUntilRound  = "until-round"
 
 	logger = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
 )
@@ -24,7 +36,7 @@ var (
 // debugging running Tendermint processes.
 var DebugCmd = &cobra.Command{
 	Use:   "debug",
-	Short: "A utility to kill or watch a Tendermint process while aggregating debugging data",
+	Short: "A utility to inspect, kill or watch a Tendermint process while aggregating debugging data",
 }
 
 func init() {
@@ -35,7 +47,39 @@ func init() {
 		"tcp://localhost:26657",
 		"The Tendermint node's RPC address (<host>:<port>)",
 	)
+	DebugCmd.PersistentFlags().StringVar(
+		&profAddr,
+		flagProfAddr,
+		"",
+		"The profiling server address (<host>:<port>)",
+	)
+	DebugCmd.PersistentFlags().StringVar(
+		&logFile,
+		flagLogFile,
+		"",
+		"The file the Tendermint node logs to, whose last lines are collected",
+	)
+	DebugCmd.PersistentFlags().UintVar(
+		&logLines,
+		flagLogLines,
+		1000,
+		"The number of log lines to collect",
+	)
+	DebugCmd.PersistentFlags().UintVar(
+		&walMessages,
+		flagWALMessages,
+		1000,
+		"The number of the last WAL messages to decode to JSON",
+	)
+	DebugCmd.PersistentFlags().UintVar(
+		&timeout,
+		flagTimeout,
+		10,
+		"The timeout (seconds) of the requests to the node, which may be wedged",
+	)
 
+	DebugCmd.AddCommand(collectCmd)
 	DebugCmd.AddCommand(killCmd)
 	DebugCmd.AddCommand(dumpCmd)
+	DebugCmd.AddCommand(consensusReplayCmd)
 }
@@ -21,7 +21,7 @@ var dumpCmd = &cobra.Command{
 	Long: `Continuously poll a Tendermint process and dump debugging data into a single
 location at a specified frequency. At each frequency interval, an archived and compressed
 file will contain node debugging information including the goroutine and heap profiles
-if enabled.`,
+if enabled, the same as 'debug collect'.`,
 	Args: cobra.ExactArgs(1),
 	RunE: dumpCmdHandler,
 }
@@ -33,13 +33,6 @@ func init() {
 		30,
 		"The frequency (seconds) in which to poll, aggregate and dump Tendermint debug data",
 	)
-
-	dumpCmd.Flags().StringVar(
-		&profAddr,
-		flagProfAddr,
-		"",
-		"The profiling server address (<host>:<port>)",
-	)
 }
 
 func dumpCmdHandler(_ *cobra.Command, args []string) error {
@@ -58,15 +51,16 @@ func dumpCmdHandler(_ *cobra.Command, args []string) error {
 		}
 	}
 
-	rpc, err := rpcclient.NewHTTP(nodeRPCAddr, "/websocket")
+	rpc, err := rpcclient.NewHTTPWithTimeout(nodeRPCAddr, "/websocket", timeout)
 	if err != nil {
 		return errors.Wrap(err, "failed to create new http client")
 	}
 
 	home := viper.GetString(cli.HomeFlag)
-	conf := cfg.DefaultConfig()
-	conf = conf.SetRoot(home)
-	cfg.EnsureRoot(conf.RootDir)
+	conf, err := loadConfig(home)
+	if err != nil {
+		return err
+	}
 
 	dumpDebugData(outDir, conf, rpc)
 
@@ -88,42 +82,8 @@ func dumpDebugData(outDir string, conf *cfg.Config, rpc *rpcclient.HTTP) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	logger.Info("getting node status...")
-	if err := dumpStatus(rpc, tmpDir, "status.json"); err != nil {
-		logger.Error("failed to dump node status", "error", err)
-		return
-	}
-
-	logger.Info("getting node network info...")
-	if err := dumpNetInfo(rpc, tmpDir, "net_info.json"); err != nil {
-		logger.Error("failed to dump node network info", "error", err)
-		return
-	}
-
-	logger.Info("getting node consensus state...")
-	if err := dumpConsensusState(rpc, tmpDir, "consensus_state.json"); err != nil {
-		logger.Error("failed to dump node consensus state", "error", err)
-		return
-	}
-
-	logger.Info("copying node WAL...")
-	if err := copyWAL(conf, tmpDir); err != nil {
-		logger.Error("failed to copy node WAL", "error", err)
-		return
-	}
-
-	if profAddr != "" {
-		logger.Info("getting node goroutine profile...")
-		if err := dumpProfile(tmpDir, profAddr, "goroutine", 2); err != nil {
-			logger.Error("failed to dump goroutine profile", "error", err)
-			return
-		}
-
-		logger.Info("getting node heap profile...")
-		if err := dumpProfile(tmpDir, profAddr, "heap", 2); err != nil {
-			logger.Error("failed to dump heap profile", "error", err)
-			return
-		}
+	if err := collectDebugData(rpc, conf, tmpDir); err != nil {
+		logger.Error("failed to collect all debugging data", "error", err)
 	}
 
 	outFile := filepath.Join(outDir, fmt.Sprintf("%s.zip", start.Format(time.Stamp)))
@@ -2,6 +2,7 @@ package debug
 
 import (
 	"archive/zip"
+	"bytes"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -115,3 +116,48 @@ func writeStateJSONToFile(state interface{}, dir, filename string) error {
 
 	return ioutil.WriteFile(path.Join(dir, filename), stateJSON, os.ModePerm)
 }
+
+// tailFile returns the last n lines of the file at path. The file is read
+// backwards from its end, so that only the tail of a large log file is read.
+func tailFile(path string, n int) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	const chunkSize = 64 * 1024
+	var (
+		offset = info.Size()
+		tail   []byte
+	)
+	// A trailing newline ends the last line, rather than starting another one.
+	for offset > 0 && bytes.Count(bytes.TrimSuffix(tail, []byte("\n")), []byte("\n")) < n {
+		size := int64(chunkSize)
+		if offset < size {
+			size = offset
+		}
+		offset -= size
+
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, offset); err != nil {
+			return nil, err
+		}
+		tail = append(chunk, tail...)
+	}
+
+	lines := bytes.SplitAfter(tail, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return bytes.Join(lines, nil), nil
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
-	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/cli"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 )
@@ -24,11 +23,15 @@ var killCmd = &cobra.Command{
 	Short: "Kill a Tendermint process while aggregating and packaging debugging data",
 	Long: `Kill a Tendermint process while also aggregating Tendermint process data
 such as the latest node state, including consensus and networking state,
-go-routine state, and the node's WAL and config information. This aggregated data
-is packaged into a compressed archive.
+go-routine state, and the node's WAL and config information, the same as
+'debug collect'. This aggregated data is packaged into a compressed archive.
+
+The process is killed even if it doesn't respond to the RPC requests, which
+is useful for a wedged node: the goroutine stacktrace it prints on exit is
+collected in any case.
 
 Example:
-$ tendermint debug 34255 /path/to/tm-debug.zip`,
+$ tendermint debug kill 34255 /path/to/tm-debug.zip`,
 	Args: cobra.ExactArgs(2),
 	RunE: killCmdHandler,
 }
@@ -44,15 +47,16 @@ func killCmdHandler(cmd *cobra.Command, args []string) error {
 		return errors.New("invalid output file")
 	}
 
-	rpc, err := rpcclient.NewHTTP(nodeRPCAddr, "/websocket")
+	rpc, err := rpcclient.NewHTTPWithTimeout(nodeRPCAddr, "/websocket", timeout)
 	if err != nil {
 		return errors.Wrap(err, "failed to create new http client")
 	}
 
 	home := viper.GetString(cli.HomeFlag)
-	conf := cfg.DefaultConfig()
-	conf = conf.SetRoot(home)
-	cfg.EnsureRoot(conf.RootDir)
+	conf, err := loadConfig(home)
+	if err != nil {
+		return err
+	}
 
 	// Create a temporary directory which will contain all the state dumps and
 	// relevant files and directories that will be compressed into a file.
@@ -62,29 +66,8 @@ func killCmdHandler(cmd *cobra.Command, args []string) error {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	logger.Info("getting node status...")
-	if err := dumpStatus(rpc, tmpDir, "status.json"); err != nil {
-		return err
-	}
-
-	logger.Info("getting node network info...")
-	if err := dumpNetInfo(rpc, tmpDir, "net_info.json"); err != nil {
-		return err
-	}
-
-	logger.Info("getting node consensus state...")
-	if err := dumpConsensusState(rpc, tmpDir, "consensus_state.json"); err != nil {
-		return err
-	}
-
-	logger.Info("copying node WAL...")
-	if err := copyWAL(conf, tmpDir); err != nil {
-		return err
-	}
-
-	logger.Info("copying node configuration...")
-	if err := copyConfig(home, tmpDir); err != nil {
-		return err
+	if err := collectDebugData(rpc, conf, tmpDir); err != nil {
+		logger.Error("failed to collect all debugging data", "error", err)
 	}
 
 	logger.Info("killing Tendermint process")
@@ -124,7 +107,7 @@ func killProc(pid uint64, dir string) error {
 	go func() {
 		// Killing the Tendermint process with the '-ABRT|-6' signal will result in
 		// a goroutine stacktrace.
-		p, err := os.FindProcess(os.Getpid())
+		p, err := os.FindProcess(int(pid))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to find PID to kill Tendermint process: %s", err)
 		} else if err = p.Signal(syscall.SIGABRT); err != nil {
@@ -1,18 +1,54 @@
 package debug
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	amino "github.com/tendermint/go-amino"
+
 	cfg "github.com/tendermint/tendermint/config"
+	cs "github.com/tendermint/tendermint/consensus"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/types"
 )
 
+var cdc = amino.NewCodec()
+
+func init() {
+	cs.RegisterMessages(cdc)
+	cs.RegisterWALMessages(cdc)
+	types.RegisterBlockAmino(cdc)
+}
+
+// loadConfig loads the Tendermint node's config file under home, falling back
+// to the default config if there is none.
+func loadConfig(home string) (*cfg.Config, error) {
+	conf := cfg.DefaultConfig()
+
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(home, "config", "config.toml"))
+	if err := v.ReadInConfig(); err == nil {
+		if err := v.Unmarshal(conf); err != nil {
+			return nil, errors.Wrap(err, "failed to decode node config")
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to read node config")
+	}
+
+	return conf.SetRoot(home), nil
+}
+
 // dumpStatus gets node status state dump from the Tendermint RPC and writes it
 // to file. It returns an error upon failure.
 func dumpStatus(rpc *rpcclient.HTTP, dir, filename string) error {
@@ -55,24 +91,126 @@ func copyWAL(conf *cfg.Config, dir string) error {
 	return copyFile(walPath, filepath.Join(dir, walFile))
 }
 
-// copyConfig copies the Tendermint node's config file. It returns an error if
-// the config file cannot be read or copied.
-func copyConfig(home, dir string) error {
+// dumpWALTail decodes the last n messages of the Tendermint node's WAL file to
+// JSON, one message per line, and writes them to file. A message which cannot
+// be decoded, like a partially written last message, ends the tail without
+// error, as it is often what is being debugged.
+func dumpWALTail(conf *cfg.Config, n int, dir, filename string) error {
+	walFile, err := os.Open(conf.Consensus.WalFile())
+	if err != nil {
+		return err
+	}
+	defer walFile.Close()
+
+	var (
+		tail      = make([][]byte, 0, n)
+		dec       = cs.NewWALDecoder(walFile)
+		decodeErr error
+	)
+	for n > 0 {
+		msg, err := dec.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			decodeErr = err
+			break
+		}
+
+		msgJSON, err := cdc.MarshalJSON(msg)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode WAL message")
+		}
+		if len(tail) == n {
+			tail = append(tail[:0], tail[1:]...)
+		}
+		tail = append(tail, msgJSON)
+	}
+
+	out := bytes.Join(tail, []byte("\n"))
+	if decodeErr != nil {
+		out = append(out, []byte(fmt.Sprintf("\nfailed to decode the next message: %v", decodeErr))...)
+	}
+
+	return ioutil.WriteFile(path.Join(dir, filename), append(out, '\n'), os.ModePerm)
+}
+
+// redactedConfigKey matches the keys of the config file whose values are
+// secrets.
+var redactedConfigKey = regexp.MustCompile(`(?i)(token|password|secret)$`)
+
+// redactConfig replaces the non-empty values of the secrets of a TOML config
+// file with "<redacted>".
+func redactConfig(config []byte) []byte {
+	lines := bytes.Split(config, []byte("\n"))
+	for i, line := range lines {
+		kv := bytes.SplitN(line, []byte("="), 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		key := strings.TrimSpace(string(kv[0]))
+		value := strings.TrimSpace(string(kv[1]))
+		if strings.HasPrefix(key, "#") || !redactedConfigKey.MatchString(key) || value == `""` {
+			continue
+		}
+		lines[i] = []byte(fmt.Sprintf(`%s= "<redacted>"`, kv[0]))
+	}
+
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// copyRedactedConfig copies the Tendermint node's config file with its secrets
+// redacted. It returns an error if the config file cannot be read or written.
+func copyRedactedConfig(home, dir string) error {
 	configFile := "config.toml"
 	configPath := filepath.Join(home, "config", configFile)
 
-	return copyFile(configPath, filepath.Join(dir, configFile))
+	config, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, configFile), redactConfig(config), 0600)
 }
 
-func dumpProfile(dir, addr, profile string, debug int) error {
+// copyLogTail copies the last n lines of the Tendermint node's log file. It
+// returns an error if the log file cannot be read or copied.
+func copyLogTail(logPath string, n int, dir string) error {
+	tail, err := tailFile(logPath, n)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, filepath.Base(logPath)), tail, os.ModePerm)
+}
+
+// dumpProfile gets a profile from the Tendermint node's profiling server,
+// authenticating with token if it isn't empty, and writes it to file.
+func dumpProfile(dir, addr, token, profile string, debug int) error {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
 	endpoint := fmt.Sprintf("%s/debug/pprof/%s?debug=%d", addr, profile, debug)
 
-	resp, err := http.Get(endpoint) // nolint: gosec
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s profile request", profile)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+	resp, err := client.Do(req)
 	if err != nil {
 		return errors.Wrapf(err, "failed to query for %s profile", profile)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to query for %s profile: %s", profile, resp.Status)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return errors.Wrapf(err, "failed to read %s profile response body", profile)
@@ -0,0 +1,58 @@
+package debug
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactConfig(t *testing.T) {
+	config := `# A token for the admin routes
+prof_auth_token = "s3cret"
+moniker = "node0"
+
+[rpc]
+admin_auth_token = ""
+laddr = "tcp://0.0.0.0:26657"
+`
+	redacted := string(redactConfig([]byte(config)))
+
+	assert.NotContains(t, redacted, "s3cret")
+	assert.Contains(t, redacted, `prof_auth_token = "<redacted>"`)
+	assert.Contains(t, redacted, `admin_auth_token = ""`)
+	assert.Contains(t, redacted, `moniker = "node0"`)
+	assert.Contains(t, redacted, `laddr = "tcp://0.0.0.0:26657"`)
+	assert.Contains(t, redacted, "# A token for the admin routes")
+}
+
+func TestTailFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "debug_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// more than a chunk, so that the file is read in several chunks
+	var lines []string
+	for i := 0; i < 10000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d with some padding to fill the chunks", i))
+	}
+	logPath := filepath.Join(dir, "node.log")
+	require.NoError(t, ioutil.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0600))
+
+	tail, err := tailFile(logPath, 3)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(lines[9997:], "\n")+"\n", string(tail))
+
+	tail, err = tailFile(logPath, 20000)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Join(lines, "\n")+"\n", string(tail))
+
+	tail, err = tailFile(logPath, 0)
+	require.NoError(t, err)
+	assert.Empty(t, tail)
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/privval"
+)
+
+var keyEncryption string
+
+func init() {
+	EncryptPrivValidatorKeyCmd.Flags().StringVar(&keyEncryption, "encryption", privval.KeyEncryptionPassphrase,
+		"Encryption of the key: passphrase or kms")
+}
+
+// EncryptPrivValidatorKeyCmd encrypts the validator key at rest.
+var EncryptPrivValidatorKeyCmd = &cobra.Command{
+	Use:   "encrypt_priv_validator_key",
+	Short: "Encrypt the validator key file",
+	Long: `encrypt_priv_validator_key encrypts the private key of the validator key file
+(priv_validator_key_file) in place, with:
+
+  passphrase  a passphrase read from priv_validator_key_passphrase_file, or
+              prompted for if it's not set
+  kms         the 32 bytes secret printed base64 encoded by
+              priv_validator_key_kms_command, e.g. a data key decrypted by a KMS
+
+The node and priv_val_server then decrypt the key on start, the same way. An
+encrypted key is decrypted first, to change its encryption. Use convert_key to
+write the key back in the clear.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keyFile := config.PrivValidatorKeyFile()
+		if !tmos.FileExists(keyFile) {
+			return errors.Errorf("%s doesn't exist", keyFile)
+		}
+		privKey, err := readPrivKeyFile(keyFile)
+		if err != nil {
+			return err
+		}
+		secret, err := newKeySecret(keyEncryption)
+		if err != nil {
+			return err
+		}
+
+		pvKey := privval.NewFilePV(privKey, keyFile, "").Key
+		if err := pvKey.Encrypt(keyEncryption, secret); err != nil {
+			return err
+		}
+		pvKey.Save()
+		logger.Info("Encrypted private validator", "keyFile", keyFile, "encryption", keyEncryption)
+		return nil
+	},
+}
+
+// newKeySecret returns the passphrase or the secret to encrypt the validator
+// key with, prompting twice for a new passphrase if there's no passphrase
+// file.
+func newKeySecret(encryption string) ([]byte, error) {
+	passphraseFile := config.PrivValidatorKeyPassphraseFile()
+	if encryption != privval.KeyEncryptionPassphrase || passphraseFile != "" {
+		return privval.NewKeySecretFunc(passphraseFile, config.PrivValidatorKeyKMSCommand)(encryption)
+	}
+	passphrase, err := privval.PromptPassphrase("New passphrase of the validator key: ")
+	if err != nil {
+		return nil, err
+	}
+	repeated, err := privval.PromptPassphrase("Repeat the passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, repeated) {
+		return nil, errors.New("the passphrases don't match")
+	}
+	return passphrase, nil
+}
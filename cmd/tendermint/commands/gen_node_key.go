@@ -9,6 +9,13 @@ import (
 	"github.com/tendermint/tendermint/p2p"
 )
 
+var nodeKeyType string
+
+func init() {
+	GenNodeKeyCmd.Flags().StringVar(&nodeKeyType, "key-type", keyTypeEd25519,
+		"Type of the key: ed25519, secp256k1 or sr25519")
+}
+
 // GenNodeKeyCmd allows the generation of a node key. It prints node's ID to
 // the standard output.
 var GenNodeKeyCmd = &cobra.Command{
@@ -23,10 +30,17 @@ func genNodeKey(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("node key at %s already exists", nodeKeyFile)
 	}
 
-	nodeKey, err := p2p.LoadOrGenNodeKey(nodeKeyFile)
+	privKey, err := genPrivKey(nodeKeyType)
 	if err != nil {
 		return err
 	}
+	nodeKey := &p2p.NodeKey{PrivKey: privKey}
+	if err := nodeKey.SaveAs(nodeKeyFile); err != nil {
+		return err
+	}
+	if jsonOutput() {
+		return printJSON(map[string]string{"id": string(nodeKey.ID()), "path": nodeKeyFile})
+	}
 	fmt.Println(nodeKey.ID())
 	return nil
 }
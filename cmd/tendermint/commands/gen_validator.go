@@ -8,20 +8,32 @@ import (
 	"github.com/tendermint/tendermint/privval"
 )
 
+var validatorKeyType string
+
+func init() {
+	GenValidatorCmd.Flags().StringVar(&validatorKeyType, "key-type", keyTypeEd25519,
+		"Type of the key: ed25519, secp256k1 or sr25519")
+}
+
 // GenValidatorCmd allows the generation of a keypair for a
 // validator.
 var GenValidatorCmd = &cobra.Command{
 	Use:   "gen_validator",
 	Short: "Generate new validator keypair",
-	Run:   genValidator,
+	RunE:  genValidator,
 }
 
-func genValidator(cmd *cobra.Command, args []string) {
-	pv := privval.GenFilePV("", "")
+func genValidator(cmd *cobra.Command, args []string) error {
+	privKey, err := genPrivKey(validatorKeyType)
+	if err != nil {
+		return err
+	}
+	pv := privval.NewFilePV(privKey, "", "")
 	jsbz, err := cdc.MarshalJSON(pv)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	fmt.Printf(`%v
 `, string(jsbz))
+	return nil
 }
@@ -0,0 +1,466 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/crypto/registry"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	genesisAppStateHash string
+	genesisOutput       string
+)
+
+func init() {
+	genesisValidateCmd.Flags().StringVar(&genesisAppStateHash, "app-state-hash", "",
+		"Expected hash of the app_state (the hex SHA256 of its compact JSON)")
+	genesisMigrateCmd.Flags().StringVarP(&genesisOutput, "output-file", "o", "",
+		"File to write the migrated genesis to (default stdout), e.g. the genesis file itself")
+
+	GenesisCmd.AddCommand(genesisValidateCmd)
+	GenesisCmd.AddCommand(genesisMigrateCmd)
+}
+
+// GenesisCmd groups the commands checking and migrating genesis files.
+var GenesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "Validate or migrate a genesis file",
+}
+
+var genesisValidateCmd = &cobra.Command{
+	Use:   "validate [genesis-file]",
+	Short: "Check a genesis file (the node's by default) against this binary",
+	Long: `validate checks a genesis file, the node's by default, as the node does when it
+starts and further: its format, chain ID, consensus params, the key types,
+addresses and voting powers of the validators, and the hash of the app_state,
+against --app-state-hash if given. Every problem found is reported.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bz, err := readGenesisFile(args)
+		if err != nil {
+			return err
+		}
+		return validateGenesis(os.Stdout, bz, genesisAppStateHash)
+	},
+}
+
+var genesisMigrateCmd = &cobra.Command{
+	Use:   "migrate [genesis-file]",
+	Short: "Rewrite a genesis file (the node's by default) of an older version",
+	Long: `migrate rewrites a genesis file of an older version of Tendermint, the node's by
+default, in the format of this binary:
+
+  app_options                  renamed app_state
+  consensus_params.block_size  renamed block (and block_size_params), with
+                               the default time_iota_ms
+  consensus_params.evidence    renamed from evidence_params, with max_age
+                               renamed max_age_num_blocks and the default
+                               max_age_duration
+  consensus_params.validator   the default if missing
+  validators                   amount renamed power; public keys with a hex
+                               "data" in the amino JSON format
+
+and numbers encoded as strings where amino expects them. The app_state is kept
+as is. The migrated genesis is validated before being written.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bz, err := readGenesisFile(args)
+		if err != nil {
+			return err
+		}
+		migrated, changes, err := migrateGenesis(bz)
+		if err != nil {
+			return err
+		}
+		if changes == nil {
+			changes = []string{}
+		}
+		if !jsonOutput() {
+			for _, change := range changes {
+				fmt.Fprintln(os.Stderr, "Migrated:", change)
+			}
+		}
+
+		genDoc := types.GenesisDoc{}
+		if err := cdc.UnmarshalJSON(migrated, &genDoc); err != nil {
+			return errors.Wrap(err, "failed to decode the migrated genesis")
+		}
+		if err := validateGenesis(ioutil.Discard, migrated, ""); err != nil {
+			return errors.Wrap(err, "the migrated genesis is invalid (run genesis validate on it)")
+		}
+
+		if genesisOutput != "" {
+			if err := genDoc.SaveAs(genesisOutput); err != nil {
+				return err
+			}
+			if jsonOutput() {
+				return printJSON(map[string]interface{}{"changes": changes, "path": genesisOutput})
+			}
+			return nil
+		}
+		if jsonOutput() {
+			genesis, err := aminoJSON(genDoc)
+			if err != nil {
+				return err
+			}
+			return printJSON(map[string]interface{}{"changes": changes, "genesis": genesis})
+		}
+		out, err := cdc.MarshalJSONIndent(genDoc, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	},
+}
+
+func readGenesisFile(args []string) ([]byte, error) {
+	path := config.GenesisFile()
+	if len(args) == 1 {
+		path = args[0]
+	}
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the genesis file")
+	}
+	return bz, nil
+}
+
+// genesisFields are the top-level fields of a genesis file.
+var genesisFields = map[string]bool{
+	"genesis_time":     true,
+	"chain_id":         true,
+	"consensus_params": true,
+	"validators":       true,
+	"app_hash":         true,
+	"app_state":        true,
+}
+
+// genesisReport is the result of the checks of a genesis file.
+type genesisReport struct {
+	Valid    bool            `json:"valid"`
+	Summary  *genesisSummary `json:"summary,omitempty"`
+	Warnings []string        `json:"warnings"`
+	Errors   []string        `json:"errors"`
+}
+
+// genesisSummary describes a genesis file which could be decoded.
+type genesisSummary struct {
+	ChainID          string    `json:"chain_id"`
+	GenesisTime      time.Time `json:"genesis_time"`
+	Validators       int       `json:"validators"`
+	TotalVotingPower int64     `json:"total_voting_power"`
+	AppStateHash     string    `json:"app_state_hash"`
+}
+
+// validateGenesis writes the checks of the genesis bz to w, as text or JSON
+// (--output), and returns an error if any of them failed.
+func validateGenesis(w io.Writer, bz []byte, appStateHash string) error {
+	report := checkGenesis(bz, appStateHash)
+	if jsonOutput() {
+		if err := writeJSON(w, report); err != nil {
+			return err
+		}
+	} else {
+		for _, warning := range report.Warnings {
+			fmt.Fprintln(w, "Warning:", warning)
+		}
+		if s := report.Summary; s != nil {
+			fmt.Fprintf(w, "Chain ID: %s\n", s.ChainID)
+			fmt.Fprintf(w, "Genesis time: %v\n", s.GenesisTime)
+			fmt.Fprintf(w, "Validators: %d (total voting power %d)\n", s.Validators, s.TotalVotingPower)
+			fmt.Fprintf(w, "App state hash: %s\n", s.AppStateHash)
+		}
+		for _, err := range report.Errors {
+			fmt.Fprintln(w, "Error:", err)
+		}
+		if report.Valid {
+			fmt.Fprintln(w, "The genesis file is valid")
+		}
+	}
+	if !report.Valid {
+		return errors.Errorf("the genesis file is invalid (%d errors)", len(report.Errors))
+	}
+	return nil
+}
+
+// checkGenesis checks the genesis bz.
+func checkGenesis(bz []byte, appStateHash string) (report genesisReport) {
+	report.Warnings, report.Errors = []string{}, []string{}
+	fail := func(format string, args ...interface{}) {
+		report.Errors = append(report.Errors, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...interface{}) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(format, args...))
+	}
+	defer func() {
+		report.Valid = len(report.Errors) == 0
+	}()
+
+	if _, changes, err := migrateGenesis(bz); err != nil {
+		fail("%v", err)
+		return
+	} else if len(changes) > 0 {
+		fail("the genesis file is in the format of an older version (%s), run genesis migrate",
+			strings.Join(changes, "; "))
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		fail("invalid JSON: %v", err)
+		return
+	}
+	for field := range fields {
+		if !genesisFields[field] {
+			warn("unknown field %q is ignored", field)
+		}
+	}
+
+	genDoc := types.GenesisDoc{}
+	if err := cdc.UnmarshalJSON(bz, &genDoc); err != nil {
+		fail("failed to decode the genesis: %v", err)
+		return
+	}
+	genesisTime := genDoc.GenesisTime
+	if genesisTime.IsZero() {
+		warn("genesis_time is missing: every node would use the time it first starts at")
+	}
+	if genDoc.ConsensusParams == nil {
+		warn("consensus_params is missing: the defaults are used")
+	}
+	if err := genDoc.ValidateAndComplete(); err != nil {
+		fail("%v", err)
+	}
+	params := genDoc.ConsensusParams
+	if params == nil {
+		params = types.DefaultConsensusParams()
+	}
+
+	// the node panics on these when creating the validator set
+	var totalPower int64
+	addresses := make(map[string]int)
+	for i, v := range genDoc.Validators {
+		if v.PubKey == nil {
+			fail("validator %d (%s) has no pub_key", i, v.Name)
+			continue
+		}
+		keyType, err := types.ABCIPubKeyType(v.PubKey)
+		if err != nil {
+			fail("validator %d (%s): %v", i, v.Name, err)
+		} else if !params.Validator.IsValidPubkeyType(keyType) {
+			fail("the %s key of validator %d (%s) isn't allowed by consensus_params.validator.pub_key_types %v",
+				keyType, i, v.Name, params.Validator.PubKeyTypes)
+		}
+		address := v.PubKey.Address().String()
+		if j, ok := addresses[address]; ok {
+			fail("validators %d and %d have the same key (address %s)", j, i, address)
+		}
+		addresses[address] = i
+		if v.Power < 0 {
+			fail("validator %d (%s) has a negative voting power %d", i, v.Name, v.Power)
+			continue
+		}
+		totalPower += v.Power
+		if totalPower > types.MaxTotalVotingPower || totalPower < 0 {
+			fail("the total voting power of the validators exceeds %d", types.MaxTotalVotingPower)
+			break
+		}
+	}
+	if len(genDoc.Validators) == 0 {
+		warn("no validators: the application must return them from InitChain")
+	}
+
+	hash, err := hashAppState(genDoc.AppState)
+	if err != nil {
+		fail("invalid app_state: %v", err)
+	} else if appStateHash != "" && !strings.EqualFold(hash, appStateHash) {
+		fail("the hash of the app_state is %s, expected %s", hash, appStateHash)
+	}
+
+	report.Summary = &genesisSummary{
+		ChainID:          genDoc.ChainID,
+		GenesisTime:      genesisTime,
+		Validators:       len(genDoc.Validators),
+		TotalVotingPower: totalPower,
+		AppStateHash:     hash,
+	}
+	return
+}
+
+// hashAppState returns the hex SHA256 of the compact JSON of appState, so
+// that it doesn't depend on its formatting.
+func hashAppState(appState json.RawMessage) (string, error) {
+	buf := new(bytes.Buffer)
+	if len(appState) > 0 {
+		if err := json.Compact(buf, appState); err != nil {
+			return "", err
+		}
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return strings.ToUpper(hex.EncodeToString(sum[:])), nil
+}
+
+// migrateGenesis rewrites the genesis bz of an older version in the current
+// format. It returns the migrated genesis and the changes made, if any.
+func migrateGenesis(bz []byte) ([]byte, []string, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &doc); err != nil {
+		return nil, nil, errors.Wrap(err, "invalid JSON")
+	}
+	var changes []string
+	changed := func(format string, args ...interface{}) {
+		changes = append(changes, fmt.Sprintf(format, args...))
+	}
+
+	if appOptions, ok := doc["app_options"]; ok {
+		if _, ok := doc["app_state"]; !ok {
+			doc["app_state"] = appOptions
+			changed("renamed app_options to app_state")
+		}
+		delete(doc, "app_options")
+	}
+
+	if raw, ok := doc["consensus_params"]; ok && !isJSONNull(raw) {
+		var params map[string]interface{}
+		if err := decodeJSON(raw, &params); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid consensus_params")
+		}
+		migrateConsensusParams(params, changed)
+		if err := encodeJSON(doc, "consensus_params", params); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if raw, ok := doc["validators"]; ok && !isJSONNull(raw) {
+		var vals []map[string]interface{}
+		if err := decodeJSON(raw, &vals); err != nil {
+			return nil, nil, errors.Wrap(err, "invalid validators")
+		}
+		for i, val := range vals {
+			if err := migrateValidator(val, i, changed); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := encodeJSON(doc, "validators", vals); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	migrated, err := json.Marshal(doc)
+	return migrated, changes, err
+}
+
+func migrateConsensusParams(params map[string]interface{}, changed func(string, ...interface{})) {
+	renameField(params, "block_size_params", "block", "consensus_params.", changed)
+	renameField(params, "block_size", "block", "consensus_params.", changed)
+	renameField(params, "evidence_params", "evidence", "consensus_params.", changed)
+
+	defaults := types.DefaultConsensusParams()
+	if block, ok := params["block"].(map[string]interface{}); ok {
+		if _, ok := block["time_iota_ms"]; !ok {
+			block["time_iota_ms"] = fmt.Sprint(defaults.Block.TimeIotaMs)
+			changed("added consensus_params.block.time_iota_ms")
+		}
+		stringifyInts(block, "consensus_params.block.", changed, "max_bytes", "max_gas", "time_iota_ms")
+	}
+	if evidence, ok := params["evidence"].(map[string]interface{}); ok {
+		renameField(evidence, "max_age", "max_age_num_blocks", "consensus_params.evidence.", changed)
+		if _, ok := evidence["max_age_duration"]; !ok {
+			evidence["max_age_duration"] = fmt.Sprint(int64(defaults.Evidence.MaxAgeDuration))
+			changed("added consensus_params.evidence.max_age_duration")
+		}
+		stringifyInts(evidence, "consensus_params.evidence.", changed, "max_age_num_blocks", "max_age_duration")
+	}
+	if _, ok := params["validator"]; !ok {
+		params["validator"] = map[string]interface{}{"pub_key_types": defaults.Validator.PubKeyTypes}
+		changed("added consensus_params.validator")
+	}
+}
+
+func migrateValidator(val map[string]interface{}, i int, changed func(string, ...interface{})) error {
+	prefix := fmt.Sprintf("validators[%d].", i)
+	renameField(val, "amount", "power", prefix, changed)
+	stringifyInts(val, prefix, changed, "power")
+
+	// e.g. {"type": "ed25519", "data": "<hex>"}
+	pubKey, ok := val["pub_key"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	data, ok := pubKey["data"].(string)
+	if !ok {
+		return nil
+	}
+	keyType, _ := pubKey["type"].(string)
+	kt, ok := registry.Get(keyType)
+	if !ok {
+		return errors.Errorf("unknown type %q of %spub_key", keyType, prefix)
+	}
+	key, err := hex.DecodeString(data)
+	if err != nil {
+		return errors.Wrapf(err, "invalid %spub_key", prefix)
+	}
+	val["pub_key"] = map[string]interface{}{
+		"type":  kt.PubKeyAminoName,
+		"value": base64.StdEncoding.EncodeToString(key),
+	}
+	changed("converted %spub_key to the amino JSON format", prefix)
+	return nil
+}
+
+func renameField(m map[string]interface{}, from, to, prefix string, changed func(string, ...interface{})) {
+	v, ok := m[from]
+	if !ok {
+		return
+	}
+	delete(m, from)
+	if _, ok := m[to]; !ok {
+		m[to] = v
+		changed("renamed %s%s to %s", prefix, from, to)
+	}
+}
+
+// stringifyInts encodes the given number fields of m as strings, as amino
+// does for 64-bit integers.
+func stringifyInts(m map[string]interface{}, prefix string, changed func(string, ...interface{}), fields ...string) {
+	for _, field := range fields {
+		if n, ok := m[field].(json.Number); ok {
+			m[field] = n.String()
+			changed("encoded %s%s as a string", prefix, field)
+		}
+	}
+}
+
+func decodeJSON(raw json.RawMessage, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func encodeJSON(doc map[string]json.RawMessage, field string, v interface{}) error {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	doc[field] = bz
+	return nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestMigrateGenesis(t *testing.T) {
+	pubKey := ed25519.GenPrivKey().PubKey().(ed25519.PubKeyEd25519)
+	old := fmt.Sprintf(`{
+  "genesis_time": "2018-10-10T08:20:13.695936996Z",
+  "chain_id": "old-chain",
+  "consensus_params": {
+    "block_size_params": {"max_bytes": 22020096, "max_gas": -1},
+    "evidence_params": {"max_age": 100000}
+  },
+  "validators": [{"pub_key": {"type": "ed25519", "data": "%X"}, "amount": 10, "name": "val0"}],
+  "app_options": {"accounts": [{"name": "a", "coins": 10}]}
+}`, pubKey[:])
+
+	assert.Error(t, validateGenesis(ioutil.Discard, []byte(old), ""))
+
+	migrated, changes, err := migrateGenesis([]byte(old))
+	require.NoError(t, err)
+	assert.Contains(t, changes, "renamed app_options to app_state")
+	assert.Contains(t, changes, "converted validators[0].pub_key to the amino JSON format")
+	require.NoError(t, validateGenesis(ioutil.Discard, migrated, ""))
+
+	genDoc, err := types.GenesisDocFromJSON(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, pubKey, genDoc.Validators[0].PubKey)
+	assert.EqualValues(t, 10, genDoc.Validators[0].Power)
+	assert.EqualValues(t, 100000, genDoc.ConsensusParams.Evidence.MaxAgeNumBlocks)
+	assert.Equal(t, types.DefaultEvidenceParams().MaxAgeDuration, genDoc.ConsensusParams.Evidence.MaxAgeDuration)
+	assert.JSONEq(t, `{"accounts": [{"name": "a", "coins": 10}]}`, string(genDoc.AppState))
+
+	// migrating again changes nothing
+	_, changes, err = migrateGenesis(migrated)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestValidateGenesis(t *testing.T) {
+	pubKey := ed25519.GenPrivKey().PubKey()
+	genDoc := types.GenesisDoc{
+		ChainID: "test-chain",
+		Validators: []types.GenesisValidator{
+			{PubKey: pubKey, Power: 10},
+			{PubKey: pubKey, Power: 5},
+			{PubKey: secp256k1.GenPrivKey().PubKey(), Power: -1},
+		},
+		AppState: []byte(`{"a": 1}`),
+	}
+	bz, err := cdc.MarshalJSON(genDoc)
+	require.NoError(t, err)
+	hash, err := hashAppState([]byte("{\n  \"a\": 1\n}"))
+	require.NoError(t, err)
+
+	w := new(bytes.Buffer)
+	assert.EqualError(t, validateGenesis(w, bz, hash), "the genesis file is invalid (4 errors)")
+	for _, s := range []string{
+		"validators 0 and 1 have the same key",
+		"the secp256k1 key of validator 2 () isn't allowed",
+		"validator 2 () has a negative voting power -1",
+		"Warning: genesis_time is missing",
+	} {
+		assert.Contains(t, w.String(), s)
+	}
+
+	genDoc.Validators = genDoc.Validators[:1]
+	bz, err = cdc.MarshalJSON(genDoc)
+	require.NoError(t, err)
+	assert.NoError(t, validateGenesis(ioutil.Discard, bz, hash))
+	assert.Error(t, validateGenesis(ioutil.Discard, bz, "00"))
+}
@@ -1,8 +1,12 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	cfg "github.com/tendermint/tendermint/config"
 	tmos "github.com/tendermint/tendermint/libs/os"
@@ -13,15 +17,82 @@ import (
 	tmtime "github.com/tendermint/tendermint/types/time"
 )
 
+var (
+	initChainID             string
+	initGenesisTime         string
+	initValidatorPower      int64
+	initValidatorsFile      string
+	initConsensusParamsFile string
+	initAppStateFile        string
+	initKeyType             string
+)
+
+func init() {
+	InitFilesCmd.Flags().StringVar(&initChainID, "chain-id", "",
+		"Chain ID of the genesis file (default \"test-chain-\" followed by random characters)")
+	InitFilesCmd.Flags().StringVar(&initGenesisTime, "genesis-time", "",
+		"Genesis time in RFC3339 format (default now)")
+	InitFilesCmd.Flags().Int64Var(&initValidatorPower, "validator-power", 10,
+		"Voting power of the local validator (0 leaves it out of the genesis validators)")
+	InitFilesCmd.Flags().StringVar(&initValidatorsFile, "validators", "",
+		"JSON file with additional genesis validators, in the same format as the validators of genesis.json")
+	InitFilesCmd.Flags().StringVar(&initConsensusParamsFile, "consensus-params", "",
+		"JSON file with consensus params, in the same format as the consensus_params of genesis.json;"+
+			" missing sections are set to their defaults")
+	InitFilesCmd.Flags().StringVar(&initAppStateFile, "app-state", "",
+		"JSON file with the app_state of the genesis file")
+	InitFilesCmd.Flags().StringVar(&initKeyType, "key-type", keyTypeEd25519,
+		"Type of the validator key: ed25519, secp256k1 or sr25519; the genesis validators are"+
+			" restricted to this type unless set by --consensus-params")
+}
+
 // InitFilesCmd initialises a fresh Tendermint Core instance.
 var InitFilesCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize Tendermint",
-	RunE:  initFiles,
+	Long: `Initialize the private validator, node key and genesis file of a
+Tendermint node. Existing files are left untouched.
+
+The genesis file can be populated with the given flags, e.g.
+
+tendermint init --chain-id devnet --validators vals.json --app-state app_state.json
+
+The validator key is of the --key-type type, e.g. for a chain with secp256k1
+validator keys:
+
+tendermint init --key-type secp256k1`,
+	RunE: initFiles,
 }
 
 func initFiles(cmd *cobra.Command, args []string) error {
-	return initFilesWithConfig(config)
+	if err := initFilesWithConfig(config); err != nil {
+		return err
+	}
+	if !jsonOutput() {
+		return nil
+	}
+
+	nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
+	if err != nil {
+		return err
+	}
+	pubKey := privval.LoadFilePVEmptyState(config.PrivValidatorKeyFile(), "").GetPubKey()
+	pubKeyJSON, err := aminoJSON(pubKey)
+	if err != nil {
+		return err
+	}
+	return printJSON(struct {
+		NodeID             p2p.ID          `json:"node_id"`
+		ValidatorAddress   string          `json:"validator_address"`
+		ValidatorPubKey    json.RawMessage `json:"validator_pub_key"`
+		PrivValidatorKey   string          `json:"priv_validator_key_file"`
+		PrivValidatorState string          `json:"priv_validator_state_file"`
+		NodeKey            string          `json:"node_key_file"`
+		Genesis            string          `json:"genesis_file"`
+	}{
+		nodeKey.ID(), pubKey.Address().String(), pubKeyJSON,
+		config.PrivValidatorKeyFile(), config.PrivValidatorStateFile(), config.NodeKeyFile(), config.GenesisFile(),
+	})
 }
 
 func initFilesWithConfig(config *cfg.Config) error {
@@ -34,7 +105,11 @@ func initFilesWithConfig(config *cfg.Config) error {
 		logger.Info("Found private validator", "keyFile", privValKeyFile,
 			"stateFile", privValStateFile)
 	} else {
-		pv = privval.GenFilePV(privValKeyFile, privValStateFile)
+		privKey, err := genPrivKey(initKeyType)
+		if err != nil {
+			return err
+		}
+		pv = privval.NewFilePV(privKey, privValKeyFile, privValStateFile)
 		pv.Save()
 		logger.Info("Generated private validator", "keyFile", privValKeyFile,
 			"stateFile", privValStateFile)
@@ -55,23 +130,123 @@ func initFilesWithConfig(config *cfg.Config) error {
 	if tmos.FileExists(genFile) {
 		logger.Info("Found genesis file", "path", genFile)
 	} else {
-		genDoc := types.GenesisDoc{
-			ChainID:         fmt.Sprintf("test-chain-%v", tmrand.Str(6)),
-			GenesisTime:     tmtime.Now(),
-			ConsensusParams: types.DefaultConsensusParams(),
+		genDoc, err := makeGenesisDoc(pv)
+		if err != nil {
+			return err
+		}
+
+		if err := genDoc.SaveAs(genFile); err != nil {
+			return err
+		}
+		logger.Info("Generated genesis file", "path", genFile)
+	}
+
+	return nil
+}
+
+// makeGenesisDoc creates the genesis doc of a new chain from the init flags,
+// including pv as a validator unless the validator power is 0.
+func makeGenesisDoc(pv *privval.FilePV) (*types.GenesisDoc, error) {
+	genDoc := &types.GenesisDoc{
+		ChainID:         initChainID,
+		ConsensusParams: types.DefaultConsensusParams(),
+	}
+	genDoc.ConsensusParams.Validator.PubKeyTypes = []string{initKeyType}
+	if genDoc.ChainID == "" {
+		genDoc.ChainID = fmt.Sprintf("test-chain-%v", tmrand.Str(6))
+	}
+
+	if initGenesisTime == "" {
+		genDoc.GenesisTime = tmtime.Now()
+	} else {
+		t, err := time.Parse(time.RFC3339Nano, initGenesisTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid --genesis-time")
 		}
+		genDoc.GenesisTime = t.UTC()
+	}
+
+	switch {
+	case initValidatorPower < 0:
+		return nil, errors.New("--validator-power can't be negative")
+	case initValidatorPower > 0:
 		key := pv.GetPubKey()
 		genDoc.Validators = []types.GenesisValidator{{
 			Address: key.Address(),
 			PubKey:  key,
-			Power:   10,
+			Power:   initValidatorPower,
 		}}
+	}
 
-		if err := genDoc.SaveAs(genFile); err != nil {
-			return err
+	if initValidatorsFile != "" {
+		bz, err := ioutil.ReadFile(initValidatorsFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read --validators file")
 		}
-		logger.Info("Generated genesis file", "path", genFile)
+		var vals []types.GenesisValidator
+		if err := cdc.UnmarshalJSON(bz, &vals); err != nil {
+			return nil, errors.Wrap(err, "failed to decode --validators file")
+		}
+		genDoc.Validators = append(genDoc.Validators, vals...)
+	}
+	if len(genDoc.Validators) == 0 {
+		return nil, errors.New("the genesis file would have no validators; set --validator-power or --validators")
 	}
 
+	if initConsensusParamsFile != "" {
+		bz, err := ioutil.ReadFile(initConsensusParamsFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read --consensus-params file")
+		}
+		if err := decodeConsensusParams(bz, genDoc.ConsensusParams); err != nil {
+			return nil, errors.Wrap(err, "failed to decode --consensus-params file")
+		}
+	}
+
+	if initAppStateFile != "" {
+		bz, err := ioutil.ReadFile(initAppStateFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read --app-state file")
+		}
+		if !json.Valid(bz) {
+			return nil, errors.New("--app-state file is not valid JSON")
+		}
+		genDoc.AppState = bz
+	}
+
+	if err := genDoc.ValidateAndComplete(); err != nil {
+		return nil, errors.Wrap(err, "invalid genesis")
+	}
+	return genDoc, nil
+}
+
+// decodeConsensusParams decodes the sections (block, evidence, validator,
+// synchrony, abci) of
+// the consensus params present in bz into params, keeping the other sections.
+func decodeConsensusParams(bz []byte, params *types.ConsensusParams) error {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(bz, &sections); err != nil {
+		return err
+	}
+	for name, section := range sections {
+		var err error
+		switch name {
+		case "block":
+			err = cdc.UnmarshalJSON(section, &params.Block)
+		case "evidence":
+			err = cdc.UnmarshalJSON(section, &params.Evidence)
+		case "validator":
+			err = cdc.UnmarshalJSON(section, &params.Validator)
+		case "synchrony":
+			err = cdc.UnmarshalJSON(section, &params.Synchrony)
+		case "abci":
+			err = cdc.UnmarshalJSON(section, &params.ABCI)
+		default:
+			err = errors.New("unknown section")
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s", name)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestMakeGenesisDoc(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pv := privval.GenFilePV(filepath.Join(dir, "key.json"), filepath.Join(dir, "state.json"))
+	otherKey := ed25519.GenPrivKey().PubKey()
+
+	writeFile := func(name string, v interface{}) string {
+		path := filepath.Join(dir, name)
+		var bz []byte
+		if s, ok := v.(string); ok {
+			bz = []byte(s)
+		} else {
+			bz = cdc.MustMarshalJSON(v)
+		}
+		require.NoError(t, ioutil.WriteFile(path, bz, 0600))
+		return path
+	}
+
+	initChainID = "devnet"
+	initGenesisTime = "2020-01-02T03:04:05Z"
+	initValidatorPower = 5
+	initValidatorsFile = writeFile("vals.json", []types.GenesisValidator{{PubKey: otherKey, Power: 7, Name: "other"}})
+	initConsensusParamsFile = writeFile("params.json", `{"block": {"max_bytes": "1000", "max_gas": "-1", "time_iota_ms": "1000"}}`)
+	initAppStateFile = writeFile("app_state.json", `{"accounts": []}`)
+	defer func() {
+		initChainID, initGenesisTime, initValidatorsFile, initConsensusParamsFile, initAppStateFile = "", "", "", "", ""
+		initValidatorPower = 10
+	}()
+
+	genDoc, err := makeGenesisDoc(pv)
+	require.NoError(t, err)
+	assert.Equal(t, "devnet", genDoc.ChainID)
+	assert.Equal(t, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), genDoc.GenesisTime)
+	require.Len(t, genDoc.Validators, 2)
+	assert.Equal(t, pv.GetPubKey(), genDoc.Validators[0].PubKey)
+	assert.EqualValues(t, 5, genDoc.Validators[0].Power)
+	assert.Equal(t, otherKey.Address(), genDoc.Validators[1].Address)
+	assert.EqualValues(t, 1000, genDoc.ConsensusParams.Block.MaxBytes)
+	assert.Equal(t, types.DefaultEvidenceParams(), genDoc.ConsensusParams.Evidence)
+	assert.JSONEq(t, `{"accounts": []}`, string(genDoc.AppState))
+
+	// the local validator can be left out, but there must be some validator
+	initValidatorPower = 0
+	genDoc, err = makeGenesisDoc(pv)
+	require.NoError(t, err)
+	assert.Len(t, genDoc.Validators, 1)
+	initValidatorsFile = ""
+	_, err = makeGenesisDoc(pv)
+	assert.Error(t, err)
+	initValidatorPower = 5
+
+	initAppStateFile = writeFile("bad_app_state.json", `{"accounts": [`)
+	_, err = makeGenesisDoc(pv)
+	assert.Error(t, err)
+}
+
+func TestInitFilesKeyType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	conf := cfg.DefaultConfig()
+	conf.SetRoot(dir)
+	cfg.EnsureRoot(dir)
+
+	initKeyType = keyTypeSecp256k1
+	defer func() { initKeyType = keyTypeEd25519 }()
+	require.NoError(t, initFilesWithConfig(conf))
+
+	pubKey := privval.LoadFilePVEmptyState(conf.PrivValidatorKeyFile(), "").GetPubKey()
+	assert.IsType(t, secp256k1.PubKeySecp256k1{}, pubKey)
+
+	genDoc, err := types.GenesisDocFromFile(conf.GenesisFile())
+	require.NoError(t, err)
+	require.Len(t, genDoc.Validators, 1)
+	assert.Equal(t, pubKey, genDoc.Validators[0].PubKey)
+	assert.Equal(t, []string{types.ABCIPubKeyTypeSecp256k1}, genDoc.ConsensusParams.Validator.PubKeyTypes)
+
+	// unknown key type
+	conf.SetRoot(filepath.Join(dir, "rsa"))
+	cfg.EnsureRoot(conf.RootDir)
+	initKeyType = "rsa"
+	assert.Error(t, initFilesWithConfig(conf))
+}
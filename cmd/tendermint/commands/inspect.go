@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	amino "github.com/tendermint/go-amino"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	rpccore "github.com/tendermint/tendermint/rpc/core"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpcserver "github.com/tendermint/tendermint/rpc/lib/server"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/state/txindex"
+	"github.com/tendermint/tendermint/state/txindex/kv"
+	"github.com/tendermint/tendermint/state/txindex/null"
+	"github.com/tendermint/tendermint/store"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	inspectListenAddr string
+
+	// state DB of the stopped node, once opened
+	inspectedStateDB dbm.DB
+	// environment serving the RPC calls from the stores of the stopped node
+	inspectedEnv *rpccore.Environment
+)
+
+func init() {
+	InspectCmd.Flags().StringVar(&inspectListenAddr, "laddr", "",
+		"Address to serve the RPC on (defaults to rpc.laddr)")
+
+	InspectCmd.AddCommand(inspectStatusCmd)
+	InspectCmd.AddCommand(inspectStateCmd)
+	InspectCmd.AddCommand(inspectBlockCmd)
+	InspectCmd.AddCommand(inspectBlockResultsCmd)
+	InspectCmd.AddCommand(inspectValidatorsCmd)
+}
+
+// InspectCmd serves a limited RPC from the data directory of a stopped node.
+var InspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Serve a read-only RPC from the data directory of a stopped node",
+	Long: `inspect opens the data directory of a stopped node read-only, e.g. of a node
+which won't start, and serves the RPC routes which only need its stores: status
+(the latest blocks of the block store and of the state), blockchain, genesis,
+block, block_by_hash, block_results, commit, tx, tx_search, validators and
+consensus_params.
+
+The subcommands print the same data as JSON instead.
+
+Only the goleveldb backend can be opened read-only: the DBs of other backends
+are opened as usual, they must not be written to.`,
+	Args: cobra.NoArgs,
+	RunE: inspect,
+}
+
+var inspectStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the latest blocks of the block store and of the state",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printInspected(func() (interface{}, error) {
+			return inspectedEnv.InspectStatus(&rpctypes.Context{})
+		})
+	},
+}
+
+var inspectStateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Print the state",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printInspected(func() (interface{}, error) {
+			return sm.LoadState(inspectedStateDB), nil
+		})
+	},
+}
+
+var inspectBlockCmd = &cobra.Command{
+	Use:   "block [height]",
+	Short: "Print the block at the given height (the latest by default)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printInspectedAtHeight(args, func(height *int64) (interface{}, error) {
+			return inspectedEnv.Block(&rpctypes.Context{}, height)
+		})
+	},
+}
+
+var inspectBlockResultsCmd = &cobra.Command{
+	Use:   "block-results [height]",
+	Short: "Print the results of the block at the given height (the latest by default)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printInspectedAtHeight(args, func(height *int64) (interface{}, error) {
+			return inspectedEnv.BlockResults(&rpctypes.Context{}, height)
+		})
+	},
+}
+
+var inspectValidatorsCmd = &cobra.Command{
+	Use:   "validators [height]",
+	Short: "Print the validators at the given height (the next height by default)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return printInspectedAtHeight(args, func(height *int64) (interface{}, error) {
+			return inspectedEnv.Validators(&rpctypes.Context{}, height, 0, 0)
+		})
+	},
+}
+
+func inspect(cmd *cobra.Command, args []string) error {
+	closeStores, err := openInspectedStores(logger)
+	if err != nil {
+		return err
+	}
+	defer closeStores()
+
+	laddr := inspectListenAddr
+	if laddr == "" {
+		laddr = config.RPC.ListenAddress
+	}
+
+	rpcConfig := rpcserver.DefaultConfig()
+	rpcConfig.MaxBodyBytes = config.RPC.MaxBodyBytes
+	rpcConfig.MaxHeaderBytes = config.RPC.MaxHeaderBytes
+	rpcConfig.MaxOpenConnections = config.RPC.MaxOpenConnections
+	listener, err := rpcserver.Listen(laddr, rpcConfig)
+	if err != nil {
+		return err
+	}
+
+	rpcLogger := logger.With("module", "rpc-server")
+	mux := http.NewServeMux()
+	rpcserver.RegisterRPCFuncs(mux, inspectedEnv.InspectRoutes(), inspectCodec(), rpcLogger)
+
+	tmos.TrapSignal(logger, func() {
+		listener.Close()
+	})
+
+	logger.Info("Serving the RPC of the stopped node", "laddr", laddr, "home", config.RootDir)
+	if err := rpcserver.StartHTTPServer(listener, mux, rpcLogger, rpcConfig); err != nil &&
+		err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// printInspected prints the result of fn, run with the stores of the stopped
+// node, as JSON. The logs go to stderr, so that the output can be piped.
+func printInspected(fn func() (interface{}, error)) error {
+	closeStores, err := openInspectedStores(log.NewTMLogger(log.NewSyncWriter(os.Stderr)))
+	if err != nil {
+		return err
+	}
+	defer closeStores()
+
+	result, err := fn()
+	if err != nil {
+		return err
+	}
+	bz, err := inspectCodec().MarshalJSONIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(bz))
+	return nil
+}
+
+func printInspectedAtHeight(args []string, fn func(height *int64) (interface{}, error)) error {
+	var height *int64
+	if len(args) == 1 {
+		h, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid height")
+		}
+		height = &h
+	}
+	return printInspected(func() (interface{}, error) { return fn(height) })
+}
+
+func inspectCodec() *amino.Codec {
+	cdc := amino.NewCodec()
+	ctypes.RegisterAmino(cdc)
+	return cdc
+}
+
+// openInspectedStores opens the stores of the stopped node and sets them as
+// the environment of the RPC routes. It returns a function closing them.
+func openInspectedStores(logger log.Logger) (func(), error) {
+	blockStoreDB, err := openDBReadOnly("blockstore", logger)
+	if err != nil {
+		return nil, err
+	}
+	stateDB, err := openDBReadOnly("state", logger)
+	if err != nil {
+		blockStoreDB.Close()
+		return nil, err
+	}
+	closers := []dbm.DB{blockStoreDB, stateDB}
+	closeStores := func() {
+		for _, db := range closers {
+			db.Close()
+		}
+	}
+
+	// the tx and block_search routes are served by the kv indexer only
+	kvIndexer := false
+	for _, indexer := range config.TxIndex.Indexers() {
+		kvIndexer = kvIndexer || indexer == "kv"
+	}
+	var (
+		txIndexer    txindex.TxIndexer    = &null.TxIndex{}
+		blockIndexer txindex.BlockIndexer = &null.BlockIndex{}
+	)
+	if kvIndexer {
+		txIndexDB, err := openDBReadOnly("tx_index", logger)
+		if err != nil {
+			logger.Error("Failed to open the tx index, the tx routes are disabled", "err", err)
+		} else {
+			closers = append(closers, txIndexDB)
+			txIndexer = kv.NewTxIndex(txIndexDB)
+			blockIndexer = kv.NewBlockIndex(txIndexDB)
+		}
+	}
+
+	genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+	if err != nil {
+		logger.Error("Failed to load the genesis file", "err", err)
+		genDoc = nil
+	}
+
+	inspectedStateDB = stateDB
+	blockStore := store.NewBlockStore(blockStoreDB)
+	inspectedEnv = &rpccore.Environment{
+		StateDB:      stateDB,
+		BlockStore:   blockStore,
+		Consensus:    storeConsensus{stateDB: stateDB, blockStore: blockStore},
+		TxIndexer:    txIndexer,
+		BlockIndexer: blockIndexer,
+		GenDoc:       genDoc,
+		Logger:       logger.With("module", "rpc"),
+	}
+	inspectedEnv.SetConfig(*config.RPC)
+
+	return closeStores, nil
+}
+
+// openDBReadOnly opens the named DB of the data directory read-only, if its
+// backend supports it.
+func openDBReadOnly(name string, logger log.Logger) (dbm.DB, error) {
+	dir := config.DBDir()
+	backend := dbm.BackendType(config.DBBackend)
+	if backend != dbm.GoLevelDBBackend {
+		logger.Info("The DB backend can't be opened read-only, opening it as usual",
+			"db", name, "backend", backend)
+		return dbm.NewDB(name, backend, dir), nil
+	}
+
+	// don't create a missing DB
+	if _, err := os.Stat(filepath.Join(dir, name+".db")); err != nil {
+		return nil, errors.Wrapf(err, "failed to open the %s DB", name)
+	}
+	db, err := dbm.NewGoLevelDBWithOpts(name, dir, &opt.Options{ReadOnly: true})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open the %s DB (is the node stopped?)", name)
+	}
+	return db, nil
+}
+
+// storeConsensus implements rpccore.Consensus for a stopped node, with the
+// state of its state DB.
+type storeConsensus struct {
+	stateDB    dbm.DB
+	blockStore *store.BlockStore
+}
+
+var errNoConsensus = errors.New("the consensus state of a stopped node is not available")
+
+func (c storeConsensus) GetState() sm.State {
+	return sm.LoadState(c.stateDB)
+}
+
+func (c storeConsensus) GetValidators() (int64, []*types.Validator) {
+	state := c.GetState()
+	if state.Validators == nil {
+		return state.LastBlockHeight, nil
+	}
+	return state.LastBlockHeight, state.Validators.Validators
+}
+
+func (c storeConsensus) GetLastHeight() int64 {
+	return c.blockStore.Height()
+}
+
+func (c storeConsensus) GetRoundStateJSON() ([]byte, error) {
+	return nil, errNoConsensus
+}
+
+func (c storeConsensus) GetRoundStateSimpleJSON() ([]byte, error) {
+	return nil, errNoConsensus
+}
+
+func (c storeConsensus) GetValidatorStatsJSON() ([]byte, error) {
+	return nil, errNoConsensus
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/privval"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestInspectStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inspect_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(c *cfg.Config) { config = c }(config)
+	config = cfg.DefaultConfig()
+	config.SetRoot(dir)
+	cfg.EnsureRoot(dir)
+
+	// the stores of a stopped node, at genesis
+	pv := privval.GenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile())
+	genDoc := &types.GenesisDoc{
+		ChainID: "inspect-chain",
+		Validators: []types.GenesisValidator{{
+			Address: pv.GetPubKey().Address(),
+			PubKey:  pv.GetPubKey(),
+			Power:   10,
+		}},
+	}
+	require.NoError(t, genDoc.ValidateAndComplete())
+	require.NoError(t, genDoc.SaveAs(config.GenesisFile()))
+	state, err := sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+
+	stateDB := dbm.NewDB("state", dbm.GoLevelDBBackend, config.DBDir())
+	sm.SaveState(stateDB, state)
+	stateDB.Close()
+
+	// the block store is missing, and isn't created
+	_, err = openInspectedStores(log.TestingLogger())
+	require.Error(t, err)
+	dbm.NewDB("blockstore", dbm.GoLevelDBBackend, config.DBDir()).Close()
+
+	closeStores, err := openInspectedStores(log.TestingLogger())
+	require.NoError(t, err)
+	defer closeStores()
+
+	status, err := inspectedEnv.InspectStatus(&rpctypes.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, "inspect-chain", status.ChainID)
+	assert.EqualValues(t, 0, status.LatestBlockHeight)
+	assert.EqualValues(t, 0, status.StateHeight)
+
+	vals, err := inspectedEnv.Validators(&rpctypes.Context{}, nil, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, vals.Validators, 1)
+	assert.EqualValues(t, 10, vals.Validators[0].VotingPower)
+
+	genesis, err := inspectedEnv.Genesis(&rpctypes.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, "inspect-chain", genesis.Genesis.ChainID)
+
+	// the DBs are opened read-only
+	assert.Error(t, inspectedStateDB.(*dbm.GoLevelDB).DB().Put([]byte("key"), []byte("value"), nil))
+}
@@ -0,0 +1,326 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/armor"
+	"github.com/tendermint/tendermint/crypto/registry"
+	"github.com/tendermint/tendermint/libs/bech32"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+)
+
+// Types of the built-in keys; other types can be added to the registry.
+const (
+	keyTypeEd25519   = registry.KeyTypeEd25519
+	keyTypeSecp256k1 = registry.KeyTypeSecp256k1
+	keyTypeSr25519   = registry.KeyTypeSr25519
+)
+
+// Encodings of the addresses and public keys.
+const (
+	encodingHex    = "hex"
+	encodingBase64 = "base64"
+	encodingBech32 = "bech32"
+	encodingJSON   = "json"
+)
+
+// File formats of the private keys.
+const (
+	keyFormatNodeKey          = "node_key"
+	keyFormatPrivValidatorKey = "priv_validator_key"
+	keyFormatArmor            = "armor"
+)
+
+// armorBlockType is the type of the armored private keys.
+const armorBlockType = "TENDERMINT PRIVATE KEY"
+
+var (
+	showNodeKey         bool
+	keyEncoding         string
+	addressBech32Prefix string
+	pubKeyBech32Prefix  string
+	keyFormat           string
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{ShowAddressCmd, ShowPubKeyCmd} {
+		cmd.Flags().BoolVar(&showNodeKey, "node", false, "Show the node key's instead of the validator key's")
+		cmd.Flags().StringVar(&keyEncoding, "encoding", "",
+			"Encoding: hex, base64, bech32 or json (pubkey only); all of them by default")
+	}
+	ShowAddressCmd.Flags().StringVar(&addressBech32Prefix, "bech32-prefix", "tm", "Prefix of the bech32 encoding")
+	ShowPubKeyCmd.Flags().StringVar(&pubKeyBech32Prefix, "bech32-prefix", "tmpub", "Prefix of the bech32 encoding")
+
+	ConvertKeyCmd.Flags().StringVar(&keyFormat, "to", keyFormatPrivValidatorKey,
+		"Format of the output file: node_key, priv_validator_key or armor")
+}
+
+// ShowAddressCmd shows the address of this node's validator or node key.
+var ShowAddressCmd = &cobra.Command{
+	Use:     "show_address",
+	Aliases: []string{"show-address"},
+	Short:   "Show the address of this node's validator key (or node key)",
+	Long: `show_address shows the address of this node's validator key, or of its node
+key (e.g. its ID, in hex), in the given encoding or all of them.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pubKey, err := loadShownPubKey()
+		if err != nil {
+			return err
+		}
+		return printEncodings(os.Stdout, pubKey.Address(), nil, keyEncoding, addressBech32Prefix)
+	},
+}
+
+// ShowPubKeyCmd shows the public key of this node's validator or node key.
+var ShowPubKeyCmd = &cobra.Command{
+	Use:     "show_pubkey",
+	Aliases: []string{"show-pubkey"},
+	Short:   "Show the public key of this node's validator key (or node key)",
+	Long: `show_pubkey shows the public key of this node's validator key, or of its node
+key, in the given encoding or all of them.
+
+The hex and base64 encodings are of the key bytes, as the value of the json
+(amino) encoding used by the genesis file; the bech32 encoding is of the amino
+encoding of the key, as in the Cosmos SDK.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pubKey, err := loadShownPubKey()
+		if err != nil {
+			return err
+		}
+		return printEncodings(os.Stdout, pubKeyBytes(pubKey), pubKey, keyEncoding, pubKeyBech32Prefix)
+	},
+}
+
+// ConvertKeyCmd converts a private key file to another format.
+var ConvertKeyCmd = &cobra.Command{
+	Use:     "convert_key <input-file> <output-file>",
+	Aliases: []string{"convert-key"},
+	Short:   "Convert a private key file to another format",
+	Long: `convert_key reads the private key of a node key file, of a validator key file
+(priv_validator_key.json or the priv_validator.json of versions before v0.28) or
+of an armored key, and writes it to a new file in the given format:
+
+  node_key            a node key file (node_key.json)
+  priv_validator_key  a validator key file (priv_validator_key.json)
+  armor               an ASCII armored key
+
+The validator state (priv_validator_state.json) isn't converted: keep the one
+of the validator, or the validator may double sign.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		in, out := args[0], args[1]
+		if tmos.FileExists(out) {
+			return errors.Errorf("%s already exists", out)
+		}
+		privKey, err := readPrivKeyFile(in)
+		if err != nil {
+			return err
+		}
+		return writePrivKeyFile(out, privKey, keyFormat)
+	},
+}
+
+// genPrivKey generates a private key of the given type.
+func genPrivKey(keyType string) (crypto.PrivKey, error) {
+	if kt, ok := registry.Get(keyType); ok && kt.GenPrivKey != nil {
+		return kt.GenPrivKey(), nil
+	}
+	var keyTypes []string
+	for _, name := range registry.Names() {
+		if kt, _ := registry.Get(name); kt.GenPrivKey != nil {
+			keyTypes = append(keyTypes, name)
+		}
+	}
+	return nil, errors.Errorf("unknown key type %q, expected one of %s", keyType, strings.Join(keyTypes, ", "))
+}
+
+// privKeyType returns the type of privKey, as given to genPrivKey.
+func privKeyType(privKey crypto.PrivKey) string {
+	if kt, ok := registry.ByPrivKey(privKey); ok {
+		return kt.Name
+	}
+	return fmt.Sprintf("%T", privKey)
+}
+
+// loadShownPubKey loads the public key of the validator key file or, if
+// --node, of the node key file.
+func loadShownPubKey() (crypto.PubKey, error) {
+	if showNodeKey {
+		nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
+		if err != nil {
+			return nil, err
+		}
+		return nodeKey.PubKey(), nil
+	}
+
+	keyFilePath := config.PrivValidatorKeyFile()
+	if !tmos.FileExists(keyFilePath) {
+		return nil, fmt.Errorf("private validator file %s does not exist", keyFilePath)
+	}
+	return privval.LoadFilePVEmptyState(keyFilePath, "").GetPubKey(), nil
+}
+
+// pubKeyBytes returns the bytes of pubKey, without their amino encoding.
+func pubKeyBytes(pubKey crypto.PubKey) []byte {
+	if kt, ok := registry.ByPubKey(pubKey); ok {
+		return kt.PubKeyBytes(pubKey)
+	}
+	return pubKey.Bytes()
+}
+
+// printEncodings prints bz (an address, or the bytes of pubKey if not nil) in
+// the given encoding or, if empty, in all of them, one per line.
+func printEncodings(w io.Writer, bz []byte, pubKey crypto.PubKey, encoding, prefix string) error {
+	encodings := []string{encodingHex, encodingBase64, encodingBech32}
+	if pubKey != nil {
+		encodings = append(encodings, encodingJSON)
+	}
+	if encoding != "" {
+		encodings = []string{encoding}
+	}
+	if jsonOutput() {
+		out := make(map[string]interface{}, len(encodings))
+		for _, encoding := range encodings {
+			s, err := encode(bz, pubKey, encoding, prefix)
+			if err != nil {
+				return err
+			}
+			if encoding == encodingJSON {
+				out[encoding] = json.RawMessage(s)
+			} else {
+				out[encoding] = s
+			}
+		}
+		return writeJSON(w, out)
+	}
+	if encoding != "" {
+		s, err := encode(bz, pubKey, encoding, prefix)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, s)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, encoding := range encodings {
+		s, err := encode(bz, pubKey, encoding, prefix)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", encoding, s)
+	}
+	return tw.Flush()
+}
+
+func encode(bz []byte, pubKey crypto.PubKey, encoding, prefix string) (string, error) {
+	switch encoding {
+	case encodingHex:
+		return strings.ToUpper(hex.EncodeToString(bz)), nil
+	case encodingBase64:
+		return base64.StdEncoding.EncodeToString(bz), nil
+	case encodingBech32:
+		if pubKey != nil {
+			bz = pubKey.Bytes()
+		}
+		return bech32.ConvertAndEncode(prefix, bz)
+	case encodingJSON:
+		if pubKey == nil {
+			break
+		}
+		jsonBytes, err := cdc.MarshalJSON(pubKey)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to marshal the pubkey")
+		}
+		return string(jsonBytes), nil
+	}
+	return "", errors.Errorf("unknown encoding %q", encoding)
+}
+
+// readPrivKeyFile reads the private key of a node key file, a validator key
+// file (of any version, decrypting it if it's encrypted), or an armored key.
+func readPrivKeyFile(path string) (crypto.PrivKey, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var privKey crypto.PrivKey
+	if bytes.HasPrefix(bytes.TrimSpace(bz), []byte("-----BEGIN")) {
+		blockType, _, data, err := armor.DecodeArmor(string(bz))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the armored key %s", path)
+		}
+		if blockType != armorBlockType {
+			return nil, errors.Errorf("unexpected armor type %q of %s, expected %q", blockType, path, armorBlockType)
+		}
+		if err := cdc.UnmarshalBinaryBare(data, &privKey); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the armored key %s", path)
+		}
+		return privKey, nil
+	}
+
+	// node keys and validator keys all have a priv_key, unless the validator
+	// key is encrypted
+	var keyFile struct {
+		PubKey           crypto.PubKey             `json:"pub_key"`
+		PrivKey          crypto.PrivKey            `json:"priv_key"`
+		EncryptedPrivKey *privval.EncryptedPrivKey `json:"encrypted_priv_key"`
+	}
+	if err := cdc.UnmarshalJSON(bz, &keyFile); err != nil {
+		return nil, errors.Wrapf(err, "failed to read the key file %s", path)
+	}
+	if keyFile.EncryptedPrivKey != nil {
+		pvKey := privval.FilePVKey{PubKey: keyFile.PubKey, EncryptedPrivKey: keyFile.EncryptedPrivKey}
+		secret, err := privval.NewKeySecretFunc(config.PrivValidatorKeyPassphraseFile(),
+			config.PrivValidatorKeyKMSCommand)(keyFile.EncryptedPrivKey.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		if err := pvKey.Decrypt(secret); err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt the key file %s", path)
+		}
+		return pvKey.PrivKey, nil
+	}
+	if keyFile.PrivKey == nil {
+		return nil, errors.Errorf("no priv_key in %s", path)
+	}
+	return keyFile.PrivKey, nil
+}
+
+// writePrivKeyFile writes privKey to a new file at path, in the given format.
+func writePrivKeyFile(path string, privKey crypto.PrivKey, format string) error {
+	switch format {
+	case keyFormatNodeKey:
+		return (&p2p.NodeKey{PrivKey: privKey}).SaveAs(path)
+	case keyFormatPrivValidatorKey:
+		privval.NewFilePV(privKey, path, "").Key.Save()
+		return nil
+	case keyFormatArmor:
+		data, err := cdc.MarshalBinaryBare(privKey)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode the key")
+		}
+		armored := armor.EncodeArmor(armorBlockType, map[string]string{"type": privKeyType(privKey)}, data)
+		return ioutil.WriteFile(path, []byte(armored+"\n"), 0600)
+	default:
+		return errors.Errorf("unknown key file format %q", format)
+	}
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/bech32"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/privval"
+)
+
+func TestGenPrivKey(t *testing.T) {
+	for _, keyType := range []string{keyTypeEd25519, keyTypeSecp256k1, keyTypeSr25519} {
+		privKey, err := genPrivKey(keyType)
+		require.NoError(t, err)
+		assert.Equal(t, keyType, privKeyType(privKey))
+	}
+	_, err := genPrivKey("rsa")
+	assert.Error(t, err)
+}
+
+func TestPrintEncodings(t *testing.T) {
+	pubKey := ed25519.GenPrivKey().PubKey()
+
+	w := new(bytes.Buffer)
+	require.NoError(t, printEncodings(w, pubKey.Address(), nil, encodingBech32, "tmvalcons"))
+	hrp, bz, err := bech32.DecodeAndConvert(strings.TrimSpace(w.String()))
+	require.NoError(t, err)
+	assert.Equal(t, "tmvalcons", hrp)
+	assert.EqualValues(t, pubKey.Address(), bz)
+
+	w.Reset()
+	require.NoError(t, printEncodings(w, pubKeyBytes(pubKey), pubKey, "", "tmpub"))
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[3], `"type":"tendermint/PubKeyEd25519"`)
+
+	// the address has no json encoding
+	assert.Error(t, printEncodings(w, pubKey.Address(), nil, encodingJSON, "tm"))
+}
+
+func TestConvertKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	privKey := ed25519.GenPrivKey()
+	nodeKeyFile := filepath.Join(dir, "node_key.json")
+	require.NoError(t, (&p2p.NodeKey{PrivKey: privKey}).SaveAs(nodeKeyFile))
+
+	// node key -> armor -> validator key -> node key
+	in := nodeKeyFile
+	for _, format := range []string{keyFormatArmor, keyFormatPrivValidatorKey, keyFormatNodeKey} {
+		out := filepath.Join(dir, format)
+		read, err := readPrivKeyFile(in)
+		require.NoError(t, err, format)
+		require.NoError(t, writePrivKeyFile(out, read, format))
+		in = out
+	}
+	pv := privval.LoadFilePVEmptyState(filepath.Join(dir, keyFormatPrivValidatorKey), "")
+	assert.Equal(t, privKey.PubKey(), pv.GetPubKey())
+	nodeKey, err := p2p.LoadNodeKey(in)
+	require.NoError(t, err)
+	assert.Equal(t, privKey, nodeKey.PrivKey)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "empty.json"), []byte("{}"), 0600))
+	_, err = readPrivKeyFile(filepath.Join(dir, "empty.json"))
+	assert.Error(t, err)
+}
+
+func TestReadEncryptedPrivKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keys_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	passphraseFile := filepath.Join(dir, "passphrase")
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("passphrase"), 0600))
+	defer func(passphrase string) { config.PrivValidatorKeyPassphrase = passphrase }(config.PrivValidatorKeyPassphrase)
+	config.PrivValidatorKeyPassphrase = passphraseFile
+
+	keyFile := filepath.Join(dir, "priv_validator_key.json")
+	pv := privval.GenFilePV(keyFile, "")
+	secret, err := newKeySecret(privval.KeyEncryptionPassphrase)
+	require.NoError(t, err)
+	require.NoError(t, pv.Key.Encrypt(privval.KeyEncryptionPassphrase, secret))
+	pv.Key.Save()
+
+	privKey, err := readPrivKeyFile(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, pv.Key.PrivKey, privKey)
+
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("wrong"), 0600))
+	_, err = readPrivKeyFile(keyFile)
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
+	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Broadcast methods, i.e. the RPC routes the transactions are sent with.
+const (
+	BroadcastTxAsync  = "async"
+	BroadcastTxSync   = "sync"
+	BroadcastTxCommit = "commit"
+)
+
+// txHeaderSize is the size of the header making every transaction unique:
+// the start time of the load test, the connection and the transaction number.
+const txHeaderSize = 24
+
+// Config configures a load test.
+type Config struct {
+	// RPC addresses of the nodes the transactions are sent to.
+	Endpoints []string
+	// Number of connections per endpoint, each sending transactions in turn.
+	Connections int
+	// Number of transactions per second, over all the connections.
+	Rate int
+	// Size of a transaction in bytes, at least 24.
+	Size int
+	// How long the transactions are sent for.
+	Duration time.Duration
+	// Broadcast method: async, sync or commit.
+	BroadcastMethod string
+	// Timeout of a request.
+	Timeout time.Duration
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg Config) ValidateBasic() error {
+	if len(cfg.Endpoints) == 0 {
+		return errors.New("no endpoints")
+	}
+	if cfg.Connections <= 0 {
+		return errors.New("connections must be positive")
+	}
+	if cfg.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	if cfg.Rate < cfg.Connections*len(cfg.Endpoints) {
+		return errors.New("rate must be at least one transaction per second per connection")
+	}
+	if cfg.Size < txHeaderSize {
+		return errors.Errorf("size must be at least %d bytes", txHeaderSize)
+	}
+	if cfg.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	switch cfg.BroadcastMethod {
+	case BroadcastTxAsync, BroadcastTxSync, BroadcastTxCommit:
+	default:
+		return errors.Errorf("unknown broadcast method %q", cfg.BroadcastMethod)
+	}
+	return nil
+}
+
+// result is the outcome of sending a transaction.
+type result int
+
+const (
+	// accepted by the mempool (and committed, with the commit method)
+	resultAccepted result = iota
+	// rejected by CheckTx or by the mempool, e.g. when it is full
+	resultRejected
+	// not sent, or sent without an answer in time
+	resultFailed
+)
+
+// broadcastFunc sends a transaction and returns the code of its CheckTx.
+type broadcastFunc func(tx types.Tx) (code uint32, err error)
+
+// outcome classifies the outcome of sending a transaction. Errors returned by
+// the node mean the transaction was rejected, except for timeouts, which mean
+// the node didn't answer in time, like transport errors.
+func outcome(code uint32, err error) result {
+	if err == nil {
+		if code != 0 {
+			return resultRejected
+		}
+		return resultAccepted
+	}
+	var rpcErr *rpctypes.RPCError
+	if errors.As(err, &rpcErr) && !tmerrors.HasCode(err, tmerrors.CodeTimeout) {
+		return resultRejected
+	}
+	return resultFailed
+}
+
+// newBroadcastFunc returns a function sending transactions to the endpoint with
+// the given broadcast method, over its own connection.
+func newBroadcastFunc(endpoint, method string, timeout time.Duration) (broadcastFunc, error) {
+	seconds := uint(timeout / time.Second)
+	if method == BroadcastTxCommit {
+		// broadcast_tx_commit waits for the commit until a timeout set by the node
+		seconds = 0
+	}
+	client, err := rpcclient.NewHTTPWithTimeout(endpoint, "/websocket", seconds)
+	if err != nil {
+		return nil, err
+	}
+
+	var broadcast func(types.Tx) (*ctypes.ResultBroadcastTx, error)
+	switch method {
+	case BroadcastTxAsync:
+		broadcast = client.BroadcastTxAsync
+	case BroadcastTxSync:
+		broadcast = client.BroadcastTxSync
+	case BroadcastTxCommit:
+		return func(tx types.Tx) (uint32, error) {
+			res, err := client.BroadcastTxCommit(tx)
+			if err != nil {
+				return 0, err
+			}
+			return res.CheckTx.Code, nil
+		}, nil
+	default:
+		return nil, errors.Errorf("unknown broadcast method %q", method)
+	}
+	return func(tx types.Tx) (uint32, error) {
+		res, err := broadcast(tx)
+		if err != nil {
+			return 0, err
+		}
+		return res.Code, nil
+	}, nil
+}
+
+// txGenerator generates unique transactions of a given size: a header with the
+// start time, the connection and the transaction number, padded with random
+// bytes.
+type txGenerator struct {
+	start uint64
+	conn  uint64
+	size  int
+	n     uint64
+}
+
+func (g *txGenerator) next() types.Tx {
+	tx := make([]byte, g.size)
+	binary.BigEndian.PutUint64(tx[0:8], g.start)
+	binary.BigEndian.PutUint64(tx[8:16], g.conn)
+	binary.BigEndian.PutUint64(tx[16:24], g.n)
+	copy(tx[txHeaderSize:], tmrand.Bytes(g.size-txHeaderSize))
+	g.n++
+	return tx
+}
+
+// runLoad sends transactions with the broadcast functions, one goroutine per
+// function, at rate transactions per second over all of them, until ctx is
+// done. It records the outcome and latency of every transaction in stats.
+func runLoad(ctx context.Context, broadcasts []broadcastFunc, rate, size int, stats *Stats, logger log.Logger) {
+	var (
+		start    = uint64(time.Now().UnixNano())
+		interval = time.Duration(len(broadcasts)) * time.Second / time.Duration(rate)
+		wg       sync.WaitGroup
+	)
+	for i, broadcast := range broadcasts {
+		wg.Add(1)
+		go func(i int, broadcast broadcastFunc) {
+			defer wg.Done()
+
+			gen := &txGenerator{start: start, conn: uint64(i), size: size}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+
+				sent := time.Now()
+				code, err := broadcast(gen.next())
+				res := outcome(code, err)
+				if res == resultFailed {
+					logger.Debug("Failed to send tx", "conn", i, "err", err)
+				}
+				stats.record(res, time.Since(sent))
+			}
+		}(i, broadcast)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,168 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+var (
+	cfg = Config{
+		Endpoints:       []string{"tcp://localhost:26657"},
+		Connections:     1,
+		Rate:            1000,
+		Size:            250,
+		Duration:        10 * time.Second,
+		BroadcastMethod: BroadcastTxAsync,
+		Timeout:         10 * time.Second,
+	}
+	outputFormat string
+
+	logger = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+)
+
+// LoadtestCmd generates transaction load against running nodes and reports the
+// throughput and latencies.
+var LoadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Generate transaction load against running Tendermint nodes",
+	Long: `Generate transaction load against running Tendermint nodes: send unique
+transactions of a given size, at a given rate over a number of connections
+per endpoint, for a given duration. Then report the rate of the transactions
+sent, the latency percentiles of the broadcast requests, the fraction of
+transactions rejected by the mempools, and the throughput of the transactions
+committed, based on the blocks of the first endpoint.
+
+Example:
+$ tendermint loadtest --endpoints=tcp://node0:26657,tcp://node1:26657 --rate=2000 --connections=4 --duration=1m`,
+	Args: cobra.NoArgs,
+	RunE: loadtestCmdHandler,
+}
+
+func init() {
+	LoadtestCmd.Flags().StringSliceVar(&cfg.Endpoints, "endpoints", cfg.Endpoints,
+		"Comma-separated RPC addresses of the nodes to send transactions to")
+	LoadtestCmd.Flags().IntVar(&cfg.Connections, "connections", cfg.Connections,
+		"Number of connections per endpoint")
+	LoadtestCmd.Flags().IntVar(&cfg.Rate, "rate", cfg.Rate,
+		"Number of transactions per second, over all connections")
+	LoadtestCmd.Flags().IntVar(&cfg.Size, "size", cfg.Size,
+		"Size of a transaction in bytes")
+	LoadtestCmd.Flags().DurationVar(&cfg.Duration, "duration", cfg.Duration,
+		"How long to send transactions for")
+	LoadtestCmd.Flags().StringVar(&cfg.BroadcastMethod, "broadcast-tx-method", cfg.BroadcastMethod,
+		"Broadcast method: async (fire and forget), sync (wait for CheckTx) or commit (wait for the commit)")
+	LoadtestCmd.Flags().DurationVar(&cfg.Timeout, "timeout", cfg.Timeout,
+		"Timeout of a request (the timeout of commit requests is set by the nodes)")
+	LoadtestCmd.Flags().StringVar(&outputFormat, "output-format", "plain",
+		"Output format of the report: plain or json")
+}
+
+func loadtestCmdHandler(_ *cobra.Command, _ []string) error {
+	if outputFormat != "plain" && outputFormat != "json" {
+		return errors.Errorf("unknown output format %q", outputFormat)
+	}
+
+	report, err := Run(context.Background(), cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	if outputFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.Write(os.Stdout)
+}
+
+// Run runs a load test and returns its report. It returns early if ctx is
+// done.
+func Run(ctx context.Context, cfg Config, logger log.Logger) (Report, error) {
+	if err := cfg.ValidateBasic(); err != nil {
+		return Report{}, err
+	}
+
+	var broadcasts []broadcastFunc
+	for _, endpoint := range cfg.Endpoints {
+		for i := 0; i < cfg.Connections; i++ {
+			broadcast, err := newBroadcastFunc(endpoint, cfg.BroadcastMethod, cfg.Timeout)
+			if err != nil {
+				return Report{}, errors.Wrapf(err, "failed to connect to %s", endpoint)
+			}
+			broadcasts = append(broadcasts, broadcast)
+		}
+	}
+
+	client, err := rpcclient.NewHTTPWithTimeout(cfg.Endpoints[0], "/websocket", uint(cfg.Timeout/time.Second))
+	if err != nil {
+		return Report{}, errors.Wrapf(err, "failed to connect to %s", cfg.Endpoints[0])
+	}
+	startHeight, err := latestHeight(client)
+	if err != nil {
+		return Report{}, err
+	}
+
+	logger.Info("Starting load test",
+		"endpoints", len(cfg.Endpoints), "connections", len(broadcasts),
+		"rate", cfg.Rate, "size", cfg.Size, "duration", cfg.Duration, "method", cfg.BroadcastMethod)
+
+	var (
+		stats        = &Stats{}
+		start        = time.Now()
+		loadCtx, cxl = context.WithTimeout(ctx, cfg.Duration)
+	)
+	runLoad(loadCtx, broadcasts, cfg.Rate, cfg.Size, stats, logger)
+	cxl()
+	report := stats.report(time.Since(start))
+
+	endHeight, err := latestHeight(client)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Blocks, report.CommittedTxs, err = committedTxs(client, startHeight+1, endHeight)
+	if err != nil {
+		return Report{}, err
+	}
+	if report.Duration > 0 {
+		report.CommittedTxRate = float64(report.CommittedTxs) / report.Duration.Seconds()
+	}
+
+	return report, nil
+}
+
+func latestHeight(client *rpcclient.HTTP) (int64, error) {
+	status, err := client.Status()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get node status")
+	}
+	return status.SyncInfo.LatestBlockHeight, nil
+}
+
+// committedTxs returns the number of blocks from minHeight to maxHeight and of
+// the transactions they contain.
+func committedTxs(client *rpcclient.HTTP, minHeight, maxHeight int64) (blocks, txs int64, err error) {
+	// blockchain_info returns at most 20 blocks, from the highest
+	for maxHeight >= minHeight {
+		info, err := client.BlockchainInfo(minHeight, maxHeight)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "failed to get blockchain info")
+		}
+		if len(info.BlockMetas) == 0 {
+			break
+		}
+		for _, meta := range info.BlockMetas {
+			blocks++
+			txs += int64(meta.NumTxs)
+		}
+		maxHeight = info.BlockMetas[len(info.BlockMetas)-1].Header.Height - 1
+	}
+	return blocks, txs, nil
+}
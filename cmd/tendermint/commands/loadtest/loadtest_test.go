@@ -0,0 +1,140 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	rpctest "github.com/tendermint/tendermint/rpc/test"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestMain(m *testing.M) {
+	node := rpctest.StartTendermint(kvstore.NewApplication())
+	code := m.Run()
+	rpctest.StopTendermint(node)
+	os.Exit(code)
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 1; i <= 100; i++ {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, percentile(durations, 50))
+	assert.Equal(t, 99*time.Millisecond, percentile(durations, 99))
+	assert.Equal(t, 100*time.Millisecond, percentile(durations, 100))
+	assert.Equal(t, time.Duration(0), percentile(nil, 50))
+	assert.Equal(t, time.Second, percentile([]time.Duration{time.Second}, 1))
+}
+
+func TestOutcome(t *testing.T) {
+	assert.Equal(t, resultAccepted, outcome(0, nil))
+	assert.Equal(t, resultRejected, outcome(1, nil))
+	mempoolFull := &rpctypes.RPCError{Code: -32603, Message: "Internal error", Data: "mempool is full"}
+	assert.Equal(t, resultRejected, outcome(0, errors.Wrap(mempoolFull, "broadcast_tx_sync")))
+	timeout := rpctypes.RPCErrorFromError(
+		rpctypes.JSONRPCStringID(""), tmerrors.New(tmerrors.CodeTimeout, "timed out")).Error
+	assert.Equal(t, resultFailed, outcome(0, errors.Wrap(timeout, "broadcast_tx_commit")))
+	assert.Equal(t, resultFailed, outcome(0, errors.New("connection refused")))
+}
+
+func TestRunLoad(t *testing.T) {
+	var (
+		mtx  sync.Mutex
+		seen = make(map[string]bool)
+		n    int
+	)
+	broadcast := func(tx types.Tx) (uint32, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		assert.Len(t, tx, 100)
+		assert.False(t, seen[string(tx[:txHeaderSize])], "duplicate tx")
+		seen[string(tx[:txHeaderSize])] = true
+		n++
+		switch n % 4 {
+		case 0:
+			return 1, nil
+		case 1:
+			return 0, errors.New("connection refused")
+		}
+		return 0, nil
+	}
+
+	stats := &Stats{}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	runLoad(ctx, []broadcastFunc{broadcast, broadcast}, 200, 100, stats, log.TestingLogger())
+
+	report := stats.report(500 * time.Millisecond)
+	assert.Equal(t, n, report.Sent)
+	assert.InDelta(t, 100, report.Sent, 40)
+	assert.InDelta(t, report.Sent/2, report.Accepted, 1)
+	assert.InDelta(t, report.Sent/4, report.Rejected, 1)
+	assert.InDelta(t, report.Sent/4, report.Failed, 1)
+	assert.InDelta(t, 0.25, report.RejectionRate, 0.02)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	assert.Contains(t, buf.String(), "Txs rejected")
+}
+
+func TestRun(t *testing.T) {
+	for _, method := range []string{BroadcastTxAsync, BroadcastTxSync} {
+		method := method
+		t.Run(method, func(t *testing.T) {
+			report, err := Run(context.Background(), Config{
+				Endpoints:       []string{rpctest.GetConfig().RPC.ListenAddress},
+				Connections:     2,
+				Rate:            50,
+				Size:            64,
+				Duration:        2 * time.Second,
+				BroadcastMethod: method,
+				Timeout:         5 * time.Second,
+			}, log.TestingLogger())
+			require.NoError(t, err)
+
+			assert.True(t, report.Sent > 0)
+			assert.Equal(t, report.Sent, report.Accepted)
+			assert.Zero(t, report.Failed)
+			assert.True(t, report.Latencies.P50 > 0)
+			assert.True(t, report.Latencies.P50 <= report.Latencies.Max)
+		})
+	}
+}
+
+func TestConfigValidateBasic(t *testing.T) {
+	valid := Config{
+		Endpoints:       []string{"tcp://localhost:26657"},
+		Connections:     1,
+		Rate:            10,
+		Size:            txHeaderSize,
+		Duration:        time.Second,
+		BroadcastMethod: BroadcastTxCommit,
+	}
+	assert.NoError(t, valid.ValidateBasic())
+
+	for name, modify := range map[string]func(*Config){
+		"no endpoints":   func(c *Config) { c.Endpoints = nil },
+		"no connections": func(c *Config) { c.Connections = 0 },
+		"low rate":       func(c *Config) { c.Connections, c.Rate = 20, 10 },
+		"small size":     func(c *Config) { c.Size = txHeaderSize - 1 },
+		"no duration":    func(c *Config) { c.Duration = 0 },
+		"unknown method": func(c *Config) { c.BroadcastMethod = "fast" },
+	} {
+		c := valid
+		modify(&c)
+		assert.Error(t, c.ValidateBasic(), name)
+	}
+}
@@ -0,0 +1,126 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats records the outcomes and latencies of the transactions sent during a
+// load test. It is safe for concurrent use.
+type Stats struct {
+	mtx       sync.Mutex
+	accepted  int
+	rejected  int
+	failed    int
+	latencies []time.Duration
+}
+
+func (s *Stats) record(res result, latency time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	switch res {
+	case resultAccepted:
+		s.accepted++
+	case resultRejected:
+		s.rejected++
+	default:
+		s.failed++
+	}
+	// the latency of failed transactions is mostly the timeout
+	if res != resultFailed {
+		s.latencies = append(s.latencies, latency)
+	}
+}
+
+// Latencies are the percentiles of the latencies of the requests answered by
+// the nodes.
+type Latencies struct {
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+	Max time.Duration `json:"max"`
+}
+
+// Report is the result of a load test.
+type Report struct {
+	Duration time.Duration `json:"duration"`
+
+	// Number of transactions sent, and by outcome.
+	Sent     int `json:"sent"`
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+	Failed   int `json:"failed"`
+
+	// Transactions sent per second.
+	SendRate float64 `json:"send_rate"`
+	// Fraction of the transactions sent which were rejected.
+	RejectionRate float64   `json:"rejection_rate"`
+	Latencies     Latencies `json:"latencies"`
+
+	// Blocks committed during the load test, and the transactions (of the load
+	// test or not) they contain per second.
+	Blocks          int64   `json:"blocks"`
+	CommittedTxs    int64   `json:"committed_txs"`
+	CommittedTxRate float64 `json:"committed_tx_rate"`
+}
+
+// report returns the report of a load test which lasted d.
+func (s *Stats) report(d time.Duration) Report {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	r := Report{
+		Duration: d,
+		Sent:     s.accepted + s.rejected + s.failed,
+		Accepted: s.accepted,
+		Rejected: s.rejected,
+		Failed:   s.failed,
+	}
+	if d > 0 {
+		r.SendRate = float64(r.Sent) / d.Seconds()
+	}
+	if r.Sent > 0 {
+		r.RejectionRate = float64(r.Rejected) / float64(r.Sent)
+	}
+
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	r.Latencies = Latencies{
+		P50: percentile(s.latencies, 50),
+		P90: percentile(s.latencies, 90),
+		P99: percentile(s.latencies, 99),
+		Max: percentile(s.latencies, 100),
+	}
+	return r
+}
+
+// percentile returns the p-th percentile of the sorted durations, with the
+// nearest-rank method, or 0 if there are none.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Write writes the report as a table.
+func (r Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Duration\t%v\n", r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Txs sent\t%d (%.1f/s)\n", r.Sent, r.SendRate)
+	fmt.Fprintf(tw, "Txs accepted\t%d\n", r.Accepted)
+	fmt.Fprintf(tw, "Txs rejected\t%d (%.2f%%)\n", r.Rejected, 100*r.RejectionRate)
+	fmt.Fprintf(tw, "Txs failed\t%d\n", r.Failed)
+	fmt.Fprintf(tw, "Latency p50/p90/p99/max\t%v / %v / %v / %v\n",
+		r.Latencies.P50, r.Latencies.P90, r.Latencies.P99, r.Latencies.Max)
+	fmt.Fprintf(tw, "Blocks committed\t%d\n", r.Blocks)
+	fmt.Fprintf(tw, "Txs committed\t%d (%.1f/s)\n", r.CommittedTxs, r.CommittedTxRate)
+	return tw.Flush()
+}
@@ -0,0 +1,144 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// DefaultPagerDutyURL is the URL of the PagerDuty Events API v2.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Severities of the alerts.
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert is fired when a threshold is crossed, and again, resolved, when it
+// isn't anymore.
+type Alert struct {
+	// Key identifies the condition, e.g. "block_lag/tcp://node0:26657".
+	Key      string    `json:"key"`
+	Summary  string    `json:"summary"`
+	Severity string    `json:"severity"`
+	Resolved bool      `json:"resolved"`
+	Time     time.Time `json:"time"`
+}
+
+func (a Alert) String() string {
+	if a.Resolved {
+		return "[RESOLVED] " + a.Summary
+	}
+	return fmt.Sprintf("[%s] %s", a.Severity, a.Summary)
+}
+
+// Hook is notified of the alerts.
+type Hook interface {
+	Fire(Alert) error
+}
+
+// LogHook logs the alerts.
+type LogHook struct {
+	Logger log.Logger
+}
+
+// Fire implements Hook.
+func (h LogHook) Fire(a Alert) error {
+	if a.Resolved {
+		h.Logger.Info("Alert resolved", "key", a.Key, "summary", a.Summary)
+	} else {
+		h.Logger.Error("Alert", "key", a.Key, "severity", a.Severity, "summary", a.Summary)
+	}
+	return nil
+}
+
+// SlackHook posts the alerts to a Slack incoming webhook, or to any webhook
+// accepting the same JSON ({"text": "..."}).
+type SlackHook struct {
+	URL    string
+	client *http.Client
+}
+
+// NewSlackHook returns a SlackHook posting to url.
+func NewSlackHook(url string, timeout time.Duration) *SlackHook {
+	return &SlackHook{URL: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Fire implements Hook.
+func (h *SlackHook) Fire(a Alert) error {
+	return postJSON(h.client, h.URL, map[string]string{"text": a.String()})
+}
+
+// PagerDutyHook triggers and resolves PagerDuty incidents with the Events API
+// v2, deduplicated by the key of the alerts.
+type PagerDutyHook struct {
+	URL        string
+	RoutingKey string
+	client     *http.Client
+}
+
+// NewPagerDutyHook returns a PagerDutyHook of the service with the given
+// routing key.
+func NewPagerDutyHook(url, routingKey string, timeout time.Duration) *PagerDutyHook {
+	return &PagerDutyHook{URL: url, RoutingKey: routingKey, client: &http.Client{Timeout: timeout}}
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary   string `json:"summary"`
+	Source    string `json:"source"`
+	Severity  string `json:"severity"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Fire implements Hook.
+func (h *PagerDutyHook) Fire(a Alert) error {
+	event := pagerDutyEvent{
+		RoutingKey:  h.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    a.Key,
+	}
+	if a.Resolved {
+		event.EventAction = "resolve"
+	} else {
+		event.Payload = &pagerDutyPayload{
+			Summary:   a.Summary,
+			Source:    "tendermint-monitor",
+			Severity:  a.Severity,
+			Timestamp: a.Time.UTC().Format(time.RFC3339),
+		}
+	}
+	return postJSON(h.client, h.URL, event)
+}
+
+func postJSON(client *http.Client, url string, v interface{}) error {
+	bz, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return errors.Errorf("webhook returned %s: %s", resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package monitor
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
+)
+
+var (
+	cfg = Config{
+		Endpoints:         []string{"tcp://localhost:26657"},
+		Interval:          5 * time.Second,
+		Timeout:           5 * time.Second,
+		MaxBlockLag:       10,
+		MinPeers:          1,
+		MissedVotesWindow: 100,
+		MaxMissedVotes:    10,
+		HaltTimeout:       time.Minute,
+	}
+	slackWebhooks       []string
+	pagerDutyRoutingKey string
+	pagerDutyURL        string
+
+	logger = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+)
+
+// MonitorCmd watches running nodes and fires alerts when they fall behind,
+// lose their peers, or when validators miss votes.
+var MonitorCmd = &cobra.Command{
+	Use:   "monitor",
+	Short: "Watch running Tendermint nodes and alert on lagging nodes and missed votes",
+	Long: `Watch the RPC endpoints of running Tendermint nodes: the height of each
+node, its number of peers, and the precommits of every validator in the last
+blocks. Alerts are fired, and resolved, when:
+
+- a node is unreachable;
+- a node is more than --max-block-lag blocks behind the highest node;
+- a node has fewer than --min-peers peers;
+- a validator missed more than --max-missed-votes precommits in the last
+  --missed-votes-window blocks;
+- no node committed a block for --halt-timeout.
+
+The alerts are logged, and posted to Slack incoming webhooks (--slack-webhook)
+and to PagerDuty (--pagerduty-routing-key).
+
+Example:
+$ tendermint monitor --endpoints=tcp://node0:26657,tcp://node1:26657 --slack-webhook=https://hooks.slack.com/services/...`,
+	Args: cobra.NoArgs,
+	RunE: monitorCmdHandler,
+}
+
+func init() {
+	MonitorCmd.Flags().StringSliceVar(&cfg.Endpoints, "endpoints", cfg.Endpoints,
+		"Comma-separated RPC addresses of the nodes to watch")
+	MonitorCmd.Flags().DurationVar(&cfg.Interval, "interval", cfg.Interval,
+		"How often to poll the nodes")
+	MonitorCmd.Flags().DurationVar(&cfg.Timeout, "timeout", cfg.Timeout,
+		"Timeout of a request")
+	MonitorCmd.Flags().Int64Var(&cfg.MaxBlockLag, "max-block-lag", cfg.MaxBlockLag,
+		"Number of blocks a node can be behind the highest node")
+	MonitorCmd.Flags().IntVar(&cfg.MinPeers, "min-peers", cfg.MinPeers,
+		"Minimum number of peers of a node (0 disables the alert)")
+	MonitorCmd.Flags().Int64Var(&cfg.MissedVotesWindow, "missed-votes-window", cfg.MissedVotesWindow,
+		"Number of blocks over which the missed precommits are counted")
+	MonitorCmd.Flags().IntVar(&cfg.MaxMissedVotes, "max-missed-votes", cfg.MaxMissedVotes,
+		"Number of precommits a validator can miss in the window")
+	MonitorCmd.Flags().DurationVar(&cfg.HaltTimeout, "halt-timeout", cfg.HaltTimeout,
+		"How long the network can go without a new block (0 disables the alert)")
+	MonitorCmd.Flags().StringSliceVar(&slackWebhooks, "slack-webhook", nil,
+		"URL of a Slack incoming webhook to post the alerts to (can be repeated)")
+	MonitorCmd.Flags().StringVar(&pagerDutyRoutingKey, "pagerduty-routing-key", "",
+		"Routing key of the PagerDuty service to trigger and resolve incidents of")
+	MonitorCmd.Flags().StringVar(&pagerDutyURL, "pagerduty-url", DefaultPagerDutyURL,
+		"URL of the PagerDuty Events API v2")
+}
+
+// Config is the configuration of a Monitor.
+type Config struct {
+	// RPC addresses of the nodes
+	Endpoints []string
+	// how often to poll the nodes, and the timeout of a request
+	Interval time.Duration
+	Timeout  time.Duration
+
+	// number of blocks a node can be behind the highest node
+	MaxBlockLag int64
+	// minimum number of peers of a node, 0 to disable
+	MinPeers int
+	// number of precommits a validator can miss in the last MissedVotesWindow
+	// blocks
+	MissedVotesWindow int64
+	MaxMissedVotes    int
+	// how long the network can go without a new block, 0 to disable
+	HaltTimeout time.Duration
+}
+
+// ValidateBasic performs basic validation.
+func (cfg Config) ValidateBasic() error {
+	if len(cfg.Endpoints) == 0 {
+		return errors.New("no endpoints")
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	if cfg.Timeout < time.Second {
+		return errors.New("timeout must be at least 1s")
+	}
+	if cfg.MaxBlockLag < 0 {
+		return errors.New("max-block-lag can't be negative")
+	}
+	if cfg.MinPeers < 0 {
+		return errors.New("min-peers can't be negative")
+	}
+	if cfg.MissedVotesWindow <= 0 {
+		return errors.New("missed-votes-window must be positive")
+	}
+	if cfg.MaxMissedVotes < 0 {
+		return errors.New("max-missed-votes can't be negative")
+	}
+	if cfg.HaltTimeout < 0 {
+		return errors.New("halt-timeout can't be negative")
+	}
+	return nil
+}
+
+func monitorCmdHandler(_ *cobra.Command, _ []string) error {
+	hooks := []Hook{LogHook{logger}}
+	for _, url := range slackWebhooks {
+		hooks = append(hooks, NewSlackHook(url, cfg.Timeout))
+	}
+	if pagerDutyRoutingKey != "" {
+		hooks = append(hooks, NewPagerDutyHook(pagerDutyURL, pagerDutyRoutingKey, cfg.Timeout))
+	}
+
+	m, err := NewMonitor(cfg, hooks, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tmos.TrapSignal(logger, cancel)
+	m.Run(ctx)
+	return nil
+}
@@ -0,0 +1,249 @@
+package monitor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// fakeClient is a node of height, whose validators vals missed the
+// precommits of the heights in missed.
+type fakeClient struct {
+	down   bool
+	height int64
+	peers  int
+	vals   []*types.Validator
+	missed map[int64][]int
+}
+
+func (c *fakeClient) Status() (*ctypes.ResultStatus, error) {
+	if c.down {
+		return nil, errors.New("connection refused")
+	}
+	status := &ctypes.ResultStatus{}
+	status.SyncInfo.LatestBlockHeight = c.height
+	return status, nil
+}
+
+func (c *fakeClient) NetInfo() (*ctypes.ResultNetInfo, error) {
+	return &ctypes.ResultNetInfo{NPeers: c.peers}, nil
+}
+
+func (c *fakeClient) Commit(height *int64) (*ctypes.ResultCommit, error) {
+	sigs := make([]types.CommitSig, len(c.vals))
+	for i := range sigs {
+		sigs[i] = types.CommitSig{BlockIDFlag: types.BlockIDFlagCommit}
+	}
+	for _, i := range c.missed[*height] {
+		sigs[i] = types.NewCommitSigAbsent()
+	}
+	header := types.Header{Height: *height}
+	return ctypes.NewResultCommit(&header, types.NewCommit(*height, 0, types.BlockID{}, sigs), true), nil
+}
+
+func (c *fakeClient) Validators(height *int64, page, perPage int) (*ctypes.ResultValidators, error) {
+	start := (page - 1) * perPage
+	if start > 0 && start >= len(c.vals) {
+		return nil, errors.New("page out of range")
+	}
+	end := start + perPage
+	if end > len(c.vals) {
+		end = len(c.vals)
+	}
+	return &ctypes.ResultValidators{BlockHeight: *height, Validators: c.vals[start:end]}, nil
+}
+
+type recordingHook struct {
+	alerts []Alert
+}
+
+func (h *recordingHook) Fire(a Alert) error {
+	h.alerts = append(h.alerts, a)
+	return nil
+}
+
+func (h *recordingHook) next() []string {
+	var keys []string
+	for _, a := range h.alerts {
+		if a.Resolved {
+			keys = append(keys, "resolved "+a.Key)
+		} else {
+			keys = append(keys, a.Key)
+		}
+	}
+	h.alerts = nil
+	return keys
+}
+
+func testConfig(endpoints ...string) Config {
+	return Config{
+		Endpoints:         endpoints,
+		Interval:          time.Second,
+		Timeout:           time.Second,
+		MaxBlockLag:       2,
+		MinPeers:          1,
+		MissedVotesWindow: 5,
+		MaxMissedVotes:    2,
+	}
+}
+
+func TestMonitorNodeAlerts(t *testing.T) {
+	node0 := &fakeClient{height: 10, peers: 2}
+	node1 := &fakeClient{height: 10, peers: 2}
+	hook := &recordingHook{}
+	m := newMonitor(testConfig("node0", "node1"), []Client{node0, node1}, []Hook{hook}, log.TestingLogger())
+
+	m.Poll()
+	assert.Empty(t, hook.next())
+
+	node0.height, node1.height = 13, 10
+	node1.peers = 0
+	m.Poll()
+	assert.Equal(t, []string{"block_lag/node1", "peers/node1"}, hook.next())
+
+	// firing alerts aren't fired again
+	m.Poll()
+	assert.Empty(t, hook.next())
+	assert.Len(t, m.Status().Alerts, 2)
+
+	node1.height, node1.peers = 12, 3
+	node0.down = true
+	m.Poll()
+	assert.Equal(t, []string{"node_down/node0", "resolved block_lag/node1", "resolved peers/node1"}, hook.next())
+
+	node0.down = false
+	m.Poll()
+	assert.Equal(t, []string{"resolved node_down/node0"}, hook.next())
+	assert.Empty(t, m.Status().Alerts)
+}
+
+func TestMonitorHalt(t *testing.T) {
+	cfg := testConfig("node0")
+	cfg.HaltTimeout = time.Millisecond
+	node0 := &fakeClient{height: 10, peers: 1}
+	hook := &recordingHook{}
+	m := newMonitor(cfg, []Client{node0}, []Hook{hook}, log.TestingLogger())
+
+	m.Poll()
+	assert.Empty(t, hook.next())
+
+	time.Sleep(5 * time.Millisecond)
+	m.Poll()
+	assert.Equal(t, []string{"halt"}, hook.next())
+
+	node0.height++
+	m.Poll()
+	assert.Equal(t, []string{"resolved halt"}, hook.next())
+}
+
+func TestMonitorMissedVotes(t *testing.T) {
+	vals := make([]*types.Validator, 150)
+	for i := range vals {
+		val, _ := types.RandValidator(false, 1)
+		vals[i] = val
+	}
+	node0 := &fakeClient{height: 1, peers: 1, vals: vals, missed: map[int64][]int{
+		2: {0, 120},
+		3: {0, 120},
+		4: {0},
+		5: {120},
+	}}
+	hook := &recordingHook{}
+	m := newMonitor(testConfig("node0"), []Client{node0}, []Hook{hook}, log.TestingLogger())
+
+	// the commits of height 1 to 4 are checked
+	node0.height = 5
+	m.Poll()
+	assert.Equal(t, []string{"missed_votes/" + vals[0].Address.String()}, hook.next())
+	status := m.Status()
+	require.Len(t, status.Validators, len(vals))
+	assert.Equal(t, 3, status.Validators[0].MissedVotes)
+	assert.Equal(t, 2, status.Validators[120].MissedVotes)
+	assert.Equal(t, 0, status.Validators[1].MissedVotes)
+
+	node0.height = 6
+	m.Poll()
+	assert.Equal(t, []string{"missed_votes/" + vals[120].Address.String()}, hook.next())
+
+	// the missed votes of height 2 and 3 leave the window; the alerts are
+	// resolved in the order of their keys
+	node0.height = 9
+	m.Poll()
+	assert.ElementsMatch(t, []string{
+		"resolved missed_votes/" + vals[0].Address.String(),
+		"resolved missed_votes/" + vals[120].Address.String(),
+	}, hook.next())
+	status = m.Status()
+	assert.Equal(t, 1, status.Validators[0].MissedVotes)
+	assert.Equal(t, 1, status.Validators[120].MissedVotes)
+}
+
+func TestHooks(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bz, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(bz, &body))
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			http.Error(w, "no", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	a := Alert{Key: "halt", Summary: "no new block", Severity: SeverityCritical, Time: time.Unix(0, 0)}
+	require.NoError(t, NewSlackHook(server.URL, time.Second).Fire(a))
+	assert.Equal(t, map[string]interface{}{"text": "[critical] no new block"}, bodies[0])
+
+	pd := NewPagerDutyHook(server.URL, "key", time.Second)
+	require.NoError(t, pd.Fire(a))
+	assert.Equal(t, map[string]interface{}{
+		"routing_key":  "key",
+		"event_action": "trigger",
+		"dedup_key":    "halt",
+		"payload": map[string]interface{}{
+			"summary":   "no new block",
+			"source":    "tendermint-monitor",
+			"severity":  "critical",
+			"timestamp": "1970-01-01T00:00:00Z",
+		},
+	}, bodies[1])
+
+	a.Resolved = true
+	require.NoError(t, pd.Fire(a))
+	assert.Equal(t, map[string]interface{}{
+		"routing_key":  "key",
+		"event_action": "resolve",
+		"dedup_key":    "halt",
+	}, bodies[2])
+
+	err := NewSlackHook(server.URL+"/fail", time.Second).Fire(a)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+}
+
+func TestConfigValidateBasic(t *testing.T) {
+	assert.NoError(t, cfg.ValidateBasic())
+	assert.NoError(t, testConfig("node0").ValidateBasic())
+
+	invalid := testConfig()
+	assert.Error(t, invalid.ValidateBasic())
+	invalid = testConfig("node0")
+	invalid.MissedVotesWindow = 0
+	assert.Error(t, invalid.ValidateBasic())
+	invalid = testConfig("node0")
+	invalid.Timeout = time.Millisecond
+	assert.Error(t, invalid.ValidateBasic())
+}
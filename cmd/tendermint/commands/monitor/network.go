@@ -0,0 +1,329 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// validatorsPerPage is the maximum number of validators per page of the
+// validators RPC route.
+const validatorsPerPage = 100
+
+// Client is the part of the RPC client used by the Monitor.
+type Client interface {
+	Status() (*ctypes.ResultStatus, error)
+	NetInfo() (*ctypes.ResultNetInfo, error)
+	Commit(height *int64) (*ctypes.ResultCommit, error)
+	Validators(height *int64, page, perPage int) (*ctypes.ResultValidators, error)
+}
+
+// NodeStatus is the last known status of a node.
+type NodeStatus struct {
+	Endpoint   string    `json:"endpoint"`
+	Moniker    string    `json:"moniker"`
+	Online     bool      `json:"online"`
+	Height     int64     `json:"height"`
+	BlockTime  time.Time `json:"block_time"`
+	CatchingUp bool      `json:"catching_up"`
+	Peers      int       `json:"peers"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// ValidatorStatus is the number of precommits a validator missed in the
+// window of the last blocks.
+type ValidatorStatus struct {
+	Address     types.Address `json:"address"`
+	VotingPower int64         `json:"voting_power"`
+	MissedVotes int           `json:"missed_votes"`
+}
+
+// Status is the status of the network as seen by the Monitor.
+type Status struct {
+	// the highest height of the nodes
+	Height     int64             `json:"height"`
+	Nodes      []NodeStatus      `json:"nodes"`
+	Validators []ValidatorStatus `json:"validators"`
+	// the alerts currently firing
+	Alerts []Alert `json:"alerts"`
+}
+
+// Monitor polls the RPC endpoints of a set of nodes, tracks their heights,
+// peers and the precommits of the validators, and fires alerts to its hooks
+// when the thresholds of its Config are crossed.
+type Monitor struct {
+	cfg     Config
+	clients []Client
+	hooks   []Hook
+	logger  log.Logger
+
+	mtx   sync.Mutex
+	nodes []NodeStatus
+	// the highest height of the nodes, and when it was first seen
+	height     int64
+	heightTime time.Time
+	// the validators of the last height whose commit was checked, and the
+	// heights of the precommits each validator missed in the window
+	commitHeight int64
+	validators   []*types.Validator
+	missed       map[string][]int64
+	// the alerts firing, by key
+	alerts map[string]Alert
+}
+
+// NewMonitor returns a Monitor of the endpoints of cfg.
+func NewMonitor(cfg Config, hooks []Hook, logger log.Logger) (*Monitor, error) {
+	if err := cfg.ValidateBasic(); err != nil {
+		return nil, err
+	}
+	clients := make([]Client, len(cfg.Endpoints))
+	for i, endpoint := range cfg.Endpoints {
+		client, err := rpcclient.NewHTTPWithTimeout(endpoint, "/websocket", uint(cfg.Timeout/time.Second))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create the client of %s", endpoint)
+		}
+		clients[i] = client
+	}
+	return newMonitor(cfg, clients, hooks, logger), nil
+}
+
+func newMonitor(cfg Config, clients []Client, hooks []Hook, logger log.Logger) *Monitor {
+	nodes := make([]NodeStatus, len(cfg.Endpoints))
+	for i, endpoint := range cfg.Endpoints {
+		nodes[i].Endpoint = endpoint
+	}
+	return &Monitor{
+		cfg:     cfg,
+		clients: clients,
+		hooks:   hooks,
+		logger:  logger,
+		nodes:   nodes,
+		missed:  make(map[string][]int64),
+		alerts:  make(map[string]Alert),
+	}
+}
+
+// Run polls the nodes every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	m.logger.Info("Monitoring the nodes", "endpoints", len(m.clients), "interval", m.cfg.Interval)
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		m.Poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll polls the nodes once, and fires the alerts which started or stopped
+// firing since the last poll.
+func (m *Monitor) Poll() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := time.Now()
+	highest := -1
+	for i := range m.nodes {
+		m.pollNode(i)
+		if m.nodes[i].Online && (highest < 0 || m.nodes[i].Height > m.nodes[highest].Height) {
+			highest = i
+		}
+	}
+	if highest >= 0 {
+		if height := m.nodes[highest].Height; height > m.height {
+			m.height, m.heightTime = height, now
+		}
+		// the commit of the last block isn't final yet
+		if err := m.checkCommits(m.clients[highest], m.nodes[highest].Height-1); err != nil {
+			m.logger.Error("Failed to check the commits", "endpoint", m.nodes[highest].Endpoint, "err", err)
+		}
+	}
+	m.evaluate(now)
+}
+
+func (m *Monitor) pollNode(i int) {
+	node := &m.nodes[i]
+	status, err := m.clients[i].Status()
+	if err == nil {
+		var netInfo *ctypes.ResultNetInfo
+		if netInfo, err = m.clients[i].NetInfo(); err == nil {
+			*node = NodeStatus{
+				Endpoint:   node.Endpoint,
+				Moniker:    status.NodeInfo.Moniker,
+				Online:     true,
+				Height:     status.SyncInfo.LatestBlockHeight,
+				BlockTime:  status.SyncInfo.LatestBlockTime,
+				CatchingUp: status.SyncInfo.CatchingUp,
+				Peers:      netInfo.NPeers,
+			}
+			return
+		}
+	}
+	node.Online = false
+	node.Error = err.Error()
+}
+
+// checkCommits records the precommits missed in the commits of the heights
+// up to height, within the window.
+func (m *Monitor) checkCommits(client Client, height int64) error {
+	from := m.commitHeight + 1
+	if min := height - m.cfg.MissedVotesWindow + 1; from < min {
+		from = min
+	}
+	if from < 1 {
+		from = 1
+	}
+
+	for h := from; h <= height; h++ {
+		h := h
+		commit, err := client.Commit(&h)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the commit of height %d", h)
+		}
+		vals, err := allValidators(client, h)
+		if err != nil {
+			return err
+		}
+		sigs := commit.Commit.Signatures
+		if len(sigs) != len(vals) {
+			return errors.Errorf("the commit of height %d has %d signatures for %d validators", h, len(sigs), len(vals))
+		}
+		for i, sig := range sigs {
+			if sig.BlockIDFlag == types.BlockIDFlagAbsent {
+				address := vals[i].Address.String()
+				m.missed[address] = append(m.missed[address], h)
+			}
+		}
+		m.commitHeight, m.validators = h, vals
+	}
+
+	min := m.commitHeight - m.cfg.MissedVotesWindow + 1
+	for address, heights := range m.missed {
+		for len(heights) > 0 && heights[0] < min {
+			heights = heights[1:]
+		}
+		if len(heights) == 0 {
+			delete(m.missed, address)
+		} else {
+			m.missed[address] = heights
+		}
+	}
+	return nil
+}
+
+func allValidators(client Client, height int64) ([]*types.Validator, error) {
+	var vals []*types.Validator
+	for page := 1; ; page++ {
+		res, err := client.Validators(&height, page, validatorsPerPage)
+		if err != nil {
+			// the previous page was the last one
+			if page > 1 && len(vals)%validatorsPerPage == 0 {
+				return vals, nil
+			}
+			return nil, errors.Wrapf(err, "failed to get the validators of height %d", height)
+		}
+		vals = append(vals, res.Validators...)
+		if len(res.Validators) < validatorsPerPage {
+			return vals, nil
+		}
+	}
+}
+
+// evaluate fires the alerts whose condition started or stopped holding.
+func (m *Monitor) evaluate(now time.Time) {
+	conditions := make(map[string]Alert)
+	alert := func(key, severity, format string, args ...interface{}) {
+		conditions[key] = Alert{Key: key, Severity: severity, Summary: fmt.Sprintf(format, args...), Time: now}
+	}
+
+	for _, node := range m.nodes {
+		switch {
+		case !node.Online:
+			alert("node_down/"+node.Endpoint, SeverityCritical, "%s is unreachable: %s", node.Endpoint, node.Error)
+			continue
+		case m.height-node.Height > m.cfg.MaxBlockLag:
+			alert("block_lag/"+node.Endpoint, SeverityWarning, "%s (%s) is %d blocks behind, at height %d of %d",
+				node.Endpoint, node.Moniker, m.height-node.Height, node.Height, m.height)
+		}
+		if m.cfg.MinPeers > 0 && node.Peers < m.cfg.MinPeers {
+			alert("peers/"+node.Endpoint, SeverityWarning, "%s (%s) has %d peers, fewer than %d",
+				node.Endpoint, node.Moniker, node.Peers, m.cfg.MinPeers)
+		}
+	}
+	for address, heights := range m.missed {
+		if len(heights) > m.cfg.MaxMissedVotes {
+			alert("missed_votes/"+address, SeverityWarning, "validator %s missed %d of the last %d precommits",
+				address, len(heights), m.cfg.MissedVotesWindow)
+		}
+	}
+	if m.cfg.HaltTimeout > 0 && m.height > 0 && now.Sub(m.heightTime) > m.cfg.HaltTimeout {
+		alert("halt", SeverityCritical, "no new block since %v, at height %d",
+			m.heightTime.Format(time.RFC3339), m.height)
+	}
+
+	for _, key := range sortedKeys(conditions) {
+		if _, ok := m.alerts[key]; !ok {
+			m.alerts[key] = conditions[key]
+			m.fire(conditions[key])
+		}
+	}
+	for _, key := range sortedKeys(m.alerts) {
+		if _, ok := conditions[key]; !ok {
+			a := m.alerts[key]
+			delete(m.alerts, key)
+			a.Resolved, a.Time = true, now
+			m.fire(a)
+		}
+	}
+}
+
+func (m *Monitor) fire(a Alert) {
+	for _, hook := range m.hooks {
+		if err := hook.Fire(a); err != nil {
+			m.logger.Error("Failed to fire the alert", "key", a.Key, "err", err)
+		}
+	}
+}
+
+// Status returns the status of the network as of the last poll.
+func (m *Monitor) Status() Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	status := Status{
+		Height: m.height,
+		Nodes:  append([]NodeStatus(nil), m.nodes...),
+	}
+	for _, val := range m.validators {
+		status.Validators = append(status.Validators, ValidatorStatus{
+			Address:     val.Address,
+			VotingPower: val.VotingPower,
+			MissedVotes: len(m.missed[val.Address.String()]),
+		})
+	}
+	for _, key := range sortedKeys(m.alerts) {
+		status.Alerts = append(status.Alerts, m.alerts[key])
+	}
+	return status
+}
+
+func sortedKeys(alerts map[string]Alert) []string {
+	keys := make([]string, 0, len(alerts))
+	for key := range alerts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Output formats of the commands, selected by the --output flag of the root
+// command.
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+var outputFormat = outputFormatText
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputFormatText, outputFormatJSON:
+		return nil
+	default:
+		return errors.Errorf("unknown output format %q, expected text or json", outputFormat)
+	}
+}
+
+// jsonOutput returns whether the commands print JSON (--output json).
+func jsonOutput() bool {
+	return outputFormat == outputFormatJSON
+}
+
+// logOutput returns where the logs of the commands go: stderr with --output
+// json, so that stdout can be parsed.
+func logOutput() io.Writer {
+	if jsonOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printJSON prints v to stdout as indented JSON. Crypto types must be
+// marshaled with cdc first (see aminoJSON).
+func printJSON(v interface{}) error {
+	return writeJSON(os.Stdout, v)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	bz, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the output")
+	}
+	_, err = fmt.Fprintln(w, string(bz))
+	return err
+}
+
+// aminoJSON returns the amino JSON of v, e.g. of a public key, to be embedded
+// in the output of printJSON.
+func aminoJSON(v interface{}) (json.RawMessage, error) {
+	bz, err := cdc.MarshalJSON(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the output")
+	}
+	return bz, nil
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/types"
+)
+
+func withJSONOutput(t *testing.T) {
+	outputFormat = outputFormatJSON
+	t.Cleanup(func() { outputFormat = outputFormatText })
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	assert.NoError(t, validateOutputFormat())
+	outputFormat = "yaml"
+	defer func() { outputFormat = outputFormatText }()
+	assert.Error(t, validateOutputFormat())
+}
+
+func TestPrintEncodingsJSON(t *testing.T) {
+	withJSONOutput(t)
+	pubKey := ed25519.GenPrivKey().PubKey()
+
+	w := new(bytes.Buffer)
+	require.NoError(t, printEncodings(w, pubKeyBytes(pubKey), pubKey, "", "tmpub"))
+	var out map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(w.Bytes(), &out))
+	assert.Len(t, out, 4)
+	var pubKeyOut ed25519.PubKeyEd25519
+	require.NoError(t, cdc.UnmarshalJSON(out[encodingJSON], &pubKeyOut))
+	assert.Equal(t, pubKey, pubKeyOut)
+
+	w.Reset()
+	require.NoError(t, printEncodings(w, pubKey.Address(), nil, encodingHex, "tm"))
+	assert.JSONEq(t, `{"hex": "`+pubKey.Address().String()+`"}`, w.String())
+}
+
+func TestValidateGenesisJSON(t *testing.T) {
+	withJSONOutput(t)
+	genDoc := types.GenesisDoc{
+		ChainID:    "test-chain",
+		Validators: []types.GenesisValidator{{PubKey: ed25519.GenPrivKey().PubKey(), Power: -1}},
+	}
+	bz, err := cdc.MarshalJSON(genDoc)
+	require.NoError(t, err)
+
+	w := new(bytes.Buffer)
+	require.Error(t, validateGenesis(w, bz, ""))
+	var report genesisReport
+	require.NoError(t, json.Unmarshal(w.Bytes(), &report))
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Warnings, "genesis_time is missing: every node would use the time it first starts at")
+	require.NotNil(t, report.Summary)
+	assert.Equal(t, "test-chain", report.Summary.ChainID)
+	assert.EqualValues(t, 1, report.Summary.Validators)
+}
@@ -17,6 +17,19 @@ var ProbeUpnpCmd = &cobra.Command{
 
 func probeUpnp(cmd *cobra.Command, args []string) error {
 	capabilities, err := upnp.Probe(logger)
+	if jsonOutput() {
+		result := struct {
+			Success      bool               `json:"success"`
+			Error        string             `json:"error,omitempty"`
+			Capabilities *upnp.Capabilities `json:"capabilities,omitempty"`
+		}{Success: err == nil}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Capabilities = &capabilities
+		}
+		return printJSON(result)
+	}
 	if err != nil {
 		fmt.Println("Probe failed: ", err)
 	} else {
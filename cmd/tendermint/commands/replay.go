@@ -6,12 +6,33 @@ import (
 	"github.com/tendermint/tendermint/consensus"
 )
 
+var replayBreakpoints []string
+
+func init() {
+	for _, cmd := range []*cobra.Command{ReplayCmd, ReplayConsoleCmd} {
+		cmd.Flags().String("proxy_app", config.ProxyApp,
+			"Proxy app to replay against, e.g. a modified build of the app, with no state")
+		cmd.Flags().StringSliceVar(&replayBreakpoints, "break", nil,
+			"Breakpoints at which to stop in the console: height[/round[/step]], e.g. 10/0/precommit")
+	}
+}
+
+const replayLong = `Replay the messages of the consensus WAL, from the genesis, against the
+proxy app (--proxy_app), which must start with no state (at height 0), e.g. a
+modified build of the app testing a fix for a past consensus failure. The
+blocks committed and the app hashes are checked against the blocks of the
+node, and the replay stops at the first divergence. The blocks which precede
+the WAL, if it was pruned, are replayed from the block store.
+
+The state of the node isn't modified: the replay uses in-memory stores.`
+
 // ReplayCmd allows replaying of messages from the WAL.
 var ReplayCmd = &cobra.Command{
 	Use:   "replay",
 	Short: "Replay messages from WAL",
-	Run: func(cmd *cobra.Command, args []string) {
-		consensus.RunReplayFile(config.BaseConfig, config.Consensus, false)
+	Long:  replayLong,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReplay(false)
 	},
 }
 
@@ -20,7 +41,25 @@ var ReplayCmd = &cobra.Command{
 var ReplayConsoleCmd = &cobra.Command{
 	Use:   "replay_console",
 	Short: "Replay messages from WAL in a console",
-	Run: func(cmd *cobra.Command, args []string) {
-		consensus.RunReplayFile(config.BaseConfig, config.Consensus, true)
+	Long: replayLong + `
+
+The console steps through the messages, stops at breakpoints, and inspects the
+round state, the vote sets and the locked and valid blocks: type help for the
+commands.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReplay(true)
 	},
 }
+
+func runReplay(console bool) error {
+	opts := consensus.ReplayOptions{Console: console}
+	for _, s := range replayBreakpoints {
+		bp, err := consensus.ParseBreakpoint(s)
+		if err != nil {
+			return err
+		}
+		opts.Breakpoints = append(opts.Breakpoints, bp)
+	}
+	consensus.RunReplayFile(config.BaseConfig, config.Consensus, opts)
+	return nil
+}
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -16,6 +15,11 @@ import (
 var (
 	config = cfg.DefaultConfig()
 	logger = log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+
+	// unfiltered logger and the switch between it and logger, used to change
+	// the log level at runtime (see reloadLogLevel)
+	baseLogger log.Logger
+	logSwitch  *log.SwapLogger
 )
 
 func init() {
@@ -24,6 +28,8 @@ func init() {
 
 func registerFlagsRootCmd(cmd *cobra.Command) {
 	cmd.PersistentFlags().String("log_level", config.LogLevel, "Log level")
+	cmd.PersistentFlags().StringVar(&outputFormat, "output", outputFormatText,
+		"Output format: text or json (the logs then go to stderr)")
 }
 
 // ParseConfig retrieves the default environment configuration,
@@ -37,30 +43,63 @@ func ParseConfig() (*cfg.Config, error) {
 	conf.SetRoot(conf.RootDir)
 	cfg.EnsureRoot(conf.RootDir)
 	if err = conf.ValidateBasic(); err != nil {
-		return nil, fmt.Errorf("error in config file: %v", err)
+		return nil, configError(conf, err)
 	}
 	return conf, err
 }
 
+// reloadConfig re-reads the config file, keeping flags and environment
+// variables in effect.
+func reloadConfig() (*cfg.Config, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return ParseConfig()
+}
+
+// reloadLogLevel changes the log level of all loggers derived from logger.
+func reloadLogLevel(logLevel string) error {
+	filtered, err := tmflags.ParseLogLevel(logLevel, baseLogger, cfg.DefaultLogLevel())
+	if err != nil {
+		return err
+	}
+	logSwitch.Swap(filtered)
+	return nil
+}
+
 // RootCmd is the root command for Tendermint core.
 var RootCmd = &cobra.Command{
 	Use:   "tendermint",
 	Short: "Tendermint Core (BFT Consensus) in Go",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
-		if cmd.Name() == VersionCmd.Name() {
+		if err := validateOutputFormat(); err != nil {
+			return err
+		}
+		logger = log.NewTMLogger(log.NewSyncWriter(logOutput()))
+		// validate reports config errors itself
+		if cmd.Name() == VersionCmd.Name() || cmd == ConfigValidateCmd || cmd == ConfigFieldsCmd {
 			return nil
 		}
+		if err := bindConfigEnv(); err != nil {
+			return err
+		}
 		config, err = ParseConfig()
 		if err != nil {
 			return err
 		}
-		if config.LogFormat == cfg.LogFormatJSON {
-			logger = log.NewTMJSONLogger(log.NewSyncWriter(os.Stdout))
+		switch config.LogFormat {
+		case cfg.LogFormatJSON:
+			logger = log.NewTMJSONLogger(log.NewSyncWriter(logOutput()))
+		case cfg.LogFormatJSONTS:
+			logger = log.NewTMJSONLoggerWithTS(log.NewSyncWriter(logOutput()))
 		}
-		logger, err = tmflags.ParseLogLevel(config.LogLevel, logger, cfg.DefaultLogLevel())
+		baseLogger = logger
+		logger, err = tmflags.ParseLogLevel(config.LogLevel, baseLogger, cfg.DefaultLogLevel())
 		if err != nil {
 			return err
 		}
+		logSwitch = log.NewSwapLogger(logger)
+		logger = logSwitch
 		if viper.GetBool(cli.TraceFlag) {
 			logger = log.NewTracingLogger(logger)
 		}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -160,6 +161,39 @@ func TestRootConfig(t *testing.T) {
 	}
 }
 
+func TestRootConfigEnvAllFields(t *testing.T) {
+	clearConfig(defaultRoot)
+
+	// fields missing from the config file can be set from the environment
+	configFilePath := filepath.Join(defaultRoot, "config")
+	require.NoError(t, tmos.EnsureDir(configFilePath, 0700))
+	require.NoError(t, WriteConfigVals(configFilePath, map[string]string{"moniker": "abc"}))
+
+	rootCmd := testRootCmd()
+	cmd := cli.PrepareBaseCmd(rootCmd, "TM", defaultRoot)
+	env := map[string]string{
+		"TM_CONSENSUS_TIMEOUT_COMMIT": "5s",
+		"TM_MEMPOOL_SIZE":             "10",
+		"TM_MONIKER":                  "def",
+	}
+	require.NoError(t, cli.RunWithArgs(cmd, []string{rootCmd.Use}, env))
+
+	assert.Equal(t, 5*time.Second, config.Consensus.TimeoutCommit)
+	assert.Equal(t, 10, config.Mempool.Size)
+	assert.Equal(t, "def", config.Moniker)
+}
+
+func TestAddNodeFlagsAllFields(t *testing.T) {
+	cmd := &cobra.Command{}
+	AddNodeFlags(cmd)
+	for _, f := range cfg.Fields(cfg.DefaultConfig()) {
+		if f.Key == "log_level" {
+			continue
+		}
+		assert.NotNil(t, cmd.Flags().Lookup(f.Key), f.Key)
+	}
+}
+
 // WriteConfigVals writes a toml file with the given values.
 // It returns an error if writing was impossible.
 func WriteConfigVals(dir string, vals map[string]string) error {
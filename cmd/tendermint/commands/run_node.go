@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"syscall"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -19,11 +20,13 @@ var (
 	genesisHash []byte
 )
 
-// AddNodeFlags exposes some common configuration options on the command-line
+// AddNodeFlags exposes the configuration options on the command-line, with
+// descriptions for the common ones.
 // These are exposed for convenience of commands embedding a tendermint node
 func AddNodeFlags(cmd *cobra.Command) {
 	// bind flags
 	cmd.Flags().String("moniker", config.Moniker, "Node Name")
+	cmd.Flags().String("mode", config.Mode, "Node mode: validator | full | seed | archive")
 
 	// priv val flags
 	cmd.Flags().String(
@@ -90,6 +93,9 @@ func AddNodeFlags(cmd *cobra.Command) {
 		"db_dir",
 		config.DBPath,
 		"Database directory")
+
+	// all other config fields
+	addConfigFlags(cmd)
 }
 
 // NewRunNodeCmd returns the command that allows the CLI to start a node.
@@ -99,14 +105,21 @@ func NewRunNodeCmd(nodeProvider nm.Provider) *cobra.Command {
 		Use:   "node",
 		Short: "Run the tendermint node",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if config.Mode == cfg.ModeSeed {
+				// seed nodes don't need the ABCI application of the provider
+				return runSeed(cmd, args)
+			}
 			if err := checkGenesisHash(config); err != nil {
 				return err
 			}
+			logConfigWarnings(cmd, config)
 
 			n, err := nodeProvider(config, logger)
 			if err != nil {
 				return fmt.Errorf("failed to create node: %w", err)
 			}
+			n.SetConfigLoader(reloadConfig)
+			n.SetLogLevelReloader(reloadLogLevel)
 
 			if err := n.Start(); err != nil {
 				return fmt.Errorf("failed to start node: %w", err)
@@ -114,10 +127,20 @@ func NewRunNodeCmd(nodeProvider nm.Provider) *cobra.Command {
 
 			logger.Info("Started node", "nodeInfo", n.Switch().NodeInfo())
 
-			// Stop upon receiving SIGTERM or CTRL-C.
-			tmos.TrapSignal(logger, func() {
+			// Stop upon receiving SIGTERM or CTRL-C, and reload the config upon
+			// receiving SIGHUP. The WAL and other autofiles reopen their files
+			// upon SIGHUP and SIGUSR1, before.
+			tmos.DefaultSignals.SetLogger(logger)
+			tmos.HandleShutdown(0, "node", func() {
 				if n.IsRunning() {
-					n.Stop()
+					if err := n.Shutdown(); err != nil {
+						logger.Error("Failed to shut down gracefully", "err", err)
+					}
+				}
+			})
+			tmos.HandleSignal(syscall.SIGHUP, 0, "config reload", func() {
+				if _, err := n.ReloadConfig(); err != nil {
+					logger.Error("Failed to reload config", "err", err)
 				}
 			})
 
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/tendermint/tendermint/config"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	nm "github.com/tendermint/tendermint/node"
+)
+
+func init() {
+	SeedCmd.Flags().String("moniker", config.Moniker, "Node Name")
+	SeedCmd.Flags().String(
+		"p2p.laddr",
+		config.P2P.ListenAddress,
+		"Node listen address. (0.0.0.0:0 means any interface, any port)")
+	SeedCmd.Flags().String("p2p.seeds", config.P2P.Seeds, "Comma-delimited ID@host:port seed nodes")
+	SeedCmd.Flags().String(
+		"p2p.seed_dns_laddr",
+		config.P2P.SeedDNSListenAddress,
+		"UDP address to answer DNS queries for p2p.seed_dns_domain on (empty disables it)")
+	SeedCmd.Flags().String(
+		"p2p.seed_dns_domain",
+		config.P2P.SeedDNSDomain,
+		"Domain to answer DNS queries for, with the best addresses of the address book")
+
+	// all other config fields
+	addConfigFlags(SeedCmd)
+}
+
+// SeedCmd runs a seed node: it crawls the network and serves addresses over
+// PEX, and optionally DNS, without running consensus, the mempool or the ABCI
+// application.
+var SeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Run a lightweight seed node",
+	Long: `seed runs a node which only crawls the network, to fill its address book with
+the addresses of the peers it could connect to, and serves them to the nodes
+connecting to it over PEX. It doesn't run consensus, the mempool nor the ABCI
+application, and doesn't store blocks: only the chain ID is read from the
+genesis file.
+
+With p2p.seed_dns_laddr and p2p.seed_dns_domain set, it also answers the DNS
+queries for the domain with the best addresses of its address book: A and AAAA
+queries with their IPs, and TXT queries with the addresses themselves
+("id@ip:port").`,
+	Args: cobra.NoArgs,
+	RunE: runSeed,
+}
+
+func runSeed(cmd *cobra.Command, args []string) error {
+	config.Mode = cfg.ModeSeed
+	logConfigWarnings(cmd, config)
+
+	n, err := nm.DefaultNewSeedNode(config, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create seed node: %w", err)
+	}
+	if err := n.Start(); err != nil {
+		return fmt.Errorf("failed to start seed node: %w", err)
+	}
+	logger.Info("Started seed node", "nodeInfo", n.NodeInfo())
+
+	// Stop upon receiving SIGTERM or CTRL-C.
+	tmos.DefaultSignals.SetLogger(logger)
+	tmos.HandleShutdown(0, "seed node", func() {
+		if n.IsRunning() {
+			if err := n.Stop(); err != nil {
+				logger.Error("Failed to stop the seed node", "err", err)
+			}
+		}
+	})
+
+	// Run forever.
+	select {}
+}
@@ -21,6 +21,9 @@ func showNodeID(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if jsonOutput() {
+		return printJSON(map[string]p2p.ID{"id": nodeKey.ID()})
+	}
 	fmt.Println(nodeKey.ID())
 	return nil
 }
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+var snapshotsRPCAddr string
+
+func init() {
+	SnapshotsCmd.PersistentFlags().StringVar(
+		&snapshotsRPCAddr,
+		"rpc-laddr",
+		"",
+		"The node's RPC address (default: rpc.laddr of the config)")
+	SnapshotsCmd.AddCommand(SnapshotsListCmd, SnapshotsCreateCmd, SnapshotsDeleteCmd)
+}
+
+// SnapshotsCmd groups the commands managing the snapshots of the application
+// of a running node, which peers restore with state sync.
+var SnapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "Manage the application's snapshots of a running node",
+	Long: `snapshots lists, creates and deletes the snapshots of the application's state,
+which the peers of the node restore with state sync, through the RPC server of
+the node. Creating and deleting snapshots needs the unsafe RPC commands
+(rpc.unsafe = true).`,
+}
+
+var SnapshotsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots",
+	Args:  cobra.NoArgs,
+	RunE:  listSnapshots,
+}
+
+var SnapshotsCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Snapshot the application's state",
+	Long: `create asks the application to snapshot its state, and prints the height of
+the snapshot. The application may take it at the end of the current block.`,
+	Args: cobra.NoArgs,
+	RunE: createSnapshot,
+}
+
+var SnapshotsDeleteCmd = &cobra.Command{
+	Use:   "delete [height] [format]",
+	Short: "Delete a snapshot",
+	Args:  cobra.ExactArgs(2),
+	RunE:  deleteSnapshot,
+}
+
+func snapshotsClient() (*rpcclient.HTTP, error) {
+	addr := snapshotsRPCAddr
+	if addr == "" {
+		addr = config.RPC.ListenAddress
+	}
+	c, err := rpcclient.NewHTTP(addr, "/websocket")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the RPC client")
+	}
+	return c, nil
+}
+
+func listSnapshots(cmd *cobra.Command, args []string) error {
+	c, err := snapshotsClient()
+	if err != nil {
+		return err
+	}
+	res, err := c.Snapshots()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(res.Snapshots)
+	}
+	for _, s := range res.Snapshots {
+		fmt.Printf("height=%d format=%d chunks=%d hash=%X\n", s.Height, s.Format, s.Chunks, s.Hash)
+	}
+	return nil
+}
+
+func createSnapshot(cmd *cobra.Command, args []string) error {
+	c, err := snapshotsClient()
+	if err != nil {
+		return err
+	}
+	res, err := c.CreateSnapshot()
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(res)
+	}
+	fmt.Printf("Creating the snapshot at height %d\n", res.Height)
+	return nil
+}
+
+func deleteSnapshot(cmd *cobra.Command, args []string) error {
+	height, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid height %q", args[0])
+	}
+	format, err := strconv.ParseUint(args[1], 10, 32)
+	if err != nil {
+		return errors.Wrapf(err, "invalid format %q", args[1])
+	}
+
+	c, err := snapshotsClient()
+	if err != nil {
+		return err
+	}
+	if _, err := c.DeleteSnapshot(height, uint32(format)); err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printJSON(map[string]uint64{"height": height, "format": format})
+	}
+	fmt.Printf("Deleted the snapshot at height %d (format %d)\n", height, format)
+	return nil
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/threshold"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/privval"
+)
+
+var (
+	splitKeyThreshold int
+	splitKeyShares    int
+	splitKeyOutputDir string
+)
+
+func init() {
+	SplitKeyCmd.Flags().IntVar(&splitKeyThreshold, "threshold", 2, "Number of shares needed to sign")
+	SplitKeyCmd.Flags().IntVar(&splitKeyShares, "shares", 3, "Number of shares")
+	SplitKeyCmd.Flags().StringVar(&splitKeyOutputDir, "output-dir", ".", "Directory the shares are written to")
+}
+
+// SplitKeyCmd splits the validator key into the shares of a threshold signer.
+var SplitKeyCmd = &cobra.Command{
+	Use:   "split_key",
+	Short: "Split the validator key into the shares of a threshold signer",
+	Long: `split_key splits the ed25519 validator key (priv_validator_key_file) into
+--shares key shares, --threshold of which are needed to sign, written to
+priv_validator_key_share_<id>.json files.
+
+Each share is then held by a shard of the threshold signer (priv_val_server
+--key-share), connecting to its own address of priv_validator_laddr. Delete the
+validator key once the shares are distributed: no shard can sign alone.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		privKey, err := readPrivKeyFile(config.PrivValidatorKeyFile())
+		if err != nil {
+			return err
+		}
+		edPrivKey, ok := privKey.(ed25519.PrivKeyEd25519)
+		if !ok {
+			return errors.Errorf("only ed25519 keys can be split, got %s", privKeyType(privKey))
+		}
+		shares, err := threshold.SplitPrivKey(edPrivKey, splitKeyThreshold, splitKeyShares)
+		if err != nil {
+			return err
+		}
+
+		paths := make([]string, len(shares))
+		for i, share := range shares {
+			paths[i] = filepath.Join(splitKeyOutputDir, fmt.Sprintf("priv_validator_key_share_%d.json", share.ID))
+			if tmos.FileExists(paths[i]) {
+				return errors.Errorf("%s already exists", paths[i])
+			}
+		}
+		for i, share := range shares {
+			if err := privval.SaveThresholdKeyShare(share, paths[i]); err != nil {
+				return err
+			}
+		}
+
+		if jsonOutput() {
+			return printJSON(struct {
+				Threshold int      `json:"threshold"`
+				Files     []string `json:"files"`
+			}{splitKeyThreshold, paths})
+		}
+		for _, path := range paths {
+			fmt.Println(path)
+		}
+		return nil
+	},
+}
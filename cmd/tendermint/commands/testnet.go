@@ -5,7 +5,6 @@ import (
 	"net"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -22,6 +21,7 @@ import (
 var (
 	nValidators    int
 	nNonValidators int
+	nSeeds         int
 	configFile     string
 	outputDir      string
 	nodeDirPrefix  string
@@ -33,6 +33,14 @@ var (
 	hostnames               []string
 	p2pPort                 int
 	randomMonikers          bool
+	topology                string
+	peersGraph              string
+
+	dockerCompose      bool
+	kubernetes         bool
+	manifestImage      string
+	manifestProxyApp   string
+	manifestVolumeSize string
 )
 
 const (
@@ -46,6 +54,8 @@ func init() {
 		"Config file to use (note some options may be overwritten)")
 	TestnetFilesCmd.Flags().IntVar(&nNonValidators, "n", 0,
 		"Number of non-validators to initialize the testnet with")
+	TestnetFilesCmd.Flags().IntVar(&nSeeds, "seeds", 0,
+		"Number of seed nodes to initialize the testnet with, which all the other nodes use as seeds")
 	TestnetFilesCmd.Flags().StringVar(&outputDir, "o", "./mytestnet",
 		"Directory to store initialization data for the testnet")
 	TestnetFilesCmd.Flags().StringVar(&nodeDirPrefix, "node-dir-prefix", "node",
@@ -71,6 +81,23 @@ func init() {
 		"P2P Port")
 	TestnetFilesCmd.Flags().BoolVar(&randomMonikers, "random-monikers", false,
 		"Randomize the moniker for each generated node")
+	TestnetFilesCmd.Flags().StringVar(&topology, "topology", topologyFull,
+		"Topology of the persistent peers: full, ring, star (around node0) or sentry"+
+			" (each validator is only connected to its sentries, the non-validators, which are fully connected)")
+	TestnetFilesCmd.Flags().StringVar(&peersGraph, "peers-graph", "",
+		"Comma-separated edges between the persistent peers, by node number, instead of a topology"+
+			" (\"0-1,1-2\" results in node1 having node0 and node2 as persistent peers)")
+
+	TestnetFilesCmd.Flags().BoolVar(&dockerCompose, "docker-compose", false,
+		"Also write a docker-compose.yml file running the testnet, with the node directories as volumes")
+	TestnetFilesCmd.Flags().BoolVar(&kubernetes, "kubernetes", false,
+		"Also write a kubernetes.yml file running the testnet, with a persistent volume per node")
+	TestnetFilesCmd.Flags().StringVar(&manifestImage, "image", "tendermint/tendermint",
+		"Docker image of the nodes in the docker-compose and Kubernetes manifests")
+	TestnetFilesCmd.Flags().StringVar(&manifestProxyApp, "proxy-app", "kvstore",
+		"Proxy app of the nodes in the docker-compose and Kubernetes manifests")
+	TestnetFilesCmd.Flags().StringVar(&manifestVolumeSize, "volume-size", "10Gi",
+		"Size of the persistent volume of each node in the Kubernetes manifests")
 }
 
 // TestnetFilesCmd allows initialisation of files for a Tendermint testnet.
@@ -82,22 +109,33 @@ necessary files (private validator, genesis, config, etc.).
 
 Note, strict routability for addresses is turned off in the config file.
 
-Optionally, it will fill in persistent_peers list in config file using either hostnames or IPs.
+Optionally, it will fill in persistent_peers list in config file using either hostnames or IPs,
+following a topology (fully connected by default) or a custom graph of persistent peers. With
+"seeds" seed nodes, the other nodes use them as seeds.
+
+Optionally, it will also write a docker-compose file and Kubernetes manifests
+running the testnet, with the ports of node i mapped to the host ports offset
+by 2*i.
 
 Example:
 
 	tendermint testnet --v 4 --o ./output --populate-persistent-peers --starting-ip-address 192.168.10.2
+	tendermint testnet --v 4 --o ./output --docker-compose --kubernetes
+	tendermint testnet --v 4 --n 8 --seeds 1 --topology sentry
 	`,
 	RunE: testnetFiles,
 }
 
 func testnetFiles(cmd *cobra.Command, args []string) error {
-	if len(hostnames) > 0 && len(hostnames) != (nValidators+nNonValidators) {
+	if len(hostnames) > 0 && len(hostnames) != numNodes() {
 		return fmt.Errorf(
-			"testnet needs precisely %d hostnames (number of validators plus non-validators) if --hostname parameter is used",
-			nValidators+nNonValidators,
+			"testnet needs precisely %d hostnames (number of validators plus non-validators and seeds) if --hostname parameter is used",
+			numNodes(),
 		)
 	}
+	if err := validateTopology(); err != nil {
+		return err
+	}
 
 	config := cfg.DefaultConfig()
 
@@ -147,8 +185,8 @@ func testnetFiles(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	for i := 0; i < nNonValidators; i++ {
-		nodeDir := filepath.Join(outputDir, fmt.Sprintf("%s%d", nodeDirPrefix, i+nValidators))
+	for i := nValidators; i < numNodes(); i++ {
+		nodeDir := filepath.Join(outputDir, fmt.Sprintf("%s%d", nodeDirPrefix, i))
 		config.SetRoot(nodeDir)
 
 		err := os.MkdirAll(filepath.Join(nodeDir, "config"), nodeDirPerm)
@@ -175,7 +213,7 @@ func testnetFiles(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write genesis file.
-	for i := 0; i < nValidators+nNonValidators; i++ {
+	for i := 0; i < numNodes(); i++ {
 		nodeDir := filepath.Join(outputDir, fmt.Sprintf("%s%d", nodeDirPrefix, i))
 		if err := genDoc.SaveAs(filepath.Join(nodeDir, config.BaseConfig.Genesis)); err != nil {
 			_ = os.RemoveAll(outputDir)
@@ -183,37 +221,109 @@ func testnetFiles(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Gather persistent peer addresses.
-	var (
-		persistentPeers string
-		err             error
-	)
+	// Gather peer addresses.
+	ids, addrs, err := nodeAddresses(config)
+	if err != nil {
+		_ = os.RemoveAll(outputDir)
+		return err
+	}
+	var peers [][]int
 	if populatePersistentPeers {
-		persistentPeers, err = persistentPeersString(config)
+		peers, err = testnetPeers()
 		if err != nil {
 			_ = os.RemoveAll(outputDir)
 			return err
 		}
 	}
+	seedNodes := []int{}
+	for i := nValidators + nNonValidators; i < numNodes(); i++ {
+		seedNodes = append(seedNodes, i)
+	}
 
 	// Overwrite default config.
-	for i := 0; i < nValidators+nNonValidators; i++ {
+	p2pConfig := *config.P2P
+	nodes := make([]testnetNode, numNodes())
+	for i := 0; i < numNodes(); i++ {
 		nodeDir := filepath.Join(outputDir, fmt.Sprintf("%s%d", nodeDirPrefix, i))
 		config.SetRoot(nodeDir)
+		*config.P2P = p2pConfig
 		config.P2P.AddrBookStrict = false
 		config.P2P.AllowDuplicateIP = true
-		if populatePersistentPeers {
-			config.P2P.PersistentPeers = persistentPeers
+		if populatePersistentPeers && !isSeed(i) {
+			config.P2P.PersistentPeers = joinAddresses(addrs, peers[i])
+		}
+		switch {
+		case isSeed(i):
+			config.P2P.SeedMode = true
+		case isSentryValidator(i):
+			// only connected to its sentries, which don't gossip its address
+			config.P2P.PexReactor = false
+			config.P2P.AllowedPeerIDs = joinIDs(ids, peers[i])
+		default:
+			if len(seedNodes) > 0 {
+				config.P2P.Seeds = joinAddresses(addrs, seedNodes)
+			}
+			if topology == topologySentry {
+				config.P2P.PrivatePeerIDs = string(ids[sentryValidator(i)])
+			}
 		}
 		config.Moniker = moniker(i)
 
 		cfg.WriteConfigFile(filepath.Join(nodeDir, "config", "config.toml"), config)
+		nodes[i] = testnetNode{Moniker: config.Moniker, Dir: nodeDir, ID: ids[i], Address: addrs[i], Role: testnetRole(i)}
+	}
+	var files []string
+
+	if dockerCompose {
+		if err := writeDockerCompose(config); err != nil {
+			_ = os.RemoveAll(outputDir)
+			return err
+		}
+		files = append(files, filepath.Join(outputDir, dockerComposeFile))
+	}
+	if kubernetes {
+		if err := writeKubernetes(config); err != nil {
+			_ = os.RemoveAll(outputDir)
+			return err
+		}
+		files = append(files, filepath.Join(outputDir, kubernetesFile))
 	}
 
-	fmt.Printf("Successfully initialized %v node directories\n", nValidators+nNonValidators)
+	if jsonOutput() {
+		return printJSON(struct {
+			ChainID string        `json:"chain_id"`
+			Nodes   []testnetNode `json:"nodes"`
+			Files   []string      `json:"files,omitempty"`
+		}{genDoc.ChainID, nodes, files})
+	}
+	for _, file := range files {
+		fmt.Printf("Wrote %s\n", file)
+	}
+	fmt.Printf("Successfully initialized %v node directories\n", numNodes())
 	return nil
 }
 
+// testnetNode describes a node directory initialized by testnet.
+type testnetNode struct {
+	Moniker string `json:"moniker"`
+	Dir     string `json:"dir"`
+	ID      p2p.ID `json:"id"`
+	// persistent peer address, e.g. id@host:26656
+	Address string `json:"address"`
+	Role    string `json:"role"`
+}
+
+func testnetRole(i int) string {
+	switch {
+	case isSeed(i):
+		return "seed"
+	case i < nValidators:
+		return "validator"
+	default:
+		return "non_validator"
+	}
+}
+
 func hostnameOrIP(i int) string {
 	if len(hostnames) > 0 && i < len(hostnames) {
 		return hostnames[i]
@@ -234,18 +344,21 @@ func hostnameOrIP(i int) string {
 	return ip.String()
 }
 
-func persistentPeersString(config *cfg.Config) (string, error) {
-	persistentPeers := make([]string, nValidators+nNonValidators)
-	for i := 0; i < nValidators+nNonValidators; i++ {
+// nodeAddresses returns the IDs and the addresses (ID@host:port) of the nodes.
+func nodeAddresses(config *cfg.Config) ([]p2p.ID, []string, error) {
+	ids := make([]p2p.ID, numNodes())
+	addrs := make([]string, numNodes())
+	for i := 0; i < numNodes(); i++ {
 		nodeDir := filepath.Join(outputDir, fmt.Sprintf("%s%d", nodeDirPrefix, i))
 		config.SetRoot(nodeDir)
 		nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
 		if err != nil {
-			return "", err
+			return nil, nil, err
 		}
-		persistentPeers[i] = p2p.IDAddressString(nodeKey.ID(), fmt.Sprintf("%s:%d", hostnameOrIP(i), p2pPort))
+		ids[i] = nodeKey.ID()
+		addrs[i] = p2p.IDAddressString(nodeKey.ID(), fmt.Sprintf("%s:%d", hostnameOrIP(i), p2pPort))
 	}
-	return strings.Join(persistentPeers, ","), nil
+	return ids, addrs, nil
 }
 
 func moniker(i int) string {
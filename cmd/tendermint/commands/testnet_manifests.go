@@ -0,0 +1,343 @@
+package commands
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	cfg "github.com/tendermint/tendermint/config"
+	tmnet "github.com/tendermint/tendermint/libs/net"
+)
+
+const (
+	dockerComposeFile   = "docker-compose.yml"
+	kubernetesFile      = "kubernetes.yml"
+	containerHome       = "/tendermint"
+	manifestFilePerm    = 0644
+	hostPortOffsetStep  = 2
+	kubernetesFilesPath = "/files"
+)
+
+// manifestNode describes a node of the testnet in the docker-compose and
+// Kubernetes manifests.
+type manifestNode struct {
+	// Name of the service (and of the Kubernetes resources) of the node.
+	Name string
+	// Directory of the node, relative to the output directory.
+	Dir string
+	// Address of the node in the persistent peers, and its static IP address
+	// if it is one.
+	Hostname string
+	IP       string
+	// Ports the node listens on, and the host ports they are mapped to.
+	P2PPort, RPCPort         int
+	HostP2PPort, HostRPCPort int
+	// Arguments of the node command.
+	Args []string
+	// Files of the node home, by path relative to the home, base64 encoded for
+	// Kubernetes.
+	Files []manifestFile
+}
+
+type manifestFile struct {
+	Key  string
+	Path string
+	Data string
+}
+
+type manifest struct {
+	Image      string
+	Subnet     string
+	VolumeSize string
+	Nodes      []manifestNode
+}
+
+var manifestTemplateFuncs = template.FuncMap{
+	"quote": strconv.Quote,
+	"copyFiles": func(files []manifestFile) string {
+		cmds := []string{}
+		for _, f := range files {
+			dest := filepath.ToSlash(filepath.Join(containerHome, f.Path))
+			src := kubernetesFilesPath + "/" + f.Key
+			if f.Key == "priv_validator_state.json" {
+				// the state is only initialized, it is then updated by the node
+				cmds = append(cmds, fmt.Sprintf("[ -f %s ] || cp %s %s", dest, src, dest))
+				continue
+			}
+			cmds = append(cmds, fmt.Sprintf("cp %s %s", src, dest))
+		}
+		return strings.Join(cmds, " && ")
+	},
+}
+
+var dockerComposeTemplate = template.Must(template.New("docker-compose").Funcs(manifestTemplateFuncs).Parse(
+	`version: '3'
+
+services:
+{{- range .Nodes}}
+  {{.Name}}:
+    image: {{quote $.Image}}
+    command: [{{range $i, $arg := .Args}}{{if $i}}, {{end}}{{quote $arg}}{{end}}]
+    ports:
+      - "{{.HostP2PPort}}:{{.P2PPort}}"
+      - "{{.HostRPCPort}}:{{.RPCPort}}"
+    volumes:
+      - ./{{.Dir}}:` + containerHome + `:Z
+    networks:
+      testnet:
+{{- if .IP}}
+        ipv4_address: {{.IP}}
+{{- else}}
+        aliases:
+          - {{quote .Hostname}}
+{{- end}}
+{{- end}}
+
+networks:
+  testnet:
+    driver: bridge
+{{- if .Subnet}}
+    ipam:
+      driver: default
+      config:
+        - subnet: {{.Subnet}}
+{{- end}}
+`))
+
+var kubernetesTemplate = template.Must(template.New("kubernetes").Funcs(manifestTemplateFuncs).Parse(
+	`{{- range .Nodes}}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{.Name}}-files
+  labels:
+    app: tendermint
+    node: {{.Name}}
+type: Opaque
+data:
+{{- range .Files}}
+  {{.Key}}: {{.Data}}
+{{- end}}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{.Name}}-home
+  labels:
+    app: tendermint
+    node: {{.Name}}
+spec:
+  accessModes:
+    - ReadWriteOnce
+  resources:
+    requests:
+      storage: {{$.VolumeSize}}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Name}}
+  labels:
+    app: tendermint
+    node: {{.Name}}
+spec:
+  selector:
+    app: tendermint
+    node: {{.Name}}
+  ports:
+    - name: p2p
+      port: {{.P2PPort}}
+    - name: rpc
+      port: {{.RPCPort}}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+  labels:
+    app: tendermint
+    node: {{.Name}}
+spec:
+  replicas: 1
+  strategy:
+    type: Recreate
+  selector:
+    matchLabels:
+      app: tendermint
+      node: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app: tendermint
+        node: {{.Name}}
+    spec:
+      initContainers:
+        - name: init-home
+          image: {{quote $.Image}}
+          command:
+            - sh
+            - -c
+            - {{quote (printf "mkdir -p ` + containerHome + `/config ` + containerHome + `/data && %s" (copyFiles .Files))}}
+          volumeMounts:
+            - name: home
+              mountPath: ` + containerHome + `
+            - name: files
+              mountPath: ` + kubernetesFilesPath + `
+              readOnly: true
+      containers:
+        - name: tendermint
+          image: {{quote $.Image}}
+          args: [{{range $i, $arg := .Args}}{{if $i}}, {{end}}{{quote $arg}}{{end}}]
+          ports:
+            - name: p2p
+              containerPort: {{.P2PPort}}
+            - name: rpc
+              containerPort: {{.RPCPort}}
+          volumeMounts:
+            - name: home
+              mountPath: ` + containerHome + `
+      volumes:
+        - name: home
+          persistentVolumeClaim:
+            claimName: {{.Name}}-home
+        - name: files
+          secret:
+            secretName: {{.Name}}-files
+{{- end}}
+`))
+
+// manifestNodes returns the nodes of the testnet, whose directories have been
+// initialized with config.
+func manifestNodes(config *cfg.Config, withFiles bool) ([]manifestNode, error) {
+	p2pListenPort, err := listenPort(config.P2P.ListenAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid p2p.laddr")
+	}
+	rpcListenPort, err := listenPort(config.RPC.ListenAddress)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid rpc.laddr")
+	}
+
+	nodes := make([]manifestNode, numNodes())
+	for i := range nodes {
+		nodeDirName := fmt.Sprintf("%s%d", nodeDirPrefix, i)
+		node := manifestNode{
+			Name:        nodeDirName,
+			Dir:         nodeDirName,
+			Hostname:    hostnameOrIP(i),
+			P2PPort:     p2pListenPort,
+			RPCPort:     rpcListenPort,
+			HostP2PPort: p2pListenPort + hostPortOffsetStep*i,
+			HostRPCPort: rpcListenPort + hostPortOffsetStep*i,
+			Args: []string{
+				"node",
+				"--proxy_app=" + manifestProxyApp,
+				// the RPC server must be reachable from outside of the container
+				fmt.Sprintf("--rpc.laddr=tcp://0.0.0.0:%d", rpcListenPort),
+			},
+		}
+		if net.ParseIP(node.Hostname) != nil {
+			node.IP = node.Hostname
+		}
+
+		if withFiles {
+			config.SetRoot(filepath.Join(outputDir, nodeDirName))
+			for _, path := range []string{
+				filepath.Join("config", "config.toml"),
+				config.BaseConfig.Genesis,
+				config.BaseConfig.NodeKey,
+				config.BaseConfig.PrivValidatorKey,
+				config.BaseConfig.PrivValidatorState,
+			} {
+				bz, err := ioutil.ReadFile(filepath.Join(config.RootDir, path))
+				if err != nil {
+					return nil, err
+				}
+				node.Files = append(node.Files, manifestFile{
+					Key:  filepath.Base(path),
+					Path: path,
+					Data: base64.StdEncoding.EncodeToString(bz),
+				})
+			}
+		}
+
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// writeDockerCompose writes a docker-compose file running the testnet, with the
+// node directories mounted as the homes of the nodes.
+func writeDockerCompose(config *cfg.Config) error {
+	nodes, err := manifestNodes(config, false)
+	if err != nil {
+		return err
+	}
+
+	m := manifest{Image: manifestImage, Nodes: nodes}
+	if startingIPAddress != "" && len(hostnames) == 0 {
+		// the static IP addresses of the nodes must be in the subnet
+		ip := net.ParseIP(startingIPAddress).To4()
+		m.Subnet = fmt.Sprintf("%s/24", ip.Mask(net.CIDRMask(24, 32)))
+	}
+	for _, node := range nodes {
+		if node.IP != "" && m.Subnet == "" {
+			return errors.Errorf("%s: the static IP addresses of the docker-compose file need --starting-ip-address",
+				node.Hostname)
+		}
+	}
+
+	return writeManifest(filepath.Join(outputDir, dockerComposeFile), dockerComposeTemplate, m)
+}
+
+// writeKubernetes writes Kubernetes manifests running the testnet: for every
+// node, a secret with its config files, a persistent volume claim for its
+// home, a service named after its hostname and a deployment.
+func writeKubernetes(config *cfg.Config) error {
+	nodes, err := manifestNodes(config, true)
+	if err != nil {
+		return err
+	}
+
+	for i, node := range nodes {
+		if node.IP != "" {
+			return errors.Errorf("%s: the Kubernetes manifests need hostnames, not IP addresses", node.Hostname)
+		}
+		// the hostnames must resolve to the services, possibly with the domain
+		// of the namespace (e.g. node0.default.svc.cluster.local)
+		nodes[i].Name = strings.SplitN(node.Hostname, ".", 2)[0]
+	}
+
+	m := manifest{Image: manifestImage, VolumeSize: manifestVolumeSize, Nodes: nodes}
+	return writeManifest(filepath.Join(outputDir, kubernetesFile), kubernetesTemplate, m)
+}
+
+func writeManifest(path string, tmpl *template.Template, m manifest) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, manifestFilePerm)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(f, m); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to write %s", path)
+	}
+	return f.Close()
+}
+
+func listenPort(laddr string) (int, error) {
+	_, addr := tmnet.ProtocolAndAddress(laddr)
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestnetFilesManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testnet_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(o string, v, n int, d, k bool) {
+		outputDir, nValidators, nNonValidators, dockerCompose, kubernetes = o, v, n, d, k
+	}(outputDir, nValidators, nNonValidators, dockerCompose, kubernetes)
+	outputDir = filepath.Join(dir, "testnet")
+	nValidators, nNonValidators = 2, 1
+	dockerCompose, kubernetes = true, true
+
+	require.NoError(t, testnetFiles(TestnetFilesCmd, nil))
+
+	compose, err := ioutil.ReadFile(filepath.Join(outputDir, dockerComposeFile))
+	require.NoError(t, err)
+	for _, s := range []string{
+		"node2:",
+		`"26660:26656"`,
+		`"26661:26657"`,
+		"./node2:/tendermint:Z",
+		`"--rpc.laddr=tcp://0.0.0.0:26657"`,
+		`- "node1"`,
+	} {
+		assert.Contains(t, string(compose), s)
+	}
+
+	manifests, err := ioutil.ReadFile(filepath.Join(outputDir, kubernetesFile))
+	require.NoError(t, err)
+	nodeKey, err := ioutil.ReadFile(filepath.Join(outputDir, "node1", "config", "node_key.json"))
+	require.NoError(t, err)
+	for _, s := range []string{
+		"name: node2-files",
+		"claimName: node2-home",
+		"storage: 10Gi",
+		"node_key.json: " + base64.StdEncoding.EncodeToString(nodeKey),
+		"[ -f /tendermint/data/priv_validator_state.json ] ||",
+	} {
+		assert.Contains(t, string(manifests), s)
+	}
+}
+
+func TestTestnetFilesKubernetesNeedsHostnames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testnet_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(o, ip string, k bool) {
+		outputDir, startingIPAddress, kubernetes = o, ip, k
+	}(outputDir, startingIPAddress, kubernetes)
+	outputDir = filepath.Join(dir, "testnet")
+	startingIPAddress = "192.168.10.2"
+	kubernetes = true
+
+	assert.Error(t, testnetFiles(TestnetFilesCmd, nil))
+	_, err = os.Stat(outputDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestTestnetPeers(t *testing.T) {
+	defer func(v, n int, tp, g string) {
+		nValidators, nNonValidators, topology, peersGraph = v, n, tp, g
+	}(nValidators, nNonValidators, topology, peersGraph)
+	nValidators, nNonValidators = 2, 3
+
+	testCases := []struct {
+		topology   string
+		peersGraph string
+		peers      [][]int
+	}{
+		{topologyFull, "", [][]int{{0, 1, 2, 3, 4}, {0, 1, 2, 3, 4}, {0, 1, 2, 3, 4}, {0, 1, 2, 3, 4}, {0, 1, 2, 3, 4}}},
+		{topologyRing, "", [][]int{{4, 1}, {0, 2}, {1, 3}, {2, 4}, {3, 0}}},
+		{topologyStar, "", [][]int{{1, 2, 3, 4}, {0}, {0}, {0}, {0}}},
+		// node2 and node4 guard node0, node3 guards node1
+		{topologySentry, "", [][]int{{2, 4}, {3}, {0, 3, 4}, {1, 2, 4}, {0, 2, 3}}},
+		{topologyFull, "0-1, 1-2,2-3,1-2", [][]int{{1}, {0, 2}, {1, 3}, {2}, nil}},
+	}
+	for _, tc := range testCases {
+		topology, peersGraph = tc.topology, tc.peersGraph
+		peers, err := testnetPeers()
+		require.NoError(t, err, tc.topology)
+		assert.Equal(t, tc.peers, peers, tc.topology+" "+tc.peersGraph)
+	}
+
+	for _, graph := range []string{"0-1-2", "0-5", "a-1", "1-1", "0"} {
+		peersGraph = graph
+		_, err := testnetPeers()
+		assert.Error(t, err, graph)
+	}
+}
+
+func TestValidateTopology(t *testing.T) {
+	defer func(v, n, s int, tp, g string, p bool) {
+		nValidators, nNonValidators, nSeeds, topology, peersGraph, populatePersistentPeers = v, n, s, tp, g, p
+	}(nValidators, nNonValidators, nSeeds, topology, peersGraph, populatePersistentPeers)
+
+	set := func(v, n, s int, tp, g string, p bool) {
+		nValidators, nNonValidators, nSeeds, topology, peersGraph, populatePersistentPeers = v, n, s, tp, g, p
+	}
+	set(4, 0, 1, topologyFull, "", false)
+	assert.NoError(t, validateTopology())
+	set(4, 4, 0, topologySentry, "", true)
+	assert.NoError(t, validateTopology())
+	set(4, 3, 0, topologySentry, "", true)
+	assert.Error(t, validateTopology())
+	set(4, 0, 0, "mesh", "", true)
+	assert.Error(t, validateTopology())
+	set(4, 0, 0, topologyRing, "", false)
+	assert.Error(t, validateTopology())
+	set(4, 0, 0, topologyRing, "0-1", true)
+	assert.Error(t, validateTopology())
+	set(4, 0, -1, topologyFull, "", true)
+	assert.Error(t, validateTopology())
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// Topologies of the persistent peers of a testnet.
+const (
+	// every node has every node as persistent peer
+	topologyFull = "full"
+	// every node has its two neighbours as persistent peers
+	topologyRing = "ring"
+	// every node has the first node as persistent peer, and vice versa
+	topologyStar = "star"
+	// every validator has its sentries as only peers, and the sentries (the
+	// non-validators) have each other as persistent peers
+	topologySentry = "sentry"
+)
+
+// numNodes returns the number of nodes of the testnet: the validators, then
+// the non-validators, then the seeds.
+func numNodes() int {
+	return nValidators + nNonValidators + nSeeds
+}
+
+// isSeed returns whether node i is a seed.
+func isSeed(i int) bool {
+	return i >= nValidators+nNonValidators
+}
+
+// isSentryValidator returns whether node i is a validator behind sentries.
+func isSentryValidator(i int) bool {
+	return topology == topologySentry && i < nValidators
+}
+
+// sentryValidator returns the validator guarded by node i, a sentry. The
+// validators are guarded by the sentries in turn.
+func sentryValidator(i int) int {
+	return (i - nValidators) % nValidators
+}
+
+func validateTopology() error {
+	if nSeeds < 0 {
+		return errors.New("the number of seeds can't be negative")
+	}
+	switch topology {
+	case topologyFull, topologyRing, topologyStar:
+	case topologySentry:
+		if nValidators == 0 || nNonValidators < nValidators {
+			return errors.New("the sentry topology needs at least one non-validator (sentry) per validator")
+		}
+	default:
+		return errors.Errorf("unknown topology %q", topology)
+	}
+	if (topology != topologyFull || peersGraph != "") && !populatePersistentPeers {
+		return errors.New("topologies need --populate-persistent-peers")
+	}
+	if topology != topologyFull && peersGraph != "" {
+		return errors.New("--topology and --peers-graph are exclusive")
+	}
+	return nil
+}
+
+// testnetPeers returns the indexes of the persistent peers of every node of
+// the testnet, seeds excluded.
+func testnetPeers() ([][]int, error) {
+	n := nValidators + nNonValidators
+	peers := make([][]int, n)
+	if peersGraph != "" {
+		return parsePeersGraph(peersGraph, n)
+	}
+
+	for i := range peers {
+		switch topology {
+		case topologyFull:
+			// including the node itself, which is ignored
+			for j := 0; j < n; j++ {
+				peers[i] = append(peers[i], j)
+			}
+		case topologyRing:
+			prev, next := (i+n-1)%n, (i+1)%n
+			if prev != i {
+				peers[i] = append(peers[i], prev)
+			}
+			if next != i && next != prev {
+				peers[i] = append(peers[i], next)
+			}
+		case topologyStar:
+			if i == 0 {
+				for j := 1; j < n; j++ {
+					peers[i] = append(peers[i], j)
+				}
+			} else {
+				peers[i] = []int{0}
+			}
+		case topologySentry:
+			if i < nValidators {
+				for j := nValidators; j < n; j++ {
+					if sentryValidator(j) == i {
+						peers[i] = append(peers[i], j)
+					}
+				}
+				continue
+			}
+			peers[i] = append(peers[i], sentryValidator(i))
+			for j := nValidators; j < n; j++ {
+				if j != i {
+					peers[i] = append(peers[i], j)
+				}
+			}
+		}
+	}
+	return peers, nil
+}
+
+// parsePeersGraph parses a comma-separated list of edges between the nodes
+// (e.g. "0-1,1-2"). The nodes of an edge are persistent peers of each other.
+func parsePeersGraph(graph string, n int) ([][]int, error) {
+	peers := make([][]int, n)
+	for _, edge := range strings.Split(graph, ",") {
+		ends := strings.Split(strings.TrimSpace(edge), "-")
+		if len(ends) != 2 {
+			return nil, errors.Errorf("invalid edge %q of the peers graph, expected <node>-<node>", edge)
+		}
+		var nodes [2]int
+		for k, end := range ends {
+			node, err := strconv.Atoi(end)
+			if err != nil || node < 0 || node >= n {
+				return nil, errors.Errorf("invalid node %q of the peers graph, expected a number in [0, %d)", end, n)
+			}
+			nodes[k] = node
+		}
+		if nodes[0] == nodes[1] {
+			return nil, errors.Errorf("invalid edge %q of the peers graph, a node can't be its own peer", edge)
+		}
+		peers[nodes[0]] = appendPeer(peers[nodes[0]], nodes[1])
+		peers[nodes[1]] = appendPeer(peers[nodes[1]], nodes[0])
+	}
+	return peers, nil
+}
+
+func appendPeer(peers []int, peer int) []int {
+	for _, p := range peers {
+		if p == peer {
+			return peers
+		}
+	}
+	return append(peers, peer)
+}
+
+// joinAddresses returns the comma-separated addresses of the given nodes.
+func joinAddresses(addrs []string, nodes []int) string {
+	s := make([]string, len(nodes))
+	for k, i := range nodes {
+		s[k] = addrs[i]
+	}
+	return strings.Join(s, ",")
+}
+
+// joinIDs returns the comma-separated IDs of the given nodes.
+func joinIDs(ids []p2p.ID, nodes []int) string {
+	s := make([]string, len(nodes))
+	for k, i := range nodes {
+		s[k] = string(ids[i])
+	}
+	return strings.Join(s, ",")
+}
@@ -12,7 +12,16 @@ import (
 var VersionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version info",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if jsonOutput() {
+			return printJSON(struct {
+				Version       string `json:"version"`
+				ABCI          string `json:"abci"`
+				BlockProtocol uint64 `json:"block_protocol"`
+				P2PProtocol   uint64 `json:"p2p_protocol"`
+			}{version.Version, version.ABCIVersion, version.BlockProtocol.Uint64(), version.P2PProtocol.Uint64()})
+		}
 		fmt.Println(version.Version)
+		return nil
 	},
 }
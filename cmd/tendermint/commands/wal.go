@@ -0,0 +1,313 @@
+package commands
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	amino "github.com/tendermint/go-amino"
+
+	cs "github.com/tendermint/tendermint/consensus"
+	auto "github.com/tendermint/tendermint/libs/autofile"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	walHeights string
+	walRound   int
+	walKinds   []string
+	walSummary bool
+)
+
+func init() {
+	walCatCmd.Flags().StringVar(&walHeights, "height", "",
+		"Only the messages of the given height, or range of heights (e.g. 10-20, 10-)")
+	walCatCmd.Flags().IntVar(&walRound, "round", -1, "Only the messages of the given round")
+	walCatCmd.Flags().StringSliceVar(&walKinds, "type", nil,
+		"Only the messages of the given types: end_height, round_state, proposal, block_part, vote, timeout")
+	walCatCmd.Flags().BoolVar(&walSummary, "summary", false,
+		"Print the number of messages per type and the gaps between the heights instead of the messages")
+
+	WALCmd.AddCommand(walCatCmd)
+}
+
+// WALCmd groups the commands reading the consensus WAL.
+var WALCmd = &cobra.Command{
+	Use:   "wal",
+	Short: "Read the consensus WAL",
+}
+
+var walCatCmd = &cobra.Command{
+	Use:   "cat [path]",
+	Short: "Decode the consensus WAL to JSON",
+	Long: `cat decodes the consensus WAL (consensus.wal_file by default) to JSON, one
+message per line.
+
+The path is either the head of an autofile group, whose rotated files are read
+first, or a single rotated file (e.g. wal.003 or wal.003.gz). The WAL of a
+running node can be read, up to the last message flushed.
+
+Decoding stops at the first corrupted message, e.g. the last message of a node
+which crashed while writing it, with an error.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.Consensus.WalFile()
+		if len(args) == 1 {
+			path = args[0]
+		}
+		filter, err := parseWALFilter(walHeights, walRound, walKinds)
+		if err != nil {
+			return err
+		}
+
+		r, err := openWAL(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to open the WAL")
+		}
+		defer r.Close()
+		return catWAL(r, os.Stdout, filter, walSummary)
+	},
+}
+
+var walCodec = amino.NewCodec()
+
+func init() {
+	cs.RegisterMessages(walCodec)
+	cs.RegisterWALMessages(walCodec)
+	types.RegisterBlockAmino(walCodec)
+}
+
+// rotatedFile matches the path of a rotated file of an autofile group.
+var rotatedFile = regexp.MustCompile(`\.[0-9]{3,}(\.gz)?$`)
+
+// openWAL opens the group with head at path, or the rotated file at path.
+func openWAL(path string) (io.ReadCloser, error) {
+	if !rotatedFile.MatchString(path) {
+		return auto.OpenGroupReader(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return gzipFile{zr, f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (f gzipFile) Close() error {
+	f.Reader.Close()
+	return f.f.Close()
+}
+
+// walFilter selects the WAL messages of some heights, round and kinds.
+type walFilter struct {
+	minHeight int64
+	maxHeight int64 // 0 if there's no maximum
+	round     int   // -1 for every round
+	kinds     map[string]bool
+}
+
+// parseWALFilter parses the flags of walCatCmd.
+func parseWALFilter(heights string, round int, kinds []string) (walFilter, error) {
+	f := walFilter{round: round, kinds: make(map[string]bool)}
+	for _, kind := range kinds {
+		f.kinds[strings.TrimSpace(kind)] = true
+	}
+	if heights == "" {
+		return f, nil
+	}
+
+	ends := strings.Split(heights, "-")
+	if len(ends) > 2 {
+		return f, errors.Errorf("invalid heights %q, expected <height> or <min>-[<max>]", heights)
+	}
+	var err error
+	if f.minHeight, err = strconv.ParseInt(ends[0], 10, 64); err != nil || f.minHeight < 1 {
+		return f, errors.Errorf("invalid height %q", ends[0])
+	}
+	f.maxHeight = f.minHeight
+	if len(ends) == 2 {
+		f.maxHeight = 0
+		if ends[1] != "" {
+			if f.maxHeight, err = strconv.ParseInt(ends[1], 10, 64); err != nil || f.maxHeight < f.minHeight {
+				return f, errors.Errorf("invalid height %q", ends[1])
+			}
+		}
+	}
+	return f, nil
+}
+
+func (f walFilter) match(msg cs.WALMessage) bool {
+	kind, height, round := cs.WALMessageKind(msg)
+	if len(f.kinds) > 0 && !f.kinds[kind] {
+		return false
+	}
+	if f.minHeight > 0 && (height < f.minHeight || (f.maxHeight > 0 && height > f.maxHeight)) {
+		return false
+	}
+	return f.round < 0 || round == f.round
+}
+
+// catWAL writes the messages of the WAL read from r which match filter to w,
+// as JSON, or their summary.
+func catWAL(r io.Reader, w io.Writer, filter walFilter, summary bool) error {
+	s := newWALStats()
+	var endHeight int64
+	dec := cs.NewWALDecoder(r)
+	for n := 0; ; n++ {
+		msg, err := dec.Decode()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if summary {
+				s.write(w)
+			}
+			return errors.Wrapf(err, "failed to decode message #%d (after the end of height %d)", n, endHeight)
+		}
+		if m, ok := msg.Msg.(cs.EndHeightMessage); ok {
+			endHeight = m.Height
+		}
+
+		if !filter.match(msg.Msg) {
+			continue
+		}
+		if summary {
+			s.add(msg)
+			continue
+		}
+		bz, err := walCodec.MarshalJSON(msg)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal message")
+		}
+		if _, err := w.Write(append(bz, '\n')); err != nil {
+			return err
+		}
+	}
+
+	if summary {
+		return s.write(w)
+	}
+	return nil
+}
+
+// walStats counts the messages of a WAL.
+type walStats struct {
+	messages    int
+	kinds       map[string]int
+	first, last time.Time
+	minHeight   int64
+	maxHeight   int64
+
+	endHeights int
+	lastEnd    int64
+	// the heights without EndHeightMessage between the first and the last
+	gaps []string
+	// the EndHeightMessages not after the previous one
+	unordered []string
+}
+
+func newWALStats() *walStats {
+	return &walStats{kinds: make(map[string]int)}
+}
+
+func (s *walStats) add(msg *cs.TimedWALMessage) {
+	kind, height, _ := cs.WALMessageKind(msg.Msg)
+	if s.messages == 0 {
+		s.first = msg.Time
+	}
+	s.messages++
+	s.kinds[kind]++
+	s.last = msg.Time
+	if height > 0 && (s.minHeight == 0 || height < s.minHeight) {
+		s.minHeight = height
+	}
+	if height > s.maxHeight {
+		s.maxHeight = height
+	}
+
+	if kind != cs.WALKindEndHeight {
+		return
+	}
+	s.endHeights++
+	switch {
+	case s.lastEnd == 0 || height == s.lastEnd+1:
+	case height == s.lastEnd+2:
+		s.gaps = append(s.gaps, strconv.FormatInt(s.lastEnd+1, 10))
+	case height > s.lastEnd:
+		s.gaps = append(s.gaps, fmt.Sprintf("%d-%d", s.lastEnd+1, height-1))
+	default:
+		s.unordered = append(s.unordered, fmt.Sprintf("%d after %d", height, s.lastEnd))
+	}
+	s.lastEnd = height
+}
+
+func (s *walStats) write(w io.Writer) error {
+	if jsonOutput() {
+		out := struct {
+			Messages   int            `json:"messages"`
+			First      *time.Time     `json:"first,omitempty"`
+			Last       *time.Time     `json:"last,omitempty"`
+			MinHeight  int64          `json:"min_height"`
+			MaxHeight  int64          `json:"max_height"`
+			EndHeights int            `json:"end_heights"`
+			Gaps       []string       `json:"gaps"`
+			Unordered  []string       `json:"out_of_order"`
+			Kinds      map[string]int `json:"kinds"`
+		}{s.messages, nil, nil, s.minHeight, s.maxHeight, s.endHeights, s.gaps, s.unordered, s.kinds}
+		if s.messages > 0 {
+			out.First, out.Last = &s.first, &s.last
+		}
+		if out.Gaps == nil {
+			out.Gaps = []string{}
+		}
+		if out.Unordered == nil {
+			out.Unordered = []string{}
+		}
+		return writeJSON(w, out)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Messages\t%d\n", s.messages)
+	if s.messages > 0 {
+		fmt.Fprintf(tw, "Time\t%v - %v\n", s.first.Format(time.RFC3339Nano), s.last.Format(time.RFC3339Nano))
+		fmt.Fprintf(tw, "Heights\t%d - %d\n", s.minHeight, s.maxHeight)
+	}
+	fmt.Fprintf(tw, "Ended heights\t%d\n", s.endHeights)
+	if len(s.gaps) > 0 {
+		fmt.Fprintf(tw, "Gaps\t%s\n", strings.Join(s.gaps, ", "))
+	}
+	if len(s.unordered) > 0 {
+		fmt.Fprintf(tw, "Out of order\t%s\n", strings.Join(s.unordered, ", "))
+	}
+
+	kinds := make([]string, 0, len(s.kinds))
+	for kind := range s.kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Fprintf(tw, "  %s\t%d\n", kind, s.kinds[kind])
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cs "github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestCatWAL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wal_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// a group with a compressed rotated file, and a gap at height 2
+	now := time.Now()
+	writeWAL := func(path string, compress bool, msgs ...cs.WALMessage) {
+		b := new(bytes.Buffer)
+		enc := cs.NewWALEncoder(b)
+		for _, msg := range msgs {
+			require.NoError(t, enc.Encode(&cs.TimedWALMessage{Time: now, Msg: msg}))
+		}
+		if compress {
+			zb := new(bytes.Buffer)
+			zw := gzip.NewWriter(zb)
+			_, err := zw.Write(b.Bytes())
+			require.NoError(t, err)
+			require.NoError(t, zw.Close())
+			b = zb
+		}
+		require.NoError(t, ioutil.WriteFile(path, b.Bytes(), 0600))
+	}
+	head := filepath.Join(dir, "wal")
+	writeWAL(head+".000.gz", true,
+		cs.EndHeightMessage{Height: 0},
+		types.EventDataRoundState{Height: 1, Round: 0, Step: "RoundStepPropose"},
+		types.EventDataRoundState{Height: 1, Round: 1, Step: "RoundStepPropose"},
+		cs.EndHeightMessage{Height: 1})
+	writeWAL(head, false,
+		types.EventDataRoundState{Height: 3, Round: 0, Step: "RoundStepPropose"},
+		cs.EndHeightMessage{Height: 3})
+
+	cat := func(heights string, round int, kinds []string, summary bool) string {
+		filter, err := parseWALFilter(heights, round, kinds)
+		require.NoError(t, err)
+		r, err := openWAL(head)
+		require.NoError(t, err)
+		defer r.Close()
+		w := new(bytes.Buffer)
+		require.NoError(t, catWAL(r, w, filter, summary))
+		return w.String()
+	}
+
+	assert.Len(t, strings.Split(strings.TrimSpace(cat("", -1, nil, false)), "\n"), 6)
+	out := cat("1", 1, nil, false)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 1)
+	assert.Contains(t, out, `"round":"1"`)
+	out = cat("1-", -1, []string{cs.WALKindEndHeight}, false)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 2)
+	assert.NotContains(t, out, "RoundState")
+
+	summary := cat("", -1, nil, true)
+	for _, s := range []string{"Messages       6", "Heights        1 - 3", "Ended heights  3",
+		"Gaps           2", "  end_height   3", "  round_state  3"} {
+		assert.Contains(t, summary, s)
+	}
+
+	// a single rotated file, with a partially written message
+	bz, err := ioutil.ReadFile(head)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(head+".001", bz[:len(bz)-1], 0600))
+	r, err := openWAL(head + ".001")
+	require.NoError(t, err)
+	defer r.Close()
+	w := new(bytes.Buffer)
+	err = catWAL(r, w, walFilter{round: -1}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode message #1 (after the end of height 0)")
+	assert.Len(t, strings.Split(strings.TrimSpace(w.String()), "\n"), 1)
+
+	for _, heights := range []string{"0", "a", "3-1", "1-2-3", "-1"} {
+		_, err := parseWALFilter(heights, -1, nil)
+		assert.Error(t, err, heights)
+	}
+}
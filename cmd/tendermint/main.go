@@ -8,6 +8,8 @@ import (
 
 	cmd "github.com/tendermint/tendermint/cmd/tendermint/commands"
 	"github.com/tendermint/tendermint/cmd/tendermint/commands/debug"
+	"github.com/tendermint/tendermint/cmd/tendermint/commands/loadtest"
+	"github.com/tendermint/tendermint/cmd/tendermint/commands/monitor"
 	cfg "github.com/tendermint/tendermint/config"
 	nm "github.com/tendermint/tendermint/node"
 )
@@ -15,20 +17,33 @@ import (
 func main() {
 	rootCmd := cmd.RootCmd
 	rootCmd.AddCommand(
+		cmd.ConfigCmd,
 		cmd.GenValidatorCmd,
+		cmd.GenesisCmd,
 		cmd.InitFilesCmd,
+		cmd.InspectCmd,
 		cmd.ProbeUpnpCmd,
 		cmd.LiteCmd,
 		cmd.ReplayCmd,
 		cmd.ReplayConsoleCmd,
 		cmd.ResetAllCmd,
 		cmd.ResetPrivValidatorCmd,
+		cmd.SeedCmd,
+		cmd.SnapshotsCmd,
 		cmd.ShowValidatorCmd,
 		cmd.TestnetFilesCmd,
 		cmd.ShowNodeIDCmd,
+		cmd.ShowAddressCmd,
+		cmd.ShowPubKeyCmd,
+		cmd.ConvertKeyCmd,
+		cmd.SplitKeyCmd,
+		cmd.EncryptPrivValidatorKeyCmd,
 		cmd.GenNodeKeyCmd,
 		cmd.VersionCmd,
+		cmd.WALCmd,
 		debug.DebugCmd,
+		loadtest.LoadtestCmd,
+		monitor.MonitorCmd,
 	)
 
 	// NOTE:
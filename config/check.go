@@ -0,0 +1,412 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Problem is an issue found in a config by Check or CheckConfigFile.
+type Problem struct {
+	// Key of the field the problem is about (e.g. "p2p.laddr"), or of the
+	// section if the problem is not about a specific field.
+	Key string `json:"key"`
+	// Message describes the problem.
+	Message string `json:"message"`
+	// Suggestion describes how to fix the problem, if known.
+	Suggestion string `json:"suggestion,omitempty"`
+	// Warning is true if the node can run with the problem, e.g. in case of
+	// deprecated or ignored fields.
+	Warning bool `json:"warning"`
+}
+
+func (p Problem) String() string {
+	level := "error"
+	if p.Warning {
+		level = "warning"
+	}
+	s := level + ": "
+	if p.Key != "" {
+		s += p.Key + ": "
+	}
+	s += p.Message
+	if p.Suggestion != "" {
+		s += " (" + p.Suggestion + ")"
+	}
+	return s
+}
+
+// Errors returns the problems which are not warnings.
+func Errors(problems []Problem) []Problem {
+	var errs []Problem
+	for _, p := range problems {
+		if !p.Warning {
+			errs = append(errs, p)
+		}
+	}
+	return errs
+}
+
+// deprecatedFields maps fields of older versions which are no longer read to
+// what replaced them.
+var deprecatedFields = map[string]string{
+	"priv_validator_file":              "use priv_validator_key_file and priv_validator_state_file",
+	"p2p.skip_upnp":                    "use p2p.upnp",
+	"p2p.max_num_peers":                "use p2p.max_num_inbound_peers and p2p.max_num_outbound_peers",
+	"p2p.max_msg_packet_payload_size":  "use p2p.max_packet_msg_payload_size",
+	"mempool.recheck_empty":            "remove it, empty blocks are no longer rechecked",
+	"consensus.blocktime_iota":         "use consensus_params.block.time_iota_ms in the genesis file",
+	"tx_index.index_tags":              "use tx_index.index_keys",
+	"tx_index.index_all_tags":          "use tx_index.index_all_keys",
+	"consensus.max_block_size_txs":     "use consensus_params.block.max_bytes in the genesis file",
+	"consensus.max_block_size_bytes":   "use consensus_params.block.max_bytes in the genesis file",
+	"consensus.create_empty_blocks_ms": "use consensus.create_empty_blocks_interval",
+}
+
+// CheckConfigFile reads the config.toml file from the given root directory and
+// returns all problems found in it (see Check), including fields of the wrong
+// type and unknown or deprecated fields. An error is returned if the file
+// can't be read or isn't valid TOML.
+func CheckConfigFile(rootDir string) ([]Problem, error) {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(rootDir, defaultConfigFilePath))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+
+	problems := CheckKeys(v.AllKeys())
+
+	conf := DefaultConfig()
+	if err := v.Unmarshal(conf); err != nil {
+		problems = append(problems, decodeProblems(err)...)
+	}
+	conf.SetRoot(rootDir)
+	return append(problems, conf.Check()...), nil
+}
+
+// CheckKeys returns a warning for each of the given keys which isn't a config
+// field, suggesting the field which was probably meant.
+func CheckKeys(keys []string) []Problem {
+	known := configKeys()
+	var problems []Problem
+	for _, key := range keys {
+		if _, ok := known[key]; ok {
+			continue
+		}
+		if replacement, ok := deprecatedFields[key]; ok {
+			problems = append(problems, Problem{
+				Key:        key,
+				Message:    "deprecated field is ignored",
+				Suggestion: replacement,
+				Warning:    true,
+			})
+			continue
+		}
+		p := Problem{Key: key, Message: "unknown field is ignored", Warning: true}
+		if similar := similarKey(key, known); similar != "" {
+			p.Suggestion = fmt.Sprintf("did you mean %s?", similar)
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// configKeys returns the keys of all config fields.
+func configKeys() map[string]struct{} {
+	keys := map[string]struct{}{"home": {}}
+	for _, f := range Fields(DefaultConfig()) {
+		keys[f.Key] = struct{}{}
+	}
+	return keys
+}
+
+// similarKey returns the known key with the same name in another section, or
+// the one with the smallest edit distance of at most 2, or "".
+func similarKey(key string, known map[string]struct{}) string {
+	name := key[strings.LastIndex(key, ".")+1:]
+	candidates := make([]string, 0, len(known))
+	for k := range known {
+		candidates = append(candidates, k)
+	}
+	sort.Strings(candidates)
+
+	for _, k := range candidates {
+		if k[strings.LastIndex(k, ".")+1:] == name {
+			return k
+		}
+	}
+	best, bestDist := "", 3
+	for _, k := range candidates {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a int, others ...int) int {
+	for _, b := range others {
+		if b < a {
+			a = b
+		}
+	}
+	return a
+}
+
+var decodeErrorKey = regexp.MustCompile(`'([^']+)'`)
+
+// decodeProblems turns the (possibly aggregated) error of decoding a config
+// into problems.
+func decodeProblems(err error) []Problem {
+	msgs := []string{err.Error()}
+	if merr, ok := err.(*mapstructure.Error); ok {
+		msgs = merr.Errors
+	}
+	problems := make([]Problem, 0, len(msgs))
+	for _, msg := range msgs {
+		p := Problem{Message: msg, Suggestion: "check the type of the value against the documentation"}
+		if m := decodeErrorKey.FindStringSubmatch(msg); m != nil {
+			p.Key = m[1]
+		}
+		problems = append(problems, p)
+	}
+	return problems
+}
+
+// Check returns all problems found in the config: invalid values (one per
+// section, see ValidateBasic), contradictory settings and malformed addresses.
+// Unlike ValidateBasic, it also reports settings which are ignored or
+// deprecated as warnings.
+func (cfg *Config) Check() []Problem {
+	var problems []Problem
+	add := func(p Problem) { problems = append(problems, p) }
+
+	sections := []struct {
+		name     string
+		validate func() error
+	}{
+		{"", cfg.BaseConfig.ValidateBasic},
+		{"rpc", cfg.RPC.ValidateBasic},
+		{"p2p", cfg.P2P.ValidateBasic},
+		{"mempool", cfg.Mempool.ValidateBasic},
+		{"fastsync", cfg.FastSync.ValidateBasic},
+		{"consensus", cfg.Consensus.ValidateBasic},
+		{"tx_index", cfg.TxIndex.ValidateBasic},
+		{"instrumentation", cfg.Instrumentation.ValidateBasic},
+	}
+	for _, s := range sections {
+		if err := s.validate(); err != nil {
+			add(Problem{Key: s.name, Message: err.Error()})
+		}
+	}
+
+	switch cfg.Mode {
+	case ModeSeed:
+		if !cfg.P2P.PexReactor {
+			add(Problem{Key: "p2p.pex", Message: "seed mode requires the PEX reactor",
+				Suggestion: "set p2p.pex = true or choose another mode"})
+		}
+	case ModeArchive:
+		if cfg.TxIndex.IsNull() {
+			add(Problem{Key: "tx_index.indexer", Message: "archive mode requires a transaction indexer",
+				Suggestion: `set tx_index.indexer = "kv" or choose another mode`})
+		}
+	}
+	if cfg.P2P.SeedMode {
+		add(Problem{Key: "p2p.seed_mode", Message: "deprecated field", Warning: true,
+			Suggestion: `use mode = "seed"`})
+	}
+	if cfg.P2P.SeedDNSListenAddress != "" && cfg.Mode != ModeSeed {
+		add(Problem{Key: "p2p.seed_dns_laddr", Message: "only used by seed nodes", Warning: true,
+			Suggestion: `run the node with "tendermint seed"`})
+	}
+	if cfg.P2P.AllowlistMode() {
+		if cfg.P2P.PexReactor {
+			add(Problem{Key: "p2p.pex", Message: "the PEX reactor is disabled as p2p.allowed_peer_ids is set",
+				Warning: true, Suggestion: "set p2p.pex = false"})
+		}
+		if cfg.P2P.Seeds != "" {
+			add(Problem{Key: "p2p.seeds", Message: "seeds are ignored as p2p.allowed_peer_ids is set", Warning: true,
+				Suggestion: "use p2p.persistent_peers"})
+		}
+	} else if !cfg.P2P.PexReactor && cfg.P2P.Seeds != "" {
+		add(Problem{Key: "p2p.seeds", Message: "seeds are ignored as the PEX reactor is disabled", Warning: true,
+			Suggestion: "set p2p.pex = true or use p2p.persistent_peers"})
+	}
+	if cfg.TxIndex.IndexAllKeys && cfg.TxIndex.IndexKeys != "" {
+		add(Problem{Key: "tx_index.index_keys", Message: "ignored as tx_index.index_all_keys is true", Warning: true,
+			Suggestion: "remove it or set tx_index.index_all_keys = false"})
+	}
+	if cfg.Instrumentation.Prometheus && cfg.Instrumentation.PrometheusListenAddr == "" {
+		add(Problem{Key: "instrumentation.prometheus_listen_addr", Message: "metrics are collected but not served",
+			Warning: true, Suggestion: `set it, e.g. to ":26660"`})
+	}
+	if cfg.RPC.Unsafe && cfg.RPC.ListenAddress != "" && !isLoopback(cfg.RPC.ListenAddress) {
+		add(Problem{Key: "rpc.unsafe", Message: "unsafe RPC endpoints are exposed on a non-loopback address",
+			Warning: true, Suggestion: "set rpc.unsafe = false or listen on 127.0.0.1"})
+	}
+
+	listenAddrs := []struct {
+		key, addr string
+	}{
+		{"proxy_app", cfg.ProxyApp},
+		{"prof_laddr", cfg.ProfListenAddress},
+		{"rpc.grpc_laddr", cfg.RPC.GRPCListenAddress},
+		{"p2p.laddr", cfg.P2P.ListenAddress},
+		{"instrumentation.prometheus_listen_addr", cfg.Instrumentation.PrometheusListenAddr},
+	}
+	for _, addr := range splitList(cfg.RPC.ListenAddress) {
+		listenAddrs = append(listenAddrs, struct{ key, addr string }{"rpc.laddr", addr})
+	}
+	for _, addr := range splitList(cfg.PrivValidatorListenAddr) {
+		listenAddrs = append(listenAddrs, struct{ key, addr string }{"priv_validator_laddr", addr})
+	}
+	for _, a := range listenAddrs {
+		if a.addr == "" || (a.key == "proxy_app" && !strings.Contains(a.addr, "://")) {
+			continue // not set, or a built-in app
+		}
+		if err := checkListenAddr(a.addr); err != nil {
+			add(Problem{Key: a.key, Message: err.Error(),
+				Suggestion: `use "tcp://host:port" or "unix://path"`})
+		}
+	}
+	if addr := cfg.P2P.ExternalAddress; addr != "" {
+		if err := checkHostPort(strings.TrimPrefix(addr, "tcp://")); err != nil {
+			add(Problem{Key: "p2p.external_address", Message: err.Error(), Suggestion: `use "host:port"`})
+		}
+	}
+
+	for key, list := range map[string]string{
+		"p2p.seeds":            cfg.P2P.Seeds,
+		"p2p.persistent_peers": cfg.P2P.PersistentPeers,
+	} {
+		for _, peer := range splitList(list) {
+			if err := checkPeerAddr(peer); err != nil {
+				add(Problem{Key: key, Message: fmt.Sprintf("%q: %v", peer, err),
+					Suggestion: `use "id@host:port", see "tendermint show_node_id"`})
+			}
+		}
+	}
+	for key, list := range map[string]string{
+		"p2p.unconditional_peer_ids": cfg.P2P.UnconditionalPeerIDs,
+		"p2p.private_peer_ids":       cfg.P2P.PrivatePeerIDs,
+	} {
+		for _, id := range splitList(list) {
+			if err := checkPeerID(id); err != nil {
+				add(Problem{Key: key, Message: fmt.Sprintf("%q: %v", id, err),
+					Suggestion: `see "tendermint show_node_id"`})
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Key < problems[j].Key })
+	return problems
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+func checkListenAddr(addr string) error {
+	parts := strings.SplitN(addr, "://", 2)
+	if len(parts) != 2 {
+		// the protocol defaults to tcp
+		return checkHostPort(addr)
+	}
+	switch parts[0] {
+	case "tcp":
+		return checkHostPort(parts[1])
+	case "unix":
+		if parts[1] == "" {
+			return errors.New("missing socket path")
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported protocol %q", parts[0])
+	}
+}
+
+func checkHostPort(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return errors.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+func checkPeerAddr(addr string) error {
+	parts := strings.SplitN(strings.TrimPrefix(addr, "tcp://"), "@", 2)
+	if len(parts) != 2 {
+		return errors.New("missing node ID")
+	}
+	if err := checkPeerID(parts[0]); err != nil {
+		return err
+	}
+	return checkHostPort(parts[1])
+}
+
+// checkPeerID checks that id is a hex encoded address (see p2p.ID).
+func checkPeerID(id string) error {
+	bz, err := hex.DecodeString(id)
+	if err != nil {
+		return errors.New("node ID is not hex encoded")
+	}
+	if len(bz) != 20 {
+		return errors.Errorf("node ID must be 20 bytes, got %d", len(bz))
+	}
+	return nil
+}
+
+func isLoopback(listenAddrs string) bool {
+	for _, addr := range splitList(listenAddrs) {
+		if strings.HasPrefix(addr, "unix://") {
+			continue
+		}
+		host, _, err := net.SplitHostPort(strings.TrimPrefix(addr, "tcp://"))
+		if err != nil {
+			return false
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return false
+		}
+	}
+	return true
+}
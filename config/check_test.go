@@ -0,0 +1,89 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDefaultConfig(t *testing.T) {
+	assert.Empty(t, DefaultConfig().Check())
+	assert.Empty(t, TestConfig().Check())
+}
+
+func TestCheck(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Mode = ModeSeed
+	conf.P2P.PexReactor = false
+	conf.P2P.Seeds = "0123456789abcdef0123456789abcdef01234567@seed.example.com:26656"
+	conf.P2P.PersistentPeers = "0123456789abcdef@1.2.3.4:26656,nopeerid:26656"
+	conf.P2P.ListenAddress = "udp://0.0.0.0:26656"
+	conf.Consensus.TimeoutCommit = -1
+	conf.Mempool.Size = -1
+
+	problems := conf.Check()
+	keys := make(map[string]bool)
+	for _, p := range problems {
+		keys[p.Key] = true
+	}
+	for _, key := range []string{"consensus", "mempool", "p2p.pex", "p2p.seeds", "p2p.persistent_peers", "p2p.laddr"} {
+		assert.True(t, keys[key], key)
+	}
+	// one problem for each malformed persistent peer
+	var peerProblems int
+	for _, p := range problems {
+		if p.Key == "p2p.persistent_peers" {
+			peerProblems++
+		}
+	}
+	assert.Equal(t, 2, peerProblems)
+	assert.Len(t, Errors(problems), len(problems)-1) // p2p.seeds is a warning
+}
+
+func TestCheckAllowlistMode(t *testing.T) {
+	conf := DefaultConfig()
+	conf.P2P.AllowedPeerIDs = "0123456789abcdef0123456789abcdef01234567"
+	conf.P2P.Seeds = "89abcdef0123456789abcdef0123456789abcdef@seed.example.com:26656"
+
+	problems := conf.Check()
+	require.Len(t, problems, 2)
+	assert.Equal(t, "p2p.pex", problems[0].Key)
+	assert.Equal(t, "p2p.seeds", problems[1].Key)
+	assert.Empty(t, Errors(problems))
+
+	conf.Mode = ModeSeed
+	assert.Error(t, conf.ValidateBasic())
+}
+
+func TestCheckConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	EnsureRoot(dir)
+
+	path := filepath.Join(dir, defaultConfigFilePath)
+	bz, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	contents := strings.Replace(string(bz), "max_num_inbound_peers = 40",
+		"max_num_inbound_peers = \"forty\"\nmax_num_peers = 50\nsend_rat = 1", 1)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+
+	problems, err := CheckConfigFile(dir)
+	require.NoError(t, err)
+	require.Len(t, problems, 3, "%v", problems)
+
+	byKey := make(map[string]Problem)
+	for _, p := range problems {
+		byKey[p.Key] = p
+	}
+	assert.False(t, byKey["p2p.max_num_inbound_peers"].Warning)
+	assert.True(t, byKey["p2p.max_num_peers"].Warning)
+	assert.Contains(t, byKey["p2p.max_num_peers"].Suggestion, "max_num_inbound_peers")
+	assert.True(t, byKey["p2p.send_rat"].Warning)
+	assert.Contains(t, byKey["p2p.send_rat"].Suggestion, "p2p.send_rate")
+}
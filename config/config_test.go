@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -36,6 +37,20 @@ func TestConfigValidateBasic(t *testing.T) {
 	// tamper with timeout_propose
 	cfg.Consensus.TimeoutPropose = -10 * time.Second
 	assert.Error(t, cfg.ValidateBasic())
+
+	// seed nodes need the PEX reactor
+	cfg = DefaultConfig()
+	cfg.Mode = ModeSeed
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.P2P.PexReactor = false
+	assert.Error(t, cfg.ValidateBasic())
+
+	// archive nodes need a tx indexer
+	cfg = DefaultConfig()
+	cfg.Mode = ModeArchive
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.TxIndex.Indexer = "null"
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestTLSConfiguration(t *testing.T) {
@@ -61,6 +76,36 @@ func TestBaseConfigValidateBasic(t *testing.T) {
 	// tamper with log format
 	cfg.LogFormat = "invalid"
 	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.Mode = "invalid"
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.ShutdownGracePeriod = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorShareTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorSigner = "round-robin"
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorFailoverCooldown = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorGRPCAddr = "tcp://127.0.0.1:26659"
+	cfg.PrivValidatorListenAddr = "tcp://127.0.0.1:26658"
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.PrivValidatorGRPCAddr = "tcp://127.0.0.1:26659"
+	cfg.PrivValidatorGRPCCertFile = "client.crt"
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestRPCConfigValidateBasic(t *testing.T) {
@@ -75,6 +120,7 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		"TimeoutBroadcastTxCommit",
 		"MaxBodyBytes",
 		"MaxHeaderBytes",
+		"WebSocketWriteBufferSize",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -82,6 +128,15 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.SubscriptionBufferSize = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.SubscriptionBufferSize = 1
+
+	cfg.SlowClientPolicy = "block"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.SlowClientPolicy = SlowClientPolicyNotify
+	assert.NoError(t, cfg.ValidateBasic())
 }
 
 func TestP2PConfigValidateBasic(t *testing.T) {
@@ -91,10 +146,16 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 	fieldsToTest := []string{
 		"MaxNumInboundPeers",
 		"MaxNumOutboundPeers",
+		"MaxNumInboundPeersPerSubnet",
+		"MaxNumOutboundPeersPerSubnet",
 		"FlushThrottleTimeout",
 		"MaxPacketMsgPayloadSize",
 		"SendRate",
 		"RecvRate",
+		"CompressionMinSize",
+		"BanDuration",
+		"TaskPoolWorkers",
+		"TaskPoolQueueSize",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -102,6 +163,71 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.Handshake = "tls"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.Handshake = P2PHandshakeNoise
+
+	cfg.Transport = "udp"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.Transport = P2PTransportTCP
+
+	cfg.STUNServers = "stun.example.com:3478,stun.example.com"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.STUNServers = "stun.example.com:3478, [2001:db8::1]:3478"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.STUNServers = ""
+
+	cfg.SeedDNSListenAddress = "0.0.0.0:53"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.SeedDNSDomain = "seed.example.com"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.SeedDNSListenAddress, cfg.SeedDNSDomain = "", ""
+
+	// the persistent peers must be allowed
+	cfg.AllowedPeerIDs = "0123456789abcdef0123456789abcdef01234567"
+	assert.True(t, cfg.AllowlistMode())
+	assert.False(t, cfg.PexEnabled())
+	cfg.PersistentPeers = "0123456789abcdef0123456789abcdef01234567@1.2.3.4:26656"
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.PersistentPeers += ",89abcdef0123456789abcdef0123456789abcdef@5.6.7.8:26656"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.AllowedPeerIDs, cfg.PersistentPeers = "", ""
+	assert.True(t, cfg.PexEnabled())
+
+	for _, list := range []string{"0x30", "0x100:1", "0x30:0", "0x30:-1", "0x30:1,48:2", "mempool:1"} {
+		cfg.ChannelPriorities = list
+		assert.Error(t, cfg.ValidateBasic(), list)
+		cfg.ChannelPriorities = ""
+		cfg.ChannelSendRates = list
+		assert.Error(t, cfg.ValidateBasic(), list)
+		cfg.ChannelSendRates = ""
+	}
+	for _, list := range []string{"0x100", "0x21,33", "mempool"} {
+		cfg.CompressedChannels = list
+		assert.Error(t, cfg.ValidateBasic(), list)
+		cfg.CompressedChannels = ""
+	}
+}
+
+func TestParseChannelValues(t *testing.T) {
+	values, err := ParseChannelValues(" 0x30:1, 33:102400 ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[byte]int64{0x30: 1, 0x21: 102400}, values)
+
+	values, err = ParseChannelValues("")
+	require.NoError(t, err)
+	assert.Empty(t, values)
+}
+
+func TestParseChannelList(t *testing.T) {
+	channels, err := ParseChannelList(" 0x21, 48 ,")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x21, 0x30}, channels)
+
+	channels, err = ParseChannelList("")
+	require.NoError(t, err)
+	assert.Empty(t, channels)
 }
 
 func TestMempoolConfigValidateBasic(t *testing.T) {
@@ -113,6 +239,12 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		"MaxTxsBytes",
 		"CacheSize",
 		"MaxTxBytes",
+		"TTLNumBlocks",
+		"TTLDuration",
+		"SnapshotInterval",
+		"MaxTxsPerSender",
+		"TxStatusCacheSize",
+		"RecheckMaxTxs",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -120,6 +252,44 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.Type = "invalid"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.Type = MempoolTypeRemote
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RemoteAddr = "tcp://127.0.0.1:26659"
+	assert.NoError(t, cfg.ValidateBasic())
+}
+
+func TestStateSyncConfigValidateBasic(t *testing.T) {
+	cfg := TestStateSyncConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.Enable = true
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RPCServers = []string{"127.0.0.1:26657", "127.0.0.1:26658"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TrustHeight = 100
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TrustHash = "not hex"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TrustHash = "0102ab"
+	assert.NoError(t, cfg.ValidateBasic())
+	assert.Equal(t, []byte{1, 2, 0xab}, cfg.TrustHashBytes())
+
+	cfg.RPCServers = []string{"127.0.0.1:26657"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RPCServers = []string{"127.0.0.1:26657", ""}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RPCServers = []string{"127.0.0.1:26657", "127.0.0.1:26658"}
+	cfg.TrustPeriod = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TrustPeriod = time.Hour
+	cfg.DiscoveryTime = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.DiscoveryTime = time.Second
+	cfg.BackfillRetainHeight = -1
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestFastSyncConfigValidateBasic(t *testing.T) {
@@ -132,6 +302,49 @@ func TestFastSyncConfigValidateBasic(t *testing.T) {
 
 	cfg.Version = "invalid"
 	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.Version = "v0"
+	cfg.MaxPendingRequestsPerPeer = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.MaxPendingRequestsPerPeer = 20
+	cfg.ParallelRequests = 3
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.ParallelRequests = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ParallelRequests = 4
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.ParallelRequests = 1
+	cfg.PeerTimeout = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PeerTimeout = time.Minute
+	cfg.MinRecvRate = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MinRecvRate = 1024
+	cfg.PeerWindowSize = cfg.PeerSampleRate / 2
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.PeerWindowSize = 2 * time.Minute
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.MaxBufferBytes = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxBufferBytes = 0
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.MaxPeerRate = cfg.MinRecvRate - 1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxPeerRate = cfg.MinRecvRate
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.MaxPeerRate = 0
+
+	cfg.NoPeersTimeout = -time.Second
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.NoPeersTimeout = 0
+	cfg.NoPeersMaxLag = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.NoPeersMaxLag = 0
+	assert.NoError(t, cfg.ValidateBasic())
 }
 
 func TestConsensusConfigValidateBasic(t *testing.T) {
@@ -139,6 +352,9 @@ func TestConsensusConfigValidateBasic(t *testing.T) {
 	assert.NoError(t, cfg.ValidateBasic())
 
 	fieldsToTest := []string{
+		"WalMaxFileSize",
+		"WalMaxTotalSize",
+		"WalMaxFiles",
 		"TimeoutPropose",
 		"TimeoutProposeDelta",
 		"TimeoutPrevote",
@@ -146,6 +362,10 @@ func TestConsensusConfigValidateBasic(t *testing.T) {
 		"TimeoutPrecommit",
 		"TimeoutPrecommitDelta",
 		"TimeoutCommit",
+		"TimeoutAdaptiveMin",
+		"TimeoutAdaptiveMax",
+		"OfflineProposerSlots",
+		"TimeoutProposeOffline",
 		"CreateEmptyBlocksInterval",
 		"PeerGossipSleepDuration",
 		"PeerQueryMaj23SleepDuration",
@@ -156,6 +376,17 @@ func TestConsensusConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg = TestConsensusConfig()
+	cfg.WalMaxTotalSize = cfg.WalMaxFileSize - 1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WalMaxTotalSize = 0
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.WalCompression = "zip"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.WalCompression = "snappy"
+	assert.NoError(t, cfg.ValidateBasic())
 }
 
 func TestInstrumentationConfigValidateBasic(t *testing.T) {
@@ -165,4 +396,12 @@ func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	// tamper with maximum open connections
 	cfg.MaxOpenConnections = -1
 	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxOpenConnections = 3
+
+	cfg.OTLPTraceSampleRate = 1.5
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.OTLPTraceSampleRate = 1
+
+	cfg.OTLPMetricsPushInterval = -1
+	assert.Error(t, cfg.ValidateBasic())
 }
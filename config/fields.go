@@ -0,0 +1,96 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Field is a field of the config.
+type Field struct {
+	// Key of the field, with the section as prefix (e.g. "p2p.laddr").
+	Key string
+	// Value of the field.
+	Value interface{}
+}
+
+// Fields returns all fields of conf, sorted by key. The root directory is
+// not included.
+func Fields(conf *Config) []Field {
+	var fields []Field
+	collectFields(reflect.ValueOf(conf).Elem(), "", &fields)
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields
+}
+
+func collectFields(v reflect.Value, prefix string, fields *[]Field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		name, opts := parseTag(f)
+		if name == "-" || name == "home" {
+			continue
+		}
+		if opts == "squash" {
+			collectFields(v.Field(i), prefix, fields)
+			continue
+		}
+		if f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
+			if !v.Field(i).IsNil() {
+				collectFields(v.Field(i).Elem(), prefix+name+".", fields)
+			}
+			continue
+		}
+		*fields = append(*fields, Field{Key: prefix + name, Value: v.Field(i).Interface()})
+	}
+}
+
+// EnvVar returns the name of the environment variable overriding the field
+// with the given key, e.g. TM_P2P_LADDR for "p2p.laddr" and the prefix "TM".
+func EnvVar(prefix, key string) string {
+	return strings.ToUpper(prefix + "_" + strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Set sets the field with the given key to value, which is decoded the same
+// way as the value of an environment variable (e.g. "10s" for a duration).
+// The config is not validated.
+func (cfg *Config) Set(key, value string) error {
+	known := false
+	for _, f := range Fields(cfg) {
+		if f.Key == key {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return errors.Errorf("unknown config field %q", key)
+	}
+	v := viper.New()
+	v.Set(key, value)
+	if err := v.Unmarshal(cfg); err != nil {
+		return errors.Wrapf(err, "invalid value for %s", key)
+	}
+	return nil
+}
+
+// Copy returns a copy of cfg whose sections can be changed without affecting
+// cfg.
+func (cfg *Config) Copy() *Config {
+	c := *cfg
+	v := reflect.ValueOf(&c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.Struct && !f.IsNil() {
+			section := reflect.New(f.Type().Elem())
+			section.Elem().Set(f.Elem())
+			f.Set(section)
+		}
+	}
+	return &c
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// JournalEntry is a config change made at runtime, as recorded in the journal
+// file (see BaseConfig.JournalFile).
+type JournalEntry struct {
+	Time  time.Time `json:"time"`
+	Key   string    `json:"key"`
+	Value string    `json:"value"`
+}
+
+// AppendJournal appends the given entry to the journal file at path, creating
+// it if necessary.
+func AppendJournal(path string, entry JournalEntry) error {
+	bz, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(bz, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadJournal returns the entries of the journal file at path, oldest first.
+// A missing file has no entries.
+func ReadJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrapf(err, "%s:%d", path, line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// ApplyJournal sets the fields of the given entries in order, so that later
+// changes of a field override earlier ones, and validates the result.
+func (cfg *Config) ApplyJournal(entries []JournalEntry) error {
+	for _, entry := range entries {
+		if err := cfg.Set(entry.Key, entry.Value); err != nil {
+			return err
+		}
+	}
+	return cfg.ValidateBasic()
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSet(t *testing.T) {
+	conf := DefaultConfig()
+	require.NoError(t, conf.Set("consensus.timeout_commit", "3s"))
+	require.NoError(t, conf.Set("mempool.cache_size", "42"))
+	require.NoError(t, conf.Set("log_level", "consensus:debug,*:info"))
+	assert.Equal(t, 3*time.Second, conf.Consensus.TimeoutCommit)
+	assert.Equal(t, 42, conf.Mempool.CacheSize)
+	assert.Equal(t, "consensus:debug,*:info", conf.LogLevel)
+	// other fields are left alone
+	assert.Equal(t, DefaultMempoolConfig().Size, conf.Mempool.Size)
+
+	assert.Error(t, conf.Set("mempool.no_such_field", "1"))
+	assert.Error(t, conf.Set("mempool.cache_size", "many"))
+}
+
+func TestConfigCopy(t *testing.T) {
+	conf := DefaultConfig()
+	c := conf.Copy()
+	c.Mempool.CacheSize = 1
+	c.LogLevel = "error"
+	assert.Equal(t, DefaultMempoolConfig().CacheSize, conf.Mempool.CacheSize)
+	assert.Equal(t, DefaultConfig().LogLevel, conf.LogLevel)
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.jsonl")
+
+	entries, err := ReadJournal(path)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for _, e := range []JournalEntry{
+		{Key: "mempool.cache_size", Value: "100"},
+		{Key: "p2p.send_rate", Value: "1000"},
+		{Key: "mempool.cache_size", Value: "200"},
+	} {
+		require.NoError(t, AppendJournal(path, e))
+	}
+	entries, err = ReadJournal(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	conf := DefaultConfig()
+	require.NoError(t, conf.ApplyJournal(entries))
+	assert.Equal(t, 200, conf.Mempool.CacheSize)
+	assert.EqualValues(t, 1000, conf.P2P.SendRate)
+
+	assert.Error(t, conf.ApplyJournal([]JournalEntry{{Key: "mempool.cache_size", Value: "-1"}}))
+}
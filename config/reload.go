@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// reloadableFields are the keys of config fields which can be applied to a
+// running node without restarting it. Changes to any other field are only
+// picked up on restart.
+var reloadableFields = map[string]struct{}{
+	"log_level": {},
+
+	"rpc.max_subscription_clients":     {},
+	"rpc.max_subscriptions_per_client": {},
+	"rpc.subscription_buffer_size":     {},
+	"rpc.slow_client_policy":           {},
+	"rpc.max_requests_per_second":      {},
+
+	"p2p.persistent_peers":            {},
+	"p2p.unconditional_peer_ids":      {},
+	"p2p.private_peer_ids":            {},
+	"p2p.send_rate":                   {},
+	"p2p.recv_rate":                   {},
+	"p2p.channel_priorities":          {},
+	"p2p.channel_send_rates":          {},
+	"p2p.mempool_yields_to_consensus": {},
+	"p2p.compression_min_size":        {},
+
+	"consensus.timeout_propose":         {},
+	"consensus.timeout_propose_delta":   {},
+	"consensus.timeout_prevote":         {},
+	"consensus.timeout_prevote_delta":   {},
+	"consensus.timeout_precommit":       {},
+	"consensus.timeout_precommit_delta": {},
+	"consensus.timeout_commit":          {},
+	"consensus.timeout_adaptive":        {},
+	"consensus.timeout_adaptive_min":    {},
+	"consensus.timeout_adaptive_max":    {},
+	"consensus.offline_proposer_slots":  {},
+	"consensus.timeout_propose_offline": {},
+	"consensus.skip_timeout_commit":     {},
+
+	"mempool.cache_size":       {},
+	"mempool.broadcast_fanout": {},
+
+	"fastsync.max_pending_requests_per_peer": {},
+	"fastsync.max_buffer_bytes":              {},
+	"fastsync.max_peer_rate":                 {},
+}
+
+// IsReloadable returns true if the field with the given key (e.g.
+// "consensus.timeout_commit") can be applied to a running node.
+func IsReloadable(key string) bool {
+	_, ok := reloadableFields[key]
+	return ok
+}
+
+// ReloadResult describes the outcome of reloading the configuration of a
+// running node.
+type ReloadResult struct {
+	// Applied lists the changed fields which were applied.
+	Applied []string `json:"applied"`
+	// RestartRequired lists the changed fields which will only take effect
+	// after the node is restarted.
+	RestartRequired []string `json:"restart_required"`
+}
+
+// ChangedFields returns the sorted keys of all fields whose values differ
+// between the two configs. Keys are the same as in config.toml, with the
+// section as prefix (e.g. "p2p.send_rate"). Root directories are ignored.
+func ChangedFields(oldCfg, newCfg *Config) []string {
+	var changed []string
+	diffStruct(reflect.ValueOf(oldCfg).Elem(), reflect.ValueOf(newCfg).Elem(), "", &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+func diffStruct(a, b reflect.Value, prefix string, changed *[]string) {
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		name, opts := parseTag(f)
+		if name == "-" || name == "home" {
+			continue
+		}
+		fa, fb := a.Field(i), b.Field(i)
+
+		if opts == "squash" {
+			diffStruct(fa, fb, prefix, changed)
+			continue
+		}
+
+		key := prefix + name
+		if f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct {
+			switch {
+			case fa.IsNil() && fb.IsNil():
+			case fa.IsNil() || fb.IsNil():
+				*changed = append(*changed, key)
+			default:
+				diffStruct(fa.Elem(), fb.Elem(), key+".", changed)
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+			*changed = append(*changed, key)
+		}
+	}
+}
+
+func parseTag(f reflect.StructField) (name, opts string) {
+	tag := f.Tag.Get("mapstructure")
+	if tag == "" {
+		return strings.ToLower(f.Name), ""
+	}
+	parts := strings.SplitN(tag, ",", 2)
+	name = parts[0]
+	if len(parts) > 1 {
+		opts = parts[1]
+	}
+	return name, opts
+}
+
+// LoadConfigFile reads the config.toml file from the given root directory.
+// Fields missing from the file are set to their defaults.
+func LoadConfigFile(rootDir string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(rootDir, defaultConfigFilePath))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "failed to read config file")
+	}
+	conf := DefaultConfig()
+	if err := v.Unmarshal(conf); err != nil {
+		return nil, errors.Wrap(err, "failed to decode config file")
+	}
+	conf.SetRoot(rootDir)
+	if err := conf.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("error in config file: %v", err)
+	}
+	return conf, nil
+}
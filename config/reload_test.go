@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedFields(t *testing.T) {
+	oldCfg, newCfg := DefaultConfig(), DefaultConfig()
+	assert.Empty(t, ChangedFields(oldCfg, newCfg))
+
+	newCfg.SetRoot("/foo")
+	assert.Empty(t, ChangedFields(oldCfg, newCfg), "root dirs should be ignored")
+
+	newCfg.LogLevel = "debug"
+	newCfg.Moniker = "other"
+	newCfg.P2P.SendRate = 1
+	newCfg.Consensus.TimeoutCommit = 10 * time.Second
+	newCfg.RPC.CORSAllowedOrigins = []string{"*"}
+
+	changed := ChangedFields(oldCfg, newCfg)
+	assert.Equal(t, []string{
+		"consensus.timeout_commit",
+		"log_level",
+		"moniker",
+		"p2p.send_rate",
+		"rpc.cors_allowed_origins",
+	}, changed)
+
+	for _, key := range changed {
+		assert.Equal(t, key != "moniker" && key != "rpc.cors_allowed_origins", IsReloadable(key), key)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "config-reload-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(rootDir)
+
+	_, err = LoadConfigFile(rootDir)
+	assert.Error(t, err, "expected error for missing config file")
+
+	EnsureRoot(rootDir)
+	conf, err := LoadConfigFile(rootDir)
+	require.NoError(t, err)
+	assert.Equal(t, rootDir, conf.RootDir)
+	assert.Equal(t, rootDir, conf.P2P.RootDir)
+	assert.Equal(t, DefaultConfig().Consensus.TimeoutCommit, conf.Consensus.TimeoutCommit)
+
+	configFile := filepath.Join(rootDir, defaultConfigFilePath)
+	require.NoError(t, ioutil.WriteFile(configFile, []byte("[consensus]\ntimeout_commit = \"5s\"\n"), 0644))
+	conf, err = LoadConfigFile(rootDir)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, conf.Consensus.TimeoutCommit)
+	assert.Equal(t, DefaultConfig().Moniker, conf.Moniker)
+}
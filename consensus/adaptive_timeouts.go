@@ -0,0 +1,124 @@
+package consensus
+
+import (
+	"time"
+
+	cfg "github.com/tendermint/tendermint/config"
+)
+
+// latencyEstimator estimates a timeout from latency samples, like the TCP
+// retransmission timeout (RFC 6298): the smoothed latency plus four times its
+// smoothed deviation.
+type latencyEstimator struct {
+	samples  int
+	smoothed time.Duration
+	variance time.Duration
+}
+
+// add adds a latency sample.
+func (e *latencyEstimator) add(sample time.Duration) {
+	if e.samples == 0 {
+		e.smoothed = sample
+		e.variance = sample / 2
+	} else {
+		diff := e.smoothed - sample
+		if diff < 0 {
+			diff = -diff
+		}
+		e.variance = (3*e.variance + diff) / 4
+		e.smoothed = (7*e.smoothed + sample) / 8
+	}
+	e.samples++
+}
+
+// timeout returns the estimated timeout, and false if there are no samples
+// yet.
+func (e *latencyEstimator) timeout() (time.Duration, bool) {
+	if e.samples == 0 {
+		return 0, false
+	}
+	return e.smoothed + 4*e.variance, true
+}
+
+// adaptiveTimeouts estimates the propose, prevote and precommit timeouts from
+// the latencies observed in the latest rounds (see
+// consensus.timeout_adaptive): the time from entering the propose step to
+// receiving the complete proposal block of another validator, and from
+// entering the prevote (or precommit) step to receiving +2/3 of the prevotes
+// (or precommits).
+//
+// NOTE: not thread safe, it's only used by the State, under its mutex.
+type adaptiveTimeouts struct {
+	propose   latencyEstimator
+	prevote   latencyEstimator
+	precommit latencyEstimator
+
+	// start of the steps being measured, zero once measured
+	proposeStart   time.Time
+	prevoteStart   time.Time
+	precommitStart time.Time
+}
+
+// enterPropose, enterPrevote and enterPrecommit start measuring the latency of
+// the step entered.
+func (at *adaptiveTimeouts) enterPropose()   { at.proposeStart = time.Now() }
+func (at *adaptiveTimeouts) enterPrevote()   { at.prevoteStart = time.Now() }
+func (at *adaptiveTimeouts) enterPrecommit() { at.precommitStart = time.Now() }
+
+// proposalReceived, prevotesReceived and precommitsReceived add the latency of
+// the step, if it is being measured.
+func (at *adaptiveTimeouts) proposalReceived()   { stopMeasuring(&at.proposeStart, &at.propose) }
+func (at *adaptiveTimeouts) prevotesReceived()   { stopMeasuring(&at.prevoteStart, &at.prevote) }
+func (at *adaptiveTimeouts) precommitsReceived() { stopMeasuring(&at.precommitStart, &at.precommit) }
+
+// reset stops measuring the steps, at the start of a new round.
+func (at *adaptiveTimeouts) reset() {
+	at.proposeStart = time.Time{}
+	at.prevoteStart = time.Time{}
+	at.precommitStart = time.Time{}
+}
+
+func stopMeasuring(stepStart *time.Time, e *latencyEstimator) {
+	if stepStart.IsZero() {
+		return
+	}
+	e.add(time.Since(*stepStart))
+	*stepStart = time.Time{}
+}
+
+// Propose returns the amount of time to wait for a proposal.
+func (at *adaptiveTimeouts) Propose(config *cfg.ConsensusConfig, round int) time.Duration {
+	return at.timeout(config, &at.propose, config.TimeoutProposeDelta, round, config.Propose)
+}
+
+// Prevote returns the amount of time to wait for straggler prevotes.
+func (at *adaptiveTimeouts) Prevote(config *cfg.ConsensusConfig, round int) time.Duration {
+	return at.timeout(config, &at.prevote, config.TimeoutPrevoteDelta, round, config.Prevote)
+}
+
+// Precommit returns the amount of time to wait for straggler precommits.
+func (at *adaptiveTimeouts) Precommit(config *cfg.ConsensusConfig, round int) time.Duration {
+	return at.timeout(config, &at.precommit, config.TimeoutPrecommitDelta, round, config.Precommit)
+}
+
+// timeout returns the timeout estimated by e, within the configured bounds,
+// plus delta for each round, or the configured one if the timeouts aren't
+// adaptive or there are no samples yet.
+func (at *adaptiveTimeouts) timeout(config *cfg.ConsensusConfig, e *latencyEstimator,
+	delta time.Duration, round int, static func(round int) time.Duration) time.Duration {
+
+	if !config.TimeoutAdaptive {
+		return static(round)
+	}
+	timeout, ok := e.timeout()
+	if !ok {
+		return static(round)
+	}
+	if timeout < config.TimeoutAdaptiveMin {
+		timeout = config.TimeoutAdaptiveMin
+	}
+	if timeout > config.TimeoutAdaptiveMax {
+		timeout = config.TimeoutAdaptiveMax
+	}
+	return timeout + delta*time.Duration(round)
+}
@@ -0,0 +1,67 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	cfg "github.com/tendermint/tendermint/config"
+)
+
+func TestLatencyEstimator(t *testing.T) {
+	var e latencyEstimator
+	_, ok := e.timeout()
+	assert.False(t, ok)
+
+	e.add(100 * time.Millisecond)
+	timeout, ok := e.timeout()
+	assert.True(t, ok)
+	assert.Equal(t, 300*time.Millisecond, timeout) // 100ms + 4 * 50ms
+
+	// converges to a steady latency
+	for i := 0; i < 100; i++ {
+		e.add(100 * time.Millisecond)
+	}
+	timeout, _ = e.timeout()
+	assert.InDelta(t, float64(100*time.Millisecond), float64(timeout), float64(time.Millisecond))
+
+	// and grows with a jittery one
+	for i := 0; i < 100; i++ {
+		e.add(time.Duration(50+100*(i%2)) * time.Millisecond)
+	}
+	timeout, _ = e.timeout()
+	assert.True(t, timeout > 250*time.Millisecond, timeout)
+}
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	config := cfg.DefaultConsensusConfig()
+	var at adaptiveTimeouts
+
+	// static until enabled, and until measured
+	at.prevote.add(time.Second)
+	assert.Equal(t, config.Prevote(1), at.Prevote(config, 1))
+	config.TimeoutAdaptive = true
+	assert.Equal(t, config.Propose(1), at.Propose(config, 1))
+	assert.Equal(t, 3*time.Second+config.TimeoutPrevoteDelta, at.Prevote(config, 1))
+
+	// within the bounds
+	config.TimeoutAdaptiveMax = 2 * time.Second
+	assert.Equal(t, 2*time.Second, at.Prevote(config, 0))
+	at.precommit.add(time.Millisecond)
+	assert.Equal(t, config.TimeoutAdaptiveMin, at.Precommit(config, 0))
+
+	// only the steps entered are measured
+	at.proposalReceived()
+	_, ok := at.propose.timeout()
+	assert.False(t, ok)
+	at.enterPropose()
+	at.reset()
+	at.proposalReceived()
+	_, ok = at.propose.timeout()
+	assert.False(t, ok)
+	at.enterPropose()
+	at.proposalReceived()
+	_, ok = at.propose.timeout()
+	assert.True(t, ok)
+}
@@ -232,6 +232,8 @@ func sendProposalAndParts(
 
 	// votes
 	cs.mtx.Lock()
+	// forget the votes signed for the other proposal, to sign conflicting ones
+	cs.signState = &signState{}
 	prevote, _ := cs.signVote(types.PrevoteType, blockHash, parts.Header())
 	precommit, _ := cs.signVote(types.PrecommitType, blockHash, parts.Header())
 	cs.mtx.Unlock()
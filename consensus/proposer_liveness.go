@@ -0,0 +1,44 @@
+package consensus
+
+import (
+	"github.com/tendermint/tendermint/types"
+)
+
+// proposerLiveness counts the consecutive rounds ended without a proposal of
+// their proposer, for each validator, so that the propose timeout can be
+// shortened when the proposer is known to be offline (see
+// consensus.offline_proposer_slots).
+//
+// NOTE: not thread safe, it's only used by the State, under its mutex.
+type proposerLiveness struct {
+	missed map[string]int // by address
+}
+
+// slotMissed records that a round proposed by addr ended without its
+// proposal.
+func (pl *proposerLiveness) slotMissed(addr types.Address) {
+	if pl.missed == nil {
+		pl.missed = make(map[string]int)
+	}
+	pl.missed[string(addr)]++
+}
+
+// proposed records that a proposal of addr was received.
+func (pl *proposerLiveness) proposed(addr types.Address) {
+	delete(pl.missed, string(addr))
+}
+
+// isOffline returns true if addr has missed its last slots rounds as proposer.
+// It's always false if slots is 0.
+func (pl *proposerLiveness) isOffline(addr types.Address, slots int) bool {
+	return slots > 0 && pl.missed[string(addr)] >= slots
+}
+
+// prune forgets the validators which aren't in vals.
+func (pl *proposerLiveness) prune(vals *types.ValidatorSet) {
+	for addr := range pl.missed {
+		if !vals.HasAddress([]byte(addr)) {
+			delete(pl.missed, addr)
+		}
+	}
+}
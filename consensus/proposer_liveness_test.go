@@ -0,0 +1,34 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestProposerLiveness(t *testing.T) {
+	valSet, _ := types.RandValidatorSet(2, 10)
+	val0, val1 := valSet.Validators[0].Address, valSet.Validators[1].Address
+
+	var pl proposerLiveness
+	assert.False(t, pl.isOffline(val0, 2))
+
+	pl.slotMissed(val0)
+	assert.False(t, pl.isOffline(val0, 2))
+	pl.slotMissed(val0)
+	assert.True(t, pl.isOffline(val0, 2))
+	assert.False(t, pl.isOffline(val0, 0), "disabled")
+	assert.False(t, pl.isOffline(val1, 2))
+
+	// online again once it proposes
+	pl.proposed(val0)
+	assert.False(t, pl.isOffline(val0, 1))
+
+	// the validators leaving the validator set are forgotten
+	pl.slotMissed(val1)
+	newValSet, _ := types.RandValidatorSet(1, 10)
+	pl.prune(newValSet)
+	assert.Empty(t, pl.missed)
+}
@@ -97,7 +97,25 @@ func (cs *State) readReplayMessage(msg *TimedWALMessage, newStepSub types.Subscr
 
 // Replay only those messages since the last block.  `timeoutRoutine` should
 // run concurrently to read off tickChan.
+// WALReplay describes the replay of the consensus WAL when the consensus State
+// started.
+type WALReplay struct {
+	// Height whose messages were replayed.
+	Height int64 `json:"height"`
+	// Number of messages replayed.
+	Messages int           `json:"messages"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
 func (cs *State) catchupReplay(csHeight int64) error {
+	replay := WALReplay{Height: csHeight, Start: time.Now()}
+	defer func() {
+		replay.Duration = time.Since(replay.Start)
+		cs.mtx.Lock()
+		cs.walReplay = &replay
+		cs.mtx.Unlock()
+	}()
 
 	// Set replayMode to true so we don't log signing errors.
 	cs.replayMode = true
@@ -160,6 +178,7 @@ LOOP:
 		if err := cs.readReplayMessage(msg, nil); err != nil {
 			return err
 		}
+		replay.Messages++
 	}
 	cs.Logger.Info("Replay: Done")
 	return nil
@@ -205,7 +224,8 @@ type Handshaker struct {
 	genDoc       *types.GenesisDoc
 	logger       log.Logger
 
-	nBlocks int // number of blocks applied to the state
+	nBlocks   int   // number of blocks applied to the state
+	appHeight int64 // last block height reported by the app
 }
 
 func NewHandshaker(stateDB dbm.DB, state sm.State,
@@ -237,6 +257,12 @@ func (h *Handshaker) NBlocks() int {
 	return h.nBlocks
 }
 
+// AppBlockHeight returns the last block height the app reported in the
+// handshake.
+func (h *Handshaker) AppBlockHeight() int64 {
+	return h.appHeight
+}
+
 // TODO: retry the handshake/replay if it fails ?
 func (h *Handshaker) Handshake(proxyApp proxy.AppConns) error {
 
@@ -250,6 +276,7 @@ func (h *Handshaker) Handshake(proxyApp proxy.AppConns) error {
 	if blockHeight < 0 {
 		return fmt.Errorf("got a negative last block height (%d) from the app", blockHeight)
 	}
+	h.appHeight = blockHeight
 	appHash := res.LastBlockAppHash
 
 	h.logger.Info("ABCI Handshake App Info",
@@ -347,6 +374,11 @@ func (h *Handshaker) ReplayBlocks(
 		assertAppHashEqualsOneFromState(appHash, state)
 		return appHash, nil
 
+	case appBlockHeight < h.store.Base()-1:
+		// the blocks the app would need to replay are not in the store, e.g.
+		// after state sync (the app can be 1 behind, the next block being replayed)
+		return appHash, sm.ErrAppBlockHeightTooLow{AppHeight: appBlockHeight, StoreBase: h.store.Base()}
+
 	case storeBlockHeight < appBlockHeight:
 		// the app should never be ahead of the store (but this is under app's control)
 		return appHash, sm.ErrAppBlockHeightTooHigh{CoreHeight: storeBlockHeight, AppHeight: appBlockHeight}
@@ -2,6 +2,7 @@ package consensus
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,7 +13,10 @@ import (
 	"github.com/pkg/errors"
 	dbm "github.com/tendermint/tm-db"
 
+	abci "github.com/tendermint/tendermint/abci/types"
 	cfg "github.com/tendermint/tendermint/config"
+	cstypes "github.com/tendermint/tendermint/consensus/types"
+	"github.com/tendermint/tendermint/libs/autofile"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
 	"github.com/tendermint/tendermint/mock"
@@ -25,167 +29,615 @@ import (
 const (
 	// event bus subscriber
 	subscriber = "replay-file"
+	// capacity of its subscription: a message can cause several steps, whose
+	// events are read when their round states are replayed
+	newStepSubCapacity = 100
 )
 
 //--------------------------------------------------------
 // replay messages interactively or all at once
 
-// replay the wal file
-func RunReplayFile(config cfg.BaseConfig, csConfig *cfg.ConsensusConfig, console bool) {
-	consensusState := newConsensusStateForReplay(config, csConfig)
+// ReplayOptions are the options of RunReplayFile.
+type ReplayOptions struct {
+	// Console starts the replay in the console, before the first message.
+	Console bool
+	// Breakpoints stop the replay in the console.
+	Breakpoints []Breakpoint
+	// Until stops the replay once the consensus has gone past its height, or
+	// its height and round if the round isn't -1, rather than at the end of the
+	// WAL. Its step is ignored, and the whole WAL is replayed if its height is
+	// 0.
+	Until Breakpoint
+	// MockApp replays the responses and the app hashes recorded by the node,
+	// in its state DB and block store, rather than executing the blocks with
+	// the proxy app, so that the replay is deterministic and needs no app.
+	MockApp bool
+	// Trace prints each message replayed: the step transitions, the timeouts
+	// fired, and the proposals and votes received.
+	Trace bool
+	// In and Out are the input and output of the console, stdin and stdout if
+	// nil.
+	In  io.Reader
+	Out io.Writer
+}
 
-	if err := consensusState.ReplayFile(csConfig.WalFile(), console); err != nil {
+// RunReplayFile replays the consensus WAL from the genesis, against the app of
+// config (which must be at height 0), and checks the blocks committed and the
+// app hashes against the block store of the node.
+func RunReplayFile(config cfg.BaseConfig, csConfig *cfg.ConsensusConfig, opts ReplayOptions) {
+	if err := ReplayFile(config, csConfig, opts); err != nil {
 		tmos.Exit(fmt.Sprintf("Error during consensus replay: %v", err))
 	}
 }
 
-// Replay msgs in file or start the console
-func (cs *State) ReplayFile(file string, console bool) error {
+// ReplayFile is like RunReplayFile, but returns the error which stopped the
+// replay, if any.
+func ReplayFile(config cfg.BaseConfig, csConfig *cfg.ConsensusConfig, opts ReplayOptions) error {
+	pb, err := newPlayback(config, csConfig, opts)
+	if err != nil {
+		return err
+	}
+	defer pb.stop()
+
+	return pb.run()
+}
+
+//------------------------------------------------
+// breakpoints
+
+// Breakpoint stops the replay when the consensus reaches a height and,
+// optionally, a round and a step.
+type Breakpoint struct {
+	Height int64
+	// the round, or -1 for any round
+	Round int
+	// the step, or 0 for any step
+	Step cstypes.RoundStepType
+}
+
+// ParseBreakpoint parses a breakpoint: a height, height/round or
+// height/round/step, where the step is the name of a step, with or without its
+// RoundStep prefix (e.g. precommit or RoundStepPrecommit).
+func ParseBreakpoint(s string) (Breakpoint, error) {
+	bp := Breakpoint{Round: -1}
+	parts := strings.Split(s, "/")
+	if len(parts) > 3 {
+		return bp, errors.Errorf("invalid breakpoint %q, expected height[/round[/step]]", s)
+	}
+	height, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || height <= 0 {
+		return bp, errors.Errorf("invalid height %q", parts[0])
+	}
+	bp.Height = height
+	if len(parts) > 1 {
+		round, err := strconv.Atoi(parts[1])
+		if err != nil || round < 0 {
+			return bp, errors.Errorf("invalid round %q", parts[1])
+		}
+		bp.Round = round
+	}
+	if len(parts) > 2 {
+		for step := cstypes.RoundStepNewHeight; step <= cstypes.RoundStepCommit; step++ {
+			name := step.String()
+			if strings.EqualFold(parts[2], name) || strings.EqualFold(parts[2], strings.TrimPrefix(name, "RoundStep")) {
+				bp.Step = step
+			}
+		}
+		if bp.Step == 0 {
+			return bp, errors.Errorf("unknown step %q", parts[2])
+		}
+	}
+	return bp, nil
+}
 
-	if cs.IsRunning() {
-		return errors.New("cs is already running, cannot replay")
+func (bp Breakpoint) String() string {
+	s := strconv.FormatInt(bp.Height, 10)
+	if bp.Round >= 0 {
+		s += "/" + strconv.Itoa(bp.Round)
 	}
-	if cs.wal != nil {
-		return errors.New("cs wal is open, cannot replay")
+	if bp.Step != 0 {
+		s += "/" + strings.TrimPrefix(bp.Step.String(), "RoundStep")
 	}
+	return s
+}
 
-	cs.startForReplay()
+func (bp Breakpoint) matches(rs *cstypes.RoundState) bool {
+	return rs.Height == bp.Height &&
+		(bp.Round < 0 || rs.Round == bp.Round) &&
+		(bp.Step == 0 || rs.Step == bp.Step)
+}
 
-	// ensure all new step events are regenerated as expected
+// passed returns true if rs is past the height, or the height and round, of
+// bp.
+func (bp Breakpoint) passed(rs *cstypes.RoundState) bool {
+	return rs.Height > bp.Height ||
+		(bp.Round >= 0 && rs.Height == bp.Height && rs.Round > bp.Round)
+}
+
+//------------------------------------------------
+// playback manager
 
-	ctx := context.Background()
-	newStepSub, err := cs.eventBus.Subscribe(ctx, subscriber, types.EventQueryNewRoundStep)
+type playback struct {
+	config   cfg.BaseConfig
+	csConfig *cfg.ConsensusConfig
+	in       *bufio.Reader
+	out      io.Writer
+	// whether to stop in the console, at the breakpoints or divergences,
+	// rather than fail
+	interactive bool
+
+	genDoc *types.GenesisDoc
+	// the block store of the node, which the replay is checked against
+	nodeStoreDB dbm.DB
+	nodeStore   *store.BlockStore
+	// the state DB of the node, whose responses are replayed by the mock app,
+	// or nil to replay against the proxy app
+	nodeStateDB dbm.DB
+	until       Breakpoint
+	trace       bool
+
+	// reset by start
+	cs         *State
+	proxyApp   proxy.AppConns
+	eventBus   *types.EventBus
+	newStepSub types.Subscription
+	gr         *autofile.GroupReader
+	dec        *WALDecoder
+	count      int // how many msgs into the file are we
+	startCount int // how many msgs up to the first #ENDHEIGHT
+
+	breakpoints []Breakpoint
+	nextN       int  // replay N more msgs before the console
+	continuing  bool // replay until a breakpoint
+	lastHRS     string
+	atEnd       bool
+	// the first difference with the node, after which the replay can't go on
+	divergence error
+}
+
+func newPlayback(config cfg.BaseConfig, csConfig *cfg.ConsensusConfig, opts ReplayOptions) (*playback, error) {
+	pb := &playback{
+		config:      config,
+		csConfig:    csConfig,
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stdout,
+		interactive: opts.Console || len(opts.Breakpoints) > 0,
+		breakpoints: opts.Breakpoints,
+		continuing:  !opts.Console,
+		until:       opts.Until,
+		trace:       opts.Trace,
+	}
+	if opts.In != nil {
+		pb.in = bufio.NewReader(opts.In)
+	}
+	if opts.Out != nil {
+		pb.out = opts.Out
+	}
+
+	genDoc, err := sm.MakeGenesisDocFromFile(config.GenesisFile())
 	if err != nil {
-		return errors.Errorf("failed to subscribe %s to %v", subscriber, types.EventQueryNewRoundStep)
+		return nil, err
 	}
-	defer cs.eventBus.Unsubscribe(ctx, subscriber, types.EventQueryNewRoundStep)
+	pb.genDoc = genDoc
+	pb.nodeStoreDB = dbm.NewDB("blockstore", dbm.BackendType(config.DBBackend), config.DBDir())
+	pb.nodeStore = store.NewBlockStore(pb.nodeStoreDB)
+	if opts.MockApp {
+		pb.nodeStateDB = dbm.NewDB("state", dbm.BackendType(config.DBBackend), config.DBDir())
+	}
+
+	if err := pb.start(); err != nil {
+		pb.stop()
+		return nil, err
+	}
+	return pb, nil
+}
+
+// start creates a consensus state from the genesis, with in-memory stores,
+// opens the WAL and replays the blocks of the node up to the first height of
+// the WAL. The blocks are replayed on the state with new connections to the
+// app, which must be at height 0, or to a new mock app.
+func (pb *playback) start() error {
+	pb.stopState()
 
-	// just open the file for reading, no need to use wal
-	fp, err := os.OpenFile(file, os.O_RDONLY, 0600)
+	stateDB := dbm.NewMemDB()
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	state, err := sm.MakeGenesisState(pb.genDoc)
 	if err != nil {
 		return err
 	}
+	sm.SaveState(stateDB, state)
 
-	pb := newPlayback(file, fp, cs, cs.state.Copy())
-	defer pb.fp.Close() // nolint: errcheck
+	clientCreator := proxy.DefaultClientCreator(pb.config.ProxyApp, pb.config.ABCI, pb.config.DBDir())
+	if pb.nodeStateDB != nil {
+		clientCreator = proxy.NewLocalClientCreator(newRecordedApp(pb.nodeStateDB, pb.nodeStore))
+	}
+	pb.proxyApp = proxy.NewAppConns(clientCreator)
+	if err := pb.proxyApp.Start(); err != nil {
+		return errors.Wrap(err, "failed to start the proxy app connections")
+	}
+	pb.eventBus = types.NewEventBus()
+	if err := pb.eventBus.Start(); err != nil {
+		return errors.Wrap(err, "failed to start the event bus")
+	}
+	// ensure all new step events are regenerated as expected
+	pb.newStepSub, err = pb.eventBus.Subscribe(context.Background(), subscriber, types.EventQueryNewRoundStep,
+		newStepSubCapacity)
+	if err != nil {
+		return errors.Wrapf(err, "failed to subscribe %s to %v", subscriber, types.EventQueryNewRoundStep)
+	}
 
-	var nextN int // apply N msgs in a row
-	var msg *TimedWALMessage
-	for {
-		if nextN == 0 && console {
-			nextN = pb.replayConsoleLoop()
-		}
+	handshaker := NewHandshaker(stateDB, state, blockStore, pb.genDoc)
+	handshaker.SetEventBus(pb.eventBus)
+	if err := handshaker.Handshake(pb.proxyApp); err != nil {
+		return errors.Wrap(err, "handshake failed (the app must be at height 0: restart it with no state)")
+	}
+	state = sm.LoadState(stateDB)
+
+	mempool, evpool := mock.Mempool{}, sm.MockEvidencePool{}
+	blockExec := sm.NewBlockExecutor(stateDB, log.NewNopLogger(), pb.proxyApp.Consensus(), mempool, evpool)
+	blockExec.SetEventBus(pb.eventBus)
+
+	if pb.gr != nil {
+		pb.gr.Close()
+	}
+	pb.gr, err = autofile.OpenGroupReader(pb.csConfig.WalFile())
+	if err != nil {
+		return errors.Wrap(err, "failed to open the WAL")
+	}
+	pb.dec = NewWALDecoder(pb.gr)
+	pb.count = 0
 
-		msg, err = pb.dec.Decode()
+	// the first messages may be of a height whose start was pruned
+	var height int64
+	for {
+		msg, err := pb.dec.Decode()
 		if err == io.EOF {
-			return nil
+			return errors.New("no #ENDHEIGHT in the WAL")
 		} else if err != nil {
 			return err
 		}
-
-		if err := pb.cs.readReplayMessage(msg, newStepSub); err != nil {
-			return err
+		pb.count++
+		if m, ok := msg.Msg.(EndHeightMessage); ok {
+			height = m.Height
+			break
 		}
+	}
+	pb.startCount = pb.count
 
-		if nextN > 0 {
-			nextN--
+	if height > pb.nodeStore.Height() {
+		return errors.Errorf("the WAL starts after height %d, but the node only has the blocks up to %d",
+			height, pb.nodeStore.Height())
+	}
+	for h := int64(1); h <= height; h++ {
+		block := pb.nodeStore.LoadBlock(h)
+		meta := pb.nodeStore.LoadBlockMeta(h)
+		blockStore.SaveBlock(block, block.MakePartSet(types.BlockPartSizeBytes), pb.nodeStore.LoadSeenCommit(h))
+		if state, err = blockExec.ApplyBlock(state, meta.BlockID, block); err != nil {
+			return errors.Wrapf(err, "failed to apply block %d of the node", h)
+		}
+		if err := pb.checkHeight(h, meta.BlockID.Hash, state); err != nil {
+			return errors.Wrap(err, "the app diverged before the start of the WAL")
 		}
-		pb.count++
 	}
-}
-
-//------------------------------------------------
-// playback manager
-
-type playback struct {
-	cs *State
+	if height > 0 {
+		fmt.Fprintf(pb.out, "Replayed the blocks of the node up to height %d, where the WAL starts\n", height)
+	}
 
-	fp    *os.File
-	dec   *WALDecoder
-	count int // how many lines/msgs into the file are we
+	pb.cs = NewState(pb.csConfig, state.Copy(), blockExec, blockStore, mempool, evpool)
+	pb.cs.SetEventBus(pb.eventBus)
+	pb.cs.startForReplay()
+	pb.lastHRS = ""
+	pb.atEnd = false
+	pb.divergence = nil
+	return nil
+}
 
-	// replays can be reset to beginning
-	fileName     string   // so we can close/reopen the file
-	genesisState sm.State // so the replay session knows where to restart from
+func (pb *playback) stopState() {
+	if pb.eventBus != nil {
+		pb.eventBus.Stop()
+		pb.eventBus = nil
+	}
+	if pb.proxyApp != nil {
+		pb.proxyApp.Stop()
+		pb.proxyApp = nil
+	}
 }
 
-func newPlayback(fileName string, fp *os.File, cs *State, genState sm.State) *playback {
-	return &playback{
-		cs:           cs,
-		fp:           fp,
-		fileName:     fileName,
-		genesisState: genState,
-		dec:          NewWALDecoder(fp),
+func (pb *playback) stop() {
+	pb.stopState()
+	if pb.gr != nil {
+		pb.gr.Close()
+	}
+	pb.nodeStoreDB.Close()
+	if pb.nodeStateDB != nil {
+		pb.nodeStateDB.Close()
 	}
 }
 
-// go back count steps by resetting the state and running (pb.count - count) steps
-func (pb *playback) replayReset(count int, newStepSub types.Subscription) error {
-	pb.cs.Stop()
-	pb.cs.Wait()
+// run replays the WAL, stopping in the console when needed.
+func (pb *playback) run() error {
+	for {
+		if pb.atEnd && !pb.interactive {
+			return nil
+		}
+		if pb.atEnd || (!pb.continuing && pb.nextN == 0) {
+			if quit := pb.replayConsoleLoop(); quit {
+				return nil
+			}
+			if pb.atEnd {
+				continue
+			}
+		}
 
-	newCS := NewState(pb.cs.config, pb.genesisState.Copy(), pb.cs.blockExec,
-		pb.cs.blockStore, pb.cs.txNotifier, pb.cs.evpool)
-	newCS.SetEventBus(pb.cs.eventBus)
-	newCS.startForReplay()
+		err := pb.replayNext(!pb.continuing)
+		if err == io.EOF {
+			fmt.Fprintf(pb.out, "Replayed the %d messages of the WAL, up to height %d\n",
+				pb.count, pb.cs.state.LastBlockHeight)
+			pb.atEnd, pb.continuing, pb.nextN = true, false, 0
+			continue
+		} else if err != nil {
+			return err
+		}
+		if pb.nextN > 0 {
+			pb.nextN--
+		}
 
-	if err := pb.fp.Close(); err != nil {
-		return err
+		if pb.divergence != nil {
+			if !pb.interactive {
+				return pb.divergence
+			}
+			fmt.Fprintf(pb.out, "Divergence: %v\n", pb.divergence)
+			pb.continuing, pb.nextN = false, 0
+		}
+		rs := &pb.cs.RoundState
+		hrs := fmt.Sprintf("%d/%d/%d", rs.Height, rs.Round, rs.Step)
+		if hrs != pb.lastHRS {
+			pb.lastHRS = hrs
+			if pb.until.Height > 0 && pb.until.passed(rs) {
+				fmt.Fprintf(pb.out, "Replayed up to %v, stopped at %v/%v/%v\n", pb.until, rs.Height, rs.Round, rs.Step)
+				pb.atEnd, pb.continuing, pb.nextN = true, false, 0
+				continue
+			}
+			for i, bp := range pb.breakpoints {
+				if bp.matches(rs) {
+					fmt.Fprintf(pb.out, "Breakpoint %d (%v) at %v/%v/%v\n", i, bp, rs.Height, rs.Round, rs.Step)
+					pb.continuing, pb.nextN = false, 0
+					break
+				}
+			}
+		}
 	}
-	fp, err := os.OpenFile(pb.fileName, os.O_RDONLY, 0600)
+}
+
+// replayNext replays the next message of the WAL, printing it if verbose, and
+// checks the block committed if any.
+func (pb *playback) replayNext(verbose bool) error {
+	msg, err := pb.dec.Decode()
 	if err != nil {
 		return err
 	}
-	pb.fp = fp
-	pb.dec = NewWALDecoder(fp)
+	pb.count++
+	if pb.trace {
+		fmt.Fprintf(pb.out, "#%d %s %s\n", pb.count, msg.Time.UTC().Format(traceTimeFormat), traceMessage(msg.Msg))
+	} else if verbose {
+		kind, height, round := WALMessageKind(msg.Msg)
+		fmt.Fprintf(pb.out, "#%d %s %d/%d\n", pb.count, kind, height, round)
+	}
+	height := pb.cs.state.LastBlockHeight
+	if err := pb.cs.readReplayMessage(msg, pb.newStepSub); err != nil {
+		return err
+	}
+
+	if h := pb.cs.state.LastBlockHeight; h > height {
+		blockHash := pb.cs.blockStore.LoadBlockMeta(h).BlockID.Hash
+		fmt.Fprintf(pb.out, "Committed block %d (%X), app hash %X\n", h, blockHash, pb.cs.state.AppHash)
+		if err := pb.checkHeight(h, blockHash, pb.cs.state); err != nil && pb.divergence == nil {
+			pb.divergence = err
+		}
+	}
+	return nil
+}
+
+// checkHeight checks the block committed at height, and the state after it,
+// against the blocks of the node.
+func (pb *playback) checkHeight(height int64, blockHash []byte, state sm.State) error {
+	meta := pb.nodeStore.LoadBlockMeta(height)
+	if meta == nil {
+		return nil
+	}
+	if !bytes.Equal(blockHash, meta.BlockID.Hash) {
+		return errors.Errorf("committed block %X at height %d, the node committed %X",
+			blockHash, height, meta.BlockID.Hash)
+	}
+	next := pb.nodeStore.LoadBlockMeta(height + 1)
+	if next == nil {
+		return nil
+	}
+	if !bytes.Equal(state.AppHash, next.Header.AppHash) {
+		return errors.Errorf("the app hash after height %d is %X, the node's was %X",
+			height, state.AppHash, next.Header.AppHash)
+	}
+	if !bytes.Equal(state.LastResultsHash, next.Header.LastResultsHash) {
+		return errors.Errorf("the results hash of height %d is %X, the node's was %X",
+			height, state.LastResultsHash, next.Header.LastResultsHash)
+	}
+	return nil
+}
+
+// go back count steps by resetting the state and running (pb.count - count) steps
+func (pb *playback) replayReset(count int) error {
 	count = pb.count - count
-	fmt.Printf("Reseting from %d to %d\n", pb.count, count)
-	pb.count = 0
-	pb.cs = newCS
-	var msg *TimedWALMessage
-	for i := 0; i < count; i++ {
-		msg, err = pb.dec.Decode()
-		if err == io.EOF {
+	fmt.Fprintf(pb.out, "Reseting from %d to %d\n", pb.count, count)
+	if err := pb.start(); err != nil {
+		return err
+	}
+	for pb.count < count {
+		if err := pb.replayNext(false); err == io.EOF {
 			return nil
 		} else if err != nil {
 			return err
 		}
-		if err := pb.cs.readReplayMessage(msg, newStepSub); err != nil {
-			return err
-		}
-		pb.count++
 	}
 	return nil
 }
 
+const traceTimeFormat = "15:04:05.000"
+
+// traceMessage describes a WAL message in the trace of the replay.
+func traceMessage(msg WALMessage) string {
+	switch m := msg.(type) {
+	case types.EventDataRoundState:
+		return fmt.Sprintf("step %d/%d/%s", m.Height, m.Round, m.Step)
+	case timeoutInfo:
+		return fmt.Sprintf("timeout %d/%d/%v fired after %v", m.Height, m.Round, m.Step, m.Duration)
+	case msgInfo:
+		peerID := m.PeerID
+		if peerID == "" {
+			peerID = "local"
+		}
+		switch mm := m.Msg.(type) {
+		case *ProposalMessage:
+			return fmt.Sprintf("proposal %v from %s", mm.Proposal, peerID)
+		case *VoteMessage:
+			return fmt.Sprintf("vote %v from %s", mm.Vote, peerID)
+		}
+	}
+	kind, height, round := WALMessageKind(msg)
+	return fmt.Sprintf("%s %d/%d", kind, height, round)
+}
+
+//------------------------------------------------
+// mock app
+
+// recordedApp is an ABCI app which returns the responses recorded by the node
+// in its state DB, and the app hashes of its blocks, so that the WAL can be
+// replayed without the app. The blocks the node didn't commit get empty
+// responses.
+type recordedApp struct {
+	abci.BaseApplication
+
+	stateDB    dbm.DB
+	blockStore *store.BlockStore
+	state      sm.State // the last state of the node
+
+	height    int64
+	responses *sm.ABCIResponses
+	txCount   int
+}
+
+func newRecordedApp(stateDB dbm.DB, blockStore *store.BlockStore) *recordedApp {
+	return &recordedApp{
+		stateDB:    stateDB,
+		blockStore: blockStore,
+		state:      sm.LoadState(stateDB),
+	}
+}
+
+// Info returns the version of the app the node last ran, at height 0.
+func (app *recordedApp) Info(req abci.RequestInfo) abci.ResponseInfo {
+	return abci.ResponseInfo{AppVersion: uint64(app.state.Version.Consensus.App)}
+}
+
+// InitChain returns the validators and the consensus params the node had at
+// height 1, which may have been changed by the InitChain of the app.
+func (app *recordedApp) InitChain(req abci.RequestInitChain) abci.ResponseInitChain {
+	res := abci.ResponseInitChain{}
+	if vals, err := sm.LoadValidators(app.stateDB, 1); err == nil {
+		res.Validators = types.TM2PB.ValidatorUpdates(vals)
+	}
+	if params, err := sm.LoadConsensusParams(app.stateDB, 1); err == nil {
+		res.ConsensusParams = types.TM2PB.ConsensusParams(&params)
+	}
+	return res
+}
+
+func (app *recordedApp) BeginBlock(req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	app.height = req.Header.Height
+	app.txCount = 0
+	responses, err := sm.LoadABCIResponses(app.stateDB, app.height)
+	if err != nil {
+		responses = &sm.ABCIResponses{}
+	}
+	app.responses = responses
+	if responses.BeginBlock == nil {
+		return abci.ResponseBeginBlock{}
+	}
+	return *responses.BeginBlock
+}
+
+func (app *recordedApp) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx {
+	i := app.txCount
+	app.txCount++
+	// the response may be nil, as amino decodes an empty response to nil
+	if i >= len(app.responses.DeliverTxs) || app.responses.DeliverTxs[i] == nil {
+		return abci.ResponseDeliverTx{}
+	}
+	return *app.responses.DeliverTxs[i]
+}
+
+func (app *recordedApp) EndBlock(req abci.RequestEndBlock) abci.ResponseEndBlock {
+	if app.responses.EndBlock == nil {
+		return abci.ResponseEndBlock{}
+	}
+	return *app.responses.EndBlock
+}
+
+// Commit returns the app hash of the next block of the node, or of its last
+// state.
+func (app *recordedApp) Commit() abci.ResponseCommit {
+	if meta := app.blockStore.LoadBlockMeta(app.height + 1); meta != nil {
+		return abci.ResponseCommit{Data: meta.Header.AppHash}
+	}
+	if app.height == app.state.LastBlockHeight {
+		return abci.ResponseCommit{Data: app.state.AppHash}
+	}
+	return abci.ResponseCommit{}
+}
+
+// replayTicker ignores the timeouts scheduled by the consensus state, as the
+// replayed ones are in the WAL.
+type replayTicker struct{}
+
+func (replayTicker) Start() error                   { return nil }
+func (replayTicker) Stop() error                    { return nil }
+func (replayTicker) Chan() <-chan timeoutInfo       { return nil }
+func (replayTicker) ScheduleTimeout(ti timeoutInfo) {}
+func (replayTicker) SetLogger(log.Logger)           {}
+
 func (cs *State) startForReplay() {
-	cs.Logger.Error("Replay commands are disabled until someone updates them and writes tests")
-	/* TODO:!
-	// since we replay tocks we just ignore ticks
-		go func() {
-			for {
-				select {
-				case <-cs.tickChan:
-				case <-cs.Quit:
-					return
-				}
-			}
-		}()*/
+	cs.SetTimeoutTicker(replayTicker{})
 }
 
-// console function for parsing input and running commands
-func (pb *playback) replayConsoleLoop() int {
+const replayConsoleHelp = `Commands:
+  next [N]            replay the next message, or the next N messages
+  continue            replay until a breakpoint, a divergence or the end
+  back [N]            go back one message, or N messages (restarts the app
+                      connections, so only works with apps starting with no state)
+  break <breakpoint>  stop at height[/round[/step]], e.g. 10/0/precommit
+  breakpoints         list the breakpoints
+  delete <i>          delete breakpoint i
+  rs [field]          print the round state, or a field of it: short,
+                      validators, proposal, proposal_block, locked_round,
+                      locked_block, valid_round, valid_block or votes
+  votes [round] [prevote|precommit]
+                      print the vote sets of a round (the current one by default)
+  locked              print the locked and valid blocks
+  state               print the last committed state
+  n                   print the number of messages replayed
+  quit                stop the replay`
+
+// replayConsoleLoop reads commands until one replays messages, and returns
+// whether to quit.
+func (pb *playback) replayConsoleLoop() (quit bool) {
 	for {
-		fmt.Printf("> ")
-		bufReader := bufio.NewReader(os.Stdin)
-		line, more, err := bufReader.ReadLine()
-		if more {
-			tmos.Exit("input is too long")
-		} else if err != nil {
-			tmos.Exit(err.Error())
+		fmt.Fprint(pb.out, "> ")
+		line, err := pb.in.ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(pb.out)
+			return true
 		}
 
-		tokens := strings.Split(string(line), " ")
+		tokens := strings.Fields(line)
 		if len(tokens) == 0 {
 			continue
 		}
@@ -194,16 +646,26 @@ func (pb *playback) replayConsoleLoop() int {
 		case "next":
 			// "next" -> replay next message
 			// "next N" -> replay next N messages
-
+			if !pb.canReplay() {
+				continue
+			}
 			if len(tokens) == 1 {
-				return 0
+				return false
 			}
 			i, err := strconv.Atoi(tokens[1])
-			if err != nil {
-				fmt.Println("next takes an integer argument")
+			if err != nil || i <= 0 {
+				fmt.Fprintln(pb.out, "next takes a positive integer argument")
 			} else {
-				return i
+				pb.nextN = i
+				return false
+			}
+
+		case "continue", "c":
+			if !pb.canReplay() {
+				continue
 			}
+			pb.continuing = true
+			return false
 
 		case "back":
 			// "back" -> go back one message
@@ -211,30 +673,49 @@ func (pb *playback) replayConsoleLoop() int {
 
 			// NOTE: "back" is not supported in the state machine design,
 			// so we restart and replay up to
+			i := 1
+			if len(tokens) > 1 {
+				if i, err = strconv.Atoi(tokens[1]); err != nil || i <= 0 {
+					fmt.Fprintln(pb.out, "back takes a positive integer argument")
+					continue
+				}
+			}
+			if i > pb.count-pb.startCount {
+				fmt.Fprintf(pb.out, "argument to back must not be larger than the messages replayed (%d)\n",
+					pb.count-pb.startCount)
+			} else if err := pb.replayReset(i); err != nil {
+				fmt.Fprintf(pb.out, "Replay reset error: %v\n", err)
+				return true
+			}
 
-			ctx := context.Background()
-			// ensure all new step events are regenerated as expected
-
-			newStepSub, err := pb.cs.eventBus.Subscribe(ctx, subscriber, types.EventQueryNewRoundStep)
+		case "break":
+			if len(tokens) != 2 {
+				fmt.Fprintln(pb.out, "break takes a height[/round[/step]] argument")
+				continue
+			}
+			bp, err := ParseBreakpoint(tokens[1])
 			if err != nil {
-				tmos.Exit(fmt.Sprintf("failed to subscribe %s to %v", subscriber, types.EventQueryNewRoundStep))
+				fmt.Fprintln(pb.out, err)
+				continue
 			}
-			defer pb.cs.eventBus.Unsubscribe(ctx, subscriber, types.EventQueryNewRoundStep)
+			pb.breakpoints = append(pb.breakpoints, bp)
+			fmt.Fprintf(pb.out, "Breakpoint %d at %v\n", len(pb.breakpoints)-1, bp)
 
-			if len(tokens) == 1 {
-				if err := pb.replayReset(1, newStepSub); err != nil {
-					pb.cs.Logger.Error("Replay reset error", "err", err)
-				}
-			} else {
-				i, err := strconv.Atoi(tokens[1])
-				if err != nil {
-					fmt.Println("back takes an integer argument")
-				} else if i > pb.count {
-					fmt.Printf("argument to back must not be larger than the current count (%d)\n", pb.count)
-				} else if err := pb.replayReset(i, newStepSub); err != nil {
-					pb.cs.Logger.Error("Replay reset error", "err", err)
-				}
+		case "breakpoints":
+			for i, bp := range pb.breakpoints {
+				fmt.Fprintf(pb.out, "%d: %v\n", i, bp)
+			}
+
+		case "delete":
+			i := -1
+			if len(tokens) == 2 {
+				i, _ = strconv.Atoi(tokens[1])
 			}
+			if i < 0 || i >= len(pb.breakpoints) {
+				fmt.Fprintln(pb.out, "delete takes the index of a breakpoint")
+				continue
+			}
+			pb.breakpoints = append(pb.breakpoints[:i], pb.breakpoints[i+1:]...)
 
 		case "rs":
 			// "rs" -> print entire round state
@@ -243,80 +724,110 @@ func (pb *playback) replayConsoleLoop() int {
 
 			rs := pb.cs.RoundState
 			if len(tokens) == 1 {
-				fmt.Println(rs)
+				fmt.Fprintln(pb.out, rs.String())
 			} else {
 				switch tokens[1] {
 				case "short":
-					fmt.Printf("%v/%v/%v\n", rs.Height, rs.Round, rs.Step)
+					fmt.Fprintf(pb.out, "%v/%v/%v\n", rs.Height, rs.Round, rs.Step)
 				case "validators":
-					fmt.Println(rs.Validators)
+					fmt.Fprintln(pb.out, rs.Validators)
 				case "proposal":
-					fmt.Println(rs.Proposal)
+					fmt.Fprintln(pb.out, rs.Proposal)
 				case "proposal_block":
-					fmt.Printf("%v %v\n", rs.ProposalBlockParts.StringShort(), rs.ProposalBlock.StringShort())
+					fmt.Fprintf(pb.out, "%v %v\n", rs.ProposalBlockParts.StringShort(), rs.ProposalBlock.StringShort())
 				case "locked_round":
-					fmt.Println(rs.LockedRound)
+					fmt.Fprintln(pb.out, rs.LockedRound)
 				case "locked_block":
-					fmt.Printf("%v %v\n", rs.LockedBlockParts.StringShort(), rs.LockedBlock.StringShort())
+					fmt.Fprintf(pb.out, "%v %v\n", rs.LockedBlockParts.StringShort(), rs.LockedBlock.StringShort())
+				case "valid_round":
+					fmt.Fprintln(pb.out, rs.ValidRound)
+				case "valid_block":
+					fmt.Fprintf(pb.out, "%v %v\n", rs.ValidBlockParts.StringShort(), rs.ValidBlock.StringShort())
 				case "votes":
-					fmt.Println(rs.Votes.StringIndented("  "))
+					fmt.Fprintln(pb.out, rs.Votes.StringIndented("  "))
 
 				default:
-					fmt.Println("Unknown option", tokens[1])
+					fmt.Fprintln(pb.out, "Unknown option", tokens[1])
 				}
 			}
+
+		case "votes":
+			pb.printVotes(tokens[1:])
+
+		case "locked":
+			rs := pb.cs.RoundState
+			fmt.Fprintf(pb.out, "Locked round %d, block %v\n", rs.LockedRound, rs.LockedBlock.StringShort())
+			fmt.Fprintf(pb.out, "Valid round %d, block %v\n", rs.ValidRound, rs.ValidBlock.StringShort())
+
+		case "state":
+			state := pb.cs.state
+			fmt.Fprintf(pb.out, "Last block %d (%X) at %v\n", state.LastBlockHeight,
+				state.LastBlockID.Hash, state.LastBlockTime)
+			fmt.Fprintf(pb.out, "App hash %X\n", state.AppHash)
+			fmt.Fprintf(pb.out, "Results hash %X\n", state.LastResultsHash)
+			fmt.Fprintf(pb.out, "Validators %X (%d)\n", state.Validators.Hash(), state.Validators.Size())
+
 		case "n":
-			fmt.Println(pb.count)
-		}
-	}
-}
+			fmt.Fprintln(pb.out, pb.count)
 
-//--------------------------------------------------------------------------------
+		case "help":
+			fmt.Fprintln(pb.out, replayConsoleHelp)
 
-// convenience for replay mode
-func newConsensusStateForReplay(config cfg.BaseConfig, csConfig *cfg.ConsensusConfig) *State {
-	dbType := dbm.BackendType(config.DBBackend)
-	// Get BlockStore
-	blockStoreDB := dbm.NewDB("blockstore", dbType, config.DBDir())
-	blockStore := store.NewBlockStore(blockStoreDB)
+		case "quit", "exit":
+			return true
 
-	// Get State
-	stateDB := dbm.NewDB("state", dbType, config.DBDir())
-	gdoc, err := sm.MakeGenesisDocFromFile(config.GenesisFile())
-	if err != nil {
-		tmos.Exit(err.Error())
-	}
-	state, err := sm.MakeGenesisState(gdoc)
-	if err != nil {
-		tmos.Exit(err.Error())
+		default:
+			fmt.Fprintf(pb.out, "Unknown command %q, see help\n", tokens[0])
+		}
 	}
+}
 
-	// Create proxyAppConn connection (consensus, mempool, query)
-	clientCreator := proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir())
-	proxyApp := proxy.NewAppConns(clientCreator)
-	err = proxyApp.Start()
-	if err != nil {
-		tmos.Exit(fmt.Sprintf("Error starting proxy app conns: %v", err))
+func (pb *playback) canReplay() bool {
+	switch {
+	case pb.atEnd:
+		fmt.Fprintln(pb.out, "The end of the WAL was reached")
+	case pb.divergence != nil:
+		fmt.Fprintln(pb.out, "The replay can't go past a divergence:", pb.divergence)
+	default:
+		return true
 	}
+	return false
+}
 
-	eventBus := types.NewEventBus()
-	if err := eventBus.Start(); err != nil {
-		tmos.Exit(fmt.Sprintf("Failed to start event bus: %v", err))
+func (pb *playback) printVotes(args []string) {
+	rs := pb.cs.RoundState
+	round := rs.Round
+	voteTypes := []types.SignedMsgType{types.PrevoteType, types.PrecommitType}
+	for _, arg := range args {
+		switch arg {
+		case "prevote", "prevotes":
+			voteTypes = []types.SignedMsgType{types.PrevoteType}
+		case "precommit", "precommits":
+			voteTypes = []types.SignedMsgType{types.PrecommitType}
+		default:
+			r, err := strconv.Atoi(arg)
+			if err != nil {
+				fmt.Fprintln(pb.out, "votes takes a round and prevote or precommit")
+				return
+			}
+			round = r
+		}
 	}
 
-	handshaker := NewHandshaker(stateDB, state, blockStore, gdoc)
-	handshaker.SetEventBus(eventBus)
-	err = handshaker.Handshake(proxyApp)
-	if err != nil {
-		tmos.Exit(fmt.Sprintf("Error on handshake: %v", err))
+	for _, voteType := range voteTypes {
+		voteSet := rs.Votes.Prevotes(round)
+		if voteType == types.PrecommitType {
+			voteSet = rs.Votes.Precommits(round)
+		}
+		if voteSet == nil {
+			fmt.Fprintf(pb.out, "No %v of round %d\n", voteType, round)
+			continue
+		}
+		if blockID, ok := voteSet.TwoThirdsMajority(); ok {
+			fmt.Fprintf(pb.out, "+2/3 for %v\n", blockID)
+		} else {
+			fmt.Fprintln(pb.out, "No +2/3 majority")
+		}
+		fmt.Fprintln(pb.out, voteSet.StringIndented("  "))
 	}
-
-	mempool, evpool := mock.Mempool{}, sm.MockEvidencePool{}
-	blockExec := sm.NewBlockExecutor(stateDB, log.TestingLogger(), proxyApp.Consensus(), mempool, evpool)
-
-	consensusState := NewState(csConfig, state.Copy(), blockExec,
-		blockStore, mempool, evpool)
-
-	consensusState.SetEventBus(eventBus)
-	return consensusState
 }
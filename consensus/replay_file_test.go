@@ -0,0 +1,151 @@
+package consensus
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	cstypes "github.com/tendermint/tendermint/consensus/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestParseBreakpoint(t *testing.T) {
+	testCases := []struct {
+		s   string
+		bp  Breakpoint
+		err bool
+	}{
+		{"10", Breakpoint{Height: 10, Round: -1}, false},
+		{"10/2", Breakpoint{Height: 10, Round: 2}, false},
+		{"10/0/precommit", Breakpoint{Height: 10, Round: 0, Step: cstypes.RoundStepPrecommit}, false},
+		{"10/0/RoundStepPrevoteWait", Breakpoint{Height: 10, Round: 0, Step: cstypes.RoundStepPrevoteWait}, false},
+		{"0", Breakpoint{}, true},
+		{"10/-1", Breakpoint{}, true},
+		{"10/0/vote", Breakpoint{}, true},
+		{"10/0/commit/1", Breakpoint{}, true},
+	}
+	for _, tc := range testCases {
+		bp, err := ParseBreakpoint(tc.s)
+		if tc.err {
+			assert.Error(t, err, tc.s)
+			continue
+		}
+		require.NoError(t, err, tc.s)
+		assert.Equal(t, tc.bp, bp, tc.s)
+	}
+	bp, _ := ParseBreakpoint("10/0/precommit")
+	assert.Equal(t, "10/0/Precommit", bp.String())
+}
+
+func TestReplayFileConsole(t *testing.T) {
+	config := getConfig(t)
+	defer os.RemoveAll(config.RootDir)
+	walFile := config.Consensus.WalFile()
+	require.NoError(t, os.MkdirAll(filepath.Dir(walFile), 0700))
+	f, err := os.Create(walFile)
+	require.NoError(t, err)
+	require.NoError(t, WALGenerateNBlocks(t, f, 3))
+	require.NoError(t, f.Close())
+
+	// the WAL was written by a persistent kvstore, with the same app hashes
+	config.ProxyApp = "kvstore"
+	in := strings.NewReader(strings.Join([]string{
+		"break 2/0/precommit",
+		"breakpoints",
+		"continue",
+		"rs short",
+		"votes precommit",
+		"locked",
+		"state",
+		"next 2",
+		"n",
+		"delete 0",
+		"continue",
+		"next",
+		"back 3",
+		"n",
+		"quit",
+	}, "\n"))
+	out := new(bytes.Buffer)
+	pb, err := newPlayback(config.BaseConfig, config.Consensus, ReplayOptions{Console: true, In: in, Out: out})
+	require.NoError(t, err)
+	defer pb.stop()
+	require.NoError(t, pb.run())
+
+	s := out.String()
+	for _, expected := range []string{
+		"Breakpoint 0 at 2/0/Precommit",
+		"0: 2/0/Precommit",
+		"Breakpoint 0 (2/0/Precommit) at 2/0/RoundStepPrecommit",
+		"> 2/0/RoundStepPrecommit\n",
+		"#19 vote 2/0\nCommitted block 2 (",
+		"Locked round 0, block Block#",
+		"Last block 1 (",
+		"Committed block 1 (",
+		"Committed block 3 (",
+		"Replayed the",
+		"The end of the WAL was reached",
+		"Reseting from",
+	} {
+		assert.Contains(t, s, expected)
+	}
+	assert.NotContains(t, s, "Divergence")
+}
+
+func TestReplayFileMockApp(t *testing.T) {
+	config := getConfig(t)
+	defer os.RemoveAll(config.RootDir)
+	walFile := config.Consensus.WalFile()
+	require.NoError(t, os.MkdirAll(filepath.Dir(walFile), 0700))
+	f, err := os.Create(walFile)
+	require.NoError(t, err)
+	require.NoError(t, WALGenerateNBlocks(t, f, 3))
+	require.NoError(t, f.Close())
+
+	// replay the WAL against a kvstore, to record the blocks and the responses
+	// in the stores of the node
+	config.ProxyApp = "kvstore"
+	config.DBBackend = "goleveldb"
+	pb, err := newPlayback(config.BaseConfig, config.Consensus, ReplayOptions{Out: new(bytes.Buffer)})
+	require.NoError(t, err)
+	require.NoError(t, pb.run())
+	stateDB := dbm.NewDB("state", dbm.BackendType(config.DBBackend), config.DBDir())
+	for h := int64(1); h <= pb.cs.blockStore.Height(); h++ {
+		block := pb.cs.blockStore.LoadBlock(h)
+		pb.nodeStore.SaveBlock(block, block.MakePartSet(types.BlockPartSizeBytes), pb.cs.blockStore.LoadSeenCommit(h))
+		responses, err := sm.LoadABCIResponses(pb.cs.blockExec.DB(), h)
+		require.NoError(t, err)
+		sm.SaveABCIResponses(stateDB, h, responses)
+	}
+	sm.SaveState(stateDB, pb.cs.state)
+	stateDB.Close()
+	pb.stop()
+
+	// the mock app replays them, with no app
+	config.ProxyApp = "noop"
+	out := new(bytes.Buffer)
+	opts := ReplayOptions{MockApp: true, Trace: true, Until: Breakpoint{Height: 2, Round: 0}, Out: out}
+	require.NoError(t, ReplayFile(config.BaseConfig, config.Consensus, opts))
+
+	s := out.String()
+	for _, expected := range []string{
+		" step 1/0/RoundStepPropose\n",
+		" proposal Proposal{1/0 ",
+		" vote Vote{0:",
+		" timeout 1/0/RoundStepNewHeight fired after ",
+		"Committed block 1 (",
+		"Committed block 2 (",
+		"Replayed up to 2/0, stopped at 3/0/",
+	} {
+		assert.Contains(t, s, expected)
+	}
+	assert.NotContains(t, s, "Committed block 3")
+	assert.NotContains(t, s, "Divergence")
+}
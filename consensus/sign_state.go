@@ -0,0 +1,161 @@
+package consensus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/tempfile"
+	"github.com/tendermint/tendermint/types"
+)
+
+// signState records the votes and the proposals signed by the validator at
+// its last height, so that the State refuses to sign conflicting ones,
+// whatever its PrivValidator does: e.g. a remote signer whose state was reset,
+// or a key shared by two signers by mistake (see consensus.sign_state_file).
+//
+// NOTE: not thread safe, it's only used by the State, under its mutex.
+type signState struct {
+	Address   types.Address    `json:"address"`
+	Height    int64            `json:"height"`
+	Votes     []signedVote     `json:"votes"`
+	Proposals []signedProposal `json:"proposals"`
+
+	filePath string // kept in memory only if empty
+}
+
+type signedVote struct {
+	Round   int                 `json:"round"`
+	Type    types.SignedMsgType `json:"type"`
+	BlockID types.BlockID       `json:"block_id"`
+}
+
+type signedProposal struct {
+	Round    int           `json:"round"`
+	POLRound int           `json:"pol_round"`
+	BlockID  types.BlockID `json:"block_id"`
+}
+
+// loadSignState loads the sign state saved in filePath, if any.
+func loadSignState(filePath string) (*signState, error) {
+	ss := &signState{}
+	bz, err := ioutil.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := cdc.UnmarshalJSON(bz, ss); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the sign state %s", filePath)
+		}
+	}
+	ss.filePath = filePath
+	return ss, nil
+}
+
+// checkVote returns an error if vote conflicts with the votes signed before:
+// if it's for a previous height, or for another block than a vote of the same
+// height, round and type.
+func (ss *signState) checkVote(vote *types.Vote) error {
+	if ok, err := ss.checkHeight(vote.ValidatorAddress, vote.Height); !ok {
+		return err
+	}
+	for _, v := range ss.Votes {
+		if v.Round == vote.Round && v.Type == vote.Type && !v.BlockID.Equals(vote.BlockID) {
+			return errors.Wrapf(ErrConflictingSignature, "%s %d/%d for %v, after signing it for %v",
+				signedMsgTypeName(vote.Type), vote.Height, vote.Round, vote.BlockID, v.BlockID)
+		}
+	}
+	return nil
+}
+
+// checkProposal returns an error if the proposal of addr conflicts with the
+// proposals signed before: if it's for a previous height, or for another block
+// or POL round than a proposal of the same height and round.
+func (ss *signState) checkProposal(addr types.Address, proposal *types.Proposal) error {
+	if ok, err := ss.checkHeight(addr, proposal.Height); !ok {
+		return err
+	}
+	for _, p := range ss.Proposals {
+		if p.Round == proposal.Round && (p.POLRound != proposal.POLRound || !p.BlockID.Equals(proposal.BlockID)) {
+			return errors.Wrapf(ErrConflictingSignature,
+				"proposal %d/%d for %v (POL round %d), after signing it for %v (POL round %d)",
+				proposal.Height, proposal.Round, proposal.BlockID, proposal.POLRound, p.BlockID, p.POLRound)
+		}
+	}
+	return nil
+}
+
+// checkHeight returns whether the records apply to a signature of addr at
+// height, or an error if addr signed a later height. The records of another
+// key are ignored.
+func (ss *signState) checkHeight(addr types.Address, height int64) (bool, error) {
+	if !bytes.Equal(ss.Address, addr) || height > ss.Height {
+		return false, nil
+	}
+	if height < ss.Height {
+		return false, errors.Wrapf(ErrConflictingSignature, "height %d, after signing height %d", height, ss.Height)
+	}
+	return true, nil
+}
+
+// recordVote records the vote signed, and saves the sign state.
+func (ss *signState) recordVote(vote *types.Vote) error {
+	ss.reset(vote.ValidatorAddress, vote.Height)
+	for _, v := range ss.Votes {
+		if v.Round == vote.Round && v.Type == vote.Type {
+			return nil
+		}
+	}
+	ss.Votes = append(ss.Votes, signedVote{Round: vote.Round, Type: vote.Type, BlockID: vote.BlockID})
+	return ss.save()
+}
+
+// recordProposal records the proposal signed by addr, and saves the sign
+// state.
+func (ss *signState) recordProposal(addr types.Address, proposal *types.Proposal) error {
+	ss.reset(addr, proposal.Height)
+	for _, p := range ss.Proposals {
+		if p.Round == proposal.Round {
+			return nil
+		}
+	}
+	ss.Proposals = append(ss.Proposals,
+		signedProposal{Round: proposal.Round, POLRound: proposal.POLRound, BlockID: proposal.BlockID})
+	return ss.save()
+}
+
+// reset forgets the records of another key or of a previous height.
+func (ss *signState) reset(addr types.Address, height int64) {
+	if !bytes.Equal(ss.Address, addr) || height > ss.Height {
+		ss.Address = addr
+		ss.Height = height
+		ss.Votes = nil
+		ss.Proposals = nil
+	}
+}
+
+func (ss *signState) save() error {
+	if ss.filePath == "" {
+		return nil
+	}
+	bz, err := cdc.MarshalJSONIndent(ss, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(ss.filePath, bz, 0600)
+}
+
+func signedMsgTypeName(t types.SignedMsgType) string {
+	switch t {
+	case types.PrevoteType:
+		return "prevote"
+	case types.PrecommitType:
+		return "precommit"
+	case types.ProposalType:
+		return "proposal"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,66 @@
+package consensus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSignState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sign_state")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "cs_sign_state.json")
+
+	ss, err := loadSignState(file)
+	require.NoError(t, err)
+	addr := tmrand.Bytes(20)
+	blockID := types.BlockID{Hash: tmrand.Bytes(tmhash.Size)}
+	vote := func(height int64, round int, voteType types.SignedMsgType, blockID types.BlockID) *types.Vote {
+		return &types.Vote{ValidatorAddress: addr, Height: height, Round: round, Type: voteType, BlockID: blockID}
+	}
+	isConflict := func(err error) bool {
+		return errors.Cause(err) == ErrConflictingSignature
+	}
+
+	require.NoError(t, ss.checkVote(vote(2, 0, types.PrevoteType, blockID)))
+	require.NoError(t, ss.recordVote(vote(2, 0, types.PrevoteType, blockID)))
+	require.NoError(t, ss.recordProposal(addr, types.NewProposal(2, 1, 0, blockID)))
+
+	// the records are saved
+	ss, err = loadSignState(file)
+	require.NoError(t, err)
+	assert.NoError(t, ss.checkVote(vote(2, 0, types.PrevoteType, blockID)))
+	assert.NoError(t, ss.checkVote(vote(2, 0, types.PrecommitType, types.BlockID{})))
+	assert.NoError(t, ss.checkVote(vote(2, 1, types.PrevoteType, types.BlockID{})))
+	assert.True(t, isConflict(ss.checkVote(vote(2, 0, types.PrevoteType, types.BlockID{}))))
+	assert.True(t, isConflict(ss.checkVote(vote(1, 5, types.PrecommitType, blockID))))
+	assert.NoError(t, ss.checkProposal(addr, types.NewProposal(2, 1, 0, blockID)))
+	assert.True(t, isConflict(ss.checkProposal(addr, types.NewProposal(2, 1, -1, blockID))))
+	assert.True(t, isConflict(ss.checkProposal(addr, types.NewProposal(2, 1, 0, types.BlockID{}))))
+
+	// the records of another key are ignored
+	other := vote(1, 0, types.PrevoteType, types.BlockID{})
+	other.ValidatorAddress = tmrand.Bytes(20)
+	assert.NoError(t, ss.checkVote(other))
+
+	// the records of the previous heights are forgotten
+	require.NoError(t, ss.recordVote(vote(3, 0, types.PrevoteType, types.BlockID{})))
+	assert.Len(t, ss.Votes, 1)
+	assert.Empty(t, ss.Proposals)
+	assert.True(t, isConflict(ss.checkVote(vote(2, 0, types.PrevoteType, blockID))))
+
+	// a corrupted file isn't ignored
+	require.NoError(t, ioutil.WriteFile(file, []byte("{"), 0600))
+	_, err = loadSignState(file)
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/tendermint/tendermint/libs/fail"
+	"github.com/tendermint/tendermint/libs/failpoint"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
 	"github.com/tendermint/tendermint/libs/service"
@@ -18,6 +19,8 @@ import (
 
 	cfg "github.com/tendermint/tendermint/config"
 	cstypes "github.com/tendermint/tendermint/consensus/types"
+	"github.com/tendermint/tendermint/crypto"
+	auto "github.com/tendermint/tendermint/libs/autofile"
 	tmevents "github.com/tendermint/tendermint/libs/events"
 	"github.com/tendermint/tendermint/p2p"
 	sm "github.com/tendermint/tendermint/state"
@@ -30,8 +33,10 @@ import (
 var (
 	ErrInvalidProposalSignature = errors.New("error invalid proposal signature")
 	ErrInvalidProposalPOLRound  = errors.New("error invalid proposal POL round")
+	ErrInvalidProposalProof     = errors.New("error invalid proposal VRF proof")
 	ErrAddingVote               = errors.New("error adding vote")
 	ErrVoteHeightMismatch       = errors.New("error vote height mismatch")
+	ErrConflictingSignature     = errors.New("error conflicting signature prevented")
 )
 
 //-----------------------------------------------------------------------------
@@ -44,6 +49,10 @@ var (
 type msgInfo struct {
 	Msg    Message `json:"msg"`
 	PeerID p2p.ID  `json:"peer_key"`
+
+	// local time when the msg was received, written to the WAL so that the
+	// timeliness of the proposals (proposer-based timestamps) is replayed
+	ReceiveTime time.Time `json:"receive_time"`
 }
 
 // internally generated messages which may update the state
@@ -63,6 +72,11 @@ type txNotifier interface {
 	TxsAvailable() <-chan struct{}
 }
 
+// interface to the mempool.TxTracker
+type txTracker interface {
+	MarkProposed(height int64, txs types.Txs)
+}
+
 // interface to the evidence pool
 type evidencePool interface {
 	AddEvidence(types.Evidence) error
@@ -78,6 +92,9 @@ type State struct {
 	// config details
 	config        *cfg.ConsensusConfig
 	privValidator types.PrivValidator // for signing votes
+	// address of privValidator, so that the votes can be told ours without
+	// asking a remote signer
+	privValidatorAddr crypto.Address
 
 	// store blocks and commits
 	blockStore sm.BlockStore
@@ -88,6 +105,23 @@ type State struct {
 	// notify us if txs are available
 	txNotifier txNotifier
 
+	// notified of the txs of the complete proposal blocks, if set
+	txTracker txTracker
+
+	// estimates the timeouts if consensus.timeout_adaptive is set
+	timeouts adaptiveTimeouts
+
+	// statistics of the votes of the validators (/dump_consensus_state)
+	validatorStats validatorStatsTracker
+
+	// tracks the proposers known to be offline, whose rounds get a shorter
+	// propose timeout (consensus.offline_proposer_slots)
+	proposerLiveness proposerLiveness
+
+	// the votes and proposals signed at the last height, so that we never sign
+	// conflicting ones (consensus.sign_state_file)
+	signState *signState
+
 	// add evidence to the pool
 	// when it's detected
 	evpool evidencePool
@@ -116,6 +150,7 @@ type State struct {
 	wal          WAL
 	replayMode   bool // so we don't log signing errors during replay
 	doWALCatchup bool // determines if we even try to do the catchup
+	walReplay    *WALReplay
 
 	// for tests where we want to limit the number of transitions the state makes
 	nSteps int
@@ -123,7 +158,7 @@ type State struct {
 	// some functions can be overwritten for testing
 	decideProposal func(height int64, round int)
 	doPrevote      func(height int64, round int)
-	setProposal    func(proposal *types.Proposal) error
+	setProposal    func(proposal *types.Proposal, receiveTime time.Time) error
 
 	// closed when we finish shutting down
 	done chan struct{}
@@ -134,6 +169,9 @@ type State struct {
 
 	// for reporting metrics
 	metrics *Metrics
+
+	// called if the receive routine panics
+	panicHandler PanicHandler
 }
 
 // StateOption sets an optional parameter on the State.
@@ -164,6 +202,7 @@ func NewState(
 		evpool:           evpool,
 		evsw:             tmevents.NewEventSwitch(),
 		metrics:          NopMetrics(),
+		signState:        &signState{},
 	}
 	// set function defaults (may be overwritten before calling Start)
 	cs.decideProposal = cs.defaultDecideProposal
@@ -197,11 +236,29 @@ func (cs *State) SetEventBus(b *types.EventBus) {
 	cs.blockExec.SetEventBus(b)
 }
 
+// PanicHandler is called with the recovered value and the stack of the
+// panicking goroutine if consensus fails, i.e. the receive routine panics, so
+// that the failure can be investigated. The WAL has been flushed, and
+// consensus is halted once the handler returns.
+type PanicHandler func(cs *State, r interface{}, stack []byte)
+
+// SetPanicHandler sets the handler called if consensus fails.
+// It must be called before the State is started.
+func (cs *State) SetPanicHandler(h PanicHandler) {
+	cs.panicHandler = h
+}
+
 // StateMetrics sets the metrics.
 func StateMetrics(metrics *Metrics) StateOption {
 	return func(cs *State) { cs.metrics = metrics }
 }
 
+// StateTxTracker sets the tracker recording the txs of the complete proposal
+// blocks as proposed (see mempool.TxTracker).
+func StateTxTracker(t txTracker) StateOption {
+	return func(cs *State) { cs.txTracker = t }
+}
+
 // String returns a string.
 func (cs *State) String() string {
 	// better not to access shared variables
@@ -231,6 +288,18 @@ func (cs *State) GetRoundState() *cstypes.RoundState {
 	return &rs
 }
 
+// WALReplay returns the replay of the WAL when cs started, if any. The WAL is
+// not replayed until cs is started, which is only after fast sync if the node
+// is fast syncing.
+func (cs *State) WALReplay() (WALReplay, bool) {
+	cs.mtx.RLock()
+	defer cs.mtx.RUnlock()
+	if cs.walReplay == nil {
+		return WALReplay{}, false
+	}
+	return *cs.walReplay, true
+}
+
 // GetRoundStateJSON returns a json of RoundState, marshalled using go-amino.
 func (cs *State) GetRoundStateJSON() ([]byte, error) {
 	cs.mtx.RLock()
@@ -245,6 +314,14 @@ func (cs *State) GetRoundStateSimpleJSON() ([]byte, error) {
 	return cdc.MarshalJSON(cs.RoundState.RoundStateSimple())
 }
 
+// GetValidatorStatsJSON returns a json of the statistics of the current
+// validators (see ValidatorStats), marshalled using go-amino.
+func (cs *State) GetValidatorStatsJSON() ([]byte, error) {
+	cs.mtx.RLock()
+	defer cs.mtx.RUnlock()
+	return cdc.MarshalJSON(cs.validatorStats.Stats())
+}
+
 // GetValidators returns a copy of the current validators.
 func (cs *State) GetValidators() (int64, []*types.Validator) {
 	cs.mtx.RLock()
@@ -256,6 +333,33 @@ func (cs *State) GetValidators() (int64, []*types.Validator) {
 func (cs *State) SetPrivValidator(priv types.PrivValidator) {
 	cs.mtx.Lock()
 	cs.privValidator = priv
+	cs.privValidatorAddr = nil
+	if priv != nil {
+		if pubKey := priv.GetPubKey(); pubKey != nil {
+			cs.privValidatorAddr = pubKey.Address()
+		}
+	}
+	cs.mtx.Unlock()
+}
+
+// SetTimeouts replaces the round timeouts (and skip_timeout_commit) with the
+// ones from the given config. The new values apply to the next scheduled
+// timeout.
+func (cs *State) SetTimeouts(config *cfg.ConsensusConfig) {
+	cs.mtx.Lock()
+	cs.config.TimeoutPropose = config.TimeoutPropose
+	cs.config.TimeoutProposeDelta = config.TimeoutProposeDelta
+	cs.config.TimeoutPrevote = config.TimeoutPrevote
+	cs.config.TimeoutPrevoteDelta = config.TimeoutPrevoteDelta
+	cs.config.TimeoutPrecommit = config.TimeoutPrecommit
+	cs.config.TimeoutPrecommitDelta = config.TimeoutPrecommitDelta
+	cs.config.TimeoutCommit = config.TimeoutCommit
+	cs.config.TimeoutAdaptive = config.TimeoutAdaptive
+	cs.config.TimeoutAdaptiveMin = config.TimeoutAdaptiveMin
+	cs.config.TimeoutAdaptiveMax = config.TimeoutAdaptiveMax
+	cs.config.OfflineProposerSlots = config.OfflineProposerSlots
+	cs.config.TimeoutProposeOffline = config.TimeoutProposeOffline
+	cs.config.SkipTimeoutCommit = config.SkipTimeoutCommit
 	cs.mtx.Unlock()
 }
 
@@ -295,6 +399,16 @@ func (cs *State) OnStart() error {
 		cs.wal = wal
 	}
 
+	// load the sign state before the WAL catchup, which may sign again
+	if signStateFile := cs.config.SignStateFile(); signStateFile != "" {
+		ss, err := loadSignState(signStateFile)
+		if err != nil {
+			cs.Logger.Error("Error loading the sign state", "file", signStateFile, "err", err)
+			return err
+		}
+		cs.signState = ss
+	}
+
 	// we need the timeoutRoutine for replay so
 	// we don't block on the tick chan.
 	// NOTE: we will get a build up of garbage go routines
@@ -341,6 +455,19 @@ go run scripts/json2wal/main.go wal.json $WALFILE # rebuild the file without cor
 	return nil
 }
 
+// handlePanic calls the panic handler, making sure it can't crash the node.
+func (cs *State) handlePanic(r interface{}, stack []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			cs.Logger.Error("Consensus panic handler panicked", "err", r)
+		}
+	}()
+	if err := cs.wal.FlushAndSync(); err != nil {
+		cs.Logger.Error("Failed to flush WAL", "err", err)
+	}
+	cs.panicHandler(cs, r, stack)
+}
+
 // timeoutRoutine: receive requests for timeouts on tickChan and fire timeouts on tockChan
 // receiveRoutine: serializes processing of proposoals, block parts, votes; coordinates state transitions
 func (cs *State) startRoutines(maxSteps int) {
@@ -368,7 +495,11 @@ func (cs *State) Wait() {
 
 // OpenWAL opens a file to log all consensus messages and timeouts for deterministic accountability
 func (cs *State) OpenWAL(walFile string) (WAL, error) {
-	wal, err := NewWAL(walFile)
+	wal, err := NewWAL(walFile,
+		auto.GroupHeadSizeLimit(cs.config.WalMaxFileSize),
+		auto.GroupTotalSizeLimit(cs.config.WalMaxTotalSize),
+		auto.GroupMaxFiles(cs.config.WalMaxFiles),
+		auto.GroupCompression(auto.Compression(cs.config.WalCompression)))
 	if err != nil {
 		cs.Logger.Error("Failed to open WAL for consensus state", "wal", walFile, "err", err)
 		return nil, err
@@ -390,9 +521,9 @@ func (cs *State) OpenWAL(walFile string) (WAL, error) {
 // AddVote inputs a vote.
 func (cs *State) AddVote(vote *types.Vote, peerID p2p.ID) (added bool, err error) {
 	if peerID == "" {
-		cs.internalMsgQueue <- msgInfo{&VoteMessage{vote}, ""}
+		cs.internalMsgQueue <- msgInfo{&VoteMessage{vote}, "", tmtime.Now()}
 	} else {
-		cs.peerMsgQueue <- msgInfo{&VoteMessage{vote}, peerID}
+		cs.peerMsgQueue <- msgInfo{&VoteMessage{vote}, peerID, tmtime.Now()}
 	}
 
 	// TODO: wait for event?!
@@ -403,9 +534,9 @@ func (cs *State) AddVote(vote *types.Vote, peerID p2p.ID) (added bool, err error
 func (cs *State) SetProposal(proposal *types.Proposal, peerID p2p.ID) error {
 
 	if peerID == "" {
-		cs.internalMsgQueue <- msgInfo{&ProposalMessage{proposal}, ""}
+		cs.internalMsgQueue <- msgInfo{&ProposalMessage{proposal}, "", tmtime.Now()}
 	} else {
-		cs.peerMsgQueue <- msgInfo{&ProposalMessage{proposal}, peerID}
+		cs.peerMsgQueue <- msgInfo{&ProposalMessage{proposal}, peerID, tmtime.Now()}
 	}
 
 	// TODO: wait for event?!
@@ -416,9 +547,9 @@ func (cs *State) SetProposal(proposal *types.Proposal, peerID p2p.ID) error {
 func (cs *State) AddProposalBlockPart(height int64, round int, part *types.Part, peerID p2p.ID) error {
 
 	if peerID == "" {
-		cs.internalMsgQueue <- msgInfo{&BlockPartMessage{height, round, part}, ""}
+		cs.internalMsgQueue <- msgInfo{&BlockPartMessage{height, round, part}, "", tmtime.Now()}
 	} else {
-		cs.peerMsgQueue <- msgInfo{&BlockPartMessage{height, round, part}, peerID}
+		cs.peerMsgQueue <- msgInfo{&BlockPartMessage{height, round, part}, peerID, tmtime.Now()}
 	}
 
 	// TODO: wait for event?!
@@ -528,7 +659,7 @@ func (cs *State) updateToState(state sm.State) {
 	}
 
 	// Reset fields based on state.
-	validators := state.Validators
+	validators := cs.withVRFProposer(state, state.Validators, 0)
 	lastPrecommits := (*types.VoteSet)(nil)
 	if cs.CommitRound > -1 && cs.Votes != nil {
 		if !cs.Votes.Precommits(cs.CommitRound).HasTwoThirdsMajority() {
@@ -555,7 +686,9 @@ func (cs *State) updateToState(state sm.State) {
 	}
 
 	cs.Validators = validators
+	cs.proposerLiveness.prune(validators)
 	cs.Proposal = nil
+	cs.ProposalReceiveTime = time.Time{}
 	cs.ProposalBlock = nil
 	cs.ProposalBlockParts = nil
 	cs.LockedRound = -1
@@ -610,7 +743,11 @@ func (cs *State) receiveRoutine(maxSteps int) {
 
 	defer func() {
 		if r := recover(); r != nil {
-			cs.Logger.Error("CONSENSUS FAILURE!!!", "err", r, "stack", string(debug.Stack()))
+			stack := debug.Stack()
+			cs.Logger.Error("CONSENSUS FAILURE!!!", "err", r, "stack", string(stack))
+			if cs.panicHandler != nil {
+				cs.handlePanic(r, stack)
+			}
 			// stop gracefully
 			//
 			// NOTE: We most probably shouldn't be running any further when there is
@@ -684,7 +821,11 @@ func (cs *State) handleMsg(mi msgInfo) {
 	case *ProposalMessage:
 		// will not cause transition.
 		// once proposal is set, we can receive block parts
-		err = cs.setProposal(msg.Proposal)
+		receiveTime := mi.ReceiveTime
+		if receiveTime.IsZero() {
+			receiveTime = tmtime.Now()
+		}
+		err = cs.setProposal(msg.Proposal, receiveTime)
 	case *BlockPartMessage:
 		// if the proposal is complete, we'll enterPrevote or tryFinalizeCommit
 		added, err = cs.addProposalBlockPart(msg, peerID)
@@ -828,18 +969,29 @@ func (cs *State) enterNewRound(height int64, round int) {
 
 	logger.Info(fmt.Sprintf("enterNewRound(%v/%v). Current: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
 
+	// The proposer of the round we're leaving missed its slot if we waited
+	// for its proposal in vain
+	if cs.Round < round && cs.Step >= cstypes.RoundStepPropose && cs.Proposal == nil {
+		cs.proposerLiveness.slotMissed(cs.Validators.GetProposer().Address)
+	}
+
 	// Increment validators if necessary
 	validators := cs.Validators
 	if cs.Round < round {
 		validators = validators.Copy()
 		validators.IncrementProposerPriority(round - cs.Round)
+		validators = cs.withVRFProposer(cs.state, validators, round)
 	}
 
 	// Setup new round
 	// we don't fire newStep for this step,
 	// but we fire an event, so update the round step first
 	cs.updateRoundStep(round, cstypes.RoundStepNewRound)
+	cs.timeouts.reset()
 	cs.Validators = validators
+	if !cs.replayMode {
+		cs.validatorStats.enterRound(height, round, validators)
+	}
 	if round == 0 {
 		// We've already reset these upon new height,
 		// and meanwhile we might have received a proposal
@@ -847,6 +999,7 @@ func (cs *State) enterNewRound(height int64, round int) {
 	} else {
 		logger.Info("Resetting Proposal info")
 		cs.Proposal = nil
+		cs.ProposalReceiveTime = time.Time{}
 		cs.ProposalBlock = nil
 		cs.ProposalBlockParts = nil
 	}
@@ -913,8 +1066,21 @@ func (cs *State) enterPropose(height int64, round int) {
 		}
 	}()
 
+	// Measure how long the proposals of the other validators take to arrive
+	if !cs.replayMode && (cs.privValidator == nil || !cs.isProposer(cs.privValidator.GetPubKey().Address())) {
+		cs.timeouts.enterPropose()
+	}
+
 	// If we don't get the proposal and all block parts quick enough, enterPrevote
-	cs.scheduleTimeout(cs.config.Propose(round), height, round, cstypes.RoundStepPropose)
+	timeout := cs.timeouts.Propose(cs.config, round)
+	if proposer := cs.Validators.GetProposer().Address; timeout > cs.config.TimeoutProposeOffline &&
+		cs.proposerLiveness.isOffline(proposer, cs.config.OfflineProposerSlots) {
+		logger.Info("Proposer is offline, shortening timeout_propose",
+			"proposer", proposer, "timeout", cs.config.TimeoutProposeOffline)
+		timeout = cs.config.TimeoutProposeOffline
+	}
+	cs.metrics.StepTimeoutSeconds.With("step", "propose").Set(timeout.Seconds())
+	cs.scheduleTimeout(timeout, height, round, cstypes.RoundStepPropose)
 
 	// Nothing more to do if we're not a validator
 	if cs.privValidator == nil {
@@ -946,6 +1112,20 @@ func (cs *State) enterPropose(height int64, round int) {
 	}
 }
 
+// withVRFProposer returns a copy of validators whose proposer is chosen by the
+// VRF proposer selection for the given round, if it is enabled by the
+// consensus params. Otherwise, validators is returned unchanged.
+func (cs *State) withVRFProposer(state sm.State, validators *types.ValidatorSet, round int) *types.ValidatorSet {
+	if !state.ConsensusParams.Validator.IsVRFProposerSelection() || validators.IsNilOrEmpty() {
+		return validators
+	}
+	height := state.LastBlockHeight + 1
+	seed := types.ProposerSeed(state.ChainID, state.VRFSeed(), height, round)
+	validators = validators.Copy()
+	validators.Proposer = validators.RandomProposer(seed)
+	return validators
+}
+
 func (cs *State) isProposer(address []byte) bool {
 	return bytes.Equal(cs.Validators.GetProposer().Address, address)
 }
@@ -973,13 +1153,27 @@ func (cs *State) defaultDecideProposal(height int64, round int) {
 	// Make proposal
 	propBlockID := types.BlockID{Hash: block.Hash(), PartsHeader: blockParts.Header()}
 	proposal := types.NewProposal(height, round, cs.ValidRound, propBlockID)
-	if err := cs.privValidator.SignProposal(cs.state.ChainID, proposal); err == nil {
+	if cs.state.ConsensusParams.Validator.IsVRFProposerSelection() {
+		// The proof of a valid block is the one of its original proposer, we
+		// prove ours in the proposal.
+		proof, err := types.ProveProposer(cs.privValidator, cs.state.ChainID, cs.state.VRFSeed(), height)
+		if err != nil {
+			cs.Logger.Error("enterPropose: Error proving the VRF output", "height", height, "round", round, "err", err)
+			return
+		}
+		proposal.ProposerProof = proof
+	}
+	if cs.state.ConsensusParams.Synchrony.ProposerBasedTimestamps {
+		// the validators check the timeliness of the block time
+		proposal.Timestamp = block.Time
+	}
+	if err := cs.signProposal(proposal); err == nil {
 
 		// send proposal and block parts on internal msg queue
-		cs.sendInternalMessage(msgInfo{&ProposalMessage{proposal}, ""})
+		cs.sendInternalMessage(msgInfo{&ProposalMessage{proposal}, "", tmtime.Now()})
 		for i := 0; i < blockParts.Total(); i++ {
 			part := blockParts.GetPart(i)
-			cs.sendInternalMessage(msgInfo{&BlockPartMessage{cs.Height, cs.Round, part}, ""})
+			cs.sendInternalMessage(msgInfo{&BlockPartMessage{cs.Height, cs.Round, part}, "", tmtime.Now()})
 		}
 		cs.Logger.Info("Signed proposal", "height", height, "round", round, "proposal", proposal)
 		cs.Logger.Debug(fmt.Sprintf("Signed proposal block: %v", block))
@@ -1026,7 +1220,17 @@ func (cs *State) createProposalBlock() (block *types.Block, blockParts *types.Pa
 	}
 
 	proposerAddr := cs.privValidator.GetPubKey().Address()
-	return cs.blockExec.CreateProposalBlock(cs.Height, cs.state, commit, proposerAddr)
+	block, blockParts = cs.blockExec.CreateProposalBlock(cs.Height, cs.state, commit, proposerAddr)
+	if cs.state.ConsensusParams.Validator.IsVRFProposerSelection() {
+		proof, err := types.ProveProposer(cs.privValidator, cs.state.ChainID, cs.state.VRFSeed(), cs.Height)
+		if err != nil {
+			cs.Logger.Error("enterPropose: Cannot propose anything: Error proving the VRF output", "err", err)
+			return nil, nil
+		}
+		block.ProposerProof = proof
+		blockParts = block.MakePartSet(types.BlockPartSizeBytes)
+	}
+	return block, blockParts
 }
 
 // Enter: `timeoutPropose` after entering Propose.
@@ -1053,6 +1257,10 @@ func (cs *State) enterPrevote(height int64, round int) {
 
 	cs.Logger.Info(fmt.Sprintf("enterPrevote(%v/%v). Current: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
 
+	if !cs.replayMode {
+		cs.timeouts.enterPrevote()
+	}
+
 	// Sign and broadcast vote as necessary
 	cs.doPrevote(height, round)
 
@@ -1077,6 +1285,25 @@ func (cs *State) defaultDoPrevote(height int64, round int) {
 		return
 	}
 
+	// With proposer-based timestamps, prevote nil if the proposal of a new
+	// block (rather than of a block with a POL) isn't timely, or if its
+	// timestamp isn't the block time.
+	if sp := cs.state.ConsensusParams.Synchrony; sp.ProposerBasedTimestamps &&
+		cs.Proposal != nil && cs.Proposal.POLRound == -1 {
+		if !cs.Proposal.Timestamp.Equal(cs.ProposalBlock.Time) {
+			logger.Error("enterPrevote: Proposal timestamp isn't the block time",
+				"timestamp", cs.Proposal.Timestamp, "blockTime", cs.ProposalBlock.Time)
+			cs.signAddVote(types.PrevoteType, nil, types.PartSetHeader{})
+			return
+		}
+		if !cs.Proposal.IsTimely(cs.ProposalReceiveTime, sp) {
+			logger.Info("enterPrevote: Proposal isn't timely",
+				"timestamp", cs.Proposal.Timestamp, "receiveTime", cs.ProposalReceiveTime)
+			cs.signAddVote(types.PrevoteType, nil, types.PartSetHeader{})
+			return
+		}
+	}
+
 	// Validate proposal block
 	err := cs.blockExec.ValidateBlock(cs.state, cs.ProposalBlock)
 	if err != nil {
@@ -1119,7 +1346,9 @@ func (cs *State) enterPrevoteWait(height int64, round int) {
 	}()
 
 	// Wait for some more prevotes; enterPrecommit
-	cs.scheduleTimeout(cs.config.Prevote(round), height, round, cstypes.RoundStepPrevoteWait)
+	timeout := cs.timeouts.Prevote(cs.config, round)
+	cs.metrics.StepTimeoutSeconds.With("step", "prevote").Set(timeout.Seconds())
+	cs.scheduleTimeout(timeout, height, round, cstypes.RoundStepPrevoteWait)
 }
 
 // Enter: `timeoutPrevote` after any +2/3 prevotes.
@@ -1144,6 +1373,10 @@ func (cs *State) enterPrecommit(height int64, round int) {
 
 	logger.Info(fmt.Sprintf("enterPrecommit(%v/%v). Current: %v/%v/%v", height, round, cs.Height, cs.Round, cs.Step))
 
+	if !cs.replayMode {
+		cs.timeouts.enterPrecommit()
+	}
+
 	defer func() {
 		// Done enterPrecommit:
 		cs.updateRoundStep(round, cstypes.RoundStepPrecommit)
@@ -1253,7 +1486,9 @@ func (cs *State) enterPrecommitWait(height int64, round int) {
 	}()
 
 	// Wait for some more precommits; enterNewRound
-	cs.scheduleTimeout(cs.config.Precommit(round), height, round, cstypes.RoundStepPrecommitWait)
+	timeout := cs.timeouts.Precommit(cs.config, round)
+	cs.metrics.StepTimeoutSeconds.With("step", "precommit").Set(timeout.Seconds())
+	cs.scheduleTimeout(timeout, height, round, cstypes.RoundStepPrecommitWait)
 
 }
 
@@ -1386,6 +1621,9 @@ func (cs *State) finalizeCommit(height int64) {
 	cs.Logger.Info(fmt.Sprintf("%v", block))
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("consensus/before-save-block"); err != nil {
+		panic(err)
+	}
 
 	// Save to blockStore.
 	if cs.blockStore.Height() < block.Height {
@@ -1400,6 +1638,9 @@ func (cs *State) finalizeCommit(height int64) {
 	}
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("consensus/before-end-height"); err != nil {
+		panic(err)
+	}
 
 	// Write EndHeightMessage{} for this height, implying that the blockstore
 	// has saved the block.
@@ -1421,6 +1662,9 @@ func (cs *State) finalizeCommit(height int64) {
 	}
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("consensus/before-apply-block"); err != nil {
+		panic(err)
+	}
 
 	// Create a copy of the state for staging and an event cache for txs.
 	stateCopy := cs.state.Copy()
@@ -1531,7 +1775,7 @@ func (cs *State) recordMetrics(height int64, block *types.Block) {
 
 //-----------------------------------------------------------------------------
 
-func (cs *State) defaultSetProposal(proposal *types.Proposal) error {
+func (cs *State) defaultSetProposal(proposal *types.Proposal, receiveTime time.Time) error {
 	// Already have one
 	// TODO: possibly catch double proposals
 	if cs.Proposal != nil {
@@ -1554,7 +1798,20 @@ func (cs *State) defaultSetProposal(proposal *types.Proposal) error {
 		return ErrInvalidProposalSignature
 	}
 
+	// Verify the VRF proof of the proposer
+	if cs.state.ConsensusParams.Validator.IsVRFProposerSelection() {
+		_, err := types.VerifyProposerProof(cs.Validators.GetProposer().PubKey, cs.state.ChainID,
+			cs.state.VRFSeed(), proposal.Height, proposal.ProposerProof)
+		if err != nil {
+			return errors.Wrap(ErrInvalidProposalProof, err.Error())
+		}
+	} else if len(proposal.ProposerProof) > 0 {
+		return errors.Wrap(ErrInvalidProposalProof, "proposers aren't selected by VRF")
+	}
+
 	cs.Proposal = proposal
+	cs.ProposalReceiveTime = receiveTime
+	cs.proposerLiveness.proposed(cs.Validators.GetProposer().Address)
 	// We don't update cs.ProposalBlockParts if it is already set.
 	// This happens if we're already in cstypes.RoundStepCommit or if there is a valid block in the current round.
 	// TODO: We can check if Proposal is for a different block as this is a sign of misbehavior!
@@ -1603,6 +1860,12 @@ func (cs *State) addProposalBlockPart(msg *BlockPartMessage, peerID p2p.ID) (add
 		// NOTE: it's possible to receive complete proposal blocks for future rounds without having the proposal
 		cs.Logger.Info("Received complete proposal block", "height", cs.ProposalBlock.Height, "hash", cs.ProposalBlock.Hash())
 		cs.eventBus.PublishEventCompleteProposal(cs.CompleteProposalEvent())
+		if cs.Step == cstypes.RoundStepPropose && cs.Proposal != nil && cs.Proposal.Round == cs.Round {
+			cs.timeouts.proposalReceived()
+		}
+		if cs.txTracker != nil {
+			cs.txTracker.MarkProposed(cs.ProposalBlock.Height, cs.ProposalBlock.Txs)
+		}
 
 		// Update Valid* if we can.
 		prevotes := cs.Votes.Prevotes(cs.Round)
@@ -1702,12 +1965,18 @@ func (cs *State) addVote(
 			// fmt.Errorf("tryAddVote: Wrong height, not a LastCommit straggler commit.")
 			return added, ErrVoteHeightMismatch
 		}
+		if err = cs.verifyVoteExtension(vote, cs.LastValidators, cs.LastCommit); err != nil {
+			return added, err
+		}
 		added, err = cs.LastCommit.AddVote(vote)
 		if !added {
 			return added, err
 		}
 
 		cs.Logger.Info(fmt.Sprintf("Added to lastPrecommits: %v", cs.LastCommit.StringShort()))
+		if !cs.replayMode {
+			cs.validatorStats.voteReceived(vote)
+		}
 		cs.eventBus.PublishEventVote(types.EventDataVote{Vote: vote})
 		cs.evsw.FireEvent(types.EventVote, vote)
 
@@ -1730,11 +1999,19 @@ func (cs *State) addVote(
 	}
 
 	height := cs.Height
+	if vote.Type == types.PrecommitType {
+		if err = cs.verifyVoteExtension(vote, cs.Validators, cs.Votes.Precommits(vote.Round)); err != nil {
+			return added, err
+		}
+	}
 	added, err = cs.Votes.AddVote(vote, peerID)
 	if !added {
 		// Either duplicate, or error upon cs.Votes.AddByIndex()
 		return
 	}
+	if !cs.replayMode {
+		cs.validatorStats.voteReceived(vote)
+	}
 
 	cs.eventBus.PublishEventVote(types.EventDataVote{Vote: vote})
 	cs.evsw.FireEvent(types.EventVote, vote)
@@ -1746,6 +2023,9 @@ func (cs *State) addVote(
 
 		// If +2/3 prevotes for a block or nil for *any* round:
 		if blockID, ok := prevotes.TwoThirdsMajority(); ok {
+			if vote.Round == cs.Round {
+				cs.timeouts.prevotesReceived()
+			}
 
 			// There was a polka!
 			// If we're locked but this is a recent polka, unlock.
@@ -1815,6 +2095,9 @@ func (cs *State) addVote(
 
 		blockID, ok := precommits.TwoThirdsMajority()
 		if ok {
+			if vote.Round == cs.Round {
+				cs.timeouts.precommitsReceived()
+			}
 			// Executed as TwoThirdsMajority could be from a higher round
 			cs.enterNewRound(height, vote.Round)
 			cs.enterPrecommit(height, vote.Round)
@@ -1838,6 +2121,41 @@ func (cs *State) addVote(
 	return added, err
 }
 
+// verifyVoteExtension checks the extension of the given precommit: absent if
+// vote extensions are disabled, accepted by the application otherwise. The
+// signature of the vote is verified first, unless it's already in voteSet, so
+// that the application only sees the extensions of the validators in valSet.
+func (cs *State) verifyVoteExtension(vote *types.Vote, valSet *types.ValidatorSet, voteSet *types.VoteSet) error {
+	if vote.BlockID.IsZero() {
+		// ValidateBasic rejects the extensions of nil precommits
+		return nil
+	}
+	if !cs.state.ConsensusParams.ABCI.VoteExtensionsEnabled(vote.Height) {
+		if len(vote.Extension) > 0 {
+			return errors.New("unexpected vote extension, vote extensions are disabled")
+		}
+		return nil
+	}
+
+	// our own extension, returned by the application
+	if cs.privValidator != nil && bytes.Equal(vote.ValidatorAddress, cs.privValidatorAddr) {
+		return nil
+	}
+	_, val := valSet.GetByIndex(vote.ValidatorIndex)
+	if val == nil {
+		// the VoteSet rejects it
+		return nil
+	}
+	if existing := voteSet.GetByIndex(vote.ValidatorIndex); existing != nil &&
+		bytes.Equal(existing.Signature, vote.Signature) {
+		return nil
+	}
+	if err := vote.Verify(cs.state.ChainID, val.PubKey); err != nil {
+		return err
+	}
+	return cs.blockExec.VerifyVoteExtension(vote)
+}
+
 func (cs *State) signVote(
 	msgType types.SignedMsgType,
 	hash []byte,
@@ -1859,8 +2177,54 @@ func (cs *State) signVote(
 		Type:             msgType,
 		BlockID:          types.BlockID{Hash: hash, PartsHeader: header},
 	}
-	err := cs.privValidator.SignVote(cs.state.ChainID, vote)
-	return vote, err
+	if msgType == types.PrecommitType && len(hash) > 0 &&
+		cs.state.ConsensusParams.ABCI.VoteExtensionsEnabled(cs.Height) {
+		ext, err := cs.blockExec.ExtendVote(vote)
+		if err != nil {
+			return vote, err
+		}
+		vote.Extension = ext
+	}
+	if err := cs.signState.checkVote(vote); err != nil {
+		cs.doubleSignPrevented(vote.Height, vote.Round, vote.Type, vote.BlockID, err)
+		return vote, err
+	}
+	if err := cs.privValidator.SignVote(cs.state.ChainID, vote); err != nil {
+		return vote, err
+	}
+	// record the vote before it's sent
+	return vote, cs.signState.recordVote(vote)
+}
+
+// signProposal signs the proposal, unless it conflicts with a proposal we
+// signed before.
+func (cs *State) signProposal(proposal *types.Proposal) error {
+	addr := cs.privValidator.GetPubKey().Address()
+	if err := cs.signState.checkProposal(addr, proposal); err != nil {
+		cs.doubleSignPrevented(proposal.Height, proposal.Round, types.ProposalType, proposal.BlockID, err)
+		return err
+	}
+	if err := cs.privValidator.SignProposal(cs.state.ChainID, proposal); err != nil {
+		return err
+	}
+	return cs.signState.recordProposal(addr, proposal)
+}
+
+// doubleSignPrevented reports a vote or a proposal which we refused to sign,
+// as it conflicted with one we signed before.
+func (cs *State) doubleSignPrevented(height int64, round int, msgType types.SignedMsgType, blockID types.BlockID,
+	err error) {
+	typeName := signedMsgTypeName(msgType)
+	cs.Logger.Error("Refused to sign a conflicting "+typeName+": the priv validator may be misconfigured",
+		"height", height, "round", round, "blockID", blockID, "err", err)
+	cs.metrics.DoubleSignsPrevented.With("type", typeName).Add(1)
+	cs.eventBus.PublishEventDoubleSignPrevented(types.EventDataDoubleSignPrevented{
+		Height:  height,
+		Round:   round,
+		Type:    typeName,
+		BlockID: blockID,
+		Reason:  err.Error(),
+	})
 }
 
 func (cs *State) voteTime() time.Time {
@@ -1890,7 +2254,7 @@ func (cs *State) signAddVote(msgType types.SignedMsgType, hash []byte, header ty
 	}
 	vote, err := cs.signVote(msgType, hash, header)
 	if err == nil {
-		cs.sendInternalMessage(msgInfo{&VoteMessage{vote}, ""})
+		cs.sendInternalMessage(msgInfo{&VoteMessage{vote}, "", tmtime.Now()})
 		cs.Logger.Info("Signed and pushed vote", "height", cs.Height, "round", cs.Round, "vote", vote, "err", err)
 		return vote
 	}
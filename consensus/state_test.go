@@ -7,15 +7,23 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/abci/example/counter"
+	abci "github.com/tendermint/tendermint/abci/types"
 	cstypes "github.com/tendermint/tendermint/consensus/types"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	"github.com/tendermint/tendermint/libs/log"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	p2pmock "github.com/tendermint/tendermint/p2p/mock"
+	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
 )
 
 /*
@@ -86,6 +94,88 @@ func TestStateProposerSelection0(t *testing.T) {
 	}
 }
 
+func TestStateProposerSelectionVRF(t *testing.T) {
+	cs1, _ := randState(4)
+
+	state := cs1.state.Copy()
+	// disabled by default
+	assert.Equal(t, cs1.Validators, cs1.withVRFProposer(state, cs1.Validators, 0))
+
+	state.ConsensusParams.Validator.ProposerSelection = types.ProposerSelectionVRF
+	for round := 0; round < 10; round++ {
+		vals := cs1.withVRFProposer(state, cs1.Validators, round)
+		seed := types.ProposerSeed(state.ChainID, state.VRFSeed(), state.LastBlockHeight+1, round)
+		assert.Equal(t, cs1.Validators.RandomProposer(seed).Address, vals.GetProposer().Address)
+	}
+}
+
+func TestStateVRFProposerProof(t *testing.T) {
+	state, privVals := randGenesisState(4, false, 10)
+	state.ConsensusParams.Validator.ProposerSelection = types.ProposerSelectionVRF
+	cs1 := newState(state, privVals[0], counter.NewApplication(true))
+	height, round := cs1.Height, cs1.Round
+
+	var proposerPV, otherPV types.PrivValidator
+	for _, pv := range privVals {
+		if bytes.Equal(pv.GetPubKey().Address(), cs1.Validators.GetProposer().Address) {
+			proposerPV = pv
+		} else {
+			otherPV = pv
+		}
+	}
+	blockID := types.BlockID{
+		Hash:        tmrand.Bytes(tmhash.Size),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: tmrand.Bytes(tmhash.Size)},
+	}
+	makeProposal := func(proof []byte) *types.Proposal {
+		proposal := types.NewProposal(height, round, -1, blockID)
+		proposal.ProposerProof = proof
+		require.NoError(t, proposerPV.SignProposal(state.ChainID, proposal))
+		return proposal
+	}
+
+	// no proof, or the proof of another validator
+	otherProof, err := types.ProveProposer(otherPV, state.ChainID, state.VRFSeed(), height)
+	require.NoError(t, err)
+	for _, proof := range [][]byte{nil, otherProof} {
+		err := cs1.defaultSetProposal(makeProposal(proof), tmtime.Now())
+		assert.Equal(t, ErrInvalidProposalProof, errors.Cause(err))
+		assert.Nil(t, cs1.Proposal)
+	}
+
+	proof, err := types.ProveProposer(proposerPV, state.ChainID, state.VRFSeed(), height)
+	require.NoError(t, err)
+	proposal := makeProposal(proof)
+	require.NoError(t, cs1.defaultSetProposal(proposal, tmtime.Now()))
+	assert.Equal(t, proposal, cs1.Proposal)
+}
+
+func TestStateVRFProposerSelectionCommits(t *testing.T) {
+	cs1, _ := randState(1)
+	cs1.state.ConsensusParams.Validator.ProposerSelection = types.ProposerSelectionVRF
+	height, round := cs1.Height, cs1.Round
+
+	newBlockCh := subscribe(cs1.eventBus, types.EventQueryNewBlock)
+	startTestRound(cs1, height, round)
+	ensureNewBlock(newBlockCh, height)
+	ensureNewBlock(newBlockCh, height+1)
+
+	// each block carries the proof of its proposer, whose output seeds the
+	// selection at the next height
+	pubKey := cs1.privValidator.GetPubKey()
+	seed := []byte(nil) // the hash of the (non-existent) block at height 0
+	for h := height; h <= height+1; h++ {
+		block := cs1.blockStore.LoadBlock(h)
+		require.Len(t, block.ProposerProof, vrf.ProofSize)
+		output, err := types.VerifyProposerProof(pubKey, cs1.state.ChainID, seed, h, block.ProposerProof)
+		require.NoError(t, err)
+		seed = output
+	}
+	cs1.mtx.RLock()
+	defer cs1.mtx.RUnlock()
+	assert.Equal(t, seed, cs1.state.LastVRFOutput)
+}
+
 // Now let's do it all again, but starting from round 2 instead of 0
 func TestStateProposerSelection2(t *testing.T) {
 	cs1, vss := randState(4) // test needs more work for more than 3 validators
@@ -230,6 +320,164 @@ func TestStateBadProposal(t *testing.T) {
 // FullRoundSuite
 
 // propose, prevote, and precommit a block
+func TestStateProposerBasedTimestamps(t *testing.T) {
+	cs1, vss := randState(1)
+	cs1.state.ConsensusParams.Synchrony.ProposerBasedTimestamps = true
+	height, round := cs1.Height, cs1.Round
+
+	proposalCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+	voteCh := subscribe(cs1.eventBus, types.EventQueryVote)
+
+	startTestRound(cs1, height, round)
+	ensureNewProposal(proposalCh, height, round)
+
+	// the proposal timestamp is the block time, which is the proposer's time
+	rs := cs1.GetRoundState()
+	assert.Equal(t, rs.ProposalBlock.Time, rs.Proposal.Timestamp)
+	assert.False(t, rs.ProposalBlock.Time.Before(cs1.state.LastBlockTime))
+	assert.WithinDuration(t, tmtime.Now(), rs.ProposalBlock.Time, time.Second)
+
+	ensurePrevote(voteCh, height, round)
+	validatePrevote(t, cs1, round, vss[0], rs.ProposalBlock.Hash())
+}
+
+func TestStateUntimelyProposal(t *testing.T) {
+	cs1, vss := randState(2)
+	cs1.state.ConsensusParams.Synchrony.ProposerBasedTimestamps = true
+	height, round := cs1.Height, cs1.Round
+	vs2 := vss[1]
+
+	proposalCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+	voteCh := subscribe(cs1.eventBus, types.EventQueryVote)
+
+	// make the second validator the proposer by incrementing round
+	round++
+	incrementRound(vss[1:]...)
+
+	// the block is valid, but its time is too far in the future
+	propBlock, _ := cs1.createProposalBlock()
+	propBlock.Time = tmtime.Now().Add(time.Hour)
+	propBlockParts := propBlock.MakePartSet(types.BlockPartSizeBytes)
+	blockID := types.BlockID{Hash: propBlock.Hash(), PartsHeader: propBlockParts.Header()}
+	proposal := types.NewProposal(vs2.Height, round, -1, blockID)
+	proposal.Timestamp = propBlock.Time
+	if err := vs2.SignProposal(config.ChainID(), proposal); err != nil {
+		t.Fatal("failed to sign untimely proposal", err)
+	}
+	if err := cs1.SetProposalAndBlock(proposal, propBlock, propBlockParts, "some peer"); err != nil {
+		t.Fatal(err)
+	}
+
+	startTestRound(cs1, height, round)
+	ensureProposal(proposalCh, height, round, blockID)
+
+	ensurePrevote(voteCh, height, round)
+	validatePrevote(t, cs1, round, vss[0], nil)
+}
+
+func TestStateOfflineProposer(t *testing.T) {
+	cs1, vss := randState(2)
+	cs1.config.TimeoutPropose = 10 * time.Second
+	cs1.config.OfflineProposerSlots = 1
+	cs1.config.TimeoutProposeOffline = 10 * time.Millisecond
+	height, round := cs1.Height, cs1.Round
+	vs2 := vss[1]
+
+	timeoutCh := subscribe(cs1.eventBus, types.EventQueryTimeoutPropose)
+
+	// make the second validator the proposer by incrementing round, and have
+	// it miss its last slot
+	round++
+	incrementRound(vss[1:]...)
+	cs1.proposerLiveness.slotMissed(vs2.GetPubKey().Address())
+
+	// timeout_propose is shortened
+	startTestRound(cs1, height, round)
+	ensureNewTimeout(timeoutCh, height, round, cs1.config.TimeoutProposeOffline.Nanoseconds())
+
+	// until a proposal of the second validator is received
+	propBlock, propBlockParts := cs1.createProposalBlock()
+	blockID := types.BlockID{Hash: propBlock.Hash(), PartsHeader: propBlockParts.Header()}
+	proposal := types.NewProposal(vs2.Height, round, -1, blockID)
+	if err := vs2.SignProposal(config.ChainID(), proposal); err != nil {
+		t.Fatal("failed to sign proposal", err)
+	}
+	if err := cs1.SetProposalAndBlock(proposal, propBlock, propBlockParts, "some peer"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	cs1.mtx.RLock()
+	assert.False(t, cs1.proposerLiveness.isOffline(vs2.GetPubKey().Address(), 1))
+	cs1.mtx.RUnlock()
+}
+
+// voteExtensionApp extends the precommits with the height, and only accepts
+// such extensions.
+type voteExtensionApp struct {
+	abci.Application
+}
+
+func (app voteExtensionApp) ExtendVote(req abci.RequestExtendVote) abci.ResponseExtendVote {
+	return abci.ResponseExtendVote{VoteExtension: []byte(fmt.Sprintf("height %d", req.Height))}
+}
+
+func (app voteExtensionApp) VerifyVoteExtension(
+	req abci.RequestVerifyVoteExtension) abci.ResponseVerifyVoteExtension {
+	if string(req.VoteExtension) != fmt.Sprintf("height %d", req.Height) {
+		return abci.ResponseVerifyVoteExtension{Status: abci.ResponseVerifyVoteExtension_REJECT}
+	}
+	return abci.ResponseVerifyVoteExtension{Status: abci.ResponseVerifyVoteExtension_ACCEPT}
+}
+
+func TestStateVoteExtensions(t *testing.T) {
+	state, privVals := randGenesisState(2, false, 10)
+	state.ConsensusParams.ABCI.VoteExtensionsEnableHeight = 1
+	cs1 := newState(state, privVals[0], voteExtensionApp{counter.NewApplication(true)})
+	vs2 := newValidatorStub(privVals[1], 1)
+	incrementHeight(vs2)
+	height, round := cs1.Height, cs1.Round
+
+	proposalCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+	voteCh := subscribe(cs1.eventBus, types.EventQueryVote)
+	newBlockCh := subscribe(cs1.eventBus, types.EventQueryNewBlock)
+
+	startTestRound(cs1, height, round)
+	ensureNewProposal(proposalCh, height, round)
+	rs := cs1.GetRoundState()
+	blockHash, blockParts := rs.ProposalBlock.Hash(), rs.ProposalBlockParts.Header()
+
+	ensurePrevote(voteCh, height, round)
+	signAddVotes(cs1, types.PrevoteType, blockHash, blockParts, vs2)
+	ensurePrevote(voteCh, height, round)
+
+	// our precommit is extended by the application
+	ensurePrecommit(voteCh, height, round)
+	precommit := cs1.GetRoundState().Votes.Precommits(round).GetByAddress(cs1.privValidator.GetPubKey().Address())
+	require.NotNil(t, precommit)
+	assert.Equal(t, []byte("height 1"), precommit.Extension)
+
+	// a precommit whose extension is rejected by the application isn't added
+	badPrecommit := signVote(vs2, types.PrecommitType, blockHash, blockParts)
+	badPrecommit.Extension = []byte("bad extension")
+	require.NoError(t, vs2.SignVote(config.ChainID(), badPrecommit))
+	addVotes(cs1, badPrecommit)
+	ensureNoNewEventOnChannel(voteCh)
+
+	goodPrecommit := signVote(vs2, types.PrecommitType, blockHash, blockParts)
+	goodPrecommit.Extension = []byte("height 1")
+	require.NoError(t, vs2.SignVote(config.ChainID(), goodPrecommit))
+	addVotes(cs1, goodPrecommit)
+	ensurePrecommit(voteCh, height, round)
+	ensureNewBlock(newBlockCh, height)
+
+	// the extensions are in the commit
+	commit := cs1.blockStore.LoadSeenCommit(height)
+	require.NotNil(t, commit)
+	for _, commitSig := range commit.Signatures {
+		assert.Equal(t, []byte("height 1"), commitSig.Extension)
+	}
+}
+
 func TestStateFullRound1(t *testing.T) {
 	cs, vss := randState(1)
 	height, round := cs.Height, cs.Round
@@ -265,6 +513,55 @@ func TestStateFullRound1(t *testing.T) {
 	validateLastPrecommit(t, cs, vss[0], propBlockHash)
 }
 
+// a validator with a secp256k1 key proposes and commits a block with another
+// one using an ed25519 key
+func TestStateFullRoundSecp256k1(t *testing.T) {
+	pv1 := types.NewMockPVWithParams(secp256k1.GenPrivKey(), false, false)
+	pv2 := types.NewMockPV()
+	genDoc := &types.GenesisDoc{
+		GenesisTime: tmtime.Now(),
+		ChainID:     config.ChainID(),
+		Validators: []types.GenesisValidator{
+			{PubKey: pv1.GetPubKey(), Power: 11}, // the first proposer
+			{PubKey: pv2.GetPubKey(), Power: 10},
+		},
+		ConsensusParams: types.DefaultConsensusParams(),
+	}
+	genDoc.ConsensusParams.Validator.PubKeyTypes = []string{types.ABCIPubKeyTypeEd25519, types.ABCIPubKeyTypeSecp256k1}
+	require.NoError(t, genDoc.ValidateAndComplete())
+	state, err := sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+
+	cs1 := newState(state, pv1, counter.NewApplication(true))
+	idx2, _ := state.Validators.GetByAddress(pv2.GetPubKey().Address())
+	vs2 := newValidatorStub(pv2, idx2)
+	incrementHeight(vs2)
+	height, round := cs1.Height, cs1.Round
+
+	voteCh := subscribe(cs1.eventBus, types.EventQueryVote)
+	propCh := subscribe(cs1.eventBus, types.EventQueryCompleteProposal)
+	newRoundCh := subscribe(cs1.eventBus, types.EventQueryNewRound)
+
+	startTestRound(cs1, height, round)
+
+	ensureNewProposal(propCh, height, round)
+	rs := cs1.GetRoundState()
+	propBlockHash, propPartsHeader := rs.ProposalBlock.Hash(), rs.ProposalBlockParts.Header()
+
+	ensurePrevote(voteCh, height, round)
+	signAddVotes(cs1, types.PrevoteType, propBlockHash, propPartsHeader, vs2)
+	ensurePrevote(voteCh, height, round)
+
+	ensurePrecommit(voteCh, height, round)
+	signAddVotes(cs1, types.PrecommitType, propBlockHash, propPartsHeader, vs2)
+	ensurePrecommit(voteCh, height, round)
+
+	ensureNewRound(newRoundCh, height, round)
+	ensureNewRound(newRoundCh, height+1, 0)
+	validateLastPrecommit(t, cs1, vs2, propBlockHash)
+	assert.NoError(t, cs1.LastCommit.MakeCommit().ValidateBasic())
+}
+
 // nil is proposed, so prevote and precommit nil
 func TestStateFullRoundNil(t *testing.T) {
 	cs, vss := randState(1)
@@ -1571,26 +1868,26 @@ func TestStateOutputsBlockPartsStats(t *testing.T) {
 	}
 
 	cs.ProposalBlockParts = types.NewPartSetFromHeader(parts.Header())
-	cs.handleMsg(msgInfo{msg, peer.ID()})
+	cs.handleMsg(msgInfo{Msg: msg, PeerID: peer.ID()})
 
 	statsMessage := <-cs.statsMsgQueue
 	require.Equal(t, msg, statsMessage.Msg, "")
 	require.Equal(t, peer.ID(), statsMessage.PeerID, "")
 
 	// sending the same part from different peer
-	cs.handleMsg(msgInfo{msg, "peer2"})
+	cs.handleMsg(msgInfo{Msg: msg, PeerID: "peer2"})
 
 	// sending the part with the same height, but different round
 	msg.Round = 1
-	cs.handleMsg(msgInfo{msg, peer.ID()})
+	cs.handleMsg(msgInfo{Msg: msg, PeerID: peer.ID()})
 
 	// sending the part from the smaller height
 	msg.Height = 0
-	cs.handleMsg(msgInfo{msg, peer.ID()})
+	cs.handleMsg(msgInfo{Msg: msg, PeerID: peer.ID()})
 
 	// sending the part from the bigger height
 	msg.Height = 3
-	cs.handleMsg(msgInfo{msg, peer.ID()})
+	cs.handleMsg(msgInfo{Msg: msg, PeerID: peer.ID()})
 
 	select {
 	case <-cs.statsMsgQueue:
@@ -1608,20 +1905,20 @@ func TestStateOutputVoteStats(t *testing.T) {
 	vote := signVote(vss[1], types.PrecommitType, []byte("test"), types.PartSetHeader{})
 
 	voteMessage := &VoteMessage{vote}
-	cs.handleMsg(msgInfo{voteMessage, peer.ID()})
+	cs.handleMsg(msgInfo{Msg: voteMessage, PeerID: peer.ID()})
 
 	statsMessage := <-cs.statsMsgQueue
 	require.Equal(t, voteMessage, statsMessage.Msg, "")
 	require.Equal(t, peer.ID(), statsMessage.PeerID, "")
 
 	// sending the same part from different peer
-	cs.handleMsg(msgInfo{&VoteMessage{vote}, "peer2"})
+	cs.handleMsg(msgInfo{Msg: &VoteMessage{vote}, PeerID: "peer2"})
 
 	// sending the vote for the bigger height
 	incrementHeight(vss[1])
 	vote = signVote(vss[1], types.PrecommitType, []byte("test"), types.PartSetHeader{})
 
-	cs.handleMsg(msgInfo{&VoteMessage{vote}, peer.ID()})
+	cs.handleMsg(msgInfo{Msg: &VoteMessage{vote}, PeerID: peer.ID()})
 
 	select {
 	case <-cs.statsMsgQueue:
@@ -1631,6 +1928,42 @@ func TestStateOutputVoteStats(t *testing.T) {
 
 }
 
+func TestStateDoubleSignPrevented(t *testing.T) {
+	cs1, _ := randState(1)
+	preventedCh := subscribe(cs1.eventBus, types.EventQueryDoubleSignPrevented)
+	hash := tmrand.Bytes(tmhash.Size)
+	header := types.PartSetHeader{Total: 1, Hash: tmrand.Bytes(tmhash.Size)}
+
+	cs1.mtx.Lock()
+	defer cs1.mtx.Unlock()
+
+	// the same vote can be signed again, e.g. when replaying the WAL
+	_, err := cs1.signVote(types.PrevoteType, hash, header)
+	require.NoError(t, err)
+	_, err = cs1.signVote(types.PrevoteType, hash, header)
+	require.NoError(t, err)
+	_, err = cs1.signVote(types.PrecommitType, nil, types.PartSetHeader{})
+	require.NoError(t, err)
+
+	// but not a conflicting one, even if the priv validator has no checks
+	_, err = cs1.signVote(types.PrevoteType, nil, types.PartSetHeader{})
+	assert.Equal(t, ErrConflictingSignature, errors.Cause(err))
+	select {
+	case msg := <-preventedCh:
+		data := msg.Data().(types.EventDataDoubleSignPrevented)
+		assert.Equal(t, cs1.Height, data.Height)
+		assert.Equal(t, "prevote", data.Type)
+		assert.Equal(t, types.BlockID{}, data.BlockID)
+	case <-time.After(time.Second):
+		t.Fatal("expected a DoubleSignPrevented event")
+	}
+
+	proposal := types.NewProposal(cs1.Height, 0, -1, types.BlockID{Hash: hash, PartsHeader: header})
+	require.NoError(t, cs1.signProposal(proposal))
+	proposal = types.NewProposal(cs1.Height, 0, -1, types.BlockID{})
+	assert.Equal(t, ErrConflictingSignature, errors.Cause(cs1.signProposal(proposal)))
+}
+
 // subscribe subscribes test client to the given query and returns a channel with cap = 1.
 func subscribe(eventBus *types.EventBus, q tmpubsub.Query) <-chan tmpubsub.Message {
 	sub, err := eventBus.Subscribe(context.Background(), testSubscriber, q)
@@ -1648,3 +1981,29 @@ func subscribeUnBuffered(eventBus *types.EventBus, q tmpubsub.Query) <-chan tmpu
 	}
 	return sub.Out()
 }
+
+func TestStatePanicHandler(t *testing.T) {
+	cs1, _ := randState(1)
+	cs1.doPrevote = func(height int64, round int) { panic("boom") }
+
+	handled := make(chan interface{}, 1)
+	cs1.SetPanicHandler(func(cs *State, r interface{}, stack []byte) {
+		assert.Equal(t, cs1, cs)
+		assert.NotEmpty(t, stack)
+		handled <- r
+	})
+
+	startTestRound(cs1, cs1.Height, 0)
+	select {
+	case r := <-handled:
+		assert.Equal(t, "boom", r)
+	case <-time.After(5 * time.Second):
+		t.Fatal("panic handler was not called")
+	}
+	// consensus halts after the handler returns
+	select {
+	case <-cs1.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive routine did not exit")
+	}
+}
@@ -0,0 +1,39 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cstypes "github.com/tendermint/tendermint/consensus/types"
+	"github.com/tendermint/tendermint/libs/clock"
+)
+
+func TestTimeoutTickerFakeClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(0, 0))
+	ticker := NewTimeoutTickerWithClock(c)
+	require.NoError(t, ticker.Start())
+	defer ticker.Stop()
+
+	ti := timeoutInfo{Duration: time.Second, Height: 1, Round: 0, Step: cstypes.RoundStepPropose}
+	ticker.ScheduleTimeout(ti)
+	c.BlockUntil(1)
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-ticker.Chan():
+		t.Fatal("timed out early")
+	default:
+	}
+	c.Advance(time.Millisecond)
+	assert.Equal(t, ti, <-ticker.Chan())
+
+	// timeouts for earlier heights are ignored, even if they are due already
+	ticker.ScheduleTimeout(timeoutInfo{Duration: 0, Height: 0, Round: 0, Step: cstypes.RoundStepPropose})
+	ti = timeoutInfo{Duration: time.Second, Height: 2, Round: 0, Step: cstypes.RoundStepPropose}
+	ticker.ScheduleTimeout(ti)
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+	assert.Equal(t, ti, <-ticker.Chan())
+}
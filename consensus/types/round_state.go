@@ -91,6 +91,9 @@ type RoundState struct {
 	LastCommit                *types.VoteSet      `json:"last_commit"`  // Last precommits at Height-1
 	LastValidators            *types.ValidatorSet `json:"last_validators"`
 	TriggeredTimeoutPrecommit bool                `json:"triggered_timeout_precommit"`
+
+	// Local time when the Proposal was received (see proposer-based timestamps)
+	ProposalReceiveTime time.Time `json:"proposal_receive_time"`
 }
 
 // Compressed version of the RoundState for use in RPC
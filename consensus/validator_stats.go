@@ -0,0 +1,139 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// validatorStatsWindow is the number of the latest rounds in which the
+// missed rounds of the validators are counted.
+const validatorStatsWindow = 100
+
+// ValidatorStats are the statistics of a validator, as seen by this node
+// (see /dump_consensus_state).
+type ValidatorStats struct {
+	Address types.Address `json:"address"`
+	// height and round of the last vote received from the validator in the
+	// round it was cast for
+	LastVoteHeight int64 `json:"last_vote_height"`
+	LastVoteRound  int   `json:"last_vote_round"`
+	// time from the start of the round (at this node) to the reception of
+	// the last prevote (or precommit) of the validator
+	PrevoteLatency   time.Duration `json:"prevote_latency"`
+	PrecommitLatency time.Duration `json:"precommit_latency"`
+	// number of the latest rounds (at most validatorStatsWindow) ended
+	// without a precommit of the validator, and of the rounds counted
+	RoundsMissed   int `json:"rounds_missed"`
+	RoundsInWindow int `json:"rounds_in_window"`
+
+	missed []bool // ring buffer of the rounds in the window
+	next   int    // next position in missed
+}
+
+// validatorStatsTracker tracks the votes of the validators in each round, from
+// its start to the start of the next one (which is after the timeout commit
+// when the round is committed, the late precommits included).
+//
+// NOTE: not thread safe, it's only used by the State, under its mutex.
+type validatorStatsTracker struct {
+	stats map[string]*ValidatorStats // by address
+
+	// the round being tracked
+	height     int64
+	round      int
+	start      time.Time
+	validators *types.ValidatorSet
+	precommits map[string]bool // by address
+}
+
+// enterRound ends the round being tracked, if any, and starts tracking the
+// given one.
+func (t *validatorStatsTracker) enterRound(height int64, round int, validators *types.ValidatorSet) {
+	if t.stats == nil {
+		t.stats = make(map[string]*ValidatorStats)
+	}
+	if t.validators != nil {
+		t.endRound()
+	}
+	t.height = height
+	t.round = round
+	t.start = time.Now()
+	t.validators = validators
+	t.precommits = make(map[string]bool, validators.Size())
+}
+
+// endRound counts the round as missed by the validators which didn't
+// precommit, and forgets the validators which left the validator set.
+func (t *validatorStatsTracker) endRound() {
+	stats := make(map[string]*ValidatorStats, t.validators.Size())
+	for _, val := range t.validators.Validators {
+		key := string(val.Address)
+		s, ok := t.stats[key]
+		if !ok {
+			s = &ValidatorStats{Address: val.Address, missed: make([]bool, validatorStatsWindow)}
+		}
+		if s.RoundsInWindow == validatorStatsWindow && s.missed[s.next] {
+			s.RoundsMissed--
+		}
+		missed := !t.precommits[key]
+		s.missed[s.next] = missed
+		s.next = (s.next + 1) % validatorStatsWindow
+		if missed {
+			s.RoundsMissed++
+		}
+		if s.RoundsInWindow < validatorStatsWindow {
+			s.RoundsInWindow++
+		}
+		stats[key] = s
+	}
+	t.stats = stats
+}
+
+// voteReceived records the latency of the given vote, if it's for the round
+// being tracked.
+func (t *validatorStatsTracker) voteReceived(vote *types.Vote) {
+	if t.validators == nil || vote.Height != t.height || vote.Round != t.round {
+		return
+	}
+	key := string(vote.ValidatorAddress)
+	s, ok := t.stats[key]
+	if !ok {
+		if !t.validators.HasAddress(vote.ValidatorAddress) {
+			return
+		}
+		s = &ValidatorStats{Address: vote.ValidatorAddress, missed: make([]bool, validatorStatsWindow)}
+		t.stats[key] = s
+	}
+
+	latency := time.Since(t.start)
+	switch vote.Type {
+	case types.PrevoteType:
+		s.PrevoteLatency = latency
+	case types.PrecommitType:
+		s.PrecommitLatency = latency
+		t.precommits[key] = true
+	}
+	s.LastVoteHeight = vote.Height
+	s.LastVoteRound = vote.Round
+}
+
+// Stats returns the statistics of the validators of the round being tracked,
+// in the order of the validator set.
+func (t *validatorStatsTracker) Stats() []ValidatorStats {
+	if t.validators == nil {
+		return []ValidatorStats{}
+	}
+	stats := make([]ValidatorStats, 0, t.validators.Size())
+	for _, val := range t.validators.Validators {
+		s, ok := t.stats[string(val.Address)]
+		if !ok {
+			stats = append(stats, ValidatorStats{Address: val.Address})
+			continue
+		}
+		vs := *s
+		vs.missed = nil
+		stats = append(stats, vs)
+	}
+	return stats
+}
@@ -0,0 +1,69 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestValidatorStatsTracker(t *testing.T) {
+	valSet, _ := types.RandValidatorSet(2, 10)
+	val0, val1 := valSet.Validators[0].Address, valSet.Validators[1].Address
+	vote := func(addr types.Address, height int64, round int, voteType types.SignedMsgType) *types.Vote {
+		return &types.Vote{ValidatorAddress: addr, Height: height, Round: round, Type: voteType}
+	}
+
+	var tracker validatorStatsTracker
+	assert.Empty(t, tracker.Stats())
+
+	// both validators vote in round 1/0, only val0 precommits in 1/1
+	tracker.enterRound(1, 0, valSet)
+	time.Sleep(10 * time.Millisecond)
+	tracker.voteReceived(vote(val0, 1, 0, types.PrevoteType))
+	tracker.voteReceived(vote(val1, 1, 0, types.PrevoteType))
+	tracker.voteReceived(vote(val0, 1, 0, types.PrecommitType))
+	tracker.voteReceived(vote(val1, 1, 0, types.PrecommitType))
+	tracker.enterRound(1, 1, valSet)
+	tracker.voteReceived(vote(val0, 1, 1, types.PrecommitType))
+	tracker.voteReceived(vote(val1, 1, 0, types.PrecommitType)) // late, ignored
+	tracker.enterRound(2, 0, valSet)
+
+	stats := tracker.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, val0, stats[0].Address)
+	assert.EqualValues(t, 1, stats[0].LastVoteHeight)
+	assert.Equal(t, 1, stats[0].LastVoteRound)
+	assert.Equal(t, 0, stats[0].RoundsMissed)
+	assert.Equal(t, 2, stats[0].RoundsInWindow)
+	assert.True(t, stats[0].PrevoteLatency >= 10*time.Millisecond)
+	assert.True(t, stats[0].PrecommitLatency < 10*time.Millisecond)
+
+	assert.Equal(t, val1, stats[1].Address)
+	assert.Equal(t, 0, stats[1].LastVoteRound)
+	assert.Equal(t, 1, stats[1].RoundsMissed)
+	assert.Equal(t, 2, stats[1].RoundsInWindow)
+
+	// the missed rounds are only counted in the window
+	for i := 0; i < validatorStatsWindow; i++ {
+		tracker.voteReceived(vote(val1, int64(2+i), 0, types.PrecommitType))
+		tracker.enterRound(int64(3+i), 0, valSet)
+	}
+	stats = tracker.Stats()
+	assert.Equal(t, validatorStatsWindow, stats[0].RoundsMissed)
+	assert.Equal(t, 0, stats[1].RoundsMissed)
+	assert.Equal(t, validatorStatsWindow, stats[1].RoundsInWindow)
+
+	// validators leaving the validator set are forgotten
+	newValSet, _ := types.RandValidatorSet(1, 10)
+	tracker.enterRound(200, 0, newValSet)
+	tracker.enterRound(201, 0, newValSet)
+	stats = tracker.Stats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, newValSet.Validators[0].Address, stats[0].Address)
+	assert.Equal(t, 1, stats[0].RoundsMissed)
+	assert.Len(t, tracker.stats, 1)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 
 	amino "github.com/tendermint/go-amino"
 	auto "github.com/tendermint/tendermint/libs/autofile"
+	"github.com/tendermint/tendermint/libs/failpoint"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
 	"github.com/tendermint/tendermint/libs/service"
@@ -48,6 +50,42 @@ type EndHeightMessage struct {
 
 type WALMessage interface{}
 
+// Kinds of WAL messages, as returned by WALMessageKind.
+const (
+	WALKindEndHeight  = "end_height"
+	WALKindRoundState = "round_state"
+	WALKindProposal   = "proposal"
+	WALKindBlockPart  = "block_part"
+	WALKindVote       = "vote"
+	WALKindTimeout    = "timeout"
+)
+
+// WALMessageKind returns the kind of msg and the height and round it belongs
+// to, for the WAL tooling. The round of an EndHeightMessage is -1, as are the
+// height and round of messages of unknown kinds, whose kind is their type.
+// @internal used by the wal command.
+func WALMessageKind(msg WALMessage) (kind string, height int64, round int) {
+	switch m := msg.(type) {
+	case EndHeightMessage:
+		return WALKindEndHeight, m.Height, -1
+	case types.EventDataRoundState:
+		return WALKindRoundState, m.Height, m.Round
+	case timeoutInfo:
+		return WALKindTimeout, m.Height, m.Round
+	case msgInfo:
+		switch mm := m.Msg.(type) {
+		case *ProposalMessage:
+			return WALKindProposal, mm.Proposal.Height, mm.Proposal.Round
+		case *BlockPartMessage:
+			return WALKindBlockPart, mm.Height, mm.Round
+		case *VoteMessage:
+			return WALKindVote, mm.Vote.Height, mm.Vote.Round
+		}
+		return fmt.Sprintf("%T", m.Msg), -1, -1
+	}
+	return fmt.Sprintf("%T", msg), -1, -1
+}
+
 func RegisterWALMessages(cdc *amino.Codec) {
 	cdc.RegisterInterface((*WALMessage)(nil), nil)
 	cdc.RegisterConcrete(types.EventDataRoundState{}, "tendermint/wal/EventDataRoundState", nil)
@@ -185,6 +223,9 @@ func (wal *BaseWAL) Write(msg WALMessage) error {
 		return nil
 	}
 
+	if err := failpoint.Inject("consensus/wal-write"); err != nil {
+		return err
+	}
 	if err := wal.enc.Encode(&TimedWALMessage{tmtime.Now(), msg}); err != nil {
 		wal.Logger.Error("Error writing msg to consensus wal. WARNING: recover may not be possible for the current height",
 			"err", err, "msg", msg)
@@ -206,6 +247,9 @@ func (wal *BaseWAL) WriteSync(msg WALMessage) error {
 		return err
 	}
 
+	if err := failpoint.Inject("consensus/wal-before-sync"); err != nil {
+		return err
+	}
 	if err := wal.FlushAndSync(); err != nil {
 		wal.Logger.Error(`WriteSync failed to flush consensus wal. 
 		WARNING: may result in creating alternative proposals / votes for the current height iff the node restarted`,
@@ -226,6 +270,11 @@ type WALSearchOptions struct {
 // and returns an auto.GroupReader, whenever it was found or not and an error.
 // Group reader will be nil if found equals false.
 //
+// The files are searched from the last one, and the heights ending after the
+// given one are skipped: a file is only decoded up to its first
+// EndHeightMessage if the latter is for a greater height, so that searching
+// for the last height doesn't decode (or decompress) the whole WAL.
+//
 // CONTRACT: caller must close group reader.
 func (wal *BaseWAL) SearchForEndHeight(
 	height int64,
@@ -247,6 +296,8 @@ func (wal *BaseWAL) SearchForEndHeight(
 		}
 
 		dec := NewWALDecoder(gr)
+		firstInFile := true
+	FILE:
 		for {
 			msg, err = dec.Decode()
 			if err == io.EOF {
@@ -269,10 +320,19 @@ func (wal *BaseWAL) SearchForEndHeight(
 
 			if m, ok := msg.Msg.(EndHeightMessage); ok {
 				lastHeightFound = m.Height
-				if m.Height == height { // found
+				switch {
+				case m.Height == height: // found
 					wal.Logger.Info("Found", "height", height, "index", index)
 					return gr, true, nil
+				case m.Height > height && firstInFile:
+					// the height ended in an older file, skip the rest of this one
+					break FILE
+				case m.Height > height:
+					// the heights are increasing, so it's not in the WAL
+					gr.Close()
+					return nil, false, nil
 				}
+				firstInFile = false
 			}
 		}
 		gr.Close()
@@ -356,7 +416,7 @@ func NewWALDecoder(rd io.Reader) *WALDecoder {
 func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 	b := make([]byte, 4)
 
-	_, err := dec.rd.Read(b)
+	_, err := io.ReadFull(dec.rd, b)
 	if err == io.EOF {
 		return nil, err
 	}
@@ -366,7 +426,7 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 	crc := binary.BigEndian.Uint32(b)
 
 	b = make([]byte, 4)
-	_, err = dec.rd.Read(b)
+	_, err = io.ReadFull(dec.rd, b)
 	if err != nil {
 		return nil, DataCorruptionError{fmt.Errorf("failed to read length: %v", err)}
 	}
@@ -380,7 +440,7 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 	}
 
 	data := make([]byte, length)
-	n, err := dec.rd.Read(data)
+	n, err := io.ReadFull(dec.rd, data)
 	if err != nil {
 		return nil, DataCorruptionError{fmt.Errorf("failed to read data: %v (read: %d, wanted: %d)", err, n, length)}
 	}
@@ -413,3 +473,40 @@ func (nilWAL) SearchForEndHeight(height int64, options *WALSearchOptions) (rd io
 func (nilWAL) Start() error { return nil }
 func (nilWAL) Stop() error  { return nil }
 func (nilWAL) Wait()        {}
+
+// WriteWALTail writes the last n messages of the WAL file at path (the head of
+// the WAL group) to w as JSON, one message per line. Decoding stops at the
+// first corrupted message, e.g. one which was only partially written.
+func WriteWALTail(w io.Writer, path string, n int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var tail []*TimedWALMessage
+	dec := NewWALDecoder(f)
+	for {
+		msg, err := dec.Decode()
+		if err == io.EOF || IsDataCorruptionError(err) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if len(tail) == n {
+			tail = tail[1:]
+		}
+		tail = append(tail, msg)
+	}
+
+	for _, msg := range tail {
+		bz, err := cdc.MarshalJSON(msg)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(bz, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
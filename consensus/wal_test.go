@@ -6,9 +6,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	// "sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -104,6 +106,46 @@ func TestWALEncoderDecoder(t *testing.T) {
 	}
 }
 
+func TestWALDecoderShortReads(t *testing.T) {
+	b := new(bytes.Buffer)
+	enc := NewWALEncoder(b)
+	require.NoError(t, enc.Encode(&TimedWALMessage{Time: tmtime.Now(), Msg: EndHeightMessage{1}}))
+	require.NoError(t, enc.Encode(&TimedWALMessage{Time: tmtime.Now(), Msg: EndHeightMessage{2}}))
+
+	// e.g. a gzip reader
+	dec := NewWALDecoder(iotest.OneByteReader(b))
+	for _, height := range []int64{1, 2} {
+		decoded, err := dec.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, EndHeightMessage{height}, decoded.Msg)
+	}
+}
+
+func TestWALMessageKind(t *testing.T) {
+	vote := &tmtypes.Vote{Height: 3, Round: 1}
+	proposal := &tmtypes.Proposal{Height: 3, Round: 2}
+	testCases := []struct {
+		msg    WALMessage
+		kind   string
+		height int64
+		round  int
+	}{
+		{EndHeightMessage{2}, WALKindEndHeight, 2, -1},
+		{tmtypes.EventDataRoundState{Height: 3, Round: 1}, WALKindRoundState, 3, 1},
+		{timeoutInfo{Height: 3, Round: 1}, WALKindTimeout, 3, 1},
+		{msgInfo{Msg: &VoteMessage{vote}}, WALKindVote, 3, 1},
+		{msgInfo{Msg: &ProposalMessage{proposal}}, WALKindProposal, 3, 2},
+		{msgInfo{Msg: &BlockPartMessage{Height: 3, Round: 2}}, WALKindBlockPart, 3, 2},
+		{msgInfo{Msg: &HasVoteMessage{Height: 3}}, "*consensus.HasVoteMessage", -1, -1},
+	}
+	for _, tc := range testCases {
+		kind, height, round := WALMessageKind(tc.msg)
+		assert.Equal(t, tc.kind, kind)
+		assert.Equal(t, tc.height, height, tc.kind)
+		assert.Equal(t, tc.round, round, tc.kind)
+	}
+}
+
 func TestWALWrite(t *testing.T) {
 	walDir, err := ioutil.TempDir("", "wal")
 	require.NoError(t, err)
@@ -167,6 +209,90 @@ func TestWALSearchForEndHeight(t *testing.T) {
 	assert.Equal(t, rs.Height, h+1, "wrong height")
 }
 
+func TestWALSearchForEndHeightSkipsLaterHeights(t *testing.T) {
+	walDir, err := ioutil.TempDir("", "wal")
+	require.NoError(t, err)
+	defer os.RemoveAll(walDir)
+
+	walFile := filepath.Join(walDir, "wal")
+	wal, err := NewWAL(walFile,
+		autofile.GroupHeadSizeLimit(0),
+		autofile.GroupCompression(autofile.CompressionSnappy),
+	)
+	require.NoError(t, err)
+	wal.SetLogger(log.TestingLogger())
+	require.NoError(t, wal.Start())
+	defer func() {
+		wal.Stop()
+		wal.Wait()
+	}()
+
+	// heights 1 to 3 in the first (compressed) file, and 4 to 6 in the head,
+	// with a corrupted entry after height 4
+	for h := int64(1); h <= 3; h++ {
+		require.NoError(t, wal.Write(EndHeightMessage{h}))
+	}
+	require.NoError(t, wal.FlushAndSync())
+	wal.Group().RotateFile()
+	require.NoError(t, wal.Write(EndHeightMessage{4}))
+	_, err = wal.Group().Write([]byte{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	require.NoError(t, err)
+	for h := int64(5); h <= 6; h++ {
+		require.NoError(t, wal.Write(EndHeightMessage{h}))
+	}
+	require.NoError(t, wal.FlushAndSync())
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(walFile + ".000.sz")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "the rotated file should have been compressed")
+
+	// The head is only decoded up to height 4, so the corrupted entry isn't
+	// read.
+	gr, found, err := wal.SearchForEndHeight(2, &WALSearchOptions{})
+	require.NoError(t, err)
+	require.True(t, found)
+	msg, err := NewWALDecoder(gr).Decode()
+	require.NoError(t, err)
+	assert.Equal(t, EndHeightMessage{3}, msg.Msg)
+	gr.Close()
+
+	gr, found, err = wal.SearchForEndHeight(6, &WALSearchOptions{IgnoreDataCorruptionErrors: true})
+	require.NoError(t, err)
+	require.True(t, found)
+	gr.Close()
+
+	_, found, err = wal.SearchForEndHeight(7, &WALSearchOptions{IgnoreDataCorruptionErrors: true})
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestWriteWALTail(t *testing.T) {
+	walBody, err := WALWithNBlocks(t, 3)
+	require.NoError(t, err)
+	// a partially written message at the end is ignored
+	walFile := tempWALWithData(append(walBody, 0x01, 0x02, 0x03))
+	defer os.Remove(walFile)
+
+	var total int
+	dec := NewWALDecoder(bytes.NewReader(walBody))
+	for {
+		if _, err := dec.Decode(); err != nil {
+			break
+		}
+		total++
+	}
+	require.True(t, total > 5)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteWALTail(&buf, walFile, 5))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 5)
+
+	buf.Reset()
+	require.NoError(t, WriteWALTail(&buf, walFile, total+10))
+	assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), total)
+}
+
 func TestWALPeriodicSync(t *testing.T) {
 	walDir, err := ioutil.TempDir("", "wal")
 	require.NoError(t, err)
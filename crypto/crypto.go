@@ -38,3 +38,14 @@ type Symmetric interface {
 	Encrypt(plaintext []byte, secret []byte) (ciphertext []byte)
 	Decrypt(ciphertext []byte, secret []byte) (plaintext []byte, err error)
 }
+
+// BatchVerifier verifies many signatures at once, which is faster than
+// verifying them one by one.
+type BatchVerifier interface {
+	// Add adds a signature to the batch. It returns an error if the key type
+	// or the signature is not supported by the verifier.
+	Add(key PubKey, msg, sig []byte) error
+	// Verify returns true if all the signatures of the batch are valid and,
+	// for each signature, whether it is valid.
+	Verify() (bool, []bool)
+}
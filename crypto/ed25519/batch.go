@@ -0,0 +1,58 @@
+package ed25519
+
+import (
+	"fmt"
+
+	"github.com/hdevalence/ed25519consensus"
+
+	"github.com/tendermint/tendermint/crypto"
+)
+
+var _ crypto.BatchVerifier = &BatchVerifier{}
+
+// BatchVerifier implements crypto.BatchVerifier for Ed25519 signatures,
+// following the ZIP 215 rules like VerifyBytes.
+type BatchVerifier struct {
+	batch ed25519consensus.BatchVerifier
+	keys  []PubKeyEd25519
+	msgs  [][]byte
+	sigs  [][]byte
+}
+
+// NewBatchVerifier returns an empty batch verifier.
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{batch: ed25519consensus.NewBatchVerifier()}
+}
+
+// Add adds a signature to the batch. key must be a PubKeyEd25519.
+func (b *BatchVerifier) Add(key crypto.PubKey, msg, sig []byte) error {
+	pubKey, ok := key.(PubKeyEd25519)
+	if !ok {
+		return fmt.Errorf("pubkey is not Ed25519: %T", key)
+	}
+	if len(sig) != SignatureSize {
+		return fmt.Errorf("invalid signature size: expected %d, got %d", SignatureSize, len(sig))
+	}
+	b.batch.Add(pubKey[:], msg, sig)
+	b.keys = append(b.keys, pubKey)
+	b.msgs = append(b.msgs, msg)
+	b.sigs = append(b.sigs, sig)
+	return nil
+}
+
+// Verify verifies the signatures of the batch at once. If the batch is not
+// valid, the signatures are verified one by one to find the invalid ones.
+// An empty batch is not valid.
+func (b *BatchVerifier) Verify() (bool, []bool) {
+	valid := make([]bool, len(b.keys))
+	if b.batch.Verify() {
+		for i := range valid {
+			valid[i] = true
+		}
+		return true, valid
+	}
+	for i, key := range b.keys {
+		valid[i] = key.VerifyBytes(b.msgs[i], b.sigs[i])
+	}
+	return false, valid
+}
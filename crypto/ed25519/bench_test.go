@@ -1,6 +1,7 @@
 package ed25519
 
 import (
+	"fmt"
 	"io"
 	"testing"
 
@@ -24,3 +25,31 @@ func BenchmarkVerification(b *testing.B) {
 	priv := GenPrivKey()
 	benchmarking.BenchmarkVerification(b, priv)
 }
+
+func BenchmarkBatchVerification(b *testing.B) {
+	for _, n := range []int{1, 8, 64, 1024} {
+		n := n
+		b.Run(fmt.Sprintf("sig-count-%d", n), func(b *testing.B) {
+			keys := make([]crypto.PubKey, n)
+			msgs := make([][]byte, n)
+			sigs := make([][]byte, n)
+			for i := 0; i < n; i++ {
+				priv := GenPrivKey()
+				keys[i] = priv.PubKey()
+				msgs[i] = crypto.CRandBytes(64)
+				sigs[i], _ = priv.Sign(msgs[i])
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				bv := NewBatchVerifier()
+				for j := 0; j < n; j++ {
+					bv.Add(keys[j], msgs[j], sigs[j]) // nolint:errcheck
+				}
+				if ok, _ := bv.Verify(); !ok {
+					b.Fatal("signature set failed batch verification")
+				}
+			}
+		})
+	}
+}
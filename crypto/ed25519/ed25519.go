@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/hdevalence/ed25519consensus"
 	amino "github.com/tendermint/go-amino"
 	"golang.org/x/crypto/ed25519"
 
@@ -148,12 +149,15 @@ func (pubKey PubKeyEd25519) Bytes() []byte {
 	return bz
 }
 
+// VerifyBytes verifies sig following the ZIP 215 validation rules, which are
+// the ones of batch verification (see BatchVerifier), so that a signature is
+// valid or not whatever the way it's verified.
 func (pubKey PubKeyEd25519) VerifyBytes(msg []byte, sig []byte) bool {
 	// make sure we use the same algorithm to sign
 	if len(sig) != SignatureSize {
 		return false
 	}
-	return ed25519.Verify(pubKey[:], msg, sig)
+	return ed25519consensus.Verify(pubKey[:], msg, sig)
 }
 
 func (pubKey PubKeyEd25519) String() string {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
 )
 
 func TestSignAndValidateEd25519(t *testing.T) {
@@ -27,3 +28,34 @@ func TestSignAndValidateEd25519(t *testing.T) {
 
 	assert.False(t, pubKey.VerifyBytes(msg, sig))
 }
+
+func TestBatchVerifier(t *testing.T) {
+	bv := ed25519.NewBatchVerifier()
+	ok, valid := bv.Verify()
+	assert.False(t, ok)
+	assert.Empty(t, valid)
+
+	sigs := make([][]byte, 5)
+	for i := range sigs {
+		privKey := ed25519.GenPrivKey()
+		msg := crypto.CRandBytes(128)
+		sig, err := privKey.Sign(msg)
+		require.NoError(t, err)
+		require.NoError(t, bv.Add(privKey.PubKey(), msg, sig))
+		sigs[i] = sig
+	}
+	ok, valid = bv.Verify()
+	assert.True(t, ok)
+	assert.Equal(t, []bool{true, true, true, true, true}, valid)
+
+	// Mutate a signature, the invalid one is found.
+	sigs[3][7] ^= byte(0x01)
+	ok, valid = bv.Verify()
+	assert.False(t, ok)
+	assert.Equal(t, []bool{true, true, true, false, true}, valid)
+
+	// Only Ed25519 signatures are supported.
+	privKey := ed25519.GenPrivKey()
+	assert.Error(t, bv.Add(privKey.PubKey(), []byte("msg"), []byte("sig")))
+	assert.Error(t, bv.Add(secp256k1.GenPrivKey().PubKey(), []byte("msg"), make([]byte, ed25519.SignatureSize)))
+}
@@ -5,19 +5,16 @@ import (
 
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/crypto"
-	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/crypto/multisig"
-	"github.com/tendermint/tendermint/crypto/secp256k1"
-	"github.com/tendermint/tendermint/crypto/sr25519"
+	"github.com/tendermint/tendermint/crypto/registry"
 )
 
 var cdc = amino.NewCodec()
 
 // nameTable is used to map public key concrete types back
-// to their registered amino names. This should eventually be handled
-// by amino. Example usage:
-// nameTable[reflect.TypeOf(ed25519.PubKeyEd25519{})] = ed25519.PubKeyAminoName
-var nameTable = make(map[reflect.Type]string, 3)
+// to their registered amino names, for the keys which aren't in the registry.
+// This should eventually be handled by amino.
+var nameTable = make(map[reflect.Type]string, 1)
 
 func init() {
 	// NOTE: It's important that there be no conflicts here,
@@ -30,9 +27,6 @@ func init() {
 
 	// TODO: Have amino provide a way to go from concrete struct to route directly.
 	// Its currently a private API
-	nameTable[reflect.TypeOf(ed25519.PubKeyEd25519{})] = ed25519.PubKeyAminoName
-	nameTable[reflect.TypeOf(sr25519.PubKeySr25519{})] = sr25519.PubKeyAminoName
-	nameTable[reflect.TypeOf(secp256k1.PubKeySecp256k1{})] = secp256k1.PubKeyAminoName
 	nameTable[reflect.TypeOf(multisig.PubKeyMultisigThreshold{})] = multisig.PubKeyMultisigThresholdAminoRoute
 }
 
@@ -40,33 +34,27 @@ func init() {
 // cdc is currently passed in, as eventually this will not be using
 // a package level codec.
 func PubkeyAminoName(cdc *amino.Codec, key crypto.PubKey) (string, bool) {
+	if kt, ok := registry.ByPubKey(key); ok {
+		return kt.PubKeyAminoName, true
+	}
 	route, found := nameTable[reflect.TypeOf(key)]
 	return route, found
 }
 
-// RegisterAmino registers all crypto related types in the given (amino) codec.
+// RegisterAmino registers all crypto related types in the given (amino) codec,
+// including the key types added to the registry later.
 func RegisterAmino(cdc *amino.Codec) {
-	// These are all written here instead of
 	cdc.RegisterInterface((*crypto.PubKey)(nil), nil)
-	cdc.RegisterConcrete(ed25519.PubKeyEd25519{},
-		ed25519.PubKeyAminoName, nil)
-	cdc.RegisterConcrete(sr25519.PubKeySr25519{},
-		sr25519.PubKeyAminoName, nil)
-	cdc.RegisterConcrete(secp256k1.PubKeySecp256k1{},
-		secp256k1.PubKeyAminoName, nil)
+	cdc.RegisterInterface((*crypto.PrivKey)(nil), nil)
+	registry.RegisterAmino(cdc)
 	cdc.RegisterConcrete(multisig.PubKeyMultisigThreshold{},
 		multisig.PubKeyMultisigThresholdAminoRoute, nil)
-
-	cdc.RegisterInterface((*crypto.PrivKey)(nil), nil)
-	cdc.RegisterConcrete(ed25519.PrivKeyEd25519{},
-		ed25519.PrivKeyAminoName, nil)
-	cdc.RegisterConcrete(sr25519.PrivKeySr25519{},
-		sr25519.PrivKeyAminoName, nil)
-	cdc.RegisterConcrete(secp256k1.PrivKeySecp256k1{},
-		secp256k1.PrivKeyAminoName, nil)
 }
 
 // RegisterKeyType registers an external key type to allow decoding it from bytes
+//
+// Deprecated: register the key type with registry.Register, so that all the
+// codecs can decode it, and validators can use it.
 func RegisterKeyType(o interface{}, name string) {
 	cdc.RegisterConcrete(o, name, nil)
 	nameTable[reflect.TypeOf(o)] = name
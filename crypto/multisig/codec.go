@@ -3,13 +3,9 @@ package multisig
 import (
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/crypto"
-	"github.com/tendermint/tendermint/crypto/ed25519"
-	"github.com/tendermint/tendermint/crypto/secp256k1"
-	"github.com/tendermint/tendermint/crypto/sr25519"
+	"github.com/tendermint/tendermint/crypto/registry"
 )
 
-// TODO: Figure out API for others to either add their own pubkey types, or
-// to make verify / marshal accept a cdc.
 const (
 	PubKeyMultisigThresholdAminoRoute = "tendermint/PubKeyMultisigThreshold"
 )
@@ -20,10 +16,7 @@ func init() {
 	cdc.RegisterInterface((*crypto.PubKey)(nil), nil)
 	cdc.RegisterConcrete(PubKeyMultisigThreshold{},
 		PubKeyMultisigThresholdAminoRoute, nil)
-	cdc.RegisterConcrete(ed25519.PubKeyEd25519{},
-		ed25519.PubKeyAminoName, nil)
-	cdc.RegisterConcrete(sr25519.PubKeySr25519{},
-		sr25519.PubKeyAminoName, nil)
-	cdc.RegisterConcrete(secp256k1.PubKeySecp256k1{},
-		secp256k1.PubKeyAminoName, nil)
+	// the private keys aren't needed, but it's simpler to register them too
+	cdc.RegisterInterface((*crypto.PrivKey)(nil), nil)
+	registry.RegisterAmino(cdc)
 }
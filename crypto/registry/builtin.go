@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/crypto/sr25519"
+)
+
+// Names of the built-in key types.
+const (
+	KeyTypeEd25519   = "ed25519"
+	KeyTypeSr25519   = "sr25519"
+	KeyTypeSecp256k1 = "secp256k1"
+)
+
+func init() {
+	Register(KeyType{
+		Name:             KeyTypeEd25519,
+		PubKey:           ed25519.PubKeyEd25519{},
+		PubKeyAminoName:  ed25519.PubKeyAminoName,
+		PrivKey:          ed25519.PrivKeyEd25519{},
+		PrivKeyAminoName: ed25519.PrivKeyAminoName,
+		GenPrivKey:       func() crypto.PrivKey { return ed25519.GenPrivKey() },
+		NewBatchVerifier: func() crypto.BatchVerifier { return ed25519.NewBatchVerifier() },
+	})
+	Register(KeyType{
+		Name:             KeyTypeSr25519,
+		PubKey:           sr25519.PubKeySr25519{},
+		PubKeyAminoName:  sr25519.PubKeyAminoName,
+		PrivKey:          sr25519.PrivKeySr25519{},
+		PrivKeyAminoName: sr25519.PrivKeyAminoName,
+		GenPrivKey:       func() crypto.PrivKey { return sr25519.GenPrivKey() },
+	})
+	Register(KeyType{
+		Name:             KeyTypeSecp256k1,
+		PubKey:           secp256k1.PubKeySecp256k1{},
+		PubKeyAminoName:  secp256k1.PubKeyAminoName,
+		PrivKey:          secp256k1.PrivKeySecp256k1{},
+		PrivKeyAminoName: secp256k1.PrivKeyAminoName,
+		GenPrivKey:       func() crypto.PrivKey { return secp256k1.GenPrivKey() },
+	})
+}
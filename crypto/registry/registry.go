@@ -0,0 +1,193 @@
+// Package registry is the registry of the types of public keys which can be
+// used by validators.
+//
+// The ed25519, secp256k1 and sr25519 key types are registered by default. A
+// new key type (e.g. BLS or a post-quantum scheme) is added with Register, in
+// the init of its package or at least before the node is created. Its keys are
+// then encoded and decoded by all the amino codecs of Tendermint (see
+// RegisterAmino), converted to and from their ABCI representation, batch
+// verified if supported, and can be allowed for validators by listing its name
+// in ConsensusParams.Validator.PubKeyTypes. The addresses of the keys are
+// derived by their Address method, and their signatures verified by their
+// VerifyBytes method.
+//
+// NOTE: the signatures of the validators can't be bigger than
+// types.MaxSignatureSize.
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	amino "github.com/tendermint/go-amino"
+
+	"github.com/tendermint/tendermint/crypto"
+)
+
+// KeyType describes a type of public keys.
+type KeyType struct {
+	// Name is the name of the type in ABCI (abci.PubKey.Type) and in
+	// ConsensusParams.Validator.PubKeyTypes, e.g. "ed25519".
+	Name string
+
+	// PubKey is a public key of the type, e.g. its zero value, and
+	// PubKeyAminoName the name it's registered with in amino.
+	PubKey          crypto.PubKey
+	PubKeyAminoName string
+
+	// PrivKey is a private key of the type, and PrivKeyAminoName the name it's
+	// registered with in amino. Optional.
+	PrivKey          crypto.PrivKey
+	PrivKeyAminoName string
+
+	// PubKeyFromBytes decodes a public key from its raw bytes, as in
+	// abci.PubKey.Data, and PubKeyBytes returns them. Both are optional if the
+	// public keys are byte arrays, as the raw bytes are then the array.
+	PubKeyFromBytes func(bz []byte) (crypto.PubKey, error)
+	PubKeyBytes     func(pubKey crypto.PubKey) []byte
+
+	// GenPrivKey generates a new private key. Optional.
+	GenPrivKey func() crypto.PrivKey
+
+	// NewBatchVerifier returns a verifier of signatures in batches. Optional.
+	NewBatchVerifier func() crypto.BatchVerifier
+}
+
+var (
+	mtx       sync.RWMutex
+	keyTypes  []KeyType
+	byName    = make(map[string]int)
+	byPubKey  = make(map[reflect.Type]int)
+	byPrivKey = make(map[reflect.Type]int)
+	codecs    []*amino.Codec
+)
+
+// Register adds the key type kt, and registers its keys in all the codecs
+// given to RegisterAmino. It panics if kt is invalid or conflicts with a key
+// type already registered.
+func Register(kt KeyType) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if kt.Name == "" {
+		panic("key type without name")
+	}
+	if _, ok := byName[kt.Name]; ok {
+		panic(fmt.Sprintf("key type %s already registered", kt.Name))
+	}
+	if kt.PubKey == nil || kt.PubKeyAminoName == "" {
+		panic(fmt.Sprintf("key type %s without public key or amino name", kt.Name))
+	}
+	pubKeyType := reflect.TypeOf(kt.PubKey)
+	if _, ok := byPubKey[pubKeyType]; ok {
+		panic(fmt.Sprintf("public key %v of key type %s already registered", pubKeyType, kt.Name))
+	}
+	if kt.PrivKey != nil && kt.PrivKeyAminoName == "" {
+		panic(fmt.Sprintf("key type %s without private key amino name", kt.Name))
+	}
+	if kt.PubKeyFromBytes == nil || kt.PubKeyBytes == nil {
+		if pubKeyType.Kind() != reflect.Array || pubKeyType.Elem().Kind() != reflect.Uint8 {
+			panic(fmt.Sprintf("key type %s without raw bytes encoding", kt.Name))
+		}
+		if kt.PubKeyFromBytes == nil {
+			kt.PubKeyFromBytes = arrayPubKeyFromBytes(pubKeyType)
+		}
+		if kt.PubKeyBytes == nil {
+			kt.PubKeyBytes = arrayPubKeyBytes
+		}
+	}
+
+	for _, cdc := range codecs {
+		registerAmino(cdc, kt)
+	}
+
+	idx := len(keyTypes)
+	keyTypes = append(keyTypes, kt)
+	byName[kt.Name] = idx
+	byPubKey[pubKeyType] = idx
+	if kt.PrivKey != nil {
+		byPrivKey[reflect.TypeOf(kt.PrivKey)] = idx
+	}
+}
+
+// Get returns the key type of the given name.
+func Get(name string) (KeyType, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	idx, ok := byName[name]
+	if !ok {
+		return KeyType{}, false
+	}
+	return keyTypes[idx], true
+}
+
+// ByPubKey returns the key type of pubKey.
+func ByPubKey(pubKey crypto.PubKey) (KeyType, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	idx, ok := byPubKey[reflect.TypeOf(pubKey)]
+	if !ok {
+		return KeyType{}, false
+	}
+	return keyTypes[idx], true
+}
+
+// ByPrivKey returns the key type of privKey.
+func ByPrivKey(privKey crypto.PrivKey) (KeyType, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	idx, ok := byPrivKey[reflect.TypeOf(privKey)]
+	if !ok {
+		return KeyType{}, false
+	}
+	return keyTypes[idx], true
+}
+
+// Names returns the names of the key types, in the order they were
+// registered.
+func Names() []string {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	names := make([]string, len(keyTypes))
+	for i, kt := range keyTypes {
+		names[i] = kt.Name
+	}
+	return names
+}
+
+// RegisterAmino registers the keys of all the key types in cdc, including the
+// ones registered later.
+func RegisterAmino(cdc *amino.Codec) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	for _, kt := range keyTypes {
+		registerAmino(cdc, kt)
+	}
+	codecs = append(codecs, cdc)
+}
+
+func registerAmino(cdc *amino.Codec, kt KeyType) {
+	cdc.RegisterConcrete(kt.PubKey, kt.PubKeyAminoName, nil)
+	if kt.PrivKey != nil {
+		cdc.RegisterConcrete(kt.PrivKey, kt.PrivKeyAminoName, nil)
+	}
+}
+
+func arrayPubKeyFromBytes(t reflect.Type) func([]byte) (crypto.PubKey, error) {
+	return func(bz []byte) (crypto.PubKey, error) {
+		if len(bz) != t.Len() {
+			return nil, fmt.Errorf("invalid size for %v. Got %d, expected %d", t.Name(), len(bz), t.Len())
+		}
+		pubKey := reflect.New(t).Elem()
+		reflect.Copy(pubKey, reflect.ValueOf(bz))
+		return pubKey.Interface().(crypto.PubKey), nil
+	}
+}
+
+func arrayPubKeyBytes(pubKey crypto.PubKey) []byte {
+	v := reflect.ValueOf(pubKey)
+	bz := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(bz), v)
+	return bz
+}
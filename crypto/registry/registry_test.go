@@ -0,0 +1,113 @@
+package registry_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	cryptoamino "github.com/tendermint/tendermint/crypto/encoding/amino"
+	"github.com/tendermint/tendermint/crypto/registry"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/types"
+)
+
+// testPubKey is a key type registered by the test, after the codecs were
+// created: ed25519 under another name.
+type testPubKey [ed25519.PubKeyEd25519Size]byte
+
+func (pubKey testPubKey) Address() crypto.Address {
+	return crypto.Address(tmhash.SumTruncated(append([]byte("test"), pubKey[:]...)))
+}
+func (pubKey testPubKey) Bytes() []byte { return types.GetCodec().MustMarshalBinaryBare(pubKey) }
+func (pubKey testPubKey) VerifyBytes(msg []byte, sig []byte) bool {
+	return ed25519.PubKeyEd25519(pubKey).VerifyBytes(msg, sig)
+}
+func (pubKey testPubKey) Equals(other crypto.PubKey) bool {
+	return bytes.Equal(pubKey.Bytes(), other.Bytes())
+}
+
+func init() {
+	registry.Register(registry.KeyType{
+		Name:            "test",
+		PubKey:          testPubKey{},
+		PubKeyAminoName: "tendermint/PubKeyTest",
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{"ed25519", "sr25519", "secp256k1", "test"}, registry.Names())
+
+	kt, ok := registry.Get("ed25519")
+	require.True(t, ok)
+	assert.Equal(t, ed25519.PubKeyAminoName, kt.PubKeyAminoName)
+	privKey := kt.GenPrivKey()
+	kt, ok = registry.ByPrivKey(privKey)
+	assert.True(t, ok)
+	assert.Equal(t, "ed25519", kt.Name)
+	_, ok = registry.Get("bls")
+	assert.False(t, ok)
+
+	// a key type can't be registered twice
+	assert.Panics(t, func() {
+		registry.Register(registry.KeyType{Name: "test", PubKey: testPubKey{}, PubKeyAminoName: "test"})
+	})
+	assert.Panics(t, func() {
+		registry.Register(registry.KeyType{Name: "test2", PubKey: testPubKey{}, PubKeyAminoName: "test"})
+	})
+}
+
+func TestRegisteredKeyType(t *testing.T) {
+	priv := ed25519.GenPrivKey()
+	pubKey := testPubKey(priv.PubKey().(ed25519.PubKeyEd25519))
+
+	// the codecs created before the registration can encode and decode it
+	bz, err := types.GetCodec().MarshalBinaryBare(pubKey)
+	require.NoError(t, err)
+	var decoded crypto.PubKey
+	require.NoError(t, types.GetCodec().UnmarshalBinaryBare(bz, &decoded))
+	assert.Equal(t, pubKey, decoded)
+	name, ok := cryptoamino.PubkeyAminoName(nil, pubKey)
+	assert.True(t, ok)
+	assert.Equal(t, "tendermint/PubKeyTest", name)
+
+	// it's converted to and from ABCI
+	abciPubKey := types.TM2PB.PubKey(pubKey)
+	assert.Equal(t, abci.PubKey{Type: "test", Data: pubKey[:]}, abciPubKey)
+	decoded, err = types.PB2TM.PubKey(abciPubKey)
+	require.NoError(t, err)
+	assert.Equal(t, pubKey, decoded)
+	_, err = types.PB2TM.PubKey(abci.PubKey{Type: "test", Data: pubKey[1:]})
+	assert.Error(t, err)
+
+	// validators can use it
+	params := types.DefaultConsensusParams()
+	params.Validator.PubKeyTypes = []string{"ed25519", "test"}
+	require.NoError(t, params.Validate())
+	params.Validator.PubKeyTypes = []string{"bls"}
+	assert.Error(t, params.Validate())
+
+	val := types.NewValidator(pubKey, 10)
+	valSet := types.NewValidatorSet([]*types.Validator{val})
+	vote := &types.Vote{
+		ValidatorAddress: val.Address,
+		Height:           1,
+		Type:             types.PrecommitType,
+		BlockID: types.BlockID{
+			Hash:        tmhash.Sum([]byte("block")),
+			PartsHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))},
+		},
+	}
+	vote.Signature, err = priv.Sign(vote.SignBytes("test-chain"))
+	require.NoError(t, err)
+	voteSet := types.NewVoteSet("test-chain", 1, 0, types.PrecommitType, valSet)
+	added, err := voteSet.AddVote(vote)
+	require.NoError(t, err)
+	assert.True(t, added)
+	commit := voteSet.MakeCommit()
+	assert.NoError(t, valSet.VerifyCommit("test-chain", vote.BlockID, 1, commit))
+}
@@ -0,0 +1,268 @@
+// Package threshold implements threshold Ed25519 signatures: the private key
+// of a validator is split into shares, any threshold of which cooperate to
+// produce a standard Ed25519 signature, verified with the public key of the
+// validator, without the private key ever being reassembled.
+//
+// The key is split by a trusted dealer with Shamir's secret sharing, and the
+// signatures are produced with the two-round FROST protocol
+// (https://eprint.iacr.org/2020/852):
+//
+//  1. each participant generates single-use nonces with Commit, and sends
+//     their Commitment to the coordinator;
+//  2. the coordinator sends the commitments of the participants, and the
+//     message, to each participant, which returns its share of the signature
+//     (SignShare);
+//  3. the coordinator sums the shares into the signature (Aggregate).
+//
+// NOTE: Nonces must never be used twice, or the key share is leaked.
+package threshold
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"filippo.io/edwards25519"
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+// KeyShare is the share of an Ed25519 private key held by a participant.
+type KeyShare struct {
+	ID        int                   `json:"id"` // 1 to Total
+	Threshold int                   `json:"threshold"`
+	Total     int                   `json:"total"`
+	PubKey    ed25519.PubKeyEd25519 `json:"pub_key"` // of the split key
+	Secret    []byte                `json:"secret"`  // share of the secret scalar
+}
+
+// Commitment is the public part of the nonces of a participant.
+type Commitment struct {
+	ID int    `json:"id"`
+	D  []byte `json:"d"`
+	E  []byte `json:"e"`
+}
+
+// Nonces are the single-use secret nonces of a participant, for one signature.
+type Nonces struct {
+	d, e *edwards25519.Scalar
+	used bool
+}
+
+// SplitPrivKey splits privKey into total shares, threshold of which are
+// needed to sign.
+func SplitPrivKey(privKey ed25519.PrivKeyEd25519, threshold, total int) ([]KeyShare, error) {
+	if threshold < 1 || threshold > total {
+		return nil, fmt.Errorf("threshold must be between 1 and the number of shares %d, got %d", total, threshold)
+	}
+	h := sha512.Sum512(privKey[:32])
+	secret := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+
+	// f(x) = secret + a1*x + ... + a(t-1)*x^(t-1), the share i is f(i)
+	coeffs := []*edwards25519.Scalar{secret}
+	for i := 1; i < threshold; i++ {
+		coeffs = append(coeffs, randScalar())
+	}
+	pubKey := privKey.PubKey().(ed25519.PubKeyEd25519)
+	shares := make([]KeyShare, total)
+	for i := range shares {
+		x := idScalar(i + 1)
+		y := edwards25519.NewScalar()
+		for j := len(coeffs) - 1; j >= 0; j-- {
+			y.MultiplyAdd(y, x, coeffs[j])
+		}
+		shares[i] = KeyShare{ID: i + 1, Threshold: threshold, Total: total, PubKey: pubKey, Secret: y.Bytes()}
+	}
+	return shares, nil
+}
+
+// ValidateBasic performs basic validation.
+func (ks KeyShare) ValidateBasic() error {
+	if ks.Threshold < 1 || ks.Threshold > ks.Total {
+		return fmt.Errorf("threshold must be between 1 and the number of shares %d, got %d", ks.Total, ks.Threshold)
+	}
+	if ks.ID < 1 || ks.ID > ks.Total {
+		return fmt.Errorf("id must be between 1 and the number of shares %d, got %d", ks.Total, ks.ID)
+	}
+	if _, err := edwards25519.NewScalar().SetCanonicalBytes(ks.Secret); err != nil {
+		return errors.Wrap(err, "invalid secret")
+	}
+	return nil
+}
+
+// Commit generates the nonces of the participant for a new signature, and
+// their commitment.
+func (ks KeyShare) Commit() (*Nonces, Commitment) {
+	nonces := &Nonces{d: randScalar(), e: randScalar()}
+	return nonces, Commitment{
+		ID: ks.ID,
+		D:  edwards25519.NewIdentityPoint().ScalarBaseMult(nonces.d).Bytes(),
+		E:  edwards25519.NewIdentityPoint().ScalarBaseMult(nonces.e).Bytes(),
+	}
+}
+
+// SignShare returns the share of the participant of the signature of msg by
+// the participants of commitments, which must include the commitment of
+// nonces. The nonces can't be used again.
+func (ks KeyShare) SignShare(nonces *Nonces, msg []byte, commitments []Commitment) ([]byte, error) {
+	if nonces.used {
+		return nil, errors.New("nonces already used")
+	}
+	secret, err := edwards25519.NewScalar().SetCanonicalBytes(ks.Secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secret")
+	}
+	if len(commitments) < ks.Threshold {
+		return nil, fmt.Errorf("%d commitments, need %d", len(commitments), ks.Threshold)
+	}
+	s, err := newSession(ks.PubKey, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+	i, ok := s.index[ks.ID]
+	if !ok {
+		return nil, errors.New("own commitment not found")
+	}
+	c := s.commitments[i]
+	if !bytes.Equal(c.D, edwards25519.NewIdentityPoint().ScalarBaseMult(nonces.d).Bytes()) ||
+		!bytes.Equal(c.E, edwards25519.NewIdentityPoint().ScalarBaseMult(nonces.e).Bytes()) {
+		return nil, errors.New("own commitment doesn't match the nonces")
+	}
+	nonces.used = true
+
+	// z = d + e*rho + lambda*secret*challenge
+	lambda := edwards25519.NewScalar().Multiply(s.lagrange(i), secret)
+	z := edwards25519.NewScalar().MultiplyAdd(lambda, s.challenge, nonces.d)
+	z = edwards25519.NewScalar().MultiplyAdd(nonces.e, s.rhos[i], z)
+	return z.Bytes(), nil
+}
+
+// Aggregate sums the shares of the signature of msg, in the order of
+// commitments, into the Ed25519 signature.
+func Aggregate(pubKey ed25519.PubKeyEd25519, msg []byte, commitments []Commitment, shares [][]byte) ([]byte, error) {
+	if len(shares) != len(commitments) {
+		return nil, fmt.Errorf("%d shares for %d commitments", len(shares), len(commitments))
+	}
+	s, err := newSession(pubKey, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+	z := edwards25519.NewScalar()
+	for i, share := range shares {
+		zi, err := edwards25519.NewScalar().SetCanonicalBytes(share)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid share of %d", commitments[i].ID)
+		}
+		z.Add(z, zi)
+	}
+	sig := append(s.r.Bytes(), z.Bytes()...)
+	if !pubKey.VerifyBytes(msg, sig) {
+		return nil, errors.New("invalid signature")
+	}
+	return sig, nil
+}
+
+// session holds the values derived from the commitments of a signature,
+// sorted by participant.
+type session struct {
+	commitments []Commitment
+	index       map[int]int // by id
+	rhos        []*edwards25519.Scalar
+	r           *edwards25519.Point
+	challenge   *edwards25519.Scalar
+}
+
+func newSession(pubKey ed25519.PubKeyEd25519, msg []byte, commitments []Commitment) (*session, error) {
+	s := &session{
+		commitments: append([]Commitment(nil), commitments...),
+		index:       make(map[int]int, len(commitments)),
+	}
+	sort.Slice(s.commitments, func(i, j int) bool { return s.commitments[i].ID < s.commitments[j].ID })
+
+	// the binding factors commit to the message and all the commitments
+	encoded := make([]byte, 0, len(commitments)*72)
+	for i, c := range s.commitments {
+		if c.ID < 1 {
+			return nil, fmt.Errorf("invalid id %d", c.ID)
+		}
+		if _, ok := s.index[c.ID]; ok {
+			return nil, fmt.Errorf("duplicate commitment of %d", c.ID)
+		}
+		s.index[c.ID] = i
+		encoded = append(encoded, idBytes(c.ID)...)
+		encoded = append(encoded, c.D...)
+		encoded = append(encoded, c.E...)
+	}
+
+	s.r = edwards25519.NewIdentityPoint()
+	for _, c := range s.commitments {
+		d, err := new(edwards25519.Point).SetBytes(c.D)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid commitment of %d", c.ID)
+		}
+		e, err := new(edwards25519.Point).SetBytes(c.E)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid commitment of %d", c.ID)
+		}
+		rho := hashToScalar([]byte("FROST-Ed25519-rho"), idBytes(c.ID), msg, encoded)
+		s.rhos = append(s.rhos, rho)
+		s.r.Add(s.r, d)
+		s.r.Add(s.r, edwards25519.NewIdentityPoint().ScalarMult(rho, e))
+	}
+
+	// as in Ed25519: H(R || A || M)
+	s.challenge = hashToScalar(s.r.Bytes(), pubKey[:], msg)
+	return s, nil
+}
+
+// lagrange returns the Lagrange coefficient at 0 of the participant i, among
+// the participants of the session.
+func (s *session) lagrange(i int) *edwards25519.Scalar {
+	num, den := idScalar(1), idScalar(1)
+	xi := idScalar(s.commitments[i].ID)
+	for j, c := range s.commitments {
+		if j == i {
+			continue
+		}
+		xj := idScalar(c.ID)
+		num.Multiply(num, xj)
+		den.Multiply(den, edwards25519.NewScalar().Subtract(xj, xi))
+	}
+	return num.Multiply(num, edwards25519.NewScalar().Invert(den))
+}
+
+func hashToScalar(parts ...[]byte) *edwards25519.Scalar {
+	h := sha512.New()
+	for _, p := range parts {
+		h.Write(p) // nolint: errcheck
+	}
+	return edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+}
+
+func randScalar() *edwards25519.Scalar {
+	var bz [64]byte
+	if _, err := rand.Read(bz[:]); err != nil {
+		panic(err)
+	}
+	return edwards25519.NewScalar().SetUniformBytes(bz[:])
+}
+
+func idBytes(id int) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, uint64(id))
+	return bz
+}
+
+func idScalar(id int) *edwards25519.Scalar {
+	bz := make([]byte, 32)
+	binary.LittleEndian.PutUint64(bz, uint64(id))
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(bz)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
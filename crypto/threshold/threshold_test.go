@@ -0,0 +1,80 @@
+package threshold
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+// sign signs msg with the shares of ids.
+func sign(t *testing.T, shares []KeyShare, ids []int, msg []byte) ([]byte, error) {
+	nonces := make([]*Nonces, len(ids))
+	commitments := make([]Commitment, len(ids))
+	for i, id := range ids {
+		nonces[i], commitments[i] = shares[id-1].Commit()
+	}
+	sigShares := make([][]byte, len(ids))
+	for i, id := range ids {
+		var err error
+		sigShares[i], err = shares[id-1].SignShare(nonces[i], msg, commitments)
+		require.NoError(t, err)
+	}
+	return Aggregate(shares[0].PubKey, msg, commitments, sigShares)
+}
+
+func TestThresholdSignature(t *testing.T) {
+	privKey := ed25519.GenPrivKey()
+	shares, err := SplitPrivKey(privKey, 3, 5)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+	for _, share := range shares {
+		require.NoError(t, share.ValidateBasic())
+		assert.Equal(t, privKey.PubKey(), share.PubKey)
+	}
+
+	msg := []byte("vote")
+	for _, ids := range [][]int{{1, 2, 3}, {5, 2, 4}, {1, 2, 3, 4, 5}} {
+		sig, err := sign(t, shares, ids, msg)
+		require.NoError(t, err, "%v", ids)
+		assert.True(t, privKey.PubKey().VerifyBytes(msg, sig), "%v", ids)
+	}
+
+	// less than the threshold
+	nonces1, c1 := shares[0].Commit()
+	_, c2 := shares[1].Commit()
+	_, err = shares[0].SignShare(nonces1, msg, []Commitment{c1, c2})
+	assert.Error(t, err)
+
+	// the shares of a subset smaller than the threshold don't make a signature
+	shares2, err := SplitPrivKey(privKey, 3, 5)
+	require.NoError(t, err)
+	_, err = sign(t, append(shares[:2:2], shares2[2:]...), []int{1, 2, 3}, msg)
+	assert.Error(t, err)
+
+	_, err = SplitPrivKey(privKey, 6, 5)
+	assert.Error(t, err)
+}
+
+func TestNoncesSingleUse(t *testing.T) {
+	shares, err := SplitPrivKey(ed25519.GenPrivKey(), 2, 2)
+	require.NoError(t, err)
+	nonces1, c1 := shares[0].Commit()
+	_, c2 := shares[1].Commit()
+	_, err = shares[0].SignShare(nonces1, []byte("a"), []Commitment{c1, c2})
+	require.NoError(t, err)
+	_, err = shares[0].SignShare(nonces1, []byte("b"), []Commitment{c1, c2})
+	assert.Error(t, err)
+
+	// the commitment must be the nonces'
+	nonces1, _ = shares[0].Commit()
+	_, err = shares[0].SignShare(nonces1, []byte("a"), []Commitment{c1, c2})
+	assert.Error(t, err)
+
+	// duplicate participants
+	nonces1, c1 = shares[0].Commit()
+	_, err = shares[0].SignShare(nonces1, []byte("a"), []Commitment{c1, c1})
+	assert.Error(t, err)
+}
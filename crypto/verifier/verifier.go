@@ -0,0 +1,212 @@
+// Package verifier provides a bounded pool of workers verifying signatures in
+// parallel.
+//
+// A single process-wide pool is shared by commit verification (consensus block
+// validation, fast sync, light clients) and evidence verification, so the
+// number of goroutines doing signature checks is bounded by the number of
+// CPUs no matter how many reactors are verifying at the same time.
+//
+// The signatures are split into chunks of ChunkSize signatures, and those of the
+// chunks whose keys support it (see crypto.BatchVerifier) are batch verified:
+// each worker verifies a chunk at once, and falls back to verifying its
+// signatures one by one only if the chunk is not valid.
+package verifier
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/registry"
+)
+
+// ChunkSize is the number of signatures verified at once. The size is fixed,
+// so that the signatures are split into the same batches whatever the number
+// of workers. Verify checks the signatures of at most one chunk inline, since
+// the cost of scheduling would outweigh the gain.
+const ChunkSize = 32
+
+// Item is a single signature to verify.
+type Item struct {
+	PubKey crypto.PubKey
+	Msg    []byte
+	Sig    []byte
+}
+
+type job struct {
+	items   []Item
+	results []bool
+	wg      *sync.WaitGroup
+}
+
+// Pool verifies signatures on a fixed number of worker goroutines. The job
+// queue is bounded; once it is full, callers block until a worker frees up.
+type Pool struct {
+	workers int
+	jobs    chan job
+
+	startOnce sync.Once
+
+	mtx     sync.RWMutex // read locked while jobs are queued
+	stopped bool
+	quit    chan struct{}
+}
+
+// NewPool returns a pool with the given number of workers. If workers is not
+// positive, runtime.NumCPU() is used. Workers are started lazily on the first
+// call to Verify.
+func NewPool(workers int) *Pool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &Pool{
+		workers: workers,
+		jobs:    make(chan job, workers*4),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Workers returns the number of workers in the pool.
+func (p *Pool) Workers() int {
+	return p.workers
+}
+
+func (p *Pool) start() {
+	p.startOnce.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			go p.worker()
+		}
+	})
+}
+
+func (p *Pool) worker() {
+	for {
+		select {
+		case j := <-p.jobs:
+			verify(j.items, j.results)
+			j.wg.Done()
+		case <-p.quit:
+			// verify the jobs queued before Stop, whose callers are waiting
+			for {
+				select {
+				case j := <-p.jobs:
+					verify(j.items, j.results)
+					j.wg.Done()
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stop terminates the workers, once they have verified the queued signatures.
+// Verify can still be called after Stop: it then verifies the signatures on
+// the calling goroutine.
+func (p *Pool) Stop() {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if !p.stopped {
+		p.stopped = true
+		close(p.quit)
+	}
+}
+
+// Verify checks all given signatures and returns, for each item, whether its
+// signature is valid. It blocks until all items have been verified.
+func (p *Pool) Verify(items []Item) []bool {
+	results := make([]bool, len(items))
+	if len(items) <= ChunkSize || p.workers == 1 {
+		verifyChunks(items, results)
+		return results
+	}
+
+	p.mtx.RLock()
+	if p.stopped {
+		p.mtx.RUnlock()
+		verifyChunks(items, results)
+		return results
+	}
+	p.start()
+	var wg sync.WaitGroup
+	for i := 0; i < len(items); i += ChunkSize {
+		end := i + ChunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+		wg.Add(1)
+		p.jobs <- job{items: items[i:end], results: results[i:end], wg: &wg}
+	}
+	p.mtx.RUnlock()
+	wg.Wait()
+	return results
+}
+
+// verifyChunks verifies items chunk by chunk, as the workers do.
+func verifyChunks(items []Item, results []bool) {
+	for i := 0; i < len(items); i += ChunkSize {
+		end := i + ChunkSize
+		if end > len(items) {
+			end = len(items)
+		}
+		verify(items[i:end], results[i:end])
+	}
+}
+
+// verify checks the signatures of items, and sets results accordingly. The
+// signatures are batch verified if all keys support it.
+func verify(items []Item, results []bool) {
+	if len(items) >= 2 {
+		if bv, ok := batchVerifier(items); ok {
+			_, valid := bv.Verify()
+			copy(results, valid)
+			return
+		}
+	}
+	for i := range items {
+		results[i] = items[i].PubKey.VerifyBytes(items[i].Msg, items[i].Sig)
+	}
+}
+
+// batchVerifier returns a batch verifier filled with the items, or false if
+// some of their keys (or signatures) can't be batch verified.
+func batchVerifier(items []Item) (crypto.BatchVerifier, bool) {
+	kt, ok := registry.ByPubKey(items[0].PubKey)
+	if !ok || kt.NewBatchVerifier == nil {
+		return nil, false
+	}
+	bv := kt.NewBatchVerifier()
+	for _, item := range items {
+		if err := bv.Add(item.PubKey, item.Msg, item.Sig); err != nil {
+			return nil, false
+		}
+	}
+	return bv, true
+}
+
+// VerifyAll returns true if all signatures are valid.
+func (p *Pool) VerifyAll(items []Item) bool {
+	for _, ok := range p.Verify(items) {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+var defaultPool = NewPool(0)
+
+// Default returns the shared, process-wide pool.
+func Default() *Pool {
+	return defaultPool
+}
+
+// Verify verifies items using the shared pool. See Pool.Verify.
+func Verify(items []Item) []bool {
+	return defaultPool.Verify(items)
+}
+
+// VerifyAll verifies items using the shared pool. See Pool.VerifyAll.
+func VerifyAll(items []Item) bool {
+	return defaultPool.VerifyAll(items)
+}
@@ -0,0 +1,199 @@
+package verifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func makeItems(n int) []Item {
+	items := make([]Item, n)
+	for i := range items {
+		priv := ed25519.GenPrivKey()
+		msg := crypto.CRandBytes(64)
+		sig, err := priv.Sign(msg)
+		if err != nil {
+			panic(err)
+		}
+		items[i] = Item{PubKey: priv.PubKey(), Msg: msg, Sig: sig}
+	}
+	return items
+}
+
+func TestPoolVerify(t *testing.T) {
+	for _, workers := range []int{1, 2, 8} {
+		pool := NewPool(workers)
+		assert.Equal(t, workers, pool.Workers())
+
+		for _, n := range []int{0, 1, 2, ChunkSize, ChunkSize + 1, 3*ChunkSize + 5} {
+			items := makeItems(n)
+			assert.True(t, pool.VerifyAll(items))
+
+			if n == 0 {
+				continue
+			}
+			// corrupt the last signature
+			items[n-1].Sig[0] ^= 0x01
+			results := pool.Verify(items)
+			for i, ok := range results {
+				assert.Equal(t, i != n-1, ok, "workers=%d n=%d item=%d", workers, n, i)
+			}
+			assert.False(t, pool.VerifyAll(items))
+		}
+		pool.Stop()
+	}
+}
+
+// edgeCaseItems returns signatures that are valid, or not, only following the
+// ZIP 215 rules: small order keys and R, and non canonical point encodings.
+func edgeCaseItems() (items []Item, valid []bool) {
+	var (
+		identity    = [32]byte{0x01}
+		nonCanonID  [32]byte // the identity, with y = p + 1
+		orderTwoKey [32]byte // (0, -1)
+	)
+	for i := 1; i < 31; i++ {
+		nonCanonID[i] = 0xff
+		orderTwoKey[i] = 0xff
+	}
+	nonCanonID[0], nonCanonID[31] = 0xee, 0x7f
+	orderTwoKey[0], orderTwoKey[31] = 0xec, 0x7f
+
+	sig := func(r [32]byte, s byte) []byte {
+		return append(r[:], append([]byte{s}, make([]byte, 31)...)...)
+	}
+	nonCanonS := sig(identity, 0xff) // S >= l
+	for i := 1; i < 32; i++ {
+		nonCanonS[32+i] = 0xff
+	}
+	add := func(pubKey [32]byte, sig []byte, ok bool) {
+		for i := byte(0); i < 4; i++ {
+			items = append(items, Item{PubKey: ed25519.PubKeyEd25519(pubKey), Msg: []byte{i}, Sig: sig})
+			valid = append(valid, ok)
+		}
+	}
+	add(orderTwoKey, sig(identity, 0), true)
+	add(orderTwoKey, sig(nonCanonID, 0), true)
+	add(nonCanonID, sig(identity, 0), true)
+	add(nonCanonID, sig(nonCanonID, 0), true)
+	add(orderTwoKey, nonCanonS, false)
+	return items, valid
+}
+
+func TestPoolVerifyEdgeCases(t *testing.T) {
+	edgeItems, valid := edgeCaseItems()
+	pools := []*Pool{NewPool(1), NewPool(2), NewPool(8)}
+	defer func() {
+		for _, pool := range pools {
+			pool.Stop()
+		}
+	}()
+
+	for k, edge := range edgeItems {
+		// alone
+		require.Equal(t, valid[k], edge.PubKey.VerifyBytes(edge.Msg, edge.Sig), "item=%d", k)
+		// in a batch of valid signatures
+		bv := ed25519.NewBatchVerifier()
+		batch := append(makeItems(2), edge)
+		for _, item := range batch {
+			require.NoError(t, bv.Add(item.PubKey, item.Msg, item.Sig))
+		}
+		ok, results := bv.Verify()
+		assert.Equal(t, valid[k], ok, "item=%d", k)
+		assert.Equal(t, []bool{true, true, valid[k]}, results, "item=%d", k)
+	}
+
+	// at the edges of the chunks, the other items being valid
+	items := makeItems(2*ChunkSize + 1)
+	for _, pool := range pools {
+		for _, pos := range []int{0, 1, ChunkSize - 1, ChunkSize, ChunkSize + 1, 2 * ChunkSize} {
+			for k, edge := range edgeItems {
+				orig := items[pos]
+				items[pos] = edge
+				for i, ok := range pool.Verify(items) {
+					require.Equal(t, i != pos || valid[k], ok,
+						"workers=%d pos=%d item=%d result=%d", pool.Workers(), pos, k, i)
+				}
+				items[pos] = orig
+			}
+		}
+	}
+}
+
+func TestPoolVerifyAfterStop(t *testing.T) {
+	pool := NewPool(2)
+	items := makeItems(3*ChunkSize + 5)
+	require.True(t, pool.VerifyAll(items))
+	pool.Stop()
+	pool.Stop()
+
+	// verified inline, rather than blocking on the stopped workers
+	items[ChunkSize].Sig[0] ^= 0x01
+	done := make(chan []bool)
+	go func() { done <- pool.Verify(items) }()
+	select {
+	case results := <-done:
+		for i, ok := range results {
+			assert.Equal(t, i != ChunkSize, ok, "item=%d", i)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Verify blocked after Stop")
+	}
+}
+
+func TestPoolStopWhileVerifying(t *testing.T) {
+	pool := NewPool(2)
+	items := makeItems(3*ChunkSize + 5)
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func() {
+			done <- pool.VerifyAll(items)
+		}()
+	}
+	pool.Stop()
+	for i := 0; i < 10; i++ {
+		select {
+		case ok := <-done:
+			assert.True(t, ok)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Verify blocked by Stop")
+		}
+	}
+}
+
+func TestPoolConcurrentCallers(t *testing.T) {
+	pool := NewPool(2)
+	defer pool.Stop()
+
+	items := makeItems(2*ChunkSize + 5)
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func() {
+			done <- pool.VerifyAll(items)
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		assert.True(t, <-done)
+	}
+}
+
+func BenchmarkVerify100(b *testing.B) {
+	items := makeItems(100)
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, item := range items {
+				item.PubKey.VerifyBytes(item.Msg, item.Sig)
+			}
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			VerifyAll(items)
+		}
+	})
+}
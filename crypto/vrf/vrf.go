@@ -0,0 +1,182 @@
+// Package vrf implements the ECVRF-EDWARDS25519-SHA512-TAI verifiable random
+// function of RFC 9381, with Ed25519 keys.
+//
+// A VRF output is unique for a given key and input, it can be computed only
+// with the private key, and anybody holding the public key can check it with
+// the proof. Since the keys are Ed25519 keys, validators prove VRF outputs
+// with their consensus keys.
+package vrf
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+
+	"filippo.io/edwards25519"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+const (
+	// ProofSize is the size of a proof: Gamma (32 bytes), c (16 bytes) and
+	// s (32 bytes).
+	ProofSize = 80
+	// OutputSize is the size of the VRF output (beta).
+	OutputSize = sha512.Size
+
+	suite      = 0x03 // ECVRF-EDWARDS25519-SHA512-TAI
+	challenges = 16   // cLen
+)
+
+// ErrInvalidProof is returned when a proof fails to decode or verify.
+var ErrInvalidProof = errors.New("invalid VRF proof")
+
+// ErrInvalidPubKey is returned when the public key is not a valid point, or
+// has a small order.
+var ErrInvalidPubKey = errors.New("invalid VRF public key")
+
+// Prove returns the proof of the VRF output of privKey on alpha. The proof is
+// deterministic.
+func Prove(privKey ed25519.PrivKeyEd25519, alpha []byte) []byte {
+	h := sha512.Sum512(privKey[:32])
+	x := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	pub := privKey.PubKey().(ed25519.PubKeyEd25519)
+
+	hPoint := encodeToCurve(pub[:], alpha)
+	gamma := edwards25519.NewIdentityPoint().ScalarMult(x, hPoint)
+
+	// RFC 9381, section 5.4.2.2: the nonce is derived as in RFC 8032
+	k := nonce(h[32:], hPoint.Bytes())
+	u := edwards25519.NewIdentityPoint().ScalarBaseMult(k)
+	v := edwards25519.NewIdentityPoint().ScalarMult(k, hPoint)
+
+	c := challenge(pub[:], hPoint, gamma, u, v)
+	s := edwards25519.NewScalar().MultiplyAdd(challengeScalar(c), x, k)
+
+	proof := make([]byte, 0, ProofSize)
+	proof = append(proof, gamma.Bytes()...)
+	proof = append(proof, c...)
+	proof = append(proof, s.Bytes()...)
+	return proof
+}
+
+// Verify checks the proof of the VRF output of pubKey on alpha, and returns
+// the output.
+func Verify(pubKey ed25519.PubKeyEd25519, alpha, proof []byte) ([]byte, error) {
+	y, err := decodePoint(pubKey[:])
+	if err != nil || isSmallOrder(y) {
+		return nil, ErrInvalidPubKey
+	}
+	gamma, c, s, err := decodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	hPoint := encodeToCurve(pubKey[:], alpha)
+	negC := edwards25519.NewScalar().Negate(challengeScalar(c))
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	u := edwards25519.NewIdentityPoint().VarTimeDoubleScalarBaseMult(negC, y, s)
+	v := edwards25519.NewIdentityPoint().VarTimeMultiScalarMult(
+		[]*edwards25519.Scalar{s, negC}, []*edwards25519.Point{hPoint, gamma})
+
+	if subtle.ConstantTimeCompare(c, challenge(pubKey[:], hPoint, gamma, u, v)) != 1 {
+		return nil, ErrInvalidProof
+	}
+	return gammaToHash(gamma), nil
+}
+
+// ProofToHash returns the VRF output of a proof, without verifying it.
+func ProofToHash(proof []byte) ([]byte, error) {
+	gamma, _, _, err := decodeProof(proof)
+	if err != nil {
+		return nil, err
+	}
+	return gammaToHash(gamma), nil
+}
+
+func decodeProof(proof []byte) (gamma *edwards25519.Point, c []byte, s *edwards25519.Scalar, err error) {
+	if len(proof) != ProofSize {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	gamma, err = decodePoint(proof[:32])
+	if err != nil {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	s, err = edwards25519.NewScalar().SetCanonicalBytes(proof[32+challenges:])
+	if err != nil {
+		return nil, nil, nil, ErrInvalidProof
+	}
+	return gamma, proof[32 : 32+challenges], s, nil
+}
+
+// decodePoint decodes a point, rejecting the non canonical encodings (RFC
+// 8032, section 5.1.3).
+func decodePoint(bz []byte) (*edwards25519.Point, error) {
+	p, err := edwards25519.NewIdentityPoint().SetBytes(bz)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(p.Bytes(), bz) != 1 {
+		return nil, errors.New("non canonical point encoding")
+	}
+	return p, nil
+}
+
+func isSmallOrder(p *edwards25519.Point) bool {
+	return edwards25519.NewIdentityPoint().MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// encodeToCurve is ECVRF_encode_to_curve_try_and_increment (RFC 9381,
+// section 5.4.1.1), with the public key as salt.
+func encodeToCurve(pubKey, alpha []byte) *edwards25519.Point {
+	for ctr := 0; ctr < 256; ctr++ {
+		hasher := sha512.New()
+		hasher.Write([]byte{suite, 0x01})
+		hasher.Write(pubKey)
+		hasher.Write(alpha)
+		hasher.Write([]byte{byte(ctr), 0x00})
+		p, err := decodePoint(hasher.Sum(nil)[:32])
+		if err == nil {
+			return p.MultByCofactor(p)
+		}
+	}
+	// each attempt succeeds with probability about 1/2
+	panic("vrf: failed to encode to curve")
+}
+
+func nonce(prefix, hString []byte) *edwards25519.Scalar {
+	hasher := sha512.New()
+	hasher.Write(prefix)
+	hasher.Write(hString)
+	return edwards25519.NewScalar().SetUniformBytes(hasher.Sum(nil))
+}
+
+// challenge is ECVRF_challenge_generation (RFC 9381, section 5.4.3).
+func challenge(pubKey []byte, points ...*edwards25519.Point) []byte {
+	hasher := sha512.New()
+	hasher.Write([]byte{suite, 0x02})
+	hasher.Write(pubKey)
+	for _, p := range points {
+		hasher.Write(p.Bytes())
+	}
+	hasher.Write([]byte{0x00})
+	return hasher.Sum(nil)[:challenges]
+}
+
+func challengeScalar(c []byte) *edwards25519.Scalar {
+	var bz [32]byte
+	copy(bz[:], c)
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(bz[:])
+	if err != nil {
+		panic(err) // unreachable: c < 2^128
+	}
+	return s
+}
+
+func gammaToHash(gamma *edwards25519.Point) []byte {
+	hasher := sha512.New()
+	hasher.Write([]byte{suite, 0x03})
+	hasher.Write(edwards25519.NewIdentityPoint().MultByCofactor(gamma).Bytes())
+	hasher.Write([]byte{0x00})
+	return hasher.Sum(nil)
+}
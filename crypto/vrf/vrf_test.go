@@ -0,0 +1,94 @@
+package vrf_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	stded25519 "golang.org/x/crypto/ed25519"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/vrf"
+)
+
+func mustDecode(s string) []byte {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func TestRFC9381Vector(t *testing.T) {
+	// RFC 9381, appendix B.3, example 16
+	var privKey ed25519.PrivKeyEd25519
+	copy(privKey[:], stded25519.NewKeyFromSeed(
+		mustDecode("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")))
+	pubKey := privKey.PubKey().(ed25519.PubKeyEd25519)
+	require.Equal(t, mustDecode("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"), pubKey[:])
+
+	proof := vrf.Prove(privKey, nil)
+	assert.Equal(t, mustDecode("8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f"+
+		"26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab12"+
+		"68a1b0db10836d9826a528ca76567805"), proof)
+
+	beta := mustDecode("90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff" +
+		"66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae")
+	out, err := vrf.Verify(pubKey, nil, proof)
+	require.NoError(t, err)
+	assert.Equal(t, beta, out)
+	out, err = vrf.ProofToHash(proof)
+	require.NoError(t, err)
+	assert.Equal(t, beta, out)
+}
+
+func TestProveAndVerify(t *testing.T) {
+	privKey := ed25519.GenPrivKey()
+	pubKey := privKey.PubKey().(ed25519.PubKeyEd25519)
+
+	msg := crypto.CRandBytes(128)
+	proof := vrf.Prove(privKey, msg)
+	require.Len(t, proof, vrf.ProofSize)
+	out, err := vrf.Verify(pubKey, msg, proof)
+	require.NoError(t, err)
+	assert.Len(t, out, vrf.OutputSize)
+
+	// a different message gives a different output
+	out2, err := vrf.Verify(pubKey, append(msg, 0x01), vrf.Prove(privKey, append(msg, 0x01)))
+	require.NoError(t, err)
+	assert.NotEqual(t, out, out2)
+
+	// wrong message
+	_, err = vrf.Verify(pubKey, append(msg, 0x01), proof)
+	assert.Equal(t, vrf.ErrInvalidProof, err)
+
+	// wrong key
+	_, err = vrf.Verify(ed25519.GenPrivKey().PubKey().(ed25519.PubKeyEd25519), msg, proof)
+	assert.Equal(t, vrf.ErrInvalidProof, err)
+
+	// mutated proof
+	for _, i := range []int{0, 40, 60} {
+		bad := append([]byte{}, proof...)
+		bad[i] ^= 0x01
+		_, err = vrf.Verify(pubKey, msg, bad)
+		assert.Equal(t, vrf.ErrInvalidProof, err, "mutated byte %d", i)
+	}
+
+	// truncated proof
+	_, err = vrf.Verify(pubKey, msg, proof[:vrf.ProofSize-1])
+	assert.Equal(t, vrf.ErrInvalidProof, err)
+	_, err = vrf.ProofToHash(proof[:vrf.ProofSize-1])
+	assert.Equal(t, vrf.ErrInvalidProof, err)
+}
+
+func TestVerifyRejectsSmallOrderKeys(t *testing.T) {
+	// the identity point, whose VRF output doesn't depend on the proof
+	var pubKey ed25519.PubKeyEd25519
+	pubKey[0] = 0x01
+	proof := make([]byte, vrf.ProofSize)
+	proof[0] = 0x01
+	_, err := vrf.Verify(pubKey, []byte("msg"), proof)
+	assert.Equal(t, vrf.ErrInvalidPubKey, err)
+}
@@ -85,7 +85,8 @@ func (evR *Reactor) Receive(chID byte, src p2p.Peer, msgBytes []byte) {
 			if err != nil {
 				evR.Logger.Info("Evidence is not valid", "evidence", msg.Evidence, "err", err)
 				// punish peer
-				evR.Switch.StopPeerForError(src, err)
+				evR.Switch.BanPeerForError(src, err)
+				return
 			}
 		}
 	default:
@@ -246,6 +247,9 @@ type ListMessage struct {
 // ValidateBasic performs basic validation.
 func (m *ListMessage) ValidateBasic() error {
 	for i, ev := range m.Evidence {
+		if ev == nil {
+			return fmt.Errorf("nil evidence (#%d)", i)
+		}
 		if err := ev.ValidateBasic(); err != nil {
 			return fmt.Errorf("invalid evidence (#%d): %v", i, err)
 		}
@@ -0,0 +1,261 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/tendermint/tendermint/libs/metrics"
+	"github.com/tendermint/tendermint/libs/service"
+)
+
+const (
+	defaultPoolWorkers   = 64
+	defaultPoolQueueSize = 1024
+)
+
+var (
+	// ErrPoolFull is returned by TrySubmit if the queue of the pool is full.
+	ErrPoolFull = errors.New("task pool is full")
+	// ErrPoolStopped is returned by Submit and TrySubmit once the pool has
+	// been stopped.
+	ErrPoolStopped = errors.New("task pool stopped")
+)
+
+// PoolTask is a function run by a Pool. ctx is done once the deadline of the
+// task has passed or the pool is stopped; tasks doing I/O should give up then.
+type PoolTask func(ctx context.Context)
+
+type poolTask struct {
+	fn      PoolTask
+	timeout time.Duration
+}
+
+// PoolMetrics contains the metrics of a Pool.
+type PoolMetrics struct {
+	// Number of tasks waiting for a worker.
+	Queued metrics.Gauge
+	// Number of tasks being run.
+	Running metrics.Gauge
+	// Number of tasks run.
+	Completed metrics.Counter
+	// Number of tasks which ran past their deadline.
+	TimedOut metrics.Counter
+	// Number of tasks rejected because the queue was full.
+	Rejected metrics.Counter
+}
+
+// PrometheusPoolMetrics returns PoolMetrics build using Prometheus client
+// library, named after the given subsystem. Optionally, labels can be
+// provided along with their values ("foo", "fooValue").
+func PrometheusPoolMetrics(namespace, subsystem string, labelsAndValues ...string) *PoolMetrics {
+	return PrometheusPoolMetricsWithRegisterer(stdprometheus.DefaultRegisterer, namespace, subsystem,
+		labelsAndValues...)
+}
+
+// PrometheusPoolMetricsWithRegisterer is like PrometheusPoolMetrics, but
+// registers the metrics with the given registerer.
+func PrometheusPoolMetricsWithRegisterer(registerer stdprometheus.Registerer, namespace, subsystem string,
+	labelsAndValues ...string) *PoolMetrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &PoolMetrics{
+		Queued: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_pool_queued",
+			Help:      "Number of tasks waiting for a worker.",
+		}, labels).With(labelsAndValues...),
+		Running: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_pool_running",
+			Help:      "Number of tasks being run.",
+		}, labels).With(labelsAndValues...),
+		Completed: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_pool_completed_total",
+			Help:      "Number of tasks run.",
+		}, labels).With(labelsAndValues...),
+		TimedOut: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_pool_timed_out_total",
+			Help:      "Number of tasks which ran past their deadline.",
+		}, labels).With(labelsAndValues...),
+		Rejected: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "task_pool_rejected_total",
+			Help:      "Number of tasks rejected because the queue was full.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopPoolMetrics returns no-op PoolMetrics.
+func NopPoolMetrics() *PoolMetrics {
+	return &PoolMetrics{
+		Queued:    discard.NewGauge(),
+		Running:   discard.NewGauge(),
+		Completed: discard.NewCounter(),
+		TimedOut:  discard.NewCounter(),
+		Rejected:  discard.NewCounter(),
+	}
+}
+
+// PoolOption sets an optional parameter on the Pool.
+type PoolOption func(*Pool)
+
+// PoolMetricsOption sets the metrics of the pool.
+func PoolMetricsOption(metrics *PoolMetrics) PoolOption {
+	return func(p *Pool) { p.metrics = metrics }
+}
+
+// PoolTaskTimeout sets the deadline of the tasks submitted without one.
+// Defaults to none.
+func PoolTaskTimeout(timeout time.Duration) PoolOption {
+	return func(p *Pool) { p.taskTimeout = timeout }
+}
+
+/*
+Pool runs tasks on a bounded number of workers, in place of spawning a
+goroutine per task. Tasks are queued until a worker is free; once the queue is
+full, Submit blocks and TrySubmit fails, which bounds both the goroutines and
+the memory used under load.
+
+Tasks are queued before the pool is started, and run once it is. Stopping the
+pool cancels the contexts of the running tasks and drops the queued ones.
+
+A task must not block submitting to its own pool: with all the workers doing
+so, the pool would deadlock.
+*/
+type Pool struct {
+	service.BaseService
+
+	workers     int
+	tasks       chan poolTask
+	taskTimeout time.Duration
+	metrics     *PoolMetrics
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewPool returns a new pool of the given number of workers, queueing up to
+// queueSize tasks. Non-positive values are replaced by defaults.
+func NewPool(workers, queueSize int, options ...PoolOption) *Pool {
+	if workers <= 0 {
+		workers = defaultPoolWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultPoolQueueSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		workers: workers,
+		tasks:   make(chan poolTask, queueSize),
+		metrics: NopPoolMetrics(),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	p.BaseService = *service.NewBaseService(nil, "TaskPool", p)
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// SetMetrics sets the metrics of the pool. It must be called before the pool
+// is started.
+func (p *Pool) SetMetrics(metrics *PoolMetrics) {
+	p.metrics = metrics
+}
+
+// OnStart implements service.Service by starting the workers.
+func (p *Pool) OnStart() error {
+	for i := 0; i < p.workers; i++ {
+		go p.worker()
+	}
+	return nil
+}
+
+// OnStop implements service.Service by cancelling the running tasks. The
+// workers quit once their task returns.
+func (p *Pool) OnStop() {
+	p.cancel()
+}
+
+// Submit queues task, with the given deadline (0 for the default of the pool).
+// It blocks while the queue is full, and returns ErrPoolStopped if the pool is
+// stopped meanwhile.
+func (p *Pool) Submit(task PoolTask, timeout time.Duration) error {
+	select {
+	case <-p.Quit():
+		return ErrPoolStopped
+	default:
+	}
+	select {
+	case p.tasks <- poolTask{fn: task, timeout: timeout}:
+		p.metrics.Queued.Add(1)
+		return nil
+	case <-p.Quit():
+		return ErrPoolStopped
+	}
+}
+
+// TrySubmit is like Submit, but returns ErrPoolFull in place of blocking.
+func (p *Pool) TrySubmit(task PoolTask, timeout time.Duration) error {
+	select {
+	case <-p.Quit():
+		return ErrPoolStopped
+	default:
+	}
+	select {
+	case p.tasks <- poolTask{fn: task, timeout: timeout}:
+		p.metrics.Queued.Add(1)
+		return nil
+	default:
+		p.metrics.Rejected.Add(1)
+		return ErrPoolFull
+	}
+}
+
+func (p *Pool) worker() {
+	for {
+		select {
+		case task := <-p.tasks:
+			p.metrics.Queued.Add(-1)
+			p.run(task)
+		case <-p.Quit():
+			return
+		}
+	}
+}
+
+func (p *Pool) run(task poolTask) {
+	timeout := task.timeout
+	if timeout <= 0 {
+		timeout = p.taskTimeout
+	}
+	ctx, cancel := p.ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(p.ctx, timeout)
+	}
+	defer cancel()
+
+	p.metrics.Running.Add(1)
+	task.fn(ctx)
+	p.metrics.Running.Add(-1)
+	p.metrics.Completed.Add(1)
+	if ctx.Err() == context.DeadlineExceeded {
+		p.metrics.TimedOut.Add(1)
+		p.Logger.Debug("Task ran past its deadline", "timeout", timeout)
+	}
+}
@@ -0,0 +1,127 @@
+package async
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolRunsQueuedTasks(t *testing.T) {
+	p := NewPool(4, 16)
+
+	// tasks submitted before the start are run once started
+	var counter int32
+	done := make(chan struct{}, 32)
+	for i := 0; i < 8; i++ {
+		require.NoError(t, p.Submit(func(context.Context) {
+			atomic.AddInt32(&counter, 1)
+			done <- struct{}{}
+		}, 0))
+	}
+	require.NoError(t, p.Start())
+	defer p.Stop()
+	for i := 0; i < 8; i++ {
+		require.NoError(t, p.Submit(func(context.Context) {
+			atomic.AddInt32(&counter, 1)
+			done <- struct{}{}
+		}, 0))
+	}
+
+	for i := 0; i < 16; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for tasks")
+		}
+	}
+	assert.EqualValues(t, 16, atomic.LoadInt32(&counter))
+}
+
+func TestPoolBackpressure(t *testing.T) {
+	p := NewPool(1, 1)
+	require.NoError(t, p.Start())
+	defer p.Stop()
+
+	release := make(chan struct{})
+	running := make(chan struct{})
+	require.NoError(t, p.Submit(func(context.Context) {
+		close(running)
+		<-release
+	}, 0))
+	<-running
+	// the worker is busy: one more task fits in the queue
+	require.NoError(t, p.TrySubmit(func(context.Context) {}, 0))
+	assert.Equal(t, ErrPoolFull, p.TrySubmit(func(context.Context) {}, 0))
+
+	submitted := make(chan error)
+	go func() {
+		submitted <- p.Submit(func(context.Context) {}, 0)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("Submit didn't block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	select {
+	case err := <-submitted:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Submit still blocked")
+	}
+}
+
+func TestPoolTaskDeadline(t *testing.T) {
+	p := NewPool(1, 1, PoolTaskTimeout(time.Hour))
+	require.NoError(t, p.Start())
+	defer p.Stop()
+
+	errs := make(chan error, 1)
+	require.NoError(t, p.Submit(func(ctx context.Context) {
+		<-ctx.Done()
+		errs <- ctx.Err()
+	}, 10*time.Millisecond))
+	select {
+	case err := <-errs:
+		assert.Equal(t, context.DeadlineExceeded, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadline not enforced")
+	}
+
+	require.NoError(t, p.Submit(func(ctx context.Context) {
+		deadline, ok := ctx.Deadline()
+		if assert.True(t, ok) {
+			assert.True(t, time.Until(deadline) > time.Minute)
+		}
+		errs <- nil
+	}, 0))
+	<-errs
+}
+
+func TestPoolStopCancelsTasks(t *testing.T) {
+	p := NewPool(1, 1)
+	require.NoError(t, p.Start())
+
+	errs := make(chan error, 1)
+	running := make(chan struct{})
+	require.NoError(t, p.Submit(func(ctx context.Context) {
+		close(running)
+		<-ctx.Done()
+		errs <- ctx.Err()
+	}, 0))
+	<-running
+	require.NoError(t, p.Stop())
+	select {
+	case err := <-errs:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("task not cancelled")
+	}
+
+	assert.Equal(t, ErrPoolStopped, p.Submit(func(context.Context) {}, 0))
+	assert.Equal(t, ErrPoolStopped, p.TrySubmit(func(context.Context) {}, 0))
+}
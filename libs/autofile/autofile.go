@@ -2,12 +2,12 @@ package autofile
 
 import (
 	"os"
-	"os/signal"
 	"path/filepath"
 	"sync"
 	"syscall"
 	"time"
 
+	tmos "github.com/tendermint/tendermint/libs/os"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 )
 
@@ -39,7 +39,8 @@ const (
 )
 
 // AutoFile automatically closes and re-opens file for writing. The file is
-// automatically setup to close itself every 1s and upon receiving SIGHUP.
+// automatically setup to close itself every 1s and upon receiving SIGHUP or
+// SIGUSR1 (tmos.ReopenSignal).
 //
 // This is useful for using a log file with the logrotate tool.
 type AutoFile struct {
@@ -48,7 +49,7 @@ type AutoFile struct {
 
 	closeTicker      *time.Ticker
 	closeTickerStopc chan struct{} // closed when closeTicker is stopped
-	hupc             chan os.Signal
+	removeSignals    func()        // removes the signal handlers
 
 	mtx  sync.Mutex
 	file *os.File
@@ -74,27 +75,28 @@ func OpenAutoFile(path string) (*AutoFile, error) {
 		return nil, err
 	}
 
-	// Close file on SIGHUP.
-	af.hupc = make(chan os.Signal, 1)
-	signal.Notify(af.hupc, syscall.SIGHUP)
-	go func() {
-		for range af.hupc {
-			af.closeFile()
-		}
-	}()
+	// Close file on SIGHUP and SIGUSR1. Handlers of other subsystems, like
+	// the config reload on SIGHUP, run after.
+	closeFile := func() { af.closeFile() }
+	removeHUP := tmos.HandleSignal(syscall.SIGHUP, -1, "reopen "+path, closeFile)
+	removeReopen := tmos.HandleSignal(tmos.ReopenSignal, -1, "reopen "+path, closeFile)
+	af.removeSignals = func() {
+		removeHUP()
+		removeReopen()
+	}
 
 	go af.closeFileRoutine()
 
 	return af, nil
 }
 
-// Close shuts down the closing goroutine, SIGHUP handler and closes the
+// Close shuts down the closing goroutine, signal handlers and closes the
 // AutoFile.
 func (af *AutoFile) Close() error {
 	af.closeTicker.Stop()
 	close(af.closeTickerStopc)
-	if af.hupc != nil {
-		close(af.hupc)
+	if af.removeSignals != nil {
+		af.removeSignals()
 	}
 	return af.closeFile()
 }
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	auto "github.com/tendermint/tendermint/libs/autofile"
 	tmos "github.com/tendermint/tendermint/libs/os"
@@ -16,16 +17,29 @@ const Version = "0.0.1"
 const readBufferSize = 1024 // 1KB at a time
 
 // Parse command-line options
-func parseFlags() (headPath string, chopSize int64, limitSize int64, version bool) {
+func parseFlags() (headPath string, groupOptions []func(*auto.Group), version bool) {
 	var flagSet = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	var chopSizeStr, limitSizeStr string
+	var chopAge, maxAge time.Duration
+	var maxFiles int
+	var compress bool
 	flagSet.StringVar(&headPath, "head", "logjack.out", "Destination (head) file.")
 	flagSet.StringVar(&chopSizeStr, "chop", "100M", "Move file if greater than this")
+	flagSet.DurationVar(&chopAge, "chop-age", 0, "Move file if older than this (0 to disable)")
 	flagSet.StringVar(&limitSizeStr, "limit", "10G", "Only keep this much (for each specified file). Remove old files.")
+	flagSet.IntVar(&maxFiles, "max-files", 0, "Only keep this many moved files (0 to disable)")
+	flagSet.DurationVar(&maxAge, "max-age", 0, "Remove moved files older than this (0 to disable)")
+	flagSet.BoolVar(&compress, "compress", false, "Compress moved files with gzip")
 	flagSet.BoolVar(&version, "version", false, "Version")
 	flagSet.Parse(os.Args[1:])
-	chopSize = parseBytesize(chopSizeStr)
-	limitSize = parseBytesize(limitSizeStr)
+	groupOptions = []func(*auto.Group){
+		auto.GroupHeadSizeLimit(parseBytesize(chopSizeStr)),
+		auto.GroupHeadAgeLimit(chopAge),
+		auto.GroupTotalSizeLimit(parseBytesize(limitSizeStr)),
+		auto.GroupMaxFiles(maxFiles),
+		auto.GroupMaxFileAge(maxAge),
+		auto.GroupCompress(compress),
+	}
 	return
 }
 
@@ -46,14 +60,14 @@ func main() {
 	})
 
 	// Read options
-	headPath, chopSize, limitSize, version := parseFlags()
+	headPath, groupOptions, version := parseFlags()
 	if version {
 		fmt.Printf("logjack version %v\n", Version)
 		return
 	}
 
 	// Open Group
-	group, err := auto.OpenGroup(headPath, auto.GroupHeadSizeLimit(chopSize), auto.GroupTotalSizeLimit(limitSize))
+	group, err := auto.OpenGroup(headPath, groupOptions...)
 	if err != nil {
 		fmt.Printf("logjack couldn't create output file %v\n", headPath)
 		os.Exit(1)
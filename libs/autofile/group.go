@@ -2,6 +2,7 @@ package autofile
 
 import (
 	"bufio"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/golang/snappy"
+
 	"github.com/tendermint/tendermint/libs/service"
 )
 
@@ -23,6 +26,48 @@ const (
 	maxFilesToRemove          = 4                      // needs to be greater than 1
 )
 
+// Compression is the algorithm compressing the rotated files of a Group.
+type Compression string
+
+const (
+	CompressionNone   Compression = "none"
+	CompressionGzip   Compression = "gzip"
+	CompressionSnappy Compression = "snappy"
+)
+
+// compressions are the algorithms the rotated files can be compressed with,
+// in the order readers look for them.
+var compressions = []Compression{CompressionGzip, CompressionSnappy}
+
+// ext returns the extension appended to the path of the rotated files
+// compressed with c.
+func (c Compression) ext() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionSnappy:
+		return ".sz"
+	default:
+		return ""
+	}
+}
+
+// newWriter returns a writer compressing to w with c.
+func (c Compression) newWriter(w io.Writer) io.WriteCloser {
+	if c == CompressionSnappy {
+		return snappy.NewBufferedWriter(w)
+	}
+	return gzip.NewWriter(w)
+}
+
+// newReader returns a reader decompressing r with c.
+func (c Compression) newReader(r io.Reader) (io.Reader, error) {
+	if c == CompressionSnappy {
+		return snappy.NewReader(r), nil
+	}
+	return gzip.NewReader(r)
+}
+
 /*
 You can open a Group to keep restrictions on an AutoFile, like
 the maximum size of each chunk, and/or the total amount of bytes
@@ -48,6 +93,15 @@ As more files are written, the index numbers grow...
 	- ...
 	- <HeadPath>       // New head path
 
+The head can also be rotated once it is older than an age limit
+(GroupHeadAgeLimit). Rotated files can be compressed with gzip or snappy
+(GroupCompression), which appends ".gz" or ".sz" to their path: a file is
+compressed in the background as soon as it is rotated, and readers decompress
+it transparently, whatever the algorithm it was compressed with. The oldest
+rotated files are removed once the group exceeds its total size limit
+(GroupTotalSizeLimit), the number of rotated files exceeds a limit
+(GroupMaxFiles) or they are older than an age limit (GroupMaxFileAge).
+
 The Group can also be used to binary-search for some line,
 assuming that marker lines are written occasionally.
 */
@@ -61,16 +115,27 @@ type Group struct {
 	ticker             *time.Ticker
 	mtx                sync.Mutex
 	headSizeLimit      int64
+	headAgeLimit       time.Duration
+	headCreated        time.Time // when the head started, for headAgeLimit
 	totalSizeLimit     int64
+	maxFiles           int
+	maxFileAge         time.Duration
+	compression        Compression
 	groupCheckDuration time.Duration
 	minIndex           int // Includes head
 	maxIndex           int // Includes head, where Head will move to
+	compressedIndex    int // Rotated files before it are compressed
 
 	// close this when the processTicks routine is done.
 	// this ensures we can cleanup the dir after calling Stop
 	// and the routine won't be trying to access it anymore
 	doneProcessTicks chan struct{}
 
+	// RotateFile signals the compressRoutine on compressCh; the latter closes
+	// doneCompress when done.
+	compressCh   chan struct{}
+	doneCompress chan struct{}
+
 	// TODO: When we start deleting files, we need to start tracking GroupReaders
 	// and their dependencies.
 }
@@ -94,10 +159,13 @@ func OpenGroup(headPath string, groupOptions ...func(*Group)) (*Group, error) {
 		Dir:                dir,
 		headSizeLimit:      defaultHeadSizeLimit,
 		totalSizeLimit:     defaultTotalSizeLimit,
+		compression:        CompressionNone,
 		groupCheckDuration: defaultGroupCheckDuration,
 		minIndex:           0,
 		maxIndex:           0,
 		doneProcessTicks:   make(chan struct{}),
+		compressCh:         make(chan struct{}, 1),
+		doneCompress:       make(chan struct{}),
 	}
 
 	for _, option := range groupOptions {
@@ -109,6 +177,17 @@ func OpenGroup(headPath string, groupOptions ...func(*Group)) (*Group, error) {
 	gInfo := g.readGroupInfo()
 	g.minIndex = gInfo.MinIndex
 	g.maxIndex = gInfo.MaxIndex
+	g.compressedIndex = gInfo.MinIndex
+	removeCompressionLeftovers(headPath, gInfo)
+
+	// The head started when the last file was rotated, i.e. when the latter
+	// was last written to.
+	g.headCreated = time.Now()
+	if gInfo.MaxIndex > gInfo.MinIndex {
+		if _, fInfo, err := statIndex(headPath, gInfo.MaxIndex-1, gInfo.MaxIndex); err == nil {
+			g.headCreated = fInfo.ModTime()
+		}
+	}
 	return g, nil
 }
 
@@ -126,6 +205,14 @@ func GroupHeadSizeLimit(limit int64) func(*Group) {
 	}
 }
 
+// GroupHeadAgeLimit makes the group rotate the head once it is older than
+// limit, unless it's empty. 0 (the default) disables it.
+func GroupHeadAgeLimit(limit time.Duration) func(*Group) {
+	return func(g *Group) {
+		g.headAgeLimit = limit
+	}
+}
+
 // GroupTotalSizeLimit allows you to overwrite default total size limit of the group - 1GB.
 func GroupTotalSizeLimit(limit int64) func(*Group) {
 	return func(g *Group) {
@@ -133,11 +220,45 @@ func GroupTotalSizeLimit(limit int64) func(*Group) {
 	}
 }
 
-// OnStart implements service.Service by starting the goroutine that checks file
-// and group limits.
+// GroupMaxFiles makes the group remove the oldest rotated files once there are
+// more than max of them. 0 (the default) disables it.
+func GroupMaxFiles(max int) func(*Group) {
+	return func(g *Group) {
+		g.maxFiles = max
+	}
+}
+
+// GroupMaxFileAge makes the group remove rotated files which were last written
+// to more than age ago. 0 (the default) disables it.
+func GroupMaxFileAge(age time.Duration) func(*Group) {
+	return func(g *Group) {
+		g.maxFileAge = age
+	}
+}
+
+// GroupCompress makes the group compress rotated files with gzip.
+func GroupCompress(compress bool) func(*Group) {
+	if compress {
+		return GroupCompression(CompressionGzip)
+	}
+	return GroupCompression(CompressionNone)
+}
+
+// GroupCompression makes the group compress rotated files with the given
+// algorithm. CompressionNone (the default) disables it.
+func GroupCompression(compression Compression) func(*Group) {
+	return func(g *Group) {
+		g.compression = compression
+	}
+}
+
+// OnStart implements service.Service by starting the goroutines that check file
+// and group limits and compress rotated files.
 func (g *Group) OnStart() error {
 	g.ticker = time.NewTicker(g.groupCheckDuration)
 	go g.processTicks()
+	go g.compressRoutine()
+	g.requestCompression() // files rotated before a restart
 	return nil
 }
 
@@ -151,8 +272,9 @@ func (g *Group) OnStop() {
 // Wait blocks until all internal goroutines are finished. Supposed to be
 // called after Stop.
 func (g *Group) Wait() {
-	// wait for processTicks routine to finish
+	// wait for processTicks and compressRoutine to finish
 	<-g.doneProcessTicks
+	<-g.doneCompress
 }
 
 // Close closes the head file. The group must be stopped by this moment.
@@ -238,7 +360,10 @@ func (g *Group) processTicks() {
 		select {
 		case <-g.ticker.C:
 			g.checkHeadSizeLimit()
+			g.checkHeadAgeLimit()
+			g.requestCompression() // retries failed compressions
 			g.checkTotalSizeLimit()
+			g.checkFileLimits()
 		case <-g.Quit():
 			return
 		}
@@ -261,6 +386,23 @@ func (g *Group) checkHeadSizeLimit() {
 	}
 }
 
+func (g *Group) checkHeadAgeLimit() {
+	g.mtx.Lock()
+	limit, created := g.headAgeLimit, g.headCreated
+	g.mtx.Unlock()
+	if limit == 0 || time.Since(created) < limit {
+		return
+	}
+	size, err := g.Head.Size()
+	if err != nil {
+		g.Logger.Error("Group's head may grow without bound", "head", g.Head.Path, "err", err)
+		return
+	}
+	if size > 0 || g.Buffered() > 0 {
+		g.RotateFile()
+	}
+}
+
 func (g *Group) checkTotalSizeLimit() {
 	limit := g.TotalSizeLimit()
 	if limit == 0 {
@@ -279,8 +421,7 @@ func (g *Group) checkTotalSizeLimit() {
 			g.Logger.Error("Group's head may grow without bound", "head", g.Head.Path)
 			return
 		}
-		pathToRemove := filePathForIndex(g.Head.Path, index, gInfo.MaxIndex)
-		fInfo, err := os.Stat(pathToRemove)
+		pathToRemove, fInfo, err := statIndex(g.Head.Path, index, gInfo.MaxIndex)
 		if err != nil {
 			g.Logger.Error("Failed to fetch info for file", "file", pathToRemove)
 			continue
@@ -294,6 +435,146 @@ func (g *Group) checkTotalSizeLimit() {
 	}
 }
 
+// checkFileLimits removes the oldest rotated files while there are more than
+// maxFiles of them or they are older than maxFileAge.
+func (g *Group) checkFileLimits() {
+	g.mtx.Lock()
+	maxFiles, maxAge := g.maxFiles, g.maxFileAge
+	g.mtx.Unlock()
+	if maxFiles == 0 && maxAge == 0 {
+		return
+	}
+
+	gInfo := g.ReadGroupInfo()
+	for index := gInfo.MinIndex; index < gInfo.MaxIndex; index++ {
+		path, fInfo, err := statIndex(g.Head.Path, index, gInfo.MaxIndex)
+		if err != nil {
+			continue
+		}
+		tooMany := maxFiles > 0 && gInfo.MaxIndex-index > maxFiles
+		tooOld := maxAge > 0 && time.Since(fInfo.ModTime()) > maxAge
+		if !tooMany && !tooOld {
+			// newer files are neither
+			return
+		}
+		if err := os.Remove(path); err != nil {
+			g.Logger.Error("Failed to remove path", "path", path, "err", err)
+			return
+		}
+	}
+}
+
+// requestCompression wakes up the compressRoutine, unless it is already due to
+// run.
+func (g *Group) requestCompression() {
+	select {
+	case g.compressCh <- struct{}{}:
+	default:
+	}
+}
+
+// compressRoutine compresses the rotated files in the background, so that
+// rotating and writing to the head are not held up by compression.
+func (g *Group) compressRoutine() {
+	defer close(g.doneCompress)
+	for {
+		select {
+		case <-g.compressCh:
+			g.compressRotatedFiles()
+		case <-g.Quit():
+			return
+		}
+	}
+}
+
+// compressRotatedFiles compresses the rotated files which are not compressed
+// yet, if compression is enabled, and advances the compressed index past them.
+func (g *Group) compressRotatedFiles() {
+	g.mtx.Lock()
+	compression := g.compression
+	g.mtx.Unlock()
+	if compression == CompressionNone {
+		return
+	}
+
+	gInfo := g.ReadGroupInfo()
+	g.mtx.Lock()
+	index := g.compressedIndex
+	g.mtx.Unlock()
+	if index < gInfo.MinIndex {
+		index = gInfo.MinIndex // older files were removed
+	}
+	for ; index < gInfo.MaxIndex; index++ {
+		path := filePathForIndex(g.Head.Path, index, gInfo.MaxIndex)
+		if _, err := os.Stat(path); err == nil {
+			if err := compressFile(path, compression); err != nil {
+				g.Logger.Error("Failed to compress file", "file", path, "err", err)
+				return
+			}
+		} // else compressed or removed
+		g.mtx.Lock()
+		g.compressedIndex = index + 1
+		g.mtx.Unlock()
+	}
+}
+
+// removeCompressionLeftovers removes the temporary files of the compressions
+// interrupted by a crash. They would otherwise count towards the total size of
+// the group.
+func removeCompressionLeftovers(headPath string, gInfo GroupInfo) {
+	for index := gInfo.MinIndex; index < gInfo.MaxIndex; index++ {
+		for _, c := range compressions {
+			path := filePathForIndex(headPath, index, gInfo.MaxIndex) + c.ext() + ".tmp"
+			os.Remove(path) // nolint: errcheck
+		}
+	}
+}
+
+// compressFile replaces the file at path with a copy compressed with
+// compression at path+compression.ext(), which keeps the modification time of
+// the original. The copy is complete before the original is removed, so that
+// readers always find one of them.
+func compressFile(path string, compression Compression) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	fInfo, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	compressedPath := path + compression.ext()
+	tmpPath := compressedPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, autoFilePerms)
+	if err != nil {
+		return err
+	}
+	zw := compression.newWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chtimes(tmpPath, fInfo.ModTime(), fInfo.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, compressedPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Remove(path)
+}
+
 // RotateFile causes group to close the current head and assign it some index.
 // Note it does not create a new head.
 func (g *Group) RotateFile() {
@@ -320,6 +601,11 @@ func (g *Group) RotateFile() {
 	}
 
 	g.maxIndex++
+	g.headCreated = time.Now()
+
+	if g.compression != CompressionNone {
+		g.requestCompression()
+	}
 }
 
 // NewReader returns a new group reader.
@@ -333,6 +619,24 @@ func (g *Group) NewReader(index int) (*GroupReader, error) {
 	return r, nil
 }
 
+// OpenGroupReader returns a reader of the group with head at headPath, from
+// its first file, without opening the group for writing, e.g. to read the
+// group of a stopped process. The files rotated after it is opened are not
+// read.
+func OpenGroupReader(headPath string) (*GroupReader, error) {
+	if _, err := os.Stat(headPath); err != nil {
+		return nil, err
+	}
+	g := &Group{
+		ID:   "group:" + headPath,
+		Head: &AutoFile{Path: headPath},
+	}
+	gInfo := g.readGroupInfo()
+	g.minIndex = gInfo.MinIndex
+	g.maxIndex = gInfo.MaxIndex
+	return g.NewReader(g.minIndex)
+}
+
 // GroupInfo holds information about the group.
 type GroupInfo struct {
 	MinIndex  int   // index of the first file in the group, including head
@@ -376,7 +680,7 @@ func (g *Group) readGroupInfo() GroupInfo {
 		} else if strings.HasPrefix(fileInfo.Name(), headBase) {
 			fileSize := fileInfo.Size()
 			totalSize += fileSize
-			indexedFilePattern := regexp.MustCompile(`^.+\.([0-9]{3,})$`)
+			indexedFilePattern := regexp.MustCompile(`^.+\.([0-9]{3,})(\.gz|\.sz)?$`)
 			submatch := indexedFilePattern.FindSubmatch([]byte(fileInfo.Name()))
 			if len(submatch) != 0 {
 				// Matches
@@ -413,6 +717,21 @@ func filePathForIndex(headPath string, index int, maxIndex int) string {
 	return fmt.Sprintf("%v.%03d", headPath, index)
 }
 
+// statIndex returns the path and info of the file with the given index, which
+// may be compressed.
+func statIndex(headPath string, index int, maxIndex int) (string, os.FileInfo, error) {
+	path := filePathForIndex(headPath, index, maxIndex)
+	fInfo, err := os.Stat(path)
+	if os.IsNotExist(err) && index != maxIndex {
+		for _, c := range compressions {
+			if cInfo, cErr := os.Stat(path + c.ext()); cErr == nil {
+				return path + c.ext(), cInfo, nil
+			}
+		}
+	}
+	return path, fInfo, err
+}
+
 //--------------------------------------------------------------------------------
 
 // GroupReader provides an interface for reading from a Group.
@@ -503,11 +822,30 @@ func (gr *GroupReader) openFile(index int) error {
 	}
 
 	curFilePath := filePathForIndex(gr.Head.Path, index, gr.Group.maxIndex)
-	curFile, err := os.OpenFile(curFilePath, os.O_RDONLY|os.O_CREATE, autoFilePerms)
+	// A rotated file is removed only once its compressed copy exists, so look
+	// for the file first.
+	curFile, err := os.Open(curFilePath)
+	var curReader *bufio.Reader
+	switch {
+	case err == nil:
+		curReader = bufio.NewReader(curFile)
+	case os.IsNotExist(err) && index != gr.Group.maxIndex:
+		for _, c := range compressions {
+			curFile, curReader, err = openCompressed(curFilePath, c)
+			if !os.IsNotExist(err) {
+				break
+			}
+		}
+	}
+	if os.IsNotExist(err) {
+		curFile, err = os.OpenFile(curFilePath, os.O_RDONLY|os.O_CREATE, autoFilePerms)
+		if err == nil {
+			curReader = bufio.NewReader(curFile)
+		}
+	}
 	if err != nil {
 		return err
 	}
-	curReader := bufio.NewReader(curFile)
 
 	// Update gr.cur*
 	if gr.curFile != nil {
@@ -520,6 +858,21 @@ func (gr *GroupReader) openFile(index int) error {
 	return nil
 }
 
+// openCompressed opens the file at path, compressed with compression, for
+// reading.
+func openCompressed(path string, compression Compression) (*os.File, *bufio.Reader, error) {
+	f, err := os.Open(path + compression.ext())
+	if err != nil {
+		return nil, nil, err
+	}
+	zr, err := compression.newReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, bufio.NewReader(zr), nil
+}
+
 // CurIndex returns cursor's file index.
 func (gr *GroupReader) CurIndex() int {
 	gr.mtx.Lock()
@@ -1,11 +1,13 @@
 package autofile
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -260,3 +262,208 @@ func TestMaxIndex(t *testing.T) {
 	// Cleanup
 	destroyTestGroup(t, g)
 }
+
+func TestCheckHeadAgeLimit(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+	GroupHeadAgeLimit(time.Hour)(g)
+
+	g.WriteLine("Line 1")
+	g.FlushAndSync()
+
+	// The head is not old enough yet.
+	g.checkHeadAgeLimit()
+	assertGroupInfo(t, g.ReadGroupInfo(), 0, 0, 7, 7)
+
+	g.headCreated = time.Now().Add(-2 * time.Hour)
+	g.checkHeadAgeLimit()
+	assertGroupInfo(t, g.ReadGroupInfo(), 0, 1, 7, 0)
+
+	// An empty head is not rotated.
+	g.headCreated = time.Now().Add(-2 * time.Hour)
+	g.checkHeadAgeLimit()
+	assertGroupInfo(t, g.ReadGroupInfo(), 0, 1, 7, 0)
+
+	destroyTestGroup(t, g)
+}
+
+func TestCompressRotatedFiles(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+	GroupCompress(true)(g)
+
+	g.WriteLine("Line 1")
+	g.WriteLine("Line 2")
+	g.FlushAndSync()
+	g.RotateFile()
+	g.WriteLine("Line 3")
+	g.FlushAndSync()
+	g.RotateFile()
+	g.WriteLine("Line 4")
+	g.FlushAndSync()
+
+	g.compressRotatedFiles()
+	for _, path := range []string{g.Head.Path + ".000", g.Head.Path + ".001"} {
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err), "%s should have been removed", path)
+		_, err = os.Stat(path + ".gz")
+		assert.NoError(t, err)
+	}
+	gInfo := g.ReadGroupInfo()
+	assert.Equal(t, 0, gInfo.MinIndex)
+	assert.Equal(t, 2, gInfo.MaxIndex)
+
+	// Readers decompress rotated files.
+	gr, err := g.NewReader(0)
+	require.NoError(t, err)
+	defer gr.Close()
+	read, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "Line 1\nLine 2\nLine 3\nLine 4\n", string(read))
+
+	// Rotating again continues after the compressed files.
+	g.RotateFile()
+	assert.Equal(t, 3, g.ReadGroupInfo().MaxIndex)
+	_, err = os.Stat(g.Head.Path + ".002")
+	assert.NoError(t, err)
+
+	destroyTestGroup(t, g)
+}
+
+func TestCompressRotatedFilesWithSnappy(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+	GroupCompression(CompressionSnappy)(g)
+
+	g.WriteLine("Line 1")
+	g.FlushAndSync()
+	g.RotateFile()
+	g.compressRotatedFiles()
+	_, err := os.Stat(g.Head.Path + ".000.sz")
+	assert.NoError(t, err)
+
+	// The files compressed with another algorithm are still read, and count
+	// towards the limits.
+	GroupCompression(CompressionGzip)(g)
+	g.WriteLine("Line 2")
+	g.FlushAndSync()
+	g.RotateFile()
+	g.compressRotatedFiles()
+	_, err = os.Stat(g.Head.Path + ".001.gz")
+	assert.NoError(t, err)
+	g.WriteLine("Line 3")
+	g.FlushAndSync()
+
+	gInfo := g.ReadGroupInfo()
+	assert.Equal(t, 0, gInfo.MinIndex)
+	assert.Equal(t, 2, gInfo.MaxIndex)
+
+	gr, err := g.NewReader(0)
+	require.NoError(t, err)
+	defer gr.Close()
+	read, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "Line 1\nLine 2\nLine 3\n", string(read))
+
+	GroupMaxFiles(1)(g)
+	g.checkFileLimits()
+	_, err = os.Stat(g.Head.Path + ".000.sz")
+	assert.True(t, os.IsNotExist(err), "the oldest file should have been removed")
+
+	destroyTestGroup(t, g)
+}
+
+func TestOpenGroupReader(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+	GroupCompress(true)(g)
+
+	g.WriteLine("Line 1")
+	g.FlushAndSync()
+	g.RotateFile()
+	g.WriteLine("Line 2")
+	g.FlushAndSync()
+	g.RotateFile()
+	g.WriteLine("Line 3")
+	g.FlushAndSync()
+	g.compressRotatedFiles()
+	g.Close()
+	defer destroyTestGroup(t, g)
+
+	// The group is read, compressed files included, once closed.
+	gr, err := OpenGroupReader(g.Head.Path)
+	require.NoError(t, err)
+	defer gr.Close()
+	read, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "Line 1\nLine 2\nLine 3\n", string(read))
+
+	_, err = OpenGroupReader(g.Head.Path + "-missing")
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(g.Head.Path + "-missing")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCompressOnRotate(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+	GroupCompress(true)(g)
+
+	// The leftover of an interrupted compression is removed on open.
+	g.WriteLine("Line 1")
+	g.FlushAndSync()
+	g.RotateFile()
+	leftover := g.Head.Path + ".000.gz.tmp"
+	require.NoError(t, ioutil.WriteFile(leftover, []byte("partial"), 0600))
+	g.Close()
+	g, err := OpenGroup(g.Head.Path, GroupHeadSizeLimit(0), GroupCompress(true))
+	require.NoError(t, err)
+	_, err = os.Stat(leftover)
+	assert.True(t, os.IsNotExist(err), "%s should have been removed", leftover)
+
+	// Files rotated before the start and from then on are compressed in the
+	// background, without waiting for the ticks.
+	GroupCheckDuration(time.Hour)(g)
+	require.NoError(t, g.Start())
+	g.WriteLine("Line 2")
+	g.FlushAndSync()
+	g.RotateFile()
+	for _, path := range []string{g.Head.Path + ".000", g.Head.Path + ".001"} {
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(path + ".gz")
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond, "%s should have been compressed", path)
+	}
+	require.NoError(t, g.Stop())
+	g.Wait()
+	assert.Equal(t, 2, g.compressedIndex)
+
+	gr, err := g.NewReader(0)
+	require.NoError(t, err)
+	defer gr.Close()
+	read, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "Line 1\nLine 2\n", string(read))
+
+	destroyTestGroup(t, g)
+}
+
+func TestCheckFileLimits(t *testing.T) {
+	g := createTestGroupWithHeadSizeLimit(t, 0)
+	GroupMaxFiles(2)(g)
+	GroupMaxFileAge(time.Hour)(g)
+
+	for i := 0; i < 4; i++ {
+		g.WriteLine(fmt.Sprintf("Line %d", i))
+		g.FlushAndSync()
+		g.RotateFile()
+	}
+	assertGroupInfo(t, g.ReadGroupInfo(), 0, 4, 28, 0)
+
+	// Only the 2 newest rotated files are kept.
+	g.checkFileLimits()
+	assertGroupInfo(t, g.ReadGroupInfo(), 2, 4, 14, 0)
+
+	// Rotated files older than the age limit are removed.
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(g.Head.Path+".002", old, old))
+	g.checkFileLimits()
+	assertGroupInfo(t, g.ReadGroupInfo(), 3, 4, 7, 0)
+
+	destroyTestGroup(t, g)
+}
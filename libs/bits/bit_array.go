@@ -11,11 +11,19 @@ import (
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 )
 
+// numStripes is the number of locks guarding the elements of a bit array.
+const numStripes = 16
+
 // BitArray is a thread-safe implementation of a bit array.
+//
+// The elements are guarded by lock stripes: GetIndex and SetIndex only lock
+// the stripe of the bit's element, so that votes of thousands of validators
+// can be recorded concurrently; operations on the whole array lock all
+// stripes. For large, sparse or nearly full arrays, see CompressedBitArray.
 type BitArray struct {
-	mtx   sync.Mutex
-	Bits  int      `json:"bits"`  // NOTE: persisted via reflect, must be exported
-	Elems []uint64 `json:"elems"` // NOTE: persisted via reflect, must be exported
+	stripes [numStripes]sync.Mutex // stripes[i%numStripes] guards Elems[i]
+	Bits    int                    `json:"bits"`  // NOTE: persisted via reflect, must be exported
+	Elems   []uint64               `json:"elems"` // NOTE: persisted via reflect, must be exported
 }
 
 // NewBitArray returns a new bit array.
@@ -44,8 +52,12 @@ func (bA *BitArray) GetIndex(i int) bool {
 	if bA == nil {
 		return false
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	if i >= bA.Bits {
+		return false
+	}
+	stripe := bA.stripe(i)
+	stripe.Lock()
+	defer stripe.Unlock()
 	return bA.getIndex(i)
 }
 
@@ -62,8 +74,12 @@ func (bA *BitArray) SetIndex(i int, v bool) bool {
 	if bA == nil {
 		return false
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	if i >= bA.Bits {
+		return false
+	}
+	stripe := bA.stripe(i)
+	stripe.Lock()
+	defer stripe.Unlock()
 	return bA.setIndex(i, v)
 }
 
@@ -79,13 +95,32 @@ func (bA *BitArray) setIndex(i int, v bool) bool {
 	return true
 }
 
+// stripe returns the lock guarding the element of bit i.
+func (bA *BitArray) stripe(i int) *sync.Mutex {
+	return &bA.stripes[(i/64)%numStripes]
+}
+
+// lock locks all stripes.
+func (bA *BitArray) lock() {
+	for i := range bA.stripes {
+		bA.stripes[i].Lock()
+	}
+}
+
+// unlock unlocks all stripes.
+func (bA *BitArray) unlock() {
+	for i := range bA.stripes {
+		bA.stripes[i].Unlock()
+	}
+}
+
 // Copy returns a copy of the provided bit array.
 func (bA *BitArray) Copy() *BitArray {
 	if bA == nil {
 		return nil
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 	return bA.copy()
 }
 
@@ -120,15 +155,14 @@ func (bA *BitArray) Or(o *BitArray) *BitArray {
 	if o == nil {
 		return bA.Copy()
 	}
-	bA.mtx.Lock()
-	o.mtx.Lock()
+	bA.lock()
+	o.lock()
 	c := bA.copyBits(tmmath.MaxInt(bA.Bits, o.Bits))
-	smaller := tmmath.MinInt(len(bA.Elems), len(o.Elems))
-	for i := 0; i < smaller; i++ {
+	for i := 0; i < len(o.Elems); i++ {
 		c.Elems[i] |= o.Elems[i]
 	}
-	bA.mtx.Unlock()
-	o.mtx.Unlock()
+	bA.unlock()
+	o.unlock()
 	return c
 }
 
@@ -139,11 +173,11 @@ func (bA *BitArray) And(o *BitArray) *BitArray {
 	if bA == nil || o == nil {
 		return nil
 	}
-	bA.mtx.Lock()
-	o.mtx.Lock()
+	bA.lock()
+	o.lock()
 	defer func() {
-		bA.mtx.Unlock()
-		o.mtx.Unlock()
+		bA.unlock()
+		o.unlock()
 	}()
 	return bA.and(o)
 }
@@ -161,8 +195,8 @@ func (bA *BitArray) Not() *BitArray {
 	if bA == nil {
 		return nil // Degenerate
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 	return bA.not()
 }
 
@@ -171,6 +205,10 @@ func (bA *BitArray) not() *BitArray {
 	for i := 0; i < len(c.Elems); i++ {
 		c.Elems[i] = ^c.Elems[i]
 	}
+	// clear the bits past the end, so that IsEmpty is right
+	if bA.Bits%64 != 0 {
+		c.Elems[len(c.Elems)-1] &= uint64(1)<<uint(bA.Bits%64) - 1
+	}
 	return c
 }
 
@@ -183,8 +221,8 @@ func (bA *BitArray) Sub(o *BitArray) *BitArray {
 		// TODO: Decide if we should do 1's complement here?
 		return nil
 	}
-	bA.mtx.Lock()
-	o.mtx.Lock()
+	bA.lock()
+	o.lock()
 	// output is the same size as bA
 	c := bA.copyBits(bA.Bits)
 	// Only iterate to the minimum size between the two.
@@ -196,8 +234,8 @@ func (bA *BitArray) Sub(o *BitArray) *BitArray {
 		// &^ is and not in golang
 		c.Elems[i] &^= o.Elems[i]
 	}
-	bA.mtx.Unlock()
-	o.mtx.Unlock()
+	bA.unlock()
+	o.unlock()
 	return c
 }
 
@@ -206,8 +244,8 @@ func (bA *BitArray) IsEmpty() bool {
 	if bA == nil {
 		return true // should this be opposite?
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 	for _, e := range bA.Elems {
 		if e > 0 {
 			return false
@@ -221,8 +259,8 @@ func (bA *BitArray) IsFull() bool {
 	if bA == nil {
 		return true
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 
 	// Check all elements except the last
 	for _, elem := range bA.Elems[:len(bA.Elems)-1] {
@@ -245,9 +283,9 @@ func (bA *BitArray) PickRandom() (int, bool) {
 		return 0, false
 	}
 
-	bA.mtx.Lock()
+	bA.lock()
 	trueIndices := bA.getTrueIndices()
-	bA.mtx.Unlock()
+	bA.unlock()
 
 	if len(trueIndices) == 0 { // no bits set to true
 		return 0, false
@@ -302,8 +340,8 @@ func (bA *BitArray) StringIndented(indent string) string {
 	if bA == nil {
 		return "nil-BitArray"
 	}
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 	return bA.stringIndented(indent)
 }
 
@@ -335,8 +373,8 @@ func (bA *BitArray) stringIndented(indent string) string {
 
 // Bytes returns the byte representation of the bits within the bitarray.
 func (bA *BitArray) Bytes() []byte {
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 
 	numBytes := (bA.Bits + 7) / 8
 	bytes := make([]byte, numBytes)
@@ -354,11 +392,11 @@ func (bA *BitArray) Update(o *BitArray) {
 	if bA == nil || o == nil {
 		return
 	}
-	bA.mtx.Lock()
-	o.mtx.Lock()
+	bA.lock()
+	o.lock()
 	defer func() {
-		bA.mtx.Unlock()
-		o.mtx.Unlock()
+		bA.unlock()
+		o.unlock()
 	}()
 
 	copy(bA.Elems, o.Elems)
@@ -371,8 +409,8 @@ func (bA *BitArray) MarshalJSON() ([]byte, error) {
 		return []byte("null"), nil
 	}
 
-	bA.mtx.Lock()
-	defer bA.mtx.Unlock()
+	bA.lock()
+	defer bA.unlock()
 
 	bits := `"`
 	for i := 0; i < bA.Bits; i++ {
@@ -395,8 +433,9 @@ func (bA *BitArray) UnmarshalJSON(bz []byte) error {
 	if b == "null" {
 		// This is required e.g. for encoding/json when decoding
 		// into a pointer with pre-allocated BitArray.
-		bA.Bits = 0
-		bA.Elems = nil
+		bA.lock()
+		bA.Bits, bA.Elems = 0, nil
+		bA.unlock()
 		return nil
 	}
 
@@ -415,6 +454,8 @@ func (bA *BitArray) UnmarshalJSON(bz []byte) error {
 			bA2.SetIndex(i, true)
 		}
 	}
-	*bA = *bA2 //nolint:govet
+	bA.lock()
+	bA.Bits, bA.Elems = bA2.Bits, bA2.Elems
+	bA.unlock()
 	return nil
 }
@@ -77,6 +77,30 @@ func TestOr(t *testing.T) {
 	}
 }
 
+func TestOrSmallerReceiver(t *testing.T) {
+	bA1, _ := randBitArray(31)
+	bA2, _ := randBitArray(200)
+	bA3 := bA1.Or(bA2)
+
+	require.Equal(t, 200, bA3.Bits)
+	for i := 0; i < bA3.Bits; i++ {
+		expected := bA1.GetIndex(i) || bA2.GetIndex(i)
+		if bA3.GetIndex(i) != expected {
+			t.Error("Wrong bit from bA3", i, bA1.GetIndex(i), bA2.GetIndex(i), bA3.GetIndex(i))
+		}
+	}
+}
+
+func TestNotOfFull(t *testing.T) {
+	bA := NewBitArray(70)
+	for i := 0; i < 70; i++ {
+		bA.SetIndex(i, true)
+	}
+	require.True(t, bA.IsFull())
+	assert.True(t, bA.Not().IsEmpty())
+	assert.True(t, bA.Not().Not().IsFull())
+}
+
 func TestSub(t *testing.T) {
 	testCases := []struct {
 		initBA        string
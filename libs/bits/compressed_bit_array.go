@@ -0,0 +1,479 @@
+package bits
+
+import (
+	"math/bits"
+	"sort"
+	"sync"
+
+	tmmath "github.com/tendermint/tendermint/libs/math"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+)
+
+const (
+	// chunkBits is the number of bits held by a container.
+	chunkBits  = 4096
+	chunkWords = chunkBits / 64
+	// maxArrayLen is the largest number of set bits held by an array
+	// container, which then takes half the memory of a bitmap.
+	maxArrayLen = chunkBits / 32
+)
+
+type containerKind uint8
+
+const (
+	arrayContainer  containerKind = iota // the sorted offsets of the set bits
+	bitmapContainer                      // chunkWords words
+	fullContainer                        // all bits set
+)
+
+// container holds the bits of a chunk of a CompressedBitArray. Empty chunks
+// have no container.
+type container struct {
+	kind   containerKind
+	array  []uint16
+	bitmap []uint64
+	n      int // number of set bits
+}
+
+/*
+CompressedBitArray is a thread-safe bit array for large validator sets,
+compressed in the style of roaring bitmaps: the bits are split into chunks of
+4096 bits, each of which is stored as
+
+  - nothing, if no bit is set
+  - the sorted offsets of the set bits, if only a few bits are set
+  - a bitmap, otherwise
+  - nothing but a flag, if all bits are set
+
+so that the arrays of a vote set - mostly empty early in a round, mostly full
+at its end - take a few bytes per chunk. The operations have the semantics of
+those of BitArray.
+*/
+type CompressedBitArray struct {
+	mtx    sync.Mutex
+	bits   int
+	chunks []*container
+}
+
+// NewCompressedBitArray returns a new compressed bit array with all bits
+// unset. It returns nil if the number of bits is zero.
+func NewCompressedBitArray(bits int) *CompressedBitArray {
+	if bits <= 0 {
+		return nil
+	}
+	return &CompressedBitArray{
+		bits:   bits,
+		chunks: make([]*container, (bits+chunkBits-1)/chunkBits),
+	}
+}
+
+// CompressBitArray returns a compressed copy of bA.
+func CompressBitArray(bA *BitArray) *CompressedBitArray {
+	if bA == nil {
+		return nil
+	}
+	bA.lock()
+	defer bA.unlock()
+	c := NewCompressedBitArray(bA.Bits)
+	for i := range c.chunks {
+		start := i * chunkWords
+		end := tmmath.MinInt(start+chunkWords, len(bA.Elems))
+		c.chunks[i] = newContainer(bA.Elems[start:end], c.chunkLen(i))
+	}
+	return c
+}
+
+// BitArray returns an uncompressed copy of c.
+func (c *CompressedBitArray) BitArray() *BitArray {
+	if c == nil {
+		return nil
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	bA := NewBitArray(c.bits)
+	for i, ct := range c.chunks {
+		copy(bA.Elems[i*chunkWords:], ct.words(c.chunkLen(i)))
+	}
+	return bA
+}
+
+// Size returns the number of bits in the bit array.
+func (c *CompressedBitArray) Size() int {
+	if c == nil {
+		return 0
+	}
+	return c.bits
+}
+
+// chunkLen returns the number of bits of chunk i.
+func (c *CompressedBitArray) chunkLen(i int) int {
+	return tmmath.MinInt(chunkBits, c.bits-i*chunkBits)
+}
+
+// GetIndex returns the bit at index i within the bit array.
+func (c *CompressedBitArray) GetIndex(i int) bool {
+	if c == nil || i < 0 || i >= c.bits {
+		return false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.chunks[i/chunkBits].get(uint16(i % chunkBits))
+}
+
+// SetIndex sets the bit at index i within the bit array. It returns false if
+// i is out of range.
+func (c *CompressedBitArray) SetIndex(i int, v bool) bool {
+	if c == nil || i < 0 || i >= c.bits {
+		return false
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	ci := i / chunkBits
+	c.chunks[ci] = c.chunks[ci].set(uint16(i%chunkBits), v, c.chunkLen(ci))
+	return true
+}
+
+// Count returns the number of set bits.
+func (c *CompressedBitArray) Count() int {
+	if c == nil {
+		return 0
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	n := 0
+	for _, ct := range c.chunks {
+		if ct != nil {
+			n += ct.n
+		}
+	}
+	return n
+}
+
+// IsEmpty returns true iff all bits are unset.
+func (c *CompressedBitArray) IsEmpty() bool {
+	return c.Count() == 0
+}
+
+// IsFull returns true iff all bits are set.
+func (c *CompressedBitArray) IsFull() bool {
+	if c == nil {
+		return true
+	}
+	return c.Count() == c.bits
+}
+
+// TrueIndices returns the indices of the set bits in increasing order.
+func (c *CompressedBitArray) TrueIndices() []int {
+	if c == nil {
+		return nil
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.trueIndices()
+}
+
+func (c *CompressedBitArray) trueIndices() []int {
+	indices := []int{}
+	for i, ct := range c.chunks {
+		if ct == nil {
+			continue
+		}
+		base := i * chunkBits
+		switch ct.kind {
+		case arrayContainer:
+			for _, off := range ct.array {
+				indices = append(indices, base+int(off))
+			}
+		case bitmapContainer:
+			for w, word := range ct.bitmap {
+				for word != 0 {
+					indices = append(indices, base+w*64+bits.TrailingZeros64(word))
+					word &= word - 1
+				}
+			}
+		case fullContainer:
+			for off := 0; off < c.chunkLen(i); off++ {
+				indices = append(indices, base+off)
+			}
+		}
+	}
+	return indices
+}
+
+// PickRandom returns a random index of a set bit. If there is no such bit, it
+// returns 0, false.
+func (c *CompressedBitArray) PickRandom() (int, bool) {
+	indices := c.TrueIndices()
+	if len(indices) == 0 {
+		return 0, false
+	}
+	return indices[tmrand.Intn(len(indices))], true
+}
+
+// Copy returns a copy of the bit array.
+func (c *CompressedBitArray) Copy() *CompressedBitArray {
+	if c == nil {
+		return nil
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.copyBits(c.bits)
+}
+
+// copyBits returns a copy of c resized to bits.
+func (c *CompressedBitArray) copyBits(bits int) *CompressedBitArray {
+	cp := NewCompressedBitArray(bits)
+	for i := range cp.chunks {
+		if i < len(c.chunks) {
+			cp.chunks[i] = newContainer(c.chunks[i].words(c.chunkLen(i)), cp.chunkLen(i))
+		}
+	}
+	return cp
+}
+
+// Or returns the bitwise OR of the bit arrays, of the size of the larger one.
+func (c *CompressedBitArray) Or(o *CompressedBitArray) *CompressedBitArray {
+	if c == nil {
+		return o.Copy()
+	}
+	if o == nil {
+		return c.Copy()
+	}
+	return c.combine(o, tmmath.MaxInt(c.bits, o.bits), func(a, b uint64) uint64 { return a | b })
+}
+
+// And returns the bitwise AND of the bit arrays, of the size of the smaller
+// one.
+func (c *CompressedBitArray) And(o *CompressedBitArray) *CompressedBitArray {
+	if c == nil || o == nil {
+		return nil
+	}
+	return c.combine(o, tmmath.MinInt(c.bits, o.bits), func(a, b uint64) uint64 { return a & b })
+}
+
+// Sub returns the bits set in c but not in o, of the size of c.
+func (c *CompressedBitArray) Sub(o *CompressedBitArray) *CompressedBitArray {
+	if c == nil || o == nil {
+		return nil
+	}
+	return c.combine(o, c.bits, func(a, b uint64) uint64 { return a &^ b })
+}
+
+// Not returns the bitwise NOT of the bit array.
+func (c *CompressedBitArray) Not() *CompressedBitArray {
+	if c == nil {
+		return nil
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	not := NewCompressedBitArray(c.bits)
+	for i, ct := range c.chunks {
+		words := ct.words(c.chunkLen(i))
+		for w := range words {
+			words[w] = ^words[w]
+		}
+		not.chunks[i] = newContainer(words, not.chunkLen(i))
+	}
+	return not
+}
+
+// combine returns the bit array of the given size whose words are op of the
+// words of c and o, the missing words being zero.
+func (c *CompressedBitArray) combine(o *CompressedBitArray, bits int, op func(a, b uint64) uint64) *CompressedBitArray {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if o != c {
+		o.mtx.Lock()
+		defer o.mtx.Unlock()
+	}
+
+	res := NewCompressedBitArray(bits)
+	for i := range res.chunks {
+		var a, b []uint64
+		if i < len(c.chunks) {
+			a = c.chunks[i].words(c.chunkLen(i))
+		}
+		if i < len(o.chunks) {
+			b = o.chunks[i].words(o.chunkLen(i))
+		}
+		words := make([]uint64, chunkWords)
+		for w := range words {
+			var x, y uint64
+			if a != nil {
+				x = a[w]
+			}
+			if b != nil {
+				y = b[w]
+			}
+			words[w] = op(x, y)
+		}
+		res.chunks[i] = newContainer(words, res.chunkLen(i))
+	}
+	return res
+}
+
+// MemSize returns the approximate number of bytes taken by the bits.
+func (c *CompressedBitArray) MemSize() int {
+	if c == nil {
+		return 0
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	size := 8 * len(c.chunks)
+	for _, ct := range c.chunks {
+		if ct != nil {
+			size += 48 + 2*len(ct.array) + 8*len(ct.bitmap)
+		}
+	}
+	return size
+}
+
+// String returns the same representation as BitArray.String.
+func (c *CompressedBitArray) String() string {
+	return c.BitArray().String()
+}
+
+//----------------------------------------
+// containers
+
+// newContainer returns the smallest container holding the first length bits
+// of words; words past length are ignored.
+func newContainer(words []uint64, length int) *container {
+	bitmap := make([]uint64, chunkWords)
+	copy(bitmap, words)
+	if length < chunkBits {
+		// clear the bits past length
+		last := length / 64
+		if length%64 != 0 {
+			bitmap[last] &= uint64(1)<<uint(length%64) - 1
+			last++
+		}
+		for w := last; w < chunkWords; w++ {
+			bitmap[w] = 0
+		}
+	}
+	n := 0
+	for _, word := range bitmap {
+		n += bits.OnesCount64(word)
+	}
+	ct := &container{kind: bitmapContainer, bitmap: bitmap, n: n}
+	return ct.optimize(length)
+}
+
+// optimize returns the smallest container holding the bits of ct.
+func (ct *container) optimize(length int) *container {
+	switch {
+	case ct.n == 0:
+		return nil
+	case ct.n == length:
+		return &container{kind: fullContainer, n: length}
+	case ct.kind == bitmapContainer && ct.n <= maxArrayLen/2:
+		// converting back only at half the limit avoids converting back and
+		// forth while bits around the limit are set and unset
+		array := make([]uint16, 0, ct.n)
+		for w, word := range ct.bitmap {
+			for word != 0 {
+				array = append(array, uint16(w*64+bits.TrailingZeros64(word)))
+				word &= word - 1
+			}
+		}
+		return &container{kind: arrayContainer, array: array, n: ct.n}
+	default:
+		return ct
+	}
+}
+
+func (ct *container) get(off uint16) bool {
+	if ct == nil {
+		return false
+	}
+	switch ct.kind {
+	case arrayContainer:
+		i := sort.Search(len(ct.array), func(i int) bool { return ct.array[i] >= off })
+		return i < len(ct.array) && ct.array[i] == off
+	case bitmapContainer:
+		return ct.bitmap[off/64]&(uint64(1)<<(off%64)) != 0
+	default:
+		return true
+	}
+}
+
+// set sets the bit at off and returns the container, which may have changed
+// kind, holding the bits.
+func (ct *container) set(off uint16, v bool, length int) *container {
+	if ct.get(off) == v {
+		return ct
+	}
+	if ct == nil {
+		return &container{kind: arrayContainer, array: []uint16{off}, n: 1}
+	}
+	switch ct.kind {
+	case arrayContainer:
+		i := sort.Search(len(ct.array), func(i int) bool { return ct.array[i] >= off })
+		if v {
+			if len(ct.array) == maxArrayLen {
+				return ct.toBitmap().set(off, v, length)
+			}
+			ct.array = append(ct.array, 0)
+			copy(ct.array[i+1:], ct.array[i:])
+			ct.array[i] = off
+			ct.n++
+			if ct.n == length {
+				return &container{kind: fullContainer, n: length}
+			}
+			return ct
+		}
+		ct.array = append(ct.array[:i], ct.array[i+1:]...)
+		ct.n--
+		if ct.n == 0 {
+			return nil
+		}
+		return ct
+	case bitmapContainer:
+		if v {
+			ct.bitmap[off/64] |= uint64(1) << (off % 64)
+			ct.n++
+		} else {
+			ct.bitmap[off/64] &^= uint64(1) << (off % 64)
+			ct.n--
+		}
+		return ct.optimize(length)
+	default:
+		// v is false
+		bitmap := &container{kind: bitmapContainer, bitmap: ct.words(length), n: ct.n}
+		return bitmap.set(off, v, length)
+	}
+}
+
+func (ct *container) toBitmap() *container {
+	bitmap := make([]uint64, chunkWords)
+	for _, off := range ct.array {
+		bitmap[off/64] |= uint64(1) << (off % 64)
+	}
+	return &container{kind: bitmapContainer, bitmap: bitmap, n: ct.n}
+}
+
+// words returns the bits of the container, the first length of which are
+// meaningful, as a new bitmap.
+func (ct *container) words(length int) []uint64 {
+	words := make([]uint64, chunkWords)
+	if ct == nil {
+		return words
+	}
+	switch ct.kind {
+	case arrayContainer:
+		for _, off := range ct.array {
+			words[off/64] |= uint64(1) << (off % 64)
+		}
+	case bitmapContainer:
+		copy(words, ct.bitmap)
+	case fullContainer:
+		for w := 0; w < length/64; w++ {
+			words[w] = ^uint64(0)
+		}
+		if length%64 != 0 {
+			words[length/64] = uint64(1)<<uint(length%64) - 1
+		}
+	}
+	return words
+}
@@ -0,0 +1,118 @@
+package bits
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+)
+
+// randCompressedBitArray returns a compressed bit array and its uncompressed
+// copy, with about density of the bits set.
+func randCompressedBitArray(bits int, density float64) (*CompressedBitArray, *BitArray) {
+	c, bA := NewCompressedBitArray(bits), NewBitArray(bits)
+	for i := 0; i < bits; i++ {
+		if tmrand.Float64() < density {
+			c.SetIndex(i, true)
+			bA.SetIndex(i, true)
+		}
+	}
+	return c, bA
+}
+
+func assertSameBits(t *testing.T, expected *BitArray, c *CompressedBitArray) {
+	t.Helper()
+	require.Equal(t, expected.Size(), c.Size())
+	assert.Equal(t, expected.String(), c.String())
+	assert.Equal(t, expected.getTrueIndices(), c.TrueIndices())
+	assert.Equal(t, expected.IsEmpty(), c.IsEmpty())
+	assert.Equal(t, expected.IsFull(), c.IsFull())
+}
+
+func TestCompressedBitArrayMatchesBitArray(t *testing.T) {
+	for _, bits := range []int{1, 63, 100, chunkBits, chunkBits + 1, 3*chunkBits - 7} {
+		for _, density := range []float64{0, 0.01, 0.5, 0.99, 1} {
+			c1, bA1 := randCompressedBitArray(bits, density)
+			c2, bA2 := randCompressedBitArray(bits/2+1, 1-density)
+			assertSameBits(t, bA1, c1)
+			assertSameBits(t, bA1, CompressBitArray(bA1))
+			assert.Equal(t, bA1.String(), c1.BitArray().String())
+
+			assertSameBits(t, bA1.Or(bA2), c1.Or(c2))
+			assertSameBits(t, bA2.Or(bA1), c2.Or(c1))
+			assertSameBits(t, bA1.And(bA2), c1.And(c2))
+			assertSameBits(t, bA1.Sub(bA2), c1.Sub(c2))
+			assertSameBits(t, bA2.Sub(bA1), c2.Sub(c1))
+			assertSameBits(t, bA1.Not(), c1.Not())
+			assertSameBits(t, bA1.Copy(), c1.Copy())
+		}
+	}
+}
+
+func TestCompressedBitArrayContainers(t *testing.T) {
+	c := NewCompressedBitArray(2 * chunkBits)
+	assert.Nil(t, c.chunks[0])
+
+	// sparse chunks are arrays
+	for i := 0; i < maxArrayLen; i++ {
+		require.True(t, c.SetIndex(2*i, true))
+	}
+	assert.Equal(t, arrayContainer, c.chunks[0].kind)
+	require.True(t, c.SetIndex(1, true))
+	assert.Equal(t, bitmapContainer, c.chunks[0].kind)
+	assert.Equal(t, maxArrayLen+1, c.Count())
+
+	// full chunks take no memory
+	for i := 0; i < chunkBits; i++ {
+		c.SetIndex(i, true)
+	}
+	assert.Equal(t, fullContainer, c.chunks[0].kind)
+	assert.Nil(t, c.chunks[1])
+	assert.True(t, c.MemSize() < 100)
+
+	c.SetIndex(0, false)
+	assert.Equal(t, bitmapContainer, c.chunks[0].kind)
+	assert.False(t, c.GetIndex(0))
+	assert.True(t, c.GetIndex(1))
+	assert.Equal(t, chunkBits-1, c.Count())
+
+	// out of range
+	assert.False(t, c.SetIndex(2*chunkBits, true))
+	assert.False(t, c.GetIndex(-1))
+	var cNil *CompressedBitArray
+	assert.False(t, cNil.SetIndex(0, true))
+	assert.Nil(t, cNil.And(c))
+	assert.Equal(t, c.String(), cNil.Or(c).String())
+}
+
+func TestCompressedBitArrayPickRandom(t *testing.T) {
+	c := NewCompressedBitArray(10000)
+	_, ok := c.PickRandom()
+	assert.False(t, ok)
+	c.SetIndex(7777, true)
+	i, ok := c.PickRandom()
+	assert.True(t, ok)
+	assert.Equal(t, 7777, i)
+}
+
+func BenchmarkBitArraySetIndexParallel(b *testing.B) {
+	bA := NewBitArray(10000)
+	b.RunParallel(func(pb *testing.PB) {
+		i := tmrand.Intn(10000)
+		for pb.Next() {
+			bA.SetIndex(i, true)
+			bA.GetIndex(i)
+			i = (i + 997) % 10000
+		}
+	})
+}
+
+func BenchmarkCompressedBitArraySetIndex(b *testing.B) {
+	c := NewCompressedBitArray(10000)
+	for n := 0; n < b.N; n++ {
+		i := (n * 997) % 10000
+		c.SetIndex(i, n%3 != 0)
+	}
+}
@@ -88,3 +88,45 @@ func ParseLogLevel(lvl string, logger log.Logger, defaultLogLevelValue string) (
 
 	return log.NewFilter(logger, options...), nil
 }
+
+// SetModuleLogLevel returns the complex log level lvl (see ParseLogLevel) with
+// the level of the given module set to level, replacing any previous level of
+// the module. The module "*" sets the level of all other modules.
+//
+// Example:
+//		SetModuleLogLevel("p2p:error,*:info", "p2p", "debug") // "p2p:debug,*:info"
+func SetModuleLogLevel(lvl, module, level string) (string, error) {
+	if module == "" || strings.ContainsAny(module, ":,") {
+		return "", fmt.Errorf("invalid module %q", module)
+	}
+	if _, err := log.AllowLevel(level); err != nil {
+		return "", err
+	}
+
+	l := lvl
+	if l != "" && !strings.Contains(l, ":") {
+		l = defaultLogLevelKey + ":" + l
+	}
+
+	var items []string
+	if l != "" {
+		items = strings.Split(l, ",")
+	}
+	pair := module + ":" + level
+	replaced := false
+	for i, item := range items {
+		if strings.SplitN(item, ":", 2)[0] == module {
+			items[i] = pair
+			replaced = true
+		}
+	}
+	if !replaced {
+		// keep "*" last, as it reads as "all other modules"
+		if n := len(items); n > 0 && strings.HasPrefix(items[n-1], defaultLogLevelKey+":") {
+			items = append(items[:n-1], pair, items[n-1])
+		} else {
+			items = append(items, pair)
+		}
+	}
+	return strings.Join(items, ","), nil
+}
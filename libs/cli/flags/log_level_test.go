@@ -92,3 +92,32 @@ func TestParseLogLevel(t *testing.T) {
 		}
 	}
 }
+
+func TestSetModuleLogLevel(t *testing.T) {
+	cases := []struct {
+		lvl, module, level string
+		expected           string
+	}{
+		{"info", "p2p", "debug", "p2p:debug,*:info"},
+		{"", "p2p", "debug", "p2p:debug"},
+		{"p2p:error,*:info", "p2p", "debug", "p2p:debug,*:info"},
+		{"main:info,state:info,*:error", "consensus", "debug", "main:info,state:info,consensus:debug,*:error"},
+		{"main:info,state:info,*:error", "*", "info", "main:info,state:info,*:info"},
+		{"mempool:error", "p2p", "none", "mempool:error,p2p:none"},
+	}
+	for _, c := range cases {
+		lvl, err := tmflags.SetModuleLogLevel(c.lvl, c.module, c.level)
+		if err != nil {
+			t.Fatalf("SetModuleLogLevel(%q, %q, %q): %v", c.lvl, c.module, c.level, err)
+		}
+		if lvl != c.expected {
+			t.Errorf("SetModuleLogLevel(%q, %q, %q)\nwant '%s'\nhave '%s'", c.lvl, c.module, c.level, c.expected, lvl)
+		}
+	}
+
+	for _, c := range [][2]string{{"p2p", "some"}, {"", "info"}, {"p2p:x", "info"}} {
+		if _, err := tmflags.SetModuleLogLevel("info", c[0], c[1]); err == nil {
+			t.Errorf("Expected module %q and level %q to produce error", c[0], c[1])
+		}
+	}
+}
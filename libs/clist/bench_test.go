@@ -11,7 +11,7 @@ func BenchmarkDetaching(b *testing.B) {
 	nxt := start.Next()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		start.removed = true
+		start.removed = 1
 		start.DetachNext()
 		start.DetachPrev()
 		tmp := nxt
@@ -44,3 +44,71 @@ func BenchmarkPushBack(b *testing.B) {
 		lst.PushBack(i)
 	}
 }
+
+// The benchmarks below use a list of 10k elements, the size of a busy
+// mempool.
+const benchListLen = 10000
+
+func newBenchList() *CList {
+	lst := New()
+	for i := 0; i < benchListLen; i++ {
+		lst.PushBack(i)
+	}
+	return lst
+}
+
+// BenchmarkPushBackRemove replaces the front element of the list, like a
+// mempool which receives a tx for each tx it removes.
+func BenchmarkPushBackRemove(b *testing.B) {
+	lst := newBenchList()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := lst.Front()
+		lst.Remove(e)
+		e.DetachNext()
+		lst.PushBack(i)
+	}
+}
+
+// BenchmarkIterate traverses the whole list once per op.
+func BenchmarkIterate(b *testing.B) {
+	lst := newBenchList()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for e := lst.Front(); e != nil; e = e.Next() {
+			_ = e.Removed()
+		}
+	}
+}
+
+// BenchmarkIterateWhileWriting traverses the whole list from several
+// goroutines, like the mempool reactor's broadcast routines, while elements
+// are pushed and removed.
+func BenchmarkIterateWhileWriting(b *testing.B) {
+	lst := newBenchList()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			e := lst.Front()
+			lst.Remove(e)
+			e.DetachNext()
+			lst.PushBack(i)
+		}
+	}()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			for e := lst.Front(); e != nil; e = e.Next() {
+				_ = e.Removed()
+			}
+		}
+	})
+}
@@ -14,6 +14,8 @@ to ensure garbage collection of removed elements.
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"unsafe"
 )
 
 // MaxLength is the max allowed number of elements a linked list is
@@ -21,35 +23,38 @@ import (
 // If more elements are pushed to the list it will panic.
 const MaxLength = int(^uint(0) >> 1)
 
+// closedCh is returned by the wait channel methods when there is nothing to
+// wait for.
+var closedCh = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
+
 /*
 
 CElement is an element of a linked-list
 Traversal from a CElement is goroutine-safe.
 
-We can't avoid using WaitGroups or for-loops given the documentation
-spec without re-implementing the primitives that already exist in
-golang/sync. Notice that WaitGroup allows many go-routines to be
-simultaneously released, which is what we want. Mutex doesn't do
-this. RWMutex does this, but it's clumsy to use in the way that a
-WaitGroup would be used -- and we'd end up having two RWMutex's for
-prev/next each, which is doubly confusing.
+Traversal takes no locks: the links and the removed flag are read and written
+atomically, so that many goroutines (e.g. the mempool reactor's broadcast
+routines) can traverse the list while txs are added and removed.
 
-sync.Cond would be sort-of useful, but we don't need a write-lock in
-the for-loop. Use sync.Cond when you need serial access to the
-"condition". In our case our condition is if `next != nil || removed`,
-and there's no reason to serialize that condition for goroutines
-waiting on NextWait() (since it's just a read operation).
+Waiting for a link to be set uses channels, which are only created once
+someone waits and closed by the next SetNext/SetPrev/SetRemoved. The mutex of
+the element only guards these channels. As waiters check the links with the
+mutex held and writers take the mutex after setting a link, a waiter either
+sees the link or gets a channel which will be closed.
 
 */
 type CElement struct {
-	mtx        sync.RWMutex
-	prev       *CElement
-	prevWg     *sync.WaitGroup
-	prevWaitCh chan struct{}
-	next       *CElement
-	nextWg     *sync.WaitGroup
-	nextWaitCh chan struct{}
-	removed    bool
+	prev    unsafe.Pointer // *CElement
+	next    unsafe.Pointer // *CElement
+	removed uint32
+
+	mtx        sync.Mutex
+	prevWaitCh chan struct{} // nil if nobody waits
+	nextWaitCh chan struct{} // nil if nobody waits
 
 	Value interface{} // immutable
 }
@@ -58,17 +63,13 @@ type CElement struct {
 // May return nil iff CElement was tail and got removed.
 func (e *CElement) NextWait() *CElement {
 	for {
-		e.mtx.RLock()
-		next := e.next
-		nextWg := e.nextWg
-		removed := e.removed
-		e.mtx.RUnlock()
-
+		removed := e.Removed()
+		next := e.Next()
 		if next != nil || removed {
 			return next
 		}
 
-		nextWg.Wait()
+		<-e.NextWaitChan()
 		// e.next doesn't necessarily exist here.
 		// That's why we need to continue a for-loop.
 	}
@@ -78,135 +79,114 @@ func (e *CElement) NextWait() *CElement {
 // May return nil iff CElement was head and got removed.
 func (e *CElement) PrevWait() *CElement {
 	for {
-		e.mtx.RLock()
-		prev := e.prev
-		prevWg := e.prevWg
-		removed := e.removed
-		e.mtx.RUnlock()
-
+		removed := e.Removed()
+		prev := e.Prev()
 		if prev != nil || removed {
 			return prev
 		}
 
-		prevWg.Wait()
+		<-e.PrevWaitChan()
 	}
 }
 
 // PrevWaitChan can be used to wait until Prev becomes not nil. Once it does,
 // channel will be closed.
 func (e *CElement) PrevWaitChan() <-chan struct{} {
-	e.mtx.RLock()
-	defer e.mtx.RUnlock()
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
 
+	if e.Prev() != nil || e.Removed() {
+		return closedCh
+	}
+	if e.prevWaitCh == nil {
+		e.prevWaitCh = make(chan struct{})
+	}
 	return e.prevWaitCh
 }
 
 // NextWaitChan can be used to wait until Next becomes not nil. Once it does,
 // channel will be closed.
 func (e *CElement) NextWaitChan() <-chan struct{} {
-	e.mtx.RLock()
-	defer e.mtx.RUnlock()
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
 
+	if e.Next() != nil || e.Removed() {
+		return closedCh
+	}
+	if e.nextWaitCh == nil {
+		e.nextWaitCh = make(chan struct{})
+	}
 	return e.nextWaitCh
 }
 
 // Nonblocking, may return nil if at the end.
 func (e *CElement) Next() *CElement {
-	e.mtx.RLock()
-	val := e.next
-	e.mtx.RUnlock()
-	return val
+	return (*CElement)(atomic.LoadPointer(&e.next))
 }
 
 // Nonblocking, may return nil if at the end.
 func (e *CElement) Prev() *CElement {
-	e.mtx.RLock()
-	prev := e.prev
-	e.mtx.RUnlock()
-	return prev
+	return (*CElement)(atomic.LoadPointer(&e.prev))
 }
 
 func (e *CElement) Removed() bool {
-	e.mtx.RLock()
-	isRemoved := e.removed
-	e.mtx.RUnlock()
-	return isRemoved
+	return atomic.LoadUint32(&e.removed) == 1
 }
 
 func (e *CElement) DetachNext() {
-	e.mtx.Lock()
-	if !e.removed {
-		e.mtx.Unlock()
+	if !e.Removed() {
 		panic("DetachNext() must be called after Remove(e)")
 	}
-	e.next = nil
-	e.mtx.Unlock()
+	atomic.StorePointer(&e.next, nil)
 }
 
 func (e *CElement) DetachPrev() {
-	e.mtx.Lock()
-	if !e.removed {
-		e.mtx.Unlock()
+	if !e.Removed() {
 		panic("DetachPrev() must be called after Remove(e)")
 	}
-	e.prev = nil
-	e.mtx.Unlock()
+	atomic.StorePointer(&e.prev, nil)
 }
 
 // NOTE: This function needs to be safe for
-// concurrent goroutines waiting on nextWg.
+// concurrent goroutines waiting on NextWaitChan.
 func (e *CElement) SetNext(newNext *CElement) {
-	e.mtx.Lock()
-
-	oldNext := e.next
-	e.next = newNext
-	if oldNext != nil && newNext == nil {
-		// See https://golang.org/pkg/sync/:
-		//
-		// If a WaitGroup is reused to wait for several independent sets of
-		// events, new Add calls must happen after all previous Wait calls have
-		// returned.
-		e.nextWg = waitGroup1() // WaitGroups are difficult to re-use.
-		e.nextWaitCh = make(chan struct{})
-	}
-	if oldNext == nil && newNext != nil {
-		e.nextWg.Done()
-		close(e.nextWaitCh)
+	atomic.StorePointer(&e.next, unsafe.Pointer(newNext))
+	if newNext != nil {
+		e.mtx.Lock()
+		if e.nextWaitCh != nil {
+			close(e.nextWaitCh)
+			e.nextWaitCh = nil
+		}
+		e.mtx.Unlock()
 	}
-	e.mtx.Unlock()
 }
 
 // NOTE: This function needs to be safe for
-// concurrent goroutines waiting on prevWg
+// concurrent goroutines waiting on PrevWaitChan.
 func (e *CElement) SetPrev(newPrev *CElement) {
-	e.mtx.Lock()
-
-	oldPrev := e.prev
-	e.prev = newPrev
-	if oldPrev != nil && newPrev == nil {
-		e.prevWg = waitGroup1() // WaitGroups are difficult to re-use.
-		e.prevWaitCh = make(chan struct{})
-	}
-	if oldPrev == nil && newPrev != nil {
-		e.prevWg.Done()
-		close(e.prevWaitCh)
+	atomic.StorePointer(&e.prev, unsafe.Pointer(newPrev))
+	if newPrev != nil {
+		e.mtx.Lock()
+		if e.prevWaitCh != nil {
+			close(e.prevWaitCh)
+			e.prevWaitCh = nil
+		}
+		e.mtx.Unlock()
 	}
-	e.mtx.Unlock()
 }
 
 func (e *CElement) SetRemoved() {
-	e.mtx.Lock()
-
-	e.removed = true
+	atomic.StoreUint32(&e.removed, 1)
 
 	// This wakes up anyone waiting in either direction.
-	if e.prev == nil {
-		e.prevWg.Done()
+	e.mtx.Lock()
+	if e.prevWaitCh != nil {
 		close(e.prevWaitCh)
+		e.prevWaitCh = nil
 	}
-	if e.next == nil {
-		e.nextWg.Done()
+	if e.nextWaitCh != nil {
 		close(e.nextWaitCh)
+		e.nextWaitCh = nil
 	}
 	e.mtx.Unlock()
 }
@@ -217,24 +197,25 @@ func (e *CElement) SetRemoved() {
 // The zero value for CList is an empty list ready to use.
 // Operations are goroutine-safe.
 // Panics if length grows beyond the max.
+//
+// PushBack and Remove are serialized by the list's mutex, while Len, Front,
+// Back and traversal take no locks (see CElement).
 type CList struct {
-	mtx    sync.RWMutex
-	wg     *sync.WaitGroup
-	waitCh chan struct{}
-	head   *CElement // first element
-	tail   *CElement // last element
-	len    int       // list length
-	maxLen int       // max list length
+	len    int64          // list length; first for 64-bit alignment of atomic ops
+	head   unsafe.Pointer // first element
+	tail   unsafe.Pointer // last element
+	mtx    sync.Mutex
+	waitCh chan struct{} // nil if nobody waits
+	maxLen int           // max list length
 }
 
 func (l *CList) Init() *CList {
 	l.mtx.Lock()
 
-	l.wg = waitGroup1()
-	l.waitCh = make(chan struct{})
-	l.head = nil
-	l.tail = nil
-	l.len = 0
+	l.waitCh = nil
+	atomic.StorePointer(&l.head, nil)
+	atomic.StorePointer(&l.tail, nil)
+	atomic.StoreInt64(&l.len, 0)
 	l.mtx.Unlock()
 	return l
 }
@@ -251,53 +232,34 @@ func newWithMax(maxLength int) *CList {
 }
 
 func (l *CList) Len() int {
-	l.mtx.RLock()
-	len := l.len
-	l.mtx.RUnlock()
-	return len
+	return int(atomic.LoadInt64(&l.len))
 }
 
 func (l *CList) Front() *CElement {
-	l.mtx.RLock()
-	head := l.head
-	l.mtx.RUnlock()
-	return head
+	return (*CElement)(atomic.LoadPointer(&l.head))
 }
 
 func (l *CList) FrontWait() *CElement {
 	// Loop until the head is non-nil else wait and try again
 	for {
-		l.mtx.RLock()
-		head := l.head
-		wg := l.wg
-		l.mtx.RUnlock()
-
-		if head != nil {
+		if head := l.Front(); head != nil {
 			return head
 		}
-		wg.Wait()
+		<-l.WaitChan()
 		// NOTE: If you think l.head exists here, think harder.
 	}
 }
 
 func (l *CList) Back() *CElement {
-	l.mtx.RLock()
-	back := l.tail
-	l.mtx.RUnlock()
-	return back
+	return (*CElement)(atomic.LoadPointer(&l.tail))
 }
 
 func (l *CList) BackWait() *CElement {
 	for {
-		l.mtx.RLock()
-		tail := l.tail
-		wg := l.wg
-		l.mtx.RUnlock()
-
-		if tail != nil {
+		if tail := l.Back(); tail != nil {
 			return tail
 		}
-		wg.Wait()
+		<-l.WaitChan()
 		// l.tail doesn't necessarily exist here.
 		// That's why we need to continue a for-loop.
 	}
@@ -309,6 +271,12 @@ func (l *CList) WaitChan() <-chan struct{} {
 	l.mtx.Lock()
 	defer l.mtx.Unlock()
 
+	if l.Front() != nil {
+		return closedCh
+	}
+	if l.waitCh == nil {
+		l.waitCh = make(chan struct{})
+	}
 	return l.waitCh
 }
 
@@ -316,36 +284,34 @@ func (l *CList) WaitChan() <-chan struct{} {
 func (l *CList) PushBack(v interface{}) *CElement {
 	l.mtx.Lock()
 
-	// Construct a new element
-	e := &CElement{
-		prev:       nil,
-		prevWg:     waitGroup1(),
-		prevWaitCh: make(chan struct{}),
-		next:       nil,
-		nextWg:     waitGroup1(),
-		nextWaitCh: make(chan struct{}),
-		removed:    false,
-		Value:      v,
+	length := l.Len()
+	if length >= l.maxLen {
+		l.mtx.Unlock()
+		panic(fmt.Sprintf("clist: maximum length list reached %d", l.maxLen))
 	}
 
-	// Release waiters on FrontWait/BackWait maybe
-	if l.len == 0 {
-		l.wg.Done()
-		close(l.waitCh)
-	}
-	if l.len >= l.maxLen {
-		panic(fmt.Sprintf("clist: maximum length list reached %d", l.maxLen))
+	// Construct a new element. Nobody else can see it yet, so its links may
+	// be set directly.
+	tail := l.Back()
+	e := &CElement{
+		prev:  unsafe.Pointer(tail),
+		Value: v,
 	}
-	l.len++
+	atomic.StoreInt64(&l.len, int64(length+1))
 
 	// Modify the tail
-	if l.tail == nil {
-		l.head = e
-		l.tail = e
+	if tail == nil {
+		atomic.StorePointer(&l.head, unsafe.Pointer(e))
+		atomic.StorePointer(&l.tail, unsafe.Pointer(e))
 	} else {
-		e.SetPrev(l.tail) // We must init e first.
-		l.tail.SetNext(e) // This will make e accessible.
-		l.tail = e        // Update the list.
+		tail.SetNext(e) // This will make e accessible.
+		atomic.StorePointer(&l.tail, unsafe.Pointer(e))
+	}
+
+	// Release waiters on FrontWait/BackWait maybe
+	if l.waitCh != nil {
+		close(l.waitCh)
+		l.waitCh = nil
 	}
 	l.mtx.Unlock()
 	return e
@@ -359,49 +325,37 @@ func (l *CList) Remove(e *CElement) interface{} {
 	prev := e.Prev()
 	next := e.Next()
 
-	if l.head == nil || l.tail == nil {
+	if l.Front() == nil || l.Back() == nil {
 		l.mtx.Unlock()
 		panic("Remove(e) on empty CList")
 	}
-	if prev == nil && l.head != e {
+	if prev == nil && l.Front() != e {
 		l.mtx.Unlock()
 		panic("Remove(e) with false head")
 	}
-	if next == nil && l.tail != e {
+	if next == nil && l.Back() != e {
 		l.mtx.Unlock()
 		panic("Remove(e) with false tail")
 	}
 
-	// If we're removing the only item, make CList FrontWait/BackWait wait.
-	if l.len == 1 {
-		l.wg = waitGroup1() // WaitGroups are difficult to re-use.
-		l.waitCh = make(chan struct{})
-	}
-
 	// Update l.len
-	l.len--
+	atomic.AddInt64(&l.len, -1)
 
 	// Connect next/prev and set head/tail
 	if prev == nil {
-		l.head = next
+		atomic.StorePointer(&l.head, unsafe.Pointer(next))
 	} else {
 		prev.SetNext(next)
 	}
 	if next == nil {
-		l.tail = prev
+		atomic.StorePointer(&l.tail, unsafe.Pointer(prev))
 	} else {
 		next.SetPrev(prev)
 	}
 
-	// Set .Done() on e, otherwise waiters will wait forever.
+	// Wake up waiters on e, otherwise they will wait forever.
 	e.SetRemoved()
 
 	l.mtx.Unlock()
 	return e.Value
 }
-
-func waitGroup1() (wg *sync.WaitGroup) {
-	wg = &sync.WaitGroup{}
-	wg.Add(1)
-	return
-}
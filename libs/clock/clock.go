@@ -0,0 +1,72 @@
+// Package clock abstracts the time functions used by timer based components,
+// so that tests can control time with a Fake clock instead of sleeping.
+package clock
+
+import (
+	"time"
+)
+
+// Clock tells the time and creates timers and tickers.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+
+	// NewTimer returns a timer sending the time on its channel after d.
+	NewTimer(d time.Duration) Timer
+	// AfterFunc returns a timer calling f in its own goroutine after d.
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker returns a ticker sending the time on its channel every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the interface of time.Timer.
+type Timer interface {
+	// C returns the channel the time is sent on. It is nil for timers created
+	// with AfterFunc.
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the interface of time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// New returns the real clock, backed by the time package.
+func New() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                  { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration { return time.Since(t) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
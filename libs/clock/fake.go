@@ -0,0 +1,196 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when Advance is called. Timers and
+// tickers fire during Advance, in the order of their deadlines; functions
+// passed to AfterFunc are called by Advance, after the clock has been
+// advanced, so that their effects are visible once Advance returns.
+//
+// A timer reset with a non-positive duration fires immediately, as with the
+// time package.
+type Fake struct {
+	mtx     sync.Mutex
+	cond    *sync.Cond // signaled when waiters change
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+var _ Clock = (*Fake)(nil)
+
+// NewFake returns a fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mtx)
+	return f
+}
+
+// fakeWaiter is a timer or a ticker (if period is not 0).
+type fakeWaiter struct {
+	fake   *Fake
+	when   time.Time
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+}
+
+// Now returns the time of the clock.
+func (f *Fake) Now() time.Time {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.now
+}
+
+// Since returns the time elapsed on the clock since t.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTimer implements Clock.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{fake: f, ch: make(chan time.Time, 1)}
+	w.Reset(d)
+	return w
+}
+
+// AfterFunc implements Clock.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	w := &fakeWaiter{fake: f, fn: fn}
+	w.Reset(d)
+	return w
+}
+
+// NewTicker implements Clock. It panics if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{fake: f, period: d, ch: make(chan time.Time, 1)}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	w.when = f.now.Add(d)
+	f.add(w)
+	return fakeTicker{w}
+}
+
+// Advance moves the clock forward by d, firing the timers and tickers due
+// meanwhile.
+func (f *Fake) Advance(d time.Duration) {
+	f.mtx.Lock()
+	target := f.now.Add(d)
+	var fns []func()
+	for {
+		w := f.next(target)
+		if w == nil {
+			break
+		}
+		f.now = w.when
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			f.remove(w)
+		}
+		if fn := w.fire(f.now); fn != nil {
+			fns = append(fns, fn)
+		}
+	}
+	f.now = target
+	f.mtx.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// BlockUntil blocks until at least n timers and tickers are pending, e.g. to
+// wait for a goroutine to schedule a timeout before advancing the clock.
+func (f *Fake) BlockUntil(n int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// next returns the pending waiter with the earliest deadline not after t.
+func (f *Fake) next(t time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range f.waiters {
+		if !w.when.After(t) && (next == nil || w.when.Before(next.when)) {
+			next = w
+		}
+	}
+	return next
+}
+
+func (f *Fake) add(w *fakeWaiter) {
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+// remove removes w and returns whether it was pending.
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, o := range f.waiters {
+		if o == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fire sends now on the waiter's channel, unless it is full, or returns the
+// function to call.
+func (w *fakeWaiter) fire(now time.Time) func() {
+	if w.fn != nil {
+		return w.fn
+	}
+	select {
+	case w.ch <- now:
+	default:
+	}
+	return nil
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// fakeTicker adapts a waiter to Ticker.
+type fakeTicker struct {
+	w *fakeWaiter
+}
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.ch }
+func (t fakeTicker) Stop()               { t.w.Stop() }
+
+// Stop implements Timer.
+func (w *fakeWaiter) Stop() bool {
+	w.fake.mtx.Lock()
+	defer w.fake.mtx.Unlock()
+	return w.fake.remove(w)
+}
+
+// Reset implements Timer.
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	f := w.fake
+	f.mtx.Lock()
+	active := f.remove(w)
+	if d > 0 {
+		w.when = f.now.Add(d)
+		f.add(w)
+		f.mtx.Unlock()
+		return active
+	}
+	w.when = f.now
+	fn := w.fire(f.now)
+	f.mtx.Unlock()
+	if fn != nil {
+		go fn()
+	}
+	return active
+}
@@ -0,0 +1,82 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTimer(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := NewFake(start)
+
+	timer := c.NewTimer(time.Second)
+	c.Advance(999 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	c.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	c.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+	assert.Equal(t, time.Hour+time.Second, c.Since(start))
+}
+
+func TestFakeAfterFunc(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	var fired []int
+	c.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	c.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+	stopped := c.AfterFunc(time.Second, func() { fired = append(fired, 0) })
+	assert.True(t, stopped.Stop())
+
+	c.Advance(3 * time.Second)
+	assert.Equal(t, []int{1, 2}, fired)
+}
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Unix(0, 0)
+	c := NewFake(start)
+	ticker := c.NewTicker(time.Second)
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+	// ticks are dropped if the channel is full
+	c.Advance(3 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+
+	ticker.Stop()
+	c.Advance(time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("stopped ticker ticked")
+	default:
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	c := NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		c.BlockUntil(1)
+		close(done)
+	}()
+	c.NewTimer(time.Second)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("BlockUntil didn't return")
+	}
+}
@@ -2,6 +2,7 @@ package cmap
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -60,10 +61,40 @@ func TestContains(t *testing.T) {
 func BenchmarkCMapHas(b *testing.B) {
 	m := NewCMap()
 	for i := 0; i < 1000; i++ {
-		m.Set(string(i), i)
+		m.Set(strconv.Itoa(i), i)
 	}
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		m.Has(string(i))
+		m.Has(strconv.Itoa(i))
 	}
 }
+
+// benchmarkParallel has parallel goroutines get, and 1 in 10 times set, keys
+// of m, to measure lock contention. Compare with -cpu 1,4,16.
+func benchmarkParallel(b *testing.B, m interface {
+	Set(string, interface{})
+	Get(string) interface{}
+}) {
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		m.Set(keys[i], i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%10 == 0 {
+				m.Set(key, i)
+			} else {
+				m.Get(key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkCMapParallel(b *testing.B) {
+	benchmarkParallel(b, NewCMap())
+}
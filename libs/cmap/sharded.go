@@ -0,0 +1,152 @@
+package cmap
+
+import "sync"
+
+const defaultShards = 32
+
+// ShardedMap is a goroutine-safe map with the API of CMap, for maps accessed
+// concurrently by many goroutines. Keys are spread over shards, each with its
+// own read-write lock, so that goroutines accessing different keys, or only
+// reading, don't wait for each other.
+//
+// Size, Keys, Values, Range and Snapshot lock the shards one at a time: they
+// see each shard at a different moment, not the whole map at once.
+type ShardedMap struct {
+	shards []shard
+}
+
+type shard struct {
+	mtx sync.RWMutex
+	m   map[string]interface{}
+}
+
+// NewShardedMap returns a map with the given number of shards, or with 32 if
+// shards is not positive. Maps with few keys need few shards.
+func NewShardedMap(shards int) *ShardedMap {
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	sm := &ShardedMap{shards: make([]shard, shards)}
+	for i := range sm.shards {
+		sm.shards[i].m = make(map[string]interface{})
+	}
+	return sm
+}
+
+// shard returns the shard of key, by FNV-1a hash.
+func (sm *ShardedMap) shard(key string) *shard {
+	if len(sm.shards) == 1 {
+		return &sm.shards[0]
+	}
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return &sm.shards[hash%uint32(len(sm.shards))]
+}
+
+func (sm *ShardedMap) Set(key string, value interface{}) {
+	s := sm.shard(key)
+	s.mtx.Lock()
+	s.m[key] = value
+	s.mtx.Unlock()
+}
+
+func (sm *ShardedMap) Get(key string) interface{} {
+	s := sm.shard(key)
+	s.mtx.RLock()
+	val := s.m[key]
+	s.mtx.RUnlock()
+	return val
+}
+
+func (sm *ShardedMap) Has(key string) bool {
+	s := sm.shard(key)
+	s.mtx.RLock()
+	_, ok := s.m[key]
+	s.mtx.RUnlock()
+	return ok
+}
+
+func (sm *ShardedMap) Delete(key string) {
+	s := sm.shard(key)
+	s.mtx.Lock()
+	delete(s.m, key)
+	s.mtx.Unlock()
+}
+
+func (sm *ShardedMap) Size() int {
+	size := 0
+	for i := range sm.shards {
+		s := &sm.shards[i]
+		s.mtx.RLock()
+		size += len(s.m)
+		s.mtx.RUnlock()
+	}
+	return size
+}
+
+func (sm *ShardedMap) Clear() {
+	for i := range sm.shards {
+		s := &sm.shards[i]
+		s.mtx.Lock()
+		s.m = make(map[string]interface{})
+		s.mtx.Unlock()
+	}
+}
+
+func (sm *ShardedMap) Keys() []string {
+	keys := make([]string, 0, sm.Size())
+	sm.Range(func(key string, _ interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+func (sm *ShardedMap) Values() []interface{} {
+	items := make([]interface{}, 0, sm.Size())
+	sm.Range(func(_ string, value interface{}) bool {
+		items = append(items, value)
+		return true
+	})
+	return items
+}
+
+// Range calls fn for each key and value, until fn returns false. fn is called
+// on a snapshot of each shard, without holding its lock, so it may use the
+// map; the changes it makes may or may not be seen by the rest of the range.
+func (sm *ShardedMap) Range(fn func(key string, value interface{}) bool) {
+	var keys []string
+	var values []interface{}
+	for i := range sm.shards {
+		s := &sm.shards[i]
+		s.mtx.RLock()
+		keys, values = keys[:0], values[:0]
+		for k, v := range s.m {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+		s.mtx.RUnlock()
+		for j, k := range keys {
+			if !fn(k, values[j]) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot returns a copy of the map.
+func (sm *ShardedMap) Snapshot() map[string]interface{} {
+	snapshot := make(map[string]interface{}, sm.Size())
+	for i := range sm.shards {
+		s := &sm.shards[i]
+		s.mtx.RLock()
+		for k, v := range s.m {
+			snapshot[k] = v
+		}
+		s.mtx.RUnlock()
+	}
+	return snapshot
+}
@@ -0,0 +1,91 @@
+package cmap
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedMap(t *testing.T) {
+	for _, shards := range []int{0, 1, 7} {
+		sm := NewShardedMap(shards)
+		for i := 1; i <= 100; i++ {
+			sm.Set(fmt.Sprintf("key%d", i), i)
+		}
+		assert.Equal(t, 100, sm.Size())
+		assert.Len(t, sm.Keys(), 100)
+		assert.Len(t, sm.Values(), 100)
+		for i := 1; i <= 100; i++ {
+			key := fmt.Sprintf("key%d", i)
+			assert.True(t, sm.Has(key))
+			assert.Equal(t, i, sm.Get(key))
+		}
+		assert.False(t, sm.Has("key0"))
+		assert.Nil(t, sm.Get("key0"))
+
+		sm.Delete("key1")
+		assert.False(t, sm.Has("key1"))
+		assert.Equal(t, 99, sm.Size())
+
+		sm.Clear()
+		assert.Equal(t, 0, sm.Size())
+		assert.Empty(t, sm.Keys())
+	}
+}
+
+func TestShardedMapRange(t *testing.T) {
+	sm := NewShardedMap(4)
+	for i := 0; i < 10; i++ {
+		sm.Set(strconv.Itoa(i), i)
+	}
+
+	snapshot := sm.Snapshot()
+	require.Len(t, snapshot, 10)
+	sum := 0
+	sm.Range(func(key string, value interface{}) bool {
+		assert.Equal(t, snapshot[key], value)
+		sum += value.(int)
+		sm.Delete(key) // doesn't deadlock
+		return true
+	})
+	assert.Equal(t, 45, sum)
+	assert.Equal(t, 0, sm.Size())
+	assert.Len(t, snapshot, 10, "the snapshot is a copy")
+
+	for i := 0; i < 10; i++ {
+		sm.Set(strconv.Itoa(i), i)
+	}
+	n := 0
+	sm.Range(func(string, interface{}) bool {
+		n++
+		return n < 3
+	})
+	assert.Equal(t, 3, n)
+}
+
+func TestShardedMapConcurrent(t *testing.T) {
+	sm := NewShardedMap(0)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("%d-%d", g, i)
+				sm.Set(key, i)
+				assert.Equal(t, i, sm.Get(key))
+				sm.Range(func(string, interface{}) bool { return true })
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 800, sm.Size())
+}
+
+func BenchmarkShardedMapParallel(b *testing.B) {
+	benchmarkParallel(b, NewShardedMap(0))
+}
@@ -0,0 +1,183 @@
+// Package db extends the databases of tm-db with point-in-time snapshots and
+// transactions (see NewSnapshot and NewTxn).
+package db
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// ErrSnapshotsUnsupported is returned by NewSnapshot and NewTxn for a database
+// whose backend doesn't support snapshots.
+var ErrSnapshotsUnsupported = errors.New("database doesn't support snapshots")
+
+// ErrReleased is returned by the methods of a released snapshot.
+var ErrReleased = errors.New("snapshot released")
+
+// Snapshot is a read-only view of a database at a point in time. Writes made
+// to the database after the snapshot was taken are not visible through it.
+type Snapshot interface {
+	Get(key []byte) ([]byte, error)
+	Has(key []byte) (bool, error)
+	Iterator(start, end []byte) (dbm.Iterator, error)
+	ReverseIterator(start, end []byte) (dbm.Iterator, error)
+
+	// Release releases the resources held by the snapshot. Iterators must be
+	// closed before.
+	Release()
+}
+
+// Snapshotter is implemented by databases taking snapshots natively, e.g.
+// wrappers of other databases.
+type Snapshotter interface {
+	NewSnapshot() (Snapshot, error)
+}
+
+// NewSnapshot takes a snapshot of db. Snapshots are supported by:
+//
+//   - goleveldb, natively
+//   - memdb, by copying the database
+//   - any database implementing Snapshotter
+//
+// For other backends (cleveldb, boltdb and rocksdb), ErrSnapshotsUnsupported
+// is returned.
+func NewSnapshot(db dbm.DB) (Snapshot, error) {
+	switch db := db.(type) {
+	case Snapshotter:
+		return db.NewSnapshot()
+	case *dbm.GoLevelDB:
+		s, err := db.DB().GetSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		return &goLevelDBSnapshot{s: s}, nil
+	case *dbm.MemDB:
+		return newMemDBSnapshot(db)
+	default:
+		return nil, ErrSnapshotsUnsupported
+	}
+}
+
+//----------------------------------------
+// goleveldb
+
+type goLevelDBSnapshot struct {
+	s *leveldb.Snapshot
+}
+
+func (s *goLevelDBSnapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.s.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	} else if err == leveldb.ErrSnapshotReleased {
+		return nil, ErrReleased
+	}
+	return value, err
+}
+
+func (s *goLevelDBSnapshot) Has(key []byte) (bool, error) {
+	has, err := s.s.Has(key, nil)
+	if err == leveldb.ErrSnapshotReleased {
+		return false, ErrReleased
+	}
+	return has, err
+}
+
+func (s *goLevelDBSnapshot) Iterator(start, end []byte) (dbm.Iterator, error) {
+	return newGoLevelDBIterator(s.s.NewIterator(&util.Range{Start: start, Limit: end}, nil), start, end, false), nil
+}
+
+func (s *goLevelDBSnapshot) ReverseIterator(start, end []byte) (dbm.Iterator, error) {
+	return newGoLevelDBIterator(s.s.NewIterator(&util.Range{Start: start, Limit: end}, nil), start, end, true), nil
+}
+
+func (s *goLevelDBSnapshot) Release() {
+	s.s.Release()
+}
+
+// goLevelDBIterator iterates over a source limited to [start, end).
+type goLevelDBIterator struct {
+	source     iterator.Iterator
+	start, end []byte
+	isReverse  bool
+}
+
+var _ dbm.Iterator = (*goLevelDBIterator)(nil)
+
+func newGoLevelDBIterator(source iterator.Iterator, start, end []byte, isReverse bool) *goLevelDBIterator {
+	if isReverse {
+		source.Last()
+	} else {
+		source.First()
+	}
+	return &goLevelDBIterator{source: source, start: start, end: end, isReverse: isReverse}
+}
+
+func (itr *goLevelDBIterator) Domain() ([]byte, []byte) { return itr.start, itr.end }
+func (itr *goLevelDBIterator) Valid() bool              { return itr.source.Valid() }
+func (itr *goLevelDBIterator) Error() error             { return itr.source.Error() }
+func (itr *goLevelDBIterator) Close()                   { itr.source.Release() }
+
+func (itr *goLevelDBIterator) Next() {
+	itr.assertIsValid()
+	if itr.isReverse {
+		itr.source.Prev()
+	} else {
+		itr.source.Next()
+	}
+}
+
+// Key returns a copy of the current key.
+func (itr *goLevelDBIterator) Key() []byte {
+	itr.assertIsValid()
+	return append([]byte{}, itr.source.Key()...)
+}
+
+// Value returns a copy of the current value.
+func (itr *goLevelDBIterator) Value() []byte {
+	itr.assertIsValid()
+	return append([]byte{}, itr.source.Value()...)
+}
+
+func (itr *goLevelDBIterator) assertIsValid() {
+	if !itr.Valid() {
+		panic("goLevelDBIterator is invalid")
+	}
+}
+
+//----------------------------------------
+// memdb
+
+type memDBSnapshot struct {
+	*dbm.MemDB
+}
+
+// newMemDBSnapshot copies db while holding its lock, so that the copy is
+// consistent with concurrent writes. Since the reads of MemDB take the lock
+// too, the items are read from its map directly.
+func newMemDBSnapshot(db *dbm.MemDB) (*memDBSnapshot, error) {
+	items := reflect.ValueOf(db).Elem().FieldByName("db")
+	if items.Kind() != reflect.Map || items.Type().Key().Kind() != reflect.String ||
+		items.Type().Elem() != reflect.TypeOf([]byte(nil)) {
+		return nil, ErrSnapshotsUnsupported
+	}
+
+	mtx := db.Mutex()
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	snapshot := dbm.NewMemDB()
+	for itr := items.MapRange(); itr.Next(); {
+		snapshot.SetNoLock([]byte(itr.Key().String()), append([]byte{}, itr.Value().Bytes()...))
+	}
+	return &memDBSnapshot{snapshot}, nil
+}
+
+func (s *memDBSnapshot) Release() {
+	s.MemDB.Close()
+}
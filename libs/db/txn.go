@@ -0,0 +1,329 @@
+package db
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/libs/failpoint"
+)
+
+var (
+	// ErrTxnTooLarge is returned by Set and Delete of a transaction if the
+	// write would exceed the limits of the transaction. The write is not made,
+	// but the transaction can still be committed.
+	ErrTxnTooLarge = errors.New("transaction too large")
+	// ErrTxnDone is returned by the methods of a transaction which has been
+	// committed or rolled back.
+	ErrTxnDone = errors.New("transaction already committed or rolled back")
+	// ErrKeyEmpty is returned for an empty key.
+	ErrKeyEmpty = errors.New("key cannot be empty")
+)
+
+// TxnOption sets an optional parameter of a transaction.
+type TxnOption func(*Txn)
+
+// TxnMaxOps limits the number of keys a transaction writes.
+func TxnMaxOps(n int) TxnOption {
+	return func(txn *Txn) { txn.maxOps = n }
+}
+
+// TxnMaxBytes limits the size of the keys and values a transaction writes.
+func TxnMaxBytes(n int) TxnOption {
+	return func(txn *Txn) { txn.maxBytes = n }
+}
+
+type txnOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+func (op *txnOp) size() int {
+	return len(op.key) + len(op.value)
+}
+
+/*
+Txn buffers writes to a database until they are committed atomically, in a
+single batch. Reads see the database as it was when the transaction began
+(see NewSnapshot), with the writes of the transaction applied; writes made to
+the database by others meanwhile are not visible, and are overwritten by the
+commit if the transaction writes the same keys.
+
+	txn, err := NewTxn(db, TxnMaxBytes(64<<20))
+	if err != nil {
+		return err
+	}
+	defer txn.Rollback() // no-op once committed
+	...
+	return txn.Commit()
+
+A Txn is not safe for concurrent use.
+*/
+type Txn struct {
+	db       dbm.DB
+	snapshot Snapshot
+	ops      map[string]*txnOp
+	bytes    int
+	done     bool
+
+	maxOps   int // 0 means unlimited
+	maxBytes int // 0 means unlimited
+}
+
+// NewTxn begins a transaction on db. ErrSnapshotsUnsupported is returned if
+// db doesn't support snapshots.
+func NewTxn(db dbm.DB, options ...TxnOption) (*Txn, error) {
+	snapshot, err := NewSnapshot(db)
+	if err != nil {
+		return nil, err
+	}
+	txn := &Txn{
+		db:       db,
+		snapshot: snapshot,
+		ops:      make(map[string]*txnOp),
+	}
+	for _, option := range options {
+		option(txn)
+	}
+	return txn, nil
+}
+
+// Get returns the value of key, or nil if it doesn't exist.
+func (txn *Txn) Get(key []byte) ([]byte, error) {
+	if txn.done {
+		return nil, ErrTxnDone
+	}
+	if op, ok := txn.ops[string(key)]; ok {
+		if op.delete {
+			return nil, nil
+		}
+		return op.value, nil
+	}
+	return txn.snapshot.Get(key)
+}
+
+// Has returns whether key exists.
+func (txn *Txn) Has(key []byte) (bool, error) {
+	if txn.done {
+		return false, ErrTxnDone
+	}
+	if op, ok := txn.ops[string(key)]; ok {
+		return !op.delete, nil
+	}
+	return txn.snapshot.Has(key)
+}
+
+// Set sets the value of key. key and value are copied.
+func (txn *Txn) Set(key, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	return txn.write(&txnOp{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+}
+
+// Delete deletes key.
+func (txn *Txn) Delete(key []byte) error {
+	return txn.write(&txnOp{key: append([]byte{}, key...), delete: true})
+}
+
+func (txn *Txn) write(op *txnOp) error {
+	if txn.done {
+		return ErrTxnDone
+	}
+	if len(op.key) == 0 {
+		return ErrKeyEmpty
+	}
+	ops, size := len(txn.ops), txn.bytes+op.size()
+	if old, ok := txn.ops[string(op.key)]; ok {
+		size -= old.size()
+	} else {
+		ops++
+	}
+	if (txn.maxOps > 0 && ops > txn.maxOps) || (txn.maxBytes > 0 && size > txn.maxBytes) {
+		return ErrTxnTooLarge
+	}
+	txn.ops[string(op.key)] = op
+	txn.bytes = size
+	return nil
+}
+
+// Size returns the number of keys written by the transaction and the size of
+// these keys and their values.
+func (txn *Txn) Size() (ops int, bytes int) {
+	return len(txn.ops), txn.bytes
+}
+
+// Iterator returns an iterator over the domain [start, end), with the writes
+// of the transaction applied. The transaction must not be written to until
+// the iterator is closed.
+func (txn *Txn) Iterator(start, end []byte) (dbm.Iterator, error) {
+	return txn.iterator(start, end, false)
+}
+
+// ReverseIterator is like Iterator, in reverse order.
+func (txn *Txn) ReverseIterator(start, end []byte) (dbm.Iterator, error) {
+	return txn.iterator(start, end, true)
+}
+
+func (txn *Txn) iterator(start, end []byte, reverse bool) (dbm.Iterator, error) {
+	if txn.done {
+		return nil, ErrTxnDone
+	}
+	var (
+		parent dbm.Iterator
+		err    error
+	)
+	if reverse {
+		parent, err = txn.snapshot.ReverseIterator(start, end)
+	} else {
+		parent, err = txn.snapshot.Iterator(start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ops := txn.sortedOps(start, end)
+	if reverse {
+		for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+			ops[i], ops[j] = ops[j], ops[i]
+		}
+	}
+	itr := &txnIterator{parent: parent, ops: ops, start: start, end: end, reverse: reverse}
+	itr.settle()
+	return itr, nil
+}
+
+// sortedOps returns the writes to keys in [start, end), sorted by key.
+func (txn *Txn) sortedOps(start, end []byte) []*txnOp {
+	ops := make([]*txnOp, 0, len(txn.ops))
+	for _, op := range txn.ops {
+		if dbm.IsKeyInDomain(op.key, start, end) {
+			ops = append(ops, op)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return bytes.Compare(ops[i].key, ops[j].key) < 0 })
+	return ops
+}
+
+// Commit writes the writes of the transaction in a single batch, synced to
+// disk, and ends the transaction. If it fails, none of the writes is made.
+func (txn *Txn) Commit() error {
+	if txn.done {
+		return ErrTxnDone
+	}
+	txn.end()
+
+	if err := failpoint.Inject("db/txn-commit"); err != nil {
+		return err
+	}
+	batch := txn.db.NewBatch()
+	defer batch.Close()
+	for _, op := range txn.sortedOps(nil, nil) {
+		if op.delete {
+			batch.Delete(op.key)
+		} else {
+			batch.Set(op.key, op.value)
+		}
+	}
+	return batch.WriteSync()
+}
+
+// Rollback discards the writes of the transaction and ends it. It does
+// nothing if the transaction has ended already, so that it can be deferred.
+func (txn *Txn) Rollback() {
+	if !txn.done {
+		txn.end()
+	}
+}
+
+func (txn *Txn) end() {
+	txn.done = true
+	txn.snapshot.Release()
+}
+
+// txnIterator merges the writes of a transaction, sorted in iteration order,
+// into an iterator of its snapshot.
+type txnIterator struct {
+	parent     dbm.Iterator
+	ops        []*txnOp
+	start, end []byte
+	reverse    bool
+	useOp      bool // whether the current item is ops[0]
+}
+
+var _ dbm.Iterator = (*txnIterator)(nil)
+
+// compare compares keys in iteration order.
+func (itr *txnIterator) compare(a, b []byte) int {
+	if itr.reverse {
+		return bytes.Compare(b, a)
+	}
+	return bytes.Compare(a, b)
+}
+
+// settle moves to the next item, skipping the items of the snapshot
+// overwritten by the transaction and the keys the transaction deleted.
+func (itr *txnIterator) settle() {
+	for {
+		if len(itr.ops) > 0 && itr.parent.Valid() {
+			c := itr.compare(itr.parent.Key(), itr.ops[0].key)
+			if c == 0 {
+				itr.parent.Next() // overwritten
+				continue
+			}
+			if c < 0 {
+				itr.useOp = false
+				return
+			}
+		}
+		if len(itr.ops) > 0 {
+			if itr.ops[0].delete {
+				itr.ops = itr.ops[1:]
+				continue
+			}
+			itr.useOp = true
+			return
+		}
+		itr.useOp = false
+		return
+	}
+}
+
+func (itr *txnIterator) Domain() ([]byte, []byte) { return itr.start, itr.end }
+func (itr *txnIterator) Valid() bool              { return itr.useOp || itr.parent.Valid() }
+func (itr *txnIterator) Error() error             { return itr.parent.Error() }
+func (itr *txnIterator) Close()                   { itr.parent.Close() }
+
+func (itr *txnIterator) Next() {
+	itr.assertIsValid()
+	if itr.useOp {
+		itr.ops = itr.ops[1:]
+	} else {
+		itr.parent.Next()
+	}
+	itr.settle()
+}
+
+func (itr *txnIterator) Key() []byte {
+	itr.assertIsValid()
+	if itr.useOp {
+		return append([]byte{}, itr.ops[0].key...)
+	}
+	return itr.parent.Key()
+}
+
+func (itr *txnIterator) Value() []byte {
+	itr.assertIsValid()
+	if itr.useOp {
+		return append([]byte{}, itr.ops[0].value...)
+	}
+	return itr.parent.Value()
+}
+
+func (itr *txnIterator) assertIsValid() {
+	if !itr.Valid() {
+		panic("txnIterator is invalid")
+	}
+}
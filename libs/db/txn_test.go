@@ -0,0 +1,222 @@
+package db
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// forEachBackend runs fn with an empty database of each backend supporting
+// snapshots.
+func forEachBackend(t *testing.T, fn func(t *testing.T, db dbm.DB)) {
+	t.Run("memdb", func(t *testing.T) {
+		fn(t, dbm.NewMemDB())
+	})
+	t.Run("goleveldb", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "libs_db_test")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		db, err := dbm.NewGoLevelDB("test", dir)
+		require.NoError(t, err)
+		defer db.Close()
+		fn(t, db)
+	})
+}
+
+// collect returns the items of itr as key=value strings and closes it.
+func collect(t *testing.T, itr dbm.Iterator, err error) []string {
+	require.NoError(t, err)
+	defer itr.Close()
+	items := []string{}
+	for ; itr.Valid(); itr.Next() {
+		items = append(items, string(itr.Key())+"="+string(itr.Value()))
+	}
+	require.NoError(t, itr.Error())
+	return items
+}
+
+func TestSnapshot(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db dbm.DB) {
+		require.NoError(t, db.Set([]byte("a"), []byte("1")))
+		require.NoError(t, db.Set([]byte("b"), []byte("2")))
+		require.NoError(t, db.Set([]byte("c"), []byte("3")))
+
+		s, err := NewSnapshot(db)
+		require.NoError(t, err)
+		defer s.Release()
+
+		// writes after the snapshot are not visible
+		require.NoError(t, db.Set([]byte("a"), []byte("changed")))
+		require.NoError(t, db.Delete([]byte("b")))
+		require.NoError(t, db.Set([]byte("d"), []byte("4")))
+
+		value, err := s.Get([]byte("a"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("1"), value)
+		has, err := s.Has([]byte("b"))
+		require.NoError(t, err)
+		assert.True(t, has)
+		value, err = s.Get([]byte("d"))
+		require.NoError(t, err)
+		assert.Nil(t, value)
+
+		itr, err := s.Iterator([]byte("b"), nil)
+		assert.Equal(t, []string{"b=2", "c=3"}, collect(t, itr, err))
+		itr, err = s.ReverseIterator(nil, []byte("c"))
+		assert.Equal(t, []string{"b=2", "a=1"}, collect(t, itr, err))
+	})
+}
+
+func TestSnapshotConcurrentWrites(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db dbm.DB) {
+		var keys [][]byte
+		for i := 0; i < 100; i++ {
+			keys = append(keys, []byte(fmt.Sprintf("key%03d", i)))
+		}
+		write := func(i int) {
+			batch := db.NewBatch()
+			defer batch.Close()
+			for _, key := range keys {
+				batch.Set(key, []byte(strconv.Itoa(i)))
+			}
+			assert.NoError(t, batch.Write())
+		}
+		write(0)
+
+		// each batch sets all keys at once, so a snapshot taken while the
+		// batches are written sees the same value for all of them, and keeps
+		// seeing it
+		const numWrites = 200
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			for i := 1; i <= numWrites; i++ {
+				write(i)
+				runtime.Gosched()
+			}
+		}()
+
+		for done := false; !done; {
+			select {
+			case <-written:
+				done = true
+			default:
+			}
+			s, err := NewSnapshot(db)
+			require.NoError(t, err)
+			itr, err := s.Iterator(nil, nil)
+			items := collect(t, itr, err)
+			require.Len(t, items, len(keys))
+			value := items[0][len("key000="):]
+			for i, key := range keys {
+				require.Equal(t, string(key)+"="+value, items[i])
+			}
+			runtime.Gosched()
+			itr, err = s.Iterator(nil, nil)
+			require.Equal(t, items, collect(t, itr, err))
+			s.Release()
+		}
+	})
+}
+
+func TestSnapshotUnsupported(t *testing.T) {
+	db := struct{ dbm.DB }{dbm.NewMemDB()}
+	_, err := NewSnapshot(db)
+	assert.Equal(t, ErrSnapshotsUnsupported, err)
+	_, err = NewTxn(db)
+	assert.Equal(t, ErrSnapshotsUnsupported, err)
+}
+
+func TestTxnReadsAndIterators(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db dbm.DB) {
+		for _, k := range []string{"a", "c", "e", "g"} {
+			require.NoError(t, db.Set([]byte(k), []byte(k)))
+		}
+
+		txn, err := NewTxn(db)
+		require.NoError(t, err)
+		defer txn.Rollback()
+
+		require.NoError(t, txn.Set([]byte("b"), []byte("B")))
+		require.NoError(t, txn.Set([]byte("c"), []byte("C")))
+		require.NoError(t, txn.Delete([]byte("e")))
+		require.NoError(t, txn.Delete([]byte("f"))) // doesn't exist
+		require.NoError(t, txn.Set([]byte("h"), []byte("H")))
+		// written concurrently, not visible
+		require.NoError(t, db.Set([]byte("d"), []byte("d")))
+
+		value, err := txn.Get([]byte("c"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("C"), value)
+		has, err := txn.Has([]byte("e"))
+		require.NoError(t, err)
+		assert.False(t, has)
+		has, err = txn.Has([]byte("d"))
+		require.NoError(t, err)
+		assert.False(t, has)
+
+		itr, err := txn.Iterator(nil, nil)
+		assert.Equal(t, []string{"a=a", "b=B", "c=C", "g=g", "h=H"}, collect(t, itr, err))
+		itr, err = txn.ReverseIterator([]byte("b"), []byte("h"))
+		assert.Equal(t, []string{"g=g", "c=C", "b=B"}, collect(t, itr, err))
+		itr, err = txn.Iterator([]byte("d"), []byte("g"))
+		assert.Equal(t, []string{}, collect(t, itr, err))
+	})
+}
+
+func TestTxnCommitAndRollback(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, db dbm.DB) {
+		require.NoError(t, db.Set([]byte("a"), []byte("1")))
+
+		txn, err := NewTxn(db)
+		require.NoError(t, err)
+		require.NoError(t, txn.Set([]byte("b"), []byte("2")))
+		txn.Rollback()
+		assert.Equal(t, ErrTxnDone, txn.Set([]byte("c"), []byte("3")))
+		assert.Equal(t, ErrTxnDone, txn.Commit())
+		has, err := db.Has([]byte("b"))
+		require.NoError(t, err)
+		assert.False(t, has)
+
+		txn, err = NewTxn(db)
+		require.NoError(t, err)
+		require.NoError(t, txn.Delete([]byte("a")))
+		require.NoError(t, txn.Set([]byte("b"), []byte("2")))
+		// writes to the database before the commit are kept, unless
+		// overwritten
+		require.NoError(t, db.Set([]byte("c"), []byte("3")))
+		require.NoError(t, txn.Commit())
+		txn.Rollback() // no-op
+
+		itr, err := db.Iterator(nil, nil)
+		assert.Equal(t, []string{"b=2", "c=3"}, collect(t, itr, err))
+	})
+}
+
+func TestTxnLimits(t *testing.T) {
+	txn, err := NewTxn(dbm.NewMemDB(), TxnMaxOps(2), TxnMaxBytes(10))
+	require.NoError(t, err)
+	defer txn.Rollback()
+
+	assert.Equal(t, ErrKeyEmpty, txn.Set(nil, []byte("v")))
+	require.NoError(t, txn.Set([]byte("a"), []byte("1234")))
+	require.NoError(t, txn.Delete([]byte("b")))
+	assert.Equal(t, ErrTxnTooLarge, txn.Set([]byte("c"), nil))
+	// overwriting doesn't count as another op
+	require.NoError(t, txn.Set([]byte("a"), []byte("12345678")))
+	assert.Equal(t, ErrTxnTooLarge, txn.Set([]byte("a"), []byte("123456789")))
+
+	ops, size := txn.Size()
+	assert.Equal(t, 2, ops)
+	assert.Equal(t, 10, size)
+	value, err := txn.Get([]byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("12345678"), value)
+}
@@ -0,0 +1,135 @@
+// Package errors provides errors carrying a Code, which classifies them, so
+// that callers in other modules, and RPC clients, can tell classes of errors
+// apart without matching error messages:
+//
+//	var errSlowPeer = tmerrors.New(tmerrors.CodeTimeout, "peer is too slow")
+//
+//	if tmerrors.CodeOf(err) == tmerrors.CodeTimeout { ... }
+//
+// Errors wrap their cause, like fmt.Errorf with %w, so errors.Is and
+// errors.As see through them. A sentinel error matches itself with errors.Is.
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code classifies errors. Codes are stable: they are part of the RPC
+// responses (see rpc/lib/types).
+type Code uint32
+
+const (
+	// CodeUnknown is the code of errors without a code.
+	CodeUnknown Code = iota
+	// CodeInternal is for bugs and unexpected failures.
+	CodeInternal
+	// CodeInvalidArgument is for invalid requests, whatever the state.
+	CodeInvalidArgument
+	// CodeNotFound is for requests of something which doesn't exist (yet).
+	CodeNotFound
+	// CodeTimeout is for operations which didn't complete in time.
+	CodeTimeout
+	// CodeUnavailable is for operations which can't be made now and may be
+	// retried, e.g. because a peer is missing.
+	CodeUnavailable
+	// CodeResourceExhausted is for limits which are reached, like a full queue
+	// or too many subscriptions.
+	CodeResourceExhausted
+	// CodeNotSupported is for operations which are disabled or not
+	// implemented.
+	CodeNotSupported
+	// CodePeerMisbehavior is for peers sending invalid data.
+	CodePeerMisbehavior
+)
+
+var codeNames = map[Code]string{
+	CodeUnknown:           "unknown",
+	CodeInternal:          "internal",
+	CodeInvalidArgument:   "invalid argument",
+	CodeNotFound:          "not found",
+	CodeTimeout:           "timeout",
+	CodeUnavailable:       "unavailable",
+	CodeResourceExhausted: "resource exhausted",
+	CodeNotSupported:      "not supported",
+	CodePeerMisbehavior:   "peer misbehavior",
+}
+
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("code %d", uint32(c))
+}
+
+// Error is an error with a code and, optionally, a cause.
+type Error struct {
+	code  Code
+	msg   string
+	cause error
+	// whether msg includes the message of cause, as with Errorf and %w
+	withCause bool
+}
+
+var _ error = (*Error)(nil)
+
+// New returns an error with the given code and message.
+func New(code Code, msg string) *Error {
+	return &Error{code: code, msg: msg}
+}
+
+// Errorf returns an error with the given code and a message formatted like
+// fmt.Errorf. If format contains %w, the error wraps the matching argument.
+func Errorf(code Code, format string, args ...interface{}) *Error {
+	err := fmt.Errorf(format, args...)
+	return &Error{code: code, msg: err.Error(), cause: errors.Unwrap(err), withCause: true}
+}
+
+// Wrap returns an error with the given code and message, wrapping err. The
+// message of the error is "msg: <message of err>". It returns nil if err is
+// nil.
+func Wrap(err error, code Code, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{code: code, msg: msg, cause: err}
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if e.cause == nil || e.withCause {
+		return e.msg
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+// Unwrap returns the cause of e, or nil.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// ErrorCode returns the code of e. It implements Coder.
+func (e *Error) ErrorCode() Code {
+	return e.code
+}
+
+// Coder is implemented by errors carrying a code, like Error and the errors
+// of RPC responses.
+type Coder interface {
+	ErrorCode() Code
+}
+
+// CodeOf returns the code of the first error in the chain of err (see
+// errors.Unwrap) which has one, or CodeUnknown.
+func CodeOf(err error) Code {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	return CodeUnknown
+}
+
+// HasCode returns whether CodeOf(err) is code.
+func HasCode(err error, code Code) bool {
+	return CodeOf(err) == code
+}
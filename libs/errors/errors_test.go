@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	errSlow := New(CodeTimeout, "peer is too slow")
+	assert.Equal(t, "peer is too slow", errSlow.Error())
+	assert.Equal(t, CodeTimeout, CodeOf(errSlow))
+	assert.Nil(t, errSlow.Unwrap())
+
+	// Wrapping keeps the code and the identity of the sentinel.
+	wrapped := pkgerrors.Wrap(errSlow, "stopping peer")
+	assert.True(t, errors.Is(wrapped, errSlow))
+	assert.True(t, HasCode(wrapped, CodeTimeout))
+	assert.False(t, errors.Is(wrapped, New(CodeTimeout, "peer is too slow")))
+
+	err := Wrap(io.EOF, CodeUnavailable, "reading")
+	assert.Equal(t, "reading: EOF", err.Error())
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, CodeUnavailable, CodeOf(err))
+	assert.Nil(t, Wrap(nil, CodeInternal, "nothing"))
+
+	// The outermost code wins.
+	err = Wrap(errSlow, CodePeerMisbehavior, "bad peer")
+	assert.Equal(t, CodePeerMisbehavior, CodeOf(err))
+	assert.True(t, errors.Is(err, errSlow))
+}
+
+func TestErrorf(t *testing.T) {
+	err := Errorf(CodeNotFound, "block %d: %w", 5, io.ErrUnexpectedEOF)
+	assert.Equal(t, "block 5: unexpected EOF", err.Error())
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Equal(t, CodeNotFound, CodeOf(err))
+
+	err = Errorf(CodeInvalidArgument, "height %d must be positive", -1)
+	assert.Equal(t, "height -1 must be positive", err.Error())
+	assert.Nil(t, err.Unwrap())
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, CodeUnknown, CodeOf(nil))
+	assert.Equal(t, CodeUnknown, CodeOf(io.EOF))
+	assert.Equal(t, "not found", CodeNotFound.String())
+	assert.Equal(t, "code 100", Code(100).String())
+}
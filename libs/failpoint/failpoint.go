@@ -0,0 +1,47 @@
+// Package failpoint provides named failpoints: places in the code, like the
+// commit path, the WAL writes or the DB batches, where a crash, a delay or an
+// error can be injected to test crash recovery.
+//
+// Failpoints are only compiled in with the failpoints build tag:
+//
+//	go test -tags failpoints ./...
+//	make install_failpoints
+//
+// Without it, Inject does nothing and Enable returns ErrNotCompiled.
+//
+// A failpoint is activated with a term "[<count>*]<action>[(<arg>)]", where
+// the action is one of:
+//
+//	off          do nothing
+//	exit         print the name of the failpoint and exit with status 1
+//	panic(msg)   panic with msg
+//	sleep(dur)   sleep for dur (a time.Duration, e.g. 100ms), then continue
+//	error(msg)   make Inject return an error with msg
+//
+// With a count, the action is only taken the first count times the failpoint
+// is reached. Failpoints are activated at startup from the TM_FAILPOINTS
+// environment variable, as a ";" separated list of name=term:
+//
+//	TM_FAILPOINTS="state/before-save-state=exit;consensus/wal-write=2*error(disk full)"
+//
+// or at runtime with Enable, which the unsafe_set_failpoint RPC endpoint calls.
+package failpoint
+
+import (
+	"github.com/pkg/errors"
+)
+
+// EnvFailpoints is the environment variable from which failpoints are
+// activated at startup.
+const EnvFailpoints = "TM_FAILPOINTS"
+
+// ErrNotCompiled is returned by Enable when failpoints are not compiled in.
+var ErrNotCompiled = errors.New("failpoints are not compiled in, build with -tags failpoints")
+
+// Failpoint describes an active failpoint.
+type Failpoint struct {
+	Name string `json:"name"`
+	Term string `json:"term"`
+	// Number of times the action was taken
+	Hits int `json:"hits"`
+}
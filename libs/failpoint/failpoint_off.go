@@ -0,0 +1,18 @@
+// +build !failpoints
+
+package failpoint
+
+// Enabled is whether failpoints are compiled in.
+const Enabled = false
+
+// Inject does nothing: failpoints are not compiled in.
+func Inject(name string) error { return nil }
+
+// Enable returns ErrNotCompiled.
+func Enable(name, term string) error { return ErrNotCompiled }
+
+// Disable does nothing.
+func Disable(name string) {}
+
+// List returns nil.
+func List() []Failpoint { return nil }
@@ -0,0 +1,16 @@
+// +build !failpoints
+
+package failpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotCompiled(t *testing.T) {
+	assert.False(t, Enabled)
+	assert.Equal(t, ErrNotCompiled, Enable("test/error", "error"))
+	assert.NoError(t, Inject("test/error"))
+	assert.Empty(t, List())
+}
@@ -0,0 +1,111 @@
+// +build failpoints
+
+package failpoint
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Enabled is whether failpoints are compiled in.
+const Enabled = true
+
+var (
+	mtx    sync.Mutex
+	points = make(map[string]*point)
+)
+
+type point struct {
+	term *term
+	left int // times the action is still taken, if term.count > 0
+	hits int
+}
+
+func init() {
+	if err := enableFromEnv(os.Getenv(EnvFailpoints)); err != nil {
+		panic(fmt.Sprintf("invalid %s: %v", EnvFailpoints, err))
+	}
+}
+
+// enableFromEnv enables the failpoints of s, a ";" separated list of
+// name=term.
+func enableFromEnv(s string) error {
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			return errors.Errorf("expected name=term, got %q", entry)
+		}
+		if err := Enable(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Inject takes the action of the failpoint name, if it is active: it may exit
+// the process, panic, sleep, or return an error, which the caller must handle
+// as a failure of the operation the failpoint stands for.
+func Inject(name string) error {
+	mtx.Lock()
+	p, ok := points[name]
+	if !ok || (p.term.count > 0 && p.left == 0) {
+		mtx.Unlock()
+		return nil
+	}
+	if p.term.count > 0 {
+		p.left--
+	}
+	p.hits++
+	t := p.term
+	mtx.Unlock()
+
+	return t.execute(name)
+}
+
+// Enable activates the failpoint name with term (see the package doc). The
+// term "off" or "" deactivates it.
+func Enable(name, term string) error {
+	if name == "" {
+		return errors.New("empty failpoint name")
+	}
+	if term == "" || term == "off" {
+		Disable(name)
+		return nil
+	}
+	t, err := parseTerm(term)
+	if err != nil {
+		return errors.Wrapf(err, "failpoint %s", name)
+	}
+	mtx.Lock()
+	points[name] = &point{term: t, left: t.count}
+	mtx.Unlock()
+	return nil
+}
+
+// Disable deactivates the failpoint name.
+func Disable(name string) {
+	mtx.Lock()
+	delete(points, name)
+	mtx.Unlock()
+}
+
+// List returns the active failpoints, sorted by name.
+func List() []Failpoint {
+	mtx.Lock()
+	defer mtx.Unlock()
+	list := make([]Failpoint, 0, len(points))
+	for name, p := range points {
+		list = append(list, Failpoint{Name: name, Term: p.term.raw, Hits: p.hits})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
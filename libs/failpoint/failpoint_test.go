@@ -0,0 +1,88 @@
+// +build failpoints
+
+package failpoint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTerm(t *testing.T) {
+	testCases := []struct {
+		term   string
+		count  int
+		action string
+		arg    string
+		ok     bool
+	}{
+		{"exit", 0, "exit", "", true},
+		{"3*error(disk full)", 3, "error", "disk full", true},
+		{"sleep(10ms)", 0, "sleep", "10ms", true},
+		{"panic(a*b)", 0, "panic", "a*b", true},
+		{"error", 0, "error", "", true},
+		{"0*exit", 0, "", "", false},
+		{"x*exit", 0, "", "", false},
+		{"exit(1)", 0, "", "", false},
+		{"sleep(soon)", 0, "", "", false},
+		{"error(oops", 0, "", "", false},
+		{"crash", 0, "", "", false},
+	}
+	for _, tc := range testCases {
+		term, err := parseTerm(tc.term)
+		if !tc.ok {
+			assert.Error(t, err, tc.term)
+			continue
+		}
+		require.NoError(t, err, tc.term)
+		assert.Equal(t, tc.count, term.count, tc.term)
+		assert.Equal(t, tc.action, term.action, tc.term)
+		assert.Equal(t, tc.arg, term.arg, tc.term)
+	}
+}
+
+func TestInject(t *testing.T) {
+	assert.NoError(t, Inject("test/inactive"))
+
+	require.NoError(t, Enable("test/error", "2*error(boom)"))
+	defer Disable("test/error")
+	for i := 0; i < 2; i++ {
+		err := Inject("test/error")
+		require.Error(t, err)
+		assert.Equal(t, "failpoint test/error: boom", err.Error())
+	}
+	assert.NoError(t, Inject("test/error"), "the count is exhausted")
+
+	require.NoError(t, Enable("test/sleep", "sleep(20ms)"))
+	defer Disable("test/sleep")
+	start := time.Now()
+	assert.NoError(t, Inject("test/sleep"))
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	require.NoError(t, Enable("test/panic", "panic(oops)"))
+	assert.PanicsWithValue(t, "failpoint test/panic: oops", func() { Inject("test/panic") }) // nolint: errcheck
+	require.NoError(t, Enable("test/panic", "off"))
+
+	assert.Equal(t, []Failpoint{
+		{Name: "test/error", Term: "2*error(boom)", Hits: 2},
+		{Name: "test/sleep", Term: "sleep(20ms)", Hits: 1},
+	}, List())
+
+	assert.Error(t, Enable("test/bad", "crash"))
+	assert.Error(t, Enable("", "exit"))
+}
+
+func TestEnableFromEnv(t *testing.T) {
+	require.NoError(t, enableFromEnv(" test/a=exit ; test/b=2*sleep(1ms);"))
+	defer Disable("test/a")
+	defer Disable("test/b")
+	list := List()
+	require.Len(t, list, 2)
+	assert.Equal(t, "exit", list[0].Term)
+	assert.Equal(t, "2*sleep(1ms)", list[1].Term)
+
+	assert.Error(t, enableFromEnv("test/c"))
+	assert.Error(t, enableFromEnv("test/c=crash"))
+}
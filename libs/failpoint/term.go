@@ -0,0 +1,78 @@
+// +build failpoints
+
+package failpoint
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// term is a parsed "[<count>*]<action>[(<arg>)]".
+type term struct {
+	raw    string
+	count  int // 0 is unlimited
+	action string
+	arg    string
+	delay  time.Duration // of sleep
+}
+
+func parseTerm(s string) (*term, error) {
+	t := &term{raw: s}
+	if i := strings.Index(s, "*"); i >= 0 && !strings.Contains(s[:i], "(") {
+		count, err := strconv.Atoi(s[:i])
+		if err != nil || count <= 0 {
+			return nil, errors.Errorf("invalid count %q in %q", s[:i], s)
+		}
+		t.count = count
+		s = s[i+1:]
+	}
+	t.action = s
+	if i := strings.Index(s, "("); i >= 0 {
+		if !strings.HasSuffix(s, ")") {
+			return nil, errors.Errorf("missing ) in %q", t.raw)
+		}
+		t.action, t.arg = s[:i], s[i+1:len(s)-1]
+	}
+
+	switch t.action {
+	case "off", "exit":
+		if t.arg != "" {
+			return nil, errors.Errorf("%s takes no argument, got %q", t.action, t.raw)
+		}
+	case "panic", "error":
+	case "sleep":
+		delay, err := time.ParseDuration(t.arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid sleep duration in %q", t.raw)
+		}
+		t.delay = delay
+	default:
+		return nil, errors.Errorf("unknown action %q in %q", t.action, t.raw)
+	}
+	return t, nil
+}
+
+// execute takes the action of t for the failpoint name.
+func (t *term) execute(name string) error {
+	switch t.action {
+	case "exit":
+		fmt.Printf("*** failpoint %s ***\n", name)
+		os.Exit(1)
+	case "panic":
+		panic(fmt.Sprintf("failpoint %s: %s", name, t.arg))
+	case "sleep":
+		time.Sleep(t.delay)
+	case "error":
+		msg := t.arg
+		if msg == "" {
+			msg = "injected error"
+		}
+		return errors.Errorf("failpoint %s: %s", name, msg)
+	}
+	return nil
+}
@@ -31,6 +31,10 @@ type Monitor struct {
 
 	tBytes int64         // Number of bytes expected in the current transfer
 	tLast  time.Duration // Time of the most recent transfer of at least 1 byte
+
+	rates      history // Most recent rSamples
+	latencies  history // Most recent latencies (nanoseconds)
+	nLatencies int64   // Total number of latencies recorded
 }
 
 // New creates a new flow control monitor. Instantaneous transfer rate is
@@ -231,6 +235,7 @@ func (m *Monitor) update(n int) (now time.Duration) {
 		if m.rSample = float64(m.sBytes) / t; m.rSample > m.rPeak {
 			m.rPeak = m.rSample
 		}
+		m.rates.add(m.rSample)
 
 		// Exponential moving average using a method similar to *nix load
 		// average calculation. Longer sampling periods carry greater weight.
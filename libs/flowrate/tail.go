@@ -0,0 +1,95 @@
+package flowrate
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// historySize is the number of the most recent rate samples and latencies
+// kept for percentiles.
+const historySize = 128
+
+// TailStatus summarizes the recent tail behavior of a transfer, which a single
+// moving average (Status.CurRate) hides: a peer which stalls every few
+// seconds can have a fine average but a poor 10th percentile rate. Rates are
+// in bytes per second. Percentiles are computed over the last 128 samples and
+// latencies, and are 0 if there are none.
+type TailStatus struct {
+	RateP10    int64         // 10th percentile of the instantaneous transfer rate
+	RateP50    int64         // Median instantaneous transfer rate
+	RateP90    int64         // 90th percentile of the instantaneous transfer rate
+	Latencies  int64         // Total number of latencies recorded
+	LatencyP50 time.Duration // Median latency (see RecordLatency)
+	LatencyP90 time.Duration // 90th percentile latency
+	LatencyP99 time.Duration // 99th percentile latency
+}
+
+// RecordLatency records the latency of a request or message of the transfer,
+// e.g. the time between requesting a block and receiving it, for TailStatus.
+func (m *Monitor) RecordLatency(d time.Duration) {
+	m.mu.Lock()
+	if m.active {
+		m.latencies.add(float64(d))
+		m.nLatencies++
+	}
+	m.mu.Unlock()
+}
+
+// TailStatus returns the percentiles of the recent transfer rates and
+// latencies. Like Status, it becomes static after a call to Done.
+func (m *Monitor) TailStatus() TailStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.update(0)
+
+	rates := m.rates.percentiles(10, 50, 90)
+	latencies := m.latencies.percentiles(50, 90, 99)
+	return TailStatus{
+		RateP10:    round(rates[0]),
+		RateP50:    round(rates[1]),
+		RateP90:    round(rates[2]),
+		Latencies:  m.nLatencies,
+		LatencyP50: time.Duration(latencies[0]),
+		LatencyP90: time.Duration(latencies[1]),
+		LatencyP99: time.Duration(latencies[2]),
+	}
+}
+
+// history is a ring buffer of the most recent values.
+type history struct {
+	values []float64
+	next   int
+}
+
+func (h *history) add(v float64) {
+	if len(h.values) < historySize {
+		h.values = append(h.values, v)
+		return
+	}
+	h.values[h.next] = v
+	h.next = (h.next + 1) % historySize
+}
+
+// percentiles returns the given percentiles (0 < p <= 100) of the values, using
+// the nearest-rank method.
+func (h *history) percentiles(ps ...float64) []float64 {
+	res := make([]float64, len(ps))
+	n := len(h.values)
+	if n == 0 {
+		return res
+	}
+	sorted := make([]float64, n)
+	copy(sorted, h.values)
+	sort.Float64s(sorted)
+	for i, p := range ps {
+		rank := int(math.Ceil(p / 100 * float64(n)))
+		if rank < 1 {
+			rank = 1
+		} else if rank > n {
+			rank = n
+		}
+		res[i] = sorted[rank-1]
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package flowrate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistoryPercentiles(t *testing.T) {
+	var h history
+	if p := h.percentiles(50); p[0] != 0 {
+		t.Fatalf("empty history: want 0, got %v", p[0])
+	}
+
+	// the oldest values are overwritten
+	for i := 1; i <= historySize+100; i++ {
+		h.add(float64(i))
+	}
+	if len(h.values) != historySize {
+		t.Fatalf("want %d values, got %d", historySize, len(h.values))
+	}
+	p := h.percentiles(1, 50, 100)
+	want := []float64{102, 164, 228}
+	for i := range want {
+		if p[i] != want[i] {
+			t.Errorf("percentile %d: want %v, got %v", i, want[i], p[i])
+		}
+	}
+}
+
+func TestMonitorTailStatus(t *testing.T) {
+	m := New(time.Hour, 0) // no samples are taken during the test
+	for i := 1; i <= 100; i++ {
+		m.RecordLatency(time.Duration(i) * time.Millisecond)
+	}
+	m.rates.add(1000)
+	m.rates.add(10)
+
+	s := m.TailStatus()
+	if s.Latencies != 100 {
+		t.Errorf("want 100 latencies, got %d", s.Latencies)
+	}
+	if s.LatencyP50 != 50*time.Millisecond || s.LatencyP90 != 90*time.Millisecond ||
+		s.LatencyP99 != 99*time.Millisecond {
+		t.Errorf("unexpected latency percentiles %v", s)
+	}
+	if s.RateP10 != 10 || s.RateP90 != 1000 {
+		t.Errorf("unexpected rate percentiles %v", s)
+	}
+
+	// static after Done
+	m.Done()
+	m.RecordLatency(time.Second)
+	if s := m.TailStatus(); s.Latencies != 100 {
+		t.Errorf("want 100 latencies after Done, got %d", s.Latencies)
+	}
+}
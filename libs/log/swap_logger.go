@@ -0,0 +1,83 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SwapLogger is a logger whose underlying logger can be replaced at runtime,
+// e.g. to change the log level of a running node. Loggers derived from it
+// with With follow the replacement too.
+type SwapLogger struct {
+	root    *swapRoot
+	keyvals []interface{}
+
+	mtx    sync.Mutex
+	gen    uint64
+	cached Logger
+}
+
+type swapRoot struct {
+	gen  uint64 // atomic
+	mtx  sync.RWMutex
+	next Logger
+}
+
+var _ Logger = (*SwapLogger)(nil)
+
+// NewSwapLogger returns a SwapLogger writing to next.
+func NewSwapLogger(next Logger) *SwapLogger {
+	return &SwapLogger{
+		root:   &swapRoot{next: next},
+		cached: next,
+	}
+}
+
+// Swap replaces the underlying logger of l and of all loggers sharing its
+// root (i.e. derived from the same NewSwapLogger call).
+func (l *SwapLogger) Swap(next Logger) {
+	l.root.mtx.Lock()
+	l.root.next = next
+	atomic.AddUint64(&l.root.gen, 1)
+	l.root.mtx.Unlock()
+}
+
+func (l *SwapLogger) current() Logger {
+	gen := atomic.LoadUint64(&l.root.gen)
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.gen != gen || l.cached == nil {
+		l.root.mtx.RLock()
+		next := l.root.next
+		gen = atomic.LoadUint64(&l.root.gen)
+		l.root.mtx.RUnlock()
+		if len(l.keyvals) > 0 {
+			next = next.With(l.keyvals...)
+		}
+		l.cached, l.gen = next, gen
+	}
+	return l.cached
+}
+
+// Info implements Logger.
+func (l *SwapLogger) Info(msg string, keyvals ...interface{}) {
+	l.current().Info(msg, keyvals...)
+}
+
+// Debug implements Logger.
+func (l *SwapLogger) Debug(msg string, keyvals ...interface{}) {
+	l.current().Debug(msg, keyvals...)
+}
+
+// Error implements Logger.
+func (l *SwapLogger) Error(msg string, keyvals ...interface{}) {
+	l.current().Error(msg, keyvals...)
+}
+
+// With implements Logger. The returned logger follows subsequent swaps.
+func (l *SwapLogger) With(keyvals ...interface{}) Logger {
+	kvs := make([]interface{}, 0, len(l.keyvals)+len(keyvals))
+	kvs = append(kvs, l.keyvals...)
+	kvs = append(kvs, keyvals...)
+	return &SwapLogger{root: l.root, keyvals: kvs}
+}
@@ -0,0 +1,32 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestSwapLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := log.NewTMJSONLogger(&buf)
+
+	swap := log.NewSwapLogger(log.NewFilter(base, log.AllowError()))
+	derived := swap.With("module", "test")
+
+	derived.Info("dropped")
+	derived.Error("first")
+
+	swap.Swap(log.NewFilter(base, log.AllowInfo()))
+	derived.Info("second")
+	derived.Debug("dropped")
+
+	want := strings.Join([]string{
+		`{"_msg":"first","level":"error","module":"test"}`,
+		`{"_msg":"second","level":"info","module":"test"}`,
+	}, "\n")
+	if have := strings.TrimSpace(buf.String()); have != want {
+		t.Errorf("\nwant:\n%s\nhave:\n%s", want, have)
+	}
+}
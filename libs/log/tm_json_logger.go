@@ -13,3 +13,9 @@ import (
 func NewTMJSONLogger(w io.Writer) Logger {
 	return &tmLogger{kitlog.NewJSONLogger(w)}
 }
+
+// NewTMJSONLoggerWithTS is the same as NewTMJSONLogger, but includes a "ts"
+// key with the UTC time of the event.
+func NewTMJSONLoggerWithTS(w io.Writer) Logger {
+	return &tmLogger{kitlog.With(kitlog.NewJSONLogger(w), "ts", kitlog.DefaultTimestampUTC)}
+}
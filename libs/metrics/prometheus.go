@@ -0,0 +1,36 @@
+// Package metrics creates the go-kit Prometheus metrics of the other packages
+// with a given registerer, rather than the default Prometheus registerer, so
+// that the metrics of several nodes can be registered side by side.
+package metrics
+
+import (
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// NewCounterFrom is like prometheus.NewCounterFrom, but registers the counter
+// with the given registerer.
+func NewCounterFrom(registerer stdprometheus.Registerer, opts stdprometheus.CounterOpts,
+	labelNames []string) *prometheus.Counter {
+	cv := stdprometheus.NewCounterVec(opts, labelNames)
+	registerer.MustRegister(cv)
+	return prometheus.NewCounter(cv)
+}
+
+// NewGaugeFrom is like prometheus.NewGaugeFrom, but registers the gauge with
+// the given registerer.
+func NewGaugeFrom(registerer stdprometheus.Registerer, opts stdprometheus.GaugeOpts,
+	labelNames []string) *prometheus.Gauge {
+	gv := stdprometheus.NewGaugeVec(opts, labelNames)
+	registerer.MustRegister(gv)
+	return prometheus.NewGauge(gv)
+}
+
+// NewHistogramFrom is like prometheus.NewHistogramFrom, but registers the
+// histogram with the given registerer.
+func NewHistogramFrom(registerer stdprometheus.Registerer, opts stdprometheus.HistogramOpts,
+	labelNames []string) *prometheus.Histogram {
+	hv := stdprometheus.NewHistogramVec(opts, labelNames)
+	registerer.MustRegister(hv)
+	return prometheus.NewHistogram(hv)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/signal"
 	"syscall"
 )
 
@@ -13,19 +12,24 @@ type logger interface {
 }
 
 // TrapSignal catches the SIGTERM/SIGINT and executes cb function. After that it exits
-// with code 0.
+// with code 0. It registers cb in DefaultSignals, see HandleShutdown to
+// control the order of several callbacks.
 func TrapSignal(logger logger, cb func()) {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		for sig := range c {
-			logger.Info(fmt.Sprintf("captured %v, exiting...", sig))
-			if cb != nil {
-				cb()
-			}
-			os.Exit(0)
-		}
-	}()
+	DefaultSignals.SetLogger(logger)
+	if cb == nil {
+		cb = func() {}
+	}
+	HandleShutdown(0, "shutdown", cb)
+}
+
+// TrapReloadSignal catches SIGHUP and executes cb function every time it is
+// received. Unlike TrapSignal, it does not exit.
+func TrapReloadSignal(logger logger, cb func()) {
+	DefaultSignals.SetLogger(logger)
+	if cb == nil {
+		cb = func() {}
+	}
+	HandleSignal(syscall.SIGHUP, 0, "reload", cb)
 }
 
 // Kill the running process by sending itself SIGTERM.
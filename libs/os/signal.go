@@ -0,0 +1,175 @@
+package os
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+)
+
+// Signals dispatches OS signals to the callbacks registered for them by the
+// subsystems of a process, e.g. the node's shutdown on SIGTERM, the config
+// reload on SIGHUP and the reopening of log files on SIGUSR1. The callbacks
+// of a signal are run one at a time, by ascending order, then by registration
+// order. After the callbacks of a shutdown signal (SIGINT and SIGTERM), the
+// process exits with code 0.
+type Signals struct {
+	mtx      sync.Mutex
+	logger   logger
+	handlers map[os.Signal]*signalHandlers
+	nextID   int
+	exit     func(code int) // os.Exit, replaced in tests
+}
+
+type signalHandlers struct {
+	c         chan os.Signal
+	callbacks []signalCallback
+}
+
+type signalCallback struct {
+	id    int
+	order int
+	name  string
+	fn    func()
+}
+
+// ShutdownSignals are the signals after which Signals exits the process.
+var ShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// NewSignals returns an empty registry logging the signals it captures to
+// logger.
+func NewSignals(logger logger) *Signals {
+	return &Signals{
+		logger:   logger,
+		handlers: make(map[os.Signal]*signalHandlers),
+		exit:     os.Exit,
+	}
+}
+
+// SetLogger sets the logger of the captured signals.
+func (s *Signals) SetLogger(logger logger) {
+	s.mtx.Lock()
+	s.logger = logger
+	s.mtx.Unlock()
+}
+
+// Handle registers fn, named name in the logs, to be called upon receiving
+// sig, after the callbacks of lower order. It returns a function removing the
+// callback. Signals without callbacks get their default behaviour back. A nil
+// sig, like ReopenSignal on platforms without it, is ignored.
+func (s *Signals) Handle(sig os.Signal, order int, name string, fn func()) (remove func()) {
+	if sig == nil {
+		return func() {}
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.nextID++
+	id := s.nextID
+	h, ok := s.handlers[sig]
+	if !ok {
+		h = &signalHandlers{c: make(chan os.Signal, 1)}
+		s.handlers[sig] = h
+		signal.Notify(h.c, sig)
+		go s.dispatchRoutine(h.c)
+	}
+	h.callbacks = append(h.callbacks, signalCallback{id: id, order: order, name: name, fn: fn})
+	sort.SliceStable(h.callbacks, func(i, j int) bool { return h.callbacks[i].order < h.callbacks[j].order })
+
+	return func() { s.remove(sig, id) }
+}
+
+// HandleShutdown registers fn for all the ShutdownSignals.
+func (s *Signals) HandleShutdown(order int, name string, fn func()) (remove func()) {
+	removes := make([]func(), 0, len(ShutdownSignals))
+	for _, sig := range ShutdownSignals {
+		removes = append(removes, s.Handle(sig, order, name, fn))
+	}
+	return func() {
+		for _, remove := range removes {
+			remove()
+		}
+	}
+}
+
+func (s *Signals) remove(sig os.Signal, id int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	h, ok := s.handlers[sig]
+	if !ok {
+		return
+	}
+	for i, cb := range h.callbacks {
+		if cb.id == id {
+			h.callbacks = append(h.callbacks[:i], h.callbacks[i+1:]...)
+			break
+		}
+	}
+	if len(h.callbacks) == 0 {
+		signal.Stop(h.c)
+		close(h.c)
+		delete(s.handlers, sig)
+	}
+}
+
+func (s *Signals) dispatchRoutine(c chan os.Signal) {
+	for sig := range c {
+		s.dispatch(sig)
+	}
+}
+
+// dispatch runs the callbacks of sig, then exits if sig is a shutdown signal.
+func (s *Signals) dispatch(sig os.Signal) {
+	s.mtx.Lock()
+	var callbacks []signalCallback
+	if h, ok := s.handlers[sig]; ok {
+		callbacks = append(callbacks, h.callbacks...)
+	}
+	logger, exit := s.logger, s.exit
+	s.mtx.Unlock()
+
+	shutdown := isShutdownSignal(sig)
+	if shutdown {
+		logger.Info(fmt.Sprintf("captured %v, exiting...", sig))
+	} else {
+		logger.Info(fmt.Sprintf("captured %v", sig))
+	}
+	for _, cb := range callbacks {
+		logger.Info("Running signal handler", "signal", sig, "handler", cb.name)
+		cb.fn()
+	}
+	if shutdown {
+		exit(0)
+	}
+}
+
+func isShutdownSignal(sig os.Signal) bool {
+	for _, s := range ShutdownSignals {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
+
+//----------------------------------------
+// Default registry
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{}) {}
+
+// DefaultSignals is the registry of the process, used by the functions below.
+var DefaultSignals = NewSignals(nopLogger{})
+
+// HandleSignal registers fn for sig in DefaultSignals. See Signals.Handle.
+func HandleSignal(sig os.Signal, order int, name string, fn func()) (remove func()) {
+	return DefaultSignals.Handle(sig, order, name, fn)
+}
+
+// HandleShutdown registers fn for the ShutdownSignals in DefaultSignals.
+func HandleShutdown(order int, name string, fn func()) (remove func()) {
+	return DefaultSignals.HandleShutdown(order, name, fn)
+}
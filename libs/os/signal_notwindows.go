@@ -0,0 +1,12 @@
+// +build !windows
+
+package os
+
+import (
+	"os"
+	"syscall"
+)
+
+// ReopenSignal is the signal upon which log files are reopened, e.g. after
+// logrotate moved them.
+var ReopenSignal os.Signal = syscall.SIGUSR1
@@ -0,0 +1,59 @@
+package os
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalsOrder(t *testing.T) {
+	s := NewSignals(nopLogger{})
+	var exitCode = -1
+	s.exit = func(code int) { exitCode = code }
+
+	var calls []string
+	record := func(name string) func() { return func() { calls = append(calls, name) } }
+	removeNode := s.HandleShutdown(0, "node", record("node"))
+	s.HandleShutdown(10, "db", record("db"))
+	s.HandleShutdown(-1, "files", record("files"))
+	s.HandleShutdown(0, "telemetry", record("telemetry"))
+	s.Handle(syscall.SIGHUP, 0, "reload", record("reload"))
+
+	s.dispatch(syscall.SIGTERM)
+	assert.Equal(t, []string{"files", "node", "telemetry", "db"}, calls)
+	assert.Equal(t, 0, exitCode)
+
+	calls, exitCode = nil, -1
+	removeNode()
+	s.dispatch(syscall.SIGHUP)
+	assert.Equal(t, []string{"reload"}, calls)
+	assert.Equal(t, -1, exitCode, "SIGHUP doesn't exit")
+
+	calls = nil
+	s.dispatch(os.Interrupt)
+	assert.Equal(t, []string{"files", "telemetry", "db"}, calls)
+}
+
+func TestSignalsReceive(t *testing.T) {
+	s := NewSignals(nopLogger{})
+	received := make(chan struct{}, 1)
+	remove := s.Handle(syscall.SIGHUP, 0, "test", func() { received <- struct{}{} })
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP was not handled")
+	}
+
+	remove()
+	s.mtx.Lock()
+	assert.Empty(t, s.handlers)
+	s.mtx.Unlock()
+	assert.NotPanics(t, remove, "removing twice is a no-op")
+	assert.NotPanics(t, s.Handle(nil, 0, "nil", func() {}))
+}
@@ -0,0 +1,8 @@
+// +build windows
+
+package os
+
+import "os"
+
+// ReopenSignal is nil: there is no SIGUSR1 on Windows.
+var ReopenSignal os.Signal
@@ -10,6 +10,12 @@
 // match, this message will be pushed to all clients, subscribed to that query.
 // See query subpackage for our implementation.
 //
+// Each subscription has a buffer and an overflow policy, which declares what
+// happens when a message is published while the buffer is full: the
+// subscription is terminated (the default for buffered subscriptions), the
+// oldest message is dropped, or the publisher blocks (the default for
+// unbuffered subscriptions). See SubscribeWithPolicy.
+//
 // Example:
 //
 //     q, err := query.New("account.name='John'")
@@ -93,10 +99,13 @@ type Server struct {
 	cmds    chan cmd
 	cmdsCap int
 
+	// closed when the server is stopping, to unblock a blocked publisher
+	stopping chan struct{}
+
 	// check if we have subscription before
 	// subscribing or unsubscribing
 	mtx           sync.RWMutex
-	subscriptions map[string]map[string]struct{} // subscriber -> query (string) -> empty struct
+	subscriptions map[string]map[string]*Subscription // subscriber -> query (string) -> subscription
 }
 
 // Option sets a parameter for the server.
@@ -107,7 +116,8 @@ type Option func(*Server)
 // provided, the resulting server's queue is unbuffered.
 func NewServer(options ...Option) *Server {
 	s := &Server{
-		subscriptions: make(map[string]map[string]struct{}),
+		stopping:      make(chan struct{}),
+		subscriptions: make(map[string]map[string]*Subscription),
 	}
 	s.BaseService = *service.NewBaseService(nil, "PubSub", s)
 
@@ -159,17 +169,40 @@ func (s *Server) Subscribe(
 		outCap = outCapacity[0]
 	}
 
-	return s.subscribe(ctx, clientID, query, outCap)
+	return s.subscribe(ctx, clientID, query, NewSubscription(outCap))
 }
 
 // SubscribeUnbuffered does the same as Subscribe, except it returns a
 // subscription with unbuffered channel. Use with caution as it can freeze the
 // server.
 func (s *Server) SubscribeUnbuffered(ctx context.Context, clientID string, query Query) (*Subscription, error) {
-	return s.subscribe(ctx, clientID, query, 0)
+	return s.subscribe(ctx, clientID, query, NewSubscription(0))
 }
 
-func (s *Server) subscribe(ctx context.Context, clientID string, query Query, outCapacity int) (*Subscription, error) {
+// SubscribeWithPolicy does the same as Subscribe, except that it uses the
+// given overflow policy once the Subscription#Out channel with capacity
+// outCapacity is full. A capacity of zero requires OverflowBlock.
+//
+// A blocking subscriber stalls the server, and thus all publishers, until it
+// receives the message, unsubscribes or the server is stopped.
+func (s *Server) SubscribeWithPolicy(
+	ctx context.Context,
+	clientID string,
+	query Query,
+	outCapacity int,
+	policy OverflowPolicy) (*Subscription, error) {
+	if outCapacity < 0 || (outCapacity == 0 && policy != OverflowBlock) {
+		return nil, errors.Errorf("capacity %d is not allowed with the %v overflow policy", outCapacity, policy)
+	}
+	switch policy {
+	case OverflowTerminate, OverflowBlock, OverflowDropOldest:
+	default:
+		return nil, errors.Errorf("unknown overflow policy %v", policy)
+	}
+	return s.subscribe(ctx, clientID, query, NewSubscriptionWithPolicy(outCapacity, policy))
+}
+
+func (s *Server) subscribe(ctx context.Context, clientID string, query Query, subscription *Subscription) (*Subscription, error) {
 	s.mtx.RLock()
 	clientSubscriptions, ok := s.subscriptions[clientID]
 	if ok {
@@ -180,14 +213,13 @@ func (s *Server) subscribe(ctx context.Context, clientID string, query Query, ou
 		return nil, ErrAlreadySubscribed
 	}
 
-	subscription := NewSubscription(outCapacity)
 	select {
 	case s.cmds <- cmd{op: sub, clientID: clientID, query: query, subscription: subscription}:
 		s.mtx.Lock()
 		if _, ok = s.subscriptions[clientID]; !ok {
-			s.subscriptions[clientID] = make(map[string]struct{})
+			s.subscriptions[clientID] = make(map[string]*Subscription)
 		}
-		s.subscriptions[clientID][query.String()] = struct{}{}
+		s.subscriptions[clientID][query.String()] = subscription
 		s.mtx.Unlock()
 		return subscription, nil
 	case <-ctx.Done():
@@ -201,15 +233,18 @@ func (s *Server) subscribe(ctx context.Context, clientID string, query Query, ou
 // returned to the caller if the context is canceled or if subscription does
 // not exist.
 func (s *Server) Unsubscribe(ctx context.Context, clientID string, query Query) error {
+	var subscription *Subscription
 	s.mtx.RLock()
 	clientSubscriptions, ok := s.subscriptions[clientID]
 	if ok {
-		_, ok = clientSubscriptions[query.String()]
+		subscription, ok = clientSubscriptions[query.String()]
 	}
 	s.mtx.RUnlock()
 	if !ok {
 		return ErrSubscriptionNotFound
 	}
+	// the server may be blocked on this very subscription
+	subscription.unsubscribe()
 
 	select {
 	case s.cmds <- cmd{op: unsub, clientID: clientID, query: query}:
@@ -221,6 +256,8 @@ func (s *Server) Unsubscribe(ctx context.Context, clientID string, query Query)
 		s.mtx.Unlock()
 		return nil
 	case <-ctx.Done():
+		// the subscription is still served
+		subscription.undoUnsubscribe()
 		return ctx.Err()
 	case <-s.Quit():
 		return nil
@@ -231,7 +268,10 @@ func (s *Server) Unsubscribe(ctx context.Context, clientID string, query Query)
 // to the caller if the context is canceled or if subscription does not exist.
 func (s *Server) UnsubscribeAll(ctx context.Context, clientID string) error {
 	s.mtx.RLock()
-	_, ok := s.subscriptions[clientID]
+	clientSubscriptions, ok := s.subscriptions[clientID]
+	for _, subscription := range clientSubscriptions {
+		subscription.unsubscribe()
+	}
 	s.mtx.RUnlock()
 	if !ok {
 		return ErrSubscriptionNotFound
@@ -244,6 +284,10 @@ func (s *Server) UnsubscribeAll(ctx context.Context, clientID string) error {
 		s.mtx.Unlock()
 		return nil
 	case <-ctx.Done():
+		// the subscriptions are still served
+		for _, subscription := range clientSubscriptions {
+			subscription.undoUnsubscribe()
+		}
 		return ctx.Err()
 	case <-s.Quit():
 		return nil
@@ -286,6 +330,7 @@ func (s *Server) PublishWithEvents(ctx context.Context, msg interface{}, events
 
 // OnStop implements Service.OnStop by shutting down the server.
 func (s *Server) OnStop() {
+	close(s.stopping)
 	s.cmds <- cmd{op: shutdown}
 }
 
@@ -315,6 +360,7 @@ func (s *Server) OnStart() error {
 
 // OnReset implements Service.OnReset
 func (s *Server) OnReset() error {
+	s.stopping = make(chan struct{})
 	return nil
 }
 
@@ -334,7 +380,7 @@ loop:
 		case sub:
 			state.add(cmd.clientID, cmd.query, cmd.subscription)
 		case pub:
-			if err := state.send(cmd.msg, cmd.events); err != nil {
+			if err := state.send(cmd.msg, cmd.events, s.stopping); err != nil {
 				s.Logger.Error("Error querying for events", "err", err)
 			}
 		}
@@ -403,7 +449,7 @@ func (state *state) removeAll(reason error) {
 	}
 }
 
-func (state *state) send(msg interface{}, events map[string][]string) error {
+func (state *state) send(msg interface{}, events map[string][]string, done <-chan struct{}) error {
 	for qStr, clientSubscriptions := range state.subscriptions {
 		q := state.queries[qStr].q
 
@@ -414,16 +460,8 @@ func (state *state) send(msg interface{}, events map[string][]string) error {
 
 		if match {
 			for clientID, subscription := range clientSubscriptions {
-				if cap(subscription.out) == 0 {
-					// block on unbuffered channel
-					subscription.out <- NewMessage(msg, events)
-				} else {
-					// don't block on buffered channels
-					select {
-					case subscription.out <- NewMessage(msg, events):
-					default:
-						state.remove(clientID, qStr, ErrOutOfCapacity)
-					}
+				if !subscription.deliver(NewMessage(msg, events), done) {
+					state.remove(clientID, qStr, ErrOutOfCapacity)
 				}
 			}
 		}
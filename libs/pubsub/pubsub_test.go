@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"strconv"
 	"testing"
 	"time"
 
@@ -127,6 +128,144 @@ func TestSlowClientIsRemovedWithErrOutOfCapacity(t *testing.T) {
 	assertCancelled(t, subscription, pubsub.ErrOutOfCapacity)
 }
 
+func TestSubscribeWithPolicyDropOldest(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	s.Start()
+	defer s.Stop()
+
+	ctx := context.Background()
+	subscription, err := s.SubscribeWithPolicy(ctx, clientID, query.Empty{}, 2, pubsub.OverflowDropOldest)
+	require.NoError(t, err)
+	assert.Equal(t, pubsub.OverflowDropOldest, subscription.Policy())
+	for _, msg := range []string{"Fat Cobra", "Viper", "Black Mamba"} {
+		err = s.Publish(ctx, msg)
+		require.NoError(t, err)
+	}
+	// sync with the server
+	err = s.Unsubscribe(ctx, clientID, query.Empty{})
+	require.NoError(t, err)
+
+	assertReceive(t, "Viper", subscription.Out())
+	assertReceive(t, "Black Mamba", subscription.Out())
+	assert.EqualValues(t, 1, subscription.Dropped())
+	assertCancelled(t, subscription, pubsub.ErrUnsubscribed)
+}
+
+func TestSubscribeWithPolicyBlock(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	s.Start()
+	defer s.Stop()
+
+	ctx := context.Background()
+	subscription, err := s.SubscribeWithPolicy(ctx, clientID, query.Empty{}, 1, pubsub.OverflowBlock)
+	require.NoError(t, err)
+	err = s.Publish(ctx, "Fat Cobra")
+	require.NoError(t, err)
+	err = s.Publish(ctx, "Viper")
+	require.NoError(t, err)
+
+	assertReceive(t, "Fat Cobra", subscription.Out())
+	assertReceive(t, "Viper", subscription.Out())
+	assert.Nil(t, subscription.Err())
+}
+
+func TestBlockedSubscriberCanUnsubscribe(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	s.Start()
+	defer s.Stop()
+
+	ctx := context.Background()
+	subscription, err := s.SubscribeUnbuffered(ctx, clientID, query.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, pubsub.OverflowBlock, subscription.Policy())
+	// blocks the server until the client unsubscribes
+	err = s.Publish(ctx, "Fat Cobra")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	err = s.Unsubscribe(ctx, clientID, query.Empty{})
+	require.NoError(t, err)
+	assertCancelled(t, subscription, pubsub.ErrUnsubscribed)
+
+	// other subscribers are served again
+	subscription, err = s.Subscribe(ctx, "other-client", query.Empty{})
+	require.NoError(t, err)
+	err = s.Publish(ctx, "Viper")
+	require.NoError(t, err)
+	assertReceive(t, "Viper", subscription.Out())
+}
+
+func TestCancelledUnsubscribeKeepsSubscription(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	s.Start()
+	defer s.Stop()
+
+	ctx := context.Background()
+	subscription, err := s.SubscribeUnbuffered(ctx, clientID, query.MustParse("tm.events.type='NewBlock'"))
+	require.NoError(t, err)
+	blocking, err := s.SubscribeUnbuffered(ctx, "other-client", query.MustParse("tm.events.type='Tx'"))
+	require.NoError(t, err)
+	// blocks the server, so the unsubscription can't be sent
+	err = s.PublishWithEvents(ctx, "Fat Cobra", map[string][]string{"tm.events.type": {"Tx"}})
+	require.NoError(t, err)
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = s.Unsubscribe(cancelledCtx, clientID, query.MustParse("tm.events.type='NewBlock'"))
+	assert.Equal(t, context.Canceled, err)
+	err = s.UnsubscribeAll(cancelledCtx, clientID)
+	assert.Equal(t, context.Canceled, err)
+	assertReceive(t, "Fat Cobra", blocking.Out())
+
+	// the subscription isn't skipped
+	err = s.PublishWithEvents(ctx, "Viper", map[string][]string{"tm.events.type": {"NewBlock"}})
+	require.NoError(t, err)
+	assertReceive(t, "Viper", subscription.Out())
+	assert.Nil(t, subscription.Err())
+}
+
+func TestStopWithBlockedSubscriber(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	s.Start()
+
+	ctx := context.Background()
+	subscription, err := s.SubscribeUnbuffered(ctx, clientID, query.Empty{})
+	require.NoError(t, err)
+	err = s.Publish(ctx, "Fat Cobra")
+	require.NoError(t, err)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked on the subscriber")
+	}
+	assertCancelled(t, subscription, nil)
+}
+
+func TestSubscribeWithPolicyInvalidCapacity(t *testing.T) {
+	s := pubsub.NewServer()
+	s.SetLogger(log.TestingLogger())
+	s.Start()
+	defer s.Stop()
+
+	ctx := context.Background()
+	_, err := s.SubscribeWithPolicy(ctx, clientID, query.Empty{}, 0, pubsub.OverflowDropOldest)
+	assert.Error(t, err)
+	_, err = s.SubscribeWithPolicy(ctx, clientID, query.Empty{}, -1, pubsub.OverflowBlock)
+	assert.Error(t, err)
+}
+
 func TestDifferentClients(t *testing.T) {
 	s := pubsub.NewServer()
 	s.SetLogger(log.TestingLogger())
@@ -382,7 +521,7 @@ func benchmarkNClients(n int, b *testing.B) {
 		s.PublishWithEvents(
 			ctx,
 			"Gamora",
-			map[string][]string{"abci.Account.Owner": {"Ivan"}, "abci.Invoices.Number": {string(i)}},
+			map[string][]string{"abci.Account.Owner": {"Ivan"}, "abci.Invoices.Number": {strconv.Itoa(i)}},
 		)
 	}
 }
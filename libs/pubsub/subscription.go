@@ -2,6 +2,7 @@ package pubsub
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 )
 
@@ -14,24 +15,77 @@ var (
 	ErrOutOfCapacity = errors.New("client is not pulling messages fast enough")
 )
 
+// OverflowPolicy declares what happens when a message is published to a
+// subscription whose buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowTerminate terminates the subscription with ErrOutOfCapacity.
+	OverflowTerminate OverflowPolicy = iota
+	// OverflowBlock blocks the publisher until the subscriber receives the
+	// message, the subscriber unsubscribes or the server is stopped. Use with
+	// caution: a slow subscriber stalls all publishers.
+	OverflowBlock
+	// OverflowDropOldest drops the oldest buffered message to make room for
+	// the new one. Dropped messages are counted (see Subscription.Dropped).
+	OverflowDropOldest
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowTerminate:
+		return "terminate"
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
 // A Subscription represents a client subscription for a particular query and
 // consists of three things:
 // 1) channel onto which messages and events are published
 // 2) channel which is closed if a client is too slow or choose to unsubscribe
 // 3) err indicating the reason for (2)
 type Subscription struct {
-	out chan Message
+	out    chan Message
+	policy OverflowPolicy
 
 	cancelled chan struct{}
 	mtx       sync.RWMutex
 	err       error
+	dropped   int64
+
+	// closed while the client unsubscribes, to unblock a blocked publisher
+	unsubscribing chan struct{}
 }
 
-// NewSubscription returns a new subscription with the given outCapacity.
+// NewSubscription returns a new subscription with the given outCapacity. If
+// the capacity is zero, the publisher blocks until the subscriber receives
+// each message (OverflowBlock); otherwise, the subscription is terminated once
+// its buffer is full (OverflowTerminate).
 func NewSubscription(outCapacity int) *Subscription {
+	policy := OverflowTerminate
+	if outCapacity == 0 {
+		policy = OverflowBlock
+	}
+	return NewSubscriptionWithPolicy(outCapacity, policy)
+}
+
+// NewSubscriptionWithPolicy returns a new subscription with the given
+// outCapacity and overflow policy. Panics if the capacity is zero and the
+// policy is not OverflowBlock.
+func NewSubscriptionWithPolicy(outCapacity int, policy OverflowPolicy) *Subscription {
+	if outCapacity == 0 && policy != OverflowBlock {
+		panic(fmt.Sprintf("zero capacity requires the block overflow policy, given %v", policy))
+	}
 	return &Subscription{
-		out:       make(chan Message, outCapacity),
-		cancelled: make(chan struct{}),
+		out:           make(chan Message, outCapacity),
+		policy:        policy,
+		cancelled:     make(chan struct{}),
+		unsubscribing: make(chan struct{}),
 	}
 }
 
@@ -61,6 +115,82 @@ func (s *Subscription) Err() error {
 	return s.err
 }
 
+// Policy returns the overflow policy of the subscription.
+func (s *Subscription) Policy() OverflowPolicy {
+	return s.policy
+}
+
+// Dropped returns the number of messages dropped because the buffer was full
+// (see OverflowDropOldest).
+func (s *Subscription) Dropped() int64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.dropped
+}
+
+// deliver sends msg to the subscriber according to the overflow policy. It
+// returns false if the subscription must be terminated, because its buffer
+// is full. A blocked send is abandoned once done is closed.
+func (s *Subscription) deliver(msg Message, done <-chan struct{}) bool {
+	switch s.policy {
+	case OverflowBlock:
+		s.mtx.RLock()
+		unsubscribing := s.unsubscribing
+		s.mtx.RUnlock()
+		select {
+		case s.out <- msg:
+		case <-unsubscribing:
+		case <-done:
+		}
+		return true
+	case OverflowDropOldest:
+		for {
+			select {
+			case s.out <- msg:
+				return true
+			default:
+			}
+			select {
+			case <-s.out:
+				s.mtx.Lock()
+				s.dropped++
+				s.mtx.Unlock()
+			default:
+			}
+		}
+	default:
+		select {
+		case s.out <- msg:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// unsubscribe unblocks a publisher blocked on the subscription, and makes it
+// skip the subscription until the server removes it.
+func (s *Subscription) unsubscribe() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	select {
+	case <-s.unsubscribing:
+	default:
+		close(s.unsubscribing)
+	}
+}
+
+// undoUnsubscribe reverts unsubscribe, when the client failed to unsubscribe.
+func (s *Subscription) undoUnsubscribe() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	select {
+	case <-s.unsubscribing:
+		s.unsubscribing = make(chan struct{})
+	default:
+	}
+}
+
 func (s *Subscription) cancel(err error) {
 	s.mtx.Lock()
 	s.err = err
@@ -0,0 +1,90 @@
+package rand
+
+import (
+	"fmt"
+	mrand "math/rand"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// TestSeedEnv is the environment variable read by SetDeterministicForTesting.
+const TestSeedEnv = "TM_TEST_SEED"
+
+var (
+	detMtx sync.Mutex
+	// seeds of the generators created by NewRand; nil unless deterministic
+	detSeeds *mrand.Rand
+)
+
+// SetDeterministic makes this package reproducible from seed, for tests: the
+// global generator is reseeded, and the generators created by NewRand from now
+// on (e.g. for the peer selection and dial timers of p2p) are seeded with a
+// sequence derived from seed, in place of OS randomness. The same seed yields
+// the same numbers as long as the generators are created and used in the same
+// order.
+//
+// Deterministic randomness must never be enabled outside of tests. It doesn't
+// affect crypto randomness (crypto.CRandBytes and the keys it generates).
+func SetDeterministic(seed int64) {
+	detMtx.Lock()
+	detSeeds = mrand.New(mrand.NewSource(seed))
+	globalSeed := detSeeds.Int63()
+	detMtx.Unlock()
+	grand.Seed(globalSeed)
+}
+
+// ResetDeterministic disables deterministic randomness: the global generator
+// and the generators created by NewRand are seeded with OS randomness again.
+func ResetDeterministic() {
+	detMtx.Lock()
+	detSeeds = nil
+	detMtx.Unlock()
+	grand.Lock()
+	grand.init()
+	grand.Unlock()
+}
+
+// IsDeterministic returns whether deterministic randomness is enabled.
+func IsDeterministic() bool {
+	detMtx.Lock()
+	defer detMtx.Unlock()
+	return detSeeds != nil
+}
+
+// SetDeterministicForTesting enables deterministic randomness with the seed of
+// the TM_TEST_SEED environment variable, or with a random seed if it isn't
+// set. The seed is printed and returned, so that a failing run can be
+// reproduced with TM_TEST_SEED=<seed>. It is meant to be called from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		tmrand.SetDeterministicForTesting()
+//		os.Exit(m.Run())
+//	}
+func SetDeterministicForTesting() int64 {
+	var seed int64
+	if s := os.Getenv(TestSeedEnv); s != "" {
+		var err error
+		seed, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("invalid %s %q: %v", TestSeedEnv, s, err))
+		}
+	} else {
+		seed = NewRand().Int63()
+	}
+	fmt.Printf("libs/rand: deterministic randomness with seed %d (set %s=%d to reproduce)\n",
+		seed, TestSeedEnv, seed)
+	SetDeterministic(seed)
+	return seed
+}
+
+// nextDeterministicSeed returns the seed of the next generator, if
+// deterministic randomness is enabled.
+func nextDeterministicSeed() (int64, bool) {
+	detMtx.Lock()
+	defer detMtx.Unlock()
+	if detSeeds == nil {
+		return 0, false
+	}
+	return detSeeds.Int63(), true
+}
@@ -0,0 +1,69 @@
+package rand
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample draws from the global generator and from new generators.
+func sample() []int64 {
+	out := []int64{Int63()}
+	for i := 0; i < 3; i++ {
+		r := NewRand()
+		out = append(out, r.Int63(), int64(r.Intn(1000)))
+	}
+	return out
+}
+
+func TestSetDeterministic(t *testing.T) {
+	defer ResetDeterministic()
+
+	SetDeterministic(42)
+	assert.True(t, IsDeterministic())
+	first := sample()
+	SetDeterministic(42)
+	assert.Equal(t, first, sample())
+	SetDeterministic(43)
+	assert.NotEqual(t, first, sample())
+
+	ResetDeterministic()
+	assert.False(t, IsDeterministic())
+	assert.NotEqual(t, first, sample())
+}
+
+func TestSetDeterministicForTesting(t *testing.T) {
+	defer ResetDeterministic()
+	defer os.Unsetenv(TestSeedEnv)
+
+	require.NoError(t, os.Setenv(TestSeedEnv, "7"))
+	assert.EqualValues(t, 7, SetDeterministicForTesting())
+	first := sample()
+	SetDeterministic(7)
+	assert.Equal(t, first, sample())
+
+	require.NoError(t, os.Unsetenv(TestSeedEnv))
+	seed := SetDeterministicForTesting()
+	second := sample()
+	require.NoError(t, os.Setenv(TestSeedEnv, strconv.FormatInt(seed, 10)))
+	SetDeterministicForTesting()
+	assert.Equal(t, second, sample())
+
+	require.NoError(t, os.Setenv(TestSeedEnv, "not a number"))
+	assert.Panics(t, func() { SetDeterministicForTesting() })
+}
+
+func TestNewSeededRand(t *testing.T) {
+	a, b := NewSeededRand(1), NewSeededRand(1)
+	assert.Equal(t, a.Perm(10), b.Perm(10))
+	assert.Equal(t, a.Str(20), b.Str(20))
+
+	s1, s2 := []int{0, 1, 2, 3, 4, 5, 6, 7}, []int{0, 1, 2, 3, 4, 5, 6, 7}
+	a.Shuffle(len(s1), func(i, j int) { s1[i], s1[j] = s1[j], s1[i] })
+	b.Shuffle(len(s2), func(i, j int) { s2[i], s2[j] = s2[j], s2[i] })
+	assert.Equal(t, s1, s2)
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7}, s1)
+}
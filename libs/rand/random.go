@@ -11,9 +11,11 @@ const (
 	strChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz" // 62 characters
 )
 
-// Rand is a prng, that is seeded with OS randomness.
+// Rand is a prng, that is seeded with OS randomness, unless deterministic
+// randomness is enabled for tests (see SetDeterministic).
 // The OS randomness is obtained from crypto/rand, however none of the provided
-// methods are suitable for cryptographic usage.
+// methods are suitable for cryptographic usage: use crypto.CRandBytes instead,
+// which is never deterministic.
 // They all utilize math/rand's prng internally.
 //
 // All of the methods here are suitable for concurrent use.
@@ -30,13 +32,30 @@ func init() {
 	grand.init()
 }
 
+// NewRand returns a new Rand, seeded with OS randomness, or with the next
+// seed derived from the seed of SetDeterministic if it is enabled.
 func NewRand() *Rand {
 	rand := &Rand{}
 	rand.init()
 	return rand
 }
 
+// NewSeededRand returns a new Rand seeded with seed.
+func NewSeededRand(seed int64) *Rand {
+	return NewRandFromSource(mrand.NewSource(seed))
+}
+
+// NewRandFromSource returns a new Rand drawing from src, e.g. to inject a
+// custom sequence in tests. src doesn't need to be safe for concurrent use.
+func NewRandFromSource(src mrand.Source) *Rand {
+	return &Rand{rand: mrand.New(src)}
+}
+
 func (r *Rand) init() {
+	if seed, ok := nextDeterministicSeed(); ok {
+		r.reset(seed)
+		return
+	}
 	bz := cRandBytes(8)
 	var seed uint64
 	for i := 0; i < 8; i++ {
@@ -133,6 +152,10 @@ func Intn(n int) int {
 	return grand.Intn(n)
 }
 
+func Shuffle(n int, swap func(i, j int)) {
+	grand.Shuffle(n, swap)
+}
+
 func Perm(n int) []int {
 	return grand.Perm(n)
 }
@@ -293,6 +316,13 @@ func (r *Rand) Perm(n int) []int {
 	return perm
 }
 
+// Shuffle pseudo-randomizes the order of n elements, swapped by swap.
+func (r *Rand) Shuffle(n int, swap func(i, j int)) {
+	r.Lock()
+	r.rand.Shuffle(n, swap)
+	r.Unlock()
+}
+
 // NOTE: This relies on the os's random number generator.
 // For real security, we should salt that with some seed.
 // See github.com/tendermint/tendermint/crypto for a more secure reader.
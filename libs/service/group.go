@@ -0,0 +1,334 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// State is the state of a service in a Group.
+type State uint8
+
+const (
+	// StatePending means the service has not been started by the group yet.
+	StatePending State = iota
+	// StateRunning means the service has started.
+	StateRunning
+	// StateStopped means the service has been stopped, either by the group or
+	// by itself.
+	StateStopped
+	// StateFailed means the service failed to start.
+	StateFailed
+	// StateSkipped means the service wasn't started because one of its
+	// dependencies failed to start.
+	StateSkipped
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "pending"
+	case StateRunning:
+		return "running"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	case StateSkipped:
+		return "skipped"
+	default:
+		return fmt.Sprintf("State(%d)", uint8(s))
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so that the state is encoded
+// by name.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Status is the status of a service in a Group, along with the statuses of the
+// services depending on it.
+type Status struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	// Error is why the service failed, was skipped or was stopped by the group
+	// before the group was stopped.
+	Error      string   `json:"error,omitempty"`
+	Dependents []Status `json:"dependents,omitempty"`
+}
+
+type groupMember struct {
+	name  string
+	svc   Service
+	deps  []string
+	state State
+	err   error
+}
+
+/*
+Group starts and stops services in the order of their declared dependencies.
+
+Services are started in topological order, services without a dependency
+between them in the order they were added, and stopped in reverse. If a
+service fails to start, the services depending on it are skipped, and the
+services started so far are stopped again. If a service stops by itself while
+the group is running, the services depending on it are stopped.
+
+A service which is already running when the group is started, e.g. because it
+had to be started earlier, is adopted as is.
+
+	g := NewGroup(logger)
+	g.Add("events", eventBus)
+	g.Add("txindex", indexerService, "events")
+	if err := g.Start(); err != nil {
+		return err
+	}
+	defer g.Stop()
+*/
+type Group struct {
+	mtx     sync.Mutex
+	logger  log.Logger
+	members []*groupMember
+	byName  map[string]*groupMember
+	order   []*groupMember // start order, set by Start
+	started bool
+	stopped bool
+	quit    chan struct{}
+}
+
+// NewGroup returns an empty group.
+func NewGroup(logger log.Logger) *Group {
+	if logger == nil {
+		logger = log.NewNopLogger()
+	}
+	return &Group{
+		logger: logger,
+		byName: make(map[string]*groupMember),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Add adds svc to the group under the given name. The services named by deps
+// are started before svc and stopped after it. They may be added after svc,
+// but before the group is started.
+func (g *Group) Add(name string, svc Service, deps ...string) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.started {
+		return errors.Errorf("can't add %s: group already started", name)
+	}
+	if _, ok := g.byName[name]; ok {
+		return errors.Errorf("duplicate service %s", name)
+	}
+	m := &groupMember{name: name, svc: svc, deps: deps}
+	g.members = append(g.members, m)
+	g.byName[name] = m
+	return nil
+}
+
+// Start starts the services in order. If a service fails to start, the
+// services started so far are stopped and the error is returned.
+func (g *Group) Start() error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if g.started {
+		return ErrAlreadyStarted
+	}
+	order, err := g.sort()
+	if err != nil {
+		return err
+	}
+	g.order = order
+	g.started = true
+
+	for i, m := range order {
+		if m.state == StateSkipped {
+			continue
+		}
+		err := m.svc.Start()
+		if err == ErrAlreadyStarted && m.svc.IsRunning() {
+			err = nil
+		}
+		if err != nil {
+			m.state, m.err = StateFailed, err
+			g.logger.Error("Failed to start service", "service", m.name, "err", err)
+			g.skipDependents(m)
+			for j := i - 1; j >= 0; j-- {
+				g.stopMember(order[j], nil)
+			}
+			g.stopped = true
+			close(g.quit)
+			return errors.Wrapf(err, "failed to start %s", m.name)
+		}
+		m.state = StateRunning
+		go g.watch(m)
+	}
+	return nil
+}
+
+// Stop stops the running services in the reverse order they were started.
+func (g *Group) Stop() error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	if !g.started {
+		return ErrNotStarted
+	}
+	if g.stopped {
+		return ErrAlreadyStopped
+	}
+	g.stopped = true
+	close(g.quit)
+	for i := len(g.order) - 1; i >= 0; i-- {
+		g.stopMember(g.order[i], nil)
+	}
+	return nil
+}
+
+// Status returns the statuses of the services without dependencies, each
+// including the statuses of the services depending on it, recursively. A
+// service with several dependencies is listed under each of them.
+func (g *Group) Status() []Status {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	roots := []Status{}
+	for _, m := range g.members {
+		if len(m.deps) == 0 {
+			roots = append(roots, g.status(m))
+		}
+	}
+	return roots
+}
+
+func (g *Group) status(m *groupMember) Status {
+	s := Status{Name: m.name, State: m.state}
+	if m.err != nil {
+		s.Error = m.err.Error()
+	}
+	for _, d := range g.dependents(m) {
+		s.Dependents = append(s.Dependents, g.status(d))
+	}
+	return s
+}
+
+// sort returns the members in topological order. It fails if a dependency is
+// unknown or the dependencies form a cycle.
+func (g *Group) sort() ([]*groupMember, error) {
+	for _, m := range g.members {
+		for _, dep := range m.deps {
+			if _, ok := g.byName[dep]; !ok {
+				return nil, errors.Errorf("%s depends on unknown service %s", m.name, dep)
+			}
+		}
+	}
+
+	order := make([]*groupMember, 0, len(g.members))
+	added := make(map[*groupMember]bool, len(g.members))
+	for len(order) < len(g.members) {
+		progress := false
+		for _, m := range g.members {
+			if added[m] || !g.depsAdded(m, added) {
+				continue
+			}
+			order = append(order, m)
+			added[m] = true
+			progress = true
+		}
+		if !progress {
+			var cycle []string
+			for _, m := range g.members {
+				if !added[m] {
+					cycle = append(cycle, m.name)
+				}
+			}
+			return nil, errors.Errorf("dependency cycle between %v", cycle)
+		}
+	}
+	return order, nil
+}
+
+func (g *Group) depsAdded(m *groupMember, added map[*groupMember]bool) bool {
+	for _, dep := range m.deps {
+		if !added[g.byName[dep]] {
+			return false
+		}
+	}
+	return true
+}
+
+// dependents returns the members depending directly on m.
+func (g *Group) dependents(m *groupMember) []*groupMember {
+	var dependents []*groupMember
+	for _, d := range g.members {
+		for _, dep := range d.deps {
+			if dep == m.name {
+				dependents = append(dependents, d)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// skipDependents marks the members depending on m, recursively, as skipped.
+func (g *Group) skipDependents(m *groupMember) {
+	for _, d := range g.dependents(m) {
+		if d.state == StatePending {
+			d.state, d.err = StateSkipped, errors.Errorf("dependency %s failed", m.name)
+			g.skipDependents(d)
+		}
+	}
+}
+
+// stopMember stops m if it is running, recording reason as its error.
+func (g *Group) stopMember(m *groupMember, reason error) {
+	if m.state != StateRunning {
+		return
+	}
+	m.state, m.err = StateStopped, reason
+	if err := m.svc.Stop(); err != nil && err != ErrAlreadyStopped {
+		g.logger.Error("Error stopping service", "service", m.name, "err", err)
+	}
+}
+
+// watch stops the dependents of m if m stops while the group is running.
+func (g *Group) watch(m *groupMember) {
+	select {
+	case <-m.svc.Quit():
+	case <-g.quit:
+		return
+	}
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if g.stopped || m.state != StateRunning {
+		return
+	}
+	g.logger.Error("Service stopped, stopping its dependents", "service", m.name)
+	m.state = StateStopped
+
+	// stop the transitive dependents in the reverse order they were started
+	stop := map[*groupMember]bool{m: true}
+	for _, o := range g.order {
+		for _, dep := range o.deps {
+			if stop[g.byName[dep]] {
+				stop[o] = true
+				break
+			}
+		}
+	}
+	reason := errors.Errorf("dependency %s stopped", m.name)
+	for i := len(g.order) - 1; i >= 0; i-- {
+		if o := g.order[i]; o != m && stop[o] {
+			g.stopMember(o, reason)
+		}
+	}
+}
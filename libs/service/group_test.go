@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderService records the order services are started and stopped in.
+type orderService struct {
+	BaseService
+	log      *[]string
+	mtx      *sync.Mutex
+	startErr error
+}
+
+func newOrderService(name string, log *[]string, mtx *sync.Mutex) *orderService {
+	s := &orderService{log: log, mtx: mtx}
+	s.BaseService = *NewBaseService(nil, name, s)
+	return s
+}
+
+func (s *orderService) OnStart() error {
+	if s.startErr != nil {
+		return s.startErr
+	}
+	s.mtx.Lock()
+	*s.log = append(*s.log, "start "+s.String())
+	s.mtx.Unlock()
+	return nil
+}
+
+func (s *orderService) OnStop() {
+	s.mtx.Lock()
+	*s.log = append(*s.log, "stop "+s.String())
+	s.mtx.Unlock()
+}
+
+func TestGroupStartStopOrder(t *testing.T) {
+	var (
+		log []string
+		mtx sync.Mutex
+	)
+	g := NewGroup(nil)
+	// added before its dependency
+	require.NoError(t, g.Add("c", newOrderService("c", &log, &mtx), "a", "b"))
+	require.NoError(t, g.Add("a", newOrderService("a", &log, &mtx)))
+	require.NoError(t, g.Add("b", newOrderService("b", &log, &mtx), "a"))
+	assert.Error(t, g.Add("a", newOrderService("a", &log, &mtx)))
+
+	require.NoError(t, g.Start())
+	require.NoError(t, g.Stop())
+	assert.Equal(t, []string{"start a", "start b", "start c", "stop c", "stop b", "stop a"}, log)
+	assert.Equal(t, ErrAlreadyStopped, g.Stop())
+}
+
+func TestGroupInvalidDependencies(t *testing.T) {
+	var (
+		log []string
+		mtx sync.Mutex
+	)
+	g := NewGroup(nil)
+	require.NoError(t, g.Add("a", newOrderService("a", &log, &mtx), "x"))
+	assert.Error(t, g.Start())
+
+	g = NewGroup(nil)
+	require.NoError(t, g.Add("a", newOrderService("a", &log, &mtx), "b"))
+	require.NoError(t, g.Add("b", newOrderService("b", &log, &mtx), "a"))
+	assert.Error(t, g.Start())
+	assert.Empty(t, log)
+}
+
+func TestGroupStartFailure(t *testing.T) {
+	var (
+		log []string
+		mtx sync.Mutex
+	)
+	a, b := newOrderService("a", &log, &mtx), newOrderService("b", &log, &mtx)
+	c, d := newOrderService("c", &log, &mtx), newOrderService("d", &log, &mtx)
+	b.startErr = errors.New("boom")
+	g := NewGroup(nil)
+	require.NoError(t, g.Add("a", a))
+	require.NoError(t, g.Add("b", b, "a"))
+	require.NoError(t, g.Add("c", c, "b"))
+	require.NoError(t, g.Add("d", d, "a"))
+
+	assert.Error(t, g.Start())
+	assert.Equal(t, []string{"start a", "stop a"}, log)
+	assert.False(t, c.IsRunning())
+	assert.False(t, d.IsRunning())
+
+	assert.Equal(t, []Status{{
+		Name:  "a",
+		State: StateStopped,
+		Dependents: []Status{
+			{Name: "b", State: StateFailed, Error: "boom", Dependents: []Status{
+				{Name: "c", State: StateSkipped, Error: "dependency b failed"},
+			}},
+			{Name: "d", State: StatePending},
+		},
+	}}, g.Status())
+}
+
+func TestGroupAdoptsRunningService(t *testing.T) {
+	var (
+		log []string
+		mtx sync.Mutex
+	)
+	a := newOrderService("a", &log, &mtx)
+	require.NoError(t, a.Start())
+	g := NewGroup(nil)
+	require.NoError(t, g.Add("a", a))
+	require.NoError(t, g.Start())
+	assert.Equal(t, StateRunning, g.Status()[0].State)
+	require.NoError(t, g.Stop())
+	assert.False(t, a.IsRunning())
+}
+
+func TestGroupStopsDependentsOfStoppedService(t *testing.T) {
+	var (
+		log []string
+		mtx sync.Mutex
+	)
+	a, b := newOrderService("a", &log, &mtx), newOrderService("b", &log, &mtx)
+	c, d := newOrderService("c", &log, &mtx), newOrderService("d", &log, &mtx)
+	g := NewGroup(nil)
+	require.NoError(t, g.Add("a", a))
+	require.NoError(t, g.Add("b", b, "a"))
+	require.NoError(t, g.Add("c", c, "b"))
+	require.NoError(t, g.Add("d", d, "a"))
+	require.NoError(t, g.Start())
+
+	require.NoError(t, b.Stop())
+	assert.Eventually(t, func() bool { return !c.IsRunning() }, time.Second, 10*time.Millisecond)
+	assert.True(t, a.IsRunning())
+	assert.True(t, d.IsRunning())
+
+	status := g.Status()[0]
+	assert.Equal(t, StateStopped, status.Dependents[0].State)
+	assert.Equal(t, "dependency b stopped", status.Dependents[0].Dependents[0].Error)
+
+	require.NoError(t, g.Stop())
+	assert.False(t, a.IsRunning())
+	assert.False(t, d.IsRunning())
+}
@@ -0,0 +1,66 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// NewLogger returns a logger correlating the lines of next with the span of
+// ctx: lines are annotated with the trace_id and span_id of the span, and
+// info and error lines are also added as events to the span. It returns next
+// if ctx has no span.
+func NewLogger(ctx context.Context, next log.Logger) log.Logger {
+	span := trace.SpanFromContext(ctx)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		return next
+	}
+	return &spanLogger{
+		next: next.With("trace_id", sc.TraceID.String(), "span_id", sc.SpanID.String()),
+		ctx:  ctx,
+		span: span,
+	}
+}
+
+type spanLogger struct {
+	next    log.Logger
+	ctx     context.Context
+	span    trace.Span
+	keyvals []interface{} // of With, added to the events
+}
+
+func (l *spanLogger) Debug(msg string, keyvals ...interface{}) {
+	l.next.Debug(msg, keyvals...)
+}
+
+func (l *spanLogger) Info(msg string, keyvals ...interface{}) {
+	l.addEvent("info", msg, keyvals)
+	l.next.Info(msg, keyvals...)
+}
+
+func (l *spanLogger) Error(msg string, keyvals ...interface{}) {
+	l.addEvent("error", msg, keyvals)
+	l.next.Error(msg, keyvals...)
+}
+
+func (l *spanLogger) With(keyvals ...interface{}) log.Logger {
+	return &spanLogger{
+		next:    l.next.With(keyvals...),
+		ctx:     l.ctx,
+		span:    l.span,
+		keyvals: append(append([]interface{}{}, l.keyvals...), keyvals...),
+	}
+}
+
+func (l *spanLogger) addEvent(level, msg string, keyvals []interface{}) {
+	if !l.span.IsRecording() {
+		return
+	}
+	attrs := append([]interface{}{}, l.keyvals...)
+	attrs = append(attrs, keyvals...)
+	l.span.AddEvent(l.ctx, msg, append(attributes(attrs), key.String("level", level))...)
+}
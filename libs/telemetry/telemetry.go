@@ -0,0 +1,206 @@
+// Package telemetry exports traces and metrics with OpenTelemetry, over OTLP,
+// in addition to the Prometheus metrics. Telemetry is optional: until a
+// Provider is set with SetProvider, spans started by StartSpan are no-ops.
+package telemetry
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/metric"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	metricexport "go.opentelemetry.io/otel/sdk/export/metric"
+	traceexport "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/metric/batcher/ungrouped"
+	"go.opentelemetry.io/otel/sdk/metric/controller/push"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/resource/resourcekeys"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	serviceName = "tendermint"
+
+	// InstrumentationName is the name of the tracers and meters of Tendermint.
+	InstrumentationName = "github.com/tendermint/tendermint"
+
+	defaultMetricsPushInterval = 10 * time.Second
+)
+
+// Resource identifies the node producing the telemetry. Its fields are
+// attached to every span and metric.
+type Resource struct {
+	ChainID string
+	NodeID  string
+	Moniker string
+}
+
+func (r Resource) attributes() []core.KeyValue {
+	return []core.KeyValue{
+		key.String(resourcekeys.ServiceKeyName, serviceName),
+		key.String(resourcekeys.ServiceKeyNamespace, r.ChainID),
+		key.String(resourcekeys.ServiceKeyInstanceID, r.NodeID),
+		key.String("tendermint.chain_id", r.ChainID),
+		key.String("tendermint.node_id", r.NodeID),
+		key.String("tendermint.moniker", r.Moniker),
+	}
+}
+
+// Config configures the export of telemetry.
+type Config struct {
+	// Address (host:port) of the OTLP collector.
+	Endpoint string
+	// Whether to connect to the collector without TLS.
+	Insecure bool
+	// Fraction of the traces sampled, in [0, 1].
+	TraceSampleRate float64
+	// Interval between two exports of the metrics. Defaults to 10s.
+	MetricsPushInterval time.Duration
+}
+
+// Provider provides tracers and meters exporting to an OTLP collector.
+type Provider struct {
+	traces     *sdktrace.Provider
+	processor  sdktrace.SpanProcessor
+	metrics    *push.Controller
+	stopExport func() error
+
+	stopOnce sync.Once
+}
+
+// NewProvider returns a Provider exporting the telemetry of the node described
+// by res to the collector at cfg.Endpoint. The collector doesn't have to be
+// reachable yet: the connection is retried in the background.
+func NewProvider(cfg Config, res Resource) (*Provider, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("no OTLP endpoint")
+	}
+	opts := []otlp.ExporterOption{otlp.WithAddress(cfg.Endpoint)}
+	if cfg.Insecure {
+		opts = append(opts, otlp.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create OTLP exporter")
+	}
+	p, err := newProvider(cfg, res, exporter, exporter)
+	if err != nil {
+		exporter.Stop() // nolint: errcheck
+		return nil, err
+	}
+	p.stopExport = exporter.Stop
+	return p, nil
+}
+
+func newProvider(
+	cfg Config,
+	res Resource,
+	spanExporter traceexport.SpanBatcher,
+	metricExporter metricexport.Exporter,
+) (*Provider, error) {
+	if cfg.TraceSampleRate < 0 || cfg.TraceSampleRate > 1 {
+		return nil, errors.Errorf("trace sample rate must be in [0, 1], got %v", cfg.TraceSampleRate)
+	}
+	interval := cfg.MetricsPushInterval
+	if interval <= 0 {
+		interval = defaultMetricsPushInterval
+	}
+
+	processor, err := sdktrace.NewBatchSpanProcessor(spanExporter)
+	if err != nil {
+		return nil, err
+	}
+	traces, err := sdktrace.NewProvider(sdktrace.WithConfig(sdktrace.Config{
+		DefaultSampler: sdktrace.ProbabilitySampler(cfg.TraceSampleRate),
+		Resource:       resource.New(res.attributes()...),
+	}))
+	if err != nil {
+		return nil, err
+	}
+	traces.RegisterSpanProcessor(processor)
+
+	metrics := push.New(
+		ungrouped.New(simple.NewWithExactMeasure(), true),
+		metricExporter,
+		interval,
+		push.WithResource(resource.New(res.attributes()...)),
+	)
+	metrics.Start()
+
+	return &Provider{
+		traces:     traces,
+		processor:  processor,
+		metrics:    metrics,
+		stopExport: func() error { return nil },
+	}, nil
+}
+
+// Tracer returns the named tracer.
+func (p *Provider) Tracer(name string) trace.Tracer {
+	return p.traces.Tracer(name)
+}
+
+// Meter returns the named meter, for metrics exported over OTLP only.
+func (p *Provider) Meter(name string) metric.Meter {
+	return p.metrics.Meter(name)
+}
+
+// Stop exports the pending spans and metrics, then closes the connection to
+// the collector.
+func (p *Provider) Stop() error {
+	var err error
+	p.stopOnce.Do(func() {
+		p.traces.UnregisterSpanProcessor(p.processor)
+		p.metrics.Stop()
+		err = p.stopExport()
+	})
+	return err
+}
+
+//----------------------------------------
+// Default provider
+
+var (
+	mtx      sync.RWMutex
+	provider *Provider
+)
+
+// SetProvider sets the provider used by StartSpan and Meter, or disables
+// telemetry if p is nil. There is a single provider per process: with several
+// nodes in the same process, the telemetry of all of them goes to the provider
+// of the node which set it last.
+func SetProvider(p *Provider) {
+	mtx.Lock()
+	provider = p
+	mtx.Unlock()
+}
+
+// UnsetProvider disables telemetry if p is the provider set with SetProvider.
+func UnsetProvider(p *Provider) {
+	mtx.Lock()
+	if provider == p {
+		provider = nil
+	}
+	mtx.Unlock()
+}
+
+// GetProvider returns the provider set with SetProvider, or nil.
+func GetProvider() *Provider {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	return provider
+}
+
+// Meter returns the named meter of the provider set with SetProvider, or a
+// no-op meter if there is none.
+func Meter(name string) metric.Meter {
+	if p := GetProvider(); p != nil {
+		return p.Meter(name)
+	}
+	return metric.NoopMeter{}
+}
@@ -0,0 +1,159 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	metricapi "go.opentelemetry.io/otel/api/metric"
+	metricexport "go.opentelemetry.io/otel/sdk/export/metric"
+	traceexport "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+type testExporter struct {
+	mtx      sync.Mutex
+	spans    []*traceexport.SpanData
+	metrics  []string
+	resource *resource.Resource
+}
+
+func (e *testExporter) ExportSpans(_ context.Context, spans []*traceexport.SpanData) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.spans = append(e.spans, spans...)
+}
+
+func (e *testExporter) Export(_ context.Context, res *resource.Resource, cps metricexport.CheckpointSet) error {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.resource = res
+	return cps.ForEach(func(record metricexport.Record) error {
+		e.metrics = append(e.metrics, record.Descriptor().Name())
+		return nil
+	})
+}
+
+var testResource = Resource{ChainID: "test-chain", NodeID: "deadbeef", Moniker: "node0"}
+
+func newTestProvider(t *testing.T) (*Provider, *testExporter) {
+	exporter := &testExporter{}
+	p, err := newProvider(Config{TraceSampleRate: 1}, testResource, exporter, exporter)
+	require.NoError(t, err)
+	SetProvider(p)
+	return p, exporter
+}
+
+func attribute(attrs []core.KeyValue, name string) (core.Value, bool) {
+	for _, kv := range attrs {
+		if string(kv.Key) == name {
+			return kv.Value, true
+		}
+	}
+	return core.Value{}, false
+}
+
+func TestStartSpan(t *testing.T) {
+	p, exporter := newTestProvider(t)
+	defer SetProvider(nil)
+
+	ctx, parent := StartSpan(context.Background(), "parent", "height", int64(5), "hash", []byte{0xab})
+	_, child := StartSpan(ctx, "child")
+	EndSpan(ctx, child, errors.New("boom"))
+	parent.End()
+	require.NoError(t, p.Stop())
+
+	require.Len(t, exporter.spans, 2)
+	childData, parentData := exporter.spans[0], exporter.spans[1]
+	assert.Equal(t, "child", childData.Name)
+	assert.Equal(t, parentData.SpanContext.SpanID, childData.ParentSpanID)
+	require.Len(t, childData.MessageEvents, 1) // the error
+
+	height, ok := attribute(parentData.Attributes, "height")
+	require.True(t, ok)
+	assert.EqualValues(t, 5, height.AsInt64())
+	hash, ok := attribute(parentData.Attributes, "hash")
+	require.True(t, ok)
+	assert.Equal(t, "[171]", hash.AsString())
+
+	for _, kv := range []core.KeyValue{
+		key.String("service.name", "tendermint"),
+		key.String("tendermint.chain_id", "test-chain"),
+		key.String("tendermint.node_id", "deadbeef"),
+		key.String("tendermint.moniker", "node0"),
+	} {
+		v, ok := attribute(parentData.Resource.Attributes(), string(kv.Key))
+		if assert.True(t, ok, kv.Key) {
+			assert.Equal(t, kv.Value, v)
+		}
+	}
+}
+
+func TestStartSpanWithoutProvider(t *testing.T) {
+	SetProvider(nil)
+	ctx, span := StartSpan(context.Background(), "noop", "height", 1)
+	defer span.End()
+	assert.False(t, span.IsRecording())
+	var buf bytes.Buffer
+	logger := log.NewTMLogger(&buf)
+	assert.Equal(t, logger, NewLogger(ctx, logger))
+}
+
+func TestNewLogger(t *testing.T) {
+	p, exporter := newTestProvider(t)
+	defer SetProvider(nil)
+
+	ctx, span := StartSpan(context.Background(), "span")
+	var buf bytes.Buffer
+	logger := NewLogger(ctx, log.NewTMLogger(&buf)).With("module", "test")
+	logger.Debug("not an event")
+	logger.Info("an event", "n", 1)
+	logger.Error("another event", "err", errors.New("boom"))
+	span.End()
+	require.NoError(t, p.Stop())
+
+	sc := span.SpanContext()
+	assert.Contains(t, buf.String(), "trace_id="+sc.TraceID.String())
+	assert.Contains(t, buf.String(), "span_id="+sc.SpanID.String())
+
+	require.Len(t, exporter.spans, 1)
+	events := exporter.spans[0].MessageEvents
+	require.Len(t, events, 2)
+	assert.Equal(t, "an event", events[0].Name)
+	module, _ := attribute(events[0].Attributes, "module")
+	assert.Equal(t, "test", module.AsString())
+	level, _ := attribute(events[1].Attributes, "level")
+	assert.Equal(t, "error", level.AsString())
+	errAttr, _ := attribute(events[1].Attributes, "err")
+	assert.Equal(t, "boom", errAttr.AsString())
+}
+
+func TestMeter(t *testing.T) {
+	p, exporter := newTestProvider(t)
+	defer SetProvider(nil)
+
+	counter := metricapi.Must(Meter("test")).NewInt64Counter("test.counter")
+	counter.Add(context.Background(), 1)
+	require.NoError(t, p.Stop()) // exports one last time
+
+	assert.Equal(t, []string{"test.counter"}, exporter.metrics)
+	chainID, ok := attribute(exporter.resource.Attributes(), "tendermint.chain_id")
+	require.True(t, ok)
+	assert.Equal(t, "test-chain", chainID.AsString())
+}
+
+func TestNewProviderValidation(t *testing.T) {
+	_, err := NewProvider(Config{}, testResource)
+	assert.Error(t, err)
+	exporter := &testExporter{}
+	_, err = newProvider(Config{TraceSampleRate: 2}, testResource, exporter, exporter)
+	assert.Error(t, err)
+}
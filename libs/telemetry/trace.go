@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/api/core"
+	"go.opentelemetry.io/otel/api/key"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// StartSpan starts a span named name, child of the span of ctx if any, with
+// attributes given as alternating keys and values, like log keyvals:
+//
+//	ctx, span := telemetry.StartSpan(ctx, "ApplyBlock", "height", block.Height)
+//	defer span.End()
+//
+// The span is a no-op if no provider is set.
+func StartSpan(ctx context.Context, name string, keyvals ...interface{}) (context.Context, trace.Span) {
+	p := GetProvider()
+	if p == nil {
+		return trace.NoopTracer{}.Start(ctx, name)
+	}
+	return p.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attributes(keyvals)...))
+}
+
+// EndSpan ends span, recording err, if any, as the error of the span.
+func EndSpan(ctx context.Context, span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(ctx, err)
+	}
+	span.End()
+}
+
+// attributes converts log keyvals to span attributes. Keys are formatted with
+// fmt.Sprint; values of basic types are kept as such and others are
+// formatted with %v.
+func attributes(keyvals []interface{}) []core.KeyValue {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, nil)
+	}
+	attrs := make([]core.KeyValue, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := fmt.Sprint(keyvals[i])
+		switch v := keyvals[i+1].(type) {
+		case bool:
+			attrs = append(attrs, key.Bool(k, v))
+		case int:
+			attrs = append(attrs, key.Int(k, v))
+		case int32:
+			attrs = append(attrs, key.Int32(k, v))
+		case int64:
+			attrs = append(attrs, key.Int64(k, v))
+		case uint32:
+			attrs = append(attrs, key.Uint32(k, v))
+		case uint64:
+			attrs = append(attrs, key.Uint64(k, v))
+		case float64:
+			attrs = append(attrs, key.Float64(k, v))
+		case string:
+			attrs = append(attrs, key.String(k, v))
+		case error:
+			attrs = append(attrs, key.String(k, v.Error()))
+		default:
+			attrs = append(attrs, key.String(k, fmt.Sprintf("%v", v)))
+		}
+	}
+	return attrs
+}
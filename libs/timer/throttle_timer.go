@@ -3,6 +3,8 @@ package timer
 import (
 	"sync"
 	"time"
+
+	"github.com/tendermint/tendermint/libs/clock"
 )
 
 /*
@@ -18,16 +20,21 @@ type ThrottleTimer struct {
 	dur  time.Duration
 
 	mtx   sync.Mutex
-	timer *time.Timer
+	timer clock.Timer
 	isSet bool
 }
 
 func NewThrottleTimer(name string, dur time.Duration) *ThrottleTimer {
+	return NewThrottleTimerWithClock(name, dur, clock.New())
+}
+
+// NewThrottleTimerWithClock returns a ThrottleTimer measuring dur with c.
+func NewThrottleTimerWithClock(name string, dur time.Duration, c clock.Clock) *ThrottleTimer {
 	var ch = make(chan struct{})
 	var quit = make(chan struct{})
 	var t = &ThrottleTimer{Name: name, Ch: ch, dur: dur, quit: quit}
 	t.mtx.Lock()
-	t.timer = time.AfterFunc(dur, t.fireRoutine)
+	t.timer = c.AfterFunc(dur, t.fireRoutine)
 	t.mtx.Unlock()
 	t.timer.Stop()
 	return t
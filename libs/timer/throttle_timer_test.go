@@ -8,6 +8,8 @@ import (
 	// make govet noshadow happy...
 
 	asrt "github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/libs/clock"
 )
 
 type thCounter struct {
@@ -77,3 +79,31 @@ func TestThrottle(test *testing.T) {
 
 	close(t.Ch)
 }
+
+func TestThrottleFakeClock(test *testing.T) {
+	assert := asrt.New(test)
+
+	delay := time.Second
+	fakeClock := clock.NewFake(time.Now())
+	t := NewThrottleTimerWithClock("foo", delay, fakeClock)
+	defer t.Stop()
+
+	c := &thCounter{input: t.Ch}
+	go c.Read()
+
+	// a burst fires once, after the delay
+	for i := 0; i < 5; i++ {
+		t.Set()
+	}
+	fakeClock.Advance(delay - time.Millisecond)
+	assert.Equal(0, c.Count())
+	// the timer fires again until the event is received
+	assert.Eventually(func() bool {
+		fakeClock.Advance(delay)
+		return c.Count() == 1
+	}, time.Second, time.Millisecond)
+
+	// nothing fires unless set
+	fakeClock.Advance(10 * delay)
+	assert.Equal(1, c.Count())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	"github.com/tendermint/tendermint/lite2/provider"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
 	"github.com/tendermint/tendermint/types"
@@ -62,8 +63,9 @@ func (p *http) SignedHeader(height int64) (*types.SignedHeader, error) {
 
 	commit, err := p.client.Commit(h)
 	if err != nil {
-		// TODO: standartise errors on the RPC side
-		if strings.Contains(err.Error(), "height must be less than or equal") {
+		// Older nodes don't send the code of the error.
+		if tmerrors.HasCode(err, tmerrors.CodeNotFound) ||
+			strings.Contains(err.Error(), "height must be less than or equal") {
 			return nil, provider.ErrSignedHeaderNotFound
 		}
 		return nil, err
@@ -92,8 +94,9 @@ func (p *http) ValidatorSet(height int64) (*types.ValidatorSet, error) {
 	const maxPerPage = 100
 	res, err := p.client.Validators(h, 0, maxPerPage)
 	if err != nil {
-		// TODO: standartise errors on the RPC side
-		if strings.Contains(err.Error(), "height must be less than or equal") {
+		// Older nodes don't send the code of the error.
+		if tmerrors.HasCode(err, tmerrors.CodeNotFound) ||
+			strings.Contains(err.Error(), "height must be less than or equal") {
 			return nil, provider.ErrValidatorSetNotFound
 		}
 		return nil, err
@@ -135,14 +135,19 @@ func (c *Client) BroadcastTxSync(tx types.Tx) (*ctypes.ResultBroadcastTx, error)
 	return c.next.BroadcastTxSync(tx)
 }
 
-func (c *Client) UnconfirmedTxs(limit int) (*ctypes.ResultUnconfirmedTxs, error) {
-	return c.next.UnconfirmedTxs(limit)
+func (c *Client) UnconfirmedTxs(limit int, cursor uint64, sender string,
+	minPriority *int64, hashPrefix []byte) (*ctypes.ResultUnconfirmedTxs, error) {
+	return c.next.UnconfirmedTxs(limit, cursor, sender, minPriority, hashPrefix)
 }
 
 func (c *Client) NumUnconfirmedTxs() (*ctypes.ResultUnconfirmedTxs, error) {
 	return c.next.NumUnconfirmedTxs()
 }
 
+func (c *Client) TxStatus(hash []byte) (*ctypes.ResultTxStatus, error) {
+	return c.next.TxStatus(hash)
+}
+
 func (c *Client) NetInfo() (*ctypes.ResultNetInfo, error) {
 	return c.next.NetInfo()
 }
@@ -300,6 +305,11 @@ func (c *Client) TxSearch(query string, prove bool, page, perPage int, orderBy s
 	return c.next.TxSearch(query, prove, page, perPage, orderBy)
 }
 
+func (c *Client) BlockSearch(query string, page, perPage int, orderBy string) (
+	*ctypes.ResultBlockSearch, error) {
+	return c.next.BlockSearch(query, page, perPage, orderBy)
+}
+
 func (c *Client) Validators(height *int64, page, perPage int) (*ctypes.ResultValidators, error) {
 	return c.next.Validators(height, page, perPage)
 }
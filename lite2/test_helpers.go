@@ -5,6 +5,7 @@ import (
 
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
 
 	"github.com/tendermint/tendermint/types"
 	tmtime "github.com/tendermint/tendermint/types/time"
@@ -42,20 +43,20 @@ func (pkz privKeys) Extend(n int) privKeys {
 	return append(pkz, extra...)
 }
 
-// // GenSecpPrivKeys produces an array of secp256k1 private keys to generate commits.
-// func GenSecpPrivKeys(n int) privKeys {
-// 	res := make(privKeys, n)
-// 	for i := range res {
-// 		res[i] = secp256k1.GenPrivKey()
-// 	}
-// 	return res
-// }
+// genSecpPrivKeys produces an array of secp256k1 private keys to generate commits.
+func genSecpPrivKeys(n int) privKeys {
+	res := make(privKeys, n)
+	for i := range res {
+		res[i] = secp256k1.GenPrivKey()
+	}
+	return res
+}
 
-// // ExtendSecp adds n more secp256k1 keys (to remove, just take a slice).
-// func (pkz privKeys) ExtendSecp(n int) privKeys {
-// 	extra := GenSecpPrivKeys(n)
-// 	return append(pkz, extra...)
-// }
+// ExtendSecp adds n more secp256k1 keys (to remove, just take a slice).
+func (pkz privKeys) ExtendSecp(n int) privKeys {
+	extra := genSecpPrivKeys(n)
+	return append(pkz, extra...)
+}
 
 // ToValidators produces a valset from the set of keys.
 // The first key has weight `init` and it increases by `inc` every step
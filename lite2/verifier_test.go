@@ -330,3 +330,38 @@ func TestValidateTrustLevel(t *testing.T) {
 		}
 	}
 }
+
+func TestVerifySecp256k1Headers(t *testing.T) {
+	const chainID = "TestVerifySecp256k1Headers"
+
+	var (
+		bTime, _ = time.Parse(time.RFC3339, "2006-01-02T15:04:05Z")
+		now      = bTime.Add(2 * time.Hour)
+	)
+
+	for name, keys := range map[string]privKeys{
+		"secp256k1": genSecpPrivKeys(4),
+		"mixed":     genPrivKeys(2).ExtendSecp(2),
+	} {
+		keys := keys
+		t.Run(name, func(t *testing.T) {
+			vals := keys.ToValidators(20, 10)
+			header := keys.GenSignedHeader(chainID, 1, bTime, nil, vals, vals,
+				[]byte("app_hash"), []byte("cons_hash"), []byte("results_hash"), 0, len(keys))
+
+			// adjacent
+			next := keys.GenSignedHeader(chainID, 2, bTime.Add(time.Hour), nil, vals, vals,
+				[]byte("app_hash"), []byte("cons_hash"), []byte("results_hash"), 0, len(keys))
+			assert.NoError(t, Verify(chainID, header, vals, next, vals, 3*time.Hour, now, DefaultTrustLevel))
+
+			// non adjacent
+			later := keys.GenSignedHeader(chainID, 5, bTime.Add(time.Hour), nil, vals, vals,
+				[]byte("app_hash"), []byte("cons_hash"), []byte("results_hash"), 0, len(keys))
+			assert.NoError(t, Verify(chainID, header, vals, later, vals, 3*time.Hour, now, DefaultTrustLevel))
+
+			// a forged signature is detected
+			later.Commit.Signatures[0].Signature[0] ^= 0x01
+			assert.Error(t, Verify(chainID, header, vals, later, vals, 3*time.Hour, now, DefaultTrustLevel))
+		})
+	}
+}
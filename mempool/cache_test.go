@@ -35,6 +35,27 @@ func TestCacheRemove(t *testing.T) {
 	}
 }
 
+func TestCacheResize(t *testing.T) {
+	cache := newMapTxCache(10)
+	for i := 0; i < 10; i++ {
+		cache.Push(types.Tx{byte(i)})
+	}
+
+	// shrinking evicts the least recently used txs
+	cache.Resize(4)
+	require.Equal(t, 4, len(cache.cacheMap))
+	require.Equal(t, 4, cache.list.Len())
+	require.True(t, cache.Push(types.Tx{0}))
+	require.False(t, cache.Push(types.Tx{9}))
+
+	// growing keeps all txs
+	cache.Resize(20)
+	for i := 10; i < 20; i++ {
+		cache.Push(types.Tx{byte(i)})
+	}
+	require.Equal(t, 14, cache.list.Len())
+}
+
 func TestCacheAfterUpdate(t *testing.T) {
 	app := kvstore.NewApplication()
 	cc := proxy.NewLocalClientCreator(app)
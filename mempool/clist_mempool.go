@@ -5,6 +5,7 @@ import (
 	"container/list"
 	"crypto/sha256"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,9 +33,10 @@ import (
 // be efficiently accessed by multiple concurrent readers.
 type CListMempool struct {
 	// Atomic integers
-	height     int64 // the last block Update()'d to
-	txsBytes   int64 // total size of mempool, in bytes
-	rechecking int32 // for re-checking filtered txs on Update()
+	height     int64  // the last block Update()'d to
+	lastSeq    uint64 // seq of the last tx added
+	txsBytes   int64  // total size of mempool, in bytes
+	rechecking int32  // for re-checking filtered txs on Update()
 
 	// notify listeners (ie. consensus) when txs are available
 	notifiedTxsAvailable bool
@@ -53,11 +55,22 @@ type CListMempool struct {
 	// in serial (ie. by abci responses which are called in serial).
 	recheckCursor *clist.CElement // next expected response
 	recheckEnd    *clist.CElement // re-checking stops here
+	recheckStart  time.Time       // for the RecheckDurationSeconds metric
 
 	// Map for quick access to txs to record sender in CheckTx.
 	// txsMap: txKey -> CElement
 	txsMap sync.Map
 
+	// Holders of the txs being checked for the first time, so that the peers
+	// sending them again in the meantime are recorded too.
+	// checkingTxs: txKey -> *sync.Map (see mempoolTx.holders)
+	checkingTxs sync.Map
+
+	// Number of txs in the mempool per sender (see mempool.max_txs_per_sender).
+	// senderTxs: sender -> int
+	sendersMtx sync.Mutex
+	senderTxs  map[string]int
+
 	// Keep a cache of already-seen txs.
 	// This reduces the pressure on the proxyApp.
 	cache txCache
@@ -65,6 +78,12 @@ type CListMempool struct {
 	// A log of mempool txs
 	wal *auto.AutoFile
 
+	// publishes the additions and removals of txs
+	eventBus types.MempoolEventPublisher
+
+	// records the lifecycle of the txs, if set
+	txTracker *TxTracker
+
 	logger log.Logger
 
 	metrics *Metrics
@@ -90,6 +109,8 @@ func NewCListMempool(
 		rechecking:    0,
 		recheckCursor: nil,
 		recheckEnd:    nil,
+		senderTxs:     make(map[string]int),
+		eventBus:      types.NopEventBus{},
 		logger:        log.NewNopLogger(),
 		metrics:       NopMetrics(),
 	}
@@ -132,6 +153,18 @@ func WithMetrics(metrics *Metrics) CListMempoolOption {
 	return func(mem *CListMempool) { mem.metrics = metrics }
 }
 
+// WithEventBus sets the event bus the additions and removals of txs are
+// published to.
+func WithEventBus(eventBus types.MempoolEventPublisher) CListMempoolOption {
+	return func(mem *CListMempool) { mem.eventBus = eventBus }
+}
+
+// WithTxTracker sets the TxTracker the additions, broadcasts (by the Reactor),
+// commits and evictions of txs are recorded to.
+func WithTxTracker(txTracker *TxTracker) CListMempoolOption {
+	return func(mem *CListMempool) { mem.txTracker = txTracker }
+}
+
 // *panics* if can't create directory or open file.
 // *not thread safe*
 func (mem *CListMempool) InitWAL() {
@@ -177,6 +210,8 @@ func (mem *CListMempool) FlushAppConn() error {
 	return mem.proxyAppConn.FlushSync()
 }
 
+// Flush removes all the txs from the mempool and the cache, without
+// publishing TxRemoved events.
 func (mem *CListMempool) Flush() {
 	mem.proxyMtx.Lock()
 	defer mem.proxyMtx.Unlock()
@@ -189,9 +224,24 @@ func (mem *CListMempool) Flush() {
 	}
 
 	mem.txsMap = sync.Map{}
+	mem.sendersMtx.Lock()
+	mem.senderTxs = make(map[string]int)
+	mem.sendersMtx.Unlock()
 	_ = atomic.SwapInt64(&mem.txsBytes, 0)
 }
 
+// SetCacheSize changes the size of the cache of already-seen transactions.
+// A disabled cache (cache_size = 0) can't be enabled, nor an enabled one
+// disabled, without a restart.
+func (mem *CListMempool) SetCacheSize(size int) error {
+	cache, ok := mem.cache.(*mapTxCache)
+	if !ok || size <= 0 {
+		return errors.New("the cache can only be enabled or disabled on startup")
+	}
+	cache.Resize(size)
+	return nil
+}
+
 // TxsFront returns the first transaction in the ordered list for peer
 // goroutines to call .NextWait() on.
 // FIXME: leaking implementation details!
@@ -220,8 +270,10 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 		txsBytes = mem.TxsBytes()
 		txSize   = len(tx)
 	)
-	if memSize >= mem.config.Size ||
-		int64(txSize)+txsBytes > mem.config.MaxTxsBytes {
+	// With priorities, a full mempool can still make room for the tx, once its
+	// priority is known.
+	if !mem.config.PrioritizeTxs() && (memSize >= mem.config.Size ||
+		int64(txSize)+txsBytes > mem.config.MaxTxsBytes) {
 		return ErrMempoolIsFull{
 			memSize, mem.config.Size,
 			txsBytes, mem.config.MaxTxsBytes}
@@ -245,10 +297,10 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 		// Record a new sender for a tx we've already seen.
 		// Note it's possible a tx is still in the cache but no longer in the mempool
 		// (eg. after committing a block, txs are removed from mempool but not cache),
-		// so we only record the sender for txs still in the mempool.
-		if e, ok := mem.txsMap.Load(txKey(tx)); ok {
-			memTx := e.(*clist.CElement).Value.(*mempoolTx)
-			memTx.senders.LoadOrStore(txInfo.SenderID, true)
+		// so we only record the sender for txs still in the mempool, or being
+		// checked.
+		if holders, ok := mem.txHolders(tx); ok {
+			holders.LoadOrStore(txInfo.SenderID, true)
 			// TODO: consider punishing peer for dups,
 			// its non-trivial since invalid txs can become valid,
 			// but they can spam the same tx with little cost to them atm.
@@ -278,6 +330,10 @@ func (mem *CListMempool) CheckTx(tx types.Tx, cb func(*abci.Response), txInfo Tx
 		return err
 	}
 
+	holders := &sync.Map{}
+	holders.Store(txInfo.SenderID, true)
+	mem.checkingTxs.Store(txKey(tx), holders)
+
 	reqRes := mem.proxyAppConn.CheckTxAsync(abci.RequestCheckTx{Tx: tx})
 	reqRes.SetCallback(mem.reqResCb(tx, txInfo.SenderID, txInfo.SenderP2PID, cb))
 
@@ -339,9 +395,15 @@ func (mem *CListMempool) reqResCb(
 // Called from:
 //  - resCbFirstTime (lock not held) if tx is valid
 func (mem *CListMempool) addTx(memTx *mempoolTx) {
+	memTx.seq = atomic.AddUint64(&mem.lastSeq, 1)
 	e := mem.txs.PushBack(memTx)
 	mem.txsMap.Store(txKey(memTx.tx), e)
 	atomic.AddInt64(&mem.txsBytes, int64(len(memTx.tx)))
+	if memTx.sender != "" {
+		mem.sendersMtx.Lock()
+		mem.senderTxs[memTx.sender]++
+		mem.sendersMtx.Unlock()
+	}
 	mem.metrics.TxSizeBytes.Observe(float64(len(memTx.tx)))
 }
 
@@ -353,12 +415,44 @@ func (mem *CListMempool) removeTx(tx types.Tx, elem *clist.CElement, removeFromC
 	elem.DetachPrev()
 	mem.txsMap.Delete(txKey(tx))
 	atomic.AddInt64(&mem.txsBytes, int64(-len(tx)))
+	if sender := elem.Value.(*mempoolTx).sender; sender != "" {
+		mem.sendersMtx.Lock()
+		if mem.senderTxs[sender]--; mem.senderTxs[sender] <= 0 {
+			delete(mem.senderTxs, sender)
+		}
+		mem.sendersMtx.Unlock()
+	}
 
 	if removeFromCache {
 		mem.cache.Remove(tx)
 	}
 }
 
+// senderIsFull returns true if sender already has mempool.max_txs_per_sender
+// txs in the mempool.
+func (mem *CListMempool) senderIsFull(sender string) bool {
+	if sender == "" || mem.config.MaxTxsPerSender <= 0 {
+		return false
+	}
+	mem.sendersMtx.Lock()
+	defer mem.sendersMtx.Unlock()
+	return mem.senderTxs[sender] >= mem.config.MaxTxsPerSender
+}
+
+// txHolders returns the ids of the peers known to have tx, if it's in the
+// mempool or being checked for the first time.
+func (mem *CListMempool) txHolders(tx types.Tx) (*sync.Map, bool) {
+	key := txKey(tx)
+	// checkingTxs first: a tx is only deleted from it once in txsMap
+	if holders, ok := mem.checkingTxs.Load(key); ok {
+		return holders.(*sync.Map), true
+	}
+	if e, ok := mem.txsMap.Load(key); ok {
+		return e.(*clist.CElement).Value.(*mempoolTx).holders, true
+	}
+	return nil, false
+}
+
 // callback, which is called after the app checked the tx for the first time.
 //
 // The case where the app checks the tx for the second and subsequent times is
@@ -371,6 +465,14 @@ func (mem *CListMempool) resCbFirstTime(
 ) {
 	switch r := res.Value.(type) {
 	case *abci.Response_CheckTx:
+		key := txKey(tx)
+		holders := &sync.Map{}
+		if h, ok := mem.checkingTxs.Load(key); ok {
+			holders = h.(*sync.Map)
+		}
+		holders.Store(peerID, true)
+		defer mem.checkingTxs.Delete(key)
+
 		var postCheckErr error
 		if mem.postCheck != nil {
 			postCheckErr = mem.postCheck(tx, r.CheckTx)
@@ -379,9 +481,28 @@ func (mem *CListMempool) resCbFirstTime(
 			memTx := &mempoolTx{
 				height:    mem.height,
 				gasWanted: r.CheckTx.GasWanted,
+				priority:  r.CheckTx.Priority,
+				sender:    r.CheckTx.Sender,
+				timestamp: time.Now(),
 				tx:        tx,
+				holders:   holders,
+			}
+			if mem.senderIsFull(memTx.sender) {
+				mem.logger.Info("Rejected good transaction: too many txs from the sender",
+					"tx", txID(tx), "peerID", peerP2PID, "sender", memTx.sender)
+				mem.metrics.FailedTxs.Add(1)
+				// remove from cache (there might be room later)
+				mem.cache.Remove(tx)
+				return
+			}
+			if mem.config.PrioritizeTxs() && !mem.makeRoom(memTx) {
+				mem.logger.Info("Rejected good transaction: mempool is full",
+					"tx", txID(tx), "peerID", peerP2PID, "priority", memTx.priority)
+				mem.metrics.FailedTxs.Add(1)
+				// remove from cache (there might be room later)
+				mem.cache.Remove(tx)
+				return
 			}
-			memTx.senders.Store(peerID, true)
 			mem.addTx(memTx)
 			mem.logger.Info("Added good transaction",
 				"tx", txID(tx),
@@ -389,6 +510,17 @@ func (mem *CListMempool) resCbFirstTime(
 				"height", memTx.height,
 				"total", mem.Size(),
 			)
+			err := mem.eventBus.PublishEventTxAdded(types.EventDataTxAdded{
+				Tx:       tx,
+				Sender:   memTx.sender,
+				Priority: memTx.priority,
+			})
+			if err != nil {
+				mem.logger.Error("Error publishing the addition of a tx", "tx", txID(tx), "err", err)
+			}
+			if mem.txTracker != nil {
+				mem.txTracker.MarkReceived(tx)
+			}
 			mem.notifyTxsAvailable()
 		} else {
 			// ignore bad transaction
@@ -403,6 +535,92 @@ func (mem *CListMempool) resCbFirstTime(
 	}
 }
 
+// makeRoom evicts the lowest priority txs, all lower than memTx's, so that
+// memTx fits in the mempool, and returns false if it can't. Among the txs of
+// the same priority, the latest are evicted first.
+func (mem *CListMempool) makeRoom(memTx *mempoolTx) bool {
+	var (
+		memSize  = mem.Size()
+		txsBytes = mem.TxsBytes()
+		txSize   = int64(len(memTx.tx))
+	)
+	isFull := func() bool {
+		return memSize >= mem.config.Size || txSize+txsBytes > mem.config.MaxTxsBytes
+	}
+	if !isFull() {
+		return true
+	}
+
+	var evictable []*clist.CElement
+	for e := mem.txs.Back(); e != nil; e = e.Prev() {
+		if e.Value.(*mempoolTx).priority < memTx.priority {
+			evictable = append(evictable, e)
+		}
+	}
+	sort.SliceStable(evictable, func(i, j int) bool {
+		return evictable[i].Value.(*mempoolTx).priority < evictable[j].Value.(*mempoolTx).priority
+	})
+	n := 0
+	for ; n < len(evictable) && isFull(); n++ {
+		memSize--
+		txsBytes -= int64(len(evictable[n].Value.(*mempoolTx).tx))
+	}
+	if isFull() {
+		return false
+	}
+
+	for _, e := range evictable[:n] {
+		mem.evictTx(e, types.TxRemovedPriority)
+	}
+	return true
+}
+
+// evictExpiredTxs evicts the txs which weren't committed within
+// mempool.ttl_num_blocks or mempool.ttl_duration.
+func (mem *CListMempool) evictExpiredTxs(height int64, now time.Time) {
+	ttlNumBlocks, ttlDuration := mem.config.TTLNumBlocks, mem.config.TTLDuration
+	if ttlNumBlocks <= 0 && ttlDuration <= 0 {
+		return
+	}
+	// the txs are in the order they were added, the oldest first
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		memTx := e.Value.(*mempoolTx)
+		if !(ttlNumBlocks > 0 && height-memTx.height > ttlNumBlocks) &&
+			!(ttlDuration > 0 && now.Sub(memTx.timestamp) > ttlDuration) {
+			return
+		}
+		mem.evictTx(e, types.TxRemovedExpired)
+	}
+}
+
+// evictTx removes an uncommitted tx from the mempool, publishing the
+// eviction.
+func (mem *CListMempool) evictTx(e *clist.CElement, reason string) {
+	memTx := e.Value.(*mempoolTx)
+	// NOTE: we remove tx from the cache so that it can be resubmitted
+	mem.removeTx(memTx.tx, e, true)
+	mem.metrics.EvictedTxs.Add(1)
+	mem.logger.Info("Evicted transaction", "tx", txID(memTx.tx), "reason", reason,
+		"priority", memTx.priority, "height", memTx.height)
+	mem.publishTxRemoved(memTx, reason)
+}
+
+// publishTxRemoved publishes the removal of memTx from the mempool, and
+// records its eviction unless it was committed.
+func (mem *CListMempool) publishTxRemoved(memTx *mempoolTx, reason string) {
+	if mem.txTracker != nil && reason != types.TxRemovedCommitted {
+		mem.txTracker.MarkEvicted(memTx.tx, reason)
+	}
+	err := mem.eventBus.PublishEventTxRemoved(types.EventDataTxRemoved{
+		Tx:     memTx.tx,
+		Sender: memTx.sender,
+		Reason: reason,
+	})
+	if err != nil {
+		mem.logger.Error("Error publishing the removal of a tx", "tx", txID(memTx.tx), "err", err)
+	}
+}
+
 // callback, which is called after the app rechecked the tx.
 //
 // The case where the app checks the tx for the first time is handled by the
@@ -429,6 +647,7 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 			mem.logger.Info("Tx is no longer valid", "tx", txID(tx), "res", r, "err", postCheckErr)
 			// NOTE: we remove tx from the cache because it might be good later
 			mem.removeTx(tx, mem.recheckCursor, true)
+			mem.publishTxRemoved(memTx, types.TxRemovedRecheck)
 		}
 		if mem.recheckCursor == mem.recheckEnd {
 			mem.recheckCursor = nil
@@ -438,6 +657,7 @@ func (mem *CListMempool) resCbRecheck(req *abci.Request, res *abci.Response) {
 		if mem.recheckCursor == nil {
 			// Done!
 			atomic.StoreInt32(&mem.rechecking, 0)
+			mem.metrics.RecheckDurationSeconds.Observe(time.Since(mem.recheckStart).Seconds())
 			mem.logger.Info("Done rechecking txs")
 
 			// incase the recheck removed all txs
@@ -472,7 +692,7 @@ func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	mem.proxyMtx.Lock()
 	defer mem.proxyMtx.Unlock()
 
-	for atomic.LoadInt32(&mem.rechecking) > 0 {
+	for mem.waitRecheck() {
 		// TODO: Something better?
 		time.Sleep(time.Millisecond * 10)
 	}
@@ -483,8 +703,7 @@ func (mem *CListMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	// size per tx, and set the initial capacity based off of that.
 	// txs := make([]types.Tx, 0, tmmath.MinInt(mem.txs.Len(), max/mem.avgTxSize))
 	txs := make([]types.Tx, 0, mem.txs.Len())
-	for e := mem.txs.Front(); e != nil; e = e.Next() {
-		memTx := e.Value.(*mempoolTx)
+	for _, memTx := range mem.reapOrder() {
 		// Check total size requirement
 		aminoOverhead := types.ComputeAminoOverhead(memTx.tx, 1)
 		if maxBytes > -1 && totalBytes+int64(len(memTx.tx))+aminoOverhead > maxBytes {
@@ -513,19 +732,64 @@ func (mem *CListMempool) ReapMaxTxs(max int) types.Txs {
 		max = mem.txs.Len()
 	}
 
-	for atomic.LoadInt32(&mem.rechecking) > 0 {
+	for mem.waitRecheck() {
 		// TODO: Something better?
 		time.Sleep(time.Millisecond * 10)
 	}
 
 	txs := make([]types.Tx, 0, tmmath.MinInt(mem.txs.Len(), max))
-	for e := mem.txs.Front(); e != nil && len(txs) <= max; e = e.Next() {
-		memTx := e.Value.(*mempoolTx)
+	for _, memTx := range mem.reapOrder() {
+		if len(txs) > max {
+			break
+		}
 		txs = append(txs, memTx.tx)
 	}
 	return txs
 }
 
+// FilterTxs returns up to max txs matching filter, in the order they were
+// added (by arrival, even with mempool.prioritize), after the one with seq
+// cursor, and the seq of the last tx returned.
+func (mem *CListMempool) FilterTxs(filter TxFilter, cursor uint64, max int) (types.Txs, uint64) {
+	txs := make([]types.Tx, 0)
+	for e := mem.txs.Front(); e != nil && (max < 0 || len(txs) < max); e = e.Next() {
+		memTx := e.Value.(*mempoolTx)
+		if memTx.seq <= cursor {
+			continue
+		}
+		if (filter.Sender != "" && memTx.sender != filter.Sender) ||
+			(filter.MinPriority != nil && memTx.priority < *filter.MinPriority) ||
+			!bytes.HasPrefix(memTx.tx.Hash(), filter.HashPrefix) {
+			continue
+		}
+		txs = append(txs, memTx.tx)
+		cursor = memTx.seq
+	}
+	return txs, cursor
+}
+
+// waitRecheck returns true if the txs are being rechecked, and must be before
+// being reaped (see mempool.recheck_async).
+func (mem *CListMempool) waitRecheck() bool {
+	return !mem.config.RecheckAsync && atomic.LoadInt32(&mem.rechecking) > 0
+}
+
+// reapOrder returns the txs in the order they are reaped: by arrival or, if
+// mempool.prioritize is on, by decreasing priority, ties broken by arrival.
+// Txs are still gossiped by arrival.
+func (mem *CListMempool) reapOrder() []*mempoolTx {
+	memTxs := make([]*mempoolTx, 0, mem.txs.Len())
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		memTxs = append(memTxs, e.Value.(*mempoolTx))
+	}
+	if mem.config.PrioritizeTxs() {
+		sort.SliceStable(memTxs, func(i, j int) bool {
+			return memTxs[i].priority > memTxs[j].priority
+		})
+	}
+	return memTxs
+}
+
 func (mem *CListMempool) Update(
 	height int64,
 	txs types.Txs,
@@ -563,11 +827,19 @@ func (mem *CListMempool) Update(
 		// Mempool after:
 		//   100
 		// https://github.com/tendermint/tendermint/issues/3322.
+		if mem.txTracker != nil {
+			mem.txTracker.MarkCommitted(tx, height, deliverTxResponses[i].Code)
+		}
+
 		if e, ok := mem.txsMap.Load(txKey(tx)); ok {
+			memTx := e.(*clist.CElement).Value.(*mempoolTx)
 			mem.removeTx(tx, e.(*clist.CElement), false)
+			mem.publishTxRemoved(memTx, types.TxRemovedCommitted)
 		}
 	}
 
+	mem.evictExpiredTxs(height, time.Now())
+
 	// Either recheck non-committed txs to see if they became invalid
 	// or just notify there're some txs left.
 	if mem.Size() > 0 {
@@ -594,17 +866,29 @@ func (mem *CListMempool) recheckTxs() {
 	}
 
 	atomic.StoreInt32(&mem.rechecking, 1)
+	mem.recheckStart = time.Now()
 	mem.recheckCursor = mem.txs.Front()
 	mem.recheckEnd = mem.txs.Back()
+	// only the oldest txs, if mempool.recheck_max_txs is set
+	if max := mem.config.RecheckMaxTxs; max > 0 && mem.Size() > max {
+		mem.recheckEnd = mem.recheckCursor
+		for i := 1; i < max; i++ {
+			mem.recheckEnd = mem.recheckEnd.Next()
+		}
+		mem.logger.Info("Not rechecking the latest txs", "numtxs", mem.Size()-max)
+	}
 
 	// Push txs to proxyAppConn
 	// NOTE: globalCb may be called concurrently.
-	for e := mem.txs.Front(); e != nil; e = e.Next() {
+	for e := mem.recheckCursor; e != nil; e = e.Next() {
 		memTx := e.Value.(*mempoolTx)
 		mem.proxyAppConn.CheckTxAsync(abci.RequestCheckTx{
 			Tx:   memTx.tx,
 			Type: abci.CheckTxType_Recheck,
 		})
+		if e == mem.recheckEnd {
+			break
+		}
 	}
 
 	mem.proxyAppConn.FlushAsync()
@@ -614,13 +898,22 @@ func (mem *CListMempool) recheckTxs() {
 
 // mempoolTx is a transaction that successfully ran
 type mempoolTx struct {
-	height    int64    // height that this tx had been validated in
-	gasWanted int64    // amount of gas this tx states it will require
-	tx        types.Tx //
-
-	// ids of peers who've sent us this tx (as a map for quick lookups).
-	// senders: PeerID -> bool
-	senders sync.Map
+	seq       uint64    // position of this tx in the order the txs were added
+	height    int64     // height that this tx had been validated in
+	gasWanted int64     // amount of gas this tx states it will require
+	priority  int64     // priority the app assigned to this tx in CheckTx
+	sender    string    // sender the app assigned to this tx in CheckTx
+	timestamp time.Time // time this tx was added to the mempool
+	tx        types.Tx  //
+
+	// ids of peers known to have this tx, which sent it to us or which we
+	// sent it to, so that it isn't gossiped to them (as a map for quick
+	// lookups).
+	// holders: PeerID -> bool
+	holders *sync.Map
+
+	// number of peers the tx was sent to, or is being sent to (atomic)
+	broadcasts int32
 }
 
 // Height returns the height for this transaction
@@ -690,6 +983,20 @@ func (cache *mapTxCache) Push(tx types.Tx) bool {
 	return true
 }
 
+// Resize changes the size of the cache, evicting the least recently used
+// transactions if it shrinks.
+func (cache *mapTxCache) Resize(size int) {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+
+	cache.size = size
+	for cache.list.Len() > size {
+		popped := cache.list.Front()
+		delete(cache.cacheMap, popped.Value.([sha256.Size]byte))
+		cache.list.Remove(popped)
+	}
+}
+
 // Remove removes the given tx from the cache.
 func (cache *mapTxCache) Remove(tx types.Tx) {
 	cache.mtx.Lock()
@@ -1,6 +1,7 @@
 package mempool
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/binary"
@@ -9,6 +10,7 @@ import (
 	mrand "math/rand"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,6 +25,7 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/log"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/libs/service"
 	"github.com/tendermint/tendermint/proxy"
@@ -37,14 +40,18 @@ func newMempoolWithApp(cc proxy.ClientCreator) (*CListMempool, cleanupFunc) {
 	return newMempoolWithAppAndConfig(cc, cfg.ResetTestRoot("mempool_test"))
 }
 
-func newMempoolWithAppAndConfig(cc proxy.ClientCreator, config *cfg.Config) (*CListMempool, cleanupFunc) {
+func newMempoolWithAppAndConfig(
+	cc proxy.ClientCreator,
+	config *cfg.Config,
+	options ...CListMempoolOption,
+) (*CListMempool, cleanupFunc) {
 	appConnMem, _ := cc.NewABCIClient()
 	appConnMem.SetLogger(log.TestingLogger().With("module", "abci-client", "connection", "mempool"))
 	err := appConnMem.Start()
 	if err != nil {
 		panic(err)
 	}
-	mempool := NewCListMempool(config.Mempool, appConnMem, 0)
+	mempool := NewCListMempool(config.Mempool, appConnMem, 0, options...)
 	mempool.SetLogger(log.TestingLogger())
 	return mempool, func() { os.RemoveAll(config.RootDir) }
 }
@@ -538,6 +545,244 @@ func TestMempoolTxsBytes(t *testing.T) {
 	assert.EqualValues(t, 0, mempool.TxsBytes())
 }
 
+// priorityApp assigns the first byte of a tx as its priority.
+type priorityApp struct {
+	abci.BaseApplication
+}
+
+func (priorityApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	return abci.ResponseCheckTx{Code: abci.CodeTypeOK, Priority: int64(req.Tx[0])}
+}
+
+func TestMempoolPrioritize(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(priorityApp{})
+	config := cfg.ResetTestRoot("mempool_test")
+	config.Mempool.Prioritize = true
+	config.Mempool.Size = 4
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config)
+	defer cleanup()
+
+	checkTx := func(tx types.Tx) {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+	assertReaped := func(txs ...types.Tx) {
+		assert.Equal(t, types.Txs(txs), mempool.ReapMaxBytesMaxGas(-1, -1))
+	}
+
+	// reaped by priority, then arrival
+	checkTx([]byte{1, 0})
+	checkTx([]byte{3, 0})
+	checkTx([]byte{2, 0})
+	checkTx([]byte{3, 1})
+	assertReaped([]byte{3, 0}, []byte{3, 1}, []byte{2, 0}, []byte{1, 0})
+
+	// once full, only a higher priority tx is added, the lowest evicted
+	checkTx([]byte{1, 1})
+	checkTx([]byte{5, 0})
+	assertReaped([]byte{5, 0}, []byte{3, 0}, []byte{3, 1}, []byte{2, 0})
+	assert.Equal(t, 4, mempool.Size())
+
+	// the latest of the lowest priority first
+	checkTx([]byte{3, 2})
+	checkTx([]byte{4, 0})
+	assertReaped([]byte{5, 0}, []byte{4, 0}, []byte{3, 0}, []byte{3, 1})
+
+	// the evicted tx can be resubmitted
+	mempool.Update(1, []types.Tx{[]byte{5, 0}}, abciResponses(1, abci.CodeTypeOK), nil, nil)
+	checkTx([]byte{2, 0})
+	assertReaped([]byte{4, 0}, []byte{3, 0}, []byte{3, 1}, []byte{2, 0})
+}
+
+func TestMempoolTTL(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer eventBus.Stop()
+
+	cc := proxy.NewLocalClientCreator(kvstore.NewApplication())
+	config := cfg.ResetTestRoot("mempool_test")
+	config.Mempool.TTLNumBlocks = 2
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config, WithEventBus(eventBus))
+	defer cleanup()
+
+	tx1, tx2 := types.Tx("tx1"), types.Tx("tx2")
+	query := fmt.Sprintf("tm.event='TxRemoved' AND tx.hash='%X'", tx1.Hash())
+	sub, err := eventBus.Subscribe(context.Background(), "test", tmquery.MustParse(query))
+	require.NoError(t, err)
+
+	// by height
+	require.NoError(t, mempool.CheckTx(tx1, nil, TxInfo{}))
+	mempool.Update(1, nil, nil, nil, nil)
+	mempool.Update(2, nil, nil, nil, nil)
+	require.NoError(t, mempool.CheckTx(tx2, nil, TxInfo{}))
+	assert.Equal(t, 2, mempool.Size())
+	mempool.Update(3, nil, nil, nil, nil)
+	assert.Equal(t, types.Txs{tx2}, mempool.ReapMaxTxs(-1))
+
+	select {
+	case msg := <-sub.Out():
+		assert.Equal(t, types.EventDataTxRemoved{Tx: tx1, Reason: types.TxRemovedExpired}, msg.Data())
+	case <-time.After(time.Second):
+		t.Fatal("expected the eviction of tx1 to be published")
+	}
+
+	// by time
+	config.Mempool.TTLDuration = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	mempool.Update(4, nil, nil, nil, nil)
+	assert.Zero(t, mempool.Size())
+}
+
+func TestMempoolSnapshot(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(kvstore.NewApplication())
+	config := cfg.ResetTestRoot("mempool_test")
+	path := config.Mempool.SnapshotFile()
+
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config)
+	defer cleanup()
+	txs := checkTxs(t, mempool, 3, UnknownPeerID)
+	require.NoError(t, mempool.SaveSnapshot(path))
+
+	// no snapshot yet
+	restarted, cleanup2 := newMempoolWithAppAndConfig(cc, config)
+	defer cleanup2()
+	added, err := restarted.LoadSnapshot(filepath.Join(config.RootDir, "missing.json"))
+	require.NoError(t, err)
+	assert.Zero(t, added)
+
+	// the tx already in the mempool stays where it is
+	require.NoError(t, restarted.CheckTx(txs[1], nil, TxInfo{}))
+	added, err = restarted.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, types.Txs{txs[1], txs[0], txs[2]}, restarted.ReapMaxTxs(-1))
+}
+
+// senderApp assigns the first byte of a tx as its sender.
+type senderApp struct {
+	abci.BaseApplication
+}
+
+func (senderApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	return abci.ResponseCheckTx{Code: abci.CodeTypeOK, Sender: string(req.Tx[:1])}
+}
+
+func TestMempoolMaxTxsPerSender(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(senderApp{})
+	config := cfg.ResetTestRoot("mempool_test")
+	config.Mempool.MaxTxsPerSender = 2
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config)
+	defer cleanup()
+
+	checkTx := func(tx types.Tx) {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+
+	// the third tx of a sender is rejected, not the other senders'
+	checkTx([]byte("a1"))
+	checkTx([]byte("a2"))
+	checkTx([]byte("a3"))
+	checkTx([]byte("b1"))
+	assert.Equal(t, types.Txs{[]byte("a1"), []byte("a2"), []byte("b1")}, mempool.ReapMaxTxs(-1))
+
+	// once one of its txs is committed, the rejected tx can be resubmitted
+	mempool.Update(1, []types.Tx{[]byte("a1")}, abciResponses(1, abci.CodeTypeOK), nil, nil)
+	checkTx([]byte("a3"))
+	checkTx([]byte("a4"))
+	assert.Equal(t, types.Txs{[]byte("a2"), []byte("b1"), []byte("a3")}, mempool.ReapMaxTxs(-1))
+}
+
+func TestMempoolFilterTxs(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(senderApp{})
+	mempool, cleanup := newMempoolWithApp(cc)
+	defer cleanup()
+
+	txs := types.Txs{[]byte("a1"), []byte("b1"), []byte("a2"), []byte("a3")}
+	for _, tx := range txs {
+		require.NoError(t, mempool.CheckTx(tx, nil, TxInfo{}))
+	}
+
+	// paginated
+	filter := TxFilter{Sender: "a"}
+	page, cursor := mempool.FilterTxs(filter, 0, 2)
+	assert.Equal(t, types.Txs{txs[0], txs[2]}, page)
+	page, cursor = mempool.FilterTxs(filter, cursor, 2)
+	assert.Equal(t, types.Txs{txs[3]}, page)
+	page, _ = mempool.FilterTxs(filter, cursor, 2)
+	assert.Empty(t, page)
+
+	// by hash prefix
+	page, _ = mempool.FilterTxs(TxFilter{HashPrefix: txs[1].Hash()[:2]}, 0, -1)
+	assert.Equal(t, types.Txs{txs[1]}, page)
+
+	// by priority (all 0)
+	minPriority := int64(1)
+	page, _ = mempool.FilterTxs(TxFilter{MinPriority: &minPriority}, 0, -1)
+	assert.Empty(t, page)
+}
+
+func TestMempoolTxEvents(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer eventBus.Stop()
+
+	cc := proxy.NewLocalClientCreator(senderApp{})
+	config := cfg.ResetTestRoot("mempool_test")
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config, WithEventBus(eventBus))
+	defer cleanup()
+
+	query := "tx.sender='a'"
+	sub, err := eventBus.Subscribe(context.Background(), "test", tmquery.MustParse(query), 10)
+	require.NoError(t, err)
+
+	a1, b1 := types.Tx("a1"), types.Tx("b1")
+	require.NoError(t, mempool.CheckTx(a1, nil, TxInfo{}))
+	require.NoError(t, mempool.CheckTx(b1, nil, TxInfo{}))
+	mempool.Update(1, types.Txs{a1, b1}, abciResponses(2, abci.CodeTypeOK), nil, nil)
+
+	expected := []types.TMEventData{
+		types.EventDataTxAdded{Tx: a1, Sender: "a"},
+		types.EventDataTxRemoved{Tx: a1, Sender: "a", Reason: types.TxRemovedCommitted},
+	}
+	for _, data := range expected {
+		select {
+		case msg := <-sub.Out():
+			assert.Equal(t, data, msg.Data())
+		case <-time.After(time.Second):
+			t.Fatalf("expected %v to be published", data)
+		}
+	}
+}
+
+// recheckApp rejects the txs when rechecked.
+type recheckApp struct {
+	abci.BaseApplication
+}
+
+func (recheckApp) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
+	if req.Type == abci.CheckTxType_Recheck {
+		return abci.ResponseCheckTx{Code: 1}
+	}
+	return abci.ResponseCheckTx{Code: abci.CodeTypeOK}
+}
+
+func TestMempoolRecheckPolicies(t *testing.T) {
+	cc := proxy.NewLocalClientCreator(recheckApp{})
+	config := cfg.ResetTestRoot("mempool_test")
+	config.Mempool.RecheckMaxTxs = 2
+	mempool, cleanup := newMempoolWithAppAndConfig(cc, config)
+	defer cleanup()
+
+	// only the 2 oldest txs are rechecked (and rejected)
+	txs := checkTxs(t, mempool, 3, UnknownPeerID)
+	mempool.Update(1, nil, nil, nil, nil)
+	assert.Equal(t, types.Txs{txs[2]}, mempool.ReapMaxTxs(-1))
+
+	// the txs are reaped while being rechecked
+	config.Mempool.RecheckAsync = true
+	atomic.StoreInt32(&mempool.rechecking, 1)
+	assert.Equal(t, types.Txs{txs[2]}, mempool.ReapMaxBytesMaxGas(-1, -1))
+}
+
 // This will non-deterministically catch some concurrency failures like
 // https://github.com/tendermint/tendermint/issues/3509
 // TODO: all of the tests should probably also run using the remote proxy app
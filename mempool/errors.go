@@ -9,6 +9,10 @@ import (
 var (
 	// ErrTxInCache is returned to the client if we saw tx earlier
 	ErrTxInCache = errors.New("tx already exists in cache")
+
+	// ErrNopMempool is returned to the client if the mempool is disabled
+	// (mempool.type = "nop")
+	ErrNopMempool = errors.New("the mempool is disabled, submit txs to the application")
 )
 
 // ErrTxTooLarge means the tx is too big to be sent in a message to other peers
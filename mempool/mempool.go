@@ -29,6 +29,12 @@ type Mempool interface {
 	// transactions (~ all available transactions).
 	ReapMaxTxs(max int) types.Txs
 
+	// FilterTxs returns up to max txs matching filter (all of them if max is
+	// negative), in the order they were added, starting after the tx with the
+	// given cursor (0 to start from the first tx). It also returns the cursor
+	// of the last tx returned, to get the next ones.
+	FilterTxs(filter TxFilter, cursor uint64, max int) (types.Txs, uint64)
+
 	// Lock locks the mempool. The consensus must be able to hold lock to safely update.
 	Lock()
 
@@ -74,6 +80,13 @@ type Mempool interface {
 	// CloseWAL closes and discards the underlying WAL file.
 	// Any further writes will not be relayed to disk.
 	CloseWAL()
+
+	// SaveSnapshot saves the txs to the given file.
+	SaveSnapshot(path string) error
+
+	// LoadSnapshot checks the txs saved to the given file again, and returns
+	// the number of txs added back.
+	LoadSnapshot(path string) (int, error)
 }
 
 //--------------------------------------------------------------------------------
@@ -98,6 +111,17 @@ type TxInfo struct {
 	SenderP2PID p2p.ID
 }
 
+// TxFilter selects the txs returned by FilterTxs. The zero value selects all
+// the txs.
+type TxFilter struct {
+	// Sender the application returned in CheckTx, if not empty.
+	Sender string
+	// Minimum priority the application returned in CheckTx, if not nil.
+	MinPriority *int64
+	// Prefix of the tx hash.
+	HashPrefix []byte
+}
+
 //--------------------------------------------------------------------------------
 
 // PreCheckAminoMaxBytes checks that the size of the transaction plus the amino
@@ -3,8 +3,9 @@ package mempool
 import (
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
-	"github.com/go-kit/kit/metrics/prometheus"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	tmmetrics "github.com/tendermint/tendermint/libs/metrics"
 )
 
 const (
@@ -24,51 +25,77 @@ type Metrics struct {
 	FailedTxs metrics.Counter
 	// Number of times transactions are rechecked in the mempool.
 	RecheckTimes metrics.Counter
+	// Number of transactions evicted, by higher priority ones or once expired.
+	EvictedTxs metrics.Counter
+	// Time taken to recheck the transactions after a block, in seconds.
+	RecheckDurationSeconds metrics.Histogram
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return PrometheusMetricsWithRegisterer(stdprometheus.DefaultRegisterer, namespace, labelsAndValues...)
+}
+
+// PrometheusMetricsWithRegisterer is like PrometheusMetrics, but registers the
+// metrics with the given registerer.
+func PrometheusMetricsWithRegisterer(registerer stdprometheus.Registerer, namespace string,
+	labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
 	return &Metrics{
-		Size: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Size: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "size",
 			Help:      "Size of the mempool (number of uncommitted transactions).",
 		}, labels).With(labelsAndValues...),
-		TxSizeBytes: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		TxSizeBytes: tmmetrics.NewHistogramFrom(registerer, stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "tx_size_bytes",
 			Help:      "Transaction sizes in bytes.",
 			Buckets:   stdprometheus.ExponentialBuckets(1, 3, 17),
 		}, labels).With(labelsAndValues...),
-		FailedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		FailedTxs: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "failed_txs",
 			Help:      "Number of failed transactions.",
 		}, labels).With(labelsAndValues...),
-		RecheckTimes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		RecheckTimes: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "recheck_times",
 			Help:      "Number of times transactions are rechecked in the mempool.",
 		}, labels).With(labelsAndValues...),
+		EvictedTxs: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "evicted_txs",
+			Help:      "Number of transactions evicted, by higher priority ones or once expired.",
+		}, labels).With(labelsAndValues...),
+		RecheckDurationSeconds: tmmetrics.NewHistogramFrom(registerer, stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "recheck_duration_seconds",
+			Help:      "Time taken to recheck the transactions after a block, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.001, 2, 15),
+		}, labels).With(labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Size:         discard.NewGauge(),
-		TxSizeBytes:  discard.NewHistogram(),
-		FailedTxs:    discard.NewCounter(),
-		RecheckTimes: discard.NewCounter(),
+		Size:                   discard.NewGauge(),
+		TxSizeBytes:            discard.NewHistogram(),
+		FailedTxs:              discard.NewCounter(),
+		RecheckTimes:           discard.NewCounter(),
+		EvictedTxs:             discard.NewCounter(),
+		RecheckDurationSeconds: discard.NewHistogram(),
 	}
 }
@@ -0,0 +1,48 @@
+package mempool
+
+import (
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// NopMempool is a mempool which accepts no txs, for the applications managing
+// their own mempool (mempool.type = "nop"). The blocks are proposed without
+// txs, and the txs aren't gossiped.
+type NopMempool struct{}
+
+var _ Mempool = NopMempool{}
+
+// CheckTx always returns ErrNopMempool.
+func (NopMempool) CheckTx(types.Tx, func(*abci.Response), TxInfo) error {
+	return ErrNopMempool
+}
+
+func (NopMempool) ReapMaxBytesMaxGas(_, _ int64) types.Txs { return types.Txs{} }
+func (NopMempool) ReapMaxTxs(int) types.Txs                { return types.Txs{} }
+func (NopMempool) FilterTxs(_ TxFilter, cursor uint64, _ int) (types.Txs, uint64) {
+	return types.Txs{}, cursor
+}
+func (NopMempool) Lock()   {}
+func (NopMempool) Unlock() {}
+func (NopMempool) Update(
+	int64,
+	types.Txs,
+	[]*abci.ResponseDeliverTx,
+	PreCheckFunc,
+	PostCheckFunc,
+) error {
+	return nil
+}
+func (NopMempool) FlushAppConn() error { return nil }
+func (NopMempool) Flush()              {}
+
+// TxsAvailable returns nil: the txs are never available.
+func (NopMempool) TxsAvailable() <-chan struct{} { return nil }
+func (NopMempool) EnableTxsAvailable()           {}
+func (NopMempool) Size() int                     { return 0 }
+func (NopMempool) TxsBytes() int64               { return 0 }
+func (NopMempool) InitWAL()                      {}
+func (NopMempool) CloseWAL()                     {}
+
+func (NopMempool) SaveSnapshot(string) error        { return nil }
+func (NopMempool) LoadSnapshot(string) (int, error) { return 0, nil }
@@ -5,6 +5,7 @@ import (
 	"math"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	amino "github.com/tendermint/go-amino"
@@ -32,12 +33,13 @@ const (
 
 // Reactor handles mempool tx broadcasting amongst peers.
 // It maintains a map from peer ID to counter, to prevent gossiping txs to the
-// peers you received it from.
+// peers you received them from, or already sent them to.
 type Reactor struct {
 	p2p.BaseReactor
 	config  *cfg.MempoolConfig
 	mempool *CListMempool
 	ids     *mempoolIDs
+	fanout  int32 // atomic; see SetBroadcastFanout
 }
 
 type mempoolIDs struct {
@@ -109,6 +111,7 @@ func NewReactor(config *cfg.MempoolConfig, mempool *CListMempool) *Reactor {
 		config:  config,
 		mempool: mempool,
 		ids:     newMempoolIDs(),
+		fanout:  int32(config.BroadcastFanout),
 	}
 	memR.BaseReactor = *p2p.NewBaseReactor("Reactor", memR)
 	return memR
@@ -120,6 +123,13 @@ func (memR *Reactor) SetLogger(l log.Logger) {
 	memR.mempool.SetLogger(l)
 }
 
+// SetBroadcastFanout sets the maximum number of peers each tx is broadcast to,
+// 0 meaning all. It can be changed while the reactor is running, and applies
+// to the txs not broadcast to that many peers yet.
+func (memR *Reactor) SetBroadcastFanout(fanout int) {
+	atomic.StoreInt32(&memR.fanout, int32(fanout))
+}
+
 // OnStart implements p2p.BaseReactor.
 func (memR *Reactor) OnStart() error {
 	if !memR.config.Broadcast {
@@ -231,15 +241,22 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 			continue
 		}
 
-		// ensure peer hasn't already sent us this tx
-		if _, ok := memTx.senders.Load(peerID); !ok {
+		// ensure peer doesn't already have this tx: it sent it to us, or we
+		// sent it before starting over from the front of the list. Past the
+		// fanout, the peer is left to get it from the peers we sent it to.
+		if _, ok := memTx.holders.Load(peerID); !ok && memR.reserveBroadcast(memTx) {
 			// send memTx
 			msg := &TxMessage{Tx: memTx.tx}
 			success := peer.Send(MempoolChannel, cdc.MustMarshalBinaryBare(msg))
 			if !success {
+				atomic.AddInt32(&memTx.broadcasts, -1)
 				time.Sleep(peerCatchupSleepIntervalMS * time.Millisecond)
 				continue
 			}
+			memTx.holders.Store(peerID, true)
+			if memR.mempool.txTracker != nil {
+				memR.mempool.txTracker.MarkBroadcast(memTx.tx)
+			}
 		}
 
 		select {
@@ -254,6 +271,17 @@ func (memR *Reactor) broadcastTxRoutine(peer p2p.Peer) {
 	}
 }
 
+// reserveBroadcast returns true if memTx can be sent to one more peer without
+// exceeding the fanout, counting that peer.
+func (memR *Reactor) reserveBroadcast(memTx *mempoolTx) bool {
+	fanout := atomic.LoadInt32(&memR.fanout)
+	if n := atomic.AddInt32(&memTx.broadcasts, 1); fanout > 0 && n > fanout {
+		atomic.AddInt32(&memTx.broadcasts, -1)
+		return false
+	}
+	return true
+}
+
 //-----------------------------------------------------------------------------
 // Messages
 
@@ -3,6 +3,7 @@ package mempool
 import (
 	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -149,6 +150,63 @@ func TestReactorNoBroadcastToSender(t *testing.T) {
 	ensureNoTxs(t, reactors[1], 100*time.Millisecond)
 }
 
+func TestReactorRecordsTxHolders(t *testing.T) {
+	config := cfg.TestConfig()
+	const N = 2
+	reactors := makeAndConnectReactors(config, N)
+	defer func() {
+		for _, r := range reactors {
+			r.Stop()
+		}
+	}()
+	for _, r := range reactors {
+		for _, peer := range r.Switch.Peers().List() {
+			peer.Set(types.PeerStateKey, peerState{1})
+		}
+	}
+
+	txs := checkTxs(t, reactors[0].mempool, 10, UnknownPeerID)
+	waitForTxsOnReactors(t, txs, reactors)
+
+	// both sides know the other has the txs, so they aren't sent back
+	for _, r := range reactors {
+		peer := r.Switch.Peers().List()[0]
+		peerID := r.ids.GetForPeer(peer)
+		for e := r.mempool.TxsFront(); e != nil; e = e.Next() {
+			memTx := e.Value.(*mempoolTx)
+			_, ok := memTx.holders.Load(peerID)
+			assert.True(t, ok, "peer %v not recorded as a holder of %X", peer.ID(), memTx.tx)
+		}
+	}
+}
+
+func TestReactorBroadcastFanout(t *testing.T) {
+	config := cfg.TestConfig()
+	const N = 4
+	reactors := makeAndConnectReactors(config, N)
+	defer func() {
+		for _, r := range reactors {
+			r.Stop()
+		}
+	}()
+	for _, r := range reactors {
+		for _, peer := range r.Switch.Peers().List() {
+			peer.Set(types.PeerStateKey, peerState{1})
+		}
+	}
+
+	// the first reactor sends each tx to a single peer, which relays it to
+	// the others
+	reactors[0].SetBroadcastFanout(1)
+	txs := checkTxs(t, reactors[0].mempool, 100, UnknownPeerID)
+	waitForTxsOnReactors(t, txs, reactors)
+
+	for e := reactors[0].mempool.TxsFront(); e != nil; e = e.Next() {
+		memTx := e.Value.(*mempoolTx)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&memTx.broadcasts), "tx %X", memTx.tx)
+	}
+}
+
 func TestBroadcastTxForPeerStopsWhenPeerStops(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
@@ -0,0 +1,225 @@
+// Package remote implements a client of an external mempool, reached over
+// gRPC, and a server exposing a local mempool as such.
+package remote
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	tmnet "github.com/tendermint/tendermint/libs/net"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Mempool is a client of an external mempool implementing MempoolService
+// (mempool.type = "remote"). The external mempool checks the txs with the
+// application and gossips them itself.
+//
+// NOTE: the txs available are only notified after the txs checked through
+// this client and after each block, so that the txs submitted to the
+// external mempool directly aren't noticed sooner with
+// consensus.create_empty_blocks = false.
+type Mempool struct {
+	mtx    sync.Mutex
+	conn   *grpc.ClientConn
+	client MempoolServiceClient
+
+	notifiedTxsAvailable bool
+	txsAvailable         chan struct{}
+	txsAvailableMtx      sync.Mutex
+
+	logger log.Logger
+}
+
+var _ mempl.Mempool = (*Mempool)(nil)
+
+// NewMempool returns a client of the external mempool at addr. The connection
+// is established in the background.
+func NewMempool(addr string) (*Mempool, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithContextDialer(dialerFunc))
+	if err != nil {
+		return nil, err
+	}
+	return &Mempool{
+		conn:   conn,
+		client: NewMempoolServiceClient(conn),
+		logger: log.NewNopLogger(),
+	}, nil
+}
+
+func dialerFunc(ctx context.Context, addr string) (net.Conn, error) {
+	return tmnet.Connect(addr)
+}
+
+// SetLogger sets the Logger.
+func (mem *Mempool) SetLogger(l log.Logger) {
+	mem.logger = l
+}
+
+// Close closes the connection to the external mempool.
+func (mem *Mempool) Close() error {
+	return mem.conn.Close()
+}
+
+// CheckTx submits tx to the external mempool. The ABCI response is passed to
+// cb, once the external mempool checked it.
+func (mem *Mempool) CheckTx(tx types.Tx, cb func(*abci.Response), _ mempl.TxInfo) error {
+	res, err := mem.client.CheckTx(context.Background(), &RequestCheckTx{Tx: tx})
+	if err != nil {
+		return err
+	}
+	if res.CheckTx == nil {
+		res.CheckTx = &abci.ResponseCheckTx{}
+	}
+	if res.CheckTx.IsOK() {
+		mem.notifyTxsAvailable()
+	}
+	if cb != nil {
+		cb(abci.ToResponseCheckTx(*res.CheckTx))
+	}
+	return nil
+}
+
+func (mem *Mempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
+	res, err := mem.client.ReapMaxBytesMaxGas(context.Background(),
+		&RequestReapMaxBytesMaxGas{MaxBytes: maxBytes, MaxGas: maxGas})
+	if err != nil {
+		mem.logger.Error("Failed to reap txs from the remote mempool", "err", err)
+		return types.Txs{}
+	}
+	return toTxs(res.Txs)
+}
+
+func (mem *Mempool) ReapMaxTxs(max int) types.Txs {
+	res, err := mem.client.ReapMaxTxs(context.Background(), &RequestReapMaxTxs{Max: int64(max)})
+	if err != nil {
+		mem.logger.Error("Failed to reap txs from the remote mempool", "err", err)
+		return types.Txs{}
+	}
+	return toTxs(res.Txs)
+}
+
+func (mem *Mempool) FilterTxs(filter mempl.TxFilter, cursor uint64, max int) (types.Txs, uint64) {
+	req := &RequestFilterTxs{
+		Sender:     filter.Sender,
+		HashPrefix: filter.HashPrefix,
+		Cursor:     cursor,
+		Max:        int64(max),
+	}
+	if filter.MinPriority != nil {
+		req.FilterPriority = true
+		req.MinPriority = *filter.MinPriority
+	}
+	res, err := mem.client.FilterTxs(context.Background(), req)
+	if err != nil {
+		mem.logger.Error("Failed to filter the txs of the remote mempool", "err", err)
+		return types.Txs{}, cursor
+	}
+	return toTxs(res.Txs), res.Cursor
+}
+
+func (mem *Mempool) Lock() {
+	mem.mtx.Lock()
+}
+
+func (mem *Mempool) Unlock() {
+	mem.mtx.Unlock()
+}
+
+// Update informs the external mempool that the given txs were committed. The
+// pre and post check functions aren't supported.
+func (mem *Mempool) Update(
+	height int64,
+	txs types.Txs,
+	deliverTxResponses []*abci.ResponseDeliverTx,
+	_ mempl.PreCheckFunc,
+	_ mempl.PostCheckFunc,
+) error {
+	req := &RequestUpdate{Height: height, DeliverTxs: deliverTxResponses}
+	for _, tx := range txs {
+		req.Txs = append(req.Txs, tx)
+	}
+	if _, err := mem.client.Update(context.Background(), req); err != nil {
+		return err
+	}
+
+	mem.txsAvailableMtx.Lock()
+	mem.notifiedTxsAvailable = false
+	mem.txsAvailableMtx.Unlock()
+	if mem.Size() > 0 {
+		mem.notifyTxsAvailable()
+	}
+	return nil
+}
+
+func (mem *Mempool) FlushAppConn() error {
+	return nil
+}
+
+func (mem *Mempool) Flush() {
+	if _, err := mem.client.Flush(context.Background(), &RequestFlush{}); err != nil {
+		mem.logger.Error("Failed to flush the remote mempool", "err", err)
+	}
+}
+
+func (mem *Mempool) TxsAvailable() <-chan struct{} {
+	return mem.txsAvailable
+}
+
+// NOTE: not thread safe - should only be called once, on startup
+func (mem *Mempool) EnableTxsAvailable() {
+	mem.txsAvailable = make(chan struct{}, 1)
+}
+
+func (mem *Mempool) notifyTxsAvailable() {
+	mem.txsAvailableMtx.Lock()
+	defer mem.txsAvailableMtx.Unlock()
+
+	if mem.txsAvailable != nil && !mem.notifiedTxsAvailable {
+		// channel cap is 1, so this will send once
+		mem.notifiedTxsAvailable = true
+		select {
+		case mem.txsAvailable <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (mem *Mempool) Size() int {
+	res, err := mem.client.Size(context.Background(), &RequestSize{})
+	if err != nil {
+		mem.logger.Error("Failed to get the size of the remote mempool", "err", err)
+		return 0
+	}
+	return int(res.Size_)
+}
+
+func (mem *Mempool) TxsBytes() int64 {
+	res, err := mem.client.Size(context.Background(), &RequestSize{})
+	if err != nil {
+		mem.logger.Error("Failed to get the size of the remote mempool", "err", err)
+		return 0
+	}
+	return res.TxsBytes
+}
+
+// The external mempool has its own WAL and snapshots, if any.
+
+func (mem *Mempool) InitWAL()  {}
+func (mem *Mempool) CloseWAL() {}
+
+func (mem *Mempool) SaveSnapshot(string) error        { return nil }
+func (mem *Mempool) LoadSnapshot(string) (int, error) { return 0, nil }
+
+func toTxs(bzs [][]byte) types.Txs {
+	txs := make(types.Txs, len(bzs))
+	for i, bz := range bzs {
+		txs[i] = bz
+	}
+	return txs
+}
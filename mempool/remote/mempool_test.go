@@ -0,0 +1,71 @@
+package remote
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/proxy"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestRemoteMempool(t *testing.T) {
+	config := cfg.ResetTestRoot("remote_mempool_test")
+	defer os.RemoveAll(config.RootDir)
+
+	appConn, err := proxy.NewLocalClientCreator(kvstore.NewApplication()).NewABCIClient()
+	require.NoError(t, err)
+	require.NoError(t, appConn.Start())
+	defer appConn.Stop()
+	local := mempl.NewCListMempool(config.Mempool, appConn, 0)
+	local.SetLogger(log.TestingLogger())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	RegisterMempoolServiceServer(grpcServer, NewServer(local))
+	go grpcServer.Serve(ln) // nolint:errcheck
+	defer grpcServer.Stop()
+
+	mempool, err := NewMempool("tcp://" + ln.Addr().String())
+	require.NoError(t, err)
+	defer mempool.Close()
+	mempool.EnableTxsAvailable()
+
+	var res *abci.Response
+	require.NoError(t, mempool.CheckTx(types.Tx("a=1"), func(r *abci.Response) { res = r }, mempl.TxInfo{}))
+	require.NotNil(t, res)
+	assert.True(t, res.GetCheckTx().IsOK())
+	require.NoError(t, mempool.CheckTx(types.Tx("b=2"), nil, mempl.TxInfo{}))
+	select {
+	case <-mempool.TxsAvailable():
+	default:
+		t.Fatal("expected the txs to be available")
+	}
+
+	assert.Equal(t, 2, mempool.Size())
+	assert.Equal(t, local.TxsBytes(), mempool.TxsBytes())
+	assert.Equal(t, types.Txs{types.Tx("a=1"), types.Tx("b=2")}, mempool.ReapMaxBytesMaxGas(-1, -1))
+	assert.Equal(t, types.Txs{types.Tx("a=1"), types.Tx("b=2")}, mempool.ReapMaxTxs(-1))
+	txs, cursor := mempool.FilterTxs(mempl.TxFilter{}, 0, 1)
+	assert.Equal(t, types.Txs{types.Tx("a=1")}, txs)
+	txs, _ = mempool.FilterTxs(mempl.TxFilter{}, cursor, 1)
+	assert.Equal(t, types.Txs{types.Tx("b=2")}, txs)
+
+	err = mempool.Update(1, types.Txs{types.Tx("a=1")},
+		[]*abci.ResponseDeliverTx{{Code: abci.CodeTypeOK}}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.Txs{types.Tx("b=2")}, mempool.ReapMaxTxs(-1))
+
+	mempool.Flush()
+	assert.Zero(t, mempool.Size())
+}
@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"context"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+// server serves a local mempool, e.g. a CListMempool connected to the
+// application, as an external mempool.
+type server struct {
+	mempool mempl.Mempool
+}
+
+var _ MempoolServiceServer = server{}
+
+// NewServer returns a MempoolServiceServer serving mempool, to be registered
+// on a gRPC server with RegisterMempoolServiceServer.
+func NewServer(mempool mempl.Mempool) MempoolServiceServer {
+	return server{mempool: mempool}
+}
+
+func (s server) CheckTx(ctx context.Context, req *RequestCheckTx) (*ResponseCheckTx, error) {
+	resCh := make(chan *abci.Response, 1)
+	err := s.mempool.CheckTx(req.Tx, func(res *abci.Response) {
+		resCh <- res
+	}, mempl.TxInfo{SenderID: mempl.UnknownPeerID})
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case res := <-resCh:
+		return &ResponseCheckTx{CheckTx: res.GetCheckTx()}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s server) ReapMaxBytesMaxGas(ctx context.Context, req *RequestReapMaxBytesMaxGas) (*ResponseReap, error) {
+	return &ResponseReap{Txs: fromTxs(s.mempool.ReapMaxBytesMaxGas(req.MaxBytes, req.MaxGas))}, nil
+}
+
+func (s server) ReapMaxTxs(ctx context.Context, req *RequestReapMaxTxs) (*ResponseReap, error) {
+	return &ResponseReap{Txs: fromTxs(s.mempool.ReapMaxTxs(int(req.Max)))}, nil
+}
+
+func (s server) FilterTxs(ctx context.Context, req *RequestFilterTxs) (*ResponseFilterTxs, error) {
+	filter := mempl.TxFilter{Sender: req.Sender, HashPrefix: req.HashPrefix}
+	if req.FilterPriority {
+		filter.MinPriority = &req.MinPriority
+	}
+	txs, cursor := s.mempool.FilterTxs(filter, req.Cursor, int(req.Max))
+	return &ResponseFilterTxs{Txs: fromTxs(txs), Cursor: cursor}, nil
+}
+
+func (s server) Update(ctx context.Context, req *RequestUpdate) (*ResponseUpdate, error) {
+	txs := make(types.Txs, len(req.Txs))
+	for i, tx := range req.Txs {
+		txs[i] = tx
+	}
+
+	s.mempool.Lock()
+	defer s.mempool.Unlock()
+	if err := s.mempool.FlushAppConn(); err != nil {
+		return nil, err
+	}
+	if err := s.mempool.Update(req.Height, txs, req.DeliverTxs, nil, nil); err != nil {
+		return nil, err
+	}
+	return &ResponseUpdate{}, nil
+}
+
+func (s server) Flush(ctx context.Context, req *RequestFlush) (*ResponseFlush, error) {
+	s.mempool.Flush()
+	return &ResponseFlush{}, nil
+}
+
+func (s server) Size(ctx context.Context, req *RequestSize) (*ResponseSize, error) {
+	return &ResponseSize{Size_: int64(s.mempool.Size()), TxsBytes: s.mempool.TxsBytes()}, nil
+}
+
+func fromTxs(txs types.Txs) [][]byte {
+	bzs := make([][]byte, len(txs))
+	for i, tx := range txs {
+		bzs[i] = tx
+	}
+	return bzs
+}
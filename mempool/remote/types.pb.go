@@ -0,0 +1,3710 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: mempool/remote/types.proto
+
+package remote
+
+import (
+	bytes "bytes"
+	context "context"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	golang_proto "github.com/golang/protobuf/proto"
+	types "github.com/tendermint/tendermint/abci/types"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = golang_proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type RequestCheckTx struct {
+	Tx                   []byte   `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestCheckTx) Reset()         { *m = RequestCheckTx{} }
+func (m *RequestCheckTx) String() string { return proto.CompactTextString(m) }
+func (*RequestCheckTx) ProtoMessage()    {}
+func (*RequestCheckTx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{0}
+}
+func (m *RequestCheckTx) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestCheckTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestCheckTx.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestCheckTx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestCheckTx.Merge(m, src)
+}
+func (m *RequestCheckTx) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestCheckTx) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestCheckTx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestCheckTx proto.InternalMessageInfo
+
+func (m *RequestCheckTx) GetTx() []byte {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+type RequestReapMaxBytesMaxGas struct {
+	MaxBytes             int64    `protobuf:"varint,1,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	MaxGas               int64    `protobuf:"varint,2,opt,name=max_gas,json=maxGas,proto3" json:"max_gas,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestReapMaxBytesMaxGas) Reset()         { *m = RequestReapMaxBytesMaxGas{} }
+func (m *RequestReapMaxBytesMaxGas) String() string { return proto.CompactTextString(m) }
+func (*RequestReapMaxBytesMaxGas) ProtoMessage()    {}
+func (*RequestReapMaxBytesMaxGas) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{1}
+}
+func (m *RequestReapMaxBytesMaxGas) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestReapMaxBytesMaxGas) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestReapMaxBytesMaxGas.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestReapMaxBytesMaxGas) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestReapMaxBytesMaxGas.Merge(m, src)
+}
+func (m *RequestReapMaxBytesMaxGas) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestReapMaxBytesMaxGas) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestReapMaxBytesMaxGas.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestReapMaxBytesMaxGas proto.InternalMessageInfo
+
+func (m *RequestReapMaxBytesMaxGas) GetMaxBytes() int64 {
+	if m != nil {
+		return m.MaxBytes
+	}
+	return 0
+}
+
+func (m *RequestReapMaxBytesMaxGas) GetMaxGas() int64 {
+	if m != nil {
+		return m.MaxGas
+	}
+	return 0
+}
+
+type RequestReapMaxTxs struct {
+	Max                  int64    `protobuf:"varint,1,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestReapMaxTxs) Reset()         { *m = RequestReapMaxTxs{} }
+func (m *RequestReapMaxTxs) String() string { return proto.CompactTextString(m) }
+func (*RequestReapMaxTxs) ProtoMessage()    {}
+func (*RequestReapMaxTxs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{2}
+}
+func (m *RequestReapMaxTxs) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestReapMaxTxs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestReapMaxTxs.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestReapMaxTxs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestReapMaxTxs.Merge(m, src)
+}
+func (m *RequestReapMaxTxs) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestReapMaxTxs) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestReapMaxTxs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestReapMaxTxs proto.InternalMessageInfo
+
+func (m *RequestReapMaxTxs) GetMax() int64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+type RequestFilterTxs struct {
+	Sender               string   `protobuf:"bytes,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	FilterPriority       bool     `protobuf:"varint,2,opt,name=filter_priority,json=filterPriority,proto3" json:"filter_priority,omitempty"`
+	MinPriority          int64    `protobuf:"varint,3,opt,name=min_priority,json=minPriority,proto3" json:"min_priority,omitempty"`
+	HashPrefix           []byte   `protobuf:"bytes,4,opt,name=hash_prefix,json=hashPrefix,proto3" json:"hash_prefix,omitempty"`
+	Cursor               uint64   `protobuf:"varint,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Max                  int64    `protobuf:"varint,6,opt,name=max,proto3" json:"max,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestFilterTxs) Reset()         { *m = RequestFilterTxs{} }
+func (m *RequestFilterTxs) String() string { return proto.CompactTextString(m) }
+func (*RequestFilterTxs) ProtoMessage()    {}
+func (*RequestFilterTxs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{3}
+}
+func (m *RequestFilterTxs) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestFilterTxs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestFilterTxs.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestFilterTxs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestFilterTxs.Merge(m, src)
+}
+func (m *RequestFilterTxs) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestFilterTxs) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestFilterTxs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestFilterTxs proto.InternalMessageInfo
+
+func (m *RequestFilterTxs) GetSender() string {
+	if m != nil {
+		return m.Sender
+	}
+	return ""
+}
+
+func (m *RequestFilterTxs) GetFilterPriority() bool {
+	if m != nil {
+		return m.FilterPriority
+	}
+	return false
+}
+
+func (m *RequestFilterTxs) GetMinPriority() int64 {
+	if m != nil {
+		return m.MinPriority
+	}
+	return 0
+}
+
+func (m *RequestFilterTxs) GetHashPrefix() []byte {
+	if m != nil {
+		return m.HashPrefix
+	}
+	return nil
+}
+
+func (m *RequestFilterTxs) GetCursor() uint64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+func (m *RequestFilterTxs) GetMax() int64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+type RequestUpdate struct {
+	Height               int64                      `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Txs                  [][]byte                   `protobuf:"bytes,2,rep,name=txs,proto3" json:"txs,omitempty"`
+	DeliverTxs           []*types.ResponseDeliverTx `protobuf:"bytes,3,rep,name=deliver_txs,json=deliverTxs,proto3" json:"deliver_txs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
+	XXX_unrecognized     []byte                     `json:"-"`
+	XXX_sizecache        int32                      `json:"-"`
+}
+
+func (m *RequestUpdate) Reset()         { *m = RequestUpdate{} }
+func (m *RequestUpdate) String() string { return proto.CompactTextString(m) }
+func (*RequestUpdate) ProtoMessage()    {}
+func (*RequestUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{4}
+}
+func (m *RequestUpdate) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestUpdate.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestUpdate.Merge(m, src)
+}
+func (m *RequestUpdate) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestUpdate proto.InternalMessageInfo
+
+func (m *RequestUpdate) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *RequestUpdate) GetTxs() [][]byte {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
+func (m *RequestUpdate) GetDeliverTxs() []*types.ResponseDeliverTx {
+	if m != nil {
+		return m.DeliverTxs
+	}
+	return nil
+}
+
+type RequestFlush struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestFlush) Reset()         { *m = RequestFlush{} }
+func (m *RequestFlush) String() string { return proto.CompactTextString(m) }
+func (*RequestFlush) ProtoMessage()    {}
+func (*RequestFlush) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{5}
+}
+func (m *RequestFlush) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestFlush) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestFlush.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestFlush) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestFlush.Merge(m, src)
+}
+func (m *RequestFlush) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestFlush) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestFlush.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestFlush proto.InternalMessageInfo
+
+type RequestSize struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestSize) Reset()         { *m = RequestSize{} }
+func (m *RequestSize) String() string { return proto.CompactTextString(m) }
+func (*RequestSize) ProtoMessage()    {}
+func (*RequestSize) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{6}
+}
+func (m *RequestSize) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RequestSize) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RequestSize.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RequestSize) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestSize.Merge(m, src)
+}
+func (m *RequestSize) XXX_Size() int {
+	return m.Size()
+}
+func (m *RequestSize) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestSize.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestSize proto.InternalMessageInfo
+
+type ResponseCheckTx struct {
+	CheckTx              *types.ResponseCheckTx `protobuf:"bytes,1,opt,name=check_tx,json=checkTx,proto3" json:"check_tx,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *ResponseCheckTx) Reset()         { *m = ResponseCheckTx{} }
+func (m *ResponseCheckTx) String() string { return proto.CompactTextString(m) }
+func (*ResponseCheckTx) ProtoMessage()    {}
+func (*ResponseCheckTx) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{7}
+}
+func (m *ResponseCheckTx) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseCheckTx) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseCheckTx.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseCheckTx) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseCheckTx.Merge(m, src)
+}
+func (m *ResponseCheckTx) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseCheckTx) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseCheckTx.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseCheckTx proto.InternalMessageInfo
+
+func (m *ResponseCheckTx) GetCheckTx() *types.ResponseCheckTx {
+	if m != nil {
+		return m.CheckTx
+	}
+	return nil
+}
+
+type ResponseReap struct {
+	Txs                  [][]byte `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseReap) Reset()         { *m = ResponseReap{} }
+func (m *ResponseReap) String() string { return proto.CompactTextString(m) }
+func (*ResponseReap) ProtoMessage()    {}
+func (*ResponseReap) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{8}
+}
+func (m *ResponseReap) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseReap) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseReap.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseReap) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseReap.Merge(m, src)
+}
+func (m *ResponseReap) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseReap) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseReap.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseReap proto.InternalMessageInfo
+
+func (m *ResponseReap) GetTxs() [][]byte {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
+type ResponseFilterTxs struct {
+	Txs                  [][]byte `protobuf:"bytes,1,rep,name=txs,proto3" json:"txs,omitempty"`
+	Cursor               uint64   `protobuf:"varint,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseFilterTxs) Reset()         { *m = ResponseFilterTxs{} }
+func (m *ResponseFilterTxs) String() string { return proto.CompactTextString(m) }
+func (*ResponseFilterTxs) ProtoMessage()    {}
+func (*ResponseFilterTxs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{9}
+}
+func (m *ResponseFilterTxs) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseFilterTxs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseFilterTxs.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseFilterTxs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseFilterTxs.Merge(m, src)
+}
+func (m *ResponseFilterTxs) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseFilterTxs) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseFilterTxs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseFilterTxs proto.InternalMessageInfo
+
+func (m *ResponseFilterTxs) GetTxs() [][]byte {
+	if m != nil {
+		return m.Txs
+	}
+	return nil
+}
+
+func (m *ResponseFilterTxs) GetCursor() uint64 {
+	if m != nil {
+		return m.Cursor
+	}
+	return 0
+}
+
+type ResponseUpdate struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseUpdate) Reset()         { *m = ResponseUpdate{} }
+func (m *ResponseUpdate) String() string { return proto.CompactTextString(m) }
+func (*ResponseUpdate) ProtoMessage()    {}
+func (*ResponseUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{10}
+}
+func (m *ResponseUpdate) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseUpdate.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseUpdate.Merge(m, src)
+}
+func (m *ResponseUpdate) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseUpdate proto.InternalMessageInfo
+
+type ResponseFlush struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseFlush) Reset()         { *m = ResponseFlush{} }
+func (m *ResponseFlush) String() string { return proto.CompactTextString(m) }
+func (*ResponseFlush) ProtoMessage()    {}
+func (*ResponseFlush) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{11}
+}
+func (m *ResponseFlush) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseFlush) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseFlush.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseFlush) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseFlush.Merge(m, src)
+}
+func (m *ResponseFlush) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseFlush) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseFlush.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseFlush proto.InternalMessageInfo
+
+type ResponseSize struct {
+	Size_                int64    `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	TxsBytes             int64    `protobuf:"varint,2,opt,name=txs_bytes,json=txsBytes,proto3" json:"txs_bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ResponseSize) Reset()         { *m = ResponseSize{} }
+func (m *ResponseSize) String() string { return proto.CompactTextString(m) }
+func (*ResponseSize) ProtoMessage()    {}
+func (*ResponseSize) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0351181f190c071e, []int{12}
+}
+func (m *ResponseSize) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ResponseSize) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ResponseSize.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ResponseSize) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ResponseSize.Merge(m, src)
+}
+func (m *ResponseSize) XXX_Size() int {
+	return m.Size()
+}
+func (m *ResponseSize) XXX_DiscardUnknown() {
+	xxx_messageInfo_ResponseSize.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ResponseSize proto.InternalMessageInfo
+
+func (m *ResponseSize) GetSize_() int64 {
+	if m != nil {
+		return m.Size_
+	}
+	return 0
+}
+
+func (m *ResponseSize) GetTxsBytes() int64 {
+	if m != nil {
+		return m.TxsBytes
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*RequestCheckTx)(nil), "tendermint.mempool.remote.RequestCheckTx")
+	golang_proto.RegisterType((*RequestCheckTx)(nil), "tendermint.mempool.remote.RequestCheckTx")
+	proto.RegisterType((*RequestReapMaxBytesMaxGas)(nil), "tendermint.mempool.remote.RequestReapMaxBytesMaxGas")
+	golang_proto.RegisterType((*RequestReapMaxBytesMaxGas)(nil), "tendermint.mempool.remote.RequestReapMaxBytesMaxGas")
+	proto.RegisterType((*RequestReapMaxTxs)(nil), "tendermint.mempool.remote.RequestReapMaxTxs")
+	golang_proto.RegisterType((*RequestReapMaxTxs)(nil), "tendermint.mempool.remote.RequestReapMaxTxs")
+	proto.RegisterType((*RequestFilterTxs)(nil), "tendermint.mempool.remote.RequestFilterTxs")
+	golang_proto.RegisterType((*RequestFilterTxs)(nil), "tendermint.mempool.remote.RequestFilterTxs")
+	proto.RegisterType((*RequestUpdate)(nil), "tendermint.mempool.remote.RequestUpdate")
+	golang_proto.RegisterType((*RequestUpdate)(nil), "tendermint.mempool.remote.RequestUpdate")
+	proto.RegisterType((*RequestFlush)(nil), "tendermint.mempool.remote.RequestFlush")
+	golang_proto.RegisterType((*RequestFlush)(nil), "tendermint.mempool.remote.RequestFlush")
+	proto.RegisterType((*RequestSize)(nil), "tendermint.mempool.remote.RequestSize")
+	golang_proto.RegisterType((*RequestSize)(nil), "tendermint.mempool.remote.RequestSize")
+	proto.RegisterType((*ResponseCheckTx)(nil), "tendermint.mempool.remote.ResponseCheckTx")
+	golang_proto.RegisterType((*ResponseCheckTx)(nil), "tendermint.mempool.remote.ResponseCheckTx")
+	proto.RegisterType((*ResponseReap)(nil), "tendermint.mempool.remote.ResponseReap")
+	golang_proto.RegisterType((*ResponseReap)(nil), "tendermint.mempool.remote.ResponseReap")
+	proto.RegisterType((*ResponseFilterTxs)(nil), "tendermint.mempool.remote.ResponseFilterTxs")
+	golang_proto.RegisterType((*ResponseFilterTxs)(nil), "tendermint.mempool.remote.ResponseFilterTxs")
+	proto.RegisterType((*ResponseUpdate)(nil), "tendermint.mempool.remote.ResponseUpdate")
+	golang_proto.RegisterType((*ResponseUpdate)(nil), "tendermint.mempool.remote.ResponseUpdate")
+	proto.RegisterType((*ResponseFlush)(nil), "tendermint.mempool.remote.ResponseFlush")
+	golang_proto.RegisterType((*ResponseFlush)(nil), "tendermint.mempool.remote.ResponseFlush")
+	proto.RegisterType((*ResponseSize)(nil), "tendermint.mempool.remote.ResponseSize")
+	golang_proto.RegisterType((*ResponseSize)(nil), "tendermint.mempool.remote.ResponseSize")
+}
+
+func init() { proto.RegisterFile("mempool/remote/types.proto", fileDescriptor_0351181f190c071e) }
+func init() { golang_proto.RegisterFile("mempool/remote/types.proto", fileDescriptor_0351181f190c071e) }
+
+var fileDescriptor_0351181f190c071e = []byte{
+	// 691 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0x41, 0x6f, 0xd3, 0x4a,
+	0x10, 0x96, 0x93, 0x34, 0x4d, 0x27, 0x69, 0xda, 0xee, 0xa1, 0x2f, 0xf5, 0x93, 0xfc, 0xf2, 0x2c,
+	0xbd, 0xd6, 0x7d, 0x54, 0x8e, 0x54, 0xb8, 0x22, 0x44, 0x41, 0x20, 0x0e, 0x91, 0x8a, 0x1b, 0x84,
+	0x54, 0x09, 0x05, 0xc7, 0xd9, 0xc6, 0x2b, 0xe2, 0xd8, 0xec, 0x6e, 0xaa, 0x4d, 0xcf, 0xfc, 0x18,
+	0x7e, 0x02, 0x47, 0xb8, 0xf5, 0xc8, 0x4f, 0x80, 0xf0, 0x27, 0x38, 0xa2, 0x5d, 0xaf, 0xeb, 0x44,
+	0x05, 0x62, 0x6e, 0xb3, 0xb3, 0xdf, 0xcc, 0xb7, 0xf3, 0xcd, 0x8c, 0x16, 0xcc, 0x08, 0x47, 0x49,
+	0x1c, 0x8f, 0x3b, 0x14, 0x47, 0x31, 0xc7, 0x1d, 0x3e, 0x4b, 0x30, 0x73, 0x13, 0x1a, 0xf3, 0x18,
+	0xed, 0x71, 0x3c, 0x19, 0x62, 0x1a, 0x91, 0x09, 0x77, 0x35, 0xcc, 0x4d, 0x61, 0xe6, 0x3e, 0x0f,
+	0x09, 0x1d, 0xf6, 0x13, 0x9f, 0xf2, 0x59, 0x47, 0xa1, 0x3b, 0xa3, 0x78, 0x14, 0xe7, 0x56, 0x9a,
+	0xc2, 0xdc, 0xf5, 0x07, 0x01, 0x49, 0x93, 0x2e, 0xa6, 0xb6, 0xdb, 0xd0, 0xf4, 0xf0, 0xdb, 0x29,
+	0x66, 0xfc, 0x51, 0x88, 0x83, 0x37, 0x3d, 0x81, 0x9a, 0x50, 0xe2, 0xa2, 0x65, 0xb4, 0x0d, 0xa7,
+	0xe1, 0x95, 0xb8, 0xb0, 0x9f, 0xc3, 0x9e, 0x46, 0x78, 0xd8, 0x4f, 0xba, 0xbe, 0x38, 0x99, 0x71,
+	0xcc, 0xba, 0xbe, 0x78, 0xea, 0x33, 0xf4, 0x37, 0x6c, 0x44, 0xbe, 0xe8, 0x0f, 0xa4, 0x4b, 0xc5,
+	0x94, 0xbd, 0x5a, 0xa4, 0x21, 0xe8, 0x2f, 0x58, 0x97, 0x97, 0x23, 0x9f, 0xb5, 0x4a, 0xea, 0xaa,
+	0x1a, 0xa9, 0x28, 0xfb, 0x3f, 0xd8, 0x59, 0x4e, 0xd9, 0x13, 0x0c, 0x6d, 0x43, 0x39, 0xf2, 0x85,
+	0x4e, 0x22, 0x4d, 0xfb, 0x93, 0x01, 0xdb, 0x1a, 0xf7, 0x84, 0x8c, 0x39, 0xa6, 0x12, 0xb6, 0x0b,
+	0x55, 0xa6, 0xd4, 0x50, 0xc8, 0x0d, 0x4f, 0x9f, 0xd0, 0x01, 0x6c, 0x5d, 0x28, 0x50, 0x3f, 0xa1,
+	0x24, 0xa6, 0x84, 0xcf, 0x14, 0x69, 0xcd, 0x6b, 0xa6, 0xee, 0x53, 0xed, 0x45, 0xff, 0x42, 0x23,
+	0x22, 0x93, 0x1c, 0x55, 0x56, 0x84, 0xf5, 0x88, 0x4c, 0x6e, 0x20, 0xff, 0x40, 0x3d, 0xf4, 0x59,
+	0xd8, 0x4f, 0x28, 0xbe, 0x20, 0xa2, 0x55, 0x51, 0x5a, 0x80, 0x74, 0x9d, 0x2a, 0x8f, 0x7c, 0x44,
+	0x30, 0xa5, 0x2c, 0xa6, 0xad, 0xb5, 0xb6, 0xe1, 0x54, 0x3c, 0x7d, 0xca, 0x6a, 0xa8, 0xe6, 0x35,
+	0xbc, 0x33, 0x60, 0x53, 0xd7, 0xf0, 0x22, 0x19, 0xfa, 0x1c, 0xcb, 0xd8, 0x10, 0x93, 0x51, 0xc8,
+	0x75, 0xa9, 0xfa, 0x24, 0x63, 0xb9, 0x90, 0x4a, 0x95, 0x9d, 0x86, 0x27, 0x4d, 0xf4, 0x0c, 0xea,
+	0x43, 0x3c, 0x26, 0x97, 0x98, 0xf6, 0xe5, 0x4d, 0xb9, 0x5d, 0x76, 0xea, 0xc7, 0x8e, 0xbb, 0x30,
+	0x0c, 0xb2, 0xa9, 0x6e, 0xda, 0x4e, 0x0f, 0xb3, 0x24, 0x9e, 0x30, 0xfc, 0x38, 0x8d, 0xe8, 0x09,
+	0x0f, 0x86, 0x99, 0xc9, 0xec, 0x26, 0x34, 0x32, 0x25, 0xc7, 0x53, 0x16, 0xda, 0x9b, 0x50, 0xd7,
+	0xe7, 0x33, 0x72, 0x85, 0xed, 0x1e, 0x6c, 0x65, 0xf1, 0xd9, 0x18, 0x3c, 0x84, 0x5a, 0x20, 0xcd,
+	0xbe, 0x1e, 0x86, 0xfa, 0xf1, 0xfe, 0x0a, 0x66, 0x1d, 0xe9, 0xad, 0x07, 0xa9, 0x61, 0xb7, 0xa1,
+	0x91, 0xdd, 0xc9, 0x3e, 0x67, 0x15, 0x1a, 0x37, 0x15, 0xda, 0xf7, 0x61, 0x27, 0x43, 0xe4, 0x1d,
+	0xbe, 0x05, 0x5b, 0x90, 0xbb, 0xb4, 0x28, 0xb7, 0xbd, 0x0d, 0xcd, 0x2c, 0x3c, 0x15, 0xd7, 0xde,
+	0x82, 0xcd, 0xcc, 0x93, 0x16, 0xfa, 0x20, 0x7f, 0x83, 0xac, 0x14, 0x21, 0xa8, 0x30, 0x72, 0x85,
+	0xb5, 0xf6, 0xca, 0x96, 0x43, 0xcc, 0x05, 0xd3, 0x43, 0x9c, 0x4e, 0x6a, 0x8d, 0x0b, 0xa6, 0x86,
+	0xf8, 0xf8, 0x7a, 0x0d, 0x9a, 0xdd, 0x74, 0xe7, 0xce, 0x30, 0xbd, 0x24, 0x01, 0x46, 0xaf, 0x61,
+	0x3d, 0x53, 0xe9, 0xd0, 0xfd, 0xe5, 0x6a, 0xba, 0xcb, 0x7b, 0x65, 0xfe, 0xff, 0x5b, 0xe8, 0xb2,
+	0xf8, 0x0c, 0xd0, 0x4f, 0x96, 0xed, 0xde, 0x6a, 0xb2, 0xdb, 0x51, 0xe6, 0x41, 0x01, 0x5e, 0xd5,
+	0x9e, 0x00, 0x60, 0x61, 0x1d, 0x8f, 0x0a, 0x93, 0xf5, 0xc4, 0x1f, 0x90, 0x5c, 0xc0, 0x46, 0xde,
+	0xe9, 0x3b, 0xab, 0x39, 0x6e, 0xc0, 0xe6, 0x51, 0x01, 0x8a, 0x3c, 0xf5, 0x2b, 0xa8, 0xea, 0x7d,
+	0x73, 0x56, 0x93, 0xa4, 0x48, 0xf3, 0xb0, 0x00, 0x83, 0x4e, 0x7a, 0x0e, 0x6b, 0x6a, 0xbe, 0xd0,
+	0x41, 0x81, 0x12, 0x24, 0xd0, 0x74, 0x8a, 0x3c, 0x5f, 0xa5, 0x7c, 0x09, 0x15, 0x35, 0xaa, 0xfb,
+	0xab, 0x53, 0x4b, 0x5c, 0x21, 0xed, 0x25, 0xf0, 0xa4, 0xfb, 0xfd, 0xab, 0x65, 0xbc, 0x9f, 0x5b,
+	0xc6, 0x87, 0xb9, 0x65, 0x5c, 0xcf, 0x2d, 0xe3, 0xf3, 0xdc, 0x32, 0xbe, 0xcc, 0x2d, 0xe3, 0xe3,
+	0x37, 0xcb, 0x38, 0xef, 0x8c, 0x08, 0x0f, 0xa7, 0x03, 0x37, 0x88, 0xa3, 0x4e, 0x9e, 0x70, 0xd1,
+	0x5c, 0xfe, 0xa1, 0x06, 0x55, 0xf5, 0x83, 0xdc, 0xfd, 0x31, 0x00, 0x7e, 0x58, 0x43, 0xdb, 0xba,
+	0x06, 0x00, 0x00,
+}
+
+func (this *RequestCheckTx) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestCheckTx)
+	if !ok {
+		that2, ok := that.(RequestCheckTx)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Tx, that1.Tx) {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *RequestReapMaxBytesMaxGas) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestReapMaxBytesMaxGas)
+	if !ok {
+		that2, ok := that.(RequestReapMaxBytesMaxGas)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.MaxBytes != that1.MaxBytes {
+		return false
+	}
+	if this.MaxGas != that1.MaxGas {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *RequestReapMaxTxs) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestReapMaxTxs)
+	if !ok {
+		that2, ok := that.(RequestReapMaxTxs)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Max != that1.Max {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *RequestFilterTxs) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestFilterTxs)
+	if !ok {
+		that2, ok := that.(RequestFilterTxs)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Sender != that1.Sender {
+		return false
+	}
+	if this.FilterPriority != that1.FilterPriority {
+		return false
+	}
+	if this.MinPriority != that1.MinPriority {
+		return false
+	}
+	if !bytes.Equal(this.HashPrefix, that1.HashPrefix) {
+		return false
+	}
+	if this.Cursor != that1.Cursor {
+		return false
+	}
+	if this.Max != that1.Max {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *RequestUpdate) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestUpdate)
+	if !ok {
+		that2, ok := that.(RequestUpdate)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Height != that1.Height {
+		return false
+	}
+	if len(this.Txs) != len(that1.Txs) {
+		return false
+	}
+	for i := range this.Txs {
+		if !bytes.Equal(this.Txs[i], that1.Txs[i]) {
+			return false
+		}
+	}
+	if len(this.DeliverTxs) != len(that1.DeliverTxs) {
+		return false
+	}
+	for i := range this.DeliverTxs {
+		if !this.DeliverTxs[i].Equal(that1.DeliverTxs[i]) {
+			return false
+		}
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *RequestFlush) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestFlush)
+	if !ok {
+		that2, ok := that.(RequestFlush)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *RequestSize) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*RequestSize)
+	if !ok {
+		that2, ok := that.(RequestSize)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ResponseCheckTx) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ResponseCheckTx)
+	if !ok {
+		that2, ok := that.(ResponseCheckTx)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !this.CheckTx.Equal(that1.CheckTx) {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ResponseReap) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ResponseReap)
+	if !ok {
+		that2, ok := that.(ResponseReap)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Txs) != len(that1.Txs) {
+		return false
+	}
+	for i := range this.Txs {
+		if !bytes.Equal(this.Txs[i], that1.Txs[i]) {
+			return false
+		}
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ResponseFilterTxs) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ResponseFilterTxs)
+	if !ok {
+		that2, ok := that.(ResponseFilterTxs)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Txs) != len(that1.Txs) {
+		return false
+	}
+	for i := range this.Txs {
+		if !bytes.Equal(this.Txs[i], that1.Txs[i]) {
+			return false
+		}
+	}
+	if this.Cursor != that1.Cursor {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ResponseUpdate) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ResponseUpdate)
+	if !ok {
+		that2, ok := that.(ResponseUpdate)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ResponseFlush) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ResponseFlush)
+	if !ok {
+		that2, ok := that.(ResponseFlush)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+func (this *ResponseSize) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ResponseSize)
+	if !ok {
+		that2, ok := that.(ResponseSize)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Size_ != that1.Size_ {
+		return false
+	}
+	if this.TxsBytes != that1.TxsBytes {
+		return false
+	}
+	if !bytes.Equal(this.XXX_unrecognized, that1.XXX_unrecognized) {
+		return false
+	}
+	return true
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// MempoolServiceClient is the client API for MempoolService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MempoolServiceClient interface {
+	CheckTx(ctx context.Context, in *RequestCheckTx, opts ...grpc.CallOption) (*ResponseCheckTx, error)
+	ReapMaxBytesMaxGas(ctx context.Context, in *RequestReapMaxBytesMaxGas, opts ...grpc.CallOption) (*ResponseReap, error)
+	ReapMaxTxs(ctx context.Context, in *RequestReapMaxTxs, opts ...grpc.CallOption) (*ResponseReap, error)
+	FilterTxs(ctx context.Context, in *RequestFilterTxs, opts ...grpc.CallOption) (*ResponseFilterTxs, error)
+	Update(ctx context.Context, in *RequestUpdate, opts ...grpc.CallOption) (*ResponseUpdate, error)
+	Flush(ctx context.Context, in *RequestFlush, opts ...grpc.CallOption) (*ResponseFlush, error)
+	Size(ctx context.Context, in *RequestSize, opts ...grpc.CallOption) (*ResponseSize, error)
+}
+
+type mempoolServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMempoolServiceClient(cc *grpc.ClientConn) MempoolServiceClient {
+	return &mempoolServiceClient{cc}
+}
+
+func (c *mempoolServiceClient) CheckTx(ctx context.Context, in *RequestCheckTx, opts ...grpc.CallOption) (*ResponseCheckTx, error) {
+	out := new(ResponseCheckTx)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/CheckTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) ReapMaxBytesMaxGas(ctx context.Context, in *RequestReapMaxBytesMaxGas, opts ...grpc.CallOption) (*ResponseReap, error) {
+	out := new(ResponseReap)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/ReapMaxBytesMaxGas", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) ReapMaxTxs(ctx context.Context, in *RequestReapMaxTxs, opts ...grpc.CallOption) (*ResponseReap, error) {
+	out := new(ResponseReap)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/ReapMaxTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) FilterTxs(ctx context.Context, in *RequestFilterTxs, opts ...grpc.CallOption) (*ResponseFilterTxs, error) {
+	out := new(ResponseFilterTxs)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/FilterTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) Update(ctx context.Context, in *RequestUpdate, opts ...grpc.CallOption) (*ResponseUpdate, error) {
+	out := new(ResponseUpdate)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) Flush(ctx context.Context, in *RequestFlush, opts ...grpc.CallOption) (*ResponseFlush, error) {
+	out := new(ResponseFlush)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/Flush", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mempoolServiceClient) Size(ctx context.Context, in *RequestSize, opts ...grpc.CallOption) (*ResponseSize, error) {
+	out := new(ResponseSize)
+	err := c.cc.Invoke(ctx, "/tendermint.mempool.remote.MempoolService/Size", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MempoolServiceServer is the server API for MempoolService service.
+type MempoolServiceServer interface {
+	CheckTx(context.Context, *RequestCheckTx) (*ResponseCheckTx, error)
+	ReapMaxBytesMaxGas(context.Context, *RequestReapMaxBytesMaxGas) (*ResponseReap, error)
+	ReapMaxTxs(context.Context, *RequestReapMaxTxs) (*ResponseReap, error)
+	FilterTxs(context.Context, *RequestFilterTxs) (*ResponseFilterTxs, error)
+	Update(context.Context, *RequestUpdate) (*ResponseUpdate, error)
+	Flush(context.Context, *RequestFlush) (*ResponseFlush, error)
+	Size(context.Context, *RequestSize) (*ResponseSize, error)
+}
+
+// UnimplementedMempoolServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedMempoolServiceServer struct {
+}
+
+func (*UnimplementedMempoolServiceServer) CheckTx(ctx context.Context, req *RequestCheckTx) (*ResponseCheckTx, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckTx not implemented")
+}
+func (*UnimplementedMempoolServiceServer) ReapMaxBytesMaxGas(ctx context.Context, req *RequestReapMaxBytesMaxGas) (*ResponseReap, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReapMaxBytesMaxGas not implemented")
+}
+func (*UnimplementedMempoolServiceServer) ReapMaxTxs(ctx context.Context, req *RequestReapMaxTxs) (*ResponseReap, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReapMaxTxs not implemented")
+}
+func (*UnimplementedMempoolServiceServer) FilterTxs(ctx context.Context, req *RequestFilterTxs) (*ResponseFilterTxs, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FilterTxs not implemented")
+}
+func (*UnimplementedMempoolServiceServer) Update(ctx context.Context, req *RequestUpdate) (*ResponseUpdate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (*UnimplementedMempoolServiceServer) Flush(ctx context.Context, req *RequestFlush) (*ResponseFlush, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Flush not implemented")
+}
+func (*UnimplementedMempoolServiceServer) Size(ctx context.Context, req *RequestSize) (*ResponseSize, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Size not implemented")
+}
+
+func RegisterMempoolServiceServer(s *grpc.Server, srv MempoolServiceServer) {
+	s.RegisterService(&_MempoolService_serviceDesc, srv)
+}
+
+func _MempoolService_CheckTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestCheckTx)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).CheckTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/CheckTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).CheckTx(ctx, req.(*RequestCheckTx))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_ReapMaxBytesMaxGas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestReapMaxBytesMaxGas)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).ReapMaxBytesMaxGas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/ReapMaxBytesMaxGas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).ReapMaxBytesMaxGas(ctx, req.(*RequestReapMaxBytesMaxGas))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_ReapMaxTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestReapMaxTxs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).ReapMaxTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/ReapMaxTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).ReapMaxTxs(ctx, req.(*RequestReapMaxTxs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_FilterTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestFilterTxs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).FilterTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/FilterTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).FilterTxs(ctx, req.(*RequestFilterTxs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestUpdate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).Update(ctx, req.(*RequestUpdate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_Flush_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestFlush)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).Flush(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/Flush",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).Flush(ctx, req.(*RequestFlush))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MempoolService_Size_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestSize)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MempoolServiceServer).Size(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.mempool.remote.MempoolService/Size",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MempoolServiceServer).Size(ctx, req.(*RequestSize))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _MempoolService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tendermint.mempool.remote.MempoolService",
+	HandlerType: (*MempoolServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckTx",
+			Handler:    _MempoolService_CheckTx_Handler,
+		},
+		{
+			MethodName: "ReapMaxBytesMaxGas",
+			Handler:    _MempoolService_ReapMaxBytesMaxGas_Handler,
+		},
+		{
+			MethodName: "ReapMaxTxs",
+			Handler:    _MempoolService_ReapMaxTxs_Handler,
+		},
+		{
+			MethodName: "FilterTxs",
+			Handler:    _MempoolService_FilterTxs_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _MempoolService_Update_Handler,
+		},
+		{
+			MethodName: "Flush",
+			Handler:    _MempoolService_Flush_Handler,
+		},
+		{
+			MethodName: "Size",
+			Handler:    _MempoolService_Size_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "mempool/remote/types.proto",
+}
+
+func (m *RequestCheckTx) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestCheckTx) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestCheckTx) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Tx) > 0 {
+		i -= len(m.Tx)
+		copy(dAtA[i:], m.Tx)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Tx)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RequestReapMaxBytesMaxGas) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestReapMaxBytesMaxGas) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestReapMaxBytesMaxGas) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.MaxGas != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxGas))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.MaxBytes != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxBytes))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RequestReapMaxTxs) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestReapMaxTxs) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestReapMaxTxs) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Max != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Max))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RequestFilterTxs) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestFilterTxs) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestFilterTxs) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Max != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Max))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.Cursor != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Cursor))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.HashPrefix) > 0 {
+		i -= len(m.HashPrefix)
+		copy(dAtA[i:], m.HashPrefix)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.HashPrefix)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.MinPriority != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MinPriority))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.FilterPriority {
+		i--
+		if m.FilterPriority {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Sender) > 0 {
+		i -= len(m.Sender)
+		copy(dAtA[i:], m.Sender)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Sender)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RequestUpdate) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestUpdate) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestUpdate) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.DeliverTxs) > 0 {
+		for iNdEx := len(m.DeliverTxs) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.DeliverTxs[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTypes(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Txs) > 0 {
+		for iNdEx := len(m.Txs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Txs[iNdEx])
+			copy(dAtA[i:], m.Txs[iNdEx])
+			i = encodeVarintTypes(dAtA, i, uint64(len(m.Txs[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Height != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RequestFlush) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestFlush) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestFlush) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RequestSize) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RequestSize) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RequestSize) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ResponseCheckTx) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseCheckTx) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ResponseCheckTx) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.CheckTx != nil {
+		{
+			size, err := m.CheckTx.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ResponseReap) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseReap) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ResponseReap) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Txs) > 0 {
+		for iNdEx := len(m.Txs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Txs[iNdEx])
+			copy(dAtA[i:], m.Txs[iNdEx])
+			i = encodeVarintTypes(dAtA, i, uint64(len(m.Txs[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ResponseFilterTxs) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseFilterTxs) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ResponseFilterTxs) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Cursor != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Cursor))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Txs) > 0 {
+		for iNdEx := len(m.Txs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Txs[iNdEx])
+			copy(dAtA[i:], m.Txs[iNdEx])
+			i = encodeVarintTypes(dAtA, i, uint64(len(m.Txs[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ResponseUpdate) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseUpdate) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ResponseUpdate) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ResponseFlush) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseFlush) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ResponseFlush) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ResponseSize) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ResponseSize) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ResponseSize) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.TxsBytes != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.TxsBytes))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Size_ != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.Size_))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
+	offset -= sovTypes(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func NewPopulatedRequestCheckTx(r randyTypes, easy bool) *RequestCheckTx {
+	this := &RequestCheckTx{}
+	v1 := r.Intn(100)
+	this.Tx = make([]byte, v1)
+	for i := 0; i < v1; i++ {
+		this.Tx[i] = byte(r.Intn(256))
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 2)
+	}
+	return this
+}
+
+func NewPopulatedRequestReapMaxBytesMaxGas(r randyTypes, easy bool) *RequestReapMaxBytesMaxGas {
+	this := &RequestReapMaxBytesMaxGas{}
+	this.MaxBytes = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.MaxBytes *= -1
+	}
+	this.MaxGas = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.MaxGas *= -1
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 3)
+	}
+	return this
+}
+
+func NewPopulatedRequestReapMaxTxs(r randyTypes, easy bool) *RequestReapMaxTxs {
+	this := &RequestReapMaxTxs{}
+	this.Max = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.Max *= -1
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 2)
+	}
+	return this
+}
+
+func NewPopulatedRequestFilterTxs(r randyTypes, easy bool) *RequestFilterTxs {
+	this := &RequestFilterTxs{}
+	this.Sender = string(randStringTypes(r))
+	this.FilterPriority = bool(bool(r.Intn(2) == 0))
+	this.MinPriority = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.MinPriority *= -1
+	}
+	v2 := r.Intn(100)
+	this.HashPrefix = make([]byte, v2)
+	for i := 0; i < v2; i++ {
+		this.HashPrefix[i] = byte(r.Intn(256))
+	}
+	this.Cursor = uint64(uint64(r.Uint32()))
+	this.Max = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.Max *= -1
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 7)
+	}
+	return this
+}
+
+func NewPopulatedRequestUpdate(r randyTypes, easy bool) *RequestUpdate {
+	this := &RequestUpdate{}
+	this.Height = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.Height *= -1
+	}
+	v3 := r.Intn(10)
+	this.Txs = make([][]byte, v3)
+	for i := 0; i < v3; i++ {
+		v4 := r.Intn(100)
+		this.Txs[i] = make([]byte, v4)
+		for j := 0; j < v4; j++ {
+			this.Txs[i][j] = byte(r.Intn(256))
+		}
+	}
+	if r.Intn(5) != 0 {
+		v5 := r.Intn(5)
+		this.DeliverTxs = make([]*types.ResponseDeliverTx, v5)
+		for i := 0; i < v5; i++ {
+			this.DeliverTxs[i] = types.NewPopulatedResponseDeliverTx(r, easy)
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 4)
+	}
+	return this
+}
+
+func NewPopulatedRequestFlush(r randyTypes, easy bool) *RequestFlush {
+	this := &RequestFlush{}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 1)
+	}
+	return this
+}
+
+func NewPopulatedRequestSize(r randyTypes, easy bool) *RequestSize {
+	this := &RequestSize{}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 1)
+	}
+	return this
+}
+
+func NewPopulatedResponseCheckTx(r randyTypes, easy bool) *ResponseCheckTx {
+	this := &ResponseCheckTx{}
+	if r.Intn(5) != 0 {
+		this.CheckTx = types.NewPopulatedResponseCheckTx(r, easy)
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 2)
+	}
+	return this
+}
+
+func NewPopulatedResponseReap(r randyTypes, easy bool) *ResponseReap {
+	this := &ResponseReap{}
+	v6 := r.Intn(10)
+	this.Txs = make([][]byte, v6)
+	for i := 0; i < v6; i++ {
+		v7 := r.Intn(100)
+		this.Txs[i] = make([]byte, v7)
+		for j := 0; j < v7; j++ {
+			this.Txs[i][j] = byte(r.Intn(256))
+		}
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 2)
+	}
+	return this
+}
+
+func NewPopulatedResponseFilterTxs(r randyTypes, easy bool) *ResponseFilterTxs {
+	this := &ResponseFilterTxs{}
+	v8 := r.Intn(10)
+	this.Txs = make([][]byte, v8)
+	for i := 0; i < v8; i++ {
+		v9 := r.Intn(100)
+		this.Txs[i] = make([]byte, v9)
+		for j := 0; j < v9; j++ {
+			this.Txs[i][j] = byte(r.Intn(256))
+		}
+	}
+	this.Cursor = uint64(uint64(r.Uint32()))
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 3)
+	}
+	return this
+}
+
+func NewPopulatedResponseUpdate(r randyTypes, easy bool) *ResponseUpdate {
+	this := &ResponseUpdate{}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 1)
+	}
+	return this
+}
+
+func NewPopulatedResponseFlush(r randyTypes, easy bool) *ResponseFlush {
+	this := &ResponseFlush{}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 1)
+	}
+	return this
+}
+
+func NewPopulatedResponseSize(r randyTypes, easy bool) *ResponseSize {
+	this := &ResponseSize{}
+	this.Size_ = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.Size_ *= -1
+	}
+	this.TxsBytes = int64(r.Int63())
+	if r.Intn(2) == 0 {
+		this.TxsBytes *= -1
+	}
+	if !easy && r.Intn(10) != 0 {
+		this.XXX_unrecognized = randUnrecognizedTypes(r, 3)
+	}
+	return this
+}
+
+type randyTypes interface {
+	Float32() float32
+	Float64() float64
+	Int63() int64
+	Int31() int32
+	Uint32() uint32
+	Intn(n int) int
+}
+
+func randUTF8RuneTypes(r randyTypes) rune {
+	ru := r.Intn(62)
+	if ru < 10 {
+		return rune(ru + 48)
+	} else if ru < 36 {
+		return rune(ru + 55)
+	}
+	return rune(ru + 61)
+}
+func randStringTypes(r randyTypes) string {
+	v10 := r.Intn(100)
+	tmps := make([]rune, v10)
+	for i := 0; i < v10; i++ {
+		tmps[i] = randUTF8RuneTypes(r)
+	}
+	return string(tmps)
+}
+func randUnrecognizedTypes(r randyTypes, maxFieldNumber int) (dAtA []byte) {
+	l := r.Intn(5)
+	for i := 0; i < l; i++ {
+		wire := r.Intn(4)
+		if wire == 3 {
+			wire = 5
+		}
+		fieldNumber := maxFieldNumber + r.Intn(100)
+		dAtA = randFieldTypes(dAtA, r, fieldNumber, wire)
+	}
+	return dAtA
+}
+func randFieldTypes(dAtA []byte, r randyTypes, fieldNumber int, wire int) []byte {
+	key := uint32(fieldNumber)<<3 | uint32(wire)
+	switch wire {
+	case 0:
+		dAtA = encodeVarintPopulateTypes(dAtA, uint64(key))
+		v11 := r.Int63()
+		if r.Intn(2) == 0 {
+			v11 *= -1
+		}
+		dAtA = encodeVarintPopulateTypes(dAtA, uint64(v11))
+	case 1:
+		dAtA = encodeVarintPopulateTypes(dAtA, uint64(key))
+		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+	case 2:
+		dAtA = encodeVarintPopulateTypes(dAtA, uint64(key))
+		ll := r.Intn(100)
+		dAtA = encodeVarintPopulateTypes(dAtA, uint64(ll))
+		for j := 0; j < ll; j++ {
+			dAtA = append(dAtA, byte(r.Intn(256)))
+		}
+	default:
+		dAtA = encodeVarintPopulateTypes(dAtA, uint64(key))
+		dAtA = append(dAtA, byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(256)))
+	}
+	return dAtA
+}
+func encodeVarintPopulateTypes(dAtA []byte, v uint64) []byte {
+	for v >= 1<<7 {
+		dAtA = append(dAtA, uint8(uint64(v)&0x7f|0x80))
+		v >>= 7
+	}
+	dAtA = append(dAtA, uint8(v))
+	return dAtA
+}
+func (m *RequestCheckTx) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Tx)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestReapMaxBytesMaxGas) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MaxBytes != 0 {
+		n += 1 + sovTypes(uint64(m.MaxBytes))
+	}
+	if m.MaxGas != 0 {
+		n += 1 + sovTypes(uint64(m.MaxGas))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestReapMaxTxs) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Max != 0 {
+		n += 1 + sovTypes(uint64(m.Max))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestFilterTxs) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Sender)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	if m.FilterPriority {
+		n += 2
+	}
+	if m.MinPriority != 0 {
+		n += 1 + sovTypes(uint64(m.MinPriority))
+	}
+	l = len(m.HashPrefix)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	if m.Cursor != 0 {
+		n += 1 + sovTypes(uint64(m.Cursor))
+	}
+	if m.Max != 0 {
+		n += 1 + sovTypes(uint64(m.Max))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestUpdate) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Height != 0 {
+		n += 1 + sovTypes(uint64(m.Height))
+	}
+	if len(m.Txs) > 0 {
+		for _, b := range m.Txs {
+			l = len(b)
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if len(m.DeliverTxs) > 0 {
+		for _, e := range m.DeliverTxs {
+			l = e.Size()
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestFlush) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RequestSize) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseCheckTx) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.CheckTx != nil {
+		l = m.CheckTx.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseReap) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Txs) > 0 {
+		for _, b := range m.Txs {
+			l = len(b)
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseFilterTxs) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Txs) > 0 {
+		for _, b := range m.Txs {
+			l = len(b)
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.Cursor != 0 {
+		n += 1 + sovTypes(uint64(m.Cursor))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseUpdate) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseFlush) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ResponseSize) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Size_ != 0 {
+		n += 1 + sovTypes(uint64(m.Size_))
+	}
+	if m.TxsBytes != 0 {
+		n += 1 + sovTypes(uint64(m.TxsBytes))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovTypes(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozTypes(x uint64) (n int) {
+	return sovTypes(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *RequestCheckTx) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestCheckTx: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestCheckTx: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Tx", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Tx = append(m.Tx[:0], dAtA[iNdEx:postIndex]...)
+			if m.Tx == nil {
+				m.Tx = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestReapMaxBytesMaxGas) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestReapMaxBytesMaxGas: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestReapMaxBytesMaxGas: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxBytes", wireType)
+			}
+			m.MaxBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxGas", wireType)
+			}
+			m.MaxGas = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxGas |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestReapMaxTxs) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestReapMaxTxs: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestReapMaxTxs: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Max", wireType)
+			}
+			m.Max = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Max |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestFilterTxs) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestFilterTxs: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestFilterTxs: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Sender", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Sender = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FilterPriority", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.FilterPriority = bool(v != 0)
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MinPriority", wireType)
+			}
+			m.MinPriority = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MinPriority |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HashPrefix", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HashPrefix = append(m.HashPrefix[:0], dAtA[iNdEx:postIndex]...)
+			if m.HashPrefix == nil {
+				m.HashPrefix = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cursor", wireType)
+			}
+			m.Cursor = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Cursor |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Max", wireType)
+			}
+			m.Max = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Max |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestUpdate) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestUpdate: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestUpdate: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Txs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Txs = append(m.Txs, make([]byte, postIndex-iNdEx))
+			copy(m.Txs[len(m.Txs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DeliverTxs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DeliverTxs = append(m.DeliverTxs, &types.ResponseDeliverTx{})
+			if err := m.DeliverTxs[len(m.DeliverTxs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestFlush) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestFlush: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestFlush: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RequestSize) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RequestSize: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RequestSize: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseCheckTx) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseCheckTx: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseCheckTx: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CheckTx", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.CheckTx == nil {
+				m.CheckTx = &types.ResponseCheckTx{}
+			}
+			if err := m.CheckTx.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseReap) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseReap: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseReap: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Txs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Txs = append(m.Txs, make([]byte, postIndex-iNdEx))
+			copy(m.Txs[len(m.Txs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseFilterTxs) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseFilterTxs: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseFilterTxs: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Txs", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Txs = append(m.Txs, make([]byte, postIndex-iNdEx))
+			copy(m.Txs[len(m.Txs)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cursor", wireType)
+			}
+			m.Cursor = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Cursor |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseUpdate) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseUpdate: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseUpdate: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseFlush) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseFlush: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseFlush: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ResponseSize) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ResponseSize: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ResponseSize: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Size_", wireType)
+			}
+			m.Size_ = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Size_ |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TxsBytes", wireType)
+			}
+			m.TxsBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TxsBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipTypes(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthTypes
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupTypes
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthTypes
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthTypes        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowTypes          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupTypes = fmt.Errorf("proto: unexpected end of group")
+)
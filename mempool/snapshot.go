@@ -0,0 +1,63 @@
+package mempool
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/tempfile"
+	"github.com/tendermint/tendermint/types"
+)
+
+// snapshot is the content of the mempool snapshot file (see
+// mempool.snapshot_interval).
+type snapshot struct {
+	Time time.Time  `json:"time"`
+	Txs  []types.Tx `json:"txs"`
+}
+
+// SaveSnapshot saves the txs of the mempool to path, in order.
+func (mem *CListMempool) SaveSnapshot(path string) error {
+	s := snapshot{Time: time.Now(), Txs: []types.Tx{}}
+	for e := mem.txs.Front(); e != nil; e = e.Next() {
+		s.Txs = append(s.Txs, e.Value.(*mempoolTx).tx)
+	}
+
+	bz, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(path, bz, 0600)
+}
+
+// LoadSnapshot checks the txs of the snapshot file at path, if any, again, so
+// that the valid ones are added back to the mempool. It returns the number of
+// txs added.
+func (mem *CListMempool) LoadSnapshot(path string) (int, error) {
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	var s snapshot
+	if err := json.Unmarshal(bz, &s); err != nil {
+		return 0, errors.Wrapf(err, "failed to decode %s", path)
+	}
+
+	sizeBefore := mem.Size()
+	for _, tx := range s.Txs {
+		// the txs which aren't valid anymore (eg. committed in the meantime) are
+		// rejected by the app, and the others are gossiped like new ones
+		if err := mem.CheckTx(tx, nil, TxInfo{SenderID: UnknownPeerID}); err != nil {
+			mem.logger.Debug("Could not check tx of the snapshot", "tx", txID(tx), "err", err)
+		}
+	}
+	if err := mem.FlushAppConn(); err != nil {
+		return 0, err
+	}
+	return mem.Size() - sizeBefore, nil
+}
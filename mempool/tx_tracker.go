@@ -0,0 +1,168 @@
+package mempool
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// TxTracker records the lifecycle of the latest txs: received by the mempool,
+// broadcast to a peer, included in a proposal block, and then committed or
+// evicted. It is fed by the CListMempool (WithTxTracker), the mempool Reactor
+// and the consensus State (consensus.StateTxTracker), and publishes a TxStatus
+// event for each change, so that the txs submitted with broadcast_tx_async (or
+// broadcast_tx_sync) can be polled (/tx_status) or subscribed to instead of
+// using broadcast_tx_commit.
+//
+// The statuses of at most size txs are kept, the least recently updated being
+// forgotten first.
+type TxTracker struct {
+	mtx      sync.Mutex
+	size     int
+	statuses map[[sha256.Size]byte]*list.Element
+	list     *list.List // of *types.EventDataTxStatus
+
+	eventBus types.TxStatusPublisher
+	logger   log.Logger
+}
+
+// NewTxTracker returns a new TxTracker keeping the statuses of at most size
+// txs.
+func NewTxTracker(size int) *TxTracker {
+	return &TxTracker{
+		size:     size,
+		statuses: make(map[[sha256.Size]byte]*list.Element, size),
+		list:     list.New(),
+		eventBus: types.NopEventBus{},
+		logger:   log.NewNopLogger(),
+	}
+}
+
+// SetLogger sets the Logger.
+func (t *TxTracker) SetLogger(l log.Logger) {
+	t.logger = l
+}
+
+// SetEventBus sets the event bus the status changes are published to.
+func (t *TxTracker) SetEventBus(eventBus types.TxStatusPublisher) {
+	t.eventBus = eventBus
+}
+
+// Status returns the status of the tx with the given hash, and false if it
+// isn't tracked.
+func (t *TxTracker) Status(hash []byte) (types.EventDataTxStatus, bool) {
+	if len(hash) != sha256.Size {
+		return types.EventDataTxStatus{}, false
+	}
+	var key [sha256.Size]byte
+	copy(key[:], hash)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	e, ok := t.statuses[key]
+	if !ok {
+		return types.EventDataTxStatus{}, false
+	}
+	return *e.Value.(*types.EventDataTxStatus), true
+}
+
+// MarkReceived records that tx was added to the mempool.
+func (t *TxTracker) MarkReceived(tx types.Tx) {
+	t.update(tx, func(status *types.EventDataTxStatus, tracked bool) bool {
+		// a tx evicted from the mempool can be resubmitted
+		if tracked && status.Status != types.TxStatusEvicted {
+			return false
+		}
+		*status = types.EventDataTxStatus{Status: types.TxStatusReceived}
+		return true
+	})
+}
+
+// MarkBroadcast records that tx was sent to a peer.
+func (t *TxTracker) MarkBroadcast(tx types.Tx) {
+	t.update(tx, func(status *types.EventDataTxStatus, tracked bool) bool {
+		if !tracked || status.Status != types.TxStatusReceived {
+			return false
+		}
+		status.Status = types.TxStatusBroadcast
+		return true
+	})
+}
+
+// MarkProposed records that txs were included in a complete proposal block
+// at height. The txs received from other nodes are tracked from then on.
+func (t *TxTracker) MarkProposed(height int64, txs types.Txs) {
+	for _, tx := range txs {
+		t.update(tx, func(status *types.EventDataTxStatus, tracked bool) bool {
+			if tracked && (status.Status == types.TxStatusProposed && status.Height == height ||
+				status.Status == types.TxStatusCommitted) {
+				return false
+			}
+			*status = types.EventDataTxStatus{Status: types.TxStatusProposed, Height: height}
+			return true
+		})
+	}
+}
+
+// MarkCommitted records that tx was committed at height, with the code
+// returned by DeliverTx.
+func (t *TxTracker) MarkCommitted(tx types.Tx, height int64, code uint32) {
+	t.update(tx, func(status *types.EventDataTxStatus, tracked bool) bool {
+		if tracked && status.Status == types.TxStatusCommitted {
+			return false
+		}
+		*status = types.EventDataTxStatus{Status: types.TxStatusCommitted, Height: height, Code: code}
+		return true
+	})
+}
+
+// MarkEvicted records that tx was removed from the mempool without being
+// committed, for the given reason (see types.EventDataTxRemoved).
+func (t *TxTracker) MarkEvicted(tx types.Tx, reason string) {
+	t.update(tx, func(status *types.EventDataTxStatus, tracked bool) bool {
+		if tracked && status.Status == types.TxStatusCommitted {
+			return false
+		}
+		*status = types.EventDataTxStatus{Status: types.TxStatusEvicted, Reason: reason}
+		return true
+	})
+}
+
+// update applies fn to the status of tx, publishing it if fn returns true.
+func (t *TxTracker) update(tx types.Tx, fn func(status *types.EventDataTxStatus, tracked bool) bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	key := txKey(tx)
+	e, tracked := t.statuses[key]
+	status := &types.EventDataTxStatus{}
+	if tracked {
+		status = e.Value.(*types.EventDataTxStatus)
+	}
+	if !fn(status, tracked) {
+		return
+	}
+	status.Hash = tx.Hash()
+
+	if tracked {
+		t.list.MoveToBack(e)
+	} else {
+		if t.list.Len() >= t.size {
+			popped := t.list.Front()
+			var poppedKey [sha256.Size]byte
+			copy(poppedKey[:], popped.Value.(*types.EventDataTxStatus).Hash)
+			delete(t.statuses, poppedKey)
+			t.list.Remove(popped)
+		}
+		t.statuses[key] = t.list.PushBack(status)
+	}
+
+	// published while holding the lock, so that the events are in order
+	if err := t.eventBus.PublishEventTxStatus(*status); err != nil {
+		t.logger.Error("Error publishing the status of a tx", "tx", txID(tx), "err", err)
+	}
+}
@@ -0,0 +1,106 @@
+package mempool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestTxTrackerLifecycle(t *testing.T) {
+	tracker := NewTxTracker(10)
+	tx := types.Tx("tx")
+
+	status := func() types.EventDataTxStatus {
+		status, ok := tracker.Status(tx.Hash())
+		require.True(t, ok)
+		return status
+	}
+
+	_, ok := tracker.Status(tx.Hash())
+	assert.False(t, ok)
+
+	// not received, so not tracked
+	tracker.MarkBroadcast(tx)
+	_, ok = tracker.Status(tx.Hash())
+	assert.False(t, ok)
+
+	tracker.MarkReceived(tx)
+	assert.Equal(t, types.TxStatusReceived, status().Status)
+	tracker.MarkBroadcast(tx)
+	assert.Equal(t, types.TxStatusBroadcast, status().Status)
+	tracker.MarkProposed(2, types.Txs{tx})
+	assert.Equal(t, types.EventDataTxStatus{Hash: tx.Hash(), Status: types.TxStatusProposed, Height: 2}, status())
+
+	// doesn't go back
+	tracker.MarkBroadcast(tx)
+	assert.Equal(t, types.TxStatusProposed, status().Status)
+
+	tracker.MarkCommitted(tx, 2, 1)
+	assert.Equal(t, types.EventDataTxStatus{Hash: tx.Hash(), Status: types.TxStatusCommitted, Height: 2, Code: 1},
+		status())
+
+	// committed is final
+	tracker.MarkEvicted(tx, types.TxRemovedExpired)
+	tracker.MarkReceived(tx)
+	assert.Equal(t, types.TxStatusCommitted, status().Status)
+
+	// an evicted tx can be received again
+	tx2 := types.Tx("tx2")
+	tracker.MarkReceived(tx2)
+	tracker.MarkEvicted(tx2, types.TxRemovedExpired)
+	status2, _ := tracker.Status(tx2.Hash())
+	assert.Equal(t, types.EventDataTxStatus{Hash: tx2.Hash(), Status: types.TxStatusEvicted,
+		Reason: types.TxRemovedExpired}, status2)
+	tracker.MarkReceived(tx2)
+	status2, _ = tracker.Status(tx2.Hash())
+	assert.Equal(t, types.TxStatusReceived, status2.Status)
+}
+
+func TestTxTrackerSize(t *testing.T) {
+	tracker := NewTxTracker(2)
+	txs := types.Txs{types.Tx("tx1"), types.Tx("tx2"), types.Tx("tx3")}
+	for _, tx := range txs {
+		tracker.MarkReceived(tx)
+	}
+	// updating tx2 makes tx3 the least recently updated
+	tracker.MarkBroadcast(txs[1])
+	tracker.MarkReceived(types.Tx("tx4"))
+
+	_, ok := tracker.Status(txs[0].Hash())
+	assert.False(t, ok)
+	_, ok = tracker.Status(txs[1].Hash())
+	assert.True(t, ok)
+	_, ok = tracker.Status(txs[2].Hash())
+	assert.False(t, ok)
+}
+
+func TestTxTrackerEvents(t *testing.T) {
+	eventBus := types.NewEventBus()
+	require.NoError(t, eventBus.Start())
+	defer eventBus.Stop()
+
+	tracker := NewTxTracker(10)
+	tracker.SetEventBus(eventBus)
+	tx := types.Tx("tx")
+
+	sub, err := eventBus.Subscribe(context.Background(), "test", types.EventQueryTxStatus, 10)
+	require.NoError(t, err)
+
+	tracker.MarkReceived(tx)
+	tracker.MarkReceived(tx) // no change, not published
+	tracker.MarkCommitted(tx, 1, 0)
+
+	for _, status := range []string{types.TxStatusReceived, types.TxStatusCommitted} {
+		select {
+		case msg := <-sub.Out():
+			assert.Equal(t, status, msg.Data().(types.EventDataTxStatus).Status)
+		case <-time.After(time.Second):
+			t.Fatalf("expected the %s status to be published", status)
+		}
+	}
+}
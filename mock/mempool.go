@@ -20,6 +20,9 @@ func (Mempool) CheckTx(_ types.Tx, _ func(*abci.Response), _ mempl.TxInfo) error
 }
 func (Mempool) ReapMaxBytesMaxGas(_, _ int64) types.Txs { return types.Txs{} }
 func (Mempool) ReapMaxTxs(n int) types.Txs              { return types.Txs{} }
+func (Mempool) FilterTxs(_ mempl.TxFilter, cursor uint64, _ int) (types.Txs, uint64) {
+	return types.Txs{}, cursor
+}
 func (Mempool) Update(
 	_ int64,
 	_ types.Txs,
@@ -40,3 +43,6 @@ func (Mempool) TxsWaitChan() <-chan struct{} { return nil }
 
 func (Mempool) InitWAL()  {}
 func (Mempool) CloseWAL() {}
+
+func (Mempool) SaveSnapshot(path string) error        { return nil }
+func (Mempool) LoadSnapshot(path string) (int, error) { return 0, nil }
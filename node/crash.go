@@ -0,0 +1,143 @@
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	cfg "github.com/tendermint/tendermint/config"
+	cs "github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// crashDumpWALMessages is the number of the latest WAL messages included in a
+// crash dump.
+const crashDumpWALMessages = 1000
+
+// secretConfigFields are the config fields redacted from crash dumps.
+var secretConfigFields = map[string]bool{
+	"prof_auth_token":      true,
+	"rpc.admin_auth_token": true,
+}
+
+// crashConfigDigest identifies the config the node was running with.
+type crashConfigDigest struct {
+	// SHA256 of config.toml, if it could be read
+	FileSHA256 string `json:"file_sha256,omitempty"`
+	// values of all fields, including those overridden by flags and
+	// environment variables, with secrets redacted
+	Fields map[string]string `json:"fields"`
+}
+
+// newCrashDumpHandler returns a consensus panic handler writing a crash dump
+// (see writeCrashDump).
+func newCrashDumpHandler(config *cfg.Config, logger log.Logger) cs.PanicHandler {
+	return func(consensusState *cs.State, r interface{}, stack []byte) {
+		dir, err := writeCrashDump(config, consensusState, r, stack)
+		if err != nil {
+			logger.Error("Failed to write crash dump", "dir", dir, "err", err)
+			return
+		}
+		logger.Error("Wrote crash dump", "dir", dir)
+	}
+}
+
+// writeCrashDump writes the following files to a new directory in the crash
+// dump directory, and returns the new directory:
+//
+//  panic.txt            - the recovered value and the stack of the panic
+//  consensus_state.json - the consensus round state
+//  wal_tail.json        - the latest messages of the consensus WAL
+//  goroutines.txt       - the stacks of all goroutines
+//  config.json          - the config digest
+//
+// All files are written even if some of them fail.
+func writeCrashDump(config *cfg.Config, consensusState *cs.State, r interface{}, stack []byte) (string, error) {
+	dir := filepath.Join(config.CrashDumpDir(), "crash-"+time.Now().UTC().Format("20060102T150405.000Z"))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return dir, err
+	}
+
+	var errs []string
+	write := func(name string, fn func(w io.Writer) error) {
+		if err := writeCrashDumpFile(filepath.Join(dir, name), fn); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	write("panic.txt", func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%v\n\n%s", r, stack)
+		return err
+	})
+	write("consensus_state.json", func(w io.Writer) error {
+		bz, err := consensusState.GetRoundStateJSON()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bz)
+		return err
+	})
+	write("wal_tail.json", func(w io.Writer) error {
+		return cs.WriteWALTail(w, config.Consensus.WalFile(), crashDumpWALMessages)
+	})
+	write("goroutines.txt", func(w io.Writer) error {
+		return pprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	write("config.json", func(w io.Writer) error {
+		bz, err := json.MarshalIndent(newCrashConfigDigest(config), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bz)
+		return err
+	})
+
+	if len(errs) > 0 {
+		return dir, errors.New(strings.Join(errs, "; "))
+	}
+	return dir, nil
+}
+
+// writeCrashDumpFile creates the file at path and writes it with fn. A panic
+// in fn, e.g. because the consensus state is inconsistent, is returned as an
+// error.
+func writeCrashDumpFile(path string, fn func(w io.Writer) error) (err error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panicked: %v", r)
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return fn(f)
+}
+
+func newCrashConfigDigest(config *cfg.Config) crashConfigDigest {
+	digest := crashConfigDigest{Fields: make(map[string]string)}
+	if bz, err := ioutil.ReadFile(filepath.Join(config.RootDir, "config", "config.toml")); err == nil {
+		sum := sha256.Sum256(bz)
+		digest.FileSHA256 = hex.EncodeToString(sum[:])
+	}
+	for _, f := range cfg.Fields(config) {
+		value := fmt.Sprint(f.Value)
+		if secretConfigFields[f.Key] && value != "" {
+			value = "<redacted>"
+		}
+		digest.Fields[f.Key] = value
+	}
+	return digest
+}
@@ -0,0 +1,22 @@
+package node
+
+import (
+	"time"
+)
+
+// mempoolSnapshotRoutine saves a mempool snapshot every snapshot interval
+// until the node is stopped.
+func (n *Node) mempoolSnapshotRoutine() {
+	ticker := time.NewTicker(n.config.Mempool.SnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := n.mempool.SaveSnapshot(n.config.Mempool.SnapshotFile()); err != nil {
+				n.Logger.Error("Failed to save mempool snapshot", "err", err)
+			}
+		case <-n.Quit():
+			return
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package node
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	bcv0 "github.com/tendermint/tendermint/blockchain/v0"
+	cfg "github.com/tendermint/tendermint/config"
+	cs "github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/p2p"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+// ChainProvider creates the node of a single chain hosted by a MultiNode. The
+// given metrics provider must be used for the node's metrics, so that the
+// metrics of all chains can be registered side by side.
+type ChainProvider func(config *cfg.Config, metricsProvider MetricsProvider, logger log.Logger) (*Node, error)
+
+// DefaultNewChainNode is the ChainProvider counterpart of DefaultNewNode.
+func DefaultNewChainNode(config *cfg.Config, metricsProvider MetricsProvider, logger log.Logger) (*Node, error) {
+	return defaultNewNode(config, metricsProvider, logger)
+}
+
+// MultiNode runs several independent chains in one process. Each chain has its
+// own config, root directory, ports and node, while the logger and the
+// Prometheus registry are shared: log lines carry a "home" key with the
+// chain's root directory and metrics a "chain_id" label.
+type MultiNode struct {
+	service.BaseService
+
+	nodes []*Node
+}
+
+// NewMultiNode creates a node for each of the given configs using newNode
+// (DefaultNewChainNode if nil). The configs must not share root directories
+// or listen addresses.
+func NewMultiNode(configs []*cfg.Config, newNode ChainProvider, logger log.Logger) (*MultiNode, error) {
+	if len(configs) == 0 {
+		return nil, errors.New("no chains given")
+	}
+	if err := validateChainConfigs(configs); err != nil {
+		return nil, err
+	}
+	if newNode == nil {
+		newNode = DefaultNewChainNode
+	}
+
+	mn := &MultiNode{}
+	chainIDs := make(map[string]string, len(configs))
+	for _, config := range configs {
+		n, err := newNode(config, multiChainMetricsProvider(config.Instrumentation),
+			logger.With("home", config.RootDir))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create node in %s", config.RootDir)
+		}
+		chainID := n.GenesisDoc().ChainID
+		if other, ok := chainIDs[chainID]; ok {
+			return nil, errors.Errorf("%s and %s are both running chain %s", other, config.RootDir, chainID)
+		}
+		chainIDs[chainID] = config.RootDir
+		mn.nodes = append(mn.nodes, n)
+	}
+	mn.BaseService = *service.NewBaseService(logger, "MultiNode", mn)
+	return mn, nil
+}
+
+// validateChainConfigs checks that no two configs share a root directory or
+// a listen address.
+func validateChainConfigs(configs []*cfg.Config) error {
+	rootDirs := make(map[string]bool, len(configs))
+	addrs := make(map[string]string)
+	for _, config := range configs {
+		if err := config.ValidateBasic(); err != nil {
+			return errors.Wrapf(err, "invalid config in %s", config.RootDir)
+		}
+		if rootDirs[config.RootDir] {
+			return errors.Errorf("root directory %s is used more than once", config.RootDir)
+		}
+		rootDirs[config.RootDir] = true
+
+		listenAddrs := append([]string{
+			config.P2P.ListenAddress,
+			config.RPC.GRPCListenAddress,
+			config.ProfListenAddress,
+		}, splitAndTrimEmpty(config.RPC.ListenAddress, ",", " ")...)
+		if config.Instrumentation.Prometheus {
+			listenAddrs = append(listenAddrs, config.Instrumentation.PrometheusListenAddr)
+		}
+		for _, addr := range listenAddrs {
+			if addr == "" {
+				continue
+			}
+			if other, ok := addrs[addr]; ok {
+				return errors.Errorf("%s and %s both listen on %s", other, config.RootDir, addr)
+			}
+			addrs[addr] = config.RootDir
+		}
+	}
+	return nil
+}
+
+// multiChainMetricsProvider is like DefaultMetricsProvider, but sets
+// "chain_id" as a constant label instead of a variable one, by registering the
+// metrics with a wrapper of the default Prometheus registerer. This allows the
+// metrics of several chains to be registered side by side.
+func multiChainMetricsProvider(config *cfg.InstrumentationConfig) MetricsProvider {
+	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *bcv0.Metrics) {
+		if !config.Prometheus {
+			return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics(), bcv0.NopMetrics()
+		}
+
+		registerer := prometheus.WrapRegistererWith(prometheus.Labels{"chain_id": chainID},
+			prometheus.DefaultRegisterer)
+		return cs.PrometheusMetricsWithRegisterer(registerer, config.Namespace),
+			p2p.PrometheusMetricsWithRegisterer(registerer, config.Namespace),
+			mempl.PrometheusMetricsWithRegisterer(registerer, config.Namespace),
+			sm.PrometheusMetricsWithRegisterer(registerer, config.Namespace),
+			bcv0.PrometheusMetricsWithRegisterer(registerer, config.Namespace)
+	}
+}
+
+// OnStart starts the nodes in order. If a node fails to start, the nodes
+// started before it are stopped.
+func (mn *MultiNode) OnStart() error {
+	for i, n := range mn.nodes {
+		if err := n.Start(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if err := mn.nodes[j].Stop(); err != nil {
+					mn.Logger.Error("Error stopping node", "chain", mn.nodes[j].GenesisDoc().ChainID, "err", err)
+				}
+			}
+			return errors.Wrapf(err, "failed to start chain %s", n.GenesisDoc().ChainID)
+		}
+	}
+	return nil
+}
+
+// OnStop stops the nodes in reverse order.
+func (mn *MultiNode) OnStop() {
+	for i := len(mn.nodes) - 1; i >= 0; i-- {
+		if !mn.nodes[i].IsRunning() {
+			continue
+		}
+		if err := mn.nodes[i].Stop(); err != nil {
+			mn.Logger.Error("Error stopping node", "chain", mn.nodes[i].GenesisDoc().ChainID, "err", err)
+		}
+	}
+}
+
+// Shutdown stops all nodes concurrently, each within its own
+// shutdown_grace_period (see Node.Shutdown).
+func (mn *MultiNode) Shutdown() error {
+	if !mn.IsRunning() {
+		return nil
+	}
+	errs := make([]error, len(mn.nodes))
+	var wg sync.WaitGroup
+	for i, n := range mn.nodes {
+		wg.Add(1)
+		go func(i int, n *Node) {
+			defer wg.Done()
+			errs[i] = n.Shutdown()
+		}(i, n)
+	}
+	wg.Wait()
+	// The nodes are stopped or stopping, so this only marks mn as stopped.
+	mn.Stop() // nolint: errcheck
+
+	for i, err := range errs {
+		if err != nil {
+			return errors.Wrapf(err, "chain %s", mn.nodes[i].GenesisDoc().ChainID)
+		}
+	}
+	return nil
+}
+
+// Nodes returns the nodes of all chains, in the order of the configs they
+// were created from.
+func (mn *MultiNode) Nodes() []*Node {
+	return mn.nodes
+}
+
+// Node returns the node running the chain with the given ID, or nil.
+func (mn *MultiNode) Node(chainID string) *Node {
+	for _, n := range mn.nodes {
+		if n.GenesisDoc().ChainID == chainID {
+			return n
+		}
+	}
+	return nil
+}
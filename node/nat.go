@@ -0,0 +1,79 @@
+package node
+
+import (
+	"net"
+	"strconv"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/nat"
+	"github.com/tendermint/tendermint/p2p/pex"
+)
+
+// createNATMapper returns a nat.Mapper mapping the port of the node on the
+// gateway, and calling onAddress with the external address of the node, or nil
+// if UPnP, NAT-PMP and STUN are all disabled.
+func createNATMapper(
+	config *cfg.P2PConfig,
+	port int,
+	onAddress func(ip net.IP, port int),
+	logger log.Logger,
+) *nat.Mapper {
+	stunServers := splitAndTrimEmpty(config.STUNServers, ",", " ")
+	if !config.UPNP && !config.NATPMP && len(stunServers) == 0 {
+		return nil
+	}
+
+	protocols := []string{"TCP"}
+	if config.Transport == cfg.P2PTransportQUIC {
+		protocols = append(protocols, "UDP")
+	}
+	mapper := nat.NewMapper(nat.Config{
+		UPnP:        config.UPNP,
+		NATPMP:      config.NATPMP,
+		STUNServers: stunServers,
+		Port:        port,
+		Protocols:   protocols,
+	}, onAddress)
+	mapper.SetLogger(logger.With("module", "nat"))
+	return mapper
+}
+
+// publishExternalAddress adds the external address of the node to the address
+// book, as one of our own, and, unless the external address is set in the
+// config, advertises it to the peers connecting afterwards in the node info.
+func publishExternalAddress(
+	config *cfg.P2PConfig,
+	nodeKey *p2p.NodeKey,
+	sw *p2p.Switch,
+	transport nodeTransport,
+	addrBook pex.AddrBook,
+	ip net.IP,
+	port int,
+) {
+	addrBook.AddOurAddress(&p2p.NetAddress{ID: nodeKey.ID(), IP: ip, Port: uint16(port)})
+
+	if config.ExternalAddress != "" {
+		return
+	}
+	nodeInfo, ok := sw.NodeInfo().(p2p.DefaultNodeInfo)
+	if !ok {
+		return
+	}
+	nodeInfo.ListenAddr = net.JoinHostPort(ip.String(), strconv.Itoa(port))
+	sw.SetNodeInfo(nodeInfo)
+	transport.SetNodeInfo(nodeInfo)
+}
+
+// setExternalAddress publishes the external address detected by the NAT
+// mapper.
+func (n *Node) setExternalAddress(ip net.IP, port int) {
+	publishExternalAddress(n.config.P2P, n.nodeKey, n.sw, n.transport, n.addrBook, ip, port)
+}
+
+// setExternalAddress publishes the external address detected by the NAT
+// mapper.
+func (n *SeedNode) setExternalAddress(ip net.IP, port int) {
+	publishExternalAddress(n.config.P2P, n.nodeKey, n.sw, n.transport, n.addrBook, ip, port)
+}
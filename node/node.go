@@ -3,12 +3,14 @@ package node
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
-	_ "net/http/pprof" // nolint: gosec // securely exposed on separate, optional port
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -28,10 +30,16 @@ import (
 	"github.com/tendermint/tendermint/libs/log"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/libs/telemetry"
+	lite "github.com/tendermint/tendermint/lite2"
 	mempl "github.com/tendermint/tendermint/mempool"
+	remotemempl "github.com/tendermint/tendermint/mempool/remote"
 	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/conn"
+	"github.com/tendermint/tendermint/p2p/nat"
 	"github.com/tendermint/tendermint/p2p/pex"
 	"github.com/tendermint/tendermint/privval"
+	privvalgrpc "github.com/tendermint/tendermint/privval/grpc"
 	"github.com/tendermint/tendermint/proxy"
 	rpccore "github.com/tendermint/tendermint/rpc/core"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -41,6 +49,8 @@ import (
 	"github.com/tendermint/tendermint/state/txindex"
 	"github.com/tendermint/tendermint/state/txindex/kv"
 	"github.com/tendermint/tendermint/state/txindex/null"
+	"github.com/tendermint/tendermint/state/txindex/psql"
+	"github.com/tendermint/tendermint/statesync"
 	"github.com/tendermint/tendermint/store"
 	"github.com/tendermint/tendermint/types"
 	tmtime "github.com/tendermint/tendermint/types/time"
@@ -86,12 +96,29 @@ type Provider func(*cfg.Config, log.Logger) (*Node, error)
 // PrivValidator, ClientCreator, GenesisDoc, and DBProvider.
 // It implements NodeProvider.
 func DefaultNewNode(config *cfg.Config, logger log.Logger) (*Node, error) {
+	return defaultNewNode(config, DefaultMetricsProvider(config.Instrumentation), logger)
+}
+
+func defaultNewNode(config *cfg.Config, metricsProvider MetricsProvider, logger log.Logger) (*Node, error) {
 	// Generate node PrivKey
 	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
 	if err != nil {
 		return nil, err
 	}
 
+	// Only validators need a private validator key.
+	if config.Mode != cfg.ModeValidator {
+		return NewNode(config,
+			nil,
+			nodeKey,
+			proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+			DefaultGenesisDocProviderFunc(config),
+			DefaultDBProvider,
+			metricsProvider,
+			logger,
+		)
+	}
+
 	// Convert old PrivValidator if it exists.
 	oldPrivVal := config.OldPrivValidatorFile()
 	newPrivValKey := config.PrivValidatorKeyFile()
@@ -110,30 +137,33 @@ func DefaultNewNode(config *cfg.Config, logger log.Logger) (*Node, error) {
 	}
 
 	return NewNode(config,
-		privval.LoadOrGenFilePV(newPrivValKey, newPrivValState),
+		privval.LoadOrGenFilePVWithSecret(newPrivValKey, newPrivValState,
+			privval.NewKeySecretFunc(config.PrivValidatorKeyPassphraseFile(), config.PrivValidatorKeyKMSCommand)),
 		nodeKey,
 		proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
 		DefaultGenesisDocProviderFunc(config),
 		DefaultDBProvider,
-		DefaultMetricsProvider(config.Instrumentation),
+		metricsProvider,
 		logger,
 	)
 }
 
-// MetricsProvider returns a consensus, p2p and mempool Metrics.
-type MetricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics)
+// MetricsProvider returns a consensus, p2p, mempool, state and fast sync
+// Metrics.
+type MetricsProvider func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *bcv0.Metrics)
 
 // DefaultMetricsProvider returns Metrics build using Prometheus client library
 // if Prometheus is enabled. Otherwise, it returns no-op Metrics.
 func DefaultMetricsProvider(config *cfg.InstrumentationConfig) MetricsProvider {
-	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics) {
+	return func(chainID string) (*cs.Metrics, *p2p.Metrics, *mempl.Metrics, *sm.Metrics, *bcv0.Metrics) {
 		if config.Prometheus {
 			return cs.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				p2p.PrometheusMetrics(config.Namespace, "chain_id", chainID),
 				mempl.PrometheusMetrics(config.Namespace, "chain_id", chainID),
-				sm.PrometheusMetrics(config.Namespace, "chain_id", chainID)
+				sm.PrometheusMetrics(config.Namespace, "chain_id", chainID),
+				bcv0.PrometheusMetrics(config.Namespace, "chain_id", chainID)
 		}
-		return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics()
+		return cs.NopMetrics(), p2p.NopMetrics(), mempl.NopMetrics(), sm.NopMetrics(), bcv0.NopMetrics()
 	}
 }
 
@@ -148,6 +178,7 @@ type Option func(*Node)
 //
 //  - MEMPOOL
 //  - BLOCKCHAIN
+//  - STATESYNC
 //  - CONSENSUS
 //  - EVIDENCE
 //  - PEX
@@ -160,10 +191,37 @@ func CustomReactors(reactors map[string]p2p.Reactor) Option {
 				n.sw.RemoveReactor(name, existingReactor)
 			}
 			n.sw.AddReactor(name, reactor)
+			n.advertiseChannels(reactor)
 		}
 	}
 }
 
+// CustomServices allows you to add services (e.g. background workers) whose
+// lifecycle is managed by the node. They are started in the given order after
+// the node's Switch, so they can rely on the reactors running, and stopped in
+// reverse order before the Switch.
+func CustomServices(services ...service.Service) Option {
+	return func(n *Node) {
+		n.customServices = append(n.customServices, services...)
+	}
+}
+
+// advertiseChannels adds the channels of reactor to the node's info, so that
+// peers send messages on them.
+func (n *Node) advertiseChannels(reactor p2p.Reactor) {
+	nodeInfo, ok := n.sw.NodeInfo().(p2p.DefaultNodeInfo)
+	if !ok {
+		return
+	}
+	for _, chDesc := range reactor.GetChannels() {
+		if !bytes.Contains(nodeInfo.Channels, []byte{chDesc.ID}) {
+			nodeInfo.Channels = append(nodeInfo.Channels, chDesc.ID)
+		}
+	}
+	n.sw.SetNodeInfo(nodeInfo)
+	n.transport.SetNodeInfo(nodeInfo)
+}
+
 //------------------------------------------------------------------------------
 
 // Node is the highest level interface to a full Tendermint node.
@@ -177,29 +235,50 @@ type Node struct {
 	privValidator types.PrivValidator // local node's validator key
 
 	// network
-	transport   *p2p.MultiplexTransport
+	transport   nodeTransport
 	sw          *p2p.Switch  // p2p connections
 	addrBook    pex.AddrBook // known peers
-	nodeInfo    p2p.NodeInfo
 	nodeKey     *p2p.NodeKey // our node privkey
 	isListening bool
+	natMapper   *nat.Mapper // nil unless UPnP, NAT-PMP or STUN is enabled
 
 	// services
 	eventBus         *types.EventBus // pub/sub for services
 	stateDB          dbm.DB
-	blockStore       *store.BlockStore // store the blockchain to disk
-	bcReactor        p2p.Reactor       // for fast-syncing
-	mempoolReactor   *mempl.Reactor    // for gossipping transactions
+	blockStore       *store.BlockStore  // store the blockchain to disk
+	bcReactor        p2p.Reactor        // for fast-syncing
+	stateSyncReactor *statesync.Reactor // for restoring the app from a snapshot
+	stateSync        bool               // whether the node state syncs on start
+	fastSync         bool               // whether the node fast syncs, after state syncing
+	mempoolReactor   *mempl.Reactor     // for gossipping transactions
 	mempool          mempl.Mempool
+	txTracker        *mempl.TxTracker
 	consensusState   *cs.State      // latest consensus state
 	consensusReactor *cs.Reactor    // for participating in the consensus
 	pexReactor       *pex.Reactor   // for exchanging peer addresses
 	evidencePool     *evidence.Pool // tracking evidence
 	proxyApp         proxy.AppConns // connection to the application
 	rpcListeners     []net.Listener // rpc servers
+	rpcEnvOnce       sync.Once
+	rpcEnv           *rpccore.Environment // serving the rpc calls
+	rpcLimiter       *rateLimiter         // limiting the rate of the rpc requests
 	txIndexer        txindex.TxIndexer
+	blockIndexer     txindex.BlockIndexer
 	indexerService   *txindex.IndexerService
 	prometheusSrv    *http.Server
+	telemetry        *telemetry.Provider // nil unless an OTLP endpoint is set
+	customServices   []service.Service
+	services         *service.Group // started by OnStart
+
+	startedUp uint32       // atomic; set once OnStart has completed
+	probeSrv  *probeServer // nil unless rpc.probe_laddr is set
+
+	// config reloading
+	reloadMtx        sync.Mutex
+	configLoader     func() (*cfg.Config, error)
+	logLevelReloader func(logLevel string) error
+
+	startup *startupRecorder
 }
 
 func initDBs(config *cfg.Config, dbProvider DBProvider) (blockStore *store.BlockStore, stateDB dbm.DB, err error) {
@@ -236,34 +315,65 @@ func createAndStartEventBus(logger log.Logger) (*types.EventBus, error) {
 	return eventBus, nil
 }
 
-func createAndStartIndexerService(config *cfg.Config, dbProvider DBProvider,
-	eventBus *types.EventBus, logger log.Logger) (*txindex.IndexerService, txindex.TxIndexer, error) {
+func createAndStartIndexerService(config *cfg.Config, dbProvider DBProvider, chainID string,
+	eventBus *types.EventBus, logger log.Logger) (*txindex.IndexerService, txindex.TxIndexer, txindex.BlockIndexer, error) {
 
-	var txIndexer txindex.TxIndexer
-	switch config.TxIndex.Indexer {
-	case "kv":
-		store, err := dbProvider(&DBContext{"tx_index", config})
-		if err != nil {
-			return nil, nil, err
-		}
-		switch {
-		case config.TxIndex.IndexKeys != "":
-			txIndexer = kv.NewTxIndex(store, kv.IndexEvents(splitAndTrimEmpty(config.TxIndex.IndexKeys, ",", " ")))
-		case config.TxIndex.IndexAllKeys:
-			txIndexer = kv.NewTxIndex(store, kv.IndexAllEvents())
-		default:
-			txIndexer = kv.NewTxIndex(store)
+	indexers := config.TxIndex.Indexers()
+
+	var (
+		sinks      []txindex.TxIndexer
+		blockSinks []txindex.BlockIndexer
+	)
+	for _, indexer := range indexers {
+		switch indexer {
+		case "kv":
+			store, err := dbProvider(&DBContext{"tx_index", config})
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			switch {
+			case config.Mode == cfg.ModeArchive:
+				sinks = append(sinks, kv.NewTxIndex(store, kv.IndexAllEvents()))
+			case config.TxIndex.IndexKeys != "":
+				sinks = append(sinks, kv.NewTxIndex(store, kv.IndexEvents(splitAndTrimEmpty(config.TxIndex.IndexKeys, ",", " "))))
+			case config.TxIndex.IndexAllKeys:
+				sinks = append(sinks, kv.NewTxIndex(store, kv.IndexAllEvents()))
+			default:
+				sinks = append(sinks, kv.NewTxIndex(store))
+			}
+			blockSinks = append(blockSinks, kv.NewBlockIndex(store))
+		case "psql":
+			txIndexer, err := psql.NewTxIndex(config.TxIndex.PsqlConn, chainID)
+			if err != nil {
+				return nil, nil, nil, errors.Wrap(err, "could not create the psql indexer")
+			}
+			sinks = append(sinks, txIndexer)
+			blockSinks = append(blockSinks, txIndexer.BlockIndex())
 		}
-	default:
+	}
+
+	var (
+		txIndexer    txindex.TxIndexer
+		blockIndexer txindex.BlockIndexer
+	)
+	switch len(sinks) {
+	case 0:
 		txIndexer = &null.TxIndex{}
+		blockIndexer = &null.BlockIndex{}
+	case 1:
+		txIndexer = sinks[0]
+		blockIndexer = blockSinks[0]
+	default:
+		txIndexer = txindex.NewMultiTxIndex(sinks...)
+		blockIndexer = txindex.NewMultiBlockIndex(blockSinks...)
 	}
 
-	indexerService := txindex.NewIndexerService(txIndexer, eventBus)
+	indexerService := txindex.NewIndexerService(txIndexer, blockIndexer, eventBus)
 	indexerService.SetLogger(logger.With("module", "txindex"))
 	if err := indexerService.Start(); err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	return indexerService, txIndexer, nil
+	return indexerService, txIndexer, blockIndexer, nil
 }
 
 func doHandshake(
@@ -273,18 +383,18 @@ func doHandshake(
 	genDoc *types.GenesisDoc,
 	eventBus types.BlockEventPublisher,
 	proxyApp proxy.AppConns,
-	consensusLogger log.Logger) error {
+	consensusLogger log.Logger) (*cs.Handshaker, error) {
 
 	handshaker := cs.NewHandshaker(stateDB, state, blockStore, genDoc)
 	handshaker.SetLogger(consensusLogger)
 	handshaker.SetEventBus(eventBus)
 	if err := handshaker.Handshake(proxyApp); err != nil {
-		return fmt.Errorf("error during handshake: %v", err)
+		return nil, fmt.Errorf("error during handshake: %v", err)
 	}
-	return nil
+	return handshaker, nil
 }
 
-func logNodeStartupInfo(state sm.State, pubKey crypto.PubKey, logger, consensusLogger log.Logger) {
+func logNodeStartupInfo(state sm.State, pubKey crypto.PubKey, mode string, logger, consensusLogger log.Logger) {
 	// Log the version info.
 	logger.Info("Version info",
 		"software", version.TMCoreSemVer,
@@ -300,6 +410,18 @@ func logNodeStartupInfo(state sm.State, pubKey crypto.PubKey, logger, consensusL
 		)
 	}
 
+	if pubKey == nil {
+		consensusLogger.Info("This node is not a validator", "mode", mode)
+		return
+	}
+
+	if keyType, err := types.ABCIPubKeyType(pubKey); err != nil ||
+		!state.ConsensusParams.Validator.IsValidPubkeyType(keyType) {
+		consensusLogger.Error("The type of the validator key isn't allowed by the consensus params,"+
+			" this node can't become a validator",
+			"pubKey", pubKey, "pubKeyTypes", state.ConsensusParams.Validator.PubKeyTypes)
+	}
+
 	addr := pubKey.Address()
 	// Log whether this node is a validator or an observer
 	if state.Validators.HasAddress(addr) {
@@ -310,32 +432,67 @@ func logNodeStartupInfo(state sm.State, pubKey crypto.PubKey, logger, consensusL
 }
 
 func onlyValidatorIsUs(state sm.State, privVal types.PrivValidator) bool {
-	if state.Validators.Size() > 1 {
+	if privVal == nil || state.Validators.Size() > 1 {
 		return false
 	}
 	addr, _ := state.Validators.GetByIndex(0)
 	return bytes.Equal(privVal.GetPubKey().Address(), addr)
 }
 
+// createMempoolAndMempoolReactor creates the mempool of mempool.type. The
+// reactor is nil unless the mempool is a CListMempool: the "nop" mempool has
+// no txs to gossip, and the "remote" mempool gossips them itself.
 func createMempoolAndMempoolReactor(config *cfg.Config, proxyApp proxy.AppConns,
-	state sm.State, memplMetrics *mempl.Metrics, logger log.Logger) (*mempl.Reactor, *mempl.CListMempool) {
+	state sm.State, eventBus *types.EventBus, txTracker *mempl.TxTracker, memplMetrics *mempl.Metrics,
+	logger log.Logger) (*mempl.Reactor, mempl.Mempool, error) {
+
+	mempoolLogger := logger.With("module", "mempool")
+	switch config.Mempool.Type {
+	case cfg.MempoolTypeNop:
+		return nil, mempl.NopMempool{}, nil
+	case cfg.MempoolTypeRemote:
+		mempool, err := remotemempl.NewMempool(config.Mempool.RemoteAddr)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not connect to the remote mempool")
+		}
+		mempool.SetLogger(mempoolLogger)
+		if config.Consensus.WaitForTxs() {
+			mempool.EnableTxsAvailable()
+		}
+		return nil, mempool, nil
+	}
 
 	mempool := mempl.NewCListMempool(
 		config.Mempool,
 		proxyApp.Mempool(),
 		state.LastBlockHeight,
 		mempl.WithMetrics(memplMetrics),
+		mempl.WithEventBus(eventBus),
+		mempl.WithTxTracker(txTracker),
 		mempl.WithPreCheck(sm.TxPreCheck(state)),
 		mempl.WithPostCheck(sm.TxPostCheck(state)),
 	)
-	mempoolLogger := logger.With("module", "mempool")
 	mempoolReactor := mempl.NewReactor(config.Mempool, mempool)
 	mempoolReactor.SetLogger(mempoolLogger)
 
 	if config.Consensus.WaitForTxs() {
 		mempool.EnableTxsAvailable()
 	}
-	return mempoolReactor, mempool
+	return mempoolReactor, mempool, nil
+}
+
+// createTxTracker creates the TxTracker recording the lifecycle of the txs for
+// /tx_status, or returns nil if mempool.tx_status_cache_size is 0 or the
+// mempool isn't a CListMempool.
+func createTxTracker(config *cfg.Config, eventBus *types.EventBus, logger log.Logger) *mempl.TxTracker {
+	if config.Mempool.TxStatusCacheSize == 0 ||
+		config.Mempool.Type == cfg.MempoolTypeNop || config.Mempool.Type == cfg.MempoolTypeRemote {
+		return nil
+	}
+	txTracker := mempl.NewTxTracker(config.Mempool.TxStatusCacheSize)
+	txTracker.SetLogger(logger.With("module", "mempool"))
+	txTracker.SetEventBus(eventBus)
+	return txTracker
 }
 
 func createEvidenceReactor(config *cfg.Config, dbProvider DBProvider,
@@ -358,13 +515,27 @@ func createBlockchainReactor(config *cfg.Config,
 	blockExec *sm.BlockExecutor,
 	blockStore *store.BlockStore,
 	fastSync bool,
+	metrics *bcv0.Metrics,
 	logger log.Logger) (bcReactor p2p.Reactor, err error) {
 
 	switch config.FastSync.Version {
 	case "v0":
-		bcReactor = bcv0.NewBlockchainReactor(state.Copy(), blockExec, blockStore, fastSync)
+		r := bcv0.NewBlockchainReactor(state.Copy(), blockExec, blockStore, fastSync)
+		r.SetMaxPendingRequestsPerPeer(config.FastSync.MaxPendingRequestsPerPeer)
+		r.SetParallelRequests(config.FastSync.ParallelRequests)
+		r.SetMaxBufferBytes(config.FastSync.MaxBufferBytes)
+		r.SetMaxPeerRate(config.FastSync.MaxPeerRate)
+		r.SetPeerParams(config.FastSync.PeerTimeout, config.FastSync.MinRecvRate,
+			config.FastSync.PeerSampleRate, config.FastSync.PeerWindowSize)
+		r.SetNoPeersFallback(config.FastSync.NoPeersTimeout, config.FastSync.NoPeersMaxLag)
+		r.SetVerifyCommits(config.FastSync.VerifyCommits)
+		r.SetMetrics(metrics)
+		bcReactor = r
 	case "v1":
-		bcReactor = bcv1.NewBlockchainReactor(state.Copy(), blockExec, blockStore, fastSync)
+		r := bcv1.NewBlockchainReactor(state.Copy(), blockExec, blockStore, fastSync)
+		r.SetPeerParams(config.FastSync.PeerTimeout, config.FastSync.MinRecvRate,
+			config.FastSync.PeerSampleRate, config.FastSync.PeerWindowSize)
+		bcReactor = r
 	default:
 		return nil, fmt.Errorf("unknown fastsync version %s", config.FastSync.Version)
 	}
@@ -377,14 +548,19 @@ func createConsensusReactor(config *cfg.Config,
 	state sm.State,
 	blockExec *sm.BlockExecutor,
 	blockStore sm.BlockStore,
-	mempool *mempl.CListMempool,
+	mempool mempl.Mempool,
 	evidencePool *evidence.Pool,
 	privValidator types.PrivValidator,
 	csMetrics *cs.Metrics,
 	fastSync bool,
 	eventBus *types.EventBus,
+	txTracker *mempl.TxTracker,
 	consensusLogger log.Logger) (*consensus.Reactor, *consensus.State) {
 
+	options := []cs.StateOption{cs.StateMetrics(csMetrics)}
+	if txTracker != nil {
+		options = append(options, cs.StateTxTracker(txTracker))
+	}
 	consensusState := cs.NewState(
 		config.Consensus,
 		state.Copy(),
@@ -392,7 +568,7 @@ func createConsensusReactor(config *cfg.Config,
 		blockStore,
 		mempool,
 		evidencePool,
-		cs.StateMetrics(csMetrics),
+		options...,
 	)
 	consensusState.SetLogger(consensusLogger)
 	if privValidator != nil {
@@ -406,14 +582,25 @@ func createConsensusReactor(config *cfg.Config,
 	return consensusReactor, consensusState
 }
 
+// nodeTransport is the transport of the node's Switch, a MultiplexTransport or
+// a QUICTransport.
+type nodeTransport interface {
+	p2p.Transport
+	Listen(p2p.NetAddress) error
+	Close() error
+	SetNodeInfo(p2p.NodeInfo)
+	SetMConnConfig(conn.MConnConfig)
+}
+
 func createTransport(
 	config *cfg.Config,
 	nodeInfo p2p.NodeInfo,
 	nodeKey *p2p.NodeKey,
 	proxyApp proxy.AppConns,
 ) (
-	*p2p.MultiplexTransport,
+	nodeTransport,
 	[]p2p.PeerFilterFunc,
+	error,
 ) {
 	var (
 		mConnConfig = p2p.MConnConfig(config.P2P)
@@ -466,8 +653,26 @@ func createTransport(
 		)
 	}
 
+	// Only connect to the allowed peers
+	if config.P2P.AllowlistMode() {
+		filter, err := p2p.PeerAllowlistFilter(splitAndTrimEmpty(config.P2P.AllowedPeerIDs, ",", " "))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "could not add peer ids from allowed_peer_ids field")
+		}
+		peerFilters = append(peerFilters, filter)
+	}
+
 	p2p.MultiplexTransportConnFilters(connFilters...)(transport)
-	return transport, peerFilters
+	p2p.MultiplexTransportHandshake(config.P2P.Handshake)(transport)
+
+	if config.P2P.Transport == cfg.P2PTransportQUIC {
+		quicTransport, err := p2p.NewQUICTransport(transport)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to create the QUIC transport")
+		}
+		return quicTransport, peerFilters, nil
+	}
+	return transport, peerFilters, nil
 }
 
 func createSwitch(config *cfg.Config,
@@ -476,6 +681,7 @@ func createSwitch(config *cfg.Config,
 	peerFilters []p2p.PeerFilterFunc,
 	mempoolReactor *mempl.Reactor,
 	bcReactor p2p.Reactor,
+	stateSyncReactor *statesync.Reactor,
 	consensusReactor *consensus.Reactor,
 	evidenceReactor *evidence.Reactor,
 	nodeInfo p2p.NodeInfo,
@@ -489,10 +695,16 @@ func createSwitch(config *cfg.Config,
 		p2p.SwitchPeerFilters(peerFilters...),
 	)
 	sw.SetLogger(p2pLogger)
-	sw.AddReactor("MEMPOOL", mempoolReactor)
-	sw.AddReactor("BLOCKCHAIN", bcReactor)
-	sw.AddReactor("CONSENSUS", consensusReactor)
-	sw.AddReactor("EVIDENCE", evidenceReactor)
+	// seed nodes only run the PEX reactor
+	if config.Mode != cfg.ModeSeed {
+		if mempoolReactor != nil {
+			sw.AddReactor("MEMPOOL", mempoolReactor)
+		}
+		sw.AddReactor("BLOCKCHAIN", bcReactor)
+		sw.AddReactor("STATESYNC", stateSyncReactor)
+		sw.AddReactor("CONSENSUS", consensusReactor)
+		sw.AddReactor("EVIDENCE", evidenceReactor)
+	}
 
 	sw.SetNodeInfo(nodeInfo)
 	sw.SetNodeKey(nodeKey)
@@ -501,6 +713,15 @@ func createSwitch(config *cfg.Config,
 	return sw
 }
 
+func createBanListAndSetOnSwitch(config *cfg.Config, sw *p2p.Switch) error {
+	banList, err := p2p.LoadBanList(config.P2P.BanListFile())
+	if err != nil {
+		return err
+	}
+	sw.SetBanList(banList)
+	return nil
+}
+
 func createAddrBookAndSetOnSwitch(config *cfg.Config, sw *p2p.Switch,
 	p2pLogger log.Logger, nodeKey *p2p.NodeKey) (pex.AddrBook, error) {
 
@@ -535,7 +756,7 @@ func createPEXReactorAndAddToSwitch(addrBook pex.AddrBook, config *cfg.Config,
 	pexReactor := pex.NewReactor(addrBook,
 		&pex.ReactorConfig{
 			Seeds:    splitAndTrimEmpty(config.P2P.Seeds, ",", " "),
-			SeedMode: config.P2P.SeedMode,
+			SeedMode: config.P2P.SeedMode || config.Mode == cfg.ModeSeed,
 			// See consensus/reactor.go: blocksToContributeToBecomeGoodPeer 10000
 			// blocks assuming 10s blocks ~ 28 hours.
 			// TODO (melekes): make it dynamic based on the actual block latencies
@@ -560,21 +781,33 @@ func NewNode(config *cfg.Config,
 	logger log.Logger,
 	options ...Option) (*Node, error) {
 
+	// seed nodes have neither blocks nor an ABCI application
+	if config.Mode == cfg.ModeSeed {
+		return nil, errors.New("seed nodes run without an ABCI application: use NewSeedNode")
+	}
+
+	startup := newStartupRecorder(logger.With("module", "startup"))
+	dbProvider = startup.timedDBProvider(dbProvider)
+
 	blockStore, stateDB, err := initDBs(config, dbProvider)
 	if err != nil {
 		return nil, err
 	}
 
+	start := time.Now()
 	state, genDoc, err := LoadStateFromDBOrGenesisDocProvider(stateDB, genesisDocProvider)
 	if err != nil {
 		return nil, err
 	}
+	startup.record("load_state", start, map[string]interface{}{"height": state.LastBlockHeight})
 
 	// Create the proxyApp and establish connections to the ABCI app (consensus, mempool, query).
+	start = time.Now()
 	proxyApp, err := createAndStartProxyAppConns(clientCreator, logger)
 	if err != nil {
 		return nil, err
 	}
+	startup.record("proxy_app", start, nil)
 
 	// EventBus and IndexerService must be started before the handshake because
 	// we might need to index the txs of the replayed block as this might not have happened
@@ -586,49 +819,108 @@ func NewNode(config *cfg.Config,
 	}
 
 	// Transaction indexing
-	indexerService, txIndexer, err := createAndStartIndexerService(config, dbProvider, eventBus, logger)
+	start = time.Now()
+	indexerService, txIndexer, blockIndexer, err := createAndStartIndexerService(config, dbProvider, genDoc.ChainID, eventBus, logger)
 	if err != nil {
 		return nil, err
 	}
+	startup.record("indexer", start, nil)
 
-	// Create the handshaker, which calls RequestInfo, sets the AppVersion on the state,
-	// and replays any blocks as necessary to sync tendermint with the app.
-	consensusLogger := logger.With("module", "consensus")
-	if err := doHandshake(stateDB, state, blockStore, genDoc, eventBus, proxyApp, consensusLogger); err != nil {
-		return nil, err
+	// State sync only runs when the node has no blocks yet: the app is then
+	// restored from a snapshot in OnStart, rather than by the handshake.
+	stateSync := config.StateSync.Enable
+	if stateSync && state.LastBlockHeight > 0 {
+		logger.Info("Found local state with non-zero height, skipping state sync")
+		stateSync = false
 	}
 
-	// Reload the state. It will have the Version.Consensus.App set by the
-	// Handshake, and may have other modifications as well (ie. depending on
-	// what happened during block replay).
-	state = sm.LoadState(stateDB)
-
-	// If an address is provided, listen on the socket for a connection from an
-	// external signing process.
-	if config.PrivValidatorListenAddr != "" {
-		// FIXME: we should start services inside OnStart
-		privValidator, err = createAndStartPrivValidatorSocketClient(config.PrivValidatorListenAddr, logger)
+	// Serve the probes while the handshake replays the stored blocks, which
+	// may take a while, until the node is created (or fails to be).
+	var probeSrv *probeServer
+	if config.RPC.ProbeListenAddress != "" {
+		probeSrv, err = startProbeServer(config.RPC.ProbeListenAddress, logger.With("module", "probes"))
 		if err != nil {
-			return nil, errors.Wrap(err, "error with private validator socket client")
+			return nil, err
 		}
+		defer func() {
+			if probeSrv.node.Load() == nil {
+				probeSrv.stop()
+			}
+		}()
 	}
 
-	pubKey := privValidator.GetPubKey()
-	if pubKey == nil {
-		// TODO: GetPubKey should return errors - https://github.com/tendermint/tendermint/issues/3602
-		return nil, errors.New("could not retrieve public key from private validator")
+	// Create the handshaker, which calls RequestInfo, sets the AppVersion on the state,
+	// and replays any blocks as necessary to sync tendermint with the app.
+	consensusLogger := logger.With("module", "consensus")
+	if !stateSync {
+		start = time.Now()
+		handshaker, err := doHandshake(stateDB, state, blockStore, genDoc, eventBus, proxyApp, consensusLogger)
+		if err != nil {
+			return nil, err
+		}
+		startup.record("handshake", start, map[string]interface{}{
+			"app_height":      handshaker.AppBlockHeight(),
+			"state_height":    state.LastBlockHeight,
+			"store_height":    blockStore.Height(),
+			"replayed_blocks": handshaker.NBlocks(),
+		})
+
+		// Reload the state. It will have the Version.Consensus.App set by the
+		// Handshake, and may have other modifications as well (ie. depending on
+		// what happened during block replay).
+		state = sm.LoadState(stateDB)
+	}
+
+	var pubKey crypto.PubKey
+	if config.Mode == cfg.ModeValidator {
+		// If an address is provided, listen on the socket for a connection from an
+		// external signing process.
+		if config.PrivValidatorListenAddr != "" {
+			// FIXME: we should start services inside OnStart
+			start = time.Now()
+			privValidator, err = createAndStartPrivValidatorSocketClient(config.BaseConfig, genDoc.ChainID, logger)
+			if err != nil {
+				return nil, errors.Wrap(err, "error with private validator socket client")
+			}
+			startup.record("privval", start, nil)
+		}
+		if config.PrivValidatorGRPCAddr != "" {
+			start = time.Now()
+			privValidator, err = createPrivValidatorGRPCClient(config.BaseConfig, genDoc.ChainID)
+			if err != nil {
+				return nil, errors.Wrap(err, "error with private validator gRPC client")
+			}
+			startup.record("privval", start, nil)
+		}
+		if privValidator == nil {
+			return nil, errors.New("validator mode requires a private validator")
+		}
+
+		pubKey = privValidator.GetPubKey()
+		if pubKey == nil {
+			// TODO: GetPubKey should return errors - https://github.com/tendermint/tendermint/issues/3602
+			return nil, errors.New("could not retrieve public key from private validator")
+		}
+	} else {
+		// only validators sign
+		privValidator = nil
 	}
 
-	logNodeStartupInfo(state, pubKey, logger, consensusLogger)
+	logNodeStartupInfo(state, pubKey, config.Mode, logger, consensusLogger)
 
 	// Decide whether to fast-sync or not
 	// We don't fast-sync when the only validator is us.
 	fastSync := config.FastSyncMode && !onlyValidatorIsUs(state, privValidator)
 
-	csMetrics, p2pMetrics, memplMetrics, smMetrics := metricsProvider(genDoc.ChainID)
+	csMetrics, p2pMetrics, memplMetrics, smMetrics, bcMetrics := metricsProvider(genDoc.ChainID)
 
 	// Make MempoolReactor
-	mempoolReactor, mempool := createMempoolAndMempoolReactor(config, proxyApp, state, memplMetrics, logger)
+	txTracker := createTxTracker(config, eventBus, logger)
+	mempoolReactor, mempool, err := createMempoolAndMempoolReactor(config, proxyApp, state, eventBus, txTracker,
+		memplMetrics, logger)
+	if err != nil {
+		return nil, err
+	}
 
 	// Make Evidence Reactor
 	evidenceReactor, evidencePool, err := createEvidenceReactor(config, dbProvider, stateDB, logger)
@@ -646,32 +938,56 @@ func NewNode(config *cfg.Config,
 		sm.BlockExecutorWithMetrics(smMetrics),
 	)
 
-	// Make BlockchainReactor
-	bcReactor, err := createBlockchainReactor(config, state, blockExec, blockStore, fastSync, logger)
+	// Make BlockchainReactor. When state syncing, fast sync is only switched
+	// to once the state is restored.
+	bcReactor, err := createBlockchainReactor(config, state, blockExec, blockStore, fastSync && !stateSync,
+		bcMetrics, logger)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not create blockchain reactor")
 	}
+	if _, ok := bcReactor.(fastSyncReactor); stateSync && fastSync && !ok {
+		return nil, fmt.Errorf("fastsync version %s does not support switching from state sync",
+			config.FastSync.Version)
+	}
+	if _, ok := bcReactor.(backfillReactor); stateSync && config.StateSync.BackfillRetainHeight > 0 && !ok {
+		return nil, fmt.Errorf("fastsync version %s does not support backfilling blocks",
+			config.FastSync.Version)
+	}
 
 	// Make ConsensusReactor
 	consensusReactor, consensusState := createConsensusReactor(
 		config, state, blockExec, blockStore, mempool, evidencePool,
-		privValidator, csMetrics, fastSync, eventBus, consensusLogger,
+		privValidator, csMetrics, fastSync || stateSync, eventBus, txTracker, consensusLogger,
 	)
 
+	// Make StateSyncReactor, which also serves the snapshots of the app to
+	// the peers state syncing
+	stateSyncReactor := statesync.NewReactor(proxyApp.Snapshot(), proxyApp.Query(), config.StateSync.TempDir)
+	stateSyncReactor.SetLogger(logger.With("module", "statesync"))
+	if config.CrashDumpDir() != "" {
+		consensusState.SetPanicHandler(newCrashDumpHandler(config, consensusLogger))
+	}
+
 	nodeInfo, err := makeNodeInfo(config, nodeKey, txIndexer, genDoc, state)
 	if err != nil {
 		return nil, err
 	}
 
 	// Setup Transport.
-	transport, peerFilters := createTransport(config, nodeInfo, nodeKey, proxyApp)
+	transport, peerFilters, err := createTransport(config, nodeInfo, nodeKey, proxyApp)
+	if err != nil {
+		return nil, err
+	}
 
 	// Setup Switch.
 	p2pLogger := logger.With("module", "p2p")
 	sw := createSwitch(
-		config, transport, p2pMetrics, peerFilters, mempoolReactor, bcReactor,
+		config, transport, p2pMetrics, peerFilters, mempoolReactor, bcReactor, stateSyncReactor,
 		consensusReactor, evidenceReactor, nodeInfo, nodeKey, p2pLogger,
 	)
+	sw.SetReactorStartHook(func(name string, start time.Time) {
+		startup.record("reactor."+name, start, nil)
+	})
 
 	err = sw.AddPersistentPeers(splitAndTrimEmpty(config.P2P.PersistentPeers, ",", " "))
 	if err != nil {
@@ -688,6 +1004,10 @@ func NewNode(config *cfg.Config,
 		return nil, errors.Wrap(err, "could not create addrbook")
 	}
 
+	if err := createBanListAndSetOnSwitch(config, sw); err != nil {
+		return nil, errors.Wrap(err, "could not load the ban list")
+	}
+
 	// Optionally, start the pex reactor
 	//
 	// TODO:
@@ -701,13 +1021,17 @@ func NewNode(config *cfg.Config,
 	// If PEX is on, it should handle dialing the seeds. Otherwise the switch does it.
 	// Note we currently use the addrBook regardless at least for AddOurAddress
 	var pexReactor *pex.Reactor
-	if config.P2P.PexReactor {
+	if config.P2P.PexEnabled() {
 		pexReactor = createPEXReactorAndAddToSwitch(addrBook, config, sw, logger)
 	}
 
 	if config.ProfListenAddress != "" {
+		if config.ProfAuthToken == "" {
+			logger.Info("Profile server does not require authentication (prof_auth_token is not set)")
+		}
 		go func() {
-			logger.Error("Profile server", "err", http.ListenAndServe(config.ProfListenAddress, nil))
+			logger.Error("Profile server", "err",
+				http.ListenAndServe(config.ProfListenAddress, newProfHandler(config.ProfAuthToken)))
 		}()
 	}
 
@@ -719,22 +1043,29 @@ func NewNode(config *cfg.Config,
 		transport: transport,
 		sw:        sw,
 		addrBook:  addrBook,
-		nodeInfo:  nodeInfo,
 		nodeKey:   nodeKey,
 
 		stateDB:          stateDB,
 		blockStore:       blockStore,
 		bcReactor:        bcReactor,
+		stateSyncReactor: stateSyncReactor,
+		stateSync:        stateSync,
+		fastSync:         fastSync,
 		mempoolReactor:   mempoolReactor,
 		mempool:          mempool,
+		txTracker:        txTracker,
 		consensusState:   consensusState,
 		consensusReactor: consensusReactor,
 		pexReactor:       pexReactor,
 		evidencePool:     evidencePool,
 		proxyApp:         proxyApp,
 		txIndexer:        txIndexer,
+		blockIndexer:     blockIndexer,
 		indexerService:   indexerService,
 		eventBus:         eventBus,
+		rpcLimiter:       newRateLimiter(config.RPC.MaxRequestsPerSecond),
+
+		startup: startup,
 	}
 	node.BaseService = *service.NewBaseService(logger, "Node", node)
 
@@ -742,6 +1073,28 @@ func NewNode(config *cfg.Config,
 		option(node)
 	}
 
+	// The event bus and the indexer service are already running, so they are
+	// adopted by the group. The reactors subscribe to the event bus, and the
+	// custom services may depend on the reactors.
+	node.services = service.NewGroup(logger.With("module", "services"))
+	if err := node.services.Add("events", eventBus); err != nil {
+		return nil, err
+	}
+	if err := node.services.Add("txindex", indexerService, "events"); err != nil {
+		return nil, err
+	}
+	if err := node.services.Add("p2p", sw, "events"); err != nil {
+		return nil, err
+	}
+	for _, s := range node.customServices {
+		if err := node.services.Add(s.String(), s, "p2p"); err != nil {
+			return nil, errors.Wrap(err, "invalid custom service")
+		}
+	}
+
+	node.probeSrv = probeSrv
+	probeSrv.setNode(node)
+
 	return node, nil
 }
 
@@ -751,7 +1104,19 @@ func (n *Node) OnStart() error {
 	genTime := n.genesisDoc.GenesisTime
 	if genTime.After(now) {
 		n.Logger.Info("Genesis time is in the future. Sleeping until then...", "genTime", genTime)
+		start := time.Now()
 		time.Sleep(genTime.Sub(now))
+		n.startup.record("genesis_wait", start, nil)
+	}
+
+	// Set up the light client verifying the snapshots before anything is
+	// started, so that the start fails cleanly if it can't reach the servers
+	var stateProvider statesync.StateProvider
+	if n.stateSync {
+		var err error
+		if stateProvider, err = n.newStateSyncProvider(); err != nil {
+			return errors.Wrap(err, "failed to start state sync")
+		}
 	}
 
 	// Add private IDs to addrbook to block those peers being added
@@ -760,16 +1125,24 @@ func (n *Node) OnStart() error {
 	// Start the RPC server before the P2P server
 	// so we can eg. receive txs for the first block
 	if n.config.RPC.ListenAddress != "" {
+		start := time.Now()
 		listeners, err := n.startRPC()
 		if err != nil {
 			return err
 		}
 		n.rpcListeners = listeners
+		n.startup.record("rpc", start, nil)
 	}
 
 	if n.config.Instrumentation.Prometheus &&
 		n.config.Instrumentation.PrometheusListenAddr != "" {
-		n.prometheusSrv = n.startPrometheusServer(n.config.Instrumentation.PrometheusListenAddr)
+		n.prometheusSrv = startPrometheusServer(n.config.Instrumentation, n.Logger)
+	}
+
+	if n.config.Instrumentation.OTLPEndpoint != "" {
+		if err := n.startTelemetry(); err != nil {
+			return err
+		}
 	}
 
 	// Start the transport.
@@ -783,15 +1156,56 @@ func (n *Node) OnStart() error {
 
 	n.isListening = true
 
+	// Map the port on the gateway and advertise the external address
+	n.natMapper = createNATMapper(n.config.P2P, int(n.transport.NetAddress().Port), n.setExternalAddress, n.Logger)
+	if n.natMapper != nil {
+		if err := n.natMapper.Start(); err != nil {
+			return err
+		}
+	}
+
 	if n.config.Mempool.WalEnabled() {
+		start := time.Now()
 		n.mempool.InitWAL() // no need to have the mempool wal during tests
+		n.startup.record("mempool_wal", start, nil)
 	}
 
-	// Start the switch (the P2P server).
-	err = n.sw.Start()
-	if err != nil {
+	// Add the txs saved before the restart back, before they're gossiped
+	if n.config.Mempool.SnapshotInterval > 0 {
+		start := time.Now()
+		added, err := n.mempool.LoadSnapshot(n.config.Mempool.SnapshotFile())
+		if err != nil {
+			n.Logger.Error("Failed to load mempool snapshot", "err", err)
+		}
+		n.startup.record("mempool_snapshot", start, map[string]interface{}{"txs": added})
+		go n.mempoolSnapshotRoutine()
+	}
+
+	// Start the switch (the P2P server) and the custom services.
+	if err := n.services.Start(); err != nil {
 		return err
 	}
+	if n.stateSync {
+		n.startStateSync(stateProvider)
+	}
+
+	// Unless fast syncing, the consensus reactor has replayed the WAL.
+	if replay, ok := n.consensusState.WALReplay(); ok {
+		n.startup.recordStep(StartupStep{
+			Name:     "wal_replay",
+			Start:    replay.Start,
+			Duration: replay.Duration,
+			Details:  map[string]interface{}{"height": replay.Height, "messages": replay.Messages},
+		})
+	}
+
+	// Reconnect to the peers we were connected to before the restart
+	if n.config.P2P.PeerSnapshotInterval > 0 {
+		if err := n.dialPeerSnapshot(); err != nil {
+			n.Logger.Error("Failed to dial peers of the last peer snapshot", "err", err)
+		}
+		go n.peerSnapshotRoutine()
+	}
 
 	// Always connect to persistent peers
 	err = n.sw.DialPeersAsync(splitAndTrimEmpty(n.config.P2P.PersistentPeers, ",", " "))
@@ -799,44 +1213,154 @@ func (n *Node) OnStart() error {
 		return errors.Wrap(err, "could not dial peers from persistent_peers field")
 	}
 
+	atomic.StoreUint32(&n.startedUp, 1)
+
+	report := n.startup.finish()
+	n.Logger.Info("Started node", "took", report.Total)
+	if err := n.writeStartupReport(report); err != nil {
+		n.Logger.Error("Failed to write startup report", "err", err)
+	}
+
 	return nil
 }
 
+// fastSyncReactor is a blockchain reactor which can switch to fast sync once
+// the state is restored by state sync.
+type fastSyncReactor interface {
+	SwitchToFastSync(state sm.State) error
+}
+
+// backfillReactor is a blockchain reactor which can fetch the blocks below the
+// height restored by state sync.
+type backfillReactor interface {
+	Backfill(blockID types.BlockID, height int64, commit *types.Commit, retainHeight int64) error
+}
+
+// newStateSyncProvider creates the light client verifying the snapshots
+// against the RPC servers of the config.
+func (n *Node) newStateSyncProvider() (statesync.StateProvider, error) {
+	config := n.config.StateSync
+	stateProvider, err := statesync.NewRPCStateProvider(n.genesisDoc.ChainID, config.RPCServers,
+		lite.TrustOptions{
+			Period: config.TrustPeriod,
+			Height: config.TrustHeight,
+			Hash:   config.TrustHashBytes(),
+		}, n.Logger.With("module", "lite"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set up the light client state provider")
+	}
+	return stateProvider, nil
+}
+
+// startStateSync restores the app from a snapshot in the background. Once
+// restored, the node switches to fast sync, or to consensus, and backfills the
+// blocks below the height restored down to backfill_retain_height, if set.
+func (n *Node) startStateSync(stateProvider statesync.StateProvider) {
+	config := n.config.StateSync
+	logger := n.stateSyncReactor.Logger
+	logger.Info("Starting state sync")
+
+	go func() {
+		state, commit, err := n.stateSyncReactor.Sync(stateProvider, config.RPCServers, config.DiscoveryTime)
+		if err != nil {
+			logger.Error("State sync failed", "err", err)
+			return
+		}
+		sm.BootstrapState(n.stateDB, state)
+		n.blockStore.SaveSeenCommit(state.LastBlockHeight, commit)
+		logger.Info("Restored the state", "height", state.LastBlockHeight, "appHash", state.AppHash)
+
+		if config.BackfillRetainHeight > 0 {
+			// checked in NewNode
+			go func() {
+				err := n.bcReactor.(backfillReactor).Backfill(state.LastBlockID, state.LastBlockHeight, commit,
+					config.BackfillRetainHeight)
+				if err != nil {
+					logger.Error("Failed to backfill blocks", "err", err)
+				}
+			}()
+		}
+
+		if n.fastSync {
+			// checked in NewNode
+			if err := n.bcReactor.(fastSyncReactor).SwitchToFastSync(state); err != nil {
+				logger.Error("Failed to switch to fast sync", "err", err)
+			}
+			return
+		}
+		n.consensusReactor.SwitchToConsensus(state, 0)
+	}()
+}
+
 // OnStop stops the Node. It implements service.Service.
 func (n *Node) OnStop() {
 	n.BaseService.OnStop()
 
 	n.Logger.Info("Stopping Node")
 
-	// first stop the non-reactor services
-	n.eventBus.Stop()
-	n.indexerService.Stop()
+	// first stop accepting RPC requests
+	for _, l := range n.rpcListeners {
+		n.Logger.Info("Closing rpc listener", "listener", l)
+		if err := l.Close(); err != nil {
+			n.Logger.Error("Error closing listener", "listener", l, "err", err)
+		}
+	}
+	n.probeSrv.stop()
+
+	if n.config.P2P.PeerSnapshotInterval > 0 {
+		if err := n.savePeerSnapshot(); err != nil {
+			n.Logger.Error("Failed to save peer snapshot", "err", err)
+		}
+	}
+
+	// Stop the custom services, then the reactors, then the non-reactor
+	// services, which cancels all subscriptions. The consensus reactor waits
+	// for the current consensus step to finish and for the WAL to be flushed;
+	// peers get the messages already queued for them before being
+	// disconnected.
+	if err := n.services.Stop(); err != nil {
+		n.Logger.Error("Error stopping services", "err", err)
+	}
 
-	// now stop the reactors
-	n.sw.Stop()
+	// the mempool doesn't change anymore
+	if n.config.Mempool.SnapshotInterval > 0 {
+		if err := n.mempool.SaveSnapshot(n.config.Mempool.SnapshotFile()); err != nil {
+			n.Logger.Error("Failed to save mempool snapshot", "err", err)
+		}
+	}
 
 	// stop mempool WAL
 	if n.config.Mempool.WalEnabled() {
 		n.mempool.CloseWAL()
 	}
 
+	if mempool, ok := n.mempool.(*remotemempl.Mempool); ok {
+		if err := mempool.Close(); err != nil {
+			n.Logger.Error("Error closing the connection to the remote mempool", "err", err)
+		}
+	}
+
+	if n.natMapper != nil {
+		if err := n.natMapper.Stop(); err != nil {
+			n.Logger.Error("Error stopping the NAT mapper", "err", err)
+		}
+	}
+
 	if err := n.transport.Close(); err != nil {
 		n.Logger.Error("Error closing transport", "err", err)
 	}
 
 	n.isListening = false
 
-	// finally stop the listeners / external services
-	for _, l := range n.rpcListeners {
-		n.Logger.Info("Closing rpc listener", "listener", l)
-		if err := l.Close(); err != nil {
-			n.Logger.Error("Error closing listener", "listener", l, "err", err)
-		}
-	}
-
+	// finally stop the external services
 	if pvsc, ok := n.privValidator.(service.Service); ok {
 		pvsc.Stop()
 	}
+	if pvsc, ok := n.privValidator.(*privvalgrpc.SignerClient); ok {
+		if err := pvsc.Close(); err != nil {
+			n.Logger.Error("Error closing the connection to the private validator", "err", err)
+		}
+	}
 
 	if n.prometheusSrv != nil {
 		if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
@@ -844,38 +1368,160 @@ func (n *Node) OnStop() {
 			n.Logger.Error("Prometheus HTTP server Shutdown", "err", err)
 		}
 	}
+
+	if n.telemetry != nil {
+		telemetry.UnsetProvider(n.telemetry)
+		if err := n.telemetry.Stop(); err != nil {
+			n.Logger.Error("Error stopping telemetry export", "err", err)
+		}
+	}
 }
 
-// ConfigureRPC sets all variables in rpccore so they will serve
-// rpc calls from this node
-func (n *Node) ConfigureRPC() {
-	rpccore.SetStateDB(n.stateDB)
-	rpccore.SetBlockStore(n.blockStore)
-	rpccore.SetConsensusState(n.consensusState)
-	rpccore.SetMempool(n.mempool)
-	rpccore.SetEvidencePool(n.evidencePool)
-	rpccore.SetP2PPeers(n.sw)
-	rpccore.SetP2PTransport(n)
-	pubKey := n.privValidator.GetPubKey()
-	rpccore.SetPubKey(pubKey)
-	rpccore.SetGenesisDoc(n.genesisDoc)
-	rpccore.SetProxyAppQuery(n.proxyApp.Query())
-	rpccore.SetTxIndexer(n.txIndexer)
-	rpccore.SetConsensusReactor(n.consensusReactor)
-	rpccore.SetEventBus(n.eventBus)
-	rpccore.SetLogger(n.Logger.With("module", "rpc"))
-	rpccore.SetConfig(*n.config.RPC)
+// startTelemetry starts exporting traces and metrics to the OTLP collector of
+// the config, and sets the provider used by StartSpan (see libs/telemetry).
+func (n *Node) startTelemetry() error {
+	instrumentation := n.config.Instrumentation
+	provider, err := telemetry.NewProvider(telemetry.Config{
+		Endpoint:            instrumentation.OTLPEndpoint,
+		Insecure:            instrumentation.OTLPInsecure,
+		TraceSampleRate:     instrumentation.OTLPTraceSampleRate,
+		MetricsPushInterval: instrumentation.OTLPMetricsPushInterval,
+	}, telemetry.Resource{
+		ChainID: n.genesisDoc.ChainID,
+		NodeID:  string(n.nodeKey.ID()),
+		Moniker: n.config.Moniker,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to start telemetry export")
+	}
+	telemetry.SetProvider(provider)
+	n.telemetry = provider
+	n.Logger.Info("Exporting telemetry", "endpoint", instrumentation.OTLPEndpoint)
+	return nil
+}
+
+// Shutdown stops the node, waiting at most shutdown_grace_period for it to
+// stop. An error is returned if the grace period has passed before the node
+// stopped, in which case the node may still be stopping.
+func (n *Node) Shutdown() error {
+	stopped := make(chan struct{})
+	go func() {
+		if err := n.Stop(); err != nil {
+			n.Logger.Error("Error stopping node", "err", err)
+		}
+		close(stopped)
+	}()
+
+	grace := n.config.ShutdownGracePeriod
+	if grace == 0 {
+		<-stopped
+		return nil
+	}
+	select {
+	case <-stopped:
+		return nil
+	case <-time.After(grace):
+		return errors.Errorf("node did not stop within the shutdown grace period (%v)", grace)
+	}
+}
+
+// ConfigureRPC returns the environment serving the rpc calls from this node,
+// creating it on the first call.
+func (n *Node) ConfigureRPC() *rpccore.Environment {
+	n.rpcEnvOnce.Do(func() {
+		env := &rpccore.Environment{
+			ProxyAppQuery:     n.proxyApp.Query(),
+			ProxyAppSnapshot:  n.proxyApp.Snapshot(),
+			StateDB:           n.stateDB,
+			BlockStore:        n.blockStore,
+			EvidencePool:      n.evidencePool,
+			Consensus:         n.consensusState,
+			P2PPeers:          n.sw,
+			P2PTransport:      n,
+			GenDoc:            n.genesisDoc,
+			TxIndexer:         n.txIndexer,
+			BlockIndexer:      n.blockIndexer,
+			ConsensusReactor:  n.consensusReactor,
+			EventBus:          n.eventBus,
+			Mempool:           n.mempool,
+			TxTracker:         n.txTracker,
+			Logger:            n.Logger.With("module", "rpc"),
+			ConfigReloader:    n.ReloadConfig,
+			ConfigFieldSetter: n.SetConfigField,
+			LogLevelSetter:    n.SetModuleLogLevel,
+		}
+		if n.privValidator != nil {
+			env.PubKey = n.privValidator.GetPubKey()
+		}
+		if bcR, ok := n.bcReactor.(*bcv0.BlockchainReactor); ok {
+			env.FastSyncReactor = bcR
+		}
+		env.SetConfig(*n.config.RPC)
+		n.rpcEnv = env
+	})
+	return n.rpcEnv
+}
+
+// newRPCMux returns a mux serving the given routes over HTTP and websockets,
+// along with the health probes.
+func (n *Node) newRPCMux(
+	routes map[string]*rpcserver.RPCFunc,
+	codec *amino.Codec,
+	config *rpcserver.Config,
+	rpcLogger log.Logger,
+) *http.ServeMux {
+	mux := http.NewServeMux()
+	wmLogger := rpcLogger.With("protocol", "websocket")
+	wm := rpcserver.NewWebsocketManager(routes, codec,
+		rpcserver.OnDisconnect(func(remoteAddr string) {
+			err := n.eventBus.UnsubscribeAll(context.Background(), remoteAddr)
+			if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
+				wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
+			}
+		}),
+		rpcserver.ReadLimit(config.MaxBodyBytes),
+		rpcserver.WriteChanCapacity(n.config.RPC.WebSocketWriteBufferSize),
+	)
+	wm.SetLogger(wmLogger)
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	n.registerProbes(mux)
+	rpcserver.RegisterRPCFuncs(mux, routes, codec, rpcLogger)
+	return mux
+}
+
+// rpcRoutes returns the routes served to all clients and, if an admin token is
+// set, the routes served to the clients authenticating with it, by a separate
+// mux: the unsafe routes are then only served to them.
+func (n *Node) rpcRoutes(env *rpccore.Environment) (routes, adminRoutes map[string]*rpcserver.RPCFunc) {
+	routes = env.Routes()
+	if !n.config.RPC.Unsafe {
+		return routes, nil
+	}
+	unsafeRoutes := env.UnsafeRoutes()
+	if n.config.RPC.AdminAuthToken == "" {
+		// unsafe_profile hands out the memory and goroutine profiles of the
+		// node, so it's never served to unauthenticated clients
+		delete(unsafeRoutes, "unsafe_profile")
+		n.Logger.Info("Not serving unsafe_profile, as rpc.admin_auth_token is not set")
+		for name, route := range unsafeRoutes {
+			routes[name] = route
+		}
+		return routes, nil
+	}
+	adminRoutes = unsafeRoutes
+	for name, route := range routes {
+		adminRoutes[name] = route
+	}
+	return routes, adminRoutes
 }
 
 func (n *Node) startRPC() ([]net.Listener, error) {
-	n.ConfigureRPC()
+	env := n.ConfigureRPC()
 	listenAddrs := splitAndTrimEmpty(n.config.RPC.ListenAddress, ",", " ")
 	coreCodec := amino.NewCodec()
 	ctypes.RegisterAmino(coreCodec)
 
-	if n.config.RPC.Unsafe {
-		rpccore.AddUnsafeRoutes()
-	}
+	routes, adminRoutes := n.rpcRoutes(env)
 
 	config := rpcserver.DefaultConfig()
 	config.MaxBodyBytes = n.config.RPC.MaxBodyBytes
@@ -891,21 +1537,12 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 	// we may expose the rpc over both a unix and tcp socket
 	listeners := make([]net.Listener, len(listenAddrs))
 	for i, listenAddr := range listenAddrs {
-		mux := http.NewServeMux()
 		rpcLogger := n.Logger.With("module", "rpc-server")
-		wmLogger := rpcLogger.With("protocol", "websocket")
-		wm := rpcserver.NewWebsocketManager(rpccore.Routes, coreCodec,
-			rpcserver.OnDisconnect(func(remoteAddr string) {
-				err := n.eventBus.UnsubscribeAll(context.Background(), remoteAddr)
-				if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
-					wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
-				}
-			}),
-			rpcserver.ReadLimit(config.MaxBodyBytes),
-		)
-		wm.SetLogger(wmLogger)
-		mux.HandleFunc("/websocket", wm.WebsocketHandler)
-		rpcserver.RegisterRPCFuncs(mux, rpccore.Routes, coreCodec, rpcLogger)
+		var mux http.Handler = n.newRPCMux(routes, coreCodec, config, rpcLogger)
+		if adminRoutes != nil {
+			mux = newAdminHandler(mux, n.newRPCMux(adminRoutes, coreCodec, config, rpcLogger),
+				n.config.RPC.AdminAuthToken)
+		}
 		listener, err := rpcserver.Listen(
 			listenAddr,
 			config,
@@ -923,6 +1560,7 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 			})
 			rootHandler = corsMiddleware.Handler(mux)
 		}
+		rootHandler = n.rpcLimiter.handler(rootHandler)
 		if n.config.RPC.IsTLSEnabled() {
 			go rpcserver.StartHTTPAndTLSServer(
 				listener,
@@ -962,7 +1600,7 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 		if err != nil {
 			return nil, err
 		}
-		go grpccore.StartGRPCServer(listener)
+		go grpccore.StartGRPCServer(env, listener)
 		listeners = append(listeners, listener)
 	}
 
@@ -970,26 +1608,33 @@ func (n *Node) startRPC() ([]net.Listener, error) {
 }
 
 // startPrometheusServer starts a Prometheus HTTP server, listening for metrics
-// collectors on addr.
-func (n *Node) startPrometheusServer(addr string) *http.Server {
+// collectors on the address of the config.
+func startPrometheusServer(config *cfg.InstrumentationConfig, logger log.Logger) *http.Server {
 	srv := &http.Server{
-		Addr: addr,
+		Addr: config.PrometheusListenAddr,
 		Handler: promhttp.InstrumentMetricHandler(
 			prometheus.DefaultRegisterer, promhttp.HandlerFor(
 				prometheus.DefaultGatherer,
-				promhttp.HandlerOpts{MaxRequestsInFlight: n.config.Instrumentation.MaxOpenConnections},
+				promhttp.HandlerOpts{MaxRequestsInFlight: config.MaxOpenConnections},
 			),
 		),
 	}
 	go func() {
 		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
 			// Error starting or closing listener:
-			n.Logger.Error("Prometheus HTTP server ListenAndServe", "err", err)
+			logger.Error("Prometheus HTTP server ListenAndServe", "err", err)
 		}
 	}()
 	return srv
 }
 
+// ServiceStatus returns the status tree of the node's services: the event bus,
+// followed by the services depending on it (the indexer service and the
+// switch), followed by the custom services, which depend on the switch.
+func (n *Node) ServiceStatus() []service.Status {
+	return n.services.Status()
+}
+
 // Switch returns the Node's Switch.
 func (n *Node) Switch() *p2p.Switch {
 	return n.sw
@@ -1010,7 +1655,8 @@ func (n *Node) ConsensusReactor() *cs.Reactor {
 	return n.consensusReactor
 }
 
-// MempoolReactor returns the Node's mempool reactor.
+// MempoolReactor returns the Node's mempool reactor, nil unless the mempool
+// is a CListMempool (see mempool.type).
 func (n *Node) MempoolReactor() *mempl.Reactor {
 	return n.mempoolReactor
 }
@@ -1070,7 +1716,7 @@ func (n *Node) IsListening() bool {
 
 // NodeInfo returns the Node's Info from the Switch.
 func (n *Node) NodeInfo() p2p.NodeInfo {
-	return n.nodeInfo
+	return n.sw.NodeInfo()
 }
 
 func makeNodeInfo(
@@ -1080,17 +1726,19 @@ func makeNodeInfo(
 	genDoc *types.GenesisDoc,
 	state sm.State,
 ) (p2p.NodeInfo, error) {
+	// whether the node serves /tx and /tx_search
 	txIndexerStatus := "on"
-	if _, ok := txIndexer.(*null.TxIndex); ok {
+	switch txIndexer.(type) {
+	case *null.TxIndex, *psql.TxIndex:
 		txIndexerStatus = "off"
 	}
 
-	var bcChannel byte
+	var bcChannels []byte
 	switch config.FastSync.Version {
 	case "v0":
-		bcChannel = bcv0.BlockchainChannel
+		bcChannels = []byte{bcv0.BlockchainChannel, bcv0.BlockchainRangeChannel}
 	case "v1":
-		bcChannel = bcv1.BlockchainChannel
+		bcChannels = []byte{bcv1.BlockchainChannel}
 	default:
 		return nil, fmt.Errorf("unknown fastsync version %s", config.FastSync.Version)
 	}
@@ -1104,12 +1752,12 @@ func makeNodeInfo(
 		DefaultNodeID: nodeKey.ID(),
 		Network:       genDoc.ChainID,
 		Version:       version.TMCoreSemVer,
-		Channels: []byte{
-			bcChannel,
+		Channels: append(bcChannels,
 			cs.StateChannel, cs.DataChannel, cs.VoteChannel, cs.VoteSetBitsChannel,
 			mempl.MempoolChannel,
 			evidence.EvidenceChannel,
-		},
+			statesync.SnapshotChannel, statesync.ChunkChannel,
+		),
 		Moniker: config.Moniker,
 		Other: p2p.DefaultNodeInfoOther{
 			TxIndex:    txIndexerStatus,
@@ -1117,10 +1765,27 @@ func makeNodeInfo(
 		},
 	}
 
-	if config.P2P.PexReactor {
+	if config.Mode == cfg.ModeSeed {
+		nodeInfo.Channels = []byte{}
+	}
+
+	// only the CListMempool has a reactor
+	if mt := config.Mempool.Type; mt == cfg.MempoolTypeNop || mt == cfg.MempoolTypeRemote {
+		nodeInfo.Channels = bytes.Replace(nodeInfo.Channels, []byte{mempl.MempoolChannel}, nil, 1)
+	}
+
+	if config.P2P.PexEnabled() {
 		nodeInfo.Channels = append(nodeInfo.Channels, pex.PexChannel)
 	}
 
+	// the config is validated, the list is well-formed
+	compressedChannels, _ := cfg.ParseChannelList(config.P2P.CompressedChannels)
+	for _, chID := range compressedChannels {
+		if bytes.IndexByte(nodeInfo.Channels, chID) >= 0 {
+			nodeInfo.CompressedChannels = append(nodeInfo.CompressedChannels, chID)
+		}
+	}
+
 	lAddr := config.P2P.ExternalAddress
 
 	if lAddr == "" {
@@ -1192,20 +1857,71 @@ func saveGenesisDoc(db dbm.DB, genDoc *types.GenesisDoc) {
 }
 
 func createAndStartPrivValidatorSocketClient(
-	listenAddr string,
+	config cfg.BaseConfig,
+	chainID string,
 	logger log.Logger,
 ) (types.PrivValidator, error) {
-	pve, err := privval.NewSignerListener(listenAddr, logger)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to start private validator")
+	listenAddrs := splitAndTrimEmpty(config.PrivValidatorListenAddr, ",", " ")
+	if len(listenAddrs) == 1 {
+		pve, err := privval.NewSignerListener(listenAddrs[0], logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start private validator")
+		}
+
+		pvsc, err := privval.NewSignerClient(pve)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start private validator")
+		}
+
+		return pvsc, nil
+	}
+
+	endpoints := make([]*privval.SignerListenerEndpoint, len(listenAddrs))
+	for i, addr := range listenAddrs {
+		pve, err := privval.NewSignerListener(addr, logger)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start private validator")
+		}
+		endpoints[i] = pve
+	}
+
+	if config.PrivValidatorSigner == cfg.PrivValidatorSignerThreshold {
+		// a shard per address
+		tsc, err := privval.NewThresholdSignerClient(endpoints, chainID, config.PrivValidatorShareTimeout,
+			logger.With("module", "privval"))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start private validator")
+		}
+		return tsc, nil
 	}
 
-	pvsc, err := privval.NewSignerClient(pve)
+	// a remote signer per address
+	signers := make([]*privval.SignerClient, len(endpoints))
+	for i, pve := range endpoints {
+		pvsc, err := privval.NewSignerClient(pve)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to start private validator")
+		}
+		signers[i] = pvsc
+	}
+	fsc, err := privval.NewFailoverSignerClient(signers, config.PrivValidatorFailoverCooldown,
+		logger.With("module", "privval"))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to start private validator")
 	}
+	return fsc, nil
+}
 
-	return pvsc, nil
+func createPrivValidatorGRPCClient(config cfg.BaseConfig, chainID string) (types.PrivValidator, error) {
+	var tlsConfig *tls.Config
+	if caFile, certFile, keyFile := config.PrivValidatorGRPCTLSFiles(); caFile != "" {
+		var err error
+		tlsConfig, err = privvalgrpc.ClientTLSConfig(caFile, certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load the TLS files")
+		}
+	}
+	return privvalgrpc.NewSignerClient(config.PrivValidatorGRPCAddr, chainID, tlsConfig)
 }
 
 // splitAndTrimEmpty slices s into all subslices separated by sep and returns a
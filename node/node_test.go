@@ -1,29 +1,48 @@
 package node
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
 	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/threshold"
 	"github.com/tendermint/tendermint/evidence"
 	"github.com/tendermint/tendermint/libs/log"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/libs/telemetry"
 	mempl "github.com/tendermint/tendermint/mempool"
 	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/conn"
 	p2pmock "github.com/tendermint/tendermint/p2p/mock"
+	"github.com/tendermint/tendermint/p2p/pex"
 	"github.com/tendermint/tendermint/privval"
+	privvalgrpc "github.com/tendermint/tendermint/privval/grpc"
 	"github.com/tendermint/tendermint/proxy"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/lib/client"
 	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/state/txindex/kv"
+	"github.com/tendermint/tendermint/statesync"
 	"github.com/tendermint/tendermint/types"
 	tmtime "github.com/tendermint/tendermint/types/time"
 	"github.com/tendermint/tendermint/version"
@@ -72,6 +91,148 @@ func TestNodeStartStop(t *testing.T) {
 	}
 }
 
+func TestNodeStartupReport(t *testing.T) {
+	config := cfg.ResetTestRoot("node_startup_report_test")
+	defer os.RemoveAll(config.RootDir)
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	defer n.Stop()
+
+	report := n.StartupReport()
+	var names []string
+	for _, step := range report.Steps {
+		names = append(names, step.Name)
+	}
+	assert.Subset(t, names, []string{
+		"db.blockstore", "db.state", "db.tx_index", "db.evidence",
+		"load_state", "proxy_app", "indexer", "handshake", "rpc", "wal_replay",
+	})
+	// reactors are started in the order they were added
+	var reactors []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "reactor.") {
+			reactors = append(reactors, name)
+		}
+	}
+	assert.Equal(t, []string{
+		"reactor.MEMPOOL", "reactor.BLOCKCHAIN", "reactor.STATESYNC", "reactor.CONSENSUS", "reactor.EVIDENCE",
+		"reactor.PEX",
+	}, reactors)
+	assert.True(t, report.Total > 0)
+
+	bz, err := ioutil.ReadFile(filepath.Join(config.DBDir(), startupReportFile))
+	require.NoError(t, err)
+	var written StartupReport
+	require.NoError(t, json.Unmarshal(bz, &written))
+	assert.Len(t, written.Steps, len(report.Steps))
+}
+
+func TestNodeStateSync(t *testing.T) {
+	config := cfg.ResetTestRoot("node_state_sync_test")
+	defer os.RemoveAll(config.RootDir)
+	config.StateSync.Enable = true
+	config.StateSync.RPCServers = []string{"tcp://127.0.0.1:1", "tcp://127.0.0.1:2"}
+	config.StateSync.TrustHeight = 1
+	config.StateSync.TrustHash = "0102"
+
+	// the app is restored from a snapshot on start, rather than by the handshake
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.True(t, n.stateSync)
+	assert.True(t, n.ConsensusReactor().FastSync(), "consensus waits for state sync")
+	for _, step := range n.startup.report.Steps {
+		assert.NotEqual(t, "handshake", step.Name)
+	}
+	assert.Contains(t, n.NodeInfo().(p2p.DefaultNodeInfo).Channels, statesync.SnapshotChannel)
+
+	// the light client can't reach the RPC servers
+	err = n.Start()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start state sync")
+}
+
+func TestNodeStateSyncBackfill(t *testing.T) {
+	config := cfg.ResetTestRoot("node_state_sync_backfill_test")
+	defer os.RemoveAll(config.RootDir)
+	config.StateSync.Enable = true
+	config.StateSync.RPCServers = []string{"tcp://127.0.0.1:1", "tcp://127.0.0.1:2"}
+	config.StateSync.TrustHeight = 1
+	config.StateSync.TrustHash = "0102"
+	config.StateSync.BackfillRetainHeight = 1
+	config.FastSyncMode = false
+
+	// only fast sync v0 backfills the blocks
+	config.FastSync.Version = "v1"
+	_, err := DefaultNewNode(config, log.TestingLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not support backfilling blocks")
+}
+
+func TestNodeCrashDump(t *testing.T) {
+	config := cfg.ResetTestRoot("node_crash_dump_test")
+	defer os.RemoveAll(config.RootDir)
+	config.RPC.AdminAuthToken = "secret"
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	defer n.Stop()
+
+	blocksSub, err := n.EventBus().Subscribe(context.Background(), "node_test", types.EventQueryNewBlock)
+	require.NoError(t, err)
+	select {
+	case <-blocksSub.Out():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the node to produce a block")
+	}
+
+	dir, err := writeCrashDump(config, n.consensusState, "boom", []byte("stack"))
+	require.NoError(t, err)
+	assert.Equal(t, config.CrashDumpDir(), filepath.Dir(dir))
+
+	bz, err := ioutil.ReadFile(filepath.Join(dir, "panic.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "boom\n\nstack", string(bz))
+
+	bz, err = ioutil.ReadFile(filepath.Join(dir, "consensus_state.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), `"height"`)
+
+	bz, err = ioutil.ReadFile(filepath.Join(dir, "wal_tail.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), "EndHeightMessage")
+
+	bz, err = ioutil.ReadFile(filepath.Join(dir, "goroutines.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(bz), "goroutine")
+
+	bz, err = ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	require.NoError(t, err)
+	var digest crashConfigDigest
+	require.NoError(t, json.Unmarshal(bz, &digest))
+	assert.NotEmpty(t, digest.FileSHA256)
+	assert.Equal(t, "<redacted>", digest.Fields["rpc.admin_auth_token"])
+	assert.Equal(t, config.Moniker, digest.Fields["moniker"])
+}
+
+func TestNodeTelemetry(t *testing.T) {
+	config := cfg.ResetTestRoot("node_telemetry_test")
+	defer os.RemoveAll(config.RootDir)
+	// the collector doesn't have to be reachable
+	config.Instrumentation.OTLPEndpoint = "127.0.0.1:1"
+	config.Instrumentation.OTLPInsecure = true
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	assert.NotNil(t, telemetry.GetProvider())
+
+	require.NoError(t, n.Stop())
+	assert.Nil(t, telemetry.GetProvider())
+}
+
 func TestSplitAndTrimEmpty(t *testing.T) {
 	testCases := []struct {
 		s        string
@@ -125,7 +286,7 @@ func TestNodeSetAppVersion(t *testing.T) {
 	assert.Equal(t, state.Version.Consensus.App, appVersion)
 
 	// check version is set in node info
-	assert.Equal(t, n.nodeInfo.(p2p.DefaultNodeInfo).ProtocolVersion.App, appVersion)
+	assert.Equal(t, n.NodeInfo().(p2p.DefaultNodeInfo).ProtocolVersion.App, appVersion)
 }
 
 func TestNodeSetPrivValTCP(t *testing.T) {
@@ -205,6 +366,87 @@ func TestNodeSetPrivValIPC(t *testing.T) {
 	assert.IsType(t, &privval.SignerClient{}, n.PrivValidator())
 }
 
+func TestNodeSetPrivValThreshold(t *testing.T) {
+	config := cfg.ResetTestRoot("node_priv_val_threshold_test")
+	defer os.RemoveAll(config.RootDir)
+
+	privKey := ed25519.GenPrivKey()
+	keyShares, err := threshold.SplitPrivKey(privKey, 2, 2)
+	require.NoError(t, err)
+	var addrs []string
+	for i, keyShare := range keyShares {
+		keyFile := filepath.Join(config.RootDir, fmt.Sprintf("share%d.json", i))
+		require.NoError(t, privval.SaveThresholdKeyShare(keyShare, keyFile))
+		shard, err := privval.LoadThresholdShard(keyFile, filepath.Join(config.RootDir, fmt.Sprintf("state%d.json", i)))
+		require.NoError(t, err)
+
+		tmpfile := "/tmp/kms." + tmrand.Str(6) + ".sock"
+		defer os.Remove(tmpfile) // clean up
+		addrs = append(addrs, "unix://"+tmpfile)
+
+		dialerEndpoint := privval.NewSignerDialerEndpoint(log.TestingLogger(), privval.DialUnixFn(tmpfile))
+		privval.SignerDialerEndpointTimeoutReadWrite(100 * time.Millisecond)(dialerEndpoint)
+		ss := privval.NewThresholdSignerServer(dialerEndpoint, config.ChainID(), shard)
+		go func() {
+			err := ss.Start()
+			require.NoError(t, err)
+		}()
+		defer ss.Stop()
+	}
+	config.BaseConfig.PrivValidatorListenAddr = strings.Join(addrs, ",")
+	config.BaseConfig.PrivValidatorSigner = cfg.PrivValidatorSignerThreshold
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &privval.ThresholdSignerClient{}, n.PrivValidator())
+	assert.Equal(t, privKey.PubKey(), n.PrivValidator().GetPubKey())
+}
+
+func TestNodeSetPrivValFailover(t *testing.T) {
+	config := cfg.ResetTestRoot("node_priv_val_failover_test")
+	defer os.RemoveAll(config.RootDir)
+
+	// the first signer is down, the node starts with the second one
+	pv := types.NewMockPV()
+	addrs := []string{"unix:///tmp/kms." + tmrand.Str(6) + ".sock"}
+	tmpfile := "/tmp/kms." + tmrand.Str(6) + ".sock"
+	defer os.Remove(tmpfile) // clean up
+	addrs = append(addrs, "unix://"+tmpfile)
+
+	dialerEndpoint := privval.NewSignerDialerEndpoint(log.TestingLogger(), privval.DialUnixFn(tmpfile))
+	privval.SignerDialerEndpointTimeoutReadWrite(100 * time.Millisecond)(dialerEndpoint)
+	ss := privval.NewSignerServer(dialerEndpoint, config.ChainID(), pv)
+	go func() {
+		err := ss.Start()
+		require.NoError(t, err)
+	}()
+	defer ss.Stop()
+	config.BaseConfig.PrivValidatorListenAddr = strings.Join(addrs, ",")
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &privval.FailoverSignerClient{}, n.PrivValidator())
+	assert.Equal(t, pv.GetPubKey(), n.PrivValidator().GetPubKey())
+}
+
+func TestNodeSetPrivValGRPC(t *testing.T) {
+	config := cfg.ResetTestRoot("node_priv_val_grpc_test")
+	defer os.RemoveAll(config.RootDir)
+
+	pv := types.NewMockPV()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := privvalgrpc.NewGRPCServer(pv, config.ChainID(), nil)
+	go grpcServer.Serve(ln) // nolint:errcheck
+	defer grpcServer.Stop()
+	config.BaseConfig.PrivValidatorGRPCAddr = "tcp://" + ln.Addr().String()
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &privvalgrpc.SignerClient{}, n.PrivValidator())
+	assert.Equal(t, pv.GetPubKey(), n.PrivValidator().GetPubKey())
+}
+
 // testFreeAddr claims a free port so we don't block on listener being ready.
 func testFreeAddr(t *testing.T) string {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
@@ -323,6 +565,74 @@ func TestNodeNewNodeCustomReactors(t *testing.T) {
 	assert.Equal(t, customBlockchainReactor, n.Switch().Reactor("BLOCKCHAIN"))
 }
 
+type channelReactor struct {
+	*p2pmock.Reactor
+}
+
+func (r channelReactor) GetChannels() []*conn.ChannelDescriptor {
+	return []*conn.ChannelDescriptor{{ID: 0x99, Priority: 1}}
+}
+
+type testService struct {
+	service.BaseService
+}
+
+func newTestService(name string) *testService {
+	s := &testService{}
+	s.BaseService = *service.NewBaseService(nil, name, s)
+	return s
+}
+
+func TestNodeCustomReactorChannelsAndServices(t *testing.T) {
+	config := cfg.ResetTestRoot("node_custom_services_test")
+	defer os.RemoveAll(config.RootDir)
+
+	cr := channelReactor{p2pmock.NewReactor()}
+	svc1, svc2 := newTestService("svc1"), newTestService("svc2")
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	require.NoError(t, err)
+
+	n, err := NewNode(config,
+		privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()),
+		nodeKey,
+		proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+		DefaultGenesisDocProviderFunc(config),
+		DefaultDBProvider,
+		DefaultMetricsProvider(config.Instrumentation),
+		log.TestingLogger(),
+		CustomReactors(map[string]p2p.Reactor{"FOO": cr}),
+		CustomServices(svc1, svc2),
+	)
+	require.NoError(t, err)
+
+	// the custom channel is advertised to peers
+	assert.Contains(t, n.NodeInfo().(p2p.DefaultNodeInfo).Channels, byte(0x99))
+	assert.Contains(t, n.Switch().NodeInfo().(p2p.DefaultNodeInfo).Channels, byte(0x99))
+
+	assert.False(t, svc1.IsRunning())
+	require.NoError(t, n.Start())
+	assert.True(t, svc1.IsRunning())
+	assert.True(t, svc2.IsRunning())
+
+	status := n.ServiceStatus()
+	require.Len(t, status, 1)
+	assert.Equal(t, "events", status[0].Name)
+	require.Len(t, status[0].Dependents, 2)
+	p2pStatus := status[0].Dependents[1]
+	assert.Equal(t, "p2p", p2pStatus.Name)
+	assert.Equal(t, service.StateRunning, p2pStatus.State)
+	assert.Equal(t, []service.Status{
+		{Name: "svc1", State: service.StateRunning},
+		{Name: "svc2", State: service.StateRunning},
+	}, p2pStatus.Dependents)
+
+	require.NoError(t, n.Stop())
+	assert.False(t, svc1.IsRunning())
+	assert.False(t, svc2.IsRunning())
+	assert.Equal(t, service.StateStopped, n.ServiceStatus()[0].State)
+}
+
 func state(nVals int, height int64) (sm.State, dbm.DB) {
 	vals := make([]types.GenesisValidator, nVals)
 	for i := 0; i < nVals; i++ {
@@ -352,3 +662,693 @@ func state(nVals int, height int64) (sm.State, dbm.DB) {
 	}
 	return s, stateDB
 }
+
+func TestNodeApplyConfig(t *testing.T) {
+	config := cfg.ResetTestRoot("node_apply_config_test")
+	defer os.RemoveAll(config.RootDir)
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+
+	var logLevel string
+	n.SetLogLevelReloader(func(lvl string) error {
+		logLevel = lvl
+		return nil
+	})
+
+	newConfig := cfg.ResetTestRoot("node_apply_config_test")
+	defer os.RemoveAll(newConfig.RootDir)
+	newConfig.LogLevel = "debug"
+	newConfig.Moniker = "renamed"
+	newConfig.Consensus.TimeoutPropose = 7 * time.Second
+	newConfig.P2P.SendRate = 1024
+	newConfig.P2P.UnconditionalPeerIDs = "7e1b7a2ae3308a4c1e48e8bdd4e8bd9961e3ec3b"
+	newConfig.RPC.MaxSubscriptionClients = 3
+	newConfig.RPC.SlowClientPolicy = cfg.SlowClientPolicyNotify
+
+	res, err := n.ApplyConfig(newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"consensus.timeout_propose",
+		"log_level",
+		"p2p.send_rate",
+		"p2p.unconditional_peer_ids",
+		"rpc.max_subscription_clients",
+		"rpc.slow_client_policy",
+	}, res.Applied)
+	assert.Equal(t, []string{"moniker"}, res.RestartRequired)
+
+	assert.Equal(t, "debug", logLevel)
+	assert.Equal(t, 7*time.Second, n.config.Consensus.TimeoutPropose)
+	assert.True(t, n.sw.IsPeerUnconditional("7e1b7a2ae3308a4c1e48e8bdd4e8bd9961e3ec3b"))
+
+	// applying the same config again changes nothing but the moniker
+	res, err = n.ApplyConfig(newConfig)
+	require.NoError(t, err)
+	assert.Empty(t, res.Applied)
+	assert.Equal(t, []string{"moniker"}, res.RestartRequired)
+
+	// removing an unconditional peer requires a restart
+	newConfig.P2P.UnconditionalPeerIDs = ""
+	res, err = n.ApplyConfig(newConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"moniker", "p2p.unconditional_peer_ids"}, res.RestartRequired)
+
+	newConfig.Consensus.TimeoutPropose = -1
+	_, err = n.ApplyConfig(newConfig)
+	assert.Error(t, err)
+}
+
+func TestNodeSetConfigField(t *testing.T) {
+	config := cfg.ResetTestRoot("node_set_config_field_test")
+	defer os.RemoveAll(config.RootDir)
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+
+	res, err := n.SetConfigField("mempool.cache_size", "50")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mempool.cache_size"}, res.Applied)
+	assert.Equal(t, 50, n.config.Mempool.CacheSize)
+
+	res, err = n.SetConfigField("fastsync.max_pending_requests_per_peer", "5")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fastsync.max_pending_requests_per_peer"}, res.Applied)
+
+	// disabling the cache requires a restart
+	res, err = n.SetConfigField("mempool.cache_size", "0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mempool.cache_size"}, res.RestartRequired)
+
+	// setting the current value changes nothing
+	res, err = n.SetConfigField("fastsync.max_pending_requests_per_peer", "5")
+	require.NoError(t, err)
+	assert.Empty(t, res.Applied)
+
+	_, err = n.SetConfigField("moniker", "renamed")
+	assert.Error(t, err)
+	_, err = n.SetConfigField("mempool.cache_size", "-1")
+	assert.Error(t, err)
+
+	res, err = n.SetConfigField("mempool.broadcast_fanout", "3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mempool.broadcast_fanout"}, res.Applied)
+	assert.Equal(t, 3, n.config.Mempool.BroadcastFanout)
+
+	res, err = n.SetConfigField("rpc.max_requests_per_second", "0.5")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rpc.max_requests_per_second"}, res.Applied)
+	assert.True(t, n.rpcLimiter.allow("client", time.Now()))
+	assert.False(t, n.rpcLimiter.allow("client", time.Now()))
+
+	entries, err := cfg.ReadJournal(config.JournalFile())
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	assert.Equal(t, "mempool.cache_size", entries[0].Key)
+	assert.Equal(t, "50", entries[0].Value)
+	assert.Equal(t, "fastsync.max_pending_requests_per_peer", entries[1].Key)
+	assert.Equal(t, "0", entries[2].Value)
+}
+
+func TestNodeSetModuleLogLevel(t *testing.T) {
+	config := cfg.ResetTestRoot("node_set_module_log_level_test")
+	defer os.RemoveAll(config.RootDir)
+	config.LogLevel = "main:info,*:error"
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	var reloaded string
+	n.SetLogLevelReloader(func(logLevel string) error {
+		reloaded = logLevel
+		return nil
+	})
+
+	res, err := n.SetModuleLogLevel("p2p", "debug")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"log_level"}, res.Applied)
+	assert.Equal(t, "main:info,p2p:debug,*:error", reloaded)
+	assert.Equal(t, reloaded, n.config.LogLevel)
+
+	_, err = n.SetModuleLogLevel("p2p", "verbose")
+	assert.Error(t, err)
+
+	entries, err := cfg.ReadJournal(config.JournalFile())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "log_level", entries[0].Key)
+	assert.Equal(t, "main:info,p2p:debug,*:error", entries[0].Value)
+}
+
+func TestNodeShutdown(t *testing.T) {
+	config := cfg.ResetTestRoot("node_shutdown_test")
+	defer os.RemoveAll(config.RootDir)
+	config.ShutdownGracePeriod = 5 * time.Second
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+
+	blocksSub, err := n.EventBus().Subscribe(context.Background(), "node_test", types.EventQueryNewBlock)
+	require.NoError(t, err)
+	select {
+	case <-blocksSub.Out():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the node to produce a block")
+	}
+
+	require.NoError(t, n.Shutdown())
+	assert.False(t, n.IsRunning())
+	assert.False(t, n.ConsensusState().IsRunning())
+
+	// subscriptions are cancelled
+	select {
+	case <-blocksSub.Cancelled():
+	case <-time.After(time.Second):
+		t.Fatal("subscription was not cancelled")
+	}
+
+	// the RPC listeners are closed
+	for _, l := range n.rpcListeners {
+		_, err := net.Dial("tcp", l.Addr().String())
+		assert.Error(t, err)
+	}
+}
+
+func TestNodeProbes(t *testing.T) {
+	config := cfg.ResetTestRoot("node_probes_test")
+	defer os.RemoveAll(config.RootDir)
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	n.registerProbes(mux)
+	probe := func(path string) int {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, probe(LivenessProbePath))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(StartupProbePath))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(ReadinessProbePath))
+
+	require.NoError(t, n.Start())
+	defer n.Stop()
+
+	// wait for the second block, as the first one has the genesis time
+	blocksSub, err := n.EventBus().Subscribe(context.Background(), "node_test", types.EventQueryNewBlock)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-blocksSub.Out():
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for the node to produce a block")
+		}
+	}
+
+	assert.Equal(t, http.StatusOK, probe(LivenessProbePath))
+	assert.Equal(t, http.StatusOK, probe(StartupProbePath))
+	assert.Equal(t, http.StatusOK, probe(ReadinessProbePath))
+
+	n.config.RPC.ReadinessMinPeers = 1
+	assert.Equal(t, http.StatusServiceUnavailable, probe(ReadinessProbePath))
+	n.config.RPC.ReadinessMinPeers = 0
+
+	n.config.RPC.ReadinessMaxBlockAge = time.Hour
+	assert.Equal(t, http.StatusOK, probe(ReadinessProbePath))
+	n.config.RPC.ReadinessMaxBlockAge = time.Nanosecond
+	assert.Equal(t, http.StatusServiceUnavailable, probe(ReadinessProbePath))
+}
+
+// blockingInfoApp blocks the handshake until unblock is closed.
+type blockingInfoApp struct {
+	*kvstore.Application
+	unblock chan struct{}
+}
+
+func (app blockingInfoApp) Info(req abci.RequestInfo) abci.ResponseInfo {
+	<-app.unblock
+	return app.Application.Info(req)
+}
+
+func TestNodeProbeServer(t *testing.T) {
+	config := cfg.ResetTestRoot("node_probe_server_test")
+	defer os.RemoveAll(config.RootDir)
+	addr := testFreeAddr(t)
+	config.RPC.ProbeListenAddress = "tcp://" + addr
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	require.NoError(t, err)
+	app := blockingInfoApp{kvstore.NewApplication(), make(chan struct{})}
+	type result struct {
+		n   *Node
+		err error
+	}
+	created := make(chan result, 1)
+	go func() {
+		n, err := NewNode(config,
+			privval.LoadOrGenFilePV(config.PrivValidatorKeyFile(), config.PrivValidatorStateFile()),
+			nodeKey,
+			proxy.NewLocalClientCreator(app),
+			DefaultGenesisDocProviderFunc(config),
+			DefaultDBProvider,
+			DefaultMetricsProvider(config.Instrumentation),
+			log.TestingLogger(),
+		)
+		created <- result{n, err}
+	}()
+
+	probe := func(path string) int {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// the node is alive, but not started up, during the handshake
+	require.Eventually(t, func() bool { return probe(LivenessProbePath) == http.StatusOK },
+		5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, http.StatusServiceUnavailable, probe(StartupProbePath))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(ReadinessProbePath))
+
+	close(app.unblock)
+	res := <-created
+	require.NoError(t, res.err)
+	n := res.n
+	assert.Equal(t, http.StatusServiceUnavailable, probe(StartupProbePath))
+
+	require.NoError(t, n.Start())
+	assert.Equal(t, http.StatusOK, probe(LivenessProbePath))
+	assert.Equal(t, http.StatusOK, probe(StartupProbePath))
+
+	require.NoError(t, n.Stop())
+	assert.Equal(t, 0, probe(LivenessProbePath))
+}
+
+func TestNodeModes(t *testing.T) {
+	t.Run("validator", func(t *testing.T) {
+		config := cfg.ResetTestRoot("node_modes_test")
+		defer os.RemoveAll(config.RootDir)
+
+		nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+		require.NoError(t, err)
+		_, err = NewNode(config,
+			nil,
+			nodeKey,
+			proxy.DefaultClientCreator(config.ProxyApp, config.ABCI, config.DBDir()),
+			DefaultGenesisDocProviderFunc(config),
+			DefaultDBProvider,
+			DefaultMetricsProvider(config.Instrumentation),
+			log.TestingLogger(),
+		)
+		assert.Error(t, err, "validator mode requires a private validator")
+	})
+
+	t.Run("full", func(t *testing.T) {
+		config := cfg.ResetTestRoot("node_modes_test")
+		defer os.RemoveAll(config.RootDir)
+		config.Mode = cfg.ModeFull
+
+		n, err := DefaultNewNode(config, log.TestingLogger())
+		require.NoError(t, err)
+		assert.Nil(t, n.PrivValidator())
+		assert.NotNil(t, n.Switch().Reactor("CONSENSUS"))
+		assert.NotNil(t, n.Switch().Reactor("MEMPOOL"))
+	})
+
+	t.Run("seed", func(t *testing.T) {
+		config := cfg.ResetTestRoot("node_modes_test")
+		defer os.RemoveAll(config.RootDir)
+		config.Mode = cfg.ModeSeed
+
+		// seed nodes are SeedNodes, see TestSeedNode
+		_, err := DefaultNewNode(config, log.TestingLogger())
+		assert.Error(t, err)
+		// the ABCI application isn't started
+		_, err = os.Stat(filepath.Join(config.DBDir(), "state.db"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		config := cfg.ResetTestRoot("node_modes_test")
+		defer os.RemoveAll(config.RootDir)
+		config.Mode = cfg.ModeArchive
+
+		n, err := DefaultNewNode(config, log.TestingLogger())
+		require.NoError(t, err)
+		assert.Nil(t, n.PrivValidator())
+		_, isKV := n.txIndexer.(*kv.TxIndex)
+		assert.True(t, isKV)
+	})
+}
+
+func TestSeedNode(t *testing.T) {
+	config := cfg.ResetTestRoot("node_seed_test")
+	defer os.RemoveAll(config.RootDir)
+
+	_, err := DefaultNewSeedNode(config, log.TestingLogger())
+	assert.Error(t, err, "the mode must be seed")
+
+	config.Mode = cfg.ModeSeed
+	config.DBBackend = "goleveldb"
+	config.P2P.ListenAddress = "tcp://127.0.0.1:0"
+	config.P2P.SeedDNSListenAddress = "127.0.0.1:0"
+	config.P2P.SeedDNSDomain = "seed.example.com"
+	n, err := DefaultNewSeedNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []byte{pex.PexChannel}, []byte(n.NodeInfo().(p2p.DefaultNodeInfo).Channels))
+	assert.Equal(t, config.ChainID(), n.NodeInfo().(p2p.DefaultNodeInfo).Network)
+
+	require.NoError(t, n.Start())
+	assert.True(t, n.PEXReactor().IsRunning())
+	assert.True(t, n.AddrBook().IsRunning())
+	require.NotNil(t, n.DNSSeed())
+	assert.NotNil(t, n.DNSSeed().Addr())
+
+	// no blockstore, state nor ABCI application
+	for _, db := range []string{"blockstore", "state", "tx_index"} {
+		_, err = os.Stat(filepath.Join(config.DBDir(), db+".db"))
+		assert.True(t, os.IsNotExist(err), db)
+	}
+
+	require.NoError(t, n.Stop())
+	assert.False(t, n.DNSSeed().IsRunning())
+}
+
+func TestNodeSetExternalAddress(t *testing.T) {
+	config := cfg.ResetTestRoot("node_external_address_test")
+	defer os.RemoveAll(config.RootDir)
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+
+	// the detected address is advertised...
+	n.setExternalAddress(net.ParseIP("203.0.113.1"), 26656)
+	assert.Equal(t, "203.0.113.1:26656", n.NodeInfo().(p2p.DefaultNodeInfo).ListenAddr)
+	assert.True(t, n.addrBook.OurAddress(&p2p.NetAddress{ID: n.nodeKey.ID(), IP: net.ParseIP("203.0.113.1"), Port: 26656}))
+
+	// ...unless the external address is set in the config
+	config.P2P.ExternalAddress = "198.51.100.1:26656"
+	n.setExternalAddress(net.ParseIP("203.0.113.2"), 26656)
+	assert.Equal(t, "203.0.113.1:26656", n.NodeInfo().(p2p.DefaultNodeInfo).ListenAddr)
+	assert.True(t, n.addrBook.OurAddress(&p2p.NetAddress{ID: n.nodeKey.ID(), IP: net.ParseIP("203.0.113.2"), Port: 26656}))
+}
+
+func TestNodeAllowlistMode(t *testing.T) {
+	config := cfg.ResetTestRoot("node_allowlist_test")
+	defer os.RemoveAll(config.RootDir)
+	config.P2P.PexReactor = true
+	config.P2P.AllowedPeerIDs = "0123456789abcdef0123456789abcdef01234567"
+
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+
+	// no PEX
+	assert.Nil(t, n.pexReactor)
+	assert.Nil(t, n.Switch().Reactor("PEX"))
+	assert.False(t, bytes.Contains(n.NodeInfo().(p2p.DefaultNodeInfo).Channels, []byte{pex.PexChannel}))
+
+	config.P2P.AllowedPeerIDs = "invalid"
+	_, err = DefaultNewNode(config, log.TestingLogger())
+	assert.Error(t, err)
+}
+
+func TestNodeMempoolTypes(t *testing.T) {
+	for _, mempoolType := range []string{cfg.MempoolTypePriority, cfg.MempoolTypeNop} {
+		mempoolType := mempoolType
+		t.Run(mempoolType, func(t *testing.T) {
+			config := cfg.ResetTestRoot("node_mempool_types_test")
+			defer os.RemoveAll(config.RootDir)
+			config.Mode = cfg.ModeFull
+			config.Mempool.Type = mempoolType
+
+			n, err := DefaultNewNode(config, log.TestingLogger())
+			require.NoError(t, err)
+			channels := n.NodeInfo().(p2p.DefaultNodeInfo).Channels
+			if mempoolType == cfg.MempoolTypeNop {
+				assert.Equal(t, mempl.NopMempool{}, n.Mempool())
+				assert.Nil(t, n.Switch().Reactor("MEMPOOL"))
+				assert.NotContains(t, []byte(channels), mempl.MempoolChannel)
+			} else {
+				assert.IsType(t, &mempl.CListMempool{}, n.Mempool())
+				assert.NotNil(t, n.Switch().Reactor("MEMPOOL"))
+				assert.Contains(t, []byte(channels), mempl.MempoolChannel)
+			}
+		})
+	}
+}
+
+func TestProfHandlerAuth(t *testing.T) {
+	get := func(h http.Handler, setAuth func(r *http.Request)) int {
+		req := httptest.NewRequest("GET", "/debug/pprof/", nil)
+		if setAuth != nil {
+			setAuth(req)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, get(newProfHandler(""), nil))
+
+	h := newProfHandler("secret")
+	assert.Equal(t, http.StatusUnauthorized, get(h, nil))
+	assert.Equal(t, http.StatusUnauthorized, get(h, func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer wrong")
+	}))
+	assert.Equal(t, http.StatusOK, get(h, func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer secret")
+	}))
+	assert.Equal(t, http.StatusOK, get(h, func(r *http.Request) {
+		r.SetBasicAuth("anyone", "secret")
+	}))
+}
+
+func TestAdminHandler(t *testing.T) {
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name)) // nolint: errcheck
+		})
+	}
+	h := newAdminHandler(handler("public"), handler("admin"), "secret")
+	get := func(auth string) string {
+		req := httptest.NewRequest("GET", "/status", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	assert.Equal(t, "public", get(""))
+	assert.Equal(t, "public", get("Bearer wrong"))
+	assert.Equal(t, "admin", get("Bearer secret"))
+}
+
+func TestRateLimiter(t *testing.T) {
+	rl := newRateLimiter(0)
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		require.True(t, rl.allow("a", now))
+	}
+
+	// bursts of up to a second worth of requests
+	rl.setRate(2)
+	assert.True(t, rl.allow("a", now))
+	assert.True(t, rl.allow("a", now))
+	assert.False(t, rl.allow("a", now))
+	// other clients have their own bucket
+	assert.True(t, rl.allow("b", now))
+	// the bucket refills at the rate
+	assert.True(t, rl.allow("a", now.Add(500*time.Millisecond)))
+	assert.False(t, rl.allow("a", now.Add(500*time.Millisecond)))
+
+	// the idle clients are forgotten
+	assert.True(t, rl.allow("b", now.Add(2*time.Minute)))
+	assert.Len(t, rl.buckets, 1)
+
+	h := rl.handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	get := func() int {
+		req := httptest.NewRequest("GET", "/status", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, http.StatusOK, get())
+	assert.Equal(t, http.StatusTooManyRequests, get())
+}
+
+func TestNodeRPCRoutes(t *testing.T) {
+	config := cfg.ResetTestRoot("node_rpc_routes_test")
+	defer os.RemoveAll(config.RootDir)
+	n, err := DefaultNewNode(config, log.TestingLogger())
+	require.NoError(t, err)
+	env := n.ConfigureRPC()
+
+	n.config.RPC.Unsafe = false
+	routes, adminRoutes := n.rpcRoutes(env)
+	assert.Contains(t, routes, "status")
+	assert.NotContains(t, routes, "dial_peers")
+	assert.Nil(t, adminRoutes)
+
+	// without an admin token, unsafe_profile is not served
+	n.config.RPC.Unsafe = true
+	routes, adminRoutes = n.rpcRoutes(env)
+	assert.Contains(t, routes, "dial_peers")
+	assert.NotContains(t, routes, "unsafe_profile")
+	assert.Nil(t, adminRoutes)
+
+	n.config.RPC.AdminAuthToken = "secret"
+	routes, adminRoutes = n.rpcRoutes(env)
+	assert.NotContains(t, routes, "dial_peers")
+	assert.NotContains(t, routes, "unsafe_profile")
+	assert.Contains(t, adminRoutes, "status")
+	assert.Contains(t, adminRoutes, "unsafe_profile")
+}
+
+func TestMultiNode(t *testing.T) {
+	chainIDs := []string{"multi_chain_a", "multi_chain_b"}
+	var configs []*cfg.Config
+	for i, chainID := range chainIDs {
+		config := cfg.ResetTestRootWithChainID("node_multi_test", chainID)
+		defer os.RemoveAll(config.RootDir)
+		config.P2P.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", 36700+i)
+		config.RPC.ListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", 36702+i)
+		config.RPC.GRPCListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", 36704+i)
+		config.Instrumentation.Prometheus = true
+		config.Instrumentation.PrometheusListenAddr = ""
+		configs = append(configs, config)
+	}
+
+	mn, err := NewMultiNode(configs, nil, log.TestingLogger())
+	require.NoError(t, err)
+	require.Len(t, mn.Nodes(), 2)
+	require.NoError(t, mn.Start())
+
+	for i, chainID := range chainIDs {
+		n := mn.Node(chainID)
+		require.NotNil(t, n, chainID)
+		// the metrics of a height are recorded before its next round starts
+		roundsSub, err := n.EventBus().Subscribe(context.Background(), "node_test", types.EventQueryNewRound)
+		require.NoError(t, err)
+	wait:
+		for {
+			select {
+			case msg := <-roundsSub.Out():
+				if msg.Data().(types.EventDataNewRound).Height > 1 {
+					break wait
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatalf("timed out waiting for chain %s to produce a block", chainID)
+			}
+		}
+
+		// each chain serves its own RPC
+		c, err := rpcclient.NewJSONRPCClient(configs[i].RPC.ListenAddress)
+		require.NoError(t, err)
+		ctypes.RegisterAmino(c.Codec())
+		status := new(ctypes.ResultStatus)
+		_, err = c.Call("status", map[string]interface{}{}, status)
+		require.NoError(t, err)
+		assert.Equal(t, chainID, status.NodeInfo.Network)
+		assert.NotZero(t, status.SyncInfo.LatestBlockHeight)
+	}
+
+	// both chains' metrics are registered, distinguished by chain_id
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	heights := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "tendermint_consensus_height" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "chain_id" {
+					heights[label.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	assert.Len(t, heights, 2)
+	for _, chainID := range chainIDs {
+		assert.True(t, heights[chainID] > 1, "height of chain %s: %v", chainID, heights[chainID])
+	}
+
+	require.NoError(t, mn.Shutdown())
+	for _, n := range mn.Nodes() {
+		assert.False(t, n.IsRunning())
+	}
+}
+
+func TestMultiNodeConfigConflicts(t *testing.T) {
+	a := cfg.ResetTestRootWithChainID("node_multi_conflict_test", "a")
+	defer os.RemoveAll(a.RootDir)
+	b := cfg.ResetTestRootWithChainID("node_multi_conflict_test", "b")
+	defer os.RemoveAll(b.RootDir)
+	b.RPC.ListenAddress = ""
+	b.RPC.GRPCListenAddress = ""
+
+	// same p2p address
+	_, err := NewMultiNode([]*cfg.Config{a, b}, nil, log.TestingLogger())
+	assert.Error(t, err)
+
+	// same root directory
+	_, err = NewMultiNode([]*cfg.Config{a, a}, nil, log.TestingLogger())
+	assert.Error(t, err)
+
+	// same RPC address
+	b.P2P.ListenAddress = "tcp://127.0.0.1:36710"
+	b.RPC.ListenAddress = a.RPC.ListenAddress
+	_, err = NewMultiNode([]*cfg.Config{a, b}, nil, log.TestingLogger())
+	assert.Error(t, err)
+}
+
+func TestNodePeerSnapshot(t *testing.T) {
+	configA := cfg.ResetTestRoot("node_peer_snapshot_test")
+	defer os.RemoveAll(configA.RootDir)
+	configA.P2P.PeerSnapshotInterval = 100 * time.Millisecond
+	configA.P2P.ListenAddress = "tcp://127.0.0.1:36720"
+
+	// b follows a's chain
+	configB := cfg.ResetTestRoot("node_peer_snapshot_test")
+	defer os.RemoveAll(configB.RootDir)
+	configB.Mode = cfg.ModeFull
+	configB.P2P.ListenAddress = "tcp://127.0.0.1:36721"
+	configB.RPC.ListenAddress = ""
+	configB.RPC.GRPCListenAddress = ""
+	genesis, err := ioutil.ReadFile(configA.GenesisFile())
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(configB.GenesisFile(), genesis, 0644))
+
+	a, err := DefaultNewNode(configA, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	b, err := DefaultNewNode(configB, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+	defer b.Stop()
+
+	bAddr := p2p.IDAddressString(b.NodeInfo().ID(), "127.0.0.1:36721")
+	require.NoError(t, a.Switch().DialPeersAsync([]string{bAddr}))
+	assert.Eventually(t, func() bool {
+		peers, err := loadPeerSnapshot(configA.P2P.PeerSnapshotFile())
+		return err == nil && len(peers) == 1 && peers[0] == bAddr
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// on restart, a dials b again
+	require.NoError(t, a.Stop())
+	assert.Eventually(t, func() bool { return b.Switch().Peers().Size() == 0 }, 5*time.Second, 50*time.Millisecond)
+	a, err = DefaultNewNode(configA, log.TestingLogger())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	defer a.Stop()
+	assert.Eventually(t, func() bool { return a.Switch().Peers().Has(b.NodeInfo().ID()) },
+		10*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,111 @@
+package node
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/tempfile"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// peerSnapshot is the content of the peer snapshot file (see
+// p2p.peer_snapshot_interval).
+type peerSnapshot struct {
+	Time  time.Time `json:"time"`
+	Peers []string  `json:"peers"`
+}
+
+// savePeerSnapshot saves the addresses of the peers which have been connected
+// for at least the snapshot interval. For inbound peers, the address they
+// listen on is saved.
+func (n *Node) savePeerSnapshot() error {
+	minDuration := n.config.P2P.PeerSnapshotInterval
+	snapshot := peerSnapshot{Time: time.Now(), Peers: []string{}}
+	for _, peer := range n.sw.Peers().List() {
+		if peer.Status().Duration < minDuration {
+			continue
+		}
+		addr := peer.SocketAddr()
+		if !peer.IsOutbound() {
+			var err error
+			if addr, err = peer.NodeInfo().NetAddress(); err != nil {
+				continue
+			}
+		}
+		snapshot.Peers = append(snapshot.Peers, addr.String())
+	}
+
+	bz, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(n.config.P2P.PeerSnapshotFile(), bz, 0600)
+}
+
+// loadPeerSnapshot returns the peers of the peer snapshot file, if any.
+func loadPeerSnapshot(path string) ([]string, error) {
+	bz, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var snapshot peerSnapshot
+	if err := json.Unmarshal(bz, &snapshot); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s", path)
+	}
+	return snapshot.Peers, nil
+}
+
+// dialPeerSnapshot dials the peers of the last peer snapshot, except for
+// persistent peers, which are dialed anyway. At most max_num_outbound_peers
+// peers are dialed.
+func (n *Node) dialPeerSnapshot() error {
+	peers, err := loadPeerSnapshot(n.config.P2P.PeerSnapshotFile())
+	if err != nil {
+		return err
+	}
+	persistent := make(map[p2p.ID]bool)
+	for _, addr := range splitAndTrimEmpty(n.config.P2P.PersistentPeers, ",", " ") {
+		if netAddr, err := p2p.NewNetAddressString(addr); err == nil {
+			persistent[netAddr.ID] = true
+		}
+	}
+	var dial []string
+	for _, addr := range peers {
+		netAddr, err := p2p.NewNetAddressString(addr)
+		if err != nil || persistent[netAddr.ID] || netAddr.ID == n.nodeKey.ID() {
+			continue
+		}
+		if len(dial) == n.config.P2P.MaxNumOutboundPeers {
+			break
+		}
+		dial = append(dial, addr)
+	}
+	if len(dial) == 0 {
+		return nil
+	}
+	n.Logger.Info("Dialing peers of the last peer snapshot", "peers", dial)
+	return n.sw.DialPeersAsync(dial)
+}
+
+// peerSnapshotRoutine saves a peer snapshot every snapshot interval until the
+// node is stopped.
+func (n *Node) peerSnapshotRoutine() {
+	ticker := time.NewTicker(n.config.P2P.PeerSnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := n.savePeerSnapshot(); err != nil {
+				n.Logger.Error("Failed to save peer snapshot", "err", err)
+			}
+		case <-n.Quit():
+			return
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpcserver "github.com/tendermint/tendermint/rpc/lib/server"
+	tmtime "github.com/tendermint/tendermint/types/time"
+)
+
+// Paths of the probes served on the RPC listeners.
+const (
+	LivenessProbePath  = "/livez"
+	ReadinessProbePath = "/readyz"
+	StartupProbePath   = "/startupz"
+)
+
+func (n *Node) registerProbes(mux *http.ServeMux) {
+	mux.HandleFunc(LivenessProbePath, probeHandler(n.checkLiveness))
+	mux.HandleFunc(ReadinessProbePath, probeHandler(n.checkReadiness))
+	mux.HandleFunc(StartupProbePath, probeHandler(n.checkStartup))
+}
+
+// probeServer serves the probes only, on rpc.probe_laddr. It is started by
+// NewNode before the handshake, which may replay many blocks, so that the node
+// is reported alive, but not started up, meanwhile. Once the node is created,
+// the startup and readiness probes are answered by its checks.
+type probeServer struct {
+	srv    *http.Server
+	node   atomic.Value // *Node
+	logger log.Logger
+}
+
+func startProbeServer(addr string, logger log.Logger) (*probeServer, error) {
+	listener, err := rpcserver.Listen(addr, rpcserver.DefaultConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to listen for probes")
+	}
+	ps := &probeServer{logger: logger}
+	mux := http.NewServeMux()
+	// the server is shut down with the node, so responding is enough
+	mux.HandleFunc(LivenessProbePath, probeHandler(func() error { return nil }))
+	mux.HandleFunc(ReadinessProbePath, probeHandler(ps.check((*Node).checkReadiness)))
+	mux.HandleFunc(StartupProbePath, probeHandler(ps.check((*Node).checkStartup)))
+	ps.srv = &http.Server{Handler: mux}
+	go func() {
+		if err := ps.srv.Serve(listener); err != http.ErrServerClosed {
+			logger.Error("Probe server", "err", err)
+		}
+	}()
+	return ps, nil
+}
+
+// check returns a check failing until the node is set, and then running
+// nodeCheck.
+func (ps *probeServer) check(nodeCheck func(*Node) error) func() error {
+	return func() error {
+		n, ok := ps.node.Load().(*Node)
+		if !ok {
+			return errors.New("node is replaying blocks")
+		}
+		return nodeCheck(n)
+	}
+}
+
+func (ps *probeServer) setNode(n *Node) {
+	if ps != nil {
+		ps.node.Store(n)
+	}
+}
+
+func (ps *probeServer) stop() {
+	if ps == nil {
+		return
+	}
+	if err := ps.srv.Shutdown(context.Background()); err != nil {
+		ps.logger.Error("Probe server Shutdown", "err", err)
+	}
+}
+
+// probeHandler responds with 200 if check succeeds and with 503 and the
+// error otherwise.
+func probeHandler(check func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := check(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// checkLiveness succeeds as long as the process is able to respond, including
+// while it is replaying the WAL or syncing.
+func (n *Node) checkLiveness() error {
+	if !n.IsRunning() {
+		return errors.New("node is not running")
+	}
+	return nil
+}
+
+// checkStartup succeeds once the node has started, i.e. the WAL has been
+// replayed and all reactors are running.
+func (n *Node) checkStartup() error {
+	if atomic.LoadUint32(&n.startedUp) == 0 {
+		return errors.New("node is starting up")
+	}
+	return nil
+}
+
+// checkReadiness succeeds if the node has started, has at least
+// readiness_min_peers peers, is not fast syncing and its latest block is not
+// older than readiness_max_block_age.
+func (n *Node) checkReadiness() error {
+	if err := n.checkStartup(); err != nil {
+		return err
+	}
+	if minPeers, numPeers := n.config.RPC.ReadinessMinPeers, n.sw.Peers().Size(); numPeers < minPeers {
+		return errors.Errorf("%d peers connected, need at least %d", numPeers, minPeers)
+	}
+	if n.consensusReactor.FastSync() {
+		return errors.New("node is fast syncing")
+	}
+	if maxAge := n.config.RPC.ReadinessMaxBlockAge; maxAge > 0 {
+		height := n.blockStore.Height()
+		meta := n.blockStore.LoadBlockMeta(height)
+		if meta == nil {
+			return errors.New("no blocks yet")
+		}
+		if age := tmtime.Now().Sub(meta.Header.Time); age > maxAge {
+			return errors.Errorf("latest block %d is %v old, max %v", height, age, maxAge)
+		}
+	}
+	return nil
+}
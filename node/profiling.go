@@ -0,0 +1,56 @@
+package node
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// newProfHandler returns the handler of the profiling server. If token is not
+// empty, requests must authenticate with it, either as a bearer token or as
+// the basic auth password.
+func newProfHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	if token == "" {
+		return mux
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !tokenAuthorized(r, token) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="tendermint profiling"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// tokenAuthorized returns true if r carries token as a bearer token or as the
+// basic auth password.
+func tokenAuthorized(r *http.Request, token string) bool {
+	var given string
+	if _, password, ok := r.BasicAuth(); ok {
+		given = password
+	} else if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		given = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// newAdminHandler returns a handler passing requests authenticated with token
+// (see tokenAuthorized) to admin and all others to public.
+func newAdminHandler(public, admin http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tokenAuthorized(r, token) {
+			admin.ServeHTTP(w, r)
+			return
+		}
+		public.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter limits the rate of the RPC requests of each client IP, with a
+// token bucket per client holding up to one second worth of requests.
+type rateLimiter struct {
+	mtx       sync.Mutex
+	rate      float64 // requests per second, 0 meaning unlimited
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	return &rateLimiter{
+		rate:      rate,
+		buckets:   make(map[string]*tokenBucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// setRate sets the maximum number of requests per second of each client, 0
+// meaning unlimited. It can be changed while requests are served.
+func (rl *rateLimiter) setRate(rate float64) {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+	rl.rate = rate
+}
+
+// allow returns true if a request of client can be served at now, taking a
+// token from its bucket.
+func (rl *rateLimiter) allow(client string, now time.Time) bool {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	if rl.rate == 0 {
+		return true
+	}
+	burst := math.Max(rl.rate, 1)
+
+	// forget the clients whose buckets have refilled, as if they were new
+	if now.Sub(rl.lastPrune) > time.Minute {
+		for c, b := range rl.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*rl.rate >= burst {
+				delete(rl.buckets, c)
+			}
+		}
+		rl.lastPrune = now
+	}
+
+	b, ok := rl.buckets[client]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		rl.buckets[client] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rl.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// handler returns a handler passing the requests of the clients within the
+// rate to next, and answering the others with 429 (Too Many Requests).
+func (rl *rateLimiter) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil { // e.g. a unix socket
+			client = r.RemoteAddr
+		}
+		if !rl.allow(client, time.Now()) {
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,306 @@
+package node
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	cfg "github.com/tendermint/tendermint/config"
+	tmflags "github.com/tendermint/tendermint/libs/cli/flags"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+// SetConfigLoader sets the function used by ReloadConfig to obtain the new
+// config. By default, the config file in the node's root directory is read.
+// It must be called before the node is started.
+func (n *Node) SetConfigLoader(fn func() (*cfg.Config, error)) {
+	n.configLoader = fn
+}
+
+// SetLogLevelReloader sets the function used to apply a changed log_level.
+// Without it, changes to log_level require a restart.
+// It must be called before the node is started.
+func (n *Node) SetLogLevelReloader(fn func(logLevel string) error) {
+	n.logLevelReloader = fn
+}
+
+// ReloadConfig loads the config (see SetConfigLoader) and applies it to the
+// running node. See ApplyConfig.
+func (n *Node) ReloadConfig() (*cfg.ReloadResult, error) {
+	load := n.configLoader
+	if load == nil {
+		load = func() (*cfg.Config, error) { return cfg.LoadConfigFile(n.config.RootDir) }
+	}
+	newConfig, err := load()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load config")
+	}
+	return n.ApplyConfig(newConfig)
+}
+
+// ApplyConfig applies the fields of newConfig which can be changed at runtime
+// (see cfg.IsReloadable) and reports all other changed fields as requiring a
+// restart. Peers are only ever added: removing a persistent peer stops the
+// node from reconnecting to it, while removing an unconditional or private
+// peer ID requires a restart. Rate limits only apply to new connections.
+//
+// If an error is returned, fields of groups processed before the failing one
+// may already have been applied.
+func (n *Node) ApplyConfig(newConfig *cfg.Config) (*cfg.ReloadResult, error) {
+	n.reloadMtx.Lock()
+	defer n.reloadMtx.Unlock()
+	return n.applyConfig(newConfig)
+}
+
+// SetConfigField sets the config field with the given key to value (see
+// cfg.Config.Set) and applies it like ApplyConfig. Only reloadable fields can
+// be set. Each change is appended to the config journal (see
+// cfg.BaseConfig.JournalFile), so that it can be written to the config file
+// and survive a restart.
+func (n *Node) SetConfigField(key, value string) (*cfg.ReloadResult, error) {
+	if !cfg.IsReloadable(key) {
+		return nil, errors.Errorf("%s can't be changed at runtime", key)
+	}
+
+	n.reloadMtx.Lock()
+	defer n.reloadMtx.Unlock()
+	return n.setConfigField(key, value)
+}
+
+// SetModuleLogLevel sets the log level of the given module (e.g. "p2p"), or
+// of all other modules if module is "*", by changing log_level with
+// SetConfigField.
+func (n *Node) SetModuleLogLevel(module, level string) (*cfg.ReloadResult, error) {
+	n.reloadMtx.Lock()
+	defer n.reloadMtx.Unlock()
+
+	logLevel, err := tmflags.SetModuleLogLevel(n.config.LogLevel, module, level)
+	if err != nil {
+		return nil, err
+	}
+	return n.setConfigField("log_level", logLevel)
+}
+
+func (n *Node) setConfigField(key, value string) (*cfg.ReloadResult, error) {
+	newConfig := n.config.Copy()
+	if err := newConfig.Set(key, value); err != nil {
+		return nil, err
+	}
+	res, err := n.applyConfig(newConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Applied) == 0 && len(res.RestartRequired) == 0 {
+		return res, nil
+	}
+	entry := cfg.JournalEntry{Time: time.Now(), Key: key, Value: value}
+	if err := cfg.AppendJournal(n.config.JournalFile(), entry); err != nil {
+		return nil, errors.Wrapf(err, "changed %s, but failed to record it in the journal", key)
+	}
+	return res, nil
+}
+
+func (n *Node) applyConfig(newConfig *cfg.Config) (*cfg.ReloadResult, error) {
+	if err := newConfig.ValidateBasic(); err != nil {
+		return nil, errors.Wrap(err, "invalid config")
+	}
+
+	res := &cfg.ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	changed := make(map[string]bool)
+	for _, key := range cfg.ChangedFields(n.config, newConfig) {
+		if cfg.IsReloadable(key) {
+			changed[key] = true
+		} else {
+			res.RestartRequired = append(res.RestartRequired, key)
+		}
+	}
+	// applied marks the given changed keys as applied.
+	applied := func(keys ...string) {
+		for _, key := range keys {
+			if changed[key] {
+				res.Applied = append(res.Applied, key)
+			}
+		}
+	}
+	restartRequired := func(key string) {
+		res.RestartRequired = append(res.RestartRequired, key)
+	}
+
+	if changed["log_level"] {
+		if n.logLevelReloader == nil {
+			restartRequired("log_level")
+		} else {
+			if err := n.logLevelReloader(newConfig.LogLevel); err != nil {
+				return nil, errors.Wrap(err, "failed to apply log_level")
+			}
+			n.config.LogLevel = newConfig.LogLevel
+			applied("log_level")
+		}
+	}
+
+	if hasPrefix(changed, "consensus.") {
+		n.consensusState.SetTimeouts(newConfig.Consensus)
+		for key := range changed {
+			if strings.HasPrefix(key, "consensus.") {
+				applied(key)
+			}
+		}
+	}
+
+	if changed["rpc.max_subscription_clients"] || changed["rpc.max_subscriptions_per_client"] {
+		n.config.RPC.MaxSubscriptionClients = newConfig.RPC.MaxSubscriptionClients
+		n.config.RPC.MaxSubscriptionsPerClient = newConfig.RPC.MaxSubscriptionsPerClient
+		n.ConfigureRPC().SetConfig(*n.config.RPC)
+		applied("rpc.max_subscription_clients", "rpc.max_subscriptions_per_client")
+	}
+
+	// only the new subscriptions get the new buffer size and policy
+	if changed["rpc.subscription_buffer_size"] || changed["rpc.slow_client_policy"] {
+		n.config.RPC.SubscriptionBufferSize = newConfig.RPC.SubscriptionBufferSize
+		n.config.RPC.SlowClientPolicy = newConfig.RPC.SlowClientPolicy
+		n.ConfigureRPC().SetConfig(*n.config.RPC)
+		applied("rpc.subscription_buffer_size", "rpc.slow_client_policy")
+	}
+
+	if changed["rpc.max_requests_per_second"] {
+		n.rpcLimiter.setRate(newConfig.RPC.MaxRequestsPerSecond)
+		n.config.RPC.MaxRequestsPerSecond = newConfig.RPC.MaxRequestsPerSecond
+		applied("rpc.max_requests_per_second")
+	}
+
+	if changed["mempool.cache_size"] {
+		mem, ok := n.mempool.(interface{ SetCacheSize(int) error })
+		if !ok || mem.SetCacheSize(newConfig.Mempool.CacheSize) != nil {
+			restartRequired("mempool.cache_size")
+		} else {
+			n.config.Mempool.CacheSize = newConfig.Mempool.CacheSize
+			applied("mempool.cache_size")
+		}
+	}
+
+	if changed["mempool.broadcast_fanout"] {
+		if n.mempoolReactor != nil {
+			n.mempoolReactor.SetBroadcastFanout(newConfig.Mempool.BroadcastFanout)
+			n.config.Mempool.BroadcastFanout = newConfig.Mempool.BroadcastFanout
+			applied("mempool.broadcast_fanout")
+		} else {
+			restartRequired("mempool.broadcast_fanout")
+		}
+	}
+
+	if changed["fastsync.max_pending_requests_per_peer"] {
+		if r, ok := n.bcReactor.(interface{ SetMaxPendingRequestsPerPeer(int) }); ok {
+			r.SetMaxPendingRequestsPerPeer(newConfig.FastSync.MaxPendingRequestsPerPeer)
+			n.config.FastSync.MaxPendingRequestsPerPeer = newConfig.FastSync.MaxPendingRequestsPerPeer
+			applied("fastsync.max_pending_requests_per_peer")
+		} else {
+			restartRequired("fastsync.max_pending_requests_per_peer")
+		}
+	}
+
+	if changed["fastsync.max_buffer_bytes"] {
+		if r, ok := n.bcReactor.(interface{ SetMaxBufferBytes(int64) }); ok {
+			r.SetMaxBufferBytes(newConfig.FastSync.MaxBufferBytes)
+			n.config.FastSync.MaxBufferBytes = newConfig.FastSync.MaxBufferBytes
+			applied("fastsync.max_buffer_bytes")
+		} else {
+			restartRequired("fastsync.max_buffer_bytes")
+		}
+	}
+
+	if changed["fastsync.max_peer_rate"] {
+		if r, ok := n.bcReactor.(interface{ SetMaxPeerRate(int64) }); ok {
+			r.SetMaxPeerRate(newConfig.FastSync.MaxPeerRate)
+			n.config.FastSync.MaxPeerRate = newConfig.FastSync.MaxPeerRate
+			applied("fastsync.max_peer_rate")
+		} else {
+			restartRequired("fastsync.max_peer_rate")
+		}
+	}
+
+	if changed["p2p.persistent_peers"] {
+		oldPeers := splitAndTrimEmpty(n.config.P2P.PersistentPeers, ",", " ")
+		newPeers := splitAndTrimEmpty(newConfig.P2P.PersistentPeers, ",", " ")
+		if err := n.sw.AddPersistentPeers(newPeers); err != nil {
+			return nil, errors.Wrap(err, "could not add peers from persistent_peers field")
+		}
+		if added := missingFrom(newPeers, oldPeers); len(added) > 0 {
+			if err := n.sw.DialPeersAsync(added); err != nil {
+				return nil, errors.Wrap(err, "could not dial peers from persistent_peers field")
+			}
+		}
+		n.config.P2P.PersistentPeers = newConfig.P2P.PersistentPeers
+		applied("p2p.persistent_peers")
+	}
+
+	if changed["p2p.unconditional_peer_ids"] {
+		oldIDs := splitAndTrimEmpty(n.config.P2P.UnconditionalPeerIDs, ",", " ")
+		newIDs := splitAndTrimEmpty(newConfig.P2P.UnconditionalPeerIDs, ",", " ")
+		if len(missingFrom(oldIDs, newIDs)) > 0 {
+			restartRequired("p2p.unconditional_peer_ids")
+		} else {
+			if err := n.sw.AddUnconditionalPeerIDs(missingFrom(newIDs, oldIDs)); err != nil {
+				return nil, errors.Wrap(err, "could not add peer ids from unconditional_peer_ids field")
+			}
+			n.config.P2P.UnconditionalPeerIDs = newConfig.P2P.UnconditionalPeerIDs
+			applied("p2p.unconditional_peer_ids")
+		}
+	}
+
+	if changed["p2p.private_peer_ids"] {
+		oldIDs := splitAndTrimEmpty(n.config.P2P.PrivatePeerIDs, ",", " ")
+		newIDs := splitAndTrimEmpty(newConfig.P2P.PrivatePeerIDs, ",", " ")
+		if len(missingFrom(oldIDs, newIDs)) > 0 {
+			restartRequired("p2p.private_peer_ids")
+		} else {
+			n.addrBook.AddPrivateIDs(missingFrom(newIDs, oldIDs))
+			n.config.P2P.PrivatePeerIDs = newConfig.P2P.PrivatePeerIDs
+			applied("p2p.private_peer_ids")
+		}
+	}
+
+	if changed["p2p.send_rate"] || changed["p2p.recv_rate"] || changed["p2p.channel_priorities"] ||
+		changed["p2p.channel_send_rates"] || changed["p2p.mempool_yields_to_consensus"] {
+		n.config.P2P.SendRate = newConfig.P2P.SendRate
+		n.config.P2P.RecvRate = newConfig.P2P.RecvRate
+		n.config.P2P.ChannelPriorities = newConfig.P2P.ChannelPriorities
+		n.config.P2P.ChannelSendRates = newConfig.P2P.ChannelSendRates
+		n.config.P2P.MempoolYieldsToConsensus = newConfig.P2P.MempoolYieldsToConsensus
+		n.transport.SetMConnConfig(p2p.MConnConfig(n.config.P2P))
+		applied("p2p.send_rate", "p2p.recv_rate", "p2p.channel_priorities", "p2p.channel_send_rates",
+			"p2p.mempool_yields_to_consensus")
+	}
+
+	sort.Strings(res.Applied)
+	sort.Strings(res.RestartRequired)
+	n.Logger.Info("Reloaded config",
+		"applied", res.Applied,
+		"restart_required", res.RestartRequired)
+	return res, nil
+}
+
+func hasPrefix(keys map[string]bool, prefix string) bool {
+	for key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// missingFrom returns the elements of a which are not in b.
+func missingFrom(a, b []string) []string {
+	inB := make(map[string]struct{}, len(b))
+	for _, s := range b {
+		inB[s] = struct{}{}
+	}
+	var missing []string
+	for _, s := range a {
+		if _, ok := inB[s]; !ok {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,233 @@
+package node
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/nat"
+	"github.com/tendermint/tendermint/p2p/pex"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/state/txindex/null"
+)
+
+// SeedNode is a lightweight node which only runs the PEX reactor in seed
+// mode: it crawls the network to fill its address book, serves addresses to
+// the nodes which connect to it, and optionally answers DNS queries with the
+// best addresses of its book (see pex.DNSSeed).
+//
+// Unlike a Node in seed mode, it has no blockstore, state, mempool nor ABCI
+// application: only the chain ID is read from the genesis doc.
+type SeedNode struct {
+	service.BaseService
+
+	config  *cfg.Config
+	nodeKey *p2p.NodeKey
+
+	transport  nodeTransport
+	sw         *p2p.Switch
+	addrBook   pex.AddrBook
+	pexReactor *pex.Reactor
+	dnsSeed    *pex.DNSSeed // nil if disabled
+	natMapper  *nat.Mapper  // nil unless UPnP, NAT-PMP or STUN is enabled
+
+	prometheusSrv *http.Server
+}
+
+// NewSeedNode returns a new SeedNode. The mode of the config must be "seed",
+// and the PEX reactor enabled.
+func NewSeedNode(config *cfg.Config,
+	nodeKey *p2p.NodeKey,
+	genesisDocProvider GenesisDocProvider,
+	logger log.Logger) (*SeedNode, error) {
+
+	if config.Mode != cfg.ModeSeed {
+		return nil, errors.Errorf("mode must be %q, got %q", cfg.ModeSeed, config.Mode)
+	}
+	if !config.P2P.PexReactor {
+		return nil, errors.New("seed nodes require the PEX reactor")
+	}
+	if config.FilterPeers {
+		return nil, errors.New("seed nodes can't filter peers, as they have no ABCI application")
+	}
+
+	genDoc, err := genesisDocProvider()
+	if err != nil {
+		return nil, err
+	}
+	state, err := sm.MakeGenesisState(genDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeInfo, err := makeNodeInfo(config, nodeKey, &null.TxIndex{}, genDoc, state)
+	if err != nil {
+		return nil, err
+	}
+	// there's no RPC server either
+	if ni, ok := nodeInfo.(p2p.DefaultNodeInfo); ok {
+		ni.Other.RPCAddress = ""
+		nodeInfo = ni
+	}
+
+	transport, peerFilters, err := createTransport(config, nodeInfo, nodeKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, p2pMetrics, _, _, _ := DefaultMetricsProvider(config.Instrumentation)(genDoc.ChainID)
+	p2pLogger := logger.With("module", "p2p")
+	sw := createSwitch(
+		config, transport, p2pMetrics, peerFilters, nil, nil, nil,
+		nil, nil, nodeInfo, nodeKey, p2pLogger,
+	)
+
+	err = sw.AddPersistentPeers(splitAndTrimEmpty(config.P2P.PersistentPeers, ",", " "))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not add peers from persistent_peers field")
+	}
+
+	err = sw.AddUnconditionalPeerIDs(splitAndTrimEmpty(config.P2P.UnconditionalPeerIDs, ",", " "))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not add peer ids from unconditional_peer_ids field")
+	}
+
+	addrBook, err := createAddrBookAndSetOnSwitch(config, sw, p2pLogger, nodeKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create addrbook")
+	}
+	if err := createBanListAndSetOnSwitch(config, sw); err != nil {
+		return nil, errors.Wrap(err, "could not load the ban list")
+	}
+	pexReactor := createPEXReactorAndAddToSwitch(addrBook, config, sw, logger)
+
+	var dnsSeed *pex.DNSSeed
+	if config.P2P.SeedDNSListenAddress != "" {
+		dnsSeed, err = pex.NewDNSSeed(addrBook, config.P2P.SeedDNSDomain, config.P2P.SeedDNSListenAddress)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not create the DNS seed")
+		}
+		dnsSeed.SetLogger(logger.With("module", "dns"))
+	}
+
+	node := &SeedNode{
+		config:     config,
+		nodeKey:    nodeKey,
+		transport:  transport,
+		sw:         sw,
+		addrBook:   addrBook,
+		pexReactor: pexReactor,
+		dnsSeed:    dnsSeed,
+	}
+	node.BaseService = *service.NewBaseService(logger, "SeedNode", node)
+	return node, nil
+}
+
+// DefaultNewSeedNode returns a SeedNode for the given config, with the node
+// key and the genesis doc of the config.
+func DefaultNewSeedNode(config *cfg.Config, logger log.Logger) (*SeedNode, error) {
+	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	if err != nil {
+		return nil, err
+	}
+	return NewSeedNode(config, nodeKey, DefaultGenesisDocProviderFunc(config), logger)
+}
+
+// OnStart starts the SeedNode. It implements service.Service.
+func (n *SeedNode) OnStart() error {
+	// Add private IDs to addrbook to block those peers being added
+	n.addrBook.AddPrivateIDs(splitAndTrimEmpty(n.config.P2P.PrivatePeerIDs, ",", " "))
+
+	if n.config.Instrumentation.Prometheus &&
+		n.config.Instrumentation.PrometheusListenAddr != "" {
+		n.prometheusSrv = startPrometheusServer(n.config.Instrumentation, n.Logger)
+	}
+
+	addr, err := p2p.NewNetAddressString(p2p.IDAddressString(n.nodeKey.ID(), n.config.P2P.ListenAddress))
+	if err != nil {
+		return err
+	}
+	if err := n.transport.Listen(*addr); err != nil {
+		return err
+	}
+
+	n.natMapper = createNATMapper(n.config.P2P, int(n.transport.NetAddress().Port), n.setExternalAddress, n.Logger)
+	if n.natMapper != nil {
+		if err := n.natMapper.Start(); err != nil {
+			return err
+		}
+	}
+
+	// Start the switch, which starts the PEX reactor and the address book.
+	if err := n.sw.Start(); err != nil {
+		return err
+	}
+
+	if n.dnsSeed != nil {
+		if err := n.dnsSeed.Start(); err != nil {
+			return errors.Wrap(err, "could not start the DNS seed")
+		}
+	}
+
+	err = n.sw.DialPeersAsync(splitAndTrimEmpty(n.config.P2P.PersistentPeers, ",", " "))
+	if err != nil {
+		return errors.Wrap(err, "could not dial peers from persistent_peers field")
+	}
+	return nil
+}
+
+// OnStop stops the SeedNode. It implements service.Service.
+func (n *SeedNode) OnStop() {
+	if n.dnsSeed != nil {
+		if err := n.dnsSeed.Stop(); err != nil {
+			n.Logger.Error("Error stopping the DNS seed", "err", err)
+		}
+	}
+	if err := n.sw.Stop(); err != nil {
+		n.Logger.Error("Error stopping switch", "err", err)
+	}
+	if n.natMapper != nil {
+		if err := n.natMapper.Stop(); err != nil {
+			n.Logger.Error("Error stopping the NAT mapper", "err", err)
+		}
+	}
+	if err := n.transport.Close(); err != nil {
+		n.Logger.Error("Error closing transport", "err", err)
+	}
+	if n.prometheusSrv != nil {
+		if err := n.prometheusSrv.Shutdown(context.Background()); err != nil {
+			n.Logger.Error("Prometheus HTTP server Shutdown", "err", err)
+		}
+	}
+}
+
+// Switch returns the SeedNode's Switch.
+func (n *SeedNode) Switch() *p2p.Switch {
+	return n.sw
+}
+
+// AddrBook returns the SeedNode's address book.
+func (n *SeedNode) AddrBook() pex.AddrBook {
+	return n.addrBook
+}
+
+// PEXReactor returns the SeedNode's PEX reactor.
+func (n *SeedNode) PEXReactor() *pex.Reactor {
+	return n.pexReactor
+}
+
+// DNSSeed returns the SeedNode's DNS seed, or nil if it doesn't answer DNS
+// queries.
+func (n *SeedNode) DNSSeed() *pex.DNSSeed {
+	return n.dnsSeed
+}
+
+// NodeInfo returns the SeedNode's NodeInfo.
+func (n *SeedNode) NodeInfo() p2p.NodeInfo {
+	return n.sw.NodeInfo()
+}
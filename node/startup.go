@@ -0,0 +1,123 @@
+package node
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/tempfile"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// startupReportFile is the file, relative to the data directory, the startup
+// report is written to once the node has started.
+const startupReportFile = "startup_report.json"
+
+// StartupStep is a step of the node's startup.
+type StartupStep struct {
+	// Name of the step, e.g. "db.blockstore" or "reactor.CONSENSUS".
+	Name     string        `json:"name"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	// Details of the step, e.g. the heights involved.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// StartupReport lists the steps of the node's startup in the order they
+// finished, so that a slow start can be attributed to a subsystem: opening
+// the databases, the handshake with the app (which replays blocks the app is
+// missing), starting each reactor (the consensus reactor replays the WAL) and
+// so on.
+type StartupReport struct {
+	Steps []StartupStep `json:"steps"`
+	// Total time from the node's creation until it started.
+	Total time.Duration `json:"total"`
+}
+
+// startupRecorder records the steps of the startup, logging each as it
+// finishes, so that the last step logged points to the culprit of a hanging
+// start.
+type startupRecorder struct {
+	mtx    sync.Mutex
+	start  time.Time
+	report StartupReport
+	logger log.Logger
+}
+
+func newStartupRecorder(logger log.Logger) *startupRecorder {
+	return &startupRecorder{
+		start:  time.Now(),
+		report: StartupReport{Steps: []StartupStep{}},
+		logger: logger,
+	}
+}
+
+// record records a step which began at start and finished now.
+func (r *startupRecorder) record(name string, start time.Time, details map[string]interface{}) {
+	r.recordStep(StartupStep{Name: name, Start: start, Duration: time.Since(start), Details: details})
+}
+
+func (r *startupRecorder) recordStep(step StartupStep) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.report.Steps = append(r.report.Steps, step)
+
+	keyvals := []interface{}{"step", step.Name, "took", step.Duration}
+	keys := make([]string, 0, len(step.Details))
+	for k := range step.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		keyvals = append(keyvals, k, step.Details[k])
+	}
+	r.logger.Info("Startup step", keyvals...)
+}
+
+// finish sets the total startup time and returns the report.
+func (r *startupRecorder) finish() StartupReport {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.report.Total = time.Since(r.start)
+	return r.copy()
+}
+
+func (r *startupRecorder) copy() StartupReport {
+	report := r.report
+	report.Steps = append([]StartupStep{}, r.report.Steps...)
+	return report
+}
+
+// timedDBProvider wraps dbProvider, recording the time spent opening each
+// database as the step "db.<ID>".
+func (r *startupRecorder) timedDBProvider(dbProvider DBProvider) DBProvider {
+	return func(ctx *DBContext) (dbm.DB, error) {
+		start := time.Now()
+		db, err := dbProvider(ctx)
+		if err == nil {
+			r.record("db."+ctx.ID, start, nil)
+		}
+		return db, err
+	}
+}
+
+// StartupReport returns the steps of the node's startup recorded so far. Once
+// the node has started, the report is also written to startup_report.json in
+// the data directory.
+func (n *Node) StartupReport() StartupReport {
+	n.startup.mtx.Lock()
+	defer n.startup.mtx.Unlock()
+	return n.startup.copy()
+}
+
+// writeStartupReport writes the report to the data directory.
+func (n *Node) writeStartupReport(report StartupReport) error {
+	bz, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(filepath.Join(n.config.DBDir(), startupReportFile), bz, 0644)
+}
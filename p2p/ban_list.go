@@ -0,0 +1,165 @@
+package p2p
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/tempfile"
+)
+
+// Ban is the ban of a peer, which the switch neither accepts nor dials until
+// it expires.
+type Ban struct {
+	ID       ID        `json:"id"`
+	Reason   string    `json:"reason"`
+	BannedAt time.Time `json:"banned_at"`
+	Expires  time.Time `json:"expires"`
+}
+
+// BanList keeps the bans of peers, saving them to a file, if any, so that they
+// outlive restarts. Expired bans are forgotten. It is safe for concurrent use.
+type BanList struct {
+	mtx      sync.Mutex
+	filePath string // empty if the bans are kept in memory only
+	bans     map[ID]Ban
+
+	now func() time.Time // for testing
+}
+
+// NewBanList returns an empty BanList, kept in memory only.
+func NewBanList() *BanList {
+	return &BanList{
+		bans: make(map[ID]Ban),
+		now:  time.Now,
+	}
+}
+
+// LoadBanList returns the BanList saved to filePath, which is created on the
+// first ban if it doesn't exist.
+func LoadBanList(filePath string) (*BanList, error) {
+	bl := NewBanList()
+	bl.filePath = filePath
+
+	jsonBytes, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return bl, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var bans []Ban
+	if err := json.Unmarshal(jsonBytes, &bans); err != nil {
+		return nil, fmt.Errorf("error reading ban list from %v: %v", filePath, err)
+	}
+	now := bl.now()
+	for _, ban := range bans {
+		if now.Before(ban.Expires) {
+			bl.bans[ban.ID] = ban
+		}
+	}
+	return bl, nil
+}
+
+// Ban bans the peer for the given duration, replacing any previous ban.
+func (bl *BanList) Ban(id ID, duration time.Duration, reason string) (Ban, error) {
+	if err := validateID(id); err != nil {
+		return Ban{}, errors.Wrap(err, "wrong ID")
+	}
+	if duration <= 0 {
+		return Ban{}, errors.New("duration must be positive")
+	}
+
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	now := bl.now()
+	ban := Ban{
+		ID:       id,
+		Reason:   reason,
+		BannedAt: now,
+		Expires:  now.Add(duration),
+	}
+	bl.bans[id] = ban
+	return ban, bl.save()
+}
+
+// Unban lifts the ban of the peer. It returns false if the peer isn't banned.
+func (bl *BanList) Unban(id ID) (bool, error) {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	if _, ok := bl.get(id); !ok {
+		return false, nil
+	}
+	delete(bl.bans, id)
+	return true, bl.save()
+}
+
+// Get returns the ban of the peer, if it's banned.
+func (bl *BanList) Get(id ID) (Ban, bool) {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+	return bl.get(id)
+}
+
+// List returns the bans which haven't expired, the earliest to expire first.
+func (bl *BanList) List() []Ban {
+	bl.mtx.Lock()
+	defer bl.mtx.Unlock()
+
+	now := bl.now()
+	bans := make([]Ban, 0, len(bl.bans))
+	for _, ban := range bl.bans {
+		if now.Before(ban.Expires) {
+			bans = append(bans, ban)
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Expires.Before(bans[j].Expires)
+	})
+	return bans
+}
+
+// get must be called with mtx held.
+func (bl *BanList) get(id ID) (Ban, bool) {
+	ban, ok := bl.bans[id]
+	if !ok {
+		return Ban{}, false
+	}
+	if !bl.now().Before(ban.Expires) {
+		delete(bl.bans, id)
+		return Ban{}, false
+	}
+	return ban, true
+}
+
+// save writes the bans which haven't expired to the file, if any. It must be
+// called with mtx held.
+func (bl *BanList) save() error {
+	now := bl.now()
+	bans := make([]Ban, 0, len(bl.bans))
+	for id, ban := range bl.bans {
+		if !now.Before(ban.Expires) {
+			delete(bl.bans, id)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	if bl.filePath == "" {
+		return nil
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].ID < bans[j].ID })
+
+	jsonBytes, err := json.MarshalIndent(bans, "", "\t")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(bl.filePath, jsonBytes, 0644)
+}
@@ -0,0 +1,62 @@
+package p2p
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ban_list")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "banned_peers.json")
+
+	bl, err := LoadBanList(filePath)
+	require.NoError(t, err)
+	now := time.Now()
+	bl.now = func() time.Time { return now }
+
+	id1, id2 := newMockPeer(nil).ID(), newMockPeer(nil).ID()
+	_, err = bl.Ban("not an ID", time.Hour, "")
+	assert.Error(t, err)
+	_, err = bl.Ban(id1, 0, "")
+	assert.Error(t, err)
+
+	ban1, err := bl.Ban(id1, time.Hour, "spam")
+	require.NoError(t, err)
+	assert.Equal(t, Ban{ID: id1, Reason: "spam", BannedAt: now, Expires: now.Add(time.Hour)}, ban1)
+	ban2, err := bl.Ban(id2, time.Minute, "invalid block")
+	require.NoError(t, err)
+
+	ban, ok := bl.Get(id1)
+	assert.True(t, ok)
+	assert.Equal(t, ban1, ban)
+	assert.Equal(t, []Ban{ban2, ban1}, bl.List())
+
+	// the bans are saved, and the expired ones forgotten
+	loaded, err := LoadBanList(filePath)
+	require.NoError(t, err)
+	assert.Len(t, loaded.List(), 2)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = bl.Get(id2)
+	assert.False(t, ok)
+	assert.Len(t, bl.List(), 1)
+
+	unbanned, err := bl.Unban(id1)
+	require.NoError(t, err)
+	assert.True(t, unbanned)
+	unbanned, err = bl.Unban(id1)
+	require.NoError(t, err)
+	assert.False(t, unbanned)
+
+	loaded, err = LoadBanList(filePath)
+	require.NoError(t, err)
+	assert.Empty(t, loaded.List())
+}
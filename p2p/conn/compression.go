@@ -0,0 +1,109 @@
+package conn
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Every msg of a compressed channel (see MConnConfig.CompressedChannels) is
+// prefixed with its encoding: msgs of at least MConnConfig.CompressionMinSize
+// bytes are compressed with zstd, unless it doesn't make them smaller, and
+// the others are sent as they are.
+const (
+	msgEncodingRaw  = byte(0x00)
+	msgEncodingZstd = byte(0x01)
+)
+
+var (
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+
+	// decoders by the maximum size of the msgs they decode, i.e. the
+	// RecvMessageCapacity of the channels, so that a msg can't be
+	// decompressed past the capacity of its channel
+	zstdDecodersMtx sync.Mutex
+	zstdDecoders    = make(map[int]*zstd.Decoder)
+)
+
+// encodeMsg returns msgBytes prefixed with their encoding, compressed if they
+// are at least minSize bytes.
+func encodeMsg(msgBytes []byte, minSize int) []byte {
+	if len(msgBytes) >= minSize {
+		encoded := make([]byte, 1, 1+len(msgBytes))
+		encoded[0] = msgEncodingZstd
+		encoded = getZstdEncoder().EncodeAll(msgBytes, encoded)
+		if len(encoded) < 1+len(msgBytes) {
+			return encoded
+		}
+	}
+	encoded := make([]byte, 1+len(msgBytes))
+	encoded[0] = msgEncodingRaw
+	copy(encoded[1:], msgBytes)
+	return encoded
+}
+
+// decodeMsg returns the msg encoded by encodeMsg, failing if it's larger than
+// maxSize bytes once decompressed. A msg which wasn't compressed shares the
+// bytes of encoded.
+func decodeMsg(encoded []byte, maxSize int) ([]byte, error) {
+	if len(encoded) == 0 {
+		return nil, errors.New("msg without encoding")
+	}
+	switch encoded[0] {
+	case msgEncodingRaw:
+		return encoded[1:], nil
+	case msgEncodingZstd:
+		decoder, err := getZstdDecoder(maxSize)
+		if err != nil {
+			return nil, err
+		}
+		msgBytes, err := decoder.DecodeAll(encoded[1:], nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "can't decompress msg")
+		}
+		if len(msgBytes) > maxSize {
+			return nil, fmt.Errorf("decompressed message exceeds available capacity: %v < %v", maxSize, len(msgBytes))
+		}
+		return msgBytes, nil
+	default:
+		return nil, fmt.Errorf("unknown msg encoding %X", encoded[0])
+	}
+}
+
+func getZstdEncoder() *zstd.Encoder {
+	zstdEncoderOnce.Do(func() {
+		// the default level compresses about as well as zlib, at a fraction
+		// of its cost. Single segment frames have a window of the size of
+		// the msg, which the decoder of the channel accepts.
+		encoder, err := zstd.NewWriter(nil, zstd.WithSingleSegment(true))
+		if err != nil {
+			panic(err)
+		}
+		zstdEncoder = encoder
+	})
+	return zstdEncoder
+}
+
+func getZstdDecoder(maxSize int) (*zstd.Decoder, error) {
+	zstdDecodersMtx.Lock()
+	defer zstdDecodersMtx.Unlock()
+
+	if decoder, ok := zstdDecoders[maxSize]; ok {
+		return decoder, nil
+	}
+	// the window of a frame is at least zstd.MinWindowSize, even if the msg
+	// is smaller
+	maxMemory := uint64(maxSize)
+	if maxMemory < zstd.MinWindowSize {
+		maxMemory = zstd.MinWindowSize
+	}
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxMemory))
+	if err != nil {
+		return nil, err
+	}
+	zstdDecoders[maxSize] = decoder
+	return decoder, nil
+}
@@ -44,6 +44,9 @@ const (
 	defaultSendTimeout         = 10 * time.Second
 	defaultPingInterval        = 60 * time.Second
 	defaultPongTimeout         = 45 * time.Second
+
+	// how often the channels which exceeded their send rate are checked again
+	channelSendRateRecheck = 20 * time.Millisecond
 )
 
 type receiveCbFunc func(chID byte, msgBytes []byte)
@@ -85,6 +88,7 @@ type MConnection struct {
 	pong          chan struct{}
 	channels      []*Channel
 	channelsIdx   map[byte]*Channel
+	yieldTo       []*Channel
 	onReceive     receiveCbFunc
 	onError       errorCbFunc
 	errored       uint32
@@ -105,9 +109,17 @@ type MConnection struct {
 	flushTimer *timer.ThrottleTimer // flush writes as necessary but throttled.
 	pingTimer  *time.Ticker         // send pings periodically
 
+	// wake the sendRoutine up once channels over their send rate can send again
+	sendRateTimer *timer.ThrottleTimer
+	// set by FlushStop, which sends the pending msgs regardless of the send
+	// rates of their channels
+	flushing bool
+
 	// close conn if pong is not received in pongTimeout
 	pongTimer     *time.Timer
 	pongTimeoutCh chan bool // true - timeout, false - peer sent pong
+	pingSent      time.Time // when the last ping was sent, for the round trip time
+	rtt           int64     // atomic, round trip time of the last ping
 
 	chStatsTimer *time.Ticker // update channel stats periodically
 
@@ -132,6 +144,24 @@ type MConnConfig struct {
 
 	// Maximum wait time for pongs
 	PongTimeout time.Duration `mapstructure:"pong_timeout"`
+
+	// Priorities of channels, overriding those of their descriptors
+	ChannelPriorities map[byte]int `mapstructure:"channel_priorities"`
+
+	// Rates at which channels can send, in bytes/second. SendRate still
+	// applies to the channels altogether.
+	ChannelSendRates map[byte]int64 `mapstructure:"channel_send_rates"`
+
+	// Channels which don't send while any of the YieldTo channels has msgs
+	// waiting to be sent
+	YieldingChannels []byte `mapstructure:"yielding_channels"`
+	YieldTo          []byte `mapstructure:"yield_to"`
+
+	// Channels on which the msgs of at least CompressionMinSize bytes are
+	// compressed with zstd. As every msg of these channels is prefixed with
+	// its encoding, both sides of the connection must agree on them.
+	CompressedChannels []byte `mapstructure:"compressed_channels"`
+	CompressionMinSize int    `mapstructure:"compression_min_size"`
 }
 
 // DefaultMConnConfig returns the default config.
@@ -192,13 +222,37 @@ func NewMConnectionWithConfig(
 	var channels = []*Channel{}
 
 	for _, desc := range chDescs {
-		channel := newChannel(mconn, *desc)
+		chDesc := *desc
+		if priority, ok := config.ChannelPriorities[chDesc.ID]; ok {
+			chDesc.Priority = priority
+		}
+		channel := newChannel(mconn, chDesc)
+		if rate, ok := config.ChannelSendRates[chDesc.ID]; ok && rate > 0 {
+			channel.sendRate = rate
+			channel.sendMonitor = flow.New(0, 0)
+		}
 		channelsIdx[channel.desc.ID] = channel
 		channels = append(channels, channel)
 	}
 	mconn.channels = channels
 	mconn.channelsIdx = channelsIdx
 
+	for _, chID := range config.YieldingChannels {
+		if channel, ok := channelsIdx[chID]; ok {
+			channel.yields = true
+		}
+	}
+	for _, chID := range config.YieldTo {
+		if channel, ok := channelsIdx[chID]; ok {
+			mconn.yieldTo = append(mconn.yieldTo, channel)
+		}
+	}
+	for _, chID := range config.CompressedChannels {
+		if channel, ok := channelsIdx[chID]; ok {
+			channel.compressed = true
+		}
+	}
+
 	mconn.BaseService = *service.NewBaseService(nil, "MConnection", mconn)
 
 	// maxPacketMsgSize() is a bit heavy, so call just once
@@ -220,6 +274,7 @@ func (c *MConnection) OnStart() error {
 		return err
 	}
 	c.flushTimer = timer.NewThrottleTimer("flush", c.config.FlushThrottle)
+	c.sendRateTimer = timer.NewThrottleTimer("sendRate", channelSendRateRecheck)
 	c.pingTimer = time.NewTicker(c.config.PingInterval)
 	c.pongTimeoutCh = make(chan bool, 1)
 	c.chStatsTimer = time.NewTicker(updateStats)
@@ -254,6 +309,7 @@ func (c *MConnection) stopServices() (alreadyStopped bool) {
 
 	c.BaseService.OnStop()
 	c.flushTimer.Stop()
+	c.sendRateTimer.Stop()
 	c.pingTimer.Stop()
 	c.chStatsTimer.Stop()
 
@@ -281,6 +337,7 @@ func (c *MConnection) FlushStop() {
 		// Send and flush all pending msgs.
 		// Since sendRoutine has exited, we can call this
 		// safely
+		c.flushing = true
 		eof := c.sendSomePacketMsgs()
 		for !eof {
 			eof = c.sendSomePacketMsgs()
@@ -358,6 +415,9 @@ func (c *MConnection) Send(chID byte, msgBytes []byte) bool {
 		return false
 	}
 
+	if channel.compressed {
+		msgBytes = encodeMsg(msgBytes, c.config.CompressionMinSize)
+	}
 	success := channel.sendBytes(msgBytes)
 	if success {
 		// Wake up sendRoutine if necessary
@@ -387,6 +447,9 @@ func (c *MConnection) TrySend(chID byte, msgBytes []byte) bool {
 		return false
 	}
 
+	if channel.compressed {
+		msgBytes = encodeMsg(msgBytes, c.config.CompressionMinSize)
+	}
 	ok = channel.trySendBytes(msgBytes)
 	if ok {
 		// Wake up sendRoutine if necessary
@@ -447,11 +510,17 @@ FOR_LOOP:
 				}
 			})
 			c.flush()
+			c.pingSent = time.Now()
 		case timeout := <-c.pongTimeoutCh:
 			if timeout {
 				c.Logger.Debug("Pong timeout")
 				err = errors.New("pong timeout")
 			} else {
+				if c.pongTimer != nil {
+					rtt := time.Since(c.pingSent)
+					c.recvMonitor.RecordLatency(rtt)
+					atomic.StoreInt64(&c.rtt, int64(rtt))
+				}
 				c.stopPongTimer()
 			}
 		case <-c.pong:
@@ -464,6 +533,11 @@ FOR_LOOP:
 			c.flush()
 		case <-c.quitSendRoutine:
 			break FOR_LOOP
+		case <-c.sendRateTimer.Ch:
+			select {
+			case c.send <- struct{}{}:
+			default:
+			}
 		case <-c.send:
 			// Send some PacketMsgs
 			eof := c.sendSomePacketMsgs()
@@ -508,17 +582,36 @@ func (c *MConnection) sendSomePacketMsgs() bool {
 	return false
 }
 
-// Returns true if messages from channels were exhausted.
+// Returns true if messages from channels were exhausted, or if the channels
+// with pending messages are over their send rate (in which case the
+// sendRoutine is woken up again later).
 func (c *MConnection) sendPacketMsg() bool {
+	// The yielding channels wait while any channel they yield to has msgs to send.
+	yield := false
+	for _, channel := range c.yieldTo {
+		if channel.isSendPending() {
+			yield = true
+			break
+		}
+	}
+
 	// Choose a channel to create a PacketMsg from.
 	// The chosen channel will be the one whose recentlySent/priority is the least.
 	var leastRatio float32 = math.MaxFloat32
 	var leastChannel *Channel
+	var overSendRate bool
 	for _, channel := range c.channels {
 		// If nothing to send, skip this channel
 		if !channel.isSendPending() {
 			continue
 		}
+		if yield && channel.yields {
+			continue
+		}
+		if !c.flushing && !channel.canSendAtRate(c._maxPacketMsgSize) {
+			overSendRate = true
+			continue
+		}
 		// Get ratio, and keep track of lowest ratio.
 		ratio := float32(channel.recentlySent) / float32(channel.desc.Priority)
 		if ratio < leastRatio {
@@ -529,6 +622,9 @@ func (c *MConnection) sendPacketMsg() bool {
 
 	// Nothing to send?
 	if leastChannel == nil {
+		if overSendRate {
+			c.sendRateTimer.Set()
+		}
 		return true
 	}
 	// c.Logger.Info("Found a msgPacket to send")
@@ -675,7 +771,13 @@ type ConnectionStatus struct {
 	Duration    time.Duration
 	SendMonitor flow.Status
 	RecvMonitor flow.Status
-	Channels    []ChannelStatus
+	// Percentiles of the recent send and receive rates. The latencies of
+	// RecvTail are the round trip times of pings.
+	SendTail flow.TailStatus
+	RecvTail flow.TailStatus
+	// Round trip time of the last ping, 0 until a pong is received
+	RTT      time.Duration
+	Channels []ChannelStatus
 }
 
 type ChannelStatus struct {
@@ -684,6 +786,11 @@ type ChannelStatus struct {
 	SendQueueSize     int
 	Priority          int
 	RecentlySent      int64
+	// Total bytes of the messages sent and received on the channel
+	// (compressed, if the channel is)
+	SentBytes     int64
+	ReceivedBytes int64
+	Compressed    bool
 }
 
 func (c *MConnection) Status() ConnectionStatus {
@@ -691,6 +798,9 @@ func (c *MConnection) Status() ConnectionStatus {
 	status.Duration = time.Since(c.created)
 	status.SendMonitor = c.sendMonitor.Status()
 	status.RecvMonitor = c.recvMonitor.Status()
+	status.SendTail = c.sendMonitor.TailStatus()
+	status.RecvTail = c.recvMonitor.TailStatus()
+	status.RTT = time.Duration(atomic.LoadInt64(&c.rtt))
 	status.Channels = make([]ChannelStatus, len(c.channels))
 	for i, channel := range c.channels {
 		status.Channels[i] = ChannelStatus{
@@ -699,6 +809,9 @@ func (c *MConnection) Status() ConnectionStatus {
 			SendQueueSize:     int(atomic.LoadInt32(&channel.sendQueueSize)),
 			Priority:          channel.desc.Priority,
 			RecentlySent:      atomic.LoadInt64(&channel.recentlySent),
+			SentBytes:         atomic.LoadInt64(&channel.sentBytes),
+			ReceivedBytes:     atomic.LoadInt64(&channel.receivedBytes),
+			Compressed:        channel.compressed,
 		}
 	}
 	return status
@@ -738,6 +851,16 @@ type Channel struct {
 	recving       []byte
 	sending       []byte
 	recentlySent  int64 // exponential moving average
+	sentBytes     int64 // atomic, total bytes of the messages sent
+	receivedBytes int64 // atomic, total bytes of the messages received
+
+	// set if the channel is rate limited (see MConnConfig.ChannelSendRates)
+	sendRate    int64
+	sendMonitor *flow.Monitor
+	// see MConnConfig.YieldingChannels
+	yields bool
+	// see MConnConfig.CompressedChannels
+	compressed bool
 
 	maxPacketMsgPayloadSize int
 
@@ -812,6 +935,16 @@ func (ch *Channel) isSendPending() bool {
 	return true
 }
 
+// Returns true if the channel can send n more bytes without exceeding its send
+// rate.
+// Not goroutine-safe
+func (ch *Channel) canSendAtRate(n int) bool {
+	if ch.sendMonitor == nil {
+		return true
+	}
+	return ch.sendMonitor.Limit(n, ch.sendRate, false) > 0
+}
+
 // Creates a new PacketMsg to send.
 // Not goroutine-safe
 func (ch *Channel) nextPacketMsg() PacketMsg {
@@ -836,6 +969,10 @@ func (ch *Channel) writePacketMsgTo(w io.Writer) (n int64, err error) {
 	var packet = ch.nextPacketMsg()
 	n, err = cdc.MarshalBinaryLengthPrefixedWriter(w, packet)
 	atomic.AddInt64(&ch.recentlySent, n)
+	atomic.AddInt64(&ch.sentBytes, int64(len(packet.Bytes)))
+	if ch.sendMonitor != nil {
+		ch.sendMonitor.Update(int(n))
+	}
 	return
 }
 
@@ -848,6 +985,7 @@ func (ch *Channel) recvPacketMsg(packet PacketMsg) ([]byte, error) {
 	if recvCap < recvReceived {
 		return nil, fmt.Errorf("received message exceeds available capacity: %v < %v", recvCap, recvReceived)
 	}
+	atomic.AddInt64(&ch.receivedBytes, int64(len(packet.Bytes)))
 	ch.recving = append(ch.recving, packet.Bytes...)
 	if packet.EOF == byte(0x01) {
 		msgBytes := ch.recving
@@ -857,6 +995,9 @@ func (ch *Channel) recvPacketMsg(packet PacketMsg) ([]byte, error) {
 		//   suggests this could be a memory leak, but we might as well keep the memory for the channel until it closes,
 		//	at which point the recving slice stops being used and should be garbage collected
 		ch.recving = ch.recving[:0] // make([]byte, 0, ch.desc.RecvBufferCapacity)
+		if ch.compressed {
+			return decodeMsg(msgBytes, ch.desc.RecvMessageCapacity)
+		}
 		return msgBytes, nil
 	}
 	return nil, nil
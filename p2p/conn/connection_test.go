@@ -1,7 +1,9 @@
 package conn
 
 import (
+	"bufio"
 	"bytes"
+	"io/ioutil"
 	"net"
 	"testing"
 	"time"
@@ -12,6 +14,7 @@ import (
 
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/timer"
 )
 
 const maxPingPongPacketSize = 1024 // bytes
@@ -143,6 +146,8 @@ func TestMConnectionReceive(t *testing.T) {
 	case <-time.After(500 * time.Millisecond):
 		t.Fatalf("Did not receive %s message in 500ms", msg)
 	}
+	assert.EqualValues(t, len(msg), mconn1.Status().Channels[0].ReceivedBytes)
+	assert.EqualValues(t, len(msg), mconn2.Status().Channels[0].SentBytes)
 }
 
 func TestMConnectionStatus(t *testing.T) {
@@ -342,6 +347,10 @@ func TestMConnectionPingPongs(t *testing.T) {
 	case <-time.After(2 * pongTimerExpired):
 		assert.True(t, mconn.IsRunning())
 	}
+
+	// round trip times of the pings are recorded
+	assert.NotZero(t, mconn.Status().RecvTail.Latencies)
+	assert.NotZero(t, mconn.Status().RTT)
 }
 
 func TestMConnectionStopsAndReturnsError(t *testing.T) {
@@ -532,3 +541,163 @@ func TestMConnectionTrySend(t *testing.T) {
 	assert.False(t, mconn.TrySend(0x01, msg))
 	assert.Equal(t, "TrySend", <-resultCh)
 }
+
+// newSendTestMConnection returns a connection whose packets can be sent one by
+// one with sendPacketMsg, without running the sendRoutine.
+func newSendTestMConnection(cfg MConnConfig, chDescs []*ChannelDescriptor) *MConnection {
+	mconn := NewMConnectionWithConfig(&net.TCPConn{}, chDescs, nil, nil, cfg)
+	mconn.SetLogger(log.TestingLogger())
+	mconn.bufConnWriter = bufio.NewWriter(ioutil.Discard)
+	mconn.flushTimer = timer.NewThrottleTimer("flush", time.Hour)
+	mconn.sendRateTimer = timer.NewThrottleTimer("sendRate", time.Millisecond)
+	return mconn
+}
+
+func TestMConnectionChannelPriorities(t *testing.T) {
+	cfg := DefaultMConnConfig()
+	cfg.ChannelPriorities = map[byte]int{0x02: 20}
+	mconn := newSendTestMConnection(cfg, []*ChannelDescriptor{
+		{ID: 0x01, Priority: 10, SendQueueCapacity: 10},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 10},
+	})
+
+	status := mconn.Status()
+	assert.Equal(t, 10, status.Channels[0].Priority)
+	assert.Equal(t, 20, status.Channels[1].Priority)
+
+	// the channel sending twice as often is picked first
+	mconn.channelsIdx[0x01].recentlySent = 100
+	mconn.channelsIdx[0x02].recentlySent = 100
+	require.True(t, mconn.channelsIdx[0x01].trySendBytes([]byte("one")))
+	require.True(t, mconn.channelsIdx[0x02].trySendBytes([]byte("two")))
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 1, mconn.channelsIdx[0x01].loadSendQueueSize())
+	assert.Equal(t, 0, mconn.channelsIdx[0x02].loadSendQueueSize())
+}
+
+func TestMConnectionYieldingChannels(t *testing.T) {
+	cfg := DefaultMConnConfig()
+	cfg.YieldingChannels = []byte{0x01}
+	cfg.YieldTo = []byte{0x02, 0x03}
+	mconn := newSendTestMConnection(cfg, []*ChannelDescriptor{
+		{ID: 0x01, Priority: 10, SendQueueCapacity: 10},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 10},
+		{ID: 0x03, Priority: 1, SendQueueCapacity: 10},
+	})
+	yielding, other := mconn.channelsIdx[0x01], mconn.channelsIdx[0x02]
+
+	require.True(t, yielding.trySendBytes([]byte("tx")))
+	require.True(t, other.trySendBytes([]byte("vote")))
+	require.True(t, other.trySendBytes([]byte("vote")))
+
+	// the yielding channel waits for the other to send all its msgs
+	assert.False(t, mconn.sendPacketMsg())
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 1, yielding.loadSendQueueSize())
+	assert.Equal(t, 0, other.loadSendQueueSize())
+
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 0, yielding.loadSendQueueSize())
+	assert.True(t, mconn.sendPacketMsg())
+}
+
+func TestMConnectionChannelSendRates(t *testing.T) {
+	cfg := DefaultMConnConfig()
+	cfg.ChannelSendRates = map[byte]int64{0x01: 1}
+	mconn := newSendTestMConnection(cfg, []*ChannelDescriptor{
+		{ID: 0x01, Priority: 1, SendQueueCapacity: 10},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 10},
+	})
+	limited, other := mconn.channelsIdx[0x01], mconn.channelsIdx[0x02]
+
+	require.True(t, limited.trySendBytes([]byte("one")))
+	require.True(t, limited.trySendBytes([]byte("two")))
+	require.True(t, other.trySendBytes([]byte("three")))
+
+	// the limited channel sends a packet, then has to wait for the others
+	assert.False(t, mconn.sendPacketMsg())
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 1, limited.loadSendQueueSize())
+	assert.Equal(t, 0, other.loadSendQueueSize())
+
+	// and for the sendRoutine to be woken up once it can send again
+	assert.True(t, mconn.sendPacketMsg())
+	assert.Equal(t, 1, limited.loadSendQueueSize())
+	select {
+	case <-mconn.sendRateTimer.Ch:
+	case <-time.After(time.Second):
+		t.Fatal("sendRoutine is not woken up")
+	}
+
+	// unless the connection is being flushed
+	mconn.flushing = true
+	assert.False(t, mconn.sendPacketMsg())
+	assert.Equal(t, 0, limited.loadSendQueueSize())
+}
+
+func TestMConnectionCompressedChannel(t *testing.T) {
+	server, client := NetPipe()
+	defer server.Close() // nolint: errcheck
+	defer client.Close() // nolint: errcheck
+
+	cfg := DefaultMConnConfig()
+	cfg.CompressedChannels = []byte{0x01}
+	cfg.CompressionMinSize = 100
+	chDescs := []*ChannelDescriptor{{ID: 0x01, Priority: 1, SendQueueCapacity: 1}}
+
+	receivedCh := make(chan []byte)
+	onReceive := func(chID byte, msgBytes []byte) {
+		receivedCh <- append([]byte(nil), msgBytes...)
+	}
+	onError := func(r interface{}) {
+		t.Error(r)
+	}
+	mconn1 := NewMConnectionWithConfig(client, chDescs, onReceive, onError, cfg)
+	mconn1.SetLogger(log.TestingLogger())
+	require.NoError(t, mconn1.Start())
+	defer mconn1.Stop()
+
+	mconn2 := NewMConnectionWithConfig(server, chDescs, onReceive, onError, cfg)
+	mconn2.SetLogger(log.TestingLogger())
+	require.NoError(t, mconn2.Start())
+	defer mconn2.Stop()
+
+	// a small msg is sent as it is, a large one compressed
+	small, large := []byte("Cyclops"), bytes.Repeat([]byte("Cyclops"), 1000)
+	for _, msg := range [][]byte{small, large} {
+		assert.True(t, mconn2.Send(0x01, msg))
+		select {
+		case receivedBytes := <-receivedCh:
+			assert.Equal(t, msg, receivedBytes)
+		case <-time.After(time.Second):
+			t.Fatalf("Did not receive a message of %d bytes in 1s", len(msg))
+		}
+	}
+	status := mconn2.Status().Channels[0]
+	assert.True(t, status.Compressed)
+	assert.True(t, status.SentBytes > int64(1+len(small)))
+	assert.True(t, status.SentBytes < int64(len(large)/10))
+	assert.Equal(t, status.SentBytes, mconn1.Status().Channels[0].ReceivedBytes)
+}
+
+func TestDecodeMsg(t *testing.T) {
+	msg := bytes.Repeat([]byte{0x42}, 1000)
+
+	decoded, err := decodeMsg(encodeMsg(msg, 1), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+	decoded, err = decodeMsg(encodeMsg(msg, 1001), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, msg, decoded)
+
+	// msgs larger than the capacity of the channel once decompressed
+	_, err = decodeMsg(encodeMsg(msg, 1), 999)
+	assert.Error(t, err)
+
+	_, err = decodeMsg(nil, 1000)
+	assert.Error(t, err)
+	_, err = decodeMsg([]byte{0x02, 0x42}, 1000)
+	assert.Error(t, err)
+	_, err = decodeMsg(encodeMsg(msg, 1)[:10], 1000)
+	assert.Error(t, err)
+}
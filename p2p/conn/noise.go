@@ -0,0 +1,384 @@
+package conn
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+// Handshakes establishing a SecretConnection, chosen by the dialer.
+const (
+	// HandshakeSTS is the station-to-station handshake of MakeSecretConnection,
+	// supported by all versions.
+	HandshakeSTS = "sts"
+	// HandshakeNoise is the Noise_XX_25519_ChaChaPoly_SHA256 handshake,
+	// authenticating the node keys with signatures of the handshake hash.
+	HandshakeNoise = "noise"
+)
+
+// The dialer announces the Noise handshake with a version byte, which the
+// first byte of the STS handshake, the length of the amino encoded ephemeral
+// key, never is. A future handshake gets a new version byte.
+const (
+	stsFirstByte    byte = 0x21
+	noiseVersionXX1 byte = 0x01
+)
+
+const (
+	noiseProtocolName = "Noise_XX_25519_ChaChaPoly_SHA256"
+	noiseMaxMsgSize   = 65535
+)
+
+var (
+	// ErrNoiseUnsupported is returned on dialing a peer with the Noise
+	// handshake if it only supports the STS handshake.
+	ErrNoiseUnsupported = errors.New("peer doesn't support the Noise handshake")
+
+	noisePrologue  = []byte("TENDERMINT_SECRET_CONNECTION_NOISE")
+	labelNoiseAuth = []byte("TENDERMINT_SECRET_CONNECTION_NOISE_AUTH")
+)
+
+// DialSecretConnection establishes a SecretConnection with handshake
+// (HandshakeSTS or HandshakeNoise) as the dialer. With HandshakeNoise, it
+// returns ErrNoiseUnsupported if the peer only supports HandshakeSTS.
+func DialSecretConnection(conn net.Conn, locPrivKey crypto.PrivKey, handshake string) (*SecretConnection, error) {
+	switch handshake {
+	case HandshakeSTS:
+		return MakeSecretConnection(conn, locPrivKey)
+	case HandshakeNoise:
+		if _, err := conn.Write([]byte{noiseVersionXX1}); err != nil {
+			return nil, err
+		}
+		version, err := readByte(conn)
+		if err != nil {
+			return nil, err
+		}
+		switch version {
+		case noiseVersionXX1:
+			return makeNoiseSecretConnection(conn, locPrivKey, true)
+		case stsFirstByte:
+			return nil, ErrNoiseUnsupported
+		default:
+			return nil, errors.Errorf("unknown handshake version %#x", version)
+		}
+	default:
+		return nil, errors.Errorf("unknown handshake %q", handshake)
+	}
+}
+
+// AcceptSecretConnection establishes a SecretConnection as the accepting side,
+// with the handshake of the dialer.
+func AcceptSecretConnection(conn net.Conn, locPrivKey crypto.PrivKey) (*SecretConnection, error) {
+	version, err := readByte(conn)
+	if err != nil {
+		return nil, err
+	}
+	if version != noiseVersionXX1 {
+		// an STS handshake, which the byte read is the start of
+		return MakeSecretConnection(&prefixedConn{
+			Conn:   conn,
+			reader: io.MultiReader(bytes.NewReader([]byte{version}), conn),
+		}, locPrivKey)
+	}
+	if _, err := conn.Write([]byte{noiseVersionXX1}); err != nil {
+		return nil, err
+	}
+	return makeNoiseSecretConnection(conn, locPrivKey, false)
+}
+
+// makeNoiseSecretConnection performs the Noise XX handshake:
+//
+//	-> e
+//	<- e, ee, s, es
+//	-> s, se
+//
+// with fresh static keys, each side authenticating with its node key by
+// signing the handshake hash in its last message. The SecretConnection then
+// encrypts with the keys of the handshake.
+func makeNoiseSecretConnection(conn net.Conn, locPrivKey crypto.PrivKey, initiator bool) (*SecretConnection, error) {
+	hs := newNoiseHandshake()
+	locEphPub, locEphPriv := genEphKeys()
+	locStaticPub, locStaticPriv := genEphKeys()
+	var remEphPub *[32]byte
+	var remPubKey crypto.PubKey
+
+	// authPayload signs the handshake hash with the node key.
+	authPayload := func() []byte {
+		sig, err := locPrivKey.Sign(append(append([]byte{}, labelNoiseAuth...), hs.h[:]...))
+		if err != nil {
+			panic(err)
+		}
+		return cdc.MustMarshalBinaryBare(authSigMessage{locPrivKey.PubKey(), sig})
+	}
+	// readStaticAndAuth reads the "s" of the peer, mixes the DH of the local
+	// ephemeral key and it ("es" or "se"), and verifies the signature of the
+	// handshake hash by the node key of the peer in the payload.
+	readStaticAndAuth := func(msg []byte) error {
+		if len(msg) < 32+aeadSizeOverhead {
+			return errors.New("noise message too short")
+		}
+		sBz, err := hs.decryptAndHash(msg[:32+aeadSizeOverhead])
+		if err != nil {
+			return err
+		}
+		remStaticPub, _, _ := readKey(sBz)
+		if err := hs.mixDH(locEphPriv, remStaticPub); err != nil {
+			return err
+		}
+		h := hs.h
+		payload, err := hs.decryptAndHash(msg[32+aeadSizeOverhead:])
+		if err != nil {
+			return err
+		}
+
+		var auth authSigMessage
+		if err := cdc.UnmarshalBinaryBare(payload, &auth); err != nil {
+			return errors.Wrap(err, "failed to decode the authentication")
+		}
+		if _, ok := auth.Key.(ed25519.PubKeyEd25519); !ok {
+			return errors.Errorf("expected ed25519 pubkey, got %T", auth.Key)
+		}
+		if !auth.Key.VerifyBytes(append(append([]byte{}, labelNoiseAuth...), h[:]...), auth.Sig) {
+			return errors.New("challenge verification failed")
+		}
+		remPubKey = auth.Key
+		return nil
+	}
+
+	if initiator {
+		// -> e, with an empty payload
+		hs.mixHash(locEphPub[:])
+		hs.mixHash(nil)
+		if err := writeNoiseMsg(conn, locEphPub[:]); err != nil {
+			return nil, err
+		}
+
+		// <- e, ee, s, es
+		msg, err := readNoiseMsg(conn)
+		if err != nil {
+			return nil, err
+		}
+		if remEphPub, msg, err = readKey(msg); err != nil {
+			return nil, err
+		}
+		hs.mixHash(remEphPub[:])
+		if err := hs.mixDH(locEphPriv, remEphPub); err != nil {
+			return nil, err
+		}
+		if err := readStaticAndAuth(msg); err != nil {
+			return nil, err
+		}
+
+		// -> s, se
+		out := hs.encryptAndHash(locStaticPub[:])
+		if err := hs.mixDH(locStaticPriv, remEphPub); err != nil {
+			return nil, err
+		}
+		out = append(out, hs.encryptAndHash(authPayload())...)
+		if err := writeNoiseMsg(conn, out); err != nil {
+			return nil, err
+		}
+	} else {
+		// -> e
+		msg, err := readNoiseMsg(conn)
+		if err != nil {
+			return nil, err
+		}
+		if remEphPub, msg, err = readKey(msg); err != nil {
+			return nil, err
+		}
+		hs.mixHash(remEphPub[:])
+		hs.mixHash(msg)
+
+		// <- e, ee, s, es
+		hs.mixHash(locEphPub[:])
+		out := append([]byte{}, locEphPub[:]...)
+		if err := hs.mixDH(locEphPriv, remEphPub); err != nil {
+			return nil, err
+		}
+		out = append(out, hs.encryptAndHash(locStaticPub[:])...)
+		if err := hs.mixDH(locStaticPriv, remEphPub); err != nil {
+			return nil, err
+		}
+		out = append(out, hs.encryptAndHash(authPayload())...)
+		if err := writeNoiseMsg(conn, out); err != nil {
+			return nil, err
+		}
+
+		// -> s, se
+		if msg, err = readNoiseMsg(conn); err != nil {
+			return nil, err
+		}
+		if err := readStaticAndAuth(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	initiatorKey, responderKey := hs.split()
+	sendSecret, recvSecret := initiatorKey, responderKey
+	if !initiator {
+		sendSecret, recvSecret = responderKey, initiatorKey
+	}
+	sendAead, err := chacha20poly1305.New(sendSecret[:])
+	if err != nil {
+		return nil, errors.New("invalid send SecretConnection Key")
+	}
+	recvAead, err := chacha20poly1305.New(recvSecret[:])
+	if err != nil {
+		return nil, errors.New("invalid receive SecretConnection Key")
+	}
+	return &SecretConnection{
+		conn:      conn,
+		recvNonce: new([aeadNonceSize]byte),
+		sendNonce: new([aeadNonceSize]byte),
+		recvAead:  recvAead,
+		sendAead:  sendAead,
+		remPubKey: remPubKey,
+	}, nil
+}
+
+// noiseHandshake is the symmetric state of a Noise handshake.
+type noiseHandshake struct {
+	ck [32]byte
+	h  [32]byte
+	k  cipher.AEAD // nil until the first DH
+	n  uint64
+}
+
+func newNoiseHandshake() *noiseHandshake {
+	hs := &noiseHandshake{}
+	// the protocol name is exactly 32 bytes long
+	copy(hs.h[:], noiseProtocolName)
+	hs.ck = hs.h
+	hs.mixHash(noisePrologue)
+	return hs
+}
+
+func (hs *noiseHandshake) mixHash(data []byte) {
+	hash := sha256.New()
+	hash.Write(hs.h[:]) // nolint: errcheck, gosec
+	hash.Write(data)    // nolint: errcheck, gosec
+	copy(hs.h[:], hash.Sum(nil))
+}
+
+// mixDH mixes the X25519 of locPriv and remPub into the chaining key, and
+// derives the key encrypting the rest of the handshake.
+func (hs *noiseHandshake) mixDH(locPriv, remPub *[32]byte) error {
+	dhSecret, err := computeDHSecret(remPub, locPriv)
+	if err != nil {
+		return err
+	}
+	var k [32]byte
+	hs.ck, k = noiseHKDF(hs.ck, dhSecret[:])
+	hs.k, err = chacha20poly1305.New(k[:])
+	hs.n = 0
+	return err
+}
+
+func (hs *noiseHandshake) encryptAndHash(plaintext []byte) []byte {
+	ciphertext := hs.k.Seal(nil, hs.nonce(), plaintext, hs.h[:])
+	hs.mixHash(ciphertext)
+	return ciphertext
+}
+
+func (hs *noiseHandshake) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	plaintext, err := hs.k.Open(nil, hs.nonce(), ciphertext, hs.h[:])
+	if err != nil {
+		return nil, errors.New("failed to decrypt the noise handshake")
+	}
+	hs.mixHash(ciphertext)
+	return plaintext, nil
+}
+
+// nonce returns the next nonce: 32 bits of zeros and the little-endian
+// counter, as incrNonce does.
+func (hs *noiseHandshake) nonce() []byte {
+	var nonce [aeadNonceSize]byte
+	binary.LittleEndian.PutUint64(nonce[4:], hs.n)
+	hs.n++
+	return nonce[:]
+}
+
+// split returns the keys the initiator and the responder send with.
+func (hs *noiseHandshake) split() (initiatorKey, responderKey [32]byte) {
+	return noiseHKDF(hs.ck, nil)
+}
+
+// noiseHKDF is the HKDF of the Noise specification, with two outputs.
+func noiseHKDF(ck [32]byte, ikm []byte) (out1, out2 [32]byte) {
+	mac := hmac.New(sha256.New, ck[:])
+	mac.Write(ikm) // nolint: errcheck, gosec
+	temp := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, temp)
+	mac.Write([]byte{0x01}) // nolint: errcheck, gosec
+	copy(out1[:], mac.Sum(nil))
+
+	mac = hmac.New(sha256.New, temp)
+	mac.Write(out1[:])      // nolint: errcheck, gosec
+	mac.Write([]byte{0x02}) // nolint: errcheck, gosec
+	copy(out2[:], mac.Sum(nil))
+	return out1, out2
+}
+
+// writeNoiseMsg writes a handshake message, prefixed with its 2 bytes
+// big-endian length.
+func writeNoiseMsg(w io.Writer, msg []byte) error {
+	if len(msg) > noiseMaxMsgSize {
+		return errors.New("noise message too long")
+	}
+	buf := make([]byte, 2+len(msg))
+	binary.BigEndian.PutUint16(buf, uint16(len(msg)))
+	copy(buf[2:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readNoiseMsg(r io.Reader) ([]byte, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// readKey reads a 32 bytes key from the start of msg, returning the rest.
+func readKey(msg []byte) (*[32]byte, []byte, error) {
+	if len(msg) < 32 {
+		return nil, nil, errors.New("noise message too short")
+	}
+	var key [32]byte
+	copy(key[:], msg)
+	return &key, msg[32:], nil
+}
+
+func readByte(r io.Reader) (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(r, b[:])
+	return b[0], err
+}
+
+// prefixedConn is a net.Conn reading from reader, a MultiReader of bytes
+// already read and the connection.
+type prefixedConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *prefixedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,146 @@
+package conn
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+// makeDialedConnPair returns the SecretConnections of the dialer and of the
+// accepting side, the dialer dialing with dial.
+func makeDialedConnPair(
+	dial func(net.Conn, crypto.PrivKey) (*SecretConnection, error),
+	accept func(net.Conn, crypto.PrivKey) (*SecretConnection, error),
+) (dialerKey, acceptorKey crypto.PrivKey, dialerConn, acceptorConn *SecretConnection, dialErr, acceptErr error) {
+	dialerKey, acceptorKey = ed25519.GenPrivKey(), ed25519.GenPrivKey()
+	c1, c2 := net.Pipe()
+
+	done := make(chan struct{})
+	go func() {
+		acceptorConn, acceptErr = accept(c2, acceptorKey)
+		if acceptErr != nil {
+			c2.Close()
+		}
+		close(done)
+	}()
+	dialerConn, dialErr = dial(c1, dialerKey)
+	if dialErr != nil {
+		c1.Close()
+	}
+	<-done
+	return
+}
+
+func TestNoiseSecretConnection(t *testing.T) {
+	dialNoise := func(c net.Conn, key crypto.PrivKey) (*SecretConnection, error) {
+		return DialSecretConnection(c, key, HandshakeNoise)
+	}
+	dialerKey, acceptorKey, dialerConn, acceptorConn, err1, err2 := makeDialedConnPair(dialNoise, AcceptSecretConnection)
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, acceptorKey.PubKey(), dialerConn.RemotePubKey())
+	assert.Equal(t, dialerKey.PubKey(), acceptorConn.RemotePubKey())
+
+	// data flows both ways, in frames larger than dataMaxSize
+	msg := make([]byte, 3*dataMaxSize)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	go func() {
+		dialerConn.Write(msg) // nolint: errcheck
+	}()
+	received := make([]byte, len(msg))
+	_, err := io.ReadFull(acceptorConn, received)
+	require.NoError(t, err)
+	assert.Equal(t, msg, received)
+
+	go func() {
+		acceptorConn.Write([]byte("pong")) // nolint: errcheck
+	}()
+	received = make([]byte, 4)
+	_, err = io.ReadFull(dialerConn, received)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(received))
+}
+
+func TestNoiseSecretConnectionBackwardsCompatible(t *testing.T) {
+	// a dialer with the STS handshake is accepted
+	dialSTS := func(c net.Conn, key crypto.PrivKey) (*SecretConnection, error) {
+		return DialSecretConnection(c, key, HandshakeSTS)
+	}
+	dialerKey, acceptorKey, dialerConn, acceptorConn, err1, err2 := makeDialedConnPair(dialSTS, AcceptSecretConnection)
+	require.NoError(t, err1)
+	require.NoError(t, err2)
+	assert.Equal(t, acceptorKey.PubKey(), dialerConn.RemotePubKey())
+	assert.Equal(t, dialerKey.PubKey(), acceptorConn.RemotePubKey())
+	go func() {
+		dialerConn.Write([]byte("ping")) // nolint: errcheck
+	}()
+	received := make([]byte, 4)
+	_, err := io.ReadFull(acceptorConn, received)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(received))
+
+	// a peer only supporting the STS handshake is detected
+	dialNoise := func(c net.Conn, key crypto.PrivKey) (*SecretConnection, error) {
+		conn, err := DialSecretConnection(c, key, HandshakeNoise)
+		c.Close()
+		return conn, err
+	}
+	acceptSTS := func(c net.Conn, key crypto.PrivKey) (*SecretConnection, error) {
+		return MakeSecretConnection(c, key)
+	}
+	_, _, _, _, err1, err2 = makeDialedConnPair(dialNoise, acceptSTS)
+	assert.Equal(t, ErrNoiseUnsupported, err1)
+	assert.Error(t, err2)
+}
+
+func TestNoiseSecretConnectionTampered(t *testing.T) {
+	// flip a bit of every handshake message in turn
+	for i := 0; i < 3; i++ {
+		i := i
+		dialNoise := func(c net.Conn, key crypto.PrivKey) (*SecretConnection, error) {
+			conn, err := DialSecretConnection(&tamperingConn{Conn: c, tamperedMsg: i}, key, HandshakeNoise)
+			c.Close()
+			return conn, err
+		}
+		_, _, _, _, err1, err2 := makeDialedConnPair(dialNoise, AcceptSecretConnection)
+		assert.True(t, err1 != nil || err2 != nil, "tampered message %d", i)
+	}
+}
+
+// tamperingConn flips a bit of the tamperedMsg-th handshake message, counting
+// the messages written and read, after the version bytes.
+type tamperingConn struct {
+	net.Conn
+	tamperedMsg int
+	msgs        int
+}
+
+func (c *tamperingConn) Write(b []byte) (int, error) {
+	if len(b) > 2 {
+		if c.msgs == c.tamperedMsg {
+			b = append([]byte{}, b...)
+			b[len(b)-1] ^= 1
+		}
+		c.msgs++
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *tamperingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 2 {
+		if c.msgs == c.tamperedMsg {
+			b[n-1] ^= 1
+		}
+		c.msgs++
+	}
+	return n, err
+}
@@ -3,6 +3,7 @@ package p2p
 import (
 	"fmt"
 	"net"
+	"time"
 )
 
 // ErrFilterTimeout indicates that a filter operation timed out.
@@ -182,3 +183,25 @@ type ErrCurrentlyDialingOrExistingAddress struct {
 func (e ErrCurrentlyDialingOrExistingAddress) Error() string {
 	return fmt.Sprintf("connection with %s has been established or dialed", e.Addr)
 }
+
+// ErrSubnetQuotaReached is returned when dialing an address of a subnet which
+// already has the maximum number of outbound peers (see
+// P2PConfig.MaxNumOutboundPeersPerSubnet).
+type ErrSubnetQuotaReached struct {
+	Subnet string
+	Max    int
+}
+
+func (e ErrSubnetQuotaReached) Error() string {
+	return fmt.Sprintf("already have %d outbound peers from subnet %s", e.Max, e.Subnet)
+}
+
+// ErrPeerBanned is returned when dialing, or accepting, a peer which is banned
+// (see Switch.BanPeer).
+type ErrPeerBanned struct {
+	Ban Ban
+}
+
+func (e ErrPeerBanned) Error() string {
+	return fmt.Sprintf("peer %v is banned until %v: %s", e.Ban.ID, e.Ban.Expires.Format(time.RFC3339), e.Ban.Reason)
+}
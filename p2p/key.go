@@ -76,15 +76,19 @@ func genNodeKey(filePath string) (*NodeKey, error) {
 		PrivKey: privKey,
 	}
 
-	jsonBytes, err := cdc.MarshalJSON(nodeKey)
-	if err != nil {
+	if err := nodeKey.SaveAs(filePath); err != nil {
 		return nil, err
 	}
-	err = ioutil.WriteFile(filePath, jsonBytes, 0600)
+	return nodeKey, nil
+}
+
+// SaveAs persists the NodeKey to filePath.
+func (nodeKey *NodeKey) SaveAs(filePath string) error {
+	jsonBytes, err := cdc.MarshalJSON(nodeKey)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return nodeKey, nil
+	return ioutil.WriteFile(filePath, jsonBytes, 0600)
 }
 
 //------------------------------------------------------------------------------
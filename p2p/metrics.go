@@ -3,8 +3,10 @@ package p2p
 import (
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
-	"github.com/go-kit/kit/metrics/prometheus"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tendermint/tendermint/libs/async"
+	tmmetrics "github.com/tendermint/tendermint/libs/metrics"
 )
 
 const (
@@ -23,59 +25,97 @@ type Metrics struct {
 	PeerSendBytesTotal metrics.Counter
 	// Pending bytes to be sent to a given peer.
 	PeerPendingSendBytes metrics.Gauge
+	// Number of messages queued to be sent to a given peer, per channel.
+	PeerSendQueueSize metrics.Gauge
+	// Round trip time of the last ping of a given peer.
+	PeerRTTSeconds metrics.Gauge
+	// Time since the connection to a given peer was established.
+	PeerConnectionAgeSeconds metrics.Gauge
 	// Number of transactions submitted by each peer.
 	NumTxs metrics.Gauge
+	// Metrics of the task pool of the switch.
+	TaskPool *async.PoolMetrics
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	return PrometheusMetricsWithRegisterer(stdprometheus.DefaultRegisterer, namespace, labelsAndValues...)
+}
+
+// PrometheusMetricsWithRegisterer is like PrometheusMetrics, but registers the
+// metrics with the given registerer.
+func PrometheusMetricsWithRegisterer(registerer stdprometheus.Registerer, namespace string,
+	labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
 	return &Metrics{
-		Peers: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		Peers: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peers",
 			Help:      "Number of peers.",
 		}, labels).With(labelsAndValues...),
-		PeerReceiveBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		PeerReceiveBytesTotal: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peer_receive_bytes_total",
 			Help:      "Number of bytes received from a given peer.",
 		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
-		PeerSendBytesTotal: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		PeerSendBytesTotal: tmmetrics.NewCounterFrom(registerer, stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peer_send_bytes_total",
 			Help:      "Number of bytes sent to a given peer.",
 		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
-		PeerPendingSendBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		PeerPendingSendBytes: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "peer_pending_send_bytes",
 			Help:      "Number of pending bytes to be sent to a given peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
-		NumTxs: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+		PeerSendQueueSize: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_send_queue_size",
+			Help:      "Number of messages queued to be sent to a given peer, per channel.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+		PeerRTTSeconds: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_rtt_seconds",
+			Help:      "Round trip time of the last ping of a given peer.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		PeerConnectionAgeSeconds: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_connection_age_seconds",
+			Help:      "Time since the connection to a given peer was established.",
+		}, append(labels, "peer_id")).With(labelsAndValues...),
+		NumTxs: tmmetrics.NewGaugeFrom(registerer, stdprometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "num_txs",
 			Help:      "Number of transactions submitted by each peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
+		TaskPool: async.PrometheusPoolMetricsWithRegisterer(registerer, namespace, MetricsSubsystem, labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Peers:                 discard.NewGauge(),
-		PeerReceiveBytesTotal: discard.NewCounter(),
-		PeerSendBytesTotal:    discard.NewCounter(),
-		PeerPendingSendBytes:  discard.NewGauge(),
-		NumTxs:                discard.NewGauge(),
+		Peers:                    discard.NewGauge(),
+		PeerReceiveBytesTotal:    discard.NewCounter(),
+		PeerSendBytesTotal:       discard.NewCounter(),
+		PeerPendingSendBytes:     discard.NewGauge(),
+		PeerSendQueueSize:        discard.NewGauge(),
+		PeerRTTSeconds:           discard.NewGauge(),
+		PeerConnectionAgeSeconds: discard.NewGauge(),
+		NumTxs:                   discard.NewGauge(),
+		TaskPool:                 async.NopPoolMetrics(),
 	}
 }
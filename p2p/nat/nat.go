@@ -0,0 +1,246 @@
+// Package nat keeps a node behind a NAT reachable: it maps the port of the
+// node on the gateway, with UPnP or NAT-PMP, and detects the external address
+// of the node, for it to be advertised to peers.
+package nat
+
+import (
+	"net"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/p2p/upnp"
+)
+
+const (
+	// lifetime of the port mappings, which are refreshed every half of it
+	mappingLifetime = 20 * time.Minute
+
+	// the mappings are refreshed, and the external address checked again,
+	// every refreshPeriod
+	refreshPeriod = mappingLifetime / 2
+
+	// time a STUN server is given to respond
+	stunTimeout = 5 * time.Second
+
+	// time given to remove the mappings once stopped
+	stopTimeout = 5 * time.Second
+
+	mappingDescription = "Tendermint"
+)
+
+// private and shared (RFC 6598) address ranges, which are not external
+// addresses
+var privateNets = []*net.IPNet{
+	mustParseCIDR("10.0.0.0/8"),
+	mustParseCIDR("172.16.0.0/12"),
+	mustParseCIDR("192.168.0.0/16"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("fc00::/7"),
+}
+
+// Config configures a Mapper.
+type Config struct {
+	// Map the port with UPnP
+	UPnP bool
+	// Map the port with NAT-PMP, if UPnP is disabled or the gateway doesn't
+	// support it
+	NATPMP bool
+	// STUN servers ("host:port") asked for the external IP when the gateway
+	// can't tell it, e.g. behind two NATs
+	STUNServers []string
+
+	// Port of the node
+	Port int
+	// Protocols the port is mapped for ("TCP", "UDP"). The external port of
+	// the first is the port of the external address.
+	Protocols []string
+}
+
+// Mapper keeps the port of the node mapped on the gateway, with UPnP or
+// NAT-PMP, and keeps track of the external address of the node: the IP told by
+// the gateway or, failing that, by the STUN servers, and the port mapped on the
+// gateway (or the port of the node, assumed to be forwarded). The mappings are
+// refreshed, and the address checked again, every refreshPeriod, and the
+// mappings are removed once the Mapper stops.
+type Mapper struct {
+	service.BaseService
+
+	config    Config
+	onAddress func(ip net.IP, port int)
+	stop      chan struct{} // closed by OnStop, before Quit
+	done      chan struct{}
+
+	// for testing
+	discoverUPnP   func() (upnp.NAT, error)
+	discoverNATPMP func() (upnp.NAT, error)
+	stunAddress    func(server string, timeout time.Duration) (*net.UDPAddr, error)
+
+	// only accessed by the routine
+	nat    upnp.NAT
+	mapped map[string]int // protocol -> external port
+	ip     net.IP
+	port   int
+}
+
+// NewMapper returns a Mapper, calling onAddress with the external address of
+// the node whenever it's detected, or changes.
+func NewMapper(config Config, onAddress func(ip net.IP, port int)) *Mapper {
+	m := &Mapper{
+		config:         config,
+		onAddress:      onAddress,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+		discoverUPnP:   upnp.Discover,
+		discoverNATPMP: DiscoverNATPMP,
+		stunAddress:    STUNExternalAddress,
+		mapped:         make(map[string]int),
+	}
+	m.BaseService = *service.NewBaseService(nil, "NATMapper", m)
+	return m
+}
+
+// OnStart implements service.Service. The gateway is discovered in the
+// background.
+func (m *Mapper) OnStart() error {
+	go m.routine()
+	return nil
+}
+
+// OnStop implements service.Service. It waits for the mappings to be removed,
+// for a few seconds at most.
+func (m *Mapper) OnStop() {
+	close(m.stop)
+	select {
+	case <-m.done:
+	case <-time.After(stopTimeout):
+		m.Logger.Error("Timed out removing the port mappings")
+	}
+}
+
+func (m *Mapper) routine() {
+	defer close(m.done)
+
+	m.refresh()
+	ticker := time.NewTicker(refreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.refresh()
+		case <-m.stop:
+			m.unmap()
+			return
+		}
+	}
+}
+
+// refresh maps the port on the gateway, discovering it first if needed, and
+// checks the external address.
+func (m *Mapper) refresh() {
+	if m.nat == nil {
+		m.nat = m.discover()
+	}
+
+	port := m.config.Port
+	if m.nat != nil {
+		for i, protocol := range m.config.Protocols {
+			extPort, err := m.nat.AddPortMapping(protocol, m.config.Port, m.config.Port,
+				mappingDescription, int(mappingLifetime/time.Second))
+			if err != nil {
+				m.Logger.Error("Failed to map port", "protocol", protocol, "port", m.config.Port, "err", err)
+				continue
+			}
+			if m.mapped[protocol] != extPort {
+				m.Logger.Info("Mapped port", "protocol", protocol, "port", m.config.Port, "external", extPort)
+			}
+			m.mapped[protocol] = extPort
+			if i == 0 {
+				port = extPort
+			}
+		}
+	}
+
+	ip := m.externalIP()
+	if ip == nil {
+		return
+	}
+	if !ip.Equal(m.ip) || port != m.port {
+		m.ip, m.port = ip, port
+		m.Logger.Info("Detected external address", "ip", ip, "port", port)
+		m.onAddress(ip, port)
+	}
+}
+
+// discover returns the NAT of the gateway, or nil if port mapping is disabled
+// or unsupported.
+func (m *Mapper) discover() upnp.NAT {
+	if m.config.UPnP {
+		nat, err := m.discoverUPnP()
+		if err == nil {
+			return nat
+		}
+		m.Logger.Info("UPnP gateway not found", "err", err)
+	}
+	if m.config.NATPMP {
+		nat, err := m.discoverNATPMP()
+		if err == nil {
+			return nat
+		}
+		m.Logger.Info("NAT-PMP gateway not found", "err", err)
+	}
+	return nil
+}
+
+// externalIP returns the external IP told by the gateway or, failing that, by
+// the first STUN server responding, or nil.
+func (m *Mapper) externalIP() net.IP {
+	if m.nat != nil {
+		ip, err := m.nat.GetExternalAddress()
+		if err != nil {
+			m.Logger.Error("Failed to get the external address of the gateway", "err", err)
+		} else if isExternal(ip) {
+			return ip
+		}
+	}
+	for _, server := range m.config.STUNServers {
+		addr, err := m.stunAddress(server, stunTimeout)
+		if err != nil {
+			m.Logger.Info("Failed to get the external address from the STUN server", "server", server, "err", err)
+			continue
+		}
+		if isExternal(addr.IP) {
+			return addr.IP
+		}
+	}
+	return nil
+}
+
+// unmap removes the port mappings.
+func (m *Mapper) unmap() {
+	for protocol, extPort := range m.mapped {
+		if err := m.nat.DeletePortMapping(protocol, extPort, m.config.Port); err != nil {
+			m.Logger.Error("Failed to remove port mapping", "protocol", protocol, "external", extPort, "err", err)
+		}
+		delete(m.mapped, protocol)
+	}
+}
+
+func isExternal(ip net.IP) bool {
+	if ip == nil || !ip.IsGlobalUnicast() {
+		return false
+	}
+	for _, ipNet := range privateNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
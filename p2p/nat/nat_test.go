@@ -0,0 +1,146 @@
+package nat
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p/upnp"
+)
+
+type testNAT struct {
+	mtx      sync.Mutex
+	ip       net.IP
+	mappings map[string]int // protocol -> external port
+}
+
+func (n *testNAT) GetExternalAddress() (net.IP, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return n.ip, nil
+}
+
+func (n *testNAT) AddPortMapping(protocol string, extPort, intPort int, desc string, timeout int) (int, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	n.mappings[protocol] = extPort + 1000
+	return extPort + 1000, nil
+}
+
+func (n *testNAT) DeletePortMapping(protocol string, extPort, intPort int) error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	delete(n.mappings, protocol)
+	return nil
+}
+
+type testAddress struct {
+	ip   net.IP
+	port int
+}
+
+func newTestMapper(config Config) (*Mapper, chan testAddress) {
+	addrs := make(chan testAddress, 10)
+	m := NewMapper(config, func(ip net.IP, port int) {
+		addrs <- testAddress{ip, port}
+	})
+	m.SetLogger(log.TestingLogger())
+	m.discoverUPnP = func() (upnp.NAT, error) { return nil, errors.New("no UPnP") }
+	m.discoverNATPMP = func() (upnp.NAT, error) { return nil, errors.New("no NAT-PMP") }
+	m.stunAddress = func(string, time.Duration) (*net.UDPAddr, error) { return nil, errors.New("no STUN") }
+	return m, addrs
+}
+
+func TestMapperMapsPorts(t *testing.T) {
+	nat := &testNAT{ip: net.IPv4(203, 0, 113, 7), mappings: make(map[string]int)}
+	m, addrs := newTestMapper(Config{NATPMP: true, Port: 26656, Protocols: []string{"TCP", "UDP"}})
+	m.discoverNATPMP = func() (upnp.NAT, error) { return nat, nil }
+	require.NoError(t, m.Start())
+
+	select {
+	case addr := <-addrs:
+		assert.Equal(t, "203.0.113.7", addr.ip.String())
+		assert.Equal(t, 27656, addr.port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no external address")
+	}
+	nat.mtx.Lock()
+	assert.Equal(t, map[string]int{"TCP": 27656, "UDP": 27656}, nat.mappings)
+	nat.mtx.Unlock()
+
+	// the mappings are removed once stopped
+	require.NoError(t, m.Stop())
+	nat.mtx.Lock()
+	assert.Empty(t, nat.mappings)
+	nat.mtx.Unlock()
+}
+
+func TestMapperSTUN(t *testing.T) {
+	// the gateway is behind another NAT
+	nat := &testNAT{ip: net.IPv4(192, 168, 1, 2), mappings: make(map[string]int)}
+	m, addrs := newTestMapper(Config{
+		UPnP:        true,
+		STUNServers: []string{"stun1:3478", "stun2:3478"},
+		Port:        26656,
+		Protocols:   []string{"TCP"},
+	})
+	m.discoverUPnP = func() (upnp.NAT, error) { return nat, nil }
+	m.stunAddress = func(server string, _ time.Duration) (*net.UDPAddr, error) {
+		if server == "stun1:3478" {
+			return nil, errors.New("timeout")
+		}
+		return &net.UDPAddr{IP: net.IPv4(198, 51, 100, 1), Port: 40000}, nil
+	}
+	require.NoError(t, m.Start())
+	defer m.Stop() // nolint: errcheck
+
+	select {
+	case addr := <-addrs:
+		assert.Equal(t, "198.51.100.1", addr.ip.String())
+		assert.Equal(t, 27656, addr.port, "the port mapped on the gateway")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no external address")
+	}
+}
+
+func TestMapperWithoutGateway(t *testing.T) {
+	m, addrs := newTestMapper(Config{UPnP: true, NATPMP: true, Port: 26656, Protocols: []string{"TCP"}})
+	m.refresh()
+	assert.Nil(t, m.nat)
+	assert.Empty(t, addrs, "no external address")
+
+	// the port is assumed to be forwarded
+	m.config.STUNServers = []string{"stun:3478"}
+	m.stunAddress = func(string, time.Duration) (*net.UDPAddr, error) {
+		return &net.UDPAddr{IP: net.IPv4(198, 51, 100, 1), Port: 40000}, nil
+	}
+	m.refresh()
+	require.Len(t, addrs, 1)
+	assert.Equal(t, testAddress{net.IPv4(198, 51, 100, 1), 26656}, <-addrs)
+
+	// the address is only reported when it changes
+	m.refresh()
+	assert.Empty(t, addrs)
+}
+
+func TestIsExternal(t *testing.T) {
+	for ip, external := range map[string]bool{
+		"203.0.113.7": true,
+		"2001:db8::1": true,
+		"10.1.2.3":    false,
+		"172.20.0.1":  false,
+		"192.168.1.1": false,
+		"100.64.0.1":  false,
+		"127.0.0.1":   false,
+		"fd00::1":     false,
+		"0.0.0.0":     false,
+	} {
+		assert.Equal(t, external, isExternal(net.ParseIP(ip)), ip)
+	}
+}
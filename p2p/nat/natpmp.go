@@ -0,0 +1,175 @@
+package nat
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/p2p/upnp"
+)
+
+// Just enough NAT-PMP (RFC 6886) to map ports on the gateway, for the
+// gateways which don't support UPnP.
+
+const (
+	natPMPPort = 5351
+
+	natPMPOpExternalAddress = 0
+	natPMPOpMapUDP          = 1
+	natPMPOpMapTCP          = 2
+	natPMPOpResponse        = 128
+
+	// the gateway is given 250ms to respond, doubled at each of the tries
+	natPMPTries = 4
+)
+
+type natPMP struct {
+	gateway string // host:port
+}
+
+var _ upnp.NAT = (*natPMP)(nil)
+
+// DiscoverNATPMP returns the NAT-PMP client of the default gateway, once it
+// responded with its external address.
+func DiscoverNATPMP() (upnp.NAT, error) {
+	gateway, err := defaultGateway()
+	if err != nil {
+		return nil, err
+	}
+	nat := newNATPMP(net.JoinHostPort(gateway.String(), fmt.Sprint(natPMPPort)))
+	if _, err := nat.GetExternalAddress(); err != nil {
+		return nil, err
+	}
+	return nat, nil
+}
+
+func newNATPMP(gateway string) *natPMP {
+	return &natPMP{gateway: gateway}
+}
+
+// GetExternalAddress implements upnp.NAT.
+func (n *natPMP) GetExternalAddress() (net.IP, error) {
+	resp, err := n.request([]byte{0, natPMPOpExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+// AddPortMapping implements upnp.NAT. The mapping lasts timeout seconds.
+func (n *natPMP) AddPortMapping(
+	protocol string,
+	externalPort,
+	internalPort int,
+	description string,
+	timeout int) (int, error) {
+
+	resp, err := n.mapPort(protocol, externalPort, internalPort, timeout)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(resp[10:])), nil
+}
+
+// DeletePortMapping implements upnp.NAT.
+func (n *natPMP) DeletePortMapping(protocol string, externalPort, internalPort int) error {
+	_, err := n.mapPort(protocol, 0, internalPort, 0)
+	return err
+}
+
+func (n *natPMP) mapPort(protocol string, externalPort, internalPort, lifetime int) ([]byte, error) {
+	var op byte
+	switch strings.ToLower(protocol) {
+	case "udp":
+		op = natPMPOpMapUDP
+	case "tcp":
+		op = natPMPOpMapTCP
+	default:
+		return nil, fmt.Errorf("unknown protocol %q", protocol)
+	}
+	req := make([]byte, 12)
+	req[1] = op
+	binary.BigEndian.PutUint16(req[4:], uint16(internalPort))
+	binary.BigEndian.PutUint16(req[6:], uint16(externalPort))
+	binary.BigEndian.PutUint32(req[8:], uint32(lifetime))
+	return n.request(req, 16)
+}
+
+// request sends the request to the gateway until it responds with a response
+// of size bytes, and returns it.
+func (n *natPMP) request(req []byte, size int) ([]byte, error) {
+	conn, err := net.Dial("udp", n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close() // nolint: errcheck
+
+	timeout := 250 * time.Millisecond
+	resp := make([]byte, 16)
+	for i := 0; i < natPMPTries; i++ {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+		timeout *= 2
+
+		nr, err := conn.Read(resp)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+		if nr < size || resp[0] != 0 || resp[1] != natPMPOpResponse+req[1] {
+			continue
+		}
+		if code := binary.BigEndian.Uint16(resp[2:]); code != 0 {
+			return nil, fmt.Errorf("NAT-PMP request failed with result code %d", code)
+		}
+		return resp[:size], nil
+	}
+	return nil, errors.New("no response from the NAT-PMP gateway")
+}
+
+// defaultGateway returns the IPv4 gateway of the default route (only known on
+// Linux).
+func defaultGateway() (net.IP, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, fmt.Errorf("can't find the default gateway: %v", err)
+	}
+	defer f.Close()
+	return parseDefaultGateway(f)
+}
+
+// parseDefaultGateway returns the gateway of the default route of the
+// /proc/net/route table.
+func parseDefaultGateway(r io.Reader) (net.IP, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		// in the byte order of the host, i.e. little-endian
+		gw, err := hex.DecodeString(fields[2])
+		if err != nil || len(gw) != net.IPv4len {
+			return nil, fmt.Errorf("invalid gateway %q", fields[2])
+		}
+		return net.IPv4(gw[3], gw[2], gw[1], gw[0]), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("no default route")
+}
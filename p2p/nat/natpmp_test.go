@@ -0,0 +1,97 @@
+package nat
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGateway is a NAT-PMP gateway recording the mappings requested.
+type testGateway struct {
+	conn *net.UDPConn
+
+	mtx      sync.Mutex
+	mappings map[byte]uint32 // op -> lifetime
+}
+
+func newTestGateway(t *testing.T) *testGateway {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	gw := &testGateway{conn: conn, mappings: make(map[byte]uint32)}
+	go gw.serve()
+	return gw
+}
+
+func (gw *testGateway) serve() {
+	buf := make([]byte, 64)
+	for {
+		n, from, err := gw.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		var resp []byte
+		switch op := buf[1]; {
+		case n == 2 && op == natPMPOpExternalAddress:
+			resp = make([]byte, 12)
+			copy(resp[8:], net.IPv4(203, 0, 113, 7).To4())
+		case n == 12 && (op == natPMPOpMapUDP || op == natPMPOpMapTCP):
+			resp = make([]byte, 16)
+			copy(resp[8:10], buf[4:6])                                                // internal port
+			binary.BigEndian.PutUint16(resp[10:], binary.BigEndian.Uint16(buf[6:])+1) // external port
+			copy(resp[12:], buf[8:12])                                                // lifetime
+			gw.mtx.Lock()
+			gw.mappings[op] = binary.BigEndian.Uint32(buf[8:])
+			gw.mtx.Unlock()
+		default:
+			continue
+		}
+		resp[1] = natPMPOpResponse + buf[1]
+		_, _ = gw.conn.WriteToUDP(resp, from)
+	}
+}
+
+func TestNATPMP(t *testing.T) {
+	gw := newTestGateway(t)
+	defer gw.conn.Close()
+	nat := newNATPMP(gw.conn.LocalAddr().String())
+
+	ip, err := nat.GetExternalAddress()
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip.String())
+
+	port, err := nat.AddPortMapping("TCP", 26656, 26656, "test", 1200)
+	require.NoError(t, err)
+	assert.Equal(t, 26657, port)
+	_, err = nat.AddPortMapping("udp", 26656, 26656, "test", 1200)
+	require.NoError(t, err)
+	_, err = nat.AddPortMapping("sctp", 26656, 26656, "test", 1200)
+	assert.Error(t, err)
+
+	gw.mtx.Lock()
+	assert.Equal(t, map[byte]uint32{natPMPOpMapTCP: 1200, natPMPOpMapUDP: 1200}, gw.mappings)
+	gw.mtx.Unlock()
+
+	// deleted with a lifetime of 0
+	require.NoError(t, nat.DeletePortMapping("tcp", 26657, 26656))
+	gw.mtx.Lock()
+	assert.EqualValues(t, 0, gw.mappings[natPMPOpMapTCP])
+	gw.mtx.Unlock()
+}
+
+func TestParseDefaultGateway(t *testing.T) {
+	routes := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	0000A8C0	00000000	0001	0	0	0	00FFFFFF	0	0	0
+eth0	00000000	0100A8C0	0003	0	0	0	00000000	0	0	0
+`
+	gw, err := parseDefaultGateway(strings.NewReader(routes))
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.1", gw.String())
+
+	_, err = parseDefaultGateway(strings.NewReader(strings.Split(routes, "\n")[0]))
+	assert.Error(t, err)
+}
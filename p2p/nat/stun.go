@@ -0,0 +1,170 @@
+package nat
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Just enough STUN (RFC 5389) to learn the address a UDP socket is seen from
+// by a server on the Internet: a binding request, and the (XOR-)MAPPED-ADDRESS
+// attribute of its response.
+
+const (
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunMagicCookie     = 0x2112A442
+	stunHeaderSize      = 20
+
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXORMappedAddress = 0x0020
+
+	stunFamilyIPv4 = 0x01
+	stunFamilyIPv6 = 0x02
+)
+
+// STUNExternalAddress asks the STUN server ("host:port") the address it sees
+// a UDP socket of this host from, retrying until the timeout. Behind a NAT,
+// it's the external address of the NAT (the port being the one the NAT chose
+// for this socket).
+func STUNExternalAddress(server string, timeout time.Duration) (*net.UDPAddr, error) {
+	raddr, err := net.ResolveUDPAddr("udp", server)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close() // nolint: errcheck
+
+	req := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(req[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:], stunMagicCookie)
+	if _, err := rand.Read(req[8:stunHeaderSize]); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	retry := 250 * time.Millisecond
+	buf := make([]byte, 1024)
+	for time.Now().Before(deadline) {
+		if _, err := conn.WriteToUDP(req, raddr); err != nil {
+			return nil, err
+		}
+		if err := conn.SetReadDeadline(minTime(time.Now().Add(retry), deadline)); err != nil {
+			return nil, err
+		}
+		retry *= 2
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				break // timed out: send the request again
+			}
+			if !from.IP.Equal(raddr.IP) || from.Port != raddr.Port {
+				continue
+			}
+			addr, err := parseSTUNResponse(buf[:n], req[8:stunHeaderSize])
+			if err != nil {
+				return nil, err
+			}
+			return addr, nil
+		}
+	}
+	return nil, fmt.Errorf("no response from STUN server %s", server)
+}
+
+// parseSTUNResponse returns the mapped address of the binding response to the
+// transaction txID.
+func parseSTUNResponse(msg, txID []byte) (*net.UDPAddr, error) {
+	if len(msg) < stunHeaderSize {
+		return nil, errors.New("STUN message too short")
+	}
+	if binary.BigEndian.Uint16(msg[0:]) != stunBindingResponse {
+		return nil, fmt.Errorf("unexpected STUN message type %#04x", binary.BigEndian.Uint16(msg[0:]))
+	}
+	if binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie || !bytes.Equal(msg[8:stunHeaderSize], txID) {
+		return nil, errors.New("STUN response to another transaction")
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if stunHeaderSize+length > len(msg) {
+		return nil, errors.New("STUN message truncated")
+	}
+
+	var mapped *net.UDPAddr
+	attrs := msg[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		typ := binary.BigEndian.Uint16(attrs[0:])
+		size := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+size > len(attrs) {
+			return nil, errors.New("STUN attribute truncated")
+		}
+		value := attrs[4 : 4+size]
+		switch typ {
+		case stunAttrXORMappedAddress:
+			addr, err := parseSTUNAddress(value)
+			if err != nil {
+				return nil, err
+			}
+			// the port is XORed with the most significant 16 bits of the
+			// cookie, the IP with the cookie followed by the transaction ID
+			var key [16]byte
+			binary.BigEndian.PutUint32(key[0:], stunMagicCookie)
+			copy(key[4:], txID)
+			addr.Port ^= stunMagicCookie >> 16
+			for i := range addr.IP {
+				addr.IP[i] ^= key[i]
+			}
+			return addr, nil // preferred
+		case stunAttrMappedAddress:
+			addr, err := parseSTUNAddress(value)
+			if err != nil {
+				return nil, err
+			}
+			mapped = addr
+		}
+		// attributes are padded to 4 bytes
+		next := 4 + (size+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	if mapped == nil {
+		return nil, errors.New("no mapped address in STUN response")
+	}
+	return mapped, nil
+}
+
+func parseSTUNAddress(value []byte) (*net.UDPAddr, error) {
+	if len(value) < 4 {
+		return nil, errors.New("STUN address too short")
+	}
+	var ipLen int
+	switch value[1] {
+	case stunFamilyIPv4:
+		ipLen = net.IPv4len
+	case stunFamilyIPv6:
+		ipLen = net.IPv6len
+	default:
+		return nil, fmt.Errorf("unknown STUN address family %d", value[1])
+	}
+	if len(value) < 4+ipLen {
+		return nil, errors.New("STUN address too short")
+	}
+	return &net.UDPAddr{
+		IP:   append(net.IP(nil), value[4:4+ipLen]...),
+		Port: int(binary.BigEndian.Uint16(value[2:])),
+	}, nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package nat
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveSTUN answers the binding requests with the XOR-MAPPED-ADDRESS of
+// mapped, or of the requester if nil.
+func serveSTUN(t *testing.T, mapped *net.UDPAddr) *net.UDPConn {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderSize || binary.BigEndian.Uint16(buf) != stunBindingRequest {
+				continue
+			}
+			addr := from
+			if mapped != nil {
+				addr = mapped
+			}
+			_, _ = conn.WriteToUDP(stunResponse(buf[8:stunHeaderSize], addr), from)
+		}
+	}()
+	return conn
+}
+
+func stunResponse(txID []byte, addr *net.UDPAddr) []byte {
+	ip := addr.IP.To4()
+	family := byte(stunFamilyIPv4)
+	if ip == nil {
+		ip = addr.IP.To16()
+		family = stunFamilyIPv6
+	}
+	var key [16]byte
+	binary.BigEndian.PutUint32(key[0:], stunMagicCookie)
+	copy(key[4:], txID)
+
+	value := make([]byte, 4+len(ip))
+	value[1] = family
+	binary.BigEndian.PutUint16(value[2:], uint16(addr.Port)^(stunMagicCookie>>16))
+	for i := range ip {
+		value[4+i] = ip[i] ^ key[i]
+	}
+
+	msg := make([]byte, stunHeaderSize, stunHeaderSize+4+len(value))
+	binary.BigEndian.PutUint16(msg[0:], stunBindingResponse)
+	binary.BigEndian.PutUint16(msg[2:], uint16(4+len(value)))
+	binary.BigEndian.PutUint32(msg[4:], stunMagicCookie)
+	copy(msg[8:], txID)
+	msg = append(msg, 0, stunAttrXORMappedAddress, 0, byte(len(value)))
+	return append(msg, value...)
+}
+
+func TestSTUNExternalAddress(t *testing.T) {
+	server := serveSTUN(t, nil)
+	defer server.Close()
+
+	addr, err := STUNExternalAddress(server.LocalAddr().String(), time.Second)
+	require.NoError(t, err)
+	assert.True(t, addr.IP.Equal(net.IPv4(127, 0, 0, 1)), addr.IP)
+	assert.NotZero(t, addr.Port)
+
+	// no server
+	_, err = STUNExternalAddress("127.0.0.1:1", 300*time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestParseSTUNResponse(t *testing.T) {
+	txID := []byte("0123456789ab")
+	for _, addr := range []*net.UDPAddr{
+		{IP: net.ParseIP("203.0.113.7").To4(), Port: 26656},
+		{IP: net.ParseIP("2001:db8::1"), Port: 443},
+	} {
+		parsed, err := parseSTUNResponse(stunResponse(txID, addr), txID)
+		require.NoError(t, err)
+		assert.Equal(t, addr.String(), parsed.String())
+	}
+
+	msg := stunResponse(txID, &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 1})
+	_, err := parseSTUNResponse(msg, []byte("another tx i"))
+	assert.Error(t, err)
+	_, err = parseSTUNResponse(msg[:len(msg)-2], txID)
+	assert.Error(t, err)
+	_, err = parseSTUNResponse(msg[:stunHeaderSize-1], txID)
+	assert.Error(t, err)
+}
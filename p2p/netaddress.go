@@ -38,15 +38,23 @@ func IDAddressString(id ID, protocolHostPort string) string {
 }
 
 // NewNetAddress returns a new NetAddress using the provided TCP
-// address. When testing, other net.Addr (except TCP) will result in
-// using 0.0.0.0:0. When normal run, other net.Addr (except TCP) will
-// panic. Panics if ID is invalid.
+// address, or UDP address (of QUIC connections). When testing, other
+// net.Addr will result in using 0.0.0.0:0. When normal run, other net.Addr
+// will panic. Panics if ID is invalid.
 // TODO: socks proxies?
 func NewNetAddress(id ID, addr net.Addr) *NetAddress {
-	tcpAddr, ok := addr.(*net.TCPAddr)
-	if !ok {
+	var (
+		ip   net.IP
+		port int
+	)
+	switch addr := addr.(type) {
+	case *net.TCPAddr:
+		ip, port = addr.IP, addr.Port
+	case *net.UDPAddr:
+		ip, port = addr.IP, addr.Port
+	default:
 		if flag.Lookup("test.v") == nil { // normal run
-			panic(fmt.Sprintf("Only TCPAddrs are supported. Got: %v", addr))
+			panic(fmt.Sprintf("Only TCPAddrs and UDPAddrs are supported. Got: %v", addr))
 		} else { // in testing
 			netAddr := NewNetAddressIPPort(net.IP("127.0.0.1"), 0)
 			netAddr.ID = id
@@ -58,9 +66,7 @@ func NewNetAddress(id ID, addr net.Addr) *NetAddress {
 		panic(fmt.Sprintf("Invalid ID %v: %v (addr: %v)", id, err, addr))
 	}
 
-	ip := tcpAddr.IP
-	port := uint16(tcpAddr.Port)
-	na := NewNetAddressIPPort(ip, port)
+	na := NewNetAddressIPPort(ip, uint16(port))
 	na.ID = id
 	return na
 }
@@ -203,6 +209,21 @@ func (na *NetAddress) DialTimeout(timeout time.Duration) (net.Conn, error) {
 	return conn, nil
 }
 
+// Subnet returns the /24 subnet of an IPv4 address, or the /64 subnet of an
+// IPv6 address, e.g. "203.0.113.0/24", which the quotas of peers per subnet
+// apply to. It returns "" for local and private addresses, which are exempt.
+func (na *NetAddress) Subnet() string {
+	if na == nil || na.IP == nil || na.Local() || na.RFC1918() || na.RFC3927() || na.RFC4193() || na.RFC4862() {
+		return ""
+	}
+	mask := net.CIDRMask(64, 8*net.IPv6len)
+	if na.IP.To4() != nil {
+		mask = net.CIDRMask(24, 8*net.IPv4len)
+	}
+	subnet := net.IPNet{IP: na.IP.Mask(mask), Mask: mask}
+	return subnet.String()
+}
+
 // Routable returns true if the address is routable.
 func (na *NetAddress) Routable() bool {
 	if err := na.Valid(); err != nil {
@@ -19,9 +19,13 @@ func TestNewNetAddress(t *testing.T) {
 	addr := NewNetAddress("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", tcpAddr)
 	assert.Equal(t, "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef@127.0.0.1:8080", addr.String())
 
+	// the address of a QUIC connection
+	addr = NewNetAddress("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8000})
+	assert.Equal(t, "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef@127.0.0.1:8000", addr.String())
+
 	assert.NotPanics(t, func() {
-		NewNetAddress("", &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 8000})
-	}, "Calling NewNetAddress with UDPAddr should not panic in testing")
+		NewNetAddress("", &net.IPAddr{IP: net.ParseIP("127.0.0.1")})
+	}, "Calling NewNetAddress with IPAddr should not panic in testing")
 }
 
 func TestNewNetAddressString(t *testing.T) {
@@ -167,3 +171,23 @@ func TestNetAddressReachabilityTo(t *testing.T) {
 		assert.Equal(t, tc.reachability, addr.ReachabilityTo(other))
 	}
 }
+
+func TestNetAddressSubnet(t *testing.T) {
+	testCases := []struct {
+		ip     string
+		subnet string
+	}{
+		{"203.0.113.7", "203.0.113.0/24"},
+		{"2001:db8:1:2:3:4:5:6", "2001:db8:1:2::/64"},
+		{"::ffff:198.51.100.1", "198.51.100.0/24"},
+		{"127.0.0.1", ""},
+		{"10.1.2.3", ""},
+		{"192.168.1.1", ""},
+		{"fd00::1", ""},
+	}
+
+	for _, tc := range testCases {
+		addr := NewNetAddressIPPort(net.ParseIP(tc.ip), 26656)
+		assert.Equal(t, tc.subnet, addr.Subnet(), tc.ip)
+	}
+}
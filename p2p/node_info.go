@@ -90,6 +90,11 @@ type DefaultNodeInfo struct {
 	// ASCIIText fields
 	Moniker string               `json:"moniker"` // arbitrary moniker
 	Other   DefaultNodeInfoOther `json:"other"`   // other application specific data
+
+	// Channels on which this node compresses msgs (see
+	// conn.MConnConfig.CompressedChannels), if the peer does too.
+	// Last, so that nodes which don't know of it ignore it.
+	CompressedChannels bytes.HexBytes `json:"compressed_channels"`
 }
 
 // DefaultNodeInfoOther is the misc. applcation specific data
@@ -147,6 +152,11 @@ func (info DefaultNodeInfo) Validate() error {
 		}
 		channels[ch] = struct{}{}
 	}
+	for _, ch := range info.CompressedChannels {
+		if _, ok := channels[ch]; !ok {
+			return fmt.Errorf("info.CompressedChannels contains unknown channel id %v", ch)
+		}
+	}
 
 	// Validate Moniker.
 	if !tmstrings.IsASCIIText(info.Moniker) || tmstrings.ASCIITrim(info.Moniker) == "" {
@@ -39,6 +39,13 @@ func TestNodeInfoValidate(t *testing.T) {
 		{"Duplicate Channel", func(ni *DefaultNodeInfo) { ni.Channels = dupChannels }, true},
 		{"Good Channels", func(ni *DefaultNodeInfo) { ni.Channels = ni.Channels[:5] }, false},
 
+		{
+			"Unknown Compressed Channel",
+			func(ni *DefaultNodeInfo) { ni.CompressedChannels = []byte{byte(maxNumChannels)} },
+			true,
+		},
+		{"Good Compressed Channels", func(ni *DefaultNodeInfo) { ni.CompressedChannels = ni.Channels[:2] }, false},
+
 		{"Invalid NetAddress", func(ni *DefaultNodeInfo) { ni.ListenAddr = "not-an-address" }, true},
 		{"Good NetAddress", func(ni *DefaultNodeInfo) { ni.ListenAddr = "0.0.0.0:26656" }, false},
 
@@ -39,6 +39,19 @@ type Peer interface {
 	Get(string) interface{}
 }
 
+// peerMConnection multiplexes the channels of a peer over its connection: an
+// MConnection, or a quicMConnection carrying every channel on a QUIC stream of
+// its own.
+type peerMConnection interface {
+	service.Service
+	FlushStop()
+
+	Status() tmconn.ConnectionStatus
+	Send(byte, []byte) bool
+	TrySend(byte, []byte) bool
+	CanSend(byte) bool
+}
+
 //----------------------------------------------------------
 
 // peerConn contains the raw connection and its config.
@@ -102,7 +115,7 @@ type peer struct {
 
 	// raw peerConn and the multiplex connection
 	peerConn
-	mconn *tmconn.MConnection
+	mconn peerMConnection
 
 	// peer's node info and the channel it knows about
 	// channels = nodeInfo.Channels
@@ -110,8 +123,9 @@ type peer struct {
 	nodeInfo NodeInfo
 	channels []byte
 
-	// User data
-	Data *cmap.CMap
+	// User data, read by the routines of every reactor (e.g. the consensus
+	// peer state), so under a read-write lock
+	Data *cmap.ShardedMap
 
 	metrics       *Metrics
 	metricsTicker *time.Ticker
@@ -132,19 +146,30 @@ func newPeer(
 		peerConn:      pc,
 		nodeInfo:      nodeInfo,
 		channels:      nodeInfo.(DefaultNodeInfo).Channels, // TODO
-		Data:          cmap.NewCMap(),
+		Data:          cmap.NewShardedMap(1),
 		metricsTicker: time.NewTicker(metricsTickerDuration),
 		metrics:       NopMetrics(),
 	}
 
-	p.mconn = createMConnection(
-		pc.conn,
-		p,
-		reactorsByCh,
-		chDescs,
-		onPeerError,
-		mConfig,
-	)
+	if qc, ok := pc.conn.(*quicConn); ok {
+		p.mconn = newQUICMConnection(
+			qc,
+			p,
+			reactorsByCh,
+			chDescs,
+			onPeerError,
+			mConfig,
+		)
+	} else {
+		p.mconn = createMConnection(
+			pc.conn,
+			p,
+			reactorsByCh,
+			chDescs,
+			onPeerError,
+			mConfig,
+		)
+	}
 	p.BaseService = *service.NewBaseService(nil, "Peer", p)
 	for _, option := range options {
 		option(p)
@@ -350,9 +375,17 @@ func (p *peer) metricsReporter() {
 			var sendQueueSize float64
 			for _, chStatus := range status.Channels {
 				sendQueueSize += float64(chStatus.SendQueueSize)
+				p.metrics.PeerSendQueueSize.With(
+					"peer_id", string(p.ID()),
+					"chID", fmt.Sprintf("%#x", chStatus.ID),
+				).Set(float64(chStatus.SendQueueSize))
 			}
 
 			p.metrics.PeerPendingSendBytes.With("peer_id", string(p.ID())).Set(sendQueueSize)
+			if status.RTT > 0 {
+				p.metrics.PeerRTTSeconds.With("peer_id", string(p.ID())).Set(status.RTT.Seconds())
+			}
+			p.metrics.PeerConnectionAgeSeconds.With("peer_id", string(p.ID())).Set(status.Duration.Seconds())
 		case <-p.Quit():
 			return
 		}
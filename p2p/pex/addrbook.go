@@ -9,8 +9,8 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-	"math/rand"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
@@ -67,6 +67,12 @@ type AddrBook interface {
 	GetSelection() []*p2p.NetAddress
 	// Send a selection of addresses with bias
 	GetSelectionWithBias(biasTowardsNewAddrs int) []*p2p.NetAddress
+	// Get the addresses the most likely to be reachable, best first
+	BestAddresses(n int) []*p2p.NetAddress
+
+	// Record, and get, the outbound peers to dial first after a restart
+	SetAnchors([]*p2p.NetAddress)
+	Anchors() []*p2p.NetAddress
 
 	Size() int
 
@@ -91,6 +97,7 @@ type addrBook struct {
 	bucketsNew []map[string]*knownAddress
 	nOld       int
 	nNew       int
+	anchors    []*p2p.NetAddress
 
 	// immutable after creation
 	filePath          string
@@ -363,7 +370,7 @@ func (a *addrBook) GetSelection() []*p2p.NetAddress {
 	// `numAddresses' since we are throwing the rest.
 	for i := 0; i < numAddresses; i++ {
 		// pick a number between current index and the end
-		j := tmrand.Intn(len(allAddr)-i) + i
+		j := a.rand.Intn(len(allAddr)-i) + i
 		allAddr[i], allAddr[j] = allAddr[j], allAddr[i]
 	}
 
@@ -415,6 +422,55 @@ func (a *addrBook) GetSelectionWithBias(biasTowardsNewAddrs int) []*p2p.NetAddre
 	return selection
 }
 
+// BestAddresses implements AddrBook - it returns up to n of the addresses
+// which were connected to, ordered by score (see knownAddress.score), the
+// addresses of equal score in random order. Suitable for seeds answering
+// DNS queries.
+func (a *addrBook) BestAddresses(n int) []*p2p.NetAddress {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	addresses := make([]*knownAddress, 0, a.nOld)
+	for _, ka := range a.addrLookup {
+		if ka.isOld() {
+			addresses = append(addresses, ka)
+		}
+	}
+	a.rand.Shuffle(len(addresses), func(i, j int) {
+		addresses[i], addresses[j] = addresses[j], addresses[i]
+	})
+	sort.SliceStable(addresses, func(i, j int) bool {
+		return addresses[i].score() > addresses[j].score()
+	})
+
+	best := make([]*p2p.NetAddress, 0, tmmath.MinInt(n, len(addresses)))
+	for _, ka := range addresses {
+		if len(best) >= n {
+			break
+		}
+		best = append(best, ka.Addr)
+	}
+	return best
+}
+
+// SetAnchors implements AddrBook - it records the addresses of the outbound
+// peers to reconnect to first after a restart, so that a node can't be
+// eclipsed by flooding its book while it is offline.
+func (a *addrBook) SetAnchors(addrs []*p2p.NetAddress) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.anchors = append([]*p2p.NetAddress(nil), addrs...)
+}
+
+// Anchors implements AddrBook.
+func (a *addrBook) Anchors() []*p2p.NetAddress {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return append([]*p2p.NetAddress(nil), a.anchors...)
+}
+
 //------------------------------------------------
 
 // Size returns the number of addresses in the book.
@@ -466,8 +522,8 @@ func (a *addrBook) getBucket(bucketType byte, bucketIdx int) map[string]*knownAd
 	}
 }
 
-// Adds ka to new bucket. Returns false if it couldn't do it cuz buckets full.
-// NOTE: currently it always returns true.
+// Adds ka to new bucket, unless it is picked to be evicted from the bucket
+// when full (see expireNew).
 func (a *addrBook) addToNewBucket(ka *knownAddress, bucketIdx int) {
 	// Sanity check
 	if ka.isOld() {
@@ -484,9 +540,11 @@ func (a *addrBook) addToNewBucket(ka *knownAddress, bucketIdx int) {
 	}
 
 	// Enforce max addresses.
-	if len(bucket) > newBucketSize {
+	if len(bucket) >= newBucketSize {
 		a.Logger.Info("new bucket is full, expiring new")
-		a.expireNew(bucketIdx)
+		if !a.expireNew(bucketIdx, ka) {
+			return
+		}
 	}
 
 	// Add to bucket.
@@ -521,7 +579,7 @@ func (a *addrBook) addToOldBucket(ka *knownAddress, bucketIdx int) bool {
 	}
 
 	// Enforce max addresses.
-	if len(bucket) > oldBucketSize {
+	if len(bucket) >= oldBucketSize {
 		return false
 	}
 
@@ -570,15 +628,43 @@ func (a *addrBook) removeFromAllBuckets(ka *knownAddress) {
 
 //----------------------------------------------------------
 
-func (a *addrBook) pickOldest(bucketType byte, bucketIdx int) *knownAddress {
-	bucket := a.getBucket(bucketType, bucketIdx)
-	var oldest *knownAddress
-	for _, ka := range bucket {
-		if oldest == nil || ka.LastAttempt.Before(oldest.LastAttempt) {
-			oldest = ka
+// pickEvicted picks the address to evict from a full bucket to make room for
+// ka (which may be nil, or picked itself). It is picked at random among the
+// addresses of the group (see groupOf) the most represented in the bucket,
+// so that the addresses of a group, e.g. all announced by a single source, only
+// replace each other once they outnumber those of any other group.
+func (a *addrBook) pickEvicted(
+	bucket map[string]*knownAddress,
+	ka *knownAddress,
+	groupOf func(*knownAddress) string,
+) *knownAddress {
+	byGroup := make(map[string][]*knownAddress)
+	for _, other := range bucket {
+		group := groupOf(other)
+		byGroup[group] = append(byGroup[group], other)
+	}
+	if ka != nil {
+		group := groupOf(ka)
+		byGroup[group] = append(byGroup[group], ka)
+	}
+	var largest []*knownAddress
+	for _, kas := range byGroup {
+		if len(kas) > len(largest) {
+			largest = kas
 		}
 	}
-	return oldest
+	if len(largest) == 0 {
+		return nil
+	}
+	return largest[a.rand.Intn(len(largest))]
+}
+
+func (a *addrBook) srcGroupKey(ka *knownAddress) string {
+	return a.groupKey(ka.Src)
+}
+
+func (a *addrBook) addrGroupKey(ka *knownAddress) string {
+	return a.groupKey(ka.Addr)
 }
 
 // adds the address to a "new" bucket. if its already in one,
@@ -655,7 +741,7 @@ func (a *addrBook) randomPickAddresses(bucketType byte, num int) []*p2p.NetAddre
 	}
 	selection := make([]*p2p.NetAddress, 0, num)
 	chosenSet := make(map[string]bool, num)
-	rand.Shuffle(total, func(i, j int) {
+	a.rand.Shuffle(total, func(i, j int) {
 		addresses[i], addresses[j] = addresses[j], addresses[i]
 	})
 	for _, addr := range addresses {
@@ -671,26 +757,31 @@ func (a *addrBook) randomPickAddresses(bucketType byte, num int) []*p2p.NetAddre
 	return selection
 }
 
-// Make space in the new buckets by expiring the really bad entries.
-// If no bad entries are available we remove the oldest.
-func (a *addrBook) expireNew(bucketIdx int) {
-	for addrStr, ka := range a.bucketsNew[bucketIdx] {
+// Make space in the new buckets for ka by expiring the really bad entries.
+// If no bad entries are available we remove one from the source group the
+// most represented in the bucket (see pickEvicted), which may be ka itself:
+// it returns false if ka is not to be added.
+func (a *addrBook) expireNew(bucketIdx int, ka *knownAddress) bool {
+	for addrStr, other := range a.bucketsNew[bucketIdx] {
 		// If an entry is bad, throw it away
-		if ka.isBad() {
+		if other.isBad() {
 			a.Logger.Info(fmt.Sprintf("expiring bad address %v", addrStr))
-			a.removeFromBucket(ka, bucketTypeNew, bucketIdx)
-			return
+			a.removeFromBucket(other, bucketTypeNew, bucketIdx)
+			return true
 		}
 	}
 
-	// If we haven't thrown out a bad entry, throw out the oldest entry
-	oldest := a.pickOldest(bucketTypeNew, bucketIdx)
-	a.removeFromBucket(oldest, bucketTypeNew, bucketIdx)
+	evicted := a.pickEvicted(a.bucketsNew[bucketIdx], ka, a.srcGroupKey)
+	if evicted == ka {
+		return false
+	}
+	a.removeFromBucket(evicted, bucketTypeNew, bucketIdx)
+	return true
 }
 
 // Promotes an address from new to old. If the destination bucket is full,
-// demote the oldest one to a "new" bucket.
-// TODO: Demote more probabilistically?
+// demote one of the address group the most represented in the bucket (see
+// pickEvicted) to a "new" bucket.
 func (a *addrBook) moveToOld(ka *knownAddress) {
 	// Sanity check
 	if ka.isOld() {
@@ -711,11 +802,12 @@ func (a *addrBook) moveToOld(ka *knownAddress) {
 	oldBucketIdx := a.calcOldBucket(ka.Addr)
 	added := a.addToOldBucket(ka, oldBucketIdx)
 	if !added {
-		// No room; move the oldest to a new bucket
-		oldest := a.pickOldest(bucketTypeOld, oldBucketIdx)
-		a.removeFromBucket(oldest, bucketTypeOld, oldBucketIdx)
-		newBucketIdx := a.calcNewBucket(oldest.Addr, oldest.Src)
-		a.addToNewBucket(oldest, newBucketIdx)
+		// No room; move one to a new bucket
+		demoted := a.pickEvicted(a.bucketsOld[oldBucketIdx], nil, a.addrGroupKey)
+		a.removeFromBucket(demoted, bucketTypeOld, oldBucketIdx)
+		demoted.BucketType = bucketTypeNew
+		newBucketIdx := a.calcNewBucket(demoted.Addr, demoted.Src)
+		a.addToNewBucket(demoted, newBucketIdx)
 
 		// Finally, add our ka to old bucket again.
 		added = a.addToOldBucket(ka, oldBucketIdx)
@@ -782,17 +874,17 @@ func (a *addrBook) groupKey(na *p2p.NetAddress) string {
 	}
 
 	if ipv4 := na.IP.To4(); ipv4 != nil {
-		return (&net.IPNet{IP: na.IP, Mask: net.CIDRMask(16, 32)}).String()
+		return ipGroup(na.IP, net.CIDRMask(16, 32))
 	}
 	if na.RFC6145() || na.RFC6052() {
 		// last four bytes are the ip address
 		ip := na.IP[12:16]
-		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(16, 32)}).String()
+		return ipGroup(ip, net.CIDRMask(16, 32))
 	}
 
 	if na.RFC3964() {
-		ip := na.IP[2:7]
-		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(16, 32)}).String()
+		ip := na.IP[2:6]
+		return ipGroup(ip, net.CIDRMask(16, 32))
 
 	}
 	if na.RFC4380() {
@@ -802,7 +894,7 @@ func (a *addrBook) groupKey(na *p2p.NetAddress) string {
 		for i, byte := range na.IP[12:16] {
 			ip[i] = byte ^ 0xff
 		}
-		return (&net.IPNet{IP: ip, Mask: net.CIDRMask(16, 32)}).String()
+		return ipGroup(ip, net.CIDRMask(16, 32))
 	}
 
 	// OK, so now we know ourselves to be a IPv6 address.
@@ -815,7 +907,15 @@ func (a *addrBook) groupKey(na *p2p.NetAddress) string {
 		bits = 36
 	}
 
-	return (&net.IPNet{IP: na.IP, Mask: net.CIDRMask(bits, 128)}).String()
+	return ipGroup(na.IP, net.CIDRMask(bits, 128))
+}
+
+// ipGroup returns the network of ip with the given mask, e.g. "1.2.0.0/16".
+func ipGroup(ip net.IP, mask net.IPMask) string {
+	if len(mask) == net.IPv4len {
+		ip = ip.To4()
+	}
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
 }
 
 // doubleSha256 calculates sha256(sha256(b)) and returns the resulting bytes.
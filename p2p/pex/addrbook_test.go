@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math"
+	"net"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -638,3 +640,119 @@ func analyseSelectionLayout(book *addrBook, addrs []*p2p.NetAddress) (seqLens, s
 
 	return
 }
+
+func TestAddrBookNewBucketFloodedBySingleSource(t *testing.T) {
+	fname := createTempFileName("addrbook_test")
+	defer deleteTempFile(fname)
+
+	book := NewAddrBook(fname, true).(*addrBook)
+	book.SetLogger(log.TestingLogger())
+
+	// half a bucket of addresses, each from a source of its own group
+	const bucketIdx = 0
+	var honest []*knownAddress
+	for i := 0; i < newBucketSize/2; i++ {
+		src := p2p.NewNetAddressIPPort(net.IPv4(byte(i+1), 1, 1, 1), 26656)
+		ka := newKnownAddress(randIPv4Address(t), src)
+		book.addToNewBucket(ka, bucketIdx)
+		honest = append(honest, ka)
+	}
+
+	// flooded by addresses from a single source
+	src := randIPv4Address(t)
+	for i := 0; i < 10*newBucketSize; i++ {
+		book.addToNewBucket(newKnownAddress(randIPv4Address(t), src), bucketIdx)
+	}
+
+	assert.Len(t, book.bucketsNew[bucketIdx], newBucketSize)
+	for _, ka := range honest {
+		assert.True(t, book.HasAddress(ka.Addr), "address %v was evicted", ka.Addr)
+	}
+}
+
+func TestAddrBookPromoteToFullOldBucket(t *testing.T) {
+	fname := createTempFileName("addrbook_test")
+	defer deleteTempFile(fname)
+
+	book := NewAddrBook(fname, true).(*addrBook)
+	book.SetLogger(log.TestingLogger())
+
+	// the addresses of a group fill at most oldBucketsPerGroup old buckets
+	n := oldBucketsPerGroup*oldBucketSize + 10
+	for i := 0; i < n; i++ {
+		addr := p2p.NewNetAddressIPPort(net.IPv4(1, 2, byte(i/256), byte(i%256)), 26656)
+		addr.ID = p2p.ID(hex.EncodeToString(tmrand.Bytes(p2p.IDByteLength)))
+		require.NoError(t, book.AddAddress(addr, randIPv4Address(t)))
+		book.MarkGood(addr.ID)
+	}
+
+	// the addresses demoted to make room are still in the book
+	assert.Equal(t, n, book.Size())
+	assert.True(t, book.nOld <= oldBucketsPerGroup*oldBucketSize, "%d old addresses", book.nOld)
+	assert.True(t, book.nNew >= 10, "%d new addresses", book.nNew)
+}
+
+func TestAddrBookSaveLoadAnchors(t *testing.T) {
+	fname := createTempFileName("addrbook_test")
+	defer deleteTempFile(fname)
+
+	book := NewAddrBook(fname, true).(*addrBook)
+	book.SetLogger(log.TestingLogger())
+	assert.Empty(t, book.Anchors())
+
+	anchors := []*p2p.NetAddress{randIPv4Address(t), randIPv4Address(t)}
+	book.SetAnchors(anchors)
+	book.saveToFile(fname)
+
+	book = NewAddrBook(fname, true).(*addrBook)
+	book.SetLogger(log.TestingLogger())
+	book.loadFromFile(fname)
+	assert.Equal(t, anchors, book.Anchors())
+}
+
+func TestAddrBookGroupKey(t *testing.T) {
+	book := NewAddrBook(createTempFileName("addrbook_test"), true).(*addrBook)
+	defer deleteTempFile(book.FilePath())
+
+	testCases := []struct {
+		ip    string
+		group string
+	}{
+		{"1.2.3.4", "1.2.0.0/16"},
+		{"1.2.200.201", "1.2.0.0/16"},
+		{"127.0.0.1", "local"},
+		{"10.0.0.1", "unroutable"},
+		{"2002:0102:0304::1", "1.2.0.0/16"}, // RFC3964
+		{"2a00:1450:4001:81b::200e", "2a00:1450::/32"},
+		{"2001:470:1f0b::1", "2001:470:1000::/36"}, // he.net
+	}
+	for _, tc := range testCases {
+		addr := p2p.NewNetAddressIPPort(net.ParseIP(tc.ip), 26656)
+		addr.ID = p2p.ID(hex.EncodeToString(tmrand.Bytes(p2p.IDByteLength)))
+		assert.Equal(t, tc.group, book.groupKey(addr), tc.ip)
+	}
+}
+
+func TestAddrBookBestAddresses(t *testing.T) {
+	book, fname := createAddrBookWithMOldAndNNewAddrs(t, 3, 5)
+	defer deleteTempFile(fname)
+
+	var old []*knownAddress
+	for _, ka := range book.addrLookup {
+		if ka.isOld() {
+			old = append(old, ka)
+		}
+	}
+	require.Len(t, old, 3)
+	old[0].LastSuccess = time.Now().Add(-48 * time.Hour)
+	old[1].Attempts = 2
+
+	// only the addresses which were connected to, the best first
+	best := book.BestAddresses(10)
+	require.Len(t, best, 3)
+	assert.Equal(t, old[2].Addr, best[0])
+	assert.Equal(t, old[1].Addr, best[1])
+	assert.Equal(t, old[0].Addr, best[2])
+
+	assert.Len(t, book.BestAddresses(2), 2)
+}
@@ -0,0 +1,203 @@
+package pex
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/p2p"
+)
+
+const (
+	// time the answers are cached for, by the resolvers and by the DNSSeed
+	dnsSeedTTL = time.Minute
+
+	// number of addresses picked from the book every dnsSeedTTL
+	dnsSeedNumAddrs = 64
+
+	// max number of records in an answer, for it to fit in the 512 bytes of a
+	// DNS message over UDP
+	dnsSeedMaxIPs  = 16
+	dnsSeedMaxTXTs = 4
+
+	dnsMaxMsgSize = 512
+)
+
+// DNSSeed answers the DNS queries for a domain with the best addresses of the
+// address book (see AddrBook.BestAddresses), so that nodes can discover peers
+// by looking the domain up:
+//   - A and AAAA queries are answered with the IPs of the addresses
+//   - TXT queries are answered with the addresses, "id@ip:port", as the ports
+//     of the peers can't be known from their IPs
+//
+// It only answers over UDP, and refuses the queries for other domains.
+type DNSSeed struct {
+	service.BaseService
+
+	book   AddrBook
+	domain string // fully qualified
+	laddr  string
+
+	conn net.PacketConn
+
+	// only accessed by serveRoutine
+	addrs     []*p2p.NetAddress
+	addrsTime time.Time
+}
+
+// NewDNSSeed returns a DNSSeed answering the queries for domain on the UDP
+// address laddr (e.g. "0.0.0.0:53").
+func NewDNSSeed(book AddrBook, domain, laddr string) (*DNSSeed, error) {
+	if !strings.HasSuffix(domain, ".") {
+		domain += "."
+	}
+	if _, err := dnsmessage.NewName(domain); err != nil {
+		return nil, errors.Wrap(err, "invalid domain")
+	}
+	s := &DNSSeed{
+		book:   book,
+		domain: domain,
+		laddr:  laddr,
+	}
+	s.BaseService = *service.NewBaseService(nil, "DNSSeed", s)
+	return s, nil
+}
+
+// OnStart implements service.Service.
+func (s *DNSSeed) OnStart() error {
+	conn, err := net.ListenPacket("udp", s.laddr)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	go s.serveRoutine()
+	return nil
+}
+
+// OnStop implements service.Service.
+func (s *DNSSeed) OnStop() {
+	s.conn.Close()
+}
+
+// Addr returns the address the DNSSeed listens on, once started.
+func (s *DNSSeed) Addr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+func (s *DNSSeed) serveRoutine() {
+	buf := make([]byte, dnsMaxMsgSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if !s.IsRunning() {
+				return
+			}
+			s.Logger.Error("Failed to read DNS query", "err", err)
+			continue
+		}
+		resp, err := s.answer(buf[:n])
+		if err != nil {
+			s.Logger.Debug("Invalid DNS query", "from", addr, "err", err)
+			continue
+		}
+		if _, err := s.conn.WriteTo(resp, addr); err != nil {
+			s.Logger.Debug("Failed to write DNS answer", "to", addr, "err", err)
+		}
+	}
+}
+
+// answer returns the response to the DNS query.
+func (s *DNSSeed) answer(query []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(query)
+	if err != nil {
+		return nil, err
+	}
+	if h.Response {
+		return nil, errors.New("not a query")
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+
+	rh := dnsmessage.Header{
+		ID:               h.ID,
+		Response:         true,
+		OpCode:           h.OpCode,
+		Authoritative:    true,
+		RecursionDesired: h.RecursionDesired,
+	}
+	switch {
+	case h.OpCode != 0:
+		rh.RCode = dnsmessage.RCodeNotImplemented
+	case !strings.EqualFold(q.Name.String(), s.domain):
+		rh.Authoritative = false
+		rh.RCode = dnsmessage.RCodeRefused
+	}
+
+	b := dnsmessage.NewBuilder(make([]byte, 0, dnsMaxMsgSize), rh)
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	if rh.RCode == dnsmessage.RCodeSuccess && q.Class == dnsmessage.ClassINET {
+		if err := s.addAnswers(&b, q); err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+func (s *DNSSeed) addAnswers(b *dnsmessage.Builder, q dnsmessage.Question) error {
+	if time.Since(s.addrsTime) > dnsSeedTTL {
+		s.addrs = s.book.BestAddresses(dnsSeedNumAddrs)
+		s.addrsTime = time.Now()
+	}
+
+	rh := dnsmessage.ResourceHeader{
+		Name:  q.Name,
+		Class: dnsmessage.ClassINET,
+		TTL:   uint32(dnsSeedTTL / time.Second),
+	}
+	max := dnsSeedMaxIPs
+	if q.Type == dnsmessage.TypeTXT {
+		max = dnsSeedMaxTXTs
+	}
+	n := 0
+	for _, addr := range s.addrs {
+		if n >= max {
+			break
+		}
+		var err error
+		switch ip4 := addr.IP.To4(); {
+		case q.Type == dnsmessage.TypeA && ip4 != nil:
+			r := dnsmessage.AResource{}
+			copy(r.A[:], ip4)
+			err = b.AResource(rh, r)
+		case q.Type == dnsmessage.TypeAAAA && ip4 == nil:
+			r := dnsmessage.AAAAResource{}
+			copy(r.AAAA[:], addr.IP.To16())
+			err = b.AAAAResource(rh, r)
+		case q.Type == dnsmessage.TypeTXT:
+			err = b.TXTResource(rh, dnsmessage.TXTResource{TXT: []string{addr.String()}})
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		n++
+	}
+	return nil
+}
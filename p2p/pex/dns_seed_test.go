@@ -0,0 +1,99 @@
+package pex
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func queryDNSSeed(t *testing.T, addr net.Addr, name string, qtype dnsmessage.Type) dnsmessage.Message {
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	bz, err := query.Pack()
+	require.NoError(t, err)
+
+	conn, err := net.Dial("udp", addr.String())
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write(bz)
+	require.NoError(t, err)
+
+	buf := make([]byte, dnsMaxMsgSize)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	var resp dnsmessage.Message
+	require.NoError(t, resp.Unpack(buf[:n]))
+	assert.EqualValues(t, 42, resp.ID)
+	return resp
+}
+
+func TestDNSSeed(t *testing.T) {
+	book, fname := createAddrBookWithMOldAndNNewAddrs(t, 20, 10)
+	defer deleteTempFile(fname)
+
+	seed, err := NewDNSSeed(book, "seed.example.com", "127.0.0.1:0")
+	require.NoError(t, err)
+	seed.SetLogger(log.TestingLogger())
+	require.NoError(t, seed.Start())
+	defer seed.Stop() // nolint:errcheck
+
+	// the IPs of the addresses which were connected to
+	resp := queryDNSSeed(t, seed.Addr(), "SEED.example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.RCode)
+	assert.True(t, resp.Authoritative)
+	require.Len(t, resp.Answers, dnsSeedMaxIPs)
+	for _, answer := range resp.Answers {
+		a := answer.Body.(*dnsmessage.AResource)
+		ka := findKnownAddressByIP(book, net.IP(a.A[:]))
+		require.NotNil(t, ka)
+		assert.True(t, ka.isOld())
+	}
+
+	// the addresses themselves
+	resp = queryDNSSeed(t, seed.Addr(), "seed.example.com.", dnsmessage.TypeTXT)
+	require.Len(t, resp.Answers, dnsSeedMaxTXTs)
+	for _, answer := range resp.Answers {
+		txt := answer.Body.(*dnsmessage.TXTResource)
+		require.Len(t, txt.TXT, 1)
+		var ka *knownAddress
+		for _, k := range book.addrLookup {
+			if k.Addr.String() == txt.TXT[0] {
+				ka = k
+			}
+		}
+		require.NotNil(t, ka, txt.TXT[0])
+		assert.True(t, ka.isOld())
+	}
+
+	// no IPv6 addresses
+	resp = queryDNSSeed(t, seed.Addr(), "seed.example.com.", dnsmessage.TypeAAAA)
+	assert.Equal(t, dnsmessage.RCodeSuccess, resp.RCode)
+	assert.Empty(t, resp.Answers)
+
+	// other domains are refused
+	resp = queryDNSSeed(t, seed.Addr(), "example.com.", dnsmessage.TypeA)
+	assert.Equal(t, dnsmessage.RCodeRefused, resp.RCode)
+	assert.Empty(t, resp.Answers)
+}
+
+func findKnownAddressByIP(book *addrBook, ip net.IP) *knownAddress {
+	for _, ka := range book.addrLookup {
+		if ka.Addr.IP.Equal(ip) {
+			return ka
+		}
+	}
+	return nil
+}
@@ -6,13 +6,15 @@ import (
 	"os"
 
 	"github.com/tendermint/tendermint/libs/tempfile"
+	"github.com/tendermint/tendermint/p2p"
 )
 
 /* Loading & Saving */
 
 type addrBookJSON struct {
-	Key   string          `json:"key"`
-	Addrs []*knownAddress `json:"addrs"`
+	Key     string            `json:"key"`
+	Addrs   []*knownAddress   `json:"addrs"`
+	Anchors []*p2p.NetAddress `json:"anchors,omitempty"`
 }
 
 func (a *addrBook) saveToFile(filePath string) {
@@ -26,8 +28,9 @@ func (a *addrBook) saveToFile(filePath string) {
 		addrs = append(addrs, ka)
 	}
 	aJSON := &addrBookJSON{
-		Key:   a.key,
-		Addrs: addrs,
+		Key:     a.key,
+		Addrs:   addrs,
+		Anchors: a.anchors,
 	}
 
 	jsonBytes, err := json.MarshalIndent(aJSON, "", "\t")
@@ -66,6 +69,7 @@ func (a *addrBook) loadFromFile(filePath string) bool {
 	// Restore all the fields...
 	// Restore the key
 	a.key = aJSON.Key
+	a.anchors = aJSON.Anchors
 	// Restore .bucketsNew & .bucketsOld
 	for _, ka := range aJSON.Addrs {
 		for _, bucketIndex := range ka.Buckets {
@@ -1,6 +1,7 @@
 package pex
 
 import (
+	"math"
 	"time"
 
 	"github.com/tendermint/tendermint/p2p"
@@ -54,6 +55,17 @@ func (ka *knownAddress) markGood() {
 	ka.LastSuccess = now
 }
 
+// score rates how likely the address is to be reachable: it halves with
+// every day since the last successful connection, and is divided by the
+// number of failed attempts since.
+func (ka *knownAddress) score() float64 {
+	if ka.LastSuccess.IsZero() {
+		return 0
+	}
+	days := time.Since(ka.LastSuccess).Hours() / 24
+	return math.Pow(0.5, days) / float64(1+ka.Attempts)
+}
+
 func (ka *knownAddress) addBucketRef(bucketIdx int) int {
 	for _, bucket := range ka.Buckets {
 		if bucket == bucketIdx {
@@ -1,8 +1,10 @@
 package pex
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,7 +13,6 @@ import (
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/libs/cmap"
 	tmmath "github.com/tendermint/tendermint/libs/math"
-	"github.com/tendermint/tendermint/libs/rand"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/libs/service"
 	"github.com/tendermint/tendermint/p2p"
@@ -50,6 +51,12 @@ const (
 	// Especially in the beginning, node should have more trusted peers than
 	// untrusted.
 	biasToSelectNewPeers = 30 // 70 to select good peers
+
+	// outbound peers recorded as anchors, to dial first after a restart
+	numAnchors = 2
+
+	// minimum time an outbound peer must have been connected to be an anchor
+	minAnchorDuration = 5 * time.Minute
 )
 
 type errMaxAttemptsToDial struct {
@@ -79,16 +86,26 @@ func (e errTooEarlyToDial) Error() string {
 //
 // Only accept pexAddrsMsg from peers we sent a corresponding pexRequestMsg too.
 // Only accept one pexRequestMsg every ~defaultEnsurePeersPeriod.
+//
+// ## Preventing eclipse attacks
+//
+// The addresses are placed in the buckets of the book according to the IP of
+// the connection of the peer which sent them, not to the address it claims to
+// listen on. Once the outbound peers have been connected for a while, the
+// longest connected are recorded as anchors in the book: they are dialed first
+// after a restart, so that a book flooded with the addresses of an attacker
+// while the node was offline doesn't choose all of its peers.
 type Reactor struct {
 	p2p.BaseReactor
 
 	book              AddrBook
 	config            *ReactorConfig
 	ensurePeersPeriod time.Duration // TODO: should go in the config
+	ensurePeersCh     chan struct{} // to ensure peers before the next period
 
 	// maps to prevent abuse
-	requestsSent         *cmap.CMap // ID->struct{}: unanswered send requests
-	lastReceivedRequests *cmap.CMap // ID->time.Time: last time peer requested from us
+	requestsSent         *cmap.ShardedMap // ID->struct{}: unanswered send requests
+	lastReceivedRequests *cmap.ShardedMap // ID->time.Time: last time peer requested from us
 
 	seedAddrs []*p2p.NetAddress
 
@@ -96,6 +113,10 @@ type Reactor struct {
 
 	// seed/crawled mode fields
 	crawlPeerInfos map[p2p.ID]crawlPeerInfo
+
+	anchorsDialed bool // the anchors are dialed by the first ensurePeers
+
+	rng *tmrand.Rand // for picking peers and seeds, and jittering dials
 }
 
 func (r *Reactor) minReceiveRequestInterval() time.Duration {
@@ -133,14 +154,23 @@ func NewReactor(b AddrBook, config *ReactorConfig) *Reactor {
 		book:                 b,
 		config:               config,
 		ensurePeersPeriod:    defaultEnsurePeersPeriod,
-		requestsSent:         cmap.NewCMap(),
-		lastReceivedRequests: cmap.NewCMap(),
+		ensurePeersCh:        make(chan struct{}, 1),
+		requestsSent:         cmap.NewShardedMap(0),
+		lastReceivedRequests: cmap.NewShardedMap(0),
 		crawlPeerInfos:       make(map[p2p.ID]crawlPeerInfo),
+		rng:                  tmrand.NewRand(),
 	}
 	r.BaseReactor = *p2p.NewBaseReactor("Reactor", r)
 	return r
 }
 
+// SetRand sets the generator picking peers and seeds and jittering dials,
+// e.g. a seeded one for reproducible tests. It must be called before the
+// reactor is started.
+func (r *Reactor) SetRand(rng *tmrand.Rand) {
+	r.rng = rng
+}
+
 // OnStart implements BaseService
 func (r *Reactor) OnStart() error {
 	err := r.book.Start()
@@ -201,8 +231,10 @@ func (r *Reactor) AddPeer(p Peer) {
 			return
 		}
 
-		// Make it explicit that addr and src are the same for an inbound peer.
-		src := addr
+		// The source is the address the peer connected from, rather than the
+		// one it claims to listen on, which it could choose to land its
+		// addresses in any bucket.
+		src := p.SocketAddr()
 
 		// add to book. dont RequestAddrs right away because
 		// we don't trust inbound as much - let ensurePeersRoutine handle it.
@@ -255,18 +287,22 @@ func (r *Reactor) Receive(chID byte, src Peer, msgBytes []byte) {
 			v := r.lastReceivedRequests.Get(id)
 			if v != nil {
 				// FlushStop/StopPeer are already
-				// running in a go-routine.
+				// running in a task.
 				return
 			}
 			r.lastReceivedRequests.Set(id, time.Now())
 
 			// Send addrs and disconnect
 			r.SendAddrs(src, r.book.GetSelectionWithBias(biasToSelectNewPeers))
-			go func() {
-				// In a go-routine so it doesn't block .Receive.
+			err := r.Switch.TaskPool().TrySubmit(func(context.Context) {
+				// In a task so it doesn't block .Receive.
 				src.FlushStop()
 				r.Switch.StopPeerGracefully(src)
-			}()
+			}, 0)
+			if err != nil {
+				// Too busy to flush the addrs: just disconnect.
+				r.Switch.StopPeerGracefully(src)
+			}
 
 		} else {
 			// Check we're not receiving requests too frequently.
@@ -344,10 +380,8 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 	}
 	r.requestsSent.Delete(id)
 
-	srcAddr, err := src.NodeInfo().NetAddress()
-	if err != nil {
-		return err
-	}
+	// see AddPeer
+	srcAddr := src.SocketAddr()
 
 	srcIsSeed := false
 	for _, seedAddr := range r.seedAddrs {
@@ -359,7 +393,7 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 
 	for _, netAddr := range addrs {
 		// NOTE: we check netAddr validity and routability in book#AddAddress.
-		err = r.book.AddAddress(netAddr, srcAddr)
+		err := r.book.AddAddress(netAddr, srcAddr)
 		if err != nil {
 			r.logErrAddrBook(err)
 			// XXX: should we be strict about incoming data and disconnect from a
@@ -371,17 +405,22 @@ func (r *Reactor) ReceiveAddrs(addrs []*p2p.NetAddress, src Peer) error {
 		// waiting (#2093)
 		if srcIsSeed {
 			r.Logger.Info("Will dial address, which came from seed", "addr", netAddr, "seed", srcAddr)
-			go func(addr *p2p.NetAddress) {
+			addr := netAddr
+			err := r.Switch.TaskPool().TrySubmit(func(context.Context) {
 				err := r.dialPeer(addr)
 				if err != nil {
 					switch err.(type) {
-					case errMaxAttemptsToDial, errTooEarlyToDial, p2p.ErrCurrentlyDialingOrExistingAddress:
+					case errMaxAttemptsToDial, errTooEarlyToDial, p2p.ErrCurrentlyDialingOrExistingAddress,
+						p2p.ErrSubnetQuotaReached:
 						r.Logger.Debug(err.Error(), "addr", addr)
 					default:
 						r.Logger.Error(err.Error(), "addr", addr)
 					}
 				}
-			}(netAddr)
+			}, 0)
+			if err != nil {
+				r.Logger.Debug("Skipping dial of address from seed", "addr", addr, "err", err)
+			}
 		}
 	}
 
@@ -401,8 +440,7 @@ func (r *Reactor) SetEnsurePeersPeriod(d time.Duration) {
 // Ensures that sufficient peers are connected. (continuous)
 func (r *Reactor) ensurePeersRoutine() {
 	var (
-		seed   = rand.NewRand()
-		jitter = seed.Int63n(r.ensurePeersPeriod.Nanoseconds())
+		jitter = r.rng.Int63n(r.ensurePeersPeriod.Nanoseconds())
 	)
 
 	// Randomize first round of communication to avoid thundering herd.
@@ -422,6 +460,8 @@ func (r *Reactor) ensurePeersRoutine() {
 		select {
 		case <-ticker.C:
 			r.ensurePeers()
+		case <-r.ensurePeersCh:
+			r.ensurePeers()
 		case <-r.Quit():
 			ticker.Stop()
 			return
@@ -429,6 +469,29 @@ func (r *Reactor) ensurePeersRoutine() {
 	}
 }
 
+// outboundSubnets returns the number of outbound peers per subnet, persistent
+// and unconditional peers aside as they're exempt from the quotas.
+func (r *Reactor) outboundSubnets() map[string]int {
+	subnets := make(map[string]int)
+	for _, peer := range r.Switch.Peers().List() {
+		if !peer.IsOutbound() || peer.IsPersistent() || r.Switch.IsPeerUnconditional(peer.ID()) {
+			continue
+		}
+		subnets[peer.SocketAddr().Subnet()]++
+	}
+	return subnets
+}
+
+// EnsurePeers makes the reactor dial new peers now if not enough are
+// connected, rather than at the next ensure peers period, e.g. because fast
+// sync has no peers left. It doesn't block.
+func (r *Reactor) EnsurePeers() {
+	select {
+	case r.ensurePeersCh <- struct{}{}:
+	default:
+	}
+}
+
 // ensurePeers ensures that sufficient peers are connected. (once)
 //
 // heuristic that we haven't perfected yet, or, perhaps is manually edited by
@@ -447,6 +510,8 @@ func (r *Reactor) ensurePeers() {
 		"numToDial", numToDial,
 	)
 
+	r.updateAnchors()
+
 	if numToDial <= 0 {
 		return
 	}
@@ -457,6 +522,27 @@ func (r *Reactor) ensurePeers() {
 	newBias := tmmath.MinInt(out, 8)*10 + 10
 
 	toDial := make(map[p2p.ID]*p2p.NetAddress)
+
+	// After a restart, dial the anchors first.
+	if !r.anchorsDialed {
+		r.anchorsDialed = true
+		for _, addr := range r.book.Anchors() {
+			if len(toDial) < numToDial && !r.Switch.IsDialingOrExistingAddress(addr) {
+				r.Logger.Info("Will dial anchor", "addr", addr)
+				toDial[addr.ID] = addr
+			}
+		}
+	}
+
+	// Prefer diversity: the subnets (see NetAddress.Subnet) of the outbound
+	// peers, and of the addresses picked, are only picked again in the last
+	// third of the attempts, and never once they reached their quota.
+	subnets := r.outboundSubnets()
+	for _, addr := range toDial {
+		subnets[addr.Subnet()]++
+	}
+	maxPerSubnet := r.Switch.MaxNumOutboundPeersPerSubnet()
+
 	// Try maxAttempts times to pick numToDial addresses to dial
 	maxAttempts := numToDial * 3
 
@@ -471,26 +557,40 @@ func (r *Reactor) ensurePeers() {
 		if r.Switch.IsDialingOrExistingAddress(try) {
 			continue
 		}
+		if subnet := try.Subnet(); subnet != "" {
+			if maxPerSubnet > 0 && subnets[subnet] >= maxPerSubnet {
+				continue
+			}
+			if subnets[subnet] > 0 && i < maxAttempts*2/3 {
+				continue
+			}
+		}
 		// TODO: consider moving some checks from toDial into here
 		// so we don't even consider dialing peers that we want to wait
 		// before dialling again, or have dialed too many times already
 		r.Logger.Info("Will dial address", "addr", try)
 		toDial[try.ID] = try
+		subnets[try.Subnet()]++
 	}
 
 	// Dial picked addresses
 	for _, addr := range toDial {
-		go func(addr *p2p.NetAddress) {
+		addr := addr
+		err := r.Switch.TaskPool().TrySubmit(func(context.Context) {
 			err := r.dialPeer(addr)
 			if err != nil {
 				switch err.(type) {
-				case errMaxAttemptsToDial, errTooEarlyToDial:
+				case errMaxAttemptsToDial, errTooEarlyToDial, p2p.ErrSubnetQuotaReached:
 					r.Logger.Debug(err.Error(), "addr", addr)
 				default:
 					r.Logger.Error(err.Error(), "addr", addr)
 				}
 			}
-		}(addr)
+		}, 0)
+		if err != nil {
+			// retried by the next ensurePeers
+			r.Logger.Debug("Skipping dial", "addr", addr, "err", err)
+		}
 	}
 
 	if r.book.NeedMoreAddrs() {
@@ -498,7 +598,7 @@ func (r *Reactor) ensurePeers() {
 		peers := r.Switch.Peers().List()
 		peersCount := len(peers)
 		if peersCount > 0 {
-			peer := peers[tmrand.Int()%peersCount]
+			peer := peers[r.rng.Int()%peersCount]
 			r.Logger.Info("We need more addresses. Sending pexRequest to random peer", "peer", peer)
 			r.RequestAddrs(peer)
 		}
@@ -513,6 +613,33 @@ func (r *Reactor) ensurePeers() {
 	}
 }
 
+// updateAnchors records the outbound peers connected for the longest (at
+// least minAnchorDuration) as the anchors to dial first after a restart.
+// Persistent peers, which are dialed anyway, are left out. The anchors are
+// kept as they are if no peer qualifies.
+func (r *Reactor) updateAnchors() {
+	var peers []Peer
+	for _, p := range r.Switch.Peers().List() {
+		if p.IsOutbound() && !p.IsPersistent() && p.Status().Duration >= minAnchorDuration {
+			peers = append(peers, p)
+		}
+	}
+	if len(peers) == 0 {
+		return
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Status().Duration > peers[j].Status().Duration
+	})
+	anchors := make([]*p2p.NetAddress, 0, numAnchors)
+	for _, p := range peers {
+		if len(anchors) == numAnchors {
+			break
+		}
+		anchors = append(anchors, p.SocketAddr())
+	}
+	r.book.SetAnchors(anchors)
+}
+
 func (r *Reactor) dialAttemptsInfo(addr *p2p.NetAddress) (attempts int, lastDialed time.Time) {
 	_attempts, ok := r.attemptsToDial.Load(addr.DialString())
 	if !ok {
@@ -535,7 +662,7 @@ func (r *Reactor) dialPeer(addr *p2p.NetAddress) error {
 
 	// exponential backoff if it's not our first attempt to dial given address
 	if attempts > 0 {
-		jitterSeconds := time.Duration(tmrand.Float64() * float64(time.Second)) // 1s == (1e9 ns)
+		jitterSeconds := time.Duration(r.rng.Float64() * float64(time.Second)) // 1s == (1e9 ns)
 		backoffDuration := jitterSeconds + ((1 << uint(attempts)) * time.Second)
 		backoffDuration = r.maxBackoffDurationForPeer(addr, backoffDuration)
 		sinceLastDialed := time.Since(lastDialed)
@@ -546,7 +673,8 @@ func (r *Reactor) dialPeer(addr *p2p.NetAddress) error {
 
 	err := r.Switch.DialPeerWithAddress(addr)
 	if err != nil {
-		if _, ok := err.(p2p.ErrCurrentlyDialingOrExistingAddress); ok {
+		switch err.(type) {
+		case p2p.ErrCurrentlyDialingOrExistingAddress, p2p.ErrSubnetQuotaReached:
 			return err
 		}
 
@@ -601,7 +729,7 @@ func (r *Reactor) checkSeeds() (numOnline int, netAddrs []*p2p.NetAddress, err e
 
 // randomly dial seeds until we connect to one or exhaust them
 func (r *Reactor) dialSeeds() {
-	perm := tmrand.Perm(len(r.seedAddrs))
+	perm := r.rng.Perm(len(r.seedAddrs))
 	// perm := r.Switch.rng.Perm(lSeeds)
 	for _, i := range perm {
 		// dial a random seed
@@ -695,7 +823,8 @@ func (r *Reactor) crawlPeers(addrs []*p2p.NetAddress) {
 		err := r.dialPeer(addr)
 		if err != nil {
 			switch err.(type) {
-			case errMaxAttemptsToDial, errTooEarlyToDial, p2p.ErrCurrentlyDialingOrExistingAddress:
+			case errMaxAttemptsToDial, errTooEarlyToDial, p2p.ErrCurrentlyDialingOrExistingAddress,
+				p2p.ErrSubnetQuotaReached:
 				r.Logger.Debug(err.Error(), "addr", addr)
 			default:
 				r.Logger.Error(err.Error(), "addr", addr)
@@ -3,6 +3,7 @@ package pex
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/p2p/mock"
 )
@@ -27,6 +29,13 @@ func init() {
 	cfg.AllowDuplicateIP = true
 }
 
+// TestMain seeds the peer selection and dial timers, so that a failing run
+// can be reproduced with the printed TM_TEST_SEED.
+func TestMain(m *testing.M) {
+	tmrand.SetDeterministicForTesting()
+	os.Exit(m.Run())
+}
+
 func TestPEXReactorBasic(t *testing.T) {
 	r, book := createReactor(&ReactorConfig{})
 	defer teardownReactor(book)
@@ -518,6 +527,54 @@ func TestPEXReactorDialPeer(t *testing.T) {
 	}
 }
 
+func TestPEXReactorSubnetQuota(t *testing.T) {
+	pexR, book := createReactor(&ReactorConfig{})
+	defer teardownReactor(book)
+
+	p2pCfg := *cfg
+	p2pCfg.MaxNumOutboundPeersPerSubnet = 1
+	sw := p2p.MakeSwitch(&p2pCfg, 0, "127.0.0.1", "123.123.123", func(i int, sw *p2p.Switch) *p2p.Switch { return sw })
+	sw.SetLogger(log.TestingLogger())
+	sw.AddReactor(pexR.String(), pexR)
+	pexR.SetSwitch(sw)
+	sw.SetAddrBook(book)
+
+	peer := mock.NewPeer(net.IP{203, 0, 113, 1})
+	peer.Outbound = true
+	p2p.AddPeerToSwitchPeerSet(sw, peer)
+	assert.Equal(t, map[string]int{"203.0.113.0/24": 1}, pexR.outboundSubnets())
+
+	// not dialed, nor counted as an attempt
+	addr := mock.NewPeer(net.IP{203, 0, 113, 2}).SocketAddr()
+	err := pexR.dialPeer(addr)
+	assert.IsType(t, p2p.ErrSubnetQuotaReached{}, err)
+	assert.Equal(t, 0, pexR.AttemptsToDial(addr))
+}
+
+func TestPEXReactorDialsAnchors(t *testing.T) {
+	pexR, book := createReactor(&ReactorConfig{})
+	defer teardownReactor(book)
+
+	sw := createSwitchAndAddReactors(pexR)
+	sw.SetAddrBook(book)
+	require.NoError(t, sw.TaskPool().Start())
+	defer sw.TaskPool().Stop()
+
+	// the anchors are dialed by the first ensurePeers only
+	addr := mock.NewPeer(nil).SocketAddr()
+	book.SetAnchors([]*p2p.NetAddress{addr})
+	pexR.ensurePeers()
+	assert.Eventually(t, func() bool { return pexR.AttemptsToDial(addr) == 1 }, time.Second, 10*time.Millisecond)
+
+	pexR.ensurePeers()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 1, pexR.AttemptsToDial(addr))
+
+	// no peer has been connected long enough to replace them
+	pexR.updateAnchors()
+	assert.Equal(t, []*p2p.NetAddress{addr}, book.Anchors())
+}
+
 func assertPeersWithTimeout(
 	t *testing.T,
 	switches []*p2p.Switch,
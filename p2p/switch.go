@@ -1,15 +1,19 @@
 package p2p
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/async"
 	"github.com/tendermint/tendermint/libs/cmap"
+	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/libs/service"
 	"github.com/tendermint/tendermint/p2p/conn"
@@ -29,6 +33,17 @@ const (
 	// ie. 3**10 = 16hrs
 	reconnectBackOffAttempts    = 10
 	reconnectBackOffBaseSeconds = 3
+
+	// tasks of the task pool running longer are counted as timed out
+	defaultTaskTimeout = 30 * time.Second
+)
+
+// The channels of the consensus and mempool reactors (see
+// consensus.StateChannel etc. and mempool.MempoolChannel), which the mempool
+// yields to when P2PConfig.MempoolYieldsToConsensus is set.
+var (
+	consensusChannels = []byte{0x20, 0x21, 0x22, 0x23}
+	mempoolChannel    = byte(0x30)
 )
 
 // MConnConfig returns an MConnConfig with fields updated
@@ -39,6 +54,26 @@ func MConnConfig(cfg *config.P2PConfig) conn.MConnConfig {
 	mConfig.SendRate = cfg.SendRate
 	mConfig.RecvRate = cfg.RecvRate
 	mConfig.MaxPacketMsgPayloadSize = cfg.MaxPacketMsgPayloadSize
+
+	// the config is validated, the lists are well-formed
+	priorities, _ := config.ParseChannelValues(cfg.ChannelPriorities)
+	if len(priorities) > 0 {
+		mConfig.ChannelPriorities = make(map[byte]int, len(priorities))
+		for chID, priority := range priorities {
+			mConfig.ChannelPriorities[chID] = int(priority)
+		}
+	}
+	rates, _ := config.ParseChannelValues(cfg.ChannelSendRates)
+	if len(rates) > 0 {
+		mConfig.ChannelSendRates = rates
+	}
+	if cfg.MempoolYieldsToConsensus {
+		mConfig.YieldingChannels = []byte{mempoolChannel}
+		mConfig.YieldTo = consensusChannels
+	}
+	// the compressed channels are negotiated with each peer (see
+	// DefaultNodeInfo.CompressedChannels)
+	mConfig.CompressionMinSize = cfg.CompressionMinSize
 	return mConfig
 }
 
@@ -60,6 +95,24 @@ type AddrBook interface {
 // fully setup.
 type PeerFilterFunc func(IPeerSet, Peer) error
 
+// PeerAllowlistFilter returns a PeerFilterFunc rejecting the peers whose ID
+// isn't one of ids.
+func PeerAllowlistFilter(ids []string) (PeerFilterFunc, error) {
+	allowed := make(map[ID]struct{}, len(ids))
+	for i, id := range ids {
+		if err := validateID(ID(id)); err != nil {
+			return nil, errors.Wrapf(err, "wrong ID #%d", i)
+		}
+		allowed[ID(id)] = struct{}{}
+	}
+	return func(_ IPeerSet, p Peer) error {
+		if _, ok := allowed[p.ID()]; !ok {
+			return fmt.Errorf("peer %v is not allowed", p.ID())
+		}
+		return nil
+	}, nil
+}
+
 //-----------------------------------------------------------------------------
 
 // Switch handles peer connections and exposes an API to receive incoming messages
@@ -71,15 +124,19 @@ type Switch struct {
 
 	config       *config.P2PConfig
 	reactors     map[string]Reactor
+	reactorNames []string // in the order reactors were added, which they're started in
 	chDescs      []*conn.ChannelDescriptor
 	reactorsByCh map[byte]Reactor
 	peers        *PeerSet
-	dialing      *cmap.CMap
-	reconnecting *cmap.CMap
+	dialing      *cmap.ShardedMap
+	reconnecting *cmap.ShardedMap
+	nodeInfoMtx  sync.RWMutex
 	nodeInfo     NodeInfo // our node info
 	nodeKey      *NodeKey // our node privkey
 	addrBook     AddrBook
+	banList      *BanList
 	// peers addresses with whom we'll maintain constant connection
+	peerListsMtx         sync.RWMutex
 	persistentPeersAddrs []*NetAddress
 	unconditionalPeerIDs map[ID]struct{}
 
@@ -91,6 +148,11 @@ type Switch struct {
 	rng *rand.Rand // seed for randomizing dial times and orders
 
 	metrics *Metrics
+
+	// runs the background tasks of the switch and its reactors
+	taskPool *async.Pool
+
+	onReactorStarted func(name string, start time.Time)
 }
 
 // NetAddress returns the address the switch is listening on.
@@ -114,13 +176,16 @@ func NewSwitch(
 		chDescs:              make([]*conn.ChannelDescriptor, 0),
 		reactorsByCh:         make(map[byte]Reactor),
 		peers:                NewPeerSet(),
-		dialing:              cmap.NewCMap(),
-		reconnecting:         cmap.NewCMap(),
+		dialing:              cmap.NewShardedMap(0),
+		reconnecting:         cmap.NewShardedMap(0),
 		metrics:              NopMetrics(),
 		transport:            transport,
 		filterTimeout:        defaultFilterTimeout,
 		persistentPeersAddrs: make([]*NetAddress, 0),
 		unconditionalPeerIDs: make(map[ID]struct{}),
+		banList:              NewBanList(),
+		taskPool: async.NewPool(cfg.TaskPoolWorkers, cfg.TaskPoolQueueSize,
+			async.PoolTaskTimeout(defaultTaskTimeout)),
 	}
 
 	// Ensure we have a completely undeterministic PRNG.
@@ -145,9 +210,20 @@ func SwitchPeerFilters(filters ...PeerFilterFunc) SwitchOption {
 	return func(sw *Switch) { sw.peerFilters = filters }
 }
 
+// SwitchRand sets the generator randomizing dial times and orders, e.g. a
+// seeded one for reproducible tests.
+func SwitchRand(rng *rand.Rand) SwitchOption {
+	return func(sw *Switch) { sw.rng = rng }
+}
+
 // WithMetrics sets the metrics.
 func WithMetrics(metrics *Metrics) SwitchOption {
-	return func(sw *Switch) { sw.metrics = metrics }
+	return func(sw *Switch) {
+		sw.metrics = metrics
+		if metrics.TaskPool != nil {
+			sw.taskPool.SetMetrics(metrics.TaskPool)
+		}
+	}
 }
 
 //---------------------------------------------------------------------
@@ -165,6 +241,9 @@ func (sw *Switch) AddReactor(name string, reactor Reactor) Reactor {
 		sw.chDescs = append(sw.chDescs, chDesc)
 		sw.reactorsByCh[chID] = reactor
 	}
+	if _, ok := sw.reactors[name]; !ok {
+		sw.reactorNames = append(sw.reactorNames, name)
+	}
 	sw.reactors[name] = reactor
 	reactor.SetSwitch(sw)
 	return reactor
@@ -184,6 +263,12 @@ func (sw *Switch) RemoveReactor(name string, reactor Reactor) {
 		delete(sw.reactorsByCh, chDesc.ID)
 	}
 	delete(sw.reactors, name)
+	for i, n := range sw.reactorNames {
+		if n == name {
+			sw.reactorNames = append(sw.reactorNames[:i], sw.reactorNames[i+1:]...)
+			break
+		}
+	}
 	reactor.SetSwitch(nil)
 }
 
@@ -199,15 +284,24 @@ func (sw *Switch) Reactor(name string) Reactor {
 	return sw.reactors[name]
 }
 
-// SetNodeInfo sets the switch's NodeInfo for checking compatibility and handshaking with other nodes.
+// SetReactorStartHook sets a function called with the name of each reactor
+// and the time it began starting at, once the reactor has started.
 // NOTE: Not goroutine safe.
+func (sw *Switch) SetReactorStartHook(fn func(name string, start time.Time)) {
+	sw.onReactorStarted = fn
+}
+
+// SetNodeInfo sets the switch's NodeInfo for checking compatibility and handshaking with other nodes.
 func (sw *Switch) SetNodeInfo(nodeInfo NodeInfo) {
+	sw.nodeInfoMtx.Lock()
 	sw.nodeInfo = nodeInfo
+	sw.nodeInfoMtx.Unlock()
 }
 
 // NodeInfo returns the switch's NodeInfo.
-// NOTE: Not goroutine safe.
 func (sw *Switch) NodeInfo() NodeInfo {
+	sw.nodeInfoMtx.RLock()
+	defer sw.nodeInfoMtx.RUnlock()
 	return sw.nodeInfo
 }
 
@@ -220,14 +314,25 @@ func (sw *Switch) SetNodeKey(nodeKey *NodeKey) {
 //---------------------------------------------------------------------
 // Service start/stop
 
-// OnStart implements BaseService. It starts all the reactors and peers.
+// OnStart implements BaseService. It starts all the reactors, in the order
+// they were added, and peers.
 func (sw *Switch) OnStart() error {
+	if err := sw.taskPool.Start(); err != nil {
+		return errors.Wrap(err, "failed to start task pool")
+	}
+
 	// Start reactors
-	for _, reactor := range sw.reactors {
+	for _, name := range sw.reactorNames {
+		reactor := sw.reactors[name]
+		start := time.Now()
 		err := reactor.Start()
 		if err != nil {
+			sw.taskPool.Stop()
 			return errors.Wrapf(err, "failed to start %v", reactor)
 		}
+		if sw.onReactorStarted != nil {
+			sw.onReactorStarted(name, start)
+		}
 	}
 
 	// Start accepting Peers.
@@ -238,9 +343,12 @@ func (sw *Switch) OnStart() error {
 
 // OnStop implements BaseService. It stops all peers and reactors.
 func (sw *Switch) OnStop() {
-	// Stop peers
+	// Stop peers, flushing the messages already queued for them (e.g. our
+	// latest votes) before closing the connections.
 	for _, p := range sw.peers.List() {
-		sw.stopAndRemovePeer(p, nil)
+		sw.transport.Cleanup(p)
+		p.FlushStop()
+		sw.removePeer(p, nil)
 	}
 
 	// Stop reactors
@@ -248,38 +356,64 @@ func (sw *Switch) OnStop() {
 	for _, reactor := range sw.reactors {
 		reactor.Stop()
 	}
+
+	sw.taskPool.Stop()
+}
+
+// SetLogger implements service.Service by setting the logger of the switch and
+// its task pool.
+func (sw *Switch) SetLogger(l log.Logger) {
+	sw.BaseService.SetLogger(l)
+	sw.taskPool.SetLogger(l)
+}
+
+// TaskPool returns the pool running the background tasks of the switch.
+// Reactors should submit their fire-and-forget work (e.g. dials) to it in place
+// of spawning goroutines, so that the goroutines stay bounded under load.
+func (sw *Switch) TaskPool() *async.Pool {
+	return sw.taskPool
 }
 
 //---------------------------------------------------------------------
 // Peers
 
-// Broadcast runs a go routine for each attempted send, which will block trying
-// to send for defaultSendTimeoutSeconds. Returns a channel which receives
-// success values for each attempted send (false if times out). Channel will be
-// closed once msg bytes are sent to all peers (or time out).
+// Broadcast submits a task to the task pool for each attempted send, which
+// will block trying to send for defaultSendTimeoutSeconds. Returns a channel
+// which receives success values for each attempted send (false if times out,
+// or if the switch is stopped). Channel will be closed once msg bytes are sent
+// to all peers (or time out). Broadcast blocks while the queue of the task pool
+// is full.
 //
-// NOTE: Broadcast uses goroutines, so order of broadcast may not be preserved.
+// NOTE: Broadcast sends concurrently, so order of broadcast may not be
+// preserved.
 func (sw *Switch) Broadcast(chID byte, msgBytes []byte) chan bool {
 	sw.Logger.Debug("Broadcast", "channel", chID, "msgBytes", fmt.Sprintf("%X", msgBytes))
 
 	peers := sw.peers.List()
-	var wg sync.WaitGroup
-	wg.Add(len(peers))
 	successChan := make(chan bool, len(peers))
+	if len(peers) == 0 {
+		close(successChan)
+		return successChan
+	}
 
+	// the last send to complete closes the channel
+	remaining := int32(len(peers))
+	done := func(success bool) {
+		successChan <- success
+		if atomic.AddInt32(&remaining, -1) == 0 {
+			close(successChan)
+		}
+	}
 	for _, peer := range peers {
-		go func(p Peer) {
-			defer wg.Done()
-			success := p.Send(chID, msgBytes)
-			successChan <- success
-		}(peer)
+		p := peer
+		err := sw.taskPool.Submit(func(context.Context) {
+			done(p.Send(chID, msgBytes))
+		}, 0)
+		if err != nil {
+			done(false)
+		}
 	}
 
-	go func() {
-		wg.Wait()
-		close(successChan)
-	}()
-
 	return successChan
 }
 
@@ -303,6 +437,8 @@ func (sw *Switch) NumPeers() (outbound, inbound, dialing int) {
 }
 
 func (sw *Switch) IsPeerUnconditional(id ID) bool {
+	sw.peerListsMtx.RLock()
+	defer sw.peerListsMtx.RUnlock()
 	_, ok := sw.unconditionalPeerIDs[id]
 	return ok
 }
@@ -312,6 +448,28 @@ func (sw *Switch) MaxNumOutboundPeers() int {
 	return sw.config.MaxNumOutboundPeers
 }
 
+// MaxNumOutboundPeersPerSubnet returns the maximum number of outbound peers
+// from the same subnet (0 - unlimited).
+func (sw *Switch) MaxNumOutboundPeersPerSubnet() int {
+	return sw.config.MaxNumOutboundPeersPerSubnet
+}
+
+// NumPeersInSubnet returns the number of outbound, or inbound, peers whose
+// address is in the subnet (see NetAddress.Subnet). Unconditional and
+// persistent peers aren't counted, as they're exempt from the quotas.
+func (sw *Switch) NumPeersInSubnet(subnet string, outbound bool) int {
+	n := 0
+	for _, peer := range sw.peers.List() {
+		if peer.IsOutbound() != outbound || peer.IsPersistent() || sw.IsPeerUnconditional(peer.ID()) {
+			continue
+		}
+		if peer.SocketAddr().Subnet() == subnet {
+			n++
+		}
+	}
+	return n
+}
+
 // Peers returns the set of peers that are connected to the switch.
 func (sw *Switch) Peers() IPeerSet {
 	return sw.peers
@@ -341,6 +499,19 @@ func (sw *Switch) StopPeerForError(peer Peer, reason interface{}) {
 	}
 }
 
+// BanPeerForError bans a peer which misbehaved for P2PConfig.BanDuration, and
+// disconnects from it. If the duration is 0, the peer is only disconnected, as
+// with StopPeerForError.
+func (sw *Switch) BanPeerForError(peer Peer, reason interface{}) {
+	if sw.config.BanDuration == 0 {
+		sw.StopPeerForError(peer, reason)
+		return
+	}
+	if _, err := sw.BanPeer(peer.ID(), 0, fmt.Sprintf("%v", reason)); err != nil {
+		sw.Logger.Error("Failed to ban peer", "peer", peer, "err", err)
+	}
+}
+
 // StopPeerGracefully disconnects from a peer gracefully.
 // TODO: handle graceful disconnects.
 func (sw *Switch) StopPeerGracefully(peer Peer) {
@@ -351,7 +522,10 @@ func (sw *Switch) StopPeerGracefully(peer Peer) {
 func (sw *Switch) stopAndRemovePeer(peer Peer, reason interface{}) {
 	sw.transport.Cleanup(peer)
 	peer.Stop()
+	sw.removePeer(peer, reason)
+}
 
+func (sw *Switch) removePeer(peer Peer, reason interface{}) {
 	for _, reactor := range sw.reactors {
 		reactor.RemovePeer(peer, reason)
 	}
@@ -392,6 +566,9 @@ func (sw *Switch) reconnectToPeer(addr *NetAddress) {
 			return // success
 		} else if _, ok := err.(ErrCurrentlyDialingOrExistingAddress); ok {
 			return
+		} else if _, ok := err.(ErrPeerBanned); ok {
+			sw.Logger.Info("Stopped reconnecting to banned peer", "addr", addr)
+			return
 		}
 
 		sw.Logger.Info("Error reconnecting to peer. Trying again", "tries", i, "err", err, "addr", addr)
@@ -416,6 +593,9 @@ func (sw *Switch) reconnectToPeer(addr *NetAddress) {
 			return // success
 		} else if _, ok := err.(ErrCurrentlyDialingOrExistingAddress); ok {
 			return
+		} else if _, ok := err.(ErrPeerBanned); ok {
+			sw.Logger.Info("Stopped reconnecting to banned peer", "addr", addr)
+			return
 		}
 		sw.Logger.Info("Error reconnecting to peer. Trying again", "tries", i, "err", err, "addr", addr)
 	}
@@ -427,6 +607,49 @@ func (sw *Switch) SetAddrBook(addrBook AddrBook) {
 	sw.addrBook = addrBook
 }
 
+// SetBanList sets the list of banned peers, e.g. one saved to a file so that
+// the bans outlive restarts. The switch has a new, in memory, one otherwise.
+// NOTE: Not goroutine safe.
+func (sw *Switch) SetBanList(banList *BanList) {
+	sw.banList = banList
+}
+
+// BanPeer bans the peer for the given duration, or P2PConfig.BanDuration if
+// it's 0, replacing any previous ban, and disconnects from it. The switch
+// won't accept nor dial the peer until the ban expires, even if it's
+// persistent or unconditional. The ban is in effect even if an error is
+// returned for failing to save it.
+func (sw *Switch) BanPeer(id ID, duration time.Duration, reason string) (Ban, error) {
+	if duration == 0 {
+		duration = sw.config.BanDuration
+	}
+	ban, err := sw.banList.Ban(id, duration, reason)
+	if ban.ID == "" {
+		return ban, err
+	}
+	sw.Logger.Info("Banned peer", "peer", id, "until", ban.Expires, "reason", reason)
+	if peer := sw.peers.Get(id); peer != nil {
+		sw.stopAndRemovePeer(peer, ErrPeerBanned{ban})
+	}
+	return ban, err
+}
+
+// UnbanPeer lifts the ban of the peer. It returns false if the peer isn't
+// banned.
+func (sw *Switch) UnbanPeer(id ID) (bool, error) {
+	unbanned, err := sw.banList.Unban(id)
+	if unbanned {
+		sw.Logger.Info("Unbanned peer", "peer", id)
+	}
+	return unbanned, err
+}
+
+// BannedPeers returns the bans of peers in effect, the earliest to expire
+// first.
+func (sw *Switch) BannedPeers() []Ban {
+	return sw.banList.List()
+}
+
 // MarkPeerAsGood marks the given peer as good when it did something useful
 // like contributed to consensus.
 func (sw *Switch) MarkPeerAsGood(peer Peer) {
@@ -512,7 +735,8 @@ func (sw *Switch) dialPeersAsync(netAddrs []*NetAddress) {
 			err := sw.DialPeerWithAddress(addr)
 			if err != nil {
 				switch err.(type) {
-				case ErrSwitchConnectToSelf, ErrSwitchDuplicatePeerID, ErrCurrentlyDialingOrExistingAddress:
+				case ErrSwitchConnectToSelf, ErrSwitchDuplicatePeerID, ErrCurrentlyDialingOrExistingAddress,
+					ErrSubnetQuotaReached, ErrPeerBanned:
 					sw.Logger.Debug("Error dialing peer", "err", err)
 				default:
 					sw.Logger.Error("Error dialing peer", "err", err)
@@ -525,12 +749,26 @@ func (sw *Switch) dialPeersAsync(netAddrs []*NetAddress) {
 // DialPeerWithAddress dials the given peer and runs sw.addPeer if it connects
 // and authenticates successfully.
 // If we're currently dialing this address or it belongs to an existing peer,
-// ErrCurrentlyDialingOrExistingAddress is returned.
+// ErrCurrentlyDialingOrExistingAddress is returned. If its subnet already has
+// the maximum number of outbound peers, ErrSubnetQuotaReached is returned,
+// unless the peer is persistent or unconditional. If the peer is banned,
+// ErrPeerBanned is returned.
 func (sw *Switch) DialPeerWithAddress(addr *NetAddress) error {
+	if ban, ok := sw.banList.Get(addr.ID); ok {
+		return ErrPeerBanned{ban}
+	}
+
 	if sw.IsDialingOrExistingAddress(addr) {
 		return ErrCurrentlyDialingOrExistingAddress{addr.String()}
 	}
 
+	if max := sw.config.MaxNumOutboundPeersPerSubnet; max > 0 &&
+		!sw.IsPeerPersistent(addr) && !sw.IsPeerUnconditional(addr.ID) {
+		if subnet := addr.Subnet(); subnet != "" && sw.NumPeersInSubnet(subnet, true) >= max {
+			return ErrSubnetQuotaReached{Subnet: subnet, Max: max}
+		}
+	}
+
 	sw.dialing.Set(string(addr.ID), addr)
 	defer sw.dialing.Delete(string(addr.ID))
 
@@ -568,7 +806,9 @@ func (sw *Switch) AddPersistentPeers(addrs []string) error {
 		}
 		return err
 	}
+	sw.peerListsMtx.Lock()
 	sw.persistentPeersAddrs = netAddrs
+	sw.peerListsMtx.Unlock()
 	return nil
 }
 
@@ -579,12 +819,18 @@ func (sw *Switch) AddUnconditionalPeerIDs(ids []string) error {
 		if err != nil {
 			return errors.Wrapf(err, "wrong ID #%d", i)
 		}
+	}
+	sw.peerListsMtx.Lock()
+	for _, id := range ids {
 		sw.unconditionalPeerIDs[ID(id)] = struct{}{}
 	}
+	sw.peerListsMtx.Unlock()
 	return nil
 }
 
 func (sw *Switch) IsPeerPersistent(na *NetAddress) bool {
+	sw.peerListsMtx.RLock()
+	defer sw.peerListsMtx.RUnlock()
 	for _, pa := range sw.persistentPeersAddrs {
 		if pa.Equals(na) {
 			return true
@@ -665,6 +911,22 @@ func (sw *Switch) acceptRoutine() {
 				continue
 			}
 
+			subnet := p.SocketAddr().Subnet()
+			if max := sw.config.MaxNumInboundPeersPerSubnet; max > 0 && subnet != "" && !p.IsPersistent() {
+				if n := sw.NumPeersInSubnet(subnet, false); n >= max {
+					sw.Logger.Info(
+						"Ignoring inbound connection: already have enough inbound peers from subnet",
+						"address", p.SocketAddr(),
+						"subnet", subnet,
+						"have", n,
+						"max", max,
+					)
+
+					sw.transport.Cleanup(p)
+
+					continue
+				}
+			}
 		}
 
 		if err := sw.addPeer(p); err != nil {
@@ -738,6 +1000,10 @@ func (sw *Switch) addOutboundPeerWithConfig(
 }
 
 func (sw *Switch) filterPeer(p Peer) error {
+	if ban, ok := sw.banList.Get(p.ID()); ok {
+		return ErrRejected{id: p.ID(), err: ErrPeerBanned{ban}, isFiltered: true}
+	}
+
 	// Avoid duplicate
 	if sw.peers.Has(p.ID()) {
 		return ErrRejected{id: p.ID(), isDuplicate: true}
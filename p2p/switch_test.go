@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"regexp"
 	"strconv"
 	"sync"
@@ -23,6 +24,7 @@ import (
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/p2p/conn"
 )
 
@@ -36,6 +38,13 @@ func init() {
 	cfg.AllowDuplicateIP = true
 }
 
+// TestMain seeds the peer selection and dial timers, so that a failing run
+// can be reproduced with the printed TM_TEST_SEED.
+func TestMain(m *testing.M) {
+	tmrand.SetDeterministicForTesting()
+	os.Exit(m.Run())
+}
+
 type PeerMessage struct {
 	PeerID  ID
 	Bytes   []byte
@@ -203,6 +212,26 @@ func TestSwitchFiltersOutItself(t *testing.T) {
 	assertNoPeersAfterTimeout(t, s1, 100*time.Millisecond)
 }
 
+func TestSwitchStartsReactorsInOrder(t *testing.T) {
+	sw := MakeSwitch(cfg, 1, "127.0.0.1", "123.123.123", func(i int, sw *Switch) *Switch {
+		for j, name := range []string{"c", "a", "d", "b"} {
+			sw.AddReactor(name, NewTestReactor([]*conn.ChannelDescriptor{{ID: byte(j)}}, false))
+		}
+		return sw
+	})
+	sw.RemoveReactor("d", sw.Reactor("d"))
+
+	var started []string
+	sw.SetReactorStartHook(func(name string, start time.Time) {
+		assert.True(t, sw.Reactor(name).IsRunning())
+		started = append(started, name)
+	})
+	require.NoError(t, sw.Start())
+	defer sw.Stop()
+
+	assert.Equal(t, []string{"c", "a", "b"}, started)
+}
+
 func TestSwitchPeerFilter(t *testing.T) {
 	var (
 		filters = []PeerFilterFunc{
@@ -246,6 +275,18 @@ func TestSwitchPeerFilter(t *testing.T) {
 	}
 }
 
+func TestPeerAllowlistFilter(t *testing.T) {
+	allowed, denied := newMockPeer(nil), newMockPeer(nil)
+
+	_, err := PeerAllowlistFilter([]string{string(allowed.ID()), "invalid"})
+	assert.Error(t, err)
+
+	filter, err := PeerAllowlistFilter([]string{string(allowed.ID())})
+	require.NoError(t, err)
+	assert.NoError(t, filter(NewPeerSet(), allowed))
+	assert.Error(t, filter(NewPeerSet(), denied))
+}
+
 func TestSwitchPeerFilterTimeout(t *testing.T) {
 	var (
 		filters = []PeerFilterFunc{
@@ -503,6 +544,77 @@ func TestSwitchDialPeersAsync(t *testing.T) {
 	require.NotNil(t, sw.Peers().Get(rp.ID()))
 }
 
+// outboundMockPeer is an outbound, non-persistent mockPeer with an address.
+type outboundMockPeer struct {
+	*mockPeer
+	addr *NetAddress
+}
+
+func (mp outboundMockPeer) IsOutbound() bool        { return true }
+func (mp outboundMockPeer) IsPersistent() bool      { return false }
+func (mp outboundMockPeer) SocketAddr() *NetAddress { return mp.addr }
+
+func TestSwitchDialPeerSubnetQuota(t *testing.T) {
+	p2pCfg := *cfg
+	p2pCfg.MaxNumOutboundPeersPerSubnet = 1
+	sw := MakeSwitch(&p2pCfg, 1, "testing", "123.123.123", initSwitchFunc)
+
+	peer := newMockPeer(net.IP{203, 0, 113, 1})
+	require.NoError(t, sw.peers.Add(outboundMockPeer{peer, NewNetAddressIPPort(peer.ip, 26656)}))
+	// not counted, as persistent
+	require.NoError(t, sw.peers.Add(newMockPeer(net.IP{203, 0, 113, 2})))
+	assert.Equal(t, 1, sw.NumPeersInSubnet("203.0.113.0/24", true))
+	assert.Equal(t, 0, sw.NumPeersInSubnet("203.0.113.0/24", false))
+
+	addr := NewNetAddressIPPort(net.IP{203, 0, 113, 3}, 26656)
+	addr.ID = newMockPeer(nil).ID()
+	err := sw.DialPeerWithAddress(addr)
+	assert.Equal(t, ErrSubnetQuotaReached{Subnet: "203.0.113.0/24", Max: 1}, err)
+}
+
+func TestSwitchBanPeer(t *testing.T) {
+	sw1, sw2 := MakeSwitchPair(t, initSwitchFunc)
+	defer sw1.Stop()
+	defer sw2.Stop()
+	require.Equal(t, 1, sw1.Peers().Size())
+	peer := sw1.Peers().List()[0]
+
+	ban, err := sw1.BanPeer(peer.ID(), 0, "spam")
+	require.NoError(t, err)
+	assert.Equal(t, cfg.BanDuration, ban.Expires.Sub(ban.BannedAt))
+	assert.Equal(t, 0, sw1.Peers().Size())
+	assert.Equal(t, []Ban{ban}, sw1.BannedPeers())
+
+	// the banned peer is neither dialed nor accepted
+	err = sw1.DialPeerWithAddress(sw2.NetAddress())
+	assert.Equal(t, ErrPeerBanned{ban}, err)
+	err = sw1.filterPeer(peer)
+	if err, ok := err.(ErrRejected); assert.True(t, ok) {
+		assert.True(t, err.IsFiltered())
+	}
+
+	unbanned, err := sw1.UnbanPeer(peer.ID())
+	require.NoError(t, err)
+	assert.True(t, unbanned)
+	assert.Empty(t, sw1.BannedPeers())
+	assert.NoError(t, sw1.filterPeer(peer))
+}
+
+func TestSwitchBanPeerForError(t *testing.T) {
+	sw1, sw2 := MakeSwitchPair(t, initSwitchFunc)
+	defer sw1.Stop()
+	defer sw2.Stop()
+	require.Equal(t, 1, sw1.Peers().Size())
+	peer := sw1.Peers().List()[0]
+
+	sw1.BanPeerForError(peer, fmt.Errorf("invalid block"))
+	assert.Equal(t, 0, sw1.Peers().Size())
+	if bans := sw1.BannedPeers(); assert.Len(t, bans, 1) {
+		assert.Equal(t, peer.ID(), bans[0].ID)
+		assert.Equal(t, "invalid block", bans[0].Reason)
+	}
+}
+
 func waitUntilSwitchHasAtLeastNPeers(sw *Switch, n int) {
 	for i := 0; i < 20; i++ {
 		time.Sleep(250 * time.Millisecond)
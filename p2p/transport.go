@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -122,6 +123,13 @@ func MultiplexTransportResolver(resolver IPResolver) MultiplexTransportOption {
 	return func(mt *MultiplexTransport) { mt.resolver = resolver }
 }
 
+// MultiplexTransportHandshake sets the handshake the connections are dialed
+// with, conn.HandshakeNoise by default. A peer not supporting the Noise
+// handshake is dialed again, and from then on, with conn.HandshakeSTS.
+func MultiplexTransportHandshake(handshake string) MultiplexTransportOption {
+	return func(mt *MultiplexTransport) { mt.handshake = handshake }
+}
+
 // MultiplexTransport accepts and dials tcp connections and upgrades them to
 // multiplexed peers.
 type MultiplexTransport struct {
@@ -138,14 +146,23 @@ type MultiplexTransport struct {
 	dialTimeout      time.Duration
 	filterTimeout    time.Duration
 	handshakeTimeout time.Duration
-	nodeInfo         NodeInfo
 	nodeKey          NodeKey
 	resolver         IPResolver
 
+	// handshake the connections are dialed with, and the peers only supporting
+	// conn.HandshakeSTS.
+	handshake   string
+	stsPeersMtx sync.Mutex
+	stsPeers    map[ID]struct{}
+
 	// TODO(xla): This config is still needed as we parameterise peerConn and
 	// peer currently. All relevant configuration should be refactored into options
 	// with sane defaults.
-	mConfig conn.MConnConfig
+	mConfigMtx sync.RWMutex
+	mConfig    conn.MConnConfig
+
+	nodeInfoMtx sync.RWMutex
+	nodeInfo    NodeInfo
 }
 
 // Test multiplexTransport for interface completeness.
@@ -169,6 +186,8 @@ func NewMultiplexTransport(
 		nodeKey:          nodeKey,
 		conns:            NewConnSet(),
 		resolver:         net.DefaultResolver,
+		handshake:        conn.HandshakeNoise,
+		stsPeers:         make(map[ID]struct{}),
 	}
 }
 
@@ -177,6 +196,28 @@ func (mt *MultiplexTransport) NetAddress() NetAddress {
 	return mt.netAddr
 }
 
+// SetNodeInfo replaces the NodeInfo sent to peers during the handshake. It
+// only applies to peers connected afterwards.
+func (mt *MultiplexTransport) SetNodeInfo(nodeInfo NodeInfo) {
+	mt.nodeInfoMtx.Lock()
+	mt.nodeInfo = nodeInfo
+	mt.nodeInfoMtx.Unlock()
+}
+
+func (mt *MultiplexTransport) currentNodeInfo() NodeInfo {
+	mt.nodeInfoMtx.RLock()
+	defer mt.nodeInfoMtx.RUnlock()
+	return mt.nodeInfo
+}
+
+// SetMConnConfig replaces the MConnection config. It only applies to peers
+// connected afterwards.
+func (mt *MultiplexTransport) SetMConnConfig(mConfig conn.MConnConfig) {
+	mt.mConfigMtx.Lock()
+	mt.mConfig = mConfig
+	mt.mConfigMtx.Unlock()
+}
+
 // Accept implements Transport.
 func (mt *MultiplexTransport) Accept(cfg peerConfig) (Peer, error) {
 	select {
@@ -210,7 +251,15 @@ func (mt *MultiplexTransport) Dial(
 		return nil, err
 	}
 
-	secretConn, nodeInfo, err := mt.upgrade(c, &addr)
+	handshake := mt.dialHandshake(addr.ID)
+	secretConn, nodeInfo, err := mt.upgrade(c, &addr, handshake)
+	if e, ok := err.(ErrRejected); ok && errors.Cause(e.err) == conn.ErrNoiseUnsupported {
+		// The peer runs a version without the Noise handshake.
+		mt.stsPeersMtx.Lock()
+		mt.stsPeers[addr.ID] = struct{}{}
+		mt.stsPeersMtx.Unlock()
+		return mt.Dial(addr, cfg)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -297,7 +346,7 @@ func (mt *MultiplexTransport) acceptPeers() {
 
 			err := mt.filterConn(c)
 			if err == nil {
-				secretConn, nodeInfo, err = mt.upgrade(c, nil)
+				secretConn, nodeInfo, err = mt.upgrade(c, nil, "")
 				if err == nil {
 					addr := c.RemoteAddr()
 					id := PubKeyToID(secretConn.RemotePubKey())
@@ -373,9 +422,22 @@ func (mt *MultiplexTransport) filterConn(c net.Conn) (err error) {
 	return nil
 }
 
+// dialHandshake returns the handshake to dial the peer id with.
+func (mt *MultiplexTransport) dialHandshake(id ID) string {
+	mt.stsPeersMtx.Lock()
+	defer mt.stsPeersMtx.Unlock()
+	if _, ok := mt.stsPeers[id]; ok {
+		return conn.HandshakeSTS
+	}
+	return mt.handshake
+}
+
+// upgrade establishes a SecretConnection over c, dialed with
+// secretConnHandshake if dialedAddr isn't nil, and exchanges the NodeInfo.
 func (mt *MultiplexTransport) upgrade(
 	c net.Conn,
 	dialedAddr *NetAddress,
+	secretConnHandshake string,
 ) (secretConn *conn.SecretConnection, nodeInfo NodeInfo, err error) {
 	defer func() {
 		if err != nil {
@@ -383,20 +445,40 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}()
 
-	secretConn, err = upgradeSecretConn(c, mt.handshakeTimeout, mt.nodeKey.PrivKey)
+	secretConn, err = mt.makeSecretConn(c, dialedAddr != nil, secretConnHandshake)
 	if err != nil {
 		return nil, nil, ErrRejected{
 			conn:          c,
-			err:           fmt.Errorf("secret conn failed: %v", err),
+			err:           errors.Wrap(err, "secret conn failed"),
 			isAuthFailure: true,
 		}
 	}
 
+	nodeInfo, err = mt.handshakePeer(
+		c,
+		secretConn,
+		PubKeyToID(secretConn.RemotePubKey()),
+		dialedAddr,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return secretConn, nodeInfo, nil
+}
+
+// handshakePeer exchanges the NodeInfo over hc with the peer authenticated as
+// connID on c, and checks it.
+func (mt *MultiplexTransport) handshakePeer(
+	c net.Conn,
+	hc net.Conn,
+	connID ID,
+	dialedAddr *NetAddress,
+) (nodeInfo NodeInfo, err error) {
 	// For outgoing conns, ensure connection key matches dialed key.
-	connID := PubKeyToID(secretConn.RemotePubKey())
 	if dialedAddr != nil {
 		if dialedID := dialedAddr.ID; connID != dialedID {
-			return nil, nil, ErrRejected{
+			return nil, ErrRejected{
 				conn: c,
 				id:   connID,
 				err: fmt.Errorf(
@@ -409,9 +491,10 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
-	nodeInfo, err = handshake(secretConn, mt.handshakeTimeout, mt.nodeInfo)
+	ourNodeInfo := mt.currentNodeInfo()
+	nodeInfo, err = handshake(hc, mt.handshakeTimeout, ourNodeInfo)
 	if err != nil {
-		return nil, nil, ErrRejected{
+		return nil, ErrRejected{
 			conn:          c,
 			err:           fmt.Errorf("handshake failed: %v", err),
 			isAuthFailure: true,
@@ -419,7 +502,7 @@ func (mt *MultiplexTransport) upgrade(
 	}
 
 	if err := nodeInfo.Validate(); err != nil {
-		return nil, nil, ErrRejected{
+		return nil, ErrRejected{
 			conn:              c,
 			err:               err,
 			isNodeInfoInvalid: true,
@@ -428,7 +511,7 @@ func (mt *MultiplexTransport) upgrade(
 
 	// Ensure connection key matches self reported key.
 	if connID != nodeInfo.ID() {
-		return nil, nil, ErrRejected{
+		return nil, ErrRejected{
 			conn: c,
 			id:   connID,
 			err: fmt.Errorf(
@@ -441,8 +524,8 @@ func (mt *MultiplexTransport) upgrade(
 	}
 
 	// Reject self.
-	if mt.nodeInfo.ID() == nodeInfo.ID() {
-		return nil, nil, ErrRejected{
+	if ourNodeInfo.ID() == nodeInfo.ID() {
+		return nil, ErrRejected{
 			addr:   *NewNetAddress(nodeInfo.ID(), c.RemoteAddr()),
 			conn:   c,
 			id:     nodeInfo.ID(),
@@ -450,8 +533,8 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
-	if err := mt.nodeInfo.CompatibleWith(nodeInfo); err != nil {
-		return nil, nil, ErrRejected{
+	if err := ourNodeInfo.CompatibleWith(nodeInfo); err != nil {
+		return nil, ErrRejected{
 			conn:           c,
 			err:            err,
 			id:             nodeInfo.ID(),
@@ -459,7 +542,7 @@ func (mt *MultiplexTransport) upgrade(
 		}
 	}
 
-	return secretConn, nodeInfo, nil
+	return nodeInfo, nil
 }
 
 func (mt *MultiplexTransport) wrapPeer(
@@ -488,9 +571,14 @@ func (mt *MultiplexTransport) wrapPeer(
 		socketAddr,
 	)
 
+	mt.mConfigMtx.RLock()
+	mConfig := mt.mConfig
+	mt.mConfigMtx.RUnlock()
+	mConfig.CompressedChannels = compressedChannels(mt.currentNodeInfo(), ni)
+
 	p := newPeer(
 		peerConn,
-		mt.mConfig,
+		mConfig,
 		ni,
 		cfg.reactorsByCh,
 		cfg.chDescs,
@@ -501,6 +589,21 @@ func (mt *MultiplexTransport) wrapPeer(
 	return p
 }
 
+// compressedChannels returns the channels both ours and theirs compress.
+func compressedChannels(ours, theirs NodeInfo) []byte {
+	var (
+		ourChannels   = ours.(DefaultNodeInfo).CompressedChannels
+		theirChannels = theirs.(DefaultNodeInfo).CompressedChannels
+		channels      = []byte{}
+	)
+	for _, chID := range ourChannels {
+		if containsByte(theirChannels, chID) {
+			channels = append(channels, chID)
+		}
+	}
+	return channels
+}
+
 func handshake(
 	c net.Conn,
 	timeout time.Duration,
@@ -540,6 +643,32 @@ func handshake(
 	return peerNodeInfo, c.SetDeadline(time.Time{})
 }
 
+// makeSecretConn establishes a SecretConnection over c, dialing it with
+// handshake if dialed, and accepting it with the handshake of the dialer
+// otherwise.
+func (mt *MultiplexTransport) makeSecretConn(
+	c net.Conn,
+	dialed bool,
+	handshake string,
+) (sc *conn.SecretConnection, err error) {
+	if err := c.SetDeadline(time.Now().Add(mt.handshakeTimeout)); err != nil {
+		return nil, err
+	}
+
+	if dialed {
+		sc, err = conn.DialSecretConnection(c, mt.nodeKey.PrivKey, handshake)
+	} else {
+		sc, err = conn.AcceptSecretConnection(c, mt.nodeKey.PrivKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sc, sc.SetDeadline(time.Time{})
+}
+
+// upgradeSecretConn establishes a SecretConnection over c with the STS
+// handshake, which both sides start.
 func upgradeSecretConn(
 	c net.Conn,
 	timeout time.Duration,
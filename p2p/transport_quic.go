@@ -0,0 +1,691 @@
+package p2p
+
+import (
+	"context"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	quic "github.com/quic-go/quic-go"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	flow "github.com/tendermint/tendermint/libs/flowrate"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	tmconn "github.com/tendermint/tendermint/p2p/conn"
+)
+
+const (
+	// quicALPN is the application protocol negotiated by the TLS handshake of
+	// the QUIC connections.
+	quicALPN = "tendermint-p2p"
+
+	// quicKeepAlivePeriod is the period of the keep-alive packets, keeping
+	// the QUIC connections open (and their NAT mappings) between the pings
+	// of the channels.
+	quicKeepAlivePeriod = 15 * time.Second
+)
+
+// QUICTransport accepts and dials QUIC connections and upgrades them to peers
+// carrying every channel on a QUIC stream of its own, so that a packet lost on
+// one channel (e.g. a block part) doesn't hold the others back, like it does
+// on a TCP connection.
+//
+// It listens for QUIC connections on the UDP port of the listen address, and
+// for TCP connections on its TCP port with the MultiplexTransport it wraps, so
+// that peers running the MultiplexTransport can still dial it. A peer which
+// can't be dialed over QUIC is dialed, and from then on, over TCP.
+//
+// The peers are authenticated by the TLS handshake of the QUIC connection,
+// presenting certificates signed with their node keys.
+type QUICTransport struct {
+	// MultiplexTransport of the TCP connections, whose config, filters and
+	// set of connections also apply to the QUIC connections.
+	tcp *MultiplexTransport
+
+	tlsConfig  *tls.Config
+	quicConfig *quic.Config
+
+	// UDP socket the QUIC connections are accepted on and dialed from.
+	udp      *quic.Transport
+	listener *quic.Listener
+
+	// peers dialed over TCP, since they can't be over QUIC
+	tcpPeersMtx sync.Mutex
+	tcpPeers    map[ID]struct{}
+}
+
+// Test QUICTransport for interface completeness.
+var _ Transport = (*QUICTransport)(nil)
+var _ transportLifecycle = (*QUICTransport)(nil)
+
+// NewQUICTransport returns a QUIC transport, falling back to tcp for the peers
+// which don't support QUIC. The node key of tcp must be an ed25519 key.
+func NewQUICTransport(tcp *MultiplexTransport) (*QUICTransport, error) {
+	tlsConfig, err := quicTLSConfig(tcp.nodeKey.PrivKey)
+	if err != nil {
+		return nil, err
+	}
+	return &QUICTransport{
+		tcp:       tcp,
+		tlsConfig: tlsConfig,
+		quicConfig: &quic.Config{
+			HandshakeIdleTimeout: tcp.handshakeTimeout,
+			KeepAlivePeriod:      quicKeepAlivePeriod,
+		},
+		tcpPeers: make(map[ID]struct{}),
+	}, nil
+}
+
+// NetAddress implements Transport.
+func (qt *QUICTransport) NetAddress() NetAddress {
+	return qt.tcp.NetAddress()
+}
+
+// SetNodeInfo replaces the NodeInfo sent to peers during the handshake. It
+// only applies to peers connected afterwards.
+func (qt *QUICTransport) SetNodeInfo(nodeInfo NodeInfo) {
+	qt.tcp.SetNodeInfo(nodeInfo)
+}
+
+// SetMConnConfig replaces the MConnection config, applied to the stream of
+// every channel. It only applies to peers connected afterwards.
+func (qt *QUICTransport) SetMConnConfig(mConfig tmconn.MConnConfig) {
+	qt.tcp.SetMConnConfig(mConfig)
+}
+
+// Accept implements Transport.
+func (qt *QUICTransport) Accept(cfg peerConfig) (Peer, error) {
+	// the QUIC connections are made available along the TCP ones
+	return qt.tcp.Accept(cfg)
+}
+
+// Dial implements Transport.
+func (qt *QUICTransport) Dial(
+	addr NetAddress,
+	cfg peerConfig,
+) (Peer, error) {
+	if qt.dialsTCP(addr.ID) {
+		return qt.tcp.Dial(addr, cfg)
+	}
+
+	c, err := qt.dial(addr)
+	if err != nil {
+		// The peer doesn't accept QUIC connections, e.g. it runs the
+		// MultiplexTransport.
+		p, tcpErr := qt.tcp.Dial(addr, cfg)
+		if tcpErr != nil {
+			return nil, tcpErr
+		}
+		qt.tcpPeersMtx.Lock()
+		qt.tcpPeers[addr.ID] = struct{}{}
+		qt.tcpPeersMtx.Unlock()
+		return p, nil
+	}
+
+	if err := qt.tcp.filterConn(c); err != nil {
+		return nil, err
+	}
+
+	nodeInfo, err := qt.upgrade(c, &addr)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.outbound = true
+
+	return qt.tcp.wrapPeer(c, nodeInfo, cfg, &addr), nil
+}
+
+// Cleanup implements Transport.
+func (qt *QUICTransport) Cleanup(p Peer) {
+	qt.tcp.Cleanup(p)
+}
+
+// Close implements transportLifecycle.
+func (qt *QUICTransport) Close() error {
+	err := qt.tcp.Close()
+
+	if qt.udp != nil {
+		// the quic.Transport doesn't close the socket it was given
+		_ = qt.udp.Close()
+		if udpErr := qt.udp.Conn.Close(); err == nil {
+			err = udpErr
+		}
+	}
+
+	return err
+}
+
+// Listen implements transportLifecycle.
+func (qt *QUICTransport) Listen(addr NetAddress) error {
+	if err := qt.tcp.Listen(addr); err != nil {
+		return err
+	}
+
+	// Listen on the same port as the TCP listener, e.g. if addr has port 0.
+	_, port, err := net.SplitHostPort(qt.tcp.listener.Addr().String())
+	if err != nil {
+		return err
+	}
+	udpConn, err := net.ListenPacket("udp", net.JoinHostPort(addr.IP.String(), port))
+	if err != nil {
+		return err
+	}
+
+	udp := &quic.Transport{Conn: udpConn}
+	ln, err := udp.Listen(qt.tlsConfig, qt.quicConfig)
+	if err != nil {
+		_ = udpConn.Close()
+		return err
+	}
+	qt.udp = udp
+	qt.listener = ln
+
+	go qt.acceptPeers()
+
+	return nil
+}
+
+func (qt *QUICTransport) acceptPeers() {
+	for {
+		qc, err := qt.listener.Accept(context.Background())
+		if err != nil {
+			// If Close() has been called, silently exit.
+			select {
+			case _, ok := <-qt.tcp.closec:
+				if !ok {
+					return
+				}
+			default:
+				// Transport is not closed
+			}
+
+			qt.tcp.acceptc <- accept{err: err}
+			return
+		}
+
+		// Upgrade the connections asynchronously, like the MultiplexTransport.
+		go func(c *quicConn) {
+			defer func() {
+				if r := recover(); r != nil {
+					err := ErrRejected{
+						conn:          c,
+						err:           errors.Errorf("recovered from panic: %v", r),
+						isAuthFailure: true,
+					}
+					select {
+					case qt.tcp.acceptc <- accept{err: err}:
+					case <-qt.tcp.closec:
+						// Give up if the transport was closed.
+						_ = c.Close()
+						return
+					}
+				}
+			}()
+
+			var (
+				nodeInfo NodeInfo
+				netAddr  *NetAddress
+			)
+
+			err := qt.tcp.filterConn(c)
+			if err == nil {
+				nodeInfo, err = qt.upgrade(c, nil)
+				if err == nil {
+					netAddr = NewNetAddress(nodeInfo.ID(), c.RemoteAddr())
+				}
+			}
+
+			select {
+			case qt.tcp.acceptc <- accept{netAddr, c, nodeInfo, err}:
+				// Make the upgraded peer available.
+			case <-qt.tcp.closec:
+				// Give up if the transport was closed.
+				_ = c.Close()
+				return
+			}
+		}(newQUICConn(qc))
+	}
+}
+
+// dialsTCP returns whether the peer id is dialed over TCP.
+func (qt *QUICTransport) dialsTCP(id ID) bool {
+	qt.tcpPeersMtx.Lock()
+	defer qt.tcpPeersMtx.Unlock()
+	_, ok := qt.tcpPeers[id]
+	return ok
+}
+
+// dial dials a QUIC connection to addr, from the UDP socket listened on if
+// any.
+func (qt *QUICTransport) dial(addr NetAddress) (*quicConn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), qt.tcp.dialTimeout)
+	defer cancel()
+
+	var (
+		qc  quic.Connection
+		err error
+	)
+	if qt.udp != nil {
+		qc, err = qt.udp.Dial(ctx, &net.UDPAddr{IP: addr.IP, Port: int(addr.Port)}, qt.tlsConfig, qt.quicConfig)
+	} else {
+		qc, err = quic.DialAddr(ctx, addr.DialString(), qt.tlsConfig, qt.quicConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newQUICConn(qc), nil
+}
+
+// upgrade authenticates the peer of c, dialed if dialedAddr isn't nil,
+// exchanges the NodeInfo on the control stream, and sets up the streams of the
+// channels both sides have.
+func (qt *QUICTransport) upgrade(
+	c *quicConn,
+	dialedAddr *NetAddress,
+) (nodeInfo NodeInfo, err error) {
+	defer func() {
+		if err != nil {
+			_ = qt.tcp.cleanup(c)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), qt.tcp.handshakeTimeout)
+	defer cancel()
+
+	connID, err := quicConnID(c.conn)
+	if err != nil {
+		return nil, ErrRejected{
+			conn:          c,
+			err:           errors.Wrap(err, "quic conn failed"),
+			isAuthFailure: true,
+		}
+	}
+
+	var control quic.Stream
+	if dialedAddr != nil {
+		control, err = c.conn.OpenStreamSync(ctx)
+	} else {
+		control, err = c.conn.AcceptStream(ctx)
+	}
+	if err != nil {
+		return nil, ErrRejected{
+			conn:          c,
+			err:           errors.Wrap(err, "control stream failed"),
+			isAuthFailure: true,
+		}
+	}
+	c.quicStream = quicStream{Stream: control, conn: c.conn}
+
+	nodeInfo, err = qt.tcp.handshakePeer(c, c, connID, dialedAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := sharedChannels(qt.tcp.currentNodeInfo(), nodeInfo)
+	if dialedAddr != nil {
+		err = c.openChannelStreams(ctx, channels)
+	} else {
+		err = c.acceptChannelStreams(ctx, channels)
+	}
+	if err != nil {
+		return nil, ErrRejected{
+			conn:          c,
+			err:           errors.Wrap(err, "channel streams failed"),
+			id:            connID,
+			isAuthFailure: true,
+		}
+	}
+
+	return nodeInfo, nil
+}
+
+// sharedChannels returns the channels of both ours and theirs, sorted.
+func sharedChannels(ours, theirs NodeInfo) []byte {
+	var (
+		ourChannels   = ours.(DefaultNodeInfo).Channels
+		theirChannels = theirs.(DefaultNodeInfo).Channels
+		channels      = []byte{}
+	)
+	for _, chID := range ourChannels {
+		for _, theirChID := range theirChannels {
+			if chID == theirChID {
+				channels = append(channels, chID)
+				break
+			}
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i] < channels[j] })
+	return channels
+}
+
+// quicConnID returns the ID of the node key the peer of qc presented the TLS
+// certificate of.
+func quicConnID(qc quic.Connection) (ID, error) {
+	certs := qc.ConnectionState().TLS.PeerCertificates
+	if len(certs) == 0 {
+		return "", errors.New("no peer certificate")
+	}
+	key, ok := certs[0].PublicKey.(stded25519.PublicKey)
+	if !ok || len(key) != ed25519.PubKeyEd25519Size {
+		return "", errors.New("the peer certificate has no ed25519 key")
+	}
+	var pubKey ed25519.PubKeyEd25519
+	copy(pubKey[:], key)
+	return PubKeyToID(pubKey), nil
+}
+
+// quicTLSConfig returns the TLS config of the QUIC connections, presenting a
+// certificate signed with privKey, and accepting the certificates of any
+// ed25519 key, checked against the dialed or self reported ID of the peer.
+func quicTLSConfig(privKey crypto.PrivKey) (*tls.Config, error) {
+	edKey, ok := privKey.(ed25519.PrivKeyEd25519)
+	if !ok {
+		return nil, errors.Errorf("the QUIC transport requires an ed25519 node key, got %T", privKey)
+	}
+	key := stded25519.PrivateKey(edKey[:])
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(100 * 365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the TLS certificate")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		NextProtos:   []string{quicALPN},
+		MinVersion:   tls.VersionTLS13,
+		ClientAuth:   tls.RequireAnyClientCert,
+		// The certificates are self-signed, the peers are authenticated by
+		// the ID of their key.
+		InsecureSkipVerify: true, // nolint: gosec
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) != 1 {
+				return errors.Errorf("expected 1 peer certificate, got %d", len(rawCerts))
+			}
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			if _, ok := cert.PublicKey.(stded25519.PublicKey); !ok {
+				return errors.New("the peer certificate has no ed25519 key")
+			}
+			return nil
+		},
+	}, nil
+}
+
+//-----------------------------------------------------------------------------
+
+// quicStream is a QUIC stream as a net.Conn.
+type quicStream struct {
+	quic.Stream
+	conn quic.Connection
+}
+
+// LocalAddr implements net.Conn.
+func (s quicStream) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// RemoteAddr implements net.Conn.
+func (s quicStream) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// Close implements net.Conn, closing both directions of the stream.
+func (s quicStream) Close() error {
+	s.CancelRead(0)
+	return s.Stream.Close()
+}
+
+// quicConn is a QUIC connection as a net.Conn, reading and writing its control
+// stream, on which the NodeInfo is exchanged. It carries every channel on a
+// stream of its own.
+type quicConn struct {
+	quicStream
+
+	streams map[byte]net.Conn
+}
+
+func newQUICConn(qc quic.Connection) *quicConn {
+	return &quicConn{quicStream: quicStream{conn: qc}}
+}
+
+// Close implements net.Conn, closing the connection and all its streams.
+func (c *quicConn) Close() error {
+	return c.conn.CloseWithError(0, "")
+}
+
+// openChannelStreams opens the streams of channels, sending the channel ID on
+// each.
+func (c *quicConn) openChannelStreams(ctx context.Context, channels []byte) error {
+	c.streams = make(map[byte]net.Conn, len(channels))
+	for _, chID := range channels {
+		s, err := c.conn.OpenStreamSync(ctx)
+		if err != nil {
+			return err
+		}
+		if _, err := s.Write([]byte{chID}); err != nil {
+			return err
+		}
+		c.streams[chID] = quicStream{Stream: s, conn: c.conn}
+	}
+	return nil
+}
+
+// acceptChannelStreams accepts the streams of channels, opened by the dialer.
+func (c *quicConn) acceptChannelStreams(ctx context.Context, channels []byte) error {
+	c.streams = make(map[byte]net.Conn, len(channels))
+	deadline, _ := ctx.Deadline()
+	for range channels {
+		s, err := c.conn.AcceptStream(ctx)
+		if err != nil {
+			return err
+		}
+		chID := make([]byte, 1)
+		if err := s.SetReadDeadline(deadline); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(s, chID); err != nil {
+			return err
+		}
+		if err := s.SetReadDeadline(time.Time{}); err != nil {
+			return err
+		}
+		if _, ok := c.streams[chID[0]]; ok || !containsByte(channels, chID[0]) {
+			return fmt.Errorf("unexpected stream of channel %#x", chID[0])
+		}
+		c.streams[chID[0]] = quicStream{Stream: s, conn: c.conn}
+	}
+	return nil
+}
+
+func containsByte(bz []byte, b byte) bool {
+	for _, x := range bz {
+		if x == b {
+			return true
+		}
+	}
+	return false
+}
+
+//-----------------------------------------------------------------------------
+
+// quicMConnection multiplexes the channels of a peer over the streams of a
+// quicConn, running an MConnection of a single channel on each.
+// NOTE: The send and receive rates of the MConnConfig apply to every channel.
+// As the channels don't share a connection, their priorities and the yielding
+// channels have no effect.
+type quicMConnection struct {
+	service.BaseService
+
+	conn   *quicConn
+	chIDs  []byte
+	mconns map[byte]*tmconn.MConnection
+}
+
+func newQUICMConnection(
+	conn *quicConn,
+	p *peer,
+	reactorsByCh map[byte]Reactor,
+	chDescs []*tmconn.ChannelDescriptor,
+	onPeerError func(Peer, interface{}),
+	config tmconn.MConnConfig,
+) *quicMConnection {
+	mc := &quicMConnection{
+		conn:   conn,
+		mconns: make(map[byte]*tmconn.MConnection),
+	}
+	for _, chDesc := range chDescs {
+		stream, ok := conn.streams[chDesc.ID]
+		if !ok {
+			continue
+		}
+		mc.chIDs = append(mc.chIDs, chDesc.ID)
+		mc.mconns[chDesc.ID] = createMConnection(
+			stream,
+			p,
+			reactorsByCh,
+			[]*tmconn.ChannelDescriptor{chDesc},
+			onPeerError,
+			config,
+		)
+	}
+	mc.BaseService = *service.NewBaseService(nil, "QUICMConnection", mc)
+	return mc
+}
+
+// SetLogger implements BaseService.
+func (mc *quicMConnection) SetLogger(l log.Logger) {
+	mc.BaseService.SetLogger(l)
+	for _, chID := range mc.chIDs {
+		mc.mconns[chID].SetLogger(l.With("chID", fmt.Sprintf("%#x", chID)))
+	}
+}
+
+// OnStart implements BaseService.
+func (mc *quicMConnection) OnStart() error {
+	for _, chID := range mc.chIDs {
+		if err := mc.mconns[chID].Start(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FlushStop flushes the sends of every channel, and closes the connection.
+func (mc *quicMConnection) FlushStop() {
+	for _, chID := range mc.chIDs {
+		mc.mconns[chID].FlushStop()
+	}
+	mc.conn.Close() // nolint: errcheck
+}
+
+// OnStop implements BaseService.
+func (mc *quicMConnection) OnStop() {
+	for _, chID := range mc.chIDs {
+		mc.mconns[chID].Stop() // nolint: errcheck
+	}
+	mc.conn.Close() // nolint: errcheck
+}
+
+func (mc *quicMConnection) String() string {
+	return fmt.Sprintf("QUICConn{%v}", mc.conn.RemoteAddr())
+}
+
+// Send queues msgBytes on the stream of chID.
+func (mc *quicMConnection) Send(chID byte, msgBytes []byte) bool {
+	mconn, ok := mc.mconns[chID]
+	if !ok {
+		return false
+	}
+	return mconn.Send(chID, msgBytes)
+}
+
+// TrySend queues msgBytes on the stream of chID, if it isn't full.
+func (mc *quicMConnection) TrySend(chID byte, msgBytes []byte) bool {
+	mconn, ok := mc.mconns[chID]
+	if !ok {
+		return false
+	}
+	return mconn.TrySend(chID, msgBytes)
+}
+
+// CanSend returns whether msgBytes can be queued on the stream of chID.
+func (mc *quicMConnection) CanSend(chID byte) bool {
+	mconn, ok := mc.mconns[chID]
+	if !ok {
+		return false
+	}
+	return mconn.CanSend(chID)
+}
+
+// Status returns the status of the streams together: the rates are summed
+// over the streams, and the ping latencies and round trip time are those of the
+// stream with the most pings.
+func (mc *quicMConnection) Status() tmconn.ConnectionStatus {
+	var status tmconn.ConnectionStatus
+	for _, chID := range mc.chIDs {
+		s := mc.mconns[chID].Status()
+		if s.Duration > status.Duration {
+			status.Duration = s.Duration
+		}
+		addFlowStatus(&status.SendMonitor, s.SendMonitor)
+		addFlowStatus(&status.RecvMonitor, s.RecvMonitor)
+		addTailRates(&status.SendTail, s.SendTail)
+		addTailRates(&status.RecvTail, s.RecvTail)
+		if s.RecvTail.Latencies > status.RecvTail.Latencies {
+			status.RecvTail.Latencies = s.RecvTail.Latencies
+			status.RecvTail.LatencyP50 = s.RecvTail.LatencyP50
+			status.RecvTail.LatencyP90 = s.RecvTail.LatencyP90
+			status.RecvTail.LatencyP99 = s.RecvTail.LatencyP99
+			status.RTT = s.RTT
+		}
+		status.Channels = append(status.Channels, s.Channels...)
+	}
+	return status
+}
+
+// addFlowStatus adds the transfer of s to status.
+func addFlowStatus(status *flow.Status, s flow.Status) {
+	if status.Start.IsZero() || s.Start.Before(status.Start) {
+		status.Start = s.Start
+	}
+	if s.Duration > status.Duration {
+		status.Duration = s.Duration
+	}
+	if status.Samples == 0 || s.Idle < status.Idle {
+		status.Idle = s.Idle
+	}
+	status.Bytes += s.Bytes
+	status.Samples += s.Samples
+	status.InstRate += s.InstRate
+	status.CurRate += s.CurRate
+	status.AvgRate += s.AvgRate
+	status.PeakRate += s.PeakRate
+	status.Active = status.Active || s.Active
+}
+
+// addTailRates adds the rate percentiles of s to status.
+func addTailRates(status *flow.TailStatus, s flow.TailStatus) {
+	status.RateP10 += s.RateP10
+	status.RateP50 += s.RateP50
+	status.RateP90 += s.RateP90
+}
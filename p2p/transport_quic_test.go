@@ -0,0 +1,164 @@
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p/conn"
+)
+
+func newQUICTestTransport(t *testing.T, name string, channels []byte) *QUICTransport {
+	pv := ed25519.GenPrivKey()
+	nodeInfo := testNodeInfo(PubKeyToID(pv.PubKey()), name).(DefaultNodeInfo)
+	nodeInfo.Channels = channels
+	qt, err := NewQUICTransport(newMultiplexTransport(nodeInfo, NodeKey{PrivKey: pv}))
+	require.NoError(t, err)
+	return qt
+}
+
+func listenQUICTestTransport(t *testing.T, qt *QUICTransport) *NetAddress {
+	addr, err := NewNetAddressString(IDAddressString(qt.tcp.nodeKey.ID(), "127.0.0.1:0"))
+	require.NoError(t, err)
+	require.NoError(t, qt.Listen(*addr))
+	return NewNetAddress(qt.tcp.nodeKey.ID(), qt.tcp.listener.Addr())
+}
+
+func testPeerConfig(reactor *TestReactor) peerConfig {
+	reactorsByCh := make(map[byte]Reactor)
+	for _, chDesc := range reactor.GetChannels() {
+		reactorsByCh[chDesc.ID] = reactor
+	}
+	return peerConfig{
+		chDescs:      reactor.GetChannels(),
+		onPeerError:  func(Peer, interface{}) {},
+		reactorsByCh: reactorsByCh,
+		metrics:      NopMetrics(),
+	}
+}
+
+func TestQUICTransport(t *testing.T) {
+	chDescs := []*conn.ChannelDescriptor{
+		{ID: 0x01, Priority: 1, SendQueueCapacity: 10},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 10},
+	}
+	var (
+		listener        = newQUICTestTransport(t, "listener", []byte{0x01, 0x02})
+		dialer          = newQUICTestTransport(t, "dialer", []byte{0x01, 0x02})
+		listenerReactor = NewTestReactor(chDescs, true)
+		dialerReactor   = NewTestReactor(chDescs, true)
+	)
+	addr := listenQUICTestTransport(t, listener)
+	defer listener.Close()
+
+	acceptc := make(chan Peer)
+	go func() {
+		p, err := listener.Accept(testPeerConfig(listenerReactor))
+		assert.NoError(t, err)
+		acceptc <- p
+	}()
+
+	dialed, err := dialer.Dial(*addr, testPeerConfig(dialerReactor))
+	require.NoError(t, err)
+	accepted := <-acceptc
+	require.NotNil(t, accepted)
+	assert.Equal(t, addr.ID, dialed.ID())
+	assert.Equal(t, dialer.tcp.nodeKey.ID(), accepted.ID())
+
+	// every channel is carried on a QUIC stream of its own
+	for _, p := range []Peer{dialed, accepted} {
+		assert.Equal(t, "udp", p.RemoteAddr().Network())
+		assert.NotZero(t, p.SocketAddr().Port)
+		mconn, ok := p.(*peer).mconn.(*quicMConnection)
+		require.True(t, ok)
+		assert.Equal(t, []byte{0x01, 0x02}, mconn.chIDs)
+
+		p.SetLogger(log.TestingLogger())
+		require.NoError(t, p.Start())
+		defer p.Stop() // nolint: errcheck
+	}
+
+	assert.True(t, dialed.Send(0x01, []byte("one")))
+	assert.True(t, dialed.Send(0x02, []byte("two")))
+	assert.True(t, accepted.Send(0x02, []byte("back")))
+	assert.Eventually(t, func() bool {
+		return len(listenerReactor.getMsgs(0x01)) == 1 &&
+			len(listenerReactor.getMsgs(0x02)) == 1 &&
+			len(dialerReactor.getMsgs(0x02)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []byte("two"), listenerReactor.getMsgs(0x02)[0].Bytes)
+	assert.Equal(t, []byte("back"), dialerReactor.getMsgs(0x02)[0].Bytes)
+
+	status := dialed.Status()
+	assert.Len(t, status.Channels, 2)
+	assert.NotZero(t, status.SendMonitor.Bytes)
+}
+
+func TestQUICTransportSharedChannels(t *testing.T) {
+	chDescs := []*conn.ChannelDescriptor{
+		{ID: 0x01, Priority: 1, SendQueueCapacity: 10},
+		{ID: 0x02, Priority: 1, SendQueueCapacity: 10},
+	}
+	var (
+		listener = newQUICTestTransport(t, "listener", []byte{0x02, 0x03})
+		dialer   = newQUICTestTransport(t, "dialer", []byte{0x01, 0x02})
+	)
+	addr := listenQUICTestTransport(t, listener)
+	defer listener.Close()
+
+	errc := make(chan error)
+	go func() {
+		_, err := listener.Accept(testPeerConfig(NewTestReactor(chDescs, false)))
+		errc <- err
+	}()
+
+	p, err := dialer.Dial(*addr, testPeerConfig(NewTestReactor(chDescs, false)))
+	require.NoError(t, err)
+	defer p.CloseConn() // nolint: errcheck
+	require.NoError(t, <-errc)
+
+	// only the channel both have gets a stream
+	assert.Equal(t, []byte{0x02}, p.(*peer).mconn.(*quicMConnection).chIDs)
+	assert.False(t, p.(*peer).mconn.CanSend(0x01))
+}
+
+func TestQUICTransportTCPPeer(t *testing.T) {
+	// a peer running the MultiplexTransport is dialed over TCP
+	tcpPeer := testSetupMultiplexTransport(t)
+	defer tcpPeer.Close()
+	go func() {
+		_, err := tcpPeer.Accept(peerConfig{})
+		assert.NoError(t, err)
+	}()
+
+	dialer := newQUICTestTransport(t, "dialer", []byte{testCh})
+	dialerAddr := listenQUICTestTransport(t, dialer)
+	defer dialer.Close()
+
+	addr := NewNetAddress(tcpPeer.nodeKey.ID(), tcpPeer.listener.Addr())
+	p, err := dialer.Dial(*addr, peerConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", p.RemoteAddr().Network())
+	assert.True(t, dialer.dialsTCP(addr.ID))
+	_ = p.CloseConn()
+
+	// and dials the QUIC transport over TCP too
+	go func() {
+		_, err := tcpPeer.Dial(*dialerAddr, peerConfig{})
+		assert.NoError(t, err)
+	}()
+	p, err = dialer.Accept(peerConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", p.RemoteAddr().Network())
+	assert.IsType(t, &net.TCPAddr{}, p.RemoteAddr())
+}
+
+func TestQUICTransportRequiresEd25519Key(t *testing.T) {
+	_, err := quicTLSConfig(nil)
+	assert.Error(t, err)
+}
@@ -504,6 +504,120 @@ func TestTransportConnDuplicateIPFilter(t *testing.T) {
 	}
 }
 
+func TestTransportMultiplexDialSTSPeer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	var (
+		peerPV = ed25519.GenPrivKey()
+		peerID = PubKeyToID(peerPV.PubKey())
+		pv     = ed25519.GenPrivKey()
+		dialer = newMultiplexTransport(
+			testNodeInfo(PubKeyToID(pv.PubKey()), defaultNodeName),
+			NodeKey{
+				PrivKey: pv,
+			},
+		)
+	)
+
+	// Simulate a peer of a version only supporting the STS handshake, which
+	// drops the connection dialed with the Noise handshake.
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			sc, err := upgradeSecretConn(c, time.Second, peerPV)
+			if err != nil {
+				c.Close()
+				continue
+			}
+			_, err = handshake(sc, time.Second, testNodeInfo(peerID, "sts_peer"))
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+
+	addr := NewNetAddress(peerID, ln.Addr())
+	for i := 0; i < 2; i++ {
+		p, err := dialer.Dial(*addr, peerConfig{})
+		if err != nil {
+			t.Fatalf("connection failed: %v", err)
+		}
+		if have, want := p.ID(), peerID; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		if have, want := dialer.dialHandshake(peerID), conn.HandshakeSTS; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		dialer.Cleanup(p)
+	}
+}
+
+func TestTransportMultiplexCompressedChannels(t *testing.T) {
+	newTransport := func(compressedChannels []byte) *MultiplexTransport {
+		pv := ed25519.GenPrivKey()
+		ni := testNodeInfo(PubKeyToID(pv.PubKey()), defaultNodeName).(DefaultNodeInfo)
+		ni.CompressedChannels = compressedChannels
+		return newMultiplexTransport(ni, NodeKey{PrivKey: pv})
+	}
+	cfg := peerConfig{
+		chDescs: []*conn.ChannelDescriptor{{ID: testCh, Priority: 1}},
+	}
+
+	mt := newTransport([]byte{testCh})
+	addr, err := NewNetAddressString(IDAddressString(mt.nodeKey.ID(), "127.0.0.1:0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mt.Listen(*addr); err != nil {
+		t.Fatal(err)
+	}
+	defer mt.Close()
+
+	// the channel is only compressed if both sides compress it
+	for _, compressed := range []bool{true, false} {
+		var dialer *MultiplexTransport
+		if compressed {
+			dialer = newTransport([]byte{testCh})
+		} else {
+			dialer = newTransport(nil)
+		}
+
+		acceptedc := make(chan Peer)
+		go func() {
+			p, err := mt.Accept(cfg)
+			if err != nil {
+				t.Error(err)
+			}
+			acceptedc <- p
+		}()
+
+		dialed, err := dialer.Dial(*NewNetAddress(mt.nodeKey.ID(), mt.listener.Addr()), cfg)
+		if err != nil {
+			t.Fatalf("connection failed: %v", err)
+		}
+		accepted := <-acceptedc
+		if accepted == nil {
+			t.Fatal("connection not accepted")
+		}
+
+		if have, want := dialed.Status().Channels[0].Compressed, compressed; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		if have, want := accepted.Status().Channels[0].Compressed, compressed; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		dialer.Cleanup(dialed)
+		mt.Cleanup(accepted)
+	}
+}
+
 func TestTransportHandshake(t *testing.T) {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
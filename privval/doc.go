@@ -6,6 +6,8 @@ FilePV
 
 FilePV is the simplest implementation and developer default.
 It uses one file for the private key and another to store state.
+The private key can be encrypted at rest, with a passphrase or with a secret
+from a KMS, and is then decrypted on loading with LoadFilePVWithSecret.
 
 SignerListenerEndpoint
 
@@ -19,5 +21,23 @@ SignerDialerEndpoint
 
 SignerDialerEndpoint is a simple wrapper around a net.Conn. It's used by both IPCVal and TCPVal.
 
+FailoverSignerClient
+
+FailoverSignerClient signs with one of several SignerClient, connected to
+remote signers holding the same key, and fails over to the next one when the
+active signer can't be reached.
+
+ThresholdSignerClient
+
+ThresholdSignerClient signs with a key split into shares, held by several
+ThresholdShard processes connecting to a SignerListenerEndpoint each.
+Threshold of them are needed to sign, and none can sign alone.
+
+gRPC
+
+The privval/grpc package implements the remote signer protocol over gRPC:
+the node connects to the signer, serving the PrivValidatorAPI service,
+optionally over mutual TLS, and keeps the connection alive with pings.
+
 */
 package privval
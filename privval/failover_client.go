@@ -0,0 +1,248 @@
+package privval
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// failoverHealthCheckPeriod is the period the remote signers are checked at.
+	failoverHealthCheckPeriod = time.Second
+	// failoverSignTimeout is the time to wait for a remote signer to sign,
+	// before failing over.
+	failoverSignTimeout = time.Second
+)
+
+// FailoverSignerClient implements PrivValidator with several remote signers
+// holding the same key, connected to an endpoint each. It signs with one of
+// them, the active signer, and fails over to the next available one if it
+// can't be reached, instead of blocking consensus until it's back.
+//
+// A signer not signing within failoverSignTimeout fails. A failed signer
+// isn't used again for the cooldown, nor until it answers a health check with
+// the right public key. The active signer is kept as long as it works, there's
+// no failing back to the first one.
+//
+// The signers refusing to sign (e.g. a double sign) isn't a failure: the
+// error is returned, the request isn't sent to the others.
+type FailoverSignerClient struct {
+	signers  []*SignerClient
+	cooldown time.Duration
+	logger   log.Logger
+	quit     chan struct{}
+	signing  []int32 // 1 while the signer hasn't answered the last request
+
+	checkMtx sync.Mutex // held during a round of health checks
+
+	mtx       sync.Mutex
+	pubKey    crypto.PubKey
+	active    int
+	downUntil []time.Time // zero while the signer is available
+}
+
+var _ types.PrivValidator = (*FailoverSignerClient)(nil)
+
+// NewFailoverSignerClient returns a FailoverSignerClient signing with
+// signers, the first available being the active one. It returns an error if
+// none is available.
+func NewFailoverSignerClient(
+	signers []*SignerClient,
+	cooldown time.Duration,
+	logger log.Logger,
+) (*FailoverSignerClient, error) {
+	fc := &FailoverSignerClient{
+		signers:   signers,
+		cooldown:  cooldown,
+		logger:    logger,
+		quit:      make(chan struct{}),
+		signing:   make([]int32, len(signers)),
+		active:    -1,
+		downUntil: make([]time.Time, len(signers)),
+	}
+	now := time.Now()
+	for i, sc := range signers {
+		pubKey := sc.GetPubKey()
+		switch {
+		case pubKey == nil:
+			fc.downUntil[i] = now.Add(cooldown)
+			logger.Error("Remote signer unavailable", "signer", i)
+		case fc.pubKey == nil:
+			fc.pubKey, fc.active = pubKey, i
+		case !pubKey.Equals(fc.pubKey):
+			return nil, fmt.Errorf("remote signer %d has another key (%v), expected %v", i, pubKey, fc.pubKey)
+		}
+	}
+	if fc.pubKey == nil {
+		return nil, errors.New("no remote signer available")
+	}
+	go fc.healthCheckRoutine()
+	return fc, nil
+}
+
+// Close stops the health checks and closes the underlying connections.
+func (fc *FailoverSignerClient) Close() error {
+	close(fc.quit)
+	for _, sc := range fc.signers {
+		if err := sc.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPubKey returns the public key of the signers.
+func (fc *FailoverSignerClient) GetPubKey() crypto.PubKey {
+	return fc.pubKey
+}
+
+// String returns a string representation of the FailoverSignerClient.
+func (fc *FailoverSignerClient) String() string {
+	return fmt.Sprintf("FailoverSignerClient{%v %d signers}", fc.pubKey.Address(), len(fc.signers))
+}
+
+// SignVote signs vote with the active signer, failing over if needed.
+func (fc *FailoverSignerClient) SignVote(chainID string, vote *types.Vote) error {
+	signed, err := fc.sign(func(sc *SignerClient) (func(), error) {
+		v := *vote
+		err := sc.SignVote(chainID, &v)
+		return func() { *vote = v }, err
+	})
+	if err != nil {
+		return err
+	}
+	signed()
+	return nil
+}
+
+// SignProposal signs proposal with the active signer, failing over if needed.
+func (fc *FailoverSignerClient) SignProposal(chainID string, proposal *types.Proposal) error {
+	signed, err := fc.sign(func(sc *SignerClient) (func(), error) {
+		p := *proposal
+		err := sc.SignProposal(chainID, &p)
+		return func() { *proposal = p }, err
+	})
+	if err != nil {
+		return err
+	}
+	signed()
+	return nil
+}
+
+// sign signs with the active signer, or with the next available one if it
+// fails, until one of them signs or refuses to. signFn signs a copy, and
+// returns a function updating the original: a signer timing out may still
+// sign it later.
+func (fc *FailoverSignerClient) sign(signFn func(*SignerClient) (func(), error)) (func(), error) {
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+
+	for n := 0; n < len(fc.signers); n++ {
+		i := (fc.active + n) % len(fc.signers)
+		if !fc.downUntil[i].IsZero() {
+			continue
+		}
+		signed, err := fc.signWith(i, signFn)
+		if _, ok := err.(*RemoteSignerError); err == nil || ok {
+			if i != fc.active {
+				fc.logger.Info("Failed over to another remote signer", "signer", i)
+				fc.active = i
+			}
+			return signed, err
+		}
+		fc.logger.Error("Remote signer failed", "signer", i, "err", err)
+		fc.downUntil[i] = time.Now().Add(fc.cooldown)
+	}
+	return nil, errors.New("no remote signer available")
+}
+
+// signWith signs with signer i, waiting failoverSignTimeout at most.
+func (fc *FailoverSignerClient) signWith(i int, signFn func(*SignerClient) (func(), error)) (func(), error) {
+	if !atomic.CompareAndSwapInt32(&fc.signing[i], 0, 1) {
+		return nil, errors.New("previous request pending")
+	}
+	type result struct {
+		signed func()
+		err    error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		signed, err := signFn(fc.signers[i])
+		atomic.StoreInt32(&fc.signing[i], 0)
+		resultCh <- result{signed, err}
+	}()
+	select {
+	case r := <-resultCh:
+		return r.signed, r.err
+	case <-time.After(failoverSignTimeout):
+		return nil, fmt.Errorf("no signature in %v", failoverSignTimeout)
+	}
+}
+
+// healthCheckRoutine checks the signers periodically: the available ones
+// still are, and the ones which failed are back, once their cooldown is over.
+func (fc *FailoverSignerClient) healthCheckRoutine() {
+	ticker := time.NewTicker(failoverHealthCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			fc.checkHealth()
+		case <-fc.quit:
+			return
+		}
+	}
+}
+
+// checkHealth runs a round of health checks: the signers whose cooldown is
+// over are checked concurrently, and it returns once they all answered or
+// timed out. The rounds don't overlap.
+func (fc *FailoverSignerClient) checkHealth() {
+	fc.checkMtx.Lock()
+	defer fc.checkMtx.Unlock()
+
+	fc.mtx.Lock()
+	now := time.Now()
+	var wg sync.WaitGroup
+	for i := range fc.signers {
+		if now.Before(fc.downUntil[i]) {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fc.healthCheck(i)
+		}(i)
+	}
+	fc.mtx.Unlock()
+	wg.Wait()
+}
+
+// healthCheck checks signer i is reachable and has the right key.
+func (fc *FailoverSignerClient) healthCheck(i int) {
+	// GetPubKey blocks until the signer is connected, or times out
+	pubKey := fc.signers[i].GetPubKey()
+
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+	switch {
+	case pubKey == nil:
+		if fc.downUntil[i].IsZero() {
+			fc.logger.Error("Remote signer unavailable", "signer", i)
+		}
+		fc.downUntil[i] = time.Now().Add(fc.cooldown)
+	case !pubKey.Equals(fc.pubKey):
+		fc.logger.Error("Remote signer has another key", "signer", i, "pubKey", pubKey, "expected", fc.pubKey)
+		fc.downUntil[i] = time.Now().Add(fc.cooldown)
+	case !fc.downUntil[i].IsZero():
+		fc.logger.Info("Remote signer available again", "signer", i)
+		fc.downUntil[i] = time.Time{}
+	}
+}
@@ -0,0 +1,142 @@
+package privval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// newFailoverSigner returns a failover client signing with privVals, and the
+// servers of privVals.
+func newFailoverSigner(
+	t *testing.T,
+	chainID string,
+	cooldown time.Duration,
+	privVals ...types.PrivValidator,
+) (*FailoverSignerClient, []*SignerServer) {
+	var (
+		signers []*SignerClient
+		servers []*SignerServer
+	)
+	for _, privVal := range privVals {
+		unixFilePath, err := testUnixAddr()
+		require.NoError(t, err)
+		sl, sd := getMockEndpoints(t, "unix://"+unixFilePath, DialUnixFn(unixFilePath))
+		sl.timeoutAccept = testTimeoutReadWrite // not to wait for stopped signers
+		sc, err := NewSignerClient(sl)
+		require.NoError(t, err)
+		ss := NewSignerServer(sd, chainID, privVal)
+		require.NoError(t, ss.Start())
+		signers = append(signers, sc)
+		servers = append(servers, ss)
+	}
+
+	fc, err := NewFailoverSignerClient(signers, cooldown, log.TestingLogger())
+	require.NoError(t, err)
+	return fc, servers
+}
+
+// activeSigner returns the index of the active signer of fc.
+func activeSigner(fc *FailoverSignerClient) int {
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+	return fc.active
+}
+
+// endCooldown ends the cooldown of signer i of fc, as if it had failed long
+// enough ago.
+func endCooldown(fc *FailoverSignerClient, i int) {
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+	fc.downUntil[i] = time.Now()
+}
+
+// isDown returns true if signer i of fc isn't available.
+func isDown(fc *FailoverSignerClient, i int) bool {
+	fc.mtx.Lock()
+	defer fc.mtx.Unlock()
+	return !fc.downUntil[i].IsZero()
+}
+
+func TestFailoverSignerClient(t *testing.T) {
+	chainID := "test-chain"
+	privKey := ed25519.GenPrivKey()
+	fc, servers := newFailoverSigner(t, chainID, time.Hour,
+		types.NewMockPVWithParams(privKey, false, false),
+		types.NewMockPVWithParams(privKey, false, false))
+	defer fc.Close()
+	assert.Equal(t, privKey.PubKey(), fc.GetPubKey())
+
+	blockID := types.BlockID{Hash: tmhash.Sum([]byte("block")),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))}}
+	vote := newVote(privKey.PubKey().Address(), 0, 1, 0, byte(types.PrevoteType), blockID)
+	require.NoError(t, fc.SignVote(chainID, vote))
+	assert.True(t, privKey.PubKey().VerifyBytes(vote.SignBytes(chainID), vote.Signature))
+	assert.Equal(t, 0, activeSigner(fc))
+
+	// an available signer passes the health check once its cooldown is over
+	endCooldown(fc, 1)
+	fc.checkHealth()
+	require.False(t, isDown(fc, 1))
+
+	// the second signer takes over
+	require.NoError(t, servers[0].Stop())
+	proposal := newProposal(1, 1, blockID)
+	require.NoError(t, fc.SignProposal(chainID, proposal))
+	assert.True(t, privKey.PubKey().VerifyBytes(proposal.SignBytes(chainID), proposal.Signature))
+	assert.Equal(t, 1, activeSigner(fc))
+
+	// the first one is in cooldown
+	require.NoError(t, servers[1].Stop())
+	vote = newVote(privKey.PubKey().Address(), 0, 2, 0, byte(types.PrevoteType), blockID)
+	assert.Error(t, fc.SignVote(chainID, vote))
+}
+
+func TestFailoverSignerClientRefusal(t *testing.T) {
+	chainID := "test-chain"
+	privKey := ed25519.GenPrivKey()
+	fc, servers := newFailoverSigner(t, chainID, time.Hour,
+		&types.ErroringMockPV{MockPV: types.NewMockPVWithParams(privKey, false, false)},
+		types.NewMockPVWithParams(privKey, false, false))
+	defer fc.Close()
+	defer func() {
+		for _, ss := range servers {
+			ss.Stop()
+		}
+	}()
+
+	// refusing to sign isn't failing
+	blockID := types.BlockID{Hash: tmhash.Sum([]byte("block")),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))}}
+	vote := newVote(privKey.PubKey().Address(), 0, 1, 0, byte(types.PrevoteType), blockID)
+	assert.Error(t, fc.SignVote(chainID, vote))
+	assert.Equal(t, 0, activeSigner(fc))
+	assert.False(t, isDown(fc, 0))
+}
+
+func TestFailoverSignerClientOtherKey(t *testing.T) {
+	chainID := "test-chain"
+	var signers []*SignerClient
+	for _, privVal := range []types.PrivValidator{types.NewMockPV(), types.NewMockPV()} {
+		unixFilePath, err := testUnixAddr()
+		require.NoError(t, err)
+		sl, sd := getMockEndpoints(t, "unix://"+unixFilePath, DialUnixFn(unixFilePath))
+		sc, err := NewSignerClient(sl)
+		require.NoError(t, err)
+		ss := NewSignerServer(sd, chainID, privVal)
+		require.NoError(t, ss.Start())
+		defer ss.Stop()
+		defer sc.Close()
+		signers = append(signers, sc)
+	}
+
+	_, err := NewFailoverSignerClient(signers, time.Second, log.TestingLogger())
+	assert.Error(t, err)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	tmos "github.com/tendermint/tendermint/libs/os"
 	"github.com/tendermint/tendermint/libs/tempfile"
@@ -39,10 +40,13 @@ func voteToStep(vote *types.Vote) int8 {
 //-------------------------------------------------------------------------------
 
 // FilePVKey stores the immutable part of PrivValidator.
+// If EncryptedPrivKey is set, the private key is only saved encrypted, and
+// PrivKey is nil until Decrypt is called.
 type FilePVKey struct {
-	Address types.Address  `json:"address"`
-	PubKey  crypto.PubKey  `json:"pub_key"`
-	PrivKey crypto.PrivKey `json:"priv_key"`
+	Address          types.Address     `json:"address"`
+	PubKey           crypto.PubKey     `json:"pub_key"`
+	PrivKey          crypto.PrivKey    `json:"priv_key,omitempty"`
+	EncryptedPrivKey *EncryptedPrivKey `json:"encrypted_priv_key,omitempty"`
 
 	filePath string
 }
@@ -53,6 +57,9 @@ func (pvKey FilePVKey) Save() {
 	if outFile == "" {
 		panic("cannot save PrivValidator key: filePath not set")
 	}
+	if pvKey.EncryptedPrivKey != nil {
+		pvKey.PrivKey = nil
+	}
 
 	jsonBytes, err := cdc.MarshalJSONIndent(pvKey, "", "  ")
 	if err != nil {
@@ -150,8 +157,12 @@ type FilePV struct {
 // GenFilePV generates a new validator with randomly generated private key
 // and sets the filePaths, but does not call Save().
 func GenFilePV(keyFilePath, stateFilePath string) *FilePV {
-	privKey := ed25519.GenPrivKey()
+	return NewFilePV(ed25519.GenPrivKey(), keyFilePath, stateFilePath)
+}
 
+// NewFilePV returns a new validator with the given private key, of any type,
+// and sets the filePaths, but does not call Save().
+func NewFilePV(privKey crypto.PrivKey, keyFilePath, stateFilePath string) *FilePV {
 	return &FilePV{
 		Key: FilePVKey{
 			Address:  privKey.PubKey().Address(),
@@ -169,18 +180,27 @@ func GenFilePV(keyFilePath, stateFilePath string) *FilePV {
 // LoadFilePV loads a FilePV from the filePaths.  The FilePV handles double
 // signing prevention by persisting data to the stateFilePath.  If either file path
 // does not exist, the program will exit.
+// An encrypted private key is left encrypted: the FilePV can't sign.
 func LoadFilePV(keyFilePath, stateFilePath string) *FilePV {
-	return loadFilePV(keyFilePath, stateFilePath, true)
+	return loadFilePV(keyFilePath, stateFilePath, true, nil)
+}
+
+// LoadFilePVWithSecret loads a FilePV like LoadFilePV, decrypting the
+// private key, if it's encrypted, with the passphrase or secret returned by
+// secret. If it fails, the program will exit.
+func LoadFilePVWithSecret(keyFilePath, stateFilePath string, secret KeySecretFunc) *FilePV {
+	return loadFilePV(keyFilePath, stateFilePath, true, secret)
 }
 
 // LoadFilePVEmptyState loads a FilePV from the given keyFilePath, with an empty LastSignState.
 // If the keyFilePath does not exist, the program will exit.
 func LoadFilePVEmptyState(keyFilePath, stateFilePath string) *FilePV {
-	return loadFilePV(keyFilePath, stateFilePath, false)
+	return loadFilePV(keyFilePath, stateFilePath, false, nil)
 }
 
 // If loadState is true, we load from the stateFilePath. Otherwise, we use an empty LastSignState.
-func loadFilePV(keyFilePath, stateFilePath string, loadState bool) *FilePV {
+// If secret isn't nil, an encrypted private key is decrypted with it.
+func loadFilePV(keyFilePath, stateFilePath string, loadState bool, secret KeySecretFunc) *FilePV {
 	keyJSONBytes, err := ioutil.ReadFile(keyFilePath)
 	if err != nil {
 		tmos.Exit(err.Error())
@@ -191,8 +211,26 @@ func loadFilePV(keyFilePath, stateFilePath string, loadState bool) *FilePV {
 		tmos.Exit(fmt.Sprintf("Error reading PrivValidator key from %v: %v\n", keyFilePath, err))
 	}
 
+	if pvKey.EncryptedPrivKey != nil {
+		pvKey.PrivKey = nil
+		if pvKey.PubKey == nil {
+			tmos.Exit(fmt.Sprintf("Error reading PrivValidator key from %v: no public key\n", keyFilePath))
+		}
+		if secret != nil {
+			bz, err := secret(pvKey.EncryptedPrivKey.Encryption)
+			if err == nil {
+				err = pvKey.Decrypt(bz)
+			}
+			if err != nil {
+				tmos.Exit(fmt.Sprintf("Error decrypting PrivValidator key from %v: %v\n", keyFilePath, err))
+			}
+		}
+	}
+
 	// overwrite pubkey and address for convenience
-	pvKey.PubKey = pvKey.PrivKey.PubKey()
+	if pvKey.PrivKey != nil {
+		pvKey.PubKey = pvKey.PrivKey.PubKey()
+	}
 	pvKey.Address = pvKey.PubKey.Address()
 	pvKey.filePath = keyFilePath
 
@@ -219,9 +257,15 @@ func loadFilePV(keyFilePath, stateFilePath string, loadState bool) *FilePV {
 // LoadOrGenFilePV loads a FilePV from the given filePaths
 // or else generates a new one and saves it to the filePaths.
 func LoadOrGenFilePV(keyFilePath, stateFilePath string) *FilePV {
+	return LoadOrGenFilePVWithSecret(keyFilePath, stateFilePath, nil)
+}
+
+// LoadOrGenFilePVWithSecret loads a FilePV like LoadFilePVWithSecret, or
+// else generates a new one, not encrypted, and saves it to the filePaths.
+func LoadOrGenFilePVWithSecret(keyFilePath, stateFilePath string, secret KeySecretFunc) *FilePV {
 	var pv *FilePV
 	if tmos.FileExists(keyFilePath) {
-		pv = LoadFilePV(keyFilePath, stateFilePath)
+		pv = loadFilePV(keyFilePath, stateFilePath, true, secret)
 	} else {
 		pv = GenFilePV(keyFilePath, stateFilePath)
 		pv.Save()
@@ -259,6 +303,16 @@ func (pv *FilePV) SignProposal(chainID string, proposal *types.Proposal) error {
 	return nil
 }
 
+// ProveVRF returns the VRF proof of alpha made with the private key, which must
+// be an Ed25519 key. Implements types.VRFProver.
+func (pv *FilePV) ProveVRF(alpha []byte) ([]byte, error) {
+	privKey, ok := pv.Key.PrivKey.(ed25519.PrivKeyEd25519)
+	if !ok {
+		return nil, fmt.Errorf("can't prove VRF outputs with %T keys", pv.Key.PrivKey)
+	}
+	return vrf.Prove(privKey, alpha), nil
+}
+
 // Save persists the FilePV to disk.
 func (pv *FilePV) Save() {
 	pv.Key.Save()
@@ -323,6 +377,9 @@ func (pv *FilePV) signVote(chainID string, vote *types.Vote) error {
 	}
 
 	// It passed the checks. Sign the vote
+	if pv.Key.PrivKey == nil {
+		return ErrEncryptedPrivKey
+	}
 	sig, err := pv.Key.PrivKey.Sign(signBytes)
 	if err != nil {
 		return err
@@ -365,6 +422,9 @@ func (pv *FilePV) signProposal(chainID string, proposal *types.Proposal) error {
 	}
 
 	// It passed the checks. Sign the proposal
+	if pv.Key.PrivKey == nil {
+		return ErrEncryptedPrivKey
+	}
 	sig, err := pv.Key.PrivKey.Sign(signBytes)
 	if err != nil {
 		return err
@@ -0,0 +1,166 @@
+package privval
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/xsalsa20symmetric"
+)
+
+// Encryptions of the private key of a FilePVKey.
+const (
+	// KeyEncryptionPassphrase encrypts the key with a secret derived from a
+	// passphrase with scrypt.
+	KeyEncryptionPassphrase = "passphrase"
+	// KeyEncryptionKMS encrypts the key with a 32 bytes secret fetched from a
+	// KMS, e.g. a data key decrypted by the KMS.
+	KeyEncryptionKMS = "kms"
+)
+
+// scrypt parameters deriving the secret of a passphrase.
+const (
+	scryptN       = 1 << 15
+	scryptR       = 8
+	scryptP       = 1
+	scryptSaltLen = 16
+
+	secretLen = 32
+)
+
+// ErrEncryptedPrivKey is returned on signing with a FilePV loaded without
+// decrypting its private key.
+var ErrEncryptedPrivKey = errors.New("the private key is encrypted")
+
+// EncryptedPrivKey is the private key of a FilePVKey encrypted at rest.
+type EncryptedPrivKey struct {
+	Encryption string `json:"encryption"`
+	Salt       []byte `json:"salt,omitempty"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// KeySecretFunc returns the passphrase (KeyEncryptionPassphrase) or the
+// secret (KeyEncryptionKMS) a FilePVKey is encrypted with.
+type KeySecretFunc func(encryption string) ([]byte, error)
+
+// Encrypt encrypts the private key with the passphrase or the secret, with
+// encryption KeyEncryptionPassphrase or KeyEncryptionKMS. The private key
+// isn't saved to the key file anymore.
+func (pvKey *FilePVKey) Encrypt(encryption string, secret []byte) error {
+	if pvKey.PrivKey == nil {
+		return ErrEncryptedPrivKey
+	}
+	enc := &EncryptedPrivKey{Encryption: encryption}
+	if encryption == KeyEncryptionPassphrase {
+		enc.Salt = crypto.CRandBytes(scryptSaltLen)
+	}
+	key, err := enc.deriveKey(secret)
+	if err != nil {
+		return err
+	}
+	enc.Ciphertext = xsalsa20symmetric.EncryptSymmetric(cdc.MustMarshalBinaryBare(pvKey.PrivKey), key)
+	pvKey.EncryptedPrivKey = enc
+	return nil
+}
+
+// Decrypt decrypts the private key with the passphrase or the secret it's
+// encrypted with. The key stays encrypted in the key file.
+func (pvKey *FilePVKey) Decrypt(secret []byte) error {
+	if pvKey.EncryptedPrivKey == nil {
+		return errors.New("the private key isn't encrypted")
+	}
+	key, err := pvKey.EncryptedPrivKey.deriveKey(secret)
+	if err != nil {
+		return err
+	}
+	bz, err := xsalsa20symmetric.DecryptSymmetric(pvKey.EncryptedPrivKey.Ciphertext, key)
+	if err != nil {
+		return errors.Errorf("failed to decrypt the private key: wrong %s", pvKey.EncryptedPrivKey.Encryption)
+	}
+	var privKey crypto.PrivKey
+	if err := cdc.UnmarshalBinaryBare(bz, &privKey); err != nil {
+		return errors.Wrap(err, "failed to decode the private key")
+	}
+	if !privKey.PubKey().Equals(pvKey.PubKey) {
+		return errors.New("the private key doesn't match the public key")
+	}
+	pvKey.PrivKey = privKey
+	return nil
+}
+
+// deriveKey returns the key of the symmetric encryption.
+func (enc *EncryptedPrivKey) deriveKey(secret []byte) ([]byte, error) {
+	switch enc.Encryption {
+	case KeyEncryptionPassphrase:
+		if len(secret) == 0 {
+			return nil, errors.New("empty passphrase")
+		}
+		return scrypt.Key(secret, enc.Salt, scryptN, scryptR, scryptP, secretLen)
+	case KeyEncryptionKMS:
+		if len(secret) != secretLen {
+			return nil, errors.Errorf("expected a %d bytes secret, got %d bytes", secretLen, len(secret))
+		}
+		return secret, nil
+	default:
+		return nil, errors.Errorf("unknown key encryption %q", enc.Encryption)
+	}
+}
+
+// NewKeySecretFunc returns a KeySecretFunc reading the passphrase from
+// passphraseFile or, if it's empty, prompting for it on the terminal, and
+// fetching the secret from the standard output of kmsCommand, run with sh -c,
+// where it's base64 encoded (e.g. the plaintext of a data key decrypted by
+// the KMS).
+func NewKeySecretFunc(passphraseFile, kmsCommand string) KeySecretFunc {
+	return func(encryption string) ([]byte, error) {
+		switch encryption {
+		case KeyEncryptionPassphrase:
+			if passphraseFile != "" {
+				bz, err := ioutil.ReadFile(passphraseFile)
+				if err != nil {
+					return nil, err
+				}
+				return bytes.TrimRight(bz, "\r\n"), nil
+			}
+			return PromptPassphrase("Passphrase of the validator key: ")
+		case KeyEncryptionKMS:
+			if kmsCommand == "" {
+				return nil, errors.New("no command fetching the secret from the KMS")
+			}
+			cmd := exec.Command("sh", "-c", kmsCommand)
+			cmd.Stderr = os.Stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch the secret with %q", kmsCommand)
+			}
+			secret, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(out)))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to decode the secret fetched from the KMS")
+			}
+			return secret, nil
+		default:
+			return nil, errors.Errorf("unknown key encryption %q", encryption)
+		}
+	}
+}
+
+// PromptPassphrase prints prompt to the standard error, and reads a
+// passphrase on the terminal without echoing it.
+func PromptPassphrase(prompt string) ([]byte, error) {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return nil, errors.New("no terminal to prompt for the passphrase on")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return passphrase, err
+}
@@ -0,0 +1,82 @@
+package privval
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEncryptedFilePV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "priv_validator_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile, stateFile := filepath.Join(dir, "key.json"), filepath.Join(dir, "state.json")
+	passphraseFile := filepath.Join(dir, "passphrase")
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("correct horse\n"), 0600))
+
+	privVal := GenFilePV(keyFile, stateFile)
+	require.NoError(t, privVal.Key.Encrypt(KeyEncryptionPassphrase, []byte("correct horse")))
+	privVal.Save()
+
+	// the private key isn't saved in the clear
+	bz, err := ioutil.ReadFile(keyFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bz), `"priv_key"`)
+	assert.False(t, bytes.Contains(bz, []byte(base64.StdEncoding.EncodeToString(privVal.Key.PrivKey.Bytes()))))
+
+	// loaded without the passphrase, the key can't sign
+	loaded := LoadFilePV(keyFile, stateFile)
+	assert.Equal(t, privVal.GetPubKey(), loaded.GetPubKey())
+	assert.Equal(t, privVal.GetAddress(), loaded.GetAddress())
+	vote := newVote(loaded.GetAddress(), 0, 1, 0, byte(types.PrevoteType), types.BlockID{})
+	err = loaded.SignVote("mychainid", vote)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ErrEncryptedPrivKey.Error())
+
+	// resetting it keeps it encrypted
+	loaded.Reset()
+	bz, err = ioutil.ReadFile(keyFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bz), `"priv_key"`)
+
+	loaded = LoadFilePVWithSecret(keyFile, stateFile, NewKeySecretFunc(passphraseFile, ""))
+	assert.Equal(t, privVal.Key.PrivKey, loaded.Key.PrivKey)
+	require.NoError(t, loaded.SignVote("mychainid", vote))
+	assert.True(t, loaded.GetPubKey().VerifyBytes(vote.SignBytes("mychainid"), vote.Signature))
+
+	// a wrong passphrase fails to decrypt it
+	pvKey := loaded.Key
+	pvKey.PrivKey = nil
+	assert.Error(t, pvKey.Decrypt([]byte("wrong horse")))
+}
+
+func TestEncryptedFilePVKMS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "priv_validator_")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile, stateFile := filepath.Join(dir, "key.json"), filepath.Join(dir, "state.json")
+
+	secret := crypto.CRandBytes(32)
+	privVal := GenFilePV(keyFile, stateFile)
+	assert.Error(t, privVal.Key.Encrypt(KeyEncryptionKMS, secret[:16]))
+	require.NoError(t, privVal.Key.Encrypt(KeyEncryptionKMS, secret))
+	privVal.Save()
+
+	getSecret := NewKeySecretFunc("", "echo "+base64.StdEncoding.EncodeToString(secret))
+	loaded := LoadFilePVWithSecret(keyFile, stateFile, getSecret)
+	assert.Equal(t, privVal.Key.PrivKey, loaded.Key.PrivKey)
+
+	_, err = NewKeySecretFunc("", "exit 1")(KeyEncryptionKMS)
+	assert.Error(t, err)
+	_, err = NewKeySecretFunc("", "")(KeyEncryptionKMS)
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Package privvalgrpc implements the remote signer protocol over gRPC: a
+// PrivValidator signing with a remote signer implementing PrivValidatorAPI,
+// and a server exposing a local PrivValidator as such.
+//
+// Unlike the socket protocol of privval, where the signer dials the node, the
+// node connects to the gRPC signer, optionally over (mutual) TLS.
+package privvalgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+
+	"github.com/tendermint/tendermint/crypto"
+	tmnet "github.com/tendermint/tendermint/libs/net"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// requestTimeout is the time to wait for the signer to answer a request,
+	// connecting to it if needed.
+	requestTimeout = 3 * time.Second
+
+	// the connection is checked with a ping after keepaliveTime without
+	// activity, and closed if the ping isn't answered within keepaliveTimeout
+	keepaliveTime    = 10 * time.Second
+	keepaliveTimeout = 3 * time.Second
+)
+
+// SignerClient implements PrivValidator with a remote signer implementing
+// PrivValidatorAPI, reached over gRPC.
+type SignerClient struct {
+	conn    *grpc.ClientConn
+	client  PrivValidatorAPIClient
+	chainID string
+	pubKey  crypto.PubKey
+}
+
+var _ types.PrivValidator = (*SignerClient)(nil)
+
+// NewSignerClient connects to the signer at addr (tcp:// or unix://), over
+// TLS if tlsConfig isn't nil, and gets its public key for chainID.
+func NewSignerClient(addr, chainID string, tlsConfig *tls.Config) (*SignerClient, error) {
+	creds := grpc.WithInsecure()
+	if tlsConfig != nil {
+		if tlsConfig.ServerName == "" {
+			// the target isn't a host:port gRPC could verify the certificate of
+			tlsConfig = tlsConfig.Clone()
+			_, address := tmnet.ProtocolAndAddress(addr)
+			if host, _, err := net.SplitHostPort(address); err == nil {
+				tlsConfig.ServerName = host
+			}
+		}
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+	conn, err := grpc.Dial(addr,
+		creds,
+		grpc.WithContextDialer(dialerFunc),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &SignerClient{
+		conn:    conn,
+		client:  NewPrivValidatorAPIClient(conn),
+		chainID: chainID,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := sc.client.GetPubKey(ctx, &RequestPubKey{ChainId: chainID}, grpc.WaitForReady(true))
+	if err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to get the public key of the signer")
+	}
+	sc.pubKey, err = types.PB2TM.PubKey(res.PubKey)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return sc, nil
+}
+
+func dialerFunc(ctx context.Context, addr string) (net.Conn, error) {
+	return tmnet.Connect(addr)
+}
+
+// Close closes the connection to the signer.
+func (sc *SignerClient) Close() error {
+	return sc.conn.Close()
+}
+
+// GetPubKey returns the public key of the signer, got on connecting.
+func (sc *SignerClient) GetPubKey() crypto.PubKey {
+	return sc.pubKey
+}
+
+// SignVote requests the signer to sign vote.
+func (sc *SignerClient) SignVote(chainID string, vote *types.Vote) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := sc.client.SignVote(ctx, &RequestSignVote{ChainId: chainID, Vote: voteToProto(vote)})
+	if err != nil {
+		return signError(err)
+	}
+	signed, err := voteFromProto(res.Vote)
+	if err != nil {
+		return err
+	}
+	*vote = *signed
+	return nil
+}
+
+// SignProposal requests the signer to sign proposal.
+func (sc *SignerClient) SignProposal(chainID string, proposal *types.Proposal) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	res, err := sc.client.SignProposal(ctx,
+		&RequestSignProposal{ChainId: chainID, Proposal: proposalToProto(proposal)})
+	if err != nil {
+		return signError(err)
+	}
+	signed, err := proposalFromProto(res.Proposal)
+	if err != nil {
+		return err
+	}
+	*proposal = *signed
+	return nil
+}
+
+// String returns a string representation of the SignerClient.
+func (sc *SignerClient) String() string {
+	return "privvalgrpc.SignerClient{" + sc.conn.Target() + "}"
+}
+
+// signError returns the error of the signer refusing to sign as is, and wraps
+// the others.
+func signError(err error) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.FailedPrecondition {
+		return errors.New(s.Message())
+	}
+	return errors.Wrap(err, "failed to reach the signer")
+}
@@ -0,0 +1,139 @@
+package privvalgrpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSignerClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "privval_grpc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	chainID := "test-chain"
+	pv := privval.GenFilePV(filepath.Join(dir, "key.json"), filepath.Join(dir, "state.json"))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := NewGRPCServer(pv, chainID, nil)
+	go grpcServer.Serve(ln) // nolint:errcheck
+	defer grpcServer.Stop()
+
+	_, err = NewSignerClient("tcp://"+ln.Addr().String(), "other-chain", nil)
+	assert.Error(t, err)
+
+	sc, err := NewSignerClient("tcp://"+ln.Addr().String(), chainID, nil)
+	require.NoError(t, err)
+	defer sc.Close()
+	assert.Equal(t, pv.GetPubKey(), sc.GetPubKey())
+
+	blockID := types.BlockID{Hash: tmhash.Sum([]byte("block")),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))}}
+	vote := &types.Vote{Type: types.PrecommitType, Height: 1, BlockID: blockID,
+		Timestamp: time.Now().UTC(), ValidatorAddress: pv.GetPubKey().Address(), Extension: []byte("ext")}
+	require.NoError(t, sc.SignVote(chainID, vote))
+	assert.True(t, pv.GetPubKey().VerifyBytes(vote.SignBytes(chainID), vote.Signature))
+
+	proposal := types.NewProposal(2, 0, -1, blockID)
+	require.NoError(t, sc.SignProposal(chainID, proposal))
+	assert.True(t, pv.GetPubKey().VerifyBytes(proposal.SignBytes(chainID), proposal.Signature))
+
+	// the signer refuses to sign a conflicting proposal
+	proposal = types.NewProposal(2, 0, -1, types.BlockID{})
+	err = sc.SignProposal(chainID, proposal)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicting data")
+}
+
+func TestSignerClientTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "privval_grpc")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	chainID := "test-chain"
+	pv := types.NewMockPV()
+
+	caFile, _, caCert, caKey := writeCert(t, dir, "ca", nil, nil)
+	serverCert, serverKey, _, _ := writeCert(t, dir, "server", caCert, caKey)
+	clientCert, clientKey, _, _ := writeCert(t, dir, "client", caCert, caKey)
+
+	serverTLS, err := ServerTLSConfig(serverCert, serverKey, caFile)
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := NewGRPCServer(pv, chainID, serverTLS)
+	go grpcServer.Serve(ln) // nolint:errcheck
+	defer grpcServer.Stop()
+	addr := "tcp://" + ln.Addr().String()
+
+	// a client certificate is required
+	clientTLS, err := ClientTLSConfig(caFile, "", "")
+	require.NoError(t, err)
+	_, err = NewSignerClient(addr, chainID, clientTLS)
+	assert.Error(t, err)
+
+	clientTLS, err = ClientTLSConfig(caFile, clientCert, clientKey)
+	require.NoError(t, err)
+	sc, err := NewSignerClient(addr, chainID, clientTLS)
+	require.NoError(t, err)
+	defer sc.Close()
+	assert.Equal(t, pv.GetPubKey(), sc.GetPubKey())
+
+	vote := &types.Vote{Type: types.PrevoteType, Height: 1, Timestamp: time.Now().UTC()}
+	require.NoError(t, sc.SignVote(chainID, vote))
+	assert.True(t, pv.GetPubKey().VerifyBytes(vote.SignBytes(chainID), vote.Signature))
+}
+
+// writeCert writes the certificate and the key name.crt and name.key in dir,
+// signed by parent, or self-signed as a CA if parent is nil. It returns the
+// paths of the files, and the certificate and key as parent of others.
+func writeCert(
+	t *testing.T,
+	dir, name string,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (string, string, *x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile, cert, key
+}
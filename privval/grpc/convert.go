@@ -0,0 +1,85 @@
+package privvalgrpc
+
+import (
+	"errors"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func blockIDToProto(blockID types.BlockID) BlockID {
+	return BlockID{
+		Hash: blockID.Hash,
+		PartsHeader: PartSetHeader{
+			Total: int64(blockID.PartsHeader.Total),
+			Hash:  blockID.PartsHeader.Hash,
+		},
+	}
+}
+
+func blockIDFromProto(blockID BlockID) types.BlockID {
+	return types.BlockID{
+		Hash: blockID.Hash,
+		PartsHeader: types.PartSetHeader{
+			Total: int(blockID.PartsHeader.Total),
+			Hash:  blockID.PartsHeader.Hash,
+		},
+	}
+}
+
+func voteToProto(vote *types.Vote) *Vote {
+	return &Vote{
+		Type:             int32(vote.Type),
+		Height:           vote.Height,
+		Round:            int64(vote.Round),
+		BlockID:          blockIDToProto(vote.BlockID),
+		Timestamp:        vote.Timestamp,
+		ValidatorAddress: vote.ValidatorAddress,
+		ValidatorIndex:   int64(vote.ValidatorIndex),
+		Signature:        vote.Signature,
+		Extension:        vote.Extension,
+	}
+}
+
+func voteFromProto(vote *Vote) (*types.Vote, error) {
+	if vote == nil {
+		return nil, errors.New("no vote")
+	}
+	return &types.Vote{
+		Type:             types.SignedMsgType(vote.Type),
+		Height:           vote.Height,
+		Round:            int(vote.Round),
+		BlockID:          blockIDFromProto(vote.BlockID),
+		Timestamp:        vote.Timestamp,
+		ValidatorAddress: vote.ValidatorAddress,
+		ValidatorIndex:   int(vote.ValidatorIndex),
+		Signature:        vote.Signature,
+		Extension:        vote.Extension,
+	}, nil
+}
+
+func proposalToProto(proposal *types.Proposal) *Proposal {
+	return &Proposal{
+		Type:      int32(proposal.Type),
+		Height:    proposal.Height,
+		Round:     int64(proposal.Round),
+		PolRound:  int64(proposal.POLRound),
+		BlockID:   blockIDToProto(proposal.BlockID),
+		Timestamp: proposal.Timestamp,
+		Signature: proposal.Signature,
+	}
+}
+
+func proposalFromProto(proposal *Proposal) (*types.Proposal, error) {
+	if proposal == nil {
+		return nil, errors.New("no proposal")
+	}
+	return &types.Proposal{
+		Type:      types.SignedMsgType(proposal.Type),
+		Height:    proposal.Height,
+		Round:     int(proposal.Round),
+		POLRound:  int(proposal.PolRound),
+		BlockID:   blockIDFromProto(proposal.BlockID),
+		Timestamp: proposal.Timestamp,
+		Signature: proposal.Signature,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package privvalgrpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// server serves a local PrivValidator, e.g. a FilePV, as a remote signer.
+type server struct {
+	mtx     sync.Mutex // the PrivValidator signs one request at a time
+	privVal types.PrivValidator
+	chainID string
+}
+
+var _ PrivValidatorAPIServer = (*server)(nil)
+
+// NewServer returns a PrivValidatorAPIServer serving privVal for chainID, to
+// be registered on a gRPC server with RegisterPrivValidatorAPIServer.
+func NewServer(privVal types.PrivValidator, chainID string) PrivValidatorAPIServer {
+	return &server{privVal: privVal, chainID: chainID}
+}
+
+// NewGRPCServer returns a gRPC server serving privVal for chainID, over TLS
+// if tlsConfig isn't nil, allowing the keepalives of SignerClient.
+func NewGRPCServer(privVal types.PrivValidator, chainID string, tlsConfig *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             keepaliveTime / 2,
+			PermitWithoutStream: true,
+		}),
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	RegisterPrivValidatorAPIServer(grpcServer, NewServer(privVal, chainID))
+	return grpcServer
+}
+
+func (s *server) GetPubKey(ctx context.Context, req *RequestPubKey) (*ResponsePubKey, error) {
+	if err := s.checkChainID(req.ChainId); err != nil {
+		return nil, err
+	}
+	return &ResponsePubKey{PubKey: types.TM2PB.PubKey(s.privVal.GetPubKey())}, nil
+}
+
+func (s *server) SignVote(ctx context.Context, req *RequestSignVote) (*ResponseSignVote, error) {
+	if err := s.checkChainID(req.ChainId); err != nil {
+		return nil, err
+	}
+	vote, err := voteFromProto(req.Vote)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.privVal.SignVote(s.chainID, vote); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &ResponseSignVote{Vote: voteToProto(vote)}, nil
+}
+
+func (s *server) SignProposal(ctx context.Context, req *RequestSignProposal) (*ResponseSignProposal, error) {
+	if err := s.checkChainID(req.ChainId); err != nil {
+		return nil, err
+	}
+	proposal, err := proposalFromProto(req.Proposal)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.privVal.SignProposal(s.chainID, proposal); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &ResponseSignProposal{Proposal: proposalToProto(proposal)}, nil
+}
+
+func (s *server) checkChainID(chainID string) error {
+	if chainID != s.chainID {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("signer of chain %s, not %s", s.chainID, chainID))
+	}
+	return nil
+}
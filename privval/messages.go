@@ -3,6 +3,7 @@ package privval
 import (
 	amino "github.com/tendermint/go-amino"
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/threshold"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -20,6 +21,15 @@ func RegisterRemoteSignerMsg(cdc *amino.Codec) {
 
 	cdc.RegisterConcrete(&PingRequest{}, "tendermint/remotesigner/PingRequest", nil)
 	cdc.RegisterConcrete(&PingResponse{}, "tendermint/remotesigner/PingResponse", nil)
+
+	cdc.RegisterConcrete(&ThresholdHandshakeRequest{}, "tendermint/remotesigner/ThresholdHandshakeRequest", nil)
+	cdc.RegisterConcrete(&ThresholdHandshakeResponse{}, "tendermint/remotesigner/ThresholdHandshakeResponse", nil)
+	cdc.RegisterConcrete(&ThresholdCommitRequest{}, "tendermint/remotesigner/ThresholdCommitRequest", nil)
+	cdc.RegisterConcrete(&ThresholdCommitResponse{}, "tendermint/remotesigner/ThresholdCommitResponse", nil)
+	cdc.RegisterConcrete(&ThresholdShareRequest{}, "tendermint/remotesigner/ThresholdShareRequest", nil)
+	cdc.RegisterConcrete(&ThresholdShareResponse{}, "tendermint/remotesigner/ThresholdShareResponse", nil)
+	cdc.RegisterConcrete(&ThresholdSignedRequest{}, "tendermint/remotesigner/ThresholdSignedRequest", nil)
+	cdc.RegisterConcrete(&ThresholdSignedResponse{}, "tendermint/remotesigner/ThresholdSignedResponse", nil)
 }
 
 // TODO: Add ChainIDRequest
@@ -62,3 +72,63 @@ type PingRequest struct {
 // PingResponse is a response to confirm that the connection is alive.
 type PingResponse struct {
 }
+
+// ThresholdHandshakeRequest is sent by a ThresholdSignerClient to each shard
+// connecting, to check it's a shard of the same key and chain.
+type ThresholdHandshakeRequest struct {
+	ChainID string
+}
+
+// ThresholdHandshakeResponse describes the key share of the shard.
+type ThresholdHandshakeResponse struct {
+	ID        int
+	Threshold int
+	Total     int
+	PubKey    crypto.PubKey
+	Error     *RemoteSignerError
+}
+
+// ThresholdCommitRequest is a request to start signing a vote or a proposal,
+// sent to all the shards.
+type ThresholdCommitRequest struct {
+	Vote     *types.Vote
+	Proposal *types.Proposal
+}
+
+// ThresholdCommitResponse is a response containing the nonce commitment of the
+// shard, or the signature already known for the height/round/step of the
+// request, with its sign bytes.
+type ThresholdCommitResponse struct {
+	Commitment *threshold.Commitment
+	SignBytes  []byte
+	Signature  []byte
+	Error      *RemoteSignerError
+}
+
+// ThresholdShareRequest is a request to sign the share of the signature, sent
+// to the shards whose commitments were chosen.
+type ThresholdShareRequest struct {
+	Commitments []threshold.Commitment
+}
+
+// ThresholdShareResponse is a response containing the share of the signature.
+type ThresholdShareResponse struct {
+	Share []byte
+	Error *RemoteSignerError
+}
+
+// ThresholdSignedRequest shares the signature of a height/round/step with all
+// the shards, so that they refuse to sign conflicting data, even those which
+// didn't sign a share.
+type ThresholdSignedRequest struct {
+	Height    int64
+	Round     int
+	Step      int8
+	SignBytes []byte
+	Signature []byte
+}
+
+// ThresholdSignedResponse acknowledges a ThresholdSignedRequest.
+type ThresholdSignedResponse struct {
+	Error *RemoteSignerError
+}
@@ -0,0 +1,443 @@
+package privval
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/threshold"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// ThresholdSignerClient implements PrivValidator with a threshold signer: the
+// key of the validator is split into shares (see threshold.SplitPrivKey), held
+// by shards connected to an endpoint each (see NewThresholdSignerServer),
+// threshold of which sign each vote and proposal. The validator keeps signing
+// as long as threshold shards are available, and no shard can sign alone.
+//
+// On connecting, each shard is checked to hold a share of the same key, for
+// the same chain. Then the shards sign in two rounds: all of them are asked to
+// commit nonces, and the first threshold to answer within the share timeout
+// sign their share of the signature. The signature is then sent to all the
+// shards, so that each refuses to sign conflicting data later, even if it
+// didn't take part.
+type ThresholdSignerClient struct {
+	endpoints    []*SignerListenerEndpoint
+	chainID      string
+	shareTimeout time.Duration
+	logger       log.Logger
+	// 1 while a request to the endpoint, or the broadcast of a signature, is
+	// pending
+	requesting   []int32
+	broadcasting []int32
+
+	mtx       sync.Mutex
+	pubKey    ed25519.PubKeyEd25519
+	threshold int
+	total     int
+}
+
+var _ types.PrivValidator = (*ThresholdSignerClient)(nil)
+
+// NewThresholdSignerClient returns a ThresholdSignerClient signing with the
+// shards connecting to endpoints, for chainID. It starts the endpoints (if not
+// already started), and waits for the handshake of threshold shards.
+func NewThresholdSignerClient(
+	endpoints []*SignerListenerEndpoint,
+	chainID string,
+	shareTimeout time.Duration,
+	logger log.Logger,
+) (*ThresholdSignerClient, error) {
+	for _, endpoint := range endpoints {
+		if !endpoint.IsRunning() {
+			if err := endpoint.Start(); err != nil {
+				return nil, errors.Wrap(err, "failed to start listener endpoint")
+			}
+		}
+	}
+	tc := &ThresholdSignerClient{
+		endpoints:    endpoints,
+		chainID:      chainID,
+		shareTimeout: shareTimeout,
+		logger:       logger,
+		requesting:   make([]int32, len(endpoints)),
+		broadcasting: make([]int32, len(endpoints)),
+	}
+	if err := tc.handshake(); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// Close closes the underlying connections.
+func (tc *ThresholdSignerClient) Close() error {
+	for _, endpoint := range tc.endpoints {
+		if err := endpoint.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPubKey returns the public key of the split key.
+func (tc *ThresholdSignerClient) GetPubKey() crypto.PubKey {
+	return tc.pubKey
+}
+
+// String returns a string representation of the ThresholdSignerClient.
+func (tc *ThresholdSignerClient) String() string {
+	return fmt.Sprintf("ThresholdSignerClient{%v %d of %d}", tc.pubKey.Address(), tc.threshold, tc.total)
+}
+
+// SignVote signs vote with threshold shards.
+func (tc *ThresholdSignerClient) SignVote(chainID string, vote *types.Vote) error {
+	tc.mtx.Lock()
+	defer tc.mtx.Unlock()
+
+	step := voteToStep(vote)
+	signBytes := vote.SignBytes(chainID)
+	lastSignBytes, sig, err := tc.sign(&ThresholdCommitRequest{Vote: vote}, signBytes,
+		checkVotesOnlyDifferByTimestamp)
+	if err != nil {
+		return fmt.Errorf("error signing vote: %v", err)
+	}
+	if lastSignBytes != nil && !bytes.Equal(lastSignBytes, signBytes) {
+		// signed already, with another timestamp
+		vote.Timestamp, _ = checkVotesOnlyDifferByTimestamp(lastSignBytes, signBytes)
+		signBytes = lastSignBytes
+	}
+	vote.Signature = sig
+	tc.broadcastSigned(&ThresholdSignedRequest{vote.Height, vote.Round, step, signBytes, sig})
+	return nil
+}
+
+// SignProposal signs proposal with threshold shards.
+func (tc *ThresholdSignerClient) SignProposal(chainID string, proposal *types.Proposal) error {
+	tc.mtx.Lock()
+	defer tc.mtx.Unlock()
+
+	signBytes := proposal.SignBytes(chainID)
+	lastSignBytes, sig, err := tc.sign(&ThresholdCommitRequest{Proposal: proposal}, signBytes,
+		checkProposalsOnlyDifferByTimestamp)
+	if err != nil {
+		return fmt.Errorf("error signing proposal: %v", err)
+	}
+	if lastSignBytes != nil && !bytes.Equal(lastSignBytes, signBytes) {
+		proposal.Timestamp, _ = checkProposalsOnlyDifferByTimestamp(lastSignBytes, signBytes)
+		signBytes = lastSignBytes
+	}
+	proposal.Signature = sig
+	tc.broadcastSigned(&ThresholdSignedRequest{proposal.Height, proposal.Round, stepPropose, signBytes, sig})
+	return nil
+}
+
+// handshake checks the shards hold shares of the same key, and learns the
+// key and the threshold. It fails unless threshold shards agree.
+func (tc *ThresholdSignerClient) handshake() error {
+	responses := make([]SignerMessage, len(tc.endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range tc.endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *SignerListenerEndpoint) {
+			defer wg.Done()
+			res, err := endpoint.SendRequest(&ThresholdHandshakeRequest{ChainID: tc.chainID})
+			if err != nil {
+				tc.logger.Error("Threshold signer handshake failed", "shard", i, "err", err)
+				return
+			}
+			responses[i] = res
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	ids := make(map[int]bool)
+	for i, res := range responses {
+		if res == nil {
+			continue
+		}
+		resp, ok := res.(*ThresholdHandshakeResponse)
+		if !ok {
+			tc.logger.Error("Threshold signer handshake failed", "shard", i, "err", ErrUnexpectedResponse)
+			continue
+		}
+		if resp.Error != nil {
+			tc.logger.Error("Threshold signer handshake failed", "shard", i, "err", resp.Error)
+			continue
+		}
+		pubKey, ok := resp.PubKey.(ed25519.PubKeyEd25519)
+		if !ok {
+			return fmt.Errorf("shard %d: expected an ed25519 key, got %T", i, resp.PubKey)
+		}
+		if len(ids) == 0 {
+			tc.pubKey, tc.threshold, tc.total = pubKey, resp.Threshold, resp.Total
+		}
+		if pubKey != tc.pubKey || resp.Threshold != tc.threshold || resp.Total != tc.total {
+			return fmt.Errorf("shard %d holds a share of another key (%v, %d of %d), expected %v, %d of %d",
+				i, pubKey, resp.Threshold, resp.Total, tc.pubKey, tc.threshold, tc.total)
+		}
+		if ids[resp.ID] {
+			return fmt.Errorf("shard %d: share %d held by two shards", i, resp.ID)
+		}
+		ids[resp.ID] = true
+	}
+	if len(ids) == 0 || len(ids) < tc.threshold {
+		return fmt.Errorf("%d shards available, need %d", len(ids), tc.threshold)
+	}
+	if len(tc.endpoints) < tc.total {
+		tc.logger.Info("Fewer endpoints than shards", "endpoints", len(tc.endpoints), "shards", tc.total)
+	}
+	return nil
+}
+
+// thresholdCommit is the commitment of the shard of an endpoint.
+type thresholdCommit struct {
+	endpoint   int
+	commitment threshold.Commitment
+}
+
+// onlyDifferByTimestamp returns the timestamp of lastSignBytes, and true if
+// they differ from newSignBytes by the timestamp only.
+type onlyDifferByTimestamp func(lastSignBytes, newSignBytes []byte) (time.Time, bool)
+
+// sign signs signBytes, trying other shards if some fail to sign their share.
+// If the signature of the height/round/step of req is known by a shard
+// already, its sign bytes are returned with it.
+func (tc *ThresholdSignerClient) sign(
+	req *ThresholdCommitRequest,
+	signBytes []byte,
+	differByTimestamp onlyDifferByTimestamp,
+) ([]byte, []byte, error) {
+	failed := make(map[int]bool)
+	for {
+		if len(tc.endpoints)-len(failed) < tc.threshold {
+			return nil, nil, fmt.Errorf("%d shards available, need %d", len(tc.endpoints)-len(failed), tc.threshold)
+		}
+		commits, lastSignBytes, sig, err := tc.collectCommitments(req, signBytes, differByTimestamp, failed)
+		if err != nil || sig != nil {
+			return lastSignBytes, sig, err
+		}
+		numFailed := len(failed)
+		sig, err = tc.collectShares(commits, signBytes, failed)
+		if err == nil {
+			return nil, sig, nil
+		}
+		if len(failed) == numFailed {
+			return nil, nil, err // e.g. an invalid share, from an unknown shard
+		}
+		tc.logger.Error("Threshold signature failed, retrying with other shards", "err", err)
+	}
+}
+
+// collectCommitments asks the shards to commit nonces, and returns the first
+// threshold commitments received within the share timeout. If a shard knows
+// the signature of the height/round/step already, it's returned instead.
+func (tc *ThresholdSignerClient) collectCommitments(
+	req *ThresholdCommitRequest,
+	signBytes []byte,
+	differByTimestamp onlyDifferByTimestamp,
+	failed map[int]bool,
+) (commits []thresholdCommit, lastSignBytes []byte, sig []byte, err error) {
+	type result struct {
+		endpoint int
+		res      *ThresholdCommitResponse
+		err      error
+	}
+	resultCh := make(chan result, len(tc.endpoints))
+	pending := 0
+	for i := range tc.endpoints {
+		if failed[i] {
+			continue
+		}
+		i := i
+		sent := tc.sendRequest(tc.requesting, i, req, func(res SignerMessage, err error) {
+			if err != nil {
+				resultCh <- result{i, nil, err}
+				return
+			}
+			resp, ok := res.(*ThresholdCommitResponse)
+			switch {
+			case !ok:
+				resultCh <- result{i, nil, ErrUnexpectedResponse}
+			case resp.Error != nil:
+				resultCh <- result{i, nil, resp.Error}
+			case resp.Signature == nil && resp.Commitment == nil:
+				resultCh <- result{i, nil, errors.New("no commitment")}
+			default:
+				resultCh <- result{i, resp, nil}
+			}
+		})
+		if !sent {
+			// still waiting for the shard to answer a previous request, e.g.
+			// waiting for it to connect
+			failed[i] = true
+			continue
+		}
+		pending++
+	}
+
+	ids := make(map[int]bool)
+	timeout := time.After(tc.shareTimeout)
+	for ; pending > 0 && len(commits) < tc.threshold; pending-- {
+		var r result
+		select {
+		case r = <-resultCh:
+		case <-timeout:
+			return nil, nil, nil, fmt.Errorf("%d commitments received in %v, need %d",
+				len(commits), tc.shareTimeout, tc.threshold)
+		}
+		if r.err != nil {
+			tc.logger.Error("Threshold signer shard failed to commit", "shard", r.endpoint, "err", r.err)
+			failed[r.endpoint] = true
+			continue
+		}
+		if r.res.Signature != nil {
+			if tc.reusable(r.res.SignBytes, r.res.Signature, signBytes, differByTimestamp) {
+				return nil, r.res.SignBytes, r.res.Signature, nil
+			}
+			tc.logger.Error("Threshold signer shard sent an invalid signature", "shard", r.endpoint)
+			failed[r.endpoint] = true
+			continue
+		}
+		c := *r.res.Commitment
+		if c.ID < 1 || c.ID > tc.total || ids[c.ID] {
+			tc.logger.Error("Threshold signer shard sent an invalid commitment", "shard", r.endpoint, "id", c.ID)
+			failed[r.endpoint] = true
+			continue
+		}
+		ids[c.ID] = true
+		commits = append(commits, thresholdCommit{r.endpoint, c})
+	}
+	if len(commits) < tc.threshold {
+		return nil, nil, nil, fmt.Errorf("%d commitments received, need %d", len(commits), tc.threshold)
+	}
+	return commits, nil, nil, nil
+}
+
+// collectShares asks the shards of commits to sign their share of the
+// signature of signBytes, within the share timeout, and aggregates them.
+func (tc *ThresholdSignerClient) collectShares(
+	commits []thresholdCommit,
+	signBytes []byte,
+	failed map[int]bool,
+) ([]byte, error) {
+	commitments := make([]threshold.Commitment, len(commits))
+	for i, c := range commits {
+		commitments[i] = c.commitment
+	}
+	req := &ThresholdShareRequest{Commitments: commitments}
+
+	type result struct {
+		i     int
+		share []byte
+		err   error
+	}
+	resultCh := make(chan result, len(commits))
+	for i, c := range commits {
+		i := i
+		sent := tc.sendRequest(tc.requesting, c.endpoint, req, func(res SignerMessage, err error) {
+			if err != nil {
+				resultCh <- result{i, nil, err}
+				return
+			}
+			resp, ok := res.(*ThresholdShareResponse)
+			switch {
+			case !ok:
+				resultCh <- result{i, nil, ErrUnexpectedResponse}
+			case resp.Error != nil:
+				resultCh <- result{i, nil, resp.Error}
+			default:
+				resultCh <- result{i, resp.Share, nil}
+			}
+		})
+		if !sent {
+			resultCh <- result{i, nil, errors.New("previous request pending")}
+		}
+	}
+
+	shares := make([][]byte, len(commits))
+	timeout := time.After(tc.shareTimeout)
+	var err error
+	for range commits {
+		select {
+		case r := <-resultCh:
+			if r.err != nil {
+				failed[commits[r.i].endpoint] = true
+				err = errors.Wrapf(r.err, "shard %d failed to sign its share", commits[r.i].endpoint)
+				continue
+			}
+			shares[r.i] = r.share
+		case <-timeout:
+			for i, share := range shares {
+				if share == nil {
+					failed[commits[i].endpoint] = true
+				}
+			}
+			return nil, fmt.Errorf("shares not received in %v", tc.shareTimeout)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return threshold.Aggregate(tc.pubKey, signBytes, commitments, shares)
+}
+
+// reusable returns true if sig is a valid signature of lastSignBytes, which
+// differ from signBytes at most by the timestamp.
+func (tc *ThresholdSignerClient) reusable(
+	lastSignBytes, sig, signBytes []byte,
+	differByTimestamp onlyDifferByTimestamp,
+) bool {
+	if !tc.pubKey.VerifyBytes(lastSignBytes, sig) {
+		return false
+	}
+	if bytes.Equal(lastSignBytes, signBytes) {
+		return true
+	}
+	_, ok := differByTimestamp(lastSignBytes, signBytes)
+	return ok
+}
+
+// broadcastSigned sends the signature to all the shards, in the background.
+func (tc *ThresholdSignerClient) broadcastSigned(req *ThresholdSignedRequest) {
+	for i := range tc.endpoints {
+		i := i
+		tc.sendRequest(tc.broadcasting, i, req, func(res SignerMessage, err error) {
+			if err == nil {
+				if resp, ok := res.(*ThresholdSignedResponse); ok && resp.Error != nil {
+					err = resp.Error
+				}
+			}
+			if err != nil {
+				tc.logger.Debug("Failed to send the signature to a threshold signer shard", "shard", i, "err", err)
+			}
+		})
+	}
+}
+
+// sendRequest sends req to the shard of endpoint i in the background, and
+// calls handle with its response. It returns false, without sending req, if
+// the previous request flagged in pending is still pending: SendRequest blocks
+// until the shard is connected, requests must not pile up while it's not.
+func (tc *ThresholdSignerClient) sendRequest(
+	pending []int32,
+	i int,
+	req SignerMessage,
+	handle func(SignerMessage, error),
+) bool {
+	if !atomic.CompareAndSwapInt32(&pending[i], 0, 1) {
+		return false
+	}
+	go func() {
+		res, err := tc.endpoints[i].SendRequest(req)
+		atomic.StoreInt32(&pending[i], 0)
+		handle(res, err)
+	}()
+	return true
+}
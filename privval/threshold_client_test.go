@@ -0,0 +1,171 @@
+package privval
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/threshold"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// newThresholdSigner returns a threshold client and the servers of the shards
+// of privKey, threshold of total, and a function stopping them.
+func newThresholdSigner(
+	t *testing.T,
+	privKey ed25519.PrivKeyEd25519,
+	thresh, total int,
+	chainID string,
+) (*ThresholdSignerClient, []*SignerServer, []*ThresholdShard, func()) {
+	dir, err := ioutil.TempDir("", "threshold")
+	require.NoError(t, err)
+
+	keyShares, err := threshold.SplitPrivKey(privKey, thresh, total)
+	require.NoError(t, err)
+	var (
+		endpoints []*SignerListenerEndpoint
+		servers   []*SignerServer
+		shards    []*ThresholdShard
+	)
+	for i, keyShare := range keyShares {
+		keyFile := filepath.Join(dir, fmt.Sprintf("share%d.json", i))
+		require.NoError(t, SaveThresholdKeyShare(keyShare, keyFile))
+		shard, err := LoadThresholdShard(keyFile, filepath.Join(dir, fmt.Sprintf("state%d.json", i)))
+		require.NoError(t, err)
+
+		unixFilePath, err := testUnixAddr()
+		require.NoError(t, err)
+		sl, sd := getMockEndpoints(t, "unix://"+unixFilePath, DialUnixFn(unixFilePath))
+		sl.timeoutAccept = testTimeoutReadWrite // not to wait for stopped shards
+		ss := NewThresholdSignerServer(sd, chainID, shard)
+		require.NoError(t, ss.Start())
+		endpoints = append(endpoints, sl)
+		servers = append(servers, ss)
+		shards = append(shards, shard)
+	}
+
+	tc, err := NewThresholdSignerClient(endpoints, chainID, time.Second, log.TestingLogger())
+	require.NoError(t, err)
+	return tc, servers, shards, func() {
+		tc.Close()
+		for _, ss := range servers {
+			ss.Stop()
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestThresholdSignerClient(t *testing.T) {
+	chainID := "test-chain"
+	privKey := ed25519.GenPrivKey()
+	tc, servers, shards, stop := newThresholdSigner(t, privKey, 2, 3, chainID)
+	defer stop()
+	assert.Equal(t, privKey.PubKey(), tc.GetPubKey())
+
+	blockID := types.BlockID{Hash: tmhash.Sum([]byte("block")),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))}}
+	vote := newVote(privKey.PubKey().Address(), 0, 1, 0, byte(types.PrevoteType), blockID)
+	require.NoError(t, tc.SignVote(chainID, vote))
+	assert.True(t, privKey.PubKey().VerifyBytes(vote.SignBytes(chainID), vote.Signature))
+
+	// all the shards learn the signature, even the one which didn't sign a share
+	for _, shard := range shards {
+		shard := shard
+		assert.Eventually(t, func() bool {
+			shard.mtx.Lock()
+			defer shard.mtx.Unlock()
+			return shard.LastSignState.Signature != nil
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	// the same vote with another timestamp gets the same signature
+	vote2 := vote.Copy()
+	vote2.Timestamp = vote.Timestamp.Add(time.Second)
+	vote2.Signature = nil
+	require.NoError(t, tc.SignVote(chainID, vote2))
+	assert.Equal(t, vote.Timestamp, vote2.Timestamp)
+	assert.Equal(t, vote.Signature, vote2.Signature)
+
+	// no conflicting vote, nor regression
+	vote3 := newVote(privKey.PubKey().Address(), 0, 1, 0, byte(types.PrevoteType), types.BlockID{})
+	assert.Error(t, tc.SignVote(chainID, vote3))
+
+	// threshold shards are enough
+	require.NoError(t, servers[0].Stop())
+	proposal := newProposal(1, 1, blockID)
+	require.NoError(t, tc.SignProposal(chainID, proposal))
+	assert.True(t, privKey.PubKey().VerifyBytes(proposal.SignBytes(chainID), proposal.Signature))
+
+	// not less
+	require.NoError(t, servers[1].Stop())
+	vote4 := newVote(privKey.PubKey().Address(), 0, 1, 1, byte(types.PrevoteType), blockID)
+	assert.Error(t, tc.SignVote(chainID, vote4))
+}
+
+func TestThresholdShardConflicts(t *testing.T) {
+	chainID := "test-chain"
+	privKey := ed25519.GenPrivKey()
+	keyShares, err := threshold.SplitPrivKey(privKey, 2, 2)
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "threshold")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	shard := &ThresholdShard{Key: keyShares[0]}
+	shard.LastSignState.filePath = filepath.Join(dir, "state.json")
+	handle := func(req SignerMessage) SignerMessage {
+		res, _ := shard.HandleRequest(nil, req, chainID)
+		return res
+	}
+
+	res := handle(&ThresholdHandshakeRequest{ChainID: "other-chain"})
+	assert.NotNil(t, res.(*ThresholdHandshakeResponse).Error)
+	res = handle(&ThresholdHandshakeRequest{ChainID: chainID})
+	assert.Equal(t, &ThresholdHandshakeResponse{ID: 1, Threshold: 2, Total: 2, PubKey: privKey.PubKey()}, res)
+
+	// a share is signed once per commitment
+	blockID := types.BlockID{Hash: tmhash.Sum([]byte("block")),
+		PartsHeader: types.PartSetHeader{Total: 1, Hash: tmhash.Sum([]byte("parts"))}}
+	vote := newVote(privKey.PubKey().Address(), 0, 2, 0, byte(types.PrecommitType), blockID)
+	res = handle(&ThresholdCommitRequest{Vote: vote})
+	c1 := res.(*ThresholdCommitResponse).Commitment
+	require.NotNil(t, c1)
+	_, c2 := keyShares[1].Commit()
+	res = handle(&ThresholdShareRequest{Commitments: []threshold.Commitment{*c1, c2}})
+	assert.NotNil(t, res.(*ThresholdShareResponse).Share)
+	res = handle(&ThresholdShareRequest{Commitments: []threshold.Commitment{*c1, c2}})
+	assert.NotNil(t, res.(*ThresholdShareResponse).Error)
+
+	// the height/round/step is recorded with the share
+	assert.EqualValues(t, 2, shard.LastSignState.Height)
+	for _, v := range []*types.Vote{
+		newVote(privKey.PubKey().Address(), 0, 2, 0, byte(types.PrecommitType), types.BlockID{}),
+		newVote(privKey.PubKey().Address(), 0, 1, 5, byte(types.PrecommitType), blockID),
+		newVote(privKey.PubKey().Address(), 0, 2, 0, byte(types.PrevoteType), blockID),
+	} {
+		res = handle(&ThresholdCommitRequest{Vote: v})
+		assert.NotNil(t, res.(*ThresholdCommitResponse).Error, "%v", v)
+	}
+
+	// signatures of later votes are learnt, invalid ones aren't
+	vote = newVote(privKey.PubKey().Address(), 0, 3, 0, byte(types.PrevoteType), blockID)
+	sig, err := privKey.Sign(vote.SignBytes(chainID))
+	require.NoError(t, err)
+	res = handle(&ThresholdSignedRequest{3, 0, stepPrevote, vote.SignBytes(chainID), sig[1:]})
+	assert.NotNil(t, res.(*ThresholdSignedResponse).Error)
+	res = handle(&ThresholdSignedRequest{3, 0, stepPrevote, vote.SignBytes(chainID), sig})
+	assert.Nil(t, res.(*ThresholdSignedResponse).Error)
+	assert.EqualValues(t, 3, shard.LastSignState.Height)
+	assert.Equal(t, sig, shard.LastSignState.Signature)
+
+	res = handle(&ThresholdCommitRequest{Vote: vote})
+	assert.Equal(t, sig, res.(*ThresholdCommitResponse).Signature)
+}
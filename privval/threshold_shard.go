@@ -0,0 +1,246 @@
+package privval
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/crypto/threshold"
+	"github.com/tendermint/tendermint/libs/tempfile"
+	"github.com/tendermint/tendermint/types"
+)
+
+// ThresholdShard holds a share of the key of a validator, and signs the shares
+// of its signatures for a ThresholdSignerClient. Like FilePV, it refuses to
+// sign votes and proposals conflicting with the last one it signed a share of,
+// or learnt the signature of from the client.
+type ThresholdShard struct {
+	Key           threshold.KeyShare
+	LastSignState FilePVLastSignState // Signature is empty until learnt
+
+	mtx     sync.Mutex
+	session *thresholdSession // waiting for the ThresholdShareRequest
+}
+
+// thresholdSession is a signature the shard committed nonces for.
+type thresholdSession struct {
+	height    int64
+	round     int
+	step      int8
+	signBytes []byte
+	nonces    *threshold.Nonces
+}
+
+// LoadThresholdShard loads the key share saved in keyFilePath, and the last
+// sign state saved in stateFilePath, if any.
+func LoadThresholdShard(keyFilePath, stateFilePath string) (*ThresholdShard, error) {
+	bz, err := ioutil.ReadFile(keyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	sh := &ThresholdShard{}
+	if err := cdc.UnmarshalJSON(bz, &sh.Key); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the key share %s", keyFilePath)
+	}
+	if err := sh.Key.ValidateBasic(); err != nil {
+		return nil, errors.Wrapf(err, "invalid key share %s", keyFilePath)
+	}
+
+	bz, err = ioutil.ReadFile(stateFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := cdc.UnmarshalJSON(bz, &sh.LastSignState); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the sign state %s", stateFilePath)
+		}
+	}
+	sh.LastSignState.filePath = stateFilePath
+	return sh, nil
+}
+
+// SaveThresholdKeyShare saves share to filePath.
+func SaveThresholdKeyShare(share threshold.KeyShare, filePath string) error {
+	bz, err := cdc.MarshalJSONIndent(share, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(filePath, bz, 0600)
+}
+
+// NewThresholdSignerServer returns a SignerServer serving the requests of a
+// ThresholdSignerClient with shard.
+func NewThresholdSignerServer(endpoint *SignerDialerEndpoint, chainID string, shard *ThresholdShard) *SignerServer {
+	ss := NewSignerServer(endpoint, chainID, nil)
+	ss.SetRequestHandler(shard.HandleRequest)
+	return ss
+}
+
+// HandleRequest handles the requests of a ThresholdSignerClient. It
+// implements ValidationRequestHandlerFunc, privVal is ignored.
+func (sh *ThresholdShard) HandleRequest(
+	privVal types.PrivValidator,
+	req SignerMessage,
+	chainID string,
+) (SignerMessage, error) {
+	sh.mtx.Lock()
+	defer sh.mtx.Unlock()
+
+	switch r := req.(type) {
+	case *ThresholdHandshakeRequest:
+		if r.ChainID != chainID {
+			err := fmt.Errorf("shard of chain %s, not %s", chainID, r.ChainID)
+			return &ThresholdHandshakeResponse{Error: &RemoteSignerError{0, err.Error()}}, err
+		}
+		return &ThresholdHandshakeResponse{
+			ID:        sh.Key.ID,
+			Threshold: sh.Key.Threshold,
+			Total:     sh.Key.Total,
+			PubKey:    sh.Key.PubKey,
+		}, nil
+
+	case *ThresholdCommitRequest:
+		res, err := sh.commit(r, chainID)
+		if err != nil {
+			return &ThresholdCommitResponse{Error: &RemoteSignerError{0, err.Error()}}, err
+		}
+		return res, nil
+
+	case *ThresholdShareRequest:
+		share, err := sh.signShare(r.Commitments)
+		if err != nil {
+			return &ThresholdShareResponse{Error: &RemoteSignerError{0, err.Error()}}, err
+		}
+		return &ThresholdShareResponse{Share: share}, nil
+
+	case *ThresholdSignedRequest:
+		if err := sh.signed(r); err != nil {
+			return &ThresholdSignedResponse{Error: &RemoteSignerError{0, err.Error()}}, err
+		}
+		return &ThresholdSignedResponse{}, nil
+
+	case *PubKeyRequest:
+		return &PubKeyResponse{sh.Key.PubKey, nil}, nil
+
+	case *PingRequest:
+		return &PingResponse{}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown msg: %v", r)
+	}
+}
+
+// commit checks the vote or the proposal of r can be signed, and commits new
+// nonces to sign it.
+func (sh *ThresholdShard) commit(r *ThresholdCommitRequest, chainID string) (*ThresholdCommitResponse, error) {
+	s := &thresholdSession{}
+	switch {
+	case r.Vote != nil:
+		if r.Vote.Type != types.PrevoteType && r.Vote.Type != types.PrecommitType {
+			return nil, fmt.Errorf("unknown vote type %v", r.Vote.Type)
+		}
+		s.height, s.round, s.step = r.Vote.Height, r.Vote.Round, voteToStep(r.Vote)
+		s.signBytes = r.Vote.SignBytes(chainID)
+	case r.Proposal != nil:
+		s.height, s.round, s.step = r.Proposal.Height, r.Proposal.Round, stepPropose
+		s.signBytes = r.Proposal.SignBytes(chainID)
+	default:
+		return nil, errors.New("nothing to sign")
+	}
+
+	sameHRS, err := sh.checkHRS(s.height, s.round, s.step, s.signBytes)
+	if err != nil {
+		return nil, err
+	}
+	if sameHRS && sh.LastSignState.Signature != nil {
+		// signed already, the client reuses the signature
+		return &ThresholdCommitResponse{
+			SignBytes: sh.LastSignState.SignBytes,
+			Signature: sh.LastSignState.Signature,
+		}, nil
+	}
+
+	var commitment threshold.Commitment
+	s.nonces, commitment = sh.Key.Commit()
+	sh.session = s
+	return &ThresholdCommitResponse{Commitment: &commitment}, nil
+}
+
+// signShare signs the share of the signature of the last session committed,
+// recording its height/round/step first.
+func (sh *ThresholdShard) signShare(commitments []threshold.Commitment) ([]byte, error) {
+	s := sh.session
+	if s == nil {
+		return nil, errors.New("no nonces committed")
+	}
+	sh.session = nil // the nonces are used once, whatever happens
+
+	// the state may have changed since the nonces were committed
+	if _, err := sh.checkHRS(s.height, s.round, s.step, s.signBytes); err != nil {
+		return nil, err
+	}
+	if !sh.sameHRS(s.height, s.round, s.step) || !bytes.Equal(sh.LastSignState.SignBytes, s.signBytes) {
+		sh.saveSigned(s.height, s.round, s.step, s.signBytes, nil)
+	}
+	return sh.Key.SignShare(s.nonces, s.signBytes, commitments)
+}
+
+// signed records the signature of r, unless the shard signed a later
+// height/round/step already.
+func (sh *ThresholdShard) signed(r *ThresholdSignedRequest) error {
+	if !sh.Key.PubKey.VerifyBytes(r.SignBytes, r.Signature) {
+		return errors.New("invalid signature")
+	}
+	if _, err := sh.checkHRS(r.Height, r.Round, r.Step, r.SignBytes); err != nil {
+		return nil // outdated
+	}
+	sh.saveSigned(r.Height, r.Round, r.Step, r.SignBytes, r.Signature)
+	return nil
+}
+
+// checkHRS returns an error if signBytes, at height/round/step, conflicts
+// with the last sign state: if it's a regression, or if it's for the same
+// height/round/step and the sign bytes differ by more than the timestamp.
+func (sh *ThresholdShard) checkHRS(height int64, round int, step int8, signBytes []byte) (bool, error) {
+	lss := sh.LastSignState
+	switch {
+	case height < lss.Height:
+		return false, fmt.Errorf("height regression. Got %v, last height %v", height, lss.Height)
+	case height == lss.Height && round < lss.Round:
+		return false, fmt.Errorf("round regression at height %v. Got %v, last round %v", height, round, lss.Round)
+	case height == lss.Height && round == lss.Round && step < lss.Step:
+		return false, fmt.Errorf("step regression at height %v round %v. Got %v, last step %v",
+			height, round, step, lss.Step)
+	case !sh.sameHRS(height, round, step) || lss.SignBytes == nil || bytes.Equal(signBytes, lss.SignBytes):
+		return sh.sameHRS(height, round, step), nil
+	}
+
+	var ok bool
+	if step == stepPropose {
+		_, ok = checkProposalsOnlyDifferByTimestamp(lss.SignBytes, signBytes)
+	} else {
+		_, ok = checkVotesOnlyDifferByTimestamp(lss.SignBytes, signBytes)
+	}
+	if !ok {
+		return true, errors.New("conflicting data")
+	}
+	return true, nil
+}
+
+func (sh *ThresholdShard) sameHRS(height int64, round int, step int8) bool {
+	lss := sh.LastSignState
+	return lss.Height == height && lss.Round == round && lss.Step == step
+}
+
+func (sh *ThresholdShard) saveSigned(height int64, round int, step int8, signBytes, sig []byte) {
+	sh.LastSignState.Height = height
+	sh.LastSignState.Round = round
+	sh.LastSignState.Step = step
+	sh.LastSignState.SignBytes = signBytes
+	sh.LastSignState.Signature = sig
+	sh.LastSignState.Save()
+}
@@ -197,6 +197,51 @@ func (c *baseRPCClient) ABCIInfo() (*ctypes.ResultABCIInfo, error) {
 	return result, nil
 }
 
+// Snapshots lists the snapshots of the application.
+func (c *baseRPCClient) Snapshots() (*ctypes.ResultSnapshots, error) {
+	result := new(ctypes.ResultSnapshots)
+	_, err := c.caller.Call("snapshots", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "Snapshots")
+	}
+	return result, nil
+}
+
+// SnapshotChunk returns a chunk of a snapshot of the application.
+func (c *baseRPCClient) SnapshotChunk(height uint64, format uint32, chunk uint32) (
+	*ctypes.ResultSnapshotChunk, error) {
+	result := new(ctypes.ResultSnapshotChunk)
+	_, err := c.caller.Call("snapshot_chunk",
+		map[string]interface{}{"height": height, "format": format, "chunk": chunk}, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "SnapshotChunk")
+	}
+	return result, nil
+}
+
+// CreateSnapshot asks the application to snapshot its state. The node must
+// serve the unsafe routes.
+func (c *baseRPCClient) CreateSnapshot() (*ctypes.ResultCreateSnapshot, error) {
+	result := new(ctypes.ResultCreateSnapshot)
+	_, err := c.caller.Call("unsafe_create_snapshot", map[string]interface{}{}, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "CreateSnapshot")
+	}
+	return result, nil
+}
+
+// DeleteSnapshot asks the application to delete a snapshot. The node must
+// serve the unsafe routes.
+func (c *baseRPCClient) DeleteSnapshot(height uint64, format uint32) (*ctypes.ResultDeleteSnapshot, error) {
+	result := new(ctypes.ResultDeleteSnapshot)
+	_, err := c.caller.Call("unsafe_delete_snapshot",
+		map[string]interface{}{"height": height, "format": format}, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "DeleteSnapshot")
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) ABCIQuery(path string, data bytes.HexBytes) (*ctypes.ResultABCIQuery, error) {
 	return c.ABCIQueryWithOptions(path, data, DefaultABCIQueryOptions)
 }
@@ -241,9 +286,17 @@ func (c *baseRPCClient) broadcastTX(route string, tx types.Tx) (*ctypes.ResultBr
 	return result, nil
 }
 
-func (c *baseRPCClient) UnconfirmedTxs(limit int) (*ctypes.ResultUnconfirmedTxs, error) {
+func (c *baseRPCClient) UnconfirmedTxs(limit int, cursor uint64, sender string,
+	minPriority *int64, hashPrefix []byte) (*ctypes.ResultUnconfirmedTxs, error) {
 	result := new(ctypes.ResultUnconfirmedTxs)
-	_, err := c.caller.Call("unconfirmed_txs", map[string]interface{}{"limit": limit}, result)
+	params := map[string]interface{}{
+		"limit":        limit,
+		"cursor":       cursor,
+		"sender":       sender,
+		"min_priority": minPriority,
+		"hash_prefix":  hashPrefix,
+	}
+	_, err := c.caller.Call("unconfirmed_txs", params, result)
 	if err != nil {
 		return nil, errors.Wrap(err, "unconfirmed_txs")
 	}
@@ -259,6 +312,15 @@ func (c *baseRPCClient) NumUnconfirmedTxs() (*ctypes.ResultUnconfirmedTxs, error
 	return result, nil
 }
 
+func (c *baseRPCClient) TxStatus(hash []byte) (*ctypes.ResultTxStatus, error) {
+	result := new(ctypes.ResultTxStatus)
+	_, err := c.caller.Call("tx_status", map[string]interface{}{"hash": hash}, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "TxStatus")
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) NetInfo() (*ctypes.ResultNetInfo, error) {
 	result := new(ctypes.ResultNetInfo)
 	_, err := c.caller.Call("net_info", map[string]interface{}{}, result)
@@ -381,6 +443,22 @@ func (c *baseRPCClient) TxSearch(query string, prove bool, page, perPage int, or
 	return result, nil
 }
 
+func (c *baseRPCClient) BlockSearch(query string, page, perPage int, orderBy string) (
+	*ctypes.ResultBlockSearch, error) {
+	result := new(ctypes.ResultBlockSearch)
+	params := map[string]interface{}{
+		"query":    query,
+		"page":     page,
+		"per_page": perPage,
+		"order_by": orderBy,
+	}
+	_, err := c.caller.Call("block_search", params, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "BlockSearch")
+	}
+	return result, nil
+}
+
 func (c *baseRPCClient) Validators(height *int64, page, perPage int) (*ctypes.ResultValidators, error) {
 	result := new(ctypes.ResultValidators)
 	_, err := c.caller.Call("validators", map[string]interface{}{
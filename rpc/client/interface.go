@@ -70,6 +70,7 @@ type SignClient interface {
 	Validators(height *int64, page, perPage int) (*ctypes.ResultValidators, error)
 	Tx(hash []byte, prove bool) (*ctypes.ResultTx, error)
 	TxSearch(query string, prove bool, page, perPage int, orderBy string) (*ctypes.ResultTxSearch, error)
+	BlockSearch(query string, page, perPage int, orderBy string) (*ctypes.ResultBlockSearch, error)
 }
 
 // HistoryClient provides access to data from genesis to now in large chunks.
@@ -112,8 +113,10 @@ type EventsClient interface {
 
 // MempoolClient shows us data about current mempool state.
 type MempoolClient interface {
-	UnconfirmedTxs(limit int) (*ctypes.ResultUnconfirmedTxs, error)
+	UnconfirmedTxs(limit int, cursor uint64, sender string,
+		minPriority *int64, hashPrefix []byte) (*ctypes.ResultUnconfirmedTxs, error)
 	NumUnconfirmedTxs() (*ctypes.ResultUnconfirmedTxs, error)
+	TxStatus(hash []byte) (*ctypes.ResultTxStatus, error)
 }
 
 // EvidenceClient is used for submitting an evidence of the malicious
@@ -200,6 +200,47 @@ func TestABCIQuery(t *testing.T) {
 	}
 }
 
+func TestSnapshots(t *testing.T) {
+	type snapshotClient interface {
+		client.Client
+		Snapshots() (*ctypes.ResultSnapshots, error)
+		SnapshotChunk(height uint64, format uint32, chunk uint32) (*ctypes.ResultSnapshotChunk, error)
+		CreateSnapshot() (*ctypes.ResultCreateSnapshot, error)
+		DeleteSnapshot(height uint64, format uint32) (*ctypes.ResultDeleteSnapshot, error)
+	}
+	for i, c := range []snapshotClient{getHTTPClient(), getLocalClient()} {
+		created, err := c.CreateSnapshot()
+		require.NoError(t, err, "%d", i)
+		require.NotZero(t, created.Height, "%d", i)
+		// the snapshot may be taken at the end of the current block
+		err = client.WaitForHeight(c, int64(created.Height)+1, nil)
+		require.NoError(t, err, "%d", i)
+
+		res, err := c.Snapshots()
+		require.NoError(t, err, "%d", i)
+		var snapshot *abci.Snapshot
+		for _, s := range res.Snapshots {
+			if s.Height == created.Height {
+				snapshot = s
+			}
+		}
+		require.NotNil(t, snapshot, "%d: %v", i, res.Snapshots)
+		assert.NotZero(t, snapshot.Chunks, "%d", i)
+
+		chunk, err := c.SnapshotChunk(snapshot.Height, snapshot.Format, 0)
+		require.NoError(t, err, "%d", i)
+		assert.NotEmpty(t, chunk.Chunk, "%d", i)
+		chunk, err = c.SnapshotChunk(snapshot.Height, snapshot.Format, snapshot.Chunks)
+		require.NoError(t, err, "%d", i)
+		assert.Nil(t, chunk.Chunk, "%d: the app doesn't have the chunk", i)
+
+		_, err = c.DeleteSnapshot(snapshot.Height, snapshot.Format)
+		require.NoError(t, err, "%d", i)
+		_, err = c.DeleteSnapshot(snapshot.Height, snapshot.Format)
+		assert.Error(t, err, "%d", i)
+	}
+}
+
 // Make some app checks
 func TestAppCalls(t *testing.T) {
 	assert, require := assert.New(t), require.New(t)
@@ -337,13 +378,23 @@ func TestUnconfirmedTxs(t *testing.T) {
 	for i, c := range GetClients() {
 		mc, ok := c.(client.MempoolClient)
 		require.True(t, ok, "%d", i)
-		res, err := mc.UnconfirmedTxs(1)
+		res, err := mc.UnconfirmedTxs(1, 0, "", nil, nil)
 		require.Nil(t, err, "%d: %+v", i, err)
 
 		assert.Equal(t, 1, res.Count)
 		assert.Equal(t, 1, res.Total)
 		assert.Equal(t, mempool.TxsBytes(), res.TotalBytes)
 		assert.Exactly(t, types.Txs{tx}, types.Txs(res.Txs))
+
+		// no more txs after the cursor
+		res, err = mc.UnconfirmedTxs(1, res.Cursor, "", nil, nil)
+		require.Nil(t, err, "%d: %+v", i, err)
+		assert.Zero(t, res.Count)
+
+		// filtered by hash prefix
+		res, err = mc.UnconfirmedTxs(1, 0, "", nil, types.Tx(tx).Hash()[:2])
+		require.Nil(t, err, "%d: %+v", i, err)
+		assert.Exactly(t, types.Txs{tx}, types.Txs(res.Txs))
 	}
 
 	mempool.Flush()
@@ -370,6 +421,26 @@ func TestNumUnconfirmedTxs(t *testing.T) {
 	mempool.Flush()
 }
 
+func TestTxStatus(t *testing.T) {
+	for i, c := range GetClients() {
+		_, _, tx := MakeTxKV()
+		bres, err := c.BroadcastTxCommit(tx)
+		require.Nil(t, err, "%d: %+v", i, err)
+
+		mc, ok := c.(client.MempoolClient)
+		require.True(t, ok, "%d", i)
+		res, err := mc.TxStatus(bres.Hash)
+		require.Nil(t, err, "%d: %+v", i, err)
+		assert.Equal(t, types.TxStatusCommitted, res.Status)
+		assert.Equal(t, bres.Height, res.Height)
+		assert.EqualValues(t, bres.Hash, res.Hash)
+
+		res, err = mc.TxStatus(types.Tx("unknown").Hash())
+		require.Nil(t, err, "%d: %+v", i, err)
+		assert.Equal(t, types.TxStatusUnknown, res.Status)
+	}
+}
+
 func TestTx(t *testing.T) {
 	// first we broadcast a tx
 	c := getHTTPClient()
@@ -550,6 +621,38 @@ func TestTxSearch(t *testing.T) {
 	}
 }
 
+func TestBlockSearch(t *testing.T) {
+	c := getHTTPClient()
+
+	_, _, tx := MakeTxKV()
+	bres, err := c.BroadcastTxCommit(tx)
+	require.NoError(t, err)
+
+	for i, c := range GetClients() {
+		t.Logf("client %d", i)
+
+		// search by height
+		result, err := c.BlockSearch(fmt.Sprintf("block.height = %d", bres.Height), 1, 30, "")
+		require.NoError(t, err)
+		require.Len(t, result.Blocks, 1)
+		assert.Equal(t, 1, result.TotalCount)
+		assert.EqualValues(t, bres.Height, result.Blocks[0].Block.Height)
+		assert.NotEmpty(t, result.Blocks[0].BlockID.Hash)
+
+		// the blocks are sorted by height
+		result, err = c.BlockSearch(fmt.Sprintf("block.height <= %d", bres.Height), 1, 100, "desc")
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Blocks)
+		assert.EqualValues(t, bres.Height, result.Blocks[0].Block.Height)
+		for j := 1; j < len(result.Blocks); j++ {
+			assert.Greater(t, result.Blocks[j-1].Block.Height, result.Blocks[j].Block.Height)
+		}
+
+		_, err = c.BlockSearch("block.height >= 1", 1, 30, "random")
+		require.Error(t, err)
+	}
+}
+
 func deepcpVote(vote *types.Vote) (res *types.Vote) {
 	res = &types.Vote{
 		ValidatorAddress: make([]byte, len(vote.ValidatorAddress)),
@@ -1,36 +1,42 @@
 package core
 
 import (
-	"fmt"
+	"sort"
 
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	tmmath "github.com/tendermint/tendermint/libs/math"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
 	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/state/txindex/null"
 	"github.com/tendermint/tendermint/types"
 )
 
+var errBlockIndexingDisabled = tmerrors.New(tmerrors.CodeNotSupported, "block indexing is disabled")
+
 // BlockchainInfo gets block headers for minHeight <= height <= maxHeight.
 // Block headers are returned in descending order (highest first).
 // More: https://docs.tendermint.com/master/rpc/#/Info/blockchain
-func BlockchainInfo(ctx *rpctypes.Context, minHeight, maxHeight int64) (*ctypes.ResultBlockchainInfo, error) {
+func (env *Environment) BlockchainInfo(ctx *rpctypes.Context, minHeight, maxHeight int64) (
+	*ctypes.ResultBlockchainInfo, error) {
 	// maximum 20 block metas
 	const limit int64 = 20
 	var err error
-	minHeight, maxHeight, err = filterMinMax(blockStore.Height(), minHeight, maxHeight, limit)
+	minHeight, maxHeight, err = filterMinMax(env.BlockStore.Base(), env.BlockStore.Height(), minHeight, maxHeight, limit)
 	if err != nil {
 		return nil, err
 	}
-	logger.Debug("BlockchainInfoHandler", "maxHeight", maxHeight, "minHeight", minHeight)
+	env.Logger.Debug("BlockchainInfoHandler", "maxHeight", maxHeight, "minHeight", minHeight)
 
 	blockMetas := []*types.BlockMeta{}
 	for height := maxHeight; height >= minHeight; height-- {
-		blockMeta := blockStore.LoadBlockMeta(height)
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
 		blockMetas = append(blockMetas, blockMeta)
 	}
 
 	return &ctypes.ResultBlockchainInfo{
-		LastHeight: blockStore.Height(),
+		LastHeight: env.BlockStore.Height(),
 		BlockMetas: blockMetas}, nil
 }
 
@@ -38,16 +44,19 @@ func BlockchainInfo(ctx *rpctypes.Context, minHeight, maxHeight int64) (*ctypes.
 // if 0, use 1 for min, latest block height for max
 // enforce limit.
 // error if min > max
-func filterMinMax(height, min, max, limit int64) (int64, int64, error) {
+// min is raised to the base of the block store, as the blocks below it are
+// not available (e.g. the node was state synced).
+func filterMinMax(base, height, min, max, limit int64) (int64, int64, error) {
 	// filter negatives
 	if min < 0 || max < 0 {
-		return min, max, fmt.Errorf("heights must be non-negative")
+		return min, max, tmerrors.New(tmerrors.CodeInvalidArgument, "heights must be non-negative")
 	}
 
 	// adjust for default values
 	if min == 0 {
 		min = 1
 	}
+	min = tmmath.MaxInt64(min, base)
 	if max == 0 {
 		max = height
 	}
@@ -60,7 +69,8 @@ func filterMinMax(height, min, max, limit int64) (int64, int64, error) {
 	min = tmmath.MaxInt64(min, max-limit+1)
 
 	if min > max {
-		return min, max, fmt.Errorf("min height %d can't be greater than max height %d", min, max)
+		return min, max, tmerrors.Errorf(tmerrors.CodeInvalidArgument,
+			"min height %d can't be greater than max height %d", min, max)
 	}
 	return min, max, nil
 }
@@ -68,15 +78,15 @@ func filterMinMax(height, min, max, limit int64) (int64, int64, error) {
 // Block gets block at a given height.
 // If no height is provided, it will fetch the latest block.
 // More: https://docs.tendermint.com/master/rpc/#/Info/block
-func Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
-	storeHeight := blockStore.Height()
-	height, err := getHeight(storeHeight, heightPtr)
+func (env *Environment) Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error) {
+	storeHeight := env.BlockStore.Height()
+	height, err := env.getHeight(storeHeight, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	block := blockStore.LoadBlock(height)
-	blockMeta := blockStore.LoadBlockMeta(height)
+	block := env.BlockStore.LoadBlock(height)
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
 	if blockMeta == nil {
 		return &ctypes.ResultBlock{BlockID: types.BlockID{}, Block: block}, nil
 	}
@@ -85,27 +95,27 @@ func Block(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlock, error)
 
 // BlockByHash gets block by hash.
 // More: https://docs.tendermint.com/master/rpc/#/Info/block_by_hash
-func BlockByHash(ctx *rpctypes.Context, hash []byte) (*ctypes.ResultBlock, error) {
-	block := blockStore.LoadBlockByHash(hash)
+func (env *Environment) BlockByHash(ctx *rpctypes.Context, hash []byte) (*ctypes.ResultBlock, error) {
+	block := env.BlockStore.LoadBlockByHash(hash)
 	if block == nil {
 		return &ctypes.ResultBlock{BlockID: types.BlockID{}, Block: nil}, nil
 	}
 	// If block is not nil, then blockMeta can't be nil.
-	blockMeta := blockStore.LoadBlockMeta(block.Height)
+	blockMeta := env.BlockStore.LoadBlockMeta(block.Height)
 	return &ctypes.ResultBlock{BlockID: blockMeta.BlockID, Block: block}, nil
 }
 
 // Commit gets block commit at a given height.
 // If no height is provided, it will fetch the commit for the latest block.
 // More: https://docs.tendermint.com/master/rpc/#/Info/commit
-func Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
-	storeHeight := blockStore.Height()
-	height, err := getHeight(storeHeight, heightPtr)
+func (env *Environment) Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, error) {
+	storeHeight := env.BlockStore.Height()
+	height, err := env.getHeight(storeHeight, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	blockMeta := blockStore.LoadBlockMeta(height)
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
 	if blockMeta == nil {
 		return nil, nil
 	}
@@ -114,12 +124,12 @@ func Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, erro
 	// If the next block has not been committed yet,
 	// use a non-canonical commit
 	if height == storeHeight {
-		commit := blockStore.LoadSeenCommit(height)
+		commit := env.BlockStore.LoadSeenCommit(height)
 		return ctypes.NewResultCommit(&header, commit, false), nil
 	}
 
 	// Return the canonical commit (comes from the block at height+1)
-	commit := blockStore.LoadBlockCommit(height)
+	commit := env.BlockStore.LoadBlockCommit(height)
 	return ctypes.NewResultCommit(&header, commit, true), nil
 }
 
@@ -130,14 +140,14 @@ func Commit(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultCommit, erro
 // Thus response.results.deliver_tx[5] is the results of executing
 // getBlock(h).Txs[5]
 // More: https://docs.tendermint.com/master/rpc/#/Info/block_results
-func BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlockResults, error) {
-	storeHeight := blockStore.Height()
-	height, err := getHeight(storeHeight, heightPtr)
+func (env *Environment) BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlockResults, error) {
+	storeHeight := env.BlockStore.Height()
+	height, err := env.getHeight(storeHeight, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	results, err := sm.LoadABCIResponses(stateDB, height)
+	results, err := sm.LoadABCIResponses(env.StateDB, height)
 	if err != nil {
 		return nil, err
 	}
@@ -152,14 +162,75 @@ func BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultBlockR
 	}, nil
 }
 
-func getHeight(currentHeight int64, heightPtr *int64) (int64, error) {
+// BlockSearch searches for the blocks whose BeginBlock and EndBlock events
+// match the query (e.g. "rewards.amount > 10 AND block.height >= 100"). It
+// returns a list of blocks (maximum ?per_page entries) and the total count.
+// More: https://docs.tendermint.com/master/rpc/#/Info/block_search
+func (env *Environment) BlockSearch(ctx *rpctypes.Context, query string, page, perPage int, orderBy string) (
+	*ctypes.ResultBlockSearch, error) {
+	// if index is disabled, return error
+	if _, ok := env.BlockIndexer.(*null.BlockIndex); ok {
+		return nil, errBlockIndexingDisabled
+	}
+
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, err
+	}
+
+	heights, err := env.BlockIndexer.Search(ctx.Context(), q)
+	if err != nil {
+		return nil, err
+	}
+
+	// sort results (must be done before pagination)
+	switch orderBy {
+	case "desc":
+		sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+	case "asc", "":
+		sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	default:
+		return nil, tmerrors.New(tmerrors.CodeInvalidArgument,
+			"expected order_by to be either `asc` or `desc` or empty")
+	}
+
+	// paginate results
+	totalCount := len(heights)
+	perPage = validatePerPage(perPage)
+	page, err = validatePage(page, perPage, totalCount)
+	if err != nil {
+		return nil, err
+	}
+	skipCount := validateSkipCount(page, perPage)
+	pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
+
+	apiResults := make([]*ctypes.ResultBlock, 0, pageSize)
+	for i := skipCount; i < skipCount+pageSize; i++ {
+		block := env.BlockStore.LoadBlock(heights[i])
+		blockMeta := env.BlockStore.LoadBlockMeta(heights[i])
+		if blockMeta == nil {
+			apiResults = append(apiResults, &ctypes.ResultBlock{BlockID: types.BlockID{}, Block: block})
+			continue
+		}
+		apiResults = append(apiResults, &ctypes.ResultBlock{BlockID: blockMeta.BlockID, Block: block})
+	}
+
+	return &ctypes.ResultBlockSearch{Blocks: apiResults, TotalCount: totalCount}, nil
+}
+
+func (env *Environment) getHeight(currentHeight int64, heightPtr *int64) (int64, error) {
 	if heightPtr != nil {
 		height := *heightPtr
 		if height <= 0 {
-			return 0, fmt.Errorf("height must be greater than 0")
+			return 0, tmerrors.New(tmerrors.CodeInvalidArgument, "height must be greater than 0")
 		}
 		if height > currentHeight {
-			return 0, fmt.Errorf("height must be less than or equal to the current blockchain height")
+			return 0, tmerrors.New(tmerrors.CodeNotFound,
+				"height must be less than or equal to the current blockchain height")
+		}
+		if base := env.BlockStore.Base(); height < base {
+			return 0, tmerrors.Errorf(tmerrors.CodeNotFound,
+				"height %d is not available, lowest height is %d", height, base)
 		}
 		return height, nil
 	}
@@ -18,42 +18,47 @@ import (
 func TestBlockchainInfo(t *testing.T) {
 	cases := []struct {
 		min, max     int64
-		height       int64
+		base, height int64
 		limit        int64
 		resultLength int64
 		wantErr      bool
 	}{
 
 		// min > max
-		{0, 0, 0, 10, 0, true},  // min set to 1
-		{0, 1, 0, 10, 0, true},  // max set to height (0)
-		{0, 0, 1, 10, 1, false}, // max set to height (1)
-		{2, 0, 1, 10, 0, true},  // max set to height (1)
-		{2, 1, 5, 10, 0, true},
+		{0, 0, 1, 0, 10, 0, true},  // min set to 1
+		{0, 1, 1, 0, 10, 0, true},  // max set to height (0)
+		{0, 0, 1, 1, 10, 1, false}, // max set to height (1)
+		{2, 0, 1, 1, 10, 0, true},  // max set to height (1)
+		{2, 1, 1, 5, 10, 0, true},
 
 		// negative
-		{1, 10, 14, 10, 10, false}, // control
-		{-1, 10, 14, 10, 0, true},
-		{1, -10, 14, 10, 0, true},
-		{-9223372036854775808, -9223372036854775788, 100, 20, 0, true},
+		{1, 10, 1, 14, 10, 10, false}, // control
+		{-1, 10, 1, 14, 10, 0, true},
+		{1, -10, 1, 14, 10, 0, true},
+		{-9223372036854775808, -9223372036854775788, 1, 100, 20, 0, true},
 
 		// check limit and height
-		{1, 1, 1, 10, 1, false},
-		{1, 1, 5, 10, 1, false},
-		{2, 2, 5, 10, 1, false},
-		{1, 2, 5, 10, 2, false},
-		{1, 5, 1, 10, 1, false},
-		{1, 5, 10, 10, 5, false},
-		{1, 15, 10, 10, 10, false},
-		{1, 15, 15, 10, 10, false},
-		{1, 15, 15, 20, 15, false},
-		{1, 20, 15, 20, 15, false},
-		{1, 20, 20, 20, 20, false},
+		{1, 1, 1, 1, 10, 1, false},
+		{1, 1, 1, 5, 10, 1, false},
+		{2, 2, 1, 5, 10, 1, false},
+		{1, 2, 1, 5, 10, 2, false},
+		{1, 5, 1, 1, 10, 1, false},
+		{1, 5, 1, 10, 10, 5, false},
+		{1, 15, 1, 10, 10, 10, false},
+		{1, 15, 1, 15, 10, 10, false},
+		{1, 15, 1, 15, 20, 15, false},
+		{1, 20, 1, 15, 20, 15, false},
+		{1, 20, 1, 20, 20, 20, false},
+
+		// below the base
+		{1, 20, 11, 20, 20, 10, false},
+		{0, 0, 11, 20, 20, 10, false},
+		{1, 10, 11, 20, 20, 0, true},
 	}
 
 	for i, c := range cases {
 		caseString := fmt.Sprintf("test %d failed", i)
-		min, max, err := filterMinMax(c.height, c.min, c.max, c.limit)
+		min, max, err := filterMinMax(c.base, c.height, c.min, c.max, c.limit)
 		if c.wantErr {
 			require.Error(t, err, caseString)
 		} else {
@@ -74,9 +79,11 @@ func TestBlockResults(t *testing.T) {
 		BeginBlock: &abci.ResponseBeginBlock{},
 	}
 
-	stateDB = dbm.NewMemDB()
-	sm.SaveABCIResponses(stateDB, 100, results)
-	blockStore = mockBlockStore{height: 100}
+	env := &Environment{
+		StateDB:    dbm.NewMemDB(),
+		BlockStore: mockBlockStore{base: 50, height: 100},
+	}
+	sm.SaveABCIResponses(env.StateDB, 100, results)
 
 	testCases := []struct {
 		height  int64
@@ -86,6 +93,7 @@ func TestBlockResults(t *testing.T) {
 		{-1, true, nil},
 		{0, true, nil},
 		{101, true, nil},
+		{49, true, nil}, // below the base
 		{100, false, &ctypes.ResultBlockResults{
 			Height:                100,
 			TxsResults:            results.DeliverTxs,
@@ -97,7 +105,7 @@ func TestBlockResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		res, err := BlockResults(&rpctypes.Context{}, &tc.height)
+		res, err := env.BlockResults(&rpctypes.Context{}, &tc.height)
 		if tc.wantErr {
 			assert.Error(t, err)
 		} else {
@@ -108,9 +116,11 @@ func TestBlockResults(t *testing.T) {
 }
 
 type mockBlockStore struct {
+	base   int64
 	height int64
 }
 
+func (store mockBlockStore) Base() int64                                 { return store.base }
 func (store mockBlockStore) Height() int64                               { return store.height }
 func (mockBlockStore) LoadBlockMeta(height int64) *types.BlockMeta       { return nil }
 func (mockBlockStore) LoadBlock(height int64) *types.Block               { return nil }
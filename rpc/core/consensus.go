@@ -14,16 +14,17 @@ import (
 // Note the validators are sorted by their address - this is the canonical
 // order for the validators in the set as used in computing their Merkle root.
 // More: https://docs.tendermint.com/master/rpc/#/Info/validators
-func Validators(ctx *rpctypes.Context, heightPtr *int64, page, perPage int) (*ctypes.ResultValidators, error) {
+func (env *Environment) Validators(ctx *rpctypes.Context, heightPtr *int64, page, perPage int) (
+	*ctypes.ResultValidators, error) {
 	// The latest validator that we know is the
 	// NextValidator of the last block.
-	height := consensusState.GetState().LastBlockHeight + 1
-	height, err := getHeight(height, heightPtr)
+	height := env.Consensus.GetState().LastBlockHeight + 1
+	height, err := env.getHeight(height, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	validators, err := sm.LoadValidators(stateDB, height)
+	validators, err := sm.LoadValidators(env.StateDB, height)
 	if err != nil {
 		return nil, err
 	}
@@ -47,9 +48,9 @@ func Validators(ctx *rpctypes.Context, heightPtr *int64, page, perPage int) (*ct
 // DumpConsensusState dumps consensus state.
 // UNSTABLE
 // More: https://docs.tendermint.com/master/rpc/#/Info/dump_consensus_state
-func DumpConsensusState(ctx *rpctypes.Context) (*ctypes.ResultDumpConsensusState, error) {
+func (env *Environment) DumpConsensusState(ctx *rpctypes.Context) (*ctypes.ResultDumpConsensusState, error) {
 	// Get Peer consensus states.
-	peers := p2pPeers.Peers().List()
+	peers := env.P2PPeers.Peers().List()
 	peerStates := make([]ctypes.PeerStateInfo, len(peers))
 	for i, peer := range peers {
 		peerState, ok := peer.Get(types.PeerStateKey).(*cm.PeerState)
@@ -68,35 +69,42 @@ func DumpConsensusState(ctx *rpctypes.Context) (*ctypes.ResultDumpConsensusState
 		}
 	}
 	// Get self round state.
-	roundState, err := consensusState.GetRoundStateJSON()
+	roundState, err := env.Consensus.GetRoundStateJSON()
+	if err != nil {
+		return nil, err
+	}
+	// Get the statistics of the validators.
+	validatorStats, err := env.Consensus.GetValidatorStatsJSON()
 	if err != nil {
 		return nil, err
 	}
 	return &ctypes.ResultDumpConsensusState{
-		RoundState: roundState,
-		Peers:      peerStates}, nil
+		RoundState:     roundState,
+		Peers:          peerStates,
+		ValidatorStats: validatorStats}, nil
 }
 
 // ConsensusState returns a concise summary of the consensus state.
 // UNSTABLE
 // More: https://docs.tendermint.com/master/rpc/#/Info/consensus_state
-func ConsensusState(ctx *rpctypes.Context) (*ctypes.ResultConsensusState, error) {
+func (env *Environment) ConsensusState(ctx *rpctypes.Context) (*ctypes.ResultConsensusState, error) {
 	// Get self round state.
-	bz, err := consensusState.GetRoundStateSimpleJSON()
+	bz, err := env.Consensus.GetRoundStateSimpleJSON()
 	return &ctypes.ResultConsensusState{RoundState: bz}, err
 }
 
 // ConsensusParams gets the consensus parameters  at the given block height.
 // If no height is provided, it will fetch the current consensus params.
 // More: https://docs.tendermint.com/master/rpc/#/Info/consensus_params
-func ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (*ctypes.ResultConsensusParams, error) {
-	height := consensusState.GetState().LastBlockHeight + 1
-	height, err := getHeight(height, heightPtr)
+func (env *Environment) ConsensusParams(ctx *rpctypes.Context, heightPtr *int64) (
+	*ctypes.ResultConsensusParams, error) {
+	height := env.Consensus.GetState().LastBlockHeight + 1
+	height, err := env.getHeight(height, heightPtr)
 	if err != nil {
 		return nil, err
 	}
 
-	consensusparams, err := sm.LoadConsensusParams(stateDB, height)
+	consensusparams, err := sm.LoadConsensusParams(env.StateDB, height)
 	if err != nil {
 		return nil, err
 	}
@@ -1,23 +1,105 @@
 package core
 
 import (
+	"bytes"
+	"io"
 	"os"
 	"runtime/pprof"
+	"runtime/trace"
+	"sync/atomic"
+	"time"
 
+	"github.com/pkg/errors"
+
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
+	"github.com/tendermint/tendermint/libs/failpoint"
+	"github.com/tendermint/tendermint/libs/log"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
 )
 
 // UnsafeFlushMempool removes all transactions from the mempool.
-func UnsafeFlushMempool(ctx *rpctypes.Context) (*ctypes.ResultUnsafeFlushMempool, error) {
-	mempool.Flush()
+func (env *Environment) UnsafeFlushMempool(ctx *rpctypes.Context) (*ctypes.ResultUnsafeFlushMempool, error) {
+	env.Mempool.Flush()
 	return &ctypes.ResultUnsafeFlushMempool{}, nil
 }
 
+// UnsafeReloadConfig re-reads the node's config file and applies the fields
+// which can be changed without a restart. The result lists the changed fields
+// which were applied and those which require a restart.
+func (env *Environment) UnsafeReloadConfig(ctx *rpctypes.Context) (*ctypes.ResultReloadConfig, error) {
+	if env.ConfigReloader == nil {
+		return nil, tmerrors.New(tmerrors.CodeNotSupported, "config reloading is not supported by this node")
+	}
+	res, err := env.ConfigReloader()
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultReloadConfig{
+		Applied:         res.Applied,
+		RestartRequired: res.RestartRequired,
+	}, nil
+}
+
+// UnsafeSetConfig sets the config field with the given key (e.g.
+// "mempool.cache_size") to value and applies it to the running node. Only
+// fields which can be reloaded are accepted. The change is recorded in the
+// node's config journal, from which it can be written to the config file
+// with "tendermint config apply-journal".
+func (env *Environment) UnsafeSetConfig(ctx *rpctypes.Context, key, value string) (*ctypes.ResultReloadConfig, error) {
+	if env.ConfigFieldSetter == nil {
+		return nil, tmerrors.New(tmerrors.CodeNotSupported, "changing the config is not supported by this node")
+	}
+	res, err := env.ConfigFieldSetter(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultReloadConfig{
+		Applied:         res.Applied,
+		RestartRequired: res.RestartRequired,
+	}, nil
+}
+
+// UnsafeSetLogLevel sets the log level of the given module (e.g. "p2p"), or
+// of all other modules if module is "*", without restarting the node. Level
+// must be one of "debug", "info", "error" or "none". Like UnsafeSetConfig, the
+// change is recorded in the config journal.
+func (env *Environment) UnsafeSetLogLevel(ctx *rpctypes.Context, module, level string) (
+	*ctypes.ResultReloadConfig, error) {
+	if env.LogLevelSetter == nil {
+		return nil, tmerrors.New(tmerrors.CodeNotSupported, "changing the log level is not supported by this node")
+	}
+	res, err := env.LogLevelSetter(module, level)
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultReloadConfig{
+		Applied:         res.Applied,
+		RestartRequired: res.RestartRequired,
+	}, nil
+}
+
+// UnsafeSetFailpoint activates the failpoint name with term (e.g. "exit" or
+// "2*error(disk full)"), or deactivates it if term is "off". It is only
+// available if the node is built with the failpoints tag. The result lists the
+// active failpoints.
+func (env *Environment) UnsafeSetFailpoint(ctx *rpctypes.Context, name, term string) (*ctypes.ResultFailpoints, error) {
+	if err := failpoint.Enable(name, term); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultFailpoints{Failpoints: failpoint.List()}, nil
+}
+
+// UnsafeFailpoints lists the active failpoints.
+func (env *Environment) UnsafeFailpoints(ctx *rpctypes.Context) (*ctypes.ResultFailpoints, error) {
+	return &ctypes.ResultFailpoints{Failpoints: failpoint.List()}, nil
+}
+
 var profFile *os.File
 
 // UnsafeStartCPUProfiler starts a pprof profiler using the given filename.
-func UnsafeStartCPUProfiler(ctx *rpctypes.Context, filename string) (*ctypes.ResultUnsafeProfile, error) {
+func (env *Environment) UnsafeStartCPUProfiler(ctx *rpctypes.Context, filename string) (
+	*ctypes.ResultUnsafeProfile, error) {
 	var err error
 	profFile, err = os.Create(filename)
 	if err != nil {
@@ -31,7 +113,7 @@ func UnsafeStartCPUProfiler(ctx *rpctypes.Context, filename string) (*ctypes.Res
 }
 
 // UnsafeStopCPUProfiler stops the running pprof profiler.
-func UnsafeStopCPUProfiler(ctx *rpctypes.Context) (*ctypes.ResultUnsafeProfile, error) {
+func (env *Environment) UnsafeStopCPUProfiler(ctx *rpctypes.Context) (*ctypes.ResultUnsafeProfile, error) {
 	pprof.StopCPUProfile()
 	if err := profFile.Close(); err != nil {
 		return nil, err
@@ -40,7 +122,8 @@ func UnsafeStopCPUProfiler(ctx *rpctypes.Context) (*ctypes.ResultUnsafeProfile,
 }
 
 // UnsafeWriteHeapProfile dumps a heap profile to the given filename.
-func UnsafeWriteHeapProfile(ctx *rpctypes.Context, filename string) (*ctypes.ResultUnsafeProfile, error) {
+func (env *Environment) UnsafeWriteHeapProfile(ctx *rpctypes.Context, filename string) (
+	*ctypes.ResultUnsafeProfile, error) {
 	memProfFile, err := os.Create(filename)
 	if err != nil {
 		return nil, err
@@ -54,3 +137,100 @@ func UnsafeWriteHeapProfile(ctx *rpctypes.Context, filename string) (*ctypes.Res
 
 	return &ctypes.ResultUnsafeProfile{}, nil
 }
+
+// MaxProfileDuration is the maximum duration of a CPU profile or execution
+// trace captured with UnsafeProfile.
+const MaxProfileDuration = 5 * time.Minute
+
+// set while a CPU profile or execution trace is being captured, since only
+// one can be captured at a time
+var profiling uint32
+
+// UnsafeProfile captures a profile of the given kind: "cpu" or "trace" for a
+// CPU profile or execution trace over the given number of seconds (at most
+// MaxProfileDuration), or one of the runtime/pprof profiles ("heap",
+// "goroutine", "allocs", "block", "mutex", "threadcreate") as a snapshot.
+//
+// CPU profiles and traces are written to filename in the background and the
+// call returns immediately. Snapshots are written to filename or, if it is
+// empty, returned in the result. To stream CPU profiles and traces back, use
+// the profiling server (prof_laddr) instead.
+//
+// The node only serves it if rpc.admin_auth_token is set.
+func (env *Environment) UnsafeProfile(ctx *rpctypes.Context, profile string, seconds int, filename string) (
+	*ctypes.ResultProfile, error) {
+	switch profile {
+	case "cpu", "trace":
+		return env.captureProfile(profile, seconds, filename)
+	}
+
+	p := pprof.Lookup(profile)
+	if p == nil {
+		return nil, errors.Errorf("unknown profile %q", profile)
+	}
+	if filename == "" {
+		var buf bytes.Buffer
+		if err := p.WriteTo(&buf, 0); err != nil {
+			return nil, err
+		}
+		return &ctypes.ResultProfile{Profile: profile, Data: buf.Bytes()}, nil
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.WriteTo(f, 0); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultProfile{Profile: profile, Filename: filename}, nil
+}
+
+func (env *Environment) captureProfile(profile string, seconds int, filename string) (*ctypes.ResultProfile, error) {
+	if filename == "" {
+		return nil, errors.Errorf("a filename is required for %s profiles", profile)
+	}
+	duration := time.Duration(seconds) * time.Second
+	if duration <= 0 || duration > MaxProfileDuration {
+		return nil, errors.Errorf("seconds must be between 1 and %d", int(MaxProfileDuration.Seconds()))
+	}
+
+	start, stop := pprof.StartCPUProfile, pprof.StopCPUProfile
+	if profile == "trace" {
+		start, stop = trace.Start, trace.Stop
+	}
+
+	if !atomic.CompareAndSwapUint32(&profiling, 0, 1) {
+		return nil, tmerrors.New(tmerrors.CodeUnavailable, "a CPU profile or trace is already being captured")
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		atomic.StoreUint32(&profiling, 0)
+		return nil, err
+	}
+	if err := start(f); err != nil {
+		f.Close()
+		atomic.StoreUint32(&profiling, 0)
+		return nil, err
+	}
+
+	go func(f io.Closer, logger log.Logger) {
+		defer atomic.StoreUint32(&profiling, 0)
+		time.Sleep(duration)
+		stop()
+		if err := f.Close(); err != nil {
+			logger.Error("Failed to close profile", "file", filename, "err", err)
+			return
+		}
+		logger.Info("Captured profile", "profile", profile, "file", filename)
+	}(f, env.Logger)
+
+	return &ctypes.ResultProfile{
+		Profile:  profile,
+		Filename: filename,
+		Until:    time.Now().Add(duration),
+	}, nil
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+)
+
+func TestUnsafeProfile(t *testing.T) {
+	env := &Environment{Logger: log.TestingLogger()}
+
+	dir, err := ioutil.TempDir("", "rpc-core-profile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// snapshots are returned inline or written to a file
+	res, err := env.UnsafeProfile(&rpctypes.Context{}, "goroutine", 0, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.Data)
+
+	heapFile := filepath.Join(dir, "heap.out")
+	res, err = env.UnsafeProfile(&rpctypes.Context{}, "heap", 0, heapFile)
+	require.NoError(t, err)
+	assert.Equal(t, heapFile, res.Filename)
+	assert.FileExists(t, heapFile)
+
+	_, err = env.UnsafeProfile(&rpctypes.Context{}, "unknown", 0, "")
+	assert.Error(t, err)
+
+	// CPU profiles need a file and a bounded duration
+	_, err = env.UnsafeProfile(&rpctypes.Context{}, "cpu", 1, "")
+	assert.Error(t, err)
+	_, err = env.UnsafeProfile(&rpctypes.Context{}, "cpu", 0, filepath.Join(dir, "cpu.out"))
+	assert.Error(t, err)
+	_, err = env.UnsafeProfile(&rpctypes.Context{}, "cpu", int(MaxProfileDuration.Seconds())+1,
+		filepath.Join(dir, "cpu.out"))
+	assert.Error(t, err)
+
+	traceFile := filepath.Join(dir, "trace.out")
+	res, err = env.UnsafeProfile(&rpctypes.Context{}, "trace", 1, traceFile)
+	require.NoError(t, err)
+	assert.True(t, res.Until.After(time.Now()))
+
+	// only one capture at a time
+	_, err = env.UnsafeProfile(&rpctypes.Context{}, "cpu", 1, filepath.Join(dir, "cpu.out"))
+	assert.Error(t, err)
+
+	time.Sleep(time.Until(res.Until) + 500*time.Millisecond)
+	info, err := os.Stat(traceFile)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+}
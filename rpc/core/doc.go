@@ -25,6 +25,15 @@ CORS (Cross-Origin Resource Sharing) can be enabled by setting
 Arguments which expect strings or byte arrays may be passed as quoted strings,
 like `"abc"` or as `0x`-prefixed strings, like `0x616263`.
 
+## Errors
+
+Errors of a class known to the node are server errors whose code is -32000
+minus the class: -32002 for invalid arguments, -32003 for something not found
+(e.g. a height above the latest one), -32004 for timeouts, -32005 when the
+node is unavailable, -32006 when a limit is reached and -32007 for disabled
+features. The message is the name of the class and the data the description
+of the error. Other errors are internal errors (-32603). See libs/errors.
+
 ## URI/HTTP
 
 ```bash
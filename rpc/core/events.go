@@ -6,60 +6,63 @@ import (
 
 	"github.com/pkg/errors"
 
+	cfg "github.com/tendermint/tendermint/config"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	"github.com/tendermint/tendermint/types"
 )
 
 // Subscribe for events via WebSocket.
 // More: https://docs.tendermint.com/master/rpc/#/Websocket/subscribe
-func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
+func (env *Environment) Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, error) {
 	addr := ctx.RemoteAddr()
-
-	if eventBus.NumClients() >= config.MaxSubscriptionClients {
-		return nil, fmt.Errorf("max_subscription_clients %d reached", config.MaxSubscriptionClients)
-	} else if eventBus.NumClientSubscriptions(addr) >= config.MaxSubscriptionsPerClient {
-		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", config.MaxSubscriptionsPerClient)
-	}
-
-	logger.Info("Subscribe to query", "remote", addr, "query", query)
-
-	q, err := tmquery.New(query)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to parse query")
-	}
-
-	subCtx, cancel := context.WithTimeout(ctx.Context(), SubscribeTimeout)
-	defer cancel()
-
-	sub, err := eventBus.Subscribe(subCtx, addr, q)
+	sub, err := env.SubscribeEvents(ctx.Context(), addr, query)
 	if err != nil {
 		return nil, err
 	}
 
 	// Capture the current ID, since it can change in the future.
 	subscriptionID := ctx.JSONReq.ID
+	notifyDropped := env.NotifyDroppedEvents()
 	go func() {
+		var dropped int64 // events dropped so far, reported to the client
 		for {
 			select {
 			case msg := <-sub.Out():
 				resultEvent := &ctypes.ResultEvent{Query: query, Data: msg.Data(), Events: msg.Events()}
-				ctx.WSConn.TryWriteRPCResponse(
+				if n := sub.Dropped(); notifyDropped && n > dropped {
+					resultEvent.Dropped = n - dropped
+					dropped = n
+				}
+				// Block until the response is buffered, so that the events of a
+				// slow client are buffered by its subscription, whose buffer
+				// applies the slow_client_policy once full.
+				ctx.WSConn.WriteRPCResponse(
 					rpctypes.NewRPCSuccessResponse(
 						ctx.WSConn.Codec(),
 						subscriptionID,
 						resultEvent,
 					))
 			case <-sub.Cancelled():
-				if sub.Err() != tmpubsub.ErrUnsubscribed {
-					var reason string
-					if sub.Err() == nil {
-						reason = "Tendermint exited"
-					} else {
-						reason = sub.Err().Error()
+				switch err := sub.Err(); err {
+				case tmpubsub.ErrUnsubscribed:
+				case tmpubsub.ErrOutOfCapacity:
+					env.Logger.Info("Cancelled the subscription of a slow client", "remote", addr, "query", query)
+					ctx.WSConn.WriteRPCResponse(
+						rpctypes.RPCErrorFromError(
+							subscriptionID,
+							tmerrors.Errorf(tmerrors.CodeResourceExhausted,
+								"subscription was cancelled (reason: %v)", err),
+						))
+				default:
+					reason := "Tendermint exited"
+					if err != nil {
+						reason = err.Error()
 					}
-					ctx.WSConn.TryWriteRPCResponse(
+					ctx.WSConn.WriteRPCResponse(
 						rpctypes.RPCServerError(
 							subscriptionID,
 							fmt.Errorf("subscription was cancelled (reason: %s)", reason),
@@ -73,16 +76,64 @@ func Subscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultSubscribe, er
 	return &ctypes.ResultSubscribe{}, nil
 }
 
+// SubscribeEvents subscribes the subscriber to the events matching the query,
+// within the subscription limits of the RPC config, with its buffer size and
+// slow client policy. It's for the APIs which
+// deliver the events themselves, like gRPC: they must unsubscribe with
+// UnsubscribeEvents once done.
+func (env *Environment) SubscribeEvents(ctx context.Context, subscriber, query string) (types.Subscription, error) {
+	rpcConfig := env.getConfig()
+	if env.EventBus.NumClients() >= rpcConfig.MaxSubscriptionClients {
+		return nil, tmerrors.Errorf(tmerrors.CodeResourceExhausted,
+			"max_subscription_clients %d reached", rpcConfig.MaxSubscriptionClients)
+	} else if env.EventBus.NumClientSubscriptions(subscriber) >= rpcConfig.MaxSubscriptionsPerClient {
+		return nil, tmerrors.Errorf(tmerrors.CodeResourceExhausted,
+			"max_subscriptions_per_client %d reached", rpcConfig.MaxSubscriptionsPerClient)
+	}
+
+	env.Logger.Info("Subscribe to query", "remote", subscriber, "query", query)
+
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse query")
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, SubscribeTimeout)
+	defer cancel()
+
+	policy := tmpubsub.OverflowDropOldest
+	if rpcConfig.SlowClientPolicy == cfg.SlowClientPolicyClose {
+		policy = tmpubsub.OverflowTerminate
+	}
+	return env.EventBus.SubscribeWithPolicy(subCtx, subscriber, q, rpcConfig.SubscriptionBufferSize, policy)
+}
+
+// NotifyDroppedEvents returns whether the clients must be told how many events
+// they missed along with the next event, with the "notify" slow_client_policy.
+func (env *Environment) NotifyDroppedEvents() bool {
+	return env.getConfig().SlowClientPolicy == cfg.SlowClientPolicyNotify
+}
+
+// UnsubscribeEvents cancels the subscription of SubscribeEvents.
+func (env *Environment) UnsubscribeEvents(subscriber, query string) error {
+	env.Logger.Info("Unsubscribe from query", "remote", subscriber, "query", query)
+	q, err := tmquery.New(query)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse query")
+	}
+	return env.EventBus.Unsubscribe(context.Background(), subscriber, q)
+}
+
 // Unsubscribe from events via WebSocket.
 // More: https://docs.tendermint.com/master/rpc/#/Websocket/unsubscribe
-func Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
+func (env *Environment) Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe, error) {
 	addr := ctx.RemoteAddr()
-	logger.Info("Unsubscribe from query", "remote", addr, "query", query)
+	env.Logger.Info("Unsubscribe from query", "remote", addr, "query", query)
 	q, err := tmquery.New(query)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse query")
 	}
-	err = eventBus.Unsubscribe(context.Background(), addr, q)
+	err = env.EventBus.Unsubscribe(context.Background(), addr, q)
 	if err != nil {
 		return nil, err
 	}
@@ -91,10 +142,10 @@ func Unsubscribe(ctx *rpctypes.Context, query string) (*ctypes.ResultUnsubscribe
 
 // UnsubscribeAll from all events via WebSocket.
 // More: https://docs.tendermint.com/master/rpc/#/Websocket/unsubscribe_all
-func UnsubscribeAll(ctx *rpctypes.Context) (*ctypes.ResultUnsubscribe, error) {
+func (env *Environment) UnsubscribeAll(ctx *rpctypes.Context) (*ctypes.ResultUnsubscribe, error) {
 	addr := ctx.RemoteAddr()
-	logger.Info("Unsubscribe from all", "remote", addr)
-	err := eventBus.UnsubscribeAll(context.Background(), addr)
+	env.Logger.Info("Unsubscribe from all", "remote", addr)
+	err := env.EventBus.UnsubscribeAll(context.Background(), addr)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	amino "github.com/tendermint/go-amino"
+
+	cfg "github.com/tendermint/tendermint/config"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
+	"github.com/tendermint/tendermint/libs/log"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// slowWSConn is a websocket connection whose client reads a response only
+// when told to: WriteRPCResponse blocks until release.
+type slowWSConn struct {
+	cdc       *amino.Codec
+	responses chan rpctypes.RPCResponse
+	release   chan struct{}
+}
+
+var _ rpctypes.WSRPCConnection = (*slowWSConn)(nil)
+
+func newSlowWSConn() *slowWSConn {
+	cdc := amino.NewCodec()
+	ctypes.RegisterAmino(cdc)
+	return &slowWSConn{
+		cdc:       cdc,
+		responses: make(chan rpctypes.RPCResponse, 1),
+		release:   make(chan struct{}),
+	}
+}
+
+func (c *slowWSConn) GetRemoteAddr() string { return "slow" }
+func (c *slowWSConn) WriteRPCResponse(resp rpctypes.RPCResponse) {
+	c.responses <- resp
+	<-c.release
+}
+func (c *slowWSConn) TryWriteRPCResponse(resp rpctypes.RPCResponse) bool {
+	select {
+	case c.responses <- resp:
+		return true
+	default:
+		return false
+	}
+}
+func (c *slowWSConn) Codec() *amino.Codec        { return c.cdc }
+func (c *slowWSConn) Context() context.Context   { return context.Background() }
+func (c *slowWSConn) next() rpctypes.RPCResponse { c.release <- struct{}{}; return <-c.responses }
+
+func TestSubscribeSlowClientPolicy(t *testing.T) {
+	for _, policy := range []string{cfg.SlowClientPolicyClose, cfg.SlowClientPolicyDrop, cfg.SlowClientPolicyNotify} {
+		policy := policy
+		t.Run(policy, func(t *testing.T) {
+			// the publishers wait for the event bus to take their events
+			eb := types.NewEventBusWithBufferCapacity(0)
+			require.NoError(t, eb.Start())
+			defer eb.Stop()
+			env := &Environment{EventBus: eb, Logger: log.TestingLogger()}
+
+			rpcConfig := cfg.DefaultRPCConfig()
+			rpcConfig.SubscriptionBufferSize = 1
+			rpcConfig.SlowClientPolicy = policy
+			env.SetConfig(*rpcConfig)
+
+			// once probe gets its event, the previous events are delivered
+			probe, err := eb.Subscribe(context.Background(), "probe", types.EventQueryNewRound, 1)
+			require.NoError(t, err)
+			sync := func() {
+				require.NoError(t, eb.PublishEventNewRound(types.EventDataNewRound{}))
+				<-probe.Out()
+			}
+
+			conn := newSlowWSConn()
+			defer close(conn.release)
+			ctx := &rpctypes.Context{JSONReq: &rpctypes.RPCRequest{ID: rpctypes.JSONRPCIntID(1)}, WSConn: conn}
+			_, err = env.Subscribe(ctx, types.EventQueryPolka.String())
+			require.NoError(t, err)
+
+			// the client doesn't read the first event, nor the 3 next, which
+			// overflow the buffer of the subscription
+			for i := 1; i <= 4; i++ {
+				require.NoError(t, eb.PublishEventPolka(types.EventDataRoundState{Round: i}))
+				if i == 1 {
+					<-conn.responses
+				}
+			}
+			sync()
+
+			resp := conn.next()
+			if policy == cfg.SlowClientPolicyClose {
+				// the buffered event may come before the error
+				if resp.Error == nil {
+					resp = conn.next()
+				}
+				require.NotNil(t, resp.Error)
+				assert.Equal(t, tmerrors.CodeResourceExhausted, resp.Error.ErrorCode())
+				assert.Contains(t, resp.Error.Data, "not pulling messages fast enough")
+				return
+			}
+
+			require.Nil(t, resp.Error)
+			var event ctypes.ResultEvent
+			require.NoError(t, conn.cdc.UnmarshalJSON(resp.Result, &event))
+			assert.Equal(t, 4, event.Data.(types.EventDataRoundState).Round)
+			if policy == cfg.SlowClientPolicyNotify {
+				assert.EqualValues(t, 2, event.Dropped)
+			} else {
+				assert.Zero(t, event.Dropped)
+			}
+		})
+	}
+}
@@ -8,8 +8,9 @@ import (
 
 // BroadcastEvidence broadcasts evidence of the misbehavior.
 // More: https://docs.tendermint.com/master/rpc/#/Info/broadcast_evidence
-func BroadcastEvidence(ctx *rpctypes.Context, ev types.Evidence) (*ctypes.ResultBroadcastEvidence, error) {
-	err := evidencePool.AddEvidence(ev)
+func (env *Environment) BroadcastEvidence(ctx *rpctypes.Context, ev types.Evidence) (
+	*ctypes.ResultBroadcastEvidence, error) {
+	err := env.EvidencePool.AddEvidence(ev)
 	if err != nil {
 		return nil, err
 	}
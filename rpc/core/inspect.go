@@ -0,0 +1,76 @@
+package core
+
+import (
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpc "github.com/tendermint/tendermint/rpc/lib/server"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+	sm "github.com/tendermint/tendermint/state"
+)
+
+// InspectRoutes returns the routes served by `tendermint inspect` from the data
+// directory of a stopped node: the routes which only need the block store, the
+// state and the tx index, and InspectStatus instead of Status.
+//
+// They need the state DB, the block store, the tx indexer, the genesis doc and
+// a Consensus returning the state of the state DB.
+func (env *Environment) InspectRoutes() map[string]*rpc.RPCFunc {
+	return map[string]*rpc.RPCFunc{
+		"status":           rpc.NewRPCFunc(env.InspectStatus, ""),
+		"blockchain":       rpc.NewRPCFunc(env.BlockchainInfo, "minHeight,maxHeight"),
+		"genesis":          rpc.NewRPCFunc(env.Genesis, ""),
+		"block":            rpc.NewRPCFunc(env.Block, "height"),
+		"block_by_hash":    rpc.NewRPCFunc(env.BlockByHash, "hash"),
+		"block_results":    rpc.NewRPCFunc(env.BlockResults, "height"),
+		"commit":           rpc.NewRPCFunc(env.Commit, "height"),
+		"tx":               rpc.NewRPCFunc(env.Tx, "hash,prove"),
+		"tx_search":        rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by"),
+		"block_search":     rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by"),
+		"validators":       rpc.NewRPCFunc(env.Validators, "height,page,per_page"),
+		"consensus_params": rpc.NewRPCFunc(env.ConsensusParams, "height"),
+	}
+}
+
+// InspectStatus returns the latest blocks of the block store and of the state
+// of a stopped node.
+//
+// ```shell
+// curl 'localhost:26657/status'
+// ```
+//
+// > The above command returns JSON structured like this:
+//
+// ```json
+// {
+// 	"jsonrpc": "2.0",
+// 	"id": "",
+// 	"result": {
+// 		"chain_id": "test-chain-I8SHY7",
+// 		"latest_block_hash": "1D3EAB1B80D9A2EEE8E4B1D1A0D8A0F7AC35897D6AF9DFC1FA727A2350C7B6E3",
+// 		"latest_app_hash": "0000000000000000",
+// 		"latest_block_height": "231",
+// 		"latest_block_time": "2019-03-05T16:36:37.034008204Z",
+// 		"state_height": "230",
+// 		"state_app_hash": "0000000000000000"
+// 	}
+// }
+// ```
+func (env *Environment) InspectStatus(ctx *rpctypes.Context) (*ctypes.ResultInspectStatus, error) {
+	state := sm.LoadState(env.StateDB)
+	result := &ctypes.ResultInspectStatus{
+		ChainID:           state.ChainID,
+		LatestBlockHeight: env.BlockStore.Height(),
+		StateHeight:       state.LastBlockHeight,
+		StateAppHash:      state.AppHash,
+	}
+	if env.GenDoc != nil && result.ChainID == "" {
+		result.ChainID = env.GenDoc.ChainID
+	}
+
+	if meta := env.BlockStore.LoadBlockMeta(result.LatestBlockHeight); meta != nil {
+		result.LatestBlockHash = meta.BlockID.Hash
+		result.LatestAppHash = meta.Header.AppHash
+		result.LatestBlockTime = meta.Header.Time
+	}
+
+	return result, nil
+}
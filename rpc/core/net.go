@@ -2,9 +2,11 @@ package core
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	"github.com/tendermint/tendermint/p2p"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
@@ -12,8 +14,8 @@ import (
 
 // NetInfo returns network info.
 // More: https://docs.tendermint.com/master/rpc/#/Info/net_info
-func NetInfo(ctx *rpctypes.Context) (*ctypes.ResultNetInfo, error) {
-	peersList := p2pPeers.Peers().List()
+func (env *Environment) NetInfo(ctx *rpctypes.Context) (*ctypes.ResultNetInfo, error) {
+	peersList := env.P2PPeers.Peers().List()
 	peers := make([]ctypes.Peer, 0, len(peersList))
 	for _, peer := range peersList {
 		nodeInfo, ok := peer.NodeInfo().(p2p.DefaultNodeInfo)
@@ -31,20 +33,20 @@ func NetInfo(ctx *rpctypes.Context) (*ctypes.ResultNetInfo, error) {
 	// PRO: useful info
 	// CON: privacy
 	return &ctypes.ResultNetInfo{
-		Listening: p2pTransport.IsListening(),
-		Listeners: p2pTransport.Listeners(),
+		Listening: env.P2PTransport.IsListening(),
+		Listeners: env.P2PTransport.Listeners(),
 		NPeers:    len(peers),
 		Peers:     peers,
 	}, nil
 }
 
 // UnsafeDialSeeds dials the given seeds (comma-separated id@IP:PORT).
-func UnsafeDialSeeds(ctx *rpctypes.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
+func (env *Environment) UnsafeDialSeeds(ctx *rpctypes.Context, seeds []string) (*ctypes.ResultDialSeeds, error) {
 	if len(seeds) == 0 {
 		return &ctypes.ResultDialSeeds{}, errors.New("no seeds provided")
 	}
-	logger.Info("DialSeeds", "seeds", seeds)
-	if err := p2pPeers.DialPeersAsync(seeds); err != nil {
+	env.Logger.Info("DialSeeds", "seeds", seeds)
+	if err := env.P2PPeers.DialPeersAsync(seeds); err != nil {
 		return &ctypes.ResultDialSeeds{}, err
 	}
 	return &ctypes.ResultDialSeeds{Log: "Dialing seeds in progress. See /net_info for details"}, nil
@@ -52,24 +54,65 @@ func UnsafeDialSeeds(ctx *rpctypes.Context, seeds []string) (*ctypes.ResultDialS
 
 // UnsafeDialPeers dials the given peers (comma-separated id@IP:PORT),
 // optionally making them persistent.
-func UnsafeDialPeers(ctx *rpctypes.Context, peers []string, persistent bool) (*ctypes.ResultDialPeers, error) {
+func (env *Environment) UnsafeDialPeers(ctx *rpctypes.Context, peers []string, persistent bool) (
+	*ctypes.ResultDialPeers, error) {
 	if len(peers) == 0 {
 		return &ctypes.ResultDialPeers{}, errors.New("no peers provided")
 	}
-	logger.Info("DialPeers", "peers", peers, "persistent", persistent)
+	env.Logger.Info("DialPeers", "peers", peers, "persistent", persistent)
 	if persistent {
-		if err := p2pPeers.AddPersistentPeers(peers); err != nil {
+		if err := env.P2PPeers.AddPersistentPeers(peers); err != nil {
 			return &ctypes.ResultDialPeers{}, err
 		}
 	}
-	if err := p2pPeers.DialPeersAsync(peers); err != nil {
+	if err := env.P2PPeers.DialPeersAsync(peers); err != nil {
 		return &ctypes.ResultDialPeers{}, err
 	}
 	return &ctypes.ResultDialPeers{Log: "Dialing peers in progress. See /net_info for details"}, nil
 }
 
+// UnsafeBanPeer bans the peer with the given ID for the given number of
+// seconds, or p2p.ban_duration if 0, and disconnects from it. The node neither
+// accepts nor dials the peer until the ban expires, or is lifted with
+// UnsafeUnbanPeer, and the ban outlives restarts.
+func (env *Environment) UnsafeBanPeer(ctx *rpctypes.Context, id string, duration int64, reason string) (
+	*ctypes.ResultBanPeer, error) {
+	if duration < 0 {
+		return nil, tmerrors.New(tmerrors.CodeInvalidArgument, "duration can't be negative")
+	}
+	env.Logger.Info("BanPeer", "id", id, "duration", duration, "reason", reason)
+	ban, err := env.P2PPeers.BanPeer(p2p.ID(id), time.Duration(duration)*time.Second, reason)
+	if ban.ID == "" {
+		return nil, tmerrors.Wrap(err, tmerrors.CodeInvalidArgument, "can't ban peer")
+	}
+	if err != nil {
+		// the peer is banned until the node restarts
+		return nil, tmerrors.Wrap(err, tmerrors.CodeInternal, "can't save the ban")
+	}
+	return &ctypes.ResultBanPeer{Ban: ban}, nil
+}
+
+// UnsafeUnbanPeer lifts the ban of the peer with the given ID.
+func (env *Environment) UnsafeUnbanPeer(ctx *rpctypes.Context, id string) (*ctypes.ResultUnbanPeer, error) {
+	env.Logger.Info("UnbanPeer", "id", id)
+	unbanned, err := env.P2PPeers.UnbanPeer(p2p.ID(id))
+	if err != nil {
+		return nil, tmerrors.Wrap(err, tmerrors.CodeInternal, "can't save the unban")
+	}
+	if !unbanned {
+		return nil, tmerrors.Errorf(tmerrors.CodeNotFound, "peer %s is not banned", id)
+	}
+	return &ctypes.ResultUnbanPeer{ID: p2p.ID(id)}, nil
+}
+
+// UnsafeBannedPeers returns the bans of peers in effect, the earliest to
+// expire first.
+func (env *Environment) UnsafeBannedPeers(ctx *rpctypes.Context) (*ctypes.ResultBannedPeers, error) {
+	return &ctypes.ResultBannedPeers{Bans: env.P2PPeers.BannedPeers()}, nil
+}
+
 // Genesis returns genesis file.
 // More: https://docs.tendermint.com/master/rpc/#/Info/genesis
-func Genesis(ctx *rpctypes.Context) (*ctypes.ResultGenesis, error) {
-	return &ctypes.ResultGenesis{Genesis: genDoc}, nil
+func (env *Environment) Genesis(ctx *rpctypes.Context) (*ctypes.ResultGenesis, error) {
+	return &ctypes.ResultGenesis{Genesis: env.GenDoc}, nil
 }
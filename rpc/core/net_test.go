@@ -2,6 +2,7 @@ package core
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,8 +20,7 @@ func TestUnsafeDialSeeds(t *testing.T) {
 	require.NoError(t, err)
 	defer sw.Stop()
 
-	logger = log.TestingLogger()
-	p2pPeers = sw
+	env := &Environment{P2PPeers: sw, Logger: log.TestingLogger()}
 
 	testCases := []struct {
 		seeds []string
@@ -32,7 +32,7 @@ func TestUnsafeDialSeeds(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		res, err := UnsafeDialSeeds(&rpctypes.Context{}, tc.seeds)
+		res, err := env.UnsafeDialSeeds(&rpctypes.Context{}, tc.seeds)
 		if tc.isErr {
 			assert.Error(t, err)
 		} else {
@@ -49,8 +49,7 @@ func TestUnsafeDialPeers(t *testing.T) {
 	require.NoError(t, err)
 	defer sw.Stop()
 
-	logger = log.TestingLogger()
-	p2pPeers = sw
+	env := &Environment{P2PPeers: sw, Logger: log.TestingLogger()}
 
 	testCases := []struct {
 		peers []string
@@ -62,7 +61,7 @@ func TestUnsafeDialPeers(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		res, err := UnsafeDialPeers(&rpctypes.Context{}, tc.peers, false)
+		res, err := env.UnsafeDialPeers(&rpctypes.Context{}, tc.peers, false)
 		if tc.isErr {
 			assert.Error(t, err)
 		} else {
@@ -71,3 +70,34 @@ func TestUnsafeDialPeers(t *testing.T) {
 		}
 	}
 }
+
+func TestUnsafeBanPeer(t *testing.T) {
+	sw := p2p.MakeSwitch(cfg.DefaultP2PConfig(), 1, "testing", "123.123.123",
+		func(n int, sw *p2p.Switch) *p2p.Switch { return sw })
+	err := sw.Start()
+	require.NoError(t, err)
+	defer sw.Stop()
+
+	env := &Environment{P2PPeers: sw, Logger: log.TestingLogger()}
+
+	id := "d51fb70907db1c6c2d5237e78379b25cf1a37ab4"
+	_, err = env.UnsafeBanPeer(&rpctypes.Context{}, "127.0.0.1", 60, "")
+	assert.Error(t, err)
+	_, err = env.UnsafeBanPeer(&rpctypes.Context{}, id, -1, "")
+	assert.Error(t, err)
+
+	res, err := env.UnsafeBanPeer(&rpctypes.Context{}, id, 60, "spam")
+	require.NoError(t, err)
+	assert.EqualValues(t, id, res.Ban.ID)
+	assert.Equal(t, "spam", res.Ban.Reason)
+	assert.Equal(t, time.Minute, res.Ban.Expires.Sub(res.Ban.BannedAt))
+
+	banned, err := env.UnsafeBannedPeers(&rpctypes.Context{})
+	require.NoError(t, err)
+	assert.Equal(t, []p2p.Ban{res.Ban}, banned.Bans)
+
+	_, err = env.UnsafeUnbanPeer(&rpctypes.Context{}, id)
+	require.NoError(t, err)
+	_, err = env.UnsafeUnbanPeer(&rpctypes.Context{}, id)
+	assert.Error(t, err)
+}
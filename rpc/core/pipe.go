@@ -2,8 +2,10 @@ package core
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
+	bcv0 "github.com/tendermint/tendermint/blockchain/v0"
 	cfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/consensus"
 	"github.com/tendermint/tendermint/crypto"
@@ -36,6 +38,7 @@ type Consensus interface {
 	GetLastHeight() int64
 	GetRoundStateJSON() ([]byte, error)
 	GetRoundStateSimpleJSON() ([]byte, error)
+	GetValidatorStatsJSON() ([]byte, error)
 }
 
 type transport interface {
@@ -44,100 +47,72 @@ type transport interface {
 	NodeInfo() p2p.NodeInfo
 }
 
+type fastSyncReactor interface {
+	SyncProgress() (bcv0.SyncProgress, bool)
+}
+
 type peers interface {
 	AddPersistentPeers([]string) error
 	DialPeersAsync([]string) error
 	Peers() p2p.IPeerSet
+	BanPeer(id p2p.ID, duration time.Duration, reason string) (p2p.Ban, error)
+	UnbanPeer(id p2p.ID) (bool, error)
+	BannedPeers() []p2p.Ban
 }
 
 //----------------------------------------------
-// These package level globals come with setters
-// that are expected to be called only once, on startup
 
-var (
+// Environment contains the objects and interfaces the RPC functions are
+// served from, set up once by the node on startup. Each node of a process has
+// its own, so that several nodes can serve RPC side by side (see
+// node.MultiNode).
+type Environment struct {
 	// external, thread safe interfaces
-	proxyAppQuery proxy.AppConnQuery
+	ProxyAppQuery    proxy.AppConnQuery
+	ProxyAppSnapshot proxy.AppConnSnapshot
 
 	// interfaces defined in types and above
-	stateDB        dbm.DB
-	blockStore     sm.BlockStore
-	evidencePool   sm.EvidencePool
-	consensusState Consensus
-	p2pPeers       peers
-	p2pTransport   transport
+	StateDB      dbm.DB
+	BlockStore   sm.BlockStore
+	EvidencePool sm.EvidencePool
+	Consensus    Consensus
+	P2PPeers     peers
+	P2PTransport transport
 
 	// objects
-	pubKey           crypto.PubKey
-	genDoc           *types.GenesisDoc // cache the genesis structure
-	txIndexer        txindex.TxIndexer
-	consensusReactor *consensus.Reactor
-	eventBus         *types.EventBus // thread safe
-	mempool          mempl.Mempool
-
-	logger log.Logger
-
-	config cfg.RPCConfig
-)
-
-func SetStateDB(db dbm.DB) {
-	stateDB = db
-}
-
-func SetBlockStore(bs sm.BlockStore) {
-	blockStore = bs
-}
-
-func SetMempool(mem mempl.Mempool) {
-	mempool = mem
-}
-
-func SetEvidencePool(evpool sm.EvidencePool) {
-	evidencePool = evpool
-}
-
-func SetConsensusState(cs Consensus) {
-	consensusState = cs
-}
-
-func SetP2PPeers(p peers) {
-	p2pPeers = p
-}
-
-func SetP2PTransport(t transport) {
-	p2pTransport = t
-}
-
-func SetPubKey(pk crypto.PubKey) {
-	pubKey = pk
-}
-
-func SetGenesisDoc(doc *types.GenesisDoc) {
-	genDoc = doc
-}
-
-func SetProxyAppQuery(appConn proxy.AppConnQuery) {
-	proxyAppQuery = appConn
-}
-
-func SetTxIndexer(indexer txindex.TxIndexer) {
-	txIndexer = indexer
-}
-
-func SetConsensusReactor(conR *consensus.Reactor) {
-	consensusReactor = conR
-}
-
-func SetLogger(l log.Logger) {
-	logger = l
-}
-
-func SetEventBus(b *types.EventBus) {
-	eventBus = b
-}
-
-// SetConfig sets an RPCConfig.
-func SetConfig(c cfg.RPCConfig) {
-	config = c
+	PubKey           crypto.PubKey
+	GenDoc           *types.GenesisDoc // cache the genesis structure
+	TxIndexer        txindex.TxIndexer
+	BlockIndexer     txindex.BlockIndexer
+	ConsensusReactor *consensus.Reactor
+	FastSyncReactor  fastSyncReactor // nil unless fast sync v0 is used
+	EventBus         *types.EventBus // thread safe
+	Mempool          mempl.Mempool
+	TxTracker        *mempl.TxTracker // nil unless the tx statuses are tracked
+
+	Logger log.Logger
+
+	// functions used by UnsafeReloadConfig, UnsafeSetConfig and UnsafeSetLogLevel
+	ConfigReloader    func() (*cfg.ReloadResult, error)
+	ConfigFieldSetter func(key, value string) (*cfg.ReloadResult, error)
+	LogLevelSetter    func(module, level string) (*cfg.ReloadResult, error)
+
+	configMtx sync.RWMutex
+	config    cfg.RPCConfig
+}
+
+// SetConfig sets an RPCConfig. Unlike the other fields, it may be set again
+// at runtime, when the node's configuration is reloaded.
+func (env *Environment) SetConfig(c cfg.RPCConfig) {
+	env.configMtx.Lock()
+	env.config = c
+	env.configMtx.Unlock()
+}
+
+func (env *Environment) getConfig() cfg.RPCConfig {
+	env.configMtx.RLock()
+	defer env.configMtx.RUnlock()
+	return env.config
 }
 
 func validatePage(page, perPage, totalCount int) (int, error) {
@@ -1,58 +1,87 @@
 package core
 
 import (
+	"github.com/tendermint/tendermint/libs/failpoint"
 	rpc "github.com/tendermint/tendermint/rpc/lib/server"
 )
 
 // TODO: better system than "unsafe" prefix
 // NOTE: Amino is registered in rpc/core/types/codec.go.
 
-var Routes = map[string]*rpc.RPCFunc{
-	// subscribe/unsubscribe are reserved for websocket events.
-	"subscribe":       rpc.NewWSRPCFunc(Subscribe, "query"),
-	"unsubscribe":     rpc.NewWSRPCFunc(Unsubscribe, "query"),
-	"unsubscribe_all": rpc.NewWSRPCFunc(UnsubscribeAll, ""),
-
-	// info API
-	"health":               rpc.NewRPCFunc(Health, ""),
-	"status":               rpc.NewRPCFunc(Status, ""),
-	"net_info":             rpc.NewRPCFunc(NetInfo, ""),
-	"blockchain":           rpc.NewRPCFunc(BlockchainInfo, "minHeight,maxHeight"),
-	"genesis":              rpc.NewRPCFunc(Genesis, ""),
-	"block":                rpc.NewRPCFunc(Block, "height"),
-	"block_by_hash":        rpc.NewRPCFunc(BlockByHash, "hash"),
-	"block_results":        rpc.NewRPCFunc(BlockResults, "height"),
-	"commit":               rpc.NewRPCFunc(Commit, "height"),
-	"tx":                   rpc.NewRPCFunc(Tx, "hash,prove"),
-	"tx_search":            rpc.NewRPCFunc(TxSearch, "query,prove,page,per_page,order_by"),
-	"validators":           rpc.NewRPCFunc(Validators, "height,page,per_page"),
-	"dump_consensus_state": rpc.NewRPCFunc(DumpConsensusState, ""),
-	"consensus_state":      rpc.NewRPCFunc(ConsensusState, ""),
-	"consensus_params":     rpc.NewRPCFunc(ConsensusParams, "height"),
-	"unconfirmed_txs":      rpc.NewRPCFunc(UnconfirmedTxs, "limit"),
-	"num_unconfirmed_txs":  rpc.NewRPCFunc(NumUnconfirmedTxs, ""),
-
-	// tx broadcast API
-	"broadcast_tx_commit": rpc.NewRPCFunc(BroadcastTxCommit, "tx"),
-	"broadcast_tx_sync":   rpc.NewRPCFunc(BroadcastTxSync, "tx"),
-	"broadcast_tx_async":  rpc.NewRPCFunc(BroadcastTxAsync, "tx"),
-
-	// abci API
-	"abci_query": rpc.NewRPCFunc(ABCIQuery, "path,data,height,prove"),
-	"abci_info":  rpc.NewRPCFunc(ABCIInfo, ""),
-
-	// evidence API
-	"broadcast_evidence": rpc.NewRPCFunc(BroadcastEvidence, "evidence"),
+// Routes returns the routes of the RPC functions, served from env.
+func (env *Environment) Routes() map[string]*rpc.RPCFunc {
+	return map[string]*rpc.RPCFunc{
+		// subscribe/unsubscribe are reserved for websocket events.
+		"subscribe":       rpc.NewWSRPCFunc(env.Subscribe, "query"),
+		"unsubscribe":     rpc.NewWSRPCFunc(env.Unsubscribe, "query"),
+		"unsubscribe_all": rpc.NewWSRPCFunc(env.UnsubscribeAll, ""),
+
+		// info API
+		"health":               rpc.NewRPCFunc(env.Health, ""),
+		"status":               rpc.NewRPCFunc(env.Status, ""),
+		"net_info":             rpc.NewRPCFunc(env.NetInfo, ""),
+		"blockchain":           rpc.NewRPCFunc(env.BlockchainInfo, "minHeight,maxHeight"),
+		"genesis":              rpc.NewRPCFunc(env.Genesis, ""),
+		"block":                rpc.NewRPCFunc(env.Block, "height"),
+		"block_by_hash":        rpc.NewRPCFunc(env.BlockByHash, "hash"),
+		"block_results":        rpc.NewRPCFunc(env.BlockResults, "height"),
+		"commit":               rpc.NewRPCFunc(env.Commit, "height"),
+		"tx":                   rpc.NewRPCFunc(env.Tx, "hash,prove"),
+		"tx_status":            rpc.NewRPCFunc(env.TxStatus, "hash"),
+		"tx_search":            rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by"),
+		"block_search":         rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by"),
+		"validators":           rpc.NewRPCFunc(env.Validators, "height,page,per_page"),
+		"dump_consensus_state": rpc.NewRPCFunc(env.DumpConsensusState, ""),
+		"consensus_state":      rpc.NewRPCFunc(env.ConsensusState, ""),
+		"consensus_params":     rpc.NewRPCFunc(env.ConsensusParams, "height"),
+		"unconfirmed_txs":      rpc.NewRPCFunc(env.UnconfirmedTxs, "limit,cursor,sender,min_priority,hash_prefix"),
+		"num_unconfirmed_txs":  rpc.NewRPCFunc(env.NumUnconfirmedTxs, ""),
+
+		// tx broadcast API
+		"broadcast_tx_commit": rpc.NewRPCFunc(env.BroadcastTxCommit, "tx"),
+		"broadcast_tx_sync":   rpc.NewRPCFunc(env.BroadcastTxSync, "tx"),
+		"broadcast_tx_async":  rpc.NewRPCFunc(env.BroadcastTxAsync, "tx"),
+
+		// abci API
+		"abci_query":     rpc.NewRPCFunc(env.ABCIQuery, "path,data,height,prove"),
+		"abci_info":      rpc.NewRPCFunc(env.ABCIInfo, ""),
+		"snapshots":      rpc.NewRPCFunc(env.Snapshots, ""),
+		"snapshot_chunk": rpc.NewRPCFunc(env.SnapshotChunk, "height,format,chunk"),
+
+		// evidence API
+		"broadcast_evidence": rpc.NewRPCFunc(env.BroadcastEvidence, "evidence"),
+	}
 }
 
-func AddUnsafeRoutes() {
-	// control API
-	Routes["dial_seeds"] = rpc.NewRPCFunc(UnsafeDialSeeds, "seeds")
-	Routes["dial_peers"] = rpc.NewRPCFunc(UnsafeDialPeers, "peers,persistent")
-	Routes["unsafe_flush_mempool"] = rpc.NewRPCFunc(UnsafeFlushMempool, "")
+// UnsafeRoutes returns the routes of the unsafe commands, served from env.
+// They are not part of Routes.
+func (env *Environment) UnsafeRoutes() map[string]*rpc.RPCFunc {
+	routes := map[string]*rpc.RPCFunc{
+		// control API
+		"dial_seeds":           rpc.NewRPCFunc(env.UnsafeDialSeeds, "seeds"),
+		"dial_peers":           rpc.NewRPCFunc(env.UnsafeDialPeers, "peers,persistent"),
+		"ban_peer":             rpc.NewRPCFunc(env.UnsafeBanPeer, "id,duration,reason"),
+		"unban_peer":           rpc.NewRPCFunc(env.UnsafeUnbanPeer, "id"),
+		"banned_peers":         rpc.NewRPCFunc(env.UnsafeBannedPeers, ""),
+		"unsafe_flush_mempool": rpc.NewRPCFunc(env.UnsafeFlushMempool, ""),
+		"unsafe_reload_config": rpc.NewRPCFunc(env.UnsafeReloadConfig, ""),
+		"unsafe_set_config":    rpc.NewRPCFunc(env.UnsafeSetConfig, "key,value"),
+		"unsafe_set_log_level": rpc.NewRPCFunc(env.UnsafeSetLogLevel, "module,level"),
+
+		// snapshot API
+		"unsafe_create_snapshot": rpc.NewRPCFunc(env.UnsafeCreateSnapshot, ""),
+		"unsafe_delete_snapshot": rpc.NewRPCFunc(env.UnsafeDeleteSnapshot, "height,format"),
 
-	// profiler API
-	Routes["unsafe_start_cpu_profiler"] = rpc.NewRPCFunc(UnsafeStartCPUProfiler, "filename")
-	Routes["unsafe_stop_cpu_profiler"] = rpc.NewRPCFunc(UnsafeStopCPUProfiler, "")
-	Routes["unsafe_write_heap_profile"] = rpc.NewRPCFunc(UnsafeWriteHeapProfile, "filename")
+		// profiler API
+		"unsafe_start_cpu_profiler": rpc.NewRPCFunc(env.UnsafeStartCPUProfiler, "filename"),
+		"unsafe_stop_cpu_profiler":  rpc.NewRPCFunc(env.UnsafeStopCPUProfiler, ""),
+		"unsafe_write_heap_profile": rpc.NewRPCFunc(env.UnsafeWriteHeapProfile, "filename"),
+		"unsafe_profile":            rpc.NewRPCFunc(env.UnsafeProfile, "profile,seconds,filename"),
+	}
+	// failpoint API, in test builds only
+	if failpoint.Enabled {
+		routes["unsafe_set_failpoint"] = rpc.NewRPCFunc(env.UnsafeSetFailpoint, "name,term")
+		routes["unsafe_failpoints"] = rpc.NewRPCFunc(env.UnsafeFailpoints, "")
+	}
+	return routes
 }
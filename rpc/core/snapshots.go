@@ -0,0 +1,77 @@
+package core
+
+import (
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
+)
+
+// Snapshots lists the snapshots of the application's state, which peers can
+// restore with state sync.
+// More: https://docs.tendermint.com/master/rpc/#/ABCI/snapshots
+func (env *Environment) Snapshots(ctx *rpctypes.Context) (*ctypes.ResultSnapshots, error) {
+	res, err := env.ProxyAppSnapshot.ListSnapshotsSync(abci.RequestListSnapshots{})
+	if err != nil {
+		return nil, err
+	}
+	snapshots := res.Snapshots
+	if snapshots == nil {
+		snapshots = []*abci.Snapshot{}
+	}
+	return &ctypes.ResultSnapshots{Snapshots: snapshots}, nil
+}
+
+// SnapshotChunk returns a chunk of a snapshot of the application's state, so
+// that nodes can state sync from the RPC servers of the operator rather than
+// from p2p peers. The chunk is nil if the application doesn't have it.
+// More: https://docs.tendermint.com/master/rpc/#/ABCI/snapshot_chunk
+func (env *Environment) SnapshotChunk(ctx *rpctypes.Context, height uint64, format uint32, chunk uint32) (
+	*ctypes.ResultSnapshotChunk, error) {
+	res, err := env.ProxyAppSnapshot.LoadSnapshotChunkSync(abci.RequestLoadSnapshotChunk{
+		Height: height,
+		Format: format,
+		Chunk:  chunk,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ctypes.ResultSnapshotChunk{Chunk: res.Chunk}, nil
+}
+
+// UnsafeCreateSnapshot asks the application to snapshot its state. The
+// snapshot may be taken asynchronously, e.g. at the end of the current block:
+// the result is the height it is, or will be, taken at.
+func (env *Environment) UnsafeCreateSnapshot(ctx *rpctypes.Context) (*ctypes.ResultCreateSnapshot, error) {
+	res, err := env.ProxyAppSnapshot.CreateSnapshotSync(abci.RequestCreateSnapshot{})
+	if err != nil {
+		return nil, err
+	}
+	if res.IsErr() {
+		return nil, tmerrors.Errorf(snapshotErrorCode(res.Code), "failed to create the snapshot: %s", res.Log)
+	}
+	return &ctypes.ResultCreateSnapshot{Height: res.Height}, nil
+}
+
+// UnsafeDeleteSnapshot asks the application to delete the snapshot of the
+// given height and format.
+func (env *Environment) UnsafeDeleteSnapshot(ctx *rpctypes.Context, height uint64, format uint32) (
+	*ctypes.ResultDeleteSnapshot, error) {
+	res, err := env.ProxyAppSnapshot.DeleteSnapshotSync(abci.RequestDeleteSnapshot{Height: height, Format: format})
+	if err != nil {
+		return nil, err
+	}
+	if res.IsErr() {
+		return nil, tmerrors.Errorf(snapshotErrorCode(res.Code), "failed to delete the snapshot: %s", res.Log)
+	}
+	return &ctypes.ResultDeleteSnapshot{}, nil
+}
+
+// snapshotErrorCode returns the code of the errors of the snapshot commands
+// failing with the given ABCI code.
+func snapshotErrorCode(code uint32) tmerrors.Code {
+	if code == abci.CodeTypeNotSupported {
+		return tmerrors.CodeNotSupported
+	}
+	return tmerrors.CodeUnknown
+}
@@ -2,8 +2,10 @@ package core
 
 import (
 	"bytes"
+	"strconv"
 	"time"
 
+	bcv0 "github.com/tendermint/tendermint/blockchain/v0"
 	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/p2p"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -15,12 +17,12 @@ import (
 // Status returns Tendermint status including node info, pubkey, latest block
 // hash, app hash, block height and time.
 // More: https://docs.tendermint.com/master/rpc/#/Info/status
-func Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
+func (env *Environment) Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
 	var latestHeight int64
-	if consensusReactor.FastSync() {
-		latestHeight = blockStore.Height()
+	if env.ConsensusReactor.FastSync() {
+		latestHeight = env.BlockStore.Height()
 	} else {
-		latestHeight = consensusState.GetLastHeight()
+		latestHeight = env.Consensus.GetLastHeight()
 	}
 
 	var (
@@ -30,43 +32,96 @@ func Status(ctx *rpctypes.Context) (*ctypes.ResultStatus, error) {
 		latestBlockTimeNano int64
 	)
 	if latestHeight != 0 {
-		latestBlockMeta = blockStore.LoadBlockMeta(latestHeight)
-		latestBlockHash = latestBlockMeta.BlockID.Hash
-		latestAppHash = latestBlockMeta.Header.AppHash
-		latestBlockTimeNano = latestBlockMeta.Header.Time.UnixNano()
+		// the block of a state synced node's first height is not stored
+		latestBlockMeta = env.BlockStore.LoadBlockMeta(latestHeight)
+		if latestBlockMeta != nil {
+			latestBlockHash = latestBlockMeta.BlockID.Hash
+			latestAppHash = latestBlockMeta.Header.AppHash
+			latestBlockTimeNano = latestBlockMeta.Header.Time.UnixNano()
+		}
 	}
 
 	latestBlockTime := time.Unix(0, latestBlockTimeNano)
 
+	var (
+		earliestBlockHeight   = env.BlockStore.Base()
+		earliestBlockHash     tmbytes.HexBytes
+		earliestAppHash       tmbytes.HexBytes
+		earliestBlockTimeNano int64
+	)
+	if earliestBlockMeta := env.BlockStore.LoadBlockMeta(earliestBlockHeight); earliestBlockMeta != nil {
+		earliestBlockHash = earliestBlockMeta.BlockID.Hash
+		earliestAppHash = earliestBlockMeta.Header.AppHash
+		earliestBlockTimeNano = earliestBlockMeta.Header.Time.UnixNano()
+	}
+
 	var votingPower int64
-	if val := validatorAtHeight(latestHeight); val != nil {
+	if val := env.validatorAtHeight(latestHeight); val != nil {
 		votingPower = val.VotingPower
 	}
 
 	result := &ctypes.ResultStatus{
-		NodeInfo: p2pTransport.NodeInfo().(p2p.DefaultNodeInfo),
+		NodeInfo: env.P2PTransport.NodeInfo().(p2p.DefaultNodeInfo),
 		SyncInfo: ctypes.SyncInfo{
 			LatestBlockHash:   latestBlockHash,
 			LatestAppHash:     latestAppHash,
 			LatestBlockHeight: latestHeight,
 			LatestBlockTime:   latestBlockTime,
-			CatchingUp:        consensusReactor.FastSync(),
+
+			EarliestBlockHash:   earliestBlockHash,
+			EarliestAppHash:     earliestAppHash,
+			EarliestBlockHeight: earliestBlockHeight,
+			EarliestBlockTime:   time.Unix(0, earliestBlockTimeNano),
+
+			CatchingUp: env.ConsensusReactor.FastSync(),
 		},
-		ValidatorInfo: ctypes.ValidatorInfo{
-			Address:     pubKey.Address(),
-			PubKey:      pubKey,
+	}
+	if env.FastSyncReactor != nil {
+		if progress, ok := env.FastSyncReactor.SyncProgress(); ok {
+			result.SyncInfo.FastSync = fastSyncInfo(progress)
+		}
+	}
+	// non-validator nodes have no validator key
+	if env.PubKey != nil {
+		result.ValidatorInfo = ctypes.ValidatorInfo{
+			Address:     env.PubKey.Address(),
+			PubKey:      env.PubKey,
 			VotingPower: votingPower,
-		},
+		}
 	}
 
 	return result, nil
 }
 
-func validatorAtHeight(h int64) *types.Validator {
-	privValAddress := pubKey.Address()
+func fastSyncInfo(progress bcv0.SyncProgress) *ctypes.FastSyncInfo {
+	info := &ctypes.FastSyncInfo{
+		Height:          progress.Height,
+		TargetHeight:    progress.TargetHeight,
+		BlockRate:       strconv.FormatFloat(progress.BlockRate, 'f', 2, 64),
+		PendingRequests: progress.PendingRequests,
+		Peers:           make([]ctypes.FastSyncPeerInfo, len(progress.Peers)),
+		BufferedBytes:   progress.BufferedBytes,
+	}
+	for i, peer := range progress.Peers {
+		info.Peers[i] = ctypes.FastSyncPeerInfo{
+			ID:              peer.ID,
+			Height:          peer.Height,
+			PendingRequests: peer.PendingRequests,
+			RecvRate:        peer.RecvRate,
+			Penalty:         strconv.FormatFloat(peer.Penalty, 'f', 2, 64),
+		}
+	}
+	return info
+}
+
+func (env *Environment) validatorAtHeight(h int64) *types.Validator {
+	if env.PubKey == nil {
+		return nil
+	}
+	privValAddress := env.PubKey.Address()
 
 	// If we're still at height h, search in the current validator set.
-	lastBlockHeight, vals := consensusState.GetValidators()
+	lastBlockHeight, vals := env.Consensus.GetValidators()
 	if lastBlockHeight == h {
 		for _, val := range vals {
 			if bytes.Equal(val.Address, privValAddress) {
@@ -77,7 +132,7 @@ func validatorAtHeight(h int64) *types.Validator {
 
 	// If we've moved to the next height, retrieve the validator set from DB.
 	if lastBlockHeight > h {
-		vals, err := sm.LoadValidators(stateDB, h)
+		vals, err := sm.LoadValidators(env.StateDB, h)
 		if err != nil {
 			return nil // should not happen
 		}
@@ -1,11 +1,9 @@
 package core
 
 import (
-	"fmt"
 	"sort"
 
-	"github.com/pkg/errors"
-
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
@@ -14,23 +12,25 @@ import (
 	"github.com/tendermint/tendermint/types"
 )
 
+var errTxIndexingDisabled = tmerrors.New(tmerrors.CodeNotSupported, "transaction indexing is disabled")
+
 // Tx allows you to query the transaction results. `nil` could mean the
 // transaction is in the mempool, invalidated, or was not sent in the first
 // place.
 // More: https://docs.tendermint.com/master/rpc/#/Info/tx
-func Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
+func (env *Environment) Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error) {
 	// if index is disabled, return error
-	if _, ok := txIndexer.(*null.TxIndex); ok {
-		return nil, fmt.Errorf("transaction indexing is disabled")
+	if _, ok := env.TxIndexer.(*null.TxIndex); ok {
+		return nil, errTxIndexingDisabled
 	}
 
-	r, err := txIndexer.Get(hash)
+	r, err := env.TxIndexer.Get(hash)
 	if err != nil {
 		return nil, err
 	}
 
 	if r == nil {
-		return nil, fmt.Errorf("tx (%X) not found", hash)
+		return nil, tmerrors.Errorf(tmerrors.CodeNotFound, "tx (%X) not found", hash)
 	}
 
 	height := r.Height
@@ -38,7 +38,7 @@ func Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error
 
 	var proof types.TxProof
 	if prove {
-		block := blockStore.LoadBlock(height)
+		block := env.BlockStore.LoadBlock(height)
 		proof = block.Data.Txs.Proof(int(index)) // XXX: overflow on 32-bit machines
 	}
 
@@ -52,14 +52,54 @@ func Tx(ctx *rpctypes.Context, hash []byte, prove bool) (*ctypes.ResultTx, error
 	}, nil
 }
 
+// TxStatus returns the status of a transaction: received by the mempool,
+// broadcast to a peer, proposed, committed or evicted from the mempool, as
+// recorded since the node started (see mempool.tx_status_cache_size), or
+// committed if it is indexed. It allows to submit transactions with
+// broadcast_tx_async and poll their status, rather than waiting for them to be
+// committed with broadcast_tx_commit. The changes of status are also published
+// as TxStatus events.
+// More: https://docs.tendermint.com/master/rpc/#/Info/tx_status
+func (env *Environment) TxStatus(ctx *rpctypes.Context, hash []byte) (*ctypes.ResultTxStatus, error) {
+	if env.TxTracker != nil {
+		if status, ok := env.TxTracker.Status(hash); ok {
+			return &ctypes.ResultTxStatus{
+				Hash:   status.Hash,
+				Status: status.Status,
+				Height: status.Height,
+				Code:   status.Code,
+				Reason: status.Reason,
+			}, nil
+		}
+	}
+
+	unknown := &ctypes.ResultTxStatus{Hash: hash, Status: types.TxStatusUnknown}
+	if _, ok := env.TxIndexer.(*null.TxIndex); ok {
+		return unknown, nil
+	}
+	r, err := env.TxIndexer.Get(hash)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return unknown, nil
+	}
+	return &ctypes.ResultTxStatus{
+		Hash:   hash,
+		Status: types.TxStatusCommitted,
+		Height: r.Height,
+		Code:   r.Result.Code,
+	}, nil
+}
+
 // TxSearch allows you to query for multiple transactions results. It returns a
 // list of transactions (maximum ?per_page entries) and the total count.
 // More: https://docs.tendermint.com/master/rpc/#/Info/tx_search
-func TxSearch(ctx *rpctypes.Context, query string, prove bool, page, perPage int, orderBy string) (
+func (env *Environment) TxSearch(ctx *rpctypes.Context, query string, prove bool, page, perPage int, orderBy string) (
 	*ctypes.ResultTxSearch, error) {
 	// if index is disabled, return error
-	if _, ok := txIndexer.(*null.TxIndex); ok {
-		return nil, errors.New("transaction indexing is disabled")
+	if _, ok := env.TxIndexer.(*null.TxIndex); ok {
+		return nil, errTxIndexingDisabled
 	}
 
 	q, err := tmquery.New(query)
@@ -67,7 +107,7 @@ func TxSearch(ctx *rpctypes.Context, query string, prove bool, page, perPage int
 		return nil, err
 	}
 
-	results, err := txIndexer.Search(ctx.Context(), q)
+	results, err := env.TxIndexer.Search(ctx.Context(), q)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +129,8 @@ func TxSearch(ctx *rpctypes.Context, query string, prove bool, page, perPage int
 			return results[i].Height < results[j].Height
 		})
 	default:
-		return nil, errors.New("expected order_by to be either `asc` or `desc` or empty")
+		return nil, tmerrors.New(tmerrors.CodeInvalidArgument,
+			"expected order_by to be either `asc` or `desc` or empty")
 	}
 
 	// paginate results
@@ -108,7 +149,7 @@ func TxSearch(ctx *rpctypes.Context, query string, prove bool, page, perPage int
 
 		var proof types.TxProof
 		if prove {
-			block := blockStore.LoadBlock(r.Height)
+			block := env.BlockStore.LoadBlock(r.Height)
 			proof = block.Data.Txs.Proof(int(r.Index)) // XXX: overflow on 32-bit machines
 		}
 
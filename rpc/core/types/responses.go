@@ -7,6 +7,7 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/failpoint"
 
 	"github.com/tendermint/tendermint/p2p"
 	"github.com/tendermint/tendermint/types"
@@ -65,7 +66,40 @@ type SyncInfo struct {
 	LatestAppHash     bytes.HexBytes `json:"latest_app_hash"`
 	LatestBlockHeight int64          `json:"latest_block_height"`
 	LatestBlockTime   time.Time      `json:"latest_block_time"`
-	CatchingUp        bool           `json:"catching_up"`
+	// the first block of the block store, above 1 if the node was state
+	// synced
+	EarliestBlockHash   bytes.HexBytes `json:"earliest_block_hash"`
+	EarliestAppHash     bytes.HexBytes `json:"earliest_app_hash"`
+	EarliestBlockHeight int64          `json:"earliest_block_height"`
+	EarliestBlockTime   time.Time      `json:"earliest_block_time"`
+	CatchingUp          bool           `json:"catching_up"`
+	// the progress of fast sync, while the node is fast syncing
+	FastSync *FastSyncInfo `json:"fast_sync,omitempty"`
+}
+
+// Info about the progress of fast sync
+type FastSyncInfo struct {
+	// height of the last block synced, highest height of the peers, and
+	// blocks synced per second, as a decimal
+	Height       int64  `json:"height"`
+	TargetHeight int64  `json:"target_height"`
+	BlockRate    string `json:"block_rate"`
+	// blocks requested and not received yet
+	PendingRequests int32              `json:"pending_requests"`
+	Peers           []FastSyncPeerInfo `json:"peers"`
+	// size of the blocks received and not synced yet
+	BufferedBytes int64 `json:"buffered_bytes"`
+}
+
+// Info about the progress of fast sync with a peer
+type FastSyncPeerInfo struct {
+	ID              p2p.ID `json:"id"`
+	Height          int64  `json:"height"`
+	PendingRequests int32  `json:"pending_requests"`
+	// bytes per second at which the peer sends the requested blocks
+	RecvRate int64 `json:"recv_rate"`
+	// penalty for timeouts and invalid blocks, as a decimal, lower is better
+	Penalty string `json:"penalty"`
 }
 
 // Info about the node's validator
@@ -90,6 +124,22 @@ func (s *ResultStatus) TxIndexEnabled() bool {
 	return s.NodeInfo.Other.TxIndex == "on"
 }
 
+// Status of the stores of a stopped node, served by `tendermint inspect`
+type ResultInspectStatus struct {
+	ChainID string `json:"chain_id"`
+
+	// latest block of the block store
+	LatestBlockHash   bytes.HexBytes `json:"latest_block_hash"`
+	LatestAppHash     bytes.HexBytes `json:"latest_app_hash"`
+	LatestBlockHeight int64          `json:"latest_block_height"`
+	LatestBlockTime   time.Time      `json:"latest_block_time"`
+
+	// latest block applied to the state, which may lag the block store when
+	// the node stopped while committing a block
+	StateHeight  int64          `json:"state_height"`
+	StateAppHash bytes.HexBytes `json:"state_app_hash"`
+}
+
 // Info about peer connections
 type ResultNetInfo struct {
 	Listening bool     `json:"listening"`
@@ -108,6 +158,21 @@ type ResultDialPeers struct {
 	Log string `json:"log"`
 }
 
+// Ban of a peer
+type ResultBanPeer struct {
+	Ban p2p.Ban `json:"ban"`
+}
+
+// Peer whose ban was lifted
+type ResultUnbanPeer struct {
+	ID p2p.ID `json:"id"`
+}
+
+// Bans of peers in effect
+type ResultBannedPeers struct {
+	Bans []p2p.Ban `json:"bans"`
+}
+
 // A peer
 type Peer struct {
 	NodeInfo         p2p.DefaultNodeInfo  `json:"node_info"`
@@ -131,8 +196,9 @@ type ResultConsensusParams struct {
 // Info about the consensus state.
 // UNSTABLE
 type ResultDumpConsensusState struct {
-	RoundState json.RawMessage `json:"round_state"`
-	Peers      []PeerStateInfo `json:"peers"`
+	RoundState     json.RawMessage `json:"round_state"`
+	Peers          []PeerStateInfo `json:"peers"`
+	ValidatorStats json.RawMessage `json:"validator_stats"`
 }
 
 // UNSTABLE
@@ -179,12 +245,30 @@ type ResultTxSearch struct {
 	TotalCount int         `json:"total_count"`
 }
 
+// Result of searching for blocks
+type ResultBlockSearch struct {
+	Blocks     []*ResultBlock `json:"blocks"`
+	TotalCount int            `json:"total_count"`
+}
+
+// Status of a tx: received, broadcast, proposed (at height), committed (at
+// height, with the DeliverTx code), evicted (for reason) or unknown
+type ResultTxStatus struct {
+	Hash   bytes.HexBytes `json:"hash"`
+	Status string         `json:"status"`
+	Height int64          `json:"height,omitempty"`
+	Code   uint32         `json:"code,omitempty"`
+	Reason string         `json:"reason,omitempty"`
+}
+
 // List of mempool txs
 type ResultUnconfirmedTxs struct {
 	Count      int        `json:"n_txs"`
 	Total      int        `json:"total"`
 	TotalBytes int64      `json:"total_bytes"`
 	Txs        []types.Tx `json:"txs"`
+	// cursor of the last tx listed, to list the next ones
+	Cursor uint64 `json:"cursor,omitempty"`
 }
 
 // Info abci msg
@@ -202,6 +286,46 @@ type ResultBroadcastEvidence struct {
 	Hash []byte `json:"hash"`
 }
 
+// List of the application's snapshots
+type ResultSnapshots struct {
+	Snapshots []*abci.Snapshot `json:"snapshots"`
+}
+
+// A chunk of a snapshot, nil if the application doesn't have it
+type ResultSnapshotChunk struct {
+	Chunk []byte `json:"chunk"`
+}
+
+// Result of creating a snapshot: the height it is taken at
+type ResultCreateSnapshot struct {
+	Height uint64 `json:"height"`
+}
+
+// Result of deleting a snapshot
+type ResultDeleteSnapshot struct{}
+
+// Result of reloading the node's config
+type ResultReloadConfig struct {
+	Applied         []string `json:"applied"`
+	RestartRequired []string `json:"restart_required"`
+}
+
+// Result of setting or listing the failpoints
+type ResultFailpoints struct {
+	Failpoints []failpoint.Failpoint `json:"failpoints"`
+}
+
+// Result of capturing a profile
+type ResultProfile struct {
+	Profile string `json:"profile"`
+	// Filename the profile is written to, if any.
+	Filename string `json:"filename,omitempty"`
+	// Until is the time at which a CPU profile or trace will be complete.
+	Until time.Time `json:"until,omitempty"`
+	// Data is the profile, if no filename was given.
+	Data []byte `json:"data,omitempty"`
+}
+
 // empty results
 type (
 	ResultUnsafeFlushMempool struct{}
@@ -216,4 +340,7 @@ type ResultEvent struct {
 	Query  string              `json:"query"`
 	Data   types.TMEventData   `json:"data"`
 	Events map[string][]string `json:"events"`
+	// number of events dropped since the previous one, because the client
+	// was too slow (with the "notify" slow_client_policy)
+	Dropped int64 `json:"dropped,omitempty"`
 }
@@ -3,12 +3,28 @@ package coregrpc
 import (
 	"context"
 
+	amino "github.com/tendermint/go-amino"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
 	abci "github.com/tendermint/tendermint/abci/types"
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
+	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	core "github.com/tendermint/tendermint/rpc/core"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	rpctypes "github.com/tendermint/tendermint/rpc/lib/types"
 )
 
+// cdc encodes the event data of the subscriptions, as the websocket does.
+var cdc = amino.NewCodec()
+
+func init() {
+	ctypes.RegisterAmino(cdc)
+}
+
 type broadcastAPI struct {
+	env *core.Environment
 }
 
 func (bapi *broadcastAPI) Ping(ctx context.Context, req *RequestPing) (*ResponsePing, error) {
@@ -19,7 +35,7 @@ func (bapi *broadcastAPI) Ping(ctx context.Context, req *RequestPing) (*Response
 func (bapi *broadcastAPI) BroadcastTx(ctx context.Context, req *RequestBroadcastTx) (*ResponseBroadcastTx, error) {
 	// NOTE: there's no way to get client's remote address
 	// see https://stackoverflow.com/questions/33684570/session-and-remote-ip-address-in-grpc-go
-	res, err := core.BroadcastTxCommit(&rpctypes.Context{}, req.Tx)
+	res, err := bapi.env.BroadcastTxCommit(&rpctypes.Context{}, req.Tx)
 	if err != nil {
 		return nil, err
 	}
@@ -37,3 +53,180 @@ func (bapi *broadcastAPI) BroadcastTx(ctx context.Context, req *RequestBroadcast
 		},
 	}, nil
 }
+
+// coreAPI serves the JSON-RPC endpoints of the same names, with the
+// subscription limits of the RPC config.
+type coreAPI struct {
+	env *core.Environment
+}
+
+func (capi *coreAPI) Status(ctx context.Context, req *RequestStatus) (*ResponseStatus, error) {
+	res, err := capi.env.Status(&rpctypes.Context{})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return statusToProto(res), nil
+}
+
+func (capi *coreAPI) Block(ctx context.Context, req *RequestBlock) (*ResponseBlock, error) {
+	res, err := capi.env.Block(&rpctypes.Context{}, heightPtr(req.Height))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &ResponseBlock{
+		BlockID: blockIDToProto(res.BlockID),
+		Block:   blockToProto(res.Block),
+	}, nil
+}
+
+func (capi *coreAPI) BlockResults(ctx context.Context, req *RequestBlockResults) (*ResponseBlockResults, error) {
+	res, err := capi.env.BlockResults(&rpctypes.Context{}, heightPtr(req.Height))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &ResponseBlockResults{
+		Height:                res.Height,
+		TxsResults:            res.TxsResults,
+		BeginBlockEvents:      res.BeginBlockEvents,
+		EndBlockEvents:        res.EndBlockEvents,
+		ValidatorUpdates:      res.ValidatorUpdates,
+		ConsensusParamUpdates: res.ConsensusParamUpdates,
+	}, nil
+}
+
+func (capi *coreAPI) Tx(ctx context.Context, req *RequestTx) (*ResponseTx, error) {
+	res, err := capi.env.Tx(&rpctypes.Context{}, req.Hash, req.Prove)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return txToProto(res), nil
+}
+
+func (capi *coreAPI) Validators(ctx context.Context, req *RequestValidators) (*ResponseValidators, error) {
+	res, err := capi.env.Validators(&rpctypes.Context{}, heightPtr(req.Height), int(req.Page), int(req.PerPage))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return validatorsToProto(res), nil
+}
+
+func (capi *coreAPI) BroadcastTxAsync(
+	ctx context.Context,
+	req *RequestBroadcastTx,
+) (*ResponseBroadcastTxAsync, error) {
+	res, err := capi.env.BroadcastTxAsync(&rpctypes.Context{}, req.Tx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &ResponseBroadcastTxAsync{Code: res.Code, Data: res.Data, Log: res.Log, Hash: res.Hash}, nil
+}
+
+func (capi *coreAPI) BroadcastTxSync(
+	ctx context.Context,
+	req *RequestBroadcastTx,
+) (*ResponseBroadcastTxSync, error) {
+	res, err := capi.env.BroadcastTxSync(&rpctypes.Context{}, req.Tx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &ResponseBroadcastTxSync{Code: res.Code, Data: res.Data, Log: res.Log, Hash: res.Hash}, nil
+}
+
+func (capi *coreAPI) BroadcastTxCommit(
+	ctx context.Context,
+	req *RequestBroadcastTx,
+) (*ResponseBroadcastTxCommit, error) {
+	res, err := capi.env.BroadcastTxCommit(&rpctypes.Context{}, req.Tx)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &ResponseBroadcastTxCommit{
+		CheckTx:   res.CheckTx,
+		DeliverTx: res.DeliverTx,
+		Hash:      res.Hash,
+		Height:    res.Height,
+	}, nil
+}
+
+// Subscribe subscribes the remote address of the client, so that, as with the
+// websocket, a client can't subscribe twice to the same query.
+func (capi *coreAPI) Subscribe(req *RequestSubscribe, stream CoreAPI_SubscribeServer) error {
+	ctx := stream.Context()
+	var subscriber string
+	if p, ok := peer.FromContext(ctx); ok {
+		subscriber = p.Addr.String()
+	}
+
+	sub, err := capi.env.SubscribeEvents(ctx, subscriber, req.Query)
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer capi.env.UnsubscribeEvents(subscriber, req.Query) // nolint: errcheck
+
+	notifyDropped := capi.env.NotifyDroppedEvents()
+	var dropped int64 // events dropped so far, reported to the client
+	for {
+		select {
+		case msg := <-sub.Out():
+			data, err := cdc.MarshalJSON(msg.Data())
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			res := &ResponseSubscribe{
+				Query:  req.Query,
+				Data:   data,
+				Events: eventsToProto(msg.Events()),
+			}
+			if n := sub.Dropped(); notifyDropped && n > dropped {
+				res.Dropped = n - dropped
+				dropped = n
+			}
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+		case <-sub.Cancelled():
+			switch err := sub.Err(); err {
+			case nil:
+				return status.Error(codes.Unavailable, "subscription was cancelled (reason: Tendermint exited)")
+			case tmpubsub.ErrOutOfCapacity:
+				return status.Errorf(codes.ResourceExhausted, "subscription was cancelled (reason: %s)", err)
+			default:
+				return status.Errorf(codes.Unavailable, "subscription was cancelled (reason: %s)", err)
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// heightPtr returns nil, i.e. the latest height, for 0.
+func heightPtr(height int64) *int64 {
+	if height == 0 {
+		return nil
+	}
+	return &height
+}
+
+// toStatusError returns the gRPC status of the code of err.
+func toStatusError(err error) error {
+	var code codes.Code
+	switch tmerrors.CodeOf(err) {
+	case tmerrors.CodeInternal:
+		code = codes.Internal
+	case tmerrors.CodeInvalidArgument:
+		code = codes.InvalidArgument
+	case tmerrors.CodeNotFound:
+		code = codes.NotFound
+	case tmerrors.CodeTimeout:
+		code = codes.DeadlineExceeded
+	case tmerrors.CodeUnavailable:
+		code = codes.Unavailable
+	case tmerrors.CodeResourceExhausted:
+		code = codes.ResourceExhausted
+	case tmerrors.CodeNotSupported:
+		code = codes.Unimplemented
+	default:
+		code = codes.Unknown
+	}
+	return status.Error(code, err.Error())
+}
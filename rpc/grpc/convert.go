@@ -0,0 +1,150 @@
+package coregrpc
+
+import (
+	"sort"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func blockIDToProto(blockID types.BlockID) BlockID {
+	return BlockID{
+		Hash: blockID.Hash,
+		PartsHeader: PartSetHeader{
+			Total: int64(blockID.PartsHeader.Total),
+			Hash:  blockID.PartsHeader.Hash,
+		},
+	}
+}
+
+func blockToProto(block *types.Block) *Block {
+	if block == nil {
+		return nil
+	}
+	txs := make([][]byte, len(block.Txs))
+	for i, tx := range block.Txs {
+		txs[i] = tx
+	}
+	evidence := make([][]byte, len(block.Evidence.Evidence))
+	for i, ev := range block.Evidence.Evidence {
+		evidence[i] = types.GetCodec().MustMarshalBinaryBare(ev)
+	}
+	return &Block{
+		Header:     types.TM2PB.Header(&block.Header),
+		Txs:        txs,
+		Evidence:   evidence,
+		LastCommit: commitToProto(block.LastCommit),
+	}
+}
+
+func commitToProto(commit *types.Commit) *Commit {
+	if commit == nil {
+		return nil
+	}
+	sigs := make([]CommitSig, len(commit.Signatures))
+	for i, sig := range commit.Signatures {
+		sigs[i] = CommitSig{
+			BlockIDFlag:      int32(sig.BlockIDFlag),
+			ValidatorAddress: sig.ValidatorAddress,
+			Timestamp:        sig.Timestamp,
+			Signature:        sig.Signature,
+			Extension:        sig.Extension,
+		}
+	}
+	return &Commit{
+		Height:     commit.Height,
+		Round:      int32(commit.Round),
+		BlockID:    blockIDToProto(commit.BlockID),
+		Signatures: sigs,
+	}
+}
+
+// pubKeyToProto is types.TM2PB.PubKey, but returns an empty PubKey for a nil
+// key, e.g. of a node which isn't a validator.
+func pubKeyToProto(pubKey crypto.PubKey) abci.PubKey {
+	if pubKey == nil {
+		return abci.PubKey{}
+	}
+	return types.TM2PB.PubKey(pubKey)
+}
+
+func statusToProto(status *ctypes.ResultStatus) *ResponseStatus {
+	nodeInfo := status.NodeInfo
+	return &ResponseStatus{
+		NodeInfo: NodeInfo{
+			ProtocolVersion: ProtocolVersion{
+				P2P:   uint64(nodeInfo.ProtocolVersion.P2P),
+				Block: uint64(nodeInfo.ProtocolVersion.Block),
+				App:   uint64(nodeInfo.ProtocolVersion.App),
+			},
+			ID:         string(nodeInfo.ID()),
+			ListenAddr: nodeInfo.ListenAddr,
+			Network:    nodeInfo.Network,
+			Version:    nodeInfo.Version,
+			Channels:   nodeInfo.Channels,
+			Moniker:    nodeInfo.Moniker,
+			TxIndex:    nodeInfo.Other.TxIndex,
+			RPCAddress: nodeInfo.Other.RPCAddress,
+		},
+		SyncInfo: SyncInfo{
+			LatestBlockHash:   status.SyncInfo.LatestBlockHash,
+			LatestAppHash:     status.SyncInfo.LatestAppHash,
+			LatestBlockHeight: status.SyncInfo.LatestBlockHeight,
+			LatestBlockTime:   status.SyncInfo.LatestBlockTime,
+			CatchingUp:        status.SyncInfo.CatchingUp,
+		},
+		ValidatorInfo: ValidatorInfo{
+			Address:     status.ValidatorInfo.Address,
+			PubKey:      pubKeyToProto(status.ValidatorInfo.PubKey),
+			VotingPower: status.ValidatorInfo.VotingPower,
+		},
+	}
+}
+
+func txToProto(tx *ctypes.ResultTx) *ResponseTx {
+	return &ResponseTx{
+		Hash:     tx.Hash,
+		Height:   tx.Height,
+		Index:    tx.Index,
+		TxResult: tx.TxResult,
+		Tx:       tx.Tx,
+		Proof: TxProof{
+			RootHash: tx.Proof.RootHash,
+			Data:     tx.Proof.Data,
+			Proof: SimpleProof{
+				Total:    int64(tx.Proof.Proof.Total),
+				Index:    int64(tx.Proof.Proof.Index),
+				LeafHash: tx.Proof.Proof.LeafHash,
+				Aunts:    tx.Proof.Proof.Aunts,
+			},
+		},
+	}
+}
+
+func validatorsToProto(vals *ctypes.ResultValidators) *ResponseValidators {
+	pbVals := make([]*Validator, len(vals.Validators))
+	for i, val := range vals.Validators {
+		pbVals[i] = &Validator{
+			Address:          val.Address,
+			PubKey:           pubKeyToProto(val.PubKey),
+			VotingPower:      val.VotingPower,
+			ProposerPriority: val.ProposerPriority,
+		}
+	}
+	return &ResponseValidators{
+		BlockHeight: vals.BlockHeight,
+		Validators:  pbVals,
+	}
+}
+
+// eventsToProto returns the events of a subscription sorted by key.
+func eventsToProto(events map[string][]string) []EventValues {
+	pbEvents := make([]EventValues, 0, len(events))
+	for key, values := range events {
+		pbEvents = append(pbEvents, EventValues{Key: key, Values: values})
+	}
+	sort.Slice(pbEvents, func(i, j int) bool { return pbEvents[i].Key < pbEvents[j].Key })
+	return pbEvents
+}
@@ -2,9 +2,11 @@ package coregrpc_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/abci/example/kvstore"
@@ -33,3 +35,66 @@ func TestBroadcastTx(t *testing.T) {
 	require.EqualValues(t, 0, res.CheckTx.Code)
 	require.EqualValues(t, 0, res.DeliverTx.Code)
 }
+
+func TestCoreAPI(t *testing.T) {
+	ctx := context.Background()
+	client := rpctest.GetGRPCCoreClient()
+
+	// subscribe first, to get the event of the tx
+	stream, err := client.Subscribe(ctx, &core_grpc.RequestSubscribe{Query: "tm.event = 'Tx'"})
+	require.NoError(t, err)
+
+	tx := []byte("grpc=core")
+	bres, err := client.BroadcastTxCommit(ctx, &core_grpc.RequestBroadcastTx{Tx: tx})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, bres.CheckTx.Code)
+	require.EqualValues(t, 0, bres.DeliverTx.Code)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Contains(t, string(event.Data), "tendermint/event/Tx")
+	var hashes []string
+	for _, values := range event.Events {
+		if values.Key == "tx.hash" {
+			hashes = values.Values
+		}
+	}
+	assert.Equal(t, []string{fmt.Sprintf("%X", bres.Hash)}, hashes)
+
+	status, err := client.Status(ctx, &core_grpc.RequestStatus{})
+	require.NoError(t, err)
+	assert.True(t, status.SyncInfo.LatestBlockHeight >= bres.Height)
+	assert.NotEmpty(t, status.NodeInfo.ID)
+	assert.NotEmpty(t, status.ValidatorInfo.PubKey.Data)
+
+	block, err := client.Block(ctx, &core_grpc.RequestBlock{Height: bres.Height})
+	require.NoError(t, err)
+	assert.Equal(t, bres.Height, block.Block.Header.Height)
+	assert.Equal(t, [][]byte{tx}, block.Block.Txs)
+
+	results, err := client.BlockResults(ctx, &core_grpc.RequestBlockResults{Height: bres.Height})
+	require.NoError(t, err)
+	require.Len(t, results.TxsResults, 1)
+	assert.EqualValues(t, 0, results.TxsResults[0].Code)
+
+	txRes, err := client.Tx(ctx, &core_grpc.RequestTx{Hash: bres.Hash, Prove: true})
+	require.NoError(t, err)
+	assert.Equal(t, tx, txRes.Tx)
+	assert.Equal(t, block.Block.Header.DataHash, txRes.Proof.RootHash)
+
+	vals, err := client.Validators(ctx, &core_grpc.RequestValidators{Height: bres.Height})
+	require.NoError(t, err)
+	require.Len(t, vals.Validators, 1)
+	assert.Equal(t, status.ValidatorInfo.Address, vals.Validators[0].Address)
+
+	sres, err := client.BroadcastTxSync(ctx, &core_grpc.RequestBroadcastTx{Tx: []byte("grpc=sync")})
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, sres.Code)
+	_, err = client.BroadcastTxAsync(ctx, &core_grpc.RequestBroadcastTx{Tx: []byte("grpc=async")})
+	require.NoError(t, err)
+
+	_, err = client.Block(ctx, &core_grpc.RequestBlock{Height: bres.Height + 1000})
+	assert.Error(t, err)
+	_, err = client.Tx(ctx, &core_grpc.RequestTx{Hash: []byte("not a hash")})
+	assert.Error(t, err)
+}
@@ -104,7 +104,7 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, cdc *amino.Codec, logger lo
 			logger.Info("HTTPJSONRPC", "method", request.Method, "args", args, "returns", returns)
 			result, err := unreflectResult(returns)
 			if err != nil {
-				responses = append(responses, types.RPCInternalError(request.ID, err))
+				responses = append(responses, types.RPCErrorFromError(request.ID, err))
 				continue
 			}
 			responses = append(responses, types.NewRPCSuccessResponse(cdc, request.ID, result))
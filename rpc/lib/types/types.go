@@ -11,6 +11,8 @@ import (
 	"github.com/pkg/errors"
 
 	amino "github.com/tendermint/go-amino"
+
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 )
 
 // a wrapper to emulate a sum type: jsonrpcid = string | int
@@ -149,6 +151,21 @@ func (err RPCError) Error() string {
 	return fmt.Sprintf(baseFormat, err.Code, err.Message)
 }
 
+// ErrorCode returns the code of libs/errors of the error, as sent by
+// RPCErrorFromError. It implements tmerrors.Coder.
+func (err RPCError) ErrorCode() tmerrors.Code {
+	switch {
+	case err.Code < serverErrorCode && err.Code >= serverErrorCode-99:
+		return tmerrors.Code(serverErrorCode - err.Code)
+	case err.Code == invalidParamsCode:
+		return tmerrors.CodeInvalidArgument
+	case err.Code == internalErrorCode:
+		return tmerrors.CodeInternal
+	default:
+		return tmerrors.CodeUnknown
+	}
+}
+
 type RPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      jsonrpcid       `json:"id,omitempty"`
@@ -230,16 +247,35 @@ func RPCMethodNotFoundError(id jsonrpcid) RPCResponse {
 	return NewRPCErrorResponse(id, -32601, "Method not found", "")
 }
 
+const (
+	invalidParamsCode = -32602
+	internalErrorCode = -32603
+	serverErrorCode   = -32000
+)
+
 func RPCInvalidParamsError(id jsonrpcid, err error) RPCResponse {
-	return NewRPCErrorResponse(id, -32602, "Invalid params", err.Error())
+	return NewRPCErrorResponse(id, invalidParamsCode, "Invalid params", err.Error())
 }
 
 func RPCInternalError(id jsonrpcid, err error) RPCResponse {
-	return NewRPCErrorResponse(id, -32603, "Internal error", err.Error())
+	return NewRPCErrorResponse(id, internalErrorCode, "Internal error", err.Error())
 }
 
 func RPCServerError(id jsonrpcid, err error) RPCResponse {
-	return NewRPCErrorResponse(id, -32000, "Server error", err.Error())
+	return NewRPCErrorResponse(id, serverErrorCode, "Server error", err.Error())
+}
+
+// RPCErrorFromError returns the response of an error returned by an RPC
+// function. Errors with a code of libs/errors (e.g. tmerrors.CodeNotFound) are
+// server errors, with the JSON-RPC code -32000 minus their code and the code
+// name as message, which RPCError.ErrorCode converts back. Other errors are
+// internal errors.
+func RPCErrorFromError(id jsonrpcid, err error) RPCResponse {
+	code := tmerrors.CodeOf(err)
+	if code == tmerrors.CodeUnknown || code > 99 {
+		return RPCInternalError(id, err)
+	}
+	return NewRPCErrorResponse(id, serverErrorCode-int(code), code.String(), err.Error())
 }
 
 //----------------------------------------
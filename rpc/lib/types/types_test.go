@@ -9,6 +9,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	amino "github.com/tendermint/go-amino"
+
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 )
 
 type SampleResult struct {
@@ -85,3 +87,21 @@ func TestRPCError(t *testing.T) {
 			Message: "Badness",
 		}))
 }
+
+func TestRPCErrorFromError(t *testing.T) {
+	id := JSONRPCIntID(1)
+
+	resp := RPCErrorFromError(id, errors.Wrap(tmerrors.New(tmerrors.CodeNotFound, "tx not found"), "query"))
+	assert.Equal(t, -32003, resp.Error.Code)
+	assert.Equal(t, "not found", resp.Error.Message)
+	assert.Equal(t, "query: tx not found", resp.Error.Data)
+	assert.Equal(t, tmerrors.CodeNotFound, tmerrors.CodeOf(resp.Error))
+
+	resp = RPCErrorFromError(id, errors.New("boom"))
+	assert.Equal(t, -32603, resp.Error.Code)
+	assert.Equal(t, tmerrors.CodeInternal, tmerrors.CodeOf(resp.Error))
+
+	resp = RPCInvalidParamsError(id, errors.New("bad height"))
+	assert.Equal(t, tmerrors.CodeInvalidArgument, tmerrors.CodeOf(resp.Error))
+	assert.Equal(t, tmerrors.CodeUnknown, tmerrors.CodeOf(RPCMethodNotFoundError(id).Error))
+}
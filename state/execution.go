@@ -1,12 +1,16 @@
 package state
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	"github.com/tendermint/tendermint/libs/fail"
+	"github.com/tendermint/tendermint/libs/failpoint"
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/telemetry"
 	mempl "github.com/tendermint/tendermint/mempool"
 	"github.com/tendermint/tendermint/proxy"
 	"github.com/tendermint/tendermint/types"
@@ -108,6 +112,41 @@ func (blockExec *BlockExecutor) CreateProposalBlock(
 	return state.MakeBlock(height, txs, commit, evidence, proposerAddr)
 }
 
+// ExtendVote returns the extension of the given non-nil precommit, returned
+// by the application.
+func (blockExec *BlockExecutor) ExtendVote(vote *types.Vote) ([]byte, error) {
+	res, err := blockExec.proxyApp.ExtendVoteSync(abci.RequestExtendVote{
+		Hash:   vote.BlockID.Hash,
+		Height: vote.Height,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(res.VoteExtension)) > types.MaxVoteExtensionBytes {
+		return nil, fmt.Errorf("vote extension is too big: %d bytes, max: %d bytes",
+			len(res.VoteExtension), types.MaxVoteExtensionBytes)
+	}
+	return res.VoteExtension, nil
+}
+
+// VerifyVoteExtension asks the application to verify the extension of the
+// given precommit, and returns an error if it's not accepted.
+func (blockExec *BlockExecutor) VerifyVoteExtension(vote *types.Vote) error {
+	res, err := blockExec.proxyApp.VerifyVoteExtensionSync(abci.RequestVerifyVoteExtension{
+		Hash:             vote.BlockID.Hash,
+		ValidatorAddress: vote.ValidatorAddress,
+		Height:           vote.Height,
+		VoteExtension:    vote.Extension,
+	})
+	if err != nil {
+		return err
+	}
+	if res.Status != abci.ResponseVerifyVoteExtension_ACCEPT {
+		return fmt.Errorf("vote extension not accepted by the application: %v", res.Status)
+	}
+	return nil
+}
+
 // ValidateBlock validates the given block against the given state.
 // If the block is invalid, it returns an error.
 // Validation does not mutate state, but does require historical information from the stateDB,
@@ -121,14 +160,22 @@ func (blockExec *BlockExecutor) ValidateBlock(state State, block *types.Block) e
 // It's the only function that needs to be called
 // from outside this package to process and commit an entire block.
 // It takes a blockID to avoid recomputing the parts hash.
-func (blockExec *BlockExecutor) ApplyBlock(state State, blockID types.BlockID, block *types.Block) (State, error) {
+func (blockExec *BlockExecutor) ApplyBlock(
+	state State,
+	blockID types.BlockID,
+	block *types.Block,
+) (_ State, err error) {
+	ctx, span := telemetry.StartSpan(context.Background(), "ApplyBlock",
+		"height", block.Height, "num_txs", len(block.Txs))
+	defer func() { telemetry.EndSpan(ctx, span, err) }()
+	logger := telemetry.NewLogger(ctx, blockExec.logger)
 
 	if err := blockExec.ValidateBlock(state, block); err != nil {
 		return state, ErrInvalidBlock(err)
 	}
 
 	startTime := time.Now().UnixNano()
-	abciResponses, err := execBlockOnProxyApp(blockExec.logger, blockExec.proxyApp, block, blockExec.db)
+	abciResponses, err := execBlockOnProxyApp(logger, blockExec.proxyApp, block, blockExec.db)
 	endTime := time.Now().UnixNano()
 	blockExec.metrics.BlockProcessingTime.Observe(float64(endTime-startTime) / 1000000)
 	if err != nil {
@@ -136,11 +183,17 @@ func (blockExec *BlockExecutor) ApplyBlock(state State, blockID types.BlockID, b
 	}
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("state/before-save-abci-responses"); err != nil {
+		return state, err
+	}
 
 	// Save the results before we commit.
 	SaveABCIResponses(blockExec.db, block.Height, abciResponses)
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("state/after-save-abci-responses"); err != nil {
+		return state, err
+	}
 
 	// validate the validator updates and convert to tendermint types
 	abciValUpdates := abciResponses.EndBlock.ValidatorUpdates
@@ -153,7 +206,7 @@ func (blockExec *BlockExecutor) ApplyBlock(state State, blockID types.BlockID, b
 		return state, err
 	}
 	if len(validatorUpdates) > 0 {
-		blockExec.logger.Info("Updates to validators", "updates", types.ValidatorListString(validatorUpdates))
+		logger.Info("Updates to validators", "updates", types.ValidatorListString(validatorUpdates))
 	}
 
 	// Update the state with the block and responses.
@@ -172,16 +225,22 @@ func (blockExec *BlockExecutor) ApplyBlock(state State, blockID types.BlockID, b
 	blockExec.evpool.Update(block, state)
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("state/before-save-state"); err != nil {
+		return state, err
+	}
 
 	// Update the app hash and save the state.
 	state.AppHash = appHash
 	SaveState(blockExec.db, state)
 
 	fail.Fail() // XXX
+	if err := failpoint.Inject("state/after-save-state"); err != nil {
+		return state, err
+	}
 
 	// Events are fired after everything else.
 	// NOTE: if we crash between Commit and Save, events wont be fired during replay
-	fireEvents(blockExec.logger, blockExec.eventBus, block, abciResponses, validatorUpdates)
+	fireEvents(logger, blockExec.eventBus, block, abciResponses, validatorUpdates)
 
 	return state, nil
 }
@@ -208,6 +267,10 @@ func (blockExec *BlockExecutor) Commit(
 		return nil, err
 	}
 
+	if err := failpoint.Inject("state/before-app-commit"); err != nil {
+		return nil, err
+	}
+
 	// Commit block, get hash back
 	res, err := blockExec.proxyApp.CommitSync()
 	if err != nil {
@@ -335,6 +398,7 @@ func getBeginBlockValidatorInfo(block *types.Block, stateDB dbm.DB) (abci.LastCo
 			voteInfos[i] = abci.VoteInfo{
 				Validator:       types.TM2PB.Validator(val),
 				SignedLastBlock: !commitSig.Absent(),
+				VoteExtension:   commitSig.Extension,
 			}
 		}
 	}
@@ -419,6 +483,15 @@ func updateState(
 		lastHeightParamsChanged = header.Height + 1
 	}
 
+	// The block was validated, so its proof is valid.
+	var vrfOutput []byte
+	if len(header.ProposerProof) > 0 {
+		var err error
+		if vrfOutput, err = vrf.ProofToHash(header.ProposerProof); err != nil {
+			return state, fmt.Errorf("error reading the VRF output of the proposer: %v", err)
+		}
+	}
+
 	// TODO: allow app to upgrade version
 	nextVersion := state.Version
 
@@ -438,6 +511,7 @@ func updateState(
 		LastHeightConsensusParamsChanged: lastHeightParamsChanged,
 		LastResultsHash:                  abciResponses.ResultsHash(),
 		AppHash:                          nil,
+		LastVRFOutput:                    vrfOutput,
 	}, nil
 }
 
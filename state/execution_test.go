@@ -110,6 +110,33 @@ func TestBeginBlockValidators(t *testing.T) {
 }
 
 // TestBeginBlockByzantineValidators ensures we send byzantine validators list.
+func TestBeginBlockVoteExtensions(t *testing.T) {
+	app := &testApp{}
+	cc := proxy.NewLocalClientCreator(app)
+	proxyApp := proxy.NewAppConns(cc)
+	err := proxyApp.Start()
+	require.Nil(t, err)
+	defer proxyApp.Stop()
+
+	state, stateDB, _ := makeState(2, 2)
+
+	prevBlockID := types.BlockID{Hash: state.LastBlockID.Hash, PartsHeader: types.PartSetHeader{}}
+	commitSig0 := types.NewCommitSigForBlock([]byte("Signature1"), state.Validators.Validators[0].Address, tmtime.Now())
+	commitSig0.Extension = []byte("extension")
+	lastCommit := types.NewCommit(1, 0, prevBlockID, []types.CommitSig{commitSig0, types.NewCommitSigAbsent()})
+
+	// block for height 2
+	block, _ := state.MakeBlock(2, makeTxs(2), lastCommit, nil, state.Validators.GetProposer().Address)
+
+	_, err = sm.ExecCommitBlock(proxyApp.Consensus(), block, log.TestingLogger(), stateDB)
+	require.Nil(t, err)
+
+	// -> app receives the extensions of the last commit
+	require.Len(t, app.CommitVotes, 2)
+	assert.Equal(t, []byte("extension"), app.CommitVotes[0].VoteExtension)
+	assert.Empty(t, app.CommitVotes[1].VoteExtension)
+}
+
 func TestBeginBlockByzantineValidators(t *testing.T) {
 	app := &testApp{}
 	cc := proxy.NewLocalClientCreator(app)
@@ -80,6 +80,10 @@ type State struct {
 
 	// the latest AppHash we've received from calling abci.Commit()
 	AppHash []byte
+
+	// the VRF output proven by the proposer of the last block, if any (see
+	// VRFSeed)
+	LastVRFOutput []byte
 }
 
 // Copy makes a copy of the State for mutating.
@@ -103,7 +107,20 @@ func (state State) Copy() State {
 		AppHash: state.AppHash,
 
 		LastResultsHash: state.LastResultsHash,
+
+		LastVRFOutput: state.LastVRFOutput,
+	}
+}
+
+// VRFSeed returns the seed of the VRF proposer selection at the next height:
+// the VRF output proven by the proposer of the last block or, if it has no
+// proof (at the first height, or right after the VRF proposer selection is
+// enabled), the hash of the last block.
+func (state State) VRFSeed() []byte {
+	if len(state.LastVRFOutput) > 0 {
+		return state.LastVRFOutput
 	}
+	return state.LastBlockID.Hash
 }
 
 // Equals returns true if the States are identical.
@@ -141,9 +158,12 @@ func (state State) MakeBlock(
 
 	// Set time.
 	var timestamp time.Time
-	if height == 1 {
+	switch {
+	case state.ConsensusParams.Synchrony.ProposerBasedTimestamps:
+		timestamp = ProposerTime(height, state.LastBlockTime)
+	case height == 1:
 		timestamp = state.LastBlockTime // genesis time
-	} else {
+	default:
 		timestamp = MedianTime(commit, state.LastValidators)
 	}
 
@@ -159,6 +179,21 @@ func (state State) MakeBlock(
 	return block, block.MakePartSet(types.BlockPartSizeBytes)
 }
 
+// ProposerTime returns the time of a new block at height with proposer-based
+// timestamps: the local time, unless it isn't after the time of the last block
+// (or before the genesis time, for the first block), in which case the block
+// is unlikely to be timely for the validators.
+func ProposerTime(height int64, lastBlockTime time.Time) time.Time {
+	now := tmtime.Now()
+	switch {
+	case height == 1 && now.Before(lastBlockTime):
+		return lastBlockTime // genesis time
+	case height > 1 && !now.After(lastBlockTime):
+		return lastBlockTime.Add(time.Millisecond)
+	}
+	return now
+}
+
 // MedianTime computes a median time for a given Commit (based on Timestamp field of votes messages) and the
 // corresponding validator set. The computed time is always between timestamps of
 // the votes sent by honest processes, i.e., a faulty processes can not arbitrarily increase or decrease the
@@ -97,6 +97,19 @@ func SaveState(db dbm.DB, state State) {
 	saveState(db, state, stateKey)
 }
 
+// BootstrapState saves the state of a node which starts at a recent height,
+// e.g. after restoring the app from a snapshot, instead of the genesis. As the
+// previous heights are unknown, the validator sets of the last, current and
+// next heights and the consensus params are saved in full.
+func BootstrapState(db dbm.DB, state State) {
+	height := state.LastBlockHeight
+	saveValidatorsInfo(db, height, height, state.LastValidators)
+	saveValidatorsInfo(db, height+1, height+1, state.Validators)
+	saveValidatorsInfo(db, height+2, height+2, state.NextValidators)
+	saveConsensusParamsInfo(db, height+1, height+1, state.ConsensusParams)
+	db.SetSync(stateKey, state.Bytes())
+}
+
 func saveState(db dbm.DB, state State, key []byte) {
 	nextHeight := state.LastBlockHeight + 1
 	// If first block, save validators for block 1.
@@ -35,6 +35,49 @@ func TestStoreLoadValidators(t *testing.T) {
 	assert.NotZero(t, loadedVals.Size())
 }
 
+func TestBootstrapState(t *testing.T) {
+	stateDB := dbm.NewMemDB()
+	vals := genValSet(2)
+	state := sm.State{
+		ChainID:                          "test-chain",
+		LastBlockHeight:                  100,
+		LastValidators:                   vals,
+		Validators:                       vals.CopyIncrementProposerPriority(1),
+		NextValidators:                   vals.CopyIncrementProposerPriority(2),
+		LastHeightValidatorsChanged:      102,
+		ConsensusParams:                  *types.DefaultConsensusParams(),
+		LastHeightConsensusParamsChanged: 101,
+	}
+	sm.BootstrapState(stateDB, state)
+
+	assert.Equal(t, state.Bytes(), sm.LoadState(stateDB).Bytes())
+	for height, want := range map[int64]*types.ValidatorSet{
+		100: state.LastValidators,
+		101: state.Validators,
+		102: state.NextValidators,
+	} {
+		loadedVals, err := sm.LoadValidators(stateDB, height)
+		require.NoError(t, err)
+		assert.Equal(t, want.Hash(), loadedVals.Hash(), "height %d", height)
+		assert.Equal(t, want.GetProposer(), loadedVals.GetProposer(), "height %d", height)
+	}
+	_, err := sm.LoadValidators(stateDB, 99)
+	assert.Error(t, err)
+	params, err := sm.LoadConsensusParams(stateDB, 101)
+	require.NoError(t, err)
+	assert.Equal(t, state.ConsensusParams, params)
+
+	// the blocks following it are saved as usual
+	state.LastBlockHeight++
+	state.LastValidators = state.Validators
+	state.Validators = state.NextValidators
+	state.NextValidators = state.NextValidators.CopyIncrementProposerPriority(1)
+	sm.SaveState(stateDB, state)
+	loadedVals, err := sm.LoadValidators(stateDB, 103)
+	require.NoError(t, err)
+	assert.Equal(t, state.NextValidators.GetProposer(), loadedVals.GetProposer())
+}
+
 func BenchmarkLoadValidators(b *testing.B) {
 	const valSetSize = 100
 
@@ -24,10 +24,10 @@ func TestTxFilter(t *testing.T) {
 		isErr bool
 	}{
 		{types.Tx(tmrand.Bytes(250)), false},
-		{types.Tx(tmrand.Bytes(1811)), false},
-		{types.Tx(tmrand.Bytes(1831)), false},
-		{types.Tx(tmrand.Bytes(1838)), true},
-		{types.Tx(tmrand.Bytes(1839)), true},
+		{types.Tx(tmrand.Bytes(702)), false},
+		{types.Tx(tmrand.Bytes(722)), false},
+		{types.Tx(tmrand.Bytes(723)), true},
+		{types.Tx(tmrand.Bytes(729)), true},
 		{types.Tx(tmrand.Bytes(3000)), true},
 	}
 
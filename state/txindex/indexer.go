@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	tmerrors "github.com/tendermint/tendermint/libs/errors"
 	"github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/types"
 )
@@ -25,6 +26,18 @@ type TxIndexer interface {
 	Search(ctx context.Context, q *query.Query) ([]*types.TxResult, error)
 }
 
+// BlockIndexer interface defines methods to index and search the events of the
+// blocks, returned by BeginBlock and EndBlock.
+type BlockIndexer interface {
+
+	// Index indexes the events of the block of the header.
+	Index(header types.EventDataNewBlockHeader) error
+
+	// Search returns the heights of the blocks matching the query, in
+	// ascending order. The height of a block is its "block.height" event.
+	Search(ctx context.Context, q *query.Query) ([]int64, error)
+}
+
 //----------------------------------------------------
 // Txs are written as a batch
 
@@ -57,3 +70,9 @@ func (b *Batch) Size() int {
 
 // ErrorEmptyHash indicates empty hash
 var ErrorEmptyHash = errors.New("transaction hash cannot be empty")
+
+// ErrSearchNotSupported is returned by Get and Search of the indexers which
+// only write the transactions and blocks to an external store, queried
+// directly.
+var ErrSearchNotSupported = tmerrors.New(tmerrors.CodeNotSupported,
+	"the indexer doesn't support search (set 'tx_index.indexer' to include \"kv\")")
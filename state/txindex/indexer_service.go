@@ -12,24 +12,25 @@ const (
 	subscriber = "IndexerService"
 )
 
-// IndexerService connects event bus and transaction indexer together in order
-// to index transactions coming from event bus.
+// IndexerService connects event bus and transaction and block indexers
+// together in order to index transactions and blocks coming from event bus.
 type IndexerService struct {
 	service.BaseService
 
 	idr      TxIndexer
+	blockIdr BlockIndexer
 	eventBus *types.EventBus
 }
 
 // NewIndexerService returns a new service instance.
-func NewIndexerService(idr TxIndexer, eventBus *types.EventBus) *IndexerService {
-	is := &IndexerService{idr: idr, eventBus: eventBus}
+func NewIndexerService(idr TxIndexer, blockIdr BlockIndexer, eventBus *types.EventBus) *IndexerService {
+	is := &IndexerService{idr: idr, blockIdr: blockIdr, eventBus: eventBus}
 	is.BaseService = *service.NewBaseService(nil, "IndexerService", is)
 	return is
 }
 
 // OnStart implements service.Service by subscribing for all transactions
-// and indexing them by events.
+// and blocks and indexing them by events.
 func (is *IndexerService) OnStart() error {
 	// Use SubscribeUnbuffered here to ensure both subscriptions does not get
 	// cancelled due to not pulling messages fast enough. Cause this might
@@ -42,21 +43,33 @@ func (is *IndexerService) OnStart() error {
 	if err != nil {
 		return err
 	}
+	headers := types.NewNewBlockHeaderSubscription(blockHeadersSub)
 
 	txsSub, err := is.eventBus.SubscribeUnbuffered(context.Background(), subscriber, types.EventQueryTx)
 	if err != nil {
 		return err
 	}
+	txs := types.NewTxSubscription(txsSub)
 
 	go func() {
+		ctx := context.Background()
 		for {
-			msg := <-blockHeadersSub.Out()
-			eventDataHeader := msg.Data().(types.EventDataNewBlockHeader)
+			eventDataHeader, err := headers.Next(ctx)
+			if err != nil {
+				return
+			}
 			height := eventDataHeader.Header.Height
+			if err := is.blockIdr.Index(eventDataHeader); err != nil {
+				is.Logger.Error("Failed to index block events", "height", height, "err", err)
+			}
+
 			batch := NewBatch(eventDataHeader.NumTxs)
 			for i := int64(0); i < eventDataHeader.NumTxs; i++ {
-				msg2 := <-txsSub.Out()
-				txResult := msg2.Data().(types.EventDataTx).TxResult
+				eventDataTx, err := txs.Next(ctx)
+				if err != nil {
+					return
+				}
+				txResult := eventDataTx.TxResult
 				if err = batch.Add(&txResult); err != nil {
 					is.Logger.Error("Can't add tx to batch",
 						"height", height,
@@ -1,6 +1,7 @@
 package txindex_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -8,7 +9,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	tmkv "github.com/tendermint/tendermint/libs/kv"
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/state/txindex"
 	"github.com/tendermint/tendermint/state/txindex/kv"
 	"github.com/tendermint/tendermint/types"
@@ -26,8 +29,9 @@ func TestIndexerServiceIndexesBlocks(t *testing.T) {
 	// tx indexer
 	store := db.NewMemDB()
 	txIndexer := kv.NewTxIndex(store, kv.IndexAllEvents())
+	blockIndexer := kv.NewBlockIndex(store)
 
-	service := txindex.NewIndexerService(txIndexer, eventBus)
+	service := txindex.NewIndexerService(txIndexer, blockIndexer, eventBus)
 	service.SetLogger(log.TestingLogger())
 	err = service.Start()
 	require.NoError(t, err)
@@ -37,6 +41,9 @@ func TestIndexerServiceIndexesBlocks(t *testing.T) {
 	eventBus.PublishEventNewBlockHeader(types.EventDataNewBlockHeader{
 		Header: types.Header{Height: 1},
 		NumTxs: int64(2),
+		ResultEndBlock: abci.ResponseEndBlock{Events: []abci.Event{
+			{Type: "rewards", Attributes: []tmkv.Pair{{Key: []byte("amount"), Value: []byte("10")}}},
+		}},
 	})
 	txResult1 := &types.TxResult{
 		Height: 1,
@@ -62,4 +69,7 @@ func TestIndexerServiceIndexesBlocks(t *testing.T) {
 	res, err = txIndexer.Get(types.Tx("bar").Hash())
 	assert.NoError(t, err)
 	assert.Equal(t, txResult2, res)
+	heights, err := blockIndexer.Search(context.Background(), query.MustParse("rewards.amount = 10"))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, heights)
 }
@@ -0,0 +1,157 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/state/txindex"
+	"github.com/tendermint/tendermint/types"
+)
+
+// blockIndexPrefix prefixes the keys of a BlockIndex, so that it can share the
+// store of a TxIndex.
+var blockIndexPrefix = []byte("block_events/")
+
+var _ txindex.BlockIndexer = (*BlockIndex)(nil)
+
+// BlockIndex indexes the heights of the blocks by the events returned by
+// BeginBlock and EndBlock, and by "block.height". Unlike the events of the
+// txs, all the events of the blocks are indexed.
+type BlockIndex struct {
+	store dbm.DB
+}
+
+// NewBlockIndex creates new KV block indexer. The store can be the one of a
+// TxIndex.
+func NewBlockIndex(store dbm.DB) *BlockIndex {
+	return &BlockIndex{store: dbm.NewPrefixDB(store, blockIndexPrefix)}
+}
+
+// Index indexes the height of the block by its events. Any event with an
+// empty type is not indexed.
+func (bi *BlockIndex) Index(header types.EventDataNewBlockHeader) error {
+	b := bi.store.NewBatch()
+	defer b.Close()
+
+	height := header.Header.Height
+	heightBz := []byte(strconv.FormatInt(height, 10))
+
+	// index block by height
+	b.Set(keyForBlockEvent(types.BlockHeightKey, heightBz, height, "block"), heightBz)
+
+	// index block by events
+	indexBlockEvents(b, header.ResultBeginBlock.Events, height, "begin_block")
+	indexBlockEvents(b, header.ResultEndBlock.Events, height, "end_block")
+
+	return b.WriteSync()
+}
+
+func indexBlockEvents(b dbm.SetDeleter, events []abci.Event, height int64, typ string) {
+	heightBz := []byte(strconv.FormatInt(height, 10))
+	for _, event := range events {
+		// only index events with a non-empty type
+		if len(event.Type) == 0 {
+			continue
+		}
+
+		for _, attr := range event.Attributes {
+			if len(attr.Key) == 0 {
+				continue
+			}
+
+			compositeKey := fmt.Sprintf("%s.%s", event.Type, string(attr.Key))
+			b.Set(keyForBlockEvent(compositeKey, attr.Value, height, typ), heightBz)
+		}
+	}
+}
+
+// Search performs a search using the given query, and returns the matching
+// heights in ascending order.
+//
+// As with TxIndex.Search, the results of the conditions are intersected, and
+// range queries should have both lower and upper bounds, so we are not
+// performing a full scan.
+//
+// Search will exit early and return any result fetched so far,
+// when a message is received on the context chan.
+func (bi *BlockIndex) Search(ctx context.Context, q *query.Query) ([]int64, error) {
+	// Potentially exit early.
+	select {
+	case <-ctx.Done():
+		return []int64{}, nil
+	default:
+	}
+
+	var heightsInitialized bool
+	filteredHeights := make(map[string][]byte)
+
+	// get a list of conditions (like "block.height > 5")
+	conditions, err := q.Conditions()
+	if err != nil {
+		return nil, errors.Wrap(err, "error during parsing conditions from query")
+	}
+
+	// conditions to skip because they're handled before "everything else"
+	skipIndexes := make([]int, 0)
+
+	// extract ranges
+	ranges, rangeIndexes := lookForRanges(conditions)
+	if len(ranges) > 0 {
+		skipIndexes = append(skipIndexes, rangeIndexes...)
+
+		for _, r := range ranges {
+			filteredHeights = matchRange(ctx, bi.store, r, startKey(r.key), filteredHeights, !heightsInitialized)
+			heightsInitialized = true
+
+			// Ignore any remaining conditions if a condition resulted in no
+			// matches (assuming implicit AND operand).
+			if len(filteredHeights) == 0 {
+				break
+			}
+		}
+	}
+
+	// for all other conditions
+	for i, c := range conditions {
+		if intInSlice(i, skipIndexes) {
+			continue
+		}
+
+		filteredHeights = match(ctx, bi.store, c, startKey(c.CompositeKey, c.Operand),
+			filteredHeights, !heightsInitialized)
+		heightsInitialized = true
+
+		if len(filteredHeights) == 0 {
+			break
+		}
+	}
+
+	heights := make([]int64, 0, len(filteredHeights))
+	for _, heightBz := range filteredHeights {
+		height, err := strconv.ParseInt(string(heightBz), 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse height %q", heightBz)
+		}
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	return heights, nil
+}
+
+func keyForBlockEvent(compositeKey string, value []byte, height int64, typ string) []byte {
+	return []byte(fmt.Sprintf("%s/%s/%d/%s",
+		compositeKey,
+		value,
+		height,
+		typ,
+	))
+}
@@ -0,0 +1,86 @@
+package kv
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/kv"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestBlockIndexSearch(t *testing.T) {
+	store := db.NewMemDB()
+	indexer := NewBlockIndex(store)
+
+	for height := int64(1); height <= 3; height++ {
+		require.NoError(t, indexer.Index(types.EventDataNewBlockHeader{
+			Header: types.Header{Height: height},
+			ResultBeginBlock: abci.ResponseBeginBlock{Events: []abci.Event{
+				{Type: "proposer", Attributes: []kv.Pair{{Key: []byte("name"), Value: []byte("Ivan")}}},
+			}},
+			ResultEndBlock: abci.ResponseEndBlock{Events: []abci.Event{
+				{Type: "rewards", Attributes: []kv.Pair{
+					{Key: []byte("amount"), Value: []byte(strconv.FormatInt(height, 10))},
+				}},
+				{Type: "", Attributes: []kv.Pair{{Key: []byte("not_allowed"), Value: []byte("Vlad")}}},
+			}},
+		}))
+	}
+	require.NoError(t, indexer.Index(types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 4},
+		ResultEndBlock: abci.ResponseEndBlock{Events: []abci.Event{
+			{Type: "slash", Attributes: []kv.Pair{{Key: []byte("reason"), Value: []byte("double_sign")}}},
+		}},
+	}))
+
+	testCases := []struct {
+		q       string
+		heights []int64
+	}{
+		// search by height
+		{"block.height = 2", []int64{2}},
+		{"block.height = 5", []int64{}},
+		// search by range of heights
+		{"block.height > 1 AND block.height <= 3", []int64{2, 3}},
+		{"block.height >= 3", []int64{3, 4}},
+		// search by exact match of an event of BeginBlock or EndBlock
+		{"proposer.name = 'Ivan'", []int64{1, 2, 3}},
+		{"rewards.amount = 2", []int64{2}},
+		{"slash.reason = 'double_sign'", []int64{4}},
+		// search by exact match (two keys)
+		{"proposer.name = 'Ivan' AND rewards.amount = 3", []int64{3}},
+		{"proposer.name = 'Vlad' AND rewards.amount = 3", []int64{}},
+		// search by range of an event
+		{"rewards.amount >= 2", []int64{2, 3}},
+		{"rewards.amount < 2 AND block.height >= 1", []int64{1}},
+		// search using CONTAINS
+		{"slash.reason CONTAINS 'sign'", []int64{4}},
+		// search using not allowed key
+		{"not_allowed = 'Vlad'", []int64{}},
+	}
+
+	ctx := context.Background()
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.q, func(t *testing.T) {
+			heights, err := indexer.Search(ctx, query.MustParse(tc.q))
+			require.NoError(t, err)
+			assert.Equal(t, tc.heights, heights)
+		})
+	}
+
+	// the block index doesn't show up in the searches of a tx index of the
+	// same store
+	txResults, err := NewTxIndex(store, IndexAllEvents()).Search(ctx, query.MustParse("slash.reason = 'double_sign'"))
+	require.NoError(t, err)
+	assert.Empty(t, txResults)
+}
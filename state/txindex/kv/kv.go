@@ -13,6 +13,7 @@ import (
 
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/libs/failpoint"
 	"github.com/tendermint/tendermint/libs/pubsub/query"
 	tmstring "github.com/tendermint/tendermint/libs/strings"
 	"github.com/tendermint/tendermint/state/txindex"
@@ -106,6 +107,9 @@ func (txi *TxIndex) AddBatch(b *txindex.Batch) error {
 		storeBatch.Set(hash, rawBytes)
 	}
 
+	if err := failpoint.Inject("txindex/write-batch"); err != nil {
+		return err
+	}
 	storeBatch.WriteSync()
 	return nil
 }
@@ -217,7 +221,7 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResu
 
 		for _, r := range ranges {
 			if !hashesInitialized {
-				filteredHashes = txi.matchRange(ctx, r, startKey(r.key), filteredHashes, true)
+				filteredHashes = matchRange(ctx, txi.store, r, startKey(r.key), filteredHashes, true)
 				hashesInitialized = true
 
 				// Ignore any remaining conditions if the first condition resulted
@@ -226,7 +230,7 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResu
 					break
 				}
 			} else {
-				filteredHashes = txi.matchRange(ctx, r, startKey(r.key), filteredHashes, false)
+				filteredHashes = matchRange(ctx, txi.store, r, startKey(r.key), filteredHashes, false)
 			}
 		}
 	}
@@ -241,7 +245,7 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResu
 		}
 
 		if !hashesInitialized {
-			filteredHashes = txi.match(ctx, c, startKeyForCondition(c, height), filteredHashes, true)
+			filteredHashes = match(ctx, txi.store, c, startKeyForCondition(c, height), filteredHashes, true)
 			hashesInitialized = true
 
 			// Ignore any remaining conditions if the first condition resulted
@@ -250,7 +254,7 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResu
 				break
 			}
 		} else {
-			filteredHashes = txi.match(ctx, c, startKeyForCondition(c, height), filteredHashes, false)
+			filteredHashes = match(ctx, txi.store, c, startKeyForCondition(c, height), filteredHashes, false)
 		}
 	}
 
@@ -387,13 +391,15 @@ func isRangeOperation(op query.Operator) bool {
 	}
 }
 
-// match returns all matching txs by hash that meet a given condition and start
-// key. An already filtered result (filteredHashes) is provided such that any
+// match returns all matching values (tx hashes, or block heights with a
+// BlockIndex) of the store that meet a given condition and start key. An
+// already filtered result (filteredHashes) is provided such that any
 // non-intersecting matches are removed.
 //
 // NOTE: filteredHashes may be empty if no previous condition has matched.
-func (txi *TxIndex) match(
+func match(
 	ctx context.Context,
+	store dbm.DB,
 	c query.Condition,
 	startKeyBz []byte,
 	filteredHashes map[string][]byte,
@@ -409,7 +415,7 @@ func (txi *TxIndex) match(
 
 	switch {
 	case c.Op == query.OpEqual:
-		it, err := dbm.IteratePrefix(txi.store, startKeyBz)
+		it, err := dbm.IteratePrefix(store, startKeyBz)
 		if err != nil {
 			panic(err)
 		}
@@ -430,7 +436,7 @@ func (txi *TxIndex) match(
 		// XXX: startKey does not apply here.
 		// For example, if startKey = "account.owner/an/" and search query = "account.owner CONTAINS an"
 		// we can't iterate with prefix "account.owner/an/" because we might miss keys like "account.owner/Ulan/"
-		it, err := dbm.IteratePrefix(txi.store, startKey(c.CompositeKey))
+		it, err := dbm.IteratePrefix(store, startKey(c.CompositeKey))
 		if err != nil {
 			panic(err)
 		}
@@ -485,13 +491,15 @@ func (txi *TxIndex) match(
 	return filteredHashes
 }
 
-// matchRange returns all matching txs by hash that meet a given queryRange and
-// start key. An already filtered result (filteredHashes) is provided such that
-// any non-intersecting matches are removed.
+// matchRange returns all matching values (tx hashes, or block heights with a
+// BlockIndex) of the store that meet a given queryRange and start key. An
+// already filtered result (filteredHashes) is provided such that any
+// non-intersecting matches are removed.
 //
 // NOTE: filteredHashes may be empty if no previous condition has matched.
-func (txi *TxIndex) matchRange(
+func matchRange(
 	ctx context.Context,
+	store dbm.DB,
 	r queryRange,
 	startKey []byte,
 	filteredHashes map[string][]byte,
@@ -507,7 +515,7 @@ func (txi *TxIndex) matchRange(
 	lowerBound := r.lowerBoundValue()
 	upperBound := r.upperBoundValue()
 
-	it, err := dbm.IteratePrefix(txi.store, startKey)
+	it, err := dbm.IteratePrefix(store, startKey)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,119 @@
+package txindex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+var _ TxIndexer = (*MultiTxIndex)(nil)
+
+// MultiTxIndex fans the transactions out to several indexers (sinks), e.g. kv
+// and psql, and searches them in the first one which supports it.
+type MultiTxIndex struct {
+	sinks []TxIndexer
+}
+
+// NewMultiTxIndex returns a MultiTxIndex of the given sinks, in the order
+// they are searched.
+func NewMultiTxIndex(sinks ...TxIndexer) *MultiTxIndex {
+	return &MultiTxIndex{sinks: sinks}
+}
+
+// Sinks returns the indexers the transactions are written to.
+func (txi *MultiTxIndex) Sinks() []TxIndexer {
+	return txi.sinks
+}
+
+// AddBatch writes the batch to all sinks, even if some of them fail.
+func (txi *MultiTxIndex) AddBatch(b *Batch) error {
+	return txi.forEach(func(sink TxIndexer) error { return sink.AddBatch(b) })
+}
+
+// Index writes the transaction to all sinks, even if some of them fail.
+func (txi *MultiTxIndex) Index(result *types.TxResult) error {
+	return txi.forEach(func(sink TxIndexer) error { return sink.Index(result) })
+}
+
+// Get returns the transaction from the first sink which supports search.
+func (txi *MultiTxIndex) Get(hash []byte) (*types.TxResult, error) {
+	for _, sink := range txi.sinks {
+		res, err := sink.Get(hash)
+		if err != ErrSearchNotSupported {
+			return res, err
+		}
+	}
+	return nil, ErrSearchNotSupported
+}
+
+// Search searches the first sink which supports it.
+func (txi *MultiTxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResult, error) {
+	for _, sink := range txi.sinks {
+		res, err := sink.Search(ctx, q)
+		if err != ErrSearchNotSupported {
+			return res, err
+		}
+	}
+	return nil, ErrSearchNotSupported
+}
+
+func (txi *MultiTxIndex) forEach(fn func(sink TxIndexer) error) error {
+	var errs []string
+	for i, sink := range txi.sinks {
+		if err := fn(sink); err != nil {
+			errs = append(errs, fmt.Sprintf("sink %d (%T): %v", i, sink, err))
+		}
+	}
+	return indexErr(errs)
+}
+
+var _ BlockIndexer = (*MultiBlockIndex)(nil)
+
+// MultiBlockIndex is the BlockIndexer counterpart of MultiTxIndex.
+type MultiBlockIndex struct {
+	sinks []BlockIndexer
+}
+
+// NewMultiBlockIndex returns a MultiBlockIndex of the given sinks, in the
+// order they are searched.
+func NewMultiBlockIndex(sinks ...BlockIndexer) *MultiBlockIndex {
+	return &MultiBlockIndex{sinks: sinks}
+}
+
+// Sinks returns the indexers the blocks are written to.
+func (bi *MultiBlockIndex) Sinks() []BlockIndexer {
+	return bi.sinks
+}
+
+// Index writes the events of the block to all sinks, even if some of them
+// fail.
+func (bi *MultiBlockIndex) Index(header types.EventDataNewBlockHeader) error {
+	var errs []string
+	for i, sink := range bi.sinks {
+		if err := sink.Index(header); err != nil {
+			errs = append(errs, fmt.Sprintf("sink %d (%T): %v", i, sink, err))
+		}
+	}
+	return indexErr(errs)
+}
+
+// Search searches the first sink which supports it.
+func (bi *MultiBlockIndex) Search(ctx context.Context, q *query.Query) ([]int64, error) {
+	for _, sink := range bi.sinks {
+		heights, err := sink.Search(ctx, q)
+		if err != ErrSearchNotSupported {
+			return heights, err
+		}
+	}
+	return nil, ErrSearchNotSupported
+}
+
+func indexErr(errs []string) error {
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to index: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
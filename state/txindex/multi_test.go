@@ -0,0 +1,118 @@
+package txindex_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/state/txindex"
+	"github.com/tendermint/tendermint/state/txindex/kv"
+	"github.com/tendermint/tendermint/types"
+	db "github.com/tendermint/tm-db"
+)
+
+// writeOnlyTxIndex records the txs it's given, like the psql sink, and
+// doesn't support search.
+type writeOnlyTxIndex struct {
+	results []*types.TxResult
+	err     error
+}
+
+func (txi *writeOnlyTxIndex) AddBatch(b *txindex.Batch) error {
+	txi.results = append(txi.results, b.Ops...)
+	return txi.err
+}
+
+func (txi *writeOnlyTxIndex) Index(result *types.TxResult) error {
+	txi.results = append(txi.results, result)
+	return txi.err
+}
+
+func (txi *writeOnlyTxIndex) Get(hash []byte) (*types.TxResult, error) {
+	return nil, txindex.ErrSearchNotSupported
+}
+
+func (txi *writeOnlyTxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResult, error) {
+	return nil, txindex.ErrSearchNotSupported
+}
+
+func TestMultiTxIndex(t *testing.T) {
+	writeOnly := &writeOnlyTxIndex{}
+	// the first sink doesn't support search: the second one is searched
+	indexer := txindex.NewMultiTxIndex(writeOnly, kv.NewTxIndex(db.NewMemDB(), kv.IndexAllEvents()))
+
+	txResult1 := &types.TxResult{Height: 1, Index: 0, Tx: types.Tx("foo"), Result: abci.ResponseDeliverTx{}}
+	txResult2 := &types.TxResult{Height: 1, Index: 1, Tx: types.Tx("bar"), Result: abci.ResponseDeliverTx{}}
+	batch := txindex.NewBatch(2)
+	require.NoError(t, batch.Add(txResult1))
+	require.NoError(t, batch.Add(txResult2))
+	require.NoError(t, indexer.AddBatch(batch))
+
+	txResult3 := &types.TxResult{Height: 2, Index: 0, Tx: types.Tx("baz"), Result: abci.ResponseDeliverTx{}}
+	require.NoError(t, indexer.Index(txResult3))
+
+	assert.Equal(t, []*types.TxResult{txResult1, txResult2, txResult3}, writeOnly.results)
+
+	res, err := indexer.Get(types.Tx("bar").Hash())
+	require.NoError(t, err)
+	assert.Equal(t, txResult2, res)
+
+	results, err := indexer.Search(context.Background(), query.MustParse("tx.height = 1"))
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// the txs are written to the other sinks when one fails
+	writeOnly.err = errors.New("boom")
+	txResult4 := &types.TxResult{Height: 3, Index: 0, Tx: types.Tx("qux"), Result: abci.ResponseDeliverTx{}}
+	err = indexer.Index(txResult4)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	res, err = indexer.Get(types.Tx("qux").Hash())
+	require.NoError(t, err)
+	assert.Equal(t, txResult4, res)
+
+	// none of the sinks supports search
+	indexer = txindex.NewMultiTxIndex(&writeOnlyTxIndex{}, &writeOnlyTxIndex{})
+	_, err = indexer.Get(types.Tx("foo").Hash())
+	assert.Equal(t, txindex.ErrSearchNotSupported, err)
+	_, err = indexer.Search(context.Background(), query.MustParse("tx.height = 1"))
+	assert.Equal(t, txindex.ErrSearchNotSupported, err)
+}
+
+// writeOnlyBlockIndex is the BlockIndexer counterpart of writeOnlyTxIndex.
+type writeOnlyBlockIndex struct {
+	heights []int64
+}
+
+func (bi *writeOnlyBlockIndex) Index(header types.EventDataNewBlockHeader) error {
+	bi.heights = append(bi.heights, header.Header.Height)
+	return nil
+}
+
+func (bi *writeOnlyBlockIndex) Search(ctx context.Context, q *query.Query) ([]int64, error) {
+	return nil, txindex.ErrSearchNotSupported
+}
+
+func TestMultiBlockIndex(t *testing.T) {
+	writeOnly := &writeOnlyBlockIndex{}
+	indexer := txindex.NewMultiBlockIndex(writeOnly, kv.NewBlockIndex(db.NewMemDB()))
+
+	for height := int64(1); height <= 2; height++ {
+		require.NoError(t, indexer.Index(types.EventDataNewBlockHeader{Header: types.Header{Height: height}}))
+	}
+	assert.Equal(t, []int64{1, 2}, writeOnly.heights)
+
+	heights, err := indexer.Search(context.Background(), query.MustParse("block.height >= 2"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, heights)
+
+	// none of the sinks supports search
+	indexer = txindex.NewMultiBlockIndex(&writeOnlyBlockIndex{})
+	_, err = indexer.Search(context.Background(), query.MustParse("block.height >= 2"))
+	assert.Equal(t, txindex.ErrSearchNotSupported, err)
+}
@@ -32,3 +32,17 @@ func (txi *TxIndex) Index(result *types.TxResult) error {
 func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResult, error) {
 	return []*types.TxResult{}, nil
 }
+
+var _ txindex.BlockIndexer = (*BlockIndex)(nil)
+
+// BlockIndex acts as a /dev/null.
+type BlockIndex struct{}
+
+// Index is a noop and always returns nil.
+func (bi *BlockIndex) Index(header types.EventDataNewBlockHeader) error {
+	return nil
+}
+
+func (bi *BlockIndex) Search(ctx context.Context, q *query.Query) ([]int64, error) {
+	return []int64{}, nil
+}
@@ -0,0 +1,83 @@
+package psql
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/kv"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/state/txindex"
+	"github.com/tendermint/tendermint/types"
+)
+
+var _ txindex.BlockIndexer = (*BlockIndex)(nil)
+
+// BlockIndex writes the events of the blocks, returned by BeginBlock and
+// EndBlock, to the database of a TxIndex: they are the events without a
+// tx_id (see the block_events view).
+type BlockIndex struct {
+	txi *TxIndex
+}
+
+// BlockIndex returns the block indexer writing to the database of the
+// TxIndex.
+func (txi *TxIndex) BlockIndex() *BlockIndex {
+	return &BlockIndex{txi: txi}
+}
+
+// Search is not supported: query the database instead.
+func (bi *BlockIndex) Search(ctx context.Context, q *query.Query) ([]int64, error) {
+	return nil, txindex.ErrSearchNotSupported
+}
+
+// Index writes the events of the block, unless they have been written
+// already.
+func (bi *BlockIndex) Index(header types.EventDataNewBlockHeader) error {
+	height := header.Header.Height
+
+	dbTx, err := bi.txi.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback() // nolint: errcheck
+
+	blockID, err := bi.txi.insertBlock(dbTx, height, time.Now().UTC())
+	if err != nil {
+		return errors.Wrapf(err, "failed to insert block %d", height)
+	}
+	// the block.height event is always written
+	var indexed bool
+	err = dbTx.QueryRow(
+		`SELECT EXISTS (SELECT 1 FROM events WHERE block_id = $1 AND tx_id IS NULL)`,
+		blockID).Scan(&indexed)
+	if err != nil || indexed {
+		return err
+	}
+
+	var events []eventRow
+	for _, event := range blockEvents(header) {
+		events = append(events, eventRow{blockID: blockID, event: event})
+	}
+	if err := insertEvents(dbTx, events); err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+// blockEvents returns the events of BeginBlock and EndBlock, along with the
+// block.height of the block, as the kv indexer indexes them.
+func blockEvents(header types.EventDataNewBlockHeader) []abci.Event {
+	blockEvent := abci.Event{
+		Type: "block",
+		Attributes: []kv.Pair{
+			{Key: []byte("height"), Value: []byte(strconv.FormatInt(header.Header.Height, 10))},
+		},
+	}
+	events := append([]abci.Event{blockEvent}, header.ResultBeginBlock.Events...)
+	return append(events, header.ResultEndBlock.Events...)
+}
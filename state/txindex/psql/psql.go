@@ -0,0 +1,366 @@
+// Package psql has a transaction indexer writing the transactions and their
+// events to a PostgreSQL database (see schema.go), where they can be queried
+// with SQL, e.g. by block explorers. It doesn't search them itself: use it
+// along with the kv indexer (see txindex.MultiTxIndex) to serve /tx_search.
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq" // the "postgres" driver
+	"github.com/pkg/errors"
+	amino "github.com/tendermint/go-amino"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/kv"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/state/txindex"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	driverName = "postgres"
+
+	// maxParams is the maximum number of parameters of a PostgreSQL statement.
+	// The rows are inserted in batches of as many rows as a statement takes.
+	maxParams = 65535
+
+	// key of the advisory lock of the migrations, so that the nodes sharing a
+	// database migrate its schema one at a time
+	migrationLockKey = 0x7e4d1d3a
+)
+
+var cdc = amino.NewCodec()
+
+var _ txindex.TxIndexer = (*TxIndex)(nil)
+
+// TxIndex writes the transactions of a chain to a PostgreSQL database. It's
+// safe for concurrent use.
+type TxIndex struct {
+	db      *sql.DB
+	chainID string
+}
+
+// NewTxIndex connects to the database of connStr, and migrates its schema to
+// the latest version. The database can be shared by several chains.
+func NewTxIndex(connStr, chainID string) (*TxIndex, error) {
+	db, err := sql.Open(driverName, connStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to connect to the database")
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to migrate the schema")
+	}
+	return &TxIndex{db: db, chainID: chainID}, nil
+}
+
+// Close closes the connections to the database.
+func (txi *TxIndex) Close() error {
+	return txi.db.Close()
+}
+
+// Get is not supported: query the database instead.
+func (txi *TxIndex) Get(hash []byte) (*types.TxResult, error) {
+	return nil, txindex.ErrSearchNotSupported
+}
+
+// Search is not supported: query the database instead.
+func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*types.TxResult, error) {
+	return nil, txindex.ErrSearchNotSupported
+}
+
+// AddBatch writes the transactions of the batch, and their events, in a
+// single database transaction.
+func (txi *TxIndex) AddBatch(b *txindex.Batch) error {
+	return txi.index(b.Ops)
+}
+
+// Index writes the transaction and its events.
+func (txi *TxIndex) Index(result *types.TxResult) error {
+	return txi.index([]*types.TxResult{result})
+}
+
+// txKey identifies a row of tx_results.
+type txKey struct {
+	blockID int64
+	index   uint32
+}
+
+// index writes the results, skipping those already written, so that a block
+// can be indexed again, e.g. after a crash.
+func (txi *TxIndex) index(results []*types.TxResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+
+	dbTx, err := txi.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback() // nolint: errcheck
+
+	blockIDs := make(map[int64]int64) // by height
+	for _, result := range results {
+		if _, ok := blockIDs[result.Height]; ok {
+			continue
+		}
+		blockID, err := txi.insertBlock(dbTx, result.Height, now)
+		if err != nil {
+			return errors.Wrapf(err, "failed to insert block %d", result.Height)
+		}
+		blockIDs[result.Height] = blockID
+	}
+
+	txRows := make([][]interface{}, len(results))
+	for i, result := range results {
+		resultBytes, err := cdc.MarshalBinaryBare(result)
+		if err != nil {
+			return err
+		}
+		txRows[i] = []interface{}{
+			blockIDs[result.Height], result.Index, now, fmt.Sprintf("%X", result.Tx.Hash()), resultBytes,
+		}
+	}
+	txIDs := make(map[txKey]int64) // of the new rows only
+	err = insertRows(dbTx, "tx_results",
+		[]string{"block_id", "index", "created_at", "tx_hash", "tx_result"}, txRows,
+		"ON CONFLICT DO NOTHING RETURNING rowid, block_id, index",
+		func(rows *sql.Rows) error {
+			var (
+				id  int64
+				key txKey
+			)
+			if err := rows.Scan(&id, &key.blockID, &key.index); err != nil {
+				return err
+			}
+			txIDs[key] = id
+			return nil
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert txs")
+	}
+
+	var events []eventRow
+	for _, result := range results {
+		blockID := blockIDs[result.Height]
+		txID, ok := txIDs[txKey{blockID, result.Index}]
+		if !ok {
+			continue // already indexed
+		}
+		for _, event := range txEvents(result) {
+			events = append(events, eventRow{blockID: blockID, txID: txID, event: event})
+		}
+	}
+	if err := insertEvents(dbTx, events); err != nil {
+		return err
+	}
+
+	return dbTx.Commit()
+}
+
+// eventRow is an event of a tx, or of a block if txID is nil.
+type eventRow struct {
+	blockID int64
+	txID    interface{}
+	event   abci.Event
+}
+
+// insertEvents inserts the events, and their attributes. Any event with an
+// empty type is not inserted.
+func insertEvents(dbTx *sql.Tx, events []eventRow) error {
+	var (
+		inserted []eventRow
+		rows     [][]interface{}
+	)
+	for _, e := range events {
+		if e.event.Type == "" {
+			continue
+		}
+		inserted = append(inserted, e)
+		rows = append(rows, []interface{}{e.blockID, e.txID, e.event.Type})
+	}
+
+	var eventIDs []int64
+	err := insertRows(dbTx, "events", []string{"block_id", "tx_id", "type"}, rows,
+		"RETURNING rowid",
+		func(rows *sql.Rows) error {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			eventIDs = append(eventIDs, id)
+			return nil
+		})
+	if err != nil {
+		return errors.Wrap(err, "failed to insert events")
+	}
+	if len(eventIDs) != len(inserted) {
+		return fmt.Errorf("inserted %d events, got %d ids", len(inserted), len(eventIDs))
+	}
+	// the ids of the rows of a statement increase in the order of the rows
+	sort.Slice(eventIDs, func(i, j int) bool { return eventIDs[i] < eventIDs[j] })
+
+	var attrRows [][]interface{}
+	for i, e := range inserted {
+		for _, attr := range e.event.Attributes {
+			attrRows = append(attrRows, []interface{}{
+				eventIDs[i], string(attr.Key), e.event.Type + "." + string(attr.Key), string(attr.Value),
+			})
+		}
+	}
+	err = insertRows(dbTx, "attributes", []string{"event_id", "key", "composite_key", "value"}, attrRows, "", nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert attributes")
+	}
+	return nil
+}
+
+// insertBlock inserts the block, unless it exists, and returns its rowid.
+func (txi *TxIndex) insertBlock(dbTx *sql.Tx, height int64, now time.Time) (int64, error) {
+	_, err := dbTx.Exec(
+		`INSERT INTO blocks (height, chain_id, created_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		height, txi.chainID, now)
+	if err != nil {
+		return 0, err
+	}
+	var blockID int64
+	err = dbTx.QueryRow(
+		`SELECT rowid FROM blocks WHERE height = $1 AND chain_id = $2`,
+		height, txi.chainID).Scan(&blockID)
+	return blockID, err
+}
+
+// txEvents returns the events of the result, along with the tx.hash and
+// tx.height of the tx, as the kv indexer indexes them.
+func txEvents(result *types.TxResult) []abci.Event {
+	txEvent := abci.Event{
+		Type: "tx",
+		Attributes: []kv.Pair{
+			{Key: []byte("hash"), Value: []byte(fmt.Sprintf("%X", result.Tx.Hash()))},
+			{Key: []byte("height"), Value: []byte(strconv.FormatInt(result.Height, 10))},
+		},
+	}
+	return append([]abci.Event{txEvent}, result.Result.Events...)
+}
+
+// insertRows inserts the rows into the table, with as few statements as
+// possible. The suffix (e.g. a RETURNING clause) is appended to each
+// statement and, if scan isn't nil, the rows they return are passed to it.
+func insertRows(
+	dbTx *sql.Tx,
+	table string,
+	columns []string,
+	rows [][]interface{},
+	suffix string,
+	scan func(*sql.Rows) error,
+) error {
+	rowsPerStmt := maxParams / len(columns)
+	for start := 0; start < len(rows); start += rowsPerStmt {
+		end := start + rowsPerStmt
+		if end > len(rows) {
+			end = len(rows)
+		}
+		stmt, args := insertStatement(table, columns, rows[start:end], suffix)
+		if scan == nil {
+			if _, err := dbTx.Exec(stmt, args...); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := queryRows(dbTx, stmt, args, scan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryRows(dbTx *sql.Tx, stmt string, args []interface{}, scan func(*sql.Rows) error) error {
+	rows, err := dbTx.Query(stmt, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// insertStatement returns the statement inserting the rows into the table,
+// and its arguments.
+func insertStatement(table string, columns []string, rows [][]interface{}, suffix string) (string, []interface{}) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j, value := range row {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			args = append(args, value)
+			fmt.Fprintf(&sb, "$%d", len(args))
+		}
+		sb.WriteString(")")
+	}
+	if suffix != "" {
+		sb.WriteString(" ")
+		sb.WriteString(suffix)
+	}
+	return sb.String(), args
+}
+
+// migrate applies the migrations which haven't been applied to the database.
+func migrate(db *sql.DB) error {
+	dbTx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback() // nolint: errcheck
+
+	if _, err := dbTx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+		return err
+	}
+	if _, err := dbTx.Exec(`CREATE TABLE IF NOT EXISTS tendermint_schema (version INTEGER NOT NULL)`); err != nil {
+		return err
+	}
+	var version int
+	err = dbTx.QueryRow(`SELECT version FROM tendermint_schema`).Scan(&version)
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := dbTx.Exec(`INSERT INTO tendermint_schema (version) VALUES (0)`); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("the schema version %d is newer than the latest known one (%d)", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		if _, err := dbTx.Exec(migrations[i]); err != nil {
+			return errors.Wrapf(err, "migration %d", i+1)
+		}
+	}
+	if _, err := dbTx.Exec(`UPDATE tendermint_schema SET version = $1`, len(migrations)); err != nil {
+		return err
+	}
+	return dbTx.Commit()
+}
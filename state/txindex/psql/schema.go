@@ -0,0 +1,73 @@
+package psql
+
+// migrations are the changes of the schema, applied in order and once each:
+// the number of the migrations applied to a database is its version, in the
+// tendermint_schema table. Append new migrations; never edit the applied ones.
+var migrations = []string{
+	// 1: the blocks, their txs and the events of the txs, as rows of blocks,
+	// tx_results, events and attributes, joined by the views.
+	`
+CREATE TABLE blocks (
+	rowid      BIGSERIAL PRIMARY KEY,
+	height     BIGINT NOT NULL,
+	chain_id   VARCHAR NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (height, chain_id)
+);
+
+CREATE TABLE tx_results (
+	rowid      BIGSERIAL PRIMARY KEY,
+	block_id   BIGINT NOT NULL REFERENCES blocks(rowid),
+	index      INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	tx_hash    VARCHAR NOT NULL,
+	-- the amino encoding of the types.TxResult
+	tx_result  BYTEA NOT NULL,
+	UNIQUE (block_id, index)
+);
+CREATE INDEX idx_tx_results_tx_hash ON tx_results(tx_hash);
+
+-- tx_id is NULL for the events of a block, rather than of a tx
+CREATE TABLE events (
+	rowid    BIGSERIAL PRIMARY KEY,
+	block_id BIGINT NOT NULL REFERENCES blocks(rowid),
+	tx_id    BIGINT NULL REFERENCES tx_results(rowid),
+	type     VARCHAR NOT NULL
+);
+CREATE INDEX idx_events_block_id ON events(block_id);
+CREATE INDEX idx_events_tx_id ON events(tx_id);
+
+-- composite_key is "type.key", as in the queries of /tx_search
+CREATE TABLE attributes (
+	event_id      BIGINT NOT NULL REFERENCES events(rowid),
+	key           VARCHAR NOT NULL,
+	composite_key VARCHAR NOT NULL,
+	value         VARCHAR NULL
+);
+CREATE INDEX idx_attributes_event_id ON attributes(event_id);
+CREATE INDEX idx_attributes_composite_key_value ON attributes(composite_key, value);
+
+CREATE VIEW event_attributes AS
+	SELECT events.rowid AS event_id, events.block_id, events.tx_id, events.type,
+		attributes.key, attributes.composite_key, attributes.value
+	FROM events LEFT JOIN attributes ON events.rowid = attributes.event_id;
+
+CREATE VIEW tx_events AS
+	SELECT blocks.height, blocks.chain_id, tx_results.index, tx_results.tx_hash,
+		tx_results.created_at, event_attributes.type, event_attributes.key,
+		event_attributes.composite_key, event_attributes.value
+	FROM blocks
+	JOIN tx_results ON blocks.rowid = tx_results.block_id
+	JOIN event_attributes ON tx_results.rowid = event_attributes.tx_id;
+`,
+	// 2: the view of the events of the blocks, returned by BeginBlock and
+	// EndBlock.
+	`
+CREATE VIEW block_events AS
+	SELECT blocks.height, blocks.chain_id, blocks.created_at, event_attributes.type,
+		event_attributes.key, event_attributes.composite_key, event_attributes.value
+	FROM blocks
+	JOIN event_attributes ON blocks.rowid = event_attributes.block_id
+	WHERE event_attributes.tx_id IS NULL;
+`,
+}
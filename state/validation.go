@@ -93,9 +93,20 @@ func validateBlock(evidencePool EvidencePool, stateDB dbm.DB, state State, block
 		if err != nil {
 			return err
 		}
+		if !state.ConsensusParams.ABCI.VoteExtensionsEnabled(block.Height - 1) {
+			for i, commitSig := range block.LastCommit.Signatures {
+				if len(commitSig.Extension) > 0 {
+					return fmt.Errorf("LastCommit signature #%d has an extension, but vote extensions are disabled", i)
+				}
+			}
+		}
 	}
 
 	// Validate block Time
+	// NOTE: with proposer-based timestamps, the time is any time after the last
+	// block time (or the genesis time), its timeliness being only checked by
+	// the validators before prevoting.
+	pbts := state.ConsensusParams.Synchrony.ProposerBasedTimestamps
 	if block.Height > 1 {
 		if !block.Time.After(state.LastBlockTime) {
 			return fmt.Errorf("block time %v not greater than last block time %v",
@@ -104,16 +115,24 @@ func validateBlock(evidencePool EvidencePool, stateDB dbm.DB, state State, block
 			)
 		}
 
-		medianTime := MedianTime(block.LastCommit, state.LastValidators)
-		if !block.Time.Equal(medianTime) {
-			return fmt.Errorf("invalid block time. Expected %v, got %v",
-				medianTime,
-				block.Time,
-			)
+		if !pbts {
+			medianTime := MedianTime(block.LastCommit, state.LastValidators)
+			if !block.Time.Equal(medianTime) {
+				return fmt.Errorf("invalid block time. Expected %v, got %v",
+					medianTime,
+					block.Time,
+				)
+			}
 		}
 	} else if block.Height == 1 {
 		genesisTime := state.LastBlockTime
-		if !block.Time.Equal(genesisTime) {
+		if pbts && block.Time.Before(genesisTime) {
+			return fmt.Errorf("block time %v is before genesis time %v",
+				block.Time,
+				genesisTime,
+			)
+		}
+		if !pbts && !block.Time.Equal(genesisTime) {
 			return fmt.Errorf("block time %v is not equal to genesis time %v",
 				block.Time,
 				genesisTime,
@@ -149,6 +168,19 @@ func validateBlock(evidencePool EvidencePool, stateDB dbm.DB, state State, block
 		)
 	}
 
+	// Validate the VRF proof of the proposer, which seeds the proposer
+	// selection of the next height.
+	if state.ConsensusParams.Validator.IsVRFProposerSelection() {
+		_, proposer := state.Validators.GetByAddress(block.ProposerAddress)
+		_, err := types.VerifyProposerProof(proposer.PubKey, state.ChainID, state.VRFSeed(), block.Height,
+			block.ProposerProof)
+		if err != nil {
+			return fmt.Errorf("invalid block.Header.ProposerProof: %v", err)
+		}
+	} else if len(block.ProposerProof) > 0 {
+		return errors.New("block.Header.ProposerProof is set, but proposers aren't selected by VRF")
+	}
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package state_test
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
 	sm "github.com/tendermint/tendermint/state"
 	"github.com/tendermint/tendermint/types"
 	tmtime "github.com/tendermint/tendermint/types/time"
@@ -87,6 +90,101 @@ func TestValidateBlockHeader(t *testing.T) {
 	}
 }
 
+func TestValidateBlockTimeProposerBased(t *testing.T) {
+	proxyApp := newTestApp()
+	require.NoError(t, proxyApp.Start())
+	defer proxyApp.Stop()
+
+	state, stateDB, privVals := makeState(3, 1)
+	state.ConsensusParams.Synchrony.ProposerBasedTimestamps = true
+	blockExec := sm.NewBlockExecutor(
+		stateDB,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		mock.Mempool{},
+		sm.MockEvidencePool{},
+	)
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+
+	for height := int64(1); height < validationTestsStopHeight; height++ {
+		proposerAddr := state.Validators.GetProposer().Address
+
+		// any time after the last block time (or the genesis time) is valid
+		block, _ := state.MakeBlock(height, makeTxs(height), lastCommit, nil, proposerAddr)
+		require.False(t, block.Time.Before(state.LastBlockTime))
+		block.Time = block.Time.Add(time.Hour)
+		require.NoError(t, blockExec.ValidateBlock(state, block), "height %d", height)
+
+		block.Time = state.LastBlockTime.Add(-time.Millisecond)
+		require.Error(t, blockExec.ValidateBlock(state, block), "height %d", height)
+
+		var err error
+		state, _, lastCommit, err = makeAndCommitGoodBlock(state, height, lastCommit, proposerAddr, blockExec, privVals, nil)
+		require.NoError(t, err, "height %d", height)
+	}
+}
+
+func TestValidateBlockProposerProof(t *testing.T) {
+	proxyApp := newTestApp()
+	require.NoError(t, proxyApp.Start())
+	defer proxyApp.Stop()
+
+	state, stateDB, privVals := makeState(3, 1)
+	state.ConsensusParams.Validator.ProposerSelection = types.ProposerSelectionVRF
+	blockExec := sm.NewBlockExecutor(
+		stateDB,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		mock.Mempool{},
+		sm.MockEvidencePool{},
+	)
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+
+	for height := int64(1); height < validationTestsStopHeight; height++ {
+		proposerAddr := state.Validators.GetProposer().Address
+		_, other := state.Validators.GetByIndex(0)
+		if bytes.Equal(other.Address, proposerAddr) {
+			_, other = state.Validators.GetByIndex(1)
+		}
+		block, _ := state.MakeBlock(height, makeTxs(height), lastCommit, nil, proposerAddr)
+
+		// no proof, the proof of another validator or of another height
+		otherProof, err := types.ProveProposer(privVals[other.Address.String()], chainID, state.VRFSeed(), height)
+		require.NoError(t, err)
+		nextProof, err := types.ProveProposer(privVals[proposerAddr.String()], chainID, state.VRFSeed(), height+1)
+		require.NoError(t, err)
+		for _, proof := range [][]byte{nil, otherProof, nextProof} {
+			block.ProposerProof = proof
+			require.Error(t, blockExec.ValidateBlock(state, block), "height %d", height)
+		}
+
+		proof, err := types.ProveProposer(privVals[proposerAddr.String()], chainID, state.VRFSeed(), height)
+		require.NoError(t, err)
+		block.ProposerProof = proof
+		require.NoError(t, blockExec.ValidateBlock(state, block), "height %d", height)
+
+		blockID := types.BlockID{Hash: block.Hash(), PartsHeader: types.PartSetHeader{Total: 3, Hash: tmrand.Bytes(32)}}
+		state, err = blockExec.ApplyBlock(state, blockID, block)
+		require.NoError(t, err, "height %d", height)
+		// the proven output seeds the next height
+		output, err := vrf.ProofToHash(proof)
+		require.NoError(t, err)
+		require.Equal(t, output, state.VRFSeed())
+
+		lastCommit, err = makeValidCommit(height, blockID, state.LastValidators, privVals)
+		require.NoError(t, err)
+	}
+
+	// a proof is rejected if the proposers aren't selected by VRF
+	state.ConsensusParams.Validator.ProposerSelection = types.ProposerSelectionPriority
+	proposerAddr := state.Validators.GetProposer().Address
+	block, _ := state.MakeBlock(validationTestsStopHeight, makeTxs(validationTestsStopHeight), lastCommit, nil,
+		proposerAddr)
+	require.NoError(t, blockExec.ValidateBlock(state, block))
+	block.ProposerProof = make([]byte, vrf.ProofSize)
+	require.Error(t, blockExec.ValidateBlock(state, block))
+}
+
 func TestValidateBlockCommit(t *testing.T) {
 	proxyApp := newTestApp()
 	require.NoError(t, proxyApp.Start())
@@ -190,6 +288,48 @@ func TestValidateBlockCommit(t *testing.T) {
 	}
 }
 
+func TestValidateBlockVoteExtensions(t *testing.T) {
+	proxyApp := newTestApp()
+	require.NoError(t, proxyApp.Start())
+	defer proxyApp.Stop()
+
+	state, stateDB, privVals := makeState(1, 1)
+	blockExec := sm.NewBlockExecutor(
+		stateDB,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		mock.Mempool{},
+		sm.MockEvidencePool{},
+	)
+	proposerAddr := state.Validators.GetProposer().Address
+	state, blockID, _, err := makeAndCommitGoodBlock(
+		state, 1, types.NewCommit(0, 0, types.BlockID{}, nil), proposerAddr, blockExec, privVals, nil)
+	require.NoError(t, err)
+
+	// an extended commit for height 1
+	valIndex, _ := state.LastValidators.GetByAddress(proposerAddr)
+	vote := &types.Vote{
+		ValidatorAddress: proposerAddr,
+		ValidatorIndex:   valIndex,
+		Height:           1,
+		Round:            0,
+		Timestamp:        tmtime.Now(),
+		Type:             types.PrecommitType,
+		BlockID:          blockID,
+		Extension:        []byte("extension"),
+	}
+	require.NoError(t, privVals[proposerAddr.String()].SignVote(chainID, vote))
+	commit := types.NewCommit(1, 0, blockID, []types.CommitSig{vote.CommitSig()})
+	block, _ := state.MakeBlock(2, makeTxs(2), commit, nil, proposerAddr)
+
+	// rejected with vote extensions disabled
+	require.Error(t, blockExec.ValidateBlock(state, block))
+
+	// accepted with vote extensions enabled
+	state.ConsensusParams.ABCI.VoteExtensionsEnableHeight = 1
+	require.NoError(t, blockExec.ValidateBlock(state, block))
+}
+
 func TestValidateBlockEvidence(t *testing.T) {
 	proxyApp := newTestApp()
 	require.NoError(t, proxyApp.Start())
@@ -0,0 +1,173 @@
+package statesync
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/p2p"
+)
+
+func setupChunkQueue(t *testing.T) (*chunkQueue, func()) {
+	snapshot := &snapshot{Height: 3, Format: 1, Chunks: 5, Hash: []byte{7}}
+	queue, err := newChunkQueue(snapshot, "")
+	require.NoError(t, err)
+	return queue, func() { queue.Close() }
+}
+
+func TestNewChunkQueue(t *testing.T) {
+	_, err := newChunkQueue(&snapshot{Height: 3, Format: 1}, "")
+	assert.Error(t, err, "a snapshot without chunks is invalid")
+
+	queue, teardown := setupChunkQueue(t)
+	defer teardown()
+	_, err = os.Stat(queue.dir)
+	assert.NoError(t, err)
+
+	// the temp dir is removed once closed
+	require.NoError(t, queue.Close())
+	_, err = os.Stat(queue.dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestChunkQueueAddNext(t *testing.T) {
+	queue, teardown := setupChunkQueue(t)
+	defer teardown()
+
+	// chunks of other snapshots, or out of range, are rejected
+	for _, c := range []*chunk{
+		{Height: 2, Format: 1, Index: 0, Chunk: []byte{1}},
+		{Height: 3, Format: 2, Index: 0, Chunk: []byte{1}},
+		{Height: 3, Format: 1, Index: 5, Chunk: []byte{1}},
+		{Height: 3, Format: 1, Index: 0},
+	} {
+		_, err := queue.Add(c)
+		assert.Error(t, err)
+	}
+
+	// the chunks are returned in order, whatever the order they arrive in
+	for _, index := range []uint32{2, 0, 4, 1, 3} {
+		added, err := queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{byte(index)},
+			Sender: p2p.ID("peer")})
+		require.NoError(t, err)
+		assert.True(t, added)
+	}
+	added, err := queue.Add(&chunk{Height: 3, Format: 1, Index: 0, Chunk: []byte{9}})
+	require.NoError(t, err)
+	assert.False(t, added, "duplicate chunks are ignored")
+	assert.True(t, queue.Has(4))
+	assert.Equal(t, p2p.ID("peer"), queue.GetSender(4))
+
+	for index := uint32(0); index < 5; index++ {
+		c, err := queue.Next()
+		require.NoError(t, err)
+		assert.Equal(t, index, c.Index)
+		assert.Equal(t, []byte{byte(index)}, c.Chunk)
+	}
+	_, err = queue.Next()
+	assert.Equal(t, errDone, err)
+
+	// retried chunks are returned again, discarded ones once added again
+	queue.Retry(3)
+	c, err := queue.Next()
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, c.Index)
+	require.NoError(t, queue.Discard(1))
+	assert.False(t, queue.Has(1))
+	go func() {
+		queue.Add(&chunk{Height: 3, Format: 1, Index: 1, Chunk: []byte{11}}) // nolint: errcheck
+	}()
+	c, err = queue.Next()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{11}, c.Chunk)
+
+	queue.RetryAll()
+	c, err = queue.Next()
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, c.Index)
+}
+
+func TestChunkQueueAllocate(t *testing.T) {
+	queue, teardown := setupChunkQueue(t)
+	defer teardown()
+
+	for i := uint32(0); i < 5; i++ {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		assert.Equal(t, i, index)
+	}
+	_, err := queue.Allocate()
+	assert.Equal(t, errDone, err)
+
+	// discarded chunks are allocated again
+	_, err = queue.Add(&chunk{Height: 3, Format: 1, Index: 2, Chunk: []byte{2}})
+	require.NoError(t, err)
+	require.NoError(t, queue.Discard(2))
+	index, err := queue.Allocate()
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, index)
+}
+
+func TestChunkQueueDiscardSender(t *testing.T) {
+	queue, teardown := setupChunkQueue(t)
+	defer teardown()
+
+	for index := uint32(0); index < 3; index++ {
+		sender := p2p.ID("a")
+		if index == 1 {
+			sender = "b"
+		}
+		_, err := queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{byte(index)}, Sender: sender})
+		require.NoError(t, err)
+	}
+	_, err := queue.Next()
+	require.NoError(t, err)
+
+	// the chunks already returned are kept
+	require.NoError(t, queue.DiscardSender("a"))
+	assert.True(t, queue.Has(0))
+	assert.True(t, queue.Has(1))
+	assert.False(t, queue.Has(2))
+}
+
+func TestChunkQueueWaitFor(t *testing.T) {
+	queue, teardown := setupChunkQueue(t)
+	defer teardown()
+
+	waitFor := queue.WaitFor(1)
+	_, ok := <-queue.WaitFor(5)
+	assert.False(t, ok, "invalid index")
+
+	_, err := queue.Add(&chunk{Height: 3, Format: 1, Index: 1, Chunk: []byte{1}})
+	require.NoError(t, err)
+	index, ok := <-waitFor
+	assert.True(t, ok)
+	assert.EqualValues(t, 1, index)
+	index, ok = <-queue.WaitFor(1)
+	assert.True(t, ok, "already arrived")
+	assert.EqualValues(t, 1, index)
+
+	// the waiters are released once the queue is closed
+	waitFor = queue.WaitFor(2)
+	require.NoError(t, queue.Close())
+	_, ok = <-waitFor
+	assert.False(t, ok)
+	_, err = queue.Next()
+	assert.Equal(t, errDone, err)
+}
+
+func TestChunkQueueTempDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "statesync-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	queue, err := newChunkQueue(&snapshot{Height: 3, Format: 1, Chunks: 1}, dir)
+	require.NoError(t, err)
+	defer queue.Close()
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
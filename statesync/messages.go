@@ -0,0 +1,130 @@
+package statesync
+
+import (
+	"errors"
+	"fmt"
+
+	amino "github.com/tendermint/go-amino"
+)
+
+const (
+	// snapshotMsgSize is the maximum size of a snapshotsResponseMessage
+	snapshotMsgSize = int(4e6)
+	// chunkMsgSize is the maximum size of a chunkResponseMessage
+	chunkMsgSize = int(16e6)
+	// maxMsgSize is the maximum size of any message
+	maxMsgSize = chunkMsgSize
+)
+
+var cdc = amino.NewCodec()
+
+func init() {
+	RegisterMessages(cdc)
+}
+
+// Message is a message sent and received by the reactor.
+type Message interface {
+	ValidateBasic() error
+}
+
+// RegisterMessages registers the state sync messages for amino encoding.
+func RegisterMessages(cdc *amino.Codec) {
+	cdc.RegisterInterface((*Message)(nil), nil)
+	cdc.RegisterConcrete(&snapshotsRequestMessage{}, "tendermint/SnapshotsRequestMessage", nil)
+	cdc.RegisterConcrete(&snapshotsResponseMessage{}, "tendermint/SnapshotsResponseMessage", nil)
+	cdc.RegisterConcrete(&chunkRequestMessage{}, "tendermint/ChunkRequestMessage", nil)
+	cdc.RegisterConcrete(&chunkResponseMessage{}, "tendermint/ChunkResponseMessage", nil)
+}
+
+func decodeMsg(bz []byte) (msg Message, err error) {
+	if len(bz) > maxMsgSize {
+		return msg, fmt.Errorf("msg exceeds max size (%d > %d)", len(bz), maxMsgSize)
+	}
+	err = cdc.UnmarshalBinaryBare(bz, &msg)
+	return
+}
+
+//-------------------------------------
+
+// snapshotsRequestMessage requests the recent snapshots of a peer.
+type snapshotsRequestMessage struct{}
+
+// ValidateBasic implements Message.
+func (m *snapshotsRequestMessage) ValidateBasic() error {
+	return nil
+}
+
+func (m *snapshotsRequestMessage) String() string {
+	return "[snapshotsRequestMessage]"
+}
+
+// snapshotsResponseMessage advertises a snapshot, one per message.
+type snapshotsResponseMessage struct {
+	Height   uint64
+	Format   uint32
+	Chunks   uint32
+	Hash     []byte
+	Metadata []byte
+}
+
+// ValidateBasic implements Message.
+func (m *snapshotsResponseMessage) ValidateBasic() error {
+	if m.Height == 0 {
+		return errors.New("height cannot be 0")
+	}
+	if len(m.Hash) == 0 {
+		return errors.New("snapshot has no hash")
+	}
+	if m.Chunks == 0 {
+		return errors.New("snapshot has no chunks")
+	}
+	return nil
+}
+
+func (m *snapshotsResponseMessage) String() string {
+	return fmt.Sprintf("[snapshotsResponseMessage %v/%v %X]", m.Height, m.Format, m.Hash)
+}
+
+// chunkRequestMessage requests a chunk of a snapshot.
+type chunkRequestMessage struct {
+	Height uint64
+	Format uint32
+	Index  uint32
+}
+
+// ValidateBasic implements Message.
+func (m *chunkRequestMessage) ValidateBasic() error {
+	if m.Height == 0 {
+		return errors.New("height cannot be 0")
+	}
+	return nil
+}
+
+func (m *chunkRequestMessage) String() string {
+	return fmt.Sprintf("[chunkRequestMessage %v/%v/%v]", m.Height, m.Format, m.Index)
+}
+
+// chunkResponseMessage has a chunk of a snapshot, or Missing if the peer
+// doesn't have it.
+type chunkResponseMessage struct {
+	Height  uint64
+	Format  uint32
+	Index   uint32
+	Chunk   []byte
+	Missing bool
+}
+
+// ValidateBasic implements Message.
+func (m *chunkResponseMessage) ValidateBasic() error {
+	if m.Height == 0 {
+		return errors.New("height cannot be 0")
+	}
+	if m.Missing && len(m.Chunk) > 0 {
+		return errors.New("missing chunk cannot have contents")
+	}
+	return nil
+}
+
+func (m *chunkResponseMessage) String() string {
+	return fmt.Sprintf("[chunkResponseMessage %v/%v/%v missing=%v]", m.Height, m.Format, m.Index, m.Missing)
+}
@@ -0,0 +1,89 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abcicli "github.com/tendermint/tendermint/abci/client"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p/mock"
+	"github.com/tendermint/tendermint/proxy"
+)
+
+// recordingPeer records the messages sent to it.
+type recordingPeer struct {
+	*mock.Peer
+	msgs []Message
+}
+
+func (p *recordingPeer) Send(chID byte, msgBytes []byte) bool {
+	msg, err := decodeMsg(msgBytes)
+	if err != nil {
+		panic(err)
+	}
+	p.msgs = append(p.msgs, msg)
+	return true
+}
+
+func TestReactorServesSnapshots(t *testing.T) {
+	app, abciSnapshot := makeSnapshotApp(t, 3)
+	client := abcicli.NewLocalClient(nil, app)
+	r := NewReactor(proxy.NewAppConnSnapshot(client), proxy.NewAppConnQuery(client), "")
+	r.SetLogger(log.TestingLogger())
+	require.NoError(t, r.Start())
+	defer r.Stop()
+	peer := &recordingPeer{Peer: mock.NewPeer(nil)}
+
+	r.Receive(SnapshotChannel, peer, cdc.MustMarshalBinaryBare(&snapshotsRequestMessage{}))
+	require.Len(t, peer.msgs, 1)
+	assert.Equal(t, &snapshotsResponseMessage{
+		Height: abciSnapshot.Height,
+		Format: abciSnapshot.Format,
+		Chunks: abciSnapshot.Chunks,
+		Hash:   abciSnapshot.Hash,
+	}, peer.msgs[0])
+
+	r.Receive(ChunkChannel, peer, cdc.MustMarshalBinaryBare(&chunkRequestMessage{
+		Height: abciSnapshot.Height,
+		Format: abciSnapshot.Format,
+		Index:  0,
+	}))
+	require.Len(t, peer.msgs, 2)
+	res := peer.msgs[1].(*chunkResponseMessage)
+	assert.EqualValues(t, abciSnapshot.Height, res.Height)
+	assert.False(t, res.Missing)
+	assert.NotEmpty(t, res.Chunk)
+
+	// the chunks the app doesn't have are missing
+	r.Receive(ChunkChannel, peer, cdc.MustMarshalBinaryBare(&chunkRequestMessage{
+		Height: abciSnapshot.Height,
+		Format: abciSnapshot.Format,
+		Index:  abciSnapshot.Chunks,
+	}))
+	require.Len(t, peer.msgs, 3)
+	assert.True(t, peer.msgs[2].(*chunkResponseMessage).Missing)
+}
+
+func TestMessagesValidateBasic(t *testing.T) {
+	testCases := []struct {
+		msg     Message
+		wantErr bool
+	}{
+		{&snapshotsRequestMessage{}, false},
+		{&snapshotsResponseMessage{Height: 1, Format: 1, Chunks: 2, Hash: []byte{1}}, false},
+		{&snapshotsResponseMessage{Height: 0, Format: 1, Chunks: 2, Hash: []byte{1}}, true},
+		{&snapshotsResponseMessage{Height: 1, Format: 1, Chunks: 0, Hash: []byte{1}}, true},
+		{&snapshotsResponseMessage{Height: 1, Format: 1, Chunks: 2}, true},
+		{&chunkRequestMessage{Height: 1, Format: 1, Index: 0}, false},
+		{&chunkRequestMessage{Height: 0, Format: 1, Index: 0}, true},
+		{&chunkResponseMessage{Height: 1, Format: 1, Index: 0, Chunk: []byte{1}}, false},
+		{&chunkResponseMessage{Height: 1, Format: 1, Index: 0, Missing: true}, false},
+		{&chunkResponseMessage{Height: 1, Format: 1, Index: 0, Chunk: []byte{1}, Missing: true}, true},
+		{&chunkResponseMessage{Height: 0, Format: 1, Index: 0, Chunk: []byte{1}}, true},
+	}
+	for i, tc := range testCases {
+		assert.Equal(t, tc.wantErr, tc.msg.ValidateBasic() != nil, "#%d: %v", i, tc.msg)
+	}
+}
@@ -0,0 +1,101 @@
+package statesync
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+)
+
+// snapshotClient is the part of the RPC client serving the snapshots of a
+// node's app.
+type snapshotClient interface {
+	Snapshots() (*ctypes.ResultSnapshots, error)
+	SnapshotChunk(height uint64, format uint32, chunk uint32) (*ctypes.ResultSnapshotChunk, error)
+}
+
+// rpcSource is a snapshot source backed by the RPC server of a node, usually
+// one of the servers the state is verified against, so that operators can
+// bootstrap nodes from their own infrastructure rather than from p2p peers.
+// Its snapshots are listed with /snapshots, and their chunks fetched with
+// /snapshot_chunk.
+type rpcSource struct {
+	server   string
+	client   snapshotClient
+	addChunk func(*chunk) (bool, error)
+	logger   log.Logger
+}
+
+var _ snapshotSource = (*rpcSource)(nil)
+
+// newRPCSource creates a snapshot source for the given RPC server. The
+// chunks fetched from it are passed to addChunk.
+func newRPCSource(server string, addChunk func(*chunk) (bool, error), logger log.Logger) (*rpcSource, error) {
+	client, err := rpcclient.NewHTTP(server, "/websocket")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create RPC client for %s", server)
+	}
+	return &rpcSource{
+		server:   server,
+		client:   client,
+		addChunk: addChunk,
+		logger:   logger.With("server", server),
+	}, nil
+}
+
+// ID implements snapshotSource. It's also the sender of the chunks fetched
+// from the server, which the app can reject.
+func (s *rpcSource) ID() p2p.ID {
+	return p2p.ID("rpc:" + s.server)
+}
+
+// Send implements snapshotSource. Only chunk requests are sent to RPC
+// sources: the chunk is fetched in the background, and added like the ones
+// received from the peers. A chunk the server doesn't have is requested
+// again, possibly from another source, once the request times out.
+func (s *rpcSource) Send(chID byte, msgBytes []byte) bool {
+	msg, err := decodeMsg(msgBytes)
+	if err != nil {
+		return false
+	}
+	req, ok := msg.(*chunkRequestMessage)
+	if !ok || chID != ChunkChannel {
+		return false
+	}
+	go func() {
+		res, err := s.client.SnapshotChunk(req.Height, req.Format, req.Index)
+		if err != nil {
+			s.logger.Error("Failed to fetch chunk", "height", req.Height, "format", req.Format,
+				"chunk", req.Index, "err", err)
+			return
+		}
+		if res.Chunk == nil {
+			s.logger.Debug("Server is missing chunk", "height", req.Height, "format", req.Format,
+				"chunk", req.Index)
+			return
+		}
+		_, err = s.addChunk(&chunk{
+			Height: req.Height,
+			Format: req.Format,
+			Index:  req.Index,
+			Chunk:  res.Chunk,
+			Sender: s.ID(),
+		})
+		if err != nil {
+			s.logger.Error("Failed to add chunk", "height", req.Height, "format", req.Format,
+				"chunk", req.Index, "err", err)
+		}
+	}()
+	return true
+}
+
+// Snapshots returns the recentSnapshots most recent snapshots of the server.
+func (s *rpcSource) Snapshots() ([]*snapshot, error) {
+	res, err := s.client.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	return recentSnapshotsOf(res.Snapshots, recentSnapshots), nil
+}
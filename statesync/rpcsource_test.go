@@ -0,0 +1,83 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// appSnapshotClient serves the snapshots of an app, as its node's RPC server
+// would.
+type appSnapshotClient struct {
+	app abci.Application
+}
+
+func (c appSnapshotClient) Snapshots() (*ctypes.ResultSnapshots, error) {
+	return &ctypes.ResultSnapshots{Snapshots: c.app.ListSnapshots(abci.RequestListSnapshots{}).Snapshots}, nil
+}
+
+func (c appSnapshotClient) SnapshotChunk(height uint64, format uint32, chunk uint32) (
+	*ctypes.ResultSnapshotChunk, error) {
+	res := c.app.LoadSnapshotChunk(abci.RequestLoadSnapshotChunk{Height: height, Format: format, Chunk: chunk})
+	return &ctypes.ResultSnapshotChunk{Chunk: res.Chunk}, nil
+}
+
+func TestRPCSourceSync(t *testing.T) {
+	source, abciSnapshot := makeSnapshotApp(t, 3)
+	info := source.Info(abci.RequestInfo{})
+	sp := &fixedStateProvider{
+		state:  sm.State{LastBlockHeight: 3, AppHash: info.LastBlockAppHash},
+		commit: &types.Commit{Height: 3},
+	}
+	restored := kvstore.NewApplication()
+	syncer := newTestSyncer(restored, sp)
+	src := &rpcSource{
+		server:   "tcp://127.0.0.1:26657",
+		client:   appSnapshotClient{source},
+		addChunk: syncer.AddChunk,
+		logger:   log.TestingLogger(),
+	}
+	assert.Equal(t, p2p.ID("rpc:tcp://127.0.0.1:26657"), src.ID())
+
+	snapshots, err := src.Snapshots()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, abciSnapshot.Hash, snapshots[0].Hash)
+	added, err := syncer.AddSnapshot(src, snapshots[0])
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// the chunks are fetched from the server alone
+	state, _, err := syncer.SyncAny(0)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, state.LastBlockHeight)
+	assert.Equal(t, info, restored.Info(abci.RequestInfo{}))
+}
+
+func TestRPCSourceSend(t *testing.T) {
+	_, abciSnapshot := makeSnapshotApp(t, 3)
+	src := &rpcSource{
+		client: appSnapshotClient{kvstore.NewApplication()},
+		addChunk: func(*chunk) (bool, error) {
+			panic("the app has no chunks")
+		},
+		logger: log.TestingLogger(),
+	}
+
+	// only chunk requests are sent
+	assert.False(t, src.Send(SnapshotChannel, cdc.MustMarshalBinaryBare(&snapshotsRequestMessage{})))
+	assert.False(t, src.Send(ChunkChannel, []byte{1, 2, 3}))
+	assert.True(t, src.Send(ChunkChannel, cdc.MustMarshalBinaryBare(&chunkRequestMessage{
+		Height: abciSnapshot.Height,
+		Format: abciSnapshot.Format,
+	})))
+}
@@ -0,0 +1,129 @@
+package statesync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/mock"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// fixedStateProvider is a StateProvider returning fixed values, the app hash
+// of a height being the height itself.
+type fixedStateProvider struct {
+	state  sm.State
+	commit *types.Commit
+	err    error
+}
+
+var _ StateProvider = (*fixedStateProvider)(nil)
+
+func (sp *fixedStateProvider) AppHash(height int64) ([]byte, error) {
+	if sp.err != nil {
+		return nil, sp.err
+	}
+	if height == sp.state.LastBlockHeight {
+		return sp.state.AppHash, nil
+	}
+	return []byte{byte(height)}, nil
+}
+
+func (sp *fixedStateProvider) Commit(height int64) (*types.Commit, error) {
+	return sp.commit, sp.err
+}
+
+func (sp *fixedStateProvider) State(height int64) (sm.State, error) {
+	return sp.state, sp.err
+}
+
+func TestSnapshotPoolAdd(t *testing.T) {
+	pool := newSnapshotPool(&fixedStateProvider{})
+	peerA, peerB := mock.NewPeer(nil), mock.NewPeer(nil)
+	s := &snapshot{Height: 3, Format: 1, Chunks: 1, Hash: []byte{1}}
+
+	added, err := pool.Add(peerA, s)
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, []byte{3}, s.trustedAppHash)
+
+	// the same snapshot from another peer is not new
+	added, err = pool.Add(peerB, &snapshot{Height: 3, Format: 1, Chunks: 1, Hash: []byte{1}})
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Len(t, pool.GetPeers(s), 2)
+	assert.Contains(t, []p2p.Peer{peerA, peerB}, pool.GetPeer(s))
+
+	// the snapshots are dropped once none of their peers is left
+	pool.RemovePeer(peerA.ID())
+	assert.Len(t, pool.GetPeers(s), 1)
+	pool.RemovePeer(peerB.ID())
+	assert.Nil(t, pool.Best())
+	assert.Nil(t, pool.GetPeer(s))
+}
+
+func TestSnapshotPoolAddLimit(t *testing.T) {
+	pool := newSnapshotPool(&fixedStateProvider{})
+	peer := mock.NewPeer(nil)
+	for i := 0; i < recentSnapshots+1; i++ {
+		added, err := pool.Add(peer, &snapshot{Height: uint64(i + 1), Format: 1, Chunks: 1, Hash: []byte{1}})
+		require.NoError(t, err)
+		assert.Equal(t, i < recentSnapshots, added)
+	}
+}
+
+func TestSnapshotPoolRanked(t *testing.T) {
+	pool := newSnapshotPool(&fixedStateProvider{})
+	peerA, peerB := mock.NewPeer(nil), mock.NewPeer(nil)
+	snapshots := []*snapshot{
+		{Height: 2, Format: 2, Chunks: 1, Hash: []byte{1}},
+		{Height: 2, Format: 1, Chunks: 1, Hash: []byte{2}},
+		{Height: 1, Format: 2, Chunks: 1, Hash: []byte{3}},
+		{Height: 2, Format: 1, Chunks: 1, Hash: []byte{4}},
+	}
+	for _, s := range snapshots {
+		_, err := pool.Add(peerA, s)
+		require.NoError(t, err)
+	}
+	// the last one has more peers than the one of the same height and format
+	_, err := pool.Add(peerB, &snapshot{Height: 2, Format: 1, Chunks: 1, Hash: []byte{4}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*snapshot{snapshots[0], snapshots[3], snapshots[1], snapshots[2]}, pool.Ranked())
+	assert.Equal(t, snapshots[0], pool.Best())
+}
+
+func TestSnapshotPoolReject(t *testing.T) {
+	pool := newSnapshotPool(&fixedStateProvider{})
+	peerA, peerB := mock.NewPeer(nil), mock.NewPeer(nil)
+	snapshots := []*snapshot{
+		{Height: 3, Format: 1, Chunks: 1, Hash: []byte{1}},
+		{Height: 2, Format: 2, Chunks: 1, Hash: []byte{2}},
+		{Height: 1, Format: 1, Chunks: 1, Hash: []byte{3}},
+	}
+	for _, s := range snapshots {
+		_, err := pool.Add(peerA, s)
+		require.NoError(t, err)
+	}
+
+	pool.Reject(snapshots[0])
+	assert.Equal(t, []*snapshot{snapshots[1], snapshots[2]}, pool.Ranked())
+	added, err := pool.Add(peerB, &snapshot{Height: 3, Format: 1, Chunks: 1, Hash: []byte{1}})
+	require.NoError(t, err)
+	assert.False(t, added, "rejected snapshots are not added again")
+
+	pool.RejectFormat(1)
+	assert.Equal(t, []*snapshot{snapshots[1]}, pool.Ranked())
+	added, err = pool.Add(peerB, &snapshot{Height: 4, Format: 1, Chunks: 1, Hash: []byte{4}})
+	require.NoError(t, err)
+	assert.False(t, added, "snapshots of rejected formats are not added")
+
+	pool.RejectPeer(peerA.ID())
+	assert.Empty(t, pool.Ranked())
+	added, err = pool.Add(peerA, &snapshot{Height: 5, Format: 2, Chunks: 1, Hash: []byte{5}})
+	require.NoError(t, err)
+	assert.False(t, added, "snapshots of rejected peers are not added")
+}
@@ -0,0 +1,185 @@
+/*
+Package statesync provides the building blocks for bootstrapping a node from a
+recent height instead of replaying the chain from genesis.
+
+The Reactor discovers the snapshots of the application taken by the peers,
+and by the RPC servers given to Sync, offers them to the local application
+and fetches their chunks, until one is restored (see the snapshot methods of
+ABCI). The height and app hash of the
+snapshots are checked against a StateProvider, which obtains the light client
+verified state, app hash and commit at a given height from a list of RPC
+servers run by the operator, so that no trusted header has to be gossiped over
+p2p. The node then starts from the state at the height of the snapshot.
+*/
+package statesync
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tendermint/tendermint/crypto/vrf"
+	"github.com/tendermint/tendermint/libs/log"
+	lite "github.com/tendermint/tendermint/lite2"
+	dbs "github.com/tendermint/tendermint/lite2/store/db"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// StateProvider provides light client verified state.
+type StateProvider interface {
+	// AppHash returns the app hash after the block at the given height has
+	// been committed.
+	AppHash(height int64) ([]byte, error)
+	// Commit returns the commit of the block at the given height.
+	Commit(height int64) (*types.Commit, error)
+	// State returns the state after the block at the given height has been
+	// committed, i.e. the state a node restored at that height starts from.
+	State(height int64) (sm.State, error)
+}
+
+// ParamsProvider returns the consensus params at the given height. They are
+// verified against the light client verified header by the StateProvider.
+type ParamsProvider func(height int64) (types.ConsensusParams, error)
+
+type lightClientStateProvider struct {
+	mtx    sync.Mutex // the light client is not safe for concurrent use
+	lc     *lite.Client
+	params ParamsProvider
+}
+
+var _ StateProvider = (*lightClientStateProvider)(nil)
+
+// NewRPCStateProvider returns a StateProvider which verifies headers with a
+// light client, using the first of the given RPC servers as the primary and
+// the others as witnesses, so at least two servers are required. The trust
+// options are usually a recent height and header hash obtained out of band.
+func NewRPCStateProvider(
+	chainID string,
+	servers []string,
+	trustOptions lite.TrustOptions,
+	logger log.Logger,
+) (StateProvider, error) {
+	if len(servers) < 2 {
+		return nil, errors.Errorf("at least 2 RPC servers are required, got %d", len(servers))
+	}
+	lc, err := lite.NewHTTPClient(chainID, trustOptions, servers[0], servers[1:],
+		dbs.New(dbm.NewMemDB(), chainID), lite.Logger(logger))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create light client")
+	}
+	primary, err := rpcclient.NewHTTP(servers[0], "/websocket")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create RPC client for %s", servers[0])
+	}
+	return NewStateProvider(lc, func(height int64) (types.ConsensusParams, error) {
+		res, err := primary.ConsensusParams(&height)
+		if err != nil {
+			return types.ConsensusParams{}, err
+		}
+		return res.ConsensusParams, nil
+	}), nil
+}
+
+// NewStateProvider returns a StateProvider verifying headers with the given
+// light client and obtaining consensus params from params.
+func NewStateProvider(lc *lite.Client, params ParamsProvider) StateProvider {
+	return &lightClientStateProvider{lc: lc, params: params}
+}
+
+// AppHash implements StateProvider. The app hash of a block is only included
+// in the next header, so the header at height+1 is verified.
+func (s *lightClientStateProvider) AppHash(height int64) ([]byte, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	header, err := s.lc.VerifyHeaderAtHeight(height+1, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return header.AppHash, nil
+}
+
+// Commit implements StateProvider.
+func (s *lightClientStateProvider) Commit(height int64) (*types.Commit, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	header, err := s.lc.VerifyHeaderAtHeight(height, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return header.Commit, nil
+}
+
+// State implements StateProvider. It verifies the headers at height, height+1
+// and height+2, as the latter contain the results and validators of the
+// blocks following height.
+func (s *lightClientStateProvider) State(height int64) (sm.State, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	header, err := s.lc.VerifyHeaderAtHeight(height, now)
+	if err != nil {
+		return sm.State{}, err
+	}
+	nextHeader, err := s.lc.VerifyHeaderAtHeight(height+1, now)
+	if err != nil {
+		return sm.State{}, err
+	}
+	if _, err := s.lc.VerifyHeaderAtHeight(height+2, now); err != nil {
+		return sm.State{}, err
+	}
+
+	state := sm.State{
+		Version: sm.Version{
+			Consensus: nextHeader.Version,
+			Software:  version.TMCoreSemVer,
+		},
+		ChainID:         s.lc.ChainID(),
+		LastBlockHeight: header.Height,
+		LastBlockID:     header.Commit.BlockID,
+		LastBlockTime:   header.Time,
+
+		// the validator sets are bootstrapped up to height+2 (see
+		// sm.BootstrapState), so that's where the history starts
+		LastHeightValidatorsChanged: height + 2,
+
+		LastResultsHash: nextHeader.LastResultsHash,
+		AppHash:         nextHeader.AppHash,
+	}
+	if len(header.ProposerProof) > 0 {
+		// the header was verified, so its proof is valid
+		if state.LastVRFOutput, err = vrf.ProofToHash(header.ProposerProof); err != nil {
+			return sm.State{}, err
+		}
+	}
+	if state.LastValidators, _, err = s.lc.TrustedValidatorSet(height); err != nil {
+		return sm.State{}, err
+	}
+	if state.Validators, _, err = s.lc.TrustedValidatorSet(height + 1); err != nil {
+		return sm.State{}, err
+	}
+	if state.NextValidators, _, err = s.lc.TrustedValidatorSet(height + 2); err != nil {
+		return sm.State{}, err
+	}
+
+	params, err := s.params(nextHeader.Height)
+	if err != nil {
+		return sm.State{}, errors.Wrap(err, "failed to fetch consensus params")
+	}
+	if !bytes.Equal(params.Hash(), nextHeader.ConsensusHash) {
+		return sm.State{}, errors.Errorf("consensus params hash %X does not match the header's %X at height %d",
+			params.Hash(), nextHeader.ConsensusHash, nextHeader.Height)
+	}
+	state.ConsensusParams = params
+	state.LastHeightConsensusParamsChanged = nextHeader.Height
+
+	return state, nil
+}
@@ -0,0 +1,121 @@
+package statesync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	lite "github.com/tendermint/tendermint/lite2"
+	"github.com/tendermint/tendermint/lite2/provider"
+	"github.com/tendermint/tendermint/lite2/provider/mock"
+	dbs "github.com/tendermint/tendermint/lite2/store/db"
+	"github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const chainID = "statesync-test"
+
+// makeChain returns the signed headers and validator sets of a chain of the
+// given length, signed by a single validator.
+func makeChain(t *testing.T, length int64, params types.ConsensusParams) (
+	map[int64]*types.SignedHeader, map[int64]*types.ValidatorSet) {
+
+	pv := types.NewMockPV()
+	vals := types.NewValidatorSet([]*types.Validator{types.NewValidator(pv.GetPubKey(), 10)})
+	start := time.Now().Add(-time.Hour)
+
+	headers := make(map[int64]*types.SignedHeader)
+	valSets := make(map[int64]*types.ValidatorSet)
+	var lastBlockID types.BlockID
+	for height := int64(1); height <= length; height++ {
+		header := &types.Header{
+			ChainID:            chainID,
+			Height:             height,
+			Time:               start.Add(time.Duration(height) * time.Second),
+			LastBlockID:        lastBlockID,
+			ValidatorsHash:     vals.Hash(),
+			NextValidatorsHash: vals.Hash(),
+			ConsensusHash:      params.Hash(),
+			AppHash:            []byte{byte(height)},
+			LastResultsHash:    []byte{byte(height), 1},
+		}
+		blockID := types.BlockID{
+			Hash:        header.Hash(),
+			PartsHeader: types.PartSetHeader{Total: 1, Hash: header.Hash()},
+		}
+		vote := &types.Vote{
+			Type:             types.PrecommitType,
+			Height:           height,
+			BlockID:          blockID,
+			Timestamp:        header.Time.Add(time.Second),
+			ValidatorAddress: pv.GetPubKey().Address(),
+		}
+		require.NoError(t, pv.SignVote(chainID, vote))
+		headers[height] = &types.SignedHeader{
+			Header: header,
+			Commit: types.NewCommit(height, 0, blockID, []types.CommitSig{vote.CommitSig()}),
+		}
+		valSets[height] = vals
+		lastBlockID = blockID
+	}
+	return headers, valSets
+}
+
+func newTestStateProvider(t *testing.T, params ParamsProvider) StateProvider {
+	headers, vals := makeChain(t, 5, *types.DefaultConsensusParams())
+	lc, err := lite.NewClient(chainID,
+		lite.TrustOptions{Period: 24 * time.Hour, Height: 1, Hash: headers[1].Hash()},
+		mock.New(chainID, headers, vals),
+		[]provider.Provider{mock.New(chainID, headers, vals)},
+		dbs.New(dbm.NewMemDB(), chainID),
+		lite.Logger(log.TestingLogger()))
+	require.NoError(t, err)
+	return NewStateProvider(lc, params)
+}
+
+func TestStateProvider(t *testing.T) {
+	sp := newTestStateProvider(t, func(height int64) (types.ConsensusParams, error) {
+		return *types.DefaultConsensusParams(), nil
+	})
+
+	appHash, err := sp.AppHash(2)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{3}, appHash)
+
+	commit, err := sp.Commit(2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, commit.Height)
+
+	state, err := sp.State(2)
+	require.NoError(t, err)
+	assert.Equal(t, chainID, state.ChainID)
+	assert.EqualValues(t, 2, state.LastBlockHeight)
+	assert.Equal(t, commit.BlockID, state.LastBlockID)
+	assert.Equal(t, []byte{3}, state.AppHash)
+	assert.Equal(t, []byte{3, 1}, state.LastResultsHash)
+	assert.Equal(t, *types.DefaultConsensusParams(), state.ConsensusParams)
+	assert.NotNil(t, state.NextValidators)
+
+	// height+2 must be available
+	_, err = sp.State(4)
+	assert.Error(t, err)
+}
+
+func TestStateProviderRejectsUnverifiedParams(t *testing.T) {
+	sp := newTestStateProvider(t, func(height int64) (types.ConsensusParams, error) {
+		params := *types.DefaultConsensusParams()
+		params.Block.MaxGas = 1
+		return params, nil
+	})
+	_, err := sp.State(2)
+	assert.Error(t, err)
+}
+
+func TestNewRPCStateProviderRequiresWitness(t *testing.T) {
+	_, err := NewRPCStateProvider(chainID, []string{"tcp://127.0.0.1:26657"},
+		lite.TrustOptions{Period: time.Hour, Height: 1, Hash: make([]byte, 32)}, log.TestingLogger())
+	assert.Error(t, err)
+}
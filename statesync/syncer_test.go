@@ -0,0 +1,146 @@
+package statesync
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abcicli "github.com/tendermint/tendermint/abci/client"
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p/mock"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// makeSnapshotApp returns a kvstore app with a snapshot of the given height,
+// with a key/value pair committed at each height.
+func makeSnapshotApp(t *testing.T, height int64) (*kvstore.Application, *abci.Snapshot) {
+	app := kvstore.NewApplication()
+	for h := int64(1); h <= height; h++ {
+		app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: h}})
+		app.DeliverTx(abci.RequestDeliverTx{Tx: []byte{'k', byte(h), '=', 'v'}})
+		app.EndBlock(abci.RequestEndBlock{Height: h})
+		app.Commit()
+	}
+	res := app.CreateSnapshot(abci.RequestCreateSnapshot{})
+	require.True(t, res.IsOK(), res)
+	snapshots := app.ListSnapshots(abci.RequestListSnapshots{}).Snapshots
+	require.Len(t, snapshots, 1)
+	return app, snapshots[0]
+}
+
+func newTestSyncer(app abci.Application, sp StateProvider) *syncer {
+	client := abcicli.NewLocalClient(nil, app)
+	return newSyncer(log.TestingLogger(), proxy.NewAppConnSnapshot(client), proxy.NewAppConnQuery(client), sp, "")
+}
+
+// addChunks adds the chunks of the snapshot to the queue, as if fetched.
+func addChunks(t *testing.T, queue *chunkQueue, app *kvstore.Application, s *snapshot) {
+	for i := uint32(0); i < s.Chunks; i++ {
+		res := app.LoadSnapshotChunk(abci.RequestLoadSnapshotChunk{Height: s.Height, Format: s.Format, Chunk: i})
+		_, err := queue.Add(&chunk{Height: s.Height, Format: s.Format, Index: i, Chunk: res.Chunk})
+		require.NoError(t, err)
+	}
+}
+
+func TestSyncerSync(t *testing.T) {
+	source, abciSnapshot := makeSnapshotApp(t, 3)
+	info := source.Info(abci.RequestInfo{})
+	sp := &fixedStateProvider{
+		state:  sm.State{ChainID: "test-chain", LastBlockHeight: 3, AppHash: info.LastBlockAppHash},
+		commit: &types.Commit{Height: 3},
+	}
+	restored := kvstore.NewApplication()
+	syncer := newTestSyncer(restored, sp)
+
+	peer := mock.NewPeer(nil)
+	s := &snapshot{
+		Height: abciSnapshot.Height,
+		Format: abciSnapshot.Format,
+		Chunks: abciSnapshot.Chunks,
+		Hash:   abciSnapshot.Hash,
+	}
+	added, err := syncer.AddSnapshot(peer, s)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	queue, err := newChunkQueue(s, "")
+	require.NoError(t, err)
+	defer queue.Close()
+	addChunks(t, queue, source, s)
+
+	state, commit, err := syncer.Sync(s, queue)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, state.LastBlockHeight)
+	assert.Equal(t, sp.commit, commit)
+	assert.Equal(t, info, restored.Info(abci.RequestInfo{}))
+	res := restored.Query(abci.RequestQuery{Data: []byte{'k', 2}})
+	assert.Equal(t, []byte("v"), res.Value)
+
+	// the app is ahead of the snapshot now: it aborts
+	_, _, err = syncer.SyncAny(0)
+	assert.True(t, errors.Is(err, errAbort), err)
+}
+
+func TestSyncerSyncAnyRejects(t *testing.T) {
+	source, abciSnapshot := makeSnapshotApp(t, 3)
+	info := source.Info(abci.RequestInfo{})
+	sp := &fixedStateProvider{
+		state:  sm.State{LastBlockHeight: 3, AppHash: info.LastBlockAppHash},
+		commit: &types.Commit{Height: 3},
+	}
+	syncer := newTestSyncer(kvstore.NewApplication(), sp)
+	peer := mock.NewPeer(nil)
+
+	// the app rejects the format it doesn't know, and then the snapshot with
+	// an app hash which doesn't match, as its chunks are restored
+	_, err := syncer.AddSnapshot(peer, &snapshot{Height: 4, Format: 9, Chunks: 1, Hash: []byte{1}})
+	require.NoError(t, err)
+	_, err = syncer.AddSnapshot(peer, &snapshot{Height: 2, Format: abciSnapshot.Format, Chunks: 1, Hash: []byte{2}})
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		_, _, err := syncer.SyncAny(0)
+		done <- err
+	}()
+	// the chunk of the second snapshot, once its restore has started
+	for {
+		_, err := syncer.AddChunk(&chunk{Height: 2, Format: abciSnapshot.Format, Index: 0, Chunk: []byte("[]")})
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-done:
+		assert.Equal(t, errNoSnapshots, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("SyncAny did not return")
+	}
+	assert.Empty(t, syncer.snapshots.Ranked())
+}
+
+func TestSyncerVerifyApp(t *testing.T) {
+	source, abciSnapshot := makeSnapshotApp(t, 3)
+	syncer := newTestSyncer(source, &fixedStateProvider{})
+
+	s := &snapshot{Height: abciSnapshot.Height, trustedAppHash: source.Info(abci.RequestInfo{}).LastBlockAppHash}
+	_, err := syncer.verifyApp(s)
+	assert.NoError(t, err)
+
+	s.Height = 2
+	_, err = syncer.verifyApp(s)
+	assert.Equal(t, errVerifyFailed, err)
+
+	s.Height = 3
+	s.trustedAppHash = []byte{1}
+	_, err = syncer.verifyApp(s)
+	assert.Equal(t, errVerifyFailed, err)
+}
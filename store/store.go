@@ -9,6 +9,7 @@ import (
 
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/libs/failpoint"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -30,7 +31,12 @@ the Commit data outside the Block. (TODO)
 type BlockStore struct {
 	db dbm.DB
 
+	// serializes the saves above the height and below the base, which can
+	// happen concurrently while backfilling
+	saveMtx sync.Mutex
+
 	mtx    sync.RWMutex
+	base   int64
 	height int64
 }
 
@@ -39,11 +45,20 @@ type BlockStore struct {
 func NewBlockStore(db dbm.DB) *BlockStore {
 	bsjson := LoadBlockStoreStateJSON(db)
 	return &BlockStore{
+		base:   bsjson.Base,
 		height: bsjson.Height,
 		db:     db,
 	}
 }
 
+// Base returns the first known contiguous block height, or 0 for empty block
+// stores. It's above 1 if the node started from a snapshot (see statesync).
+func (bs *BlockStore) Base() int64 {
+	bs.mtx.RLock()
+	defer bs.mtx.RUnlock()
+	return bs.base
+}
+
 // Height returns the last known contiguous block height.
 func (bs *BlockStore) Height() int64 {
 	bs.mtx.RLock()
@@ -172,6 +187,8 @@ func (bs *BlockStore) LoadSeenCommit(height int64) *types.Commit {
 }
 
 // SaveBlock persists the given block, blockParts, and seenCommit to the underlying db.
+// The first block saved to an empty store can be of any height, which becomes
+// the base of the store; the others must follow the last one.
 // blockParts: Must be parts of the block
 // seenCommit: The +2/3 precommits that were seen which committed at height.
 //             If all the nodes restart after committing a block,
@@ -182,16 +199,79 @@ func (bs *BlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, s
 		panic("BlockStore can only save a non-nil block")
 	}
 
+	bs.saveMtx.Lock()
+	defer bs.saveMtx.Unlock()
+
 	height := block.Height
-	hash := block.Hash()
 
-	if g, w := height, bs.Height()+1; g != w {
+	if g, w := height, bs.Height()+1; bs.Base() > 0 && g != w {
 		panic(fmt.Sprintf("BlockStore can only save contiguous blocks. Wanted %v, got %v", w, g))
 	}
 	if !blockParts.IsComplete() {
 		panic(fmt.Sprintf("BlockStore can only save complete block part sets"))
 	}
 
+	bs.saveBlockData(block, blockParts, seenCommit)
+
+	// Save new BlockStoreStateJSON descriptor
+	bs.mtx.Lock()
+	bs.height = height
+	if bs.base == 0 {
+		bs.base = height
+	}
+	bsJSON := BlockStoreStateJSON{Base: bs.base, Height: bs.height}
+	bs.mtx.Unlock()
+	bsJSON.Save(bs.db)
+
+	if err := failpoint.Inject("store/before-sync-block"); err != nil {
+		panic(err)
+	}
+
+	// Flush
+	bs.db.SetSync(nil, nil)
+}
+
+// SaveBlockBelowBase persists the block preceding the base, lowering the base
+// to its height, e.g. when backfilling the blocks below the height a node
+// restored a snapshot of. An empty store saves it as its first block. The
+// block isn't verified: the caller must have checked it against the
+// LastBlockID of the block above it.
+func (bs *BlockStore) SaveBlockBelowBase(block *types.Block, blockParts *types.PartSet, seenCommit *types.Commit) {
+	if block == nil {
+		panic("BlockStore can only save a non-nil block")
+	}
+
+	bs.saveMtx.Lock()
+	defer bs.saveMtx.Unlock()
+
+	height := block.Height
+
+	if g, w := height, bs.Base()-1; bs.Base() > 0 && g != w {
+		panic(fmt.Sprintf("BlockStore can only save the block below the base. Wanted %v, got %v", w, g))
+	}
+	if !blockParts.IsComplete() {
+		panic(fmt.Sprintf("BlockStore can only save complete block part sets"))
+	}
+
+	bs.saveBlockData(block, blockParts, seenCommit)
+
+	bs.mtx.Lock()
+	bs.base = height
+	if bs.height == 0 {
+		bs.height = height
+	}
+	bsJSON := BlockStoreStateJSON{Base: bs.base, Height: bs.height}
+	bs.mtx.Unlock()
+	bsJSON.Save(bs.db)
+
+	bs.db.SetSync(nil, nil)
+}
+
+// saveBlockData saves the meta, parts and commits of the block.
+func (bs *BlockStore) saveBlockData(block *types.Block, blockParts *types.PartSet, seenCommit *types.Commit) {
+	height := block.Height
+	hash := block.Hash()
+
 	// Save block meta
 	blockMeta := types.NewBlockMeta(block, blockParts)
 	metaBytes := cdc.MustMarshalBinaryBare(blockMeta)
@@ -212,27 +292,21 @@ func (bs *BlockStore) SaveBlock(block *types.Block, blockParts *types.PartSet, s
 	// NOTE: we can delete this at a later height
 	seenCommitBytes := cdc.MustMarshalBinaryBare(seenCommit)
 	bs.db.Set(calcSeenCommitKey(height), seenCommitBytes)
-
-	// Save new BlockStoreStateJSON descriptor
-	BlockStoreStateJSON{Height: height}.Save(bs.db)
-
-	// Done!
-	bs.mtx.Lock()
-	bs.height = height
-	bs.mtx.Unlock()
-
-	// Flush
-	bs.db.SetSync(nil, nil)
 }
 
 func (bs *BlockStore) saveBlockPart(height int64, index int, part *types.Part) {
-	if height != bs.Height()+1 {
-		panic(fmt.Sprintf("BlockStore can only save contiguous blocks. Wanted %v, got %v", bs.Height()+1, height))
-	}
 	partBytes := cdc.MustMarshalBinaryBare(part)
 	bs.db.Set(calcBlockPartKey(height, index), partBytes)
 }
 
+// SaveSeenCommit saves the seen commit of the given height, without its block,
+// e.g. the commit of the height a node restored a snapshot of, so that
+// consensus can start from it.
+func (bs *BlockStore) SaveSeenCommit(height int64, seenCommit *types.Commit) {
+	seenCommitBytes := cdc.MustMarshalBinaryBare(seenCommit)
+	bs.db.SetSync(calcSeenCommitKey(height), seenCommitBytes)
+}
+
 //-----------------------------------------------------------------------------
 
 func calcBlockMetaKey(height int64) []byte {
@@ -261,6 +335,7 @@ var blockStoreKey = []byte("blockStore")
 
 // BlockStoreStateJSON is the block store state JSON structure.
 type BlockStoreStateJSON struct {
+	Base   int64 `json:"base"`
 	Height int64 `json:"height"`
 }
 
@@ -290,5 +365,9 @@ func LoadBlockStoreStateJSON(db dbm.DB) BlockStoreStateJSON {
 	if err != nil {
 		panic(fmt.Sprintf("Could not unmarshal bytes: %X", bytes))
 	}
+	// the stores saved before the base was recorded start at 1
+	if bsj.Height > 0 && bsj.Base == 0 {
+		bsj.Base = 1
+	}
 	return bsj
 }
@@ -66,7 +66,7 @@ func makeStateAndBlockStore(logger log.Logger) (sm.State, *BlockStore, cleanupFu
 func TestLoadBlockStoreStateJSON(t *testing.T) {
 	db := db.NewMemDB()
 
-	bsj := &BlockStoreStateJSON{Height: 1000}
+	bsj := &BlockStoreStateJSON{Base: 100, Height: 1000}
 	bsj.Save(db)
 
 	retrBSJ := LoadBlockStoreStateJSON(db)
@@ -74,12 +74,24 @@ func TestLoadBlockStoreStateJSON(t *testing.T) {
 	assert.Equal(t, *bsj, retrBSJ, "expected the retrieved DBs to match")
 }
 
+func TestLoadBlockStoreStateJSONWithoutBase(t *testing.T) {
+	db := db.NewMemDB()
+
+	bsj := &BlockStoreStateJSON{Height: 1000}
+	bsj.Save(db)
+
+	retrBSJ := LoadBlockStoreStateJSON(db)
+
+	assert.Equal(t, BlockStoreStateJSON{Base: 1, Height: 1000}, retrBSJ)
+}
+
 func TestNewBlockStore(t *testing.T) {
 	db := db.NewMemDB()
 	err := db.Set(blockStoreKey, []byte(`{"height": "10000"}`))
 	require.NoError(t, err)
 	bs := NewBlockStore(db)
 	require.Equal(t, int64(10000), bs.Height(), "failed to properly parse blockstore")
+	require.Equal(t, int64(1), bs.Base(), "failed to properly parse blockstore")
 
 	panicCausers := []struct {
 		data    []byte
@@ -158,16 +170,12 @@ func TestBlockStoreSaveLoadBlock(t *testing.T) {
 	require.Equal(t, bs.Height(), block.Header.Height, "expecting the new height to be changed")
 
 	incompletePartSet := types.NewPartSetFromHeader(types.PartSetHeader{Total: 2})
-	uncontiguousPartSet := types.NewPartSetFromHeader(types.PartSetHeader{Total: 0})
-	uncontiguousPartSet.AddPart(part2)
 
 	header1 := types.Header{
 		Height:  1,
 		ChainID: "block_test",
 		Time:    tmtime.Now(),
 	}
-	header2 := header1
-	header2.Height = 4
 
 	// End of setup, test data
 
@@ -196,12 +204,6 @@ func TestBlockStoreSaveLoadBlock(t *testing.T) {
 			wantPanic: "only save a non-nil block",
 		},
 
-		{
-			block:     newBlock(header2, commitAtH10),
-			parts:     uncontiguousPartSet,
-			wantPanic: "only save contiguous blocks", // and incomplete and uncontiguous parts
-		},
-
 		{
 			block:     newBlock(header1, commitAtH10),
 			parts:     incompletePartSet,
@@ -448,3 +450,82 @@ func newBlock(hdr types.Header, lastCommit *types.Commit) *types.Block {
 		LastCommit: lastCommit,
 	}
 }
+
+func TestBlockStoreSaveFromBase(t *testing.T) {
+	state, bs, cleanup := makeStateAndBlockStore(log.NewTMLogger(new(bytes.Buffer)))
+	defer cleanup()
+	require.EqualValues(t, 0, bs.Base())
+
+	// the first block saved can be of any height, e.g. after state sync
+	block := makeBlock(5, state, new(types.Commit))
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(5, tmtime.Now()))
+	assert.EqualValues(t, 5, bs.Base())
+	assert.EqualValues(t, 5, bs.Height())
+	assert.NotNil(t, bs.LoadBlock(5))
+
+	// the next ones follow it
+	block = makeBlock(7, state, new(types.Commit))
+	_, _, panicErr := doFn(func() (interface{}, error) {
+		bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(7, tmtime.Now()))
+		return nil, nil
+	})
+	require.Error(t, panicErr)
+	assert.Contains(t, panicErr.Error(), "only save contiguous blocks")
+
+	block = makeBlock(6, state, new(types.Commit))
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(6, tmtime.Now()))
+	assert.EqualValues(t, 5, bs.Base())
+	assert.EqualValues(t, 6, bs.Height())
+
+	// the base is persisted
+	bs = NewBlockStore(bs.db)
+	assert.EqualValues(t, 5, bs.Base())
+	assert.EqualValues(t, 6, bs.Height())
+}
+
+func TestBlockStoreSaveBlockBelowBase(t *testing.T) {
+	state, bs, cleanup := makeStateAndBlockStore(log.NewTMLogger(new(bytes.Buffer)))
+	defer cleanup()
+
+	// an empty store saves it as its first block
+	block := makeBlock(5, state, new(types.Commit))
+	bs.SaveBlockBelowBase(block, block.MakePartSet(2), makeTestCommit(5, tmtime.Now()))
+	assert.EqualValues(t, 5, bs.Base())
+	assert.EqualValues(t, 5, bs.Height())
+
+	// the next ones precede the base
+	block = makeBlock(3, state, new(types.Commit))
+	_, _, panicErr := doFn(func() (interface{}, error) {
+		bs.SaveBlockBelowBase(block, block.MakePartSet(2), makeTestCommit(3, tmtime.Now()))
+		return nil, nil
+	})
+	require.Error(t, panicErr)
+	assert.Contains(t, panicErr.Error(), "only save the block below the base")
+
+	block = makeBlock(4, state, new(types.Commit))
+	seenCommit := makeTestCommit(4, tmtime.Now())
+	bs.SaveBlockBelowBase(block, block.MakePartSet(2), seenCommit)
+	assert.EqualValues(t, 4, bs.Base())
+	assert.EqualValues(t, 5, bs.Height())
+	assert.Equal(t, block.Hash(), bs.LoadBlock(4).Hash())
+	assert.Equal(t, seenCommit, bs.LoadSeenCommit(4))
+
+	// blocks are still saved above the height
+	block = makeBlock(6, state, new(types.Commit))
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(6, tmtime.Now()))
+	assert.EqualValues(t, 4, bs.Base())
+	assert.EqualValues(t, 6, bs.Height())
+
+	// the base is persisted
+	bs = NewBlockStore(bs.db)
+	assert.EqualValues(t, 4, bs.Base())
+	assert.EqualValues(t, 6, bs.Height())
+}
+
+func TestBlockStoreSaveSeenCommit(t *testing.T) {
+	bs, _ := freshBlockStore()
+	commit := makeTestCommit(10, tmtime.Now())
+	bs.SaveSeenCommit(10, commit)
+	assert.Equal(t, commit, bs.LoadSeenCommit(10))
+	assert.EqualValues(t, 0, bs.Height())
+}
@@ -0,0 +1,25 @@
+package reactors
+
+import (
+	"sync"
+
+	bc "github.com/tendermint/tendermint/blockchain/v0"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+var (
+	blockchainOnce    sync.Once
+	blockchainReactor *bc.BlockchainReactor
+)
+
+// FuzzBlockchain is the entry point of the Receive path of the blockchain
+// reactor (v0, the default), fast syncing.
+func FuzzBlockchain(data []byte) int {
+	blockchainOnce.Do(func() {
+		n := newNode("fuzz_blockchain")
+		blockchainReactor = bc.NewBlockchainReactor(n.state, n.blockExec, n.blockStore, true)
+		blockchainReactor.SetLogger(log.NewNopLogger())
+		newSwitch("BLOCKCHAIN", blockchainReactor)
+	})
+	return receive(blockchainReactor, data)
+}
@@ -0,0 +1,49 @@
+package reactors
+
+import (
+	"fmt"
+	"sync"
+
+	cs "github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/libs/log"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	consensusOnce    sync.Once
+	consensusReactor *cs.Reactor
+)
+
+// FuzzConsensus is the entry point of the Receive path of the consensus
+// reactor. The consensus state runs, without a validator, so that the
+// proposals, block parts and votes received are processed, and a consensus
+// failure crashes the fuzzer.
+func FuzzConsensus(data []byte) int {
+	consensusOnce.Do(func() {
+		n := newNode("fuzz_consensus")
+		state := cs.NewState(n.config.Consensus, n.state, n.blockExec, n.blockStore,
+			n.mempool, sm.MockEvidencePool{})
+		state.SetLogger(log.NewNopLogger())
+		// a msg making consensus fail is a crash, as much as a panic of
+		// Receive, even if the node survives it
+		state.SetPanicHandler(func(_ *cs.State, r interface{}, stack []byte) {
+			go panic(fmt.Sprintf("consensus failure: %v\n%s", r, stack))
+		})
+
+		eventBus := types.NewEventBus()
+		eventBus.SetLogger(log.NewNopLogger())
+		if err := eventBus.Start(); err != nil {
+			panic(err)
+		}
+
+		consensusReactor = cs.NewReactor(state, false)
+		consensusReactor.SetLogger(log.NewNopLogger())
+		consensusReactor.SetEventBus(eventBus)
+		newSwitch("CONSENSUS", consensusReactor)
+		if err := consensusReactor.Start(); err != nil {
+			panic(err)
+		}
+	})
+	return receive(consensusReactor, data)
+}
@@ -0,0 +1,28 @@
+package reactors
+
+import (
+	"sync"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/evidence"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+var (
+	evidenceOnce    sync.Once
+	evidenceReactor *evidence.Reactor
+)
+
+// FuzzEvidence is the entry point of the Receive path of the evidence reactor.
+func FuzzEvidence(data []byte) int {
+	evidenceOnce.Do(func() {
+		n := newNode("fuzz_evidence")
+		pool := evidence.NewPool(n.stateDB, dbm.NewMemDB())
+		pool.SetLogger(log.NewNopLogger())
+		evidenceReactor = evidence.NewReactor(pool)
+		evidenceReactor.SetLogger(log.NewNopLogger())
+		newSwitch("EVIDENCE", evidenceReactor)
+	})
+	return receive(evidenceReactor, data)
+}
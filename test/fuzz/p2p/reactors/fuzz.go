@@ -0,0 +1,104 @@
+// Package reactors has the go-fuzz (https://github.com/dvyukov/go-fuzz)
+// entry points of the Receive path of the reactors. The first byte of the
+// input picks one of the channels of the reactor, and the rest is the msg a
+// peer sends on it: a malformed msg must get the peer disconnected, never
+// panic.
+//
+// See the Makefile of test/fuzz to run them.
+package reactors
+
+import (
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/mock"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/store"
+)
+
+// newSwitch returns a switch with the reactor, which is not started: it's
+// only there for the reactor to disconnect peers from.
+func newSwitch(name string, reactor p2p.Reactor) *p2p.Switch {
+	cfg := config.DefaultP2PConfig()
+	transport := p2p.NewMultiplexTransport(
+		p2p.DefaultNodeInfo{},
+		p2p.NodeKey{PrivKey: ed25519.GenPrivKey()},
+		p2p.MConnConfig(cfg),
+	)
+	sw := p2p.NewSwitch(cfg, transport)
+	sw.SetLogger(log.NewNopLogger())
+	sw.AddReactor(name, reactor)
+	return sw
+}
+
+// receive has a new peer of the reactor send it the msg of data, on the
+// channel its first byte picks. It returns 1 if the msg is received, 0 if
+// data is empty.
+func receive(reactor p2p.Reactor, data []byte, setup ...func(p2p.Peer)) int {
+	if len(data) == 0 {
+		return 0
+	}
+	channels := reactor.GetChannels()
+	chID := channels[int(data[0])%len(channels)].ID
+
+	peer := mock.NewPeer(nil)
+	peer.Outbound = true
+	peer = reactor.InitPeer(peer).(*mock.Peer)
+	reactor.AddPeer(peer)
+	for _, f := range setup {
+		f(peer)
+	}
+
+	reactor.Receive(chID, peer, data[1:])
+
+	reactor.RemovePeer(peer, nil)
+	_ = peer.Stop()
+	return 1
+}
+
+// node has what the reactors of a node with the kvstore app need, at the
+// genesis of a chain of a single validator.
+type node struct {
+	config     *config.Config
+	state      sm.State
+	stateDB    dbm.DB
+	blockStore *store.BlockStore
+	mempool    *mempl.CListMempool
+	blockExec  *sm.BlockExecutor
+}
+
+func newNode(name string) *node {
+	cfg := config.ResetTestRoot(name)
+
+	stateDB := dbm.NewMemDB()
+	state, err := sm.LoadStateFromDBOrGenesisFile(stateDB, cfg.GenesisFile())
+	if err != nil {
+		panic(err)
+	}
+	sm.SaveState(stateDB, state)
+
+	proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(kvstore.NewApplication()))
+	proxyApp.SetLogger(log.NewNopLogger())
+	if err := proxyApp.Start(); err != nil {
+		panic(err)
+	}
+
+	mempool := mempl.NewCListMempool(cfg.Mempool, proxyApp.Mempool(), state.LastBlockHeight)
+	mempool.SetLogger(log.NewNopLogger())
+
+	return &node{
+		config:     cfg,
+		state:      state,
+		stateDB:    stateDB,
+		blockStore: store.NewBlockStore(dbm.NewMemDB()),
+		mempool:    mempool,
+		blockExec: sm.NewBlockExecutor(stateDB, log.NewNopLogger(), proxyApp.Consensus(),
+			mempool, sm.MockEvidencePool{}),
+	}
+}
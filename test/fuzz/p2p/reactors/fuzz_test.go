@@ -0,0 +1,129 @@
+package reactors
+
+import (
+	"math/rand"
+	"testing"
+
+	amino "github.com/tendermint/go-amino"
+
+	cs "github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/evidence"
+	"github.com/tendermint/tendermint/libs/bits"
+	mempl "github.com/tendermint/tendermint/mempool"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+// The msgs of the blockchain and PEX reactors are unexported: these have the
+// same encoding.
+type (
+	bcMessage                  interface{}
+	bcBlockRequestMessage      struct{ Height int64 }
+	bcBlockResponseMessage     struct{ Block *types.Block }
+	bcNoBlockResponseMessage   struct{ Height int64 }
+	bcStatusRequestMessage     struct{ Height int64 }
+	bcStatusResponseMessage    struct{ Height int64 }
+	bcBlockRangeRequestMessage struct{ Height, Count int64 }
+
+	pexMessage        interface{}
+	pexRequestMessage struct{}
+	pexAddrsMessage   struct{ Addrs []*p2p.NetAddress }
+)
+
+func newCodec() *amino.Codec {
+	cdc := amino.NewCodec()
+	types.RegisterBlockAmino(cdc)
+	cs.RegisterMessages(cdc)
+	mempl.RegisterMessages(cdc)
+	evidence.RegisterMessages(cdc)
+
+	cdc.RegisterInterface((*bcMessage)(nil), nil)
+	cdc.RegisterConcrete(&bcBlockRequestMessage{}, "tendermint/blockchain/BlockRequest", nil)
+	cdc.RegisterConcrete(&bcBlockResponseMessage{}, "tendermint/blockchain/BlockResponse", nil)
+	cdc.RegisterConcrete(&bcNoBlockResponseMessage{}, "tendermint/blockchain/NoBlockResponse", nil)
+	cdc.RegisterConcrete(&bcStatusResponseMessage{}, "tendermint/blockchain/StatusResponse", nil)
+	cdc.RegisterConcrete(&bcStatusRequestMessage{}, "tendermint/blockchain/StatusRequest", nil)
+	cdc.RegisterConcrete(&bcBlockRangeRequestMessage{}, "tendermint/blockchain/BlockRangeRequest", nil)
+
+	cdc.RegisterInterface((*pexMessage)(nil), nil)
+	cdc.RegisterConcrete(&pexRequestMessage{}, "tendermint/p2p/PexRequestMessage", nil)
+	cdc.RegisterConcrete(&pexAddrsMessage{}, "tendermint/p2p/PexAddrsMessage", nil)
+	return cdc
+}
+
+// seeds returns the msgs of the reactors, valid or not, to start fuzzing from.
+func seeds() map[string][]interface{} {
+	block := types.MakeBlock(1, []types.Tx{types.Tx("key=value")}, nil, nil)
+	return map[string][]interface{}{
+		"blockchain": {
+			&bcBlockRequestMessage{Height: 1},
+			&bcBlockRequestMessage{Height: -1},
+			&bcBlockResponseMessage{Block: block},
+			&bcBlockResponseMessage{Block: &types.Block{}},
+			&bcBlockResponseMessage{},
+			&bcNoBlockResponseMessage{Height: 1},
+			&bcStatusRequestMessage{Height: 1},
+			&bcStatusResponseMessage{Height: 1 << 62},
+			&bcBlockRangeRequestMessage{Height: 1, Count: 1 << 62},
+		},
+		"consensus": {
+			&cs.NewRoundStepMessage{Height: 1, Round: 1},
+			&cs.NewValidBlockMessage{Height: 1, BlockParts: bits.NewBitArray(1)},
+			&cs.NewValidBlockMessage{Height: 1},
+			&cs.ProposalMessage{Proposal: types.NewProposal(1, 0, -1, types.BlockID{})},
+			&cs.ProposalMessage{},
+			&cs.ProposalPOLMessage{Height: 1, ProposalPOL: bits.NewBitArray(1)},
+			&cs.ProposalPOLMessage{Height: 1},
+			&cs.BlockPartMessage{Height: 1, Part: &types.Part{}},
+			&cs.BlockPartMessage{Height: 1},
+			&cs.VoteMessage{Vote: &types.Vote{Height: 1, Type: types.PrevoteType}},
+			&cs.VoteMessage{},
+			&cs.HasVoteMessage{Height: 1, Type: types.PrevoteType},
+			&cs.VoteSetMaj23Message{Height: 1, Type: types.PrecommitType},
+			&cs.VoteSetBitsMessage{Height: 1, Type: types.PrevoteType, Votes: bits.NewBitArray(1)},
+			&cs.VoteSetBitsMessage{Height: 1, Type: types.PrevoteType},
+		},
+		"evidence": {
+			&evidence.ListMessage{Evidence: []types.Evidence{&types.DuplicateVoteEvidence{}}},
+			&evidence.ListMessage{},
+		},
+		"mempool": {
+			&mempl.TxMessage{Tx: types.Tx("key=value")},
+			&mempl.TxMessage{},
+		},
+		"pex": {
+			&pexRequestMessage{},
+			&pexAddrsMessage{Addrs: []*p2p.NetAddress{{}, p2p.NewNetAddressIPPort(nil, 0)}},
+			&pexAddrsMessage{},
+		},
+	}
+}
+
+var fuzzFuncs = map[string]func([]byte) int{
+	"blockchain": FuzzBlockchain,
+	"consensus":  FuzzConsensus,
+	"evidence":   FuzzEvidence,
+	"mempool":    FuzzMempool,
+	"pex":        FuzzPEX,
+}
+
+// TestFuzzSeeds has the reactors receive the seeds on every channel, cut
+// short or with a byte changed: none must panic.
+func TestFuzzSeeds(t *testing.T) {
+	cdc := newCodec()
+	rng := rand.New(rand.NewSource(1))
+
+	for name, msgs := range seeds() {
+		fuzz := fuzzFuncs[name]
+		for _, msg := range msgs {
+			bz := cdc.MustMarshalBinaryBare(msg)
+			for ch := byte(0); ch < 4; ch++ {
+				data := append([]byte{ch}, bz...)
+				fuzz(data)
+				fuzz(data[:1+rng.Intn(len(data))])
+				data[1+rng.Intn(len(data)-1)] ^= byte(1 + rng.Intn(255))
+				fuzz(data)
+			}
+		}
+	}
+}
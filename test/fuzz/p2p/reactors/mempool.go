@@ -0,0 +1,24 @@
+package reactors
+
+import (
+	"sync"
+
+	"github.com/tendermint/tendermint/libs/log"
+	mempl "github.com/tendermint/tendermint/mempool"
+)
+
+var (
+	mempoolOnce    sync.Once
+	mempoolReactor *mempl.Reactor
+)
+
+// FuzzMempool is the entry point of the Receive path of the mempool reactor.
+func FuzzMempool(data []byte) int {
+	mempoolOnce.Do(func() {
+		n := newNode("fuzz_mempool")
+		mempoolReactor = mempl.NewReactor(n.config.Mempool, n.mempool)
+		mempoolReactor.SetLogger(log.NewNopLogger())
+		newSwitch("MEMPOOL", mempoolReactor)
+	})
+	return receive(mempoolReactor, data)
+}
@@ -0,0 +1,32 @@
+package reactors
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/p2p"
+	"github.com/tendermint/tendermint/p2p/pex"
+)
+
+var (
+	pexOnce    sync.Once
+	pexReactor *pex.Reactor
+)
+
+// FuzzPEX is the entry point of the Receive path of the PEX reactor. The
+// peer was asked for addresses, so that the addresses it sends are added to
+// the address book.
+func FuzzPEX(data []byte) int {
+	pexOnce.Do(func() {
+		n := newNode("fuzz_pex")
+		book := pex.NewAddrBook(filepath.Join(n.config.RootDir, "addrbook.json"), false)
+		book.SetLogger(log.NewNopLogger())
+		pexReactor = pex.NewReactor(book, &pex.ReactorConfig{})
+		pexReactor.SetLogger(log.NewNopLogger())
+		newSwitch("PEX", pexReactor)
+	})
+	return receive(pexReactor, data, func(peer p2p.Peer) {
+		pexReactor.RequestAddrs(peer)
+	})
+}
@@ -0,0 +1,88 @@
+#! /bin/bash
+# Crashes tendermint at each failpoint in turn, then checks that it recovers
+# and keeps making blocks. Requires tendermint built with the failpoints tag
+# (make install_failpoints) and abci-cli.
+
+export PATH="$GOBIN:$PATH"
+export TMHOME=$HOME/.tendermint_failpoints
+
+rm -rf "$TMHOME"
+tendermint init
+
+# use a unix socket so we can remove it
+RPC_ADDR="$(pwd)/rpc.sock"
+
+TM_CMD="tendermint node --log_level=debug --rpc.laddr=unix://$RPC_ADDR"
+DUMMY_CMD="abci-cli kvstore --persist $TMHOME/kvstore"
+
+function start_procs(){
+    name=$1
+    failpoint=$2
+    echo "Starting persistent kvstore and tendermint"
+    $DUMMY_CMD &> "kvstore_${name}.log" &
+    PID_DUMMY=$!
+
+    # before starting tendermint, remove the rpc socket
+    rm -f "$RPC_ADDR"
+    if [[ "$failpoint" == "" ]]; then
+        # run in background, dont fail
+        $TM_CMD &> "tendermint_${name}.log" &
+        PID_TENDERMINT=$!
+    else
+        # run in foreground, crash at the failpoint
+        TM_FAILPOINTS="$failpoint=exit" $TM_CMD &> "tendermint_${name}.log"
+        PID_TENDERMINT=$!
+    fi
+}
+
+function kill_procs(){
+    kill -9 "$PID_DUMMY" "$PID_TENDERMINT"
+    wait "$PID_DUMMY"
+    wait "$PID_TENDERMINT"
+}
+
+function status(){
+    # NOTE: --unix-socket is only available in curl v7.40+
+    curl -s --unix-socket "$RPC_ADDR" http://localhost/status
+}
+
+failpoints=$(grep -rhoE 'failpoint\.Inject\("[^"]+"' --include \*.go . | sed -E 's/.*\("(.*)"/\1/' | sort -u)
+
+for failpoint in $failpoints; do
+    echo ""
+    echo "* Test failpoint $failpoint"
+
+    bash "$(dirname "$0")/txs.sh" "localhost:26657" &
+    start_procs 1 "$failpoint"
+
+    # tendermint should already have exited at the failpoint
+    # but kill -9 for good measure
+    kill_procs
+
+    start_procs 2
+
+    # wait for node to handshake and make a new block
+    i=0
+    until status > /dev/null; do
+        sleep 1
+        i=$((i + 1))
+        if [[ $i == 20 ]]; then
+            echo "Timed out waiting for tendermint to start after failpoint $failpoint"
+            exit 1
+        fi
+    done
+
+    h1=$(status | jq .result.sync_info.latest_block_height)
+    h2=$h1
+    while [ "$h2" == "$h1" ]; do
+        sleep 1
+        h2=$(status | jq .result.sync_info.latest_block_height)
+    done
+
+    kill_procs
+
+    echo "* Passed Test for failpoint $failpoint"
+    echo ""
+done
+
+echo "Passed Test: Failpoints"
@@ -42,6 +42,12 @@ test_persistence:
 	# bash test/persist/test_failure_indices.sh
 .PHONY: test_persistence
 
+test_failpoints: install_failpoints
+	# crash tendermint at each failpoint and check that it recovers
+	# requires `abci-cli` installed
+	bash test/persist/test_failpoints.sh
+.PHONY: test_failpoints
+
 test_p2p:
 	docker rm -f rsyslog || true
 	rm -rf test/logs && mkdir -p test/logs
@@ -12,6 +12,7 @@ import (
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/merkle"
 	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	"github.com/tendermint/tendermint/libs/bits"
 	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	tmmath "github.com/tendermint/tendermint/libs/math"
@@ -20,7 +21,7 @@ import (
 
 const (
 	// MaxHeaderBytes is a maximum header size (including amino overhead).
-	MaxHeaderBytes int64 = 632
+	MaxHeaderBytes int64 = 714
 
 	// MaxAminoOverheadForBlock - maximum amino overhead to encode a block (up to
 	// MaxBlockSizeBytes in size) not including it's parts except Data.
@@ -139,6 +140,10 @@ func (b *Block) ValidateBasic() error {
 		return fmt.Errorf("expected len(Header.ProposerAddress) to be %d, got %d",
 			crypto.AddressSize, len(b.ProposerAddress))
 	}
+	if len(b.ProposerProof) != 0 && len(b.ProposerProof) != vrf.ProofSize {
+		return fmt.Errorf("expected len(Header.ProposerProof) to be 0 or %d, got %d",
+			vrf.ProofSize, len(b.ProposerProof))
+	}
 
 	return nil
 }
@@ -345,6 +350,9 @@ type Header struct {
 	// consensus info
 	EvidenceHash    tmbytes.HexBytes `json:"evidence_hash"`    // evidence included in the block
 	ProposerAddress Address          `json:"proposer_address"` // original proposer of the block
+	// VRF proof of the original proposer, if the proposers are selected by VRF
+	// (see ProposerProofInput)
+	ProposerProof tmbytes.HexBytes `json:"proposer_proof,omitempty"`
 }
 
 // Populate the Header with state-derived data.
@@ -374,11 +382,13 @@ func (h *Header) Populate(
 // Returns nil if ValidatorHash is missing,
 // since a Header is not valid unless there is
 // a ValidatorsHash (corresponding to the validator set).
+// The ProposerProof is only hashed if it's set, so that the hashes of the
+// headers of the chains not selecting proposers by VRF are unchanged.
 func (h *Header) Hash() tmbytes.HexBytes {
 	if h == nil || len(h.ValidatorsHash) == 0 {
 		return nil
 	}
-	return merkle.SimpleHashFromByteSlices([][]byte{
+	fields := [][]byte{
 		cdcEncode(h.Version),
 		cdcEncode(h.ChainID),
 		cdcEncode(h.Height),
@@ -393,7 +403,11 @@ func (h *Header) Hash() tmbytes.HexBytes {
 		cdcEncode(h.LastResultsHash),
 		cdcEncode(h.EvidenceHash),
 		cdcEncode(h.ProposerAddress),
-	})
+	}
+	if len(h.ProposerProof) > 0 {
+		fields = append(fields, cdcEncode(h.ProposerProof))
+	}
+	return merkle.SimpleHashFromByteSlices(fields)
 }
 
 // StringIndented returns a string representation of the header
@@ -454,6 +468,7 @@ type CommitSig struct {
 	ValidatorAddress Address     `json:"validator_address"`
 	Timestamp        time.Time   `json:"timestamp"`
 	Signature        []byte      `json:"signature"`
+	Extension        []byte      `json:"extension,omitempty"`
 }
 
 // NewCommitSigForBlock returns new CommitSig with BlockIDFlagCommit.
@@ -530,6 +545,9 @@ func (cs CommitSig) ValidateBasic() error {
 		if len(cs.Signature) != 0 {
 			return errors.New("signature is present")
 		}
+		if len(cs.Extension) != 0 {
+			return errors.New("extension is present")
+		}
 	default:
 		if len(cs.ValidatorAddress) != crypto.AddressSize {
 			return fmt.Errorf("expected ValidatorAddress size to be %d bytes, got %d bytes",
@@ -544,6 +562,12 @@ func (cs CommitSig) ValidateBasic() error {
 		if len(cs.Signature) > MaxSignatureSize {
 			return fmt.Errorf("signature is too big (max: %d)", MaxSignatureSize)
 		}
+		if len(cs.Extension) > 0 && cs.BlockIDFlag != BlockIDFlagCommit {
+			return errors.New("extension is only allowed in non-nil precommits")
+		}
+		if int64(len(cs.Extension)) > MaxVoteExtensionBytes {
+			return fmt.Errorf("extension is too big (max: %d)", MaxVoteExtensionBytes)
+		}
 	}
 
 	return nil
@@ -585,13 +609,17 @@ func NewCommit(height int64, round int, blockID BlockID, commitSigs []CommitSig)
 // Inverse of VoteSet.MakeCommit().
 func CommitToVoteSet(chainID string, commit *Commit, vals *ValidatorSet) *VoteSet {
 	voteSet := NewVoteSet(chainID, commit.Height, commit.Round, PrecommitType, vals)
+	votes := make([]*Vote, 0, len(commit.Signatures))
 	for idx, commitSig := range commit.Signatures {
 		if commitSig.Absent() {
 			continue // OK, some precommits can be missing.
 		}
-		added, err := voteSet.AddVote(commit.GetVote(idx))
-		if !added || err != nil {
-			panic(fmt.Sprintf("Failed to reconstruct LastCommit: %v", err))
+		votes = append(votes, commit.GetVote(idx))
+	}
+	added, errs := voteSet.AddVotes(votes)
+	for i := range votes {
+		if !added[i] || errs[i] != nil {
+			panic(fmt.Sprintf("Failed to reconstruct LastCommit: %v", errs[i]))
 		}
 	}
 	return voteSet
@@ -611,11 +639,13 @@ func (commit *Commit) GetVote(valIdx int) *Vote {
 		ValidatorAddress: commitSig.ValidatorAddress,
 		ValidatorIndex:   valIdx,
 		Signature:        commitSig.Signature,
+		Extension:        commitSig.Extension,
 	}
 }
 
 // VoteSignBytes constructs the SignBytes for the given CommitSig.
-// The only unique part of the SignBytes is the Timestamp - all other fields
+// The only unique parts of the SignBytes are the Timestamp and the Extension -
+// all other fields
 // signed over are otherwise the same for all validators.
 // Panics if valIdx >= commit.Size().
 func (commit *Commit) VoteSignBytes(chainID string, valIdx int) []byte {
@@ -17,6 +17,7 @@ import (
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/merkle"
 	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	"github.com/tendermint/tendermint/libs/bits"
 	"github.com/tendermint/tendermint/libs/bytes"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
@@ -237,6 +238,14 @@ func TestCommitValidateBasic(t *testing.T) {
 		{"Incorrect signature", func(com *Commit) { com.Signatures[0].Signature = []byte{0} }, false},
 		{"Incorrect height", func(com *Commit) { com.Height = int64(-100) }, true},
 		{"Incorrect round", func(com *Commit) { com.Round = -100 }, true},
+		{"Extension", func(com *Commit) { com.Signatures[0].Extension = []byte("extension") }, false},
+		{"Too big extension", func(com *Commit) {
+			com.Signatures[0].Extension = make([]byte, MaxVoteExtensionBytes+1)
+		}, true},
+		{"Extension of a nil vote", func(com *Commit) {
+			com.Signatures[0].BlockIDFlag = BlockIDFlagNil
+			com.Signatures[0].Extension = []byte("extension")
+		}, true},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -270,6 +279,23 @@ func TestHeaderHash(t *testing.T) {
 			EvidenceHash:       tmhash.Sum([]byte("evidence_hash")),
 			ProposerAddress:    crypto.AddressHash([]byte("proposer_address")),
 		}, hexBytesFromString("ABDC78921B18A47EE6BEF5E31637BADB0F3E587E3C0F4DB2D1E93E9FF0533862")},
+		{"Generates expected hash with a proposer proof", &Header{
+			Version:            version.Consensus{Block: 1, App: 2},
+			ChainID:            "chainId",
+			Height:             3,
+			Time:               time.Date(2019, 10, 13, 16, 14, 44, 0, time.UTC),
+			LastBlockID:        makeBlockID(make([]byte, tmhash.Size), 6, make([]byte, tmhash.Size)),
+			LastCommitHash:     tmhash.Sum([]byte("last_commit_hash")),
+			DataHash:           tmhash.Sum([]byte("data_hash")),
+			ValidatorsHash:     tmhash.Sum([]byte("validators_hash")),
+			NextValidatorsHash: tmhash.Sum([]byte("next_validators_hash")),
+			ConsensusHash:      tmhash.Sum([]byte("consensus_hash")),
+			AppHash:            tmhash.Sum([]byte("app_hash")),
+			LastResultsHash:    tmhash.Sum([]byte("last_results_hash")),
+			EvidenceHash:       tmhash.Sum([]byte("evidence_hash")),
+			ProposerAddress:    crypto.AddressHash([]byte("proposer_address")),
+			ProposerProof:      make([]byte, vrf.ProofSize),
+		}, hexBytesFromString("43E8330B4FD7270CADFEB30D5AE902BE8D04564CFECEEDCDB5452291EAEFE195")},
 		{"nil header yields nil", nil, nil},
 		{"nil ValidatorsHash yields nil", &Header{
 			Version:            version.Consensus{Block: 1, App: 2},
@@ -294,12 +320,16 @@ func TestHeaderHash(t *testing.T) {
 			assert.Equal(t, tc.expectHash, tc.header.Hash())
 
 			// We also make sure that all fields are hashed in struct order, and that all
-			// fields in the test struct are non-zero.
+			// fields in the test struct are non-zero (but the ProposerProof, which is
+			// only hashed if set).
 			if tc.header != nil && tc.expectHash != nil {
 				byteSlices := [][]byte{}
 				s := reflect.ValueOf(*tc.header)
 				for i := 0; i < s.NumField(); i++ {
 					f := s.Field(i)
+					if s.Type().Field(i).Name == "ProposerProof" && f.Len() == 0 {
+						continue
+					}
 					assert.False(t, f.IsZero(), "Found zero-valued field %v",
 						s.Type().Field(i).Name)
 					byteSlices = append(byteSlices, cdcEncode(f.Interface()))
@@ -340,6 +370,7 @@ func TestMaxHeaderBytes(t *testing.T) {
 		LastResultsHash:    tmhash.Sum([]byte("last_results_hash")),
 		EvidenceHash:       tmhash.Sum([]byte("evidence_hash")),
 		ProposerAddress:    crypto.AddressHash([]byte("proposer_address")),
+		ProposerProof:      make([]byte, vrf.ProofSize),
 	}
 
 	bz, err := cdc.MarshalBinaryLengthPrefixed(h)
@@ -377,9 +408,9 @@ func TestBlockMaxDataBytes(t *testing.T) {
 	}{
 		0: {-10, 1, 0, true, 0},
 		1: {10, 1, 0, true, 0},
-		2: {865, 1, 0, true, 0},
-		3: {866, 1, 0, false, 0},
-		4: {867, 1, 0, false, 1},
+		2: {1974, 1, 0, true, 0},
+		3: {1975, 1, 0, false, 0},
+		4: {1976, 1, 0, false, 1},
 	}
 
 	for i, tc := range testCases {
@@ -406,9 +437,9 @@ func TestBlockMaxDataBytesUnknownEvidence(t *testing.T) {
 	}{
 		0: {-10, 1, true, 0},
 		1: {10, 1, true, 0},
-		2: {961, 1, true, 0},
-		3: {962, 1, false, 0},
-		4: {963, 1, false, 1},
+		2: {2193, 1, true, 0},
+		3: {2194, 1, false, 0},
+		4: {2195, 1, false, 1},
 	}
 
 	for i, tc := range testCases {
@@ -30,6 +30,8 @@ type CanonicalProposal struct {
 	BlockID   CanonicalBlockID
 	Timestamp time.Time
 	ChainID   string
+
+	ProposerProof []byte
 }
 
 type CanonicalVote struct {
@@ -39,6 +41,7 @@ type CanonicalVote struct {
 	BlockID   CanonicalBlockID
 	Timestamp time.Time
 	ChainID   string
+	Extension []byte
 }
 
 //-----------------------------------
@@ -67,6 +70,8 @@ func CanonicalizeProposal(chainID string, proposal *Proposal) CanonicalProposal
 		BlockID:   CanonicalizeBlockID(proposal.BlockID),
 		Timestamp: proposal.Timestamp,
 		ChainID:   chainID,
+
+		ProposerProof: proposal.ProposerProof,
 	}
 }
 
@@ -78,6 +83,7 @@ func CanonicalizeVote(chainID string, vote *Vote) CanonicalVote {
 		BlockID:   CanonicalizeBlockID(vote.BlockID),
 		Timestamp: vote.Timestamp,
 		ChainID:   chainID,
+		Extension: vote.Extension,
 	}
 }
 
@@ -25,6 +25,7 @@ type Subscription interface {
 	Out() <-chan tmpubsub.Message
 	Cancelled() <-chan struct{}
 	Err() error
+	Dropped() int64
 }
 
 // EventBus is a common bus for all events going through the system. All calls
@@ -89,6 +90,18 @@ func (b *EventBus) SubscribeUnbuffered(
 	return b.pubsub.SubscribeUnbuffered(ctx, subscriber, query)
 }
 
+// SubscribeWithPolicy subscribes with the given capacity and overflow policy
+// (see tmpubsub.Server.SubscribeWithPolicy).
+func (b *EventBus) SubscribeWithPolicy(
+	ctx context.Context,
+	subscriber string,
+	query tmpubsub.Query,
+	outCapacity int,
+	policy tmpubsub.OverflowPolicy,
+) (Subscription, error) {
+	return b.pubsub.SubscribeWithPolicy(ctx, subscriber, query, outCapacity, policy)
+}
+
 func (b *EventBus) Unsubscribe(ctx context.Context, subscriber string, query tmpubsub.Query) error {
 	return b.pubsub.Unsubscribe(ctx, subscriber, query)
 }
@@ -181,6 +194,48 @@ func (b *EventBus) PublishEventTx(data EventDataTx) error {
 	return b.pubsub.PublishWithEvents(ctx, data, events)
 }
 
+// PublishEventTxAdded publishes the addition of a tx to the mempool, with the
+// predefined TxHashKey and TxSenderKey (if any), so that the txs of a given
+// sender can be subscribed to.
+func (b *EventBus) PublishEventTxAdded(data EventDataTxAdded) error {
+	return b.publishMempoolEvent(EventTxAdded, data, data.Tx, data.Sender)
+}
+
+// PublishEventTxRemoved publishes the removal of a tx from the mempool, with
+// the predefined TxHashKey and TxSenderKey (if any), so that the removal of a
+// given tx can be subscribed to.
+func (b *EventBus) PublishEventTxRemoved(data EventDataTxRemoved) error {
+	return b.publishMempoolEvent(EventTxRemoved, data, data.Tx, data.Sender)
+}
+
+// PublishEventTxStatus publishes the change of the status of a tracked tx,
+// with the predefined TxHashKey, so that the status of a given tx can be
+// subscribed to.
+func (b *EventBus) PublishEventTxStatus(data EventDataTxStatus) error {
+	// no explicit deadline for publishing events
+	ctx := context.Background()
+
+	events := map[string][]string{
+		EventTypeKey: {EventTxStatus},
+		TxHashKey:    {data.Hash.String()},
+	}
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
+func (b *EventBus) publishMempoolEvent(eventType string, data TMEventData, tx Tx, sender string) error {
+	// no explicit deadline for publishing events
+	ctx := context.Background()
+
+	events := map[string][]string{
+		EventTypeKey: {eventType},
+		TxHashKey:    {fmt.Sprintf("%X", tx.Hash())},
+	}
+	if sender != "" {
+		events[TxSenderKey] = []string{sender}
+	}
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
 func (b *EventBus) PublishEventNewRoundStep(data EventDataRoundState) error {
 	return b.Publish(EventNewRoundStep, data)
 }
@@ -217,6 +272,10 @@ func (b *EventBus) PublishEventLock(data EventDataRoundState) error {
 	return b.Publish(EventLock, data)
 }
 
+func (b *EventBus) PublishEventDoubleSignPrevented(data EventDataDoubleSignPrevented) error {
+	return b.Publish(EventDoubleSignPrevented, data)
+}
+
 func (b *EventBus) PublishEventValidatorSetUpdates(data EventDataValidatorSetUpdates) error {
 	return b.Publish(EventValidatorSetUpdates, data)
 }
@@ -257,6 +316,18 @@ func (NopEventBus) PublishEventTx(data EventDataTx) error {
 	return nil
 }
 
+func (NopEventBus) PublishEventTxAdded(data EventDataTxAdded) error {
+	return nil
+}
+
+func (NopEventBus) PublishEventTxRemoved(data EventDataTxRemoved) error {
+	return nil
+}
+
+func (NopEventBus) PublishEventTxStatus(data EventDataTxStatus) error {
+	return nil
+}
+
 func (NopEventBus) PublishEventNewRoundStep(data EventDataRoundState) error {
 	return nil
 }
@@ -293,6 +364,10 @@ func (NopEventBus) PublishEventLock(data EventDataRoundState) error {
 	return nil
 }
 
+func (NopEventBus) PublishEventDoubleSignPrevented(data EventDataDoubleSignPrevented) error {
+	return nil
+}
+
 func (NopEventBus) PublishEventValidatorSetUpdates(data EventDataValidatorSetUpdates) error {
 	return nil
 }
@@ -262,7 +262,7 @@ func TestEventBusPublish(t *testing.T) {
 	require.NoError(t, err)
 	defer eventBus.Stop()
 
-	const numEventsExpected = 14
+	const numEventsExpected = 17
 
 	sub, err := eventBus.Subscribe(context.Background(), "test", tmquery.Empty{}, numEventsExpected)
 	require.NoError(t, err)
@@ -307,6 +307,12 @@ func TestEventBusPublish(t *testing.T) {
 	require.NoError(t, err)
 	err = eventBus.PublishEventValidatorSetUpdates(EventDataValidatorSetUpdates{})
 	require.NoError(t, err)
+	err = eventBus.PublishEventTxAdded(EventDataTxAdded{})
+	require.NoError(t, err)
+	err = eventBus.PublishEventTxRemoved(EventDataTxRemoved{})
+	require.NoError(t, err)
+	err = eventBus.PublishEventTxStatus(EventDataTxStatus{})
+	require.NoError(t, err)
 
 	select {
 	case <-done:
@@ -5,6 +5,7 @@ import (
 
 	amino "github.com/tendermint/go-amino"
 	abci "github.com/tendermint/tendermint/abci/types"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 )
@@ -21,6 +22,15 @@ const (
 	EventTx                  = "Tx"
 	EventValidatorSetUpdates = "ValidatorSetUpdates"
 
+	// Mempool events, triggered when a tx is added to the mempool, and when it
+	// is removed from it (committed or not).
+	EventTxAdded   = "TxAdded"
+	EventTxRemoved = "TxRemoved"
+
+	// Tx tracking event, triggered when the status of a tracked tx changes
+	// (see mempool.TxTracker).
+	EventTxStatus = "TxStatus"
+
 	// Internal consensus events.
 	// These are used for testing the consensus state machine.
 	// They can also be used to build real-time consensus visualizers.
@@ -35,6 +45,10 @@ const (
 	EventUnlock           = "Unlock"
 	EventValidBlock       = "ValidBlock"
 	EventVote             = "Vote"
+
+	// Fired when the consensus refuses to sign a vote or a proposal which
+	// conflicts with one it signed before (see consensus.sign_state_file).
+	EventDoubleSignPrevented = "DoubleSignPrevented"
 )
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -56,6 +70,10 @@ func RegisterEventDatas(cdc *amino.Codec) {
 	cdc.RegisterConcrete(EventDataCompleteProposal{}, "tendermint/event/CompleteProposal", nil)
 	cdc.RegisterConcrete(EventDataVote{}, "tendermint/event/Vote", nil)
 	cdc.RegisterConcrete(EventDataValidatorSetUpdates{}, "tendermint/event/ValidatorSetUpdates", nil)
+	cdc.RegisterConcrete(EventDataTxAdded{}, "tendermint/event/TxAdded", nil)
+	cdc.RegisterConcrete(EventDataTxRemoved{}, "tendermint/event/TxRemoved", nil)
+	cdc.RegisterConcrete(EventDataTxStatus{}, "tendermint/event/TxStatus", nil)
+	cdc.RegisterConcrete(EventDataDoubleSignPrevented{}, "tendermint/event/DoubleSignPrevented", nil)
 	cdc.RegisterConcrete(EventDataString(""), "tendermint/event/ProposalString", nil)
 }
 
@@ -120,6 +138,61 @@ type EventDataValidatorSetUpdates struct {
 	ValidatorUpdates []*Validator `json:"validator_updates"`
 }
 
+// EventDataTxAdded is fired when a tx is added to the mempool, with the sender
+// and priority returned by CheckTx.
+type EventDataTxAdded struct {
+	Tx       Tx     `json:"tx"`
+	Sender   string `json:"sender,omitempty"`
+	Priority int64  `json:"priority"`
+}
+
+// Reasons of the removal of a tx from the mempool.
+const (
+	TxRemovedCommitted = "committed" // included in a block
+	TxRemovedExpired   = "expired"   // not committed within the mempool TTL
+	TxRemovedPriority  = "priority"  // evicted by a higher priority tx
+	TxRemovedRecheck   = "recheck"   // invalid once rechecked after a block
+)
+
+// EventDataTxRemoved is fired when a tx is removed from the mempool.
+type EventDataTxRemoved struct {
+	Tx     Tx     `json:"tx"`
+	Sender string `json:"sender,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Statuses of a tx, in the order of its lifecycle.
+const (
+	TxStatusUnknown   = "unknown"   // not tracked
+	TxStatusReceived  = "received"  // added to the mempool
+	TxStatusBroadcast = "broadcast" // sent to a peer
+	TxStatusProposed  = "proposed"  // included in a proposal block
+	TxStatusCommitted = "committed" // included in a committed block
+	TxStatusEvicted   = "evicted"   // removed from the mempool without being committed
+)
+
+// EventDataTxStatus is fired when the status of a tracked tx changes. Height
+// is set once the tx is proposed, Code once it is committed, and Reason (one
+// of the TxRemoved reasons) if it is evicted.
+type EventDataTxStatus struct {
+	Hash   tmbytes.HexBytes `json:"hash"`
+	Status string           `json:"status"`
+	Height int64            `json:"height,omitempty"`
+	Code   uint32           `json:"code,omitempty"`
+	Reason string           `json:"reason,omitempty"`
+}
+
+// EventDataDoubleSignPrevented is fired when the consensus refuses to sign a
+// vote or a proposal (Type is "prevote", "precommit" or "proposal") for
+// BlockID, which conflicts with one it signed before, as explained by Reason.
+type EventDataDoubleSignPrevented struct {
+	Height  int64   `json:"height"`
+	Round   int     `json:"round"`
+	Type    string  `json:"type"`
+	BlockID BlockID `json:"block_id"`
+	Reason  string  `json:"reason"`
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // PUBSUB
 ///////////////////////////////////////////////////////////////////////////////
@@ -133,10 +206,19 @@ const (
 	// TxHeightKey is a reserved key, used to specify transaction block's height.
 	// see EventBus#PublishEventTx
 	TxHeightKey = "tx.height"
+	// TxSenderKey is a reserved key, used to specify the sender of a mempool
+	// transaction, if the application returned one in CheckTx.
+	// see EventBus#PublishEventTxAdded
+	TxSenderKey = "tx.sender"
+	// BlockHeightKey is a reserved key, used to specify the height of a block
+	// in the searches of its BeginBlock and EndBlock events.
+	// see txindex.BlockIndexer
+	BlockHeightKey = "block.height"
 )
 
 var (
 	EventQueryCompleteProposal    = QueryForEvent(EventCompleteProposal)
+	EventQueryDoubleSignPrevented = QueryForEvent(EventDoubleSignPrevented)
 	EventQueryLock                = QueryForEvent(EventLock)
 	EventQueryNewBlock            = QueryForEvent(EventNewBlock)
 	EventQueryNewBlockHeader      = QueryForEvent(EventNewBlockHeader)
@@ -147,6 +229,9 @@ var (
 	EventQueryTimeoutPropose      = QueryForEvent(EventTimeoutPropose)
 	EventQueryTimeoutWait         = QueryForEvent(EventTimeoutWait)
 	EventQueryTx                  = QueryForEvent(EventTx)
+	EventQueryTxAdded             = QueryForEvent(EventTxAdded)
+	EventQueryTxRemoved           = QueryForEvent(EventTxRemoved)
+	EventQueryTxStatus            = QueryForEvent(EventTxStatus)
 	EventQueryUnlock              = QueryForEvent(EventUnlock)
 	EventQueryValidatorSetUpdates = QueryForEvent(EventValidatorSetUpdates)
 	EventQueryValidBlock          = QueryForEvent(EventValidBlock)
@@ -172,3 +257,14 @@ type BlockEventPublisher interface {
 type TxEventPublisher interface {
 	PublishEventTx(EventDataTx) error
 }
+
+// MempoolEventPublisher publishes the mempool events.
+type MempoolEventPublisher interface {
+	PublishEventTxAdded(EventDataTxAdded) error
+	PublishEventTxRemoved(EventDataTxRemoved) error
+}
+
+// TxStatusPublisher publishes the changes of the status of the tracked txs.
+type TxStatusPublisher interface {
+	PublishEventTxStatus(EventDataTxStatus) error
+}
@@ -13,11 +13,13 @@ import (
 
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/merkle"
+	"github.com/tendermint/tendermint/crypto/verifier"
 )
 
 const (
-	// MaxEvidenceBytes is a maximum size of any evidence (including amino overhead).
-	MaxEvidenceBytes int64 = 484
+	// MaxEvidenceBytes is a maximum size of any evidence (including amino overhead),
+	// with votes carrying extensions of MaxVoteExtensionBytes.
+	MaxEvidenceBytes int64 = 2538
 )
 
 // ErrEvidenceInvalid wraps a piece of evidence and the error denoting how or why it is invalid.
@@ -201,10 +203,14 @@ func (dve *DuplicateVoteEvidence) Verify(chainID string, pubKey crypto.PubKey) e
 	}
 
 	// Signatures must be valid
-	if !pubKey.VerifyBytes(dve.VoteA.SignBytes(chainID), dve.VoteA.Signature) {
+	valid := verifier.Verify([]verifier.Item{
+		{PubKey: pubKey, Msg: dve.VoteA.SignBytes(chainID), Sig: dve.VoteA.Signature},
+		{PubKey: pubKey, Msg: dve.VoteB.SignBytes(chainID), Sig: dve.VoteB.Signature},
+	})
+	if !valid[0] {
 		return fmt.Errorf("duplicateVoteEvidence Error verifying VoteA: %v", ErrVoteInvalidSignature)
 	}
-	if !pubKey.VerifyBytes(dve.VoteB.SignBytes(chainID), dve.VoteB.Signature) {
+	if !valid[1] {
 		return fmt.Errorf("duplicateVoteEvidence Error verifying VoteB: %v", ErrVoteInvalidSignature)
 	}
 
@@ -225,7 +231,7 @@ func (dve *DuplicateVoteEvidence) Equal(ev Evidence) bool {
 
 // ValidateBasic performs basic validation.
 func (dve *DuplicateVoteEvidence) ValidateBasic() error {
-	if len(dve.PubKey.Bytes()) == 0 {
+	if dve.PubKey == nil || len(dve.PubKey.Bytes()) == 0 {
 		return errors.New("empty PubKey")
 	}
 	if dve.VoteA == nil || dve.VoteB == nil {
@@ -106,6 +106,9 @@ func TestMaxEvidenceBytes(t *testing.T) {
 		VoteA:  makeVote(val, chainID, math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64, blockID),
 		VoteB:  makeVote(val, chainID, math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64, blockID2),
 	}
+	// the precommits carry the biggest extensions
+	ev.VoteA.Extension = make([]byte, MaxVoteExtensionBytes)
+	ev.VoteB.Extension = make([]byte, MaxVoteExtensionBytes)
 
 	bz, err := cdc.MarshalBinaryLengthPrefixed(ev)
 	require.NoError(t, err)
@@ -142,6 +145,7 @@ func TestDuplicateVoteEvidenceValidation(t *testing.T) {
 			ev.VoteA = nil
 			ev.VoteB = nil
 		}, true},
+		{"Nil PubKey", func(ev *DuplicateVoteEvidence) { ev.PubKey = nil }, true},
 		{"Invalid vote type", func(ev *DuplicateVoteEvidence) {
 			ev.VoteA = makeVote(val, chainID, math.MaxInt64, math.MaxInt64, math.MaxInt64, 0, blockID2)
 		}, true},
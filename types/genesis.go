@@ -83,6 +83,14 @@ func (genDoc *GenesisDoc) ValidateAndComplete() error {
 		if v.Power == 0 {
 			return errors.Errorf("the genesis file cannot contain validators with no voting power: %v", v)
 		}
+		keyType, err := ABCIPubKeyType(v.PubKey)
+		if err != nil {
+			return errors.Wrapf(err, "validator %v in the genesis file", v)
+		}
+		if !genDoc.ConsensusParams.Validator.IsValidPubkeyType(keyType) {
+			return errors.Errorf("the %s key of validator %v isn't allowed by consensus_params.validator.pub_key_types %v",
+				keyType, v, genDoc.ConsensusParams.Validator.PubKeyTypes)
+		}
 		if len(v.Address) > 0 && !bytes.Equal(v.PubKey.Address(), v.Address) {
 			return errors.Errorf("incorrect address for validator %v in the genesis file, should be %v", v, v.PubKey.Address())
 		}
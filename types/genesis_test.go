@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
 	tmtime "github.com/tendermint/tendermint/types/time"
 )
 
@@ -111,6 +112,21 @@ func TestGenesisGood(t *testing.T) {
 	}
 }
 
+func TestGenesisPubKeyTypes(t *testing.T) {
+	pubkey := secp256k1.GenPrivKey().PubKey()
+	genDoc := &GenesisDoc{
+		ChainID:    "abc",
+		Validators: []GenesisValidator{{pubkey.Address(), pubkey, 10, "myval"}},
+	}
+	// secp256k1 keys aren't allowed by default
+	err := genDoc.ValidateAndComplete()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "isn't allowed by consensus_params.validator.pub_key_types")
+
+	genDoc.ConsensusParams.Validator.PubKeyTypes = []string{ABCIPubKeyTypeSecp256k1}
+	assert.NoError(t, genDoc.ValidateAndComplete())
+}
+
 func TestGenesisSaveAs(t *testing.T) {
 	tmpfile, err := ioutil.TempFile("", "genesis")
 	require.NoError(t, err)
@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/registry"
 	"github.com/tendermint/tendermint/crypto/tmhash"
 	tmstrings "github.com/tendermint/tendermint/libs/strings"
 )
@@ -19,6 +20,14 @@ const (
 
 	// MaxBlockPartsCount is the maximum number of block parts.
 	MaxBlockPartsCount = (MaxBlockSizeBytes / BlockPartSizeBytes) + 1
+
+	// ProposerSelectionPriority selects proposers with the deterministic
+	// proposer priority algorithm.
+	ProposerSelectionPriority = "priority"
+	// ProposerSelectionVRF selects proposers pseudo-randomly, weighted by
+	// voting power (see ValidatorSet.RandomProposer), seeded by the VRF output
+	// proven by the proposer of the last block (see Header.ProposerProof).
+	ProposerSelectionVRF = "vrf"
 )
 
 // ConsensusParams contains consensus critical parameters that determine the
@@ -27,6 +36,8 @@ type ConsensusParams struct {
 	Block     BlockParams     `json:"block"`
 	Evidence  EvidenceParams  `json:"evidence"`
 	Validator ValidatorParams `json:"validator"`
+	Synchrony SynchronyParams `json:"synchrony"`
+	ABCI      ABCIParams      `json:"abci"`
 }
 
 // HashedParams is a subset of ConsensusParams.
@@ -53,10 +64,45 @@ type EvidenceParams struct {
 	MaxAgeDuration  time.Duration `json:"max_age_duration"`
 }
 
-// ValidatorParams restrict the public key types validators can use.
+// ValidatorParams restrict the public key types validators can use and
+// determine how the proposer is selected.
 // NOTE: uses ABCI pubkey naming, not Amino names.
 type ValidatorParams struct {
 	PubKeyTypes []string `json:"pub_key_types"`
+	// ProposerSelection is one of "priority" (default, deterministic
+	// round-robin weighted by voting power) or "vrf" (pseudo-random, weighted
+	// by voting power, seeded by the VRF output of the last proposer; requires
+	// Ed25519 validator keys). An empty value means "priority".
+	// Not exposed to the application.
+	ProposerSelection string `json:"proposer_selection,omitempty"`
+}
+
+// SynchronyParams enable proposer-based timestamps, and bound the clock drift
+// and the message delay they assume.
+// Not exposed to the application.
+type SynchronyParams struct {
+	// ProposerBasedTimestamps makes the proposer set the time of the blocks to
+	// its local time, the validators prevoting nil for a new block whose time
+	// isn't timely, rather than to the median time of the last commit (BFT
+	// time).
+	ProposerBasedTimestamps bool `json:"proposer_based_timestamps"`
+	// Precision bounds the difference between the clocks of the validators.
+	Precision time.Duration `json:"precision"`
+	// MessageDelay bounds the time it takes for a proposal to reach the
+	// validators. It grows by 10% at each round, so that consensus makes
+	// progress even if it's too small.
+	MessageDelay time.Duration `json:"message_delay"`
+}
+
+// ABCIParams configure the features of the ABCI the application relies on.
+// Not exposed to the application.
+type ABCIParams struct {
+	// VoteExtensionsEnableHeight is the first height at which the validators
+	// extend their non-nil precommits with the data returned by the
+	// application (ExtendVote), the extensions of the precommits of a height
+	// being delivered to the application in the BeginBlock of the next one.
+	// 0 disables vote extensions.
+	VoteExtensionsEnableHeight int64 `json:"vote_extensions_enable_height"`
 }
 
 // DefaultConsensusParams returns a default ConsensusParams.
@@ -65,6 +111,8 @@ func DefaultConsensusParams() *ConsensusParams {
 		DefaultBlockParams(),
 		DefaultEvidenceParams(),
 		DefaultValidatorParams(),
+		DefaultSynchronyParams(),
+		DefaultABCIParams(),
 	}
 }
 
@@ -88,7 +136,45 @@ func DefaultEvidenceParams() EvidenceParams {
 // DefaultValidatorParams returns a default ValidatorParams, which allows
 // only ed25519 pubkeys.
 func DefaultValidatorParams() ValidatorParams {
-	return ValidatorParams{[]string{ABCIPubKeyTypeEd25519}}
+	return ValidatorParams{
+		PubKeyTypes:       []string{ABCIPubKeyTypeEd25519},
+		ProposerSelection: ProposerSelectionPriority,
+	}
+}
+
+// DefaultSynchronyParams returns a default SynchronyParams, with
+// proposer-based timestamps disabled.
+func DefaultSynchronyParams() SynchronyParams {
+	return SynchronyParams{
+		ProposerBasedTimestamps: false,
+		Precision:               505 * time.Millisecond,
+		MessageDelay:            12 * time.Second,
+	}
+}
+
+// DefaultABCIParams returns a default ABCIParams, with vote extensions
+// disabled.
+func DefaultABCIParams() ABCIParams {
+	return ABCIParams{
+		VoteExtensionsEnableHeight: 0,
+	}
+}
+
+// VoteExtensionsEnabled returns true if the precommits of the given height
+// must be extended.
+func (params ABCIParams) VoteExtensionsEnabled(height int64) bool {
+	return params.VoteExtensionsEnableHeight > 0 && height >= params.VoteExtensionsEnableHeight
+}
+
+// MessageDelayInRound returns the message delay of the given round, which is
+// MessageDelay increased by 10% at each round.
+func (params SynchronyParams) MessageDelayInRound(round int) time.Duration {
+	return params.MessageDelay + params.MessageDelay/10*time.Duration(round)
+}
+
+// IsVRFProposerSelection returns true if proposers are selected by VRF.
+func (params *ValidatorParams) IsVRFProposerSelection() bool {
+	return params.ProposerSelection == ProposerSelectionVRF
 }
 
 func (params *ValidatorParams) IsValidPubkeyType(pubkeyType string) bool {
@@ -136,15 +222,47 @@ func (params *ConsensusParams) Validate() error {
 		return errors.New("len(Validator.PubKeyTypes) must be greater than 0")
 	}
 
-	// Check if keyType is a known ABCIPubKeyType
+	// Check if keyType is a registered key type
 	for i := 0; i < len(params.Validator.PubKeyTypes); i++ {
 		keyType := params.Validator.PubKeyTypes[i]
-		if _, ok := ABCIPubKeyTypesToAminoNames[keyType]; !ok {
+		if _, ok := registry.Get(keyType); !ok {
 			return errors.Errorf("params.Validator.PubKeyTypes[%d], %s, is an unknown pubkey type",
 				i, keyType)
 		}
 	}
 
+	switch params.Validator.ProposerSelection {
+	case "", ProposerSelectionPriority:
+	case ProposerSelectionVRF:
+		// the VRF proofs are made with the validators' Ed25519 keys
+		for _, keyType := range params.Validator.PubKeyTypes {
+			if keyType != ABCIPubKeyTypeEd25519 {
+				return errors.Errorf("params.Validator.PubKeyTypes must be [%q] with the %q proposer selection, got %v",
+					ABCIPubKeyTypeEd25519, ProposerSelectionVRF, params.Validator.PubKeyTypes)
+			}
+		}
+	default:
+		return errors.Errorf("params.Validator.ProposerSelection, %s, is unknown (must be %q or %q)",
+			params.Validator.ProposerSelection, ProposerSelectionPriority, ProposerSelectionVRF)
+	}
+
+	if params.Synchrony.Precision < 0 {
+		return errors.Errorf("synchrony.Precision can't be negative. Got %v", params.Synchrony.Precision)
+	}
+	if params.Synchrony.MessageDelay < 0 {
+		return errors.Errorf("synchrony.MessageDelay can't be negative. Got %v", params.Synchrony.MessageDelay)
+	}
+	if params.Synchrony.ProposerBasedTimestamps &&
+		(params.Synchrony.Precision == 0 || params.Synchrony.MessageDelay == 0) {
+		return errors.New("synchrony.Precision and synchrony.MessageDelay must be greater than 0 " +
+			"with proposer-based timestamps")
+	}
+
+	if params.ABCI.VoteExtensionsEnableHeight < 0 {
+		return errors.Errorf("abci.VoteExtensionsEnableHeight can't be negative. Got %d",
+			params.ABCI.VoteExtensionsEnableHeight)
+	}
+
 	return nil
 }
 
@@ -168,7 +286,10 @@ func (params *ConsensusParams) Hash() []byte {
 func (params *ConsensusParams) Equals(params2 *ConsensusParams) bool {
 	return params.Block == params2.Block &&
 		params.Evidence == params2.Evidence &&
-		tmstrings.StringSliceEqual(params.Validator.PubKeyTypes, params2.Validator.PubKeyTypes)
+		tmstrings.StringSliceEqual(params.Validator.PubKeyTypes, params2.Validator.PubKeyTypes) &&
+		params.Validator.ProposerSelection == params2.Validator.ProposerSelection &&
+		params.Synchrony == params2.Synchrony &&
+		params.ABCI == params2.ABCI
 }
 
 // Update returns a copy of the params with updates from the non-zero fields of p2.
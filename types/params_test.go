@@ -38,6 +38,58 @@ func TestConsensusParamsValidation(t *testing.T) {
 		// test invalid pubkey type provided
 		12: {makeParams(1, 0, 10, 1, []string{"potatoes make good pubkeys"}), false},
 	}
+	vrfParams := makeParams(1, 0, 10, 1, valEd25519)
+	vrfParams.Validator.ProposerSelection = ProposerSelectionVRF
+	vrfSecp256k1 := makeParams(1, 0, 10, 1, valEd25519)
+	vrfSecp256k1.Validator.PubKeyTypes = []string{ABCIPubKeyTypeEd25519, ABCIPubKeyTypeSecp256k1}
+	vrfSecp256k1.Validator.ProposerSelection = ProposerSelectionVRF
+	badSelection := makeParams(1, 0, 10, 1, valEd25519)
+	badSelection.Validator.ProposerSelection = "coin-toss"
+	pbtsParams := makeParams(1, 0, 10, 1, valEd25519)
+	pbtsParams.Synchrony = DefaultSynchronyParams()
+	pbtsParams.Synchrony.ProposerBasedTimestamps = true
+	noDelayParams := pbtsParams
+	noDelayParams.Synchrony.MessageDelay = 0
+	negativePrecision := makeParams(1, 0, 10, 1, valEd25519)
+	negativePrecision.Synchrony.Precision = -1
+	extensionsParams := makeParams(1, 0, 10, 1, valEd25519)
+	extensionsParams.ABCI.VoteExtensionsEnableHeight = 10
+	negativeExtensionsHeight := makeParams(1, 0, 10, 1, valEd25519)
+	negativeExtensionsHeight.ABCI.VoteExtensionsEnableHeight = -1
+	testCases = append(testCases,
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{vrfParams, true},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{vrfSecp256k1, false},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{badSelection, false},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{pbtsParams, true},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{noDelayParams, false},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{negativePrecision, false},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{extensionsParams, true},
+		struct {
+			params ConsensusParams
+			valid  bool
+		}{negativeExtensionsHeight, false},
+	)
 	for i, tc := range testCases {
 		if tc.valid {
 			assert.NoErrorf(t, tc.params.Validate(), "expected no error for valid params (#%d)", i)
@@ -131,3 +183,12 @@ func TestConsensusParamsUpdate(t *testing.T) {
 		assert.Equal(t, tc.updatedParams, tc.params.Update(tc.updates))
 	}
 }
+
+func TestABCIParamsVoteExtensionsEnabled(t *testing.T) {
+	assert.False(t, DefaultABCIParams().VoteExtensionsEnabled(1))
+
+	params := ABCIParams{VoteExtensionsEnableHeight: 10}
+	assert.False(t, params.VoteExtensionsEnabled(9))
+	assert.True(t, params.VoteExtensionsEnabled(10))
+	assert.True(t, params.VoteExtensionsEnabled(11))
+}
@@ -27,6 +27,9 @@ type Part struct {
 
 // ValidateBasic performs basic validation.
 func (part *Part) ValidateBasic() error {
+	if part == nil {
+		return errors.New("nil part")
+	}
 	if part.Index < 0 {
 		return errors.New("negative Index")
 	}
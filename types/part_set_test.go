@@ -135,4 +135,5 @@ func TestPartValidateBasic(t *testing.T) {
 			assert.Equal(t, tc.expectErr, part.ValidateBasic() != nil, "Validate Basic had an unexpected result")
 		})
 	}
+	assert.Error(t, (*Part)(nil).ValidateBasic())
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/vrf"
 )
 
 // PrivValidator defines the functionality of a local Tendermint validator
@@ -19,6 +20,13 @@ type PrivValidator interface {
 	SignProposal(chainID string, proposal *Proposal) error
 }
 
+// VRFProver is implemented by the private validators which can prove the VRF
+// outputs of their Ed25519 key (see crypto/vrf), as the proposers must if they
+// are selected by VRF (see ProposerProofInput).
+type VRFProver interface {
+	ProveVRF(alpha []byte) ([]byte, error)
+}
+
 //----------------------------------------
 // Misc.
 
@@ -95,6 +103,15 @@ func (pv MockPV) SignProposal(chainID string, proposal *Proposal) error {
 	return nil
 }
 
+// Implements VRFProver.
+func (pv MockPV) ProveVRF(alpha []byte) ([]byte, error) {
+	privKey, ok := pv.PrivKey.(ed25519.PrivKeyEd25519)
+	if !ok {
+		return nil, fmt.Errorf("can't prove VRF outputs with %T keys", pv.PrivKey)
+	}
+	return vrf.Prove(privKey, alpha), nil
+}
+
 // String returns a string representation of the MockPV.
 func (pv MockPV) String() string {
 	addr := pv.GetPubKey().Address()
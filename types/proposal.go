@@ -1,10 +1,15 @@
 package types
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"time"
 
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/vrf"
 	"github.com/tendermint/tendermint/libs/bytes"
 	tmtime "github.com/tendermint/tendermint/types/time"
 )
@@ -28,6 +33,9 @@ type Proposal struct {
 	BlockID   BlockID   `json:"block_id"`
 	Timestamp time.Time `json:"timestamp"`
 	Signature []byte    `json:"signature"`
+	// VRF proof of the proposer, if the proposers are selected by VRF
+	// (see ProposerProofInput)
+	ProposerProof bytes.HexBytes `json:"proposer_proof,omitempty"`
 }
 
 // NewProposal returns a new Proposal.
@@ -45,6 +53,9 @@ func NewProposal(height int64, round int, polRound int, blockID BlockID) *Propos
 
 // ValidateBasic performs basic validation.
 func (p *Proposal) ValidateBasic() error {
+	if p == nil {
+		return errors.New("nil proposal")
+	}
 	if p.Type != ProposalType {
 		return errors.New("invalid Type")
 	}
@@ -73,9 +84,21 @@ func (p *Proposal) ValidateBasic() error {
 	if len(p.Signature) > MaxSignatureSize {
 		return fmt.Errorf("signature is too big (max: %d)", MaxSignatureSize)
 	}
+	if len(p.ProposerProof) != 0 && len(p.ProposerProof) != vrf.ProofSize {
+		return fmt.Errorf("expected len(ProposerProof) to be 0 or %d, got %d", vrf.ProofSize, len(p.ProposerProof))
+	}
 	return nil
 }
 
+// IsTimely returns true if the proposal, received at receiveTime, is timely for
+// proposer-based timestamps: it wasn't received before its Timestamp, nor after
+// the message delay of its round, give or take the precision of the clocks.
+func (p *Proposal) IsTimely(receiveTime time.Time, params SynchronyParams) bool {
+	earliest := p.Timestamp.Add(-params.Precision)
+	latest := p.Timestamp.Add(params.MessageDelayInRound(p.Round) + params.Precision)
+	return !receiveTime.Before(earliest) && !receiveTime.After(latest)
+}
+
 // String returns a string representation of the Proposal.
 func (p *Proposal) String() string {
 	return fmt.Sprintf("Proposal{%v/%v (%v, %v) %X @ %s}",
@@ -95,3 +118,38 @@ func (p *Proposal) SignBytes(chainID string) []byte {
 	}
 	return bz
 }
+
+// ProposerProofInput returns the VRF input of the proofs of the proposers at
+// the given height, where vrfSeed is the VRF output proven by the proposer of
+// the parent block (or the hash of the parent block if it has no proof).
+// It doesn't depend on the round, so that the proof of a block can be verified
+// from the block alone.
+func ProposerProofInput(chainID string, vrfSeed []byte, height int64) []byte {
+	alpha := make([]byte, 0, tmhash.Size+8+len(vrfSeed))
+	alpha = append(alpha, tmhash.Sum([]byte(chainID))...)
+	alpha = append(alpha, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(alpha[tmhash.Size:], uint64(height))
+	return append(alpha, vrfSeed...)
+}
+
+// ProveProposer returns the VRF proof of the proposer signing with pv at the
+// given height (see ProposerProofInput). pv must implement VRFProver.
+func ProveProposer(pv PrivValidator, chainID string, vrfSeed []byte, height int64) ([]byte, error) {
+	prover, ok := pv.(VRFProver)
+	if !ok {
+		return nil, fmt.Errorf("%T can't prove VRF outputs", pv)
+	}
+	return prover.ProveVRF(ProposerProofInput(chainID, vrfSeed, height))
+}
+
+// VerifyProposerProof verifies the VRF proof of the proposer with the given
+// public key at the given height (see ProposerProofInput), and returns the VRF
+// output.
+func VerifyProposerProof(pubKey crypto.PubKey, chainID string, vrfSeed []byte, height int64,
+	proof []byte) ([]byte, error) {
+	edPubKey, ok := pubKey.(ed25519.PubKeyEd25519)
+	if !ok {
+		return nil, fmt.Errorf("can't verify the VRF proofs of %T keys", pubKey)
+	}
+	return vrf.Verify(edPubKey, ProposerProofInput(chainID, vrfSeed, height), proof)
+}
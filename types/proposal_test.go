@@ -7,7 +7,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
 	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/vrf"
 )
 
 var testProposal *Proposal
@@ -123,6 +125,12 @@ func TestProposalValidateBasic(t *testing.T) {
 		{"Too big Signature", func(p *Proposal) {
 			p.Signature = make([]byte, MaxSignatureSize+1)
 		}, true},
+		{"ProposerProof", func(p *Proposal) {
+			p.ProposerProof = make([]byte, vrf.ProofSize)
+		}, false},
+		{"Invalid ProposerProof", func(p *Proposal) {
+			p.ProposerProof = make([]byte, vrf.ProofSize-1)
+		}, true},
 	}
 	blockID := makeBlockID(tmhash.Sum([]byte("blockhash")), math.MaxInt64, tmhash.Sum([]byte("partshash")))
 
@@ -138,4 +146,62 @@ func TestProposalValidateBasic(t *testing.T) {
 			assert.Equal(t, tc.expectErr, prop.ValidateBasic() != nil, "Validate Basic had an unexpected result")
 		})
 	}
+	assert.Error(t, (*Proposal)(nil).ValidateBasic())
+}
+
+func TestProposalIsTimely(t *testing.T) {
+	now := time.Now()
+	params := SynchronyParams{
+		ProposerBasedTimestamps: true,
+		Precision:               500 * time.Millisecond,
+		MessageDelay:            2 * time.Second,
+	}
+
+	testCases := []struct {
+		testName    string
+		round       int
+		receiveTime time.Time
+		timely      bool
+	}{
+		{"received at the timestamp", 0, now, true},
+		{"received within the precision before", 0, now.Add(-400 * time.Millisecond), true},
+		{"received too early", 0, now.Add(-600 * time.Millisecond), false},
+		{"received within the message delay", 0, now.Add(2400 * time.Millisecond), true},
+		{"received too late", 0, now.Add(2600 * time.Millisecond), false},
+		{"received later in a later round", 5, now.Add(3400 * time.Millisecond), true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			p := &Proposal{Round: tc.round, Timestamp: now}
+			assert.Equal(t, tc.timely, p.IsTimely(tc.receiveTime, params))
+		})
+	}
+}
+
+func TestProposerProof(t *testing.T) {
+	privVal := NewMockPV()
+	pubKey := privVal.GetPubKey()
+	seed := tmhash.Sum([]byte("seed"))
+
+	proof, err := ProveProposer(privVal, "test_chain_id", seed, 2)
+	require.NoError(t, err)
+	output, err := VerifyProposerProof(pubKey, "test_chain_id", seed, 2, proof)
+	require.NoError(t, err)
+	assert.Len(t, output, vrf.OutputSize)
+
+	// the proof is bound to the chain, the seed and the height
+	_, err = VerifyProposerProof(pubKey, "other_chain_id", seed, 2, proof)
+	assert.Error(t, err)
+	_, err = VerifyProposerProof(pubKey, "test_chain_id", tmhash.Sum([]byte("other seed")), 2, proof)
+	assert.Error(t, err)
+	_, err = VerifyProposerProof(pubKey, "test_chain_id", seed, 3, proof)
+	assert.Error(t, err)
+
+	// only Ed25519 keys can prove VRF outputs
+	secpPV := NewMockPVWithParams(secp256k1.GenPrivKey(), false, false)
+	_, err = ProveProposer(secpPV, "test_chain_id", seed, 2)
+	assert.Error(t, err)
+	_, err = VerifyProposerProof(secpPV.GetPubKey(), "test_chain_id", seed, 2, proof)
+	assert.Error(t, err)
 }
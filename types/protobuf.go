@@ -7,9 +7,7 @@ import (
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto"
-	"github.com/tendermint/tendermint/crypto/ed25519"
-	"github.com/tendermint/tendermint/crypto/secp256k1"
-	"github.com/tendermint/tendermint/crypto/sr25519"
+	"github.com/tendermint/tendermint/crypto/registry"
 )
 
 //-------------------------------------------------------
@@ -20,20 +18,14 @@ const (
 	ABCIEvidenceTypeMock          = "mock/evidence"
 )
 
+// ABCI types of the built-in keys. More key types can be added to the
+// crypto/registry.
 const (
-	ABCIPubKeyTypeEd25519   = "ed25519"
-	ABCIPubKeyTypeSr25519   = "sr25519"
-	ABCIPubKeyTypeSecp256k1 = "secp256k1"
+	ABCIPubKeyTypeEd25519   = registry.KeyTypeEd25519
+	ABCIPubKeyTypeSr25519   = registry.KeyTypeSr25519
+	ABCIPubKeyTypeSecp256k1 = registry.KeyTypeSecp256k1
 )
 
-// TODO: Make non-global by allowing for registration of more pubkey types
-
-var ABCIPubKeyTypesToAminoNames = map[string]string{
-	ABCIPubKeyTypeEd25519:   ed25519.PubKeyAminoName,
-	ABCIPubKeyTypeSr25519:   sr25519.PubKeyAminoName,
-	ABCIPubKeyTypeSecp256k1: secp256k1.PubKeyAminoName,
-}
-
 //-------------------------------------------------------
 
 // TM2PB is used for converting Tendermint ABCI to protobuf ABCI.
@@ -97,28 +89,27 @@ func (tm2pb) ValidatorUpdate(val *Validator) abci.ValidatorUpdate {
 	}
 }
 
+// ABCIPubKeyType returns the ABCI type of pubKey (as in
+// ConsensusParams.Validator.PubKeyTypes), or an error if pubKey can't be the
+// key of a validator, i.e. its type isn't in the crypto/registry.
+func ABCIPubKeyType(pubKey crypto.PubKey) (string, error) {
+	kt, ok := registry.ByPubKey(pubKey)
+	if !ok {
+		return "", fmt.Errorf("unsupported key type %T", pubKey)
+	}
+	return kt.Name, nil
+}
+
 // XXX: panics on nil or unknown pubkey type
-// TODO: add cases when new pubkey types are added to crypto
 func (tm2pb) PubKey(pubKey crypto.PubKey) abci.PubKey {
-	switch pk := pubKey.(type) {
-	case ed25519.PubKeyEd25519:
-		return abci.PubKey{
-			Type: ABCIPubKeyTypeEd25519,
-			Data: pk[:],
-		}
-	case sr25519.PubKeySr25519:
-		return abci.PubKey{
-			Type: ABCIPubKeyTypeSr25519,
-			Data: pk[:],
-		}
-	case secp256k1.PubKeySecp256k1:
-		return abci.PubKey{
-			Type: ABCIPubKeyTypeSecp256k1,
-			Data: pk[:],
-		}
-	default:
+	kt, ok := registry.ByPubKey(pubKey)
+	if !ok {
 		panic(fmt.Sprintf("unknown pubkey type: %v %v", pubKey, reflect.TypeOf(pubKey)))
 	}
+	return abci.PubKey{
+		Type: kt.Name,
+		Data: kt.PubKeyBytes(pubKey),
+	}
 }
 
 // XXX: panics on nil or unknown pubkey type
@@ -195,34 +186,11 @@ var PB2TM = pb2tm{}
 type pb2tm struct{}
 
 func (pb2tm) PubKey(pubKey abci.PubKey) (crypto.PubKey, error) {
-	switch pubKey.Type {
-	case ABCIPubKeyTypeEd25519:
-		if len(pubKey.Data) != ed25519.PubKeyEd25519Size {
-			return nil, fmt.Errorf("invalid size for PubKeyEd25519. Got %d, expected %d",
-				len(pubKey.Data), ed25519.PubKeyEd25519Size)
-		}
-		var pk ed25519.PubKeyEd25519
-		copy(pk[:], pubKey.Data)
-		return pk, nil
-	case ABCIPubKeyTypeSr25519:
-		if len(pubKey.Data) != sr25519.PubKeySr25519Size {
-			return nil, fmt.Errorf("invalid size for PubKeySr25519. Got %d, expected %d",
-				len(pubKey.Data), sr25519.PubKeySr25519Size)
-		}
-		var pk sr25519.PubKeySr25519
-		copy(pk[:], pubKey.Data)
-		return pk, nil
-	case ABCIPubKeyTypeSecp256k1:
-		if len(pubKey.Data) != secp256k1.PubKeySecp256k1Size {
-			return nil, fmt.Errorf("invalid size for PubKeySecp256k1. Got %d, expected %d",
-				len(pubKey.Data), secp256k1.PubKeySecp256k1Size)
-		}
-		var pk secp256k1.PubKeySecp256k1
-		copy(pk[:], pubKey.Data)
-		return pk, nil
-	default:
+	kt, ok := registry.Get(pubKey.Type)
+	if !ok {
 		return nil, fmt.Errorf("unknown pubkey type %v", pubKey.Type)
 	}
+	return kt.PubKeyFromBytes(pubKey.Data)
 }
 
 func (pb2tm) ValidatorUpdates(vals []abci.ValidatorUpdate) ([]*Validator, error) {
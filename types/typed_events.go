@@ -0,0 +1,252 @@
+package types
+
+import (
+	"context"
+	"fmt"
+
+	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+)
+
+// Typed subscriptions deliver the data of a single event type, so that
+// consumers don't have to assert the type of each message themselves:
+//
+//     sub, err := eventBus.SubscribeNewBlocks(ctx, "indexer")
+//     if err != nil {
+//         return err
+//     }
+//     for {
+//         data, err := sub.Next(ctx)
+//         if err != nil {
+//             return err // unsubscribed, terminated or ctx done
+//         }
+//         // handle data.Block
+//     }
+//
+// Each typed subscription wraps a Subscription, which can be created with any
+// capacity and overflow policy (e.g. NewTxSubscription(sub)).
+
+// ErrUnexpectedEventData is returned by Next of a typed subscription if a
+// message carries data of another type, which means the subscription's query
+// matched other events.
+type ErrUnexpectedEventData struct {
+	EventType string
+	Data      interface{}
+}
+
+func (e ErrUnexpectedEventData) Error() string {
+	return fmt.Sprintf("unexpected data %T for event %s", e.Data, e.EventType)
+}
+
+// typedSubscription is the part common to all typed subscriptions.
+type typedSubscription struct {
+	Subscription
+}
+
+// next blocks until a message is published, the subscription is cancelled or
+// ctx is done.
+func (s typedSubscription) next(ctx context.Context) (interface{}, error) {
+	select {
+	case msg := <-s.Out():
+		return msg.Data(), nil
+	case <-s.Cancelled():
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		// the event bus was stopped
+		return nil, tmpubsub.ErrUnsubscribed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// NewBlockSubscription delivers EventNewBlock data.
+type NewBlockSubscription struct{ typedSubscription }
+
+// NewNewBlockSubscription wraps sub, which must be subscribed to
+// EventQueryNewBlock.
+func NewNewBlockSubscription(sub Subscription) NewBlockSubscription {
+	return NewBlockSubscription{typedSubscription{sub}}
+}
+
+// Next returns the next new block. See typed subscriptions.
+func (s NewBlockSubscription) Next(ctx context.Context) (EventDataNewBlock, error) {
+	data, err := s.next(ctx)
+	if err != nil {
+		return EventDataNewBlock{}, err
+	}
+	block, ok := data.(EventDataNewBlock)
+	if !ok {
+		return EventDataNewBlock{}, ErrUnexpectedEventData{EventNewBlock, data}
+	}
+	return block, nil
+}
+
+// NewBlockHeaderSubscription delivers EventNewBlockHeader data.
+type NewBlockHeaderSubscription struct{ typedSubscription }
+
+// NewNewBlockHeaderSubscription wraps sub, which must be subscribed to
+// EventQueryNewBlockHeader.
+func NewNewBlockHeaderSubscription(sub Subscription) NewBlockHeaderSubscription {
+	return NewBlockHeaderSubscription{typedSubscription{sub}}
+}
+
+// Next returns the next new block header. See typed subscriptions.
+func (s NewBlockHeaderSubscription) Next(ctx context.Context) (EventDataNewBlockHeader, error) {
+	data, err := s.next(ctx)
+	if err != nil {
+		return EventDataNewBlockHeader{}, err
+	}
+	header, ok := data.(EventDataNewBlockHeader)
+	if !ok {
+		return EventDataNewBlockHeader{}, ErrUnexpectedEventData{EventNewBlockHeader, data}
+	}
+	return header, nil
+}
+
+// TxSubscription delivers EventTx data.
+type TxSubscription struct{ typedSubscription }
+
+// NewTxSubscription wraps sub, which must be subscribed to EventQueryTx or a
+// query for a subset of its events.
+func NewTxSubscription(sub Subscription) TxSubscription {
+	return TxSubscription{typedSubscription{sub}}
+}
+
+// Next returns the next tx. See typed subscriptions.
+func (s TxSubscription) Next(ctx context.Context) (EventDataTx, error) {
+	data, err := s.next(ctx)
+	if err != nil {
+		return EventDataTx{}, err
+	}
+	tx, ok := data.(EventDataTx)
+	if !ok {
+		return EventDataTx{}, ErrUnexpectedEventData{EventTx, data}
+	}
+	return tx, nil
+}
+
+// VoteSubscription delivers EventVote data.
+type VoteSubscription struct{ typedSubscription }
+
+// NewVoteSubscription wraps sub, which must be subscribed to EventQueryVote.
+func NewVoteSubscription(sub Subscription) VoteSubscription {
+	return VoteSubscription{typedSubscription{sub}}
+}
+
+// Next returns the next vote. See typed subscriptions.
+func (s VoteSubscription) Next(ctx context.Context) (EventDataVote, error) {
+	data, err := s.next(ctx)
+	if err != nil {
+		return EventDataVote{}, err
+	}
+	vote, ok := data.(EventDataVote)
+	if !ok {
+		return EventDataVote{}, ErrUnexpectedEventData{EventVote, data}
+	}
+	return vote, nil
+}
+
+// ValidatorSetUpdatesSubscription delivers EventValidatorSetUpdates data.
+type ValidatorSetUpdatesSubscription struct{ typedSubscription }
+
+// NewValidatorSetUpdatesSubscription wraps sub, which must be subscribed to
+// EventQueryValidatorSetUpdates.
+func NewValidatorSetUpdatesSubscription(sub Subscription) ValidatorSetUpdatesSubscription {
+	return ValidatorSetUpdatesSubscription{typedSubscription{sub}}
+}
+
+// Next returns the next validator set updates. See typed subscriptions.
+func (s ValidatorSetUpdatesSubscription) Next(ctx context.Context) (EventDataValidatorSetUpdates, error) {
+	data, err := s.next(ctx)
+	if err != nil {
+		return EventDataValidatorSetUpdates{}, err
+	}
+	updates, ok := data.(EventDataValidatorSetUpdates)
+	if !ok {
+		return EventDataValidatorSetUpdates{}, ErrUnexpectedEventData{EventValidatorSetUpdates, data}
+	}
+	return updates, nil
+}
+
+// SubscribeNewBlocks subscribes to EventNewBlock (see Subscribe).
+func (b *EventBus) SubscribeNewBlocks(
+	ctx context.Context,
+	subscriber string,
+	outCapacity ...int,
+) (NewBlockSubscription, error) {
+	sub, err := b.Subscribe(ctx, subscriber, EventQueryNewBlock, outCapacity...)
+	if err != nil {
+		return NewBlockSubscription{}, err
+	}
+	return NewNewBlockSubscription(sub), nil
+}
+
+// SubscribeNewBlockHeaders subscribes to EventNewBlockHeader (see Subscribe).
+func (b *EventBus) SubscribeNewBlockHeaders(
+	ctx context.Context,
+	subscriber string,
+	outCapacity ...int,
+) (NewBlockHeaderSubscription, error) {
+	sub, err := b.Subscribe(ctx, subscriber, EventQueryNewBlockHeader, outCapacity...)
+	if err != nil {
+		return NewBlockHeaderSubscription{}, err
+	}
+	return NewNewBlockHeaderSubscription(sub), nil
+}
+
+// SubscribeTxs subscribes to EventTx (see Subscribe). If conditions is not
+// empty, only txs whose events match it are delivered, e.g.
+// "transfer.sender='addr1'". Unsubscribe with EventQueryTxWhere(conditions).
+func (b *EventBus) SubscribeTxs(
+	ctx context.Context,
+	subscriber string,
+	conditions string,
+	outCapacity ...int,
+) (TxSubscription, error) {
+	q, err := EventQueryTxWhere(conditions)
+	if err != nil {
+		return TxSubscription{}, err
+	}
+	sub, err := b.Subscribe(ctx, subscriber, q, outCapacity...)
+	if err != nil {
+		return TxSubscription{}, err
+	}
+	return NewTxSubscription(sub), nil
+}
+
+// SubscribeVotes subscribes to EventVote (see Subscribe).
+func (b *EventBus) SubscribeVotes(
+	ctx context.Context,
+	subscriber string,
+	outCapacity ...int,
+) (VoteSubscription, error) {
+	sub, err := b.Subscribe(ctx, subscriber, EventQueryVote, outCapacity...)
+	if err != nil {
+		return VoteSubscription{}, err
+	}
+	return NewVoteSubscription(sub), nil
+}
+
+// SubscribeValidatorSetUpdates subscribes to EventValidatorSetUpdates (see
+// Subscribe).
+func (b *EventBus) SubscribeValidatorSetUpdates(
+	ctx context.Context,
+	subscriber string,
+	outCapacity ...int,
+) (ValidatorSetUpdatesSubscription, error) {
+	sub, err := b.Subscribe(ctx, subscriber, EventQueryValidatorSetUpdates, outCapacity...)
+	if err != nil {
+		return ValidatorSetUpdatesSubscription{}, err
+	}
+	return NewValidatorSetUpdatesSubscription(sub), nil
+}
+
+// EventQueryTxWhere returns the query for the txs whose events match
+// conditions, or for all txs if conditions is empty.
+func EventQueryTxWhere(conditions string) (tmpubsub.Query, error) {
+	if conditions == "" {
+		return EventQueryTx, nil
+	}
+	return tmquery.New(fmt.Sprintf("%s='%s' AND %s", EventTypeKey, EventTx, conditions))
+}
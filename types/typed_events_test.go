@@ -0,0 +1,92 @@
+package types
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/kv"
+	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
+)
+
+func TestEventBusSubscribeTxs(t *testing.T) {
+	eventBus := NewEventBus()
+	err := eventBus.Start()
+	require.NoError(t, err)
+	defer eventBus.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	txs, err := eventBus.SubscribeTxs(ctx, "test", "testType.baz=1", 2)
+	require.NoError(t, err)
+
+	for i, value := range []string{"2", "1"} {
+		err = eventBus.PublishEventTx(EventDataTx{TxResult{
+			Height: 1,
+			Index:  uint32(i),
+			Tx:     Tx("foo"),
+			Result: abci.ResponseDeliverTx{Events: []abci.Event{
+				{Type: "testType", Attributes: []kv.Pair{{Key: []byte("baz"), Value: []byte(value)}}},
+			}},
+		}})
+		require.NoError(t, err)
+	}
+
+	tx, err := txs.Next(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, tx.Index)
+
+	q, err := EventQueryTxWhere("testType.baz=1")
+	require.NoError(t, err)
+	err = eventBus.Unsubscribe(ctx, "test", q)
+	require.NoError(t, err)
+	_, err = txs.Next(ctx)
+	assert.Equal(t, tmpubsub.ErrUnsubscribed, err)
+}
+
+func TestEventBusSubscribeNewBlocks(t *testing.T) {
+	eventBus := NewEventBus()
+	err := eventBus.Start()
+	require.NoError(t, err)
+	defer eventBus.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	blocks, err := eventBus.SubscribeNewBlocks(ctx, "test")
+	require.NoError(t, err)
+
+	block := MakeBlock(0, []Tx{}, nil, []Evidence{})
+	err = eventBus.PublishEventNewBlock(EventDataNewBlock{Block: block})
+	require.NoError(t, err)
+
+	data, err := blocks.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, block, data.Block)
+
+	// nothing else is published
+	_, err = blocks.Next(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestTypedSubscriptionUnexpectedData(t *testing.T) {
+	eventBus := NewEventBus()
+	err := eventBus.Start()
+	require.NoError(t, err)
+	defer eventBus.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	sub, err := eventBus.Subscribe(ctx, "test", QueryForEvent(EventVote))
+	require.NoError(t, err)
+	votes := NewVoteSubscription(sub)
+
+	err = eventBus.Publish(EventVote, EventDataString("not a vote"))
+	require.NoError(t, err)
+
+	_, err = votes.Next(ctx)
+	assert.Equal(t, ErrUnexpectedEventData{EventVote, EventDataString("not a vote")}, err)
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/big"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tendermint/tendermint/crypto/merkle"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/crypto/verifier"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 )
 
@@ -299,6 +302,46 @@ func (vals *ValidatorSet) GetProposer() (proposer *Validator) {
 	return vals.Proposer.Copy()
 }
 
+// ProposerSeed returns the seed used by the VRF proposer selection for the
+// given height and round, where vrfSeed is the VRF output proven by the
+// proposer of the parent block (see ProposerProofInput). Since that output is
+// unique, the proposer of the parent block can't bias the selection, but the
+// proposers of all the rounds of a height are known once its parent block is
+// committed.
+func ProposerSeed(chainID string, vrfSeed []byte, height int64, round int) []byte {
+	hasher := tmhash.New()
+	hasher.Write([]byte(chainID))
+	hasher.Write(vrfSeed)
+	var bz [16]byte
+	binary.BigEndian.PutUint64(bz[:8], uint64(height))
+	binary.BigEndian.PutUint64(bz[8:], uint64(round))
+	hasher.Write(bz[:])
+	return hasher.Sum(nil)
+}
+
+// RandomProposer returns the proposer selected by the seed r (see
+// ProposerSeed). Each validator is chosen with probability proportional to its
+// voting power. The selection is deterministic in r and does not modify the
+// set's proposer priorities. If the validator set is empty, nil is returned.
+func (vals *ValidatorSet) RandomProposer(r []byte) *Validator {
+	if len(vals.Validators) == 0 {
+		return nil
+	}
+	total := vals.TotalVotingPower()
+	// Reduce a 256 bit digest of r modulo the total power; the bias is
+	// negligible since total power is bounded by MaxTotalVotingPower.
+	digest := tmhash.Sum(r)
+	target := new(big.Int).Mod(new(big.Int).SetBytes(digest), big.NewInt(total)).Int64()
+	for _, val := range vals.Validators {
+		if target < val.VotingPower {
+			return val.Copy()
+		}
+		target -= val.VotingPower
+	}
+	// unreachable: target < total
+	return vals.Validators[len(vals.Validators)-1].Copy()
+}
+
 func (vals *ValidatorSet) findProposer() *Validator {
 	var proposer *Validator
 	for _, val := range vals.Validators {
@@ -635,8 +678,10 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 		return err
 	}
 
-	talliedVotingPower := int64(0)
-	votingPowerNeeded := vals.TotalVotingPower() * 2 / 3
+	var (
+		idxs  = make([]int, 0, len(commit.Signatures))
+		items = make([]verifier.Item, 0, len(commit.Signatures))
+	)
 	for idx, commitSig := range commit.Signatures {
 		if commitSig.Absent() {
 			continue // OK, some signatures can be absent.
@@ -644,11 +689,25 @@ func (vals *ValidatorSet) VerifyCommit(chainID string, blockID BlockID,
 
 		// The vals and commit have a 1-to-1 correspondance.
 		// This means we don't need the validator address or to do any lookup.
+		idxs = append(idxs, idx)
+		items = append(items, verifier.Item{
+			PubKey: vals.Validators[idx].PubKey,
+			Msg:    commit.VoteSignBytes(chainID, idx),
+			Sig:    commitSig.Signature,
+		})
+	}
+	// Signatures are checked in parallel, then tallied in commit order so the
+	// outcome is the same as checking them one by one.
+	valid := verifier.Verify(items)
+
+	talliedVotingPower := int64(0)
+	votingPowerNeeded := vals.TotalVotingPower() * 2 / 3
+	for i, idx := range idxs {
+		commitSig := commit.Signatures[idx]
 		val := vals.Validators[idx]
 
 		// Validate signature.
-		voteSignBytes := commit.VoteSignBytes(chainID, idx)
-		if !val.PubKey.VerifyBytes(voteSignBytes, commitSig.Signature) {
+		if !valid[i] {
 			return fmt.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
 		}
 		// Good!
@@ -710,9 +769,13 @@ func (vals *ValidatorSet) VerifyFutureCommit(newSet *ValidatorSet, chainID strin
 	}
 
 	// Check old voting power.
-	oldVotingPower := int64(0)
-	seen := map[int]bool{}
-
+	var (
+		oldVotingPower = int64(0)
+		seen           = map[int]bool{}
+		idxs           = make([]int, 0, len(commit.Signatures))
+		signers        = make([]*Validator, 0, len(commit.Signatures))
+		items          = make([]verifier.Item, 0, len(commit.Signatures))
+	)
 	for idx, commitSig := range commit.Signatures {
 		if commitSig.Absent() {
 			continue // OK, some signatures can be absent.
@@ -725,14 +788,26 @@ func (vals *ValidatorSet) VerifyFutureCommit(newSet *ValidatorSet, chainID strin
 		}
 		seen[oldIdx] = true
 
+		idxs = append(idxs, idx)
+		signers = append(signers, val)
+		items = append(items, verifier.Item{
+			PubKey: val.PubKey,
+			Msg:    commit.VoteSignBytes(chainID, idx),
+			Sig:    commitSig.Signature,
+		})
+	}
+	valid := verifier.Verify(items)
+
+	for i, idx := range idxs {
+		commitSig := commit.Signatures[idx]
+
 		// Validate signature.
-		voteSignBytes := commit.VoteSignBytes(chainID, idx)
-		if !val.PubKey.VerifyBytes(voteSignBytes, commitSig.Signature) {
+		if !valid[i] {
 			return errors.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
 		}
 		// Good!
 		if blockID.Equals(commitSig.BlockID(commit.BlockID)) {
-			oldVotingPower += val.VotingPower
+			oldVotingPower += signers[i].VotingPower
 		}
 		// else {
 		// It's OK that the BlockID doesn't match.  We include stray
@@ -766,6 +841,11 @@ func (vals *ValidatorSet) VerifyCommitTrusting(chainID string, blockID BlockID,
 		talliedVotingPower int64
 		seenVals           = make(map[int]int, len(commit.Signatures)) // validator index -> commit index
 		votingPowerNeeded  = (vals.TotalVotingPower() * trustLevel.Numerator) / trustLevel.Denominator
+
+		idxs          = make([]int, 0, len(commit.Signatures))
+		signers       = make([]*Validator, 0, len(commit.Signatures))
+		items         = make([]verifier.Item, 0, len(commit.Signatures))
+		doubleVoteErr error
 	)
 
 	for idx, commitSig := range commit.Signatures {
@@ -779,33 +859,49 @@ func (vals *ValidatorSet) VerifyCommitTrusting(chainID string, blockID BlockID,
 
 		if firstIndex, ok := seenVals[valIdx]; ok { // double vote
 			secondIndex := idx
-			return errors.Errorf("double vote from %v (%d and %d)", val, firstIndex, secondIndex)
+			// Only an error if +trustLevel is not reached before this vote.
+			doubleVoteErr = errors.Errorf("double vote from %v (%d and %d)", val, firstIndex, secondIndex)
+			break
 		}
 
 		if val != nil {
 			seenVals[valIdx] = idx
+			idxs = append(idxs, idx)
+			signers = append(signers, val)
+			items = append(items, verifier.Item{
+				PubKey: val.PubKey,
+				Msg:    commit.VoteSignBytes(chainID, idx),
+				Sig:    commitSig.Signature,
+			})
+		}
+	}
+	valid := verifier.Verify(items)
 
-			// Validate signature.
-			voteSignBytes := commit.VoteSignBytes(chainID, idx)
-			if !val.PubKey.VerifyBytes(voteSignBytes, commitSig.Signature) {
-				return errors.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
-			}
+	for i, idx := range idxs {
+		commitSig := commit.Signatures[idx]
 
-			// Good!
-			if blockID.Equals(commitSig.BlockID(commit.BlockID)) {
-				talliedVotingPower += val.VotingPower
-			}
-			// else {
-			// It's OK that the BlockID doesn't match.  We include stray
-			// signatures (~votes for nil) to measure validator availability.
-			// }
+		// Validate signature.
+		if !valid[i] {
+			return errors.Errorf("wrong signature (#%d): %X", idx, commitSig.Signature)
+		}
 
-			if talliedVotingPower > votingPowerNeeded {
-				return nil
-			}
+		// Good!
+		if blockID.Equals(commitSig.BlockID(commit.BlockID)) {
+			talliedVotingPower += signers[i].VotingPower
+		}
+		// else {
+		// It's OK that the BlockID doesn't match.  We include stray
+		// signatures (~votes for nil) to measure validator availability.
+		// }
+
+		if talliedVotingPower > votingPowerNeeded {
+			return nil
 		}
 	}
 
+	if doubleVoteErr != nil {
+		return doubleVoteErr
+	}
 	return ErrNotEnoughVotingPowerSigned{Got: talliedVotingPower, Needed: votingPowerNeeded}
 }
 
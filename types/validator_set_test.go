@@ -146,6 +146,39 @@ func TestProposerSelection1(t *testing.T) {
 	}
 }
 
+func TestRandomProposerSelection(t *testing.T) {
+	vset := NewValidatorSet([]*Validator{
+		newValidator([]byte("foo"), 1000),
+		newValidator([]byte("bar"), 100),
+		newValidator([]byte("baz"), 10),
+	})
+	orig := vset.Copy()
+
+	counts := make(map[string]int)
+	for i := 0; i < 1110; i++ {
+		seed := ProposerSeed("test-chain", []byte("block"), 1, i)
+		val := vset.RandomProposer(seed)
+		// deterministic for a given seed
+		assert.Equal(t, val.Address, vset.RandomProposer(seed).Address)
+		counts[string(val.Address)]++
+	}
+	// selection must not touch priorities
+	assert.Equal(t, orig, vset)
+
+	// weighted by voting power
+	assert.True(t, counts["foo"] > counts["bar"], "%v", counts)
+	assert.True(t, counts["bar"] > counts["baz"], "%v", counts)
+
+	assert.NotEqual(t,
+		ProposerSeed("test-chain", []byte("block"), 1, 0),
+		ProposerSeed("test-chain", []byte("block"), 1, 1))
+	assert.NotEqual(t,
+		ProposerSeed("test-chain", []byte("block"), 1, 0),
+		ProposerSeed("test-chain", []byte("other"), 1, 0))
+
+	assert.Nil(t, NewValidatorSet(nil).RandomProposer([]byte("seed")))
+}
+
 func TestProposerSelection2(t *testing.T) {
 	addr0 := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
 	addr1 := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
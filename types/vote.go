@@ -11,9 +11,14 @@ import (
 )
 
 const (
-	// MaxVoteBytes is a maximum vote size (including amino overhead).
-	MaxVoteBytes int64  = 223
+	// MaxVoteBytes is a maximum vote size (including amino overhead), with an
+	// extension of MaxVoteExtensionBytes.
+	MaxVoteBytes int64  = 1250
 	nilVoteStr   string = "nil-Vote"
+
+	// MaxVoteExtensionBytes is the maximum size of a vote extension (see
+	// ABCIParams).
+	MaxVoteExtensionBytes int64 = 1024
 )
 
 var (
@@ -54,6 +59,10 @@ type Vote struct {
 	ValidatorAddress Address       `json:"validator_address"`
 	ValidatorIndex   int           `json:"validator_index"`
 	Signature        []byte        `json:"signature"`
+
+	// Extension is the data added by the application to a non-nil precommit,
+	// covered by the signature (see ABCIParams).
+	Extension []byte `json:"extension,omitempty"`
 }
 
 // CommitSig converts the Vote to a CommitSig.
@@ -77,6 +86,7 @@ func (vote *Vote) CommitSig() CommitSig {
 		ValidatorAddress: vote.ValidatorAddress,
 		Timestamp:        vote.Timestamp,
 		Signature:        vote.Signature,
+		Extension:        vote.Extension,
 	}
 }
 
@@ -134,6 +144,9 @@ func (vote *Vote) Verify(chainID string, pubKey crypto.PubKey) error {
 
 // ValidateBasic performs basic validation.
 func (vote *Vote) ValidateBasic() error {
+	if vote == nil {
+		return errors.New("nil vote")
+	}
 	if !IsVoteTypeValid(vote.Type) {
 		return errors.New("invalid Type")
 	}
@@ -169,5 +182,13 @@ func (vote *Vote) ValidateBasic() error {
 	if len(vote.Signature) > MaxSignatureSize {
 		return fmt.Errorf("signature is too big (max: %d)", MaxSignatureSize)
 	}
+	if len(vote.Extension) > 0 {
+		if vote.Type != PrecommitType || vote.BlockID.IsZero() {
+			return errors.New("extension is only allowed in non-nil precommits")
+		}
+		if int64(len(vote.Extension)) > MaxVoteExtensionBytes {
+			return fmt.Errorf("extension is too big (max: %d)", MaxVoteExtensionBytes)
+		}
+	}
 	return nil
 }
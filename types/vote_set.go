@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/tendermint/tendermint/crypto/verifier"
 	"github.com/tendermint/tendermint/libs/bits"
 )
 
@@ -149,10 +150,80 @@ func (voteSet *VoteSet) AddVote(vote *Vote) (added bool, err error) {
 	return voteSet.addVote(vote)
 }
 
+// AddVotes adds the votes like AddVote, but verifies their signatures in a
+// batch, which is faster than adding them one by one. It returns, for each
+// vote, whether it was added and the error AddVote would have returned.
+// NOTE: VoteSet must not be nil
+func (voteSet *VoteSet) AddVotes(votes []*Vote) (added []bool, errs []error) {
+	if voteSet == nil {
+		panic("AddVotes() on nil VoteSet")
+	}
+	voteSet.mtx.Lock()
+	defer voteSet.mtx.Unlock()
+
+	added = make([]bool, len(votes))
+	errs = make([]error, len(votes))
+
+	var (
+		idxs  = make([]int, 0, len(votes))
+		items = make([]verifier.Item, 0, len(votes))
+	)
+	for i, vote := range votes {
+		val, known, err := voteSet.checkVote(vote)
+		if err != nil || known {
+			errs[i] = err
+			continue
+		}
+		if !bytes.Equal(val.PubKey.Address(), vote.ValidatorAddress) {
+			errs[i] = voteSet.verifyErr(ErrVoteInvalidValidatorAddress, val)
+			continue
+		}
+		idxs = append(idxs, i)
+		items = append(items, verifier.Item{
+			PubKey: val.PubKey,
+			Msg:    vote.SignBytes(voteSet.chainID),
+			Sig:    vote.Signature,
+		})
+	}
+	valid := verifier.Verify(items)
+
+	for j, i := range idxs {
+		vote := votes[i]
+		// Check the vote again, in case it was given twice.
+		val, known, err := voteSet.checkVote(vote)
+		if err != nil || known {
+			errs[i] = err
+			continue
+		}
+		if !valid[j] {
+			errs[i] = voteSet.verifyErr(ErrVoteInvalidSignature, val)
+			continue
+		}
+		added[i], errs[i] = voteSet.addCheckedVote(vote, val)
+	}
+	return added, errs
+}
+
 // NOTE: Validates as much as possible before attempting to verify the signature.
 func (voteSet *VoteSet) addVote(vote *Vote) (added bool, err error) {
+	val, known, err := voteSet.checkVote(vote)
+	if err != nil || known {
+		return false, err
+	}
+
+	// Check signature.
+	if err := vote.Verify(voteSet.chainID, val.PubKey); err != nil {
+		return false, voteSet.verifyErr(err, val)
+	}
+
+	return voteSet.addCheckedVote(vote, val)
+}
+
+// checkVote validates everything but the signature of vote, and returns its
+// validator. known is true if the vote was already added.
+func (voteSet *VoteSet) checkVote(vote *Vote) (val *Validator, known bool, err error) {
 	if vote == nil {
-		return false, ErrVoteNil
+		return nil, false, ErrVoteNil
 	}
 	valIndex := vote.ValidatorIndex
 	valAddr := vote.ValidatorAddress
@@ -160,16 +231,16 @@ func (voteSet *VoteSet) addVote(vote *Vote) (added bool, err error) {
 
 	// Ensure that validator index was set
 	if valIndex < 0 {
-		return false, errors.Wrap(ErrVoteInvalidValidatorIndex, "Index < 0")
+		return nil, false, errors.Wrap(ErrVoteInvalidValidatorIndex, "Index < 0")
 	} else if len(valAddr) == 0 {
-		return false, errors.Wrap(ErrVoteInvalidValidatorAddress, "Empty address")
+		return nil, false, errors.Wrap(ErrVoteInvalidValidatorAddress, "Empty address")
 	}
 
 	// Make sure the step matches.
 	if (vote.Height != voteSet.height) ||
 		(vote.Round != voteSet.round) ||
 		(vote.Type != voteSet.signedMsgType) {
-		return false, errors.Wrapf(ErrVoteUnexpectedStep, "Expected %d/%d/%d, but got %d/%d/%d",
+		return nil, false, errors.Wrapf(ErrVoteUnexpectedStep, "Expected %d/%d/%d, but got %d/%d/%d",
 			voteSet.height, voteSet.round, voteSet.signedMsgType,
 			vote.Height, vote.Round, vote.Type)
 	}
@@ -177,13 +248,13 @@ func (voteSet *VoteSet) addVote(vote *Vote) (added bool, err error) {
 	// Ensure that signer is a validator.
 	lookupAddr, val := voteSet.valSet.GetByIndex(valIndex)
 	if val == nil {
-		return false, errors.Wrapf(ErrVoteInvalidValidatorIndex,
+		return nil, false, errors.Wrapf(ErrVoteInvalidValidatorIndex,
 			"Cannot find validator %d in valSet of size %d", valIndex, voteSet.valSet.Size())
 	}
 
 	// Ensure that the signer has the right address.
 	if !bytes.Equal(valAddr, lookupAddr) {
-		return false, errors.Wrapf(ErrVoteInvalidValidatorAddress,
+		return nil, false, errors.Wrapf(ErrVoteInvalidValidatorAddress,
 			"vote.ValidatorAddress (%X) does not match address (%X) for vote.ValidatorIndex (%d)\n"+
 				"Ensure the genesis file is correct across all validators.",
 			valAddr, lookupAddr, valIndex)
@@ -192,18 +263,22 @@ func (voteSet *VoteSet) addVote(vote *Vote) (added bool, err error) {
 	// If we already know of this vote, return false.
 	if existing, ok := voteSet.getVote(valIndex, blockKey); ok {
 		if bytes.Equal(existing.Signature, vote.Signature) {
-			return false, nil // duplicate
+			return nil, true, nil // duplicate
 		}
-		return false, errors.Wrapf(ErrVoteNonDeterministicSignature, "Existing vote: %v; New vote: %v", existing, vote)
+		return nil, false, errors.Wrapf(ErrVoteNonDeterministicSignature, "Existing vote: %v; New vote: %v", existing, vote)
 	}
 
-	// Check signature.
-	if err := vote.Verify(voteSet.chainID, val.PubKey); err != nil {
-		return false, errors.Wrapf(err, "Failed to verify vote with ChainID %s and PubKey %s", voteSet.chainID, val.PubKey)
-	}
+	return val, false, nil
+}
+
+func (voteSet *VoteSet) verifyErr(err error, val *Validator) error {
+	return errors.Wrapf(err, "Failed to verify vote with ChainID %s and PubKey %s", voteSet.chainID, val.PubKey)
+}
 
+// addCheckedVote adds vote, whose signature of val was verified.
+func (voteSet *VoteSet) addCheckedVote(vote *Vote, val *Validator) (added bool, err error) {
 	// Add vote and get conflicting vote if any.
-	added, conflicting := voteSet.addVerifiedVote(vote, blockKey, val.VotingPower)
+	added, conflicting := voteSet.addVerifiedVote(vote, vote.BlockID.Key(), val.VotingPower)
 	if conflicting != nil {
 		return added, NewConflictingVoteError(val, conflicting, vote)
 	}
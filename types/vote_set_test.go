@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/crypto"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
@@ -337,6 +339,48 @@ func TestBadVotes(t *testing.T) {
 	}
 }
 
+func TestAddVotes(t *testing.T) {
+	height, round := int64(1), 0
+	voteSet, _, privValidators := randVoteSet(height, round, PrecommitType, 10, 1)
+
+	voteProto := &Vote{
+		Height:    height,
+		Round:     round,
+		Timestamp: tmtime.Now(),
+		Type:      PrecommitType,
+		BlockID:   BlockID{nil, PartSetHeader{}},
+	}
+	votes := make([]*Vote, 7)
+	for i := range votes {
+		vote := withValidator(voteProto, privValidators[i].GetPubKey().Address(), i)
+		err := privValidators[i].SignVote(voteSet.ChainID(), vote)
+		require.NoError(t, err)
+		votes[i] = vote
+	}
+	votes[1].Signature[0] ^= 0x01           // bad signature
+	votes[2] = withRound(votes[2], round+1) // wrong round
+	votes[6] = votes[5]                     // duplicate
+
+	added, errs := voteSet.AddVotes(votes)
+	assert.Equal(t, []bool{true, false, false, true, true, true, false}, added)
+	assert.NoError(t, errs[0])
+	assert.True(t, errors.Cause(errs[1]) == ErrVoteInvalidSignature, "%v", errs[1])
+	assert.True(t, errors.Cause(errs[2]) == ErrVoteUnexpectedStep, "%v", errs[2])
+	assert.NoError(t, errs[6])
+
+	assert.NotNil(t, voteSet.GetByIndex(0))
+	assert.Nil(t, voteSet.GetByIndex(1))
+	_, ok := voteSet.TwoThirdsMajority()
+	assert.False(t, ok)
+
+	// votes can still be added one by one
+	vote := withValidator(voteProto, privValidators[7].GetPubKey().Address(), 7)
+	added7, err := signAddVote(privValidators[7], vote, voteSet)
+	require.NoError(t, err)
+	assert.True(t, added7)
+	assert.NotNil(t, voteSet.GetByIndex(7))
+}
+
 func TestConflicts(t *testing.T) {
 	height, round := int64(1), 0
 	voteSet, _, privValidators := randVoteSet(height, round, PrevoteType, 4, 1)
@@ -170,6 +170,26 @@ func TestVoteVerifySignature(t *testing.T) {
 	require.True(t, valid)
 }
 
+func TestVoteExtensionSignBytes(t *testing.T) {
+	vote := examplePrecommit()
+	signBytes := vote.SignBytes("test_chain_id")
+
+	// no extension, same sign bytes
+	vote.Extension = []byte{}
+	require.Equal(t, signBytes, vote.SignBytes("test_chain_id"))
+
+	// the extension is signed
+	vote.Extension = []byte("extension")
+	require.NotEqual(t, signBytes, vote.SignBytes("test_chain_id"))
+
+	// and kept in the commit
+	privVal := NewMockPV()
+	require.NoError(t, privVal.SignVote("test_chain_id", vote))
+	commit := NewCommit(vote.Height, vote.Round, vote.BlockID, []CommitSig{vote.CommitSig()})
+	assert.Equal(t, vote.Extension, commit.GetVote(0).Extension)
+	assert.True(t, privVal.GetPubKey().VerifyBytes(commit.VoteSignBytes("test_chain_id", 0), vote.Signature))
+}
+
 func TestIsVoteTypeValid(t *testing.T) {
 	tc := []struct {
 		name string
@@ -220,7 +240,7 @@ func TestMaxVoteBytes(t *testing.T) {
 		Height:           math.MaxInt64,
 		Round:            math.MaxInt64,
 		Timestamp:        timestamp,
-		Type:             PrevoteType,
+		Type:             PrecommitType,
 		BlockID: BlockID{
 			Hash: tmhash.Sum([]byte("blockID_hash")),
 			PartsHeader: PartSetHeader{
@@ -228,6 +248,7 @@ func TestMaxVoteBytes(t *testing.T) {
 				Hash:  tmhash.Sum([]byte("blockID_part_set_header_hash")),
 			},
 		},
+		Extension: make([]byte, MaxVoteExtensionBytes),
 	}
 
 	privVal := NewMockPV()
@@ -272,6 +293,16 @@ func TestVoteValidateBasic(t *testing.T) {
 		{"Invalid ValidatorIndex", func(v *Vote) { v.ValidatorIndex = -1 }, true},
 		{"Invalid Signature", func(v *Vote) { v.Signature = nil }, true},
 		{"Too big Signature", func(v *Vote) { v.Signature = make([]byte, MaxSignatureSize+1) }, true},
+		{"Extension", func(v *Vote) { v.Extension = []byte("extension") }, false},
+		{"Too big Extension", func(v *Vote) { v.Extension = make([]byte, MaxVoteExtensionBytes+1) }, true},
+		{"Extension of a nil precommit", func(v *Vote) {
+			v.BlockID = BlockID{}
+			v.Extension = []byte("extension")
+		}, true},
+		{"Extension of a prevote", func(v *Vote) {
+			v.Type = PrevoteType
+			v.Extension = []byte("extension")
+		}, true},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -283,4 +314,5 @@ func TestVoteValidateBasic(t *testing.T) {
 			assert.Equal(t, tc.expectErr, vote.ValidateBasic() != nil, "Validate Basic had an unexpected result")
 		})
 	}
+	assert.Error(t, (*Vote)(nil).ValidateBasic())
 }